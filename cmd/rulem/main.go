@@ -16,53 +16,75 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"rulem/internal/checks"
 	"rulem/internal/config"
+	"rulem/internal/deploy"
+	"rulem/internal/diagnostics"
+	"rulem/internal/editors"
+	"rulem/internal/filemanager"
+	"rulem/internal/hooks"
+	"rulem/internal/lint"
 	"rulem/internal/logging"
+	"rulem/internal/lsp"
+	"rulem/internal/migrate"
+	"rulem/internal/notes"
+	"rulem/internal/onboarding"
+	"rulem/internal/policy"
+	"rulem/internal/project"
+	"rulem/internal/repository"
+	"rulem/internal/search"
+	"rulem/internal/share"
+	"rulem/internal/summary"
 	"rulem/internal/tui"
 	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/plainmenu"
 	"rulem/internal/tui/setupmenu"
+	"rulem/internal/version"
+	"rulem/pkg/fileops"
 	"runtime"
-	"runtime/debug"
+	"slices"
+	"strings"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
 	mcp "rulem/internal/mcp"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
-// Version info (set by GoReleaser via -ldflags at release time)
+// Build info (set by GoReleaser via -ldflags at release time; the version itself
+// lives in internal/version so other packages can compare against it)
 var (
-	version = "dev"
-	commit  = "none"
-	date    = "unknown"
+	commit = "none"
+	date   = "unknown"
 )
 
 // versionString renders the full version line shared by `rulem version` and
 // `rulem --version`.
 func versionString() string {
-	return fmt.Sprintf("rulem %s (%s) built on %s", resolveVersion(), commit, date)
-}
-
-// resolveVersion returns the release tag. GoReleaser stamps it into `version`,
-// but for builds that skip those ldflags (`go install`, `go build`) fall back to
-// the module version the toolchain recorded, which is the git tag it resolved.
-func resolveVersion() string {
-	if version != "dev" {
-		return version
-	}
-	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
-		return info.Main.Version
-	}
-	return version
+	return fmt.Sprintf("rulem %s (%s) built on %s", version.Current(), commit, date)
 }
 
 var (
-	debugMode bool
-	appLogger *logging.AppLogger
+	debugMode   bool
+	tokenOnce   bool
+	noScanCache bool
+	showTour    bool
+	plainUI     bool
+	startup     string
+	appLogger   *logging.AppLogger
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -85,15 +107,97 @@ Key features:
   # Start with debug logging enabled
   rulem --debug
 
+  # Clone or fetch a private repository with a token that is never saved
+  rulem --token-once
+
+  # Replay the first-run tour and show dismissed tips again
+  rulem --tour
+
+  # Use numbered prompts instead of the TUI, e.g. to answer them from a script
+  printf '2\n3\n' | rulem --plain
+
+  # Open the weekly summary instead of the main menu
+  rulem --startup dashboard
+
   # Start the MCP server
   rulem mcp
 
+  # Serve the rules as they were at a tag, for a reproducible assistant run
+  rulem mcp --at v1.4.0
+
+  # Only serve the Go backend rules that apply to files under src/
+  rulem mcp --tags backend,go --scope "src/**"
+
+  # Serve the repositories and rules of the "work" profile of config.yaml
+  rulem mcp --profile work
+
+  # Run the MCP server as a daemon that several editors connect to
+  rulem mcp --transport http --listen 127.0.0.1:7331
+
+  # Start the experimental rule file language server for your editor
+  rulem lsp
+
+  # Print a rule to stdout, e.g. to copy it
+  rulem cat go-style | pbcopy
+
+  # List every rule with its description, tags and size, as JSON
+  rulem list --json
+
+  # Rate a rule and note why, privately
+  rulem note go-style "Too strict on comments" --rating 3
+
+  # Search every rule repository, ripgrep-style
+  rulem grep -C 2 'fmt\.Println'
+
+  # Show how rules deployed in this project differ from their central versions
+  rulem diff
+
+  # List outdated, edited and missing deployed rules and bring them up to date
+  rulem status --sync
+
+  # Fail a CI job when deployed rules are stale or invalid
+  rulem verify --ci
+
+  # Check the rules of your repositories, e.g. in their CI
+  rulem lint --format json
+
+  # List the rules deployed in this project with their provenance
+  rulem inventory --format cyclonedx > rules.cdx.json
+
+  # Share rules publicly with organization names redacted
+  rulem export --all -o rules.zip
+
+  # Summarize the last week of rule activity
+  rulem summary
+
+  # Show the rules your assistants read most and least
+  rulem stats
+
+  # Run checks declared by rules against the current project
+  rulem check
+
+  # Show organization policies applied by your rule repositories
+  rulem policy
+
+  # Import Cursor rules into your first local rule repository
+  rulem migrate cursor .cursor/rules
+
+  # Save a rule file into a rule repository from a script
+  rulem save .github/copilot-instructions.md --name go-style.md --repo "Team Rules"
+
+  # Copy rules from your repositories into this project
+  rulem deploy go-style testing --target cursor
+
+  # Fetch the latest rules of every GitHub repository, e.g. from cron
+  rulem sync --all
+
   # Show version information
   rulem version
   rulem --version
 
 Note: Debug logs are saved to ./rulem.log in the current directory`,
-	RunE: runTUI,
+	PersistentPreRunE: prepareRun,
+	RunE:              runTUI,
 }
 
 // versionCmd represents the version command
@@ -117,24 +221,795 @@ with AI assistants that support the MCP standard.
 This allows rulem to be used as a context provider for AI assistants,
 giving them access to your organized instruction files.
 
-The server communicates via stdin/stdout using JSON-RPC as per MCP specification.`,
+The server communicates via stdin/stdout using JSON-RPC as per MCP specification.
+With --transport http (streamable HTTP at /mcp) or --transport sse (SSE at /sse)
+it instead runs as a long-lived daemon listening on --listen, so several editors
+can share one server. Set --auth-token, or $RULEM_MCP_TOKEN, to require
+clients to send the token as a bearer token.
+
+Rule files are watched while the server runs: added, edited and removed rules
+are picked up without a restart and clients are told the tool list changed.
+Pass --watch=false to serve the rules as they were at startup.
+
+With --max-calls-per-minute and --max-session-bytes each client session is
+limited in how many tool calls and resource reads it makes per minute and how
+much rule content it is served, so one runaway client cannot monopolise a
+shared server. Clients over a limit get an error result explaining it.
+
+Every rule served is recorded in a usage log (see rulem stats), with its file
+and the client it was served to. The log is rotated at 5 MB, keeping 2 old
+files. Pass --usage-log=false to serve rules without recording them.
+
+With --audit-log every access to a rule, including refused ones, is appended
+to an audit log for SIEM ingestion, as JSON lines or in Common Event Format
+(--audit-format cef). The log is rotated at 10 MB, keeping 5 old files.
+
+With --socket the same rules are also served as newline-delimited JSON on a
+local unix socket, for scripts, git hooks and editors without MCP support.
+
+At startup every tool is checked against the MCP specification and the limits
+of the clients listed under mcp_clients in config.yaml (claude, copilot,
+cursor, gemini, openai), such as name length and the number of tools loaded,
+and each problem is logged. --self-check runs only the check, printing the
+problems and exiting with status 1 when there are any.
+
+With --at the rules of Git repositories are served as of a branch, tag or
+commit, read from git objects without touching the clone, so assistant runs
+can be reproduced against a fixed snapshot of the rules. --at <rev> applies to
+every GitHub repository, --at <repo>=<rev> to one repository.
+
+With --tags and --scope only the relevant rules are served, for a
+project-specific MCP configuration: --tags keeps rules with one of the tags in
+their frontmatter, --scope keeps rules whose scope globs can match files under
+one of the given globs. Rules without a scope apply to every file and pass
+--scope.
+
+With --profile one installation serves each client its own tools: the named
+entry of mcp_profiles in config.yaml selects the repositories served, the
+tags and scope of the rules (unless --tags or --scope are given) and the
+largest rule file served.
+
+Launched in a project with a .rulem.yaml, the server serves the rules with
+the tags it lists, unless --tags or --profile choose others.`,
+	Example: `  rulem mcp
+  rulem mcp --socket
+  rulem mcp --socket-path /tmp/rulem.sock
+  rulem mcp --at v1.4.0
+  rulem mcp --at "Team Rules=3f9a0c12"
+  rulem mcp --tags backend,go --scope "src/**"
+  rulem mcp --profile work
+  rulem mcp --transport http
+  RULEM_MCP_TOKEN=secret rulem mcp --transport http --listen 0.0.0.0:7331
+  rulem mcp --transport sse --max-calls-per-minute 60 --max-session-bytes 10485760
+  rulem mcp --transport http --audit-log /var/log/rulem/audit.cef --audit-format cef
+  rulem mcp --self-check`,
 	RunE: runMCPServer,
 }
 
+var (
+	mcpSocket     bool
+	mcpSocketPath string
+	mcpAt         []string
+	mcpTransport  string
+	mcpListen     string
+	mcpAuthToken  string
+	mcpWatch      bool
+	mcpMaxCalls   int
+	mcpMaxBytes   int64
+	mcpAuditLog   string
+	mcpAuditFmt   string
+	mcpUsageLog   bool
+	mcpSelfCheck  bool
+	mcpTags       []string
+	mcpScope      []string
+	mcpProfile    string
+)
+
+// lspCmd represents the experimental language server command
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Start the rule file language server (experimental)",
+	Long: `Start an experimental Language Server Protocol server for editing rule
+files in your configured repositories.
+
+Editors get diagnostics for frontmatter that would keep a rule from being
+registered as an MCP tool, unknown frontmatter keys and broken links,
+completion of frontmatter keys, tags and links to other rule files, and
+hover descriptions of linked rules.
+
+The server communicates via stdin/stdout using JSON-RPC, like the MCP server.`,
+	SilenceUsage: true,
+	RunE:         runLSPServer,
+}
+
+var (
+	catRepo   string
+	catRender bool
+)
+
+// catCmd represents the cat command
+var catCmd = &cobra.Command{
+	Use:   "cat <rule-name>",
+	Short: "Print a rule to stdout",
+	Long: `Print the content of a rule (without its frontmatter) to stdout, so it can
+be piped into other tools.
+
+A rule can be named by its MCP tool name, its frontmatter name or its file
+name without extension. Case is ignored and "-" matches "_". Use --repo to
+pick between rules with the same name in different repositories.`,
+	Example: `  rulem cat go-style | pbcopy
+  rulem cat testing --repo "Team Rules"
+  rulem cat go-style --render`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runCat,
+}
+
+var (
+	listRepo string
+	listJSON bool
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the rule files of your repositories",
+	Long: `List the rule files of every configured repository with their MCP tool
+name, frontmatter description and tags, and size.
+
+Rules without a description are listed too, without a tool name: they are not
+served over MCP. With --json the list is printed as a JSON array, for piping
+into other tools:
+
+  [{"repository": "Team Rules", "repositoryId": "team-rules-3f9a0c12",
+    "path": "go/style.md", "tool": "go_style", "description": "...",
+    "tags": ["go"], "size": 1234}]`,
+	Example: `  rulem list
+  rulem list --repo "Team Rules"
+  rulem list --json | jq -r '.[] | select(.tags | index("go")) | .path'`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runList,
+}
+
+var (
+	noteRepo   string
+	noteRating int
+	noteClear  bool
+)
+
+// noteCmd represents the note command
+var noteCmd = &cobra.Command{
+	Use:   "note <rule-name> [text]",
+	Short: "Attach a private note or star rating to a rule",
+	Long: `Record a private note and a star rating from 1 to 5 for a rule, to remember
+which rules work well for you. Ratings are shown next to rules in the TUI's
+file picker, and the note and rating above the rule's preview.
+
+Notes are stored in your data directory, outside every repository, so they
+are never committed or shared. Rules are named like in rulem cat. Without text
+or flags the command prints the rule's current note.`,
+	Example: `  rulem note go-style "Great for new services, too strict on comments" --rating 4
+  rulem note go-style --rating 5
+  rulem note go-style
+  rulem note go-style --clear`,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE:         runNote,
+}
+
+var (
+	grepIgnoreCase   bool
+	grepBefore       int
+	grepAfter        int
+	grepContext      int
+	grepFilesMatches bool
+)
+
+// grepCmd represents the grep command
+var grepCmd = &cobra.Command{
+	Use:   "grep <pattern>",
+	Short: "Search all rule repositories with a regular expression",
+	Long: `Search the markdown files of every configured repository for lines matching
+a regular expression (Go RE2 syntax) and print them ripgrep-style as
+path:line:text, with context lines as path-line-text.
+
+The command exits with a non-zero status when nothing matches.`,
+	Example: `  rulem grep 'fmt\.Println'
+  rulem grep -i -C 2 'error handling'
+  rulem grep -l TODO`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runGrep,
+}
+
+var diffRef string
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show how rules deployed in this project differ from their central versions",
+	Long: `Compare every rule recorded in the project manifest (.rulem/deployed.yaml)
+with its central version and print unified diffs that would bring the
+project's copies up to date.
+
+With --ref the central version is read at a branch, tag or commit of the
+repository's git history instead of its working tree.
+
+The exit status is 0 when every deployed rule is up to date, 1 when any rule
+differs and 2 when a rule could not be compared, so the command can gate
+stale rules in CI.`,
+	Example: `  rulem diff
+  rulem diff --ref main
+  rulem diff --ref 9b1c3f0 > update.patch`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDiff,
+}
+
+var (
+	statusSync  bool
+	statusForce bool
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which rules deployed in this project drifted from their central versions",
+	Long: `List every rule recorded in the project manifest (.rulem/deployed.yaml)
+with how it compares with its central version:
+
+  up to date      the project has the central version
+  outdated        the central version changed since the rule was deployed
+  modified        the rule was edited in the project since it was deployed
+  missing         the deployed file was deleted from the project
+  source missing  the rule no longer exists in its repository
+
+With --sync outdated and missing rules are deployed again, the way they were
+deployed before; modified rules are only replaced with --force, which
+discards the project's edits. Rules whose source is missing are left alone.
+Use rulem diff to see what changed.
+
+The exit status is 0 when every rule is up to date (after --sync), 1 when
+any rule has drifted and 2 when a rule could not be compared or re-synced.`,
+	Example: `  rulem status
+  rulem status --sync
+  rulem status --sync --force`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runStatus,
+}
+
+var (
+	verifyCI  bool
+	verifyRef string
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the rules deployed in this project are up to date and valid",
+	Long: `Verify every rule recorded in the project manifest (.rulem/deployed.yaml):
+
+  drift   The deployed file differs from its central version (see rulem diff)
+  schema  The rule would not be registered as an MCP tool, e.g. it lacks a
+          description or breaks its repository's rulem.yaml schema or tags
+  lint    The rule sets unknown frontmatter keys, declares invalid checks,
+          looks like a prompt injection or hides text with invisible
+          characters, bidi controls or homoglyphs
+
+Problems are printed as path:line: kind: message. With --ci they are printed
+as GitHub Actions error annotations, which show up on the files in workflow
+runs and pull requests.
+
+The exit status is 0 when every rule passes, 1 when problems were found and 2
+when a rule could not be verified.`,
+	Example: `  rulem verify
+  rulem verify --ci
+  rulem verify --ci --ref main`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runVerify,
+}
+
+var (
+	lintRepo     string
+	lintFormat   string
+	lintMaxLines int
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the rule files of your repositories for problems",
+	Long: `Check every rule file of the configured repositories, e.g. in the CI of a
+rule repository:
+
+  frontmatter          The frontmatter cannot be parsed or breaks the
+                       frontmatter schema or the repository's rulem.yaml
+  missing-description  The rule has no description
+  invalid-tags         A tag is empty, repeated or not in the repository's
+                       tag list
+  unknown-key          The frontmatter sets a key rulem does not know
+  too-long             The rule has more lines than --max-lines
+  duplicate-name       Two rules would be served under the same tool name
+
+Problems are printed as path:line: check: message under the name of their
+repository. With --format json they are printed as a JSON array:
+
+  [{"repository": "Team Rules", "path": "go/style.md", "line": 1,
+    "check": "missing-description", "message": "..."}]
+
+The exit status is 0 when every rule passes, 1 when problems were found and 2
+when the rules could not be read.`,
+	Example: `  rulem lint
+  rulem lint --repo "Team Rules" --max-lines 300
+  rulem lint --format json | jq -r '.[].path' | sort -u`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runLint,
+}
+
+var (
+	inventoryFormat string
+	inventoryOutput string
+)
+
+// inventoryCmd represents the inventory command
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "List the rules deployed in this project with their provenance",
+	Long: `Print a machine-readable inventory of the rules recorded in the project
+manifest (.rulem/deployed.yaml): for each rule its name, the repository and
+commit it was deployed from, the SHA-256 of the deployed file, whether it was
+modified since, and the license from its frontmatter.
+
+Formats:
+  json       rulem's own JSON inventory (default)
+  cyclonedx  A CycloneDX 1.5 JSON bill of materials, with each rule as a
+             file component, for tools that track dependency provenance
+
+Repositories are not synced, so the inventory works offline.`,
+	Example: `  rulem inventory
+  rulem inventory --format cyclonedx --output rules.cdx.json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runInventory,
+}
+
+var (
+	exportOutput string
+	exportRepo   string
+	exportAll    bool
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [rule-name...]",
+	Short: "Export rules as an anonymized bundle that can be shared",
+	Long: `Write the selected rules, frontmatter included, to a zip archive that can be
+published. Rules are named like in rulem cat; use --all to export every rule.
+
+The redactions in the configuration replace organization-identifying strings,
+such as company names and internal hostnames, in the content and path of every
+exported rule:
+
+  redactions:
+    - pattern: '(?i)acme corp'
+      replace: Example Corp
+    - pattern: '[a-z0-9.-]+\.acme\.internal'
+      replace: example.com
+
+Patterns use Go RE2 syntax; matches of a redaction without a replacement
+become [REDACTED]. Review the bundle before publishing it: redactions only
+catch what they describe.`,
+	Example: `  rulem export go-style testing -o go-rules.zip
+  rulem export --all --repo "Team Rules" -o team-rules.zip`,
+	SilenceUsage: true,
+	RunE:         runExport,
+}
+
+var (
+	summaryDays  int
+	summaryRepo  string
+	summaryWrite bool
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show which rules assistants use most and least",
+	Long: `Rank your rules by how often rulem mcp served them to assistants over the
+last 30 days, from the usage log, and print the most and least used ones.
+
+Rules that were not served at all are listed as never used, so rules nobody
+reads stand out. The clients the rules were served to are listed too. With
+--json every rule is printed with its count, when it was last used and the
+clients it was served to.
+
+The usage log is written by rulem mcp unless it runs with --usage-log=false.`,
+	Example: `  rulem stats
+  rulem stats --days 7 --limit 5
+  rulem stats --repo "Team Rules" --json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runStats,
+}
+
+var (
+	statsDays  int
+	statsRepo  string
+	statsLimit int
+	statsJSON  bool
+)
+
+// summaryCmd represents the summary command
+var summaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize recent rule activity",
+	Long: `Print a markdown summary of the last week of rule activity:
+
+  New and changed rules  Rule files added, changed or deleted, from git history
+                         (modification times for local directories)
+  Most-served rules      Rules served to assistants by rulem mcp
+  Reviews due            Rules whose reviewBy date falls before the end of the
+                         next period, including overdue ones
+  Expiring rules         Rules whose expires date falls likewise
+
+GitHub repositories are synced first so their changes are included. With
+--write the summary is also saved to summaries/<date>.md in the repository
+chosen with --repo, ready to be committed so the whole team can read it. The
+summary is also shown in the TUI.`,
+	Example: `  rulem summary
+  rulem summary --days 30 --repo "Team Rules" --write`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runSummary,
+}
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Run checks declared by rules against the current project",
+	Long: `Run the lightweight lint checks declared in rule frontmatter under the
+"check" key against every file in the current directory.
+
+Each check is a regular expression that must not match, limited to files
+selected by a glob. Violations are printed as path:line: message and the
+command exits with a non-zero status if any are found, so it can be used
+as a pre-commit hook.`,
+	Example: `  check:
+    - pattern: 'fmt\.Println\('
+      files: "*.go"
+      message: Use the structured logger instead of fmt.Println`,
+	SilenceUsage: true,
+	RunE:         runCheck,
+}
+
+// policyCmd represents the policy command
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Show organization policies applied by your rule repositories",
+	Long: `Show the organization policies found in rulem-policy.yaml at the root of
+your configured rule repositories, and whether this client satisfies them.
+
+Policies are also evaluated whenever rulem starts; a violated policy stops
+rulem with an explanation of what needs to change.`,
+	SilenceUsage: true,
+	RunE:         runPolicy,
+}
+
+var (
+	migrateTo         string
+	migrateOverwrite  bool
+	migrateOnConflict string
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate <cursor|markdown> <source-dir>",
+	Short: "Import rules written for other tools into a rule repository",
+	Long: `Translate a directory of rules written for another tool into rulem rule
+files with generated frontmatter, then print a migration report.
+
+Sources:
+  cursor    Cursor rules (.mdc files, e.g. .cursor/rules); description and
+            globs become rulem's description and applyTo
+  markdown  Plain markdown rules without frontmatter (e.g. an ai-rules layout);
+            the description is taken from the first heading or the file name
+
+By default rules are written to your first local rule repository. A rule that
+already exists there is skipped, unless save_collision in the configuration or
+--on-conflict says to save it under a new name (rename) or replace it
+(overwrite).`,
+	Example: `  rulem migrate cursor .cursor/rules
+  rulem migrate markdown ./ai-rules --to ~/rules/imported
+  rulem migrate cursor .cursor/rules --on-conflict rename`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE:         runMigrate,
+}
+
+var (
+	saveName       string
+	saveRepo       string
+	saveOverwrite  bool
+	saveOnConflict string
+	saveJSON       bool
+)
+
+// saveCmd represents the save command
+var saveCmd = &cobra.Command{
+	Use:   "save <file>",
+	Short: "Save a rule file into a rule repository",
+	Long: `Copy a rule file into a rule repository without the TUI, for scripts and CI.
+
+The file is saved to the repository chosen with --repo (by name or ID), or to
+your first local rule repository. Shared storage is never written: rules go to
+its overlay. GitHub repositories are not synced, so their clone must exist.
+
+When the repository already has a rule with that name, save_collision in the
+configuration decides what happens, and --on-conflict overrides it: ask (the
+default) fails with already_exists, rename saves the rule with a numeric
+suffix (go-style-2.md) and overwrite replaces it. --overwrite is short for
+--on-conflict overwrite.
+
+On success the destination path is printed. With --json the result is printed
+as {"path": ..., "repository": ...}, and errors as {"error": {"code": ...,
+"message": ...}} on stdout, with one of these codes:
+
+  source_invalid      The file does not exist or cannot be read
+  repository_unknown  No repository matches --repo, or none is configured
+  name_invalid        --name is not a usable file name
+  already_exists      The repository already has a rule with that name
+  save_failed         The file could not be written
+
+The exit status is 0 on success, 2 when the rule already exists (retry with
+--overwrite or --on-conflict rename) and 1 for other errors.`,
+	Example: `  rulem save .github/copilot-instructions.md
+  rulem save AGENTS.md --name go-style.md --repo "Team Rules" --overwrite
+  rulem save AGENTS.md --name go-style.md --on-conflict rename
+  rulem save rules/testing.md --json`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runSave,
+}
+
+var (
+	deployAs        string
+	deployTarget    string
+	deployRepo      string
+	deployLink      bool
+	deployOverwrite bool
+)
+
+// deployCmd represents the deploy command
+var deployCmd = &cobra.Command{
+	Use:   "deploy <rule>...",
+	Short: "Copy or link rules from your repositories into this project",
+	Long: `Copy rules from your rule repositories into the project in the current
+directory, the reverse of rulem save.
+
+Rules are named like in rulem cat, or by their path in the repository
+(go/style.md) or file name, which also finds rules without a description.
+
+--target picks the editor the rules are for, which decides where each rule
+goes and how its frontmatter is rewritten: agents (AGENTS.md, the default),
+copilot, copilot-instructions, cursor, claude, gemini, windsurf, or a target
+configured under deploy_targets. --as sets the destination instead; a path
+ending in / or naming an existing directory receives each rule under its own
+file name, so several rules can be deployed at once with e.g. --as docs/rules/.
+
+With --link the project gets a symlink to the rule instead of a copy, so it
+follows changes to the rule; links show the rule unchanged, without the
+target's frontmatter rewrite. Existing files are only replaced with
+--overwrite. Copies are written all at once: when one fails, the project is
+left unchanged.
+
+Every deployed rule is recorded in the project manifest (.rulem/deployed.yaml)
+for rulem diff, verify and inventory, and the pre-deploy and post-deploy hooks
+run for it. The deployed paths are printed.
+
+The exit status is 0 on success, 2 when a destination already exists (retry
+with --overwrite) and 1 for other errors.`,
+	Example: `  rulem deploy go-style
+  rulem deploy go-style testing --target cursor
+  rulem deploy go-style --target copilot-instructions --as .github/instructions/go.instructions.md
+  rulem deploy go-style testing --as docs/rules/ --link
+  rulem deploy go/style.md --repo "Team Rules" --target claude --overwrite`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runDeploy,
+}
+
+var (
+	syncRepo string
+	syncAll  bool
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch the latest rules of your GitHub repositories",
+	Long: `Fetch updates for the GitHub rule repositories, without the TUI, so they can
+be kept fresh from cron or a CI job. Branches served from worktrees are
+fetched too, and post-sync hooks run as usual.
+
+Repositories with uncommitted changes, or that are inspecting an older commit,
+are skipped and left untouched. Local repositories are skipped.
+
+The result of every repository is printed. The exit status is 1 when any
+repository failed to sync, with the failures printed to stderr.`,
+	Example: `  rulem sync --all
+  rulem sync --repo "Team Rules"
+
+  # Refresh every hour
+  0 * * * * rulem sync --all`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runSync,
+}
+
+// authCmd groups the commands managing rulem's credentials
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage the GitHub credentials rulem uses",
+}
+
+var (
+	authLoginRepo string
+	authLoginHost string
+)
+
+// authLoginCmd represents the auth login command
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Sign in to GitHub in the browser instead of creating a PAT",
+	Long: `Sign in to GitHub with the device flow: rulem prints a one-time code, you
+enter it at github.com/login/device and approve rulem, and the token GitHub
+issues is stored like a PAT, replacing the one stored before. The scopes you
+granted are printed; rulem needs "repo" for private repositories.
+
+With --repo the token is stored for that repository only, and with --host for
+the repositories on that host without a token of their own; the default token
+is left alone.
+
+Press Ctrl+C to stop waiting.`,
+	Example: `  rulem auth login
+  rulem auth login --repo "Team Rules"   # a token for one repository, e.g. behind SSO
+  rulem auth login --host github.com`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runAuthLogin,
+}
+
 func init() {
 	// Setting Version makes Cobra handle --version on rootCmd. Registering the
 	// flag ourselves first stops Cobra adding its default one, which would also
 	// claim -v as a shorthand; leave -v free for a future --verbose.
-	rootCmd.Version = resolveVersion()
+	rootCmd.Version = version.Current()
 	rootCmd.SetVersionTemplate(versionString() + "\n")
 	rootCmd.Flags().Bool("version", false, "version for rulem")
+	rootCmd.Flags().BoolVar(&showTour, "tour", false, "Replay the first-run tour and show dismissed tips again")
+	rootCmd.Flags().BoolVar(&plainUI, "plain", false, "Use numbered prompts instead of the TUI; the default when there is no terminal")
+	rootCmd.Flags().StringVar(&startup, "startup", "", "What to do on launch once setup is complete: menu, dashboard, sync or mcp (defaults to startup_action)")
 
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&debugMode, "debug", "d", false, "Enable debug logging")
+	rootCmd.PersistentFlags().BoolVar(&tokenOnce, "token-once", false, "Prompt for a GitHub token used for a single clone or fetch and never saved")
+	rootCmd.PersistentFlags().BoolVar(&noScanCache, "no-scan-cache", false, "Scan rule repositories from scratch instead of reusing the scan cache")
 
 	// Add subcommands
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(mcpCmd)
+	rootCmd.AddCommand(lspCmd)
+	rootCmd.AddCommand(catCmd)
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(noteCmd)
+	rootCmd.AddCommand(grepCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(inventoryCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(summaryCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(policyCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(saveCmd)
+	rootCmd.AddCommand(deployCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authLoginCmd)
+	authLoginCmd.Flags().StringVar(&authLoginRepo, "repo", "", "Store the token for the repository with this name or ID only")
+	authLoginCmd.Flags().StringVar(&authLoginHost, "host", "", "Store the token for the repositories on this host")
+	authLoginCmd.MarkFlagsMutuallyExclusive("repo", "host")
+
+	mcpCmd.Flags().BoolVar(&mcpSocket, "socket", false, "Also serve rules on a local unix socket at "+mcp.DefaultSocketPath())
+	mcpCmd.Flags().StringVar(&mcpSocketPath, "socket-path", "", "Serve rules on a local unix socket at this path (implies --socket)")
+	mcpCmd.Flags().StringArrayVar(&mcpAt, "at", nil, "Serve rules as of this branch, tag or commit; <repo>=<rev> pins one repository (repeatable)")
+	mcpCmd.Flags().BoolVar(&mcpWatch, "watch", true, "Reload the rules when rule files are added, edited or removed")
+	mcpCmd.Flags().StringVar(&mcpTransport, "transport", string(mcp.TransportStdio), "How clients connect: stdio, http (streamable HTTP) or sse")
+	mcpCmd.Flags().StringVar(&mcpListen, "listen", mcp.DefaultHTTPAddress, "Address the http and sse transports listen on")
+	mcpCmd.Flags().StringVar(&mcpAuthToken, "auth-token", "", "Bearer token http and sse clients must send (default $"+mcp.AuthTokenEnv+")")
+	mcpCmd.Flags().IntVar(&mcpMaxCalls, "max-calls-per-minute", 0, "Tool calls and resource reads each client session may make per minute (0 for no limit)")
+	mcpCmd.Flags().Int64Var(&mcpMaxBytes, "max-session-bytes", 0, "Bytes of rule content each client session may be served (0 for no limit)")
+	mcpCmd.Flags().StringVar(&mcpAuditLog, "audit-log", "", "Append every access to a rule to this audit log")
+	mcpCmd.Flags().StringVar(&mcpAuditFmt, "audit-format", string(mcp.AuditJSONL), "Audit log format: jsonl or cef")
+	mcpCmd.Flags().BoolVar(&mcpUsageLog, "usage-log", true, "Record the rules served in the usage log read by rulem stats and rulem summary")
+	mcpCmd.Flags().BoolVar(&mcpSelfCheck, "self-check", false, "Check the tools that would be served against the MCP specification and mcp_clients, then exit")
+	mcpCmd.Flags().StringSliceVar(&mcpTags, "tags", nil, "Only serve rules with one of these frontmatter tags (comma-separated)")
+	mcpCmd.Flags().StringArrayVar(&mcpScope, "scope", nil, "Only serve rules whose scope can match files under this glob, e.g. \"src/**\" (repeatable)")
+	mcpCmd.Flags().StringVar(&mcpProfile, "profile", "", "Serve the repositories, tags and file sizes of this entry of mcp_profiles")
+
+	catCmd.Flags().StringVar(&catRepo, "repo", "", "Only look in the repository with this name or ID")
+	catCmd.Flags().BoolVar(&catRender, "render", false, "Render the markdown for the terminal")
+
+	listCmd.Flags().StringVar(&listRepo, "repo", "", "Only list the repository with this name or ID")
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "Print the list as JSON")
+
+	noteCmd.Flags().StringVar(&noteRepo, "repo", "", "Only look in the repository with this name or ID")
+	noteCmd.Flags().IntVar(&noteRating, "rating", 0, "Rate the rule from 1 to 5 stars; 0 removes the rating")
+	noteCmd.Flags().BoolVar(&noteClear, "clear", false, "Remove the rule's note and rating")
+
+	grepCmd.Flags().BoolVarP(&grepIgnoreCase, "ignore-case", "i", false, "Match case-insensitively")
+	grepCmd.Flags().IntVarP(&grepBefore, "before-context", "B", 0, "Print this many lines before each match")
+	grepCmd.Flags().IntVarP(&grepAfter, "after-context", "A", 0, "Print this many lines after each match")
+	grepCmd.Flags().IntVarP(&grepContext, "context", "C", 0, "Print this many lines before and after each match")
+	grepCmd.Flags().BoolVarP(&grepFilesMatches, "files-with-matches", "l", false, "Only print the paths of files with matches")
+
+	diffCmd.Flags().StringVar(&diffRef, "ref", "", "Compare against this branch, tag or commit of the central repository")
+
+	statusCmd.Flags().BoolVar(&statusSync, "sync", false, "Deploy outdated and missing rules again")
+	statusCmd.Flags().BoolVar(&statusForce, "force", false, "With --sync, also replace rules edited in the project")
+
+	verifyCmd.Flags().BoolVar(&verifyCI, "ci", false, "Print problems as GitHub Actions annotations")
+	verifyCmd.Flags().StringVar(&verifyRef, "ref", "", "Compare against this branch, tag or commit of the central repository")
+
+	lintCmd.Flags().StringVar(&lintRepo, "repo", "", "Only lint the repository with this name or ID")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format: text or json")
+	lintCmd.Flags().IntVar(&lintMaxLines, "max-lines", lint.DefaultMaxLines, "Report rules with more lines than this")
+
+	inventoryCmd.Flags().StringVar(&inventoryFormat, "format", "json", "Output format: json or cyclonedx")
+	inventoryCmd.Flags().StringVarP(&inventoryOutput, "output", "o", "", "Write the inventory to this file instead of stdout")
+
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "rules.zip", "Write the bundle to this file")
+	exportCmd.Flags().StringVar(&exportRepo, "repo", "", "Only export rules from the repository with this name or ID")
+	exportCmd.Flags().BoolVar(&exportAll, "all", false, "Export every rule")
+
+	summaryCmd.Flags().IntVar(&summaryDays, "days", 7, "Summarize this many days")
+	summaryCmd.Flags().StringVar(&summaryRepo, "repo", "", "Only summarize the repository with this name or ID")
+	summaryCmd.Flags().BoolVar(&summaryWrite, "write", false, "Also save the summary to summaries/<date>.md in the repository")
+
+	statsCmd.Flags().IntVar(&statsDays, "days", int(summary.DefaultStatsPeriod/(24*time.Hour)), "Count the rules served in this many days")
+	statsCmd.Flags().StringVar(&statsRepo, "repo", "", "Only show the rules of the repository with this name or ID")
+	statsCmd.Flags().IntVar(&statsLimit, "limit", 10, "Show this many of the most and of the least used rules")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the usage of every rule as JSON")
+
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "Destination directory (defaults to the first local rule repository)")
+	migrateCmd.Flags().BoolVar(&migrateOverwrite, "overwrite", false, "Replace rules that already exist in the destination")
+	migrateCmd.Flags().StringVar(&migrateOnConflict, "on-conflict", "", "What to do with rules that already exist: ask (skip them), rename or overwrite (defaults to save_collision)")
+	migrateCmd.MarkFlagsMutuallyExclusive("overwrite", "on-conflict")
+
+	saveCmd.Flags().StringVar(&saveName, "name", "", "Save the rule under this file name instead of the source's")
+	saveCmd.Flags().StringVar(&saveRepo, "repo", "", "Save to the repository with this name or ID (defaults to the project's .rulem.yaml, then the first local rule repository)")
+	saveCmd.Flags().BoolVar(&saveOverwrite, "overwrite", false, "Replace a rule with the same name in the repository")
+	saveCmd.Flags().StringVar(&saveOnConflict, "on-conflict", "", "What to do when the rule already exists: ask (fail), rename or overwrite (defaults to save_collision)")
+	saveCmd.MarkFlagsMutuallyExclusive("overwrite", "on-conflict")
+	saveCmd.Flags().BoolVar(&saveJSON, "json", false, "Print the result or error as JSON")
+
+	deployCmd.Flags().StringVar(&deployTarget, "target", "", "Deploy for this editor: agents, copilot, copilot-instructions, cursor, claude, gemini, windsurf or a configured target (defaults to agents)")
+	deployCmd.Flags().StringVar(&deployAs, "as", "", "Deploy to this path, or into this directory when it ends in / (defaults to the target's path)")
+	deployCmd.Flags().StringVar(&deployRepo, "repo", "", "Only look in the repository with this name or ID")
+	deployCmd.Flags().BoolVar(&deployLink, "link", false, "Create symlinks to the rules instead of copies")
+	deployCmd.Flags().BoolVar(&deployOverwrite, "overwrite", false, "Replace files that already exist in the project")
+
+	syncCmd.Flags().StringVar(&syncRepo, "repo", "", "Only sync the repository with this name or ID")
+	syncCmd.Flags().BoolVar(&syncAll, "all", false, "Sync every GitHub repository")
+	syncCmd.MarkFlagsMutuallyExclusive("repo", "all")
+	syncCmd.MarkFlagsOneRequired("repo", "all")
 
 	// Hide the help command and completion command in the main help output
 	rootCmd.SetHelpCommand(&cobra.Command{
@@ -161,10 +1036,88 @@ func initLogger() {
 	}
 }
 
+// prepareRun runs before every command: it turns on the scan cache unless
+// --no-scan-cache is set, applies the file settings of config.yaml, and handles
+// --token-once
+func prepareRun(cmd *cobra.Command, args []string) error {
+	if !noScanCache {
+		filemanager.UseScanCache(filemanager.LoadScanCache(filemanager.ScanCachePath()))
+	}
+	useFileSettings()
+	return promptTokenOnce(cmd, args)
+}
+
+// useFileSettings makes scans leave out the scan_excludes and look for the
+// rule_extensions of config.yaml, when it sets them, and turns the trash off
+// when it sets permanent_delete. Commands load the config again for
+// everything else, and report a config that cannot be read, or invalid
+// rule_extensions, themselves.
+func useFileSettings() {
+	path, exists := config.FindConfigFile()
+	if !exists {
+		return
+	}
+	cfg, err := config.LoadFrom(path)
+	if err != nil {
+		return
+	}
+	if cfg.ScanExcludes != nil {
+		filemanager.UseScanExcludes(cfg.ScanExcludes)
+	}
+	if extensions, err := cfg.RuleFileExtensions(); err == nil && extensions != nil {
+		filemanager.UseRuleExtensions(extensions)
+	}
+	if cfg.PermanentDelete {
+		filemanager.UseTrash("")
+	}
+}
+
+// promptTokenOnce handles --token-once: it reads a GitHub token from the
+// terminal without echoing it and hands it to repository.UseTokenOnce, for
+// users who do not want a long-lived PAT stored on the machine
+func promptTokenOnce(cmd *cobra.Command, args []string) error {
+	if !tokenOnce {
+		return nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("--token-once needs an interactive terminal to prompt for the token")
+	}
+
+	fmt.Fprint(os.Stderr, "GitHub token (used once, not saved): ")
+	token, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return fmt.Errorf("failed to read token: %w", err)
+	}
+
+	if err := repository.NewCredentialManager().ValidateGitHubToken(strings.TrimSpace(string(token))); err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+	repository.UseTokenOnce(string(token))
+	return nil
+}
+
+// exitError makes main exit with a specific status instead of 1, for commands
+// whose exit status is part of their interface
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		code := 1
+		var exit *exitError
+		if errors.As(err, &exit) {
+			code = exit.code
+		}
+		os.Exit(code)
 	}
 }
 
@@ -173,8 +1126,24 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	// Initialize logger based on debug flag
 	initLogger()
 
+	action, err := startupAction()
+	if err != nil {
+		return err
+	}
+	switch action {
+	case config.StartupSync:
+		return runSync(cmd, args)
+	case config.StartupMCP:
+		return runMCPServer(cmd, args)
+	}
+
+	if plainUI || !interactiveTerminal() {
+		return runPlainUI(cmd)
+	}
+
 	// Check if first run and handle setup
-	if config.IsFirstRun() {
+	firstRun := config.IsFirstRun()
+	if firstRun {
 		appLogger.Debug("First run detected, starting setup")
 		if err := runFirstTimeSetup(appLogger); err != nil {
 			return fmt.Errorf("setup failed: %w", err)
@@ -190,10 +1159,30 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	if cfg == nil {
 		return fmt.Errorf("configuration is nil after loading")
 	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
 	appLogger.Info("Configuration loaded successfully", "init_time", cfg.InitTime)
 
+	// Keep GitHub repositories fresh while the TUI is open
+	syncCtx, stopSync := context.WithCancel(context.Background())
+	defer stopSync()
+	scheduler, err := startAutoSync(syncCtx, cfg)
+	if err != nil {
+		return err
+	}
+
 	// Initialize TUI application with panic recovery
 	model := tui.NewMainModel(cfg, appLogger)
+	model.SetScheduler(scheduler)
+	startOnboarding(model, firstRun)
+	if action == config.StartupDashboard {
+		model.OpenAtStart(tui.StateSummary)
+	}
+	model.SetDiagnosticsDir(diagnostics.Dir())
 	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithoutCatchPanics())
 
 	appLogger.Debug("Starting TUI program")
@@ -203,20 +1192,94 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	}, appLogger, "TUI program")
 }
 
-// runFirstTimeSetup handles the initial application setup for new users.
-//
-// This function is called automatically when the application detects that
-// it's being run for the first time (no configuration file exists).
-// It launches an interactive setup wizard using Bubble Tea that guides
-// the user through:
-//
-// - Choosing a storage directory for rule files
-// - Setting up initial configuration
-// - Creating necessary directories and permissions
-//
-// The setup process runs in full-screen mode and provides a user-friendly
-// interface for configuration. If the user cancels the setup, the function
-// returns an error to prevent the application from continuing without config.
+// startupAction returns what rulem does on launch: --startup when given,
+// otherwise the startup_action of config.yaml. Until setup is complete it is
+// always the menu, so the first run shows the setup.
+func startupAction() (string, error) {
+	action, err := config.ParseStartupAction(startup)
+	if err != nil {
+		return "", fmt.Errorf("invalid --startup: %w", err)
+	}
+	if config.IsFirstRun() {
+		return config.StartupMenu, nil
+	}
+	if startup != "" {
+		return action, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("error loading config: %w", err)
+	}
+	return cfg.LaunchAction()
+}
+
+// interactiveTerminal reports whether stdin and stdout are a terminal the TUI
+// can be displayed on
+func interactiveTerminal() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// runPlainUI runs the numbered prompts of the plain text interface in place
+// of the TUI, running the setup first on the first run
+func runPlainUI(cmd *cobra.Command) error {
+	appLogger.Debug("Using the plain text interface", "requested", plainUI)
+	prompter := plainmenu.NewPrompter(cmd.InOrStdin(), cmd.OutOrStdout())
+
+	if config.IsFirstRun() {
+		if err := plainmenu.RunSetup(prompter, helpers.NewUIContext(0, 0, nil, appLogger)); err != nil {
+			return fmt.Errorf("setup failed: %w", err)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+	return plainmenu.Run(prompter, cfg, appLogger)
+}
+
+// startOnboarding enables the TUI's tips and starts its tour right after the
+// first-run setup, or when --tour asks for it. Onboarding is skipped, with a
+// warning, when its progress cannot be read.
+func startOnboarding(model *tui.MainModel, firstRun bool) {
+	store, err := onboarding.Load(onboarding.Path())
+	if err != nil {
+		appLogger.Warn("Onboarding tips are unavailable", "error", err)
+		return
+	}
+	if showTour {
+		store.Reset()
+	}
+	model.SetOnboarding(store)
+	if showTour || (firstRun && !store.TourCompleted) {
+		model.StartTour()
+	}
+}
+
+// runFirstTimeSetup handles the initial application setup for new users.
+//
+// This function is called automatically when the application detects that
+// it's being run for the first time (no configuration file exists).
+// It launches an interactive setup wizard using Bubble Tea that guides
+// the user through:
+//
+// - Choosing a storage directory for rule files
+// - Setting up initial configuration
+// - Creating necessary directories and permissions
+//
+// The setup process runs in full-screen mode and provides a user-friendly
+// interface for configuration. If the user cancels the setup, the function
+// returns an error to prevent the application from continuing without config.
 //
 // Parameters:
 //   - logger: Logger instance for recording setup progress and errors
@@ -294,6 +1357,24 @@ func runWithRecovery(fn func() error, logger *logging.AppLogger, operation strin
 	return fn()
 }
 
+// runMCPSelfCheck prints the problems clients may have with the tools the
+// server would serve, failing when there are any
+func runMCPSelfCheck(cmd *cobra.Command, server *mcp.Server) error {
+	violations, err := server.SelfCheck()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(violations) == 0 {
+		fmt.Fprintln(out, "All tools comply with the MCP specification and the configured clients")
+		return nil
+	}
+	for _, v := range violations {
+		fmt.Fprintln(out, v)
+	}
+	return &exitError{code: 1, err: fmt.Errorf("%d problem(s) found in the served tools", len(violations))}
+}
+
 // runMCPServer handles the MCP server execution
 func runMCPServer(cmd *cobra.Command, args []string) error {
 	// Initialize logger based on debug flag
@@ -307,6 +1388,13 @@ func runMCPServer(cmd *cobra.Command, args []string) error {
 	if cfg == nil {
 		return fmt.Errorf("configuration is nil after loading")
 	}
+	report, err := applyPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
 
 	// Create and start MCP server
 	appLogger.Info("Starting MCP server")
@@ -314,6 +1402,61 @@ func runMCPServer(cmd *cobra.Command, args []string) error {
 	if server == nil {
 		return fmt.Errorf("failed to initialize MCP server")
 	}
+	if report.ProposalsDisabled {
+		server.DisableProposals()
+	}
+	if err := applyMCPProfile(cmd, cfg, server); err != nil {
+		return err
+	}
+	if mcpSocketPath != "" {
+		server.EnableSocket(fileops.ExpandPath(mcpSocketPath))
+	} else if mcpSocket {
+		server.EnableSocket(mcp.DefaultSocketPath())
+	}
+	if mcpUsageLog {
+		server.EnableUsageLog(mcp.NewUsageLog(mcp.UsagePath()))
+	}
+	if err := configureAuditLog(cmd, server); err != nil {
+		return err
+	}
+	if mcpWatch {
+		server.EnableWatch()
+	}
+	if err := pinServedRevisions(cfg, server, mcpAt); err != nil {
+		return err
+	}
+	if mcpMaxCalls < 0 || mcpMaxBytes < 0 {
+		return fmt.Errorf("--max-calls-per-minute and --max-session-bytes cannot be negative")
+	}
+	server.EnableSessionLimits(mcp.SessionLimits{CallsPerMinute: mcpMaxCalls, MaxBytes: mcpMaxBytes})
+	// The project's .rulem.yaml chooses the tags when neither --tags nor a profile do
+	if len(mcpTags) == 0 && !cmd.Flags().Changed("tags") {
+		mcpTags = cfg.ProjectTags()
+	}
+	filter, err := mcp.NewToolFilter(mcpTags, mcpScope)
+	if err != nil {
+		return err
+	}
+	server.EnableToolFilter(filter)
+	if err := configureTransport(cmd, server); err != nil {
+		return err
+	}
+	if mcpSelfCheck {
+		return runMCPSelfCheck(cmd, server)
+	}
+
+	// Keep GitHub repositories fresh while serving; the watcher picks up the
+	// rules each sync brings in, and a synced commit that fails its pin or
+	// signature check stops the repository from being served
+	syncCtx, stopSync := context.WithCancel(context.Background())
+	defer stopSync()
+	scheduler, err := startAutoSync(syncCtx, cfg)
+	if err != nil {
+		return err
+	}
+	if scheduler != nil {
+		scheduler.OnRun(func([]repository.RepositorySyncResult) { server.VerifyRepositories() })
+	}
 
 	appLogger.Debug("MCP server initialized, starting communication loop")
 
@@ -351,3 +1494,1724 @@ func runMCPServer(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// applyMCPProfile applies --profile: cfg keeps only the repositories of the
+// profile, whose tags and scope are served unless --tags or --scope are given
+func applyMCPProfile(cmd *cobra.Command, cfg *config.Config, server *mcp.Server) error {
+	if mcpProfile == "" {
+		return nil
+	}
+	profile, repositories, err := cfg.ResolveMCPProfile(mcpProfile)
+	if err != nil {
+		return err
+	}
+	cfg.Repositories = repositories
+	if !cmd.Flags().Changed("tags") {
+		mcpTags = profile.Tags
+	}
+	if !cmd.Flags().Changed("scope") {
+		mcpScope = profile.Scope
+	}
+	if profile.MaxFileSize > 0 {
+		server.EnableMaxFileSize(profile.MaxFileSize)
+	}
+	appLogger.Info("Serving MCP profile", "profile", mcpProfile, "repositories", len(repositories))
+	return nil
+}
+
+// configureTransport applies the --transport, --listen and --auth-token flags.
+// The auth token falls back to $RULEM_MCP_TOKEN.
+func configureTransport(cmd *cobra.Command, server *mcp.Server) error {
+	transport, err := mcp.ParseTransport(mcpTransport)
+	if err != nil {
+		return err
+	}
+	if transport == mcp.TransportStdio {
+		if cmd.Flags().Changed("listen") || cmd.Flags().Changed("auth-token") {
+			return fmt.Errorf("--listen and --auth-token need --transport %s or %s", mcp.TransportHTTP, mcp.TransportSSE)
+		}
+		return nil
+	}
+
+	token := mcpAuthToken
+	if token == "" {
+		token = os.Getenv(mcp.AuthTokenEnv)
+	}
+	server.EnableHTTP(transport, mcp.HTTPOptions{Address: mcpListen, AuthToken: token})
+	return nil
+}
+
+// startAutoSync starts the background sync configured by sync_interval until
+// ctx is done. It returns nil when the background sync is disabled.
+func startAutoSync(ctx context.Context, cfg *config.Config) (*repository.Scheduler, error) {
+	interval, err := cfg.AutoSyncInterval()
+	if err != nil || interval == 0 {
+		return nil, err
+	}
+	scheduler, err := repository.NewScheduler(interval, cfg.Repositories, appLogger)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.Start(ctx)
+	return scheduler, nil
+}
+
+// configureAuditLog applies the --audit-log and --audit-format flags
+func configureAuditLog(cmd *cobra.Command, server *mcp.Server) error {
+	format, err := mcp.ParseAuditFormat(mcpAuditFmt)
+	if err != nil {
+		return err
+	}
+	if mcpAuditLog == "" {
+		if cmd.Flags().Changed("audit-format") {
+			return fmt.Errorf("--audit-format needs --audit-log")
+		}
+		return nil
+	}
+	server.EnableAuditLog(mcp.NewAuditLog(fileops.ExpandPath(mcpAuditLog), format))
+	return nil
+}
+
+// pinServedRevisions applies the --at flags: "<rev>" serves every GitHub
+// repository at rev, "<repo>=<rev>" serves one repository, by name or ID, at rev
+func pinServedRevisions(cfg *config.Config, server *mcp.Server, at []string) error {
+	for _, value := range at {
+		name, revision, ok := strings.Cut(value, "=")
+		if !ok {
+			for _, repo := range cfg.Repositories {
+				if repo.IsRemote() {
+					server.ServeAt(repo.ID, value)
+				}
+			}
+			continue
+		}
+
+		if name == "" || revision == "" {
+			return fmt.Errorf("invalid --at %q: want <rev> or <repo>=<rev>", value)
+		}
+		repo, err := findRepository(cfg, name)
+		if err != nil {
+			return fmt.Errorf("invalid --at %q: %w", value, err)
+		}
+		server.ServeAt(repo.ID, revision)
+	}
+	return nil
+}
+
+// runLSPServer prepares the repositories and serves the language server on stdin/stdout
+func runLSPServer(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, appLogger)
+	if err != nil {
+		return fmt.Errorf("failed to prepare repositories: %w", err)
+	}
+
+	appLogger.Info("Starting LSP server")
+	server := lsp.NewServer(cfg, prepared, appLogger)
+	return runWithRecovery(func() error {
+		return server.Serve(os.Stdin, os.Stdout)
+	}, appLogger, "LSP server")
+}
+
+// runCat resolves a rule by name and prints its content to stdout
+func runCat(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	var repositoryID string
+	if catRepo != "" {
+		repo, err := findRepository(cfg, catRepo)
+		if err != nil {
+			return err
+		}
+		repositoryID = repo.ID
+	}
+
+	_, tools, err := mcp.PrepareAndLoadRuleTools(context.Background(), cfg, appLogger)
+	if err != nil {
+		return err
+	}
+
+	tool, err := mcp.ResolveRule(tools, args[0], repositoryID)
+	if err != nil {
+		return err
+	}
+
+	content := tool.RuleFile.Content
+	if catRender {
+		if content, err = renderMarkdown(content); err != nil {
+			return err
+		}
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	_, err = fmt.Fprint(cmd.OutOrStdout(), content)
+	return err
+}
+
+// listedRule is a rule file printed by rulem list
+type listedRule struct {
+	Repository   string   `json:"repository"`
+	RepositoryID string   `json:"repositoryId"`
+	Path         string   `json:"path"`           // Slash-separated path in the repository
+	Tool         string   `json:"tool,omitempty"` // MCP tool name, empty when the rule is not served
+	Description  string   `json:"description,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Size         int64    `json:"size"`
+}
+
+// runList prints the rule files of the configured repositories
+func runList(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	var repositoryID string
+	if listRepo != "" {
+		repo, err := findRepository(cfg, listRepo)
+		if err != nil {
+			return err
+		}
+		repositoryID = repo.ID
+	}
+
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, appLogger)
+	if err != nil {
+		return fmt.Errorf("failed to prepare repositories: %w", err)
+	}
+	rules, err := listRules(cfg, prepared, repositoryID)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if listJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if rules == nil {
+			rules = []listedRule{}
+		}
+		return encoder.Encode(rules)
+	}
+
+	if len(rules) == 0 {
+		fmt.Fprintln(out, "No rule files found")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	current := ""
+	for _, rule := range rules {
+		if rule.RepositoryID != current {
+			if current != "" {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "%s\n", rule.Repository)
+			current = rule.RepositoryID
+		}
+		tool := rule.Tool
+		if tool == "" {
+			tool = "-"
+		}
+		description := rule.Description
+		if len(rule.Tags) > 0 {
+			description += " [" + strings.Join(rule.Tags, ", ") + "]"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", rule.Path, humanSize(rule.Size), tool, strings.TrimSpace(description))
+	}
+	return w.Flush()
+}
+
+// listRules scans the available repositories, or only the one with
+// repositoryID when it is set, and describes their rule files in scan order
+func listRules(cfg *config.Config, prepared []repository.PreparedRepository, repositoryID string) ([]listedRule, error) {
+	available := repository.AvailableRepositories(prepared)
+	tools, err := mcp.LoadRuleTools(cfg, prepared, appLogger)
+	if err != nil {
+		return nil, err
+	}
+	toolNames := make(map[string]string, len(tools))
+	for name, tool := range tools {
+		toolNames[tool.RuleFile.FilePath] = name
+	}
+	processor, err := mcp.NewRuleFileProcessorForRepositories(cfg, prepared, appLogger)
+	if err != nil {
+		return nil, err
+	}
+	files, err := filemanager.ScanAllRepositories(available, appLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan repositories: %w", err)
+	}
+
+	roots := make(map[string]repository.PreparedRepository, len(available))
+	for _, prep := range available {
+		roots[prep.ID()] = prep
+	}
+
+	var rules []listedRule
+	for _, file := range files {
+		if repositoryID != "" && file.RepositoryID != repositoryID {
+			continue
+		}
+		info, err := os.Stat(file.Path)
+		if err != nil {
+			appLogger.Debug("Skipping unreadable rule file", "path", file.Path, "error", err)
+			continue
+		}
+		rule := listedRule{
+			Repository:   file.RepositoryName,
+			RepositoryID: file.RepositoryID,
+			Path:         repositoryPath(roots[file.RepositoryID], file),
+			Tool:         toolNames[file.Path],
+			Size:         info.Size(),
+		}
+		if content, err := os.ReadFile(file.Path); err == nil {
+			if matter, err := processor.Frontmatter(content, file.Name); err == nil {
+				rule.Description = matter.Description
+				rule.Tags = matter.Tags
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// repositoryPath returns the path of a scanned rule file relative to the root
+// of its repository, or its scanned name when it lies outside it
+func repositoryPath(prep repository.PreparedRepository, file filemanager.FileItem) string {
+	for _, root := range []string{prep.OverlayPath, prep.LocalPath} {
+		if rel, err := filepath.Rel(root, file.Path); root != "" && err == nil && filepath.IsLocal(rel) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(file.Name)
+}
+
+// humanSize renders a byte count for rulem list
+func humanSize(n int64) string {
+	const kib = 1024
+	if n < kib {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f KB", float64(n)/float64(kib))
+}
+
+// runNote prints, records or clears the user's note on a rule
+func runNote(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	ratingSet := cmd.Flags().Changed("rating")
+	if noteClear && (ratingSet || len(args) > 1) {
+		return fmt.Errorf("--clear cannot be combined with a note or --rating")
+	}
+	if noteRating < 0 || noteRating > notes.MaxRating {
+		return fmt.Errorf("--rating must be between 0 and %d", notes.MaxRating)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	var repositoryID string
+	if noteRepo != "" {
+		repo, err := findRepository(cfg, noteRepo)
+		if err != nil {
+			return err
+		}
+		repositoryID = repo.ID
+	}
+
+	_, tools, err := mcp.PrepareAndLoadRuleTools(context.Background(), cfg, appLogger)
+	if err != nil {
+		return err
+	}
+	tool, err := mcp.ResolveRule(tools, args[0], repositoryID)
+	if err != nil {
+		return err
+	}
+
+	store, err := notes.Load(notes.Path())
+	if err != nil {
+		return err
+	}
+	key := notes.Key(cfg.Repositories, tool.RuleFile.RepositoryID, tool.RuleFile.FilePath)
+	note, _ := store.Get(key)
+	out := cmd.OutOrStdout()
+
+	if !noteClear && !ratingSet && len(args) == 1 {
+		if note.IsEmpty() {
+			fmt.Fprintf(out, "No note on %s\n", tool.Name)
+			return nil
+		}
+		if note.Rating > 0 {
+			fmt.Fprintf(out, "%s (%d/%d)\n", notes.Stars(note.Rating), note.Rating, notes.MaxRating)
+		}
+		if note.Text != "" {
+			fmt.Fprintln(out, note.Text)
+		}
+		return nil
+	}
+
+	switch {
+	case noteClear:
+		note = notes.Note{}
+	default:
+		if ratingSet {
+			note.Rating = noteRating
+		}
+		if len(args) > 1 {
+			note.Text = strings.TrimSpace(args[1])
+		}
+	}
+	note.UpdatedAt = time.Now().Unix()
+	if err := store.Set(key, note); err != nil {
+		return err
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	if note.IsEmpty() {
+		fmt.Fprintf(out, "Removed the note on %s\n", tool.Name)
+	} else {
+		fmt.Fprintf(out, "Saved the note on %s\n", tool.Name)
+	}
+	return nil
+}
+
+// findRepository looks a repository up by ID, then by name
+func findRepository(cfg *config.Config, nameOrID string) (*repository.RepositoryEntry, error) {
+	if repo, err := cfg.FindRepositoryByID(nameOrID); err == nil {
+		return repo, nil
+	}
+	return cfg.FindRepositoryByName(nameOrID)
+}
+
+// renderMarkdown renders markdown for the terminal, wrapped to its width
+func renderMarkdown(content string) (string, error) {
+	width := 80
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		width = w
+	}
+
+	renderer, err := glamour.NewTermRenderer(glamour.WithAutoStyle(), glamour.WithWordWrap(width))
+	if err != nil {
+		return "", fmt.Errorf("failed to create markdown renderer: %w", err)
+	}
+	rendered, err := renderer.Render(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to render markdown: %w", err)
+	}
+	return rendered, nil
+}
+
+// runGrep searches every repository for a regular expression
+func runGrep(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	expr := args[0]
+	if grepIgnoreCase {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	opts := search.Options{Before: grepContext, After: grepContext}
+	if cmd.Flags().Changed("before-context") {
+		opts.Before = grepBefore
+	}
+	if cmd.Flags().Changed("after-context") {
+		opts.After = grepAfter
+	}
+	if opts.Before < 0 || opts.After < 0 {
+		return fmt.Errorf("context line counts cannot be negative")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, appLogger)
+	if err != nil {
+		return fmt.Errorf("failed to prepare repositories: %w", err)
+	}
+
+	results := search.Grep(search.Files(prepared, appLogger), pattern, opts, appLogger)
+	if len(results) == 0 {
+		return fmt.Errorf("no matches for %q", args[0])
+	}
+
+	out := cmd.OutOrStdout()
+	if grepFilesMatches {
+		for _, result := range results {
+			fmt.Fprintln(out, result.Path)
+		}
+		return nil
+	}
+	return search.Print(out, results, opts)
+}
+
+// runDiff prints unified diffs between the rules deployed in the current
+// directory and their central versions. Drift exits with status 1 and any
+// other failure with status 2, like diff(1).
+func runDiff(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	drifted, err := diffProject(cmd)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	if drifted > 0 {
+		return &exitError{code: 1, err: fmt.Errorf("%d deployed rule(s) differ from their central versions", drifted)}
+	}
+	return nil
+}
+
+// diffProject compares the deployed rules and returns how many drifted
+func diffProject(cmd *cobra.Command) (int, error) {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	manifest, err := project.LoadManifest(projectDir)
+	if err != nil {
+		return 0, err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return 0, fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return 0, fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return 0, err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return 0, err
+	}
+
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, appLogger)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare repositories: %w", err)
+	}
+
+	drifted, failed := 0, 0
+	for _, c := range project.Compare(projectDir, manifest, prepared, diffRef) {
+		switch {
+		case c.Err != nil:
+			failed++
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s: %v\n", c.Entry.Path, c.Err)
+		case c.Drifted():
+			drifted++
+			fmt.Fprint(cmd.OutOrStdout(), c.Diff())
+		}
+	}
+	if failed > 0 {
+		return drifted, fmt.Errorf("%d deployed rule(s) could not be compared", failed)
+	}
+	if drifted == 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "All %d deployed rule(s) are up to date\n", len(manifest.Rules))
+	}
+	return drifted, nil
+}
+
+// runStatus lists how the deployed rules compare with their central versions
+// and, with --sync, deploys drifted ones again
+func runStatus(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return &exitError{code: 2, err: fmt.Errorf("failed to get current directory: %w", err)}
+	}
+	manifest, err := project.LoadManifest(projectDir)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return &exitError{code: 2, err: fmt.Errorf("error loading config: %w", err)}
+	}
+	if cfg == nil {
+		return &exitError{code: 2, err: fmt.Errorf("configuration is nil after loading")}
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, appLogger)
+	if err != nil {
+		return &exitError{code: 2, err: fmt.Errorf("failed to prepare repositories: %w", err)}
+	}
+
+	comparisons := project.Compare(projectDir, manifest, prepared, "")
+	if statusSync {
+		var resync []project.Comparison
+		for _, c := range comparisons {
+			if c.CanResync() && (statusForce || !c.LocallyModified()) {
+				resync = append(resync, c)
+			}
+		}
+		if len(resync) > 0 {
+			paths, err := deploy.Resync(context.Background(), resync, prepared, cfg.Hooks, appLogger)
+			if err != nil {
+				return &exitError{code: 2, err: fmt.Errorf("re-sync failed: %w", err)}
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Re-synced %d rule(s)\n", len(paths))
+			if manifest, err = project.LoadManifest(projectDir); err != nil {
+				return &exitError{code: 2, err: err}
+			}
+			comparisons = project.Compare(projectDir, manifest, prepared, "")
+		}
+	}
+
+	drifted, failed := 0, 0
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, c := range comparisons {
+		detail := c.CentralName
+		switch {
+		case c.Err != nil:
+			failed++
+			detail = c.Err.Error()
+		case c.Drifted():
+			drifted++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Label(), c.Entry.Path, detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	switch {
+	case failed > 0:
+		return &exitError{code: 2, err: fmt.Errorf("%d deployed rule(s) could not be compared", failed)}
+	case drifted > 0:
+		hint := "run rulem status --sync to update them"
+		if statusSync {
+			hint = "rules edited in the project need --force, and rules whose source is missing cannot be re-synced"
+		}
+		return &exitError{code: 1, err: fmt.Errorf("%d deployed rule(s) drifted from their central versions; %s", drifted, hint)}
+	}
+	return nil
+}
+
+// runVerify checks the deployed rules for drift and invalid frontmatter.
+// Problems exit with status 1 and rules that could not be verified with 2.
+func runVerify(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	findings, err := verifyProject()
+	if err != nil {
+		if verifyCI {
+			fmt.Fprintln(cmd.OutOrStdout(), project.Finding{Kind: project.FindingError, Message: err.Error()}.Annotation())
+		}
+		return &exitError{code: 2, err: err}
+	}
+
+	out := cmd.OutOrStdout()
+	code := 0
+	for _, finding := range findings {
+		if verifyCI {
+			fmt.Fprintln(out, finding.Annotation())
+		} else {
+			fmt.Fprintln(out, finding.String())
+		}
+		if finding.Kind == project.FindingError {
+			code = 2
+		} else if code == 0 {
+			code = 1
+		}
+	}
+	if code != 0 {
+		return &exitError{code: code, err: fmt.Errorf("%d problem(s) found in deployed rules", len(findings))}
+	}
+
+	fmt.Fprintln(out, "All deployed rules are up to date and valid")
+	return nil
+}
+
+// verifyProject loads the project manifest and verifies the deployed rules
+func verifyProject() ([]project.Finding, error) {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	manifest, err := project.LoadManifest(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return nil, err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return nil, err
+	}
+
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, appLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare repositories: %w", err)
+	}
+	processor, err := mcp.NewRuleFileProcessorForRepositories(cfg, prepared, appLogger)
+	if err != nil {
+		return nil, err
+	}
+
+	return project.Verify(projectDir, manifest, prepared, processor, verifyRef), nil
+}
+
+// runLint checks the rule files of the configured repositories. Problems exit
+// with status 1 and rules that could not be read with 2.
+func runLint(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	if lintFormat != "text" && lintFormat != "json" {
+		return fmt.Errorf("unknown format %q: use text or json", lintFormat)
+	}
+
+	findings, linted, err := lintRepositories()
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+
+	out := cmd.OutOrStdout()
+	if lintFormat == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if findings == nil {
+			findings = []lint.Finding{}
+		}
+		if err := encoder.Encode(findings); err != nil {
+			return &exitError{code: 2, err: err}
+		}
+	} else {
+		current := ""
+		for _, finding := range findings {
+			if finding.Repository != current {
+				if current != "" {
+					fmt.Fprintln(out)
+				}
+				fmt.Fprintln(out, finding.Repository)
+				current = finding.Repository
+			}
+			fmt.Fprintf(out, "  %s\n", finding)
+		}
+	}
+
+	if len(findings) > 0 {
+		return &exitError{code: 1, err: fmt.Errorf("%d problem(s) found in %d rule file(s)", len(findings), linted)}
+	}
+	if lintFormat == "text" {
+		fmt.Fprintf(out, "All %d rule file(s) passed\n", linted)
+	}
+	return nil
+}
+
+// lintRepositories reads the rule files of the configured repositories, or of
+// the one named by --repo, and lints them. It also returns how many were linted.
+func lintRepositories() ([]lint.Finding, int, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, 0, fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return nil, 0, fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return nil, 0, err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return nil, 0, err
+	}
+
+	var repositoryID string
+	if lintRepo != "" {
+		repo, err := findRepository(cfg, lintRepo)
+		if err != nil {
+			return nil, 0, err
+		}
+		repositoryID = repo.ID
+	}
+
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, appLogger)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to prepare repositories: %w", err)
+	}
+	processor, err := mcp.NewRuleFileProcessorForRepositories(cfg, prepared, appLogger)
+	if err != nil {
+		return nil, 0, err
+	}
+	available := repository.AvailableRepositories(prepared)
+	files, err := filemanager.ScanAllRepositories(available, appLogger)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to scan repositories: %w", err)
+	}
+
+	roots := make(map[string]repository.PreparedRepository, len(available))
+	for _, prep := range available {
+		roots[prep.ID()] = prep
+	}
+	var rules []lint.Rule
+	for _, file := range files {
+		if repositoryID != "" && file.RepositoryID != repositoryID {
+			continue
+		}
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		rules = append(rules, lint.Rule{
+			Repository:   file.RepositoryName,
+			RepositoryID: file.RepositoryID,
+			Path:         repositoryPath(roots[file.RepositoryID], file),
+			Content:      content,
+		})
+	}
+
+	return lint.Lint(rules, processor, lint.Options{MaxLines: lintMaxLines}), len(rules), nil
+}
+
+// runInventory prints the inventory of the rules deployed in the current directory
+func runInventory(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	if inventoryFormat != "json" && inventoryFormat != "cyclonedx" {
+		return fmt.Errorf("unknown format %q: use json or cyclonedx", inventoryFormat)
+	}
+
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	manifest, err := project.LoadManifest(projectDir)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	// Only frontmatter is parsed, so no repository needs preparing
+	processor, err := mcp.NewRuleFileProcessorForRepositories(cfg, nil, appLogger)
+	if err != nil {
+		return err
+	}
+
+	inventory, err := project.BuildInventory(projectDir, manifest, cfg.Repositories, processor)
+	if err != nil {
+		return err
+	}
+
+	write := inventory.WriteJSON
+	if inventoryFormat == "cyclonedx" {
+		write = inventory.WriteCycloneDX
+	}
+	if inventoryOutput == "" {
+		return write(cmd.OutOrStdout())
+	}
+
+	f, err := os.Create(inventoryOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", inventoryOutput, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", inventoryOutput, err)
+	}
+	return f.Close()
+}
+
+// runExport writes the selected rules to an anonymized bundle
+func runExport(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	switch {
+	case exportAll && len(args) > 0:
+		return fmt.Errorf("name the rules to export or use --all, not both")
+	case !exportAll && len(args) == 0:
+		return fmt.Errorf("name the rules to export, or use --all to export every rule")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	redactor, err := share.NewRedactor(cfg.Redactions)
+	if err != nil {
+		return fmt.Errorf("invalid redactions in config: %w", err)
+	}
+
+	var repositoryID string
+	if exportRepo != "" {
+		repo, err := findRepository(cfg, exportRepo)
+		if err != nil {
+			return err
+		}
+		repositoryID = repo.ID
+	}
+
+	prepared, tools, err := mcp.PrepareAndLoadRuleTools(context.Background(), cfg, appLogger)
+	if err != nil {
+		return err
+	}
+
+	var selected []*mcp.RuleFileTool
+	if exportAll {
+		for _, tool := range tools {
+			if repositoryID == "" || tool.RuleFile.RepositoryID == repositoryID {
+				selected = append(selected, tool)
+			}
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("no rules to export")
+		}
+	} else {
+		for _, name := range args {
+			tool, err := mcp.ResolveRule(tools, name, repositoryID)
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(selected, tool) {
+				selected = append(selected, tool)
+			}
+		}
+	}
+
+	files := make([]share.File, 0, len(selected))
+	for _, tool := range selected {
+		rel, err := ruleRepositoryPath(prepared, tool.RuleFile)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(tool.RuleFile.FilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", tool.RuleFile.FilePath, err)
+		}
+		files = append(files, share.File{Path: rel, Content: content})
+	}
+
+	f, err := os.Create(exportOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", exportOutput, err)
+	}
+	exported, err := share.WriteBundle(f, files, redactor)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write %s: %w", exportOutput, closeErr)
+	}
+	if err != nil {
+		os.Remove(exportOutput)
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	total := 0
+	for _, e := range exported {
+		fmt.Fprintf(out, "%s (%d redaction(s))\n", e.Path, e.Redactions)
+		total += e.Redactions
+	}
+	fmt.Fprintf(out, "Exported %d rule(s) to %s with %d redaction(s)\n", len(exported), exportOutput, total)
+	if redactor.Empty() {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Warning: no redactions are configured, so the bundle is not anonymized")
+	}
+	return nil
+}
+
+// runSummary prints the rule activity of the last --days days
+func runSummary(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	if summaryDays < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	var repo *repository.RepositoryEntry
+	switch {
+	case summaryRepo != "":
+		if repo, err = findRepository(cfg, summaryRepo); err != nil {
+			return err
+		}
+	case summaryWrite && len(cfg.Repositories) == 1:
+		repo = &cfg.Repositories[0]
+	case summaryWrite:
+		return fmt.Errorf("use --repo to choose the repository to write the summary to")
+	}
+	var repositoryID string
+	if repo != nil {
+		repositoryID = repo.ID
+	}
+
+	period := time.Duration(summaryDays) * 24 * time.Hour
+	report, err := summary.Generate(context.Background(), cfg, appLogger, repositoryID, period, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.OutOrStdout(), report.Markdown())
+
+	if summaryWrite {
+		root := repo.Path
+		if overlay := repo.GetOverlay(); overlay != "" {
+			root = overlay
+		}
+		path, err := report.Write(root)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Summary written to %s\n", path)
+	}
+	return nil
+}
+
+// runStats prints the most and least used rules of the last --days days
+func runStats(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	if statsDays < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+	if statsLimit < 1 {
+		return fmt.Errorf("--limit must be at least 1")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	var repositoryID string
+	if statsRepo != "" {
+		repo, err := findRepository(cfg, statsRepo)
+		if err != nil {
+			return err
+		}
+		repositoryID = repo.ID
+	}
+
+	period := time.Duration(statsDays) * 24 * time.Hour
+	stats, err := summary.GenerateStats(context.Background(), cfg, appLogger, repositoryID, period, time.Now())
+	if err != nil {
+		return err
+	}
+	if statsJSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	fmt.Fprint(cmd.OutOrStdout(), stats.Text(statsLimit))
+	return nil
+}
+
+// ruleRepositoryPath returns the path of a rule relative to the root of its
+// repository, or of the repository's overlay when the rule lives there
+func ruleRepositoryPath(prepared []repository.PreparedRepository, rule *mcp.RuleFile) (string, error) {
+	for _, prep := range prepared {
+		if prep.ID() != rule.RepositoryID {
+			continue
+		}
+		for _, root := range []string{prep.OverlayPath, prep.LocalPath} {
+			if root == "" {
+				continue
+			}
+			if rel, err := filepath.Rel(root, rule.FilePath); err == nil && filepath.IsLocal(rel) {
+				return filepath.ToSlash(rel), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("rule %s is not in a configured repository", rule.FilePath)
+}
+
+// runCheck loads checks from all configured repositories and runs them against the current directory
+func runCheck(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, appLogger)
+	if err != nil {
+		return fmt.Errorf("failed to prepare repositories: %w", err)
+	}
+
+	files, err := filemanager.ScanAllRepositories(prepared, appLogger)
+	if err != nil {
+		return fmt.Errorf("failed to scan repositories: %w", err)
+	}
+
+	processor, err := mcp.NewRuleFileProcessorForRepositories(cfg, prepared, appLogger)
+	if err != nil {
+		return err
+	}
+	ruleChecks := checks.LoadChecks(files, processor, appLogger)
+	if len(ruleChecks) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No checks declared by rules")
+		return nil
+	}
+
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	violations, err := checks.Run(projectDir, ruleChecks)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range violations {
+		fmt.Fprintln(cmd.OutOrStdout(), v.String())
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d check violation(s) found", len(violations))
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "All %d check(s) passed\n", len(ruleChecks))
+	return nil
+}
+
+// evaluatePolicy loads the organization policies shipped in the configured repositories
+// and evaluates them against this client
+func evaluatePolicy(cfg *config.Config) (*policy.Report, error) {
+	policies, err := policy.Load(cfg.Repositories, appLogger)
+	if err != nil {
+		return nil, err
+	}
+	return policy.Evaluate(policies, version.Current(), cfg.Repositories, cfg.ContentSecurity), nil
+}
+
+// enforcePolicy logs the applied organization policies and fails if any is violated
+func enforcePolicy(cfg *config.Config) error {
+	_, err := applyPolicy(cfg)
+	return err
+}
+
+// applyPolicy is enforcePolicy for commands that also act on the policies,
+// returning the report of those applied
+func applyPolicy(cfg *config.Config) (*policy.Report, error) {
+	report, err := evaluatePolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, applied := range report.Applied {
+		appLogger.Info("Organization policy applied", "policy", applied)
+	}
+	return report, report.Err()
+}
+
+// registerHooks validates the hooks in the configuration and runs the
+// post-sync hooks whenever a repository is synced
+func registerHooks(cfg *config.Config) error {
+	if err := hooks.Validate(cfg.Hooks); err != nil {
+		return fmt.Errorf("invalid hooks in config: %w", err)
+	}
+	if len(cfg.Hooks) == 0 {
+		return nil
+	}
+	configured := cfg.Hooks
+	repository.OnSync(func(repo repository.RepositoryEntry, result repository.RepositorySyncResult) {
+		payload := hooks.Payload{Event: hooks.EventPostSync, Repository: repo.Name, RepositoryID: repo.ID}
+		if commit, err := repository.HeadCommit(repo.Path); err == nil {
+			payload.Commit = commit
+		}
+		hooks.Notify(context.Background(), configured, payload, appLogger)
+	})
+	return nil
+}
+
+// runPolicy prints the organization policies and any violations
+func runPolicy(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+
+	report, err := evaluatePolicy(cfg)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(report.Applied) == 0 {
+		fmt.Fprintln(out, "No organization policies apply")
+		return nil
+	}
+
+	fmt.Fprintln(out, "Applied organization policies:")
+	for _, applied := range report.Applied {
+		fmt.Fprintf(out, "  - %s\n", applied)
+	}
+	return report.Err()
+}
+
+// runMigrate imports another tool's rules into a rule repository and prints the report
+func runMigrate(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	// --to works without a configuration; save_collision is used when there is one
+	cfg, cfgErr := config.Load()
+	destDir := migrateTo
+	if destDir == "" {
+		if cfgErr != nil {
+			return fmt.Errorf("error loading config: %w", cfgErr)
+		}
+		for _, repo := range cfg.Repositories {
+			if repo.IsLocal() {
+				// Shared storage is read-only; imported rules go to the user's overlay
+				destDir = repo.Path
+				if repo.IsShared() {
+					destDir = fileops.ExpandPath(repo.GetOverlay())
+				}
+				break
+			}
+		}
+		if destDir == "" {
+			return fmt.Errorf("no local rule repository configured; pass --to to choose a destination")
+		}
+	}
+
+	collision, err := collisionStrategy(cfg, migrateOnConflict, migrateOverwrite)
+	if err != nil {
+		return err
+	}
+
+	report, err := migrate.Migrate(migrate.Source(args[0]), args[1], destDir, collision)
+	if err != nil {
+		return err
+	}
+	appLogger.Info("Migration completed", "source", args[0], "dest", destDir,
+		"imported", len(report.Imported), "skipped", len(report.Skipped))
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Destination: %s\n\n%s", destDir, report.Markdown())
+	return nil
+}
+
+// Error codes of rulem save, printed with --json
+const (
+	saveErrSourceInvalid     = "source_invalid"
+	saveErrRepositoryUnknown = "repository_unknown"
+	saveErrNameInvalid       = "name_invalid"
+	saveErrAlreadyExists     = "already_exists"
+	saveErrSaveFailed        = "save_failed"
+)
+
+// saveError is a failed rulem save, with a code scripts can branch on
+type saveError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// saveResult is the JSON output of rulem save
+type saveResult struct {
+	Path       string     `json:"path,omitempty"`
+	Repository string     `json:"repository,omitempty"`
+	Error      *saveError `json:"error,omitempty"`
+}
+
+// runSave copies a rule file into a rule repository
+func runSave(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	result := saveRule(args[0])
+	out := cmd.OutOrStdout()
+
+	if saveJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	} else if result.Error == nil {
+		fmt.Fprintln(out, result.Path)
+	}
+
+	if result.Error == nil {
+		return nil
+	}
+	code := 1
+	if result.Error.Code == saveErrAlreadyExists {
+		code = 2
+	}
+	return &exitError{code: code, err: errors.New(result.Error.Message)}
+}
+
+// saveRule copies srcPath into the repository chosen with --repo, describing
+// any failure as a saveError
+func saveRule(srcPath string) saveResult {
+	fail := func(code string, err error) saveResult {
+		return saveResult{Error: &saveError{Code: code, Message: err.Error()}}
+	}
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return fail(saveErrSourceInvalid, fmt.Errorf("cannot read %s: %w", srcPath, err))
+	}
+	if info.IsDir() {
+		return fail(saveErrSourceInvalid, fmt.Errorf("%s is a directory, not a rule file", srcPath))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fail(saveErrRepositoryUnknown, fmt.Errorf("error loading config: %w", err))
+	}
+	if err := registerHooks(cfg); err != nil {
+		return fail(saveErrSaveFailed, err)
+	}
+
+	collision, err := collisionStrategy(cfg, saveOnConflict, saveOverwrite)
+	if err != nil {
+		return fail(saveErrSaveFailed, err)
+	}
+
+	repo, err := saveDestination(cfg)
+	if err != nil {
+		return fail(saveErrRepositoryUnknown, err)
+	}
+
+	// The repository is used as it is on disk: saving never syncs
+	prep := repository.PreparedRepository{Entry: *repo, LocalPath: fileops.ExpandPath(repo.Path)}
+	if _, err := os.Stat(prep.LocalPath); err != nil {
+		return fail(saveErrRepositoryUnknown, fmt.Errorf("repository %s is not available at %s: %w", repo.Name, prep.LocalPath, err))
+	}
+	if repo.IsShared() {
+		if prep.OverlayPath, err = repository.PrepareOverlay(prep.LocalPath, repo.GetOverlay(), appLogger); err != nil {
+			return fail(saveErrSaveFailed, err)
+		}
+	}
+
+	fm, err := filemanager.NewRepositoryFileManager(prep, appLogger)
+	if err != nil {
+		return fail(saveErrSaveFailed, err)
+	}
+
+	var newName *string
+	if saveName != "" {
+		if _, err := fileops.SanitizeFilename(saveName); err != nil {
+			return fail(saveErrNameInvalid, fmt.Errorf("invalid --name %q: %w", saveName, err))
+		}
+		newName = &saveName
+	}
+
+	destPath, err := fm.SaveFileToStorage(srcPath, newName, collision)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return fail(saveErrAlreadyExists, fmt.Errorf("%w - pass --overwrite to replace it or --on-conflict rename to keep both", err))
+		}
+		return fail(saveErrSaveFailed, err)
+	}
+	appLogger.Info("Rule saved", "source", srcPath, "dest", destPath, "repository_id", repo.ID)
+
+	if collision != fileops.CollisionOverwrite {
+		hooks.Notify(context.Background(), cfg.Hooks, hooks.RulePayload(hooks.EventRuleCreated, prep, destPath), appLogger)
+	}
+	return saveResult{Path: destPath, Repository: repo.Name}
+}
+
+// runDeploy copies or links rules into the project in the current directory
+func runDeploy(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	var repositoryID string
+	if deployRepo != "" {
+		repo, err := findRepository(cfg, deployRepo)
+		if err != nil {
+			return err
+		}
+		repositoryID = repo.ID
+	}
+
+	profiles, err := deploy.Profiles(cfg)
+	if err != nil {
+		return err
+	}
+	profile := profiles[0]
+	if deployTarget != "" {
+		if profile, err = editors.FindEditorRuleConfig(profiles, deployTarget); err != nil {
+			return fmt.Errorf("invalid --target: %w", err)
+		}
+	}
+
+	prepared, tools, err := mcp.PrepareAndLoadRuleTools(context.Background(), cfg, appLogger)
+	if err != nil {
+		return err
+	}
+	available := repository.AvailableRepositories(prepared)
+	files, err := filemanager.ScanAllRepositories(available, appLogger)
+	if err != nil {
+		return fmt.Errorf("failed to scan repositories: %w", err)
+	}
+
+	targets := make([]deploy.Target, 0, len(args))
+	for _, name := range args {
+		source, prep, err := deployedRule(available, tools, files, name, repositoryID)
+		if err != nil {
+			return err
+		}
+		dest, err := deploy.Destination(filepath.Base(source), deployAs, profile)
+		if err != nil {
+			return fmt.Errorf("invalid --as: %w", err)
+		}
+		targets = append(targets, deploy.Target{Source: source, Repository: prep, Dest: dest, Frontmatter: profile.Frontmatter, VSCodeSnippet: profile.VSCodeSnippets})
+	}
+
+	mode := project.ModeCopy
+	if deployLink {
+		mode = project.ModeLink
+	}
+	opts := deploy.Options{Mode: mode, Overwrite: deployOverwrite, Hooks: cfg.Hooks}
+	paths, err := deploy.Deploy(context.Background(), targets, opts, appLogger)
+	if err != nil {
+		if errors.Is(err, deploy.ErrDestinationExists) {
+			return &exitError{code: 2, err: fmt.Errorf("%w - pass --overwrite to replace", err)}
+		}
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for i, path := range paths {
+		fmt.Fprintln(out, targets[i].Dest)
+		appLogger.Debug("Deployed rule", "path", path)
+	}
+	return nil
+}
+
+// deployedRule resolves the rule name refers to like rulem cat does, falling
+// back to its path or file name for rules without a tool name, and returns
+// its path and repository
+func deployedRule(prepared []repository.PreparedRepository, tools map[string]*mcp.RuleFileTool, files []filemanager.FileItem, name, repositoryID string) (string, repository.PreparedRepository, error) {
+	tool, err := mcp.ResolveRule(tools, name, repositoryID)
+	if err == nil {
+		for _, prep := range prepared {
+			if prep.ID() == tool.RuleFile.RepositoryID {
+				return tool.RuleFile.FilePath, prep, nil
+			}
+		}
+		return "", repository.PreparedRepository{}, fmt.Errorf("repository of rule '%s' is not available", name)
+	}
+	if !strings.HasPrefix(err.Error(), "no rule named") {
+		return "", repository.PreparedRepository{}, err
+	}
+
+	file, prep, findErr := deploy.FindRule(prepared, files, name, repositoryID)
+	if findErr != nil {
+		return "", repository.PreparedRepository{}, findErr
+	}
+	return file.Path, prep, nil
+}
+
+// collisionStrategy returns the strategy chosen with --on-conflict or
+// --overwrite, falling back to save_collision when cfg is set
+func collisionStrategy(cfg *config.Config, onConflict string, overwrite bool) (fileops.CollisionStrategy, error) {
+	switch {
+	case overwrite:
+		return fileops.CollisionOverwrite, nil
+	case onConflict != "":
+		strategy, err := fileops.ParseCollisionStrategy(onConflict)
+		if err != nil {
+			return "", fmt.Errorf("invalid --on-conflict: %w", err)
+		}
+		return strategy, nil
+	case cfg != nil:
+		return cfg.SaveCollisionStrategy()
+	}
+	return fileops.CollisionAsk, nil
+}
+
+// saveDestination returns the repository chosen with --repo, the one the
+// project's .rulem.yaml prefers, or the first local rule repository
+func saveDestination(cfg *config.Config) (*repository.RepositoryEntry, error) {
+	if saveRepo != "" {
+		return findRepository(cfg, saveRepo)
+	}
+	if repo, err := cfg.PreferredRepository(); repo != nil || err != nil {
+		return repo, err
+	}
+	for i := range cfg.Repositories {
+		if cfg.Repositories[i].IsLocal() {
+			return &cfg.Repositories[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no local rule repository configured; pass --repo to choose one")
+}
+
+// runSync fetches updates for the selected GitHub repositories
+func runSync(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("configuration is nil after loading")
+	}
+	if err := enforcePolicy(cfg); err != nil {
+		return err
+	}
+	if err := registerHooks(cfg); err != nil {
+		return err
+	}
+
+	repos := repository.WithWorktrees(cfg.Repositories)
+	if syncRepo != "" {
+		repo, err := findRepository(cfg, syncRepo)
+		if err != nil {
+			return err
+		}
+		var selected []repository.RepositoryEntry
+		for _, entry := range repos {
+			if entry.ID == repo.ID || entry.WorktreeOf == repo.ID {
+				selected = append(selected, entry)
+			}
+		}
+		repos = selected
+	}
+	if len(repos) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No repositories configured")
+		return nil
+	}
+
+	results := repository.SyncAllRepositories(context.Background(), repos, appLogger)
+
+	var failed []string
+	for _, result := range results {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", result.RepositoryName, result.GetMessage())
+		if result.Status == repository.SyncStatusFailed {
+			failed = append(failed, fmt.Sprintf("%s (%s): %v", result.RepositoryName, result.RepositoryID, result.Error))
+		}
+	}
+	if len(failed) > 0 {
+		for _, failure := range failed {
+			fmt.Fprintln(cmd.ErrOrStderr(), failure)
+		}
+		return &exitError{code: 1, err: fmt.Errorf("%d of %d repositories failed to sync", len(failed), len(results))}
+	}
+	return nil
+}
+
+// authLoginTokenName returns the name of the token `rulem auth login` stores,
+// empty for the default token, and a description of who uses it
+func authLoginTokenName() (name, scope string, err error) {
+	switch {
+	case authLoginRepo != "":
+		cfg, err := config.Load()
+		if err != nil {
+			return "", "", fmt.Errorf("error loading config: %w", err)
+		}
+		repo, err := findRepository(cfg, authLoginRepo)
+		if err != nil {
+			return "", "", err
+		}
+		if !repo.IsRemote() || repository.DetectProvider(repo.GetRemoteURL()) != repository.ProviderGitHub {
+			return "", "", fmt.Errorf("repository '%s' is not a GitHub repository", repo.Name)
+		}
+		return repository.RepositoryTokenName(repo.ID), fmt.Sprintf("repository '%s'", repo.Name), nil
+	case authLoginHost != "":
+		host := strings.ToLower(strings.TrimSpace(authLoginHost))
+		if host == "" || strings.ContainsAny(host, "/@ ") {
+			return "", "", fmt.Errorf("invalid host %q: give a host name such as github.com", authLoginHost)
+		}
+		if repository.DetectProvider("https://"+host+"/") != repository.ProviderGitHub {
+			return "", "", fmt.Errorf("%s is not a GitHub host", host)
+		}
+		return repository.HostTokenName(host), "the repositories on " + host, nil
+	}
+	return "", "all GitHub repositories", nil
+}
+
+// runAuthLogin signs in to GitHub with the device flow and stores the token
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	initLogger()
+
+	// Work out where the token goes before asking GitHub for one
+	tokenName, scope, err := authLoginTokenName()
+	if err != nil {
+		return err
+	}
+
+	flow, err := repository.NewGitHubDeviceFlow()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	code, err := flow.Start(ctx, repository.DeviceLoginScopes)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "First copy your one-time code: %s\n", code.UserCode)
+	fmt.Fprintf(out, "Then open %s and enter it.\n\n", code.VerificationURI)
+	fmt.Fprintln(out, "Waiting for authorization...")
+
+	token, err := flow.Wait(ctx, code)
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("sign in cancelled")
+	}
+	if err != nil {
+		return err
+	}
+
+	credManager := repository.NewCredentialManager()
+	if tokenName != "" {
+		err = credManager.StoreNamedToken(repository.ProviderGitHub, tokenName, token.AccessToken)
+	} else {
+		err = credManager.StoreGitHubToken(token.AccessToken)
+	}
+	if err != nil {
+		return fmt.Errorf("signed in, but the token could not be stored: %w", err)
+	}
+	appLogger.Info("Stored GitHub token from device flow", "scopes", token.Scopes, "name", tokenName)
+
+	fmt.Fprintf(out, "Signed in to GitHub and stored the token for %s.\n", scope)
+	if len(token.Scopes) == 0 {
+		fmt.Fprintln(out, "Granted scopes: none")
+	} else {
+		fmt.Fprintf(out, "Granted scopes: %s\n", strings.Join(token.Scopes, ", "))
+	}
+	if !slices.Contains(token.Scopes, "repo") {
+		fmt.Fprintln(cmd.ErrOrStderr(), `Warning: the "repo" scope was not granted, so private repositories cannot be cloned or pushed to`)
+	}
+	return nil
+}
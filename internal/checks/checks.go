@@ -0,0 +1,254 @@
+// Package checks turns rule files into lightweight lint rules.
+//
+// A rule file can declare checks in its frontmatter under the `check` key. Each
+// check is a regular expression that must not match any line of the project
+// files selected by a glob:
+//
+//	---
+//	description: Go logging conventions
+//	check:
+//	  - pattern: 'fmt\.Println\('
+//	    files: "*.go"
+//	    message: Use the structured logger instead of fmt.Println
+//	---
+//
+// `rulem check` loads the checks from every configured repository and reports
+// each matching line as a violation, so it can be used as a pre-commit hook.
+package checks
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/mcp"
+)
+
+// maxCheckedFileSize skips files that are unlikely to be source (generated bundles, binaries)
+const maxCheckedFileSize = 5 * 1024 * 1024
+
+// skippedDirs are never walked when running checks
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// CheckDefinition is a single entry under the `check` frontmatter key
+type CheckDefinition struct {
+	Pattern string `yaml:"pattern" toml:"pattern" json:"pattern"` // Regular expression that must not match
+	Files   string `yaml:"files" toml:"files" json:"files"`       // Glob selecting files; a pattern without "/" matches base names
+	Message string `yaml:"message" toml:"message" json:"message"` // Shown for each violation
+}
+
+// Check is a compiled check along with the rule it came from
+type Check struct {
+	Rule    string // Name of the rule file that declared the check
+	Pattern *regexp.Regexp
+	Files   string
+	Message string
+}
+
+// Violation is a single line that matched a check
+type Violation struct {
+	Check Check
+	Path  string // Path relative to the project directory, using forward slashes
+	Line  int
+	Text  string
+}
+
+// String formats the violation as "path:line: message (rule)"
+func (v Violation) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", v.Path, v.Line, v.Check.Message, v.Check.Rule)
+}
+
+type ruleCheckFrontmatter struct {
+	Check []CheckDefinition `yaml:"check" toml:"check" json:"check"`
+}
+
+// ParseChecks extracts and compiles the checks declared in a rule file's frontmatter.
+// Files without frontmatter or without a `check` key yield no checks.
+//
+// Parameters:
+//   - ruleName: Name of the rule file, recorded on each check for reporting
+//   - content: Raw rule file content
+//   - processor: Parses the frontmatter with the configured delimiters
+//
+// Returns:
+//   - []Check: Compiled checks in declaration order
+//   - error: If the frontmatter is malformed or a check is invalid
+func ParseChecks(ruleName string, content []byte, processor *mcp.RuleFileProcessor) ([]Check, error) {
+	var matter ruleCheckFrontmatter
+	if err := processor.DecodeFrontmatter(content, ruleName, &matter); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter in %s: %w", ruleName, err)
+	}
+
+	checks := make([]Check, 0, len(matter.Check))
+	for i, def := range matter.Check {
+		if strings.TrimSpace(def.Pattern) == "" {
+			return nil, fmt.Errorf("check %d in %s has no pattern", i+1, ruleName)
+		}
+		re, err := regexp.Compile(def.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("check %d in %s has an invalid pattern: %w", i+1, ruleName, err)
+		}
+
+		files := strings.TrimSpace(def.Files)
+		if files == "" {
+			files = "*"
+		}
+		if _, err := filepath.Match(files, ""); err != nil {
+			return nil, fmt.Errorf("check %d in %s has an invalid files glob %q: %w", i+1, ruleName, files, err)
+		}
+
+		message := strings.TrimSpace(def.Message)
+		if message == "" {
+			message = fmt.Sprintf("matches forbidden pattern %q", def.Pattern)
+		}
+
+		checks = append(checks, Check{Rule: ruleName, Pattern: re, Files: files, Message: message})
+	}
+
+	return checks, nil
+}
+
+// LoadChecks parses checks from all rule files. Files that cannot be read or
+// declare invalid checks are logged and skipped so one broken rule does not
+// disable the others.
+func LoadChecks(files []filemanager.FileItem, processor *mcp.RuleFileProcessor, logger *logging.AppLogger) []Check {
+	var checks []Check
+	for _, file := range files {
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			logger.Warn("Failed to read rule file for checks", "path", file.Path, "error", err)
+			continue
+		}
+
+		fileChecks, err := ParseChecks(file.Name, content, processor)
+		if err != nil {
+			logger.Warn("Skipping invalid checks", "path", file.Path, "error", err)
+			continue
+		}
+		checks = append(checks, fileChecks...)
+	}
+	return checks
+}
+
+// Run applies checks to every regular file under projectDir and returns the
+// violations sorted by path and line.
+func Run(projectDir string, checks []Check) ([]Violation, error) {
+	if len(checks) == 0 {
+		return nil, nil
+	}
+
+	var violations []Violation
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != projectDir && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		applicable := applicableChecks(rel, checks)
+		if len(applicable) == 0 {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > maxCheckedFileSize {
+			return nil
+		}
+
+		fileViolations, err := checkFile(path, rel, applicable)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, fileViolations...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run checks: %w", err)
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Path != violations[j].Path {
+			return violations[i].Path < violations[j].Path
+		}
+		return violations[i].Line < violations[j].Line
+	})
+
+	return violations, nil
+}
+
+// applicableChecks returns the checks whose glob selects relPath
+func applicableChecks(relPath string, checks []Check) []Check {
+	var applicable []Check
+	for _, c := range checks {
+		if matchesGlob(c.Files, relPath) {
+			applicable = append(applicable, c)
+		}
+	}
+	return applicable
+}
+
+// matchesGlob matches a glob containing "/" against the whole relative path and
+// any other glob against the base name, like .gitignore patterns
+func matchesGlob(glob, relPath string) bool {
+	target := relPath
+	if !strings.Contains(glob, "/") {
+		target = filepath.Base(filepath.FromSlash(relPath))
+	}
+	ok, _ := filepath.Match(glob, target)
+	return ok
+}
+
+// checkFile scans a file line by line against the applicable checks
+func checkFile(path, relPath string, checks []Check) ([]Violation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s: %w", relPath, err)
+	}
+	defer f.Close()
+
+	var violations []Violation
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxCheckedFileSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		for _, c := range checks {
+			if c.Pattern.MatchString(text) {
+				violations = append(violations, Violation{Check: c, Path: relPath, Line: line, Text: text})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", relPath, err)
+	}
+
+	return violations, nil
+}
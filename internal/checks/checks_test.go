@@ -0,0 +1,193 @@
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/mcp"
+)
+
+// newProcessor returns a rule file processor with the default frontmatter delimiters
+func newProcessor(t *testing.T) *mcp.RuleFileProcessor {
+	t.Helper()
+	logger, _ := logging.NewTestLogger()
+	return mcp.NewRuleFileProcessor(logger, nil, 1024*1024)
+}
+
+func TestParseChecks(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantCount int
+		wantFiles string
+		wantErr   bool
+	}{
+		{
+			name:      "yaml check list",
+			content:   "---\ndescription: Logging\ncheck:\n  - pattern: 'fmt\\.Println'\n    files: \"*.go\"\n    message: Use the logger\n---\n# Body\n",
+			wantCount: 1,
+			wantFiles: "*.go",
+		},
+		{
+			name:      "files defaults to everything",
+			content:   "---\ncheck:\n  - pattern: TODO\n---\n",
+			wantCount: 1,
+			wantFiles: "*",
+		},
+		{
+			name:      "no check key",
+			content:   "---\ndescription: Plain rule\n---\n# Body\n",
+			wantCount: 0,
+		},
+		{
+			name:      "no frontmatter",
+			content:   "# Just a rule\n",
+			wantCount: 0,
+		},
+		{
+			name:    "missing pattern",
+			content: "---\ncheck:\n  - files: \"*.go\"\n---\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid regex",
+			content: "---\ncheck:\n  - pattern: '('\n---\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid glob",
+			content: "---\ncheck:\n  - pattern: x\n    files: '['\n---\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := ParseChecks("rule.md", []byte(tt.content), newProcessor(t))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseChecks returned error: %v", err)
+			}
+			if len(checks) != tt.wantCount {
+				t.Fatalf("got %d checks, want %d", len(checks), tt.wantCount)
+			}
+			if tt.wantCount > 0 {
+				if checks[0].Files != tt.wantFiles {
+					t.Errorf("files = %q, want %q", checks[0].Files, tt.wantFiles)
+				}
+				if checks[0].Rule != "rule.md" {
+					t.Errorf("rule = %q, want %q", checks[0].Rule, "rule.md")
+				}
+			}
+		})
+	}
+}
+
+func TestParseChecks_ConfiguredFrontmatter(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	processor, err := mcp.NewRuleFileProcessorWithDelimiters(logger, nil, 1024*1024, []config.FrontmatterDelimiter{
+		{Start: "<<<", End: ">>>", Syntax: mcp.FrontmatterSyntaxYAML},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A licence header before custom delimiters, as the MCP server reads them
+	content := "\uFEFF<!-- Licensed under MIT -->\n\n<<<\ncheck:\n  - pattern: TODO\n>>>\n# Body\n"
+	checks, err := ParseChecks("todo.md", []byte(content), processor)
+	if err != nil {
+		t.Fatalf("ParseChecks returned error: %v", err)
+	}
+	if len(checks) != 1 || checks[0].Pattern.String() != "TODO" {
+		t.Errorf("checks = %v, want the TODO check", checks)
+	}
+}
+
+func TestRun(t *testing.T) {
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, "main.go"), "package main\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n")
+	writeFile(t, filepath.Join(project, "README.md"), "fmt.Println is mentioned here\n")
+	writeFile(t, filepath.Join(project, "cmd", "tool", "tool.go"), "package tool\n// fmt.Println\n")
+	writeFile(t, filepath.Join(project, "vendor", "dep", "dep.go"), "fmt.Println\n")
+	writeFile(t, filepath.Join(project, ".git", "hooks", "x.go"), "fmt.Println\n")
+
+	checks, err := ParseChecks("logging.md", []byte("---\ncheck:\n  - pattern: 'fmt\\.Println'\n    files: \"*.go\"\n    message: Use the logger\n---\n"), newProcessor(t))
+	if err != nil {
+		t.Fatalf("ParseChecks returned error: %v", err)
+	}
+
+	violations, err := Run(project, checks)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	want := []string{
+		"cmd/tool/tool.go:2: Use the logger (logging.md)",
+		"main.go:4: Use the logger (logging.md)",
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("got %d violations, want %d: %v", len(violations), len(want), violations)
+	}
+	for i, v := range violations {
+		if v.String() != want[i] {
+			t.Errorf("violation %d = %q, want %q", i, v.String(), want[i])
+		}
+	}
+}
+
+func TestRunPathGlob(t *testing.T) {
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, "cmd", "main.go"), "panic(\"x\")\n")
+	writeFile(t, filepath.Join(project, "internal", "lib.go"), "panic(\"x\")\n")
+
+	checks, err := ParseChecks("nopanic.md", []byte("---\ncheck:\n  - pattern: 'panic\\('\n    files: \"internal/*.go\"\n---\n"), newProcessor(t))
+	if err != nil {
+		t.Fatalf("ParseChecks returned error: %v", err)
+	}
+
+	violations, err := Run(project, checks)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(violations) != 1 || violations[0].Path != "internal/lib.go" {
+		t.Errorf("expected a single violation in internal/lib.go, got %v", violations)
+	}
+}
+
+func TestLoadChecksSkipsInvalidRules(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.md")
+	invalid := filepath.Join(dir, "invalid.md")
+	writeFile(t, valid, "---\ncheck:\n  - pattern: TODO\n---\n")
+	writeFile(t, invalid, "---\ncheck:\n  - pattern: '('\n---\n")
+
+	checks := LoadChecks([]filemanager.FileItem{
+		{Name: "valid.md", Path: valid},
+		{Name: "invalid.md", Path: invalid},
+		{Name: "missing.md", Path: filepath.Join(dir, "missing.md")},
+	}, newProcessor(t), logger)
+
+	if len(checks) != 1 || checks[0].Rule != "valid.md" {
+		t.Errorf("expected only the valid rule's check, got %v", checks)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -13,7 +13,7 @@
 //
 // Configuration is stored in YAML format and includes:
 //   - Central repository location and optional sync metadata
-//   - Configuration version tracking
+//   - Configuration version tracking and migration of older configs
 //   - Initialization timestamp
 //
 // The package provides both synchronous and asynchronous (Bubble Tea command)
@@ -30,12 +30,17 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"rulem/internal/editors"
 	"rulem/internal/logging"
 	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+	"slices"
 	"strings"
 	"time"
 
@@ -78,6 +83,21 @@ const AppName = "rulem" // application name used for config directory
 //   - Version: Configuration schema version (kept for informational purposes)
 //   - InitTime: Unix timestamp when the configuration was first created
 //   - Repositories: Array of configured repositories (replaces single Central field)
+//   - FrontmatterDelimiters: Optional override of recognised rule frontmatter blocks
+//   - Redactions: Replacements applied to rules exported with `rulem export`
+//   - Hooks: Scripts and webhooks run on lifecycle events such as a sync
+//   - ContentSecurity: Content policy applied to every repository's rules
+//   - SyncInterval: How often GitHub repositories are synced in the background
+//   - AutoDescriptions: Whether rules without a description get one derived from their body
+//   - TemplateURL: Template repository suggested for new rules repositories
+//   - WatchMode, WatchPollInterval: How the MCP server notices changed rule files
+//   - ToolPrefix: Prefix of every MCP tool name the server registers
+//   - ScanExcludes: Paths scans for rule files leave out
+//   - RuleExtensions: Extensions of the files scanned for rules
+//   - StartupAction: What rulem does when launched without a command
+//   - DeployTargets: Editors rules are deployed for, besides the built-in ones
+//   - MCPProfiles: What the MCP server serves to each client, by profile name
+//   - Project: Overrides from the .rulem.yaml of the current project
 //
 // Note: RepositoryEntry is defined in the repository package as it's a domain entity.
 // Config package consumes repository domain types for persistence.
@@ -85,6 +105,427 @@ type Config struct {
 	Version      string                       `yaml:"version"`      // Track config version (informational only)
 	InitTime     int64                        `yaml:"init_time"`    // Unix timestamp of first setup
 	Repositories []repository.RepositoryEntry `yaml:"repositories"` // Configured repositories (replaces Central)
+
+	// RepositoryIDAliases maps the timestamp IDs rewritten by the 1.1
+	// migration to the IDs that replaced them, see MigrateRepositoryIDs
+	RepositoryIDAliases map[string]string `yaml:"repository_id_aliases,omitempty"`
+
+	// FrontmatterDelimiters overrides the frontmatter blocks recognised in rule files.
+	// When empty, YAML (---), TOML (+++) and JSON (;;;) are recognised.
+	FrontmatterDelimiters []FrontmatterDelimiter `yaml:"frontmatter_delimiters,omitempty"`
+
+	// Redactions replace organization-identifying strings in exported rule bundles
+	Redactions []Redaction `yaml:"redactions,omitempty"`
+
+	// Hooks run scripts or call webhooks on lifecycle events, see the hooks package
+	Hooks []Hook `yaml:"hooks,omitempty"`
+
+	// ContentSecurity selects the policy rule content is checked against (the
+	// standard profile when nil). Repositories can override it.
+	ContentSecurity *fileops.ContentSecurity `yaml:"content_security,omitempty"`
+
+	// SyncInterval is how often the TUI and the MCP server sync GitHub
+	// repositories in the background, as a Go duration such as "30m". Empty
+	// disables the background sync.
+	SyncInterval string `yaml:"sync_interval,omitempty"`
+
+	// SaveCollision is what saving a rule does when the repository already has
+	// a file with its name: "ask" (the default), "rename" to save it with a
+	// numeric suffix, or "overwrite". It applies to the save screen, rulem save
+	// and rulem migrate, each of which can override it for a single save.
+	SaveCollision string `yaml:"save_collision,omitempty"`
+
+	// AutoDescriptions registers rules without a description as MCP tools with
+	// one derived from their first heading or paragraph, marked as
+	// auto-generated. Off by default: such rules are skipped.
+	AutoDescriptions bool `yaml:"auto_descriptions,omitempty"`
+
+	// TemplateURL is the template repository suggested when a new rules
+	// repository is created from a template in Settings.
+	TemplateURL string `yaml:"template_url,omitempty"`
+
+	// WatchMode is how the MCP server notices changed rule files: "notify"
+	// uses file system notifications, "poll" rescans the repositories, and
+	// "auto" (the default) polls repositories on network file systems such as
+	// NFS or SMB, where notifications are unreliable, and notifies otherwise.
+	WatchMode string `yaml:"watch_mode,omitempty"`
+
+	// WatchPollInterval is how often rule files are rescanned when polling, as
+	// a Go duration such as "5s". Polling slows down while nothing changes.
+	WatchPollInterval string `yaml:"watch_poll_interval,omitempty"`
+
+	// MCPClients names the MCP clients the server is used with, e.g. cursor
+	// or copilot. The MCP server checks its tools against their limits, as
+	// well as the MCP specification's, when it starts.
+	MCPClients []string `yaml:"mcp_clients,omitempty"`
+
+	// BundleMaxTokens caps the rules the get_all_rules MCP tool returns in
+	// one call, in tokens estimated from their size (DefaultBundleMaxTokens
+	// when unset). Assistants can ask for a smaller bundle, not a larger one.
+	BundleMaxTokens int `yaml:"bundle_max_tokens,omitempty"`
+
+	// ToolPrefix is put in front of every MCP tool name, search_rules
+	// included, e.g. "rules_" serves go_style as rules_go_style. It keeps the
+	// tools apart from those of other MCP servers connected to the same client.
+	// Repositories can set their own tool_namespace too.
+	ToolPrefix string `yaml:"tool_prefix,omitempty"`
+
+	// ScanExcludes are gitignore patterns of paths that scans for rule files
+	// leave out, replacing filemanager.DefaultScanExcludes (dependency and build
+	// directories such as node_modules and vendor) when set. A .rulemignore file
+	// at the root of a scanned directory adds patterns of its own.
+	ScanExcludes []string `yaml:"scan_excludes,omitempty"`
+
+	// RuleExtensions are the extensions of the files scanned for rules, e.g.
+	// [".md", ".mdc", ".txt", ".yaml"], replacing
+	// filemanager.DefaultRuleExtensions (markdown and .mdc) when set. YAML and
+	// JSON files hold the frontmatter keys at the top level and the rule in a
+	// content key; other files may start with a frontmatter block.
+	RuleExtensions []string `yaml:"rule_extensions,omitempty"`
+
+	// PermanentDelete makes deleting a rule remove it for good. Off by
+	// default: deleted rules are moved to the trash (see
+	// filemanager.TrashPath), where they can be restored by hand.
+	PermanentDelete bool `yaml:"permanent_delete,omitempty"`
+
+	// StartupAction is what rulem does when launched without a command once
+	// setup is complete: "menu" (the default) opens the main menu,
+	// "dashboard" opens the weekly summary, "sync" syncs every GitHub
+	// repository and exits, and "mcp" starts the MCP server. The --startup
+	// flag overrides it for one launch.
+	StartupAction string `yaml:"startup_action,omitempty"`
+
+	// DeployTargets are editors rules can be deployed for, next to the
+	// built-in ones (editors.EditorRuleConfigs). A target with the id of a
+	// built-in one replaces it, e.g. to keep the frontmatter of CLAUDE.md.
+	DeployTargets []DeployTarget `yaml:"deploy_targets,omitempty"`
+
+	// MCPProfiles tailor the MCP server to one client, by name: `rulem mcp
+	// --profile work` serves only the repositories, tags and file sizes of the
+	// "work" profile, so one installation can serve each editor its own tools.
+	MCPProfiles map[string]MCPProfile `yaml:"mcp_profiles,omitempty"`
+
+	// Project holds the settings of the .rulem.yaml of the project rulem was
+	// launched in, nil outside of one. It is read by Load and never saved.
+	Project *ProjectConfig `yaml:"-"`
+}
+
+// Rule file watch modes, see Config.WatchMode
+const (
+	WatchModeAuto   = "auto"
+	WatchModeNotify = "notify"
+	WatchModePoll   = "poll"
+)
+
+// Startup actions, see Config.StartupAction
+const (
+	StartupMenu      = "menu"
+	StartupDashboard = "dashboard"
+	StartupSync      = "sync"
+	StartupMCP       = "mcp"
+)
+
+// DefaultBundleMaxTokens is the get_all_rules budget when BundleMaxTokens is unset
+const DefaultBundleMaxTokens = 20000
+
+const (
+	// DefaultWatchPollInterval is the poll interval when WatchPollInterval is unset
+	DefaultWatchPollInterval = 2 * time.Second
+	// MinWatchPollInterval is the shortest poll interval accepted
+	MinWatchPollInterval = 100 * time.Millisecond
+)
+
+// SaveCollisionStrategy returns the parsed SaveCollision, CollisionAsk when unset
+func (c *Config) SaveCollisionStrategy() (fileops.CollisionStrategy, error) {
+	strategy, err := fileops.ParseCollisionStrategy(c.SaveCollision)
+	if err != nil {
+		return "", fmt.Errorf("invalid save_collision: %w", err)
+	}
+	return strategy, nil
+}
+
+// AutoSyncInterval returns the parsed SyncInterval, or zero when the
+// background sync is disabled
+func (c *Config) AutoSyncInterval() (time.Duration, error) {
+	if strings.TrimSpace(c.SyncInterval) == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(strings.TrimSpace(c.SyncInterval))
+	if err != nil {
+		return 0, fmt.Errorf("invalid sync_interval %q: %w", c.SyncInterval, err)
+	}
+	if interval < repository.MinSyncInterval {
+		return 0, fmt.Errorf("sync_interval %s is too short (minimum %s)", interval, repository.MinSyncInterval)
+	}
+	return interval, nil
+}
+
+// RuleWatchMode returns the validated WatchMode, WatchModeAuto when unset
+func (c *Config) RuleWatchMode() (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(c.WatchMode)); mode {
+	case "":
+		return WatchModeAuto, nil
+	case WatchModeAuto, WatchModeNotify, WatchModePoll:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid watch_mode %q: use auto, notify or poll", c.WatchMode)
+	}
+}
+
+// LaunchAction returns the validated StartupAction, StartupMenu when unset
+func (c *Config) LaunchAction() (string, error) {
+	action, err := ParseStartupAction(c.StartupAction)
+	if err != nil {
+		return "", fmt.Errorf("invalid startup_action: %w", err)
+	}
+	return action, nil
+}
+
+// ParseStartupAction validates a startup action, as set in startup_action or
+// with --startup; "" is StartupMenu
+func ParseStartupAction(value string) (string, error) {
+	switch action := strings.ToLower(strings.TrimSpace(value)); action {
+	case "":
+		return StartupMenu, nil
+	case StartupMenu, StartupDashboard, StartupSync, StartupMCP:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown startup action %q: use menu, dashboard, sync or mcp", value)
+	}
+}
+
+// RuleWatchPollInterval returns the parsed WatchPollInterval, or
+// DefaultWatchPollInterval when unset
+func (c *Config) RuleWatchPollInterval() (time.Duration, error) {
+	if strings.TrimSpace(c.WatchPollInterval) == "" {
+		return DefaultWatchPollInterval, nil
+	}
+	interval, err := time.ParseDuration(strings.TrimSpace(c.WatchPollInterval))
+	if err != nil {
+		return 0, fmt.Errorf("invalid watch_poll_interval %q: %w", c.WatchPollInterval, err)
+	}
+	if interval < MinWatchPollInterval {
+		return 0, fmt.Errorf("watch_poll_interval %s is too short (minimum %s)", interval, MinWatchPollInterval)
+	}
+	return interval, nil
+}
+
+// RuleBundleMaxTokens returns the validated BundleMaxTokens, or
+// DefaultBundleMaxTokens when unset
+func (c *Config) RuleBundleMaxTokens() (int, error) {
+	switch {
+	case c.BundleMaxTokens < 0:
+		return 0, fmt.Errorf("invalid bundle_max_tokens %d: it cannot be negative", c.BundleMaxTokens)
+	case c.BundleMaxTokens == 0:
+		return DefaultBundleMaxTokens, nil
+	}
+	return c.BundleMaxTokens, nil
+}
+
+// MCPToolPrefix returns the validated ToolPrefix
+func (c *Config) MCPToolPrefix() (string, error) {
+	prefix := strings.TrimSpace(c.ToolPrefix)
+	if err := repository.ValidateToolNamespace(prefix); err != nil {
+		return "", fmt.Errorf("invalid tool_prefix: %w", err)
+	}
+	return prefix, nil
+}
+
+// ruleExtensionPattern matches a file extension, with or without its dot
+var ruleExtensionPattern = regexp.MustCompile(`^\.?[A-Za-z0-9_+-]+$`)
+
+// RuleFileExtensions returns the validated RuleExtensions, or nil when unset
+func (c *Config) RuleFileExtensions() ([]string, error) {
+	if c.RuleExtensions == nil {
+		return nil, nil
+	}
+	extensions := make([]string, 0, len(c.RuleExtensions))
+	for _, ext := range c.RuleExtensions {
+		ext = strings.TrimSpace(ext)
+		if !ruleExtensionPattern.MatchString(ext) {
+			return nil, fmt.Errorf("invalid rule_extensions entry %q: use an extension such as .md", ext)
+		}
+		extensions = append(extensions, ext)
+	}
+	if len(extensions) == 0 {
+		return nil, fmt.Errorf("rule_extensions cannot be empty: remove it to scan markdown files")
+	}
+	return extensions, nil
+}
+
+// deployTargetIDPattern matches the id of a deploy target, e.g. "cursor"
+var deployTargetIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// DeployProfiles returns the editors rules can be deployed for: the built-in
+// ones, each replaced by the DeployTargets entry with its id, followed by the
+// other DeployTargets in order. The deploy targets of the project's
+// .rulem.yaml are merged into DeployTargets first.
+func (c *Config) DeployProfiles() ([]editors.EditorRuleConfig, error) {
+	targets := c.deployTargets()
+	custom := make(map[string]editors.EditorRuleConfig, len(targets))
+	var added []editors.EditorRuleConfig
+	for _, target := range targets {
+		profile, err := target.profile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid deploy_targets entry %q: %w", target.ID, err)
+		}
+		if _, dup := custom[profile.ID]; dup {
+			return nil, fmt.Errorf("invalid deploy_targets: id %q is used twice", profile.ID)
+		}
+		custom[profile.ID] = profile
+		added = append(added, profile)
+	}
+
+	builtins := editors.GetAllEditorRuleConfigs()
+	profiles := make([]editors.EditorRuleConfig, 0, len(builtins)+len(added))
+	for _, builtin := range builtins {
+		if profile, ok := custom[builtin.ID]; ok {
+			profiles = append(profiles, profile)
+			delete(custom, builtin.ID)
+			continue
+		}
+		profiles = append(profiles, builtin)
+	}
+	for _, profile := range added {
+		if _, ok := custom[profile.ID]; ok {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
+
+// DeployTarget is an editor rules can be deployed for.
+//
+// Path is the directory of its rule files relative to the project root, and
+// Filename their name: {name} stands for the rule's file name without its
+// extension ("{name}.mdc"), and a name without it deploys each rule to the
+// same file ("CONVENTIONS.md"). Frontmatter rewrites the frontmatter of copied
+// rules, see editors.FrontmatterRewrite, and VSCodeSnippets adds a VS Code
+// snippet referencing each deployed rule.
+type DeployTarget struct {
+	ID             string                     `yaml:"id"`
+	Name           string                     `yaml:"name,omitempty"`
+	Description    string                     `yaml:"description,omitempty"`
+	Path           string                     `yaml:"path"`
+	Filename       string                     `yaml:"filename"`
+	Frontmatter    editors.FrontmatterRewrite `yaml:"frontmatter,omitempty"`
+	VSCodeSnippets bool                       `yaml:"vscode_snippets,omitempty"`
+}
+
+// profile validates the target and converts it to an editor configuration
+func (t DeployTarget) profile() (editors.EditorRuleConfig, error) {
+	id := strings.TrimSpace(t.ID)
+	if !deployTargetIDPattern.MatchString(id) {
+		return editors.EditorRuleConfig{}, fmt.Errorf("id must be lowercase letters, digits, '-' or '_'")
+	}
+
+	dir := filepath.ToSlash(filepath.Clean(strings.TrimSpace(t.Path)))
+	if !filepath.IsLocal(dir) && dir != "." {
+		return editors.EditorRuleConfig{}, fmt.Errorf("path %q must be relative and stay inside the project", t.Path)
+	}
+
+	filename := strings.TrimSpace(t.Filename)
+	if filename == "" || strings.ContainsAny(filename, `/\`) || filename == "." || filename == ".." {
+		return editors.EditorRuleConfig{}, fmt.Errorf("filename %q must be a file name such as {name}.md", t.Filename)
+	}
+	if err := t.Frontmatter.Validate(); err != nil {
+		return editors.EditorRuleConfig{}, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+
+	profile := editors.EditorRuleConfig{
+		ID:             id,
+		Name:           t.Name,
+		Explanation:    t.Description,
+		RulePath:       dir + "/",
+		RenameOption:   editors.RenameOptionFull,
+		NewName:        filename,
+		Frontmatter:    t.Frontmatter,
+		VSCodeSnippets: t.VSCodeSnippets,
+	}
+	if strings.Contains(filename, editors.NamePlaceholder) {
+		profile.RenameOption = editors.RenameOptionTemplate
+	}
+	if profile.Name == "" {
+		profile.Name = id
+	}
+	if profile.Explanation == "" {
+		profile.Explanation = "Configured deploy target: " + profile.RulePath + filename
+	}
+	return profile, nil
+}
+
+// MCPProfile is what the MCP server serves with --profile. Repositories are
+// the names or IDs of the repositories served, all of them when empty; Tags
+// and Scope filter the rules like --tags and --scope; and MaxFileSize skips
+// rule files larger than it, in bytes, instead of the 5 MB default.
+type MCPProfile struct {
+	Repositories []string `yaml:"repositories,omitempty"`
+	Tags         []string `yaml:"tags,omitempty"`
+	Scope        []string `yaml:"scope,omitempty"`
+	MaxFileSize  int64    `yaml:"max_file_size,omitempty"`
+}
+
+// ResolveMCPProfile returns the MCPProfiles entry called name and the
+// repositories it serves, in the order they are configured
+func (c *Config) ResolveMCPProfile(name string) (MCPProfile, []repository.RepositoryEntry, error) {
+	profile, ok := c.MCPProfiles[name]
+	if !ok {
+		if len(c.MCPProfiles) == 0 {
+			return MCPProfile{}, nil, fmt.Errorf("unknown MCP profile %q: no mcp_profiles are configured", name)
+		}
+		names := slices.Sorted(maps.Keys(c.MCPProfiles))
+		return MCPProfile{}, nil, fmt.Errorf("unknown MCP profile %q: use one of %s", name, strings.Join(names, ", "))
+	}
+	if profile.MaxFileSize < 0 {
+		return MCPProfile{}, nil, fmt.Errorf("invalid max_file_size %d in MCP profile %q: it cannot be negative", profile.MaxFileSize, name)
+	}
+	if len(profile.Repositories) == 0 {
+		return profile, c.Repositories, nil
+	}
+
+	selected := make(map[string]bool, len(profile.Repositories))
+	for _, nameOrID := range profile.Repositories {
+		repo, err := c.FindRepositoryByID(nameOrID)
+		if err != nil {
+			if repo, err = c.FindRepositoryByName(nameOrID); err != nil {
+				return MCPProfile{}, nil, fmt.Errorf("MCP profile %q: %w", name, err)
+			}
+		}
+		selected[repo.ID] = true
+	}
+	var repositories []repository.RepositoryEntry
+	for _, repo := range c.Repositories {
+		if selected[repo.ID] {
+			repositories = append(repositories, repo)
+		}
+	}
+	return profile, repositories, nil
+}
+
+// FrontmatterDelimiter describes a frontmatter block recognised in rule files:
+// the line that opens it, the line that closes it, and the syntax of its contents
+// ("yaml", "toml" or "json"). Start "{" with End "}" and syntax "json" matches a
+// bare JSON object followed by a blank line.
+type FrontmatterDelimiter struct {
+	Start  string `yaml:"start"`
+	End    string `yaml:"end"`
+	Syntax string `yaml:"syntax"`
+}
+
+// Redaction replaces every match of a regular expression (Go RE2 syntax) in
+// exported rules and their paths. Replace may refer to capture groups as $1;
+// when empty, matches are replaced with "[REDACTED]".
+type Redaction struct {
+	Pattern string `yaml:"pattern"`
+	Replace string `yaml:"replace,omitempty"`
+}
+
+// Hook runs Command or posts to URL when Event happens. Exactly one of
+// Command and URL is set.
+type Hook struct {
+	Event   string `yaml:"event"`
+	Command string `yaml:"command,omitempty"`
+	URL     string `yaml:"url,omitempty"`
 }
 
 // Path returns the standard config file paths for the current platform
@@ -108,7 +549,10 @@ func Path() (string, error) {
 //
 // This is the primary entry point for loading configuration at application startup.
 // It automatically determines the correct config file path based on the platform
-// and XDG Base Directory specification.
+// and XDG Base Directory specification. A config written by an older version
+// is upgraded to CurrentVersion and saved, after a backup of the file (see
+// migrate.go), and the .rulem.yaml of the current project is attached as
+// Project (see project.go).
 //
 // Returns:
 //   - *Config: The loaded configuration if successful
@@ -120,26 +564,68 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("no configuration found, first-time setup required")
 	}
 
-	return LoadFrom(configPath)
+	cfg, legacy, data, err := readConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Upgrade configs written by older versions once, keeping the previous
+	// file, so later loads see the current format
+	report, err := migrate(cfg, legacy)
+	if err != nil {
+		return nil, err
+	}
+	if report.Migrated() {
+		backup, err := backupConfig(configPath, data, report.From)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.SaveTo(configPath); err != nil {
+			return nil, fmt.Errorf("failed to save migrated config: %w", err)
+		}
+		logging.Warn("Migrated config", "summary", report.String(), "backup", backup)
+		// Only once the new IDs are saved can what is kept under the old
+		// ones follow them
+		cfg.migrateRepositoryState()
+	}
+
+	// Merge in the settings of the project rulem was launched in
+	if err := cfg.loadProject(); err != nil {
+		return nil, err
+	}
+	if cfg.Project != nil {
+		logging.Info("Using project config", "path", cfg.Project.Path)
+	}
+
+	return cfg, nil
 }
 
 // LoadFrom loads config from a specific path
 func LoadFrom(path string) (*Config, error) {
+	cfg, _, _, err := readConfigFile(path)
+	return cfg, err
+}
+
+// readConfigFile decodes the config file at path, along with the settings of
+// older versions that migrate needs, and returns its raw content for a backup
+func readConfigFile(path string) (*Config, legacyFields, []byte, error) {
 	logging.Info("Reading config file from: ", "path", path)
-	f, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open config file: %w", err)
+		return nil, legacyFields{}, nil, fmt.Errorf("failed to open config file: %w", err)
 	}
-	defer f.Close()
 
 	var cfg Config
+	var legacy legacyFields
 	logging.Info("Decoding config file")
-	dec := yaml.NewDecoder(f)
-	if err := dec.Decode(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&cfg); err != nil {
+		return nil, legacyFields{}, nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &legacy); err != nil {
+		return nil, legacyFields{}, nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	return &cfg, nil
+	return &cfg, legacy, data, nil
 }
 
 // FindConfigFile returns the path to an existing config file, and whether it exists.
@@ -174,7 +660,7 @@ func IsFirstRun() bool {
 //   - Config: A configuration struct with default values set
 func DefaultConfig() Config {
 	cfg := Config{
-		Version:      "1.0",
+		Version:      CurrentVersion,
 		InitTime:     0,                              // Will be set during first save
 		Repositories: []repository.RepositoryEntry{}, // Empty array - repositories added through setup
 	}
@@ -196,6 +682,9 @@ func (c *Config) FindRepositoryByID(id string) (*repository.RepositoryEntry, err
 			return &c.Repositories[i], nil
 		}
 	}
+	if alias, ok := c.RepositoryIDAliases[id]; ok && alias != id {
+		return c.FindRepositoryByID(alias)
+	}
 	return nil, fmt.Errorf("repository not found: %s", id)
 }
 
@@ -218,13 +707,37 @@ func (c *Config) FindRepositoryByName(name string) (*repository.RepositoryEntry,
 	return nil, fmt.Errorf("repository not found: %s", name)
 }
 
+// ApplyRelocation points each moved repository at its new path and saves the
+// config. On a failed save the previous paths are restored in memory.
+func (c *Config) ApplyRelocation(moves []repository.RelocationMove) error {
+	previous := make([]string, len(c.Repositories))
+	for i := range c.Repositories {
+		previous[i] = c.Repositories[i].Path
+		for _, move := range moves {
+			if move.ID == c.Repositories[i].ID {
+				c.Repositories[i].Path = move.To
+			}
+		}
+	}
+
+	if err := c.Save(); err != nil {
+		for i := range c.Repositories {
+			c.Repositories[i].Path = previous[i]
+		}
+		return fmt.Errorf("failed to save relocated paths: %w", err)
+	}
+	return nil
+}
+
 // Save writes the config to the standard location
 func (c *Config) Save() error {
 	configPath, _ := FindConfigFile()
 	return c.SaveTo(configPath)
 }
 
-// SaveTo writes the config to a specific path
+// SaveTo writes the config to a specific path. The file is written next to
+// path and renamed over it, so a failed save never leaves a truncated config.
+// Saves by other rulem processes wait for it, see fileops.Lock.
 func (c *Config) SaveTo(path string) error {
 	// Set init time if this is the first save
 	if c.InitTime == 0 {
@@ -238,20 +751,36 @@ func (c *Config) SaveTo(path string) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Create file with restrictive permissions (600) for security
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	lock, err := fileops.Lock(path, fileops.DefaultLockTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to lock config file: %w", err)
+	}
+	defer lock.Unlock()
+
+	// CreateTemp uses restrictive permissions (600) for security
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
 	if err != nil {
 		return fmt.Errorf("failed to create config file: %w", err)
 	}
-	defer f.Close()
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath)
 
 	enc := yaml.NewEncoder(f)
-	defer enc.Close()
-
 	if err := enc.Encode(c); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := f.Close(); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace config file: %w", err)
+	}
 	return nil
 }
 
@@ -274,31 +803,6 @@ func ReloadConfig() tea.Cmd {
 	}
 }
 
-// GenerateRepositoryID generates a unique, human-readable identifier for a repository.
-// The ID format is "sanitized-name-timestamp" (e.g., "personal-rules-1728756432").
-//
-// This design provides several benefits:
-//   - Human-readable: Contains the repository name for debugging
-//   - Guaranteed uniqueness: Timestamp ensures no collisions even with same name
-//   - Sortable: Natural chronological ordering
-//   - Config-friendly: Short enough for YAML readability
-//
-// Parameters:
-//   - name: The repository display name to sanitize
-//   - timestamp: Unix timestamp (typically time.Now().Unix())
-//
-// Returns:
-//   - string: Unique repository ID in format "sanitized-name-timestamp"
-//
-// Example:
-//
-//	id := GenerateRepositoryID("Personal Rules", 1728756432)
-//	// Returns: "personal-rules-1728756432"
-func GenerateRepositoryID(name string, timestamp int64) string {
-	sanitized := sanitizeNameForID(name)
-	return fmt.Sprintf("%s-%d", sanitized, timestamp)
-}
-
 // sanitizeNameForID converts a repository name to a safe, URL-friendly format for ID generation.
 // This function implements the original sanitization logic that was previously in the repository package.
 //
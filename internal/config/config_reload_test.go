@@ -85,15 +85,16 @@ func TestReloadConfig(t *testing.T) {
 		t.Errorf("Expected central path '%s', got '%s'", initialCentralPath, reloadMsg.Config.Repositories[0].Path)
 	}
 
-	if reloadMsg.Config.Version != "1.0" {
-		t.Errorf("Expected Version '1.0', got '%s'", reloadMsg.Config.Version)
+	// 1.0 configs are migrated to the current version on load
+	if reloadMsg.Config.Version != CurrentVersion {
+		t.Errorf("Expected Version '%s', got '%s'", CurrentVersion, reloadMsg.Config.Version)
 	}
 
 	// Test reload after config modification
 	// Modify config on disk
 	modifiedCentralPath := filepath.Join(homeDir, "test-rulem-modified")
 	modifiedConfig := Config{
-		Version:  "1.1",
+		Version:  CurrentVersion,
 		InitTime: initialConfig.InitTime,
 		Repositories: []repository.RepositoryEntry{
 			{
@@ -129,8 +130,8 @@ func TestReloadConfig(t *testing.T) {
 		t.Errorf("Expected modified central path '%s', got '%s'", modifiedCentralPath, reloadMsg2.Config.Repositories[0].Path)
 	}
 
-	if reloadMsg2.Config.Version != "1.1" {
-		t.Errorf("Expected modified Version '1.1', got '%s'", reloadMsg2.Config.Version)
+	if reloadMsg2.Config.Version != CurrentVersion {
+		t.Errorf("Expected modified Version '%s', got '%s'", CurrentVersion, reloadMsg2.Config.Version)
 	}
 }
 
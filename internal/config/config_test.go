@@ -5,7 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"rulem/internal/editors"
 	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -253,6 +256,75 @@ func TestConfigFilePermissions(t *testing.T) {
 	}
 }
 
+func TestConfigSaveToLeavesNoTempFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+
+	config := DefaultConfig()
+	for i := 0; i < 2; i++ {
+		if err := config.SaveTo(configPath); err != nil {
+			t.Fatalf("Failed to save config: %s", err)
+		}
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to read config directory: %s", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "config.yaml" {
+		t.Errorf("Expected only config.yaml, got %v", entries)
+	}
+}
+
+func TestApplyRelocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		blockSave bool
+		wantPaths []string
+	}{
+		{name: "moves paths", wantPaths: []string{"/new/personal", "/old/team"}},
+		{name: "failed save restores paths", blockSave: true, wantPaths: []string{"/old/personal", "/old/team"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			t.Setenv("RULEM_CONFIG_PATH", configPath)
+			if tt.blockSave {
+				// A directory in place of the config file makes the rename fail
+				if err := os.MkdirAll(filepath.Join(configPath, "blocked"), 0755); err != nil {
+					t.Fatalf("Failed to block config path: %s", err)
+				}
+			}
+
+			cfg := Config{Repositories: []repository.RepositoryEntry{
+				{ID: "personal-1", Name: "Personal", Type: repository.RepositoryTypeLocal, Path: "/old/personal"},
+				{ID: "team-2", Name: "Team", Type: repository.RepositoryTypeLocal, Path: "/old/team"},
+			}}
+			err := cfg.ApplyRelocation([]repository.RelocationMove{{ID: "personal-1", From: "/old/personal", To: "/new/personal"}})
+			if (err != nil) != tt.blockSave {
+				t.Fatalf("ApplyRelocation() error = %v, want error %v", err, tt.blockSave)
+			}
+			for i, want := range tt.wantPaths {
+				if cfg.Repositories[i].Path != want {
+					t.Errorf("repository %d path = %s, want %s", i, cfg.Repositories[i].Path, want)
+				}
+			}
+			if tt.blockSave {
+				return
+			}
+
+			saved, err := LoadFrom(configPath)
+			if err != nil {
+				t.Fatalf("Failed to load saved config: %s", err)
+			}
+			if saved.Repositories[0].Path != "/new/personal" {
+				t.Errorf("saved path = %s, want /new/personal", saved.Repositories[0].Path)
+			}
+		})
+	}
+}
+
 func TestFindRepositoryByID(t *testing.T) {
 	t.Log("Testing FindRepositoryByID")
 
@@ -380,6 +452,257 @@ func TestDefaultConfig(t *testing.T) {
 	}
 }
 
+func TestAutoSyncInterval(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"30m", 30 * time.Minute, false},
+		{" 2h ", 2 * time.Hour, false},
+		{"10s", 0, true},
+		{"hourly", 0, true},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{SyncInterval: tt.value}
+		got, err := cfg.AutoSyncInterval()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("AutoSyncInterval(%q) = %v, %v; want %v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRuleWatchSettings(t *testing.T) {
+	modes := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", WatchModeAuto, false},
+		{"Poll", WatchModePoll, false},
+		{"notify", WatchModeNotify, false},
+		{"inotify", "", true},
+	}
+	for _, tt := range modes {
+		cfg := &Config{WatchMode: tt.value}
+		got, err := cfg.RuleWatchMode()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("RuleWatchMode(%q) = %q, %v; want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	intervals := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", DefaultWatchPollInterval, false},
+		{"5s", 5 * time.Second, false},
+		{"10ms", 0, true},
+		{"often", 0, true},
+	}
+	for _, tt := range intervals {
+		cfg := &Config{WatchPollInterval: tt.value}
+		got, err := cfg.RuleWatchPollInterval()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("RuleWatchPollInterval(%q) = %v, %v; want %v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMCPToolPrefix(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{" rules_ ", "rules_", false},
+		{"team-rules.", "", true},
+		{"a-prefix-far-too-long-for-any-tool-name_", "", true},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{ToolPrefix: tt.value}
+		got, err := cfg.MCPToolPrefix()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("MCPToolPrefix(%q) = %q, %v; want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRuleBundleMaxTokens(t *testing.T) {
+	tests := []struct {
+		value   int
+		want    int
+		wantErr bool
+	}{
+		{0, DefaultBundleMaxTokens, false},
+		{4000, 4000, false},
+		{-1, 0, true},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{BundleMaxTokens: tt.value}
+		got, err := cfg.RuleBundleMaxTokens()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("RuleBundleMaxTokens(%d) = %d, %v; want %d, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRuleFileExtensions(t *testing.T) {
+	tests := []struct {
+		value   []string
+		want    []string
+		wantErr bool
+	}{
+		{nil, nil, false},
+		{[]string{".md", " txt ", ".YAML"}, []string{".md", "txt", ".YAML"}, false},
+		{[]string{}, nil, true},
+		{[]string{".md", ""}, nil, true},
+		{[]string{"*.md"}, nil, true},
+		{[]string{"rules/.md"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{RuleExtensions: tt.value}
+		got, err := cfg.RuleFileExtensions()
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("RuleFileExtensions(%q) = %q, %v; want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSaveCollisionStrategy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    fileops.CollisionStrategy
+		wantErr bool
+	}{
+		{"", fileops.CollisionAsk, false},
+		{"rename", fileops.CollisionRename, false},
+		{"overwrite", fileops.CollisionOverwrite, false},
+		{"skip", "", true},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{SaveCollision: tt.value}
+		got, err := cfg.SaveCollisionStrategy()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("SaveCollisionStrategy(%q) = %v, %v; want %v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLaunchAction(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", StartupMenu, false},
+		{"dashboard", StartupDashboard, false},
+		{" Sync ", StartupSync, false},
+		{"mcp", StartupMCP, false},
+		{"serve", "", true},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{StartupAction: tt.value}
+		got, err := cfg.LaunchAction()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("LaunchAction(%q) = %v, %v; want %v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestResolveMCPProfile(t *testing.T) {
+	cfg := Config{
+		Repositories: []repository.RepositoryEntry{
+			{ID: "work-1", Name: "Work Rules"},
+			{ID: "home-2", Name: "Home Rules"},
+			{ID: "team-3", Name: "Team Rules"},
+		},
+		MCPProfiles: map[string]MCPProfile{
+			"work":  {Repositories: []string{"team-3", "work rules"}, Tags: []string{"go"}, MaxFileSize: 65536},
+			"all":   {},
+			"wrong": {Repositories: []string{"missing"}},
+			"huge":  {MaxFileSize: -1},
+		},
+	}
+
+	profile, repos, err := cfg.ResolveMCPProfile("work")
+	if err != nil {
+		t.Fatalf("ResolveMCPProfile(work): %v", err)
+	}
+	if len(repos) != 2 || repos[0].ID != "work-1" || repos[1].ID != "team-3" {
+		t.Errorf("work repositories = %+v, want work-1 and team-3 in config order", repos)
+	}
+	if profile.MaxFileSize != 65536 || len(profile.Tags) != 1 {
+		t.Errorf("work profile = %+v", profile)
+	}
+
+	if _, repos, err := cfg.ResolveMCPProfile("all"); err != nil || len(repos) != 3 {
+		t.Errorf("a profile without repositories should serve all of them, got %+v, %v", repos, err)
+	}
+	for _, name := range []string{"wrong", "huge"} {
+		if _, _, err := cfg.ResolveMCPProfile(name); err == nil {
+			t.Errorf("ResolveMCPProfile(%s) should fail", name)
+		}
+	}
+	if _, _, err := cfg.ResolveMCPProfile("play"); err == nil || !strings.Contains(err.Error(), "all, huge, work, wrong") {
+		t.Errorf("unknown profile error = %v, want the profiles listed", err)
+	}
+}
+
+func TestDeployProfiles(t *testing.T) {
+	cfg := Config{DeployTargets: []DeployTarget{
+		{ID: "claude", Name: "Claude code", Path: ".", Filename: "CLAUDE.md"},
+		{ID: "aider", Path: "docs/", Filename: "CONVENTIONS.md", Frontmatter: editors.FrontmatterRewrite{Strip: true}},
+		{ID: "cline", Path: ".clinerules", Filename: "{name}.md"},
+	}}
+	profiles, err := cfg.DeployProfiles()
+	if err != nil {
+		t.Fatalf("DeployProfiles failed: %v", err)
+	}
+	builtins := editors.GetAllEditorRuleConfigs()
+	if len(profiles) != len(builtins)+2 {
+		t.Fatalf("got %d profiles, want the built-in ones and two more", len(profiles))
+	}
+
+	claude, err := editors.FindEditorRuleConfig(profiles, "claude")
+	if err != nil || !claude.Frontmatter.IsZero() || claude.GenerateRuleFileFullPath("go.md") != "./CLAUDE.md" {
+		t.Errorf("claude = %+v, %v; want the built-in replaced", claude, err)
+	}
+	if got := profiles[len(profiles)-2].GenerateRuleFileFullPath("go.md"); got != "docs/CONVENTIONS.md" {
+		t.Errorf("aider deploys to %q, want docs/CONVENTIONS.md", got)
+	}
+	if got := profiles[len(profiles)-1].GenerateRuleFileFullPath("go-style.md"); got != ".clinerules/go-style.md" {
+		t.Errorf("cline deploys to %q, want .clinerules/go-style.md", got)
+	}
+
+	invalid := []DeployTarget{
+		{ID: "Bad ID", Path: ".", Filename: "x.md"},
+		{ID: "up", Path: "../rules", Filename: "{name}.md"},
+		{ID: "abs", Path: "/etc", Filename: "{name}.md"},
+		{ID: "nested", Path: ".", Filename: "rules/{name}.md"},
+		{ID: "set", Path: ".", Filename: "{name}.md", Frontmatter: editors.FrontmatterRewrite{Set: map[string]string{"globs": "[oops"}}},
+	}
+	for _, target := range invalid {
+		cfg := Config{DeployTargets: []DeployTarget{target}}
+		if _, err := cfg.DeployProfiles(); err == nil {
+			t.Errorf("DeployProfiles(%+v) should fail", target)
+		}
+	}
+	dup := Config{DeployTargets: []DeployTarget{{ID: "x", Path: ".", Filename: "a.md"}, {ID: "x", Path: ".", Filename: "b.md"}}}
+	if _, err := dup.DeployProfiles(); err == nil || !strings.Contains(err.Error(), "used twice") {
+		t.Errorf("DeployProfiles() error = %v, want a duplicate id rejected", err)
+	}
+}
+
 func TestConfigPathEnvironmentOverride(t *testing.T) {
 	t.Log("Testing ConfigPath environment variable override")
 
@@ -462,152 +785,6 @@ func TestConfigErrorHandling(t *testing.T) {
 
 // ID Generation Tests
 
-func TestGenerateRepositoryID(t *testing.T) {
-	t.Log("Testing GenerateRepositoryID with various inputs")
-
-	tests := []struct {
-		name           string
-		repoName       string
-		timestamp      int64
-		expectedPrefix string // Expected sanitized name part
-	}{
-		{
-			name:           "simple name",
-			repoName:       "personal",
-			timestamp:      1728756432,
-			expectedPrefix: "personal",
-		},
-		{
-			name:           "name with spaces",
-			repoName:       "Personal Rules",
-			timestamp:      1728756432,
-			expectedPrefix: "personal-rules",
-		},
-		{
-			name:           "name with underscores",
-			repoName:       "work_project",
-			timestamp:      1728756500,
-			expectedPrefix: "work-project",
-		},
-		{
-			name:           "name with special characters",
-			repoName:       "My@Project#123!",
-			timestamp:      1728756600,
-			expectedPrefix: "my-project-123",
-		},
-		{
-			name:           "name with multiple consecutive special chars",
-			repoName:       "test___project",
-			timestamp:      1728756700,
-			expectedPrefix: "test-project",
-		},
-		{
-			name:           "name with leading/trailing spaces",
-			repoName:       "  Project Name  ",
-			timestamp:      1728756800,
-			expectedPrefix: "project-name",
-		},
-		{
-			name:           "name with unicode characters",
-			repoName:       "Проект-Rules",
-			timestamp:      1728756900,
-			expectedPrefix: "rules", // Unicode chars removed, dash removed, left with "rules"
-		},
-		{
-			name:           "empty name",
-			repoName:       "",
-			timestamp:      1728757000,
-			expectedPrefix: "repo",
-		},
-		{
-			name:           "all special characters",
-			repoName:       "!!!@@@###",
-			timestamp:      1728757100,
-			expectedPrefix: "repo",
-		},
-		{
-			name:           "numbers only",
-			repoName:       "12345",
-			timestamp:      1728757200,
-			expectedPrefix: "12345",
-		},
-		{
-			name:           "mixed case with numbers",
-			repoName:       "Project2024",
-			timestamp:      1728757300,
-			expectedPrefix: "project2024",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			id := GenerateRepositoryID(tt.repoName, tt.timestamp)
-
-			// Verify format: sanitized-name-timestamp
-			pattern := regexp.MustCompile(`^[a-z0-9-]+-\d+$`)
-			if !pattern.MatchString(id) {
-				t.Errorf("ID %q doesn't match expected format ^[a-z0-9-]+-\\d+$", id)
-			}
-
-			// Verify the ID contains the timestamp
-			timestampStr := formatTimestamp(tt.timestamp)
-			if !contains(id, timestampStr) {
-				t.Errorf("ID %q should contain timestamp %s", id, timestampStr)
-			}
-
-			// Verify the ID starts with the expected prefix
-			if !startsWith(id, tt.expectedPrefix+"-") {
-				t.Errorf("ID %q should start with %q", id, tt.expectedPrefix+"-")
-			}
-		})
-	}
-}
-
-func TestGenerateRepositoryID_Uniqueness(t *testing.T) {
-	t.Log("Testing ID uniqueness with same name but different timestamps")
-
-	name := "Test Repository"
-	timestamp1 := int64(1728756432)
-	timestamp2 := int64(1728756500)
-
-	id1 := GenerateRepositoryID(name, timestamp1)
-	id2 := GenerateRepositoryID(name, timestamp2)
-
-	if id1 == id2 {
-		t.Errorf("Expected different IDs for different timestamps, got: %s and %s", id1, id2)
-	}
-
-	// Both should have the same prefix
-	expectedPrefix := "test-repository-"
-	if !startsWith(id1, expectedPrefix) {
-		t.Errorf("ID1 %q should start with %q", id1, expectedPrefix)
-	}
-	if !startsWith(id2, expectedPrefix) {
-		t.Errorf("ID2 %q should start with %q", id2, expectedPrefix)
-	}
-}
-
-func TestGenerateRepositoryID_CurrentTimestamp(t *testing.T) {
-	t.Log("Testing ID generation with current timestamp")
-
-	name := "Real Time Test"
-	timestamp := time.Now().Unix()
-
-	id := GenerateRepositoryID(name, timestamp)
-
-	// Verify format
-	pattern := regexp.MustCompile(`^[a-z0-9-]+-\d+$`)
-	if !pattern.MatchString(id) {
-		t.Errorf("ID %q doesn't match expected format", id)
-	}
-
-	// Verify it contains current timestamp
-	timestampStr := formatTimestamp(timestamp)
-	if !contains(id, timestampStr) {
-		t.Errorf("ID %q should contain timestamp %s", id, timestampStr)
-	}
-}
-
 func TestSanitizeNameForID(t *testing.T) {
 	t.Log("Testing sanitizeNameForID helper function")
 
@@ -790,47 +967,8 @@ func TestSanitizeNameForID_EdgeCases(t *testing.T) {
 	}
 }
 
-func TestIDFormatValidation(t *testing.T) {
-	t.Log("Testing that generated IDs match expected format")
-
-	// Expected format: ^[a-z0-9-]+-\d+$
-	pattern := regexp.MustCompile(`^[a-z0-9-]+-\d+$`)
-
-	testCases := []string{
-		"Personal Rules",
-		"Work Project 2024",
-		"test-repo",
-		"MyProject@123",
-		"!!!@@@",
-		"",
-		"12345",
-	}
-
-	timestamp := int64(1728756432)
-
-	for _, name := range testCases {
-		t.Run("format_validation_"+name, func(t *testing.T) {
-			id := GenerateRepositoryID(name, timestamp)
-
-			if !pattern.MatchString(id) {
-				t.Errorf("ID %q doesn't match expected format ^[a-z0-9-]+-\\d+$", id)
-			}
-
-			// Verify structure: prefix-timestamp
-			parts := regexp.MustCompile(`-\d+$`).Split(id, -1)
-			if len(parts) < 1 {
-				t.Errorf("ID %q should have prefix and timestamp parts", id)
-			}
-		})
-	}
-}
-
 // Helper functions for ID generation tests
 
-func formatTimestamp(ts int64) string {
-	return regexp.MustCompile(`\d+`).FindString(GenerateRepositoryID("test", ts))
-}
-
 func contains(s, substr string) bool {
 	return regexp.MustCompile(regexp.QuoteMeta(substr)).MatchString(s)
 }
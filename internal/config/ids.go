@@ -0,0 +1,191 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+
+	"rulem/internal/logging"
+	"rulem/internal/notes"
+	"rulem/internal/quarantine"
+	"rulem/internal/repository"
+)
+
+// IDGenerator creates identifiers for new repository entries.
+//
+// IDs have the format "sanitized-name-xxxxxxxx" where the suffix is 8 lowercase
+// hex characters (e.g., "personal-rules-3f9a0c12"). Unlike the former
+// "sanitized-name-timestamp" format, the suffix carries no meaning, so IDs stay
+// stable when configs are copied between machines and do not depend on the clock.
+//
+// Production code uses DefaultIDGenerator; tests inject a SequentialIDGenerator
+// so generated IDs are deterministic.
+type IDGenerator interface {
+	NewRepositoryID(name string) string
+}
+
+// DefaultIDGenerator returns the generator used outside of tests, which draws
+// ID suffixes from crypto/rand
+func DefaultIDGenerator() IDGenerator {
+	return RandomIDGenerator{}
+}
+
+// RandomIDGenerator generates IDs with random suffixes read from Rand,
+// or from crypto/rand when Rand is nil
+type RandomIDGenerator struct {
+	Rand io.Reader
+}
+
+// NewRepositoryID returns a new ID for a repository with the given name
+func (g RandomIDGenerator) NewRepositoryID(name string) string {
+	r := g.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms; a zero suffix is
+		// still a valid ID and duplicates are caught by config validation
+		logging.Error("Failed to read random ID suffix", "error", err)
+	}
+	return FormatRepositoryID(name, binary.BigEndian.Uint32(b[:]))
+}
+
+// SequentialIDGenerator generates IDs with suffixes 00000001, 00000002, ...
+// It is meant for tests that need predictable IDs.
+type SequentialIDGenerator struct {
+	mu   sync.Mutex
+	next uint32
+}
+
+// NewRepositoryID returns the next ID in the sequence
+func (g *SequentialIDGenerator) NewRepositoryID(name string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.next++
+	return FormatRepositoryID(name, g.next)
+}
+
+// FormatRepositoryID builds a repository ID from a display name and a suffix.
+//
+// Example:
+//
+//	id := FormatRepositoryID("Personal Rules", 0x3f9a0c12)
+//	// Returns: "personal-rules-3f9a0c12"
+func FormatRepositoryID(name string, suffix uint32) string {
+	return fmt.Sprintf("%s-%08x", sanitizeNameForID(name), suffix)
+}
+
+// legacyIDPattern matches IDs generated before version 1.1: a sanitized name
+// followed by a Unix timestamp in seconds (e.g., "personal-rules-1728756432")
+var legacyIDPattern = regexp.MustCompile(`^([a-z0-9-]+)-(\d{9,})$`)
+
+// IsLegacyRepositoryID reports whether id uses the old "sanitized-name-timestamp" format
+func IsLegacyRepositoryID(id string) bool {
+	return legacyIDPattern.MatchString(id)
+}
+
+// MigrateRepositoryID converts a legacy timestamp ID to the current format.
+// The suffix is derived from a hash of the legacy ID, so the same config
+// always migrates to the same IDs. IDs already in the current format are
+// returned unchanged.
+//
+// Example:
+//
+//	MigrateRepositoryID("personal-rules-1728756432") // "personal-rules-<hash>"
+func MigrateRepositoryID(id string) string {
+	match := legacyIDPattern.FindStringSubmatch(id)
+	if match == nil {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	return FormatRepositoryID(match[1], binary.BigEndian.Uint32(sum[:4]))
+}
+
+// MigrateRepositoryIDs rewrites legacy timestamp IDs to the current format and
+// bumps the config version to 1.1. It returns the number of IDs rewritten; callers
+// save the config when it is non-zero.
+//
+// Each rewrite is recorded in RepositoryIDAliases. rulem keeps some state
+// about repositories outside the config under their ID (notes on their rules,
+// quarantine approvals and their own tokens); Load moves it to the new IDs
+// once the migrated config is saved, and FindRepositoryByID still finds a
+// repository by its old ID. An ID whose migrated form is already taken (by
+// another entry) is left unchanged rather than creating a duplicate.
+func (c *Config) MigrateRepositoryIDs() int {
+	taken := make(map[string]bool, len(c.Repositories))
+	for _, repo := range c.Repositories {
+		taken[repo.ID] = true
+	}
+
+	migrated := 0
+	for i := range c.Repositories {
+		oldID := c.Repositories[i].ID
+		newID := MigrateRepositoryID(oldID)
+		if newID == oldID || taken[newID] {
+			continue
+		}
+		logging.Info("Migrating repository ID", "old_id", oldID, "new_id", newID)
+		c.Repositories[i].ID = newID
+		if c.RepositoryIDAliases == nil {
+			c.RepositoryIDAliases = make(map[string]string)
+		}
+		c.RepositoryIDAliases[oldID] = newID
+		delete(taken, oldID)
+		taken[newID] = true
+		migrated++
+	}
+
+	if migrated > 0 || c.Version == "" || c.Version == "1.0" {
+		c.Version = "1.1"
+	}
+	return migrated
+}
+
+// migrateRepositoryState moves the notes, quarantine state and tokens kept
+// under the old IDs in RepositoryIDAliases to the new ones. State already kept
+// under a new ID wins. A store that cannot be updated is logged and left as it
+// is; its state for those repositories is then lost, but rulem keeps working.
+func (c *Config) migrateRepositoryState() {
+	if len(c.RepositoryIDAliases) == 0 {
+		return
+	}
+
+	if store, err := notes.Load(notes.Path()); err != nil {
+		logging.Warn("Failed to move notes to migrated repository IDs", "error", err)
+	} else if renameAll(c.RepositoryIDAliases, store.RenameRepository) {
+		if err := store.Save(); err != nil {
+			logging.Warn("Failed to move notes to migrated repository IDs", "error", err)
+		}
+	}
+
+	if store, err := quarantine.Load(quarantine.Path()); err != nil {
+		logging.Warn("Failed to move quarantine state to migrated repository IDs", "error", err)
+	} else if renameAll(c.RepositoryIDAliases, store.RenameRepository) {
+		if err := store.Save(); err != nil {
+			logging.Warn("Failed to move quarantine state to migrated repository IDs", "error", err)
+		}
+	}
+
+	credManager := repository.NewCredentialManager()
+	for oldID, newID := range c.RepositoryIDAliases {
+		if _, err := credManager.RenameRepositoryToken(oldID, newID); err != nil {
+			logging.Warn("Failed to move repository token to migrated ID", "old_id", oldID, "new_id", newID, "error", err)
+		}
+	}
+}
+
+// renameAll calls rename for every alias and reports whether any changed
+func renameAll(aliases map[string]string, rename func(oldID, newID string) bool) bool {
+	changed := false
+	for oldID, newID := range aliases {
+		if rename(oldID, newID) {
+			changed = true
+		}
+	}
+	return changed
+}
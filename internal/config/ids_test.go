@@ -0,0 +1,257 @@
+package config
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"rulem/internal/notes"
+	"rulem/internal/quarantine"
+	"rulem/internal/repository"
+)
+
+var idPattern = regexp.MustCompile(`^[a-z0-9-]+-[0-9a-f]{8}$`)
+
+func TestFormatRepositoryID(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoName string
+		suffix   uint32
+		want     string
+	}{
+		{name: "simple name", repoName: "personal", suffix: 0x3f9a0c12, want: "personal-3f9a0c12"},
+		{name: "name with spaces", repoName: "Personal Rules", suffix: 1, want: "personal-rules-00000001"},
+		{name: "name with underscores", repoName: "work_project", suffix: 0xff, want: "work-project-000000ff"},
+		{name: "name with special characters", repoName: "My@Project#123!", suffix: 0, want: "my-project-123-00000000"},
+		{name: "name with leading/trailing spaces", repoName: "  Project Name  ", suffix: 2, want: "project-name-00000002"},
+		{name: "name with unicode characters", repoName: "Проект-Rules", suffix: 3, want: "rules-00000003"},
+		{name: "empty name", repoName: "", suffix: 4, want: "repo-00000004"},
+		{name: "numbers only", repoName: "12345", suffix: 0xffffffff, want: "12345-ffffffff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatRepositoryID(tt.repoName, tt.suffix)
+			if got != tt.want {
+				t.Errorf("FormatRepositoryID(%q, %#x) = %q, want %q", tt.repoName, tt.suffix, got, tt.want)
+			}
+			if !idPattern.MatchString(got) {
+				t.Errorf("ID %q doesn't match %s", got, idPattern)
+			}
+			if IsLegacyRepositoryID(got) {
+				t.Errorf("ID %q should not be detected as legacy", got)
+			}
+		})
+	}
+}
+
+func TestRandomIDGenerator(t *testing.T) {
+	t.Run("reads suffix from Rand", func(t *testing.T) {
+		gen := RandomIDGenerator{Rand: bytes.NewReader([]byte{0xde, 0xad, 0xbe, 0xef})}
+		if got := gen.NewRepositoryID("Team Rules"); got != "team-rules-deadbeef" {
+			t.Errorf("NewRepositoryID = %q, want %q", got, "team-rules-deadbeef")
+		}
+	})
+
+	t.Run("default generator produces distinct valid IDs", func(t *testing.T) {
+		gen := DefaultIDGenerator()
+		seen := make(map[string]bool)
+		for range 50 {
+			id := gen.NewRepositoryID("Personal Rules")
+			if !idPattern.MatchString(id) {
+				t.Fatalf("ID %q doesn't match %s", id, idPattern)
+			}
+			if seen[id] {
+				t.Fatalf("duplicate ID %q", id)
+			}
+			seen[id] = true
+		}
+	})
+}
+
+func TestSequentialIDGenerator(t *testing.T) {
+	gen := &SequentialIDGenerator{}
+	want := []string{"a-00000001", "b-00000002", "a-00000003"}
+	for i, name := range []string{"a", "b", "a"} {
+		if got := gen.NewRepositoryID(name); got != want[i] {
+			t.Errorf("NewRepositoryID(%q) = %q, want %q", name, got, want[i])
+		}
+	}
+}
+
+func TestMigrateRepositoryID(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantLegacy bool
+	}{
+		{name: "legacy timestamp ID", id: "personal-rules-1728756432", wantLegacy: true},
+		{name: "legacy ID with numeric name", id: "project-2024-1728756432", wantLegacy: true},
+		{name: "current format", id: "personal-rules-3f9a0c12"},
+		{name: "current format with digits only suffix", id: "personal-rules-00000001"},
+		{name: "short numeric suffix", id: "test-repo-123456"},
+		{name: "no suffix", id: "personal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsLegacyRepositoryID(tt.id); got != tt.wantLegacy {
+				t.Errorf("IsLegacyRepositoryID(%q) = %v, want %v", tt.id, got, tt.wantLegacy)
+			}
+
+			got := MigrateRepositoryID(tt.id)
+			if !tt.wantLegacy {
+				if got != tt.id {
+					t.Errorf("MigrateRepositoryID(%q) = %q, want unchanged", tt.id, got)
+				}
+				return
+			}
+			if !idPattern.MatchString(got) {
+				t.Errorf("migrated ID %q doesn't match %s", got, idPattern)
+			}
+			if got != MigrateRepositoryID(tt.id) {
+				t.Error("migration should be deterministic")
+			}
+			if MigrateRepositoryID(got) != got {
+				t.Error("migrated ID should not be migrated again")
+			}
+		})
+	}
+
+	prefix := MigrateRepositoryID("personal-rules-1728756432")
+	if prefix[:len("personal-rules-")] != "personal-rules-" {
+		t.Errorf("migrated ID %q should keep the name prefix", prefix)
+	}
+}
+
+func TestMigrateRepositoryIDs(t *testing.T) {
+	cfg := Config{
+		Version: "1.0",
+		Repositories: []repository.RepositoryEntry{
+			{ID: "personal-rules-1728756432", Name: "Personal Rules"},
+			{ID: "team-rules-3f9a0c12", Name: "Team Rules"},
+			{ID: "work-1728756500", Name: "Work"},
+		},
+	}
+
+	if got := cfg.MigrateRepositoryIDs(); got != 2 {
+		t.Errorf("MigrateRepositoryIDs() = %d, want 2", got)
+	}
+	if cfg.Version != "1.1" {
+		t.Errorf("Version = %q, want 1.1", cfg.Version)
+	}
+	want := []string{
+		MigrateRepositoryID("personal-rules-1728756432"),
+		"team-rules-3f9a0c12",
+		MigrateRepositoryID("work-1728756500"),
+	}
+	for i, repo := range cfg.Repositories {
+		if repo.ID != want[i] {
+			t.Errorf("Repositories[%d].ID = %q, want %q", i, repo.ID, want[i])
+		}
+	}
+	wantAliases := map[string]string{"personal-rules-1728756432": want[0], "work-1728756500": want[2]}
+	if !reflect.DeepEqual(cfg.RepositoryIDAliases, wantAliases) {
+		t.Errorf("RepositoryIDAliases = %v, want %v", cfg.RepositoryIDAliases, wantAliases)
+	}
+	if repo, err := cfg.FindRepositoryByID("work-1728756500"); err != nil || repo.Name != "Work" {
+		t.Errorf("FindRepositoryByID(old ID) = %v, %v, want the Work repository", repo, err)
+	}
+
+	if got := cfg.MigrateRepositoryIDs(); got != 0 {
+		t.Errorf("second MigrateRepositoryIDs() = %d, want 0", got)
+	}
+}
+
+func TestMigrateRepositoryIDs_KeepsTakenID(t *testing.T) {
+	legacy := "personal-rules-1728756432"
+	cfg := Config{
+		Repositories: []repository.RepositoryEntry{
+			{ID: MigrateRepositoryID(legacy), Name: "Already Migrated"},
+			{ID: legacy, Name: "Personal Rules"},
+		},
+	}
+
+	if got := cfg.MigrateRepositoryIDs(); got != 0 {
+		t.Errorf("MigrateRepositoryIDs() = %d, want 0", got)
+	}
+	if cfg.Repositories[1].ID != legacy {
+		t.Errorf("ID = %q, want %q kept to avoid a duplicate", cfg.Repositories[1].ID, legacy)
+	}
+}
+
+func TestLoadMigratesLegacyIDs(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv("RULEM_CONFIG_PATH", configPath)
+
+	legacy := Config{
+		Version:  "1.0",
+		InitTime: 1728756432,
+		Repositories: []repository.RepositoryEntry{
+			{ID: "personal-rules-1728756432", Name: "Personal Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1728756432, Path: "/tmp/rules"},
+		},
+	}
+	if err := legacy.SaveTo(configPath); err != nil {
+		t.Fatalf("SaveTo: %v", err)
+	}
+
+	// State kept under the old ID outside the config
+	oldID := "personal-rules-1728756432"
+	noteStore, err := notes.Load(notes.Path())
+	if err != nil {
+		t.Fatalf("notes.Load: %v", err)
+	}
+	noteStore.Rules[oldID+":go/style.md"] = notes.Note{Rating: 4}
+	if err := noteStore.Save(); err != nil {
+		t.Fatalf("notes.Save: %v", err)
+	}
+	quarantineStore, err := quarantine.Load(quarantine.Path())
+	if err != nil {
+		t.Fatalf("quarantine.Load: %v", err)
+	}
+	quarantineStore.Repositories[oldID] = &quarantine.RepositoryState{Approved: []string{"go/style.md"}}
+	if err := quarantineStore.Save(); err != nil {
+		t.Fatalf("quarantine.Save: %v", err)
+	}
+	credManager := repository.NewCredentialManager()
+	if err := credManager.StoreNamedToken(repository.ProviderGitHub, repository.RepositoryTokenName(oldID), "ghp_personal"); err != nil {
+		t.Fatalf("StoreNamedToken: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	wantID := MigrateRepositoryID("personal-rules-1728756432")
+	if cfg.Repositories[0].ID != wantID {
+		t.Errorf("loaded ID = %q, want %q", cfg.Repositories[0].ID, wantID)
+	}
+
+	// The migration is written back so it only happens once
+	onDisk, err := LoadFrom(configPath)
+	if err != nil {
+		t.Fatalf("LoadFrom: %v", err)
+	}
+	if onDisk.Repositories[0].ID != wantID || onDisk.Version != CurrentVersion {
+		t.Errorf("saved config = (%q, %q), want (%q, %q)", onDisk.Repositories[0].ID, onDisk.Version, wantID, CurrentVersion)
+	}
+	if onDisk.RepositoryIDAliases[oldID] != wantID {
+		t.Errorf("saved aliases = %v, want %s -> %s", onDisk.RepositoryIDAliases, oldID, wantID)
+	}
+
+	// The state followed the repository to its new ID
+	if noteStore, _ = notes.Load(notes.Path()); noteStore.Rules[wantID+":go/style.md"].Rating != 4 {
+		t.Errorf("notes = %v, want the note under %s", noteStore.Rules, wantID)
+	}
+	if quarantineStore, _ = quarantine.Load(quarantine.Path()); quarantineStore.Repositories[wantID] == nil || quarantineStore.Repositories[oldID] != nil {
+		t.Errorf("quarantine = %v, want the state under %s", quarantineStore.Repositories, wantID)
+	}
+	if token, err := credManager.GetNamedToken(repository.ProviderGitHub, repository.RepositoryTokenName(wantID)); err != nil || token != "ghp_personal" {
+		t.Errorf("token under %s = %q, %v", wantID, token, err)
+	}
+	if credManager.HasNamedToken(repository.ProviderGitHub, repository.RepositoryTokenName(oldID)) {
+		t.Error("the token should no longer be stored under the old ID")
+	}
+}
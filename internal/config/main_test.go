@@ -0,0 +1,26 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// TestMain keeps migrations that move repository state (see
+// migrateRepositoryState) away from the user's notes, quarantine file and
+// credential store: go-keyring's in-memory mock replaces the OS credential
+// store, and the notes and quarantine files live in a temporary directory.
+func TestMain(m *testing.M) {
+	keyring.MockInit()
+	dir, err := os.MkdirTemp("", "rulem-config-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("RULEM_NOTES_PATH", filepath.Join(dir, "notes.yaml"))
+	os.Setenv("RULEM_QUARANTINE_PATH", filepath.Join(dir, "quarantine.yaml"))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
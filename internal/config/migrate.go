@@ -0,0 +1,219 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/internal/version"
+)
+
+// Config migrations
+//
+// The config format has changed over time: the first versions kept rules in a
+// single storage_dir, later ones in a single central repository, before
+// repositories replaced both, and version 1.1 replaced timestamp-derived
+// repository IDs. Decoding an old file into Config silently drops what it no
+// longer knows, so Load first upgrades it with the migrations below, in
+// order, from the version in the file to CurrentVersion. The previous file is
+// kept next to the config as config.yaml.v<version>.bak before the upgraded
+// one is written, and what was migrated is reported.
+
+// CurrentVersion is the config schema version written by this build.
+// Version 1.1 replaced timestamp-derived repository IDs, see MigrateRepositoryIDs;
+// version 1.2 moved storage_dir and central into repositories.
+const CurrentVersion = "1.2"
+
+// unversioned is the version of configs written before the version field
+const unversioned = "1.0"
+
+// migration upgrades a config to version. It returns what it changed.
+type migration struct {
+	version string
+	apply   func(c *Config, legacy legacyFields) []string
+}
+
+// migrations upgrade configs to CurrentVersion, oldest first
+var migrations = []migration{
+	{version: "1.1", apply: migrateRepositoryIDs},
+	{version: "1.2", apply: migrateLegacyStorage},
+}
+
+// legacyFields holds the settings of older config versions that Config no
+// longer has
+type legacyFields struct {
+	StorageDir string         `yaml:"storage_dir"`
+	Central    *legacyCentral `yaml:"central"`
+}
+
+// legacyCentral is the single central repository of configs before
+// repositories
+type legacyCentral struct {
+	Path         string  `yaml:"path"`
+	RemoteURL    *string `yaml:"remote_url"`
+	Branch       *string `yaml:"branch"`
+	LastSyncTime *int64  `yaml:"last_sync_time"`
+}
+
+// migrationReport describes the upgrade of a config by migrate
+type migrationReport struct {
+	From    string   // Version of the config before, "" when it had none
+	To      string   // Version of the config after
+	Changes []string // What the migrations changed, in order
+}
+
+// Migrated reports whether the config was upgraded and needs saving
+func (r migrationReport) Migrated() bool {
+	return r.From != r.To || len(r.Changes) > 0
+}
+
+// String summarises the report for the log
+func (r migrationReport) String() string {
+	from := r.From
+	if from == "" {
+		from = "unversioned"
+	}
+	summary := fmt.Sprintf("config migrated from version %s to %s", from, r.To)
+	if len(r.Changes) > 0 {
+		summary += ": " + strings.Join(r.Changes, "; ")
+	}
+	return summary
+}
+
+// migrate upgrades c, read along with the legacy settings of its file, to
+// CurrentVersion. A config written by a newer rulem is left as it is.
+func migrate(c *Config, legacy legacyFields) (migrationReport, error) {
+	report := migrationReport{From: c.Version, To: c.Version}
+	from := c.Version
+	if strings.TrimSpace(from) == "" {
+		from = unversioned
+	}
+	if cmp, err := version.Compare(from, CurrentVersion); err != nil {
+		return report, fmt.Errorf("invalid config version %q: %w", c.Version, err)
+	} else if cmp > 0 {
+		logging.Warn("Config was written by a newer rulem; settings this version does not know are ignored", "version", c.Version)
+		return report, nil
+	}
+
+	for _, m := range migrations {
+		if cmp, _ := version.Compare(from, m.version); cmp >= 0 {
+			continue
+		}
+		report.Changes = append(report.Changes, m.apply(c, legacy)...)
+	}
+	c.Version = CurrentVersion
+	report.To = CurrentVersion
+	return report, nil
+}
+
+// migrateRepositoryIDs is the 1.1 migration, see MigrateRepositoryIDs
+func migrateRepositoryIDs(c *Config, _ legacyFields) []string {
+	if n := c.MigrateRepositoryIDs(); n > 0 {
+		return []string{fmt.Sprintf("rewrote %d timestamp repository IDs", n)}
+	}
+	return nil
+}
+
+// migrateLegacyStorage is the 1.2 migration: storage_dir and central become
+// entries of repositories. A config with both, left by an interrupted upgrade,
+// keeps both; when they are the same directory the central repository, which
+// knows its remote, is kept. They are dropped when repositories are already
+// configured, which took their place.
+func migrateLegacyStorage(c *Config, legacy legacyFields) []string {
+	var changes []string
+	configured := len(c.Repositories) > 0
+	central := legacy.Central
+	if central != nil && central.Path == "" {
+		central = nil
+	}
+
+	if legacy.StorageDir != "" {
+		switch {
+		case configured:
+			changes = append(changes, fmt.Sprintf("dropped storage_dir %s, replaced by repositories", legacy.StorageDir))
+		case central != nil && filepath.Clean(central.Path) == filepath.Clean(legacy.StorageDir):
+			changes = append(changes, fmt.Sprintf("dropped storage_dir %s, the same directory as the central repository", legacy.StorageDir))
+		default:
+			c.Repositories = append(c.Repositories, legacyRepository(legacy.StorageDir, c.InitTime))
+			changes = append(changes, fmt.Sprintf("moved storage_dir %s to repositories", legacy.StorageDir))
+		}
+	}
+	if central != nil {
+		if configured {
+			return append(changes, fmt.Sprintf("dropped central repository %s, replaced by repositories", central.Path))
+		}
+		repo := legacyRepository(central.Path, c.InitTime)
+		if central.RemoteURL != nil && *central.RemoteURL != "" {
+			repo.Type = repository.RepositoryTypeGitHub
+			repo.RemoteURL = central.RemoteURL
+			repo.Branch = central.Branch
+			repo.LastSyncTime = central.LastSyncTime
+		}
+		// Repository names are unique, and storage_dir may share the base name
+		for _, existing := range c.Repositories {
+			if strings.EqualFold(existing.Name, repo.Name) {
+				repo.Name += " (central)"
+				break
+			}
+		}
+		c.Repositories = append(c.Repositories, repo)
+		changes = append(changes, fmt.Sprintf("moved central repository %s to repositories", central.Path))
+	}
+	return changes
+}
+
+// legacyRepository returns a local repository entry for a legacy rules
+// directory, named after it. Its ID is derived from the path, so the same
+// config always migrates to the same ID.
+func legacyRepository(path string, createdAt int64) repository.RepositoryEntry {
+	name := filepath.Base(filepath.Clean(path))
+	if name == "." || name == string(filepath.Separator) || name == "~" {
+		name = "Rules"
+	}
+	sum := sha256.Sum256([]byte(path))
+	return repository.RepositoryEntry{
+		ID:        FormatRepositoryID(name, binary.BigEndian.Uint32(sum[:4])),
+		Name:      name,
+		Type:      repository.RepositoryTypeLocal,
+		CreatedAt: createdAt,
+		Path:      path,
+	}
+}
+
+// backupConfig keeps data, the config file at path before its migration from
+// version from, as path.v<from>.bak and returns the backup path. An existing
+// backup, say of a config restored from it, is never overwritten: the next
+// free path.v<from>-<n>.bak is used instead.
+func backupConfig(path string, data []byte, from string) (string, error) {
+	if from == "" {
+		from = unversioned
+	}
+	for n := 1; ; n++ {
+		backup := fmt.Sprintf("%s.v%s.bak", path, from)
+		if n > 1 {
+			backup = fmt.Sprintf("%s.v%s-%d.bak", path, from, n)
+		}
+		file, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to back up config before migrating it: %w", err)
+		}
+		if _, err := file.Write(data); err != nil {
+			file.Close()
+			return "", fmt.Errorf("failed to back up config before migrating it: %w", err)
+		}
+		if err := file.Close(); err != nil {
+			return "", fmt.Errorf("failed to back up config before migrating it: %w", err)
+		}
+		return backup, nil
+	}
+}
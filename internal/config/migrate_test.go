@@ -0,0 +1,160 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/repository"
+)
+
+// writeConfigFile writes content as the config file Load reads
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv("RULEM_CONFIG_PATH", configPath)
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return configPath
+}
+
+func TestLoadMigratesStorageDir(t *testing.T) {
+	legacy := "version: \"1.0\"\ninit_time: 1728756432\nstorage_dir: /home/me/rulem-rules\n"
+	configPath := writeConfigFile(t, legacy)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Repositories) != 1 {
+		t.Fatalf("repositories = %+v, want the storage_dir", cfg.Repositories)
+	}
+	repo := cfg.Repositories[0]
+	if repo.Path != "/home/me/rulem-rules" || repo.Type != repository.RepositoryTypeLocal || repo.Name != "rulem-rules" || repo.CreatedAt != 1728756432 {
+		t.Errorf("migrated repository = %+v", repo)
+	}
+	if IsLegacyRepositoryID(repo.ID) || !strings.HasPrefix(repo.ID, "rulem-rules-") {
+		t.Errorf("migrated repository ID = %q", repo.ID)
+	}
+
+	// The previous file is kept and the upgraded one written back
+	backup, err := os.ReadFile(configPath + ".v1.0.bak")
+	if err != nil || string(backup) != legacy {
+		t.Errorf("backup = %q, %v; want the previous file", backup, err)
+	}
+	onDisk, err := LoadFrom(configPath)
+	if err != nil {
+		t.Fatalf("LoadFrom: %v", err)
+	}
+	if onDisk.Version != CurrentVersion || len(onDisk.Repositories) != 1 || onDisk.Repositories[0].ID != repo.ID {
+		t.Errorf("saved config = %+v", onDisk)
+	}
+}
+
+func TestMigrate_Central(t *testing.T) {
+	cfg := Config{InitTime: 1728756432}
+	url := "https://github.com/acme/rules"
+	branch := "main"
+	report, err := migrate(&cfg, legacyFields{Central: &legacyCentral{Path: "/clones/rules", RemoteURL: &url, Branch: &branch}})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if !report.Migrated() || report.From != "" || report.To != CurrentVersion || len(report.Changes) != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if !strings.Contains(report.String(), "from version unversioned to "+CurrentVersion) {
+		t.Errorf("report summary = %q", report.String())
+	}
+	if len(cfg.Repositories) != 1 {
+		t.Fatalf("repositories = %+v, want the central repository", cfg.Repositories)
+	}
+	repo := cfg.Repositories[0]
+	if repo.Type != repository.RepositoryTypeGitHub || repo.RemoteURL == nil || *repo.RemoteURL != url || repo.Branch == nil || *repo.Branch != "main" {
+		t.Errorf("migrated repository = %+v", repo)
+	}
+
+	// Repositories took the place of central, which is only dropped
+	cfg = Config{Version: "1.1", Repositories: []repository.RepositoryEntry{{ID: "rules-3f9a0c12", Path: "/rules"}}}
+	report, err = migrate(&cfg, legacyFields{Central: &legacyCentral{Path: "/old"}})
+	if err != nil || len(cfg.Repositories) != 1 || len(report.Changes) != 1 || !strings.Contains(report.Changes[0], "dropped") {
+		t.Errorf("migrate() = %+v, %v; repositories %+v", report, err, cfg.Repositories)
+	}
+}
+
+func TestMigrate_StorageDirAndCentral(t *testing.T) {
+	url := "https://github.com/acme/rules"
+	cfg := Config{InitTime: 1728756432}
+	report, err := migrate(&cfg, legacyFields{StorageDir: "/home/me/rules", Central: &legacyCentral{Path: "/clones/rules", RemoteURL: &url}})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if len(cfg.Repositories) != 2 || len(report.Changes) != 2 {
+		t.Fatalf("repositories = %+v, changes %v; want both storage_dir and central", cfg.Repositories, report.Changes)
+	}
+	local, central := cfg.Repositories[0], cfg.Repositories[1]
+	if local.Path != "/home/me/rules" || local.Type != repository.RepositoryTypeLocal {
+		t.Errorf("storage_dir repository = %+v", local)
+	}
+	if central.Path != "/clones/rules" || central.Type != repository.RepositoryTypeGitHub || central.Name != "rules (central)" {
+		t.Errorf("central repository = %+v", central)
+	}
+	if err := repository.ValidateAllRepositories(cfg.Repositories); err != nil {
+		t.Errorf("migrated repositories are invalid: %v", err)
+	}
+
+	// The same directory becomes a single repository with the central remote
+	cfg = Config{InitTime: 1728756432}
+	if _, err := migrate(&cfg, legacyFields{StorageDir: "/clones/rules/", Central: &legacyCentral{Path: "/clones/rules", RemoteURL: &url}}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if len(cfg.Repositories) != 1 || cfg.Repositories[0].Type != repository.RepositoryTypeGitHub {
+		t.Errorf("repositories = %+v, want only the central repository", cfg.Repositories)
+	}
+}
+
+func TestBackupConfigKeepsExistingBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	first, err := backupConfig(path, []byte("first"), "1.0")
+	if err != nil || first != path+".v1.0.bak" {
+		t.Fatalf("backupConfig() = %q, %v", first, err)
+	}
+	second, err := backupConfig(path, []byte("second"), "1.0")
+	if err != nil || second != path+".v1.0-2.bak" {
+		t.Fatalf("backupConfig() = %q, %v; want the next free name", second, err)
+	}
+	if data, _ := os.ReadFile(first); string(data) != "first" {
+		t.Errorf("first backup = %q, want it untouched", data)
+	}
+	if data, _ := os.ReadFile(second); string(data) != "second" {
+		t.Errorf("second backup = %q", data)
+	}
+}
+
+func TestMigrate_Versions(t *testing.T) {
+	cfg := Config{Version: CurrentVersion}
+	if report, err := migrate(&cfg, legacyFields{}); err != nil || report.Migrated() {
+		t.Errorf("a current config should not be migrated: %+v, %v", report, err)
+	}
+
+	cfg = Config{Version: "9.0"}
+	if report, err := migrate(&cfg, legacyFields{StorageDir: "/rules"}); err != nil || report.Migrated() || cfg.Version != "9.0" || len(cfg.Repositories) != 0 {
+		t.Errorf("a config from a newer rulem should be left alone: %+v, %v", report, err)
+	}
+
+	cfg = Config{Version: "latest"}
+	if _, err := migrate(&cfg, legacyFields{}); err == nil {
+		t.Error("an invalid version should fail")
+	}
+}
+
+func TestLoadKeepsCurrentConfig(t *testing.T) {
+	configPath := writeConfigFile(t, "version: \""+CurrentVersion+"\"\ninit_time: 1\nrepositories: []\n")
+	if _, err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if matches, _ := filepath.Glob(configPath + ".v*.bak"); len(matches) != 0 {
+		t.Errorf("a current config should not be backed up: %v", matches)
+	}
+}
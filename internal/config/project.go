@@ -0,0 +1,158 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/repository"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Project overrides
+//
+// A project can keep settings of its own in a .rulem.yaml at its root: deploy
+// targets for the editors it uses, the tags of the rules the MCP server serves
+// when launched there, and the repository rules saved from it go to. Load
+// looks for the file in the working directory and, inside a git repository,
+// in its parents up to the repository root, and attaches it as
+// Config.Project. It is merged with the global config where each setting is
+// used and never written to config.yaml.
+
+// ProjectFileName is the name of the project override file
+const ProjectFileName = ".rulem.yaml"
+
+// ProjectConfig holds the settings of a project's .rulem.yaml
+type ProjectConfig struct {
+	// Path is the .rulem.yaml the settings were read from
+	Path string `yaml:"-"`
+
+	// Repository is the name or ID of the repository rules saved from the
+	// project go to by default
+	Repository string `yaml:"repository,omitempty"`
+
+	// Tags select the rules the MCP server serves, like rulem mcp --tags,
+	// when neither --tags nor a profile choose them
+	Tags []string `yaml:"tags,omitempty"`
+
+	// DeployTargets are added to the global deploy_targets; an entry with
+	// the id of a global one replaces it
+	DeployTargets []DeployTarget `yaml:"deploy_targets,omitempty"`
+}
+
+// FindProjectFile returns the .rulem.yaml that applies to dir: the one in dir
+// or, inside a git repository, in the closest parent up to the repository root
+func FindProjectFile(dir string) (string, bool) {
+	inRepository := insideGitRepository(dir)
+	for current := dir; ; {
+		path := filepath.Join(current, ProjectFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil || !inRepository {
+			return "", false
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", false
+		}
+		current = parent
+	}
+}
+
+// insideGitRepository reports whether dir or one of its parents holds a .git
+func insideGitRepository(dir string) bool {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// LoadProject reads the project settings at path. Unknown keys are rejected,
+// so a misspelt setting is not silently ignored.
+func LoadProject(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	project := ProjectConfig{Path: path}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&project); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	project.Repository = strings.TrimSpace(project.Repository)
+	return &project, nil
+}
+
+// PreferredRepository returns the repository the project's .rulem.yaml
+// prefers, or nil when there is none
+func (c *Config) PreferredRepository() (*repository.RepositoryEntry, error) {
+	if c.Project == nil || c.Project.Repository == "" {
+		return nil, nil
+	}
+	repo, err := c.FindRepositoryByID(c.Project.Repository)
+	if err != nil {
+		repo, err = c.FindRepositoryByName(c.Project.Repository)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository in %s: %w", c.Project.Path, err)
+	}
+	return repo, nil
+}
+
+// ProjectTags returns the tags of the project's .rulem.yaml, if any
+func (c *Config) ProjectTags() []string {
+	if c.Project == nil {
+		return nil
+	}
+	return c.Project.Tags
+}
+
+// deployTargets returns the global deploy targets merged with those of the
+// project, which replace global ones with their id
+func (c *Config) deployTargets() []DeployTarget {
+	if c.Project == nil || len(c.Project.DeployTargets) == 0 {
+		return c.DeployTargets
+	}
+	overridden := make(map[string]bool, len(c.Project.DeployTargets))
+	for _, target := range c.Project.DeployTargets {
+		overridden[strings.TrimSpace(target.ID)] = true
+	}
+	var targets []DeployTarget
+	for _, target := range c.DeployTargets {
+		if !overridden[strings.TrimSpace(target.ID)] {
+			targets = append(targets, target)
+		}
+	}
+	return append(targets, c.Project.DeployTargets...)
+}
+
+// loadProject attaches the .rulem.yaml that applies to the working directory
+func (c *Config) loadProject() error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil // No working directory, so no project
+	}
+	path, ok := FindProjectFile(dir)
+	if !ok {
+		return nil
+	}
+	project, err := LoadProject(path)
+	if err != nil {
+		return err
+	}
+	c.Project = project
+	return nil
+}
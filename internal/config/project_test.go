@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/repository"
+)
+
+func TestFindProjectFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := FindProjectFile(nested); ok {
+		t.Error("no .rulem.yaml should be found yet")
+	}
+	project := filepath.Join(root, ProjectFileName)
+	if err := os.WriteFile(project, []byte("tags: [go]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, ok := FindProjectFile(nested); !ok || path != project {
+		t.Errorf("FindProjectFile() = %q, %v; want the repository root's %s", path, ok, project)
+	}
+
+	// Outside of a git repository only the directory itself counts
+	plain := t.TempDir()
+	if err := os.WriteFile(filepath.Join(plain, ProjectFileName), []byte("tags: [go]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(plain, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := FindProjectFile(sub); ok {
+		t.Error("a parent's .rulem.yaml should not apply outside of a git repository")
+	}
+}
+
+func TestLoadProject(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ProjectFileName)
+	content := "repository: Team Rules\ntags: [backend]\ndeploy_targets:\n  - id: aider\n    path: .\n    filename: CONVENTIONS.md\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	project, err := LoadProject(path)
+	if err != nil {
+		t.Fatalf("LoadProject: %v", err)
+	}
+	if project.Repository != "Team Rules" || len(project.Tags) != 1 || len(project.DeployTargets) != 1 || project.Path != path {
+		t.Errorf("LoadProject() = %+v", project)
+	}
+
+	if err := os.WriteFile(path, []byte("tag: [backend]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProject(path); err == nil || !strings.Contains(err.Error(), "tag") {
+		t.Errorf("LoadProject() error = %v, want the unknown key reported", err)
+	}
+}
+
+func TestConfig_ProjectOverrides(t *testing.T) {
+	cfg := Config{
+		Repositories: []repository.RepositoryEntry{{ID: "mine-1", Name: "My Rules"}, {ID: "team-2", Name: "Team Rules"}},
+		DeployTargets: []DeployTarget{
+			{ID: "aider", Path: ".", Filename: "CONVENTIONS.md"},
+			{ID: "zed", Path: ".", Filename: ".rules"},
+		},
+		Project: &ProjectConfig{
+			Path:       "/work/app/.rulem.yaml",
+			Repository: "team rules",
+			Tags:       []string{"backend"},
+			DeployTargets: []DeployTarget{
+				{ID: "aider", Path: "docs", Filename: "CONVENTIONS.md"},
+				{ID: "windsurf", Path: ".windsurf/rules", Filename: "{name}.md"},
+			},
+		},
+	}
+
+	repo, err := cfg.PreferredRepository()
+	if err != nil || repo == nil || repo.ID != "team-2" {
+		t.Errorf("PreferredRepository() = %+v, %v; want Team Rules", repo, err)
+	}
+	if tags := cfg.ProjectTags(); len(tags) != 1 || tags[0] != "backend" {
+		t.Errorf("ProjectTags() = %v", tags)
+	}
+
+	profiles, err := cfg.DeployProfiles()
+	if err != nil {
+		t.Fatalf("DeployProfiles: %v", err)
+	}
+	byID := map[string]string{}
+	for _, profile := range profiles {
+		byID[profile.ID] = profile.RulePath
+	}
+	if byID["aider"] != "docs/" || byID["zed"] != "./" || byID["windsurf"] != ".windsurf/rules/" {
+		t.Errorf("deploy targets = %v, want the project's aider and windsurf next to the global zed", byID)
+	}
+
+	cfg.Project.Repository = "Elsewhere"
+	if _, err := cfg.PreferredRepository(); err == nil {
+		t.Error("an unknown preferred repository should fail")
+	}
+	cfg.Project = nil
+	if repo, err := cfg.PreferredRepository(); repo != nil || err != nil {
+		t.Errorf("PreferredRepository() without a project = %+v, %v", repo, err)
+	}
+}
+
+func TestLoadAttachesProject(t *testing.T) {
+	writeConfigFile(t, "version: \""+CurrentVersion+"\"\ninit_time: 1\nrepositories: []\n")
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ProjectFileName), []byte("tags: [go]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Project == nil || len(cfg.Project.Tags) != 1 {
+		t.Fatalf("Project = %+v, want the project's .rulem.yaml", cfg.Project)
+	}
+
+	// The project is never written to config.yaml
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	saved, err := LoadFrom(os.Getenv("RULEM_CONFIG_PATH"))
+	if err != nil || saved.Project != nil {
+		t.Errorf("saved config = %+v, %v", saved, err)
+	}
+	data, _ := os.ReadFile(os.Getenv("RULEM_CONFIG_PATH"))
+	if strings.Contains(string(data), "tags") {
+		t.Errorf("config.yaml = %q, want no project settings", data)
+	}
+}
@@ -0,0 +1,304 @@
+// Package deploy copies or links rules from the rule repositories into the
+// project in the current directory, the reverse of saving a rule into a
+// repository.
+//
+// A deploy of several rules is one operation: every destination is checked and
+// every pre-deploy hook asked before anything is written, and copies are
+// written with fileops.AtomicBatchCopy, so either all rules are copied or the
+// project is left unchanged. Each deployed rule is recorded in the project
+// manifest (see the project package), which is what `rulem diff`, `rulem
+// status` and `rulem verify` later compare with the repositories.
+//
+// Rules are deployed for an editor (see Profiles), which decides where each
+// rule goes and how its frontmatter is rewritten: copies for Cursor keep their
+// scope as 'globs', while AGENTS.md and CLAUDE.md get the rule without its
+// frontmatter. Links always show the rule as it is in the repository.
+//
+// The import screen, the deploy screen and `rulem deploy` all deploy through
+// this package.
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/config"
+	"rulem/internal/editors"
+	"rulem/internal/filemanager"
+	"rulem/internal/hooks"
+	"rulem/internal/logging"
+	"rulem/internal/project"
+	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+)
+
+// ErrDestinationExists is returned, wrapped with the existing destinations, when
+// rules would replace files of the project without Options.Overwrite
+var ErrDestinationExists = errors.New("destination file already exists")
+
+// Target is one rule to deploy
+type Target struct {
+	// Source is the path of the rule in its repository or overlay, absolute as
+	// in filemanager.FileItem.Path or relative to the repository root
+	Source string
+
+	// Repository is the prepared repository the rule belongs to
+	Repository repository.PreparedRepository
+
+	// Dest is where the rule is deployed, relative to the current directory
+	// (see Destination)
+	Dest string
+
+	// Frontmatter is how the frontmatter of a copy is rewritten for its
+	// editor, usually the chosen profile's; the zero value copies the rule as is
+	Frontmatter editors.FrontmatterRewrite
+
+	// VSCodeSnippet adds a VS Code snippet referencing the rule, usually the
+	// chosen profile's VSCodeSnippets
+	VSCodeSnippet bool
+}
+
+// Options controls a deploy
+type Options struct {
+	Mode      project.Mode  // Copy or link the rules; "" copies
+	Overwrite bool          // Replace existing files instead of failing with ErrDestinationExists
+	Hooks     []config.Hook // Hooks asked before and told after each rule is deployed
+}
+
+// plannedRule is a Target whose source and destination were validated
+type plannedRule struct {
+	target  Target
+	source  string // Absolute path of the rule
+	dest    string // Absolute destination in the project
+	data    []byte // Rewritten content of a copy, nil to copy the rule as is
+	payload hooks.Payload
+}
+
+// Deploy copies or links targets into the project in the current directory.
+//
+// Parameters:
+//   - ctx: Context of the pre-deploy hooks
+//   - targets: The rules to deploy; destinations must be distinct
+//   - opts: Deploy mode, whether to replace existing files, and the hooks to run
+//   - logger: Logger for deploy details
+//
+// Returns:
+//   - []string: Absolute paths of the deployed rules, in the order of targets
+//   - error: Validation errors, ErrDestinationExists, a hook's veto, or write errors
+//
+// Nothing is written unless every target is valid and every hook agrees. Copies
+// then replace their destinations all at once. Links are created one by one: a
+// failure removes the links already made, but files they replaced are gone.
+//
+// Recording the rules in the project manifest, adding VS Code snippets for
+// targets that ask for them and notifying post-deploy hooks happen after the
+// rules are written; their failures are logged rather than failing the deploy.
+func Deploy(ctx context.Context, targets []Target, opts Options, logger *logging.AppLogger) ([]string, error) {
+	mode := opts.Mode
+	if mode == "" {
+		mode = project.ModeCopy
+	}
+	if mode != project.ModeCopy && mode != project.ModeLink {
+		return nil, fmt.Errorf("unknown deploy mode '%s' (want %s or %s)", mode, project.ModeCopy, project.ModeLink)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get current working directory: %w", err)
+	}
+
+	plans := make([]plannedRule, 0, len(targets))
+	seen := make(map[string]bool, len(targets))
+	var existing []string
+	for _, target := range targets {
+		plan, err := planRule(cwd, target, mode, logger)
+		if err != nil {
+			return nil, err
+		}
+		if seen[plan.dest] {
+			return nil, fmt.Errorf("two rules would be deployed to %s", target.Dest)
+		}
+		seen[plan.dest] = true
+		if _, err := os.Lstat(plan.dest); err == nil {
+			existing = append(existing, target.Dest)
+		}
+		plans = append(plans, plan)
+	}
+	if len(existing) > 0 && !opts.Overwrite {
+		return nil, fmt.Errorf("%w: %s", ErrDestinationExists, strings.Join(existing, ", "))
+	}
+
+	// Pre-deploy hooks may veto a rule, e.g. one that is not approved, which
+	// cancels the whole deploy
+	for i := range plans {
+		plan := &plans[i]
+		plan.payload = hooks.RulePayload(hooks.EventPreDeploy, plan.target.Repository, plan.source)
+		plan.payload.Destination = plan.target.Dest
+		plan.payload.Mode = string(mode)
+		if err := hooks.Run(ctx, opts.Hooks, plan.payload, logger); err != nil {
+			return nil, fmt.Errorf("deploy of %s cancelled by hook: %w", filepath.Base(plan.source), err)
+		}
+	}
+
+	for _, plan := range plans {
+		if err := fileops.EnsureDirectoryExists(filepath.Dir(plan.dest)); err != nil {
+			return nil, fmt.Errorf("cannot create destination directory: %w", err)
+		}
+	}
+
+	if mode == project.ModeLink {
+		err = linkRules(plans)
+	} else {
+		err = copyRules(plans)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, len(plans))
+	for i, plan := range plans {
+		logger.Info("Rule deployed", "source", plan.source, "dest", plan.dest, "mode", mode)
+		// The rewrite is recorded even when it changed nothing, since it
+		// applies to later versions of the rule
+		var rewrite *editors.FrontmatterRewrite
+		if mode == project.ModeCopy && !plan.target.Frontmatter.IsZero() {
+			rewrite = &plan.target.Frontmatter
+		}
+		if err := project.RecordDeployment(".", plan.dest, plan.source, plan.target.Repository, mode, rewrite); err != nil {
+			logger.Warn("Failed to record deployment in project manifest", "dest", plan.dest, "error", err)
+		}
+		if plan.target.VSCodeSnippet {
+			if snippetsPath, err := editors.UpdateVSCodeSnippets(".", filepath.Base(plan.source), plan.target.Dest); err != nil {
+				logger.Warn("Failed to update VS Code snippets", "error", err)
+			} else {
+				logger.Debug("Updated VS Code snippets", "path", snippetsPath)
+			}
+		}
+
+		plan.payload.Event = hooks.EventPostDeploy
+		plan.payload.Destination = plan.dest
+		hooks.Notify(ctx, opts.Hooks, plan.payload, logger)
+		paths[i] = plan.dest
+	}
+	return paths, nil
+}
+
+// planRule validates the source and destination of target and rewrites the
+// frontmatter of copies
+func planRule(cwd string, target Target, mode project.Mode, logger *logging.AppLogger) (plannedRule, error) {
+	if err := fileops.ValidateCWDPath(target.Dest); err != nil {
+		return plannedRule{}, fmt.Errorf("invalid destination path %s: %w", target.Dest, err)
+	}
+	fm, err := filemanager.NewRepositoryFileManager(target.Repository, logger)
+	if err != nil {
+		return plannedRule{}, fmt.Errorf("failed to access repository %s: %w", target.Repository.Name(), err)
+	}
+	source, err := fm.StorageFilePath(target.Source)
+	if err != nil {
+		return plannedRule{}, err
+	}
+	plan := plannedRule{target: target, source: source, dest: filepath.Join(cwd, target.Dest)}
+	if mode != project.ModeCopy || target.Frontmatter.IsZero() {
+		return plan, nil
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return plannedRule{}, fmt.Errorf("failed to read %s: %w", filepath.Base(source), err)
+	}
+	rewritten, err := target.Frontmatter.Apply(content)
+	if err != nil {
+		return plannedRule{}, fmt.Errorf("cannot rewrite the frontmatter of %s: %w", filepath.Base(source), err)
+	}
+	if string(rewritten) != string(content) {
+		plan.data = rewritten
+	}
+	return plan, nil
+}
+
+// copyRules copies every rule to its destination, or none
+func copyRules(plans []plannedRule) error {
+	ops := make([]fileops.CopyOp, len(plans))
+	for i, plan := range plans {
+		ops[i] = fileops.CopyOp{Src: plan.source, Dest: plan.dest, Data: plan.data}
+	}
+	if err := fileops.AtomicBatchCopy(ops); err != nil {
+		return fmt.Errorf("failed to copy rules, project unchanged: %w", err)
+	}
+	return nil
+}
+
+// linkRules links every rule at its destination, removing the links already
+// created when one fails
+func linkRules(plans []plannedRule) error {
+	for i, plan := range plans {
+		err := func() error {
+			if _, err := os.Lstat(plan.dest); err == nil {
+				if err := os.Remove(plan.dest); err != nil {
+					return fmt.Errorf("cannot remove existing destination: %w", err)
+				}
+			}
+			return fileops.CreateRelativeSymlink(plan.source, plan.dest)
+		}()
+		if err != nil {
+			for _, done := range plans[:i] {
+				os.Remove(done.dest)
+			}
+			return fmt.Errorf("failed to link %s: %w", plan.target.Dest, err)
+		}
+	}
+	return nil
+}
+
+// Profiles returns the editors rules can be deployed for with cfg (see
+// config.Config.DeployProfiles), or the built-in ones when cfg is nil
+func Profiles(cfg *config.Config) ([]editors.EditorRuleConfig, error) {
+	if cfg == nil {
+		return editors.GetAllEditorRuleConfigs(), nil
+	}
+	return cfg.DeployProfiles()
+}
+
+// Destination returns where the rule file name is deployed, relative to the
+// current directory.
+//
+// Parameters:
+//   - name: File name of the rule, e.g. "go-style.md"
+//   - as: The path asked for; "" deploys where profile puts the rule. A path
+//     ending in a separator or naming an existing directory receives the rule
+//     under its own name, and an absolute path must lie within the current
+//     directory.
+//   - profile: The editor the rule is deployed for
+//
+// Returns:
+//   - string: The destination, relative to the current directory
+//   - error: When an absolute path lies outside the current directory
+func Destination(name, as string, profile editors.EditorRuleConfig) (string, error) {
+	if as == "" {
+		return filepath.Clean(profile.GenerateRuleFileFullPath(name)), nil
+	}
+
+	dir := strings.HasSuffix(as, "/") || strings.HasSuffix(as, string(filepath.Separator))
+	if info, err := os.Stat(as); err == nil && info.IsDir() {
+		dir = true
+	}
+	if filepath.IsAbs(as) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("cannot get current working directory: %w", err)
+		}
+		rel, err := filepath.Rel(cwd, as)
+		if err != nil || !filepath.IsLocal(rel) {
+			return "", fmt.Errorf("%s is outside the current directory", as)
+		}
+		as = rel
+	}
+	if dir {
+		return filepath.Join(as, filepath.Base(name)), nil
+	}
+	return filepath.Clean(as), nil
+}
@@ -0,0 +1,315 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/editors"
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/project"
+	"rulem/internal/repository"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// setup prepares a local repository holding files and changes into an empty
+// project directory
+func setup(t *testing.T, files map[string]string) (repository.PreparedRepository, *logging.AppLogger) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+	}
+
+	logger, _ := logging.NewTestLogger()
+	entries := []repository.RepositoryEntry{
+		{ID: "rules-1", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: dir},
+	}
+	prepared, err := repository.PrepareAllRepositories(context.Background(), entries, logger)
+	if err != nil {
+		t.Fatalf("PrepareAllRepositories: %v", err)
+	}
+	t.Chdir(t.TempDir())
+	return prepared[0], logger
+}
+
+func TestDeploy(t *testing.T) {
+	prep, logger := setup(t, map[string]string{"go.md": "# go\n", "style/python.md": "# python\n"})
+
+	targets := []Target{
+		{Source: filepath.Join(prep.LocalPath, "go.md"), Repository: prep, Dest: ".cursor/rules/go.mdc"},
+		{Source: "style/python.md", Repository: prep, Dest: "AGENTS.md"},
+	}
+	paths, err := Deploy(context.Background(), targets, Options{}, logger)
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if len(paths) != 2 || !filepath.IsAbs(paths[0]) {
+		t.Fatalf("Deploy() = %v, want two absolute paths", paths)
+	}
+	if got := readFile(t, ".cursor/rules/go.mdc"); got != "# go\n" {
+		t.Errorf("go.mdc = %q, want the rule copied", got)
+	}
+	if got := readFile(t, "AGENTS.md"); got != "# python\n" {
+		t.Errorf("AGENTS.md = %q, want the rule copied", got)
+	}
+
+	manifest, err := project.LoadManifest(".")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	entry, ok := manifest.Find(".cursor/rules/go.mdc")
+	if !ok || entry.Source != "go.md" || entry.Repository != "rules-1" || entry.Mode != project.ModeCopy {
+		t.Errorf("manifest entry = %+v, want go.md from rules-1 copied", entry)
+	}
+	if _, ok := manifest.Find("AGENTS.md"); !ok {
+		t.Error("AGENTS.md should be recorded in the manifest")
+	}
+}
+
+func TestDeployVSCodeSnippets(t *testing.T) {
+	prep, logger := setup(t, map[string]string{"go.md": "# go\n", "python.md": "# python\n"})
+
+	// Editors outside of VS Code leave .vscode/ alone
+	targets := []Target{{Source: "go.md", Repository: prep, Dest: ".cursor/rules/go.mdc"}}
+	if _, err := Deploy(context.Background(), targets, Options{}, logger); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if _, err := os.Stat(".vscode"); !os.IsNotExist(err) {
+		t.Errorf("deploying for Cursor should not create .vscode/, stat err = %v", err)
+	}
+
+	copilot, err := editors.FindEditorRuleConfig(editors.GetAllEditorRuleConfigs(), "copilot-instructions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets = []Target{{Source: "python.md", Repository: prep, Dest: ".github/instructions/python.instructions.md", VSCodeSnippet: copilot.VSCodeSnippets}}
+	if _, err := Deploy(context.Background(), targets, Options{}, logger); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	snippets := readFile(t, filepath.FromSlash(editors.VSCodeSnippetsFile))
+	if !strings.Contains(snippets, "rulem-python") || strings.Contains(snippets, "rulem-go") {
+		t.Errorf("snippets = %s, want only the rule deployed for Copilot", snippets)
+	}
+}
+
+func TestDeployRewritesFrontmatter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	rule := "---\ndescription: Go style\napplyTo: \"**/*.go\"\ntags: [go]\n---\n\n# go\n"
+	prep, logger := setup(t, map[string]string{"go.md": rule})
+	cursor, err := editors.FindEditorRuleConfig(editors.GetAllEditorRuleConfigs(), "cursor")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targets := []Target{{Source: "go.md", Repository: prep, Dest: ".cursor/rules/go.mdc", Frontmatter: cursor.Frontmatter}}
+	if _, err := Deploy(context.Background(), targets, Options{}, logger); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	want := "---\ndescription: Go style\nglobs: \"**/*.go\"\n---\n\n# go\n"
+	if got := readFile(t, ".cursor/rules/go.mdc"); got != want {
+		t.Errorf("go.mdc =\n%s\nwant\n%s", got, want)
+	}
+
+	manifest, err := project.LoadManifest(".")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	entry, _ := manifest.Find(".cursor/rules/go.mdc")
+	if entry.Rewrite == nil || entry.Rewrite.Rename["applyTo"] != "globs" {
+		t.Errorf("manifest entry = %+v, want the rewrite recorded", entry)
+	}
+	if c := project.Compare(".", manifest, []repository.PreparedRepository{prep}, "")[0]; c.Status != project.StatusUpToDate {
+		t.Errorf("rewritten copy compares as %q (err %v), want up to date", c.Status, c.Err)
+	}
+
+	// Links cannot be rewritten, so they show the rule as it is
+	targets = []Target{{Source: "go.md", Repository: prep, Dest: "CLAUDE.md", Frontmatter: editors.FrontmatterRewrite{Strip: true}}}
+	if _, err := Deploy(context.Background(), targets, Options{Mode: project.ModeLink}, logger); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if got := readFile(t, "CLAUDE.md"); got != rule {
+		t.Errorf("CLAUDE.md = %q, want the rule unchanged", got)
+	}
+}
+
+func TestDeployExistingDestination(t *testing.T) {
+	prep, logger := setup(t, map[string]string{"go.md": "# go\n", "python.md": "# python\n"})
+	writeFile(t, "AGENTS.md", "# local\n")
+
+	targets := []Target{
+		{Source: "go.md", Repository: prep, Dest: "go.md"},
+		{Source: "python.md", Repository: prep, Dest: "AGENTS.md"},
+	}
+	_, err := Deploy(context.Background(), targets, Options{}, logger)
+	if !errors.Is(err, ErrDestinationExists) || !strings.Contains(err.Error(), "AGENTS.md") {
+		t.Fatalf("Deploy() error = %v, want ErrDestinationExists naming AGENTS.md", err)
+	}
+	if _, err := os.Stat("go.md"); !os.IsNotExist(err) {
+		t.Error("no rule should be deployed when a destination exists")
+	}
+
+	if _, err := Deploy(context.Background(), targets, Options{Overwrite: true}, logger); err != nil {
+		t.Fatalf("Deploy with overwrite failed: %v", err)
+	}
+	if got := readFile(t, "AGENTS.md"); got != "# python\n" {
+		t.Errorf("AGENTS.md = %q, want it replaced", got)
+	}
+}
+
+func TestDeployLink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	prep, logger := setup(t, map[string]string{"go.md": "# go\n"})
+
+	targets := []Target{{Source: "go.md", Repository: prep, Dest: "rules/go.md"}}
+	if _, err := Deploy(context.Background(), targets, Options{Mode: project.ModeLink}, logger); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	info, err := os.Lstat("rules/go.md")
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("rules/go.md should be a symlink (%v)", err)
+	}
+	if got := readFile(t, "rules/go.md"); got != "# go\n" {
+		t.Errorf("link reads %q, want the rule", got)
+	}
+	manifest, err := project.LoadManifest(".")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if entry, ok := manifest.Find("rules/go.md"); !ok || entry.Mode != project.ModeLink {
+		t.Errorf("manifest entry = %+v, want a link", entry)
+	}
+}
+
+func TestDeployInvalidTargets(t *testing.T) {
+	prep, logger := setup(t, map[string]string{"go.md": "# go\n"})
+	outside := filepath.Join(t.TempDir(), "secret.md")
+	writeFile(t, outside, "# secret\n")
+
+	tests := []struct {
+		name    string
+		targets []Target
+		wantErr string
+	}{
+		{name: "missing source", targets: []Target{{Source: "missing.md", Repository: prep, Dest: "missing.md"}}, wantErr: "source file validation failed"},
+		{name: "source outside repository", targets: []Target{{Source: outside, Repository: prep, Dest: "secret.md"}}, wantErr: "source file validation failed"},
+		{name: "destination outside project", targets: []Target{{Source: "go.md", Repository: prep, Dest: "../go.md"}}, wantErr: "invalid destination path"},
+		{name: "duplicate destination", targets: []Target{
+			{Source: "go.md", Repository: prep, Dest: "AGENTS.md"},
+			{Source: "go.md", Repository: prep, Dest: "./AGENTS.md"},
+		}, wantErr: "two rules would be deployed to"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Deploy(context.Background(), tt.targets, Options{}, logger)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Deploy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := os.Stat("AGENTS.md"); !os.IsNotExist(err) {
+		t.Error("invalid deploys should write nothing")
+	}
+}
+
+func TestDeployHookVeto(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	prep, logger := setup(t, map[string]string{"go.md": "# go\n", "python.md": "# python\n"})
+
+	hooks := []config.Hook{{Event: "pre-deploy", Command: `! grep -q '"rule":"python.md"'`}}
+	targets := []Target{
+		{Source: "go.md", Repository: prep, Dest: "go.md"},
+		{Source: "python.md", Repository: prep, Dest: "python.md"},
+	}
+	_, err := Deploy(context.Background(), targets, Options{Hooks: hooks}, logger)
+	if err == nil || !strings.Contains(err.Error(), "deploy of python.md cancelled by hook") {
+		t.Fatalf("Deploy() error = %v, want the hook's veto", err)
+	}
+	for _, name := range []string{"go.md", "python.md"} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%s should not be deployed after a veto", name)
+		}
+	}
+}
+
+func TestDestination(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Join("docs", "rules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+
+	tests := []struct {
+		as   string
+		want string
+	}{
+		{as: "", want: "AGENTS.md"},
+		{as: ".cursor/rules/", want: filepath.Join(".cursor", "rules", "go-style.md")},
+		{as: "docs/rules", want: filepath.Join("docs", "rules", "go-style.md")},
+		{as: ".github/copilot-instructions.md", want: filepath.Join(".github", "copilot-instructions.md")},
+		{as: filepath.Join(cwd, "CLAUDE.md"), want: "CLAUDE.md"},
+	}
+	for _, tt := range tests {
+		got, err := Destination("go-style.md", tt.as, editors.EditorRuleConfigs[0])
+		if err != nil || got != tt.want {
+			t.Errorf("Destination(%q) = %q, %v; want %q", tt.as, got, err, tt.want)
+		}
+	}
+
+	if _, err := Destination("go-style.md", filepath.Join(filepath.Dir(cwd), "CLAUDE.md"), editors.EditorRuleConfigs[0]); err == nil {
+		t.Error("a destination outside the current directory should fail")
+	}
+}
+
+func TestFindRule(t *testing.T) {
+	prep, logger := setup(t, map[string]string{"go/style.md": "# go\n", "python/style.md": "# python\n", "testing.md": "# testing\n"})
+	prepared := []repository.PreparedRepository{prep}
+	files, err := filemanager.ScanAllRepositories(prepared, logger)
+	if err != nil {
+		t.Fatalf("ScanAllRepositories: %v", err)
+	}
+
+	for _, name := range []string{"testing", "testing.md", "go/style.md"} {
+		file, repo, err := FindRule(prepared, files, name, "")
+		if err != nil || repo.ID() != "rules-1" || !strings.HasSuffix(filepath.ToSlash(file.Path), strings.TrimSuffix(name, ".md")+".md") {
+			t.Errorf("FindRule(%q) = %s in %s, %v", name, file.Path, repo.ID(), err)
+		}
+	}
+
+	if _, _, err := FindRule(prepared, files, "style", ""); err == nil || !strings.Contains(err.Error(), "go/style.md (Rules), python/style.md (Rules)") {
+		t.Errorf("FindRule(style) error = %v, want both candidates", err)
+	}
+	if _, _, err := FindRule(prepared, files, "testing", "other-1"); err == nil || !strings.Contains(err.Error(), "no rule named 'testing'") {
+		t.Errorf("FindRule in another repository error = %v, want no rule", err)
+	}
+}
@@ -0,0 +1,79 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/project"
+	"rulem/internal/repository"
+)
+
+// Resync deploys drifted rules again from their repositories, bringing the
+// project's copies and links back in line with the central versions, as
+// `rulem status --sync` and the project status screen do.
+//
+// Parameters:
+//   - ctx: Context of the pre-deploy hooks
+//   - comparisons: The rules to re-sync, as returned by project.Compare against
+//     the working trees; each must satisfy Comparison.CanResync
+//   - prepared: The prepared repositories the rules come from
+//   - hooks: Hooks asked before and told after each rule is deployed
+//   - logger: Logger for deploy details
+//
+// Returns:
+//   - []string: Absolute paths of the re-synced rules, copies first
+//   - error: When a rule cannot be re-synced, or the deploy fails
+//
+// Every rule is deployed the way it was before: copies are copied with the
+// frontmatter rewrite recorded in the manifest, links are linked again, and
+// local edits to the copies are replaced. Copies are written all at once, as
+// with Deploy.
+func Resync(ctx context.Context, comparisons []project.Comparison, prepared []repository.PreparedRepository, hooks []config.Hook, logger *logging.AppLogger) ([]string, error) {
+	byID := make(map[string]repository.PreparedRepository, len(prepared))
+	for _, prep := range prepared {
+		byID[prep.ID()] = prep
+	}
+
+	var copies, links []Target
+	for _, c := range comparisons {
+		if !c.CanResync() {
+			return nil, fmt.Errorf("%s cannot be re-synced: %s", c.Entry.Path, c.Label())
+		}
+		prep, ok := byID[c.Entry.Repository]
+		if !ok {
+			return nil, fmt.Errorf("repository %s of %s is not available", c.Entry.Repository, c.Entry.Path)
+		}
+		target := Target{
+			Source:     filepath.FromSlash(c.Entry.Source),
+			Repository: prep,
+			Dest:       filepath.FromSlash(c.Entry.Path),
+		}
+		if c.Entry.Mode == project.ModeLink {
+			links = append(links, target)
+			continue
+		}
+		if c.Entry.Rewrite != nil {
+			target.Frontmatter = *c.Entry.Rewrite
+		}
+		copies = append(copies, target)
+	}
+
+	var paths []string
+	for _, batch := range []struct {
+		mode    project.Mode
+		targets []Target
+	}{{project.ModeCopy, copies}, {project.ModeLink, links}} {
+		if len(batch.targets) == 0 {
+			continue
+		}
+		deployed, err := Deploy(ctx, batch.targets, Options{Mode: batch.mode, Overwrite: true, Hooks: hooks}, logger)
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, deployed...)
+	}
+	return paths, nil
+}
@@ -0,0 +1,77 @@
+package deploy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/editors"
+	"rulem/internal/project"
+	"rulem/internal/repository"
+)
+
+func TestResync(t *testing.T) {
+	prep, logger := setup(t, map[string]string{
+		"go.md":     "---\ndescription: Go\n---\n# go v1\n",
+		"python.md": "# python v1\n",
+		"rust.md":   "# rust\n",
+	})
+	targets := []Target{
+		{Source: "go.md", Repository: prep, Dest: "AGENTS.md", Frontmatter: editors.FrontmatterRewrite{Strip: true}},
+		{Source: "python.md", Repository: prep, Dest: "python.md"},
+		{Source: "rust.md", Repository: prep, Dest: "rust.md"},
+	}
+	if _, err := Deploy(context.Background(), targets, Options{}, logger); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+
+	// go.md changes centrally, python.md is deleted from the project and
+	// rust.md from the repository
+	writeFile(t, filepath.Join(prep.LocalPath, "go.md"), "---\ndescription: Go\n---\n# go v2\n")
+	if err := os.Remove("python.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(prep.LocalPath, "rust.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := project.LoadManifest(".")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	prepared := []repository.PreparedRepository{prep}
+	comparisons := project.Compare(".", manifest, prepared, "")
+	var drifted []project.Comparison
+	for _, c := range comparisons {
+		if c.CanResync() {
+			drifted = append(drifted, c)
+		} else if c.Entry.Path == "rust.md" {
+			if _, err := Resync(context.Background(), []project.Comparison{c}, prepared, nil, logger); err == nil || !strings.Contains(err.Error(), "source missing") {
+				t.Errorf("Resync(rust.md) error = %v, want a rule without source refused", err)
+			}
+		}
+	}
+	if len(drifted) != 2 {
+		t.Fatalf("%d rules can be re-synced, want AGENTS.md and python.md", len(drifted))
+	}
+
+	paths, err := Resync(context.Background(), drifted, prepared, nil, logger)
+	if err != nil || len(paths) != 2 {
+		t.Fatalf("Resync() = %v, %v; want two rules re-synced", paths, err)
+	}
+	if got := readFile(t, "AGENTS.md"); got != "# go v2\n" {
+		t.Errorf("AGENTS.md = %q, want the new version rewritten like before", got)
+	}
+	if got := readFile(t, "python.md"); got != "# python v1\n" {
+		t.Errorf("python.md = %q, want it restored", got)
+	}
+
+	manifest, _ = project.LoadManifest(".")
+	for _, c := range project.Compare(".", manifest, prepared, "") {
+		if c.Entry.Path != "rust.md" && c.Status != project.StatusUpToDate {
+			t.Errorf("%s is %s after re-sync, want up to date", c.Entry.Path, c.Label())
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package deploy
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"rulem/internal/filemanager"
+	"rulem/internal/repository"
+)
+
+// FindRule finds the rule file name refers to, by its path relative to the
+// root of its repository ("go/style.md") or by its file name with or without
+// extension. Rules without a description have no MCP tool name, so commands
+// that deploy them look them up this way.
+//
+// Parameters:
+//   - prepared: The repositories files were scanned from
+//   - files: The rule files, as returned by filemanager.ScanAllRepositories
+//   - name: The path or file name the user gave
+//   - repositoryID: When set, only rules from this repository are considered
+//
+// Returns:
+//   - filemanager.FileItem: The rule file
+//   - repository.PreparedRepository: The repository it belongs to
+//   - error: When no rule matches, or several do; the error lists the candidates
+func FindRule(prepared []repository.PreparedRepository, files []filemanager.FileItem, name, repositoryID string) (filemanager.FileItem, repository.PreparedRepository, error) {
+	want := filepath.ToSlash(filepath.Clean(name))
+	repos := make(map[string]repository.PreparedRepository, len(prepared))
+	for _, prep := range prepared {
+		repos[prep.ID()] = prep
+	}
+
+	var matches []filemanager.FileItem
+	var paths []string
+	for _, file := range files {
+		prep, ok := repos[file.RepositoryID]
+		if !ok || (repositoryID != "" && file.RepositoryID != repositoryID) {
+			continue
+		}
+		rel := relativePath(prep, file)
+		base := filepath.Base(file.Path)
+		stem := strings.TrimSuffix(base, filepath.Ext(base))
+		if rel == want || base == want || stem == want {
+			matches = append(matches, file)
+			paths = append(paths, fmt.Sprintf("%s (%s)", rel, prep.Name()))
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return filemanager.FileItem{}, repository.PreparedRepository{}, fmt.Errorf("no rule named '%s'", name)
+	case 1:
+		return matches[0], repos[matches[0].RepositoryID], nil
+	}
+	sort.Strings(paths)
+	return filemanager.FileItem{}, repository.PreparedRepository{}, fmt.Errorf("'%s' matches %d rules, use its path or --repo: %s",
+		name, len(matches), strings.Join(paths, ", "))
+}
+
+// relativePath returns the slash-separated path of file relative to the root
+// of prep, the overlay for files in a shared storage overlay
+func relativePath(prep repository.PreparedRepository, file filemanager.FileItem) string {
+	for _, root := range []string{prep.OverlayPath, prep.LocalPath} {
+		if root == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(root, file.Path); err == nil && filepath.IsLocal(rel) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return file.Name
+}
@@ -0,0 +1,137 @@
+// Package diagnostics writes diagnostic bundles: one text file holding what a
+// bug report needs, so users can attach it instead of describing their setup.
+//
+// A bundle has the error the user hit, rulem's version and platform, the
+// configuration, the last user actions and the latest log entries. Everything
+// is redacted with logging.Redact before it is written; tokens are kept in the
+// credential store and never read here.
+//
+// Bundles are written to rulem/diagnostics in the user's state directory (e.g.
+// ~/.local/state/rulem/diagnostics on Linux), one file per bundle.
+package diagnostics
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/version"
+	"rulem/pkg/fileops"
+
+	"github.com/adrg/xdg"
+	"gopkg.in/yaml.v3"
+)
+
+// Bundle is the content of a diagnostic bundle
+type Bundle struct {
+	Time       time.Time
+	Version    string
+	GoVersion  string
+	Platform   string // GOOS/GOARCH
+	Error      string
+	ConfigPath string
+	Config     string   // config.yaml, redacted
+	Operations []string // Latest user actions and state transitions, oldest first
+	Log        []string // Latest log entries, oldest first
+}
+
+// Dir returns the directory bundles are written to. It can be overridden with
+// the RULEM_DIAGNOSTICS_DIR environment variable for testing.
+func Dir() string {
+	if testDir := os.Getenv("RULEM_DIAGNOSTICS_DIR"); testDir != "" {
+		return testDir
+	}
+	return filepath.Join(xdg.StateHome, "rulem", "diagnostics")
+}
+
+// Collect gathers a bundle for cause (which may be nil). The configuration is
+// read from its file so the bundle shows it as written, falling back to cfg
+// when the file cannot be read.
+func Collect(cause error, cfg *config.Config, logger *logging.AppLogger) Bundle {
+	bundle := Bundle{
+		Time:      time.Now(),
+		Version:   version.Current(),
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if cause != nil {
+		bundle.Error = logging.Redact(cause.Error())
+	}
+
+	path, err := config.Path()
+	if err == nil {
+		bundle.ConfigPath = path
+		if data, readErr := os.ReadFile(path); readErr == nil {
+			bundle.Config = logging.Redact(string(data))
+		}
+	}
+	if bundle.Config == "" && cfg != nil {
+		if data, marshalErr := yaml.Marshal(cfg); marshalErr == nil {
+			bundle.Config = logging.Redact(string(data))
+		}
+	}
+
+	if logger != nil {
+		bundle.Operations = logger.RecentOperations()
+		bundle.Log = logger.RecentEntries()
+	}
+	return bundle
+}
+
+// Render formats the bundle as plain text
+func (b Bundle) Render() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "rulem diagnostic bundle, %s\n\n", b.Time.Format(time.RFC3339))
+	fmt.Fprintf(&s, "Version:  %s\n", b.Version)
+	fmt.Fprintf(&s, "Go:       %s\n", b.GoVersion)
+	fmt.Fprintf(&s, "Platform: %s\n", b.Platform)
+
+	section := func(title string, lines []string, empty string) {
+		fmt.Fprintf(&s, "\n## %s\n\n", title)
+		if len(lines) == 0 {
+			s.WriteString(empty + "\n")
+			return
+		}
+		for _, line := range lines {
+			s.WriteString(line + "\n")
+		}
+	}
+
+	errorLines := []string{b.Error}
+	if b.Error == "" {
+		errorLines = nil
+	}
+	section("Error", errorLines, "(none)")
+
+	configTitle := "Configuration"
+	if b.ConfigPath != "" {
+		configTitle += " (" + b.ConfigPath + ")"
+	}
+	var configLines []string
+	if b.Config != "" {
+		configLines = strings.Split(strings.TrimRight(b.Config, "\n"), "\n")
+	}
+	section(configTitle, configLines, "(not available)")
+
+	section("Recent operations", b.Operations, "(none recorded)")
+	section("Recent log", b.Log, "(none recorded)")
+	return s.String()
+}
+
+// Write writes the bundle to a new file in dir and returns its path. Only the
+// user may read it.
+func (b Bundle) Write(dir string) (string, error) {
+	if err := fileops.EnsureDirectoryExists(dir); err != nil {
+		return "", fmt.Errorf("cannot create diagnostics directory: %w", err)
+	}
+	path := filepath.Join(dir, "rulem-diagnostics-"+b.Time.Format("20060102-150405")+".txt")
+	if err := os.WriteFile(path, []byte(b.Render()), 0600); err != nil {
+		return "", fmt.Errorf("failed to write diagnostic bundle: %w", err)
+	}
+	return path, nil
+}
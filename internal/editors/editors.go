@@ -15,7 +15,9 @@
 //   - Cursor
 //   - Claude code
 //   - Gemini CLI
-//   - And more as the registry grows
+//   - Windsurf
+//   - And more as the registry grows, or as configured (deploy_targets, see
+//     config.DeployTarget)
 //
 // The first entry in EditorRuleConfigs is treated as the default: the import UI
 // builds its selection list directly from this slice order, so AGENTS.md is the
@@ -24,14 +26,21 @@
 // Each configuration specifies:
 //   - The display name and description
 //   - The target file path for the rule file
-//   - How the file should be renamed (prefix, suffix, or full rename)
+//   - How the file should be renamed (prefix, suffix, template or full rename)
 //   - The new name to use for the transformation
+//   - How the frontmatter of copied rules is rewritten for the tool (see
+//     FrontmatterRewrite)
 //
 // This package serves as the central registry for supported tools and provides
 // the mapping between user-friendly names and the technical file specifications
 // required by each AI assistant or editor.
 package editors
 
+import (
+	"fmt"
+	"strings"
+)
+
 type RenameOption int
 
 const (
@@ -43,9 +52,19 @@ const (
 	RenameOptionSuffix
 	// RenameOptionFull will rename the rule file completely
 	RenameOptionFull
+	// RenameOptionTemplate will replace {name} in the new name with the rule
+	// file name without its extension
+	RenameOptionTemplate
 )
 
+// NamePlaceholder is replaced by the rule file name without its extension in
+// the new name of RenameOptionTemplate
+const NamePlaceholder = "{name}"
+
 type EditorRuleConfig struct {
+	// ID selects the configuration by name, e.g. rulem deploy --target cursor
+	ID string
+
 	// Name of the editor or the editors instruction file
 	Name string
 
@@ -63,56 +82,94 @@ type EditorRuleConfig struct {
 	// this can be used as either a prefix, suffix or full name
 	// depending on the RenameOption
 	NewName string
+
+	// Frontmatter is how the frontmatter of rules copied for the editor is
+	// rewritten; linked rules are left as they are
+	Frontmatter FrontmatterRewrite
+
+	// VSCodeSnippets adds a snippet referencing each deployed rule to the
+	// project's .vscode/, see UpdateVSCodeSnippets. Only editors running in
+	// VS Code set it, so other projects get no .vscode/ directory.
+	VSCodeSnippets bool
 }
 
 var EditorRuleConfigs = []EditorRuleConfig{
 	{
 		// https://agents.md
+		ID:           "agents",
 		Name:         "AGENTS.md (recommended)",
 		Explanation:  "Open standard supported by most AI coding tools (Cursor, GitHub Copilot, Gemini CLI, Zed, Jules and 20+ more). Stewarded by the Agentic AI Foundation under the Linux Foundation. Placed at the project root so any compatible agent picks it up automatically. Start here unless you specifically need a tool-specific file below.\nFor more information, see https://agents.md",
 		RulePath:     "./",
 		RenameOption: RenameOptionFull,
 		NewName:      "AGENTS.md",
+		Frontmatter:  FrontmatterRewrite{Strip: true},
 	},
 	{
 		// https://code.visualstudio.com/docs/copilot/customization/custom-instructions#_use-a-githubcopilot-instructionsmd-file
-		Name:         "Github Copilot - General instructions",
-		Explanation:  "Repository-wide instructions applied to all Copilot chat requests in this workspace.\nFor more information, see https://code.visualstudio.com/docs/copilot/customization/custom-instructions#_use-a-githubcopilot-instructionsmd-file",
-		RulePath:     ".github/",
-		RenameOption: RenameOptionFull,
-		NewName:      "copilot-instructions.md",
+		ID:             "copilot",
+		Name:           "Github Copilot - General instructions",
+		Explanation:    "Repository-wide instructions applied to all Copilot chat requests in this workspace.\nFor more information, see https://code.visualstudio.com/docs/copilot/customization/custom-instructions#_use-a-githubcopilot-instructionsmd-file",
+		RulePath:       ".github/",
+		RenameOption:   RenameOptionFull,
+		NewName:        "copilot-instructions.md",
+		Frontmatter:    FrontmatterRewrite{Strip: true},
+		VSCodeSnippets: true,
 	},
 	{
 		// https://code.visualstudio.com/docs/copilot/customization/custom-instructions#_use-instructionsmd-files
-		Name:         "Github Copilot - Instructions",
-		Explanation:  "Path-scoped instructions Copilot applies depending on the files in the chat's context. Note: these files normally need an 'applyTo' frontmatter property to be scoped; since rulem copies the file verbatim, add that frontmatter yourself or prefer the repository-wide 'General instructions' option above.\nFor more information, see https://code.visualstudio.com/docs/copilot/customization/custom-instructions#_use-instructionsmd-files",
-		RulePath:     ".github/instructions/",
-		RenameOption: RenameOptionSuffix,
-		NewName:      ".instructions.md",
+		ID:             "copilot-instructions",
+		Name:           "Github Copilot - Instructions",
+		Explanation:    "Path-scoped instructions Copilot applies depending on the files in the chat's context. Copies keep the rule's 'applyTo' and 'description' frontmatter, which scope the instructions; rules without 'applyTo' are only used when attached by hand, so prefer the repository-wide 'General instructions' option above for those.\nFor more information, see https://code.visualstudio.com/docs/copilot/customization/custom-instructions#_use-instructionsmd-files",
+		RulePath:       ".github/instructions/",
+		RenameOption:   RenameOptionSuffix,
+		NewName:        ".instructions.md",
+		Frontmatter:    FrontmatterRewrite{Keep: []string{"applyTo", "description"}},
+		VSCodeSnippets: true,
 	},
 	{
 		// https://cursor.com/docs/context/rules
+		ID:           "cursor",
 		Name:         "Cursor rules",
-		Explanation:  "Directory-scoped Cursor rule. Cursor only reads '.mdc' files under .cursor/rules/ (plain .md files are ignored), so the file is saved with a .mdc extension. Because rulem copies the file verbatim it has no frontmatter, so Cursor treats it as a manual/@-referenced rule rather than always-applied. For always-on rules, use the recommended AGENTS.md option, which Cursor also reads natively. Run this tool inside the directory where you want the scoped rule.\nFor more information, see https://cursor.com/docs/context/rules",
+		Explanation:  "Directory-scoped Cursor rule. Cursor only reads '.mdc' files under .cursor/rules/ (plain .md files are ignored), so the file is saved with a .mdc extension. Copies keep the rule's 'description' and turn its 'applyTo' into Cursor's 'globs', so Cursor attaches the rule to matching files or when the description is relevant. For always-on rules, use the recommended AGENTS.md option, which Cursor also reads natively. Run this tool inside the directory where you want the scoped rule.\nFor more information, see https://cursor.com/docs/context/rules",
 		RulePath:     ".cursor/rules/",
 		RenameOption: RenameOptionSuffix,
 		NewName:      ".mdc",
+		Frontmatter: FrontmatterRewrite{
+			Rename: map[string]string{"applyTo": "globs"},
+			Keep:   []string{"description", "globs", "alwaysApply"},
+		},
 	},
 	{
 		// https://code.claude.com/docs/en/memory
+		ID:           "claude",
 		Name:         "Claude code",
 		Explanation:  "This is a general instructions file that will be added to all messages. Claude Code reads CLAUDE.md, not AGENTS.md.\nFor more information, see https://code.claude.com/docs/en/memory",
 		RulePath:     "./",
 		RenameOption: RenameOptionFull,
 		NewName:      "CLAUDE.md",
+		Frontmatter:  FrontmatterRewrite{Strip: true},
 	},
 	{
 		// https://github.com/google-gemini/gemini-cli?tab=readme-ov-file#advanced-capabilities
+		ID:           "gemini",
 		Name:         "Gemini CLI",
 		Explanation:  "This is a general instructions file that will be added to all messages.\nFor more information, see https://github.com/google-gemini/gemini-cli?tab=readme-ov-file#advanced-capabilities",
 		RulePath:     "./",
 		RenameOption: RenameOptionFull,
 		NewName:      "GEMINI.md",
+		Frontmatter:  FrontmatterRewrite{Strip: true},
+	},
+	{
+		// https://docs.windsurf.com/windsurf/cascade/memories#rules
+		ID:           "windsurf",
+		Name:         "Windsurf rules",
+		Explanation:  "Workspace rule for Windsurf's Cascade, one file per rule under .windsurf/rules/. Copies keep the rule's 'description' and 'trigger' and turn its 'applyTo' into Windsurf's 'globs'.\nFor more information, see https://docs.windsurf.com/windsurf/cascade/memories#rules",
+		RulePath:     ".windsurf/rules/",
+		RenameOption: RenameOptionNone,
+		Frontmatter: FrontmatterRewrite{
+			Rename: map[string]string{"applyTo": "globs"},
+			Keep:   []string{"trigger", "description", "globs"},
+		},
 	},
 }
 
@@ -120,13 +177,33 @@ var EditorRuleConfigs = []EditorRuleConfig{
 func (c EditorRuleConfig) Title() string       { return c.Name }
 func (c EditorRuleConfig) Description() string { return c.Explanation }
 func (c EditorRuleConfig) FilterValue() string {
-	return c.Name + " " + c.Explanation + " " + c.RulePath + " " + c.NewName
+	return c.Name + " " + c.Explanation + " " + c.RulePath + " " + c.NewName + " " + c.ID
 }
 
 func GetAllEditorRuleConfigs() []EditorRuleConfig {
 	return EditorRuleConfigs
 }
 
+// FindEditorRuleConfig returns the configuration with the given ID among configs
+//
+// Parameters:
+//   - configs: The configurations to search, e.g. GetAllEditorRuleConfigs()
+//   - id: The ID asked for, e.g. "cursor"
+//
+// Returns:
+//   - EditorRuleConfig: The configuration
+//   - error: When no configuration has the ID; the error lists the known IDs
+func FindEditorRuleConfig(configs []EditorRuleConfig, id string) (EditorRuleConfig, error) {
+	ids := make([]string, 0, len(configs))
+	for _, c := range configs {
+		if c.ID == id {
+			return c, nil
+		}
+		ids = append(ids, c.ID)
+	}
+	return EditorRuleConfig{}, fmt.Errorf("unknown target '%s' (known targets: %s)", id, strings.Join(ids, ", "))
+}
+
 // GenerateRuleFileFullPath generates the full path for the rule file based on the configuration.
 // It combines the RulePath with the NewName based on the RenameOption, this path is relative to the current working directory.
 // If RenameOption is None, it returns the currentName as is.
@@ -153,6 +230,8 @@ func (c EditorRuleConfig) GenerateRuleFileFullPath(currentName string) string {
 		}
 	case RenameOptionFull:
 		newName = c.NewName
+	case RenameOptionTemplate:
+		newName = strings.ReplaceAll(c.NewName, NamePlaceholder, removeExtension(currentName))
 	case RenameOptionNone:
 		// If no renaming is specified, return the current name as is
 		newName = currentName
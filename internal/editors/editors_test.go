@@ -127,6 +127,18 @@ func TestGenerateRuleFileFullPath(t *testing.T) {
 			expected:    "./test.md",
 		},
 
+		// RenameOptionTemplate tests
+		{
+			name: "template option replaces the name placeholder",
+			config: EditorRuleConfig{
+				RulePath:     ".windsurf/rules/",
+				RenameOption: RenameOptionTemplate,
+				NewName:      "team-{name}.md",
+			},
+			currentName: "go-style.md",
+			expected:    ".windsurf/rules/team-go-style.md",
+		},
+
 		// RenameOptionFull tests
 		{
 			name: "full option replaces entire name",
@@ -0,0 +1,188 @@
+package editors
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter rewrites
+//
+// Rules keep rulem's frontmatter (description, applyTo, tags...), which most
+// editors either ignore or show to the model as text, and some expect under
+// other names: Cursor scopes .mdc rules with 'globs' where Copilot uses
+// 'applyTo'. A FrontmatterRewrite turns the frontmatter of a rule into the one
+// its editor reads when the rule is copied into a project. Only YAML (---)
+// frontmatter is rewritten; rules with another kind are copied as they are.
+
+// FrontmatterRewrite describes how the frontmatter of a rule is rewritten for
+// an editor. The zero value leaves rules unchanged.
+//
+// Steps are applied in field order: Strip removes the whole frontmatter,
+// otherwise keys are renamed, keys not in Keep are dropped, and Set adds or
+// replaces keys. A frontmatter left without keys is removed.
+type FrontmatterRewrite struct {
+	Strip  bool              `yaml:"strip,omitempty"`  // Remove the frontmatter
+	Rename map[string]string `yaml:"rename,omitempty"` // Keys to rename, e.g. applyTo: globs
+	Keep   []string          `yaml:"keep,omitempty"`   // Keys kept after renaming; empty keeps every key
+	Set    map[string]string `yaml:"set,omitempty"`    // Keys to add or replace, values in YAML syntax
+}
+
+// IsZero reports whether the rewrite leaves rules unchanged
+func (r FrontmatterRewrite) IsZero() bool {
+	return !r.Strip && len(r.Rename) == 0 && len(r.Keep) == 0 && len(r.Set) == 0
+}
+
+// Validate checks that every key is non-empty and every Set value is valid YAML
+func (r FrontmatterRewrite) Validate() error {
+	for from, to := range r.Rename {
+		if strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			return fmt.Errorf("rename needs non-empty keys, got '%s: %s'", from, to)
+		}
+	}
+	for _, key := range r.Keep {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("keep cannot list an empty key")
+		}
+	}
+	for key, value := range r.Set {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("set cannot have an empty key")
+		}
+		if _, err := yamlValue(value); err != nil {
+			return fmt.Errorf("invalid value of %s in set: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Apply rewrites the frontmatter of content.
+//
+// Parameters:
+//   - content: The rule file
+//
+// Returns:
+//   - []byte: The rewritten rule; content itself when the rewrite is zero or
+//     the rule has no YAML frontmatter and nothing to set
+//   - error: When the frontmatter is not a YAML mapping or a Set value is invalid
+func (r FrontmatterRewrite) Apply(content []byte) ([]byte, error) {
+	if r.IsZero() {
+		return content, nil
+	}
+
+	block, body, ok := splitYAMLFrontmatter(content)
+	if r.Strip {
+		if !ok {
+			return content, nil
+		}
+		return bytes.TrimLeft(body, "\r\n"), nil
+	}
+	if !ok && len(r.Set) == 0 {
+		return content, nil
+	}
+
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if ok {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(block, &doc); err != nil {
+			return nil, fmt.Errorf("invalid frontmatter: %w", err)
+		}
+		if len(doc.Content) > 0 {
+			if doc.Content[0].Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("invalid frontmatter: not a mapping of keys to values")
+			}
+			mapping = doc.Content[0]
+		}
+	}
+
+	if err := r.rewriteMapping(mapping); err != nil {
+		return nil, err
+	}
+	if len(mapping.Content) == 0 {
+		return bytes.TrimLeft(body, "\r\n"), nil
+	}
+
+	var out bytes.Buffer
+	out.WriteString("---\n")
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(mapping); err != nil {
+		return nil, fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	encoder.Close()
+	out.WriteString("---\n")
+	if !ok {
+		out.WriteString("\n")
+	}
+	out.Write(body)
+	return out.Bytes(), nil
+}
+
+// rewriteMapping renames, drops and sets the keys of a frontmatter mapping
+func (r FrontmatterRewrite) rewriteMapping(mapping *yaml.Node) error {
+	keep := make(map[string]bool, len(r.Keep))
+	for _, key := range r.Keep {
+		keep[key] = true
+	}
+
+	pairs := mapping.Content[:0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if to, ok := r.Rename[key.Value]; ok {
+			key.Value = to
+		}
+		if len(keep) > 0 && !keep[key.Value] {
+			continue
+		}
+		if _, ok := r.Set[key.Value]; ok {
+			continue // Replaced below
+		}
+		pairs = append(pairs, key, value)
+	}
+	mapping.Content = pairs
+
+	keys := make([]string, 0, len(r.Set))
+	for key := range r.Set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := yamlValue(r.Set[key])
+		if err != nil {
+			return fmt.Errorf("invalid value of %s in set: %w", key, err)
+		}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	}
+	return nil
+}
+
+// yamlValue parses a Set value, so "false" stays a boolean and "[a, b]" a list
+func yamlValue(value string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: ""}, nil
+	}
+	return doc.Content[0], nil
+}
+
+// splitYAMLFrontmatter splits content into the YAML frontmatter block between
+// its --- lines and the body after it; ok is false when content does not
+// start with one
+func splitYAMLFrontmatter(content []byte) (block, body []byte, ok bool) {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if len(lines) == 0 || strings.TrimRight(string(lines[0]), " \t\r\n") != "---" {
+		return nil, content, false
+	}
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimRight(string(lines[i]), " \t\r\n"); line == "---" || line == "..." {
+			return bytes.Join(lines[1:i], nil), bytes.Join(lines[i+1:], nil), true
+		}
+	}
+	return nil, content, false
+}
@@ -0,0 +1,122 @@
+package editors
+
+import (
+	"strings"
+	"testing"
+)
+
+const ruleWithFrontmatter = `---
+description: Go style guide
+applyTo: "**/*.go"
+tags: [go, style]
+---
+
+# Go style
+`
+
+func TestFrontmatterRewriteApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		rewrite FrontmatterRewrite
+		content string
+		want    string
+	}{
+		{
+			name:    "zero rewrite keeps the rule",
+			content: ruleWithFrontmatter,
+			want:    ruleWithFrontmatter,
+		},
+		{
+			name:    "strip removes the frontmatter",
+			rewrite: FrontmatterRewrite{Strip: true},
+			content: ruleWithFrontmatter,
+			want:    "# Go style\n",
+		},
+		{
+			name:    "strip without frontmatter",
+			rewrite: FrontmatterRewrite{Strip: true},
+			content: "# Go style\n",
+			want:    "# Go style\n",
+		},
+		{
+			name:    "rename and keep",
+			rewrite: FrontmatterRewrite{Rename: map[string]string{"applyTo": "globs"}, Keep: []string{"description", "globs"}},
+			content: ruleWithFrontmatter,
+			want:    "---\ndescription: Go style guide\nglobs: \"**/*.go\"\n---\n\n# Go style\n",
+		},
+		{
+			name:    "set replaces and adds keys",
+			rewrite: FrontmatterRewrite{Set: map[string]string{"alwaysApply": "false", "description": "Go"}},
+			content: ruleWithFrontmatter,
+			want:    "---\napplyTo: \"**/*.go\"\ntags: [go, style]\nalwaysApply: false\ndescription: Go\n---\n\n# Go style\n",
+		},
+		{
+			name:    "set adds a frontmatter",
+			rewrite: FrontmatterRewrite{Set: map[string]string{"trigger": "always_on"}},
+			content: "# Go style\n",
+			want:    "---\ntrigger: always_on\n---\n\n# Go style\n",
+		},
+		{
+			name:    "keeping no key removes the frontmatter",
+			rewrite: FrontmatterRewrite{Keep: []string{"alwaysApply"}},
+			content: ruleWithFrontmatter,
+			want:    "# Go style\n",
+		},
+		{
+			name:    "other frontmatter is left alone",
+			rewrite: FrontmatterRewrite{Keep: []string{"description"}},
+			content: "+++\ndescription = \"Go\"\n+++\n# Go style\n",
+			want:    "+++\ndescription = \"Go\"\n+++\n# Go style\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rewrite.Apply([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Apply() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrontmatterRewriteErrors(t *testing.T) {
+	rewrite := FrontmatterRewrite{Keep: []string{"description"}}
+	if _, err := rewrite.Apply([]byte("---\n- a\n- b\n---\n# list\n")); err == nil || !strings.Contains(err.Error(), "not a mapping") {
+		t.Errorf("Apply() error = %v, want a frontmatter that is not a mapping rejected", err)
+	}
+
+	invalid := []FrontmatterRewrite{
+		{Rename: map[string]string{"applyTo": ""}},
+		{Keep: []string{" "}},
+		{Set: map[string]string{"globs": "[unclosed"}},
+	}
+	for _, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", r)
+		}
+	}
+	if err := EditorRuleConfigs[3].Frontmatter.Validate(); err != nil {
+		t.Errorf("built-in rewrite is invalid: %v", err)
+	}
+}
+
+func TestFindEditorRuleConfig(t *testing.T) {
+	cursor, err := FindEditorRuleConfig(GetAllEditorRuleConfigs(), "cursor")
+	if err != nil || cursor.GenerateRuleFileFullPath("go.md") != ".cursor/rules/go.mdc" {
+		t.Errorf("FindEditorRuleConfig(cursor) = %+v, %v", cursor, err)
+	}
+	if _, err := FindEditorRuleConfig(GetAllEditorRuleConfigs(), "vim"); err == nil || !strings.Contains(err.Error(), "agents, copilot") {
+		t.Errorf("FindEditorRuleConfig(vim) error = %v, want the known targets listed", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range EditorRuleConfigs {
+		if c.ID == "" || seen[c.ID] {
+			t.Errorf("built-in %q needs a unique ID, got %q", c.Name, c.ID)
+		}
+		seen[c.ID] = true
+	}
+}
@@ -0,0 +1,345 @@
+package editors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// VSCodeSnippetsFile is the project-scoped snippets file rulem maintains,
+// relative to the project root. VS Code loads every *.code-snippets file in .vscode/.
+const VSCodeSnippetsFile = ".vscode/rulem.code-snippets"
+
+// vscodeSnippetKeyPrefix marks snippet entries owned by rulem so they can be
+// replaced on re-import without touching snippets the user added themselves.
+const vscodeSnippetKeyPrefix = "rulem: "
+
+// VSCodeSnippet is a single entry in a VS Code .code-snippets file
+type VSCodeSnippet struct {
+	Prefix      string   `json:"prefix"`
+	Body        []string `json:"body"`
+	Description string   `json:"description,omitempty"`
+}
+
+// NewRuleReferenceSnippet builds a snippet that inserts a reference comment
+// pointing at a rule file deployed in the project, e.g. typing "rulem-go-style"
+// inserts "<!-- rulem: go-style (.github/instructions/go-style.instructions.md) -->".
+//
+// Parameters:
+//   - ruleName: Rule file name as stored in the central repository
+//   - deployedPath: Path of the deployed rule relative to the project root
+func NewRuleReferenceSnippet(ruleName, deployedPath string) VSCodeSnippet {
+	base := removeExtension(filepath.Base(ruleName))
+	deployedPath = filepath.ToSlash(deployedPath)
+
+	return VSCodeSnippet{
+		Prefix:      "rulem-" + base,
+		Body:        []string{fmt.Sprintf("<!-- rulem: %s (%s) -->", base, deployedPath)},
+		Description: fmt.Sprintf("Reference the %s rule deployed at %s", base, deployedPath),
+	}
+}
+
+// UpdateVSCodeSnippets adds or replaces the snippet for a rule in the project's
+// rulem snippets file, creating .vscode/ and the file if needed. Only the
+// rule's own entry is rewritten: snippets the user added, their comments and
+// their formatting are kept as they are. Like VS Code, the file may contain
+// comments and trailing commas (JSONC).
+//
+// Parameters:
+//   - projectDir: Project root directory (typically the current working directory)
+//   - ruleName: Rule file name as stored in the central repository
+//   - deployedPath: Path of the deployed rule relative to projectDir
+//
+// Returns:
+//   - string: Path of the snippets file that was written
+//   - error: If the existing file cannot be parsed or the file cannot be written;
+//     an unparsable file is never overwritten
+func UpdateVSCodeSnippets(projectDir, ruleName, deployedPath string) (string, error) {
+	snippetsPath := filepath.Join(projectDir, filepath.FromSlash(VSCodeSnippetsFile))
+
+	data, err := os.ReadFile(snippetsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("cannot read snippets file: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte("{}\n")
+	}
+
+	key := vscodeSnippetKeyPrefix + removeExtension(filepath.Base(ruleName))
+	value, err := json.MarshalIndent(NewRuleReferenceSnippet(ruleName, deployedPath), "  ", "  ")
+	if err != nil {
+		return "", fmt.Errorf("cannot encode snippet %s: %w", key, err)
+	}
+	data, err = setSnippet(data, key, value)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse existing snippets file %s: %w", snippetsPath, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(snippetsPath), 0755); err != nil {
+		return "", fmt.Errorf("cannot create .vscode directory: %w", err)
+	}
+
+	// Write to a temp file and rename so a crash never leaves a truncated file
+	tmpPath := snippetsPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return "", fmt.Errorf("cannot write snippets file: %w", err)
+	}
+	if err := os.Rename(tmpPath, snippetsPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("cannot replace snippets file: %w", err)
+	}
+
+	return snippetsPath, nil
+}
+
+// snippetEntry locates a top-level entry of a snippets file
+type snippetEntry struct {
+	key        string
+	valueStart int // Offset of the first byte of the value
+	valueEnd   int // Offset just past the value
+}
+
+// setSnippet returns the JSONC snippets file data with the value of key
+// replaced, or the entry appended when there is none. Everything else is
+// copied byte for byte.
+func setSnippet(data []byte, key string, value []byte) ([]byte, error) {
+	open, close, entries, err := scanSnippets(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	for _, entry := range entries {
+		if entry.key == key {
+			b.Write(data[:entry.valueStart])
+			b.Write(value)
+			b.Write(data[entry.valueEnd:])
+			return b.Bytes(), nil
+		}
+	}
+
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	entry := append(append(keyJSON, ": "...), value...)
+	switch {
+	case len(entries) > 0:
+		// After the last value, before any trailing comma or comment
+		at := entries[len(entries)-1].valueEnd
+		b.Write(data[:at])
+		b.WriteString(",\n  ")
+		b.Write(entry)
+		b.Write(data[at:])
+	case len(bytes.TrimSpace(data[open+1:close])) == 0:
+		b.Write(data[:open+1])
+		b.WriteString("\n  ")
+		b.Write(entry)
+		b.WriteString("\n")
+		b.Write(data[close:])
+	default:
+		// An object holding only comments
+		b.Write(data[:open+1])
+		b.WriteString("\n  ")
+		b.Write(entry)
+		b.WriteString(",")
+		b.Write(data[open+1:])
+	}
+	return b.Bytes(), nil
+}
+
+// scanSnippets parses a JSONC object and returns the offsets of its braces
+// and its top-level entries. Values are checked to be valid JSON once
+// comments and trailing commas are removed.
+func scanSnippets(data []byte) (open, close int, entries []snippetEntry, err error) {
+	s := jsoncScanner{data: data}
+	s.skipSpace()
+	if !s.consume('{') {
+		return 0, 0, nil, fmt.Errorf("expected a JSON object")
+	}
+	open = s.pos - 1
+	for {
+		s.skipSpace()
+		if s.consume('}') {
+			close = s.pos - 1
+			break
+		}
+		if len(entries) > 0 {
+			if !s.consume(',') {
+				return 0, 0, nil, fmt.Errorf("expected ',' or '}' at offset %d", s.pos)
+			}
+			s.skipSpace()
+			if s.consume('}') {
+				close = s.pos - 1
+				break
+			}
+		}
+
+		keyStart := s.pos
+		if err := s.skipString(); err != nil {
+			return 0, 0, nil, err
+		}
+		var key string
+		if err := json.Unmarshal(data[keyStart:s.pos], &key); err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid key at offset %d: %w", keyStart, err)
+		}
+		s.skipSpace()
+		if !s.consume(':') {
+			return 0, 0, nil, fmt.Errorf("expected ':' after key %q", key)
+		}
+		s.skipSpace()
+		entry := snippetEntry{key: key, valueStart: s.pos}
+		if err := s.skipValue(); err != nil {
+			return 0, 0, nil, err
+		}
+		entry.valueEnd = s.pos
+		var value any
+		if err := json.Unmarshal(stripJSONC(data[entry.valueStart:entry.valueEnd]), &value); err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid value of %q: %w", key, err)
+		}
+		entries = append(entries, entry)
+	}
+	s.skipSpace()
+	if s.pos != len(data) {
+		return 0, 0, nil, fmt.Errorf("unexpected content after the object at offset %d", s.pos)
+	}
+	return open, close, entries, nil
+}
+
+// jsoncScanner walks JSON with comments, without decoding it
+type jsoncScanner struct {
+	data []byte
+	pos  int
+}
+
+// consume skips c if it is the next byte
+func (s *jsoncScanner) consume(c byte) bool {
+	if s.pos < len(s.data) && s.data[s.pos] == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// skipSpace skips whitespace and // and /* */ comments
+func (s *jsoncScanner) skipSpace() {
+	for s.pos < len(s.data) {
+		rest := s.data[s.pos:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || rest[0] == '\n':
+			s.pos++
+		case bytes.HasPrefix(rest, []byte("//")):
+			if end := bytes.IndexByte(rest, '\n'); end >= 0 {
+				s.pos += end + 1
+			} else {
+				s.pos = len(s.data)
+			}
+		case bytes.HasPrefix(rest, []byte("/*")):
+			if end := bytes.Index(rest[2:], []byte("*/")); end >= 0 {
+				s.pos += end + 4
+			} else {
+				s.pos = len(s.data)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// skipString skips a string literal
+func (s *jsoncScanner) skipString() error {
+	start := s.pos
+	if !s.consume('"') {
+		return fmt.Errorf("expected a string at offset %d", start)
+	}
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return nil
+		default:
+			s.pos++
+		}
+	}
+	return fmt.Errorf("unterminated string at offset %d", start)
+}
+
+// skipValue skips a value: a string, an object or array with everything
+// nested in it, or a number or literal
+func (s *jsoncScanner) skipValue() error {
+	if s.pos >= len(s.data) {
+		return fmt.Errorf("expected a value at the end of the file")
+	}
+	switch s.data[s.pos] {
+	case '"':
+		return s.skipString()
+	case '{', '[':
+		depth := 0
+		for {
+			s.skipSpace()
+			if s.pos >= len(s.data) {
+				return fmt.Errorf("unterminated object or array")
+			}
+			switch s.data[s.pos] {
+			case '"':
+				if err := s.skipString(); err != nil {
+					return err
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			s.pos++
+			if depth == 0 {
+				return nil
+			}
+		}
+	default:
+		start := s.pos
+		for s.pos < len(s.data) && !bytes.ContainsRune([]byte(" \t\r\n,}]/"), rune(s.data[s.pos])) {
+			s.pos++
+		}
+		if s.pos == start {
+			return fmt.Errorf("expected a value at offset %d", start)
+		}
+		return nil
+	}
+}
+
+// stripJSONC returns data without comments and trailing commas, as plain JSON
+func stripJSONC(data []byte) []byte {
+	s := jsoncScanner{data: data}
+	var out []byte
+	for s.pos < len(data) {
+		c := data[s.pos]
+		switch {
+		case c == '"':
+			start := s.pos
+			if s.skipString() != nil {
+				return append(out, data[start:]...)
+			}
+			out = append(out, data[start:s.pos]...)
+		case c == '/' && s.pos+1 < len(data) && (data[s.pos+1] == '/' || data[s.pos+1] == '*'):
+			s.skipSpace()
+			out = append(out, ' ')
+		case c == ',':
+			s.pos++
+			rest := jsoncScanner{data: data, pos: s.pos}
+			rest.skipSpace()
+			if rest.pos < len(data) && (data[rest.pos] == '}' || data[rest.pos] == ']') {
+				continue
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+			s.pos++
+		}
+	}
+	return out
+}
@@ -0,0 +1,147 @@
+package editors
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewRuleReferenceSnippet(t *testing.T) {
+	snippet := NewRuleReferenceSnippet("go-style.md", filepath.Join(".github", "instructions", "go-style.instructions.md"))
+
+	if snippet.Prefix != "rulem-go-style" {
+		t.Errorf("prefix = %q, want %q", snippet.Prefix, "rulem-go-style")
+	}
+	want := "<!-- rulem: go-style (.github/instructions/go-style.instructions.md) -->"
+	if len(snippet.Body) != 1 || snippet.Body[0] != want {
+		t.Errorf("body = %v, want [%q]", snippet.Body, want)
+	}
+}
+
+func TestUpdateVSCodeSnippets(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		wantKeys []string
+		wantErr  bool
+	}{
+		{
+			name:     "creates file when missing",
+			wantKeys: []string{"rulem: go-style"},
+		},
+		{
+			name:     "preserves user snippets",
+			existing: `{"mine": {"prefix": "mine", "body": ["x"]}}`,
+			wantKeys: []string{"mine", "rulem: go-style"},
+		},
+		{
+			name:     "replaces existing rulem entry",
+			existing: `{"rulem: go-style": {"prefix": "old", "body": ["old"]}}`,
+			wantKeys: []string{"rulem: go-style"},
+		},
+		{
+			name:     "keeps string bodies and unknown keys",
+			existing: `{"mine": {"prefix": "mine", "body": "x", "scope": "go", "isFileTemplate": true}}`,
+			wantKeys: []string{"mine", "rulem: go-style"},
+		},
+		{
+			name:     "tolerates comments and trailing commas",
+			existing: "{\n  // Team snippets\n  \"mine\": {\"prefix\": \"mine\", \"body\": [\"x\"],}, /* end */\n}\n",
+			wantKeys: []string{"mine", "rulem: go-style"},
+		},
+		{
+			name:     "only comments",
+			existing: "{\n  // nothing yet\n}\n",
+			wantKeys: []string{"rulem: go-style"},
+		},
+		{
+			name:     "refuses to overwrite unparsable file",
+			existing: `{not json`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			snippetsPath := filepath.Join(dir, filepath.FromSlash(VSCodeSnippetsFile))
+			if tt.existing != "" {
+				if err := os.MkdirAll(filepath.Dir(snippetsPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(snippetsPath, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			path, err := UpdateVSCodeSnippets(dir, "go-style.md", "AGENTS.md")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				data, _ := os.ReadFile(snippetsPath)
+				if string(data) != tt.existing {
+					t.Errorf("unparsable file was modified: %q", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateVSCodeSnippets returned error: %v", err)
+			}
+			if path != snippetsPath {
+				t.Errorf("path = %q, want %q", path, snippetsPath)
+			}
+
+			data, err := os.ReadFile(snippetsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]json.RawMessage
+			if err := json.Unmarshal(stripJSONC(data), &got); err != nil {
+				t.Fatalf("written file is not valid JSONC: %v\n%s", err, data)
+			}
+			if len(got) != len(tt.wantKeys) {
+				t.Errorf("got %d snippets, want %d", len(got), len(tt.wantKeys))
+			}
+			for _, k := range tt.wantKeys {
+				if _, ok := got[k]; !ok {
+					t.Errorf("missing snippet %q", k)
+				}
+			}
+			var rulem VSCodeSnippet
+			if err := json.Unmarshal(got["rulem: go-style"], &rulem); err != nil || rulem.Prefix != "rulem-go-style" {
+				t.Errorf("rulem entry was not updated: %s", got["rulem: go-style"])
+			}
+			if mine, ok := got["mine"]; ok && !strings.Contains(string(stripJSONC([]byte(tt.existing))), string(mine)) {
+				t.Errorf("user snippet was rewritten: %s", mine)
+			}
+		})
+	}
+}
+
+func TestUpdateVSCodeSnippets_KeepsFile(t *testing.T) {
+	dir := t.TempDir()
+	snippetsPath := filepath.Join(dir, filepath.FromSlash(VSCodeSnippetsFile))
+	existing := "{\n  // Team snippets\n  \"mine\": {\"prefix\": \"mine\", \"body\": \"x\", \"scope\": \"go\"},\n  \"rulem: go-style\": {\"prefix\": \"old\", \"body\": [\"old\"]}\n}\n"
+	if err := os.MkdirAll(filepath.Dir(snippetsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(snippetsPath, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := UpdateVSCodeSnippets(dir, "go-style.md", "AGENTS.md"); err != nil {
+		t.Fatalf("UpdateVSCodeSnippets returned error: %v", err)
+	}
+	data, err := os.ReadFile(snippetsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Everything before the rulem entry's value is untouched
+	prefix := existing[:strings.Index(existing, `{"prefix": "old"`)]
+	if !strings.HasPrefix(string(data), prefix) || strings.Contains(string(data), `"old"`) {
+		t.Errorf("snippets file = %q, want only the rulem entry replaced", data)
+	}
+}
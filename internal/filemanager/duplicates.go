@@ -0,0 +1,106 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+
+	"rulem/internal/logging"
+	"rulem/pkg/fileops"
+)
+
+// Duplicate rules
+//
+// The same rule is easily saved twice under different names, or into several
+// repositories. Files are compared by their SHA-256 (see fileops.HashFile),
+// and only files of the same size are hashed, so finding duplicates in a large
+// collection reads few files. Files that cannot be read are left out.
+
+// DuplicateGroup is a set of rule files with identical content
+type DuplicateGroup struct {
+	Hash  string     // Hex SHA-256 of the content
+	Files []FileItem // At least two files, in the order they were given
+}
+
+// FindDuplicates returns the groups of identical files among files, e.g. the
+// result of ScanAllRepositories, in the order of their first file
+func FindDuplicates(files []FileItem) []DuplicateGroup {
+	bySize := make(map[int64][]int)
+	for i, file := range files {
+		info, err := os.Stat(file.Path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], i)
+	}
+
+	hashes := make(map[int]string)
+	for _, indexes := range bySize {
+		if len(indexes) < 2 {
+			continue
+		}
+		for _, i := range indexes {
+			hash, err := fileops.HashFile(files[i].Path)
+			if err != nil {
+				logging.Debug("Skipping unreadable file in duplicate search", "path", files[i].Path, "error", err)
+				continue
+			}
+			hashes[i] = hash
+		}
+	}
+
+	var groups []DuplicateGroup
+	groupOf := make(map[string]int)
+	for i, file := range files {
+		hash, ok := hashes[i]
+		if !ok {
+			continue
+		}
+		if g, ok := groupOf[hash]; ok {
+			groups[g].Files = append(groups[g].Files, file)
+			continue
+		}
+		groupOf[hash] = len(groups)
+		groups = append(groups, DuplicateGroup{Hash: hash, Files: []FileItem{file}})
+	}
+
+	// Drop the hashes shared by no other file
+	duplicates := groups[:0]
+	for _, group := range groups {
+		if len(group.Files) > 1 {
+			duplicates = append(duplicates, group)
+		}
+	}
+	return duplicates
+}
+
+// FindIdentical returns the files among files whose content is content, e.g.
+// to warn before saving a rule that is already in a repository
+func FindIdentical(files []FileItem, content []byte) []FileItem {
+	hash := fileops.HashContent(content)
+	var identical []FileItem
+	for _, file := range files {
+		info, err := os.Stat(file.Path)
+		if err != nil || !info.Mode().IsRegular() || info.Size() != int64(len(content)) {
+			continue
+		}
+		if fileHash, err := fileops.HashFile(file.Path); err == nil && fileHash == hash {
+			identical = append(identical, file)
+		}
+	}
+	return identical
+}
+
+// FindDuplicates scans the repository and returns its groups of identical
+// rule files. Use the package-level FindDuplicates with the result of
+// ScanAllRepositories to compare several repositories.
+//
+// Returns:
+//   - []DuplicateGroup: Identical files, empty when every file is unique
+//   - error: Scanning errors
+func (fm *FileManager) FindDuplicates() ([]DuplicateGroup, error) {
+	files, err := fm.ScanRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan for duplicates: %w", err)
+	}
+	return FindDuplicates(files), nil
+}
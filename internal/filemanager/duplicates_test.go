@@ -0,0 +1,94 @@
+package filemanager
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	personal := createTempDirStructure(t, map[string]string{
+		"go-style.md":    "# Go style",
+		"testing.md":     "# Testing",
+		"same-size-1.md": "# aaaa",
+		"same-size-2.md": "# bbbb",
+	})
+	team := createTempDirStructure(t, map[string]string{
+		"go/style.md":   "# Go style",
+		"go/copy.md":    "# Go style",
+		"testing-v2.md": "# Testing",
+		"unique.md":     "# Unique",
+	})
+
+	var files []FileItem
+	for _, dir := range []string{personal, team} {
+		fm, err := NewFileManager(dir, createTestLogger())
+		if err != nil {
+			t.Fatalf("Failed to create FileManager: %v", err)
+		}
+		scanned, err := fm.ScanRepository()
+		if err != nil {
+			t.Fatalf("ScanRepository failed: %v", err)
+		}
+		files = append(files, scanned...)
+	}
+
+	groups := FindDuplicates(files)
+	if len(groups) != 2 {
+		t.Fatalf("found %d groups, want 2: %+v", len(groups), groups)
+	}
+	byName := map[string][]string{}
+	for _, group := range groups {
+		if len(group.Hash) != 64 {
+			t.Errorf("Hash = %q, want a hex SHA-256", group.Hash)
+		}
+		var names []string
+		for _, file := range group.Files {
+			rel, _ := filepath.Rel(filepath.Dir(filepath.Dir(file.Path)), file.Path)
+			names = append(names, rel)
+		}
+		byName[group.Files[0].Name] = names
+	}
+	if got := byName["go-style.md"]; len(got) != 3 {
+		t.Errorf("Go style duplicates = %v, want the 3 copies", got)
+	}
+	if got := byName["testing.md"]; len(got) != 2 {
+		t.Errorf("testing duplicates = %v, want 2 copies", got)
+	}
+
+	// Within one repository
+	fm, err := NewFileManager(team, createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create FileManager: %v", err)
+	}
+	groups, err = fm.FindDuplicates()
+	if err != nil {
+		t.Fatalf("FindDuplicates failed: %v", err)
+	}
+	if len(groups) != 1 || len(groups[0].Files) != 2 {
+		t.Errorf("groups = %+v, want go/style.md and go/copy.md", groups)
+	}
+}
+
+func TestFindIdentical(t *testing.T) {
+	storageDir := createTempDirStructure(t, map[string]string{
+		"style.md":    "# Go style",
+		"go/again.md": "# Go style",
+		"other.md":    "# Other one",
+	})
+	fm, err := NewFileManager(storageDir, createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create FileManager: %v", err)
+	}
+	files, err := fm.ScanRepository()
+	if err != nil {
+		t.Fatalf("ScanRepository failed: %v", err)
+	}
+
+	identical := FindIdentical(files, []byte("# Go style"))
+	if len(identical) != 2 {
+		t.Errorf("identical = %+v, want style.md and go/again.md", identical)
+	}
+	if got := FindIdentical(files, []byte("# Go style\n")); len(got) != 0 {
+		t.Errorf("identical = %+v, want none for different bytes", got)
+	}
+}
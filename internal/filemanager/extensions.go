@@ -0,0 +1,72 @@
+package filemanager
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Rule extensions
+//
+// Scans look for rules in markdown files by default, .mdc (Cursor) included.
+// Rules kept in other files, such as plain text or YAML documents, are found
+// once their extensions are listed under rule_extensions in config.yaml,
+// which replaces DefaultRuleExtensions. How a file's frontmatter is read
+// depends on its extension, see the rule formats of the mcp package.
+
+// DefaultRuleExtensions are the extensions of the files scanned for rules
+// unless rule_extensions in config.yaml replaces them
+var DefaultRuleExtensions = []string{".md", ".mdown", ".mkdn", ".mkd", ".markdown", ".mdc"}
+
+// activeExtensions are the rule extensions of this process; nil means the defaults
+var activeExtensions atomic.Pointer[[]string]
+
+// UseRuleExtensions makes the scans of this process look for rules in files
+// with the given extensions instead of DefaultRuleExtensions; nil restores the
+// defaults. Extensions are matched case-insensitively, with or without their
+// leading dot.
+func UseRuleExtensions(extensions []string) {
+	if extensions == nil {
+		activeExtensions.Store(nil)
+		return
+	}
+	normalized := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		if ext = NormalizeRuleExtension(ext); ext != "" && !slices.Contains(normalized, ext) {
+			normalized = append(normalized, ext)
+		}
+	}
+	activeExtensions.Store(&normalized)
+}
+
+// RuleExtensions returns the extensions scans look for rules in
+func RuleExtensions() []string {
+	if extensions := activeExtensions.Load(); extensions != nil {
+		return slices.Clone(*extensions)
+	}
+	return slices.Clone(DefaultRuleExtensions)
+}
+
+// NormalizeRuleExtension returns ext in lower case with a leading dot, or ""
+// for a blank extension
+func NormalizeRuleExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext == "" || ext == "." {
+		return ""
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// isRuleFile checks if a filename has one of the rule extensions.
+// This function is used as a file filter for the directory scanner.
+func isRuleFile(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if extensions := activeExtensions.Load(); extensions != nil {
+		return slices.Contains(*extensions, ext)
+	}
+	return slices.Contains(DefaultRuleExtensions, ext)
+}
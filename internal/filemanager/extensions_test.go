@@ -0,0 +1,56 @@
+package filemanager
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUseRuleExtensions(t *testing.T) {
+	useTestScanCache(t)
+	t.Cleanup(func() { UseRuleExtensions(nil) })
+	root := createTempDirStructure(t, map[string]string{
+		"style.md":         "# Style",
+		"cursor/go.mdc":    "# Go",
+		"notes/review.txt": "Review checklist",
+		"rules/api.YAML":   "description: API\ncontent: Use REST\n",
+	})
+
+	want := []string{"go.mdc", "style.md"}
+	if got := scannedNames(t, root); !slices.Equal(got, want) {
+		t.Errorf("default scan = %v, want %v", got, want)
+	}
+
+	// Changing the extensions changes the listing, even though the cached
+	// listing's directories are unchanged
+	UseRuleExtensions([]string{".md", "txt", " .Yaml ", ".md"})
+	if got, want := RuleExtensions(), []string{".md", ".txt", ".yaml"}; !slices.Equal(got, want) {
+		t.Errorf("RuleExtensions() = %v, want %v", got, want)
+	}
+	want = []string{"api.YAML", "review.txt", "style.md"}
+	if got := scannedNames(t, root); !slices.Equal(got, want) {
+		t.Errorf("scan with extensions = %v, want %v", got, want)
+	}
+	if !IsRuleFilePath("notes/review.txt") || IsRuleFilePath("cursor/go.mdc") {
+		t.Error("IsRuleFilePath() does not follow the rule extensions")
+	}
+
+	UseRuleExtensions(nil)
+	if got := RuleExtensions(); !slices.Equal(got, DefaultRuleExtensions) {
+		t.Errorf("RuleExtensions() after reset = %v, want the defaults", got)
+	}
+}
+
+func TestNormalizeRuleExtension(t *testing.T) {
+	tests := map[string]string{
+		".md":    ".md",
+		"txt":    ".txt",
+		" .YML ": ".yml",
+		"":       "",
+		".":      "",
+	}
+	for ext, want := range tests {
+		if got := NormalizeRuleExtension(ext); got != want {
+			t.Errorf("NormalizeRuleExtension(%q) = %q, want %q", ext, got, want)
+		}
+	}
+}
@@ -13,7 +13,7 @@
 //
 // The FileManager struct is the primary entry point, providing methods for:
 //   - Copying files to/from storage with safety checks
-//   - Scanning repositories for markdown files
+//   - Scanning repositories for rule files
 //   - Managing storage directory structure
 //   - Validating file paths and permissions
 //
@@ -31,6 +31,41 @@
 // The ScanAllRepositories function orchestrates multiple FileManager instances and
 // automatically tags each file with its source repository metadata (ID, name, type).
 //
+// # Shared Storage
+//
+// A repository can live in a shared, read-only directory (e.g. /opt/rules maintained by IT)
+// with a per-user writable overlay (see NewOverlayFileManager). Reads see both directories,
+// and an overlay file shadows the shared file with the same relative path. Writes always go
+// to the overlay, even when the shared directory happens to be writable, so the shared copy
+// is never modified; "overwriting" a shared file creates a shadowing copy in the overlay.
+//
+// # Scan Cache
+//
+// Scans can reuse the results of earlier runs from a ScanCache (see UseScanCache): the
+// listing of a tree is reused while none of its directories changed, and callers can keep
+// metadata parsed from each file, such as the MCP server's parsed rules, which is reused
+// while the file's size and modification time are unchanged.
+//
+// # Ignore Patterns
+//
+// Scans leave out paths matched by the scan excludes (see UseScanExcludes) and by the
+// .rulemignore file, in gitignore syntax, at the root of the scanned directory.
+//
+// # Rule Extensions
+//
+// Scans find files with the rule extensions, markdown and .mdc by default; UseRuleExtensions
+// replaces them, e.g. to find rules kept in .txt or .yaml files.
+//
+// # Directory Tree
+//
+// ScanTree returns the same files as ScanRepository nested in their folders (see TreeNode),
+// for screens that browse large collections as collapsible folders.
+//
+// # Managing Rules
+//
+// MoveRuleFile and DeleteRuleFile reorganize a repository in place. Deleted rules are moved
+// to the trash, outside every repository, unless UseTrash turns it off.
+//
 // # Security Features
 //
 //   - Path traversal protection
@@ -46,12 +81,20 @@ import (
 	"os"
 	"path/filepath"
 	"rulem/internal/logging"
+	"rulem/internal/repository"
 	"rulem/pkg/fileops"
+	"slices"
+	"strings"
 )
 
 type FileManager struct {
 	logger     *logging.AppLogger
 	storageDir string
+	overlayDir string // writable overlay over a shared storageDir, empty if none
+
+	// excludedDirs are top-level directories of the storage and overlay
+	// directories that scans leave out, e.g. repository.ProposalsDir
+	excludedDirs []string
 }
 
 // NewFileManager initializes a new FileManager with the given logger and storage directory.
@@ -86,7 +129,61 @@ func NewFileManager(storageDir string, logger *logging.AppLogger) (*FileManager,
 	}, nil
 }
 
-// CopyFileToStorage copies a file from the source path to the storage directory.
+// NewOverlayFileManager initializes a FileManager for shared storage: sharedDir is read
+// and never written, and every write is redirected to overlayDir.
+//
+// Parameters:
+//   - sharedDir: Absolute path to the shared storage directory (only needs to be readable)
+//   - overlayDir: Absolute path to the per-user overlay directory (must exist and be writable)
+//   - logger: Application logger instance
+//
+// Returns:
+//   - *FileManager: Configured FileManager instance
+//   - error: Validation or access errors
+func NewOverlayFileManager(sharedDir, overlayDir string, logger *logging.AppLogger) (*FileManager, error) {
+	fm, err := NewFileManager(sharedDir, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fileops.ValidateStoragePath(overlayDir); err != nil {
+		return nil, fmt.Errorf("invalid overlay directory: %w", err)
+	}
+	access, err := fileops.CheckDirectoryAccess(overlayDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access overlay directory: %w", err)
+	}
+	if !access.Readable || !access.Writable {
+		return nil, fmt.Errorf("overlay directory must be readable and writable: %s", overlayDir)
+	}
+
+	fm.overlayDir = overlayDir
+	return fm, nil
+}
+
+// NewRepositoryFileManager initializes a FileManager for a prepared repository, layering
+// its overlay over the shared directory when one is configured. Scans of a repository
+// that accepts proposals leave out repository.ProposalsDir.
+func NewRepositoryFileManager(prep repository.PreparedRepository, logger *logging.AppLogger) (*FileManager, error) {
+	var fm *FileManager
+	var err error
+	if prep.OverlayPath != "" {
+		fm, err = NewOverlayFileManager(prep.LocalPath, prep.OverlayPath, logger)
+	} else {
+		fm, err = NewFileManager(prep.LocalPath, logger)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Proposals are only served once accepted
+	if prep.Entry.AcceptProposals {
+		fm.excludedDirs = []string{repository.ProposalsDir}
+	}
+	return fm, nil
+}
+
+// CopyFileToStorage copies a file from the source path to the storage directory,
+// or to the overlay directory for shared storage (see GetWriteDir).
 // Performs atomic copy operation to ensure data integrity.
 //
 // Parameters:
@@ -105,6 +202,8 @@ func NewFileManager(storageDir string, logger *logging.AppLogger) (*FileManager,
 //   - Uses atomic copy to prevent corruption
 //
 // The operation is atomic - either the file is fully copied or no changes are made.
+// For shared storage, a file with the same name in the shared directory counts as
+// existing; overwriting it writes a shadowing copy to the overlay.
 func (fm *FileManager) CopyFileToStorage(srcPath string, newFileName *string, overwrite bool) (string, error) {
 	// Validate and resolve source path
 	absPath, err := filepath.Abs(srcPath)
@@ -121,7 +220,7 @@ func (fm *FileManager) CopyFileToStorage(srcPath string, newFileName *string, ov
 	// Only validate if the path is actually a symlink
 	if isLink, err := fileops.IsSymlink(absPath); err == nil && isLink {
 		// Create allowlist of safe source locations
-		allowedPaths := []string{fm.storageDir}
+		allowedPaths := []string{fm.storageDir, fm.GetWriteDir()}
 		if cwd, err := os.Getwd(); err == nil {
 			allowedPaths = append(allowedPaths, cwd)
 		}
@@ -144,20 +243,31 @@ func (fm *FileManager) CopyFileToStorage(srcPath string, newFileName *string, ov
 		fileName = filepath.Base(srcPath)
 	}
 
-	// Construct destination path
-	destPath := filepath.Join(fm.storageDir, fileName)
+	// Construct destination path; shared storage is never written
+	writeDir := fm.GetWriteDir()
+	destPath := filepath.Join(writeDir, fileName)
+
+	// Other writers wait until the file is in place, see lockRepository
+	lock, err := lockRepository(writeDir)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Unlock()
 
 	// Check if destination exists (use Lstat to detect symlinks, even broken ones)
-	if _, err := os.Lstat(destPath); err == nil {
+	if existing, ok := fm.findStorageFile(fileName); ok {
 		if !overwrite {
 			return "", fmt.Errorf("destination file already exists: %s (use overwrite=true to replace)", fileName)
 		}
-		fm.logger.Debug("Overwriting existing file", "dest", destPath)
+		fm.logger.Debug("Overwriting existing file", "existing", existing, "dest", destPath)
 	}
 
-	// Verify we can write to storage directory
-	if err := fileops.ValidateDirectoryWritable(fm.storageDir); err != nil {
-		return "", fmt.Errorf("storage directory is not writable: %w", err)
+	// Verify we can write to the destination directory
+	if err := fileops.ValidateDirectoryWritable(writeDir); err != nil {
+		if fm.overlayDir == "" {
+			return "", fmt.Errorf("storage directory is not writable (configure an overlay for shared storage): %w", err)
+		}
+		return "", fmt.Errorf("overlay directory is not writable: %w", err)
 	}
 
 	// Perform atomic copy
@@ -169,6 +279,111 @@ func (fm *FileManager) CopyFileToStorage(srcPath string, newFileName *string, ov
 	return destPath, nil
 }
 
+// SaveFileToStorage copies a file into storage like CopyFileToStorage, handling
+// an existing file with the same name according to strategy: CollisionAsk fails
+// with an "already exists" error so the caller can ask, CollisionRename saves
+// under the first free name with a numeric suffix (rule-2.md), and
+// CollisionOverwrite replaces the file.
+func (fm *FileManager) SaveFileToStorage(srcPath string, newFileName *string, strategy fileops.CollisionStrategy) (string, error) {
+	switch strategy {
+	case fileops.CollisionOverwrite:
+		return fm.CopyFileToStorage(srcPath, newFileName, true)
+	case fileops.CollisionRename:
+		fileName := filepath.Base(srcPath)
+		if newFileName != nil {
+			cleanName, err := fileops.SanitizeFilename(*newFileName)
+			if err != nil {
+				return "", fmt.Errorf("invalid filename: %w", err)
+			}
+			fileName = cleanName
+		}
+		free, err := fileops.UniqueFilename(fileName, func(name string) bool {
+			_, exists := fm.findStorageFile(name)
+			return exists
+		})
+		if err != nil {
+			return "", err
+		}
+		if free != fileName {
+			fm.logger.Debug("Destination exists, saving under a new name", "name", fileName, "new_name", free)
+		}
+		return fm.CopyFileToStorage(srcPath, &free, false)
+	default:
+		return fm.CopyFileToStorage(srcPath, newFileName, false)
+	}
+}
+
+// InSubdirectory returns a FileManager for subdir, a directory relative to this
+// one, so rules can be saved into a folder of the repository instead of its
+// root. The directory is created in the write directory when it does not exist
+// yet; for shared storage the shared directory is only read.
+//
+// Parameters:
+//   - subdir: Directory relative to the storage root (empty string for the root)
+//
+// Returns:
+//   - *FileManager: FileManager rooted at subdir (fm itself for the root)
+//   - error: Validation or directory creation errors
+func (fm *FileManager) InSubdirectory(subdir string) (*FileManager, error) {
+	clean, err := fileops.SanitizeSubdirectory(subdir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory: %w", err)
+	}
+	if clean == "" {
+		return fm, nil
+	}
+
+	sub := &FileManager{
+		logger:     fm.logger,
+		storageDir: filepath.Join(fm.storageDir, filepath.FromSlash(clean)),
+	}
+	if fm.overlayDir != "" {
+		sub.overlayDir = filepath.Join(fm.overlayDir, filepath.FromSlash(clean))
+	}
+
+	if err := fileops.EnsureDirectoryExists(sub.GetWriteDir()); err != nil {
+		return nil, fmt.Errorf("cannot create directory %s: %w", clean, err)
+	}
+	return sub, nil
+}
+
+// ListSubdirectories returns the names of the directories directly inside dir,
+// a directory relative to the storage root, sorted by name. For shared storage
+// the directories of the overlay and the shared directory are merged. Hidden
+// directories such as .git are left out, and a dir that does not exist yet
+// has no subdirectories.
+func (fm *FileManager) ListSubdirectories(dir string) ([]string, error) {
+	clean, err := fileops.SanitizeSubdirectory(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, root := range []string{fm.overlayDir, fm.storageDir} {
+		if root == "" {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(clean)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read directory %s: %w", clean, err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() || strings.HasPrefix(name, ".") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
 // CopyFileFromStorage copies a file from the storage directory to the current working directory.
 // Performs atomic copy operation to ensure data integrity.
 //
@@ -196,20 +411,9 @@ func (fm *FileManager) CopyFileFromStorage(storagePath string, destPath string,
 		return "", fmt.Errorf("invalid destination path: %w", err)
 	}
 
-	// Handle both absolute and relative storage paths intelligently
-	var absStoragePath string
-	if filepath.IsAbs(storagePath) {
-		// If path is absolute, use it directly but validate it's within storage
-		absStoragePath = storagePath
-		fm.logger.Debug("Using absolute storage path", "absolute", absStoragePath)
-	} else {
-		// If path is relative, join with storage directory
-		absStoragePath = filepath.Join(fm.storageDir, storagePath)
-		fm.logger.Debug("Converted relative to absolute storage path", "relative", storagePath, "absolute", absStoragePath)
-	}
-
-	// Validate that source file exists and is within storage directory
-	if err := fileops.ValidateFileInDirectory(absStoragePath, fm.storageDir); err != nil {
+	// Resolve and validate the source within storage (or the overlay)
+	absStoragePath, err := fm.resolveStoragePath(storagePath)
+	if err != nil {
 		return "", fmt.Errorf("source file validation failed: %w", err)
 	}
 
@@ -271,20 +475,9 @@ func (fm *FileManager) CreateSymlinkFromStorage(storagePath string, destPath str
 		return "", fmt.Errorf("invalid destination path: %w", err)
 	}
 
-	// Handle both absolute and relative storage paths intelligently
-	var absStoragePath string
-	if filepath.IsAbs(storagePath) {
-		// If path is absolute, use it directly but validate it's within storage
-		absStoragePath = storagePath
-		fm.logger.Debug("Using absolute storage path", "absolute", absStoragePath)
-	} else {
-		// If path is relative, join with storage directory
-		absStoragePath = filepath.Join(fm.storageDir, storagePath)
-		fm.logger.Debug("Converted relative to absolute storage path", "relative", storagePath, "absolute", absStoragePath)
-	}
-
-	// Validate that source file exists and is within storage directory
-	if err := fileops.ValidateFileInDirectory(absStoragePath, fm.storageDir); err != nil {
+	// Resolve and validate the source within storage (or the overlay)
+	absStoragePath, err := fm.resolveStoragePath(storagePath)
+	if err != nil {
 		return "", fmt.Errorf("source file validation failed: %w", err)
 	}
 
@@ -323,10 +516,83 @@ func (fm *FileManager) CreateSymlinkFromStorage(storagePath string, destPath str
 	return absDestPath, nil
 }
 
+// StorageFilePath returns the absolute path of the rule at storagePath, relative or
+// absolute, after checking that it is an existing file within the storage directory
+// or the overlay. Callers that copy rules out of storage themselves, such as the
+// deploy package, validate their sources with it.
+func (fm *FileManager) StorageFilePath(storagePath string) (string, error) {
+	absStoragePath, err := fm.resolveStoragePath(storagePath)
+	if err != nil {
+		return "", fmt.Errorf("source file validation failed: %w", err)
+	}
+	return absStoragePath, nil
+}
+
+// resolveStoragePath turns a relative or absolute storage path into an absolute path
+// of an existing file within the storage directory or the overlay. A relative path
+// resolves to the overlay copy when one exists, since it shadows the shared file.
+func (fm *FileManager) resolveStoragePath(storagePath string) (string, error) {
+	if filepath.IsAbs(storagePath) {
+		fm.logger.Debug("Using absolute storage path", "absolute", storagePath)
+		if fm.overlayDir != "" && fileops.ValidateFileInDirectory(storagePath, fm.overlayDir) == nil {
+			return storagePath, nil
+		}
+		if err := fileops.ValidateFileInDirectory(storagePath, fm.storageDir); err != nil {
+			return "", err
+		}
+		return storagePath, nil
+	}
+
+	if fm.overlayDir != "" {
+		overlayPath := filepath.Join(fm.overlayDir, storagePath)
+		if fileops.ValidateFileInDirectory(overlayPath, fm.overlayDir) == nil {
+			fm.logger.Debug("Converted relative to overlay path", "relative", storagePath, "absolute", overlayPath)
+			return overlayPath, nil
+		}
+	}
+	absStoragePath := filepath.Join(fm.storageDir, storagePath)
+	fm.logger.Debug("Converted relative to absolute storage path", "relative", storagePath, "absolute", absStoragePath)
+	if err := fileops.ValidateFileInDirectory(absStoragePath, fm.storageDir); err != nil {
+		return "", err
+	}
+	return absStoragePath, nil
+}
+
+// findStorageFile reports the path of a file named name in the overlay or the
+// storage directory, including broken symlinks
+func (fm *FileManager) findStorageFile(name string) (string, bool) {
+	for _, dir := range []string{fm.overlayDir, fm.storageDir} {
+		if dir == "" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if _, err := os.Lstat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
 // GetStorageDir returns the storage directory path.
+// For shared storage this is the read-only shared directory.
 //
 // Returns:
 //   - string: Absolute path to the configured storage directory
 func (fm *FileManager) GetStorageDir() string {
 	return fm.storageDir
 }
+
+// GetOverlayDir returns the overlay directory, or empty string when writes go to
+// the storage directory.
+func (fm *FileManager) GetOverlayDir() string {
+	return fm.overlayDir
+}
+
+// GetWriteDir returns the directory new files are written to: the overlay for
+// shared storage, otherwise the storage directory.
+func (fm *FileManager) GetWriteDir() string {
+	if fm.overlayDir != "" {
+		return fm.overlayDir
+	}
+	return fm.storageDir
+}
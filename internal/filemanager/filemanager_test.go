@@ -375,6 +375,140 @@ func TestOverwriteBehavior(t *testing.T) {
 	})
 }
 
+func TestSaveFileToStorageStrategies(t *testing.T) {
+	storageDir := createTempTestDir(t, "collision_storage_")
+	fm, err := NewFileManager(storageDir, createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create FileManager: %v", err)
+	}
+	srcDir := createTempTestDir(t, "collision_src_")
+	first := createTestFile(t, srcDir, "rule.md", "first")
+	second := createTestFile(t, createTestDir(t, srcDir, "other"), "rule.md", "second")
+
+	if _, err := fm.SaveFileToStorage(first, nil, fileops.CollisionAsk); err != nil {
+		t.Fatalf("First save failed: %v", err)
+	}
+
+	t.Run("ask fails on an existing file", func(t *testing.T) {
+		_, err := fm.SaveFileToStorage(second, nil, fileops.CollisionAsk)
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("Expected 'already exists' error, got: %v", err)
+		}
+	})
+
+	t.Run("rename saves under a numeric suffix", func(t *testing.T) {
+		for _, want := range []string{"rule-2.md", "rule-3.md"} {
+			destPath, err := fm.SaveFileToStorage(second, nil, fileops.CollisionRename)
+			if err != nil {
+				t.Fatalf("Rename save failed: %v", err)
+			}
+			if filepath.Base(destPath) != want {
+				t.Errorf("Saved as %s, want %s", filepath.Base(destPath), want)
+			}
+		}
+		if content := readFileContent(t, filepath.Join(storageDir, "rule.md")); content != "first" {
+			t.Errorf("Existing file was changed to %q", content)
+		}
+	})
+
+	t.Run("overwrite replaces the existing file", func(t *testing.T) {
+		if _, err := fm.SaveFileToStorage(second, nil, fileops.CollisionOverwrite); err != nil {
+			t.Fatalf("Overwrite save failed: %v", err)
+		}
+		if content := readFileContent(t, filepath.Join(storageDir, "rule.md")); content != "second" {
+			t.Errorf("Expected 'second', got %q", content)
+		}
+	})
+}
+
+func TestInSubdirectory(t *testing.T) {
+	storageDir := createTempTestDir(t, "subdir_storage_")
+	fm, err := NewFileManager(storageDir, createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create FileManager: %v", err)
+	}
+	src := createTestFile(t, createTempTestDir(t, "subdir_src_"), "rule.md", "# rule")
+
+	t.Run("saves into a new nested folder", func(t *testing.T) {
+		sub, err := fm.InSubdirectory("frontend/react")
+		if err != nil {
+			t.Fatalf("InSubdirectory failed: %v", err)
+		}
+		destPath, err := sub.SaveFileToStorage(src, nil, fileops.CollisionAsk)
+		if err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if want := filepath.Join(storageDir, "frontend", "react", "rule.md"); destPath != want {
+			t.Errorf("Saved to %s, want %s", destPath, want)
+		}
+		if fileExists(filepath.Join(storageDir, "rule.md")) {
+			t.Error("File must not be saved to the repository root")
+		}
+	})
+
+	t.Run("collisions are checked in the folder", func(t *testing.T) {
+		sub, err := fm.InSubdirectory("frontend/react")
+		if err != nil {
+			t.Fatalf("InSubdirectory failed: %v", err)
+		}
+		if _, err := sub.SaveFileToStorage(src, nil, fileops.CollisionAsk); err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("Expected 'already exists' error, got: %v", err)
+		}
+	})
+
+	t.Run("root returns the same manager", func(t *testing.T) {
+		if sub, err := fm.InSubdirectory(""); err != nil || sub != fm {
+			t.Errorf("Expected the root manager, got %v, %v", sub, err)
+		}
+	})
+
+	t.Run("rejects escaping the root", func(t *testing.T) {
+		if _, err := fm.InSubdirectory("../outside"); err == nil {
+			t.Error("Expected an error for a directory outside the storage root")
+		}
+	})
+}
+
+func TestListSubdirectories(t *testing.T) {
+	sharedDir := createTempDirStructure(t, map[string]string{
+		"go/style.md":       "# go",
+		"react/hooks.md":    "# hooks",
+		".git/HEAD":         "ref: refs/heads/main",
+		"go/testing/a.md":   "# a",
+		"top-level-rule.md": "# top",
+	})
+	overlayDir := createTempDirStructure(t, map[string]string{
+		"python/style.md": "# python",
+		"go/mine.md":      "# mine",
+	})
+	fm, err := NewOverlayFileManager(sharedDir, overlayDir, createTestLogger())
+	if err != nil {
+		t.Fatalf("NewOverlayFileManager failed: %v", err)
+	}
+
+	tests := []struct {
+		dir  string
+		want []string
+	}{
+		{dir: "", want: []string{"go", "python", "react"}},
+		{dir: "go", want: []string{"testing"}},
+		{dir: "not-created-yet", want: nil},
+	}
+	for _, tt := range tests {
+		got, err := fm.ListSubdirectories(tt.dir)
+		if err != nil {
+			t.Fatalf("ListSubdirectories(%q) failed: %v", tt.dir, err)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("ListSubdirectories(%q) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+
+	if _, err := fm.ListSubdirectories("../.."); err == nil {
+		t.Error("Expected an error for a directory outside the storage root")
+	}
+}
+
 // 2.5 Security Tests
 
 func TestSecurity(t *testing.T) {
@@ -1348,3 +1482,83 @@ func TestFileManagerIntegration(t *testing.T) {
 		}
 	})
 }
+
+// 7. Shared Storage with Overlay Tests
+
+func TestOverlayFileManager(t *testing.T) {
+	logger := createTestLogger()
+	sharedDir := t.TempDir()
+	overlayDir := t.TempDir()
+	sharedFile := createTestFile(t, sharedDir, "shared.md", "# shared")
+	createTestFile(t, sharedDir, "team.md", "# team from IT")
+
+	fm, err := NewOverlayFileManager(sharedDir, overlayDir, logger)
+	if err != nil {
+		t.Fatalf("NewOverlayFileManager failed: %v", err)
+	}
+	if fm.GetStorageDir() != sharedDir || fm.GetWriteDir() != overlayDir {
+		t.Fatalf("expected reads from %q and writes to %q, got %q and %q", sharedDir, overlayDir, fm.GetStorageDir(), fm.GetWriteDir())
+	}
+
+	srcDir := t.TempDir()
+
+	t.Run("new files are written to the overlay", func(t *testing.T) {
+		src := createTestFile(t, srcDir, "mine.md", "# mine")
+		dest, err := fm.CopyFileToStorage(src, nil, false)
+		if err != nil {
+			t.Fatalf("CopyFileToStorage failed: %v", err)
+		}
+		if dest != filepath.Join(overlayDir, "mine.md") {
+			t.Errorf("expected destination in overlay, got %q", dest)
+		}
+		if fileExists(filepath.Join(sharedDir, "mine.md")) {
+			t.Error("shared directory must not be written")
+		}
+	})
+
+	t.Run("shared file counts as existing", func(t *testing.T) {
+		src := createTestFile(t, srcDir, "team.md", "# my team")
+		if _, err := fm.CopyFileToStorage(src, nil, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Fatalf("expected already exists error, got %v", err)
+		}
+
+		dest, err := fm.CopyFileToStorage(src, nil, true)
+		if err != nil {
+			t.Fatalf("CopyFileToStorage with overwrite failed: %v", err)
+		}
+		if dest != filepath.Join(overlayDir, "team.md") {
+			t.Errorf("expected shadowing copy in overlay, got %q", dest)
+		}
+		if got := readFileContent(t, filepath.Join(sharedDir, "team.md")); got != "# team from IT" {
+			t.Errorf("shared file must be unchanged, got %q", got)
+		}
+	})
+
+	t.Run("relative paths prefer the overlay", func(t *testing.T) {
+		cwd := t.TempDir()
+		defer changeToDir(t, cwd)()
+
+		dest, err := fm.CopyFileFromStorage("team.md", "team.md", false)
+		if err != nil {
+			t.Fatalf("CopyFileFromStorage failed: %v", err)
+		}
+		if got := readFileContent(t, dest); got != "# my team" {
+			t.Errorf("expected the overlay copy, got %q", got)
+		}
+
+		dest, err = fm.CopyFileFromStorage(sharedFile, "shared.md", false)
+		if err != nil {
+			t.Fatalf("CopyFileFromStorage of shared file failed: %v", err)
+		}
+		if got := readFileContent(t, dest); got != "# shared" {
+			t.Errorf("expected the shared file, got %q", got)
+		}
+	})
+
+	t.Run("missing overlay", func(t *testing.T) {
+		_, err := NewOverlayFileManager(sharedDir, filepath.Join(overlayDir, "missing"), logger)
+		if err == nil || !strings.Contains(err.Error(), "does not exist") {
+			t.Errorf("expected missing overlay error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package filemanager
+
+import (
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+
+	"rulem/internal/logging"
+	"rulem/pkg/fileops"
+)
+
+// Ignore patterns
+//
+// Markdown that is not a rule, such as the READMEs of dependencies, would
+// otherwise clutter the save screen and the MCP tools. Scans leave out the
+// paths matched by the default excludes (dependency and build directories),
+// which scan_excludes in config.yaml replaces, and by the .rulemignore file at
+// the root of the scanned directory: the working directory when saving rules,
+// a repository's directory when serving them. Both use gitignore syntax, see
+// fileops.IgnoreMatcher. .git is always left out.
+
+// RuleIgnoreFile lists, in gitignore syntax, paths under the directory it is
+// in that scans leave out
+const RuleIgnoreFile = ".rulemignore"
+
+// DefaultScanExcludes are the patterns scans leave out unless scan_excludes
+// in config.yaml replaces them
+var DefaultScanExcludes = []string{
+	"node_modules/", "vendor/", "target/", "build/", ".next/", "dist/", ".cache/", "__pycache__/", ".vscode/", ".idea/",
+}
+
+// alwaysExcluded are left out of every scan, whatever the excludes
+var alwaysExcluded = []string{".git/"}
+
+// scanExcludes holds the excludes in use, see UseScanExcludes
+type scanExcludes struct {
+	patterns []string
+	matcher  *fileops.IgnoreMatcher
+}
+
+// activeExcludes are the excludes of this process; nil means the defaults
+var activeExcludes atomic.Pointer[scanExcludes]
+
+// UseScanExcludes makes the scans of this process leave out paths matching
+// patterns instead of DefaultScanExcludes; nil restores the defaults
+func UseScanExcludes(patterns []string) {
+	if patterns == nil {
+		activeExcludes.Store(nil)
+		return
+	}
+	all := slices.Concat(alwaysExcluded, patterns)
+	activeExcludes.Store(&scanExcludes{patterns: all, matcher: fileops.NewIgnoreMatcher(all)})
+}
+
+// defaultExcludes are the excludes used until UseScanExcludes is called
+var defaultExcludes = func() *scanExcludes {
+	all := slices.Concat(alwaysExcluded, DefaultScanExcludes)
+	return &scanExcludes{patterns: all, matcher: fileops.NewIgnoreMatcher(all)}
+}()
+
+// currentExcludes returns the excludes in use
+func currentExcludes() *scanExcludes {
+	if excludes := activeExcludes.Load(); excludes != nil {
+		return excludes
+	}
+	return defaultExcludes
+}
+
+// scanIgnorePatterns returns the patterns a scan of root leaves out: the
+// excludes followed by root's .rulemignore, whose patterns win. An unreadable
+// .rulemignore is skipped with a warning.
+func scanIgnorePatterns(root string) []string {
+	patterns := currentExcludes().patterns
+	lines, err := fileops.ReadIgnoreFile(filepath.Join(root, RuleIgnoreFile))
+	if err != nil {
+		logging.Warn("Ignoring unreadable "+RuleIgnoreFile, "root", root, "error", err)
+		return patterns
+	}
+	return slices.Concat(patterns, lines)
+}
@@ -0,0 +1,69 @@
+package filemanager
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestScan_RespectsRuleIgnoreFile(t *testing.T) {
+	useTestScanCache(t)
+	root := createTempDirStructure(t, map[string]string{
+		"style.md":                 "# Style",
+		"drafts/idea.md":           "# Idea",
+		"go/errors.md":             "# Errors",
+		"go/errors.draft.md":       "# Errors, revised",
+		"vendor/lib/README.md":     "# Vendored",
+		"node_modules/pkg/docs.md": "# Package docs",
+		RuleIgnoreFile:             "# Not rules\ndrafts/\n*.draft.md\n",
+	})
+
+	want := []string{"errors.md", "style.md"}
+	if got := scannedNames(t, root); !slices.Equal(got, want) {
+		t.Errorf("scan = %v, want %v", got, want)
+	}
+
+	// Changing the ignore file changes the listing, even when the cached
+	// listing's directories are unchanged
+	ignorePath := filepath.Join(root, RuleIgnoreFile)
+	info, err := os.Stat(root)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if err := os.WriteFile(ignorePath, []byte("drafts/\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chtimes(root, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	want = []string{"errors.draft.md", "errors.md", "style.md"}
+	if got := scannedNames(t, root); !slices.Equal(got, want) {
+		t.Errorf("scan after editing %s = %v, want %v", RuleIgnoreFile, got, want)
+	}
+}
+
+func TestUseScanExcludes(t *testing.T) {
+	t.Cleanup(func() { UseScanExcludes(nil) })
+	root := createTempDirStructure(t, map[string]string{
+		"style.md":             "# Style",
+		"vendor/lib/README.md": "# Vendored",
+		"archive/old.md":       "# Old",
+		".git/info.md":         "never scanned",
+	})
+
+	UseScanExcludes([]string{"archive/"})
+	want := []string{"README.md", "style.md"}
+	if got := scannedNames(t, root); !slices.Equal(got, want) {
+		t.Errorf("scan = %v, want %v", got, want)
+	}
+	if !IsSkippedDir("archive") || IsSkippedDir("vendor") || !IsSkippedDir(".git") {
+		t.Error("IsSkippedDir() should follow the scan excludes, always skipping .git")
+	}
+
+	UseScanExcludes(nil)
+	want = []string{"old.md", "style.md"}
+	if got := scannedNames(t, root); !slices.Equal(got, want) {
+		t.Errorf("scan with the default excludes = %v, want %v", got, want)
+	}
+}
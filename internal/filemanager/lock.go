@@ -0,0 +1,46 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"rulem/pkg/fileops"
+)
+
+// Repository locks
+//
+// Saving, moving and deleting rules take the lock of the repository they
+// write to (see fileops.Lock), the same lock a sync of the repository takes,
+// so another rulem process never writes into a clone while it is reset to a
+// new commit, nor two processes into the same file.
+
+// lockRepository takes the lock of the repository holding dir: the root of
+// the git clone dir is in, so rules saved into a subpath wait for syncs of
+// the whole clone, or dir itself outside a clone
+func lockRepository(dir string) (*fileops.FileLock, error) {
+	lock, err := fileops.Lock(repositoryRoot(dir), fileops.DefaultLockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock repository: %w", err)
+	}
+	return lock, nil
+}
+
+// repositoryRoot returns the closest directory from dir up that has a .git
+// entry, or dir when there is none
+func repositoryRoot(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for current := abs; ; {
+		if _, err := os.Lstat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return abs
+		}
+		current = parent
+	}
+}
@@ -0,0 +1,63 @@
+package filemanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rulem/pkg/fileops"
+)
+
+func TestRepositoryRoot(t *testing.T) {
+	clone := createTempDirStructure(t, map[string]string{
+		".git/HEAD":          "ref: refs/heads/main",
+		"teams/go/style.md":  "# style",
+		"teams/go/errors.md": "# errors",
+	})
+	if got := repositoryRoot(filepath.Join(clone, "teams", "go")); got != clone {
+		t.Errorf("repositoryRoot(subpath) = %s, want the clone root %s", got, clone)
+	}
+
+	plain := createTempDirStructure(t, map[string]string{"style.md": "# style"})
+	if got := repositoryRoot(plain); got != plain {
+		t.Errorf("repositoryRoot(plain) = %s, want the directory itself", got)
+	}
+}
+
+func TestCopyFileToStorageWaitsForLock(t *testing.T) {
+	t.Setenv("RULEM_LOCK_DIR", t.TempDir())
+	storageDir := createTempDirStructure(t, nil)
+	fm, err := NewFileManager(storageDir, createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create FileManager: %v", err)
+	}
+	src := filepath.Join(t.TempDir(), "style.md")
+	if err := os.WriteFile(src, []byte("# style"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A sync of the repository holds its lock: the save waits for it
+	lock, err := fileops.Lock(storageDir, 0)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := fm.CopyFileToStorage(src, nil, false)
+		done <- err
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	if _, err := os.Stat(filepath.Join(storageDir, "style.md")); err == nil {
+		t.Error("the rule must not be saved while the repository is locked")
+	}
+	lock.Unlock()
+
+	if err := <-done; err != nil {
+		t.Fatalf("CopyFileToStorage failed once the lock was released: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(storageDir, "style.md")); err != nil {
+		t.Errorf("the rule must be saved after the lock was released: %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"rulem/pkg/fileops"
+
+	"github.com/adrg/xdg"
+)
+
+// Managing rules
+//
+// Rules are moved, renamed and deleted in the write directory only: the shared
+// directory of shared storage is never changed, so a rule that only exists
+// there cannot be moved or deleted. A deleted rule is moved to the trash (see
+// UseTrash), a directory outside every repository where it can be restored by
+// hand, unless deleting for good was chosen.
+
+// trashTimeFormat names the directory of a trash entry after the time of the deletion
+const trashTimeFormat = "20060102-150405"
+
+// activeTrash is the trash in use, see UseTrash
+var activeTrash atomic.Pointer[string]
+
+// TrashPath returns the default trash in the user's state directory (e.g.
+// ~/.local/state/rulem/trash on Linux), outside the default storage directory
+// so scans never find deleted rules. It can be overridden with the
+// RULEM_TRASH_PATH environment variable for testing.
+func TrashPath() string {
+	if testPath := os.Getenv("RULEM_TRASH_PATH"); testPath != "" {
+		return testPath
+	}
+	return filepath.Join(xdg.StateHome, "rulem", "trash")
+}
+
+// UseTrash makes DeleteRuleFile move rules into dir; an empty dir deletes them
+// for good. Until it is called rules are moved to TrashPath.
+func UseTrash(dir string) {
+	activeTrash.Store(&dir)
+}
+
+// currentTrash returns the trash set with UseTrash, or TrashPath
+func currentTrash() string {
+	if dir := activeTrash.Load(); dir != nil {
+		return *dir
+	}
+	return TrashPath()
+}
+
+// MoveRuleFile moves or renames a rule file of the repository. newPath is
+// relative to the storage root with forward slashes (e.g. "frontend/react.md");
+// oldPath is too, or absolute. Missing directories of newPath are created, and
+// directories the move leaves empty are removed.
+//
+// Parameters:
+//   - oldPath: Current path of the rule file
+//   - newPath: New path of the rule file, relative to the storage root
+//
+// Returns:
+//   - string: Absolute path of the moved file
+//   - error: Validation errors, or the destination already exists
+//
+// Security:
+//   - Both paths must stay within the write directory and outside .git
+//   - The destination must have a rule extension, so scans still find the rule
+//   - The move is a single rename: the rule is never missing or partly written
+func (fm *FileManager) MoveRuleFile(oldPath, newPath string) (string, error) {
+	src, oldRel, err := fm.resolveWritableFile(oldPath)
+	if err != nil {
+		return "", fmt.Errorf("source file validation failed: %w", err)
+	}
+
+	newRel, err := sanitizeRulePath(newPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination: %w", err)
+	}
+	if !isRuleFile(newRel) {
+		return "", fmt.Errorf("invalid destination: %s needs a rule extension (%s)", newRel, strings.Join(RuleExtensions(), ", "))
+	}
+	if newRel == oldRel {
+		return "", fmt.Errorf("%s is already at that path", oldRel)
+	}
+
+	writeDir := fm.GetWriteDir()
+	dest := filepath.Join(writeDir, filepath.FromSlash(newRel))
+
+	lock, err := lockRepository(writeDir)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Unlock()
+
+	// A file at the destination is only allowed when it is the source itself,
+	// as when the case of a name changes on a case-insensitive file system
+	if existing, ok := fm.findStorageFile(filepath.FromSlash(newRel)); ok && !sameFile(existing, src) {
+		return "", fmt.Errorf("destination file already exists: %s", newRel)
+	}
+
+	if err := fileops.EnsureDirectoryExists(filepath.Dir(dest)); err != nil {
+		return "", fmt.Errorf("cannot create destination directory: %w", err)
+	}
+	if err := os.Rename(src, dest); err != nil {
+		return "", fmt.Errorf("failed to move file: %w", err)
+	}
+	removeEmptyDirs(filepath.Dir(src), writeDir)
+
+	fm.logger.Info("Rule file moved", "src", src, "dest", dest)
+	return dest, nil
+}
+
+// DeleteRuleFile deletes a rule file of the repository, named by a path
+// relative to the storage root or an absolute one, and removes directories it
+// leaves empty. The file is moved to the trash unless UseTrash turned it off.
+//
+// Returns:
+//   - string: Path of the file in the trash, empty string when it was deleted for good
+//   - error: Validation or file system errors; the rule is left in place on error
+func (fm *FileManager) DeleteRuleFile(storagePath string) (string, error) {
+	src, rel, err := fm.resolveWritableFile(storagePath)
+	if err != nil {
+		return "", fmt.Errorf("file validation failed: %w", err)
+	}
+
+	lock, err := lockRepository(fm.GetWriteDir())
+	if err != nil {
+		return "", err
+	}
+	defer lock.Unlock()
+
+	trashed := ""
+	if trash := currentTrash(); trash != "" {
+		if trashed, err = moveToTrash(src, rel, filepath.Base(fm.GetWriteDir()), trash); err != nil {
+			return "", err
+		}
+	} else if err := os.Remove(src); err != nil {
+		return "", fmt.Errorf("failed to delete file: %w", err)
+	}
+	removeEmptyDirs(filepath.Dir(src), fm.GetWriteDir())
+
+	fm.logger.Info("Rule file deleted", "path", src, "trash", trashed)
+	return trashed, nil
+}
+
+// resolveWritableFile returns the absolute path of an existing file in the
+// write directory, and its path relative to it with forward slashes
+func (fm *FileManager) resolveWritableFile(storagePath string) (string, string, error) {
+	writeDir := fm.GetWriteDir()
+	if filepath.IsAbs(storagePath) {
+		if fm.overlayDir != "" && fileops.ValidateFileInDirectory(storagePath, fm.overlayDir) != nil &&
+			fileops.ValidateFileInDirectory(storagePath, fm.storageDir) == nil {
+			return "", "", fmt.Errorf("%s is in shared storage, which is read-only", filepath.Base(storagePath))
+		}
+		rel, err := filepath.Rel(writeDir, storagePath)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot determine relative path: %w", err)
+		}
+		storagePath = rel
+	}
+
+	rel, err := sanitizeRulePath(storagePath)
+	if err != nil {
+		return "", "", err
+	}
+	abs := filepath.Join(writeDir, filepath.FromSlash(rel))
+	if err := fileops.ValidateFileInDirectory(abs, writeDir); err != nil {
+		shared := filepath.Join(fm.storageDir, filepath.FromSlash(rel))
+		if fm.overlayDir != "" && fileops.ValidateFileInDirectory(shared, fm.storageDir) == nil {
+			return "", "", fmt.Errorf("%s is in shared storage, which is read-only", rel)
+		}
+		return "", "", err
+	}
+	return abs, rel, nil
+}
+
+// sanitizeRulePath validates the path of a file relative to a storage root
+// and returns it cleaned with forward slashes. Unlike SanitizeFilename it
+// rejects a name it would have to change rather than changing it.
+func sanitizeRulePath(rulePath string) (string, error) {
+	slashed := filepath.ToSlash(strings.TrimSpace(rulePath))
+	if strings.HasSuffix(slashed, "/") {
+		return "", fmt.Errorf("path must name a file: %q", rulePath)
+	}
+
+	dir, err := fileops.SanitizeSubdirectory(path.Dir(slashed))
+	if err != nil {
+		return "", err
+	}
+	base := path.Base(slashed)
+	name, err := fileops.SanitizeFilename(base)
+	if err != nil {
+		return "", err
+	}
+	if name != base {
+		return "", fmt.Errorf("invalid filename: %q", base)
+	}
+
+	if dir == "" {
+		return name, nil
+	}
+	return dir + "/" + name, nil
+}
+
+// moveToTrash moves src into a new entry of trash named after the current
+// time and the repository directory, keeping its relative path, and returns
+// its path there. Files are copied when the trash is on another file system.
+func moveToTrash(src, rel, repoDir, trash string) (string, error) {
+	entry := filepath.Join(trash, time.Now().Format(trashTimeFormat), repoDir)
+	name, err := fileops.UniqueFilename(filepath.FromSlash(rel), func(name string) bool {
+		_, err := os.Lstat(filepath.Join(entry, name))
+		return err == nil
+	})
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(entry, name)
+
+	if err := fileops.EnsureDirectoryExists(filepath.Dir(dest)); err != nil {
+		return "", fmt.Errorf("cannot create trash directory: %w", err)
+	}
+	if err := os.Rename(src, dest); err == nil {
+		return dest, nil
+	}
+	if err := fileops.AtomicCopy(src, dest); err != nil {
+		return "", fmt.Errorf("failed to move file to the trash: %w", err)
+	}
+	if err := os.Remove(src); err != nil {
+		os.Remove(dest)
+		return "", fmt.Errorf("failed to delete file: %w", err)
+	}
+	return dest, nil
+}
+
+// removeEmptyDirs removes dir and its parents while they are empty, stopping
+// at root, which is never removed
+func removeEmptyDirs(dir, root string) {
+	for {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return
+		}
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// sameFile reports whether two paths are the same file
+func sameFile(a, b string) bool {
+	infoA, errA := os.Lstat(a)
+	infoB, errB := os.Lstat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
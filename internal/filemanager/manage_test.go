@@ -0,0 +1,176 @@
+package filemanager
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useTestTrash points the trash at a temporary directory for the rest of the test
+func useTestTrash(t *testing.T, dir string) {
+	t.Helper()
+	UseTrash(dir)
+	t.Cleanup(func() { activeTrash.Store(nil) })
+}
+
+func TestMoveRuleFile(t *testing.T) {
+	storageDir := createTempDirStructure(t, map[string]string{
+		"style.md":        "# style",
+		"old/nested/a.md": "# a",
+		"taken.md":        "# taken",
+		".git/HEAD":       "ref: refs/heads/main",
+	})
+	fm, err := NewFileManager(storageDir, createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create FileManager: %v", err)
+	}
+
+	t.Run("renames in place", func(t *testing.T) {
+		dest, err := fm.MoveRuleFile("style.md", "go-style.md")
+		if err != nil {
+			t.Fatalf("MoveRuleFile failed: %v", err)
+		}
+		if want := filepath.Join(storageDir, "go-style.md"); dest != want {
+			t.Errorf("moved to %s, want %s", dest, want)
+		}
+		if fileExists(filepath.Join(storageDir, "style.md")) {
+			t.Error("the old file must be gone")
+		}
+	})
+
+	t.Run("moves into new folders and removes emptied ones", func(t *testing.T) {
+		src := filepath.Join(storageDir, "old", "nested", "a.md")
+		dest, err := fm.MoveRuleFile(src, "go/testing/a.md")
+		if err != nil {
+			t.Fatalf("MoveRuleFile failed: %v", err)
+		}
+		if got := readFileContent(t, dest); got != "# a" {
+			t.Errorf("moved content = %q", got)
+		}
+		if fileExists(filepath.Join(storageDir, "old")) {
+			t.Error("emptied folders must be removed")
+		}
+	})
+
+	tests := []struct {
+		name    string
+		oldPath string
+		newPath string
+		wantErr string
+	}{
+		{"existing destination", "go-style.md", "taken.md", "already exists"},
+		{"same path", "taken.md", "taken.md", "already at that path"},
+		{"missing source", "missing.md", "found.md", "does not exist"},
+		{"source outside the storage", "../style.md", "style.md", "escapes the storage root"},
+		{"destination outside the storage", "taken.md", "../taken.md", "escapes the storage root"},
+		{"destination in .git", "taken.md", ".git/taken.md", "inside .git"},
+		{"destination without a rule extension", "taken.md", "taken.txt", "needs a rule extension"},
+		{"destination names a folder", "taken.md", "rules/", "must name a file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := fm.MoveRuleFile(tt.oldPath, tt.newPath); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("MoveRuleFile(%q, %q) error = %v, want it to contain %q", tt.oldPath, tt.newPath, err, tt.wantErr)
+			}
+		})
+	}
+	if !fileExists(filepath.Join(storageDir, "taken.md")) {
+		t.Error("failed moves must leave the file in place")
+	}
+}
+
+func TestDeleteRuleFile(t *testing.T) {
+	storageDir := createTempDirStructure(t, map[string]string{
+		"style.md":     "# style",
+		"go/errors.md": "# errors",
+		"keep.md":      "# keep",
+	})
+	fm, err := NewFileManager(storageDir, createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create FileManager: %v", err)
+	}
+
+	t.Run("moves the rule to the trash", func(t *testing.T) {
+		trash := createTempTestDir(t, "trash_")
+		useTestTrash(t, trash)
+
+		trashed, err := fm.DeleteRuleFile("go/errors.md")
+		if err != nil {
+			t.Fatalf("DeleteRuleFile failed: %v", err)
+		}
+		if !strings.HasPrefix(trashed, trash) || !strings.HasSuffix(trashed, filepath.Join(filepath.Base(storageDir), "go", "errors.md")) {
+			t.Errorf("trashed to %s, want the repository and relative path under %s", trashed, trash)
+		}
+		if got := readFileContent(t, trashed); got != "# errors" {
+			t.Errorf("trashed content = %q", got)
+		}
+		if fileExists(filepath.Join(storageDir, "go")) {
+			t.Error("emptied folders must be removed")
+		}
+	})
+
+	t.Run("deletes for good without a trash", func(t *testing.T) {
+		useTestTrash(t, "")
+
+		trashed, err := fm.DeleteRuleFile(filepath.Join(storageDir, "style.md"))
+		if err != nil || trashed != "" {
+			t.Fatalf("DeleteRuleFile = %q, %v; want it deleted for good", trashed, err)
+		}
+		if fileExists(filepath.Join(storageDir, "style.md")) {
+			t.Error("the file must be deleted")
+		}
+	})
+
+	t.Run("rejects paths outside the storage", func(t *testing.T) {
+		useTestTrash(t, "")
+		outside := createTestFile(t, createTempTestDir(t, "outside_"), "rule.md", "# outside")
+
+		for _, path := range []string{outside, "../keep.md", "missing.md"} {
+			if _, err := fm.DeleteRuleFile(path); err == nil {
+				t.Errorf("DeleteRuleFile(%q) should fail", path)
+			}
+		}
+		if !fileExists(outside) || !fileExists(filepath.Join(storageDir, "keep.md")) {
+			t.Error("rejected deletes must not touch any file")
+		}
+	})
+}
+
+func TestManageRulesWithOverlay(t *testing.T) {
+	sharedDir := createTempDirStructure(t, map[string]string{
+		"shared.md": "# shared",
+	})
+	overlayDir := createTempDirStructure(t, map[string]string{
+		"mine.md": "# mine",
+	})
+	fm, err := NewOverlayFileManager(sharedDir, overlayDir, createTestLogger())
+	if err != nil {
+		t.Fatalf("NewOverlayFileManager failed: %v", err)
+	}
+	useTestTrash(t, "")
+
+	for _, path := range []string{"shared.md", filepath.Join(sharedDir, "shared.md")} {
+		if _, err := fm.MoveRuleFile(path, "moved.md"); err == nil || !strings.Contains(err.Error(), "read-only") {
+			t.Errorf("MoveRuleFile(%q) error = %v, want shared storage to be read-only", path, err)
+		}
+		if _, err := fm.DeleteRuleFile(path); err == nil || !strings.Contains(err.Error(), "read-only") {
+			t.Errorf("DeleteRuleFile(%q) error = %v, want shared storage to be read-only", path, err)
+		}
+	}
+	if _, err := fm.MoveRuleFile("mine.md", "shared.md"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("moving over a shared rule error = %v, want it to exist already", err)
+	}
+
+	if _, err := fm.MoveRuleFile("mine.md", "team/mine.md"); err != nil {
+		t.Fatalf("MoveRuleFile in the overlay failed: %v", err)
+	}
+	if !fileExists(filepath.Join(overlayDir, "team", "mine.md")) {
+		t.Error("the overlay rule must be moved within the overlay")
+	}
+	if _, err := fm.DeleteRuleFile("team/mine.md"); err != nil {
+		t.Fatalf("DeleteRuleFile in the overlay failed: %v", err)
+	}
+	if !fileExists(filepath.Join(sharedDir, "shared.md")) {
+		t.Error("shared storage must never change")
+	}
+}
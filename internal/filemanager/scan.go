@@ -9,18 +9,26 @@ import (
 	"rulem/pkg/fileops"
 	"slices"
 	"strings"
+	"time"
 )
 
-// markdownExtensions contains supported markdown file extensions
-var markdownExtensions = []string{
-	".md", ".mdown", ".mkdn", ".mkd", ".markdown", ".mdc",
+// IsSkippedDir reports whether directories called name are never scanned for
+// rule files, being matched by the scan excludes. Patterns of a .rulemignore
+// are not considered.
+func IsSkippedDir(name string) bool {
+	return currentExcludes().matcher.Match(name, true)
 }
 
-// isMarkdownFile checks if a filename has a markdown extension.
-// This function is used as a file filter for the directory scanner.
-func isMarkdownFile(filename string) bool {
-	ext := strings.ToLower(filepath.Ext(filename))
-	return slices.Contains(markdownExtensions, ext)
+// IsRuleFilePath reports whether a repository-relative, slash-separated path is
+// one ScanRepository would find: a file with a rule extension the scan excludes
+// do not match.
+// It selects rule files from trees that are not on disk, such as older commits,
+// so the .rulemignore on disk is not considered.
+func IsRuleFilePath(path string) bool {
+	if currentExcludes().matcher.Match(path, false) {
+		return false
+	}
+	return isRuleFile(filepath.Base(filepath.FromSlash(path)))
 }
 
 // ScanCurrDirectory recursively scans the current working directory and all its children
@@ -41,39 +49,11 @@ func (fm *FileManager) ScanCurrDirectory() ([]FileItem, error) {
 		return nil, fmt.Errorf("failed to get current working directory: %w", err)
 	}
 
-	// Create scanner with markdown-specific options
-	opts := &fileops.DirectoryScanOptions{
-		SkipUnreadableDirs: true,
-		MaxDepth:           20,
-		IncludeHidden:      true,
-		SkipPatterns:       []string{"node_modules", ".git", "vendor", "target", "build", ".next", "dist", ".cache", "__pycache__", ".vscode", ".idea"},
-		FileFilter:         isMarkdownFile,
-	}
-
-	// Create secure directory scanner
-	scanner, err := fileops.NewDirectoryScanner(cwd, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create directory scanner: %w", err)
-	}
-	defer scanner.Close()
-
-	// Perform the scan
-	files, err := scanner.ScanDirectory()
+	result, err := scanRuleFiles(cwd, 20)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan directory: %w", err)
 	}
-
-	// Convert fileops.FileInfo to filemanager.FileItem
-	var result []FileItem
-	for _, file := range files {
-		if !file.IsDir { // Only include files, not directories
-			absPath := filepath.Join(cwd, file.Path)
-			result = append(result, FileItem{
-				Name: file.Name,
-				Path: absPath,
-			})
-		}
-	}
+	saveScanCache()
 
 	logging.Debug("Scanned current directory for markdown files", "fileCount", len(result))
 	return result, nil
@@ -86,6 +66,9 @@ func (fm *FileManager) ScanCurrDirectory() ([]FileItem, error) {
 // It performs comprehensive security validation including symlink security checks,
 // reserved directory protection, and path traversal prevention.
 //
+// For shared storage the overlay is scanned too, and an overlay file replaces the
+// shared file with the same relative path. Overlay-only files follow the shared files.
+//
 // Returns:
 //   - []FileItem: List of discovered markdown files with absolute paths
 //   - error: Scanning errors including security violations
@@ -98,15 +81,57 @@ func (fm *FileManager) ScanRepository() ([]FileItem, error) {
 		return nil, fmt.Errorf("filemanager is nil")
 	}
 
-	storageRoot := fm.storageDir
-	if storageRoot == "" {
+	if fm.storageDir == "" {
 		return nil, fmt.Errorf("storage directory is not configured")
 	}
 
+	storageRoot, files, err := fm.scanStorageRoot(fm.storageDir)
+	if err != nil || fm.overlayDir == "" {
+		return files, err
+	}
+
+	overlayRoot, overlayFiles, err := fm.scanStorageRoot(fm.overlayDir)
+	if err != nil {
+		return nil, fmt.Errorf("overlay: %w", err)
+	}
+	return mergeOverlay(storageRoot, files, overlayRoot, overlayFiles), nil
+}
+
+// mergeOverlay replaces shared files with the overlay file at the same relative
+// path and appends the remaining overlay files
+func mergeOverlay(sharedRoot string, shared []FileItem, overlayRoot string, overlay []FileItem) []FileItem {
+	byRel := make(map[string]FileItem, len(overlay))
+	for _, file := range overlay {
+		rel, _ := filepath.Rel(overlayRoot, file.Path)
+		byRel[rel] = file
+	}
+
+	result := make([]FileItem, 0, len(shared)+len(overlay))
+	for _, file := range shared {
+		rel, _ := filepath.Rel(sharedRoot, file.Path)
+		if shadow, ok := byRel[rel]; ok {
+			file = shadow
+			delete(byRel, rel)
+		}
+		result = append(result, file)
+	}
+	for _, file := range overlay {
+		rel, _ := filepath.Rel(overlayRoot, file.Path)
+		if _, ok := byRel[rel]; ok {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+// scanStorageRoot validates and scans one storage directory, returning the
+// resolved root and its markdown files
+func (fm *FileManager) scanStorageRoot(storageRoot string) (string, []FileItem, error) {
+
 	// Handle symlinks with security validation
 	isSymlink, err := fileops.IsSymlink(storageRoot)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check if storage directory is a symlink: %w", err)
+		return "", nil, fmt.Errorf("failed to check if storage directory is a symlink: %w", err)
 	}
 
 	if isSymlink {
@@ -124,49 +149,92 @@ func (fm *FileManager) ScanRepository() ([]FileItem, error) {
 
 		// Validate symlink security
 		if err := fileops.ValidateSymlinkSecurity(storageRoot, allowedPaths); err != nil {
-			return nil, fmt.Errorf("storage directory symlink security validation failed: %w", err)
+			return "", nil, fmt.Errorf("storage directory symlink security validation failed: %w", err)
 		}
 
 		// Resolve the symlink after validation
 		absStorageRootPath, err := fileops.ResolveSymlink(storageRoot)
 		if err != nil {
-			return nil, fmt.Errorf("failed to resolve symlink for storage directory: %w", err)
+			return "", nil, fmt.Errorf("failed to resolve symlink for storage directory: %w", err)
 		}
 		storageRoot = absStorageRootPath
 	} else {
 		// Resolve absolute path
 		absPath, err := filepath.Abs(storageRoot)
 		if err != nil {
-			return nil, fmt.Errorf("failed to resolve storage directory: %w", err)
+			return "", nil, fmt.Errorf("failed to resolve storage directory: %w", err)
 		}
 		storageRoot = absPath
 	}
 
 	// Use comprehensive storage path validation from fileops
 	if err := fileops.ValidateStoragePath(storageRoot); err != nil {
-		return nil, fmt.Errorf("storage directory failed security validation: %w", err)
+		return "", nil, fmt.Errorf("storage directory failed security validation: %w", err)
 	}
 
 	// Ensure path exists and is a directory
 	info, err := os.Stat(storageRoot)
 	if err != nil {
-		return nil, fmt.Errorf("storage directory not accessible: %w", err)
+		return "", nil, fmt.Errorf("storage directory not accessible: %w", err)
 	}
 	if !info.IsDir() {
-		return nil, fmt.Errorf("storage path is not a directory")
+		return "", nil, fmt.Errorf("storage path is not a directory")
+	}
+
+	result, err := scanRuleFiles(storageRoot, 50)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to scan storage directory: %w", err)
 	}
+	result = fm.withoutExcludedDirs(storageRoot, result)
 
-	// Create scanner with markdown-specific options
+	logging.Debug("Scanned central storage for markdown files", "root", storageRoot, "fileCount", len(result))
+	return storageRoot, result, nil
+}
+
+// withoutExcludedDirs drops the files under fm's excluded directories of root
+func (fm *FileManager) withoutExcludedDirs(root string, files []FileItem) []FileItem {
+	if len(fm.excludedDirs) == 0 {
+		return files
+	}
+	return slices.DeleteFunc(files, func(file FileItem) bool {
+		rel, err := filepath.Rel(root, file.Path)
+		if err != nil {
+			return false
+		}
+		top, _, nested := strings.Cut(filepath.ToSlash(rel), "/")
+		return nested && slices.Contains(fm.excludedDirs, top)
+	})
+}
+
+// scanRuleFiles lists the files with a rule extension under root, up to
+// maxDepth directories deep, with absolute paths, leaving out those matched by
+// the scan excludes and root's .rulemignore. The listing comes from the active
+// scan cache when no directory under root, nor the ignore patterns and rule
+// extensions, changed since the last scan.
+func scanRuleFiles(root string, maxDepth int) ([]FileItem, error) {
+	ignorePatterns := scanIgnorePatterns(root)
+	extensions := RuleExtensions()
+	cache := ActiveScanCache()
+	if files, ok := cache.listing(root, ignorePatterns, extensions); ok {
+		result := make([]FileItem, 0, len(files))
+		for _, rel := range files {
+			result = append(result, FileItem{Name: filepath.Base(rel), Path: filepath.Join(root, rel)})
+		}
+		return result, nil
+	}
+
+	// Create scanner with rule-file-specific options
 	opts := &fileops.DirectoryScanOptions{
 		SkipUnreadableDirs: true,
-		MaxDepth:           50,
+		MaxDepth:           maxDepth,
 		IncludeHidden:      true,
-		SkipPatterns:       []string{"node_modules", ".git", "vendor", "target", "build", ".next", "dist", ".cache", "__pycache__", ".vscode", ".idea"},
-		FileFilter:         isMarkdownFile,
+		FileFilter:         isRuleFile,
+		Exclude:            fileops.NewIgnoreMatcher(ignorePatterns).Match,
+		IncludeDirs:        cache != nil,
 	}
 
 	// Create secure directory scanner
-	scanner, err := fileops.NewDirectoryScanner(storageRoot, opts)
+	scanner, err := fileops.NewDirectoryScanner(root, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create directory scanner: %w", err)
 	}
@@ -175,26 +243,37 @@ func (fm *FileManager) ScanRepository() ([]FileItem, error) {
 	// Perform the scan
 	files, err := scanner.ScanDirectory()
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan storage directory: %w", err)
+		return nil, err
 	}
 
-	// Convert fileops.FileInfo to filemanager.FileItem with absolute paths
+	// Convert fileops.FileInfo to filemanager.FileItem with absolute paths,
+	// noting the directories walked for the cache
 	var result []FileItem
+	var rels []string
+	dirs := make(map[string]time.Time)
 	for _, file := range files {
-		if !file.IsDir { // Only include files, not directories
-			// Construct absolute path immediately during scan
-			absPath := filepath.Join(storageRoot, file.Path)
-			result = append(result, FileItem{
-				Name: file.Name,
-				Path: absPath,
-			})
+		if file.IsDir {
+			dirs[file.Path] = file.ModTime
+			continue
 		}
+		result = append(result, FileItem{
+			Name: file.Name,
+			Path: filepath.Join(root, file.Path),
+		})
+		rels = append(rels, file.Path)
 	}
-
-	logging.Debug("Scanned central storage for markdown files", "fileCount", len(result))
+	cache.storeListing(root, ignorePatterns, extensions, dirs, rels)
 	return result, nil
 }
 
+// saveScanCache writes the active scan cache, if any. A cache that cannot be
+// written only costs the next run a full scan.
+func saveScanCache() {
+	if err := ActiveScanCache().Save(); err != nil {
+		logging.Debug("Failed to save scan cache", "error", err)
+	}
+}
+
 // ScanAllRepositories scans multiple repositories and merges their file lists.
 // This function is the main entry point for multi-repository file discovery.
 // Files are tagged with their source repository metadata for display and tracking.
@@ -261,9 +340,9 @@ func ScanAllRepositories(prepared []repository.PreparedRepository, logger *loggi
 		// Determine repository type for metadata
 		repoType := string(prep.Type())
 
-		// Create a temporary FileManager for this repository
+		// Create a temporary FileManager for this repository (with its overlay, if any)
 		// Paths are already validated by PrepareAllRepositories
-		fm, err := NewFileManager(prep.LocalPath, logger)
+		fm, err := NewRepositoryFileManager(prep, logger)
 		if err != nil {
 			errorMsg := fmt.Sprintf("repository %s (%s): failed to create file manager: %v", prep.ID(), prep.Name(), err)
 			scanErrors = append(scanErrors, errorMsg)
@@ -303,6 +382,8 @@ func ScanAllRepositories(prepared []repository.PreparedRepository, logger *loggi
 		}
 	}
 
+	saveScanCache()
+
 	if logger != nil {
 		logger.Info("Multi-repository scan completed",
 			"total_repositories", len(prepared),
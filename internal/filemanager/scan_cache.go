@@ -0,0 +1,232 @@
+package filemanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rulem/pkg/fileops"
+
+	"github.com/adrg/xdg"
+)
+
+// Scan cache
+//
+// Walking large repositories on every MCP start and TUI screen is slow. The
+// scan cache remembers the rule files found under each scanned root along
+// with the modification time of every directory walked. A file cannot be
+// added, removed or renamed without changing the modification time of its
+// directory, so while none changed the listing is reused without reading a
+// single directory. Callers that parse the files can keep what they parsed in
+// the cache too (see Metadata), which is reused while a file's size and
+// modification time stay the same.
+
+// scanCacheVersion is bumped when the layout of the cache file changes; a
+// cache written with another version is discarded
+const scanCacheVersion = 1
+
+// ScanCache keeps scan results between runs in a JSON file. It is safe for
+// concurrent use, and a nil *ScanCache caches nothing.
+type ScanCache struct {
+	path  string
+	mu    sync.Mutex
+	data  scanCacheData
+	dirty bool
+}
+
+// scanCacheData is the content of the cache file
+type scanCacheData struct {
+	Version int                   `json:"version"`
+	Roots   map[string]cachedRoot `json:"roots"` // By absolute root path
+	Files   map[string]cachedFile `json:"files"` // By absolute file path
+}
+
+// cachedRoot is the listing of a scanned root
+type cachedRoot struct {
+	Dirs       map[string]time.Time `json:"dirs"`       // Modification time of every directory walked, by relative path
+	Files      []string             `json:"files"`      // Relative paths of the rule files, in scan order
+	Ignore     []string             `json:"ignore"`     // Ignore patterns the scan applied
+	Extensions []string             `json:"extensions"` // Rule extensions the scan looked for
+}
+
+// cachedFile is metadata parsed from a file, valid while the file is unchanged
+type cachedFile struct {
+	Size     int64           `json:"size"`
+	ModTime  time.Time       `json:"modTime"`
+	Key      string          `json:"key"` // Identifies how Metadata was parsed, see Metadata
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+// ScanCachePath returns the scan cache in the user's cache directory (e.g.
+// ~/.cache/rulem/scan.json on Linux). It can be overridden with the
+// RULEM_SCAN_CACHE_PATH environment variable for testing.
+func ScanCachePath() string {
+	if testPath := os.Getenv("RULEM_SCAN_CACHE_PATH"); testPath != "" {
+		return testPath
+	}
+	return filepath.Join(xdg.CacheHome, "rulem", "scan.json")
+}
+
+// LoadScanCache reads the scan cache at path. A missing, unreadable or
+// outdated cache is empty: the cache only saves work, so it never fails.
+func LoadScanCache(path string) *ScanCache {
+	cache := &ScanCache{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		if json.Unmarshal(data, &cache.data) != nil || cache.data.Version != scanCacheVersion {
+			cache.data = scanCacheData{}
+		}
+	}
+	cache.data.Version = scanCacheVersion
+	if cache.data.Roots == nil {
+		cache.data.Roots = make(map[string]cachedRoot)
+	}
+	if cache.data.Files == nil {
+		cache.data.Files = make(map[string]cachedFile)
+	}
+	return cache
+}
+
+// Save writes the cache to its file when it changed since it was loaded or
+// last saved
+func (c *ScanCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(c.data)
+	if err != nil {
+		return fmt.Errorf("failed to encode scan cache: %w", err)
+	}
+	if err := fileops.EnsureDirectoryExists(filepath.Dir(c.path)); err != nil {
+		return fmt.Errorf("cannot create scan cache directory: %w", err)
+	}
+
+	// Write a temporary file and rename it, so a concurrent run never reads a
+	// partly written cache
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".scan-*")
+	if err != nil {
+		return fmt.Errorf("failed to write scan cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write scan cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write scan cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write scan cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// listing returns the relative paths of the rule files under root from the
+// cache, when they were stored by a scan with the same ignore patterns and
+// rule extensions and no directory under root changed since
+func (c *ScanCache) listing(root string, ignore, extensions []string) ([]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	cached, ok := c.data.Roots[root]
+	c.mu.Unlock()
+	if !ok || !slices.Equal(cached.Ignore, ignore) || !slices.Equal(cached.Extensions, extensions) {
+		return nil, false
+	}
+
+	for dir, modTime := range cached.Dirs {
+		info, err := os.Stat(filepath.Join(root, dir))
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return nil, false
+		}
+	}
+	return cached.Files, true
+}
+
+// storeListing records the rule files found under root, the ignore patterns
+// and rule extensions applied and the modification times of the directories
+// walked to find them. Metadata of files under root that were not found is
+// dropped.
+func (c *ScanCache) storeListing(root string, ignore, extensions []string, dirs map[string]time.Time, files []string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.Roots[root] = cachedRoot{Dirs: dirs, Files: files, Ignore: ignore, Extensions: extensions}
+
+	found := make(map[string]bool, len(files))
+	for _, rel := range files {
+		found[rel] = true
+	}
+	prefix := strings.TrimSuffix(root, string(filepath.Separator)) + string(filepath.Separator)
+	for path := range c.data.Files {
+		if rel, under := strings.CutPrefix(path, prefix); under && !found[rel] {
+			delete(c.data.Files, path)
+		}
+	}
+	c.dirty = true
+}
+
+// Metadata decodes the metadata stored for the file at path into v and
+// reports whether it did. Metadata is only returned while the file has the
+// size and modification time in info, and when it was stored with the same
+// key: callers derive the key from whatever else the metadata depends on, such
+// as settings, so changing those invalidates it.
+func (c *ScanCache) Metadata(path string, info os.FileInfo, key string, v any) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	cached, ok := c.data.Files[path]
+	c.mu.Unlock()
+	if !ok || cached.Key != key || cached.Size != info.Size() || !cached.ModTime.Equal(info.ModTime()) {
+		return false
+	}
+	return json.Unmarshal(cached.Metadata, v) == nil
+}
+
+// SetMetadata stores v as the metadata of the file at path, parsed from the
+// version of the file described by info. Pass the info from before the file
+// was read, so a change made while reading it is not missed.
+func (c *ScanCache) SetMetadata(path string, info os.FileInfo, key string, v any) error {
+	if c == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata of %s: %w", path, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.Files[path] = cachedFile{Size: info.Size(), ModTime: info.ModTime(), Key: key, Metadata: data}
+	c.dirty = true
+	return nil
+}
+
+// activeScanCache is the cache used by scans in this process, see UseScanCache
+var activeScanCache atomic.Pointer[ScanCache]
+
+// UseScanCache makes the scans of this process use cache; nil turns caching
+// off, which is the default
+func UseScanCache(cache *ScanCache) {
+	activeScanCache.Store(cache)
+}
+
+// ActiveScanCache returns the cache set with UseScanCache, or nil
+func ActiveScanCache() *ScanCache {
+	return activeScanCache.Load()
+}
@@ -0,0 +1,138 @@
+package filemanager
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// useTestScanCache makes scans use a cache in a temporary file until the test ends
+func useTestScanCache(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(createTempTestDir(t, "scan_cache_"), "scan.json")
+	UseScanCache(LoadScanCache(path))
+	t.Cleanup(func() { UseScanCache(nil) })
+	return path
+}
+
+// scannedNames returns the sorted names of the markdown files under root
+func scannedNames(t *testing.T, root string) []string {
+	t.Helper()
+	files, err := scanRuleFiles(root, 50)
+	if err != nil {
+		t.Fatalf("scanRuleFiles() failed: %v", err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestScanCache_ReusesListingOfUnchangedTree(t *testing.T) {
+	cachePath := useTestScanCache(t)
+	root := createTempDirStructure(t, map[string]string{
+		"style.md":      "# Style",
+		"go/errors.md":  "# Errors",
+		"notes.txt":     "not a rule",
+		".git/HEAD.md":  "skipped",
+		"docs/intro.md": "# Intro",
+	})
+
+	want := []string{"errors.md", "intro.md", "style.md"}
+	if got := scannedNames(t, root); !slices.Equal(got, want) {
+		t.Fatalf("first scan = %v, want %v", got, want)
+	}
+	if err := ActiveScanCache().Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	// A new run loading the cache; a file added behind the cache's back, with
+	// its directory's modification time restored, is not noticed
+	UseScanCache(LoadScanCache(cachePath))
+	goDir := filepath.Join(root, "go")
+	info, err := os.Stat(goDir)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	createTestFile(t, goDir, "testing.md", "# Testing")
+	if err := os.Chtimes(goDir, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if got := scannedNames(t, root); !slices.Equal(got, want) {
+		t.Errorf("scan of unchanged tree = %v, want the cached %v", got, want)
+	}
+
+	// Once the directory changes, the tree is scanned again
+	later := info.ModTime().Add(time.Second)
+	if err := os.Chtimes(goDir, later, later); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	want = []string{"errors.md", "intro.md", "style.md", "testing.md"}
+	if got := scannedNames(t, root); !slices.Equal(got, want) {
+		t.Errorf("scan after change = %v, want %v", got, want)
+	}
+
+	if err := os.Remove(filepath.Join(root, "style.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	want = []string{"errors.md", "intro.md", "testing.md"}
+	if got := scannedNames(t, root); !slices.Equal(got, want) {
+		t.Errorf("scan after removal = %v, want %v", got, want)
+	}
+}
+
+func TestScanCache_Metadata(t *testing.T) {
+	dir := createTempTestDir(t, "scan_cache_")
+	cache := LoadScanCache(filepath.Join(dir, "scan.json"))
+	path := createTestFile(t, dir, "style.md", "# Style")
+	stat := func() os.FileInfo {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		return info
+	}
+
+	type parsed struct{ Title string }
+	if err := cache.SetMetadata(path, stat(), "v1", parsed{Title: "Style"}); err != nil {
+		t.Fatalf("SetMetadata() failed: %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	cache = LoadScanCache(filepath.Join(dir, "scan.json"))
+
+	var got parsed
+	if !cache.Metadata(path, stat(), "v1", &got) || got.Title != "Style" {
+		t.Errorf("Metadata() = %+v, want the stored metadata", got)
+	}
+	if cache.Metadata(path, stat(), "v2", &got) {
+		t.Error("metadata stored under another key should not be returned")
+	}
+
+	createTestFile(t, dir, "style.md", "# Style, revised")
+	if cache.Metadata(path, stat(), "v1", &got) {
+		t.Error("metadata of a changed file should not be returned")
+	}
+
+	var none *ScanCache
+	if none.Metadata(path, stat(), "v1", &got) || none.Save() != nil {
+		t.Error("a nil cache should cache nothing")
+	}
+}
+
+func TestLoadScanCache_IgnoresUnreadableCache(t *testing.T) {
+	path := createTestFile(t, createTempTestDir(t, "scan_cache_"), "scan.json", "{not json")
+	cache := LoadScanCache(path)
+	if _, ok := cache.listing("/anywhere", nil, nil); ok {
+		t.Error("an unreadable cache should be empty")
+	}
+	if err := cache.Save(); err != nil {
+		t.Errorf("Save() of an unchanged cache failed: %v", err)
+	}
+}
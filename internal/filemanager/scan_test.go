@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"rulem/internal/logging"
 	"rulem/internal/repository"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -24,7 +25,7 @@ func containsAbsolutePath(files []FileItem, baseDir, relativePath string) bool {
 
 // Integration Tests - tests for domain-specific logic that uses fileops
 
-func TestIsMarkdownFile(t *testing.T) {
+func TestIsRuleFile(t *testing.T) {
 	tests := []struct {
 		filename string
 		expected bool
@@ -56,9 +57,32 @@ func TestIsMarkdownFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.filename, func(t *testing.T) {
-			result := isMarkdownFile(tt.filename)
+			result := isRuleFile(tt.filename)
 			if result != tt.expected {
-				t.Errorf("isMarkdownFile(%q) = %v, want %v", tt.filename, result, tt.expected)
+				t.Errorf("isRuleFile(%q) = %v, want %v", tt.filename, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsRuleFilePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"README.md", true},
+		{"go/style.mdc", true},
+		{"go/style.txt", false},
+		{"node_modules/pkg/README.md", false},
+		{"docs/build/guide.md", false},
+		{"docs/builder/guide.md", true},
+		{".git/info/rules.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := IsRuleFilePath(tt.path); got != tt.want {
+				t.Errorf("IsRuleFilePath(%q) = %v, want %v", tt.path, got, tt.want)
 			}
 		})
 	}
@@ -1429,3 +1453,90 @@ func TestScanAllRepositories_SkipsUnavailable(t *testing.T) {
 		t.Fatalf("expected only files from the available repository, got %+v", files)
 	}
 }
+
+// TestScanAllRepositories_Overlay tests that overlay files shadow shared files
+func TestScanAllRepositories_Overlay(t *testing.T) {
+	tempDir := t.TempDir()
+	logger, _ := logging.NewTestLogger()
+
+	sharedPath := filepath.Join(tempDir, "shared")
+	overlayPath := filepath.Join(tempDir, "overlay")
+	createDirWithFiles(t, sharedPath, []string{"a.md", "b.md"})
+	createDirWithFiles(t, overlayPath, []string{"b.md", "c.md"})
+
+	entry := repository.RepositoryEntry{
+		ID:        "shared-123",
+		Name:      "Shared",
+		Type:      repository.RepositoryTypeLocal,
+		CreatedAt: time.Now().Unix(),
+		Path:      sharedPath,
+		Overlay:   &overlayPath,
+	}
+	prep := makePrepared(entry, sharedPath)
+	prep.OverlayPath = overlayPath
+
+	files, err := ScanAllRepositories([]repository.PreparedRepository{prep}, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	want := []string{
+		filepath.Join(sharedPath, "a.md"),
+		filepath.Join(overlayPath, "b.md"),
+		filepath.Join(overlayPath, "c.md"),
+	}
+	var got []string
+	for _, file := range files {
+		got = append(got, file.Path)
+		if file.RepositoryID != "shared-123" {
+			t.Errorf("expected RepositoryID 'shared-123', got %q", file.RepositoryID)
+		}
+	}
+	slices.Sort(got)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("expected files %v, got %v", want, got)
+	}
+}
+
+// TestScanAllRepositories_SkipsProposals tests that staged proposals are left
+// out of repositories that accept them, and only those
+func TestScanAllRepositories_SkipsProposals(t *testing.T) {
+	tempDir := t.TempDir()
+	logger, _ := logging.NewTestLogger()
+
+	repoPath := filepath.Join(tempDir, "repo")
+	if err := os.MkdirAll(filepath.Join(repoPath, "team", "proposed"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	createDirWithFiles(t, filepath.Join(repoPath, "proposed"), []string{"new.md"})
+	createDirWithFiles(t, repoPath, []string{"style.md", "team/proposed/kept.md"})
+
+	entry := repository.RepositoryEntry{
+		ID:              "repo-123",
+		Name:            "Repository",
+		Type:            repository.RepositoryTypeLocal,
+		CreatedAt:       time.Now().Unix(),
+		Path:            repoPath,
+		AcceptProposals: true,
+	}
+	files, err := ScanAllRepositories([]repository.PreparedRepository{makePrepared(entry, repoPath)}, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var got []string
+	for _, file := range files {
+		rel, _ := filepath.Rel(repoPath, file.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	slices.Sort(got)
+	if want := []string{"style.md", "team/proposed/kept.md"}; !slices.Equal(got, want) {
+		t.Errorf("expected files %v, got %v", want, got)
+	}
+
+	entry.AcceptProposals = false
+	files, err = ScanAllRepositories([]repository.PreparedRepository{makePrepared(entry, repoPath)}, logger)
+	if err != nil || len(files) != 3 {
+		t.Errorf("expected every file of a repository that does not accept proposals, got %d: %v", len(files), err)
+	}
+}
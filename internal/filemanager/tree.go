@@ -0,0 +1,186 @@
+package filemanager
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Directory tree
+//
+// ScanTree returns the rule files of a repository as a tree of folders rather
+// than a flat list, for screens that show large collections as collapsible
+// folders. The tree holds the same files as ScanRepository: only folders that
+// contain rule files appear, and an overlay file replaces the shared file at
+// the same relative path.
+
+// TreeNode is a folder or a rule file in the tree returned by ScanTree
+type TreeNode struct {
+	Name    string // Base name; the root is named after the storage directory
+	Path    string // Absolute filesystem path
+	RelPath string // Slash-separated path relative to the repository root, "" for the root
+
+	// File is the scanned rule file, nil for folders
+	File *FileItem
+
+	// Children of a folder: folders first, then files, each sorted by name
+	Children []*TreeNode
+}
+
+// IsDir reports whether the node is a folder
+func (n *TreeNode) IsDir() bool {
+	return n.File == nil
+}
+
+// FileCount returns the number of rule files in the node and its subfolders
+func (n *TreeNode) FileCount() int {
+	if !n.IsDir() {
+		return 1
+	}
+	count := 0
+	for _, child := range n.Children {
+		count += child.FileCount()
+	}
+	return count
+}
+
+// Find returns the node at the slash-separated path relative to the root, or
+// nil when there is none
+func (n *TreeNode) Find(relPath string) *TreeNode {
+	relPath = strings.Trim(path.Clean("/"+filepath.ToSlash(relPath)), "/")
+	if relPath == "" {
+		return n
+	}
+	node := n
+	for _, name := range strings.Split(relPath, "/") {
+		node = node.child(name)
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// Walk calls fn for the node and every node below it, parents before their
+// children, with depth 0 for the node itself. Returning false from fn for a
+// folder skips its children, e.g. to leave out collapsed folders.
+func (n *TreeNode) Walk(fn func(node *TreeNode, depth int) bool) {
+	n.walk(fn, 0)
+}
+
+func (n *TreeNode) walk(fn func(node *TreeNode, depth int) bool, depth int) {
+	if !fn(n, depth) {
+		return
+	}
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// Files returns the rule files below the node in tree order
+func (n *TreeNode) Files() []FileItem {
+	var files []FileItem
+	n.Walk(func(node *TreeNode, _ int) bool {
+		if !node.IsDir() {
+			files = append(files, *node.File)
+		}
+		return true
+	})
+	return files
+}
+
+// ScanTree scans the repository like ScanRepository and returns its rule files
+// as a tree of folders rooted at the storage directory.
+//
+// Returns:
+//   - *TreeNode: The root folder, without children when there are no rule files
+//   - error: Scanning errors including security violations
+func (fm *FileManager) ScanTree() (*TreeNode, error) {
+	if fm == nil {
+		return nil, fmt.Errorf("filemanager is nil")
+	}
+
+	if fm.storageDir == "" {
+		return nil, fmt.Errorf("storage directory is not configured")
+	}
+
+	storageRoot, files, err := fm.scanStorageRoot(fm.storageDir)
+	if err != nil {
+		return nil, err
+	}
+	root := &TreeNode{Name: filepath.Base(storageRoot), Path: storageRoot}
+	if err := root.addFiles(storageRoot, files); err != nil {
+		return nil, err
+	}
+
+	if fm.overlayDir != "" {
+		overlayRoot, overlayFiles, err := fm.scanStorageRoot(fm.overlayDir)
+		if err != nil {
+			return nil, fmt.Errorf("overlay: %w", err)
+		}
+		if err := root.addFiles(overlayRoot, overlayFiles); err != nil {
+			return nil, fmt.Errorf("overlay: %w", err)
+		}
+	}
+
+	root.sort()
+	return root, nil
+}
+
+// addFiles adds files scanned under scanRoot to the tree, creating their
+// folders and replacing files already at the same relative path
+func (n *TreeNode) addFiles(scanRoot string, files []FileItem) error {
+	for _, file := range files {
+		rel, err := filepath.Rel(scanRoot, file.Path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", file.Path, err)
+		}
+		names := strings.Split(filepath.ToSlash(rel), "/")
+
+		folder := n
+		for i, name := range names[:len(names)-1] {
+			child := folder.child(name)
+			if child == nil {
+				relPath := path.Join(names[:i+1]...)
+				child = &TreeNode{Name: name, Path: filepath.Join(scanRoot, filepath.FromSlash(relPath)), RelPath: relPath}
+				folder.Children = append(folder.Children, child)
+			}
+			folder = child
+		}
+
+		name := names[len(names)-1]
+		node := &TreeNode{Name: name, Path: file.Path, RelPath: path.Join(names...), File: &file}
+		if existing := folder.child(name); existing != nil {
+			*existing = *node
+			continue
+		}
+		folder.Children = append(folder.Children, node)
+	}
+	return nil
+}
+
+// child returns the direct child called name, or nil
+func (n *TreeNode) child(name string) *TreeNode {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// sort orders the children of every folder, folders first
+func (n *TreeNode) sort() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.IsDir() != b.IsDir() {
+			return a.IsDir()
+		}
+		return a.Name < b.Name
+	})
+	for _, child := range n.Children {
+		child.sort()
+	}
+}
@@ -0,0 +1,149 @@
+package filemanager
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// treeLines renders the tree one node per line, indented by depth, with a
+// trailing slash on folders
+func treeLines(root *TreeNode) []string {
+	var lines []string
+	root.Walk(func(node *TreeNode, depth int) bool {
+		if depth == 0 {
+			return true
+		}
+		line := strings.Repeat("  ", depth-1) + node.Name
+		if node.IsDir() {
+			line += "/"
+		}
+		lines = append(lines, line)
+		return true
+	})
+	return lines
+}
+
+func TestScanTree(t *testing.T) {
+	storageDir := createTempDirStructure(t, map[string]string{
+		"zeta.md":              "# zeta",
+		"alpha.md":             "# alpha",
+		"go/testing/mocks.md":  "# mocks",
+		"go/errors.md":         "# errors",
+		"docs/notes.txt":       "not a rule",
+		".git/HEAD":            "ref: refs/heads/main",
+		"python/typing.mdc":    "# typing",
+		"python/empty/keep.go": "package keep",
+	})
+	fm, err := NewFileManager(storageDir, createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create FileManager: %v", err)
+	}
+
+	root, err := fm.ScanTree()
+	if err != nil {
+		t.Fatalf("ScanTree failed: %v", err)
+	}
+
+	want := []string{
+		"go/",
+		"  testing/",
+		"    mocks.md",
+		"  errors.md",
+		"python/",
+		"  typing.mdc",
+		"alpha.md",
+		"zeta.md",
+	}
+	if got := treeLines(root); !slices.Equal(got, want) {
+		t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if root.FileCount() != 5 {
+		t.Errorf("FileCount() = %d, want 5", root.FileCount())
+	}
+
+	mocks := root.Find("go/testing/mocks.md")
+	if mocks == nil || mocks.IsDir() || mocks.File.Name != "mocks.md" {
+		t.Fatalf("Find(go/testing/mocks.md) = %+v, want the rule file", mocks)
+	}
+	if mocks.RelPath != "go/testing/mocks.md" {
+		t.Errorf("RelPath = %q, want go/testing/mocks.md", mocks.RelPath)
+	}
+	if filepath.Base(filepath.Dir(mocks.Path)) != "testing" {
+		t.Errorf("Path = %q, want the absolute path of the file", mocks.Path)
+	}
+	if folder := root.Find("go"); folder == nil || !folder.IsDir() || folder.FileCount() != 2 {
+		t.Errorf("Find(go) = %+v, want a folder with 2 files", folder)
+	}
+	if root.Find("docs") != nil {
+		t.Error("folders without rule files must be left out")
+	}
+
+	// The tree holds the same files as the flat scan
+	flat, err := fm.ScanRepository()
+	if err != nil {
+		t.Fatalf("ScanRepository failed: %v", err)
+	}
+	if got := root.Files(); len(got) != len(flat) {
+		t.Errorf("Files() returned %d files, ScanRepository %d", len(got), len(flat))
+	}
+
+	// Collapsed folders are skipped by returning false
+	var visible []string
+	root.Walk(func(node *TreeNode, depth int) bool {
+		if depth > 0 {
+			visible = append(visible, node.RelPath)
+		}
+		return node.RelPath != "go"
+	})
+	if want := []string{"go", "python", "python/typing.mdc", "alpha.md", "zeta.md"}; !slices.Equal(visible, want) {
+		t.Errorf("visible = %v, want %v", visible, want)
+	}
+}
+
+func TestScanTreeWithOverlay(t *testing.T) {
+	sharedDir := createTempDirStructure(t, map[string]string{
+		"team/style.md": "# shared style",
+		"team/go.md":    "# go",
+	})
+	overlayDir := createTempDirStructure(t, map[string]string{
+		"team/style.md": "# my style",
+		"mine/notes.md": "# notes",
+	})
+	fm, err := NewOverlayFileManager(sharedDir, overlayDir, createTestLogger())
+	if err != nil {
+		t.Fatalf("NewOverlayFileManager failed: %v", err)
+	}
+
+	root, err := fm.ScanTree()
+	if err != nil {
+		t.Fatalf("ScanTree failed: %v", err)
+	}
+
+	want := []string{"mine/", "  notes.md", "team/", "  go.md", "  style.md"}
+	if got := treeLines(root); !slices.Equal(got, want) {
+		t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	style := root.Find("team/style.md")
+	if style == nil || !strings.HasPrefix(style.Path, overlayDir) {
+		t.Errorf("team/style.md = %+v, want the overlay file", style)
+	}
+}
+
+func TestScanTreeEmpty(t *testing.T) {
+	fm, err := NewFileManager(createTempDirStructure(t, nil), createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create FileManager: %v", err)
+	}
+	root, err := fm.ScanTree()
+	if err != nil {
+		t.Fatalf("ScanTree failed: %v", err)
+	}
+	if !root.IsDir() || len(root.Children) != 0 || root.RelPath != "" {
+		t.Errorf("root = %+v, want an empty folder", root)
+	}
+	if root.Find("") != root {
+		t.Error("Find(\"\") must return the root")
+	}
+}
@@ -3,6 +3,8 @@ package filemanager
 import (
 	"fmt"
 	"strings"
+
+	"rulem/internal/frontmatter"
 )
 
 // File item that is compatible with bubble's List model
@@ -12,14 +14,30 @@ type FileItem struct {
 	Path string // Absolute filesystem path (validated during scan)
 
 	// Repository metadata (for multi-repository support)
-	RepositoryID   string // Links to RepositoryEntry.ID (e.g., "personal-rules-1728756432")
+	RepositoryID   string // Links to RepositoryEntry.ID (e.g., "personal-rules-3f9a0c12")
 	RepositoryName string // Denormalized for display (e.g., "Personal Rules")
 	RepositoryType string // "local" or "github" (for styling/icons)
+
+	// Rating is the user's private star rating of the rule (0 when unrated), for display
+	Rating int
+
+	// Metadata is the frontmatter of the rule, for display; nil when it was
+	// not read or could not be parsed
+	Metadata *frontmatter.Metadata
 }
 
-// Title returns the file name for display in bubble tea list
+// Title returns the file name for display in bubble tea list, followed by
+// the user's star rating when the file is rated and a mark when the rule is
+// deprecated
 func (i FileItem) Title() string {
-	return i.Name
+	title := i.Name
+	if i.Rating > 0 {
+		title += " " + strings.Repeat("★", i.Rating)
+	}
+	if i.Metadata != nil && i.Metadata.Deprecated {
+		title += " (deprecated)"
+	}
+	return title
 }
 
 // Description returns repository information for display in bubble tea list
@@ -36,11 +54,14 @@ func (i FileItem) Description() string {
 }
 
 // FilterValue returns the combined search string for bubble tea filtering
-// Includes file name, path, and repository name for comprehensive search
+// Includes file name, path, repository name and tags for comprehensive search
 func (i FileItem) FilterValue() string {
 	parts := []string{i.Name, i.Path}
 	if i.RepositoryName != "" {
 		parts = append(parts, i.RepositoryName)
 	}
+	if i.Metadata != nil {
+		parts = append(parts, i.Metadata.Tags...)
+	}
 	return strings.Join(parts, " ")
 }
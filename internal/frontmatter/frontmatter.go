@@ -0,0 +1,395 @@
+// Package frontmatter defines the metadata rules declare in their frontmatter
+// and how it is decoded and validated.
+//
+// A rule's frontmatter may be YAML, TOML or JSON (see the rule formats of the
+// mcp package, which finds the block in a file). Whatever the syntax, its keys
+// are decoded strictly into Metadata: a key of the wrong type, such as a
+// version written as a number or a priority written as text, is an error that
+// names the key rather than a value silently converted or dropped. Keys
+// rulem does not know, like the globs of a Cursor rule, are left to the
+// editors that read them.
+//
+//	---
+//	description: Go style guide
+//	name: go_style
+//	scope: ["**/*.go", go.mod]
+//	tags: [go, style]
+//	priority: 10
+//	version: 1.2.0
+//	deprecated: false
+//	---
+package frontmatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DateLayout is the format of the expires and reviewBy dates
+const DateLayout = "2006-01-02"
+
+// Limits of the metadata fields
+const (
+	MaxDescriptionLength = 500
+	MaxNameLength        = 100
+	MaxApplyToLength     = 200
+	MinPriority          = -100
+	MaxPriority          = 100
+)
+
+// Metadata is the frontmatter of a rule. It decodes from YAML, TOML and JSON
+// with the same strict rules, see the package documentation.
+type Metadata struct {
+	Description string   `yaml:"description" toml:"description" json:"description"`
+	Name        string   `yaml:"name,omitempty" toml:"name,omitempty" json:"name,omitempty"`
+	ApplyTo     string   `yaml:"applyTo,omitempty" toml:"applyTo,omitempty" json:"applyTo,omitempty"`
+	Scope       []string `yaml:"scope,omitempty" toml:"scope,omitempty" json:"scope,omitempty"` // Globs of the files the rule applies to
+	Tags        []string `yaml:"tags,omitempty" toml:"tags,omitempty" json:"tags,omitempty"`
+	Priority    int      `yaml:"priority,omitempty" toml:"priority,omitempty" json:"priority,omitempty"` // Higher comes first, 0 by default
+	Version     string   `yaml:"version,omitempty" toml:"version,omitempty" json:"version,omitempty"`
+	Deprecated  bool     `yaml:"deprecated,omitempty" toml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	License     string   `yaml:"license,omitempty" toml:"license,omitempty" json:"license,omitempty"`
+	Attribution string   `yaml:"attribution,omitempty" toml:"attribution,omitempty" json:"attribution,omitempty"`
+	Expires     string   `yaml:"expires,omitempty" toml:"expires,omitempty" json:"expires,omitempty"`    // DateLayout
+	ReviewBy    string   `yaml:"reviewBy,omitempty" toml:"reviewBy,omitempty" json:"reviewBy,omitempty"` // DateLayout
+}
+
+// Keys describes the frontmatter keys rulem understands. The `check` key is
+// read by the checks package rather than Metadata.
+var Keys = map[string]string{
+	"description": "What the rule is for. Required: rules without a description are not registered as MCP tools unless auto_descriptions is set.",
+	"name":        "Tool name for the rule. Defaults to the file name.",
+	"applyTo":     "Where the rule applies, e.g. a glob or a kind of project.",
+	"scope":       "Globs of the files the rule applies to, e.g. [\"**/*.go\"]. A single glob may be written as text.",
+	"tags":        "Tags for the rule, from the repository's rulem.yaml tag list when it has one.",
+	"priority":    fmt.Sprintf("Whole number from %d to %d; rules with a higher priority come first. Defaults to 0.", MinPriority, MaxPriority),
+	"version":     "Version of the rule, e.g. 1.2.0. Write it as text: version: \"1.2\" in YAML.",
+	"deprecated":  "true when the rule should no longer be used. Deprecated rules are still served, marked as deprecated.",
+	"check":       "Lint checks run by `rulem check`, each with a `pattern`, `files` glob and `message`.",
+	"license":     "License of the rule, e.g. an SPDX identifier. Reported by `rulem inventory`.",
+	"attribution": "Who wrote the rule or where it was adapted from, e.g. an author or URL.",
+	"expires":     "Date the rule stops applying, as YYYY-MM-DD. Listed in `rulem summary` as it nears.",
+	"reviewBy":    "Date the rule should be reviewed by, as YYYY-MM-DD. Listed in `rulem summary` as it nears.",
+}
+
+// FieldError is a problem with one frontmatter key
+type FieldError struct {
+	Key     string // The frontmatter key, e.g. "priority"
+	Message string // What is wrong, naming the key
+}
+
+// Error returns the message
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// Errors lists every problem found in a frontmatter, in key order
+type Errors []*FieldError
+
+// Error joins the messages of the problems
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// errorf records a problem with key
+func (e *Errors) errorf(key, format string, args ...any) {
+	*e = append(*e, &FieldError{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns the problems as an error, or nil when there are none
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// UnmarshalYAML decodes YAML frontmatter strictly
+func (m *Metadata) UnmarshalYAML(node *yaml.Node) error {
+	var fields map[string]any
+	if err := node.Decode(&fields); err != nil {
+		return fmt.Errorf("frontmatter is not a set of keys: %w", err)
+	}
+	return m.decode(fields)
+}
+
+// UnmarshalJSON decodes JSON frontmatter strictly
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	var fields map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // Keeps 1.5 from passing as a priority of 1
+	if err := decoder.Decode(&fields); err != nil {
+		return fmt.Errorf("frontmatter is not a set of keys: %w", err)
+	}
+	return m.decode(fields)
+}
+
+// UnmarshalTOML decodes TOML frontmatter strictly
+func (m *Metadata) UnmarshalTOML(data any) error {
+	fields, ok := data.(map[string]any)
+	if !ok {
+		return fmt.Errorf("frontmatter is not a set of keys")
+	}
+	return m.decode(fields)
+}
+
+// Decode builds the metadata of a frontmatter decoded into fields, as by
+// encoding/json, yaml.v3 or BurntSushi/toml. Unknown keys are ignored.
+//
+// Returns:
+//   - Metadata: The known keys that decoded
+//   - error: Errors listing every key of the wrong type, or nil
+func Decode(fields map[string]any) (Metadata, error) {
+	var m Metadata
+	err := m.decode(fields)
+	return m, err
+}
+
+// decode replaces m with the known keys of fields, checking their types
+func (m *Metadata) decode(fields map[string]any) error {
+	*m = Metadata{}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs Errors
+	for _, key := range keys {
+		value := fields[key]
+		if value == nil {
+			continue // An empty key is unset
+		}
+		switch key {
+		case "description":
+			m.Description = decodeText(&errs, key, value)
+		case "name":
+			m.Name = decodeText(&errs, key, value)
+		case "applyTo":
+			m.ApplyTo = decodeText(&errs, key, value)
+		case "scope":
+			if glob, ok := value.(string); ok {
+				m.Scope = []string{glob}
+			} else {
+				m.Scope = decodeList(&errs, key, value)
+			}
+		case "tags":
+			m.Tags = decodeList(&errs, key, value)
+		case "priority":
+			m.Priority = decodeInt(&errs, key, value)
+		case "version":
+			m.Version = decodeText(&errs, key, value)
+		case "deprecated":
+			if deprecated, ok := value.(bool); ok {
+				m.Deprecated = deprecated
+			} else {
+				errs.errorf(key, "%s must be true or false, got %s", key, describe(value))
+			}
+		case "license":
+			m.License = decodeText(&errs, key, value)
+		case "attribution":
+			m.Attribution = decodeText(&errs, key, value)
+		case "expires":
+			m.Expires = decodeDate(&errs, key, value)
+		case "reviewBy":
+			m.ReviewBy = decodeDate(&errs, key, value)
+		}
+	}
+	return errs.err()
+}
+
+// decodeText returns value when it is text
+func decodeText(errs *Errors, key string, value any) string {
+	text, ok := value.(string)
+	if !ok {
+		errs.errorf(key, "%s must be text, got %s (quote it)", key, describe(value))
+	}
+	return text
+}
+
+// decodeList returns value when it is a list of text
+func decodeList(errs *Errors, key string, value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		errs.errorf(key, "%s must be a list, got %s", key, describe(value))
+		return nil
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		text, ok := item.(string)
+		if !ok {
+			errs.errorf(key, "%s must be a list of text, got %s in it", key, describe(item))
+			return nil
+		}
+		list = append(list, text)
+	}
+	return list
+}
+
+// decodeInt returns value when it is a whole number
+func decodeInt(errs *Errors, key string, value any) int {
+	var n int64
+	switch v := value.(type) {
+	case int:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint64:
+		if v > math.MaxInt32 {
+			errs.errorf(key, "%s is out of range, got %d", key, v)
+			return 0
+		}
+		n = int64(v)
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			errs.errorf(key, "%s must be a whole number, got %s", key, v)
+			return 0
+		}
+		n = i
+	default:
+		errs.errorf(key, "%s must be a whole number, got %s", key, describe(value))
+		return 0
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		errs.errorf(key, "%s is out of range, got %d", key, n)
+		return 0
+	}
+	return int(n)
+}
+
+// decodeDate returns value as a DateLayout date when it is text or a date, as
+// YAML and TOML decode unquoted dates. The text is checked by Validate.
+func decodeDate(errs *Errors, key string, value any) string {
+	if date, ok := value.(time.Time); ok {
+		return date.Format(DateLayout)
+	}
+	return decodeText(errs, key, value)
+}
+
+// describe names the type of a decoded value for an error
+func describe(value any) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("'%s'", v)
+	case bool:
+		return fmt.Sprintf("%t", v)
+	case int, int64, uint64, float64, json.Number:
+		return fmt.Sprintf("the number %v", v)
+	case time.Time:
+		return "the date " + v.Format(DateLayout)
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a set of keys"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// versionPattern matches versions like 1, 1.2, v1.2.0 and 2.0.0-beta.1
+var versionPattern = regexp.MustCompile(`^v?\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?$`)
+
+// Validate checks the metadata of a rule that is served: it must have a
+// description, fields must fit their limits, scope globs must be valid and
+// dates must be YYYY-MM-DD.
+//
+// Returns:
+//   - error: Errors listing every problem, or nil
+func (m Metadata) Validate() error {
+	var errs Errors
+
+	switch {
+	case strings.TrimSpace(m.Description) == "":
+		errs.errorf("description", "missing required 'description' field")
+	case len(m.Description) > MaxDescriptionLength:
+		errs.errorf("description", "description too long (max %d characters)", MaxDescriptionLength)
+	}
+	if len(m.Name) > MaxNameLength {
+		errs.errorf("name", "name too long (max %d characters)", MaxNameLength)
+	}
+	if len(m.ApplyTo) > MaxApplyToLength {
+		errs.errorf("applyTo", "applyTo field too long (max %d characters)", MaxApplyToLength)
+	}
+
+	for _, glob := range m.Scope {
+		if strings.TrimSpace(glob) == "" {
+			errs.errorf("scope", "scope cannot have an empty glob")
+			continue
+		}
+		if _, err := path.Match(glob, ""); err != nil {
+			errs.errorf("scope", "scope glob '%s' is invalid: %v", glob, err)
+		}
+	}
+	for _, tag := range m.Tags {
+		if strings.TrimSpace(tag) == "" {
+			errs.errorf("tags", "tags cannot have an empty tag")
+			break
+		}
+	}
+
+	if m.Priority < MinPriority || m.Priority > MaxPriority {
+		errs.errorf("priority", "priority must be from %d to %d, got %d", MinPriority, MaxPriority, m.Priority)
+	}
+	if m.Version != "" && !versionPattern.MatchString(m.Version) {
+		errs.errorf("version", "version must be like 1.2.0, got '%s'", m.Version)
+	}
+
+	dates := []struct{ key, value string }{{"expires", m.Expires}, {"reviewBy", m.ReviewBy}}
+	for _, date := range dates {
+		if date.value == "" {
+			continue
+		}
+		if _, err := time.Parse(DateLayout, date.value); err != nil {
+			errs.errorf(date.key, "%s must be a date like %s, got '%s'", date.key, DateLayout, date.value)
+		}
+	}
+
+	return errs.err()
+}
+
+// Has reports whether the key is set, for schemas that require keys. Keys
+// with a default, priority and deprecated, cannot be required.
+//
+// Returns:
+//   - bool: Whether the key has a value
+//   - error: When the key cannot be required
+func (m Metadata) Has(key string) (bool, error) {
+	switch key {
+	case "description":
+		return strings.TrimSpace(m.Description) != "", nil
+	case "name":
+		return strings.TrimSpace(m.Name) != "", nil
+	case "applyTo":
+		return strings.TrimSpace(m.ApplyTo) != "", nil
+	case "scope":
+		return len(m.Scope) > 0, nil
+	case "tags":
+		return len(m.Tags) > 0, nil
+	case "version":
+		return strings.TrimSpace(m.Version) != "", nil
+	case "license":
+		return strings.TrimSpace(m.License) != "", nil
+	case "attribution":
+		return strings.TrimSpace(m.Attribution) != "", nil
+	case "expires":
+		return strings.TrimSpace(m.Expires) != "", nil
+	case "reviewBy":
+		return strings.TrimSpace(m.ReviewBy) != "", nil
+	default:
+		return false, fmt.Errorf("schema requires unsupported field '%s'", key)
+	}
+}
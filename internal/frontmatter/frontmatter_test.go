@@ -0,0 +1,138 @@
+package frontmatter
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+func TestUnmarshal(t *testing.T) {
+	want := Metadata{
+		Description: "Go style",
+		Scope:       []string{"**/*.go"},
+		Tags:        []string{"go", "style"},
+		Priority:    10,
+		Version:     "1.2.0",
+		Deprecated:  true,
+		Expires:     "2030-01-31",
+	}
+	tests := []struct {
+		name      string
+		unmarshal func([]byte, any) error
+		content   string
+	}{
+		{
+			name:      "yaml",
+			unmarshal: yaml.Unmarshal,
+			content:   "description: Go style\nscope: \"**/*.go\"\ntags: [go, style]\npriority: 10\nversion: 1.2.0\ndeprecated: true\nexpires: 2030-01-31\nglobs: ignored\n",
+		},
+		{
+			name:      "toml",
+			unmarshal: toml.Unmarshal,
+			content:   "description = \"Go style\"\nscope = [\"**/*.go\"]\ntags = [\"go\", \"style\"]\npriority = 10\nversion = \"1.2.0\"\ndeprecated = true\nexpires = 2030-01-31\n",
+		},
+		{
+			name:      "json",
+			unmarshal: json.Unmarshal,
+			content:   `{"description": "Go style", "scope": ["**/*.go"], "tags": ["go", "style"], "priority": 10, "version": "1.2.0", "deprecated": true, "expires": "2030-01-31"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Metadata
+			if err := tt.unmarshal([]byte(tt.content), &m); err != nil {
+				t.Fatalf("unmarshal failed: %v", err)
+			}
+			if !reflect.DeepEqual(m, want) {
+				t.Errorf("got %+v, want %+v", m, want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "version as a number", content: "version: 1.2\n", want: []string{"version must be text, got the number 1.2"}},
+		{name: "priority as text", content: "priority: high\n", want: []string{"priority must be a whole number, got 'high'"}},
+		{name: "fractional priority", content: "priority: 1.5\n", want: []string{"priority must be a whole number"}},
+		{name: "tags as text", content: "tags: go\n", want: []string{"tags must be a list, got 'go'"}},
+		{name: "deprecated as text", content: "deprecated: soon\n", want: []string{"deprecated must be true or false"}},
+		{
+			name:    "every problem is reported",
+			content: "description: [a]\nscope: [1]\n",
+			want:    []string{"description must be text, got a list", "scope must be a list of text"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Metadata
+			err := yaml.Unmarshal([]byte(tt.content), &m)
+			var errs Errors
+			if !errors.As(err, &errs) || len(errs) != len(tt.want) {
+				t.Fatalf("error = %v, want %d problems", err, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(errs[i].Message, want) {
+					t.Errorf("problem %d = %q, want %q", i, errs[i].Message, want)
+				}
+			}
+		})
+	}
+
+	var m Metadata
+	if err := json.Unmarshal([]byte(`{"priority": 1.5}`), &m); err == nil || !strings.Contains(err.Error(), "whole number") {
+		t.Errorf("json error = %v, want a fractional priority rejected", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Metadata
+		want string
+	}{
+		{name: "valid", m: Metadata{Description: "d", Scope: []string{"src/**/*.ts"}, Priority: -5, Version: "v2.0.0-beta.1", ReviewBy: "2030-06-30"}},
+		{name: "missing description", m: Metadata{Description: " "}, want: "missing required 'description' field"},
+		{name: "description too long", m: Metadata{Description: strings.Repeat("a", 501)}, want: "description too long"},
+		{name: "invalid glob", m: Metadata{Description: "d", Scope: []string{"[a-"}}, want: "scope glob '[a-' is invalid"},
+		{name: "empty tag", m: Metadata{Description: "d", Tags: []string{"go", ""}}, want: "empty tag"},
+		{name: "priority out of range", m: Metadata{Description: "d", Priority: 101}, want: "priority must be from -100 to 100"},
+		{name: "invalid version", m: Metadata{Description: "d", Version: "latest"}, want: "version must be like 1.2.0"},
+		{name: "invalid date", m: Metadata{Description: "d", Expires: "next week"}, want: "expires must be a date like 2006-01-02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.m.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHas(t *testing.T) {
+	m := Metadata{Description: "d", Scope: []string{"*.go"}}
+	for key, want := range map[string]bool{"description": true, "scope": true, "version": false} {
+		if got, err := m.Has(key); err != nil || got != want {
+			t.Errorf("Has(%s) = %v, %v; want %v", key, got, err, want)
+		}
+	}
+	if _, err := m.Has("priority"); err == nil {
+		t.Error("Has(priority) should fail: priority has a default")
+	}
+}
@@ -0,0 +1,221 @@
+// Package hooks runs user-configured scripts and webhooks on lifecycle events,
+// so rulem can be integrated with chat notifications, documentation builds and
+// the like without forking it:
+//
+//	hooks:
+//	  - event: post-sync
+//	    command: ./scripts/rebuild-docs.sh
+//	  - event: rule-created
+//	    url: https://hooks.slack.com/services/T000/B000/XXXX
+//
+// Events:
+//
+//	post-sync     A GitHub repository was synced
+//	pre-deploy    A rule is about to be imported into a project
+//	post-deploy   A rule was imported into a project
+//	rule-created  A new rule was saved to a repository
+//
+// Each hook receives the event as a JSON Payload: on stdin for commands, which
+// run through the shell in the current directory with RULEM_EVENT set, and as
+// the body of a POST for webhooks. Hooks of an event run in configuration
+// order, each with a Timeout.
+//
+// A failing pre-deploy hook (a non-zero exit status or a non-2xx response)
+// cancels the deployment. Failures of other hooks are logged and otherwise
+// ignored, so a broken integration never blocks rulem.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+)
+
+// Lifecycle events hooks can be configured for
+const (
+	EventPostSync    = "post-sync"
+	EventPreDeploy   = "pre-deploy"
+	EventPostDeploy  = "post-deploy"
+	EventRuleCreated = "rule-created"
+)
+
+// Events lists the lifecycle events in the order they are documented
+var Events = []string{EventPostSync, EventPreDeploy, EventPostDeploy, EventRuleCreated}
+
+// Timeout bounds how long a single hook may run
+const Timeout = 30 * time.Second
+
+// maxOutput bounds how much of a failing command's output is reported
+const maxOutput = 500
+
+// Payload describes an event. Fields that do not apply to the event are omitted.
+type Payload struct {
+	Event        string    `json:"event"`
+	Time         time.Time `json:"time"`
+	Repository   string    `json:"repository,omitempty"`   // Repository name
+	RepositoryID string    `json:"repositoryId,omitempty"` // Repository ID from the configuration
+	Commit       string    `json:"commit,omitempty"`       // post-sync: the commit checked out
+	Rule         string    `json:"rule,omitempty"`         // Path of the rule in the repository, slash-separated
+	Path         string    `json:"path,omitempty"`         // Absolute path of the rule in the repository
+	Destination  string    `json:"destination,omitempty"`  // Deploy events: path of the rule in the project
+	Mode         string    `json:"mode,omitempty"`         // Deploy events: "copy" or "link"
+}
+
+// RulePayload returns the payload of event for the rule at path in prep
+func RulePayload(event string, prep repository.PreparedRepository, path string) Payload {
+	payload := Payload{Event: event, Repository: prep.Name(), RepositoryID: prep.ID(), Path: path}
+	for _, root := range []string{prep.OverlayPath, prep.LocalPath} {
+		if root == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(root, path); err == nil && filepath.IsLocal(rel) {
+			payload.Rule = filepath.ToSlash(rel)
+			break
+		}
+	}
+	return payload
+}
+
+// Validate reports the first hook that names an unknown event or does not set
+// exactly one of a command and a URL
+func Validate(hooks []config.Hook) error {
+	for i, hook := range hooks {
+		if !slices.Contains(Events, hook.Event) {
+			return fmt.Errorf("hook %d has unknown event '%s' (want one of %s)", i+1, hook.Event, strings.Join(Events, ", "))
+		}
+		if (hook.Command == "") == (hook.URL == "") {
+			return fmt.Errorf("hook %d must set either a command or a url", i+1)
+		}
+		if hook.URL != "" {
+			if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("hook %d has an invalid url: want an http or https URL", i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// Run runs the hooks configured for payload.Event and returns their failures.
+// Every hook runs even when an earlier one fails. A zero payload.Time is set
+// to the current time.
+func Run(ctx context.Context, hooks []config.Hook, payload Payload, logger *logging.AppLogger) error {
+	if payload.Time.IsZero() {
+		payload.Time = time.Now().UTC()
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s payload: %w", payload.Event, err)
+	}
+	body = append(body, '\n')
+
+	var errs []error
+	for _, hook := range hooks {
+		if hook.Event != payload.Event {
+			continue
+		}
+		hookCtx, cancel := context.WithTimeout(ctx, Timeout)
+		if hook.Command != "" {
+			err = runCommand(hookCtx, hook.Command, payload.Event, body, logger)
+		} else {
+			err = postWebhook(hookCtx, hook.URL, body)
+		}
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s hook %s: %w", payload.Event, hookName(hook), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Notify runs the hooks configured for payload.Event like Run, logging their
+// failures instead of returning them
+func Notify(ctx context.Context, hooks []config.Hook, payload Payload, logger *logging.AppLogger) {
+	if err := Run(ctx, hooks, payload, logger); err != nil && logger != nil {
+		logger.Warn("Hook failed", "event", payload.Event, "error", err)
+	}
+}
+
+// runCommand runs command through the shell with body on stdin
+func runCommand(ctx context.Context, command, event string, body []byte, logger *logging.AppLogger) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), "RULEM_EVENT="+event)
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", Timeout)
+	}
+	if err != nil {
+		if out := strings.TrimSpace(string(output)); out != "" {
+			return fmt.Errorf("%w: %s", err, truncate(out))
+		}
+		return err
+	}
+	if logger != nil && len(output) > 0 {
+		logger.Debug("Hook output", "event", event, "command", command, "output", truncate(string(output)))
+	}
+	return nil
+}
+
+// postWebhook posts body to rawURL as JSON
+func postWebhook(ctx context.Context, rawURL string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// Report the cause without the URL, see hookName
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// hookName identifies a hook in errors without revealing webhook secrets,
+// which are often part of the URL path
+func hookName(hook config.Hook) string {
+	if hook.Command != "" {
+		return fmt.Sprintf("'%s'", hook.Command)
+	}
+	if u, err := url.Parse(hook.URL); err == nil {
+		return "webhook " + u.Host
+	}
+	return "webhook"
+}
+
+func truncate(s string) string {
+	if len(s) > maxOutput {
+		return s[:maxOutput] + "..."
+	}
+	return s
+}
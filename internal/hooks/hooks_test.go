@@ -0,0 +1,140 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hooks   []config.Hook
+		wantErr string
+	}{
+		{name: "valid", hooks: []config.Hook{
+			{Event: EventPostSync, Command: "make docs"},
+			{Event: EventRuleCreated, URL: "https://hooks.example.com/rulem"},
+		}},
+		{name: "unknown event", hooks: []config.Hook{{Event: "pre-sync", Command: "true"}}, wantErr: "hook 1 has unknown event 'pre-sync'"},
+		{name: "neither command nor url", hooks: []config.Hook{{Event: EventPostDeploy}}, wantErr: "hook 1 must set either a command or a url"},
+		{name: "command and url", hooks: []config.Hook{{Event: EventPostDeploy, Command: "true", URL: "https://example.com"}}, wantErr: "hook 1 must set either a command or a url"},
+		{name: "invalid url", hooks: []config.Hook{{Event: EventPostSync, Command: "true"}, {Event: EventPostDeploy, URL: "ftp://example.com"}}, wantErr: "hook 2 has an invalid url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.hooks)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRun_Command(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	logger, _ := logging.NewTestLogger()
+	out := filepath.Join(t.TempDir(), "payload.json")
+	hooks := []config.Hook{
+		{Event: EventPostDeploy, Command: `cat > "` + out + `"; echo "$RULEM_EVENT" >> "` + out + `"`},
+		{Event: EventPostSync, Command: "exit 1"},
+	}
+
+	payload := Payload{Event: EventPostDeploy, Repository: "Rules", Rule: "go/style.md", Destination: ".github/instructions/style.md", Mode: "copy"}
+	if err := Run(context.Background(), hooks, payload, logger); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	body, event, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	if event != EventPostDeploy {
+		t.Errorf("RULEM_EVENT = %q, want %q", event, EventPostDeploy)
+	}
+	var got Payload
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("invalid payload %q: %v", body, err)
+	}
+	if got.Time.IsZero() {
+		t.Error("payload time should be set")
+	}
+	got.Time = time.Time{}
+	if got != payload {
+		t.Errorf("payload = %+v, want %+v", got, payload)
+	}
+}
+
+func TestRun_Failures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	logger, _ := logging.NewTestLogger()
+	ran := filepath.Join(t.TempDir(), "ran")
+	hooks := []config.Hook{
+		{Event: EventPreDeploy, Command: "echo 'rule is not approved' >&2; exit 3"},
+		{Event: EventPreDeploy, Command: "touch " + ran},
+	}
+
+	err := Run(context.Background(), hooks, Payload{Event: EventPreDeploy}, logger)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3: rule is not approved") {
+		t.Errorf("Run() = %v, want the failing hook's output", err)
+	}
+	if _, err := os.Stat(ran); err != nil {
+		t.Error("later hooks should run after a failure")
+	}
+}
+
+func TestRun_Webhook(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	var got Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		if r.URL.Path == "/fail/secret" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	hooks := []config.Hook{{Event: EventRuleCreated, URL: server.URL + "/ok"}}
+	if err := Run(context.Background(), hooks, Payload{Event: EventRuleCreated, Rule: "new.md"}, logger); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got.Event != EventRuleCreated || got.Rule != "new.md" {
+		t.Errorf("webhook received %+v", got)
+	}
+
+	hooks = []config.Hook{{Event: EventRuleCreated, URL: server.URL + "/fail/secret"}}
+	err := Run(context.Background(), hooks, Payload{Event: EventRuleCreated}, logger)
+	if err == nil || !strings.Contains(err.Error(), "500 Internal Server Error") {
+		t.Errorf("Run() = %v, want the response status", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error should not reveal the webhook path: %v", err)
+	}
+}
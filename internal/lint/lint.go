@@ -0,0 +1,234 @@
+// Package lint checks the rule files of the central repositories before they
+// are served or deployed.
+//
+// Where `rulem verify` checks the rules deployed in one project, `rulem lint`
+// checks every rule of the configured repositories, so it can run in the CI of
+// a rule repository:
+//
+//	frontmatter          The frontmatter cannot be parsed or breaks the schema
+//	                     of the frontmatter package or the repository's rulem.yaml
+//	missing-description  The rule has no description for assistants to pick it by
+//	invalid-tags         A tag is empty, repeated or not in the repository's tag list
+//	unknown-key          The frontmatter sets a key rulem does not know
+//	too-long             The rule has more lines than Options.MaxLines
+//	duplicate-name       Two rules would be served under the same tool name
+//
+// Findings are plain values with JSON tags, so the command can report them as
+// text or as JSON.
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"rulem/internal/frontmatter"
+	"rulem/internal/mcp"
+)
+
+// DefaultMaxLines is the number of lines a rule may have before it is reported
+// as too long: longer rules take much of an assistant's context and are
+// usually better split by topic
+const DefaultMaxLines = 500
+
+// Check names a kind of problem found by Lint
+type Check string
+
+const (
+	CheckFrontmatter        Check = "frontmatter"
+	CheckMissingDescription Check = "missing-description"
+	CheckInvalidTags        Check = "invalid-tags"
+	CheckUnknownKey         Check = "unknown-key"
+	CheckTooLong            Check = "too-long"
+	CheckDuplicateName      Check = "duplicate-name"
+)
+
+// Rule is a rule file to lint
+type Rule struct {
+	Repository   string // Name of the repository, for reports
+	RepositoryID string
+	Path         string // Relative to the repository root, with forward slashes
+	Content      []byte
+}
+
+// Options configures Lint
+type Options struct {
+	MaxLines int // Lines a rule may have; 0 uses DefaultMaxLines
+}
+
+// Finding is a problem with a rule file
+type Finding struct {
+	Repository string `json:"repository"`
+	Path       string `json:"path"`
+	Line       int    `json:"line,omitempty"` // 1-based, 0 when the finding is about the whole file
+	Check      Check  `json:"check"`
+	Message    string `json:"message"`
+}
+
+// String formats the finding as "path:line: check: message"
+func (f Finding) String() string {
+	location := f.Path
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", f.Path, f.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, f.Check, f.Message)
+}
+
+// Lint checks rules with the validation the MCP server applies to rule files,
+// plus checks for problems the server tolerates. Findings are returned in rule
+// order, each rule's ordered as the checks are listed in the package doc.
+func Lint(rules []Rule, processor *mcp.RuleFileProcessor, opts Options) []Finding {
+	if opts.MaxLines <= 0 {
+		opts.MaxLines = DefaultMaxLines
+	}
+
+	duplicates := duplicateNames(rules, processor)
+	var findings []Finding
+	for i, rule := range rules {
+		findings = append(findings, lintRule(rule, processor, opts)...)
+		findings = append(findings, duplicates[i]...)
+	}
+	return findings
+}
+
+// lintRule runs every check but duplicate-name on one rule
+func lintRule(rule Rule, processor *mcp.RuleFileProcessor, opts Options) []Finding {
+	var findings []Finding
+	report := func(line int, check Check, format string, args ...any) {
+		findings = append(findings, Finding{
+			Repository: rule.Repository,
+			Path:       rule.Path,
+			Line:       line,
+			Check:      check,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	line := 1
+	if start, _, ok := processor.FrontmatterLines(rule.Content); ok {
+		line = start + 1
+	}
+	fileName := path.Base(rule.Path)
+
+	matter, err := processor.Frontmatter(rule.Content, fileName)
+	if err != nil {
+		var errs frontmatter.Errors
+		if !errors.As(err, &errs) {
+			report(line, CheckFrontmatter, "%v", err)
+		}
+		for _, fieldErr := range errs {
+			report(line, CheckFrontmatter, "%s", fieldErr.Message)
+		}
+	} else {
+		schemaFindings := len(findings)
+		var errs frontmatter.Errors
+		if errors.As(matter.Validate(), &errs) {
+			for _, fieldErr := range errs {
+				switch {
+				case fieldErr.Key == "description" && strings.TrimSpace(matter.Description) == "":
+					report(line, CheckMissingDescription, "rule has no description; assistants pick rules by it")
+				case fieldErr.Key == "tags":
+					report(line, CheckInvalidTags, "%s", fieldErr.Message)
+				default:
+					report(line, CheckFrontmatter, "%s", fieldErr.Message)
+				}
+			}
+		}
+
+		manifest := processor.Manifest(rule.RepositoryID)
+		seen := make(map[string]bool, len(matter.Tags))
+		for _, tag := range matter.Tags {
+			key := strings.ToLower(strings.TrimSpace(tag))
+			switch {
+			case key == "":
+				// Reported by Validate
+			case seen[key]:
+				report(line, CheckInvalidTags, "tag '%s' is repeated", tag)
+			case manifest != nil && !manifest.HasTag(tag):
+				report(line, CheckInvalidTags, "tag '%s' is not in the repository's tag list", tag)
+			}
+			seen[key] = true
+		}
+
+		// The server stops at the first problem, so only ask it about rules
+		// whose metadata passed, for problems such as the content policy or
+		// the repository schema
+		if len(findings) == schemaFindings {
+			if err := processor.ValidateRuleContent(rule.Content, fileName, rule.RepositoryID); err != nil {
+				report(line, CheckFrontmatter, "%v", err)
+			}
+		}
+
+		if fields, err := processor.FrontmatterFields(rule.Content, fileName); err == nil {
+			for _, field := range fields {
+				if _, known := frontmatter.Keys[field]; !known {
+					report(line, CheckUnknownKey, "unknown frontmatter key '%s'", field)
+				}
+			}
+		}
+	}
+
+	if lines := countLines(rule.Content); lines > opts.MaxLines {
+		report(0, CheckTooLong, "rule has %d lines, more than %d; consider splitting it", lines, opts.MaxLines)
+	}
+	return findings
+}
+
+// duplicateNames reports rules that share a tool name, by index in rules. The
+// server serves the first under the name and the others with a numeric suffix,
+// which assistants cannot tell apart.
+func duplicateNames(rules []Rule, processor *mcp.RuleFileProcessor) map[int][]Finding {
+	byName := make(map[string][]int)
+	for i, rule := range rules {
+		ruleFile := &mcp.RuleFile{FileName: path.Base(rule.Path), RepositoryID: rule.RepositoryID}
+		if matter, err := processor.Frontmatter(rule.Content, ruleFile.FileName); err == nil {
+			ruleFile.Name = matter.Name
+		}
+		name := processor.BaseToolName(ruleFile)
+		byName[name] = append(byName[name], i)
+	}
+
+	findings := make(map[int][]Finding)
+	for name, indexes := range byName {
+		if len(indexes) < 2 {
+			continue
+		}
+		for _, i := range indexes {
+			var others []string
+			for _, j := range indexes {
+				if j != i {
+					others = append(others, location(rules[i], rules[j]))
+				}
+			}
+			sort.Strings(others)
+			findings[i] = append(findings[i], Finding{
+				Repository: rules[i].Repository,
+				Path:       rules[i].Path,
+				Check:      CheckDuplicateName,
+				Message:    fmt.Sprintf("tool name '%s' is also used by %s; set a distinct name in the frontmatter", name, strings.Join(others, ", ")),
+			})
+		}
+	}
+	return findings
+}
+
+// location names other for a finding about rule, with its repository when
+// they are in different ones
+func location(rule, other Rule) string {
+	if other.RepositoryID == rule.RepositoryID {
+		return other.Path
+	}
+	return other.Repository + ":" + other.Path
+}
+
+// countLines counts the lines of content, including a last one without a newline
+func countLines(content []byte) int {
+	lines := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		lines++
+	}
+	return lines
+}
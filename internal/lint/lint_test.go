@@ -0,0 +1,83 @@
+package lint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/mcp"
+	"rulem/internal/repository"
+)
+
+// newProcessor prepares a local repository with a rulem.yaml and returns a
+// processor for it
+func newProcessor(t *testing.T, manifest string) *mcp.RuleFileProcessor {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "rulem.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("write rulem.yaml: %v", err)
+	}
+
+	logger, _ := logging.NewTestLogger()
+	entries := []repository.RepositoryEntry{
+		{ID: "rules-1", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: dir},
+	}
+	prepared, err := repository.PrepareAllRepositories(context.Background(), entries, logger)
+	if err != nil {
+		t.Fatalf("PrepareAllRepositories: %v", err)
+	}
+	processor, err := mcp.NewRuleFileProcessorForRepositories(&config.Config{Repositories: entries}, prepared, logger)
+	if err != nil {
+		t.Fatalf("NewRuleFileProcessorForRepositories: %v", err)
+	}
+	return processor
+}
+
+func TestLint(t *testing.T) {
+	processor := newProcessor(t, "tags: [go, style]\n")
+	rule := func(path, content string) Rule {
+		return Rule{Repository: "Rules", RepositoryID: "rules-1", Path: path, Content: []byte(content)}
+	}
+	rules := []Rule{
+		rule("valid.md", "---\ndescription: Valid\ntags: [go]\n---\n"),
+		rule("go/style.md", "---\ndescription: Go style\ntags: [go, Go]\n---\n"),
+		rule("style.md", "---\ndescription: Style\ntags: [python]\ncolour: blue\n---\n"),
+		rule("empty.md", "# Empty\n"),
+		rule("version.md", "---\ndescription: Versioned\nversion: 1.2\n---\n"),
+		rule("long.md", "---\ndescription: Long\n---\na\nb\nc\n"),
+	}
+
+	var got []string
+	for _, finding := range Lint(rules, processor, Options{MaxLines: 5}) {
+		got = append(got, finding.String())
+	}
+	want := []string{
+		"go/style.md:1: invalid-tags: tag 'Go' is repeated",
+		"go/style.md: duplicate-name: tool name 'style' is also used by style.md; set a distinct name in the frontmatter",
+		"style.md:1: invalid-tags: tag 'python' is not in the repository's tag list",
+		"style.md:1: unknown-key: unknown frontmatter key 'colour'",
+		"style.md: duplicate-name: tool name 'style' is also used by go/style.md; set a distinct name in the frontmatter",
+		"empty.md:1: missing-description: rule has no description; assistants pick rules by it",
+		"version.md:1: frontmatter: version must be text, got the number 1.2 (quote it)",
+		"long.md: too-long: rule has 6 lines, more than 5; consider splitting it",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestLint_DefaultMaxLines(t *testing.T) {
+	processor := newProcessor(t, "tags: [go]\n")
+	content := "---\ndescription: Short\n---\n"
+	for range DefaultMaxLines {
+		content += "line\n"
+	}
+	findings := Lint([]Rule{{RepositoryID: "rules-1", Path: "short.md", Content: []byte(content)}}, processor, Options{})
+	if len(findings) != 1 || findings[0].Check != CheckTooLong {
+		t.Errorf("findings = %v, want the rule reported as too long", findings)
+	}
+}
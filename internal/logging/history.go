@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recent history
+//
+// Production runs only print warnings and errors, so by the time a user hits
+// an error the context leading up to it is gone. Every AppLogger therefore
+// also keeps the last entries it was given at info level and above (debug
+// too in debug mode), and the last user actions and state transitions, in
+// memory for diagnostic bundles. Entries are redacted as they are recorded,
+// so secrets never sit in the history.
+
+// History sizes
+const (
+	maxRecentEntries    = 200
+	maxRecentOperations = 30
+)
+
+// history is a bounded, concurrency-safe record of recent log entries and
+// operations
+type history struct {
+	mu         sync.Mutex
+	entries    []string
+	operations []string
+}
+
+func newHistory() *history {
+	return &history{}
+}
+
+// add appends line to list, dropping the oldest lines beyond max
+func (h *history) add(list *[]string, max int, line string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*list = append(*list, line)
+	if over := len(*list) - max; over > 0 {
+		*list = append((*list)[:0], (*list)[over:]...)
+	}
+}
+
+// snapshot returns a copy of list
+func (h *history) snapshot(list *[]string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), (*list)...)
+}
+
+// formatEntry formats a log entry for the history, e.g.
+// "15:04:05 WARN Sync failed repository=team-rules error=timeout"
+func formatEntry(level, msg string, keyvals []any) string {
+	var b strings.Builder
+	b.WriteString(time.Now().Format(time.TimeOnly))
+	b.WriteString(" ")
+	b.WriteString(level)
+	b.WriteString(" ")
+	b.WriteString(Redact(msg))
+	keyvals = RedactKeyvals(keyvals)
+	for i := 0; i < len(keyvals); i += 2 {
+		var value any = "(missing)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fmt.Fprintf(&b, " %v=%v", keyvals[i], value)
+	}
+	return b.String()
+}
+
+// record adds a log entry to the history, if the logger keeps one
+func (al *AppLogger) record(level, msg string, keyvals []any) {
+	if al.history == nil {
+		return
+	}
+	al.history.add(&al.history.entries, maxRecentEntries, formatEntry(level, msg, keyvals))
+}
+
+// recordOperation adds a user action or state transition to the history
+func (al *AppLogger) recordOperation(msg string, keyvals ...any) {
+	if al.history == nil {
+		return
+	}
+	al.history.add(&al.history.operations, maxRecentOperations, formatEntry("OP", msg, keyvals))
+}
+
+// RecentEntries returns the latest log entries, oldest first and redacted
+func (al *AppLogger) RecentEntries() []string {
+	if al.history == nil {
+		return nil
+	}
+	return al.history.snapshot(&al.history.entries)
+}
+
+// RecentOperations returns the latest user actions and state transitions,
+// oldest first and redacted
+func (al *AppLogger) RecentOperations() []string {
+	if al.history == nil {
+		return nil
+	}
+	return al.history.snapshot(&al.history.operations)
+}
@@ -42,8 +42,9 @@ import (
 )
 
 type AppLogger struct {
-	logger *log.Logger
-	debug  bool
+	logger  *log.Logger
+	debug   bool
+	history *history // Recent entries for diagnostic bundles, see RecentEntries
 }
 
 var (
@@ -137,26 +138,31 @@ func newAppLoggerWithDebugMode(debug bool) *AppLogger {
 	}
 
 	return &AppLogger{
-		logger: logger,
-		debug:  debug,
+		logger:  logger,
+		debug:   debug,
+		history: newHistory(),
 	}
 }
 
 // Log application events
 func (al *AppLogger) Info(msg string, keyvals ...any) {
+	al.record("INFO", msg, keyvals)
 	al.logger.Info(msg, keyvals...)
 }
 
 func (al *AppLogger) Warn(msg string, keyvals ...any) {
+	al.record("WARN", msg, keyvals)
 	al.logger.Warn(msg, keyvals...)
 }
 
 func (al *AppLogger) Error(msg string, keyvals ...any) {
+	al.record("ERROR", msg, keyvals)
 	al.logger.Error(msg, keyvals...)
 }
 
 func (al *AppLogger) Debug(msg string, keyvals ...any) {
 	if al.debug {
+		al.record("DEBUG", msg, keyvals)
 		al.logger.Debug(msg, keyvals...)
 	}
 }
@@ -191,8 +197,10 @@ func (al *AppLogger) LogPerformance(operation string, start time.Time) {
 	}
 }
 
-// Log state transitions for debugging
+// Log state transitions for debugging; they are kept for diagnostic bundles
+// in every mode
 func (al *AppLogger) LogStateTransition(component, from, to string) {
+	al.recordOperation("State transition", "component", component, "from", from, "to", to)
 	if al.debug {
 		al.logger.Debug("State transition",
 			"component", component,
@@ -202,8 +210,10 @@ func (al *AppLogger) LogStateTransition(component, from, to string) {
 	}
 }
 
-// Log user actions for debugging
+// Log user actions for debugging; they are kept for diagnostic bundles in
+// every mode
 func (al *AppLogger) LogUserAction(action, context string) {
+	al.recordOperation("User action", "action", action, "context", context)
 	if al.debug {
 		al.logger.Debug("User action",
 			"action", action,
@@ -225,7 +235,8 @@ func NewTestLogger() (*AppLogger, *bytes.Buffer) {
 	logger.SetLevel(log.DebugLevel)
 
 	return &AppLogger{
-		logger: logger,
-		debug:  true,
+		logger:  logger,
+		debug:   true,
+		history: newHistory(),
 	}, &buf
 }
@@ -0,0 +1,357 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rulem/internal/frontmatter"
+	"rulem/internal/mcp"
+	"rulem/internal/repository"
+)
+
+// markdownLink matches inline links and images, capturing the target. Titles
+// after the target ("path.md \"title\"") end the capture at the space.
+var markdownLink = regexp.MustCompile(`!?\[[^\]]*\]\(([^)\s]*)`)
+
+// link is a markdown link target on a line, as byte offsets
+type link struct {
+	target     string
+	start, end int
+}
+
+// documentContext describes where a document sits in the index
+type documentContext struct {
+	path    string
+	repo    repository.PreparedRepository
+	root    string // LocalPath or OverlayPath containing the document
+	lines   []string
+	content []byte
+
+	frontmatterStart, frontmatterEnd int
+	hasFrontmatter                   bool
+}
+
+// newDocumentContext returns the context of a markdown document inside a
+// repository, or false for any other document
+func (s *Server) newDocumentContext(path, text string) (*documentContext, bool) {
+	if !strings.EqualFold(filepath.Ext(path), ".md") {
+		return nil, false
+	}
+	repo, root, ok := s.index.repositoryFor(path)
+	if !ok {
+		return nil, false
+	}
+
+	doc := &documentContext{path: path, repo: repo, root: root, lines: splitLines(text), content: []byte(text)}
+	doc.frontmatterStart, doc.frontmatterEnd, doc.hasFrontmatter = s.index.processor.FrontmatterLines(doc.content)
+	return doc, true
+}
+
+// inFrontmatter reports whether line is between the frontmatter delimiters
+func (doc *documentContext) inFrontmatter(line int) bool {
+	return doc.hasFrontmatter && line > doc.frontmatterStart && line < doc.frontmatterEnd
+}
+
+// bodyStart returns the first line after the frontmatter
+func (doc *documentContext) bodyStart() int {
+	if doc.hasFrontmatter {
+		return doc.frontmatterEnd + 1
+	}
+	return 0
+}
+
+// diagnose validates a document the way the MCP server would register it
+func (s *Server) diagnose(path, text string) []diagnostic {
+	diagnostics := []diagnostic{}
+	doc, ok := s.newDocumentContext(path, text)
+	if !ok {
+		return diagnostics
+	}
+	processor := s.index.processor
+
+	if !doc.hasFrontmatter {
+		diagnostics = append(diagnostics, diagnostic{
+			Range:    lineRange(doc.lines, 0, 0, len(doc.lines[0])),
+			Severity: severityInformation,
+			Source:   "rulem",
+			Message:  "No frontmatter: this file is not registered as an MCP tool",
+		})
+	} else {
+		start := doc.frontmatterStart
+		if err := processor.ValidateRuleContent(doc.content, filepath.Base(path), doc.repo.ID()); err != nil {
+			diagnostics = append(diagnostics, diagnostic{
+				Range:    lineRange(doc.lines, start, 0, len(doc.lines[start])),
+				Severity: severityError,
+				Source:   "rulem",
+				Message:  "Not registered as an MCP tool: " + err.Error(),
+			})
+		}
+
+		// Unparseable frontmatter is already reported above
+		if fields, err := processor.FrontmatterFields(doc.content, filepath.Base(doc.path)); err == nil {
+			for _, field := range fields {
+				if _, known := frontmatter.Keys[field]; known {
+					continue
+				}
+				line, col := doc.keyPosition(field)
+				diagnostics = append(diagnostics, diagnostic{
+					Range:    lineRange(doc.lines, line, col, col+len(field)),
+					Severity: severityWarning,
+					Source:   "rulem",
+					Message:  fmt.Sprintf("Unknown frontmatter key '%s'", field),
+				})
+			}
+		}
+	}
+
+	links := doc.bodyLinks()
+	for line := doc.bodyStart(); line < len(doc.lines); line++ {
+		for _, l := range links[line] {
+			if message := doc.checkLink(l.target); message != "" {
+				diagnostics = append(diagnostics, diagnostic{
+					Range:    lineRange(doc.lines, line, l.start, l.end),
+					Severity: severityWarning,
+					Source:   "rulem",
+					Message:  message,
+				})
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// keyPosition finds the line and byte column of a top-level frontmatter key,
+// falling back to the opening delimiter
+func (doc *documentContext) keyPosition(key string) (int, int) {
+	for line := doc.frontmatterStart + 1; line < doc.frontmatterEnd; line++ {
+		if col, ok := keyAt(doc.lines[line], key); ok {
+			return line, col
+		}
+	}
+	return doc.frontmatterStart, 0
+}
+
+// keyAt reports the column of key when text sets it: `key:` (YAML),
+// `key =` (TOML) or `"key":` (JSON)
+func keyAt(text, key string) (int, bool) {
+	trimmed := strings.TrimLeft(text, " \t")
+	col := len(text) - len(trimmed)
+	if strings.HasPrefix(trimmed, `"`+key+`"`) {
+		return col + 1, true
+	}
+	rest, ok := strings.CutPrefix(trimmed, key)
+	if !ok {
+		return 0, false
+	}
+	rest = strings.TrimLeft(rest, " \t")
+	return col, strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, "=")
+}
+
+// currentKey returns the frontmatter key a line belongs to: the key it sets, or
+// for YAML list items the key of the list they are part of
+func (doc *documentContext) currentKey(line int) string {
+	for ; line > doc.frontmatterStart; line-- {
+		text := doc.lines[line]
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "-") {
+			continue
+		}
+		for key := range frontmatter.Keys {
+			if _, ok := keyAt(text, key); ok {
+				return key
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// bodyLinks returns the link targets on each body line outside fenced code blocks
+func (doc *documentContext) bodyLinks() map[int][]link {
+	links := make(map[int][]link)
+	inFence := false
+	for line := doc.bodyStart(); line < len(doc.lines); line++ {
+		text := doc.lines[line]
+		if strings.HasPrefix(strings.TrimSpace(text), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for _, m := range markdownLink.FindAllStringSubmatchIndex(text, -1) {
+			links[line] = append(links[line], link{target: text[m[2]:m[3]], start: m[2], end: m[3]})
+		}
+	}
+	return links
+}
+
+// resolveLink returns the absolute path a link target points at, or "" for
+// external links and in-page anchors
+func (doc *documentContext) resolveLink(target string) string {
+	if target == "" || strings.HasPrefix(target, "#") || strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+		return ""
+	}
+	target, _, _ = strings.Cut(target, "#")
+	if target == "" {
+		return ""
+	}
+	// Root-relative links resolve against the repository
+	if strings.HasPrefix(target, "/") {
+		return filepath.Join(doc.root, filepath.FromSlash(target))
+	}
+	return filepath.Join(filepath.Dir(doc.path), filepath.FromSlash(target))
+}
+
+// checkLink returns a problem with a local link target, or ""
+func (doc *documentContext) checkLink(target string) string {
+	resolved := doc.resolveLink(target)
+	if resolved == "" {
+		return ""
+	}
+	if !isWithin(resolved, doc.root) {
+		return fmt.Sprintf("Link '%s' points outside the repository", target)
+	}
+	if _, err := os.Stat(resolved); err != nil {
+		return fmt.Sprintf("Link '%s' points to a file that does not exist", target)
+	}
+	return ""
+}
+
+// complete returns completions at a position: frontmatter keys and tags inside
+// the frontmatter, and rule file paths inside markdown link targets
+func (s *Server) complete(params textDocumentPositionParams) []completionItem {
+	items := []completionItem{}
+	text, path, ok := s.document(params.TextDocument.URI)
+	if !ok {
+		return items
+	}
+	doc, ok := s.newDocumentContext(path, text)
+	line := params.Position.Line
+	if !ok || line >= len(doc.lines) {
+		return items
+	}
+	prefix := doc.lines[line][:byteOffset(doc.lines[line], params.Position.Character)]
+
+	if doc.inFrontmatter(line) {
+		if doc.currentKey(line) == "tags" {
+			return s.completeTags(doc)
+		}
+		if !strings.ContainsAny(prefix, ":=") {
+			return completeKeys(doc, s.index.processor)
+		}
+		return items
+	}
+
+	// Inside the target of a link: "[text](partial"
+	open := strings.LastIndex(prefix, "](")
+	if open == -1 || strings.Contains(prefix[open:], ")") || line < doc.bodyStart() {
+		return items
+	}
+	return s.completeLinkTargets(doc)
+}
+
+// completeKeys offers the frontmatter keys the document does not set yet
+func completeKeys(doc *documentContext, processor *mcp.RuleFileProcessor) []completionItem {
+	set := make(map[string]bool)
+	// Half-typed frontmatter may not parse; offer every key then
+	if fields, err := processor.FrontmatterFields(doc.content, filepath.Base(doc.path)); err == nil {
+		for _, field := range fields {
+			set[field] = true
+		}
+	}
+
+	items := []completionItem{}
+	for key, description := range frontmatter.Keys {
+		if !set[key] {
+			items = append(items, completionItem{Label: key, Kind: completionKindProperty, Documentation: description})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items
+}
+
+// completeTags offers the tags from the repository's rulem.yaml
+func (s *Server) completeTags(doc *documentContext) []completionItem {
+	items := []completionItem{}
+	manifest := s.index.processor.Manifest(doc.repo.ID())
+	if manifest == nil {
+		return items
+	}
+	for _, tag := range manifest.Tags {
+		items = append(items, completionItem{Label: tag, Kind: completionKindValue, Detail: "Tag from rulem.yaml"})
+	}
+	return items
+}
+
+// completeLinkTargets offers the other markdown files in the document's
+// repository, relative to the document, with the rule description when the
+// file is registered as a tool
+func (s *Server) completeLinkTargets(doc *documentContext) []completionItem {
+	items := []completionItem{}
+	dir := filepath.Dir(doc.path)
+	for _, file := range s.index.files {
+		if file.RepositoryID != doc.repo.ID() || file.Path == doc.path {
+			continue
+		}
+		rel, err := filepath.Rel(dir, file.Path)
+		if err != nil {
+			continue
+		}
+		item := completionItem{Label: filepath.ToSlash(rel), Kind: completionKindFile}
+		if tool, ok := s.index.rules[file.Path]; ok {
+			item.Detail = tool.Name
+			item.Documentation = tool.RuleFile.Description
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items
+}
+
+// hover documents the frontmatter key or link target under the cursor
+func (s *Server) hover(params textDocumentPositionParams) *hover {
+	text, path, ok := s.document(params.TextDocument.URI)
+	if !ok {
+		return nil
+	}
+	doc, ok := s.newDocumentContext(path, text)
+	line := params.Position.Line
+	if !ok || line >= len(doc.lines) {
+		return nil
+	}
+	cursor := byteOffset(doc.lines[line], params.Position.Character)
+
+	if doc.inFrontmatter(line) {
+		for key, description := range frontmatter.Keys {
+			if col, ok := keyAt(doc.lines[line], key); ok && cursor >= col && cursor <= col+len(key) {
+				r := lineRange(doc.lines, line, col, col+len(key))
+				return &hover{Contents: markupContent{Kind: "markdown", Value: fmt.Sprintf("**%s**\n\n%s", key, description)}, Range: &r}
+			}
+		}
+		return nil
+	}
+
+	for _, l := range doc.bodyLinks()[line] {
+		if cursor < l.start || cursor > l.end {
+			continue
+		}
+		resolved := doc.resolveLink(l.target)
+		tool, ok := s.index.rules[resolved]
+		if !ok {
+			return nil
+		}
+		r := lineRange(doc.lines, line, l.start, l.end)
+		value := fmt.Sprintf("**%s**\n\n%s", tool.Name, tool.RuleFile.Description)
+		if tool.RuleFile.ApplyTo != "" {
+			value += fmt.Sprintf("\n\n%s %s", mcp.ApplyToFormat, tool.RuleFile.ApplyTo)
+		}
+		return &hover{Contents: markupContent{Kind: "markdown", Value: value}, Range: &r}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// This file holds the small subset of the Language Server Protocol rulem
+// implements: JSON-RPC 2.0 messages framed by a Content-Length header, and the
+// request and result shapes for hover, completion and diagnostics.
+
+// JSON-RPC error codes used by the server
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInvalidRequest = -32600
+)
+
+// maxMessageSize bounds a single message; rule files are capped at 5 MB
+const maxMessageSize = 16 * 1024 * 1024
+
+// LSP enumerations
+const (
+	textDocumentSyncFull = 1
+
+	completionKindFile     = 17
+	completionKindProperty = 10
+	completionKindValue    = 12
+
+	severityError       = 1
+	severityWarning     = 2
+	severityInformation = 3
+)
+
+// request is an incoming request or notification. Notifications have no ID.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response answers a request. Result is always sent on success, as null when
+// the handler has nothing to return.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// notification is a message sent by the server that expects no answer
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"` // UTF-16 code units, as the protocol requires
+}
+
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentItem struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type didCloseParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     position               `json:"position"`
+}
+
+type initializeResult struct {
+	Capabilities serverCapabilities `json:"capabilities"`
+	ServerInfo   serverInfo         `json:"serverInfo"`
+}
+
+type serverCapabilities struct {
+	TextDocumentSync   int               `json:"textDocumentSync"`
+	HoverProvider      bool              `json:"hoverProvider"`
+	CompletionProvider completionOptions `json:"completionProvider"`
+}
+
+type completionOptions struct {
+	TriggerCharacters []string `json:"triggerCharacters"`
+}
+
+type serverInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type completionItem struct {
+	Label         string `json:"label"`
+	Kind          int    `json:"kind"`
+	Detail        string `json:"detail,omitempty"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    *textRange    `json:"range,omitempty"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type diagnostic struct {
+	Range    textRange `json:"range"`
+	Severity int       `json:"severity"`
+	Source   string    `json:"source"`
+	Message  string    `json:"message"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+// readMessage reads one Content-Length framed message body
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header %q", header.Get("Content-Length"))
+	}
+	if length > maxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the %d byte limit", length, maxMessageSize)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return body, nil
+}
+
+// writeMessage writes v as one Content-Length framed message
+func writeMessage(w io.Writer, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// uriToPath converts a file:// URI to a local path. Other schemes are rejected.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid document URI %q: %w", uri, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported document URI scheme %q", u.Scheme)
+	}
+
+	path := u.Path
+	// file:///C:/rules/go.md carries the drive letter after the leading slash
+	if runtime.GOOS == "windows" && len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.Clean(filepath.FromSlash(path)), nil
+}
+
+// byteOffset converts a UTF-16 character offset within line to a byte offset,
+// clamped to the line length.
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += runeUnits(r)
+	}
+	return len(line)
+}
+
+// utf16Length returns the length of s in UTF-16 code units
+func utf16Length(s string) int {
+	units := 0
+	for _, r := range s {
+		units += runeUnits(r)
+	}
+	return units
+}
+
+// runeUnits returns the UTF-16 length of r; invalid bytes count as one unit
+func runeUnits(r rune) int {
+	if n := utf16.RuneLen(r); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// lineRange returns the range of line from byte offset start to end
+func lineRange(lines []string, line, start, end int) textRange {
+	text := lines[line]
+	start, end = min(start, len(text)), min(end, len(text))
+	return textRange{
+		Start: position{Line: line, Character: utf16Length(text[:start])},
+		End:   position{Line: line, Character: utf16Length(text[:end])},
+	}
+}
+
+// splitLines splits text into lines without their line endings
+func splitLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
@@ -0,0 +1,74 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadWriteMessage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeMessage(&buf, map[string]string{"method": "initialized"}); err != nil {
+		t.Fatalf("writeMessage: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "Content-Length: 24\r\n\r\n") {
+		t.Errorf("unexpected framing %q", buf.String())
+	}
+
+	body, err := readMessage(bufio.NewReader(&buf))
+	if err != nil || string(body) != `{"method":"initialized"}` {
+		t.Errorf("readMessage() = %q, %v", body, err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "missing length", input: "Content-Type: x\r\n\r\n{}", wantErr: "invalid Content-Length"},
+		{name: "too large", input: "Content-Length: 999999999\r\n\r\n", wantErr: "exceeds"},
+		{name: "short body", input: "Content-Length: 10\r\n\r\n{}", wantErr: "failed to read message body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readMessage(bufio.NewReader(strings.NewReader(tt.input))); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("readMessage() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUTF16Offsets(t *testing.T) {
+	line := "a😀b"
+	tests := []struct {
+		character int
+		want      int
+	}{
+		{0, 0},
+		{1, 1},
+		{3, 5}, // the emoji is two UTF-16 units and four bytes
+		{4, 6},
+		{10, 6},
+	}
+	for _, tt := range tests {
+		if got := byteOffset(line, tt.character); got != tt.want {
+			t.Errorf("byteOffset(%d) = %d, want %d", tt.character, got, tt.want)
+		}
+	}
+	if got := utf16Length(line); got != 4 {
+		t.Errorf("utf16Length() = %d, want 4", got)
+	}
+}
+
+func TestURIToPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "my rules", "go.md")
+	got, err := uriToPath(fileURI(path))
+	if err != nil || got != path {
+		t.Errorf("uriToPath() = %q, %v, want %q", got, err, path)
+	}
+	if _, err := uriToPath("untitled:Untitled-1"); err == nil {
+		t.Error("expected an error for non-file URIs")
+	}
+}
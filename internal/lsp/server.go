@@ -0,0 +1,288 @@
+// Package lsp implements an experimental Language Server Protocol companion for
+// editing rule files in the configured repositories.
+//
+// The server speaks JSON-RPC over stdin/stdout like the MCP server and offers:
+//   - Diagnostics: frontmatter that would keep a rule from being registered as
+//     an MCP tool, unknown frontmatter keys, and links to missing files
+//   - Completion: frontmatter keys, tags from the repository's rulem.yaml, and
+//     paths of other rule files inside markdown links
+//   - Hover: documentation for frontmatter keys and the description of linked rules
+//
+// Validation reuses the MCP rule file processor, and the rule index is the same
+// repository scan the MCP server registers tools from, rebuilt whenever a
+// document is saved.
+//
+// Only full document sync is supported, and positions are converted between the
+// protocol's UTF-16 offsets and byte offsets per line.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/mcp"
+	"rulem/internal/repository"
+	"rulem/internal/version"
+)
+
+// Server is a language server for rule files. Each call to Serve handles one
+// client session and processes its messages sequentially.
+type Server struct {
+	config    *config.Config
+	prepared  []repository.PreparedRepository
+	logger    *logging.AppLogger
+	index     *ruleIndex
+	documents map[string]string // Open document text by URI
+	out       io.Writer
+	shutdown  bool // Set by the shutdown request; only exit is accepted afterwards
+}
+
+// ruleIndex is a snapshot of the rule files in every available repository
+type ruleIndex struct {
+	processor    *mcp.RuleFileProcessor
+	repositories []repository.PreparedRepository
+	files        []filemanager.FileItem
+	rules        map[string]*mcp.RuleFileTool // Registered rules by absolute path
+}
+
+// NewServer creates a language server for the prepared repositories
+func NewServer(cfg *config.Config, prepared []repository.PreparedRepository, logger *logging.AppLogger) *Server {
+	return &Server{
+		config:   cfg,
+		prepared: prepared,
+		logger:   logger,
+	}
+}
+
+// Serve reads requests from in and writes responses to out until the client
+// sends exit or closes the input. Exiting without a shutdown request first is
+// reported as an error, as the protocol requires.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	s.shutdown = false
+	s.documents = make(map[string]string)
+	if err := s.reindex(); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		body, err := readMessage(reader)
+		if errors.Is(err, io.EOF) {
+			s.logger.Info("LSP client closed the connection")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read LSP message: %w", err)
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.replyError(nil, codeParseError, fmt.Sprintf("invalid message: %v", err))
+			continue
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("LSP client exited without shutting down")
+			}
+			s.logger.Info("LSP server stopped")
+			return nil
+		}
+
+		s.handle(req)
+	}
+}
+
+// handle dispatches a request or notification
+func (s *Server) handle(req request) {
+	s.logger.Debug("Processing LSP message", "method", req.Method)
+	isRequest := len(req.ID) > 0
+
+	if s.shutdown {
+		if isRequest {
+			s.replyError(req.ID, codeInvalidRequest, "server is shutting down")
+		}
+		return
+	}
+
+	var result any
+	var err error
+	switch req.Method {
+	case "initialize":
+		result = initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:   textDocumentSyncFull,
+				HoverProvider:      true,
+				CompletionProvider: completionOptions{TriggerCharacters: []string{"(", "/", "-", " "}},
+			},
+			ServerInfo: serverInfo{Name: "rulem", Version: version.Current()},
+		}
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			s.documents[params.TextDocument.URI] = params.TextDocument.Text
+			s.publishDiagnostics(params.TextDocument.URI)
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err = json.Unmarshal(req.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			// Full sync: the last change holds the whole document
+			s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+			s.publishDiagnostics(params.TextDocument.URI)
+		}
+	case "textDocument/didSave":
+		// Saving can add or rename rules, so rebuild the index and revalidate
+		// links in every open document
+		if err := s.reindex(); err != nil {
+			s.logger.Error("Failed to rebuild rule index", "error", err)
+		}
+		for uri := range s.documents {
+			s.publishDiagnostics(uri)
+		}
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			delete(s.documents, params.TextDocument.URI)
+			s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []diagnostic{}})
+		}
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			result = s.complete(params)
+		}
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			if h := s.hover(params); h != nil {
+				result = h
+			}
+		}
+	case "initialized":
+	default:
+		if isRequest {
+			s.replyError(req.ID, codeMethodNotFound, fmt.Sprintf("method '%s' is not supported", req.Method))
+		}
+		return
+	}
+
+	if err != nil {
+		s.logger.Warn("Invalid LSP params", "method", req.Method, "error", err)
+		if isRequest {
+			s.replyError(req.ID, codeInvalidParams, fmt.Sprintf("invalid params: %v", err))
+		}
+		return
+	}
+	if isRequest {
+		s.reply(req.ID, result)
+	}
+}
+
+// reindex rescans the repositories and rebuilds the rule registry with a fresh
+// processor, so renamed rules do not pick up duplicate-name suffixes
+func (s *Server) reindex() error {
+	processor, err := mcp.NewRuleFileProcessorForRepositories(s.config, s.prepared, s.logger)
+	if err != nil {
+		return err
+	}
+
+	available := repository.AvailableRepositories(s.prepared)
+	files, err := filemanager.ScanAllRepositories(available, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to scan repositories: %w", err)
+	}
+	tools, err := processor.ProcessRuleFiles(files)
+	if err != nil {
+		return fmt.Errorf("failed to process rule files: %w", err)
+	}
+
+	rules := make(map[string]*mcp.RuleFileTool, len(tools))
+	for _, tool := range tools {
+		rules[tool.RuleFile.FilePath] = tool
+	}
+
+	s.index = &ruleIndex{
+		processor:    processor,
+		repositories: available,
+		files:        files,
+		rules:        rules,
+	}
+	s.logger.Debug("Rule index rebuilt", "files", len(files), "rules", len(rules))
+	return nil
+}
+
+// document returns the text and path of an open document
+func (s *Server) document(uri string) (text, path string, ok bool) {
+	text, open := s.documents[uri]
+	if !open {
+		return "", "", false
+	}
+	path, err := uriToPath(uri)
+	if err != nil {
+		s.logger.Debug("Ignoring document", "uri", uri, "error", err)
+		return "", "", false
+	}
+	return text, path, true
+}
+
+// repositoryFor returns the repository containing path, including its overlay
+func (idx *ruleIndex) repositoryFor(path string) (repository.PreparedRepository, string, bool) {
+	for _, prep := range idx.repositories {
+		for _, root := range []string{prep.OverlayPath, prep.LocalPath} {
+			if root != "" && isWithin(path, root) {
+				return prep, root, true
+			}
+		}
+	}
+	return repository.PreparedRepository{}, "", false
+}
+
+// isWithin reports whether path is root or inside it
+func isWithin(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (s *Server) publishDiagnostics(uri string) {
+	diagnostics := []diagnostic{}
+	if text, path, ok := s.document(uri); ok {
+		diagnostics = s.diagnose(path, text)
+	}
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+func (s *Server) reply(id json.RawMessage, result any) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		s.replyError(id, codeInvalidRequest, fmt.Sprintf("failed to encode result: %v", err))
+		return
+	}
+	s.write(response{JSONRPC: "2.0", ID: id, Result: raw})
+}
+
+func (s *Server) replyError(id json.RawMessage, code int, message string) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	s.write(response{JSONRPC: "2.0", ID: id, Error: &responseError{Code: code, Message: message}})
+}
+
+func (s *Server) notify(method string, params any) {
+	s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) write(v any) {
+	if err := writeMessage(s.out, v); err != nil {
+		s.logger.Error("Failed to write LSP message", "error", err)
+	}
+}
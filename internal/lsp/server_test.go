@@ -0,0 +1,275 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+)
+
+// session drives a Server over in-memory pipes
+type session struct {
+	t      *testing.T
+	in     bytes.Buffer
+	nextID int
+}
+
+func (s *session) send(method string, params any) {
+	s.t.Helper()
+	s.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *session) request(method string, params any) int {
+	s.t.Helper()
+	s.nextID++
+	s.write(map[string]any{"jsonrpc": "2.0", "id": s.nextID, "method": method, "params": params})
+	return s.nextID
+}
+
+func (s *session) write(v any) {
+	s.t.Helper()
+	if err := writeMessage(&s.in, v); err != nil {
+		s.t.Fatalf("writeMessage: %v", err)
+	}
+}
+
+// serverMessage is any message the server sends
+type serverMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// run serves the queued messages and returns the responses by ID and the
+// diagnostics last published for each URI
+func (s *session) run(server *Server) (map[int]serverMessage, map[string][]diagnostic) {
+	s.t.Helper()
+	var out bytes.Buffer
+	if err := server.Serve(&s.in, &out); err != nil {
+		s.t.Fatalf("Serve: %v", err)
+	}
+
+	responses := make(map[int]serverMessage)
+	diagnostics := make(map[string][]diagnostic)
+	reader := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(reader)
+		if err != nil {
+			break
+		}
+		var msg serverMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.t.Fatalf("decode %s: %v", body, err)
+		}
+		if msg.ID != nil {
+			responses[*msg.ID] = msg
+			continue
+		}
+		var params publishDiagnosticsParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.t.Fatalf("decode diagnostics: %v", err)
+		}
+		diagnostics[params.URI] = params.Diagnostics
+	}
+	return responses, diagnostics
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// createRuleRepository prepares a local repository with a manifest and two rules
+func createRuleRepository(t *testing.T) (*Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"rulem.yaml":  "tags: [go, testing]\n",
+		"go/style.md": "---\ndescription: Go style guide\napplyTo: \"*.go\"\ntags: [go]\n---\n# Style\n",
+		"testing.md":  "---\ndescription: Testing conventions\n---\n# Testing\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "rules-1", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: dir},
+	}}
+	logger, _ := logging.NewTestLogger()
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, logger)
+	if err != nil {
+		t.Fatalf("PrepareAllRepositories: %v", err)
+	}
+	return NewServer(cfg, prepared, logger), dir
+}
+
+func TestServer_Session(t *testing.T) {
+	server, dir := createRuleRepository(t)
+	docPath := filepath.Join(dir, "review.md")
+	uri := fileURI(docPath)
+	text := strings.Join([]string{
+		"---",
+		"description: Review checklist",
+		"tags:",
+		"  - ",
+		"colour: blue",
+		"",
+		"---",
+		"See [style](go/style.md), [missing](gone.md) and [web](https://example.com).",
+		"Also [](",
+	}, "\n")
+
+	s := &session{t: t}
+	initID := s.request("initialize", map[string]any{})
+	s.send("initialized", map[string]any{})
+	s.send("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "languageId": "markdown", "version": 1, "text": text}})
+	keysID := s.request("textDocument/completion", map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": 5, "character": 0}})
+	tagsID := s.request("textDocument/completion", map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": 3, "character": 4}})
+	linksID := s.request("textDocument/completion", map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": 8, "character": 8}})
+	hoverLinkID := s.request("textDocument/hover", map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": 7, "character": 15}})
+	hoverKeyID := s.request("textDocument/hover", map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": 1, "character": 3}})
+	unknownID := s.request("workspace/symbol", map[string]any{})
+	shutdownID := s.request("shutdown", nil)
+	s.send("exit", nil)
+
+	responses, diagnostics := s.run(server)
+
+	var init initializeResult
+	if err := json.Unmarshal(responses[initID].Result, &init); err != nil || !init.Capabilities.HoverProvider {
+		t.Errorf("initialize result = %s, err %v", responses[initID].Result, err)
+	}
+
+	var messages []string
+	for _, d := range diagnostics[uri] {
+		messages = append(messages, d.Message)
+	}
+	got := strings.Join(messages, "\n")
+	for _, want := range []string{"Unknown frontmatter key 'colour'", "Link 'gone.md' points to a file that does not exist"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diagnostics %q missing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "style.md") || strings.Contains(got, "example.com") {
+		t.Errorf("valid and external links should not be reported: %q", got)
+	}
+
+	labels := func(id int) string {
+		t.Helper()
+		var items []completionItem
+		if err := json.Unmarshal(responses[id].Result, &items); err != nil {
+			t.Fatalf("decode completion %d: %v", id, err)
+		}
+		var out []string
+		for _, item := range items {
+			out = append(out, item.Label)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := labels(keysID); got != "applyTo,attribution,check,deprecated,expires,license,name,priority,reviewBy,scope,version" {
+		t.Errorf("key completions = %q", got)
+	}
+	if got := labels(tagsID); got != "go,testing" {
+		t.Errorf("tag completions = %q", got)
+	}
+	if got := labels(linksID); got != "go/style.md,testing.md" {
+		t.Errorf("link completions = %q", got)
+	}
+
+	hoverText := func(id int) string {
+		t.Helper()
+		var h *hover
+		if err := json.Unmarshal(responses[id].Result, &h); err != nil || h == nil {
+			t.Fatalf("hover %d = %s, err %v", id, responses[id].Result, err)
+		}
+		return h.Contents.Value
+	}
+	if got := hoverText(hoverLinkID); !strings.Contains(got, "Go style guide") || !strings.Contains(got, "*.go") {
+		t.Errorf("link hover = %q", got)
+	}
+	if got := hoverText(hoverKeyID); !strings.Contains(got, "**description**") {
+		t.Errorf("key hover = %q", got)
+	}
+
+	if err := responses[unknownID].Error; err == nil || err.Code != codeMethodNotFound {
+		t.Errorf("unknown method error = %+v", err)
+	}
+	if resp, ok := responses[shutdownID]; !ok || string(resp.Result) != "null" {
+		t.Errorf("shutdown response = %+v", resp)
+	}
+}
+
+func TestServer_InvalidRuleDiagnostics(t *testing.T) {
+	server, dir := createRuleRepository(t)
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "missing description", text: "---\nname: draft\n---\n# Draft\n", want: "missing required 'description' field"},
+		{name: "tag outside taxonomy", text: "---\ndescription: Draft\ntags: [python]\n---\n", want: "tag 'python' is not in the repository's tag list"},
+		{name: "no frontmatter", text: "# Notes\n", want: "No frontmatter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri := fileURI(filepath.Join(dir, "draft.md"))
+			s := &session{t: t}
+			s.request("initialize", map[string]any{})
+			s.send("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "text": tt.text}})
+			s.request("shutdown", nil)
+			s.send("exit", nil)
+
+			_, diagnostics := s.run(server)
+			if len(diagnostics[uri]) != 1 || !strings.Contains(diagnostics[uri][0].Message, tt.want) {
+				t.Errorf("diagnostics = %+v, want one containing %q", diagnostics[uri], tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_OutsideRepository(t *testing.T) {
+	server, _ := createRuleRepository(t)
+	uri := fileURI(filepath.Join(t.TempDir(), "notes.md"))
+
+	s := &session{t: t}
+	s.send("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "text": "# Notes\n"}})
+	id := s.request("textDocument/completion", map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": 0, "character": 0}})
+	s.request("shutdown", nil)
+	s.send("exit", nil)
+
+	responses, diagnostics := s.run(server)
+	if len(diagnostics[uri]) != 0 {
+		t.Errorf("documents outside repositories should not be diagnosed: %+v", diagnostics[uri])
+	}
+	if string(responses[id].Result) != "[]" {
+		t.Errorf("completion = %s, want []", responses[id].Result)
+	}
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	server, _ := createRuleRepository(t)
+	s := &session{t: t}
+	s.send("exit", nil)
+
+	var out bytes.Buffer
+	if err := server.Serve(&s.in, &out); err == nil {
+		t.Error("expected an error when exiting without shutdown")
+	}
+}
@@ -0,0 +1,293 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rulem/pkg/fileops"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Audit log
+//
+// The usage log records which rules were served for rulem's own reports. The
+// audit log is meant for security teams: every access to a rule, including the
+// ones refused, is appended as one event per line, as JSON (AuditJSONL) or in
+// ArcSight Common Event Format (AuditCEF), which SIEM tools ingest directly.
+// The log is rotated by size: the current file is renamed to <path>.1, older
+// files shift to <path>.2 and so on, and the oldest is deleted.
+//
+// A JSONL event has these fields:
+//
+//	time        RFC 3339 timestamp in UTC
+//	actor       Client name from the MCP initialize request, "socket" for the
+//	            rule socket, "unknown" when the client did not say
+//	session     MCP session ID, omitted for stdio and the socket
+//	action      tool_call, resource_read or socket_get
+//	resource    Path of the rule file
+//	tool        Tool name of the rule
+//	repository  ID of the repository the rule came from
+//	result      success, denied (over a session limit) or error
+//	reason      Why the access was denied or failed, omitted on success
+//
+// CEF events carry the same data: the action is the signature ID, rt the
+// time in milliseconds since the epoch, suser the actor, fname the resource,
+// outcome the result and reason the reason, with the session, tool and
+// repository in cs1 to cs3.
+
+// AuditFormat selects how audit events are written
+type AuditFormat string
+
+const (
+	AuditJSONL AuditFormat = "jsonl" // One JSON object per line
+	AuditCEF   AuditFormat = "cef"   // ArcSight Common Event Format, one event per line
+)
+
+// AuditAction is what a client did
+type AuditAction string
+
+const (
+	AuditToolCall     AuditAction = "tool_call"     // Called a rule's MCP tool
+	AuditResourceRead AuditAction = "resource_read" // Read a rule's MCP resource
+	AuditSocketGet    AuditAction = "socket_get"    // Got a rule over the rule socket
+)
+
+// AuditResult is how an access ended
+type AuditResult string
+
+const (
+	AuditSuccess AuditResult = "success"
+	AuditDenied  AuditResult = "denied" // Refused by a session limit
+	AuditError   AuditResult = "error"
+)
+
+// Audit log rotation defaults
+const (
+	DefaultAuditMaxSize    = 10 << 20 // Bytes after which the log is rotated
+	DefaultAuditMaxBackups = 5        // Rotated files kept
+)
+
+// auditVersion is the product version reported in CEF headers
+const auditVersion = "1.0.0"
+
+// AuditEvent is one access to a rule
+type AuditEvent struct {
+	Time       time.Time   `json:"time"`
+	Actor      string      `json:"actor"`
+	Session    string      `json:"session,omitempty"`
+	Action     AuditAction `json:"action"`
+	Resource   string      `json:"resource"`
+	Tool       string      `json:"tool,omitempty"`
+	Repository string      `json:"repository,omitempty"`
+	Result     AuditResult `json:"result"`
+	Reason     string      `json:"reason,omitempty"`
+}
+
+// AuditLog appends audit events to a file, rotating it by size
+type AuditLog struct {
+	path       string
+	format     AuditFormat
+	MaxSize    int64 // Rotate before a write would take the file past this size
+	MaxBackups int   // Rotated files kept
+	mu         sync.Mutex
+}
+
+// ParseAuditFormat returns the audit format called name
+func ParseAuditFormat(name string) (AuditFormat, error) {
+	switch format := AuditFormat(name); format {
+	case AuditJSONL, AuditCEF:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown audit format %q: use %s or %s", name, AuditJSONL, AuditCEF)
+	}
+}
+
+// NewAuditLog creates an audit log appending events in format to path, with
+// the default rotation
+func NewAuditLog(path string, format AuditFormat) *AuditLog {
+	return &AuditLog{
+		path:       path,
+		format:     format,
+		MaxSize:    DefaultAuditMaxSize,
+		MaxBackups: DefaultAuditMaxBackups,
+	}
+}
+
+// Record appends event to the log, rotating it first when it is full
+func (l *AuditLog) Record(event AuditEvent) error {
+	var line []byte
+	switch l.format {
+	case AuditCEF:
+		line = []byte(formatCEF(event))
+	default:
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit event: %w", err)
+		}
+		line = encoded
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := fileops.EnsureDirectoryExists(filepath.Dir(l.path)); err != nil {
+		return fmt.Errorf("cannot create audit log directory: %w", err)
+	}
+	if info, err := os.Stat(l.path); err == nil && l.MaxSize > 0 && info.Size() > 0 && info.Size()+int64(len(line)) > l.MaxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// rotate shifts the log to <path>.1 and its backups up by one, deleting the
+// oldest. Callers hold mu.
+func (l *AuditLog) rotate() error {
+	if err := rotateFile(l.path, l.MaxBackups); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return nil
+}
+
+// rotateFile shifts the file at path to <path>.1 and its backups up by one,
+// keeping at most maxBackups and deleting the file when that is 0
+func rotateFile(path string, maxBackups int) error {
+	if maxBackups <= 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.Remove(backupPath(path, maxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for i := maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backupPath(path, i), backupPath(path, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(path, backupPath(path, 1))
+}
+
+// backupPath returns the path of the nth rotated file of the file at path
+func backupPath(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// cefSeverity maps results to CEF severities (0-10)
+var cefSeverity = map[AuditResult]int{
+	AuditSuccess: 3,
+	AuditError:   5,
+	AuditDenied:  7,
+}
+
+// formatCEF formats event as a CEF line
+func formatCEF(event AuditEvent) string {
+	name := "Rule served"
+	switch event.Result {
+	case AuditDenied:
+		name = "Rule access denied"
+	case AuditError:
+		name = "Rule access failed"
+	}
+
+	extension := []string{
+		"rt=" + strconv.FormatInt(event.Time.UnixMilli(), 10),
+		"suser=" + cefValue(event.Actor),
+		"act=" + cefValue(string(event.Action)),
+		"fname=" + cefValue(event.Resource),
+		"outcome=" + cefValue(string(event.Result)),
+	}
+	if event.Reason != "" {
+		extension = append(extension, "reason="+cefValue(event.Reason))
+	}
+	custom := []struct{ label, value string }{
+		{"session", event.Session},
+		{"tool", event.Tool},
+		{"repository", event.Repository},
+	}
+	for i, field := range custom {
+		if field.value != "" {
+			extension = append(extension, fmt.Sprintf("cs%dLabel=%s cs%d=%s", i+1, field.label, i+1, cefValue(field.value)))
+		}
+	}
+
+	return fmt.Sprintf("CEF:0|rulem|rulem|%s|%s|%s|%d|%s",
+		auditVersion, cefHeader(string(event.Action)), cefHeader(name), cefSeverity[event.Result], strings.Join(extension, " "))
+}
+
+// cefHeader escapes a CEF header field
+func cefHeader(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", " ", "\r", " ").Replace(s)
+}
+
+// cefValue escapes a CEF extension value
+func cefValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "=", `\=`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+// EnableAuditLog makes the server record every access to a rule in log
+func (s *Server) EnableAuditLog(log *AuditLog) {
+	s.auditLog = log
+}
+
+// audit records event, filling in its time and, unless set, the client
+// session it came from. Failures are logged rather than failing the access.
+func (s *Server) audit(ctx context.Context, event AuditEvent) {
+	if s.auditLog == nil {
+		return
+	}
+	event.Time = time.Now().UTC()
+	if event.Actor == "" {
+		event.Actor = clientName(ctx)
+		event.Session = sessionID(ctx)
+	}
+	if err := s.auditLog.Record(event); err != nil {
+		s.logger.Warn("Failed to record audit event", "action", event.Action, "resource", event.Resource, "error", err)
+	}
+}
+
+// auditRule records an access to tool's rule
+func (s *Server) auditRule(ctx context.Context, action AuditAction, tool *RuleFileTool, result AuditResult, reason string) {
+	event := AuditEvent{Action: action, Result: result, Reason: reason}
+	if tool != nil {
+		event.Resource = tool.RuleFile.FilePath
+		event.Tool = tool.Name
+		event.Repository = tool.RuleFile.RepositoryID
+	}
+	if action == AuditSocketGet {
+		event.Actor = "socket"
+	}
+	s.audit(ctx, event)
+}
+
+// clientName returns the name the client of ctx's session gave when it
+// initialized, or "unknown"
+func clientName(ctx context.Context) string {
+	session := server.ClientSessionFromContext(ctx)
+	if withInfo, ok := session.(server.SessionWithClientInfo); ok {
+		if name := withInfo.GetClientInfo().Name; name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLog_JSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	log := NewAuditLog(path, AuditJSONL)
+
+	event := AuditEvent{
+		Time:       time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		Actor:      "claude-code",
+		Session:    "session-1",
+		Action:     AuditToolCall,
+		Resource:   "/rules/go.md",
+		Tool:       "go_style",
+		Repository: "rules-1",
+		Result:     AuditSuccess,
+	}
+	if err := log.Record(event); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	var got AuditEvent
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("audit log line is not JSON: %v", err)
+	}
+	if got != event {
+		t.Errorf("recorded %+v, want %+v", got, event)
+	}
+	if strings.Contains(string(data), `"reason"`) {
+		t.Errorf("successful event %s should omit the reason", data)
+	}
+}
+
+func TestFormatCEF(t *testing.T) {
+	event := AuditEvent{
+		Time:       time.UnixMilli(1792152000000).UTC(),
+		Actor:      "cursor",
+		Action:     AuditResourceRead,
+		Resource:   `C:\rules\a=b.md`,
+		Repository: "rules-1",
+		Result:     AuditDenied,
+		Reason:     "rate limit reached",
+	}
+	want := `CEF:0|rulem|rulem|1.0.0|resource_read|Rule access denied|7|rt=1792152000000 suser=cursor act=resource_read fname=C:\\rules\\a\=b.md outcome=denied reason=rate limit reached cs3Label=repository cs3=rules-1`
+	if got := formatCEF(event); got != want {
+		t.Errorf("formatCEF() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAuditLog_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := NewAuditLog(path, AuditJSONL)
+	log.MaxSize = 200
+	log.MaxBackups = 2
+
+	for i := 0; i < 10; i++ {
+		if err := log.Record(AuditEvent{Time: time.Now(), Actor: "client", Action: AuditToolCall, Resource: "/rules/go.md", Result: AuditSuccess}); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s: %v", filepath.Base(name), err)
+		}
+		if info.Size() > log.MaxSize {
+			t.Errorf("%s is %d bytes, over the %d byte limit", filepath.Base(name), info.Size(), log.MaxSize)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only %d backups to be kept", log.MaxBackups)
+	}
+}
+
+func TestParseAuditFormat(t *testing.T) {
+	for _, name := range []string{"jsonl", "cef"} {
+		if _, err := ParseAuditFormat(name); err != nil {
+			t.Errorf("ParseAuditFormat(%q): %v", name, err)
+		}
+	}
+	if _, err := ParseAuditFormat("syslog"); err == nil {
+		t.Error("ParseAuditFormat(\"syslog\") expected an error")
+	}
+}
@@ -0,0 +1,278 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// get_all_rules tool
+//
+// Assistants often want everything relevant to a task in one call rather than
+// a tool call per rule. get_all_rules returns the selected rules, by name or
+// tag, concatenated into one bundle. The bundle is kept within a token budget
+// (bundle_max_tokens in config.yaml, or less when the assistant asks), with
+// tokens estimated from the size of the text. Rules with a higher frontmatter
+// priority are packed first; the first rule that does not fit is cut short
+// and the rest are skipped, and the bundle ends by saying which.
+
+// BundleToolName is the name of the built-in rule bundle tool, after the
+// configured tool_prefix if any. Like search_rules, rule files cannot take it.
+const BundleToolName = "get_all_rules"
+
+const (
+	// bytesPerToken estimates the tokens of rule text from its size
+	bytesPerToken = 4
+
+	// minTruncatedTokens is the least budget worth filling with the start of
+	// a rule that does not fit whole
+	minTruncatedTokens = 100
+)
+
+// BundleQuery selects the rules of a bundle. A rule is selected when it is
+// named or has one of the tags; without names or tags every rule is.
+type BundleQuery struct {
+	Names     []string // Rules to include, resolved like ResolveRule
+	Tags      []string // Include rules with any of these tags, ignoring case
+	MaxTokens int      // Budget of the bundle
+}
+
+// BundledRule is a rule included in a bundle
+type BundledRule struct {
+	Tool      *RuleFileTool
+	Content   string // The rule's content, or its start when Truncated
+	Truncated bool
+}
+
+// SkippedRule is a selected rule left out of a bundle
+type SkippedRule struct {
+	Tool   *RuleFileTool
+	Reason string
+}
+
+// Bundle is the answer to a BundleQuery
+type Bundle struct {
+	Rules     []BundledRule
+	Skipped   []SkippedRule
+	Missing   []string // Why names of the query did not resolve to a rule
+	Tokens    int      // Estimated tokens of the bundle's rules
+	MaxTokens int
+}
+
+// newBundleTool describes the get_all_rules tool, registered as name
+func newBundleTool(name string, maxTokens int) mcp.Tool {
+	return mcp.NewTool(name,
+		mcp.WithDescription("Get the full text of many rules in one call: every rule, or those named or tagged, highest priority first, within a token budget. Says which rules were cut short or left out."),
+		mcp.WithArray("names", mcp.Description("Rules to include, by tool name"), mcp.WithStringItems()),
+		mcp.WithArray("tags", mcp.Description("Include rules with any of these tags"), mcp.WithStringItems()),
+		mcp.WithNumber("max_tokens", mcp.Description(fmt.Sprintf("Most tokens of rules to return, estimated (at most and by default %d)", maxTokens)), mcp.Min(1)),
+	)
+}
+
+// bundleToolHandler answers get_all_rules calls from the current tool registry
+func (s *Server) bundleToolHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	maxTokens, err := s.config.RuleBundleMaxTokens()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	query := BundleQuery{
+		Names:     request.GetStringSlice("names", nil),
+		Tags:      request.GetStringSlice("tags", nil),
+		MaxTokens: min(request.GetInt("max_tokens", maxTokens), maxTokens),
+	}
+	if query.MaxTokens < 1 {
+		query.MaxTokens = maxTokens
+	}
+
+	s.logger.Debug("Processing rule bundle", "names", query.Names, "tags", query.Tags, "maxTokens", query.MaxTokens)
+
+	bundle := BuildBundle(s.tools(), query)
+	for _, rule := range bundle.Rules {
+		s.recordUsage(rule.Tool, clientName(ctx))
+		s.auditRule(ctx, AuditToolCall, rule.Tool, AuditSuccess, "")
+	}
+
+	result := mcp.NewToolResultText(formatBundle(bundle))
+	if fields := bundleMetaFields(bundle); len(fields) > 0 {
+		result.Meta = mcp.NewMetaFromMap(fields)
+	}
+	return result, nil
+}
+
+// BuildBundle selects the rules in tools for query and packs them within its
+// budget, highest priority first, then by tool name. Deprecated rules are
+// only included when named.
+func BuildBundle(tools map[string]*RuleFileTool, query BundleQuery) Bundle {
+	bundle := Bundle{MaxTokens: query.MaxTokens}
+
+	named := make(map[string]bool, len(query.Names))
+	for _, name := range query.Names {
+		tool, err := ResolveRule(tools, name, "")
+		if err != nil {
+			bundle.Missing = append(bundle.Missing, err.Error())
+			continue
+		}
+		named[tool.Name] = true
+	}
+
+	filtered := len(query.Names) > 0 || len(query.Tags) > 0
+	var selected []*RuleFileTool
+	for _, tool := range tools {
+		if !named[tool.Name] {
+			if filtered && !hasAnyTag(tool.RuleFile.Tags, query.Tags) {
+				continue
+			}
+			if tool.RuleFile.Deprecated {
+				bundle.Skipped = append(bundle.Skipped, SkippedRule{Tool: tool, Reason: "deprecated"})
+				continue
+			}
+		}
+		selected = append(selected, tool)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if pi, pj := selected[i].RuleFile.Priority, selected[j].RuleFile.Priority; pi != pj {
+			return pi > pj
+		}
+		return selected[i].Name < selected[j].Name
+	})
+
+	remaining := query.MaxTokens
+	for _, tool := range selected {
+		tokens := estimateTokens(bundleSection(tool, tool.RuleFile.Content, false))
+		switch {
+		case tokens <= remaining:
+			bundle.Rules = append(bundle.Rules, BundledRule{Tool: tool, Content: tool.RuleFile.Content})
+		case remaining >= minTruncatedTokens:
+			content := truncateToTokens(tool, remaining)
+			bundle.Rules = append(bundle.Rules, BundledRule{Tool: tool, Content: content, Truncated: true})
+			tokens = estimateTokens(bundleSection(tool, content, true))
+		default:
+			bundle.Skipped = append(bundle.Skipped, SkippedRule{Tool: tool, Reason: "over the token budget"})
+			continue
+		}
+		remaining -= tokens
+		bundle.Tokens += tokens
+	}
+	sort.Slice(bundle.Skipped, func(i, j int) bool { return bundle.Skipped[i].Tool.Name < bundle.Skipped[j].Tool.Name })
+	return bundle
+}
+
+// hasAnyTag reports whether tags includes one of the wanted tags, ignoring case
+func hasAnyTag(tags, wanted []string) bool {
+	return slices.ContainsFunc(wanted, func(want string) bool {
+		return slices.ContainsFunc(tags, func(tag string) bool { return strings.EqualFold(tag, want) })
+	})
+}
+
+// estimateTokens estimates the tokens of text from its size
+func estimateTokens(text string) int {
+	return (len(text) + bytesPerToken - 1) / bytesPerToken
+}
+
+// truncateToTokens returns as much of the start of a rule's content as fits,
+// with its section, in tokens; whole lines when possible
+func truncateToTokens(tool *RuleFileTool, tokens int) string {
+	content := tool.RuleFile.Content
+	size := tokens*bytesPerToken - len(bundleSection(tool, "", true))
+	if size <= 0 {
+		return ""
+	}
+	if size >= len(content) {
+		return content
+	}
+	if i := strings.LastIndex(content[:size], "\n"); i > 0 {
+		return content[:i+1]
+	}
+	// Keep whole runes
+	for size > 0 && !utf8.RuneStart(content[size]) {
+		size--
+	}
+	return content[:size]
+}
+
+// bundleSection renders one rule of a bundle
+func bundleSection(tool *RuleFileTool, content string, truncated bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n%s\n\n%s", tool.Name, tool.Description, content)
+	if !strings.HasSuffix(content, "\n") {
+		b.WriteString("\n")
+	}
+	if truncated {
+		fmt.Fprintf(&b, "[Cut short to fit the budget: call %s for the full rule.]\n", tool.Name)
+	}
+	return b.String()
+}
+
+// formatBundle renders a bundle for the assistant
+func formatBundle(bundle Bundle) string {
+	var b strings.Builder
+	switch len(bundle.Rules) {
+	case 0:
+		b.WriteString("No rules in the bundle.\n")
+	case 1:
+		fmt.Fprintf(&b, "1 rule, about %d of %d tokens.\n", bundle.Tokens, bundle.MaxTokens)
+	default:
+		fmt.Fprintf(&b, "%d rules, about %d of %d tokens, highest priority first.\n", len(bundle.Rules), bundle.Tokens, bundle.MaxTokens)
+	}
+
+	var truncated []string
+	for _, rule := range bundle.Rules {
+		b.WriteString("\n")
+		b.WriteString(bundleSection(rule.Tool, rule.Content, rule.Truncated))
+		if rule.Truncated {
+			truncated = append(truncated, rule.Tool.Name)
+		}
+	}
+
+	if len(truncated) > 0 || len(bundle.Skipped) > 0 || len(bundle.Missing) > 0 {
+		b.WriteString("\n")
+	}
+	if len(truncated) > 0 {
+		fmt.Fprintf(&b, "Cut short: %s\n", strings.Join(truncated, ", "))
+	}
+	if len(bundle.Skipped) > 0 {
+		skipped := make([]string, len(bundle.Skipped))
+		for i, skip := range bundle.Skipped {
+			skipped[i] = fmt.Sprintf("%s (%s)", skip.Tool.Name, skip.Reason)
+		}
+		fmt.Fprintf(&b, "Left out: %s\n", strings.Join(skipped, ", "))
+	}
+	for _, missing := range bundle.Missing {
+		fmt.Fprintf(&b, "Not found: %s\n", missing)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// bundleMetaFields lists the rules of a bundle that were cut short or left
+// out for the `_meta` of its result, so clients need not parse the text
+func bundleMetaFields(bundle Bundle) map[string]any {
+	fields := make(map[string]any)
+	var truncated []string
+	for _, rule := range bundle.Rules {
+		if rule.Truncated {
+			truncated = append(truncated, rule.Tool.Name)
+		}
+	}
+	if len(truncated) > 0 {
+		fields["truncated"] = truncated
+	}
+	if len(bundle.Skipped) > 0 {
+		skipped := make([]string, len(bundle.Skipped))
+		for i, skip := range bundle.Skipped {
+			skipped[i] = skip.Tool.Name
+		}
+		fields["skipped"] = skipped
+	}
+	return fields
+}
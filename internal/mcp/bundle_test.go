@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// bundleNames returns the tool names of the rules in a bundle
+func bundleNames(bundle Bundle) []string {
+	var names []string
+	for _, rule := range bundle.Rules {
+		names = append(names, rule.Tool.Name)
+	}
+	return names
+}
+
+func TestBuildBundle(t *testing.T) {
+	tools := searchTools()
+	tools["go_style"].RuleFile.Priority = 10
+	tools["old"] = &RuleFileTool{Name: "old", Description: "Old rules", RuleFile: &RuleFile{Tags: []string{"go"}, Deprecated: true, Content: "# Old\n"}}
+
+	tests := []struct {
+		name        string
+		query       BundleQuery
+		wantNames   []string
+		wantSkipped []string
+		wantMissing int
+	}{
+		{name: "every rule by priority", query: BundleQuery{MaxTokens: 1000}, wantNames: []string{"go_style", "go_errors", "py_testing"}, wantSkipped: []string{"old"}},
+		{name: "any of the tags", query: BundleQuery{Tags: []string{"PYTHON", "style"}, MaxTokens: 1000}, wantNames: []string{"go_style", "py_testing"}},
+		{name: "names and tags", query: BundleQuery{Names: []string{"py-testing", "old", "missing"}, Tags: []string{"style"}, MaxTokens: 1000}, wantNames: []string{"go_style", "old", "py_testing"}, wantMissing: 1},
+		{name: "over the budget", query: BundleQuery{Tags: []string{"go"}, MaxTokens: 20}, wantNames: []string{"go_style"}, wantSkipped: []string{"go_errors", "old"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle := BuildBundle(tools, tt.query)
+			if names := bundleNames(bundle); !slices.Equal(names, tt.wantNames) {
+				t.Errorf("rules = %v, want %v", names, tt.wantNames)
+			}
+			var skipped []string
+			for _, skip := range bundle.Skipped {
+				skipped = append(skipped, skip.Tool.Name)
+			}
+			if !slices.Equal(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %v, want %v", skipped, tt.wantSkipped)
+			}
+			if len(bundle.Missing) != tt.wantMissing {
+				t.Errorf("missing = %v, want %d", bundle.Missing, tt.wantMissing)
+			}
+			if bundle.Tokens > tt.query.MaxTokens {
+				t.Errorf("bundle has %d tokens, over its budget of %d", bundle.Tokens, tt.query.MaxTokens)
+			}
+		})
+	}
+}
+
+func TestBuildBundle_Truncates(t *testing.T) {
+	content := strings.Repeat("Keep functions short.\n", 100)
+	tools := map[string]*RuleFileTool{
+		"long":  {Name: "long", Description: "Long rule", RuleFile: &RuleFile{Priority: 1, Content: content}},
+		"short": {Name: "short", Description: "Short rule", RuleFile: &RuleFile{Content: "# Short\n"}},
+	}
+
+	bundle := BuildBundle(tools, BundleQuery{MaxTokens: 200})
+	if len(bundle.Rules) != 1 || !bundle.Rules[0].Truncated || bundle.Tokens > 200 {
+		t.Fatalf("bundle = %+v, want long cut short within 200 tokens", bundle)
+	}
+	if got := bundle.Rules[0].Content; !strings.HasPrefix(content, got) || !strings.HasSuffix(got, "\n") {
+		t.Errorf("truncated content should be whole lines from the start of the rule, got %q", got)
+	}
+
+	text := formatBundle(bundle)
+	for _, want := range []string{"1 rule, about", "## long\nLong rule\n\nKeep functions short.", "[Cut short to fit the budget: call long for the full rule.]", "Cut short: long", "Left out: short (over the token budget)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("bundle text should contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestServer_BundleToolHandler(t *testing.T) {
+	server, _ := createTestServer(t)
+	server.config.BundleMaxTokens = 30
+	server.toolRegistry = searchTools()
+
+	var request mcp.CallToolRequest
+	request.Params.Name = BundleToolName
+	request.Params.Arguments = map[string]any{"tags": []any{"go"}, "max_tokens": 1000}
+	result, err := server.bundleToolHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("bundleToolHandler() = %v, %v", result, err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "of 30 tokens") || !strings.Contains(text, "## go_errors") {
+		t.Errorf("expected the configured budget to cap max_tokens, got %q", text)
+	}
+	if result.Meta == nil || result.Meta.AdditionalFields["skipped"] == nil {
+		t.Errorf("expected the skipped rules in _meta, got %+v", result.Meta)
+	}
+}
+
+func TestGenerateToolName_ReservesBundleTool(t *testing.T) {
+	processor, _, _ := createTestRuleFileProcessor(t)
+	if name := processor.generateToolName(&RuleFile{FileName: "get_all_rules.md"}); name != "get_all_rules_1" {
+		t.Errorf("generateToolName() = %q, want get_all_rules_1", name)
+	}
+}
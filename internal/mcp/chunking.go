@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Chunked rule content
+//
+// Over network transports a very large rule is served in parts, so clients do
+// not have to buffer multi-megabyte results: a call returns the first part and
+// says how many there are, and the client asks for the others with the `part`
+// argument. A client that sends a progress token gets a progress notification
+// for each part it reads.
+//
+// The stdio transport serves rules whole; the file size limit applied when rule
+// files are processed keeps those bounded.
+
+// DefaultChunkSize is the largest part of a rule served by network transports
+const DefaultChunkSize = 256 * 1024
+
+// partArgument is the tool argument selecting the part of a chunked rule
+const partArgument = "part"
+
+// EnableChunking serves rules larger than size bytes in parts of at most size
+// bytes. Network transports enable it before Start; zero serves rules whole.
+func (s *Server) EnableChunking(size int) {
+	s.chunkSize = size
+}
+
+// isChunked reports whether content is served in parts
+func (s *Server) isChunked(content string) bool {
+	return s.chunkSize > 0 && len(content) > s.chunkSize
+}
+
+// chunkedResult returns the part of a chunked rule the request asks for,
+// notifying the client of its progress when it asked for notifications
+func (s *Server) chunkedResult(ctx context.Context, request mcp.CallToolRequest, tool *RuleFileTool, chunks []string) *mcp.CallToolResult {
+	part := request.GetInt(partArgument, 1)
+	if part < 1 || part > len(chunks) {
+		message := fmt.Sprintf("part %d out of range: %s has %d parts", part, tool.Name, len(chunks))
+		s.auditRule(ctx, AuditToolCall, tool, AuditError, message)
+		return mcp.NewToolResultError(message)
+	}
+	if part == 1 {
+		s.recordUsage(tool, clientName(ctx))
+	}
+	s.auditRule(ctx, AuditToolCall, tool, AuditSuccess, "")
+
+	s.notifyProgress(ctx, request, part, len(chunks))
+
+	result := mcp.NewToolResultText(chunks[part-1])
+	if part < len(chunks) {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("[Part %d of %d. Call %s with %s=%d for the rest of the rule.]", part, len(chunks), tool.Name, partArgument, part+1)))
+	}
+
+	fields := ruleMetaFields(tool.RuleFile)
+	fields["part"] = part
+	fields["parts"] = len(chunks)
+	result.Meta = mcp.NewMetaFromMap(fields)
+	return result
+}
+
+// notifyProgress sends a progress notification for part of total when the
+// request carries a progress token. Failures only cost the client its progress.
+func (s *Server) notifyProgress(ctx context.Context, request mcp.CallToolRequest, part, total int) {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return
+	}
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return
+	}
+
+	err := srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+		"progressToken": request.Params.Meta.ProgressToken,
+		"progress":      part,
+		"total":         total,
+		"message":       fmt.Sprintf("Part %d of %d", part, total),
+	})
+	if err != nil {
+		s.logger.Debug("Failed to send progress notification", "error", err)
+	}
+}
+
+// splitChunks splits content into parts of at most size bytes. Parts end after
+// a line break when there is one, and never inside a UTF-8 sequence.
+func splitChunks(content string, size int) []string {
+	var chunks []string
+	for len(content) > size {
+		cut := strings.LastIndexByte(content[:size], '\n') + 1
+		if cut == 0 {
+			// One long line: cut at the last rune boundary that fits
+			cut = size
+			for cut > 0 && !utf8.RuneStart(content[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				cut = size
+			}
+		}
+		chunks = append(chunks, content[:cut])
+		content = content[cut:]
+	}
+	return append(chunks, content)
+}
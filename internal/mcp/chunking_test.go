@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		size    int
+		want    []string
+	}{
+		{name: "fits", content: "one\ntwo\n", size: 16, want: []string{"one\ntwo\n"}},
+		{name: "line breaks", content: "one\ntwo\nthree\n", size: 9, want: []string{"one\ntwo\n", "three\n"}},
+		{name: "long line", content: "abcdefghij", size: 4, want: []string{"abcd", "efgh", "ij"}},
+		{name: "multibyte runes", content: "ééééé", size: 3, want: []string{"é", "é", "é", "é", "é"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitChunks(tt.content, tt.size)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("splitChunks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_ChunkedToolHandler(t *testing.T) {
+	body := strings.Repeat("A line of a very large rule.\n", 10)
+	server, _ := createTestServerWithFiles(t, map[string]string{
+		"large.md": "---\ndescription: Large rule\nname: large_rule\nlicense: MIT\n---\n" + body,
+	})
+	if err := server.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	files, err := server.getRepoFiles()
+	if err != nil {
+		t.Fatalf("Failed to get repository files: %v", err)
+	}
+	if server.toolRegistry, err = server.ruleProcessor.ProcessRuleFiles(files); err != nil {
+		t.Fatalf("Failed to process rule files: %v", err)
+	}
+	server.EnableChunking(100)
+
+	handler, err := server.getRulefileToolHandler("large_rule")
+	if err != nil {
+		t.Fatalf("getRulefileToolHandler: %v", err)
+	}
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		var request mcp.CallToolRequest
+		request.Params.Name = "large_rule"
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handler: %v", err)
+		}
+		return result
+	}
+
+	var content strings.Builder
+	parts := 0
+	for part := 1; ; part++ {
+		result := call(map[string]any{"part": part})
+		if result.IsError || result.Meta == nil {
+			t.Fatalf("part %d: unexpected result %+v", part, result)
+		}
+		parts = result.Meta.AdditionalFields["parts"].(int)
+		if result.Meta.AdditionalFields["license"] != "MIT" {
+			t.Errorf("part %d: _meta should keep the license, got %v", part, result.Meta.AdditionalFields)
+		}
+		text := result.Content[0].(mcp.TextContent).Text
+		if len(text) > 100 {
+			t.Errorf("part %d is %d bytes, want at most 100", part, len(text))
+		}
+		content.WriteString(text)
+		if part == parts {
+			break
+		}
+		if hint := result.Content[1].(mcp.TextContent).Text; !strings.Contains(hint, "part=") {
+			t.Errorf("part %d should say how to get the next part, got %q", part, hint)
+		}
+	}
+	if parts < 2 || content.String() != server.toolRegistry["large_rule"].RuleFile.Content {
+		t.Errorf("parts joined back = %q in %d parts, want the whole rule", content.String(), parts)
+	}
+
+	if first := call(nil); first.Meta.AdditionalFields["part"] != 1 {
+		t.Errorf("a call without part should return part 1, got %v", first.Meta.AdditionalFields)
+	}
+	if result := call(map[string]any{"part": parts + 1}); !result.IsError {
+		t.Error("expected an error for a part out of range")
+	}
+}
@@ -0,0 +1,246 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Tool compliance self-check
+//
+// Tool names and descriptions come from rule files, so nothing stops a rule
+// from producing a tool a client rejects: a name longer than the client
+// accepts, a description past its limit, or simply more tools than it loads.
+// Clients tend to fail opaquely when that happens, dropping tools or the whole
+// server without saying why. The self-check holds every generated tool to the
+// MCP specification and to the documented limits of the clients listed under
+// mcp_clients in config.yaml, and the server reports each violation when it
+// starts and whenever the rules are reloaded.
+
+// ToolConstraints are the limits a consumer of MCP tools puts on them. Zero
+// values mean no limit.
+type ToolConstraints struct {
+	Client               string         // Who sets the limits, as named in mcp_clients
+	NamePattern          *regexp.Regexp // Tool names must match
+	MaxNameLength        int            // In characters
+	MaxDescriptionLength int            // In characters
+	MaxTools             int            // Tools loaded from one server
+}
+
+// specConstraints are the MCP specification's rules for tool names: 1 to 128
+// ASCII letters, digits, underscores, hyphens and dots
+var specConstraints = ToolConstraints{
+	Client:        "mcp",
+	NamePattern:   regexp.MustCompile(`^[A-Za-z0-9_.-]+$`),
+	MaxNameLength: 128,
+}
+
+// cursorToolNameBudget is the length Cursor allows for a server's name and a
+// tool's name together; the check assumes the server is added as rulem
+const cursorToolNameBudget = 60
+
+// ClientConstraints are the documented tool limits of MCP clients, by the name
+// they are listed under in mcp_clients
+var ClientConstraints = map[string]ToolConstraints{
+	"claude": {
+		Client:      "claude",
+		NamePattern: regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`),
+	},
+	"copilot": {
+		Client:   "copilot",
+		MaxTools: 128,
+	},
+	"cursor": {
+		Client:        "cursor",
+		MaxNameLength: cursorToolNameBudget - len(serverName),
+		MaxTools:      40,
+	},
+	"gemini": {
+		Client:      "gemini",
+		NamePattern: regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.-]{0,63}$`),
+	},
+	"openai": {
+		Client:               "openai",
+		NamePattern:          regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`),
+		MaxDescriptionLength: 1024,
+		MaxTools:             128,
+	},
+}
+
+// ToolViolation is a way a tool breaks the limits of the specification or a client
+type ToolViolation struct {
+	Tool    string // Empty when the tool set as a whole is at fault
+	Client  string // "mcp" for the specification
+	Problem string
+}
+
+func (v ToolViolation) String() string {
+	if v.Tool == "" {
+		return fmt.Sprintf("%s: %s", v.Client, v.Problem)
+	}
+	return fmt.Sprintf("%s: tool %q %s", v.Client, v.Tool, v.Problem)
+}
+
+// clientConstraints returns the constraints of the named clients, failing on
+// a name it does not know
+func clientConstraints(clients []string) ([]ToolConstraints, error) {
+	var constraints []ToolConstraints
+	for _, name := range clients {
+		c, ok := ClientConstraints[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown MCP client %q in mcp_clients (known clients: %s)",
+				name, strings.Join(slices.Sorted(maps.Keys(ClientConstraints)), ", "))
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints, nil
+}
+
+// CheckToolCompliance checks tools against the MCP specification and the
+// limits of the named clients, returning the violations in tool order
+func CheckToolCompliance(tools []mcp.Tool, clients []string) ([]ToolViolation, error) {
+	constraints, err := clientConstraints(clients)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []ToolViolation
+	for _, tool := range tools {
+		violations = append(violations, checkToolSpec(tool)...)
+		for _, c := range constraints {
+			violations = append(violations, checkToolConstraints(tool, c)...)
+		}
+	}
+	for _, c := range append([]ToolConstraints{specConstraints}, constraints...) {
+		if c.MaxTools > 0 && len(tools) > c.MaxTools {
+			violations = append(violations, ToolViolation{
+				Client:  c.Client,
+				Problem: fmt.Sprintf("%d tools are served but only %d are loaded; serve fewer repositories or rules", len(tools), c.MaxTools),
+			})
+		}
+	}
+	return violations, nil
+}
+
+// checkToolSpec checks a tool against the MCP specification
+func checkToolSpec(tool mcp.Tool) []ToolViolation {
+	violations := checkToolConstraints(tool, specConstraints)
+	problem := func(format string, args ...any) {
+		violations = append(violations, ToolViolation{Tool: tool.Name, Client: specConstraints.Client, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(tool.Description) == "" {
+		problem("has no description, so assistants cannot tell when to use it")
+	}
+
+	data, err := json.Marshal(tool)
+	if err != nil {
+		problem("cannot be encoded: %v", err)
+		return violations
+	}
+	var encoded struct {
+		InputSchema struct {
+			Type       string         `json:"type"`
+			Properties map[string]any `json:"properties"`
+			Required   []string       `json:"required"`
+		} `json:"inputSchema"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		problem("has an input schema that is not a JSON object: %v", err)
+		return violations
+	}
+	schema := encoded.InputSchema
+	if schema.Type != "object" {
+		problem("has an input schema of type %q, but tool arguments must be an object", schema.Type)
+	}
+	for _, name := range schema.Required {
+		if _, ok := schema.Properties[name]; !ok {
+			problem("requires argument %q, which its input schema does not describe", name)
+		}
+	}
+	return violations
+}
+
+// checkToolConstraints checks a tool's name and description against c
+func checkToolConstraints(tool mcp.Tool, c ToolConstraints) []ToolViolation {
+	var violations []ToolViolation
+	problem := func(format string, args ...any) {
+		violations = append(violations, ToolViolation{Tool: tool.Name, Client: c.Client, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	nameLength := utf8.RuneCountInString(tool.Name)
+	if nameLength == 0 {
+		problem("has an empty name")
+	}
+	if c.MaxNameLength > 0 && nameLength > c.MaxNameLength {
+		problem("has a name of %d characters, more than the %d allowed; set a shorter name in its frontmatter", nameLength, c.MaxNameLength)
+	}
+	if c.NamePattern != nil && nameLength > 0 && !c.NamePattern.MatchString(tool.Name) {
+		problem("has a name that does not match %s", c.NamePattern)
+	}
+	if length := utf8.RuneCountInString(tool.Description); c.MaxDescriptionLength > 0 && length > c.MaxDescriptionLength {
+		problem("has a description of %d characters, more than the %d allowed", length, c.MaxDescriptionLength)
+	}
+	return violations
+}
+
+// checkCompliance reports the violations of the registered tools. It fails
+// only when mcp_clients names a client it does not know.
+func (s *Server) checkCompliance() error {
+	violations, err := CheckToolCompliance(s.registeredTools(), s.config.MCPClients)
+	if err != nil {
+		return err
+	}
+	for _, v := range violations {
+		s.logger.Warn("MCP tool may be rejected by clients", "client", v.Client, "tool", v.Tool, "problem", v.Problem)
+	}
+	if len(violations) > 0 {
+		s.logger.Warn("MCP tool self-check found problems", "violations", len(violations))
+	}
+	return nil
+}
+
+// registeredTools returns the tools registered with the MCP server, by name
+func (s *Server) registeredTools() []mcp.Tool {
+	tools := make([]mcp.Tool, 0, len(s.serverTools))
+	for _, name := range slices.Sorted(maps.Keys(s.serverTools)) {
+		tools = append(tools, s.serverTools[name].Tool)
+	}
+	return tools
+}
+
+// SelfCheck prepares the repositories and checks the tools the server would
+// serve against the MCP specification and the clients in mcp_clients, without
+// serving them
+func (s *Server) SelfCheck() ([]ToolViolation, error) {
+	if err := s.InitializeComponents(); err != nil {
+		return nil, err
+	}
+	tools, err := s.loadRuleFileTools()
+	if err != nil {
+		return nil, err
+	}
+
+	s.registryMu.Lock()
+	s.toolRegistry = tools
+	s.registryMu.Unlock()
+	builtins, err := s.builtinTools()
+	if err != nil {
+		return nil, err
+	}
+	s.serverTools = make(map[string]server.ServerTool, len(builtins)+len(tools))
+	for _, tool := range builtins {
+		s.serverTools[tool.Tool.Name] = tool
+	}
+	for name, tool := range tools {
+		s.serverTools[name] = server.ServerTool{Tool: s.newRuleTool(name, tool)}
+	}
+	return CheckToolCompliance(s.registeredTools(), s.config.MCPClients)
+}
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCheckToolCompliance(t *testing.T) {
+	tool := func(name, description string) mcp.Tool {
+		return mcp.NewTool(name, mcp.WithDescription(description))
+	}
+	tests := []struct {
+		name    string
+		tools   []mcp.Tool
+		clients []string
+		want    []string // Violations, as ToolViolation.String
+	}{
+		{
+			name:  "compliant tools",
+			tools: []mcp.Tool{tool("go_style", "Go style guide"), newSearchTool(SearchToolName)},
+		},
+		{
+			name:  "name outside the spec's characters",
+			tools: []mcp.Tool{tool("go style", "Go style guide")},
+			want:  []string{`mcp: tool "go style" has a name that does not match ^[A-Za-z0-9_.-]+$`},
+		},
+		{
+			name:  "missing description",
+			tools: []mcp.Tool{tool("go_style", " ")},
+			want:  []string{`mcp: tool "go_style" has no description, so assistants cannot tell when to use it`},
+		},
+		{
+			name:  "input schema that is not an object",
+			tools: []mcp.Tool{mcp.NewToolWithRawSchema("go_style", "Go style guide", json.RawMessage(`{"type":"string"}`))},
+			want:  []string{`mcp: tool "go_style" has an input schema of type "string", but tool arguments must be an object`},
+		},
+		{
+			name:    "name too long for cursor",
+			tools:   []mcp.Tool{tool(strings.Repeat("a", 56), "Long")},
+			clients: []string{"cursor"},
+			want:    []string{fmt.Sprintf(`cursor: tool %q has a name of 56 characters, more than the 55 allowed; set a shorter name in its frontmatter`, strings.Repeat("a", 56))},
+		},
+		{
+			name:    "dots rejected by claude",
+			tools:   []mcp.Tool{tool("team.style", "Team style")},
+			clients: []string{"Claude"},
+			want:    []string{`claude: tool "team.style" has a name that does not match ^[a-zA-Z0-9_-]{1,64}$`},
+		},
+		{
+			name:    "description too long for openai",
+			tools:   []mcp.Tool{tool("go_style", strings.Repeat("x", 1025))},
+			clients: []string{"openai"},
+			want:    []string{`openai: tool "go_style" has a description of 1025 characters, more than the 1024 allowed`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := CheckToolCompliance(tt.tools, tt.clients)
+			if err != nil {
+				t.Fatalf("CheckToolCompliance() error = %v", err)
+			}
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("violations =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestCheckToolCompliance_MaxTools(t *testing.T) {
+	var tools []mcp.Tool
+	for i := range 41 {
+		tools = append(tools, mcp.NewTool(fmt.Sprintf("rule_%d", i), mcp.WithDescription("A rule")))
+	}
+	violations, err := CheckToolCompliance(tools, []string{"cursor", "copilot"})
+	if err != nil {
+		t.Fatalf("CheckToolCompliance() error = %v", err)
+	}
+	if len(violations) != 1 || violations[0].Client != "cursor" || violations[0].Tool != "" {
+		t.Errorf("expected a single cursor tool count violation, got %v", violations)
+	}
+}
+
+func TestCheckToolCompliance_UnknownClient(t *testing.T) {
+	_, err := CheckToolCompliance(nil, []string{"notepad"})
+	if err == nil || !strings.Contains(err.Error(), `"notepad"`) || !strings.Contains(err.Error(), "cursor") {
+		t.Errorf("expected an error naming the unknown and the known clients, got %v", err)
+	}
+}
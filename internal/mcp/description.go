@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Auto-generated descriptions
+//
+// A rule without a description is not registered as a tool. When the
+// auto_descriptions config flag is set, such a rule gets a description derived
+// from its first heading or, before any heading, its first paragraph. The
+// derived description ends with AutoDescriptionSuffix so assistants and users
+// can tell the author did not write it. A repository schema that requires a
+// description is only satisfied by a written one.
+
+// AutoDescriptionSuffix marks descriptions derived from a rule's body
+const AutoDescriptionSuffix = " (auto-generated)"
+
+// autoDescriptionMaxLength is the most characters taken from the body
+const autoDescriptionMaxLength = 200
+
+// markdownMarkup is stripped from derived descriptions
+var markdownMarkup = strings.NewReplacer("**", "", "__", "", "`", "")
+
+// deriveDescription returns the first markdown heading or paragraph of body,
+// skipping code blocks and HTML comments, or empty string when it has neither
+func deriveDescription(body []byte) string {
+	var paragraph []string
+	inFence, inComment := false, false
+	for line := range strings.SplitSeq(string(body), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case inComment:
+			inComment = !strings.Contains(line, "-->")
+			continue
+		case strings.HasPrefix(line, "<!--"):
+			inComment = !strings.Contains(line, "-->")
+			continue
+		case strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~"):
+			inFence = !inFence
+			if len(paragraph) > 0 {
+				return summarizeDescription(paragraph)
+			}
+			continue
+		case inFence:
+			continue
+		}
+
+		if heading, ok := strings.CutPrefix(line, "#"); ok {
+			if len(paragraph) > 0 {
+				return summarizeDescription(paragraph)
+			}
+			if heading = strings.TrimSpace(strings.TrimLeft(heading, "#")); heading != "" {
+				return summarizeDescription([]string{heading})
+			}
+			continue
+		}
+
+		if line == "" {
+			if len(paragraph) > 0 {
+				return summarizeDescription(paragraph)
+			}
+			continue
+		}
+		paragraph = append(paragraph, strings.TrimLeft(line, "-*> "))
+	}
+	return summarizeDescription(paragraph)
+}
+
+// summarizeDescription joins lines into one line without markdown emphasis,
+// shortened at a word boundary to autoDescriptionMaxLength characters
+func summarizeDescription(lines []string) string {
+	text := strings.Join(strings.Fields(markdownMarkup.Replace(strings.Join(lines, " "))), " ")
+	if utf8.RuneCountInString(text) <= autoDescriptionMaxLength {
+		return text
+	}
+
+	runes := []rune(text)[:autoDescriptionMaxLength]
+	cut := string(runes)
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	}
+	return strings.TrimRight(cut, " ,;:.") + "…"
+}
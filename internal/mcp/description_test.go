@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+)
+
+func TestDeriveDescription(t *testing.T) {
+	long := strings.Repeat("word ", 60)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "first heading", body: "\n## Go **style** guide\n\nUse gofmt.\n", want: "Go style guide"},
+		{name: "paragraph before heading", body: "Use `gofmt` on\nevery file.\n# Go\n", want: "Use gofmt on every file."},
+		{name: "skips code blocks", body: "```go\n# not a heading\n```\n# Testing\n", want: "Testing"},
+		{name: "skips HTML comments", body: "<!-- generated\nby a tool -->\n- Prefer table tests\n", want: "Prefer table tests"},
+		{name: "truncated at a word", body: long, want: strings.TrimSpace(strings.Repeat("word ", 40)) + "…"},
+		{name: "nothing to derive from", body: "\n```\ncode\n```\n", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deriveDescription([]byte(tt.body)); got != tt.want {
+				t.Errorf("deriveDescription() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoDescriptions(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	file := filemanager.FileItem{Name: "style.md", Path: "/rules/style.md", RepositoryID: "repo"}
+	content := []byte("---\ntags: [go]\n---\n# Go style\n")
+
+	processor := NewRuleFileProcessor(logger, nil, 1024)
+	if _, err := processor.newRuleFile(file, content); err == nil || !strings.Contains(err.Error(), "missing required 'description' field") {
+		t.Errorf("without auto_descriptions the rule should be rejected, got %v", err)
+	}
+
+	processor.autoDescriptions = true
+	rule, err := processor.newRuleFile(file, content)
+	if err != nil {
+		t.Fatalf("newRuleFile: %v", err)
+	}
+	if rule.Description != "Go style"+AutoDescriptionSuffix || !rule.DescriptionGenerated {
+		t.Errorf("expected a derived description, got %q (generated %v)", rule.Description, rule.DescriptionGenerated)
+	}
+
+	written, err := processor.newRuleFile(file, []byte("---\ndescription: Written\n---\n# Go style\n"))
+	if err != nil || written.Description != "Written" || written.DescriptionGenerated {
+		t.Errorf("a written description must be kept, got %+v (%v)", written, err)
+	}
+
+	processor.manifests["repo"] = &repository.Manifest{Schema: repository.ManifestSchema{Required: []string{"description"}}}
+	if _, err := processor.newRuleFile(file, content); err == nil {
+		t.Error("a derived description must not satisfy a manifest that requires one")
+	}
+}
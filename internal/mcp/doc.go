@@ -2,8 +2,11 @@
 //
 // This package implements an MCP server that allows AI assistants to interact with
 // rulem's rule management capabilities through a standardized protocol. The server
-// provides the rules in the central rule files repo as tools.
-// It only adds rule files that have frontmatter with a description field.
+// provides the rules in the central rule files repo as tools, and as resources at
+// rulem://<repository-id>/<path>.
+// It only adds rule files that have frontmatter with a description field. Frontmatter
+// may be YAML (---), TOML (+++) or JSON (;;;), optionally preceded by a BOM or HTML
+// comments; the recognised delimiters can be overridden in the configuration.
 //
 // # Implementation
 //
@@ -31,7 +34,11 @@
 //	rulem mcp
 //
 // The server will read JSON-RPC requests from stdin and write responses to stdout
-// until it receives EOF or is terminated.
+// until it receives EOF or is terminated. With --transport http or sse it runs as
+// a daemon serving several clients over HTTP instead, see transport.go.
+//
+// With --socket, the registered rules are also served over a local unix socket
+// as newline-delimited JSON (list, get and search), see socket.go.
 //
 // # Architecture
 //
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Tool filters
+//
+// A project-specific MCP configuration rarely wants every rule of a large
+// collection. `rulem mcp --tags backend,go --scope "src/**"` registers only
+// the rules that carry one of the tags and whose scope globs can match files
+// under the given globs. Rules without a scope apply to every file, so a scope
+// filter keeps them; rules without tags do not pass a tag filter. Filtered
+// rules are not registered at all, so they take no tool name, resource or
+// search result.
+
+// ToolFilter selects the rule files registered as tools. The zero value
+// registers every rule.
+type ToolFilter struct {
+	Tags  []string // A rule must have one of these tags, ignoring case
+	Scope []string // A rule's scope must overlap one of these globs
+}
+
+// NewToolFilter builds a filter from the --tags and --scope flags, dropping
+// empty values and rejecting invalid globs
+func NewToolFilter(tags, scope []string) (ToolFilter, error) {
+	var filter ToolFilter
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && !slices.Contains(filter.Tags, tag) {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	for _, glob := range scope {
+		glob = strings.TrimSpace(glob)
+		if glob == "" {
+			continue
+		}
+		if _, err := path.Match(glob, ""); err != nil {
+			return ToolFilter{}, fmt.Errorf("invalid scope glob %q: %w", glob, err)
+		}
+		filter.Scope = append(filter.Scope, glob)
+	}
+	return filter, nil
+}
+
+// Empty reports whether the filter registers every rule
+func (f ToolFilter) Empty() bool {
+	return len(f.Tags) == 0 && len(f.Scope) == 0
+}
+
+// Match reports whether ruleFile passes the filter
+func (f ToolFilter) Match(ruleFile *RuleFile) bool {
+	if len(f.Tags) > 0 && !slices.ContainsFunc(ruleFile.Tags, func(tag string) bool {
+		return slices.Contains(f.Tags, strings.ToLower(strings.TrimSpace(tag)))
+	}) {
+		return false
+	}
+	if len(f.Scope) == 0 || len(ruleFile.Scope) == 0 {
+		return true
+	}
+	for _, glob := range ruleFile.Scope {
+		for _, wanted := range f.Scope {
+			if globsOverlap(glob, wanted) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EnableToolFilter registers only the rules that pass filter. Call it before
+// Start; it also applies when rules are reloaded.
+func (s *Server) EnableToolFilter(filter ToolFilter) {
+	s.toolFilter = filter
+}
+
+// globsOverlap reports whether some path could match both globs, with "**"
+// matching any number of directories and a glob without "/" matching base
+// names anywhere, like the globs of .gitignore. Segments with wildcards on
+// both sides are compared by their literal prefix and suffix, which may keep
+// a rule that is not strictly needed but never drops one that is.
+func globsOverlap(a, b string) bool {
+	return segmentsOverlap(globSegments(a), globSegments(b))
+}
+
+// globSegments splits a glob into path segments
+func globSegments(glob string) []string {
+	glob = strings.Trim(path.Clean("/"+glob), "/")
+	if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	}
+	return strings.Split(glob, "/")
+}
+
+// segmentsOverlap reports whether some path matches both segment lists
+func segmentsOverlap(a, b []string) bool {
+	switch {
+	case len(a) > 0 && a[0] == "**":
+		return segmentsOverlap(a[1:], b) || (len(b) > 0 && segmentsOverlap(a, b[1:]))
+	case len(b) > 0 && b[0] == "**":
+		return segmentsOverlap(a, b[1:]) || (len(a) > 0 && segmentsOverlap(a[1:], b))
+	case len(a) == 0 || len(b) == 0:
+		return len(a) == len(b)
+	}
+	return segmentOverlap(a[0], b[0]) && segmentsOverlap(a[1:], b[1:])
+}
+
+// segmentOverlap reports whether some name matches both segments
+func segmentOverlap(a, b string) bool {
+	const meta = `*?[\`
+	if !strings.ContainsAny(a, meta) {
+		ok, _ := path.Match(b, a)
+		return ok
+	}
+	if !strings.ContainsAny(b, meta) {
+		ok, _ := path.Match(a, b)
+		return ok
+	}
+	// The literal text before the first wildcard and after the last one,
+	// counting the end of a character class as a wildcard
+	prefixA, prefixB := a[:strings.IndexAny(a, meta)], b[:strings.IndexAny(b, meta)]
+	suffixA, suffixB := a[strings.LastIndexAny(a, `*?]\`)+1:], b[strings.LastIndexAny(b, `*?]\`)+1:]
+	return (strings.HasPrefix(prefixA, prefixB) || strings.HasPrefix(prefixB, prefixA)) &&
+		(strings.HasSuffix(suffixA, suffixB) || strings.HasSuffix(suffixB, suffixA))
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestGlobsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "**/*.go", b: "src/**", want: true},
+		{a: "*.go", b: "src/**", want: true},
+		{a: "src/api/**", b: "src/**", want: true},
+		{a: "src/**/*.ts", b: "src/web/app.ts", want: true},
+		{a: "cmd/*/main.go", b: "cmd/rulem/**", want: true},
+		{a: "x[ab]", b: "*b", want: true},
+		{a: "docs/**", b: "src/**", want: false},
+		{a: "**/*.ts", b: "**/*.go", want: false},
+		{a: "src/*.go", b: "src/api/**", want: false},
+	}
+	for _, tt := range tests {
+		if got := globsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("globsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := globsOverlap(tt.b, tt.a); got != tt.want {
+			t.Errorf("globsOverlap(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestToolFilter_Match(t *testing.T) {
+	filter, err := NewToolFilter([]string{"Backend", " go", ""}, []string{"src/**"})
+	if err != nil {
+		t.Fatalf("NewToolFilter: %v", err)
+	}
+	if !slices.Equal(filter.Tags, []string{"backend", "go"}) {
+		t.Errorf("tags = %v, want them trimmed and lowercased", filter.Tags)
+	}
+
+	tests := []struct {
+		name string
+		rule RuleFile
+		want bool
+	}{
+		{name: "tag and scope", rule: RuleFile{Tags: []string{"GO"}, Scope: []string{"src/**/*.go"}}, want: true},
+		{name: "no scope applies everywhere", rule: RuleFile{Tags: []string{"backend"}}, want: true},
+		{name: "other tags", rule: RuleFile{Tags: []string{"python"}}, want: false},
+		{name: "no tags", rule: RuleFile{}, want: false},
+		{name: "scope elsewhere", rule: RuleFile{Tags: []string{"go"}, Scope: []string{"docs/**"}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Match(&tt.rule); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !(ToolFilter{}).Match(&RuleFile{}) {
+		t.Error("the zero filter should match every rule")
+	}
+	if _, err := NewToolFilter(nil, []string{"src/[a-"}); err == nil {
+		t.Error("expected an invalid scope glob to be rejected")
+	}
+}
+
+func TestRegisterRuleFiles_Filter(t *testing.T) {
+	processor, tempDir, _ := createTestRuleFileProcessor(t)
+	defer os.RemoveAll(tempDir)
+	processor.filter = ToolFilter{Tags: []string{"go"}}
+
+	processor.registerRuleFiles([]RuleFile{
+		{FileName: "style.md", Description: "Python style", Tags: []string{"python"}},
+		{FileName: "style.md", Description: "Go style", Tags: []string{"go"}},
+	})
+	tool, ok := processor.toolRegistry["style"]
+	if len(processor.toolRegistry) != 1 || !ok || tool.RuleFile.Description != "Go style" {
+		t.Errorf("registry = %v, want only the Go rule, named without a suffix", processor.toolRegistry)
+	}
+}
@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"rulem/internal/filemanager"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule formats
+//
+// Rules are usually markdown with a frontmatter block, but the extensions of
+// the files scanned for rules are configurable (rule_extensions in
+// config.yaml, see filemanager.UseRuleExtensions), and how a rule's
+// frontmatter is extracted depends on its extension. YAML and JSON files are
+// documents whose top-level keys are the frontmatter and whose content key
+// holds the rule itself:
+//
+//	description: API design guidelines
+//	tags: [api]
+//	content: |
+//	  Prefer plural resource names.
+//
+// Every other file, markdown, .mdc or plain text, may start with one of the
+// frontmatter blocks the processor recognises.
+
+// RuleContentKey is the key of a YAML or JSON rule document that holds the rule
+const RuleContentKey = "content"
+
+// documentSyntaxes maps the extensions of rule files that are whole YAML or
+// JSON documents to their syntax
+var documentSyntaxes = map[string]string{
+	".yaml": FrontmatterSyntaxYAML,
+	".yml":  FrontmatterSyntaxYAML,
+	".json": FrontmatterSyntaxJSON,
+}
+
+// documentSyntax returns the syntax of the rule file fileName when it is a
+// YAML or JSON document rather than a file with a frontmatter block
+func documentSyntax(fileName string) (string, bool) {
+	syntax, ok := documentSyntaxes[strings.ToLower(filepath.Ext(fileName))]
+	return syntax, ok
+}
+
+// acceptsFile reports whether fileName has one of the extensions rules are
+// read from
+func (p *RuleFileProcessor) acceptsFile(fileName string) bool {
+	return slices.Contains(p.extensions, filemanager.NormalizeRuleExtension(filepath.Ext(fileName)))
+}
+
+// extensionError explains why the rule file fileName is not read
+func (p *RuleFileProcessor) extensionError(fileName string) error {
+	return fmt.Errorf("%q is not one of the rule extensions (%s)", filepath.Ext(fileName), strings.Join(p.extensions, ", "))
+}
+
+// parseRuleFrontmatter decodes the frontmatter of the rule file fileName into
+// v and returns the body, the way the file's extension calls for. Content
+// without frontmatter leaves v unchanged.
+func (p *RuleFileProcessor) parseRuleFrontmatter(content []byte, fileName string, v any) ([]byte, error) {
+	switch syntax, _ := documentSyntax(fileName); syntax {
+	case FrontmatterSyntaxYAML:
+		return parseYAMLRuleDocument(content, v)
+	case FrontmatterSyntaxJSON:
+		return parseJSONRuleDocument(content, v)
+	default:
+		return parseFrontmatter(content, v, p.frontmatterFormats)
+	}
+}
+
+// parseYAMLRuleDocument decodes the keys of a YAML rule document, but for its
+// content, into v and returns its content as the body
+func parseYAMLRuleDocument(content []byte, v any) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(bytes.TrimPrefix(content, utf8BOM), &doc); err != nil {
+		return nil, fmt.Errorf("cannot parse YAML rule: %w", err)
+	}
+	if doc.Kind == 0 {
+		// An empty file
+		return nil, nil
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("a YAML rule must be a set of keys")
+	}
+
+	mapping := doc.Content[0]
+	var body string
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != RuleContentKey {
+			continue
+		}
+		if err := mapping.Content[i+1].Decode(&body); err != nil {
+			return nil, fmt.Errorf("the %s key of a YAML rule must be text: %w", RuleContentKey, err)
+		}
+		mapping.Content = slices.Delete(mapping.Content, i, i+2)
+		break
+	}
+	if err := mapping.Decode(v); err != nil {
+		return nil, fmt.Errorf("cannot parse YAML rule: %w", err)
+	}
+	return []byte(body), nil
+}
+
+// parseJSONRuleDocument decodes the keys of a JSON rule document, but for its
+// content, into v and returns its content as the body
+func parseJSONRuleDocument(content []byte, v any) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(bytes.TrimPrefix(content, utf8BOM), &doc); err != nil {
+		return nil, fmt.Errorf("cannot parse JSON rule: %w", err)
+	}
+
+	var body string
+	if raw, ok := doc[RuleContentKey]; ok {
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return nil, fmt.Errorf("the %s key of a JSON rule must be text: %w", RuleContentKey, err)
+		}
+		delete(doc, RuleContentKey)
+	}
+	// Plain data decoded a moment ago, so encoding cannot fail
+	matter, _ := json.Marshal(doc)
+	if err := json.Unmarshal(matter, v); err != nil {
+		return nil, fmt.Errorf("cannot parse JSON rule: %w", err)
+	}
+	return []byte(body), nil
+}
@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+)
+
+func TestParseRuleFrontmatterByExtension(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	processor := NewRuleFileProcessor(logger, nil, 1024)
+
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		want     RuleFrontmatter
+		wantBody string
+		wantErr  string
+	}{
+		{
+			name:     "markdown frontmatter",
+			fileName: "style.md",
+			content:  "---\ndescription: Style\n---\n# Style\n",
+			want:     RuleFrontmatter{Description: "Style"},
+			wantBody: "# Style\n",
+		},
+		{
+			name:     "plain text frontmatter",
+			fileName: "review.TXT",
+			content:  "---\ndescription: Review\ntags: [review]\n---\nCheck the tests.\n",
+			want:     RuleFrontmatter{Description: "Review", Tags: []string{"review"}},
+			wantBody: "Check the tests.\n",
+		},
+		{
+			name:     "yaml document",
+			fileName: "api.yaml",
+			content:  "\ufeffdescription: API design\nexpires: 2030-01-31\ncontent: |\n  Prefer plural resource names.\n",
+			want:     RuleFrontmatter{Description: "API design", Expires: "2030-01-31"},
+			wantBody: "Prefer plural resource names.\n",
+		},
+		{
+			name:     "yaml document without content",
+			fileName: "empty.yml",
+			content:  "description: Nothing yet\n",
+			want:     RuleFrontmatter{Description: "Nothing yet"},
+		},
+		{
+			name:     "yaml list",
+			fileName: "list.yaml",
+			content:  "- description: A\n",
+			wantErr:  "must be a set of keys",
+		},
+		{
+			name:     "yaml content that is not text",
+			fileName: "nested.yaml",
+			content:  "description: Nested\ncontent:\n  text: body\n",
+			wantErr:  "must be text",
+		},
+		{
+			name:     "json document",
+			fileName: "api.json",
+			content:  `{"description": "API design", "tags": ["api"], "content": "Prefer plural resource names."}`,
+			want:     RuleFrontmatter{Description: "API design", Tags: []string{"api"}},
+			wantBody: "Prefer plural resource names.",
+		},
+		{
+			name:     "invalid json",
+			fileName: "broken.json",
+			content:  `{"description": `,
+			wantErr:  "cannot parse JSON rule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matter RuleFrontmatter
+			body, err := processor.parseRuleFrontmatter([]byte(tt.content), tt.fileName, &matter)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRuleFrontmatter returned error: %v", err)
+			}
+			if !reflect.DeepEqual(matter, tt.want) {
+				t.Errorf("frontmatter = %+v, want %+v", matter, tt.want)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestFrontmatterFieldsOfRuleDocument(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	processor := NewRuleFileProcessor(logger, nil, 1024)
+
+	fields, err := processor.FrontmatterFields([]byte("tags: [api]\ndescription: API\ncontent: body\n"), "api.yaml")
+	if err != nil {
+		t.Fatalf("FrontmatterFields returned error: %v", err)
+	}
+	if want := []string{"description", "tags"}; !slices.Equal(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+}
+
+func TestEditFrontmatterOfRuleDocument(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	processor := NewRuleFileProcessor(logger, nil, 1024)
+
+	got, err := processor.EditFrontmatter([]byte("description: old\ncontent: |\n  Prefer plural resource names.\n"), "api.yaml", "API design", []string{"api"})
+	if err != nil {
+		t.Fatalf("EditFrontmatter returned error: %v", err)
+	}
+	want := "description: API design\ncontent: |\n  Prefer plural resource names.\ntags: [api]\n"
+	if string(got) != want {
+		t.Errorf("EditFrontmatter() = %q, want %q", got, want)
+	}
+
+	if _, err := processor.EditFrontmatter([]byte(`{"description": "old"}`), "api.json", "new", nil); err == nil || !strings.Contains(err.Error(), "only YAML") {
+		t.Errorf("expected JSON rules not to be editable, got %v", err)
+	}
+}
+
+func TestProcessRuleFilesWithRuleExtensions(t *testing.T) {
+	processor, tempDir, _ := createTestRuleFileProcessor(t)
+	defer os.RemoveAll(tempDir)
+	repoID := createTestConfigWithPath(tempDir).Repositories[0].ID
+
+	rules := map[string]string{
+		"style.md":   "---\ndescription: Style\n---\n# Style\n",
+		"review.txt": "---\ndescription: Review\n---\nCheck the tests.\n",
+		"api.yaml":   "description: API design\ncontent: Prefer plural resource names.\n",
+	}
+	var files []filemanager.FileItem
+	for _, name := range []string{"api.yaml", "review.txt", "style.md"} {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(rules[name]), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+		files = append(files, filemanager.FileItem{Name: name, Path: path, RepositoryID: repoID})
+	}
+
+	ruleNames := func() []string {
+		t.Helper()
+		ruleFiles, err := processor.ParseRuleFiles(files)
+		if err != nil {
+			t.Fatalf("ParseRuleFiles returned error: %v", err)
+		}
+		var names []string
+		for _, ruleFile := range ruleFiles {
+			names = append(names, ruleFile.FileName)
+		}
+		return names
+	}
+
+	// Only markdown is read by default
+	if got, want := ruleNames(), []string{"style.md"}; !slices.Equal(got, want) {
+		t.Errorf("rules = %v, want %v", got, want)
+	}
+
+	processor.extensions = []string{".md", ".txt", ".yaml"}
+	if got, want := ruleNames(), []string{"api.yaml", "review.txt", "style.md"}; !slices.Equal(got, want) {
+		t.Errorf("rules = %v, want %v", got, want)
+	}
+	ruleFiles, _ := processor.ParseRuleFiles(files[:1])
+	if len(ruleFiles) != 1 || ruleFiles[0].Content != "Prefer plural resource names." {
+		t.Errorf("YAML rule = %+v, want its content key as the content", ruleFiles)
+	}
+}
@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"rulem/internal/config"
+
+	"github.com/BurntSushi/toml"
+	"github.com/adrg/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter syntaxes accepted in config.FrontmatterDelimiter.Syntax
+const (
+	FrontmatterSyntaxYAML = "yaml"
+	FrontmatterSyntaxTOML = "toml"
+	FrontmatterSyntaxJSON = "json"
+)
+
+// utf8BOM is the byte order mark some editors (notably on Windows) prepend to UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// defaultFrontmatterDelimiters returns the delimiters used when none are configured:
+// YAML (---), TOML (+++) and JSON (;;;).
+func defaultFrontmatterDelimiters() []config.FrontmatterDelimiter {
+	return []config.FrontmatterDelimiter{
+		{Start: "---", End: "---", Syntax: FrontmatterSyntaxYAML},
+		{Start: "+++", End: "+++", Syntax: FrontmatterSyntaxTOML},
+		{Start: ";;;", End: ";;;", Syntax: FrontmatterSyntaxJSON},
+	}
+}
+
+// buildFrontmatterFormats converts delimiter definitions into parser formats.
+//
+// A JSON delimiter with Start "{" and End "}" selects the bare-object form: the
+// braces are part of the JSON and the object must be followed by a blank line.
+// It is not enabled by default because rule bodies often begin with a code or
+// JSON snippet.
+func buildFrontmatterFormats(delimiters []config.FrontmatterDelimiter) ([]*frontmatter.Format, error) {
+	if len(delimiters) == 0 {
+		return nil, fmt.Errorf("at least one frontmatter delimiter is required")
+	}
+
+	formats := make([]*frontmatter.Format, 0, len(delimiters))
+	for _, d := range delimiters {
+		start := strings.TrimSpace(d.Start)
+		end := strings.TrimSpace(d.End)
+		if start == "" || end == "" {
+			return nil, fmt.Errorf("frontmatter delimiters cannot be empty (start: %q, end: %q)", d.Start, d.End)
+		}
+
+		syntax := strings.ToLower(strings.TrimSpace(d.Syntax))
+
+		var unmarshal frontmatter.UnmarshalFunc
+		switch syntax {
+		case FrontmatterSyntaxYAML:
+			unmarshal = yaml.Unmarshal
+		case FrontmatterSyntaxTOML:
+			unmarshal = toml.Unmarshal
+		case FrontmatterSyntaxJSON:
+			unmarshal = json.Unmarshal
+		default:
+			return nil, fmt.Errorf("unsupported frontmatter syntax %q for delimiter %q (must be %q, %q or %q)",
+				d.Syntax, start, FrontmatterSyntaxYAML, FrontmatterSyntaxTOML, FrontmatterSyntaxJSON)
+		}
+
+		format := frontmatter.NewFormat(start, end, unmarshal)
+		if start == "{" && end == "}" && syntax == FrontmatterSyntaxJSON {
+			format.UnmarshalDelims = true
+			format.RequiresNewLine = true
+		}
+		formats = append(formats, format)
+	}
+
+	return formats, nil
+}
+
+// stripFrontmatterPreamble removes content that may precede a frontmatter block
+// but would stop it from being detected: a UTF-8 byte order mark, blank lines,
+// and HTML comments (e.g. licence headers or "generated by" banners).
+//
+// An unterminated comment is left in place so the file is reported as having no
+// frontmatter rather than silently losing content.
+func stripFrontmatterPreamble(content []byte) []byte {
+	content = bytes.TrimPrefix(content, utf8BOM)
+
+	for {
+		trimmed := bytes.TrimLeft(content, " \t\r\n")
+		if !bytes.HasPrefix(trimmed, []byte("<!--")) {
+			return trimmed
+		}
+
+		end := bytes.Index(trimmed, []byte("-->"))
+		if end == -1 {
+			return trimmed
+		}
+		content = trimmed[end+len("-->"):]
+	}
+}
+
+// parseFrontmatter decodes the frontmatter of content into v using the given
+// formats and returns the remaining body. If no frontmatter is present, v is left
+// unchanged and the (preamble-stripped) content is returned as the body.
+func parseFrontmatter(content []byte, v any, formats []*frontmatter.Format) ([]byte, error) {
+	return frontmatter.Parse(bytes.NewReader(stripFrontmatterPreamble(content)), v, formats...)
+}
+
+// frontmatterLines locates the frontmatter delimiters the same way parseFrontmatter
+// detects them and returns their 0-based line numbers in the original content.
+// Bare-object JSON frontmatter, whose braces are part of the data, is not reported.
+func frontmatterLines(content []byte, formats []*frontmatter.Format) (start, end int, ok bool) {
+	stripped := stripFrontmatterPreamble(content)
+	start = bytes.Count(content[:len(content)-len(stripped)], []byte("\n"))
+
+	lines := strings.Split(string(stripped), "\n")
+	first := strings.TrimSpace(lines[0])
+	for _, format := range formats {
+		if format.UnmarshalDelims || first != format.Start {
+			continue
+		}
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == format.End {
+				return start, start + i, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// EditFrontmatter returns the rule file fileName with the description and
+// tags of its YAML frontmatter replaced, keeping every other key and the body
+// as they are. Content without frontmatter gets a new YAML block; a YAML rule
+// document has its keys edited instead. Empty tags remove the tags key. TOML
+// and JSON frontmatter cannot be edited.
+func (p *RuleFileProcessor) EditFrontmatter(content []byte, fileName, description string, tags []string) ([]byte, error) {
+	if syntax, ok := documentSyntax(fileName); ok {
+		if syntax != FrontmatterSyntaxYAML {
+			return nil, fmt.Errorf("only YAML frontmatter can be edited")
+		}
+		block, err := editYAMLFrontmatter(bytes.TrimPrefix(content, utf8BOM), description, tags)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(block), nil
+	}
+
+	start, end, ok := p.FrontmatterLines(content)
+	if !ok {
+		// Bare-object JSON frontmatter is not reported by FrontmatterLines
+		fields, err := p.FrontmatterFields(content, fileName)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse frontmatter: %w", err)
+		}
+		if len(fields) > 0 {
+			return nil, fmt.Errorf("only YAML frontmatter can be edited")
+		}
+		block, err := editYAMLFrontmatter(nil, description, tags)
+		if err != nil {
+			return nil, err
+		}
+		body := bytes.TrimLeft(bytes.TrimPrefix(content, utf8BOM), "\r\n")
+		return append([]byte("---\n"+block+"---\n\n"), body...), nil
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	if strings.TrimSpace(lines[start]) != "---" {
+		return nil, fmt.Errorf("only YAML frontmatter can be edited")
+	}
+	block, err := editYAMLFrontmatter([]byte(strings.Join(lines[start+1:end], "")), description, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(lines[:start+1], ""))
+	b.WriteString(block)
+	b.WriteString(strings.Join(lines[end:], ""))
+	return []byte(b.String()), nil
+}
+
+// editYAMLFrontmatter sets description and tags in a YAML frontmatter block,
+// given without its delimiters, and returns the re-encoded block
+func editYAMLFrontmatter(block []byte, description string, tags []string) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(block, &doc); err != nil {
+		return "", fmt.Errorf("cannot parse frontmatter: %w", err)
+	}
+
+	var mapping *yaml.Node
+	switch {
+	case doc.Kind == 0:
+		// An empty block
+		mapping = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{mapping}}
+	case doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 && doc.Content[0].Kind == yaml.MappingNode:
+		mapping = doc.Content[0]
+	default:
+		return "", fmt.Errorf("frontmatter is not a set of keys")
+	}
+
+	// Tagging values as strings makes the encoder quote ones like "true"
+	setYAMLKey(mapping, "description", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: description})
+	if len(tags) == 0 {
+		deleteYAMLKey(mapping, "tags")
+	} else {
+		list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		for _, tag := range tags {
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tag})
+		}
+		setYAMLKey(mapping, "tags", list)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", fmt.Errorf("cannot write frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("cannot write frontmatter: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// setYAMLKey replaces the value of key in a mapping node, appending the key
+// when it is not set
+func setYAMLKey(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// deleteYAMLKey removes key and its value from a mapping node
+func deleteYAMLKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
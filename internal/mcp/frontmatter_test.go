@@ -0,0 +1,352 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+)
+
+func TestParseFrontmatterDefaults(t *testing.T) {
+	formats, err := buildFrontmatterFormats(defaultFrontmatterDelimiters())
+	if err != nil {
+		t.Fatalf("default delimiters should build: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		content         string
+		wantDescription string
+		wantApplyTo     string
+		wantBody        string
+	}{
+		{
+			name:            "yaml",
+			content:         "---\ndescription: YAML rule\napplyTo: Go\n---\n# Body\n",
+			wantDescription: "YAML rule",
+			wantApplyTo:     "Go",
+			wantBody:        "# Body",
+		},
+		{
+			name:            "toml",
+			content:         "+++\ndescription = \"TOML rule\"\napplyTo = \"Hugo sites\"\n+++\n# Body\n",
+			wantDescription: "TOML rule",
+			wantApplyTo:     "Hugo sites",
+			wantBody:        "# Body",
+		},
+		{
+			name:            "json",
+			content:         ";;;\n{\"description\": \"JSON rule\", \"applyTo\": \"web\"}\n;;;\n# Body\n",
+			wantDescription: "JSON rule",
+			wantApplyTo:     "web",
+			wantBody:        "# Body",
+		},
+		{
+			name:            "utf-8 bom",
+			content:         "\ufeff---\ndescription: BOM rule\n---\n# Body\n",
+			wantDescription: "BOM rule",
+			wantBody:        "# Body",
+		},
+		{
+			name:            "leading html comments",
+			content:         "<!-- generated by sitegen -->\n\n<!--\n  licence header\n-->\n---\ndescription: Commented rule\n---\n# Body\n",
+			wantDescription: "Commented rule",
+			wantBody:        "# Body",
+		},
+		{
+			name:     "unterminated comment is not skipped",
+			content:  "<!-- oops\n---\ndescription: Hidden\n---\n# Body\n",
+			wantBody: "<!-- oops",
+		},
+		{
+			name:     "bare json object is not frontmatter by default",
+			content:  "{\n  \"description\": \"Snippet\"\n}\n\n# Body\n",
+			wantBody: "{",
+		},
+		{
+			name:     "no frontmatter",
+			content:  "# Just a heading\n",
+			wantBody: "# Just a heading",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matter RuleFrontmatter
+			body, err := parseFrontmatter([]byte(tt.content), &matter, formats)
+			if err != nil {
+				t.Fatalf("parseFrontmatter returned error: %v", err)
+			}
+			if matter.Description != tt.wantDescription {
+				t.Errorf("description = %q, want %q", matter.Description, tt.wantDescription)
+			}
+			if matter.ApplyTo != tt.wantApplyTo {
+				t.Errorf("applyTo = %q, want %q", matter.ApplyTo, tt.wantApplyTo)
+			}
+			if !strings.HasPrefix(strings.TrimSpace(string(body)), tt.wantBody) {
+				t.Errorf("body = %q, want prefix %q", string(body), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestParseFrontmatterBareJSONOptIn(t *testing.T) {
+	formats, err := buildFrontmatterFormats([]config.FrontmatterDelimiter{{Start: "{", End: "}", Syntax: "json"}})
+	if err != nil {
+		t.Fatalf("bare JSON delimiter should build: %v", err)
+	}
+
+	var matter RuleFrontmatter
+	body, err := parseFrontmatter([]byte("{\n  \"description\": \"Bare JSON rule\"\n}\n\n# Body\n"), &matter, formats)
+	if err != nil {
+		t.Fatalf("parseFrontmatter returned error: %v", err)
+	}
+	if matter.Description != "Bare JSON rule" {
+		t.Errorf("description = %q, want %q", matter.Description, "Bare JSON rule")
+	}
+	if strings.TrimSpace(string(body)) != "# Body" {
+		t.Errorf("body = %q, want %q", string(body), "# Body")
+	}
+}
+
+func TestBuildFrontmatterFormatsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		delimiters []config.FrontmatterDelimiter
+	}{
+		{
+			name:       "no delimiters",
+			delimiters: nil,
+		},
+		{
+			name:       "empty start",
+			delimiters: []config.FrontmatterDelimiter{{Start: "", End: "---", Syntax: "yaml"}},
+		},
+		{
+			name:       "empty end",
+			delimiters: []config.FrontmatterDelimiter{{Start: "---", End: "  ", Syntax: "yaml"}},
+		},
+		{
+			name:       "unknown syntax",
+			delimiters: []config.FrontmatterDelimiter{{Start: "===", End: "===", Syntax: "ini"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildFrontmatterFormats(tt.delimiters); err == nil {
+				t.Error("expected error for invalid delimiters")
+			}
+		})
+	}
+}
+
+func TestNewRuleFileProcessorWithDelimiters(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	paths := map[string]string{"test-repo-123456": t.TempDir()}
+
+	_, err := NewRuleFileProcessorWithDelimiters(logger, paths, 5*1024*1024,
+		[]config.FrontmatterDelimiter{{Start: "~~~", End: "~~~", Syntax: "xml"}})
+	if err == nil {
+		t.Error("expected error for unsupported syntax")
+	}
+
+	processor, err := NewRuleFileProcessorWithDelimiters(logger, paths, 5*1024*1024,
+		[]config.FrontmatterDelimiter{{Start: "~~~", End: "~~~", Syntax: "yaml"}})
+	if err != nil {
+		t.Fatalf("NewRuleFileProcessorWithDelimiters returned error: %v", err)
+	}
+	if len(processor.frontmatterFormats) != 1 {
+		t.Errorf("expected exactly the configured delimiter, got %d formats", len(processor.frontmatterFormats))
+	}
+}
+
+func TestServer_ConfiguredFrontmatterDelimiters(t *testing.T) {
+	files := map[string]string{
+		"custom.md": "~~~\ndescription: Custom delimiters\nname: custom_rule\n~~~\n# Custom\n",
+		"yaml.md":   "---\ndescription: Default delimiters\nname: yaml_rule\n---\n# YAML\n",
+	}
+
+	tests := []struct {
+		name       string
+		delimiters []config.FrontmatterDelimiter
+		wantTools  []string
+	}{
+		{
+			name:      "defaults when unset",
+			wantTools: []string{"yaml_rule"},
+		},
+		{
+			name:       "configured delimiters replace defaults",
+			delimiters: []config.FrontmatterDelimiter{{Start: "~~~", End: "~~~", Syntax: "yaml"}},
+			wantTools:  []string{"custom_rule"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := createTestServerWithFiles(t, files)
+			server.config.FrontmatterDelimiters = tt.delimiters
+
+			if err := server.InitializeComponents(); err != nil {
+				t.Fatalf("Failed to initialize server components: %v", err)
+			}
+
+			repoFiles, err := server.getRepoFiles()
+			if err != nil {
+				t.Fatalf("getRepoFiles returned error: %v", err)
+			}
+
+			tools, err := server.ruleProcessor.ProcessRuleFiles(repoFiles)
+			if err != nil {
+				t.Fatalf("ProcessRuleFiles returned error: %v", err)
+			}
+
+			if len(tools) != len(tt.wantTools) {
+				t.Errorf("expected %d tools, got %d", len(tt.wantTools), len(tools))
+			}
+			for _, name := range tt.wantTools {
+				tool, ok := tools[name]
+				if !ok {
+					t.Errorf("expected tool %s to be registered", name)
+					continue
+				}
+				if strings.Contains(tool.RuleFile.Content, "~~~") || strings.Contains(tool.RuleFile.Content, "description:") {
+					t.Errorf("tool %s content should not include frontmatter, got %q", name, tool.RuleFile.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_InvalidFrontmatterDelimiters(t *testing.T) {
+	server, _ := createTestServer(t)
+	server.config.FrontmatterDelimiters = []config.FrontmatterDelimiter{{Start: "~~~", End: "", Syntax: "yaml"}}
+
+	if err := server.InitializeComponents(); err == nil {
+		t.Error("expected InitializeComponents to fail for invalid frontmatter delimiters")
+	}
+}
+
+func TestProcessRuleFileTOML(t *testing.T) {
+	processor, tempDir, _ := createTestRuleFileProcessor(t)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "hugo.md")
+	content := "<!-- synced from docs site -->\n+++\ndescription = \"Hugo rule\"\n+++\n# Hugo\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	repoID := createTestConfigWithPath(tempDir).Repositories[0].ID
+	ruleFile, err := processor.processRuleFile(filemanager.FileItem{Name: "hugo.md", Path: filePath, RepositoryID: repoID})
+	if err != nil {
+		t.Fatalf("processRuleFile returned error: %v", err)
+	}
+	if ruleFile.Description != "Hugo rule" {
+		t.Errorf("description = %q, want %q", ruleFile.Description, "Hugo rule")
+	}
+	if strings.TrimSpace(ruleFile.Content) != "# Hugo" {
+		t.Errorf("content = %q, want %q", ruleFile.Content, "# Hugo")
+	}
+}
+
+func TestFrontmatterLines(t *testing.T) {
+	formats, err := buildFrontmatterFormats(defaultFrontmatterDelimiters())
+	if err != nil {
+		t.Fatalf("default delimiters should build: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		content   string
+		wantStart int
+		wantEnd   int
+		wantOK    bool
+	}{
+		{name: "yaml", content: "---\ndescription: x\n---\n# Body\n", wantStart: 0, wantEnd: 2, wantOK: true},
+		{name: "toml after comment", content: "<!-- generated -->\n\n+++\ndescription = \"x\"\ntags = []\n+++\n", wantStart: 2, wantEnd: 5, wantOK: true},
+		{name: "unterminated", content: "---\ndescription: x\n", wantOK: false},
+		{name: "no frontmatter", content: "# Body\n---\n", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := frontmatterLines([]byte(tt.content), formats)
+			if ok != tt.wantOK || (ok && (start != tt.wantStart || end != tt.wantEnd)) {
+				t.Errorf("frontmatterLines() = (%d, %d, %v), want (%d, %d, %v)", start, end, ok, tt.wantStart, tt.wantEnd, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEditFrontmatter(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	processor := NewRuleFileProcessor(logger, nil, 1024)
+
+	tests := []struct {
+		name        string
+		content     string
+		description string
+		tags        []string
+		want        string
+		wantErr     string
+	}{
+		{
+			name:        "adds frontmatter",
+			content:     "\n# Go style\nUse gofmt.\n",
+			description: "Go style",
+			tags:        []string{"go", "style"},
+			want:        "---\ndescription: Go style\ntags: [go, style]\n---\n\n# Go style\nUse gofmt.\n",
+		},
+		{
+			name:        "keeps other keys and the body",
+			content:     "<!-- header -->\n---\nname: go_style\ndescription: old\ntags: [old]\napplyTo: \"**/*.go\"\n---\n# Go style\n",
+			description: "New description",
+			want:        "<!-- header -->\n---\nname: go_style\ndescription: New description\napplyTo: \"**/*.go\"\n---\n# Go style\n",
+		},
+		{
+			name:        "quotes values that are not strings",
+			content:     "---\n---\nbody\n",
+			description: "true",
+			want:        "---\ndescription: \"true\"\n---\nbody\n",
+		},
+		{
+			name:        "toml frontmatter",
+			content:     "+++\ndescription = \"old\"\n+++\nbody\n",
+			description: "new",
+			wantErr:     "only YAML frontmatter",
+		},
+		{
+			name:        "list frontmatter",
+			content:     "---\n- a\n- b\n---\nbody\n",
+			description: "new",
+			wantErr:     "not a set of keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processor.EditFrontmatter([]byte(tt.content), "rule.md", tt.description, tt.tags)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if err := processor.ValidateRuleContent(got, "rule.md", ""); err != nil {
+				t.Errorf("edited content should be a valid rule: %v", err)
+			}
+		})
+	}
+}
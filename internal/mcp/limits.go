@@ -0,0 +1,225 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Session limits
+//
+// A shared network deployment serves many editors, and a runaway client (an
+// agent stuck in a loop, say) should not be able to monopolise it. Session
+// limits bound each client session separately: how many tool calls and
+// resource reads it makes per minute, and how much rule content it is served
+// over its lifetime. A session over a limit gets an error result explaining
+// the limit and what to do, rather than a protocol error, so assistants can
+// relay it to the user. Sessions are forgotten when they end.
+
+// SessionLimits bounds what one client session may ask of the server. Zero
+// values mean no limit.
+type SessionLimits struct {
+	CallsPerMinute int   // Tool calls and resource reads per minute
+	MaxBytes       int64 // Rule content served over the session's lifetime
+}
+
+// rateWindow is the period CallsPerMinute is counted over
+const rateWindow = time.Minute
+
+// sessionLimiter tracks the usage of each session against the limits
+type sessionLimiter struct {
+	limits   SessionLimits
+	now      func() time.Time
+	mu       sync.Mutex
+	sessions map[string]*sessionUsage // Keyed by session ID
+}
+
+// sessionUsage is what a session has asked of the server
+type sessionUsage struct {
+	calls []time.Time // Calls made within the last rateWindow, oldest first
+	bytes int64       // Rule content served so far
+}
+
+// newSessionLimiter creates a limiter enforcing limits
+func newSessionLimiter(limits SessionLimits) *sessionLimiter {
+	return &sessionLimiter{
+		limits:   limits,
+		now:      time.Now,
+		sessions: make(map[string]*sessionUsage),
+	}
+}
+
+// EnableSessionLimits bounds the calls and bytes each client session gets.
+// Call it before Start.
+func (s *Server) EnableSessionLimits(limits SessionLimits) {
+	if limits.CallsPerMinute <= 0 && limits.MaxBytes <= 0 {
+		s.limiter = nil
+		return
+	}
+	s.limiter = newSessionLimiter(limits)
+}
+
+// usage returns the usage of session, creating it on first use. Callers hold mu.
+func (l *sessionLimiter) usage(session string) *sessionUsage {
+	u, ok := l.sessions[session]
+	if !ok {
+		u = &sessionUsage{}
+		l.sessions[session] = u
+	}
+	return u
+}
+
+// admit records a call by session, or returns why it is refused
+func (l *sessionLimiter) admit(session string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	u := l.usage(session)
+
+	if l.limits.MaxBytes > 0 && u.bytes >= l.limits.MaxBytes {
+		return l.bytesExceeded()
+	}
+
+	if l.limits.CallsPerMinute > 0 {
+		now := l.now()
+		cutoff := now.Add(-rateWindow)
+		kept := u.calls[:0]
+		for _, call := range u.calls {
+			if call.After(cutoff) {
+				kept = append(kept, call)
+			}
+		}
+		u.calls = kept
+		if len(u.calls) >= l.limits.CallsPerMinute {
+			wait := u.calls[0].Add(rateWindow).Sub(now).Round(time.Second)
+			if wait < time.Second {
+				wait = time.Second
+			}
+			return fmt.Errorf("rate limit reached: this session may make %d rule requests per minute. Please wait %s before asking rulem for more rules",
+				l.limits.CallsPerMinute, wait)
+		}
+		u.calls = append(u.calls, now)
+	}
+	return nil
+}
+
+// serve records n bytes of rule content served to session, or returns why
+// they may not be served. Refused content does not count against the session.
+func (l *sessionLimiter) serve(session string, n int64) error {
+	if l.limits.MaxBytes <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	u := l.usage(session)
+	if u.bytes+n > l.limits.MaxBytes {
+		return l.bytesExceeded()
+	}
+	u.bytes += n
+	return nil
+}
+
+// bytesExceeded explains the byte limit
+func (l *sessionLimiter) bytesExceeded() error {
+	return fmt.Errorf("session limit reached: this session may be served at most %s of rule content. Start a new session to read more rules",
+		formatBytes(l.limits.MaxBytes))
+}
+
+// forget drops the usage of a session that ended
+func (l *sessionLimiter) forget(session string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.sessions, session)
+}
+
+// formatBytes formats n for limit messages, e.g. "10 MB"
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KB", n>>10)
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
+
+// sessionID returns the ID of the client session ctx belongs to, or "" when
+// there is none
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// sessionLimitOptions returns the MCP server options enforcing the session
+// limits on tool calls, nil when there are none
+func (s *Server) sessionLimitOptions() []server.ServerOption {
+	if s.limiter == nil {
+		return nil
+	}
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.limiter.forget(session.SessionID())
+	})
+	return []server.ServerOption{
+		server.WithToolHandlerMiddleware(s.limitToolCalls),
+		server.WithHooks(hooks),
+	}
+}
+
+// limitToolCalls refuses tool calls over the session limits with an error
+// result, and results that would take the session over its byte limit
+func (s *Server) limitToolCalls(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := sessionID(ctx)
+		if err := s.limiter.admit(session); err != nil {
+			s.logger.Warn("Refused tool call over session limit", "tool", request.Params.Name, "session", session, "error", err)
+			s.auditRule(ctx, AuditToolCall, s.tools()[request.Params.Name], AuditDenied, err.Error())
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		if err := s.limiter.serve(session, resultSize(result)); err != nil {
+			s.logger.Warn("Refused tool result over session limit", "tool", request.Params.Name, "session", session, "error", err)
+			s.auditRule(ctx, AuditToolCall, s.tools()[request.Params.Name], AuditDenied, err.Error())
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return result, nil
+	}
+}
+
+// limitResourceRead applies the session limits to a read of content from a
+// rule resource
+func (s *Server) limitResourceRead(ctx context.Context, uri, content string) error {
+	if s.limiter == nil {
+		return nil
+	}
+	session := sessionID(ctx)
+	err := s.limiter.admit(session)
+	if err == nil {
+		err = s.limiter.serve(session, int64(len(content)))
+	}
+	if err != nil {
+		s.logger.Warn("Refused resource read over session limit", "uri", uri, "session", session, "error", err)
+	}
+	return err
+}
+
+// resultSize returns the bytes of text a tool result carries
+func resultSize(result *mcp.CallToolResult) int64 {
+	var size int64
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			size += int64(len(text.Text))
+		}
+	}
+	return size
+}
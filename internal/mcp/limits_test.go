@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"rulem/internal/logging"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSessionLimiter_CallsPerMinute(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newSessionLimiter(SessionLimits{CallsPerMinute: 2})
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.admit("a"); err != nil {
+			t.Fatalf("admit() call %d: unexpected error %v", i+1, err)
+		}
+	}
+	err := limiter.admit("a")
+	if err == nil || !strings.Contains(err.Error(), "2 rule requests per minute") || !strings.Contains(err.Error(), "wait 1m0s") {
+		t.Errorf("admit() third call error = %v, want the rate limit with a wait", err)
+	}
+	if err := limiter.admit("b"); err != nil {
+		t.Errorf("admit() other session: unexpected error %v", err)
+	}
+
+	now = now.Add(rateWindow + time.Second)
+	if err := limiter.admit("a"); err != nil {
+		t.Errorf("admit() after a minute: unexpected error %v", err)
+	}
+}
+
+func TestSessionLimiter_MaxBytes(t *testing.T) {
+	limiter := newSessionLimiter(SessionLimits{MaxBytes: 10 << 10})
+
+	if err := limiter.serve("a", 8<<10); err != nil {
+		t.Fatalf("serve() unexpected error %v", err)
+	}
+	if err := limiter.serve("a", 4<<10); err == nil || !strings.Contains(err.Error(), "at most 10 KB") {
+		t.Errorf("serve() over the limit error = %v, want the byte limit", err)
+	}
+	// Refused content does not count, so a smaller rule still fits
+	if err := limiter.serve("a", 2<<10); err != nil {
+		t.Errorf("serve() up to the limit: unexpected error %v", err)
+	}
+	if err := limiter.admit("a"); err == nil {
+		t.Error("admit() expected an exhausted session to be refused")
+	}
+
+	limiter.forget("a")
+	if err := limiter.admit("a"); err != nil {
+		t.Errorf("admit() after forget: unexpected error %v", err)
+	}
+}
+
+func TestServer_LimitToolCalls(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	server := NewServer(nil, logger)
+	server.EnableSessionLimits(SessionLimits{CallsPerMinute: 5, MaxBytes: 10})
+
+	handler := server.limitToolCalls(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(strings.Repeat("x", request.GetInt("size", 0))), nil
+	})
+	call := func(size int) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "rule"
+		request.Params.Arguments = map[string]any{"size": size}
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handler() unexpected error %v", err)
+		}
+		return result
+	}
+
+	if result := call(6); result.IsError {
+		t.Errorf("first call refused: %v", result.Content)
+	}
+	result := call(6)
+	if !result.IsError {
+		t.Fatal("second call expected to be refused over the byte limit")
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Start a new session") {
+		t.Errorf("refusal = %q, want it to explain the limit", text)
+	}
+
+	server.EnableSessionLimits(SessionLimits{})
+	if server.limiter != nil {
+		t.Error("EnableSessionLimits() with no limits should disable the limiter")
+	}
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+)
+
+// LoadRuleTools scans the available prepared repositories and returns their
+// rule files keyed by tool name, as the MCP server registers them but with
+// quarantined rules included. It is used by commands that read rules without
+// starting the server.
+func LoadRuleTools(cfg *config.Config, prepared []repository.PreparedRepository, logger *logging.AppLogger) (map[string]*RuleFileTool, error) {
+	processor, err := NewRuleFileProcessorForRepositories(cfg, prepared, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filemanager.ScanAllRepositories(repository.AvailableRepositories(prepared), logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan repositories: %w", err)
+	}
+
+	return processor.ProcessRuleFiles(files)
+}
+
+// PrepareAndLoadRuleTools prepares the configured repositories and loads their rules
+func PrepareAndLoadRuleTools(ctx context.Context, cfg *config.Config, logger *logging.AppLogger) ([]repository.PreparedRepository, map[string]*RuleFileTool, error) {
+	prepared, err := repository.PrepareAllRepositories(ctx, cfg.Repositories, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prepare repositories: %w", err)
+	}
+
+	tools, err := LoadRuleTools(cfg, prepared, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prepared, tools, nil
+}
+
+// ResolveRule finds the rule a user refers to by name. A rule answers to its
+// tool name, its frontmatter name and its file name without extension; case is
+// ignored and "-" matches "_", so "go-style" finds the tool "go_style".
+// An exact tool name wins over other matches. When repositoryID is set, only
+// rules from that repository are considered.
+//
+// An error lists the candidates when the name is ambiguous.
+func ResolveRule(tools map[string]*RuleFileTool, name, repositoryID string) (*RuleFileTool, error) {
+	if tool, ok := tools[name]; ok && (repositoryID == "" || tool.RuleFile.RepositoryID == repositoryID) {
+		return tool, nil
+	}
+
+	want := normalizeRuleName(name)
+	var matches []*RuleFileTool
+	for _, tool := range tools {
+		if repositoryID != "" && tool.RuleFile.RepositoryID != repositoryID {
+			continue
+		}
+		for _, alias := range ruleAliases(tool) {
+			if normalizeRuleName(alias) == want {
+				matches = append(matches, tool)
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no rule named '%s'", name)
+	case 1:
+		return matches[0], nil
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	candidates := make([]string, len(matches))
+	for i, tool := range matches {
+		candidates[i] = fmt.Sprintf("%s (%s)", tool.Name, tool.RuleFile.FilePath)
+	}
+	return nil, fmt.Errorf("'%s' matches %d rules, use the tool name or --repo: %s",
+		name, len(matches), strings.Join(candidates, ", "))
+}
+
+// ruleAliases returns the names a rule can be referred to by
+func ruleAliases(tool *RuleFileTool) []string {
+	stem := strings.TrimSuffix(tool.RuleFile.FileName, filepath.Ext(tool.RuleFile.FileName))
+	aliases := []string{tool.Name, stem}
+	if tool.RuleFile.Name != "" {
+		aliases = append(aliases, tool.RuleFile.Name)
+	}
+	return aliases
+}
+
+// normalizeRuleName folds case and treats "-", "_" and spaces alike
+func normalizeRuleName(name string) string {
+	return strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveRule(t *testing.T) {
+	tool := func(name, fileName, frontmatterName, repositoryID string) *RuleFileTool {
+		return &RuleFileTool{Name: name, RuleFile: &RuleFile{
+			FileName:     fileName,
+			FilePath:     "/rules/" + repositoryID + "/" + fileName,
+			RepositoryID: repositoryID,
+			Name:         frontmatterName,
+		}}
+	}
+	tools := map[string]*RuleFileTool{
+		"go_style":  tool("go_style", "go-style.md", "", "personal"),
+		"testing":   tool("testing", "testing.md", "", "personal"),
+		"testing_1": tool("testing_1", "testing.md", "", "team"),
+		"review":    tool("review", "checklist.md", "Review", "team"),
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		repositoryID string
+		want         string
+		wantErr      string
+	}{
+		{name: "exact tool name", query: "testing_1", want: "testing_1"},
+		{name: "dashes and case", query: "Go-Style", want: "go_style"},
+		{name: "file name", query: "checklist", want: "review"},
+		{name: "frontmatter name", query: "review", want: "review"},
+		{name: "extension is not part of the name", query: "testing.md", wantErr: "no rule named"},
+		{name: "ambiguous file name", query: "Testing", wantErr: "matches 2 rules"},
+		{name: "repository filter", query: "testing", repositoryID: "team", want: "testing_1"},
+		{name: "filtered out", query: "go_style", repositoryID: "team", wantErr: "no rule named 'go_style'"},
+		{name: "unknown", query: "python", wantErr: "no rule named 'python'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveRule(tools, tt.query, tt.repositoryID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveRule() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveRule: %v", err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("ResolveRule() = %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRuleTools(t *testing.T) {
+	server, _ := createTestServerWithFiles(t, map[string]string{
+		"valid1.md":  validRuleFile1,
+		"invalid.md": invalidRuleFile,
+	})
+	if err := server.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+
+	tools, err := LoadRuleTools(server.config, server.preparedRepositories, server.logger)
+	if err != nil {
+		t.Fatalf("LoadRuleTools: %v", err)
+	}
+	tool, ok := tools["test_rule_1"]
+	if len(tools) != 1 || !ok {
+		t.Fatalf("expected only test_rule_1, got %v", tools)
+	}
+	if tool.RuleFile.RepositoryID != "test-repo-123456" {
+		t.Errorf("RepositoryID = %q", tool.RuleFile.RepositoryID)
+	}
+}
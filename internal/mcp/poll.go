@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"bufio"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"rulem/internal/filemanager"
+)
+
+// Polling watcher
+//
+// File system notifications are not delivered for changes made by other
+// machines to NFS or SMB mounts, and are unreliable on some FUSE file systems,
+// so hot reload can silently stop working there. The polling watcher rescans
+// the rule files of the watched trees instead and reloads when one was added,
+// edited or removed. It polls at the configured interval after a change and
+// backs off to maxPollBackoff times that while nothing changes.
+
+// maxPollBackoff bounds how far the poll interval grows while nothing changes
+const maxPollBackoff = 8
+
+// networkFilesystems are the mount types on which notifications are unreliable
+var networkFilesystems = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smb": true, "smb2": true, "smb3": true, "smbfs": true,
+	"9p": true, "afs": true, "ceph": true, "glusterfs": true, "davfs": true, "fuse.sshfs": true,
+	"fuse.rclone": true, "fuse.s3fs": true, "virtiofs": true,
+}
+
+// mountInfoPath lists the mounts of the process, on Linux
+var mountInfoPath = "/proc/self/mountinfo"
+
+// networkFilesystem returns the first root on a network file system and the
+// type of that file system, or empty strings when there is none or the mounts
+// cannot be read
+func networkFilesystem(roots []string) (string, string) {
+	mounts := readMounts()
+	for _, root := range roots {
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		var best, fsType string
+		for point, kind := range mounts {
+			if (root == point || strings.HasPrefix(root, strings.TrimSuffix(point, "/")+"/")) && len(point) > len(best) {
+				best, fsType = point, kind
+			}
+		}
+		if networkFilesystems[fsType] {
+			return root, fsType
+		}
+	}
+	return "", ""
+}
+
+// readMounts maps mount points to file system types
+func readMounts() map[string]string {
+	file, err := os.Open(mountInfoPath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	// Fields: id parent major:minor root mount-point options [optional...] - type source super-options
+	mounts := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		for i, field := range fields {
+			if field == "-" && i > 4 && i+1 < len(fields) {
+				mounts[unescapeMountPath(fields[4])] = fields[i+1]
+				break
+			}
+		}
+	}
+	return mounts
+}
+
+// unescapeMountPath decodes the octal escapes of spaces and tabs in mountinfo paths
+func unescapeMountPath(path string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(path)
+}
+
+// fileStamp identifies a version of a rule file
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// pollWatcher calls onChange when the rule files under its roots change
+type pollWatcher struct {
+	roots    []string
+	interval time.Duration
+	onChange func()
+	done     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+// newPollWatcher starts polling roots every interval until Close
+func newPollWatcher(roots []string, interval time.Duration, onChange func()) *pollWatcher {
+	w := &pollWatcher{
+		roots:    roots,
+		interval: interval,
+		onChange: onChange,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go w.run(snapshotRuleFiles(roots))
+	return w
+}
+
+// Close stops polling and waits for a running poll to finish
+func (w *pollWatcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	<-w.stopped
+	return nil
+}
+
+func (w *pollWatcher) run(last map[string]fileStamp) {
+	defer close(w.stopped)
+	interval := w.interval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-timer.C:
+		}
+
+		current := snapshotRuleFiles(w.roots)
+		if maps.Equal(current, last) {
+			interval = min(interval*2, w.interval*maxPollBackoff)
+		} else {
+			last = current
+			interval = w.interval
+			w.onChange()
+		}
+		timer.Reset(interval)
+	}
+}
+
+// snapshotRuleFiles stamps every rule file and .rulemignore under roots,
+// walking the directories that are scanned for rules
+func snapshotRuleFiles(roots []string) map[string]fileStamp {
+	stamps := map[string]fileStamp{}
+	for _, root := range roots {
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// Unreadable directories are skipped, as when scanning
+				return nil
+			}
+			if d.IsDir() {
+				if path != root && filemanager.IsSkippedDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// Editing .rulemignore changes which files are rules
+			if !filemanager.IsRuleFilePath(d.Name()) && d.Name() != filemanager.RuleIgnoreFile {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				stamps[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+			}
+			return nil
+		})
+	}
+	return stamps
+}
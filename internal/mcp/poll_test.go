@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rulem/internal/config"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestPollWatcher(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("style.md", "# Style\n")
+
+	changes := make(chan struct{}, 10)
+	watcher := newPollWatcher([]string{dir}, 10*time.Millisecond, func() { changes <- struct{}{} })
+	t.Cleanup(func() { watcher.Close() })
+
+	expectChange := func(what string) {
+		t.Helper()
+		select {
+		case <-changes:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+
+	write("go/errors.md", "# Errors\n")
+	expectChange("a new rule in a new directory")
+
+	write("notes.txt", "not a rule")
+	write("node_modules/dep.md", "# Skipped\n")
+	select {
+	case <-changes:
+		t.Fatal("files that are not rules, or are in skipped directories, should not trigger a reload")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	write("style.md", "# Style, revised\n")
+	expectChange("an edited rule")
+
+	if err := os.Remove(filepath.Join(dir, "style.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	expectChange("a removed rule")
+
+	if err := watcher.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := watcher.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestNetworkFilesystem(t *testing.T) {
+	mountInfo := filepath.Join(t.TempDir(), "mountinfo")
+	content := "" +
+		"22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw\n" +
+		"40 22 0:50 / /mnt/rules rw,relatime shared:20 - nfs4 server:/export/rules rw,vers=4.2\n" +
+		"41 22 0:51 / /mnt/team\\040share rw,relatime - cifs //server/team rw\n" +
+		"42 40 8:2 / /mnt/rules/local rw,relatime - ext4 /dev/sdb1 rw\n"
+	if err := os.WriteFile(mountInfo, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	original := mountInfoPath
+	mountInfoPath = mountInfo
+	t.Cleanup(func() { mountInfoPath = original })
+
+	tests := []struct {
+		roots    []string
+		wantRoot string
+		wantType string
+	}{
+		{[]string{"/home/me/rules"}, "", ""},
+		{[]string{"/home/me/rules", "/mnt/rules/team"}, "/mnt/rules/team", "nfs4"},
+		{[]string{"/mnt/team share/rules"}, "/mnt/team share/rules", "cifs"},
+		{[]string{"/mnt/rules/local/rules"}, "", ""},
+		{[]string{"/mnt/rulesets"}, "", ""},
+	}
+	for _, tt := range tests {
+		root, fsType := networkFilesystem(tt.roots)
+		if root != tt.wantRoot || fsType != tt.wantType {
+			t.Errorf("networkFilesystem(%v) = (%q, %q), want (%q, %q)", tt.roots, root, fsType, tt.wantRoot, tt.wantType)
+		}
+	}
+}
+
+func TestServer_PollReloadsRules(t *testing.T) {
+	srv, dir := createTestServerWithFiles(t, map[string]string{
+		"style.md": "---\ndescription: Style guide\n---\n# Style\n",
+	})
+	srv.config.WatchMode = config.WatchModePoll
+	srv.config.WatchPollInterval = "100ms"
+	if err := srv.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	srv.mcpServer = server.NewMCPServer("rulem", "test",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true))
+	if err := srv.RegisterRuleFileTools(); err != nil {
+		t.Fatalf("RegisterRuleFileTools: %v", err)
+	}
+	if err := srv.startWatcher(); err != nil {
+		t.Fatalf("startWatcher: %v", err)
+	}
+	t.Cleanup(func() { srv.closeWatcher() })
+	if _, ok := srv.watcher.(*pollWatcher); !ok {
+		t.Fatalf("watch_mode poll should poll, got %T", srv.watcher)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "errors.md"), []byte("---\ndescription: Error handling\n---\n# Errors\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	waitFor(t, "the new rule", func() bool { return srv.tools()["errors"] != nil })
+}
@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"rulem/internal/frontmatter"
+	"rulem/internal/proposals"
+	"rulem/internal/repository"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// propose_rule tool
+//
+// Assistants come across conventions worth keeping while they work.
+// propose_rule lets them submit one as a new rule: a file name, its
+// frontmatter and its body. The rule is checked like any rule file of the
+// repository, then staged in the proposed/ directory of a local repository
+// that sets accept_proposals, for a person to accept or reject in the TUI (see
+// the proposals package). Nothing is served until then, and nothing is
+// overwritten. The tool is only registered when a repository accepts
+// proposals and no organization policy disallows them (see DisableProposals).
+
+// ProposeToolName is the name of the built-in rule proposal tool, after the
+// configured tool_prefix if any. Like search_rules, rule files cannot take it.
+const ProposeToolName = "propose_rule"
+
+// newProposeTool describes the propose_rule tool, registered as name, for
+// proposals to the named repositories
+func newProposeTool(name string, repositories []string) mcp.Tool {
+	repositoryOpts := []mcp.PropertyOption{mcp.Description("Repository to propose the rule to; needed when there are several")}
+	if len(repositories) > 0 {
+		repositoryOpts = append(repositoryOpts, mcp.Enum(repositories...))
+	}
+	return mcp.NewTool(name,
+		mcp.WithDescription("Propose a new rule for a person to review, e.g. a convention worth keeping that no rule covers yet. The rule is staged, not served, until it is accepted; existing rules are never changed."),
+		mcp.WithString("filename", mcp.Required(), mcp.Description("File name of the rule, e.g. go-errors.md")),
+		mcp.WithObject("frontmatter", mcp.Required(), mcp.Description("Frontmatter of the rule: a description is required; name, tags, scope and priority are optional")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Markdown body of the rule")),
+		mcp.WithString("repository", repositoryOpts...),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+}
+
+// proposeToolHandler stages the rule of a propose_rule call
+func (s *Server) proposeToolHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	prep, err := s.proposalRepository(request.GetString("repository", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	fileName := strings.TrimSpace(request.GetString("filename", ""))
+	if err := proposals.ValidateName(fileName); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	fields, ok := request.GetArguments()["frontmatter"].(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("frontmatter must be an object of frontmatter keys, with at least a description"), nil
+	}
+	if _, err := frontmatter.Decode(fields); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid frontmatter: %v", err)), nil
+	}
+	content, err := proposals.Render(fields, request.GetString("content", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// The rule must be one the repository would serve once accepted
+	s.reloadMu.Lock()
+	err = s.ruleProcessor.ValidateRuleContent(content, fileName, prep.ID())
+	s.reloadMu.Unlock()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("the rule would not be served: %v", err)), nil
+	}
+
+	path, err := proposals.Stage(prep, fileName, content)
+	if errors.Is(err, proposals.ErrExists) {
+		return mcp.NewToolResultError(err.Error() + ": choose another file name"), nil
+	}
+	if err != nil {
+		s.logger.Error("Failed to stage proposed rule", "file", fileName, "repository", prep.ID(), "error", err)
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	s.logger.Info("Rule proposed", "file", fileName, "repository", prep.ID(), "client", clientName(ctx), "path", path)
+	return mcp.NewToolResultText(fmt.Sprintf("Proposed %s to %s. It is staged for review and will be served once a person accepts it.", fileName, prep.Name())), nil
+}
+
+// DisableProposals keeps propose_rule from being registered even when a
+// repository accepts proposals, as an organization policy with
+// allow_proposals: false requires. Call it before Start.
+func (s *Server) DisableProposals() {
+	s.proposalsDisabled = true
+}
+
+// proposalRepositories returns the prepared repositories that accept
+// proposals, none when proposals are disabled
+func (s *Server) proposalRepositories() []repository.PreparedRepository {
+	if s.proposalsDisabled {
+		return nil
+	}
+	var accepting []repository.PreparedRepository
+	for _, prep := range s.preparedRepositories {
+		if proposals.Accepting(prep) {
+			accepting = append(accepting, prep)
+		}
+	}
+	return accepting
+}
+
+// proposalRepository returns the repository a proposal goes to, by name or
+// ID, or the only repository accepting proposals when nameOrID is empty
+func (s *Server) proposalRepository(nameOrID string) (repository.PreparedRepository, error) {
+	accepting := s.proposalRepositories()
+	if nameOrID == "" {
+		switch len(accepting) {
+		case 0:
+			return repository.PreparedRepository{}, fmt.Errorf("no repository accepts proposals")
+		case 1:
+			return accepting[0], nil
+		default:
+			return repository.PreparedRepository{}, fmt.Errorf("several repositories accept proposals: choose one of %s", strings.Join(repositoryNames(accepting), ", "))
+		}
+	}
+	for _, prep := range accepting {
+		if prep.ID() == nameOrID || prep.Name() == nameOrID {
+			return prep, nil
+		}
+	}
+	return repository.PreparedRepository{}, fmt.Errorf("repository %q does not accept proposals: choose one of %s", nameOrID, strings.Join(repositoryNames(accepting), ", "))
+}
+
+// repositoryNames returns the names of prepared, sorted
+func repositoryNames(prepared []repository.PreparedRepository) []string {
+	names := make([]string, len(prepared))
+	for i, prep := range prepared {
+		names[i] = prep.Name()
+	}
+	slices.Sort(names)
+	return names
+}
+
+// proposeToolName returns the name propose_rule is registered under
+func (s *Server) proposeToolName() string {
+	return s.toolNamePrefix() + ProposeToolName
+}
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/repository"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestServer_ProposeToolHandler(t *testing.T) {
+	server, tempDir := createTestServerWithFiles(t, map[string]string{
+		"style.md": "---\ndescription: Style\n---\n# Style\n",
+	})
+	server.config.Repositories[0].AcceptProposals = true
+	if err := server.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		var request mcp.CallToolRequest
+		request.Params.Name = ProposeToolName
+		request.Params.Arguments = args
+		result, err := server.proposeToolHandler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("proposeToolHandler: %v", err)
+		}
+		return result
+	}
+
+	result := call(map[string]any{
+		"filename":    "errors.md",
+		"frontmatter": map[string]any{"description": "Go error handling", "tags": []any{"go"}},
+		"content":     "# Errors\nWrap errors with context.",
+	})
+	if result.IsError {
+		t.Fatalf("expected the proposal to be staged, got %+v", result.Content)
+	}
+	staged, err := os.ReadFile(filepath.Join(tempDir, repository.ProposalsDir, "errors.md"))
+	if err != nil || !strings.Contains(string(staged), "description: Go error handling") || !strings.HasSuffix(string(staged), "Wrap errors with context.\n") {
+		t.Errorf("staged proposal = %q, %v", staged, err)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{name: "existing proposal", args: map[string]any{"filename": "errors.md", "frontmatter": map[string]any{"description": "Again"}, "content": "# Again"}, want: "already exists"},
+		{name: "existing rule", args: map[string]any{"filename": "style.md", "frontmatter": map[string]any{"description": "Style"}, "content": "# Mine"}, want: "already exists"},
+		{name: "no description", args: map[string]any{"filename": "bare.md", "frontmatter": map[string]any{"tags": []any{"go"}}, "content": "# Bare"}, want: "would not be served"},
+		{name: "invalid frontmatter", args: map[string]any{"filename": "bad.md", "frontmatter": map[string]any{"description": "Bad", "priority": "high"}, "content": "# Bad"}, want: "priority"},
+		{name: "path in the file name", args: map[string]any{"filename": "../escape.md", "frontmatter": map[string]any{"description": "Escape"}, "content": "# Escape"}, want: "invalid file name"},
+		{name: "other repository", args: map[string]any{"filename": "other.md", "frontmatter": map[string]any{"description": "Other"}, "content": "# Other", "repository": "Elsewhere"}, want: "does not accept proposals"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := call(tt.args)
+			if !result.IsError {
+				t.Fatalf("expected an error result, got %+v", result.Content)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.want) {
+				t.Errorf("error = %q, want it to mention %q", text, tt.want)
+			}
+		})
+	}
+
+	// Proposals are not served until accepted
+	files, err := server.getRepoFiles()
+	if err != nil || len(files) != 1 {
+		t.Errorf("served files = %v, %v; want only style.md", files, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tempDir, "style.md")); string(data) != "---\ndescription: Style\n---\n# Style\n" {
+		t.Errorf("existing rule was changed to %q", data)
+	}
+}
+
+func TestServer_BuiltinTools_Propose(t *testing.T) {
+	server, _ := createTestServer(t)
+	if err := server.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	hasPropose := func() bool {
+		tools, err := server.builtinTools()
+		if err != nil {
+			t.Fatalf("builtinTools: %v", err)
+		}
+		for _, tool := range tools {
+			if tool.Tool.Name == ProposeToolName {
+				return true
+			}
+		}
+		return false
+	}
+	if hasPropose() {
+		t.Error("propose_rule should only be served when a repository accepts proposals")
+	}
+
+	server.preparedRepositories[0].Entry.AcceptProposals = true
+	if !hasPropose() {
+		t.Error("expected propose_rule once a repository accepts proposals")
+	}
+	if !strings.Contains(server.buildInstructions(), ProposeToolName) {
+		t.Error("expected the instructions to mention propose_rule")
+	}
+
+	// An organization policy can disable proposals altogether
+	server.DisableProposals()
+	if hasPropose() || strings.Contains(server.buildInstructions(), ProposeToolName) {
+		t.Error("propose_rule should not be served once proposals are disabled")
+	}
+	if _, err := server.proposalRepository(""); err == nil {
+		t.Error("expected proposals to be refused once disabled")
+	}
+}
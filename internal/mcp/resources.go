@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Rule resources
+//
+// Besides a tool, every rule is exposed as an MCP resource at
+// rulem://<repository-id>/<path>, where path is the rule file's path in its
+// repository, for clients that prefer resource semantics. Reading a resource
+// returns the same content as calling the rule's tool.
+//
+// When the registered rules change, clients are told the resource list changed
+// and get a resources/updated notification for each rule whose content changed.
+
+// ResourceScheme is the URI scheme of rule resources
+const ResourceScheme = "rulem"
+
+// ruleMIMEType is the MIME type of rule resources; rule files are markdown
+const ruleMIMEType = "text/markdown"
+
+// RuleResourceURI returns the resource URI of the rule at the slash-separated
+// relPath in the repository with repositoryID
+func RuleResourceURI(repositoryID, relPath string) string {
+	u := url.URL{Scheme: ResourceScheme, Host: repositoryID, Path: "/" + relPath}
+	return u.String()
+}
+
+// ruleResources maps the resource URI of each rule in tools to its tool
+func (s *Server) ruleResources(tools map[string]*RuleFileTool) map[string]*RuleFileTool {
+	resources := make(map[string]*RuleFileTool, len(tools))
+	for _, tool := range tools {
+		repoPath, exists := s.ruleProcessor.repositoryPaths[tool.RuleFile.RepositoryID]
+		if !exists {
+			continue
+		}
+		relPath, err := filepath.Rel(repoPath, tool.RuleFile.FilePath)
+		if err != nil {
+			s.logger.Debug("Skipping rule resource", "tool", tool.Name, "reason", err)
+			continue
+		}
+		resources[RuleResourceURI(tool.RuleFile.RepositoryID, filepath.ToSlash(relPath))] = tool
+	}
+	return resources
+}
+
+// registerResources exposes the rules in tools as resources, replacing the
+// previously registered ones and notifying clients of what changed
+func (s *Server) registerResources(tools map[string]*RuleFileTool) {
+	next := s.ruleResources(tools)
+
+	s.resourcesMu.Lock()
+	previous := s.resources
+	s.resources = next
+	s.resourcesMu.Unlock()
+
+	var removed []string
+	for uri := range previous {
+		if _, exists := next[uri]; !exists {
+			removed = append(removed, uri)
+		}
+	}
+	if len(removed) > 0 {
+		s.mcpServer.DeleteResources(removed...)
+	}
+
+	var added []server.ServerResource
+	var updated []string
+	for uri, tool := range next {
+		old, existed := previous[uri]
+		// Re-adding a resource replaces its listing
+		if !existed || old.Name != tool.Name || old.Description != tool.Description {
+			added = append(added, server.ServerResource{Resource: newRuleResource(uri, tool), Handler: s.readRuleResource})
+		}
+		if existed && old.RuleFile.Content != tool.RuleFile.Content {
+			updated = append(updated, uri)
+		}
+	}
+	if len(added) > 0 {
+		s.mcpServer.AddResources(added...)
+	}
+
+	for _, uri := range updated {
+		s.logger.Debug("Rule resource updated", "uri", uri)
+		s.mcpServer.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+	}
+}
+
+// newRuleResource describes the resource at uri serving tool's rule
+func newRuleResource(uri string, tool *RuleFileTool) mcp.Resource {
+	return mcp.NewResource(uri, tool.Name,
+		mcp.WithResourceDescription(tool.Description),
+		mcp.WithMIMEType(ruleMIMEType),
+	)
+}
+
+// readRuleResource answers resources/read requests for rule resources
+func (s *Server) readRuleResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	uri := request.Params.URI
+	s.resourcesMu.RLock()
+	tool, exists := s.resources[uri]
+	s.resourcesMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("rule resource %q not found", uri)
+	}
+
+	s.logger.Debug("Processing rule resource request", "uri", uri, "tool", tool.Name)
+	if err := s.limitResourceRead(ctx, uri, tool.RuleFile.Content); err != nil {
+		s.auditRule(ctx, AuditResourceRead, tool, AuditDenied, err.Error())
+		return nil, err
+	}
+	s.recordUsage(tool, clientName(ctx))
+	s.auditRule(ctx, AuditResourceRead, tool, AuditSuccess, "")
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: ruleMIMEType, Text: tool.RuleFile.Content},
+	}, nil
+}
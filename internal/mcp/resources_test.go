@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestRuleResourceURI(t *testing.T) {
+	tests := []struct {
+		repositoryID string
+		relPath      string
+		want         string
+	}{
+		{"rules-3f9a0c12", "style.md", "rulem://rules-3f9a0c12/style.md"},
+		{"rules-3f9a0c12", "go/errors.md", "rulem://rules-3f9a0c12/go/errors.md"},
+		{"rules-3f9a0c12", "go/error handling.md", "rulem://rules-3f9a0c12/go/error%20handling.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.relPath, func(t *testing.T) {
+			if got := RuleResourceURI(tt.repositoryID, tt.relPath); got != tt.want {
+				t.Errorf("RuleResourceURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_RuleResources(t *testing.T) {
+	srv, _ := createTestServerWithFiles(t, map[string]string{
+		"go/errors.md": "---\ndescription: Error handling in Go\n---\n# Errors\n",
+		"style.md":     "---\ndescription: Style guide\n---\n# Style\n",
+	})
+	if err := srv.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	srv.mcpServer = server.NewMCPServer("rulem", "test", server.WithResourceCapabilities(false, true))
+	if err := srv.RegisterRuleFileTools(); err != nil {
+		t.Fatalf("RegisterRuleFileTools: %v", err)
+	}
+
+	uri := "rulem://test-repo-123456/go/errors.md"
+	if len(srv.resources) != 2 || srv.resources[uri] == nil {
+		t.Fatalf("unexpected resources %v", srv.resources)
+	}
+
+	read := func(uri string) ([]mcp.ResourceContents, error) {
+		var request mcp.ReadResourceRequest
+		request.Params.URI = uri
+		return srv.readRuleResource(context.Background(), request)
+	}
+	contents, err := read(uri)
+	if err != nil {
+		t.Fatalf("readRuleResource: %v", err)
+	}
+	text, ok := contents[0].(mcp.TextResourceContents)
+	if !ok || text.URI != uri || text.MIMEType != "text/markdown" || text.Text != "# Errors\n" {
+		t.Errorf("unexpected contents %+v", contents)
+	}
+	if _, err := read("rulem://test-repo-123456/missing.md"); err == nil {
+		t.Error("expected an error for an unknown resource")
+	}
+
+	// Replacing the rules drops resources that are gone
+	changed := *srv.resources[uri]
+	changed.RuleFile = &RuleFile{FilePath: changed.RuleFile.FilePath, RepositoryID: changed.RuleFile.RepositoryID, Content: "# Errors, revised\n"}
+	srv.registerResources(map[string]*RuleFileTool{changed.Name: &changed})
+	if len(srv.resources) != 1 {
+		t.Fatalf("got %d resources, want 1", len(srv.resources))
+	}
+	if contents, err := read(uri); err != nil || contents[0].(mcp.TextResourceContents).Text != "# Errors, revised\n" {
+		t.Errorf("expected the revised content, got %+v (%v)", contents, err)
+	}
+}
@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+
+	"rulem/internal/filemanager"
+	"rulem/internal/quarantine"
+	"rulem/internal/repository"
+)
+
+// Time-travel serving
+//
+// A repository can be served as of a branch, tag or commit instead of its
+// working tree, so an assistant run can be reproduced against the exact rules
+// it saw. The rules are read from git objects; the clone's worktree and HEAD
+// are not touched and it keeps syncing as usual. Clones are shallow, so older
+// commits are only available once a sync has fetched them.
+
+// pinnedRevision is a repository served as of a revision
+type pinnedRevision struct {
+	revision string                    // Revision as requested, e.g. a tag
+	commit   string                    // Full hash the revision resolved to
+	files    []repository.RevisionFile // Rule files at the commit
+}
+
+// ServeAt serves the rules of the repository with repositoryID as of revision
+// (a branch, tag or commit hash), overriding the repository's serve_at setting.
+// It must be called before Start.
+func (s *Server) ServeAt(repositoryID, revision string) {
+	if s.revisions == nil {
+		s.revisions = make(map[string]string)
+	}
+	s.revisions[repositoryID] = revision
+}
+
+// servedRevision returns the revision prep is served at, or "" for its working tree
+func (s *Server) servedRevision(prep repository.PreparedRepository) string {
+	if revision, ok := s.revisions[prep.ID()]; ok {
+		return revision
+	}
+	return prep.Entry.GetServeAt()
+}
+
+// readPinnedRevisions reads the rule files of every available repository that
+// is served at a revision. A revision that cannot be read is an error rather
+// than a fallback to the working tree, which would defeat the pinning.
+func (s *Server) readPinnedRevisions() error {
+	s.pinned = make(map[string]pinnedRevision)
+
+	for id := range s.revisions {
+		if !s.isPrepared(id) {
+			return fmt.Errorf("cannot serve repository %s at %s: no such repository", id, s.revisions[id])
+		}
+	}
+
+	for _, prep := range repository.AvailableRepositories(s.preparedRepositories) {
+		revision := s.servedRevision(prep)
+		if revision == "" {
+			continue
+		}
+
+		subpath := prep.Entry.GetSubpath()
+		commit, files, err := repository.ReadFilesAtRevision(prep.GitRoot(), revision, func(path string) bool {
+			rel, ok := repository.RelativeToSubpath(path, subpath)
+			return ok && filemanager.IsRuleFilePath(rel)
+		})
+		if err != nil {
+			return fmt.Errorf("cannot serve repository %s at %s (clones only hold commits fetched since cloning): %w", prep.Name(), revision, err)
+		}
+		// Serve the files relative to the directory the repository exposes
+		for i := range files {
+			files[i].Path, _ = repository.RelativeToSubpath(files[i].Path, subpath)
+		}
+
+		s.logger.Info("Serving repository at revision",
+			"repository_id", prep.ID(),
+			"revision", revision,
+			"commit", commit,
+			"fileCount", len(files))
+		s.pinned[prep.ID()] = pinnedRevision{revision: revision, commit: commit, files: files}
+	}
+
+	return nil
+}
+
+// isPrepared reports whether a prepared repository has the given ID
+func (s *Server) isPrepared(repositoryID string) bool {
+	for _, prep := range s.preparedRepositories {
+		if prep.ID() == repositoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// worktreeFiles drops the scanned files of repositories served at a revision
+func (s *Server) worktreeFiles(files []filemanager.FileItem) []filemanager.FileItem {
+	if len(s.pinned) == 0 {
+		return files
+	}
+
+	var result []filemanager.FileItem
+	for _, file := range files {
+		if _, ok := s.pinned[file.RepositoryID]; !ok {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+// worktreeRepositories returns the prepared repositories served from their
+// worktree rather than at a revision
+func (s *Server) worktreeRepositories() []repository.PreparedRepository {
+	if len(s.pinned) == 0 {
+		return s.preparedRepositories
+	}
+
+	var result []repository.PreparedRepository
+	for _, prep := range s.preparedRepositories {
+		if _, ok := s.pinned[prep.ID()]; !ok {
+			result = append(result, prep)
+		}
+	}
+	return result
+}
+
+// registerPinnedRevisions adds the rules of repositories served at a revision
+// to the processor's registry, in repository ID order so tool names are stable.
+// Rules new at the revision are held in quarantine like those of a worktree.
+func (s *Server) registerPinnedRevisions() error {
+	prepared := make(map[string]repository.PreparedRepository, len(s.pinned))
+	ids := make([]string, 0, len(s.pinned))
+	for _, prep := range s.preparedRepositories {
+		if _, ok := s.pinned[prep.ID()]; ok {
+			prepared[prep.ID()] = prep
+			ids = append(ids, prep.ID())
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		files, err := quarantine.FilterRevision(prepared[id], s.pinned[id].files, s.logger)
+		if err != nil {
+			return fmt.Errorf("failed to check quarantined rules of %s: %w", id, err)
+		}
+		if _, err := s.ruleProcessor.ProcessRevisionFiles(id, files); err != nil {
+			return fmt.Errorf("failed to process rule files of %s at %s: %w", id, s.pinned[id].revision, err)
+		}
+	}
+	return nil
+}
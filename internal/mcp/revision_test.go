@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"rulem/internal/repository"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// commitAll makes dir a git repository and commits every file in it
+func commitAll(t *testing.T, dir string) {
+	t.Helper()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := worktree.Commit("add rules", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+}
+
+func TestServer_ServeAt(t *testing.T) {
+	server, dir := createTestServerWithFiles(t, map[string]string{
+		"style.md": "---\ndescription: Style v1\nname: style\n---\n# Style v1\n",
+	})
+	commitAll(t, dir)
+
+	// Later changes in the working tree are not served
+	if err := os.WriteFile(filepath.Join(dir, "style.md"), []byte("---\ndescription: Style v2\nname: style\n---\n# Style v2\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.md"), []byte("---\ndescription: New rule\nname: new_rule\n---\n# New\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	server.ServeAt("test-repo-123456", "HEAD")
+	if err := server.InitializeComponents(); err != nil {
+		t.Fatalf("InitializeComponents: %v", err)
+	}
+	files, err := server.getRepoFiles()
+	if err != nil {
+		t.Fatalf("getRepoFiles: %v", err)
+	}
+	tools, err := server.ruleProcessor.ProcessRuleFiles(server.worktreeFiles(files))
+	if err != nil {
+		t.Fatalf("ProcessRuleFiles: %v", err)
+	}
+	if err := server.registerPinnedRevisions(); err != nil {
+		t.Fatalf("registerPinnedRevisions: %v", err)
+	}
+
+	if len(tools) != 1 {
+		t.Fatalf("got %d tools, want only the committed rule", len(tools))
+	}
+	style, ok := tools["style"]
+	if !ok || style.Description != "Style v1" || !strings.Contains(style.RuleFile.Content, "# Style v1") {
+		t.Fatalf("style tool = %+v, want the committed version", style)
+	}
+	if style.RuleFile.FilePath != filepath.Join(dir, "style.md") {
+		t.Errorf("FilePath = %q, want the working tree path", style.RuleFile.FilePath)
+	}
+
+	pin := server.pinned["test-repo-123456"]
+	if len(pin.commit) != 40 || !strings.Contains(server.buildInstructions(), "served as of HEAD (commit "+pin.commit+")") {
+		t.Errorf("instructions should name the served commit, got %q", server.buildInstructions())
+	}
+}
+
+func TestServer_ServeAtQuarantine(t *testing.T) {
+	t.Setenv("RULEM_QUARANTINE_PATH", filepath.Join(t.TempDir(), "quarantine.yaml"))
+	server, dir := createTestServerWithFiles(t, map[string]string{
+		"style.md": "---\ndescription: Style\nname: style\n---\n# Style\n",
+	})
+	commitAll(t, dir)
+	server.ServeAt("test-repo-123456", "HEAD")
+	if err := server.InitializeComponents(); err != nil {
+		t.Fatalf("InitializeComponents: %v", err)
+	}
+	server.preparedRepositories[0].Entry.QuarantineNewRules = true
+
+	// The first review approves the rules at the revision as the baseline
+	if err := server.registerPinnedRevisions(); err != nil {
+		t.Fatalf("registerPinnedRevisions: %v", err)
+	}
+	if tools := server.ruleProcessor.toolRegistry; len(tools) != 1 {
+		t.Fatalf("got %d tools, want the baseline rule", len(tools))
+	}
+
+	// A rule new at the revision is held back until approved
+	pin := server.pinned["test-repo-123456"]
+	pin.files = append(pin.files, repository.RevisionFile{Path: "new.md", Content: []byte("---\ndescription: New\nname: new_rule\n---\n# New\n")})
+	server.pinned["test-repo-123456"] = pin
+	if err := server.registerPinnedRevisions(); err != nil {
+		t.Fatalf("registerPinnedRevisions: %v", err)
+	}
+	if _, ok := server.ruleProcessor.toolRegistry["new_rule"]; ok {
+		t.Error("a quarantined rule was served at the revision")
+	}
+}
+
+func TestServer_ServeAtErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		revision   string
+	}{
+		{name: "unknown revision", repository: "test-repo-123456", revision: "no-such-tag"},
+		{name: "unknown repository", repository: "other-repo-1", revision: "HEAD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, dir := createTestServerWithFiles(t, map[string]string{"style.md": validRuleFile1})
+			commitAll(t, dir)
+
+			server.ServeAt(tt.repository, tt.revision)
+			if err := server.InitializeComponents(); err == nil {
+				t.Error("expected InitializeComponents to fail")
+			}
+		})
+	}
+}
@@ -1,16 +1,24 @@
 package mcp
 
 import (
-	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"rulem/internal/config"
 	"rulem/internal/filemanager"
+	fm "rulem/internal/frontmatter"
 	"rulem/internal/logging"
+	"rulem/internal/repository"
 	"rulem/pkg/fileops"
+	"sort"
 	"strings"
 
 	"github.com/adrg/frontmatter"
+	"golang.org/x/sync/errgroup"
 )
 
 // Constants for configuring tool description generation
@@ -22,25 +30,47 @@ const (
 
 	// ApplyToFormat defines how the applyTo field is formatted in descriptions
 	ApplyToFormat = "apply to"
+
+	// ScopeFormat defines how the scope globs are formatted in descriptions
+	ScopeFormat = "files"
+
+	// DeprecatedPrefix is prepended to the descriptions of deprecated rules
+	DeprecatedPrefix = "[Deprecated] "
 )
 
-// RuleFrontmatter represents the YAML frontmatter structure expected in rule files
-type RuleFrontmatter struct {
-	Description string `yaml:"description"`
-	Name        string `yaml:"name,omitempty"`
-	ApplyTo     string `yaml:"applyTo,omitempty"`
-}
+// RuleFrontmatter represents the frontmatter structure expected in rule files.
+// The block may be written in YAML, TOML or JSON; see the frontmatter package
+// for its keys and how they are decoded and validated.
+type RuleFrontmatter = fm.Metadata
 
 // RuleFile represents a parsed rule file with frontmatter and content
 type RuleFile struct {
 	// File information
-	FileName string
-	FilePath string
+	FileName     string
+	FilePath     string
+	RepositoryID string
 
 	// Frontmatter fields
 	Description string
 	Name        string
 	ApplyTo     string
+	Scope       []string // Globs of the files the rule applies to
+	Tags        []string
+	Priority    int
+	Version     string
+	Deprecated  bool
+	License     string
+	Attribution string
+	Expires     string // frontmatter.DateLayout, empty when the rule does not expire
+	ReviewBy    string // frontmatter.DateLayout, empty when no review is due
+
+	// DescriptionGenerated is set when the rule has no description of its own
+	// and Description was derived from its body, ending with AutoDescriptionSuffix
+	DescriptionGenerated bool
+
+	// ContentWarnings describes suspicious content the repository's content
+	// policy warns about or strips rather than blocks, e.g. "prompt_injection: ..."
+	ContentWarnings []string
 
 	// File content (without frontmatter)
 	Content string
@@ -59,16 +89,92 @@ type RuleFileProcessor struct {
 	repositoryPaths map[string]string // Maps repository IDs to local filesystem paths
 	toolRegistry    map[string]*RuleFileTool
 	maxFileSize     int64 // Maximum file size in bytes
+
+	frontmatterFormats []*frontmatter.Format         // Recognised frontmatter delimiters, fixed at construction
+	delimiters         []config.FrontmatterDelimiter // Definitions of frontmatterFormats, for cacheKey
+	extensions         []string                      // Extensions of the files rules are read from, see formats.go
+
+	manifests map[string]*repository.Manifest // Maps repository IDs to their rulem.yaml, when present
+
+	toolNamePrefix string            // Put in front of every tool name, see config.Config.ToolPrefix
+	toolPrefixes   map[string]string // Maps repository IDs to a prefix for their tool names, when set
+
+	contentPolicy   fileops.ContentPolicy            // Content policy of repositories without their own
+	contentPolicies map[string]fileops.ContentPolicy // Maps repository IDs to their content policy, when it differs
+
+	autoDescriptions bool // Derive a description for rules without one, see deriveDescription
+
+	filter ToolFilter // Selects the rules registered as tools, see filter.go
 }
 
-// NewRuleFileProcessor creates a new RuleFileProcessor instance
+// NewRuleFileProcessor creates a new RuleFileProcessor instance that recognises
+// the default frontmatter delimiters (YAML ---, TOML +++, JSON ;;;)
 func NewRuleFileProcessor(logger *logging.AppLogger, repositoryPaths map[string]string, maxFileSize int64) *RuleFileProcessor {
+	delimiters := defaultFrontmatterDelimiters()
+	formats, err := buildFrontmatterFormats(delimiters)
+	if err != nil {
+		// The defaults are fixed in code, so this is a programming error
+		panic(fmt.Sprintf("invalid default frontmatter delimiters: %v", err))
+	}
+
+	p := newRuleFileProcessor(logger, repositoryPaths, maxFileSize, formats)
+	p.delimiters = delimiters
+	return p
+}
+
+// NewRuleFileProcessorWithDelimiters creates a RuleFileProcessor that recognises
+// exactly the given frontmatter delimiters instead of the defaults.
+// Returns an error if any delimiter is empty or uses an unsupported syntax.
+func NewRuleFileProcessorWithDelimiters(logger *logging.AppLogger, repositoryPaths map[string]string, maxFileSize int64, delimiters []config.FrontmatterDelimiter) (*RuleFileProcessor, error) {
+	formats, err := buildFrontmatterFormats(delimiters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid frontmatter delimiters: %w", err)
+	}
+
+	p := newRuleFileProcessor(logger, repositoryPaths, maxFileSize, formats)
+	p.delimiters = delimiters
+	return p, nil
+}
+
+func newRuleFileProcessor(logger *logging.AppLogger, repositoryPaths map[string]string, maxFileSize int64, formats []*frontmatter.Format) *RuleFileProcessor {
+	// Repository manifests constrain rule frontmatter. A manifest that cannot be
+	// loaded was already reported during preparation, so it simply does not apply.
+	manifests := make(map[string]*repository.Manifest)
+	for id, path := range repositoryPaths {
+		if path == "" {
+			continue
+		}
+		manifest, err := repository.LoadManifest(path)
+		if err != nil {
+			logger.Debug("Ignoring repository manifest", "repository_id", id, "error", err)
+			continue
+		}
+		if manifest != nil {
+			manifests[id] = manifest
+		}
+	}
+
 	return &RuleFileProcessor{
-		logger:          logger,
-		repositoryPaths: repositoryPaths,
-		toolRegistry:    make(map[string]*RuleFileTool),
-		maxFileSize:     maxFileSize,
+		logger:             logger,
+		repositoryPaths:    repositoryPaths,
+		toolRegistry:       make(map[string]*RuleFileTool),
+		maxFileSize:        maxFileSize,
+		frontmatterFormats: formats,
+		extensions:         filemanager.RuleExtensions(),
+		manifests:          manifests,
+		toolPrefixes:       make(map[string]string),
+		contentPolicy:      fileops.StandardContentPolicy(),
+		contentPolicies:    make(map[string]fileops.ContentPolicy),
+	}
+}
+
+// policyFor returns the content policy rules of the repository with
+// repositoryID are checked against
+func (p *RuleFileProcessor) policyFor(repositoryID string) fileops.ContentPolicy {
+	if policy, ok := p.contentPolicies[repositoryID]; ok {
+		return policy
 	}
+	return p.contentPolicy
 }
 
 // ParseRuleFiles takes a list of file items and parses them for frontmatter
@@ -78,17 +184,32 @@ func (p *RuleFileProcessor) ParseRuleFiles(files []filemanager.FileItem) ([]Rule
 		return nil, fmt.Errorf("repository paths not initialized")
 	}
 
+	// Files are read and parsed by a bounded pool of workers, which hides the
+	// latency of network file systems; each result keeps its file's slot so
+	// the rules come out in scan order
+	parsed := make([]*RuleFile, len(files))
+	var group errgroup.Group
+	group.SetLimit(fileops.DefaultScanWorkers)
+	for i, file := range files {
+		group.Go(func() error {
+			ruleFile, err := p.processRuleFile(file)
+			if err != nil {
+				p.logger.Debug("Skipping file", "name", file.Name, "reason", err)
+				return nil
+			}
+			parsed[i] = ruleFile
+			return nil
+		})
+	}
+	_ = group.Wait() // Files that fail are skipped, not errors
+
 	var ruleFiles []RuleFile
 	var skippedCount int
-
-	for _, file := range files {
-		ruleFile, err := p.processRuleFile(file)
-		if err != nil {
-			p.logger.Debug("Skipping file", "name", file.Name, "reason", err)
+	for _, ruleFile := range parsed {
+		if ruleFile == nil {
 			skippedCount++
 			continue
 		}
-
 		ruleFiles = append(ruleFiles, *ruleFile)
 	}
 
@@ -102,6 +223,10 @@ func (p *RuleFileProcessor) ParseRuleFiles(files []filemanager.FileItem) ([]Rule
 
 // processRuleFile handles the complete processing pipeline for a single rule file
 func (p *RuleFileProcessor) processRuleFile(file filemanager.FileItem) (*RuleFile, error) {
+	if !p.acceptsFile(file.Name) {
+		return nil, p.extensionError(file.Name)
+	}
+
 	// Get the repository path using the repository paths map
 	repoPath, exists := p.repositoryPaths[file.RepositoryID]
 	if !exists {
@@ -122,40 +247,235 @@ func (p *RuleFileProcessor) processRuleFile(file filemanager.FileItem) (*RuleFil
 		return nil, fmt.Errorf("file validation failed: %w", err)
 	}
 
+	// Reuse the rule parsed from the file while the file and the settings it
+	// was parsed with are unchanged
+	info, err := os.Stat(absolutePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	cache := filemanager.ActiveScanCache()
+	key := p.cacheKey(file.RepositoryID)
+	var cached RuleFile
+	if cache.Metadata(absolutePath, info, key, &cached) {
+		cached.FileName, cached.FilePath, cached.RepositoryID = file.Name, file.Path, file.RepositoryID
+		for _, warning := range cached.ContentWarnings {
+			p.logger.Warn("Rule content flagged by content policy", "file", file.Path, "finding", warning)
+		}
+		return &cached, nil
+	}
+
 	// Read and parse file content
 	content, err := os.ReadFile(absolutePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	ruleFile, err := p.newRuleFile(file, content)
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.SetMetadata(absolutePath, info, key, ruleFile); err != nil {
+		p.logger.Debug("Failed to cache rule file", "file", file.Path, "error", err)
+	}
+	return ruleFile, nil
+}
+
+// ruleCacheVersion is part of every cacheKey; bump it when the RuleFile parsed
+// from the same content and settings changes, so cached rules are parsed again
+const ruleCacheVersion = 2
+
+// cacheKey identifies the settings rules of the repository with repositoryID
+// are parsed with, for the scan cache: rules cached under another key are
+// parsed again.
+func (p *RuleFileProcessor) cacheKey(repositoryID string) string {
+	// Plain data, so encoding cannot fail
+	settings, _ := json.Marshal(struct {
+		Version          int
+		Delimiters       []config.FrontmatterDelimiter
+		Policy           fileops.ContentPolicy
+		Manifest         *repository.Manifest
+		AutoDescriptions bool
+	}{ruleCacheVersion, p.delimiters, p.policyFor(repositoryID), p.manifests[repositoryID], p.autoDescriptions})
+	sum := sha256.Sum256(settings)
+	return hex.EncodeToString(sum[:])
+}
+
+// processRevisionFile processes a rule file read from a commit of the repository
+// at repoPath. The file access checks of processRuleFile are replaced by the
+// ones that still apply to content that is not on disk.
+func (p *RuleFileProcessor) processRevisionFile(repositoryID, repoPath string, file repository.RevisionFile) (*RuleFile, error) {
+	if !p.acceptsFile(file.Path) {
+		return nil, p.extensionError(file.Path)
+	}
+	if err := fileops.ValidatePathSecurity(file.Path); err != nil {
+		return nil, fmt.Errorf("file validation failed: path security check failed: %w", err)
+	}
+	if int64(len(file.Content)) > p.maxFileSize {
+		return nil, fmt.Errorf("file validation failed: file size %d exceeds limit %d", len(file.Content), p.maxFileSize)
+	}
+
+	// Report the path the file has in the working tree, as scanned files do
+	item := filemanager.FileItem{
+		Name:         path.Base(file.Path),
+		Path:         filepath.Join(repoPath, filepath.FromSlash(file.Path)),
+		RepositoryID: repositoryID,
+	}
+	return p.newRuleFile(item, file.Content)
+}
+
+// newRuleFile validates a rule file's content and builds its RuleFile
+func (p *RuleFileProcessor) newRuleFile(file filemanager.FileItem, content []byte) (*RuleFile, error) {
+	parsed, err := p.parseRuleContent(content, file.Name, file.RepositoryID)
+	if err != nil {
+		return nil, err
+	}
+	matter := parsed.matter
+	for _, warning := range parsed.warnings {
+		p.logger.Warn("Rule content flagged by content policy", "file", file.Path, "finding", warning)
+	}
+	if parsed.descriptionGenerated {
+		p.logger.Debug("Derived description for rule without one", "file", file.Path, "description", matter.Description)
+	}
+
+	// Create and return RuleFile
+	ruleFile := &RuleFile{
+		FileName:             file.Name,
+		FilePath:             file.Path,
+		RepositoryID:         file.RepositoryID,
+		Description:          matter.Description,
+		DescriptionGenerated: parsed.descriptionGenerated,
+		Name:                 matter.Name,
+		ApplyTo:              matter.ApplyTo,
+		Scope:                matter.Scope,
+		Tags:                 matter.Tags,
+		Priority:             matter.Priority,
+		Version:              matter.Version,
+		Deprecated:           matter.Deprecated,
+		License:              matter.License,
+		Attribution:          matter.Attribution,
+		Expires:              matter.Expires,
+		ReviewBy:             matter.ReviewBy,
+		ContentWarnings:      parsed.warnings,
+		Content:              string(parsed.body),
+	}
+
+	return ruleFile, nil
+}
+
+// parsedRule is a rule file's content split and validated by parseRuleContent
+type parsedRule struct {
+	matter               RuleFrontmatter
+	body                 []byte   // The content after the frontmatter
+	warnings             []string // What the repository's content policy warns about
+	descriptionGenerated bool     // The description was derived from the body, see deriveDescription
+}
+
+// parseRuleContent validates a rule file's content and frontmatter, returning
+// the parsed frontmatter, the body that follows it and what the repository's
+// content policy warns about. Hidden text the policy strips is removed from
+// both the frontmatter and the body.
+func (p *RuleFileProcessor) parseRuleContent(content []byte, fileName, repositoryID string) (*parsedRule, error) {
 	// Validate content security for malicious patterns
-	if err := fileops.ValidateContentSecurity(string(content)); err != nil {
+	applied, findings, err := p.policyFor(repositoryID).Apply(string(content))
+	if err != nil {
 		return nil, fmt.Errorf("content security validation failed: %w", err)
 	}
+	content = []byte(applied)
 
-	// Parse frontmatter
-	var matter RuleFrontmatter
-	body, err := frontmatter.Parse(bytes.NewReader(content), &matter)
+	// Parse frontmatter (YAML, TOML or JSON, tolerating a BOM and leading
+	// comments), or the keys of a YAML or JSON rule document
+	parsed := &parsedRule{}
+	matter := &parsed.matter
+	parsed.body, err = p.parseRuleFrontmatter(content, fileName, matter)
 	if err != nil {
 		return nil, fmt.Errorf("no valid frontmatter found: %w", err)
 	}
 
+	// Optionally derive a missing description from the body
+	if p.autoDescriptions && strings.TrimSpace(matter.Description) == "" {
+		if derived := deriveDescription(parsed.body); derived != "" {
+			matter.Description = derived + AutoDescriptionSuffix
+			parsed.descriptionGenerated = true
+		}
+	}
+
 	// Validate frontmatter fields
-	if err := p.validateFrontmatter(&matter, file.Name); err != nil {
+	if err := p.validateFrontmatter(matter, fileName, repositoryID); err != nil {
 		return nil, fmt.Errorf("invalid frontmatter: %w", err)
 	}
 
-	// Create and return RuleFile
-	ruleFile := &RuleFile{
-		FileName:    file.Name,
-		FilePath:    file.Path,
-		Description: matter.Description,
-		Name:        matter.Name,
-		ApplyTo:     matter.ApplyTo,
-		Content:     string(body),
+	// Validate against the repository's rulem.yaml schema and tag taxonomy;
+	// a derived description does not satisfy a schema that requires one
+	schemaMatter := *matter
+	if parsed.descriptionGenerated {
+		schemaMatter.Description = ""
+	}
+	if err := validateAgainstManifest(&schemaMatter, p.manifests[repositoryID]); err != nil {
+		return nil, fmt.Errorf("frontmatter does not match repository manifest: %w", err)
 	}
 
-	return ruleFile, nil
+	for _, finding := range findings {
+		parsed.warnings = append(parsed.warnings, finding.String())
+	}
+	return parsed, nil
+}
+
+// ValidateRuleContent reports why content would not be registered as a tool for
+// the given repository, or nil when it would be. It applies the same checks as
+// ProcessRuleFiles except for the file access checks, so editors can validate
+// unsaved content.
+func (p *RuleFileProcessor) ValidateRuleContent(content []byte, fileName, repositoryID string) error {
+	_, err := p.parseRuleContent(content, fileName, repositoryID)
+	return err
+}
+
+// Frontmatter parses the frontmatter of the rule file fileName without
+// validating it, for reading metadata from rules that need not be registered
+// as tools
+func (p *RuleFileProcessor) Frontmatter(content []byte, fileName string) (*RuleFrontmatter, error) {
+	var matter RuleFrontmatter
+	if _, err := p.parseRuleFrontmatter(content, fileName, &matter); err != nil {
+		return nil, fmt.Errorf("no valid frontmatter found: %w", err)
+	}
+	return &matter, nil
+}
+
+// DecodeFrontmatter decodes the frontmatter of the rule file fileName into v,
+// with the configured delimiters and preamble handling, for packages that
+// read keys of their own. Content without frontmatter leaves v unchanged.
+func (p *RuleFileProcessor) DecodeFrontmatter(content []byte, fileName string, v any) error {
+	if _, err := p.parseRuleFrontmatter(content, fileName, v); err != nil {
+		return fmt.Errorf("no valid frontmatter found: %w", err)
+	}
+	return nil
+}
+
+// FrontmatterFields returns the top-level keys set in the frontmatter of the
+// rule file fileName, sorted. Content without frontmatter has no fields.
+func (p *RuleFileProcessor) FrontmatterFields(content []byte, fileName string) ([]string, error) {
+	var fields map[string]any
+	if _, err := p.parseRuleFrontmatter(content, fileName, &fields); err != nil {
+		return nil, fmt.Errorf("no valid frontmatter found: %w", err)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// FrontmatterLines returns the 0-based lines of the opening and closing frontmatter
+// delimiters in content. ok is false when content has no terminated frontmatter,
+// as for YAML and JSON rule documents, which have no delimiters.
+func (p *RuleFileProcessor) FrontmatterLines(content []byte) (start, end int, ok bool) {
+	return frontmatterLines(content, p.frontmatterFormats)
+}
+
+// Manifest returns the rulem.yaml loaded for a repository, or nil when it has none
+func (p *RuleFileProcessor) Manifest(repositoryID string) *repository.Manifest {
+	return p.manifests[repositoryID]
 }
 
 // validateRuleFileAccess performs comprehensive file validation using fileops functions
@@ -204,9 +524,30 @@ func (p *RuleFileProcessor) validateRuleFileAccess(absolutePath, relativePath, r
 }
 
 // generateToolName creates a unique tool name from rule file metadata
-// Uses frontmatter name field if provided, otherwise generates from filename
-// Handles duplicate names by appending numeric suffixes
+// Uses BaseToolName and handles duplicate names by appending numeric suffixes
 func (p *RuleFileProcessor) generateToolName(ruleFile *RuleFile) string {
+	baseName := p.BaseToolName(ruleFile)
+
+	// Handle duplicate names by checking registry and appending numeric suffix
+	finalName := baseName
+	counter := 1
+
+	for {
+		if _, exists := p.toolRegistry[finalName]; !exists && !p.isBuiltinToolName(finalName) {
+			break
+		}
+		finalName = fmt.Sprintf("%s_%d", baseName, counter)
+		counter++
+	}
+
+	return finalName
+}
+
+// BaseToolName returns the tool name of a rule before duplicates are told
+// apart: its frontmatter name if set, otherwise one generated from its file
+// name, prefixed with the configured tool prefix and the repository's tool
+// prefix if it has one. Only FileName, Name and RepositoryID are used.
+func (p *RuleFileProcessor) BaseToolName(ruleFile *RuleFile) string {
 	var baseName string
 
 	// Use frontmatter name field if provided, but sanitize it for security
@@ -243,24 +584,22 @@ func (p *RuleFileProcessor) generateToolName(ruleFile *RuleFile) string {
 		baseName = "rule_file"
 	}
 
-	// Handle duplicate names by checking registry and appending numeric suffix
-	finalName := baseName
-	counter := 1
-
-	for {
-		if _, exists := p.toolRegistry[finalName]; !exists {
-			break
-		}
-		finalName = fmt.Sprintf("%s_%d", baseName, counter)
-		counter++
-	}
+	// Rules of a namespaced repository, e.g. a branch worktree, get its prefix
+	return p.toolNamePrefix + p.toolPrefixes[ruleFile.RepositoryID] + baseName
+}
 
-	return finalName
+// isBuiltinToolName reports whether name is taken by search_rules,
+// get_all_rules or propose_rule, with the configured tool prefix
+func (p *RuleFileProcessor) isBuiltinToolName(name string) bool {
+	base, ok := strings.CutPrefix(name, p.toolNamePrefix)
+	return ok && (base == SearchToolName || base == BundleToolName || base == ProposeToolName)
 }
 
 // generateToolDescription creates a comprehensive tool description from rule file metadata
 // Combines description and applyTo fields according to the format:
 // "{description} (applies to: {applyTo})" when applyTo is present, or just "{description}"
+// The scope globs follow as "(files: {globs})", and deprecated rules start
+// with DeprecatedPrefix so assistants prefer their replacements.
 func (p *RuleFileProcessor) generateToolDescription(ruleFile *RuleFile) string {
 	if ruleFile.Description == "" {
 		return "Rule file tool"
@@ -272,6 +611,12 @@ func (p *RuleFileProcessor) generateToolDescription(ruleFile *RuleFile) string {
 	if ruleFile.ApplyTo != "" {
 		description = fmt.Sprintf("%s (%s: %s)", description, ApplyToFormat, ruleFile.ApplyTo)
 	}
+	if len(ruleFile.Scope) > 0 {
+		description = fmt.Sprintf("%s (%s: %s)", description, ScopeFormat, strings.Join(ruleFile.Scope, ", "))
+	}
+	if ruleFile.Deprecated {
+		description = DeprecatedPrefix + description
+	}
 
 	description = ToolDescriptionPrefix + description
 
@@ -288,8 +633,55 @@ func (p *RuleFileProcessor) ProcessRuleFiles(files []filemanager.FileItem) (map[
 		return nil, fmt.Errorf("failed to parse rule files: %w", err)
 	}
 
+	p.registerRuleFiles(ruleFiles)
+
+	p.logger.Info("Rule file tool processing completed",
+		"inputFiles", len(files),
+		"processedTools", len(p.toolRegistry))
+
+	return p.toolRegistry, nil
+}
+
+// ProcessRevisionFiles converts rule files read from a commit of a repository
+// (see repository.ReadFilesAtRevision) to RuleFileTools, adding them to the
+// same registry as ProcessRuleFiles. Each rule's FilePath is where the file
+// sits in the repository's working tree, whatever the tree holds today.
+func (p *RuleFileProcessor) ProcessRevisionFiles(repositoryID string, files []repository.RevisionFile) (map[string]*RuleFileTool, error) {
+	repoPath, exists := p.repositoryPaths[repositoryID]
+	if !exists {
+		return nil, fmt.Errorf("repository path not found for repository ID: %s", repositoryID)
+	}
+
+	var ruleFiles []RuleFile
+	for _, file := range files {
+		ruleFile, err := p.processRevisionFile(repositoryID, repoPath, file)
+		if err != nil {
+			p.logger.Debug("Skipping file", "path", file.Path, "reason", err)
+			continue
+		}
+		ruleFiles = append(ruleFiles, *ruleFile)
+	}
+
+	p.registerRuleFiles(ruleFiles)
+
+	p.logger.Info("Revision rule file processing completed",
+		"repository_id", repositoryID,
+		"inputFiles", len(files),
+		"validRules", len(ruleFiles))
+
+	return p.toolRegistry, nil
+}
+
+// registerRuleFiles names each rule file that passes the tool filter and adds
+// it to the registry as a tool
+func (p *RuleFileProcessor) registerRuleFiles(ruleFiles []RuleFile) {
 	// Convert each valid rule file to a tool
 	for _, ruleFile := range ruleFiles {
+		if !p.filter.Match(&ruleFile) {
+			p.logger.Debug("Skipping rule outside the tool filter", "path", ruleFile.FilePath)
+			continue
+		}
+
 		// Generate unique tool name using fileops sanitization
 		toolName := p.generateToolName(&ruleFile)
 
@@ -306,51 +698,61 @@ func (p *RuleFileProcessor) ProcessRuleFiles(files []filemanager.FileItem) (map[
 		// Add to internal registry for duplicate name tracking
 		p.toolRegistry[toolName] = ruleFileTool
 	}
-
-	p.logger.Info("Rule file tool processing completed",
-		"inputFiles", len(files),
-		"processedTools", len(p.toolRegistry))
-
-	return p.toolRegistry, nil
 }
 
-// validateFrontmatter validates the frontmatter fields for security and correctness
-func (p *RuleFileProcessor) validateFrontmatter(matter *RuleFrontmatter, filename string) error {
-	// Check if description field exists (required)
-	if strings.TrimSpace(matter.Description) == "" {
-		return fmt.Errorf("missing required 'description' field")
-	}
-
-	// Validate description length and content
-	if len(matter.Description) > 500 {
-		return fmt.Errorf("description too long (max 500 characters)")
+// validateFrontmatter validates the frontmatter fields for correctness (see
+// frontmatter.Metadata.Validate) and security, checking their content against
+// the repository's content policy
+func (p *RuleFileProcessor) validateFrontmatter(matter *RuleFrontmatter, filename, repositoryID string) error {
+	if err := matter.Validate(); err != nil {
+		return err
 	}
 
-	// Check for potentially malicious content in description
-	if err := fileops.ValidateContentSecurity(matter.Description); err != nil {
+	// Check for potentially malicious content in the text fields
+	policy := p.policyFor(repositoryID)
+	if _, err := policy.Check(matter.Description); err != nil {
 		return fmt.Errorf("description contains potentially malicious content: %w", err)
 	}
-
-	// Validate name field if provided
 	if matter.Name != "" {
-		if len(matter.Name) > 100 {
-			return fmt.Errorf("name too long (max 100 characters)")
-		}
-
-		// Check for control characters or other suspicious content
-		if err := fileops.ValidateContentSecurity(matter.Name); err != nil {
+		if _, err := policy.Check(matter.Name); err != nil {
 			return fmt.Errorf("name contains invalid characters: %w", err)
 		}
 	}
-
-	// Validate applyTo field if provided
 	if matter.ApplyTo != "" {
-		if len(matter.ApplyTo) > 200 {
-			return fmt.Errorf("applyTo field too long (max 200 characters)")
+		if _, err := policy.Check(matter.ApplyTo); err != nil {
+			return fmt.Errorf("applyTo contains potentially malicious content: %w", err)
 		}
+	}
+	for _, glob := range matter.Scope {
+		if _, err := policy.Check(glob); err != nil {
+			return fmt.Errorf("scope contains potentially malicious content: %w", err)
+		}
+	}
 
-		if err := fileops.ValidateContentSecurity(matter.ApplyTo); err != nil {
-			return fmt.Errorf("applyTo contains potentially malicious content: %w", err)
+	return nil
+}
+
+// validateAgainstManifest checks that a rule sets every field the repository's
+// schema requires and only uses tags from its taxonomy. A nil manifest imposes
+// no constraints.
+func validateAgainstManifest(matter *RuleFrontmatter, manifest *repository.Manifest) error {
+	if manifest == nil {
+		return nil
+	}
+
+	for _, field := range manifest.Schema.Required {
+		present, err := matter.Has(field)
+		if err != nil {
+			return err
+		}
+		if !present {
+			return fmt.Errorf("missing field '%s' required by the repository schema", field)
+		}
+	}
+
+	for _, tag := range matter.Tags {
+		if !manifest.HasTag(tag) {
+			return fmt.Errorf("tag '%s' is not in the repository's tag list", tag)
 		}
 	}
 
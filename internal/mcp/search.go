@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// search_rules tool
+//
+// Besides one tool per rule, the server registers search_rules so assistants
+// can find the rules relevant to a task without loading every rule. It takes
+// free-text terms plus optional frontmatter filters and answers with the
+// matching tool names, their descriptions and a snippet of each rule.
+
+// SearchToolName is the name of the built-in rule search tool, after the
+// configured tool_prefix if any. Rule files cannot take it: a rule named
+// search_rules is registered as search_rules_1.
+const SearchToolName = "search_rules"
+
+const (
+	// defaultSearchLimit is the number of results returned when no limit is given
+	defaultSearchLimit = 10
+
+	// maxSnippetLength bounds the snippet shown for each result
+	maxSnippetLength = 160
+)
+
+// SearchQuery selects rules for search_rules. Every set field must match.
+type SearchQuery struct {
+	Terms       []string // Lowercase terms that must all appear in the name, description, tags or content
+	Tags        []string // Tags the rule must all have, ignoring case
+	Description []string // Lowercase keywords that must all appear in the description
+}
+
+// SearchResult is a rule matched by a SearchQuery
+type SearchResult struct {
+	Tool    *RuleFileTool
+	Snippet string // Content line that best shows the match
+	score   int
+}
+
+// newSearchTool describes the search_rules tool, registered as name
+func newSearchTool(name string) mcp.Tool {
+	return mcp.NewTool(name,
+		mcp.WithDescription("Search the available rules by text and frontmatter. Returns matching rule tool names with their descriptions and a snippet; call a rule's tool for its full text."),
+		mcp.WithString("query", mcp.Description("Space-separated terms that must all appear in the rule's name, description, tags or content")),
+		mcp.WithArray("tags", mcp.Description("Only return rules with all of these tags"), mcp.WithStringItems()),
+		mcp.WithString("description", mcp.Description("Space-separated keywords that must all appear in the rule's description")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Most results to return (default %d)", defaultSearchLimit)), mcp.Min(1)),
+	)
+}
+
+// searchToolHandler answers search_rules calls from the current tool registry
+func (s *Server) searchToolHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	query := SearchQuery{
+		Terms:       strings.Fields(strings.ToLower(request.GetString("query", ""))),
+		Tags:        request.GetStringSlice("tags", nil),
+		Description: strings.Fields(strings.ToLower(request.GetString("description", ""))),
+	}
+	if len(query.Terms) == 0 && len(query.Tags) == 0 && len(query.Description) == 0 {
+		return mcp.NewToolResultError("search_rules needs a query, tags or description keywords"), nil
+	}
+	limit := request.GetInt("limit", defaultSearchLimit)
+	if limit < 1 {
+		limit = defaultSearchLimit
+	}
+
+	s.logger.Debug("Processing rule search", "terms", query.Terms, "tags", query.Tags, "description", query.Description)
+
+	results := SearchRules(s.tools(), query)
+	return mcp.NewToolResultText(formatSearchResults(results, limit)), nil
+}
+
+// SearchRules returns the rules in tools matching query, best matches first:
+// matches in the name count most, then the description and tags, then the
+// content. Ties are sorted by priority, highest first, then by tool name.
+func SearchRules(tools map[string]*RuleFileTool, query SearchQuery) []SearchResult {
+	var results []SearchResult
+	for _, tool := range tools {
+		if !hasAllTags(tool.RuleFile.Tags, query.Tags) {
+			continue
+		}
+		description := strings.ToLower(tool.Description)
+		if !containsAll(description, query.Description) {
+			continue
+		}
+		if len(query.Terms) > 0 && !matchesAllTerms(tool, query.Terms) {
+			continue
+		}
+
+		results = append(results, SearchResult{
+			Tool:    tool,
+			Snippet: snippet(tool.RuleFile.Content, query.Terms),
+			score:   searchScore(tool, query.Terms),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		if pi, pj := results[i].Tool.RuleFile.Priority, results[j].Tool.RuleFile.Priority; pi != pj {
+			return pi > pj
+		}
+		return results[i].Tool.Name < results[j].Tool.Name
+	})
+	return results
+}
+
+// hasAllTags reports whether tags includes every wanted tag, ignoring case
+func hasAllTags(tags, wanted []string) bool {
+	for _, want := range wanted {
+		if !slices.ContainsFunc(tags, func(tag string) bool { return strings.EqualFold(tag, want) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAll reports whether every lowercase term appears in text
+func containsAll(text string, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// searchScore weighs where the terms appear in a rule
+func searchScore(tool *RuleFileTool, terms []string) int {
+	name := strings.ToLower(tool.Name)
+	description := strings.ToLower(tool.Description)
+	tags := strings.ToLower(strings.Join(tool.RuleFile.Tags, " "))
+	content := strings.ToLower(tool.RuleFile.Content)
+
+	score := 0
+	for _, term := range terms {
+		if strings.Contains(name, term) {
+			score += 10
+		}
+		if strings.Contains(description, term) {
+			score += 5
+		}
+		if strings.Contains(tags, term) {
+			score += 5
+		}
+		score += min(strings.Count(content, term), 5)
+	}
+	return score
+}
+
+// snippet returns the first content line containing a term, or the first
+// non-empty line when there are no terms, shortened to maxSnippetLength
+func snippet(content string, terms []string) string {
+	var first string
+	for line := range strings.Lines(content) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if first == "" {
+			first = line
+		}
+		lower := strings.ToLower(line)
+		for _, term := range terms {
+			if i := strings.Index(lower, term); i >= 0 {
+				return shortenAround(line, i)
+			}
+		}
+	}
+	return shortenAround(first, 0)
+}
+
+// shortenAround cuts line to maxSnippetLength bytes around the byte offset at,
+// marking cut ends with "..."
+func shortenAround(line string, at int) string {
+	if len(line) <= maxSnippetLength {
+		return line
+	}
+	start := max(0, at-maxSnippetLength/4)
+	end := min(len(line), start+maxSnippetLength)
+	start = max(0, end-maxSnippetLength)
+
+	// Keep whole runes
+	for start > 0 && !utf8.RuneStart(line[start]) {
+		start++
+	}
+	for end < len(line) && !utf8.RuneStart(line[end]) {
+		end--
+	}
+
+	text := line[start:end]
+	if start > 0 {
+		text = "..." + text
+	}
+	if end < len(line) {
+		text += "..."
+	}
+	return text
+}
+
+// formatSearchResults renders up to limit results for the assistant
+func formatSearchResults(results []SearchResult, limit int) string {
+	if len(results) == 0 {
+		return "No rules match the search."
+	}
+
+	var b strings.Builder
+	switch {
+	case len(results) > limit:
+		fmt.Fprintf(&b, "%d rules match; showing the best %d.", len(results), limit)
+		results = results[:limit]
+	case len(results) == 1:
+		b.WriteString("1 rule matches.")
+	default:
+		fmt.Fprintf(&b, "%d rules match.", len(results))
+	}
+	b.WriteString(" Call a rule's tool for its full text.\n")
+
+	for _, result := range results {
+		fmt.Fprintf(&b, "\n- %s: %s", result.Tool.Name, result.Tool.Description)
+		if len(result.Tool.RuleFile.Tags) > 0 {
+			fmt.Fprintf(&b, " [tags: %s]", strings.Join(result.Tool.RuleFile.Tags, ", "))
+		}
+		if result.Snippet != "" {
+			fmt.Fprintf(&b, "\n  > %s", result.Snippet)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// searchTools returns rule tools for search tests
+func searchTools() map[string]*RuleFileTool {
+	newTool := func(name, description string, tags []string, content string) *RuleFileTool {
+		return &RuleFileTool{Name: name, Description: description, RuleFile: &RuleFile{Tags: tags, Content: content}}
+	}
+	return map[string]*RuleFileTool{
+		"go_errors":  newTool("go_errors", "Error handling in Go", []string{"go"}, "# Errors\n\nWrap errors with %w so callers can inspect them.\n"),
+		"go_style":   newTool("go_style", "Go style guide", []string{"go", "style"}, "# Style\n\nReturn errors last.\nKeep functions short.\n"),
+		"py_testing": newTool("py_testing", "Testing Python code", []string{"python"}, "# Testing\n\nUse pytest fixtures.\n"),
+	}
+}
+
+func TestSearchRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       SearchQuery
+		wantNames   []string
+		wantSnippet string
+	}{
+		{name: "terms ranked by where they match", query: SearchQuery{Terms: []string{"errors"}}, wantNames: []string{"go_errors", "go_style"}, wantSnippet: "# Errors"},
+		{name: "all terms must match", query: SearchQuery{Terms: []string{"errors", "short"}}, wantNames: []string{"go_style"}, wantSnippet: "Return errors last."},
+		{name: "tags ignore case", query: SearchQuery{Tags: []string{"GO", "style"}}, wantNames: []string{"go_style"}, wantSnippet: "# Style"},
+		{name: "description keywords", query: SearchQuery{Description: []string{"testing"}}, wantNames: []string{"py_testing"}},
+		{name: "filters combine", query: SearchQuery{Terms: []string{"pytest"}, Tags: []string{"go"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := SearchRules(searchTools(), tt.query)
+			var names []string
+			for _, result := range results {
+				names = append(names, result.Tool.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Fatalf("got %v, want %v", names, tt.wantNames)
+			}
+			if tt.wantSnippet != "" && results[0].Snippet != tt.wantSnippet {
+				t.Errorf("snippet = %q, want %q", results[0].Snippet, tt.wantSnippet)
+			}
+		})
+	}
+}
+
+func TestSearchRulesPriority(t *testing.T) {
+	tools := searchTools()
+	tools["go_style"].RuleFile.Priority = 10
+
+	results := SearchRules(tools, SearchQuery{Tags: []string{"go"}})
+	if len(results) != 2 || results[0].Tool.Name != "go_style" {
+		t.Errorf("got %d results, want go_style first as equal matches are ordered by priority", len(results))
+	}
+}
+
+func TestShortenAround(t *testing.T) {
+	line := strings.Repeat("é", 100) + "needle" + strings.Repeat("x", 200)
+	got := shortenAround(line, strings.Index(line, "needle"))
+	if !strings.Contains(got, "needle") || !strings.HasPrefix(got, "...") || !strings.HasSuffix(got, "...") {
+		t.Errorf("shortenAround() = %q, want the needle with both ends cut", got)
+	}
+	if len(got) > maxSnippetLength+6 || !strings.Contains(got, "é") {
+		t.Errorf("shortenAround() = %q (%d bytes), want at most %d bytes of whole runes", got, len(got), maxSnippetLength)
+	}
+}
+
+func TestServer_SearchToolHandler(t *testing.T) {
+	server, _ := createTestServer(t)
+	server.toolRegistry = searchTools()
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		var request mcp.CallToolRequest
+		request.Params.Name = SearchToolName
+		request.Params.Arguments = args
+		result, err := server.searchToolHandler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("searchToolHandler: %v", err)
+		}
+		return result
+	}
+
+	result := call(map[string]any{"query": "Errors", "limit": 1})
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "showing the best 1") || !strings.Contains(text, "- go_errors: Error handling in Go [tags: go]") || strings.Contains(text, "go_style") {
+		t.Errorf("unexpected result %q", text)
+	}
+
+	result = call(map[string]any{"tags": []any{"python"}})
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.HasPrefix(text, "1 rule matches.") || !strings.Contains(text, "py_testing") {
+		t.Errorf("unexpected tag search result %q", text)
+	}
+
+	if result := call(map[string]any{}); !result.IsError {
+		t.Error("expected an error without a query, tags or description")
+	}
+}
+
+func TestGenerateToolName_ReservesSearchTool(t *testing.T) {
+	processor, _, _ := createTestRuleFileProcessor(t)
+	if name := processor.generateToolName(&RuleFile{FileName: "search_rules.md"}); name != "search_rules_1" {
+		t.Errorf("generateToolName() = %q, want search_rules_1", name)
+	}
+}
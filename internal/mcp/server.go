@@ -5,30 +5,75 @@
 // exposes tools and resources for accessing organized instruction files.
 //
 // The implementation uses the mcp-go library for protocol handling and communicates
-// via stdin/stdout using JSON-RPC 2.0 as specified by the MCP standard.
+// via stdin/stdout using JSON-RPC 2.0 as specified by the MCP standard, or over
+// HTTP when a network transport is enabled (see transport.go).
 package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"reflect"
 	"rulem/internal/config"
 	"rulem/internal/filemanager"
 	"rulem/internal/logging"
+	"rulem/internal/quarantine"
 	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// serverName is the name the server gives clients
+const serverName = "rulem"
+
 type Server struct {
 	config               *config.Config
 	logger               *logging.AppLogger
 	mcpServer            *server.MCPServer
 	toolRegistry         map[string]*RuleFileTool        // Maps tool names to their RuleFileTool instances
+	serverTools          map[string]server.ServerTool    // Every tool registered with mcpServer, by name
+	registryMu           sync.RWMutex                    // Guards toolRegistry while rules are reloaded
 	ruleProcessor        *RuleFileProcessor              // Handles rule file parsing and processing
 	preparedRepositories []repository.PreparedRepository // Prepared repositories with paths and sync status
+	socketPath           string                          // Rule socket path, empty when the socket is disabled
+	socketListener       net.Listener                    // Rule socket listener while serving
+	socketMu             sync.Mutex                      // Guards socketListener between Start and Stop
+	usageLog             *UsageLog                       // Records served rules, nil when disabled
+	auditLog             *AuditLog                       // Records every access to a rule, nil when disabled
+	revisions            map[string]string               // Maps repository IDs to revisions requested with ServeAt
+	pinned               map[string]pinnedRevision       // Repositories served at a revision, keyed by ID
+	chunkSize            int                             // Largest part of a rule served at once, 0 to serve rules whole
+	resources            map[string]*RuleFileTool        // Maps rule resource URIs to the tools serving them
+	resourcesMu          sync.RWMutex                    // Guards resources while they are replaced
+	transport            Transport                       // How clients connect, stdio when empty
+	httpOptions          HTTPOptions                     // Address and auth token of network transports
+	httpServer           *http.Server                    // Network transport server while serving
+	httpMu               sync.Mutex                      // Guards httpServer between Start and Stop
+	watch                bool                            // Reload the rules when rule files change
+	watcher              io.Closer                       // Rule file watcher while serving, see startWatcher
+	watchMu              sync.Mutex                      // Guards watcher between Start and Stop
+	reloadMu             sync.Mutex                      // Serializes rule reloads
+	deferredReload       *time.Timer                     // Retries a reload held back by a sync, guarded by reloadMu
+	limiter              *sessionLimiter                 // Enforces per-session limits, nil when unlimited
+	toolFilter           ToolFilter                      // Selects the rules registered as tools, see EnableToolFilter
+	maxFileSize          int64                           // Largest rule file served, DefaultMaxFileSize when 0
+	proposalsDisabled    bool                            // Never register propose_rule, see DisableProposals
 }
 
+// DefaultMaxFileSize is the largest rule file served, unless EnableMaxFileSize
+// lowers or raises it
+const DefaultMaxFileSize int64 = 5 * 1024 * 1024 // 5 MB
+
 // NewServer creates a new MCP server instance
 func NewServer(cfg *config.Config, logger *logging.AppLogger) *Server {
 	return &Server{
@@ -38,45 +83,69 @@ func NewServer(cfg *config.Config, logger *logging.AppLogger) *Server {
 	}
 }
 
+// EnableMaxFileSize skips rule files larger than bytes instead of
+// DefaultMaxFileSize. Call it before Start; it also applies when rules are
+// reloaded.
+func (s *Server) EnableMaxFileSize(bytes int64) {
+	s.maxFileSize = bytes
+}
+
 // Start initializes and starts the MCP server
 func (s *Server) Start() error {
 	s.logger.Info("Initializing MCP server")
 
-	// Create MCP server instance
-	s.mcpServer = server.NewMCPServer("rulem", "1.0.0", server.WithToolCapabilities(true))
-
-	// Prepare all repositories
+	// Prepare all repositories and the rule file processor.
 	// This validates, prepares, syncs, and logs all repositories.
-	prepared, err := repository.PrepareAllRepositories(context.Background(), s.config.Repositories, s.logger)
-	if err != nil {
-		s.logger.Error("Multi-repository preparation failed", "error", err)
-		return fmt.Errorf("failed to prepare repositories: %w", err)
+	if err := s.InitializeComponents(); err != nil {
+		return err
 	}
 
-	// Store prepared repositories for later use
-	s.preparedRepositories = prepared
-
-	// Build repository paths map for rule file processor
-	repositoryPaths := make(map[string]string, len(prepared))
-	for _, prep := range prepared {
-		repositoryPaths[prep.ID()] = prep.LocalPath
+	// Create MCP server instance, describing the repositories to connected assistants
+	opts := []server.ServerOption{
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true),
+		server.WithInstructions(s.buildInstructions()),
 	}
+	s.mcpServer = server.NewMCPServer(serverName, "1.0.0", append(opts, s.sessionLimitOptions()...)...)
 
-	// Initialize rule file processor with repository paths
-	maxFileSize := int64(5 * 1024 * 1024) // 5 MB
-	s.ruleProcessor = NewRuleFileProcessor(s.logger, repositoryPaths, maxFileSize)
-
-	// Register rule files as MCP tools
-	err = s.RegisterRuleFileTools()
-	if err != nil {
+	// Register rule files as MCP tools and resources
+	if err := s.RegisterRuleFileTools(); err != nil {
 		s.logger.Error("Failed to register rule file tools", "error", err)
 		return err
 	}
 
 	s.logger.Info("Successfully registered rule file tools", "toolCount", len(s.toolRegistry))
 
+	// Pick up added and edited rule files without a restart
+	if s.watch {
+		if err := s.startWatcher(); err != nil {
+			s.logger.Warn("Failed to watch rule files, restart the server to pick up changes", "error", err)
+		} else {
+			defer s.closeWatcher()
+		}
+	}
+
+	// Serve the same registry to non-MCP consumers over the rule socket
+	if s.socketPath != "" {
+		if err := s.ServeSocket(s.socketPath); err != nil {
+			s.logger.Error("Failed to start rule socket", "error", err)
+			return err
+		}
+		defer s.closeSocket()
+	}
+
 	s.logger.Info("MCP server setup complete")
 
+	// Serve editors over HTTP when running as a daemon
+	if s.isNetworkTransport() {
+		if err := s.serveHTTP(); err != nil {
+			s.logger.Error("MCP server error", "error", err)
+			return fmt.Errorf("MCP server failed: %w", err)
+		}
+		s.logger.Info("MCP server stopped")
+		return nil
+	}
+
 	// Start the stdio server
 	s.logger.Info("Starting MCP stdio server")
 	if err := server.ServeStdio(s.mcpServer); err != nil {
@@ -92,7 +161,7 @@ func (s *Server) Start() error {
 func (s *Server) Stop() error {
 	s.logger.Info("Stopping MCP server")
 	// The mcp-go server will handle cleanup when context is cancelled
-	return nil
+	return errors.Join(s.closeHTTP(), s.closeSocket(), s.closeWatcher())
 }
 
 // getRepoFiles scans all repositories and returns the aggregated list of files
@@ -108,41 +177,183 @@ func (s *Server) getRepoFiles() ([]filemanager.FileItem, error) {
 		return nil, fmt.Errorf("failed to scan repositories: %w", err)
 	}
 
+	// Rules that newly appeared in repositories with quarantine are not served
+	// until they are approved. Repositories served at a revision contribute
+	// the files of that revision instead of their worktree, see
+	// registerPinnedRevisions.
+	files, err = quarantine.Filter(s.worktreeFiles(files), s.worktreeRepositories(), s.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check quarantined rules: %w", err)
+	}
+
 	return files, nil
 }
 
 // RegisterRuleFileTools registers all valid rule files as MCP tools
-// This method scans rule files with frontmatter and registers them as callable MCP tools
+// This method scans rule files with frontmatter and registers them as callable MCP tools,
+// and as resources at rulem://<repository-id>/<path>
 func (s *Server) RegisterRuleFileTools() error {
+	toolsMap, err := s.loadRuleFileTools()
+	if err != nil {
+		return err
+	}
+
+	// Register the tools with the MCP server
+	s.registerTools(toolsMap)
+
+	// Let assistants find relevant rules without loading each one, and load
+	// many at once
+	builtins, err := s.builtinTools()
+	if err != nil {
+		return err
+	}
+	for _, tool := range builtins {
+		s.serverTools[tool.Tool.Name] = tool
+	}
+	s.mcpServer.AddTools(builtins...)
+
+	// Expose the same rules as resources for clients that prefer them
+	s.registerResources(toolsMap)
+
+	// Report tools clients may reject now, rather than leaving clients to fail
+	return s.checkCompliance()
+}
+
+// loadRuleFileTools scans the repositories and processes their rule files into tools
+func (s *Server) loadRuleFileTools() (map[string]*RuleFileTool, error) {
 	// Get all files from repository
 	files, err := s.getRepoFiles()
 	if err != nil {
-		return fmt.Errorf("failed to get repository files: %w", err)
+		return nil, fmt.Errorf("failed to get repository files: %w", err)
 	}
 
 	// Process rule files using the rule processor
 	toolsMap, err := s.ruleProcessor.ProcessRuleFiles(files)
 	if err != nil {
-		return fmt.Errorf("failed to process rule files: %w", err)
+		return nil, fmt.Errorf("failed to process rule files: %w", err)
+	}
+	if err := filemanager.ActiveScanCache().Save(); err != nil {
+		s.logger.Debug("Failed to save scan cache", "error", err)
 	}
+	if err := s.registerPinnedRevisions(); err != nil {
+		return nil, err
+	}
+	return toolsMap, nil
+}
 
+// registerTools makes tools the server's registry and brings the MCP server in
+// line with it, touching only the tools in the returned diff: tools that are
+// new or changed are (re)added and tools that are gone are deleted. Clients
+// get a single notification that the tool list changed, or none when nothing
+// did.
+//
+// The registry keeps a copy of tools, so the diff is always taken against
+// the tools that are live, even when the caller changes its map afterwards.
+func (s *Server) registerTools(tools map[string]*RuleFileTool) registryDiff {
 	// Set the server's registry to the processed tools
-	s.toolRegistry = toolsMap
+	tools = maps.Clone(tools)
+	s.registryMu.Lock()
+	previous := s.toolRegistry
+	s.toolRegistry = tools
+	s.registryMu.Unlock()
+
+	diff := diffRegistry(previous, tools)
+	if s.serverTools == nil {
+		s.serverTools = make(map[string]server.ServerTool)
+	}
+	for _, toolName := range diff.Removed {
+		delete(s.serverTools, toolName)
+	}
 
-	// Loop through tools and register them with the MCP server
-	for toolName, tool := range toolsMap {
+	// Create the MCP tools of the new and changed rules
+	var changed []server.ServerTool
+	for _, toolName := range slices.Concat(diff.Added, diff.Updated) {
+		tool := tools[toolName]
 		s.logger.Debug("Registering MCP tool", "name", toolName, "description", tool.Description)
-		// create new MCP tool and its handler
-		mcpTool := mcp.NewTool(toolName, mcp.WithDescription(tool.Description))
+		mcpTool := s.newRuleTool(toolName, tool)
 		handler, err := s.getRulefileToolHandler(toolName)
 		if err != nil {
 			s.logger.Error("Failed to get tool handler", "tool", toolName, "error", err)
 			continue
 		}
-		s.mcpServer.AddTool(mcpTool, handler)
+		entry := server.ServerTool{Tool: mcpTool, Handler: handler}
+		s.serverTools[toolName] = entry
+		changed = append(changed, entry)
 	}
 
-	return nil
+	// Each call below notifies clients once, so a change that both removes
+	// and adds tools replaces the whole list instead of deleting then adding
+	switch {
+	case len(diff.Removed) > 0 && len(changed) > 0:
+		s.mcpServer.SetTools(slices.Collect(maps.Values(s.serverTools))...)
+	case len(diff.Removed) > 0:
+		s.mcpServer.DeleteTools(diff.Removed...)
+	case len(changed) > 0:
+		s.mcpServer.AddTools(changed...)
+	}
+	return diff
+}
+
+// newRuleTool describes the MCP tool serving a rule
+func (s *Server) newRuleTool(toolName string, tool *RuleFileTool) mcp.Tool {
+	opts := []mcp.ToolOption{mcp.WithDescription(tool.Description)}
+	if s.isChunked(tool.RuleFile.Content) {
+		opts = append(opts, mcp.WithNumber(partArgument,
+			mcp.Description("Part of the rule to return, starting at 1; the result says how many parts there are"),
+			mcp.Min(1)))
+	}
+	mcpTool := mcp.NewTool(toolName, opts...)
+	mcpTool.Meta = ruleMeta(tool.RuleFile)
+	return mcpTool
+}
+
+// registryDiff is how a rescan changed the tool registry, by tool name
+type registryDiff struct {
+	Added   []string
+	Removed []string
+	Updated []string // Tools whose rule changed, see sameRule
+}
+
+// Empty reports whether the registry is unchanged
+func (d registryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Updated) == 0
+}
+
+// diffRegistry compares the registry next to previous, listing each group of
+// tool names sorted
+func diffRegistry(previous, next map[string]*RuleFileTool) registryDiff {
+	var diff registryDiff
+	for toolName, tool := range next {
+		old, exists := previous[toolName]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, toolName)
+		case !sameRule(old, tool):
+			diff.Updated = append(diff.Updated, toolName)
+		}
+	}
+	for toolName := range previous {
+		if _, exists := next[toolName]; !exists {
+			diff.Removed = append(diff.Removed, toolName)
+		}
+	}
+	slices.Sort(diff.Added)
+	slices.Sort(diff.Removed)
+	slices.Sort(diff.Updated)
+	return diff
+}
+
+// sameRule reports whether two tools serve the same rule the same way
+func sameRule(a, b *RuleFileTool) bool {
+	return a.Description == b.Description && reflect.DeepEqual(*a.RuleFile, *b.RuleFile)
+}
+
+// tools returns the current tool registry. The registry is replaced, never
+// modified, when rules are reloaded, so callers may read it without locking.
+func (s *Server) tools() map[string]*RuleFileTool {
+	s.registryMu.RLock()
+	defer s.registryMu.RUnlock()
+	return s.toolRegistry
 }
 
 // getRulefileToolHandler creates an MCP tool handler function for a specific rule file tool.
@@ -168,13 +379,18 @@ func (s *Server) RegisterRuleFileTools() error {
 //	mcpServer.AddTool(tool, handler)
 func (s *Server) getRulefileToolHandler(toolName string) (server.ToolHandlerFunc, error) {
 	// Validate tool exists in registry at handler creation time
-	tool, exists := s.toolRegistry[toolName]
+	tool, exists := s.tools()[toolName]
 	if !exists {
 		return nil, fmt.Errorf("tool '%s' not found in registry", toolName)
 	}
 
 	// Capture the content at handler creation time for better performance
 	content := tool.RuleFile.Content
+	meta := ruleMeta(tool.RuleFile)
+	var chunks []string
+	if s.isChunked(content) {
+		chunks = splitChunks(content, s.chunkSize)
+	}
 
 	// Return the handler function that will be called for each tool invocation
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -190,11 +406,65 @@ func (s *Server) getRulefileToolHandler(toolName string) (server.ToolHandlerFunc
 		default:
 		}
 
+		if len(tool.RuleFile.ContentWarnings) > 0 {
+			s.logger.Warn("Serving rule flagged by content policy", "tool", toolName, "findings", tool.RuleFile.ContentWarnings)
+		}
+
+		// Large rules are served in parts over network transports
+		if chunks != nil {
+			return s.chunkedResult(ctx, request, tool, chunks), nil
+		}
+
+		s.recordUsage(tool, clientName(ctx))
+		s.auditRule(ctx, AuditToolCall, tool, AuditSuccess, "")
+
 		// Return the pre-processed rule file content
-		return mcp.NewToolResultText(content), nil
+		result := mcp.NewToolResultText(content)
+		result.Meta = meta
+		return result, nil
 	}, nil
 }
 
+// ruleMeta returns the `_meta` object for a rule's tool and results, carrying
+// its license and attribution so clients can credit the rule, its version,
+// scope, priority and deprecation, and what the content policy warned about
+// in it. Returns nil when there is none of these.
+func ruleMeta(rule *RuleFile) *mcp.Meta {
+	fields := ruleMetaFields(rule)
+	if len(fields) == 0 {
+		return nil
+	}
+	return mcp.NewMetaFromMap(fields)
+}
+
+// ruleMetaFields returns the frontmatter and content warning fields of a
+// rule's `_meta`
+func ruleMetaFields(rule *RuleFile) map[string]any {
+	fields := make(map[string]any)
+	if rule.License != "" {
+		fields["license"] = rule.License
+	}
+	if rule.Attribution != "" {
+		fields["attribution"] = rule.Attribution
+	}
+	if rule.Version != "" {
+		fields["version"] = rule.Version
+	}
+	if len(rule.Scope) > 0 {
+		fields["scope"] = rule.Scope
+	}
+	if rule.Priority != 0 {
+		fields["priority"] = rule.Priority
+	}
+	if rule.Deprecated {
+		fields["deprecated"] = true
+	}
+	if len(rule.ContentWarnings) > 0 {
+		fields["contentWarnings"] = rule.ContentWarnings
+	}
+	return fields
+}
+
 // InitializeComponents initializes the server components for multi-repository support
 // without starting the full MCP server. This method is specifically designed for testing
 // scenarios where you need to access server functionality without the MCP server lifecycle.
@@ -202,6 +472,7 @@ func (s *Server) getRulefileToolHandler(toolName string) (server.ToolHandlerFunc
 // This method initializes:
 //   - Prepared repositories via PrepareAllRepositories (validates, prepares, syncs, logs)
 //   - RuleFileProcessor for parsing and processing rule files
+//   - The rule files of repositories served at a revision (see ServeAt)
 //
 // Use this method in tests when you need to call methods like RegisterRuleFileTools,
 // getRepoFiles, or other server methods that depend on these components being initialized.
@@ -213,7 +484,8 @@ func (s *Server) getRulefileToolHandler(toolName string) (server.ToolHandlerFunc
 func (s *Server) InitializeComponents() error {
 	// Prepare all repositories for multi-repository support
 	// This validates, prepares, syncs, and logs all repositories.
-	prepared, err := repository.PrepareAllRepositories(context.Background(), s.config.Repositories, s.logger)
+	// Extra branches of GitHub repositories are prepared as repositories of their own
+	prepared, err := repository.PrepareAllRepositories(context.Background(), repository.WithWorktrees(s.config.Repositories), s.logger)
 	if err != nil {
 		s.logger.Error("Multi-repository preparation failed", "error", err)
 		return fmt.Errorf("failed to prepare repositories: %w", err)
@@ -222,15 +494,226 @@ func (s *Server) InitializeComponents() error {
 	// Store prepared repositories for later use
 	s.preparedRepositories = prepared
 
+	// Initialize rule file processor with repository paths for multi-repository support
+	processor, err := s.newRuleProcessor()
+	if err != nil {
+		s.logger.Error("Failed to initialize rule file processor", "error", err)
+		return err
+	}
+	s.ruleProcessor = processor
+
+	// Read the rules of repositories served as of a revision
+	if err := s.readPinnedRevisions(); err != nil {
+		s.logger.Error("Failed to read pinned revisions", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// newRuleProcessor creates the rule file processor for the prepared
+// repositories, registering only the rules that pass the tool filter
+func (s *Server) newRuleProcessor() (*RuleFileProcessor, error) {
+	processor, err := NewRuleFileProcessorForRepositories(s.config, s.preparedRepositories, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	processor.filter = s.toolFilter
+	if s.maxFileSize > 0 {
+		processor.maxFileSize = s.maxFileSize
+	}
+	return processor, nil
+}
+
+// NewRuleFileProcessorForRepositories creates the rule file processor for the
+// prepared repositories, honouring the frontmatter delimiters and rule
+// extensions from the configuration when any are set, prefixing every tool
+// name with the configured tool prefix, and namespacing the tools of branch
+// worktrees and, when several repositories are served or a repository sets
+// its own namespace, of each repository.
+func NewRuleFileProcessorForRepositories(cfg *config.Config, prepared []repository.PreparedRepository, logger *logging.AppLogger) (*RuleFileProcessor, error) {
 	// Build repository paths map for rule file processor
 	repositoryPaths := make(map[string]string, len(prepared))
 	for _, prep := range prepared {
 		repositoryPaths[prep.ID()] = prep.LocalPath
 	}
 
-	// Initialize rule file processor with repository paths for multi-repository support
-	maxFileSize := int64(5 * 1024 * 1024) // 5 MB
-	s.ruleProcessor = NewRuleFileProcessor(s.logger, repositoryPaths, maxFileSize)
+	maxFileSize := DefaultMaxFileSize
 
-	return nil
+	var processor *RuleFileProcessor
+	if len(cfg.FrontmatterDelimiters) == 0 {
+		processor = NewRuleFileProcessor(logger, repositoryPaths, maxFileSize)
+	} else {
+		var err error
+		processor, err = NewRuleFileProcessorWithDelimiters(logger, repositoryPaths, maxFileSize, cfg.FrontmatterDelimiters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure rule file processor: %w", err)
+		}
+	}
+
+	toolNamePrefix, err := cfg.MCPToolPrefix()
+	if err != nil {
+		return nil, err
+	}
+	processor.toolNamePrefix = toolNamePrefix
+	extensions, err := cfg.RuleFileExtensions()
+	if err != nil {
+		return nil, err
+	}
+	for i, ext := range extensions {
+		extensions[i] = filemanager.NormalizeRuleExtension(ext)
+	}
+	if extensions != nil {
+		processor.extensions = extensions
+	}
+	for id, prefix := range toolPrefixes(prepared) {
+		processor.toolPrefixes[id] = prefix
+	}
+	processor.autoDescriptions = cfg.AutoDescriptions
+
+	// Rule content is checked against the global content policy, with each
+	// repository's settings layered on top
+	policy, err := fileops.ResolveContentPolicy(cfg.ContentSecurity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content_security: %w", err)
+	}
+	processor.contentPolicy = policy
+	for _, prep := range prepared {
+		if prep.Entry.ContentSecurity == nil {
+			continue
+		}
+		policy, err := fileops.ResolveContentPolicy(cfg.ContentSecurity, prep.Entry.ContentSecurity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content_security for repository %s: %w", prep.Entry.Name, err)
+		}
+		processor.contentPolicies[prep.ID()] = policy
+	}
+	return processor, nil
+}
+
+// repositoryNamespaceSeparator separates a repository's namespace from the rest
+// of its tool names, e.g. work_rules__go_standards
+const repositoryNamespaceSeparator = "__"
+
+// toolPrefixes returns the tool name prefix of every prepared repository that
+// has one. Branch worktrees are served under their branch, so `main` and
+// `experimental` rules with the same name do not clash. When several
+// repositories are served, each is also served under its own name, so rules
+// from all of them are told apart. A repository's tool_namespace replaces its
+// name, and applies even when it is the only repository served.
+func toolPrefixes(prepared []repository.PreparedRepository) map[string]string {
+	roots := make(map[string]repository.RepositoryEntry)
+	for _, prep := range repository.AvailableRepositories(prepared) {
+		if prep.Entry.WorktreeOf == "" {
+			roots[prep.ID()] = prep.Entry
+		}
+	}
+
+	prefixes := make(map[string]string)
+	for _, prep := range repository.AvailableRepositories(prepared) {
+		var prefix string
+		root := prep.Entry
+		if parent, ok := roots[prep.Entry.WorktreeOf]; ok {
+			root = parent
+		}
+		if root.ToolNamespace != "" || len(roots) > 1 {
+			prefix = repositoryNamespace(root) + repositoryNamespaceSeparator
+		}
+		if prep.Entry.WorktreeOf != "" {
+			prefix += worktreeNamespace(prep.Entry) + "_"
+		}
+		if prefix != "" {
+			prefixes[prep.ID()] = prefix
+		}
+	}
+	return prefixes
+}
+
+// repositoryNamespace returns the tool name namespace of a repository: its
+// tool_namespace when set, otherwise derived from its name, e.g. "Work Rules"
+// becomes work_rules
+func repositoryNamespace(entry repository.RepositoryEntry) string {
+	if entry.ToolNamespace != "" {
+		return entry.ToolNamespace
+	}
+	namespace := strings.ReplaceAll(repository.BranchSlug(entry.Name), "-", "_")
+	if namespace == "" {
+		namespace = strings.ReplaceAll(repository.BranchSlug(entry.ID), "-", "_")
+	}
+	return namespace
+}
+
+// worktreeNamespace returns the tool name namespace of a branch worktree entry
+func worktreeNamespace(entry repository.RepositoryEntry) string {
+	return strings.ReplaceAll(repository.BranchSlug(entry.GetBranch()), "-", "_")
+}
+
+// toolNamePrefix returns the configured prefix of every tool name, or empty
+// string when it is unset or invalid
+func (s *Server) toolNamePrefix() string {
+	if s.config == nil {
+		return ""
+	}
+	prefix, err := s.config.MCPToolPrefix()
+	if err != nil {
+		return ""
+	}
+	return prefix
+}
+
+// searchToolName returns the name search_rules is registered under
+func (s *Server) searchToolName() string {
+	return s.toolNamePrefix() + SearchToolName
+}
+
+// bundleToolName returns the name get_all_rules is registered under
+func (s *Server) bundleToolName() string {
+	return s.toolNamePrefix() + BundleToolName
+}
+
+// builtinTools returns search_rules and get_all_rules, which are served next
+// to the rule tools, and propose_rule when a repository accepts proposals
+func (s *Server) builtinTools() ([]server.ServerTool, error) {
+	maxTokens, err := s.config.RuleBundleMaxTokens()
+	if err != nil {
+		return nil, err
+	}
+	tools := []server.ServerTool{
+		{Tool: newSearchTool(s.searchToolName()), Handler: s.searchToolHandler},
+		{Tool: newBundleTool(s.bundleToolName(), maxTokens), Handler: s.bundleToolHandler},
+	}
+	if accepting := s.proposalRepositories(); len(accepting) > 0 {
+		tools = append(tools, server.ServerTool{Tool: newProposeTool(s.proposeToolName(), repositoryNames(accepting)), Handler: s.proposeToolHandler})
+	}
+	return tools, nil
+}
+
+// buildInstructions describes the rule repositories to connected assistants, using
+// the name, description, default bundle and tags from each repository's rulem.yaml
+func (s *Server) buildInstructions() string {
+	var b strings.Builder
+	b.WriteString("rulem exposes coding rules and instructions as tools. Call a tool to get the full rule text, " + s.searchToolName() + " to find the rules relevant to a task, or " + s.bundleToolName() + " to get many rules in one call.")
+	if prefix := s.toolNamePrefix(); prefix != "" {
+		fmt.Fprintf(&b, "\n- Every tool name starts with %s", prefix)
+	}
+
+	prefixes := toolPrefixes(s.preparedRepositories)
+	for _, prep := range repository.AvailableRepositories(s.preparedRepositories) {
+		if summary := prep.Manifest.Summary(); summary != "" {
+			fmt.Fprintf(&b, "\n- Repository %s — %s", prep.Name(), summary)
+		}
+		if prep.Entry.WorktreeOf != "" {
+			fmt.Fprintf(&b, "\n- Repository %s serves branch %s; its tools are prefixed %s", prep.Name(), prep.Entry.GetBranch(), s.toolNamePrefix()+prefixes[prep.ID()])
+		} else if prefix := prefixes[prep.ID()]; prefix != "" {
+			fmt.Fprintf(&b, "\n- Repository %s: its tools are prefixed %s", prep.Name(), s.toolNamePrefix()+prefix)
+		}
+		if pin, ok := s.pinned[prep.ID()]; ok {
+			fmt.Fprintf(&b, "\n- Repository %s is served as of %s (commit %s)", prep.Name(), pin.revision, pin.commit)
+		}
+	}
+	if len(s.proposalRepositories()) > 0 {
+		fmt.Fprintf(&b, "\n- Call %s to propose a new rule when you find a convention worth keeping that no rule covers; a person reviews it before it is served", s.proposeToolName())
+	}
+
+	return b.String()
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -376,6 +377,7 @@ func TestServer_ToolHandlers(t *testing.T) {
 		"handler-test.md": validRuleFile1,
 		"another-rule.md": validRuleFile2,
 		"complex.md":      complexContentRule,
+		"licensed.md":     "---\ndescription: Licensed rule\nname: licensed_rule\nlicense: MIT\nattribution: Jane Doe\n---\n# Licensed Rule\n",
 	}
 
 	server, _ := createTestServerWithFiles(t, testFiles)
@@ -396,6 +398,8 @@ func TestServer_ToolHandlers(t *testing.T) {
 	}
 
 	server.toolRegistry = toolsMap
+	usagePath := filepath.Join(t.TempDir(), "usage.jsonl")
+	server.EnableUsageLog(NewUsageLog(usagePath))
 
 	tests := []struct {
 		name          string
@@ -432,6 +436,26 @@ func TestServer_ToolHandlers(t *testing.T) {
 			setupContext: func() (context.Context, context.CancelFunc) {
 				return context.WithTimeout(context.Background(), 5*time.Second)
 			},
+			validateExtra: func(t *testing.T, result *mcp.CallToolResult) {
+				if result.Meta != nil {
+					t.Errorf("Expected no _meta for a rule without license or attribution, got %+v", result.Meta)
+				}
+			},
+		},
+		{
+			name:        "license and attribution in _meta",
+			toolName:    "licensed_rule",
+			wantError:   false,
+			wantContent: "# Licensed Rule",
+			setupContext: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 5*time.Second)
+			},
+			validateExtra: func(t *testing.T, result *mcp.CallToolResult) {
+				want := map[string]any{"license": "MIT", "attribution": "Jane Doe"}
+				if result.Meta == nil || !reflect.DeepEqual(result.Meta.AdditionalFields, want) {
+					t.Errorf("Expected _meta %v, got %+v", want, result.Meta)
+				}
+			},
 		},
 		{
 			name:        "non-existent tool",
@@ -528,9 +552,28 @@ func TestServer_ToolHandlers(t *testing.T) {
 				} else {
 					t.Error("Result content should be text content")
 				}
+				if tt.validateExtra != nil {
+					tt.validateExtra(t, result)
+				}
 			}
 		})
 	}
+
+	// Every rule served is recorded, but not calls that failed
+	calls, err := ReadToolCalls(usagePath, time.Time{})
+	if err != nil {
+		t.Fatalf("ReadToolCalls: %v", err)
+	}
+	var served []string
+	for _, call := range calls {
+		served = append(served, call.Tool)
+		if !strings.HasSuffix(call.File, ".md") || call.Client != "unknown" {
+			t.Errorf("recorded call %+v, want its rule file and an unknown client", call)
+		}
+	}
+	if want := []string{"test_rule_1", "test_rule_2", "complex_content", "licensed_rule"}; !reflect.DeepEqual(served, want) {
+		t.Errorf("recorded calls = %v, want %v", served, want)
+	}
 }
 
 func TestServer_ConcurrentAccess(t *testing.T) {
@@ -1191,6 +1234,125 @@ name: "coding_standards"
 	}
 }
 
+func TestToolPrefixes(t *testing.T) {
+	work := repository.PreparedRepository{Entry: repository.RepositoryEntry{ID: "work-rules-1234abcd", Name: "Work Rules"}, LocalPath: "/rules/work"}
+	personal := repository.PreparedRepository{Entry: repository.RepositoryEntry{ID: "mine-1234abce", Name: "My Rules!"}, LocalPath: "/rules/mine"}
+	unavailable := repository.PreparedRepository{Entry: repository.RepositoryEntry{ID: "gone-1234abcf", Name: "Gone"}}
+	experimental := repository.PreparedRepository{
+		Entry: repository.RepositoryEntry{
+			ID:         "work-rules-experimental-1234abcd",
+			Name:       "Work Rules (experimental)",
+			Branch:     StringPtr("experimental"),
+			WorktreeOf: work.ID(),
+		},
+		LocalPath: "/rules/work.worktrees/experimental",
+	}
+	team := repository.PreparedRepository{Entry: repository.RepositoryEntry{ID: "team-1234abd0", Name: "Team", ToolNamespace: "acme"}, LocalPath: "/rules/team"}
+
+	tests := []struct {
+		name     string
+		prepared []repository.PreparedRepository
+		want     map[string]string
+	}{
+		{name: "single repository", prepared: []repository.PreparedRepository{work, unavailable}, want: map[string]string{}},
+		{
+			name:     "single repository with worktree",
+			prepared: []repository.PreparedRepository{work, experimental},
+			want:     map[string]string{experimental.ID(): "experimental_"},
+		},
+		{
+			name:     "several repositories",
+			prepared: []repository.PreparedRepository{work, personal, experimental, unavailable},
+			want: map[string]string{
+				work.ID():         "work_rules__",
+				personal.ID():     "my_rules__",
+				experimental.ID(): "work_rules__experimental_",
+			},
+		},
+		{
+			name:     "single repository with namespace",
+			prepared: []repository.PreparedRepository{team},
+			want:     map[string]string{team.ID(): "acme__"},
+		},
+		{
+			name:     "namespace replaces name",
+			prepared: []repository.PreparedRepository{work, team},
+			want:     map[string]string{work.ID(): "work_rules__", team.ID(): "acme__"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toolPrefixes(tt.prepared); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toolPrefixes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_MultiRepositoryToolNames(t *testing.T) {
+	dirs := []string{t.TempDir(), t.TempDir()}
+	for _, dir := range dirs {
+		if err := os.WriteFile(filepath.Join(dir, "go-standards.md"), []byte("---\ndescription: Go standards\n---\n# Go\n"), 0644); err != nil {
+			t.Fatalf("Failed to write rule file: %v", err)
+		}
+	}
+	prepared := []repository.PreparedRepository{
+		{Entry: repository.RepositoryEntry{ID: "work-1234abcd", Name: "Work Rules", Type: repository.RepositoryTypeLocal, Path: dirs[0]}, LocalPath: dirs[0]},
+		{Entry: repository.RepositoryEntry{ID: "oss-1234abce", Name: "OSS Rules", Type: repository.RepositoryTypeLocal, Path: dirs[1]}, LocalPath: dirs[1]},
+	}
+
+	logger, _ := logging.NewTestLogger()
+	tools, err := LoadRuleTools(&config.Config{}, prepared, logger)
+	if err != nil {
+		t.Fatalf("LoadRuleTools: %v", err)
+	}
+	for name, repoID := range map[string]string{"work_rules__go_standards": "work-1234abcd", "oss_rules__go_standards": "oss-1234abce"} {
+		tool, ok := tools[name]
+		if !ok || tool.RuleFile.RepositoryID != repoID {
+			t.Errorf("tool %s = %+v, want the rule from %s", name, tool, repoID)
+		}
+	}
+
+	// Rules still resolve by their own name within a repository
+	if tool, err := ResolveRule(tools, "go-standards", "oss-1234abce"); err != nil || tool.Name != "oss_rules__go_standards" {
+		t.Errorf("ResolveRule() = %v, %v", tool, err)
+	}
+}
+
+func TestServer_ToolPrefix(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go-standards.md"), []byte("---\ndescription: Go standards\n---\n# Go\n"), 0644); err != nil {
+		t.Fatalf("Failed to write rule file: %v", err)
+	}
+	prepared := []repository.PreparedRepository{
+		{Entry: repository.RepositoryEntry{ID: "work-1234abcd", Name: "Work Rules", Type: repository.RepositoryTypeLocal, Path: dir, ToolNamespace: "work"}, LocalPath: dir},
+	}
+	cfg := &config.Config{ToolPrefix: "rules_"}
+
+	logger, _ := logging.NewTestLogger()
+	tools, err := LoadRuleTools(cfg, prepared, logger)
+	if err != nil {
+		t.Fatalf("LoadRuleTools: %v", err)
+	}
+	if _, ok := tools["rules_work__go_standards"]; !ok || len(tools) != 1 {
+		t.Errorf("tools = %v, want rules_work__go_standards", tools)
+	}
+
+	// The search tool gets the prefix too, and rules still resolve by their own name
+	server := NewServer(cfg, logger)
+	if got := server.searchToolName(); got != "rules_search_rules" {
+		t.Errorf("searchToolName() = %q, want rules_search_rules", got)
+	}
+	if tool, err := ResolveRule(tools, "go-standards", ""); err != nil || tool.Name != "rules_work__go_standards" {
+		t.Errorf("ResolveRule() = %v, %v", tool, err)
+	}
+
+	if _, err := LoadRuleTools(&config.Config{ToolPrefix: "rules."}, prepared, logger); err == nil {
+		t.Error("LoadRuleTools() with an invalid tool_prefix should fail")
+	}
+}
+
 // TestServer_MultiRepositoryWithMixedContent tests repositories with valid and invalid files
 func TestServer_MultiRepositoryWithMixedContent(t *testing.T) {
 	// Create test directories
@@ -1416,3 +1578,114 @@ func TestServer_SyncResultsLogging(t *testing.T) {
 func StringPtr(s string) *string {
 	return &s
 }
+
+func TestServer_RepositoryManifest(t *testing.T) {
+	files := map[string]string{
+		"rulem.yaml":  "name: Acme Rules\ndescription: Shared coding rules\ntags: [go, testing]\nschema:\n  required: [tags]\n",
+		"tagged.md":   "---\ndescription: Tagged rule\nname: tagged_rule\ntags: [go]\n---\n# Tagged\n",
+		"untagged.md": "---\ndescription: Untagged rule\nname: untagged_rule\n---\n# Untagged\n",
+		"unknown.md":  "---\ndescription: Unknown tag\nname: unknown_rule\ntags: [python]\n---\n# Unknown\n",
+	}
+
+	server, _ := createTestServerWithFiles(t, files)
+	if err := server.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+
+	repoFiles, err := server.getRepoFiles()
+	if err != nil {
+		t.Fatalf("getRepoFiles returned error: %v", err)
+	}
+	tools, err := server.ruleProcessor.ProcessRuleFiles(repoFiles)
+	if err != nil {
+		t.Fatalf("ProcessRuleFiles returned error: %v", err)
+	}
+
+	if len(tools) != 1 {
+		t.Errorf("expected only the rule satisfying the manifest, got %d tools", len(tools))
+	}
+	if _, ok := tools["tagged_rule"]; !ok {
+		t.Error("expected tagged_rule to be registered")
+	}
+
+	instructions := server.buildInstructions()
+	if !strings.Contains(instructions, "Acme Rules: Shared coding rules") || !strings.Contains(instructions, "tags: go, testing") {
+		t.Errorf("instructions should describe the repository, got %q", instructions)
+	}
+}
+
+func TestRuleMetaFieldsMetadata(t *testing.T) {
+	rule := &RuleFile{Version: "1.2.0", Scope: []string{"**/*.go"}, Priority: 5, Deprecated: true}
+	want := map[string]any{"version": "1.2.0", "scope": []string{"**/*.go"}, "priority": 5, "deprecated": true}
+	if got := ruleMetaFields(rule); !reflect.DeepEqual(got, want) {
+		t.Errorf("ruleMetaFields() = %v, want %v", got, want)
+	}
+}
+
+func TestValidateAgainstManifest(t *testing.T) {
+	manifest := &repository.Manifest{
+		Tags:   []string{"go"},
+		Schema: repository.ManifestSchema{Required: []string{"applyTo"}},
+	}
+
+	tests := []struct {
+		name     string
+		matter   RuleFrontmatter
+		manifest *repository.Manifest
+		wantErr  bool
+	}{
+		{name: "no manifest", matter: RuleFrontmatter{Description: "d", Tags: []string{"anything"}}},
+		{name: "satisfies manifest", matter: RuleFrontmatter{Description: "d", ApplyTo: "*.go", Tags: []string{"go"}}, manifest: manifest},
+		{name: "missing required field", matter: RuleFrontmatter{Description: "d"}, manifest: manifest, wantErr: true},
+		{name: "tag outside taxonomy", matter: RuleFrontmatter{Description: "d", ApplyTo: "*.go", Tags: []string{"rust"}}, manifest: manifest, wantErr: true},
+		{
+			name:     "missing required license",
+			matter:   RuleFrontmatter{Description: "d"},
+			manifest: &repository.Manifest{Schema: repository.ManifestSchema{Required: []string{"license"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "required license set",
+			matter:   RuleFrontmatter{Description: "d", License: "MIT"},
+			manifest: &repository.Manifest{Schema: repository.ManifestSchema{Required: []string{"license"}}},
+		},
+		{
+			name:     "required scope set",
+			matter:   RuleFrontmatter{Description: "d", Scope: []string{"**/*.go"}},
+			manifest: &repository.Manifest{Schema: repository.ManifestSchema{Required: []string{"scope", "description"}}},
+		},
+		{
+			name:     "unsupported required field",
+			matter:   RuleFrontmatter{Description: "d"},
+			manifest: &repository.Manifest{Schema: repository.ManifestSchema{Required: []string{"owner"}}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAgainstManifest(&tt.matter, tt.manifest)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateAgainstManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServer_EnableMaxFileSize(t *testing.T) {
+	server, _ := createTestServer(t)
+	if err := server.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	if server.ruleProcessor.maxFileSize != DefaultMaxFileSize {
+		t.Errorf("maxFileSize = %d, want the default %d", server.ruleProcessor.maxFileSize, DefaultMaxFileSize)
+	}
+
+	server.EnableMaxFileSize(64 * 1024)
+	if err := server.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	if server.ruleProcessor.maxFileSize != 64*1024 {
+		t.Errorf("maxFileSize = %d, want 65536", server.ruleProcessor.maxFileSize)
+	}
+}
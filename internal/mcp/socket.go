@@ -0,0 +1,256 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rule socket
+//
+// The rule socket is a minimal JSON-over-unix-socket API for consumers that do
+// not speak MCP (shell scripts, git hooks, editors). It serves the same tool
+// registry as the MCP server, so rules are parsed once and shared.
+//
+// Each request is one JSON object per line and gets exactly one JSON object
+// per line back; a connection may send any number of requests:
+//
+//	{"method":"list"}
+//	{"method":"get","name":"go_style"}
+//	{"method":"search","query":"error handling"}
+
+// Socket API methods
+const (
+	SocketMethodList   = "list"
+	SocketMethodGet    = "get"
+	SocketMethodSearch = "search"
+)
+
+// maxSocketRequestSize bounds a single request line
+const maxSocketRequestSize = 64 * 1024
+
+// SocketRequest is a single request on the rule socket
+type SocketRequest struct {
+	Method string `json:"method"`
+	Name   string `json:"name,omitempty"`  // Tool name, for get
+	Query  string `json:"query,omitempty"` // Space-separated terms, for search
+}
+
+// SocketRule describes a rule in socket responses. Content is only set by get.
+type SocketRule struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Path        string   `json:"path"`
+	Tags        []string `json:"tags,omitempty"`
+	Content     string   `json:"content,omitempty"`
+}
+
+// SocketResponse is the reply to a SocketRequest. List and search set Rules,
+// which is omitted when nothing matches; get sets Rule; failures set Error.
+type SocketResponse struct {
+	Rules []SocketRule `json:"rules,omitempty"`
+	Rule  *SocketRule  `json:"rule,omitempty"`
+	Error string       `json:"error,omitempty"`
+}
+
+// DefaultSocketPath returns the rule socket path used when none is given:
+// rulem.sock in $XDG_RUNTIME_DIR, or a per-user file in the temp directory.
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "rulem.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("rulem-%d.sock", os.Getuid()))
+}
+
+// EnableSocket makes Start also serve the rule socket at path once the rule
+// files are registered.
+func (s *Server) EnableSocket(path string) {
+	s.socketPath = path
+}
+
+// ServeSocket starts serving the rule socket at path in the background. A stale
+// socket file left by a crashed server is replaced; a live one is an error.
+// The socket is only accessible by the current user.
+func (s *Server) ServeSocket(path string) error {
+	if err := removeStaleSocket(path); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on rule socket %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict rule socket permissions: %w", err)
+	}
+
+	s.socketMu.Lock()
+	s.socketListener = listener
+	s.socketMu.Unlock()
+	s.logger.Info("Serving rule socket", "path", path)
+
+	go s.acceptSocketConnections(listener)
+	return nil
+}
+
+// closeSocket stops the rule socket, if any, and removes the socket file
+func (s *Server) closeSocket() error {
+	s.socketMu.Lock()
+	defer s.socketMu.Unlock()
+	if s.socketListener == nil {
+		return nil
+	}
+	// Closing a unix listener also unlinks the socket file it created
+	err := s.socketListener.Close()
+	s.socketListener = nil
+	return err
+}
+
+// removeStaleSocket removes a socket file at path if no server answers on it
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check rule socket %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("rule socket path %s exists and is not a socket", path)
+	}
+
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("another rulem server is already listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale rule socket %s: %w", path, err)
+	}
+	return nil
+}
+
+// acceptSocketConnections serves each connection until the listener is closed
+func (s *Server) acceptSocketConnections(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Error("Rule socket accept failed", "error", err)
+			}
+			return
+		}
+		go s.serveSocketConnection(conn)
+	}
+}
+
+// serveSocketConnection answers newline-delimited requests until the client
+// closes the connection or sends a line that is too long to read.
+func (s *Server) serveSocketConnection(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxSocketRequestSize)
+	encoder := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req SocketRequest
+		var resp SocketResponse
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			resp = SocketResponse{Error: fmt.Sprintf("invalid request: %v", err)}
+		} else {
+			resp = s.handleSocketRequest(req)
+		}
+
+		if err := encoder.Encode(resp); err != nil {
+			s.logger.Debug("Rule socket client went away", "error", err)
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		s.logger.Debug("Rule socket read failed", "error", err)
+	}
+}
+
+// handleSocketRequest answers a single request from the tool registry
+func (s *Server) handleSocketRequest(req SocketRequest) SocketResponse {
+	s.logger.Debug("Processing rule socket request", "method", req.Method, "name", req.Name, "query", req.Query)
+
+	switch req.Method {
+	case SocketMethodList:
+		return SocketResponse{Rules: s.socketRules(func(*RuleFileTool) bool { return true })}
+	case SocketMethodGet:
+		tool, exists := s.tools()[req.Name]
+		if !exists {
+			return SocketResponse{Error: fmt.Sprintf("rule '%s' not found", req.Name)}
+		}
+		s.recordUsage(tool, "socket")
+		s.auditRule(context.Background(), AuditSocketGet, tool, AuditSuccess, "")
+		rule := newSocketRule(tool)
+		rule.Content = tool.RuleFile.Content
+		return SocketResponse{Rule: &rule}
+	case SocketMethodSearch:
+		terms := strings.Fields(strings.ToLower(req.Query))
+		if len(terms) == 0 {
+			return SocketResponse{Error: "search needs a query"}
+		}
+		return SocketResponse{Rules: s.socketRules(func(tool *RuleFileTool) bool {
+			return matchesAllTerms(tool, terms)
+		})}
+	default:
+		return SocketResponse{Error: fmt.Sprintf("unknown method '%s' (want list, get or search)", req.Method)}
+	}
+}
+
+// socketRules returns the registered rules accepted by keep, sorted by name
+func (s *Server) socketRules(keep func(*RuleFileTool) bool) []SocketRule {
+	var rules []SocketRule
+	for _, tool := range s.tools() {
+		if keep(tool) {
+			rules = append(rules, newSocketRule(tool))
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+	return rules
+}
+
+// newSocketRule describes tool without its content
+func newSocketRule(tool *RuleFileTool) SocketRule {
+	return SocketRule{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Path:        tool.RuleFile.FilePath,
+		Tags:        tool.RuleFile.Tags,
+	}
+}
+
+// matchesAllTerms reports whether every lowercase term appears in the tool's
+// name, description, tags or content.
+func matchesAllTerms(tool *RuleFileTool, terms []string) bool {
+	haystack := strings.ToLower(strings.Join([]string{
+		tool.Name,
+		tool.Description,
+		strings.Join(tool.RuleFile.Tags, " "),
+		tool.RuleFile.Content,
+	}, "\n"))
+
+	for _, term := range terms {
+		if !strings.Contains(haystack, term) {
+			return false
+		}
+	}
+	return true
+}
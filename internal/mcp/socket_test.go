@@ -0,0 +1,189 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createSocketServer serves the given rule files over a rule socket and returns its path
+func createSocketServer(t *testing.T, files map[string]string) (*Server, string) {
+	t.Helper()
+	server, _ := createTestServerWithFiles(t, files)
+	if err := server.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	repoFiles, err := server.getRepoFiles()
+	if err != nil {
+		t.Fatalf("Failed to get repository files: %v", err)
+	}
+	if server.toolRegistry, err = server.ruleProcessor.ProcessRuleFiles(repoFiles); err != nil {
+		t.Fatalf("Failed to process rule files: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "rulem.sock")
+	if err := server.ServeSocket(path); err != nil {
+		t.Fatalf("ServeSocket: %v", err)
+	}
+	t.Cleanup(func() { server.closeSocket() })
+	return server, path
+}
+
+func TestServeSocket_Requests(t *testing.T) {
+	_, path := createSocketServer(t, map[string]string{
+		"rule1.md":   validRuleFile1,
+		"rule2.md":   validRuleFile2,
+		"complex.md": complexContentRule,
+	})
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	// Requests share one connection and are answered in order
+	query := func(raw string) SocketResponse {
+		t.Helper()
+		if _, err := conn.Write([]byte(raw + "\n")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		var resp SocketResponse
+		if err := json.Unmarshal(line, &resp); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		return resp
+	}
+	names := func(rules []SocketRule) string {
+		var out []string
+		for _, rule := range rules {
+			out = append(out, rule.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		name      string
+		request   string
+		wantRules string
+		wantRule  string
+		wantErr   string
+	}{
+		{name: "list is sorted", request: `{"method":"list"}`, wantRules: "complex_content,test_rule_1,test_rule_2"},
+		{name: "search matches content", request: `{"method":"search","query":"FIRST test"}`, wantRules: "test_rule_1"},
+		{name: "search needs every term", request: `{"method":"search","query":"first second"}`, wantRules: ""},
+		{name: "get returns content", request: `{"method":"get","name":"test_rule_2"}`, wantRule: "test_rule_2"},
+		{name: "get unknown rule", request: `{"method":"get","name":"missing"}`, wantErr: "rule 'missing' not found"},
+		{name: "empty search", request: `{"method":"search","query":"  "}`, wantErr: "needs a query"},
+		{name: "unknown method", request: `{"method":"delete"}`, wantErr: "unknown method 'delete'"},
+		{name: "malformed request", request: `{"method":`, wantErr: "invalid request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := query(tt.request)
+			if tt.wantErr != "" {
+				if !strings.Contains(resp.Error, tt.wantErr) {
+					t.Errorf("error = %q, want %q", resp.Error, tt.wantErr)
+				}
+				return
+			}
+			if resp.Error != "" {
+				t.Fatalf("unexpected error: %s", resp.Error)
+			}
+			if got := names(resp.Rules); got != tt.wantRules {
+				t.Errorf("rules = %q, want %q", got, tt.wantRules)
+			}
+			if tt.wantRule == "" {
+				for _, rule := range resp.Rules {
+					if rule.Content != "" {
+						t.Errorf("%s: list results should not include content", rule.Name)
+					}
+				}
+				return
+			}
+			if resp.Rule == nil || resp.Rule.Name != tt.wantRule {
+				t.Fatalf("rule = %+v, want %s", resp.Rule, tt.wantRule)
+			}
+			if !strings.Contains(resp.Rule.Content, "# Test Rule 2") || resp.Rule.Description != "Second test rule" {
+				t.Errorf("unexpected rule %+v", resp.Rule)
+			}
+		})
+	}
+}
+
+func TestServeSocket_SocketFile(t *testing.T) {
+	server, path := createSocketServer(t, map[string]string{"rule1.md": validRuleFile1})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket permissions = %o, want 600", perm)
+	}
+
+	// A second server must not take over a live socket
+	other, _ := createTestServer(t)
+	if err := other.ServeSocket(path); err == nil || !strings.Contains(err.Error(), "already listening") {
+		other.closeSocket()
+		t.Fatalf("ServeSocket() on a live socket error = %v", err)
+	}
+
+	if err := server.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket file should be removed on stop, stat err = %v", err)
+	}
+}
+
+func TestRemoveStaleSocket(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("missing path", func(t *testing.T) {
+		if err := removeStaleSocket(filepath.Join(dir, "none.sock")); err != nil {
+			t.Errorf("removeStaleSocket: %v", err)
+		}
+	})
+
+	t.Run("regular file is kept", func(t *testing.T) {
+		path := filepath.Join(dir, "file.sock")
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := removeStaleSocket(path); err == nil || !strings.Contains(err.Error(), "not a socket") {
+			t.Errorf("removeStaleSocket() error = %v", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("regular file should be kept: %v", err)
+		}
+	})
+
+	t.Run("stale socket is removed", func(t *testing.T) {
+		path := filepath.Join(dir, "stale.sock")
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		// Leave the socket file behind as a crashed server would
+		listener.(*net.UnixListener).SetUnlinkOnClose(false)
+		listener.Close()
+
+		if err := removeStaleSocket(path); err != nil {
+			t.Fatalf("removeStaleSocket: %v", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("stale socket should be removed, stat err = %v", err)
+		}
+	})
+}
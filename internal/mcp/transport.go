@@ -0,0 +1,212 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Network transports
+//
+// By default the server speaks JSON-RPC over stdin/stdout and serves the one
+// client that started it. With a network transport it runs as a long-lived
+// daemon that several editors connect to over HTTP:
+//
+//   - http: streamable HTTP, one endpoint at /mcp
+//   - sse:  the older HTTP+SSE transport, events at /sse and messages at /message
+//
+// When an auth token is set, every request must carry it as a bearer token.
+// Whether or not one is set, requests must name the listen address or a
+// loopback host in their Host header, and browser requests must come from a
+// local origin, so a web page cannot reach the server by DNS rebinding.
+// Network transports serve large rules in parts (see chunking.go).
+
+// Transport selects how the MCP server talks to clients
+type Transport string
+
+const (
+	TransportStdio Transport = "stdio" // JSON-RPC over stdin/stdout
+	TransportHTTP  Transport = "http"  // Streamable HTTP
+	TransportSSE   Transport = "sse"   // HTTP with server-sent events
+)
+
+// DefaultHTTPAddress is where network transports listen when no address is given
+const DefaultHTTPAddress = "127.0.0.1:7331"
+
+// AuthTokenEnv is the environment variable holding the auth token of network
+// transports, so it does not have to appear on the command line
+const AuthTokenEnv = "RULEM_MCP_TOKEN"
+
+// httpShutdownTimeout bounds how long Stop waits for open requests
+const httpShutdownTimeout = 5 * time.Second
+
+// HTTPOptions configures a network transport
+type HTTPOptions struct {
+	Address   string // host:port to listen on, DefaultHTTPAddress when empty
+	AuthToken string // Bearer token clients must send, empty to accept any client
+}
+
+// ParseTransport returns the transport called name
+func ParseTransport(name string) (Transport, error) {
+	switch transport := Transport(name); transport {
+	case TransportStdio, TransportHTTP, TransportSSE:
+		return transport, nil
+	default:
+		return "", fmt.Errorf("unknown transport %q: use %s, %s or %s", name, TransportStdio, TransportHTTP, TransportSSE)
+	}
+}
+
+// EnableHTTP makes Start serve clients over transport, a network transport,
+// instead of stdio. It also serves large rules in parts.
+func (s *Server) EnableHTTP(transport Transport, opts HTTPOptions) {
+	if opts.Address == "" {
+		opts.Address = DefaultHTTPAddress
+	}
+	s.transport = transport
+	s.httpOptions = opts
+	if s.chunkSize == 0 {
+		s.EnableChunking(DefaultChunkSize)
+	}
+}
+
+// isNetworkTransport reports whether Start serves clients over HTTP
+func (s *Server) isNetworkTransport() bool {
+	return s.transport == TransportHTTP || s.transport == TransportSSE
+}
+
+// serveHTTP serves the MCP server over the network transport until Stop
+func (s *Server) serveHTTP() error {
+	listener, err := net.Listen("tcp", s.httpOptions.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.httpOptions.Address, err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           s.httpHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.httpMu.Lock()
+	s.httpServer = httpServer
+	s.httpMu.Unlock()
+
+	if s.httpOptions.AuthToken == "" && !isLoopback(listener.Addr()) {
+		s.logger.Warn("MCP server is reachable from other hosts without an auth token", "address", listener.Addr().String())
+	}
+	s.logger.Info("Starting MCP HTTP server",
+		"transport", s.transport,
+		"address", listener.Addr().String(),
+		"auth", s.httpOptions.AuthToken != "")
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// httpHandler routes the network transport's endpoints, behind the host and
+// origin checks and the auth token when one is set
+func (s *Server) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	switch s.transport {
+	case TransportSSE:
+		sse := server.NewSSEServer(s.mcpServer)
+		mux.Handle("/sse", sse)
+		mux.Handle("/message", sse)
+	default:
+		mux.Handle("/mcp", server.NewStreamableHTTPServer(s.mcpServer))
+	}
+	return requireLocalOrigin(requireToken(mux, s.httpOptions.AuthToken), s.httpOptions.Address)
+}
+
+// requireLocalOrigin rejects requests whose Host header names neither the
+// listen address nor a loopback host, and requests whose Origin header names
+// a foreign site. A server listening on all interfaces accepts any Host.
+func requireLocalOrigin(next http.Handler, address string) http.Handler {
+	listenHost, _, err := net.SplitHostPort(address)
+	if err != nil {
+		listenHost = address
+	}
+	anyHost := listenHost == ""
+	if ip := net.ParseIP(listenHost); ip != nil && ip.IsUnspecified() {
+		anyHost = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := hostname(r.Host)
+		if !anyHost && !isLocalHost(host) && !strings.EqualFold(host, listenHost) {
+			http.Error(w, "forbidden host", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || (!isLocalHost(u.Hostname()) && !strings.EqualFold(u.Hostname(), host)) {
+				http.Error(w, "forbidden origin", http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hostname strips the port from a Host header
+func hostname(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return strings.Trim(hostport, "[]")
+}
+
+// isLocalHost reports whether host names this machine's loopback interface
+func isLocalHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requireToken rejects requests that do not carry token as a bearer token.
+// An empty token lets every request through.
+func requireToken(next http.Handler, token string) http.Handler {
+	if token == "" {
+		return next
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="rulem"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// closeHTTP shuts down the network transport, if any, waiting briefly for
+// open requests
+func (s *Server) closeHTTP() error {
+	s.httpMu.Lock()
+	httpServer := s.httpServer
+	s.httpServer = nil
+	s.httpMu.Unlock()
+	if httpServer == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	return httpServer.Shutdown(ctx)
+}
+
+// isLoopback reports whether addr only accepts local connections
+func isLoopback(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	return ok && tcpAddr.IP.IsLoopback()
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestParseTransport(t *testing.T) {
+	for _, name := range []string{"stdio", "http", "sse"} {
+		if got, err := ParseTransport(name); err != nil || string(got) != name {
+			t.Errorf("ParseTransport(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseTransport("websocket"); err == nil {
+		t.Error("expected an error for an unknown transport")
+	}
+}
+
+func TestServer_EnableHTTP(t *testing.T) {
+	srv, _ := createTestServer(t)
+	srv.EnableHTTP(TransportHTTP, HTTPOptions{})
+
+	if !srv.isNetworkTransport() || srv.httpOptions.Address != DefaultHTTPAddress {
+		t.Errorf("unexpected transport %q at %q", srv.transport, srv.httpOptions.Address)
+	}
+	if srv.chunkSize != DefaultChunkSize {
+		t.Errorf("chunkSize = %d, want network transports to serve large rules in parts", srv.chunkSize)
+	}
+}
+
+func TestServer_HTTPHandler(t *testing.T) {
+	srv, _ := createTestServerWithFiles(t, map[string]string{
+		"style.md": "---\ndescription: Style guide\n---\n# Style\n",
+	})
+	if err := srv.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	srv.mcpServer = server.NewMCPServer("rulem", "test", server.WithToolCapabilities(true))
+	if err := srv.RegisterRuleFileTools(); err != nil {
+		t.Fatalf("RegisterRuleFileTools: %v", err)
+	}
+	srv.EnableHTTP(TransportHTTP, HTTPOptions{AuthToken: "secret"})
+
+	ts := httptest.NewServer(srv.httpHandler())
+	defer ts.Close()
+
+	initialize := func(token string) (int, string) {
+		t.Helper()
+		body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /mcp: %v", err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		if status, _ := initialize(token); status != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want %d", token, status, http.StatusUnauthorized)
+		}
+	}
+	status, body := initialize("secret")
+	if status != http.StatusOK || !strings.Contains(body, `"name":"rulem"`) {
+		t.Errorf("status = %d, body = %q, want the server's initialize result", status, body)
+	}
+}
+
+func TestRequireLocalOrigin(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	tests := []struct {
+		name    string
+		address string
+		host    string
+		origin  string
+		want    int
+	}{
+		{"loopback host", DefaultHTTPAddress, "127.0.0.1:7331", "", http.StatusOK},
+		{"localhost", DefaultHTTPAddress, "localhost:7331", "", http.StatusOK},
+		{"ipv6 loopback", DefaultHTTPAddress, "[::1]:7331", "", http.StatusOK},
+		{"rebound name", DefaultHTTPAddress, "attacker.example:7331", "", http.StatusForbidden},
+		{"foreign origin", DefaultHTTPAddress, "127.0.0.1:7331", "https://attacker.example", http.StatusForbidden},
+		{"local origin", DefaultHTTPAddress, "127.0.0.1:7331", "http://localhost:3000", http.StatusOK},
+		{"listen address", "10.0.0.5:7331", "10.0.0.5:7331", "", http.StatusOK},
+		{"other address", "10.0.0.5:7331", "10.0.0.6:7331", "", http.StatusForbidden},
+		{"all interfaces", "0.0.0.0:7331", "rulem.lan:7331", "", http.StatusOK},
+		{"all interfaces foreign origin", "0.0.0.0:7331", "rulem.lan:7331", "https://attacker.example", http.StatusForbidden},
+		{"same-site origin", "0.0.0.0:7331", "rulem.lan:7331", "http://rulem.lan:7331", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			requireLocalOrigin(ok, tt.address).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
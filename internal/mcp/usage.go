@@ -0,0 +1,183 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"rulem/pkg/fileops"
+
+	"github.com/adrg/xdg"
+)
+
+// Usage log
+//
+// rulem mcp records every rule it serves, as a tool call, a resource read or
+// a rule socket `get`, so `rulem stats` and `rulem summary` can report which
+// rules assistants actually read. Each call is one JSON object per line:
+//
+//	{"time":"2026-10-16T12:00:00Z","tool":"go_style","repository":"rules-1",
+//	 "file":"/home/me/rules/go/style.md","client":"claude-code"}
+//
+// client is the name the MCP client gave when it initialized, "socket" for
+// the rule socket and "unknown" when the client did not say; entries written
+// before rulem recorded files and clients have neither. Like the audit log,
+// the usage log is rotated by size, and ReadToolCalls reads the rotated files
+// too. It is written unless rulem mcp is run with --usage-log=false.
+
+// Usage log rotation defaults
+const (
+	DefaultUsageMaxSize    = 5 << 20 // Bytes after which the log is rotated
+	DefaultUsageMaxBackups = 2       // Rotated files kept
+)
+
+// ToolCall records a rule served to an assistant, as an MCP tool call or a
+// rule socket `get`
+type ToolCall struct {
+	Time       time.Time `json:"time"`
+	Tool       string    `json:"tool"`
+	Repository string    `json:"repository"`       // ID of the repository the rule came from
+	File       string    `json:"file,omitempty"`   // Path of the rule file
+	Client     string    `json:"client,omitempty"` // Client that asked for the rule
+}
+
+// UsageLog appends served rules to a JSON lines file, rotating it by size.
+// Appends are small single writes, so several servers can share the log.
+type UsageLog struct {
+	path       string
+	MaxSize    int64 // Rotate before a write would take the file past this size
+	MaxBackups int   // Rotated files kept
+	mu         sync.Mutex
+}
+
+// UsagePath returns the usage log in the user's state directory (e.g.
+// ~/.local/state/rulem/usage.jsonl on Linux). It can be overridden with the
+// RULEM_USAGE_PATH environment variable for testing.
+func UsagePath() string {
+	if testPath := os.Getenv("RULEM_USAGE_PATH"); testPath != "" {
+		return testPath
+	}
+	return filepath.Join(xdg.StateHome, "rulem", "usage.jsonl")
+}
+
+// NewUsageLog creates a usage log appending to path, with the default rotation
+func NewUsageLog(path string) *UsageLog {
+	return &UsageLog{
+		path:       path,
+		MaxSize:    DefaultUsageMaxSize,
+		MaxBackups: DefaultUsageMaxBackups,
+	}
+}
+
+// Record appends call to the log, creating it if needed and rotating it first
+// when it is full
+func (l *UsageLog) Record(call ToolCall) error {
+	line, err := json.Marshal(call)
+	if err != nil {
+		return fmt.Errorf("failed to encode tool call: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := fileops.EnsureDirectoryExists(filepath.Dir(l.path)); err != nil {
+		return fmt.Errorf("cannot create usage log directory: %w", err)
+	}
+	if info, err := os.Stat(l.path); err == nil && l.MaxSize > 0 && info.Size() > 0 && info.Size()+int64(len(line)) > l.MaxSize {
+		if err := rotateFile(l.path, l.MaxBackups); err != nil {
+			return fmt.Errorf("failed to rotate usage log: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open usage log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write usage log: %w", err)
+	}
+	return f.Close()
+}
+
+// ReadToolCalls returns the calls in the usage log at path and its rotated
+// files made at or after since, oldest file first. A missing log has no calls;
+// unreadable lines, e.g. one cut short by a crash, are skipped.
+func ReadToolCalls(path string, since time.Time) ([]ToolCall, error) {
+	files := []string{path}
+	for n := 1; ; n++ {
+		backup := backupPath(path, n)
+		if _, err := os.Stat(backup); err != nil {
+			break
+		}
+		files = append(files, backup)
+	}
+	slices.Reverse(files)
+
+	var calls []ToolCall
+	for _, file := range files {
+		fileCalls, err := readToolCalls(file, since)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, fileCalls...)
+	}
+	return calls, nil
+}
+
+// readToolCalls returns the calls in one usage log file made at or after since
+func readToolCalls(path string, since time.Time) ([]ToolCall, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage log: %w", err)
+	}
+	defer f.Close()
+
+	var calls []ToolCall
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var call ToolCall
+		if err := json.Unmarshal(scanner.Bytes(), &call); err != nil {
+			continue
+		}
+		if !call.Time.Before(since) {
+			calls = append(calls, call)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage log: %w", err)
+	}
+	return calls, nil
+}
+
+// EnableUsageLog makes the server record every rule it serves in log
+func (s *Server) EnableUsageLog(log *UsageLog) {
+	s.usageLog = log
+}
+
+// recordUsage logs that tool was served to client. Failures are logged rather
+// than failing the call: usage is informational.
+func (s *Server) recordUsage(tool *RuleFileTool, client string) {
+	if s.usageLog == nil {
+		return
+	}
+	call := ToolCall{
+		Time:       time.Now().UTC(),
+		Tool:       tool.Name,
+		Repository: tool.RuleFile.RepositoryID,
+		File:       tool.RuleFile.FilePath,
+		Client:     client,
+	}
+	if err := s.usageLog.Record(call); err != nil {
+		s.logger.Warn("Failed to record rule usage", "tool", tool.Name, "error", err)
+	}
+}
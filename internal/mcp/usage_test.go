@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUsageLog_RecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "usage.jsonl")
+	log := NewUsageLog(path)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	calls := []ToolCall{
+		{Time: now.Add(-48 * time.Hour), Tool: "old", Repository: "rules-1"},
+		{Time: now.Add(-time.Hour), Tool: "go_style", Repository: "rules-1"},
+		{Time: now, Tool: "review", Repository: "team-2"},
+	}
+	for _, call := range calls {
+		if err := log.Record(call); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	// A line cut short by a crash is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2026-10-16T13:00:00Z","tool":"trun`)
+	f.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("usage log mode = %o, want 600", perm)
+	}
+
+	got, err := ReadToolCalls(path, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("ReadToolCalls: %v", err)
+	}
+	if !reflect.DeepEqual(got, calls[1:]) {
+		t.Errorf("ReadToolCalls() = %+v, want %+v", got, calls[1:])
+	}
+
+	missing, err := ReadToolCalls(filepath.Join(t.TempDir(), "none.jsonl"), time.Time{})
+	if err != nil || missing != nil {
+		t.Errorf("ReadToolCalls of a missing log = %v, %v; want no calls", missing, err)
+	}
+}
+
+func TestUsageLog_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	log := NewUsageLog(path)
+	log.MaxSize = 150
+	log.MaxBackups = 2
+
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	var calls []ToolCall
+	for i := range 10 {
+		call := ToolCall{Time: start.Add(time.Duration(i) * time.Minute), Tool: "go_style", Repository: "rules-1", Client: "cursor"}
+		calls = append(calls, call)
+		if err := log.Record(call); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Errorf("expected two rotated files: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most two rotated files, got %s.3: %v", path, err)
+	}
+
+	got, err := ReadToolCalls(path, time.Time{})
+	if err != nil {
+		t.Fatalf("ReadToolCalls: %v", err)
+	}
+	if len(got) == 0 || len(got) >= len(calls) {
+		t.Fatalf("read %d calls, want the most recent of %d after the oldest were dropped", len(got), len(calls))
+	}
+	if want := calls[len(calls)-len(got):]; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadToolCalls() = %+v, want the latest calls oldest first %+v", got, want)
+	}
+}
@@ -0,0 +1,308 @@
+package mcp
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/repository"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Hot reload
+//
+// While serving, the server watches the working trees of its repositories and
+// reloads the rules when rule files are added, edited, renamed or removed, so
+// new rules show up without a restart. A burst of changes, such as a sync
+// checking out a new commit, is coalesced into a single reload. Clients are
+// notified that the tool list changed, and of changed rule resources.
+//
+// Repositories served at a revision are not watched: their rules come from git
+// objects, not the working tree.
+//
+// Rules are served from what the last reload read, never from disk at call
+// time, so a request in flight always sees one consistent set of rules. A
+// reload never reads a working tree a sync is still updating (see
+// repository.SyncInProgress): it keeps the current rules and retries until the
+// sync is done, then switches the registry to the new rules as one diff.
+//
+// Every reload first checks the repositories against their pins and allowed
+// signers again (see repository.VerifyRepositories), as PrepareAllRepositories
+// does at startup: a sync that moves a repository to a commit it refuses makes
+// it unavailable, and its rules are dropped rather than served unchecked.
+//
+// Changes are noticed with file system notifications, or by polling (see
+// poll.go) as watch_mode in the config selects. By default repositories on a
+// network file system are polled, as are all of them when notifications
+// cannot be set up.
+
+// reloadDelay is how long the watcher waits for changes to settle before reloading
+const reloadDelay = 300 * time.Millisecond
+
+// syncRetryDelay is how long a reload held back by a sync waits before checking
+// whether the sync is done
+var syncRetryDelay = time.Second
+
+// EnableWatch makes Start reload the rules whenever rule files change
+func (s *Server) EnableWatch() {
+	s.watch = true
+}
+
+// startWatcher watches the working trees of the available repositories in the
+// background until closeWatcher
+func (s *Server) startWatcher() error {
+	mode, interval := config.WatchModeAuto, config.DefaultWatchPollInterval
+	if s.config != nil {
+		var err error
+		if mode, err = s.config.RuleWatchMode(); err != nil {
+			return err
+		}
+		if interval, err = s.config.RuleWatchPollInterval(); err != nil {
+			return err
+		}
+	}
+
+	var roots []string
+	for _, prep := range repository.AvailableRepositories(s.preparedRepositories) {
+		if _, pinned := s.pinned[prep.ID()]; pinned {
+			continue
+		}
+		roots = append(roots, prep.LocalPath)
+	}
+
+	if mode == config.WatchModeAuto {
+		if root, fsType := networkFilesystem(roots); root != "" {
+			s.logger.Info("Polling rule files on a network file system", "path", root, "type", fsType)
+			mode = config.WatchModePoll
+		}
+	}
+	if mode != config.WatchModePoll {
+		err := s.startNotifyWatcher(roots)
+		if err == nil || mode == config.WatchModeNotify {
+			return err
+		}
+		s.logger.Warn("File system notifications are unavailable, polling rule files instead", "error", err)
+	}
+
+	watcher := newPollWatcher(roots, interval, s.reloadRules)
+	s.watchMu.Lock()
+	s.watcher = watcher
+	s.watchMu.Unlock()
+	s.logger.Info("Polling rule files for changes", "repositories", len(roots), "interval", interval)
+	return nil
+}
+
+// startNotifyWatcher watches roots with file system notifications
+func (s *Server) startNotifyWatcher(roots []string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	for _, root := range roots {
+		if err := watchTree(watcher, root); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", root, err)
+		}
+	}
+
+	s.watchMu.Lock()
+	s.watcher = watcher
+	s.watchMu.Unlock()
+	s.logger.Info("Watching rule files for changes", "directories", len(watcher.WatchList()))
+
+	go s.watchRuleFiles(watcher)
+	return nil
+}
+
+// closeWatcher stops watching rule files, if the server is
+func (s *Server) closeWatcher() error {
+	s.reloadMu.Lock()
+	if s.deferredReload != nil {
+		s.deferredReload.Stop()
+	}
+	s.reloadMu.Unlock()
+
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	if s.watcher == nil {
+		return nil
+	}
+	err := s.watcher.Close()
+	s.watcher = nil
+	return err
+}
+
+// watchTree watches root and every directory below it that is scanned for rules
+func watchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			// Unreadable directories are skipped, as when scanning
+			return nil
+		}
+		if path != root && filemanager.IsSkippedDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// watchRuleFiles reloads the rules shortly after rule files change, until the
+// watcher is closed
+func (s *Server) watchRuleFiles(watcher *fsnotify.Watcher) {
+	var reload *time.Timer
+	defer func() {
+		if reload != nil {
+			reload.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !s.isRuleChange(watcher, event) {
+				continue
+			}
+			s.logger.Debug("Rule files changed", "path", event.Name, "op", event.Op.String())
+			if reload == nil {
+				reload = time.AfterFunc(reloadDelay, s.reloadRules)
+			} else {
+				reload.Reset(reloadDelay)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			s.logger.Warn("Rule file watcher error", "error", err)
+		}
+	}
+}
+
+// isRuleChange reports whether event may change the served rules. New
+// directories are watched too, as they may hold rule files.
+func (s *Server) isRuleChange(watcher *fsnotify.Watcher, event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if filemanager.IsSkippedDir(filepath.Base(event.Name)) {
+				return false
+			}
+			if err := watchTree(watcher, event.Name); err != nil {
+				s.logger.Warn("Failed to watch new directory", "path", event.Name, "error", err)
+			}
+			return true
+		}
+	}
+	// Editing .rulemignore changes which files are rules
+	if filepath.Base(event.Name) == filemanager.RuleIgnoreFile {
+		return true
+	}
+	// A removed or renamed directory can no longer be told from a file
+	return filemanager.IsRuleFilePath(filepath.Base(event.Name)) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+}
+
+// reloadRules rescans the repositories and updates the registered tools and
+// resources. The current rules stay registered when the rescan fails, and
+// while a sync is updating a repository; the rules of a repository that fails
+// its pin or signature check are dropped.
+func (s *Server) reloadRules() {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if s.deferReloadWhileSyncing() {
+		return
+	}
+	// A sync may have moved a repository to a commit it must not serve
+	s.verifyRepositories()
+
+	tools, err := s.rescanRules()
+	if err != nil {
+		s.logger.Error("Failed to reload rule files", "error", err)
+		return
+	}
+	// A sync that started during the scan may have been read half way through
+	if s.deferReloadWhileSyncing() {
+		return
+	}
+	// and one that finished during it may have brought in a refused commit
+	if s.verifyRepositories() {
+		if tools, err = s.rescanRules(); err != nil {
+			s.logger.Error("Failed to reload rule files", "error", err)
+			return
+		}
+	}
+	diff := s.registerTools(tools)
+	s.registerResources(tools)
+	if !diff.Empty() {
+		if err := s.checkCompliance(); err != nil {
+			s.logger.Warn("Failed to check the reloaded tools", "error", err)
+		}
+	}
+	if diff.Empty() {
+		s.logger.Info("Reloaded rule files, no rules changed", "toolCount", len(tools))
+		return
+	}
+	s.logger.Info("Reloaded rule files", "toolCount", len(tools),
+		"added", len(diff.Added), "removed", len(diff.Removed), "updated", len(diff.Updated))
+}
+
+// rescanRules reads the rules of the available repositories with a fresh
+// processor, which names the rules from scratch. The caller must hold reloadMu.
+func (s *Server) rescanRules() (map[string]*RuleFileTool, error) {
+	processor, err := s.newRuleProcessor()
+	if err != nil {
+		return nil, err
+	}
+	s.ruleProcessor = processor
+	return s.loadRuleFileTools()
+}
+
+// VerifyRepositories checks the served repositories against their pins and
+// allowed signers again, e.g. after each background sync, and stops serving
+// the rules of those that fail
+func (s *Server) VerifyRepositories() {
+	s.reloadMu.Lock()
+	refused := s.verifyRepositories()
+	s.reloadMu.Unlock()
+	if refused {
+		s.reloadRules()
+	}
+}
+
+// verifyRepositories makes the repositories that fail their pin or signature
+// check unavailable, reporting whether any did. Refused repositories stay
+// unavailable until the server restarts. The caller must hold reloadMu.
+func (s *Server) verifyRepositories() bool {
+	verified, refused := repository.VerifyRepositories(s.preparedRepositories, s.logger)
+	s.preparedRepositories = verified
+	return refused
+}
+
+// deferReloadWhileSyncing reports whether a sync is updating the working tree
+// of a watched repository, and if so schedules the reload to be tried again.
+// The caller must hold reloadMu.
+func (s *Server) deferReloadWhileSyncing() bool {
+	for _, prep := range repository.AvailableRepositories(s.preparedRepositories) {
+		if _, pinned := s.pinned[prep.ID()]; pinned || !repository.SyncInProgress(prep.GitRoot()) {
+			continue
+		}
+		s.logger.Debug("Waiting for sync to finish before reloading rules", "repository", prep.Name())
+		if s.deferredReload == nil {
+			s.deferredReload = time.AfterFunc(syncRetryDelay, s.reloadRules)
+		} else {
+			s.deferredReload.Reset(syncRetryDelay)
+		}
+		return true
+	}
+	return false
+}
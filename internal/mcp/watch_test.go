@@ -0,0 +1,276 @@
+package mcp
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/crypto/ssh"
+)
+
+// waitFor polls until cond holds, failing the test after a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestServer_WatchReloadsRules(t *testing.T) {
+	srv, dir := createTestServerWithFiles(t, map[string]string{
+		"style.md": "---\ndescription: Style guide\n---\n# Style\n",
+	})
+	if err := srv.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	srv.mcpServer = server.NewMCPServer("rulem", "test",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true))
+	if err := srv.RegisterRuleFileTools(); err != nil {
+		t.Fatalf("RegisterRuleFileTools: %v", err)
+	}
+	if err := srv.startWatcher(); err != nil {
+		t.Fatalf("startWatcher: %v", err)
+	}
+	t.Cleanup(func() { srv.closeWatcher() })
+
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	// A rule in a directory created after the watcher started
+	write("go/errors.md", "---\ndescription: Error handling\n---\n# Errors\n")
+	waitFor(t, "the new rule", func() bool { return srv.tools()["errors"] != nil })
+	waitFor(t, "the new rule's resource", func() bool {
+		srv.resourcesMu.RLock()
+		defer srv.resourcesMu.RUnlock()
+		return srv.resources["rulem://test-repo-123456/go/errors.md"] != nil
+	})
+
+	write("style.md", "---\ndescription: Style guide\n---\n# Style, revised\n")
+	waitFor(t, "the edited rule", func() bool {
+		tool := srv.tools()["style"]
+		return tool != nil && tool.RuleFile.Content == "# Style, revised\n"
+	})
+
+	if err := os.Remove(filepath.Join(dir, "style.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	waitFor(t, "the removed rule to go", func() bool { return srv.tools()["style"] == nil })
+	if srv.tools()["errors"] == nil {
+		t.Error("unrelated rules should stay registered")
+	}
+}
+
+func TestServer_ReloadWaitsForSync(t *testing.T) {
+	srv, dir := createTestServerWithFiles(t, map[string]string{
+		"style.md": "---\ndescription: Style guide\n---\n# Style\n",
+	})
+	if err := srv.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	srv.mcpServer = server.NewMCPServer("rulem", "test", server.WithToolCapabilities(true))
+	if err := srv.RegisterRuleFileTools(); err != nil {
+		t.Fatalf("RegisterRuleFileTools: %v", err)
+	}
+	syncRetryDelay = 50 * time.Millisecond
+	t.Cleanup(func() {
+		srv.closeWatcher()
+		syncRetryDelay = time.Second
+	})
+
+	// A sync is half way through updating the working tree
+	marker := filepath.Join(dir, ".git", "rulem-syncing")
+	if err := os.MkdirAll(filepath.Dir(marker), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "errors.md"), []byte("---\ndescription: Error handling\n---\n# Errors\n"), 0644); err != nil {
+		t.Fatalf("write rule: %v", err)
+	}
+
+	srv.reloadRules()
+	time.Sleep(3 * syncRetryDelay)
+	if srv.tools()["errors"] != nil {
+		t.Fatal("rules should not be reloaded while a sync updates the working tree")
+	}
+	if srv.tools()["style"] == nil {
+		t.Fatal("the current rules should stay served during a sync")
+	}
+
+	if err := os.Remove(marker); err != nil {
+		t.Fatalf("remove marker: %v", err)
+	}
+	waitFor(t, "the rules of the finished sync", func() bool { return srv.tools()["errors"] != nil })
+}
+
+func TestSameRule(t *testing.T) {
+	rule := func(content string) *RuleFileTool {
+		return &RuleFileTool{Name: "style", Description: "Style guide", RuleFile: &RuleFile{Tags: []string{"go"}, Content: content}}
+	}
+	if !sameRule(rule("# Style\n"), rule("# Style\n")) {
+		t.Error("identical rules should be the same")
+	}
+	if sameRule(rule("# Style\n"), rule("# Style, revised\n")) {
+		t.Error("rules with different content should differ")
+	}
+}
+
+func TestDiffRegistry(t *testing.T) {
+	rule := func(name, content string) *RuleFileTool {
+		return &RuleFileTool{Name: name, Description: name, RuleFile: &RuleFile{Content: content}}
+	}
+	previous := map[string]*RuleFileTool{
+		"style":   rule("style", "# Style\n"),
+		"errors":  rule("errors", "# Errors\n"),
+		"testing": rule("testing", "# Testing\n"),
+	}
+	next := map[string]*RuleFileTool{
+		"style":   rule("style", "# Style\n"),
+		"errors":  rule("errors", "# Errors, revised\n"),
+		"logging": rule("logging", "# Logging\n"),
+		"api":     rule("api", "# API\n"),
+	}
+
+	diff := diffRegistry(previous, next)
+	if !slices.Equal(diff.Added, []string{"api", "logging"}) {
+		t.Errorf("Added = %v, want [api logging]", diff.Added)
+	}
+	if !slices.Equal(diff.Removed, []string{"testing"}) {
+		t.Errorf("Removed = %v, want [testing]", diff.Removed)
+	}
+	if !slices.Equal(diff.Updated, []string{"errors"}) {
+		t.Errorf("Updated = %v, want [errors]", diff.Updated)
+	}
+	if diff.Empty() {
+		t.Error("a changed registry should not give an empty diff")
+	}
+	if diff := diffRegistry(next, next); !diff.Empty() {
+		t.Errorf("an unchanged registry should give an empty diff, got %+v", diff)
+	}
+}
+
+func TestServer_RegisterToolsAppliesDiff(t *testing.T) {
+	srv, _ := createTestServerWithFiles(t, map[string]string{
+		"style.md": "---\ndescription: Style guide\n---\n# Style\n",
+	})
+	if err := srv.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	srv.mcpServer = server.NewMCPServer("rulem", "test", server.WithToolCapabilities(true))
+	if err := srv.RegisterRuleFileTools(); err != nil {
+		t.Fatalf("RegisterRuleFileTools: %v", err)
+	}
+
+	tools := maps.Clone(srv.tools())
+	if diff := srv.registerTools(tools); !diff.Empty() {
+		t.Errorf("registering the same rules again should change nothing, got %+v", diff)
+	}
+
+	delete(tools, "style")
+	tools["errors"] = &RuleFileTool{Name: "errors", Description: "Error handling", RuleFile: &RuleFile{Content: "# Errors\n"}}
+	diff := srv.registerTools(tools)
+	if !slices.Equal(diff.Added, []string{"errors"}) || !slices.Equal(diff.Removed, []string{"style"}) {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+	names := slices.Sorted(maps.Keys(srv.serverTools))
+	if want := []string{"errors", BundleToolName, SearchToolName}; !slices.Equal(names, want) {
+		t.Errorf("registered tools = %v, want %v", names, want)
+	}
+}
+
+func TestServer_SyncToUnsignedCommitStopsServing(t *testing.T) {
+	srv, dir := createTestServerWithFiles(t, map[string]string{
+		"style.md": "---\ndescription: Style guide\n---\n# Style\n",
+	})
+	if err := srv.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	srv.mcpServer = server.NewMCPServer("rulem", "test", server.WithToolCapabilities(true))
+	if err := srv.RegisterRuleFileTools(); err != nil {
+		t.Fatalf("RegisterRuleFileTools: %v", err)
+	}
+	if srv.tools()["style"] == nil {
+		t.Fatal("style should be served before the sync")
+	}
+
+	// The repository requires signed commits, and a sync checks out one that is not
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("signer: %v", err)
+	}
+	signers := filepath.Join(t.TempDir(), "allowed_signers")
+	if err := os.WriteFile(signers, []byte("alice@example.com "+string(ssh.MarshalAuthorizedKey(signer.PublicKey()))), 0644); err != nil {
+		t.Fatalf("write allowed signers: %v", err)
+	}
+	srv.preparedRepositories[0].Entry.AllowedSigners = &signers
+	if err := os.WriteFile(filepath.Join(dir, "injected.md"), []byte("---\ndescription: Injected\n---\n# Injected\n"), 0644); err != nil {
+		t.Fatalf("write rule: %v", err)
+	}
+	commitAll(t, dir)
+
+	// The watcher's reload refuses the repository instead of serving the commit
+	srv.reloadRules()
+	if srv.tools()["injected"] != nil || srv.tools()["style"] != nil {
+		t.Fatalf("rules of an unsigned commit are served: %v", slices.Sorted(maps.Keys(srv.tools())))
+	}
+	if srv.preparedRepositories[0].IsAvailable() {
+		t.Fatal("the repository should be unavailable after syncing to an unsigned commit")
+	}
+
+	// and later reloads keep it unavailable
+	srv.VerifyRepositories()
+	srv.reloadRules()
+	if len(srv.tools()) != 0 {
+		t.Errorf("tools after another reload = %v, want none", slices.Sorted(maps.Keys(srv.tools())))
+	}
+}
+
+func TestServer_VerifyRepositoriesDropsRefusedRules(t *testing.T) {
+	srv, dir := createTestServerWithFiles(t, map[string]string{
+		"style.md": "---\ndescription: Style guide\n---\n# Style\n",
+	})
+	commitAll(t, dir)
+	if err := srv.InitializeComponents(); err != nil {
+		t.Fatalf("Failed to initialize server components: %v", err)
+	}
+	srv.mcpServer = server.NewMCPServer("rulem", "test", server.WithToolCapabilities(true))
+	if err := srv.RegisterRuleFileTools(); err != nil {
+		t.Fatalf("RegisterRuleFileTools: %v", err)
+	}
+
+	// A background sync (without --watch) moves HEAD to an unsigned commit
+	signers := filepath.Join(t.TempDir(), "allowed_signers")
+	if err := os.WriteFile(signers, []byte("# no keys\n"), 0644); err != nil {
+		t.Fatalf("write allowed signers: %v", err)
+	}
+	srv.preparedRepositories[0].Entry.AllowedSigners = &signers
+
+	srv.VerifyRepositories()
+	if len(srv.tools()) != 0 {
+		t.Errorf("tools after verifying = %v, want none", slices.Sorted(maps.Keys(srv.tools())))
+	}
+}
@@ -0,0 +1,319 @@
+// Package migrate imports rule files written for other tools into a rulem repository.
+//
+// Supported sources:
+//   - cursor: a Cursor rules directory (.cursor/rules) of .mdc files whose
+//     frontmatter holds description, globs and alwaysApply
+//   - markdown: a plain directory of markdown rules (e.g. an ai-rules layout)
+//     without frontmatter
+//
+// Each imported file is written as markdown with rulem frontmatter: the
+// description comes from the source metadata or, failing that, the first heading
+// or the file name, and Cursor globs become applyTo. Every file ends up in the
+// Report as imported or skipped, with the reason.
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"rulem/pkg/fileops"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source identifies the tool a rules directory was written for
+type Source string
+
+const (
+	SourceCursor   Source = "cursor"
+	SourceMarkdown Source = "markdown"
+)
+
+// Sources lists the supported sources for help text and validation
+var Sources = []Source{SourceCursor, SourceMarkdown}
+
+// maxImportFileSize skips files that are too large to be rule files
+const maxImportFileSize = 5 * 1024 * 1024
+
+// Entry records what happened to a single source file
+type Entry struct {
+	Source string // Path relative to the source directory
+	Dest   string // Path relative to the destination directory (empty if skipped)
+	Note   string // Reason for skipping, or details about generated metadata
+}
+
+// Report is the outcome of a migration
+type Report struct {
+	Source   Source
+	Imported []Entry
+	Skipped  []Entry
+}
+
+// Markdown renders the report for display or saving alongside the migrated rules
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Migration report (%s)\n\n", r.Source)
+	fmt.Fprintf(&b, "Imported %d file(s), skipped %d.\n", len(r.Imported), len(r.Skipped))
+
+	if len(r.Imported) > 0 {
+		b.WriteString("\n## Imported\n\n")
+		for _, e := range r.Imported {
+			fmt.Fprintf(&b, "- `%s` → `%s`", e.Source, e.Dest)
+			if e.Note != "" {
+				fmt.Fprintf(&b, " (%s)", e.Note)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(r.Skipped) > 0 {
+		b.WriteString("\n## Skipped\n\n")
+		for _, e := range r.Skipped {
+			fmt.Fprintf(&b, "- `%s`: %s\n", e.Source, e.Note)
+		}
+	}
+
+	return b.String()
+}
+
+// ruleFrontmatter is the frontmatter written to migrated files
+type ruleFrontmatter struct {
+	Description string `yaml:"description"`
+	ApplyTo     string `yaml:"applyTo,omitempty"`
+}
+
+// Migrate translates the rules in srcDir into rulem rule files under destDir,
+// preserving the directory layout. A rule whose destination already exists is
+// handled according to collision: it is skipped with CollisionAsk (there is no
+// one to ask), written next to it with a numeric suffix with CollisionRename,
+// and replaces it with CollisionOverwrite.
+//
+// Returns:
+//   - *Report: Imported and skipped files
+//   - error: If the source is unknown or a directory cannot be read or written
+func Migrate(source Source, srcDir, destDir string, collision fileops.CollisionStrategy) (*Report, error) {
+	if !slices.Contains(Sources, source) {
+		return nil, fmt.Errorf("unknown migration source %q (supported: %s)", source, joinSources())
+	}
+
+	info, err := os.Stat(srcDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access source directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("source %s is not a directory", srcDir)
+	}
+
+	report := &Report{Source: source}
+	err = filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != srcDir && strings.HasPrefix(d.Name(), ".") && source != SourceCursor {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if !isSourceFile(source, d.Name()) {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			report.Skipped = append(report.Skipped, Entry{Source: rel, Note: "not a regular file"})
+			return nil
+		}
+
+		entry, err := migrateFile(source, path, rel, destDir, collision)
+		if err != nil {
+			report.Skipped = append(report.Skipped, Entry{Source: rel, Note: err.Error()})
+			return nil
+		}
+		report.Imported = append(report.Imported, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("migration failed: %w", err)
+	}
+
+	sort.Slice(report.Imported, func(i, j int) bool { return report.Imported[i].Source < report.Imported[j].Source })
+	sort.Slice(report.Skipped, func(i, j int) bool { return report.Skipped[i].Source < report.Skipped[j].Source })
+	return report, nil
+}
+
+// isSourceFile reports whether a file name belongs to the given source's layout
+func isSourceFile(source Source, name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	switch source {
+	case SourceCursor:
+		return ext == ".mdc"
+	default:
+		return ext == ".md" || ext == ".markdown"
+	}
+}
+
+// migrateFile converts a single file and writes it below destDir
+func migrateFile(source Source, path, rel, destDir string, collision fileops.CollisionStrategy) (Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Entry{}, fmt.Errorf("cannot read file: %w", err)
+	}
+	if info.Size() > maxImportFileSize {
+		return Entry{}, fmt.Errorf("file too large (%d bytes)", info.Size())
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, fmt.Errorf("cannot read file: %w", err)
+	}
+
+	meta, body := splitFrontmatter(string(content))
+	if source == SourceMarkdown && meta != nil {
+		return Entry{}, fmt.Errorf("already has frontmatter; copy it as-is instead")
+	}
+
+	var notes []string
+	matter := ruleFrontmatter{Description: strings.TrimSpace(meta["description"])}
+	if matter.Description == "" {
+		matter.Description = deriveDescription(body, rel)
+		notes = append(notes, "description generated")
+	}
+	if globs := strings.TrimSpace(meta["globs"]); globs != "" {
+		matter.ApplyTo = globs
+	}
+	if strings.EqualFold(strings.TrimSpace(meta["alwaysApply"]), "true") && matter.ApplyTo == "" {
+		matter.ApplyTo = "**"
+		notes = append(notes, "alwaysApply mapped to applyTo: **")
+	}
+
+	destRel := strings.TrimSuffix(rel, filepath.Ext(rel)) + ".md"
+	exists := func(name string) bool {
+		_, err := os.Lstat(filepath.Join(destDir, filepath.FromSlash(name)))
+		return err == nil
+	}
+	switch collision {
+	case fileops.CollisionOverwrite:
+	case fileops.CollisionRename:
+		free, err := fileops.UniqueFilename(destRel, exists)
+		if err != nil {
+			return Entry{}, err
+		}
+		if free != destRel {
+			notes = append(notes, destRel+" already exists, saved under a new name")
+			destRel = free
+		}
+	default:
+		if exists(destRel) {
+			return Entry{}, fmt.Errorf("destination %s already exists", destRel)
+		}
+	}
+	destPath := filepath.Join(destDir, filepath.FromSlash(destRel))
+
+	header, err := yaml.Marshal(matter)
+	if err != nil {
+		return Entry{}, fmt.Errorf("cannot generate frontmatter: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return Entry{}, fmt.Errorf("cannot create destination directory: %w", err)
+	}
+	out := "---\n" + string(header) + "---\n\n" + strings.TrimLeft(body, "\n")
+	if err := os.WriteFile(destPath, []byte(out), 0644); err != nil {
+		return Entry{}, fmt.Errorf("cannot write %s: %w", destRel, err)
+	}
+
+	return Entry{Source: rel, Dest: destRel, Note: strings.Join(notes, "; ")}, nil
+}
+
+// splitFrontmatter separates a leading ----delimited block into key/value pairs
+// and the body. Cursor writes unquoted globs such as `globs: *.ts` that are not
+// valid YAML, so the block is read as simple "key: value" lines; list items
+// under a key are joined with commas. Returns nil metadata if there is no block.
+func splitFrontmatter(content string) (map[string]string, string) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, content
+	}
+
+	meta := map[string]string{}
+	var lastKey string
+	consumed := len(lines[0])
+	for _, line := range lines[1:] {
+		consumed += len(line)
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "---" {
+			return meta, content[consumed:]
+		}
+
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && lastKey != "" {
+			item = unquote(item)
+			if meta[lastKey] == "" {
+				meta[lastKey] = item
+			} else {
+				meta[lastKey] += ", " + item
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		lastKey = strings.TrimSpace(key)
+		meta[lastKey] = unquote(strings.TrimSpace(value))
+	}
+
+	// Unterminated block: treat the whole file as body
+	return nil, content
+}
+
+// unquote strips matching surrounding quotes and a YAML flow list's brackets
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	if len(s) >= 2 && s[0] == '[' && s[len(s)-1] == ']' {
+		items := strings.Split(s[1:len(s)-1], ",")
+		for i, item := range items {
+			items[i] = unquote(strings.TrimSpace(item))
+		}
+		return strings.Join(items, ", ")
+	}
+	return s
+}
+
+// deriveDescription uses the first markdown heading, or the file name
+func deriveDescription(body, rel string) string {
+	for line := range strings.SplitSeq(body, "\n") {
+		line = strings.TrimSpace(line)
+		if heading, ok := strings.CutPrefix(line, "#"); ok {
+			if heading = strings.TrimSpace(strings.TrimLeft(heading, "#")); heading != "" {
+				return heading
+			}
+		}
+	}
+
+	base := strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+	return strings.NewReplacer("-", " ", "_", " ").Replace(base)
+}
+
+func joinSources() string {
+	names := make([]string, len(Sources))
+	for i, s := range Sources {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ", ")
+}
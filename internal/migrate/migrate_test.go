@@ -0,0 +1,179 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/pkg/fileops"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestMigrateCursor(t *testing.T) {
+	src := t.TempDir()
+	dest := t.TempDir()
+
+	writeFile(t, filepath.Join(src, "typescript.mdc"), "---\ndescription: TypeScript conventions\nglobs: *.ts, *.tsx\nalwaysApply: false\n---\n# TS\nUse strict mode.\n")
+	writeFile(t, filepath.Join(src, "backend", "always.mdc"), "---\ndescription:\nglobs:\nalwaysApply: true\n---\n\n# Always on\nBe kind.\n")
+	writeFile(t, filepath.Join(src, "notes.txt"), "ignored")
+
+	report, err := Migrate(SourceCursor, src, dest, fileops.CollisionAsk)
+	if err != nil {
+		t.Fatalf("Migrate returned error: %v", err)
+	}
+	if len(report.Imported) != 2 || len(report.Skipped) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	ts := readFile(t, filepath.Join(dest, "typescript.md"))
+	if !strings.Contains(ts, "description: TypeScript conventions") || !strings.Contains(ts, "applyTo: '*.ts, *.tsx'") {
+		t.Errorf("frontmatter not translated:\n%s", ts)
+	}
+	if !strings.HasSuffix(ts, "# TS\nUse strict mode.\n") {
+		t.Errorf("body not preserved:\n%s", ts)
+	}
+
+	always := readFile(t, filepath.Join(dest, "backend", "always.md"))
+	if !strings.Contains(always, "description: Always on") || !strings.Contains(always, "applyTo: '**'") {
+		t.Errorf("generated metadata missing:\n%s", always)
+	}
+	if report.Imported[0].Dest != "backend/always.md" || !strings.Contains(report.Imported[0].Note, "description generated") {
+		t.Errorf("report entry wrong: %+v", report.Imported[0])
+	}
+}
+
+func TestMigrateMarkdown(t *testing.T) {
+	src := t.TempDir()
+	dest := t.TempDir()
+
+	writeFile(t, filepath.Join(src, "code-review.md"), "Review every change.\n")
+	writeFile(t, filepath.Join(src, "has-matter.md"), "---\ndescription: Already rulem\n---\nBody\n")
+	writeFile(t, filepath.Join(src, "existing.md"), "# Existing\n")
+	writeFile(t, filepath.Join(src, ".hidden", "skip.md"), "# Hidden\n")
+	writeFile(t, filepath.Join(dest, "existing.md"), "keep me")
+
+	report, err := Migrate(SourceMarkdown, src, dest, fileops.CollisionAsk)
+	if err != nil {
+		t.Fatalf("Migrate returned error: %v", err)
+	}
+	if len(report.Imported) != 1 || len(report.Skipped) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	review := readFile(t, filepath.Join(dest, "code-review.md"))
+	if !strings.HasPrefix(review, "---\ndescription: code review\n---\n\nReview every change.") {
+		t.Errorf("unexpected migrated file:\n%s", review)
+	}
+	if readFile(t, filepath.Join(dest, "existing.md")) != "keep me" {
+		t.Error("existing destination must not be overwritten")
+	}
+
+	md := report.Markdown()
+	if !strings.Contains(md, "Imported 1 file(s), skipped 2.") || !strings.Contains(md, "`existing.md`: destination existing.md already exists") {
+		t.Errorf("report markdown incomplete:\n%s", md)
+	}
+}
+
+func TestMigrateCollisionStrategies(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "style.md"), "# Style\n")
+
+	dest := t.TempDir()
+	writeFile(t, filepath.Join(dest, "style.md"), "keep me")
+	report, err := Migrate(SourceMarkdown, src, dest, fileops.CollisionRename)
+	if err != nil {
+		t.Fatalf("Migrate returned error: %v", err)
+	}
+	if len(report.Imported) != 1 || report.Imported[0].Dest != "style-2.md" {
+		t.Fatalf("rename: unexpected report: %+v", report)
+	}
+	if readFile(t, filepath.Join(dest, "style.md")) != "keep me" {
+		t.Error("rename: existing destination must not be overwritten")
+	}
+
+	dest = t.TempDir()
+	writeFile(t, filepath.Join(dest, "style.md"), "replace me")
+	if _, err := Migrate(SourceMarkdown, src, dest, fileops.CollisionOverwrite); err != nil {
+		t.Fatalf("Migrate returned error: %v", err)
+	}
+	if !strings.Contains(readFile(t, filepath.Join(dest, "style.md")), "# Style") {
+		t.Error("overwrite: existing destination was not replaced")
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	if _, err := Migrate("windsurf", t.TempDir(), t.TempDir(), fileops.CollisionAsk); err == nil {
+		t.Error("expected error for unknown source")
+	}
+	if _, err := Migrate(SourceCursor, filepath.Join(t.TempDir(), "missing"), t.TempDir(), fileops.CollisionAsk); err == nil {
+		t.Error("expected error for missing source directory")
+	}
+}
+
+func TestSplitFrontmatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantMeta map[string]string
+		wantBody string
+	}{
+		{
+			name:     "no frontmatter",
+			content:  "# Title\n",
+			wantBody: "# Title\n",
+		},
+		{
+			name:     "glob list",
+			content:  "---\nglobs:\n  - \"*.go\"\n  - '*.mod'\n---\nBody\n",
+			wantMeta: map[string]string{"globs": "*.go, *.mod"},
+			wantBody: "Body\n",
+		},
+		{
+			name:     "flow list and crlf",
+			content:  "---\r\nglobs: [\"*.py\", \"*.pyi\"]\r\n---\r\nBody\r\n",
+			wantMeta: map[string]string{"globs": "*.py, *.pyi"},
+			wantBody: "Body\r\n",
+		},
+		{
+			name:     "unterminated",
+			content:  "---\ndescription: x\n",
+			wantBody: "---\ndescription: x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, body := splitFrontmatter(tt.content)
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+			for k, v := range tt.wantMeta {
+				if meta[k] != v {
+					t.Errorf("meta[%q] = %q, want %q", k, meta[k], v)
+				}
+			}
+			if tt.wantMeta == nil && meta != nil {
+				t.Errorf("expected no metadata, got %v", meta)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package notes stores the private notes and star ratings users attach to
+// rules, to remember which rules work well for them.
+//
+// Notes live in the user's data directory, in rulem-notes/notes.yaml (e.g.
+// ~/.local/share/rulem-notes/notes.yaml on Linux). That is beside rather than
+// inside the default rule storage, which may itself be a repository, so notes
+// are never committed or shared:
+//
+//	rules:
+//	  team-rules-3f9a0c12:go/style.md:
+//	    rating: 4
+//	    text: Works well, but too strict about comments
+//	    updatedAt: 1760000000
+//
+// Rules are keyed by repository ID and their path in the repository (see Key),
+// so notes survive moving a repository on disk.
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+
+	"github.com/adrg/xdg"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxRating is the highest star rating
+const MaxRating = 5
+
+// Note is what a user recorded about a rule
+type Note struct {
+	Rating    int    `yaml:"rating,omitempty"` // 1 to MaxRating stars, 0 when unrated
+	Text      string `yaml:"text,omitempty"`
+	UpdatedAt int64  `yaml:"updatedAt"` // Unix time
+}
+
+// IsEmpty reports whether the note has neither a rating nor text
+func (n Note) IsEmpty() bool {
+	return n.Rating == 0 && n.Text == ""
+}
+
+// Store holds the notes of every rule
+type Store struct {
+	path  string
+	Rules map[string]Note `yaml:"rules"`
+}
+
+// Path returns the notes file in the user's data directory. It can be
+// overridden with the RULEM_NOTES_PATH environment variable for testing.
+func Path() string {
+	if testPath := os.Getenv("RULEM_NOTES_PATH"); testPath != "" {
+		return testPath
+	}
+	return filepath.Join(xdg.DataHome, "rulem-notes", "notes.yaml")
+}
+
+// Load reads the notes file at path. A missing file is an empty store.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path, Rules: make(map[string]Note)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+	if err := yaml.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("invalid notes file %s: %w", path, err)
+	}
+	if store.Rules == nil {
+		store.Rules = make(map[string]Note)
+	}
+	return store, nil
+}
+
+// Get returns the note for a rule key
+func (s *Store) Get(key string) (Note, bool) {
+	note, ok := s.Rules[key]
+	return note, ok
+}
+
+// Set records the note for a rule key, removing it when the note is empty
+func (s *Store) Set(key string, note Note) error {
+	if note.Rating < 0 || note.Rating > MaxRating {
+		return fmt.Errorf("rating must be between 0 and %d", MaxRating)
+	}
+	if note.IsEmpty() {
+		delete(s.Rules, key)
+		return nil
+	}
+	s.Rules[key] = note
+	return nil
+}
+
+// Save writes the store back to the file it was loaded from, replacing it atomically
+func (s *Store) Save() error {
+	if err := fileops.EnsureDirectoryExists(filepath.Dir(s.path)); err != nil {
+		return fmt.Errorf("cannot create notes directory: %w", err)
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode notes: %w", err)
+	}
+
+	// Notes are private, so only the user may read them
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+	return nil
+}
+
+// RenameRepository moves the notes on rules of the repository with oldID to
+// newID, e.g. when its ID is migrated. A note already kept under newID wins.
+// Returns whether the store changed and needs saving.
+func (s *Store) RenameRepository(oldID, newID string) bool {
+	changed := false
+	prefix := oldID + ":"
+	for key, note := range s.Rules {
+		rel, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		if _, exists := s.Rules[newID+":"+rel]; !exists {
+			s.Rules[newID+":"+rel] = note
+		}
+		delete(s.Rules, key)
+		changed = true
+	}
+	return changed
+}
+
+// Key identifies the rule at path in the repository with repositoryID as
+// "<repository ID>:<path in the repository>", where a path in the repository's
+// overlay counts as a path in the repository. Rules outside every known
+// repository are keyed by their absolute path.
+func Key(repos []repository.RepositoryEntry, repositoryID, path string) string {
+	for _, repo := range repos {
+		if repo.ID != repositoryID {
+			continue
+		}
+		roots := []string{repo.Path}
+		if overlay := repo.GetOverlay(); overlay != "" {
+			roots = []string{overlay, repo.Path}
+		}
+		for _, root := range roots {
+			if rel, err := filepath.Rel(root, path); err == nil && filepath.IsLocal(rel) {
+				return repositoryID + ":" + filepath.ToSlash(rel)
+			}
+		}
+	}
+	return path
+}
+
+// Stars renders a rating as filled and empty stars, e.g. "★★★☆☆", or "" when unrated
+func Stars(rating int) string {
+	if rating <= 0 {
+		return ""
+	}
+	rating = min(rating, MaxRating)
+	return strings.Repeat("★", rating) + strings.Repeat("☆", MaxRating-rating)
+}
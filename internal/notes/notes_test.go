@@ -0,0 +1,102 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rulem/internal/repository"
+)
+
+func TestStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes", "notes.yaml")
+
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load of a missing file: %v", err)
+	}
+	if len(store.Rules) != 0 {
+		t.Fatalf("expected an empty store, got %v", store.Rules)
+	}
+
+	note := Note{Rating: 4, Text: "Works well", UpdatedAt: 1700000000}
+	if err := store.Set("rules-1:go/style.md", note); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := store.Set("rules-1:old.md", Note{Rating: 2}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	// An empty note removes the rule's note
+	if err := store.Set("rules-1:old.md", Note{}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("notes file mode = %o, want 600", perm)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got, ok := loaded.Get("rules-1:go/style.md"); !ok || got != note {
+		t.Errorf("Get() = %+v, %v; want %+v", got, ok, note)
+	}
+	if _, ok := loaded.Get("rules-1:old.md"); ok {
+		t.Error("removed note should not be stored")
+	}
+}
+
+func TestStore_SetInvalidRating(t *testing.T) {
+	store, _ := Load(filepath.Join(t.TempDir(), "notes.yaml"))
+	for _, rating := range []int{-1, MaxRating + 1} {
+		if err := store.Set("rules-1:a.md", Note{Rating: rating}); err == nil {
+			t.Errorf("Set with rating %d should fail", rating)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	overlay := "/overlay/team"
+	repos := []repository.RepositoryEntry{
+		{ID: "rules-1", Path: "/data/rules"},
+		{ID: "team-2", Path: "/shared/team", Overlay: &overlay},
+	}
+
+	tests := []struct {
+		name         string
+		repositoryID string
+		path         string
+		want         string
+	}{
+		{"in repository", "rules-1", "/data/rules/go/style.md", "rules-1:go/style.md"},
+		{"in overlay", "team-2", "/overlay/team/mine.md", "team-2:mine.md"},
+		{"in shared directory", "team-2", "/shared/team/go.md", "team-2:go.md"},
+		{"unknown repository", "other-3", "/data/rules/go/style.md", "/data/rules/go/style.md"},
+		{"outside repository", "rules-1", "/elsewhere/style.md", "/elsewhere/style.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key(repos, tt.repositoryID, tt.path); got != tt.want {
+				t.Errorf("Key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStars(t *testing.T) {
+	tests := map[int]string{0: "", 1: "★☆☆☆☆", 4: "★★★★☆", 5: "★★★★★", 9: "★★★★★"}
+	for rating, want := range tests {
+		if got := Stars(rating); got != want {
+			t.Errorf("Stars(%d) = %q, want %q", rating, got, want)
+		}
+	}
+}
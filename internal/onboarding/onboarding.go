@@ -0,0 +1,101 @@
+// Package onboarding remembers how far a user has got with the TUI's
+// first-run tour and which contextual tips they have dismissed, so neither is
+// shown again.
+//
+// Progress lives in the user's state directory, in rulem/onboarding.yaml (e.g.
+// ~/.local/state/rulem/onboarding.yaml on Linux):
+//
+//	tourCompleted: true
+//	dismissedTips:
+//	  - menu
+//	  - save-rules
+package onboarding
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"rulem/pkg/fileops"
+
+	"github.com/adrg/xdg"
+	"gopkg.in/yaml.v3"
+)
+
+// Store records the user's onboarding progress
+type Store struct {
+	path          string
+	TourCompleted bool     `yaml:"tourCompleted"` // The tour was finished or skipped
+	DismissedTips []string `yaml:"dismissedTips,omitempty"`
+}
+
+// Path returns the onboarding file in the user's state directory. It can be
+// overridden with the RULEM_ONBOARDING_PATH environment variable for testing.
+func Path() string {
+	if testPath := os.Getenv("RULEM_ONBOARDING_PATH"); testPath != "" {
+		return testPath
+	}
+	return filepath.Join(xdg.StateHome, "rulem", "onboarding.yaml")
+}
+
+// Load reads the onboarding file at path. A missing file is a fresh start.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read onboarding progress: %w", err)
+	}
+	if err := yaml.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("invalid onboarding file %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// TipDismissed reports whether the tip with id was dismissed
+func (s *Store) TipDismissed(id string) bool {
+	return slices.Contains(s.DismissedTips, id)
+}
+
+// DismissTip records that the tip with id should not be shown again
+func (s *Store) DismissTip(id string) {
+	if !s.TipDismissed(id) {
+		s.DismissedTips = append(s.DismissedTips, id)
+	}
+}
+
+// CompleteTour records that the tour was finished or skipped
+func (s *Store) CompleteTour() {
+	s.TourCompleted = true
+}
+
+// Reset forgets all progress, so the tour and every tip are shown again
+func (s *Store) Reset() {
+	s.TourCompleted = false
+	s.DismissedTips = nil
+}
+
+// Save writes the store back to the file it was loaded from, replacing it atomically
+func (s *Store) Save() error {
+	if err := fileops.EnsureDirectoryExists(filepath.Dir(s.path)); err != nil {
+		return fmt.Errorf("cannot create onboarding directory: %w", err)
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode onboarding progress: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write onboarding progress: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write onboarding progress: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package onboarding
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rulem", "onboarding.yaml")
+
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load of a missing file: %v", err)
+	}
+	if store.TourCompleted || len(store.DismissedTips) != 0 {
+		t.Fatalf("expected a fresh start, got %+v", store)
+	}
+
+	store.CompleteTour()
+	store.DismissTip("menu")
+	store.DismissTip("menu") // Dismissing twice records it once
+	store.DismissTip("save-rules")
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("onboarding file mode = %o, want 600", perm)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !loaded.TourCompleted {
+		t.Error("tour should be recorded as completed")
+	}
+	if len(loaded.DismissedTips) != 2 || !loaded.TipDismissed("menu") || !loaded.TipDismissed("save-rules") {
+		t.Errorf("DismissedTips = %v, want [menu save-rules]", loaded.DismissedTips)
+	}
+	if loaded.TipDismissed("import") {
+		t.Error("tip that was never dismissed reported as dismissed")
+	}
+
+	loaded.Reset()
+	if loaded.TourCompleted || loaded.TipDismissed("menu") {
+		t.Errorf("Reset should forget all progress, got %+v", loaded)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "onboarding.yaml")
+	if err := os.WriteFile(path, []byte("dismissedTips: {"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for an invalid onboarding file")
+	}
+}
+
+func TestPath_EnvOverride(t *testing.T) {
+	t.Setenv("RULEM_ONBOARDING_PATH", "/tmp/onboarding.yaml")
+	if got := Path(); got != "/tmp/onboarding.yaml" {
+		t.Errorf("Path() = %q, want the RULEM_ONBOARDING_PATH override", got)
+	}
+}
@@ -0,0 +1,257 @@
+// Package policy loads and enforces organization policies distributed in rule repositories.
+//
+// An organization can commit a read-only rulem-policy.yaml to the root of its
+// rules repository to constrain every rulem client that uses it:
+//
+//	min_rulem_version: 1.4.0
+//	allowed_repositories:
+//	  - https://github.com/acme/
+//	allow_local_repositories: false
+//	allow_proposals: false
+//	require_content_security: standard
+//
+// Policies are evaluated at startup. When several configured repositories ship a
+// policy, the strictest combination applies: the highest minimum version wins,
+// every repository must satisfy every allowlist and every required content
+// security profile, and any policy can disallow rule proposals.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/internal/version"
+	"rulem/pkg/fileops"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the policy file looked up at the root of each repository
+const FileName = "rulem-policy.yaml"
+
+// Policy is the content of a single policy file
+type Policy struct {
+	// MinRulemVersion is the oldest rulem release allowed to use the repository
+	MinRulemVersion string `yaml:"min_rulem_version,omitempty"`
+
+	// AllowedRepositories lists URL prefixes that remote repositories must
+	// match, on whole path segments
+	AllowedRepositories []string `yaml:"allowed_repositories,omitempty"`
+
+	// AllowLocalRepositories permits local directory repositories; defaults to true
+	AllowLocalRepositories *bool `yaml:"allow_local_repositories,omitempty"`
+
+	// AllowProposals permits the propose_rule MCP tool; defaults to true
+	AllowProposals *bool `yaml:"allow_proposals,omitempty"`
+
+	// RequireContentSecurity names the weakest content security profile the
+	// configuration may apply to any repository, e.g. "standard", which strips
+	// hidden text. A profile or override that filters less is a violation.
+	RequireContentSecurity string `yaml:"require_content_security,omitempty"`
+
+	// Source identifies the repository the policy was loaded from
+	Source string `yaml:"-"`
+}
+
+// Report is the outcome of evaluating policies against the running client
+type Report struct {
+	Applied    []string // Human-readable description of each applied policy rule
+	Violations []string // Human-readable description of each violation
+
+	ProposalsDisabled bool // A policy disallows the propose_rule MCP tool
+}
+
+// Err returns an error listing all violations, or nil if there are none
+func (r *Report) Err() error {
+	if len(r.Violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("organization policy violated:\n  - %s", strings.Join(r.Violations, "\n  - "))
+}
+
+// Load reads the policy file from the root of each repository. Repositories
+// without a policy file, or not yet cloned, are skipped.
+//
+// Returns:
+//   - []Policy: Policies found, in repository order
+//   - error: If a policy file exists but cannot be read or parsed; a broken
+//     policy must not silently stop being enforced
+func Load(repos []repository.RepositoryEntry, logger *logging.AppLogger) ([]Policy, error) {
+	var policies []Policy
+	for _, repo := range repos {
+		if repo.Path == "" {
+			continue
+		}
+
+		path := filepath.Join(repo.Path, FileName)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read policy for repository %s: %w", repo.Name, err)
+		}
+
+		var p Policy
+		if err := yaml.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("invalid policy in repository %s: %w", repo.Name, err)
+		}
+		p.Source = repo.Name
+
+		if logger != nil {
+			logger.Debug("Loaded organization policy", "repository", repo.Name, "path", path)
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// Evaluate checks the client version, configured repositories and the global
+// content_security settings against all policies.
+//
+// Development builds cannot be compared, so a minimum version is reported as
+// applied but not enforced for them.
+func Evaluate(policies []Policy, clientVersion string, repos []repository.RepositoryEntry, contentSecurity *fileops.ContentSecurity) *Report {
+	report := &Report{}
+
+	for _, p := range policies {
+		if p.MinRulemVersion != "" {
+			report.Applied = append(report.Applied, fmt.Sprintf("%s: minimum rulem version %s", p.Source, p.MinRulemVersion))
+			if violation := checkMinVersion(p, clientVersion); violation != "" {
+				report.Violations = append(report.Violations, violation)
+			}
+		}
+
+		if len(p.AllowedRepositories) > 0 {
+			report.Applied = append(report.Applied, fmt.Sprintf("%s: remote repositories restricted to %s",
+				p.Source, strings.Join(p.AllowedRepositories, ", ")))
+		}
+		if p.AllowLocalRepositories != nil && !*p.AllowLocalRepositories {
+			report.Applied = append(report.Applied, fmt.Sprintf("%s: local repositories not allowed", p.Source))
+		}
+		if p.AllowProposals != nil && !*p.AllowProposals {
+			report.Applied = append(report.Applied, fmt.Sprintf("%s: rule proposals not allowed", p.Source))
+			report.ProposalsDisabled = true
+		}
+		if p.RequireContentSecurity != "" {
+			report.Applied = append(report.Applied, fmt.Sprintf("%s: content security at least %s", p.Source, p.RequireContentSecurity))
+			report.Violations = append(report.Violations, checkContentSecurity(p, repos, contentSecurity)...)
+		}
+
+		for _, repo := range repos {
+			if violation := checkRepository(p, repo); violation != "" {
+				report.Violations = append(report.Violations, violation)
+			}
+		}
+	}
+
+	return report
+}
+
+// checkMinVersion returns a violation if clientVersion is older than the policy minimum
+func checkMinVersion(p Policy, clientVersion string) string {
+	if !version.IsRelease(clientVersion) {
+		return ""
+	}
+
+	ok, err := version.AtLeast(clientVersion, p.MinRulemVersion)
+	if err != nil {
+		return fmt.Sprintf("%s: invalid min_rulem_version: %v", p.Source, err)
+	}
+	if !ok {
+		return fmt.Sprintf("%s: requires rulem %s or newer (running %s); please upgrade rulem",
+			p.Source, p.MinRulemVersion, clientVersion)
+	}
+	return ""
+}
+
+// checkRepository returns a violation if repo is not allowed by the policy
+func checkRepository(p Policy, repo repository.RepositoryEntry) string {
+	if repo.IsLocal() {
+		if p.AllowLocalRepositories != nil && !*p.AllowLocalRepositories {
+			return fmt.Sprintf("%s: local repository %q is not allowed; remove it from your rulem configuration", p.Source, repo.Name)
+		}
+		return ""
+	}
+
+	if len(p.AllowedRepositories) == 0 {
+		return ""
+	}
+	url := repo.GetRemoteURL()
+	for _, prefix := range p.AllowedRepositories {
+		if matchesPrefix(url, prefix) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%s: repository %q (%s) is not in the allowed list; remove it from your rulem configuration", p.Source, repo.Name, url)
+}
+
+// actionStrength orders content actions from letting content through to
+// rejecting it; stripping hidden text filters more than warning about it
+var actionStrength = map[fileops.ContentAction]int{
+	fileops.ContentAllow: 0,
+	fileops.ContentWarn:  1,
+	fileops.ContentStrip: 2,
+	fileops.ContentBlock: 3,
+}
+
+// checkContentSecurity returns a violation for the global settings and for
+// each repository whose content policy filters less than the policy's
+// required profile
+func checkContentSecurity(p Policy, repos []repository.RepositoryEntry, global *fileops.ContentSecurity) []string {
+	required, err := fileops.ContentProfile(p.RequireContentSecurity)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: invalid require_content_security: %v", p.Source, err)}
+	}
+
+	var violations []string
+	check := func(where string, layers ...*fileops.ContentSecurity) {
+		effective, err := fileops.ResolveContentPolicy(layers...)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("%s: invalid content_security %s: %v", p.Source, where, err))
+			return
+		}
+		for _, category := range fileops.ContentCategories {
+			if actionStrength[effective[category]] < actionStrength[required[category]] {
+				violations = append(violations, fmt.Sprintf("%s: content_security %s sets %s to %q, weaker than the required %s profile (%q); remove the override from your rulem configuration",
+					p.Source, where, category, actionOrAllow(effective[category]), p.RequireContentSecurity, required[category]))
+			}
+		}
+	}
+
+	check("in config.yaml", global)
+	for _, repo := range repos {
+		if repo.ContentSecurity != nil {
+			check(fmt.Sprintf("of repository %q", repo.Name), global, repo.ContentSecurity)
+		}
+	}
+	return violations
+}
+
+// actionOrAllow names action, or allow for a category a policy leaves out
+func actionOrAllow(action fileops.ContentAction) fileops.ContentAction {
+	if action == "" {
+		return fileops.ContentAllow
+	}
+	return action
+}
+
+// matchesPrefix reports whether url starts with prefix on whole path segments,
+// so https://github.com/acme allows https://github.com/acme/rules but not
+// https://github.com/acme-evil/rules. A prefix ending in "/" or ":" already
+// ends a segment.
+func matchesPrefix(url, prefix string) bool {
+	if !strings.HasPrefix(url, prefix) {
+		return false
+	}
+	rest := url[len(prefix):]
+	if prefix == "" || rest == "" || rest == ".git" || strings.HasSuffix(prefix, "/") || strings.HasSuffix(prefix, ":") {
+		return true
+	}
+	return strings.HasPrefix(rest, "/")
+}
@@ -0,0 +1,215 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+)
+
+func remoteRepo(name, url string) repository.RepositoryEntry {
+	return repository.RepositoryEntry{ID: name, Name: name, Type: repository.RepositoryTypeGitHub, RemoteURL: &url}
+}
+
+func localRepo(name, path string) repository.RepositoryEntry {
+	return repository.RepositoryEntry{ID: name, Name: name, Type: repository.RepositoryTypeLocal, Path: path}
+}
+
+func TestLoad(t *testing.T) {
+	withPolicy := t.TempDir()
+	withoutPolicy := t.TempDir()
+	if err := os.WriteFile(filepath.Join(withPolicy, FileName), []byte("min_rulem_version: 1.2.0\nallowed_repositories:\n  - https://github.com/acme/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	policies, err := Load([]repository.RepositoryEntry{
+		localRepo("org", withPolicy),
+		localRepo("personal", withoutPolicy),
+		localRepo("not-cloned", filepath.Join(withoutPolicy, "missing")),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(policies) != 1 {
+		t.Fatalf("got %d policies, want 1", len(policies))
+	}
+	if policies[0].Source != "org" || policies[0].MinRulemVersion != "1.2.0" || len(policies[0].AllowedRepositories) != 1 {
+		t.Errorf("unexpected policy: %+v", policies[0])
+	}
+}
+
+func TestLoadInvalidPolicy(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("allowed_repositories: [unterminated\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load([]repository.RepositoryEntry{localRepo("org", dir)}, nil); err == nil {
+		t.Error("expected error for invalid policy file")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	noLocal := false
+
+	tests := []struct {
+		name           string
+		policies       []Policy
+		clientVersion  string
+		repos          []repository.RepositoryEntry
+		wantApplied    int
+		wantViolations int
+	}{
+		{
+			name:          "no policies",
+			clientVersion: "1.0.0",
+		},
+		{
+			name:          "version satisfied",
+			policies:      []Policy{{Source: "org", MinRulemVersion: "1.2.0"}},
+			clientVersion: "v1.3.0",
+			wantApplied:   1,
+		},
+		{
+			name:           "version too old",
+			policies:       []Policy{{Source: "org", MinRulemVersion: "1.2.0"}},
+			clientVersion:  "1.1.9",
+			wantApplied:    1,
+			wantViolations: 1,
+		},
+		{
+			name:          "dev build is not blocked",
+			policies:      []Policy{{Source: "org", MinRulemVersion: "1.2.0"}},
+			clientVersion: "dev",
+			wantApplied:   1,
+		},
+		{
+			name:          "allowed remote repository",
+			policies:      []Policy{{Source: "org", AllowedRepositories: []string{"https://github.com/acme/"}}},
+			clientVersion: "1.0.0",
+			repos: []repository.RepositoryEntry{
+				remoteRepo("rules", "https://github.com/acme/rules.git"),
+				localRepo("scratch", "/tmp/scratch"),
+			},
+			wantApplied: 1,
+		},
+		{
+			name:          "disallowed remote repository",
+			policies:      []Policy{{Source: "org", AllowedRepositories: []string{"https://github.com/acme/"}}},
+			clientVersion: "1.0.0",
+			repos: []repository.RepositoryEntry{
+				remoteRepo("rules", "https://github.com/acme/rules.git"),
+				remoteRepo("other", "https://github.com/elsewhere/rules.git"),
+			},
+			wantApplied:    1,
+			wantViolations: 1,
+		},
+		{
+			name:          "allowlist matches whole path segments",
+			policies:      []Policy{{Source: "org", AllowedRepositories: []string{"https://github.com/acme", "https://github.com/tools/rules"}}},
+			clientVersion: "1.0.0",
+			repos: []repository.RepositoryEntry{
+				remoteRepo("rules", "https://github.com/acme/rules.git"),
+				remoteRepo("tools", "https://github.com/tools/rules.git"),
+				remoteRepo("evil", "https://github.com/acme-evil/rules.git"),
+				remoteRepo("lookalike", "https://github.com/tools/rules-fork.git"),
+			},
+			wantApplied:    1,
+			wantViolations: 2,
+		},
+		{
+			name:           "local repositories forbidden",
+			policies:       []Policy{{Source: "org", AllowLocalRepositories: &noLocal}},
+			clientVersion:  "1.0.0",
+			repos:          []repository.RepositoryEntry{localRepo("scratch", "/tmp/scratch")},
+			wantApplied:    1,
+			wantViolations: 1,
+		},
+		{
+			name: "strictest minimum wins",
+			policies: []Policy{
+				{Source: "a", MinRulemVersion: "1.0.0"},
+				{Source: "b", MinRulemVersion: "2.0.0"},
+			},
+			clientVersion:  "1.5.0",
+			wantApplied:    2,
+			wantViolations: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Evaluate(tt.policies, tt.clientVersion, tt.repos, nil)
+			if len(report.Applied) != tt.wantApplied {
+				t.Errorf("applied = %v, want %d entries", report.Applied, tt.wantApplied)
+			}
+			if len(report.Violations) != tt.wantViolations {
+				t.Errorf("violations = %v, want %d entries", report.Violations, tt.wantViolations)
+			}
+			if (report.Err() != nil) != (tt.wantViolations > 0) {
+				t.Errorf("Err() = %v, want error: %v", report.Err(), tt.wantViolations > 0)
+			}
+		})
+	}
+}
+
+func TestEvaluateProposals(t *testing.T) {
+	noProposals := false
+	allowed := true
+
+	report := Evaluate([]Policy{{Source: "org", AllowProposals: &allowed}}, "1.0.0", nil, nil)
+	if report.ProposalsDisabled || len(report.Applied) != 0 {
+		t.Errorf("report = %+v, want proposals allowed", report)
+	}
+
+	report = Evaluate([]Policy{{Source: "a"}, {Source: "b", AllowProposals: &noProposals}}, "1.0.0", nil, nil)
+	if !report.ProposalsDisabled || len(report.Applied) != 1 || report.Err() != nil {
+		t.Errorf("report = %+v, want proposals disabled without a violation", report)
+	}
+}
+
+func TestEvaluateContentSecurity(t *testing.T) {
+	withContent := func(repo repository.RepositoryEntry, c fileops.ContentSecurity) repository.RepositoryEntry {
+		repo.ContentSecurity = &c
+		return repo
+	}
+	standard := []Policy{{Source: "org", RequireContentSecurity: fileops.ContentProfileStandard}}
+
+	tests := []struct {
+		name           string
+		policies       []Policy
+		repos          []repository.RepositoryEntry
+		global         *fileops.ContentSecurity
+		wantViolations int
+	}{
+		{name: "defaults meet standard", policies: standard, repos: []repository.RepositoryEntry{remoteRepo("team", "https://github.com/acme/rules")}},
+		{name: "strict global meets standard", policies: standard, global: &fileops.ContentSecurity{Profile: fileops.ContentProfileStrict}},
+		{name: "permissive global", policies: standard, global: &fileops.ContentSecurity{Profile: fileops.ContentProfilePermissive}, wantViolations: 4},
+		{name: "hidden text only warned", policies: standard, global: &fileops.ContentSecurity{HiddenText: fileops.ContentWarn}, wantViolations: 1},
+		{
+			name:     "repository override weakens",
+			policies: standard,
+			repos: []repository.RepositoryEntry{
+				withContent(remoteRepo("web", "https://github.com/acme/web"), fileops.ContentSecurity{Scripts: fileops.ContentAllow}),
+				remoteRepo("team", "https://github.com/acme/rules"),
+			},
+			wantViolations: 1,
+		},
+		{name: "defaults fall short of strict", policies: []Policy{{Source: "org", RequireContentSecurity: fileops.ContentProfileStrict}}, wantViolations: 2},
+		{name: "unknown profile", policies: []Policy{{Source: "org", RequireContentSecurity: "paranoid"}}, wantViolations: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Evaluate(tt.policies, "1.0.0", tt.repos, tt.global)
+			if len(report.Applied) != 1 {
+				t.Errorf("applied = %v, want the required profile", report.Applied)
+			}
+			if len(report.Violations) != tt.wantViolations {
+				t.Errorf("violations = %v, want %d entries", report.Violations, tt.wantViolations)
+			}
+		})
+	}
+}
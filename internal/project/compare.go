@@ -0,0 +1,189 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"rulem/internal/repository"
+)
+
+// Status is how a deployed rule compares with its central version
+type Status string
+
+const (
+	StatusUpToDate      Status = "up to date"     // Deployed and central content are equal
+	StatusChanged       Status = "changed"        // The contents differ
+	StatusMissing       Status = "missing"        // The deployed file was deleted from the project
+	StatusSourceMissing Status = "source missing" // The rule no longer exists in the repository
+	StatusError         Status = "error"          // The comparison failed, see Comparison.Err
+)
+
+// Comparison is the result of comparing one deployed rule with its central version
+type Comparison struct {
+	Entry    Entry
+	Status   Status
+	Deployed []byte // Content in the project, nil when missing
+	Central  []byte // Content in the repository, rewritten like the deployed rule; nil when missing
+	Rule     []byte // Content in the repository before Entry.Rewrite, nil when missing
+	// CentralName labels the central version, e.g. "Team Rules:go/style.md@main"
+	CentralName string
+	Err         error
+}
+
+// Drifted reports whether the deployed rule differs from its central version
+func (c Comparison) Drifted() bool {
+	return c.Status == StatusChanged || c.Status == StatusMissing || c.Status == StatusSourceMissing
+}
+
+// LocallyModified reports whether the deployed file was edited since it was
+// deployed, as opposed to only the central version having changed
+func (c Comparison) LocallyModified() bool {
+	return c.Deployed != nil && c.Entry.SHA256 != "" && Hash(c.Deployed) != c.Entry.SHA256
+}
+
+// Label names the state of the deployed rule for rulem status: a changed rule
+// is "modified" when it was edited in the project since it was deployed and
+// "outdated" when only its central version changed
+func (c Comparison) Label() string {
+	if c.Status != StatusChanged {
+		return string(c.Status)
+	}
+	if c.LocallyModified() {
+		return "modified"
+	}
+	return "outdated"
+}
+
+// CanResync reports whether deploying the rule again would bring it up to
+// date, which is not the case when its source is gone from the repository
+func (c Comparison) CanResync() bool {
+	return c.Status == StatusChanged || c.Status == StatusMissing
+}
+
+// Diff returns a unified diff that turns the deployed file into the central
+// version, or "" when they are equal or the comparison failed
+func (c Comparison) Diff() string {
+	if !c.Drifted() {
+		return ""
+	}
+	from, to := "a/"+c.Entry.Path, "b/"+c.Entry.Path
+	if c.Status == StatusMissing {
+		from = "/dev/null"
+	}
+	if c.Status == StatusSourceMissing {
+		to = "/dev/null"
+	}
+	header := fmt.Sprintf("diff %s %s\n", c.Entry.Path, c.CentralName)
+	return header + UnifiedDiff(from, to, c.Deployed, c.Central)
+}
+
+// Compare compares every rule in the manifest of the project at projectDir
+// with its central version, in manifest order.
+//
+// With an empty revision the central version is the repository's working
+// tree, where a file in the overlay shadows the shared one. Otherwise it is
+// the file at that branch, tag or commit of the repository's git history.
+func Compare(projectDir string, manifest *Manifest, prepared []repository.PreparedRepository, revision string) []Comparison {
+	byID := make(map[string]repository.PreparedRepository, len(prepared))
+	for _, prep := range prepared {
+		byID[prep.ID()] = prep
+	}
+
+	results := make([]Comparison, 0, len(manifest.Rules))
+	for _, entry := range manifest.Rules {
+		results = append(results, compareEntry(projectDir, entry, byID, revision))
+	}
+	return results
+}
+
+func compareEntry(projectDir string, entry Entry, byID map[string]repository.PreparedRepository, revision string) Comparison {
+	result := Comparison{Entry: entry, CentralName: entry.Repository + ":" + entry.Source}
+	fail := func(err error) Comparison {
+		result.Status = StatusError
+		result.Err = err
+		return result
+	}
+
+	prep, ok := byID[entry.Repository]
+	if !ok {
+		return fail(fmt.Errorf("repository %s is not configured", entry.Repository))
+	}
+	result.CentralName = prep.Name() + ":" + entry.Source
+	if revision != "" {
+		result.CentralName += "@" + revision
+	}
+	if !prep.IsAvailable() {
+		return fail(fmt.Errorf("repository %s is not available: %s", prep.Name(), prep.GetStatusMessage()))
+	}
+
+	deployedPath, err := localPath(projectDir, entry.Path)
+	if err != nil {
+		return fail(err)
+	}
+	deployedMissing := false
+	if result.Deployed, err = os.ReadFile(deployedPath); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fail(fmt.Errorf("failed to read %s: %w", entry.Path, err))
+		}
+		deployedMissing = true
+	}
+
+	sourceMissing := false
+	if result.Central, err = centralContent(prep, entry.Source, revision); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fail(err)
+		}
+		sourceMissing = true
+	}
+	result.Rule = result.Central
+	if entry.Rewrite != nil && !sourceMissing {
+		if result.Central, err = entry.Rewrite.Apply(result.Rule); err != nil {
+			return fail(fmt.Errorf("failed to rewrite %s like %s: %w", result.CentralName, entry.Path, err))
+		}
+	}
+
+	switch {
+	case deployedMissing && sourceMissing:
+		return fail(fmt.Errorf("%s is missing from both the project and %s", entry.Path, result.CentralName))
+	case deployedMissing:
+		result.Status = StatusMissing
+	case sourceMissing:
+		result.Status = StatusSourceMissing
+	case string(result.Deployed) == string(result.Central):
+		result.Status = StatusUpToDate
+	default:
+		result.Status = StatusChanged
+	}
+	return result
+}
+
+// centralContent reads a rule from the repository's working tree, or from its
+// git history when revision is set. Missing files return os.ErrNotExist (wrapped).
+func centralContent(prep repository.PreparedRepository, source, revision string) ([]byte, error) {
+	if revision != "" {
+		// Git history is relative to the clone, which may hold more than the rules
+		content, err := repository.ReadFileAtRevision(prep.GitRoot(), revision, path.Join(prep.Entry.GetSubpath(), filepath.ToSlash(source)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s: %w", prep.Name(), err)
+		}
+		return content, err
+	}
+
+	for _, root := range []string{prep.OverlayPath, prep.LocalPath} {
+		if root == "" {
+			continue
+		}
+		path, err := localPath(root, source)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err == nil || !errors.Is(err, os.ErrNotExist) {
+			return content, err
+		}
+	}
+	return nil, fmt.Errorf("%s not found in %s: %w", source, prep.Name(), os.ErrNotExist)
+}
@@ -0,0 +1,188 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"rulem/internal/editors"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// prepareRepository prepares a local repository holding files
+func prepareRepository(t *testing.T, files map[string]string) (repository.PreparedRepository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+	}
+
+	logger, _ := logging.NewTestLogger()
+	entries := []repository.RepositoryEntry{
+		{ID: "rules-1", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: dir},
+	}
+	prepared, err := repository.PrepareAllRepositories(context.Background(), entries, logger)
+	if err != nil {
+		t.Fatalf("PrepareAllRepositories: %v", err)
+	}
+	return prepared[0], prepared[0].LocalPath
+}
+
+func TestCompare(t *testing.T) {
+	prep, _ := prepareRepository(t, map[string]string{
+		"current.md": "same\n",
+		"changed.md": "central\n",
+		"deleted.md": "gone from project\n",
+	})
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "current.md"), "same\n")
+	writeFile(t, filepath.Join(projectDir, "changed.md"), "local\n")
+	writeFile(t, filepath.Join(projectDir, "orphan.md"), "no source\n")
+
+	manifest := &Manifest{Rules: []Entry{
+		{Path: "current.md", Repository: "rules-1", Source: "current.md"},
+		{Path: "changed.md", Repository: "rules-1", Source: "changed.md"},
+		{Path: "deleted.md", Repository: "rules-1", Source: "deleted.md"},
+		{Path: "orphan.md", Repository: "rules-1", Source: "removed.md"},
+		{Path: "current.md", Repository: "unknown-1", Source: "current.md"},
+		{Path: "../escape.md", Repository: "rules-1", Source: "current.md"},
+	}}
+
+	results := Compare(projectDir, manifest, []repository.PreparedRepository{prep}, "")
+	want := []Status{StatusUpToDate, StatusChanged, StatusMissing, StatusSourceMissing, StatusError, StatusError}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("%s from %s: status %q, want %q (err %v)", result.Entry.Path, result.Entry.Repository, result.Status, want[i], result.Err)
+		}
+		if result.Drifted() != (want[i] != StatusUpToDate && want[i] != StatusError) {
+			t.Errorf("%s: Drifted() = %v", result.Entry.Path, result.Drifted())
+		}
+	}
+
+	diff := results[1].Diff()
+	for _, line := range []string{"diff changed.md Rules:changed.md", "--- a/changed.md", "+++ b/changed.md", "-local", "+central"} {
+		if !strings.Contains(diff, line+"\n") {
+			t.Errorf("diff missing %q:\n%s", line, diff)
+		}
+	}
+	if diff := results[2].Diff(); !strings.Contains(diff, "--- /dev/null\n") || !strings.Contains(diff, "+gone from project\n") {
+		t.Errorf("missing file diff:\n%s", diff)
+	}
+	if diff := results[3].Diff(); !strings.Contains(diff, "+++ /dev/null\n") || !strings.Contains(diff, "-no source\n") {
+		t.Errorf("removed source diff:\n%s", diff)
+	}
+	if results[0].Diff() != "" || results[4].Diff() != "" {
+		t.Error("up-to-date and failed comparisons should have no diff")
+	}
+}
+
+func TestComparisonLabel(t *testing.T) {
+	deployed := []byte("local\n")
+	tests := []struct {
+		c         Comparison
+		want      string
+		canResync bool
+	}{
+		{Comparison{Status: StatusUpToDate}, "up to date", false},
+		{Comparison{Status: StatusChanged, Deployed: deployed, Entry: Entry{SHA256: Hash(deployed)}}, "outdated", true},
+		{Comparison{Status: StatusChanged, Deployed: deployed, Entry: Entry{SHA256: Hash([]byte("deployed\n"))}}, "modified", true},
+		{Comparison{Status: StatusMissing}, "missing", true},
+		{Comparison{Status: StatusSourceMissing, Deployed: deployed}, "source missing", false},
+		{Comparison{Status: StatusError}, "error", false},
+	}
+	for _, tt := range tests {
+		if got := tt.c.Label(); got != tt.want || tt.c.CanResync() != tt.canResync {
+			t.Errorf("Label() = %q, CanResync() = %v; want %q, %v", got, tt.c.CanResync(), tt.want, tt.canResync)
+		}
+	}
+}
+
+func TestCompare_Rewrite(t *testing.T) {
+	prep, _ := prepareRepository(t, map[string]string{"go.md": "---\ndescription: Go\napplyTo: \"*.go\"\n---\n# go\n"})
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "AGENTS.md"), "# go\n")
+
+	manifest := &Manifest{Rules: []Entry{
+		{Path: "AGENTS.md", Repository: "rules-1", Source: "go.md", Rewrite: &editors.FrontmatterRewrite{Strip: true}},
+		{Path: "AGENTS.md", Repository: "rules-1", Source: "go.md"},
+	}}
+	results := Compare(projectDir, manifest, []repository.PreparedRepository{prep}, "")
+	if results[0].Status != StatusUpToDate || !strings.HasPrefix(string(results[0].Rule), "---\n") {
+		t.Errorf("rewritten rule: %q (err %v), want up to date with the rule kept", results[0].Status, results[0].Err)
+	}
+	if results[1].Status != StatusChanged {
+		t.Errorf("without the rewrite: %q, want changed", results[1].Status)
+	}
+}
+
+func TestCompare_Revision(t *testing.T) {
+	prep, repoDir := prepareRepository(t, map[string]string{"style.md": "v1\n"})
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if _, err := worktree.Add("style.md"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := worktree.Commit("add style", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	// Uncommitted change in the working tree
+	writeFile(t, filepath.Join(repoDir, "style.md"), "v2\n")
+
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "style.md"), "v1\n")
+	manifest := &Manifest{Rules: []Entry{{Path: "style.md", Repository: "rules-1", Source: "style.md"}}}
+	prepared := []repository.PreparedRepository{prep}
+
+	if result := Compare(projectDir, manifest, prepared, "")[0]; result.Status != StatusChanged {
+		t.Errorf("against working tree: %q (err %v), want changed", result.Status, result.Err)
+	}
+	result := Compare(projectDir, manifest, prepared, "HEAD")[0]
+	if result.Status != StatusUpToDate || result.CentralName != "Rules:style.md@HEAD" {
+		t.Errorf("against HEAD: %q %q (err %v), want up to date", result.Status, result.CentralName, result.Err)
+	}
+	if result := Compare(projectDir, manifest, prepared, "no-such-branch")[0]; result.Status != StatusError {
+		t.Errorf("against unknown revision: %q, want error", result.Status)
+	}
+}
+
+func TestCompare_Overlay(t *testing.T) {
+	prep, _ := prepareRepository(t, map[string]string{"style.md": "shared\n"})
+	prep.OverlayPath = t.TempDir()
+	writeFile(t, filepath.Join(prep.OverlayPath, "style.md"), "mine\n")
+
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "style.md"), "mine\n")
+	manifest := &Manifest{Rules: []Entry{{Path: "style.md", Repository: "rules-1", Source: "style.md"}}}
+
+	if result := Compare(projectDir, manifest, []repository.PreparedRepository{prep}, "")[0]; result.Status != StatusUpToDate {
+		t.Errorf("status %q (err %v), want the overlay copy to be compared", result.Status, result.Err)
+	}
+}
@@ -0,0 +1,196 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// maxDiffCells bounds the line-matching table; larger files are shown as a
+// single hunk replacing every line
+const maxDiffCells = 16 * 1024 * 1024
+
+// edit is one line of an edit script: ' ' kept, '-' removed or '+' added
+type edit struct {
+	kind byte
+	line string // Including its newline, if any
+}
+
+// UnifiedDiff returns a unified diff turning from into to, labelled with the
+// given file names, or "" when the contents are equal.
+func UnifiedDiff(fromName, toName string, from, to []byte) string {
+	if string(from) == string(to) {
+		return ""
+	}
+	edits := diffLines(splitLines(string(from)), splitLines(string(to)))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+
+	// fromLine[i] and toLine[i] count the lines consumed before edits[i]
+	fromLine := make([]int, len(edits)+1)
+	toLine := make([]int, len(edits)+1)
+	for i, e := range edits {
+		fromLine[i+1], toLine[i+1] = fromLine[i], toLine[i]
+		if e.kind != '+' {
+			fromLine[i+1]++
+		}
+		if e.kind != '-' {
+			toLine[i+1]++
+		}
+	}
+
+	for _, h := range hunks(edits) {
+		fromCount := fromLine[h.end] - fromLine[h.start]
+		toCount := toLine[h.end] - toLine[h.start]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(fromLine[h.start], fromCount), hunkRange(toLine[h.start], toCount))
+		for _, e := range edits[h.start:h.end] {
+			b.WriteByte(e.kind)
+			b.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// firstChangedLine returns the 1-based line of from where it first differs
+// from to. A change after the last line reports the last line.
+func firstChangedLine(from, to []byte) int {
+	lines := splitLines(string(from))
+	line := 0
+	for _, e := range diffLines(lines, splitLines(string(to))) {
+		if e.kind != ' ' {
+			break
+		}
+		line++
+	}
+	return max(1, min(line+1, len(lines)))
+}
+
+// hunkRange formats the start and length of one side of a hunk header. An empty
+// side starts at the line before the change, as diff(1) does.
+func hunkRange(before, count int) string {
+	start := before + 1
+	if count == 0 {
+		start = before
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// span is a half-open range of edits
+type span struct{ start, end int }
+
+// hunks groups the changes of an edit script with their context, merging
+// changes whose context would overlap
+func hunks(edits []edit) []span {
+	var result []span
+	for i := 0; i < len(edits); i++ {
+		if edits[i].kind == ' ' {
+			continue
+		}
+		start := max(0, i-diffContext)
+		end := min(len(edits), i+1+diffContext)
+		if n := len(result); n > 0 && start <= result[n-1].end {
+			result[n-1].end = end
+		} else {
+			result = append(result, span{start, end})
+		}
+	}
+	return result
+}
+
+// splitLines splits s after each newline; the last line has no newline when s
+// does not end with one
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns an edit script from a to b with a longest common
+// subsequence of lines. Rule files are small, so the quadratic table is fine.
+func diffLines(a, b []string) []edit {
+	// Common prefix and suffix need no table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []edit
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{' ', line})
+	}
+	edits = append(edits, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{' ', line})
+	}
+	return edits
+}
+
+// diffMiddle diffs the lines between the common prefix and suffix
+func diffMiddle(a, b []string) []edit {
+	var edits []edit
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			edits = append(edits, edit{'-', line})
+		}
+		for _, line := range b {
+			edits = append(edits, edit{'+', line})
+		}
+		return edits
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, edit{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, edit{'+', b[j]})
+	}
+	return edits
+}
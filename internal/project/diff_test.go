@@ -0,0 +1,65 @@
+package project
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{name: "equal", from: "a\nb\n", to: "a\nb\n", want: ""},
+		{
+			name: "changed line",
+			from: "a\nb\nc\n",
+			to:   "a\nB\nc\n",
+			want: "--- from\n+++ to\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "added to empty",
+			from: "",
+			to:   "a\nb\n",
+			want: "--- from\n+++ to\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "removed everything",
+			from: "a\n",
+			to:   "",
+			want: "--- from\n+++ to\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name: "missing final newline",
+			from: "a\nb\n",
+			to:   "a\nb",
+			want: "--- from\n+++ to\n@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name: "separate hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			want: "--- from\n+++ to\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			name: "merged hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\neight\n",
+			want: "--- from\n+++ to\n@@ -1,8 +1,8 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n",
+		},
+		{
+			name: "insertion in the middle",
+			from: "a\nb\nc\nd\n",
+			to:   "a\nb\nnew\nc\nd\n",
+			want: "--- from\n+++ to\n@@ -1,4 +1,5 @@\n a\n b\n+new\n c\n d\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("from", "to", []byte(tt.from), []byte(tt.to))
+			if got != tt.want {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"rulem/internal/mcp"
+	"rulem/internal/repository"
+	"rulem/internal/version"
+)
+
+// Inventory lists the rules deployed into a project with their provenance, like
+// a software bill of materials lists dependencies
+type Inventory struct {
+	Generator   string          `json:"generator"`   // rulem version that built the inventory
+	GeneratedAt string          `json:"generatedAt"` // RFC 3339
+	Rules       []InventoryRule `json:"rules"`
+}
+
+// InventoryRule describes one deployed rule.
+//
+// Fields:
+//   - Name: Frontmatter name, or the source file name without extension
+//   - Path: Deployed file, relative to the project root
+//   - Repository: Where the rule came from
+//   - Source: Rule file relative to the repository root
+//   - Commit: Commit of the repository the rule was deployed from, when known
+//   - SHA256: Hash of the deployed file as it is now; empty when it is missing
+//   - Modified: The deployed file changed since it was deployed
+//   - Missing: The deployed file was deleted from the project
+//   - License: The rule's `license` frontmatter field
+//   - Attribution: The rule's `attribution` frontmatter field
+type InventoryRule struct {
+	Name        string              `json:"name"`
+	Path        string              `json:"path"`
+	Repository  InventoryRepository `json:"repository"`
+	Source      string              `json:"source"`
+	Commit      string              `json:"commit,omitempty"`
+	SHA256      string              `json:"sha256,omitempty"`
+	Modified    bool                `json:"modified"`
+	Missing     bool                `json:"missing,omitempty"`
+	License     string              `json:"license,omitempty"`
+	Attribution string              `json:"attribution,omitempty"`
+	Mode        Mode                `json:"mode"`
+	DeployedAt  string              `json:"deployedAt,omitempty"` // RFC 3339
+}
+
+// InventoryRepository identifies the repository a rule came from. Only the ID is
+// known when the repository is not configured.
+type InventoryRepository struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+	URL  string `json:"url,omitempty"` // Remote URL of GitHub repositories
+}
+
+// BuildInventory describes every rule in the manifest of the project at
+// projectDir. Repository details come from repositories, so the repositories
+// need not be prepared (or cloned); processor only parses frontmatter.
+func BuildInventory(projectDir string, manifest *Manifest, repositories []repository.RepositoryEntry, processor *mcp.RuleFileProcessor) (*Inventory, error) {
+	byID := make(map[string]repository.RepositoryEntry, len(repositories))
+	for _, repo := range repositories {
+		byID[repo.ID] = repo
+	}
+
+	inventory := &Inventory{
+		Generator:   "rulem " + version.Current(),
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Rules:       make([]InventoryRule, 0, len(manifest.Rules)),
+	}
+	for _, entry := range manifest.Rules {
+		rule := InventoryRule{
+			Name:       strings.TrimSuffix(path.Base(entry.Source), path.Ext(entry.Source)),
+			Path:       entry.Path,
+			Repository: InventoryRepository{ID: entry.Repository},
+			Source:     entry.Source,
+			Commit:     entry.Commit,
+			Mode:       entry.Mode,
+		}
+		if entry.DeployedAt > 0 {
+			rule.DeployedAt = time.Unix(entry.DeployedAt, 0).UTC().Format(time.RFC3339)
+		}
+		if repo, ok := byID[entry.Repository]; ok {
+			rule.Repository.Name = repo.Name
+			rule.Repository.Type = string(repo.Type)
+			rule.Repository.URL = repo.GetRemoteURL()
+		}
+
+		deployedPath, err := localPath(projectDir, entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(deployedPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			rule.Missing = true
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Path, err)
+		default:
+			rule.SHA256 = Hash(content)
+			rule.Modified = entry.SHA256 != "" && rule.SHA256 != entry.SHA256
+			// Rules without frontmatter keep the file name and have no license
+			if matter, err := processor.Frontmatter(content, path.Base(entry.Path)); err == nil {
+				if matter.Name != "" {
+					rule.Name = matter.Name
+				}
+				rule.License = matter.License
+				rule.Attribution = matter.Attribution
+			}
+		}
+		inventory.Rules = append(inventory.Rules, rule)
+	}
+	return inventory, nil
+}
+
+// WriteJSON writes the inventory as indented JSON
+func (inv *Inventory) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(inv)
+}
+
+// cycloneDX is the subset of the CycloneDX 1.5 JSON format the inventory uses
+type cycloneDX struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    cycloneDXMetadata    `json:"metadata"`
+	Components  []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []cycloneDXComponent `json:"components"`
+	} `json:"tools"`
+}
+
+type cycloneDXComponent struct {
+	Type               string               `json:"type"`
+	BOMRef             string               `json:"bom-ref,omitempty"`
+	Name               string               `json:"name"`
+	Version            string               `json:"version,omitempty"`
+	Hashes             []cycloneDXHash      `json:"hashes,omitempty"`
+	Licenses           []cycloneDXLicense   `json:"licenses,omitempty"`
+	ExternalReferences []cycloneDXReference `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty  `json:"properties,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cycloneDXLicense struct {
+	License struct {
+		Name string `json:"name"`
+	} `json:"license"`
+}
+
+type cycloneDXReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// WriteCycloneDX writes the inventory as a CycloneDX 1.5 JSON bill of
+// materials, with each rule as a file component. Details without a CycloneDX
+// field are recorded as "rulem:" properties.
+func (inv *Inventory) WriteCycloneDX(w io.Writer) error {
+	bom := cycloneDX{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Components:  make([]cycloneDXComponent, 0, len(inv.Rules)),
+	}
+	bom.Metadata.Timestamp = inv.GeneratedAt
+	bom.Metadata.Tools.Components = []cycloneDXComponent{{Type: "application", Name: "rulem", Version: version.Current()}}
+
+	for _, rule := range inv.Rules {
+		component := cycloneDXComponent{
+			Type:    "file",
+			BOMRef:  rule.Path,
+			Name:    rule.Name,
+			Version: rule.Commit,
+		}
+		if rule.SHA256 != "" {
+			component.Hashes = []cycloneDXHash{{Alg: "SHA-256", Content: rule.SHA256}}
+		}
+		if rule.License != "" {
+			var license cycloneDXLicense
+			license.License.Name = rule.License
+			component.Licenses = []cycloneDXLicense{license}
+		}
+		if rule.Repository.URL != "" {
+			component.ExternalReferences = []cycloneDXReference{{Type: "vcs", URL: rule.Repository.URL}}
+		}
+
+		properties := [][2]string{
+			{"rulem:path", rule.Path},
+			{"rulem:repository", rule.Repository.ID},
+			{"rulem:repositoryName", rule.Repository.Name},
+			{"rulem:source", rule.Source},
+			{"rulem:attribution", rule.Attribution},
+			{"rulem:mode", string(rule.Mode)},
+			{"rulem:deployedAt", rule.DeployedAt},
+			{"rulem:modified", fmt.Sprint(rule.Modified)},
+		}
+		if rule.Missing {
+			properties = append(properties, [2]string{"rulem:missing", "true"})
+		}
+		for _, p := range properties {
+			if p[1] != "" {
+				component.Properties = append(component.Properties, cycloneDXProperty{Name: p[0], Value: p[1]})
+			}
+		}
+		bom.Components = append(bom.Components, component)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bom)
+}
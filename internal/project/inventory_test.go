@@ -0,0 +1,78 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"rulem/internal/logging"
+	"rulem/internal/mcp"
+	"rulem/internal/repository"
+)
+
+func TestBuildInventory(t *testing.T) {
+	projectDir := t.TempDir()
+	licensed := "---\ndescription: Style\nname: go-style\nlicense: MIT\nattribution: Acme\n---\n# Style\n"
+	writeFile(t, filepath.Join(projectDir, "style.md"), licensed)
+	writeFile(t, filepath.Join(projectDir, "notes.md"), "# Notes, edited\n")
+
+	manifest := &Manifest{Rules: []Entry{
+		{Path: "style.md", Repository: "team-1", Source: "go/style.md", Mode: ModeCopy, Commit: "abc123", SHA256: Hash([]byte(licensed)), DeployedAt: 1700000000},
+		{Path: "notes.md", Repository: "local-1", Source: "notes.md", Mode: ModeLink, SHA256: Hash([]byte("# Notes\n"))},
+		{Path: "gone.md", Repository: "unknown-1", Source: "misc/gone.md", Mode: ModeCopy},
+	}}
+	url := "https://github.com/acme/rules.git"
+	repositories := []repository.RepositoryEntry{
+		{ID: "team-1", Name: "Team", Type: repository.RepositoryTypeGitHub, RemoteURL: &url},
+		{ID: "local-1", Name: "Mine", Type: repository.RepositoryTypeLocal},
+	}
+	logger, _ := logging.NewTestLogger()
+	processor := mcp.NewRuleFileProcessor(logger, nil, 1024*1024)
+
+	inventory, err := BuildInventory(projectDir, manifest, repositories, processor)
+	if err != nil {
+		t.Fatalf("BuildInventory: %v", err)
+	}
+	want := []InventoryRule{
+		{
+			Name: "go-style", Path: "style.md", Source: "go/style.md", Mode: ModeCopy,
+			Repository: InventoryRepository{ID: "team-1", Name: "Team", Type: "github", URL: url},
+			Commit:     "abc123", SHA256: Hash([]byte(licensed)), License: "MIT", Attribution: "Acme", DeployedAt: "2023-11-14T22:13:20Z",
+		},
+		{
+			Name: "notes", Path: "notes.md", Source: "notes.md", Mode: ModeLink,
+			Repository: InventoryRepository{ID: "local-1", Name: "Mine", Type: "local"},
+			SHA256:     Hash([]byte("# Notes, edited\n")), Modified: true,
+		},
+		{
+			Name: "gone", Path: "gone.md", Source: "misc/gone.md", Mode: ModeCopy,
+			Repository: InventoryRepository{ID: "unknown-1"}, Missing: true,
+		},
+	}
+	if !reflect.DeepEqual(inventory.Rules, want) {
+		t.Errorf("rules =\n%+v\nwant\n%+v", inventory.Rules, want)
+	}
+
+	var buf bytes.Buffer
+	if err := inventory.WriteCycloneDX(&buf); err != nil {
+		t.Fatalf("WriteCycloneDX: %v", err)
+	}
+	var bom cycloneDX
+	if err := json.Unmarshal(buf.Bytes(), &bom); err != nil {
+		t.Fatalf("decode BOM: %v", err)
+	}
+	if bom.BOMFormat != "CycloneDX" || len(bom.Components) != 3 {
+		t.Fatalf("BOM = %+v", bom)
+	}
+	style := bom.Components[0]
+	if style.Type != "file" || style.Name != "go-style" || style.Version != "abc123" ||
+		style.Licenses[0].License.Name != "MIT" || style.ExternalReferences[0].URL != url ||
+		style.Hashes[0].Content != Hash([]byte(licensed)) {
+		t.Errorf("style component = %+v", style)
+	}
+	if gone := bom.Components[2]; len(gone.Hashes) != 0 || gone.Properties[len(gone.Properties)-1].Name != "rulem:missing" {
+		t.Errorf("missing component = %+v", gone)
+	}
+}
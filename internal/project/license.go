@@ -0,0 +1,65 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/mcp"
+)
+
+// RuleLicense is a rule's `license` and `attribution` frontmatter fields
+type RuleLicense struct {
+	License     string
+	Attribution string
+}
+
+// ReadRuleLicense reads the license and attribution of the rule file at path.
+// A rule without frontmatter has neither.
+func ReadRuleLicense(path string, processor *mcp.RuleFileProcessor) (RuleLicense, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return RuleLicense{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	matter, err := processor.Frontmatter(content, filepath.Base(path))
+	if err != nil {
+		return RuleLicense{}, nil
+	}
+	return RuleLicense{License: strings.TrimSpace(matter.License), Attribution: strings.TrimSpace(matter.Attribution)}, nil
+}
+
+// CheckLicense returns a warning when a rule with license may not be deployed
+// under the manifest's allowedLicenses policy, or "" when it may. Licenses are
+// compared case-insensitively, as SPDX identifiers are. Without a policy every
+// rule may be deployed.
+func (m *Manifest) CheckLicense(license string) string {
+	if len(m.AllowedLicenses) == 0 {
+		return ""
+	}
+	allowed := strings.Join(m.AllowedLicenses, ", ")
+	if license == "" {
+		return fmt.Sprintf("rule has no license; this project allows %s", allowed)
+	}
+	for _, a := range m.AllowedLicenses {
+		if strings.EqualFold(strings.TrimSpace(a), license) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("license %s is not allowed in this project; it allows %s", license, allowed)
+}
+
+// LicenseWarning checks license against the allowed-license policy of the
+// project at projectDir; see Manifest.CheckLicense. A project without a
+// manifest has no policy.
+func LicenseWarning(projectDir, license string) (string, error) {
+	manifest, err := LoadManifest(projectDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return manifest.CheckLicense(license), nil
+}
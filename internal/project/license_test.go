@@ -0,0 +1,87 @@
+package project
+
+import (
+	"path/filepath"
+	"testing"
+
+	"rulem/internal/logging"
+	"rulem/internal/mcp"
+)
+
+func TestReadRuleLicense(t *testing.T) {
+	dir := t.TempDir()
+	logger, _ := logging.NewTestLogger()
+	processor := mcp.NewRuleFileProcessor(logger, nil, 1024*1024)
+
+	tests := []struct {
+		name    string
+		content string
+		want    RuleLicense
+	}{
+		{"both", "---\ndescription: Style\nlicense: MIT\nattribution: Jane Doe\n---\n# Style\n", RuleLicense{License: "MIT", Attribution: "Jane Doe"}},
+		{"license only", "---\ndescription: Style\nlicense: ' Apache-2.0 '\n---\n", RuleLicense{License: "Apache-2.0"}},
+		{"no frontmatter", "# Style\n", RuleLicense{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "rule.md")
+			writeFile(t, path, tt.content)
+			got, err := ReadRuleLicense(path, processor)
+			if err != nil {
+				t.Fatalf("ReadRuleLicense: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ReadRuleLicense() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ReadRuleLicense(filepath.Join(dir, "missing.md"), processor); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestManifest_CheckLicense(t *testing.T) {
+	policy := &Manifest{AllowedLicenses: []string{"MIT", "Apache-2.0"}}
+
+	tests := []struct {
+		name     string
+		manifest *Manifest
+		license  string
+		want     string
+	}{
+		{"no policy", &Manifest{}, "", ""},
+		{"allowed", policy, "MIT", ""},
+		{"allowed ignoring case", policy, "apache-2.0", ""},
+		{"missing", policy, "", "rule has no license; this project allows MIT, Apache-2.0"},
+		{"not allowed", policy, "GPL-3.0", "license GPL-3.0 is not allowed in this project; it allows MIT, Apache-2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.manifest.CheckLicense(tt.license); got != tt.want {
+				t.Errorf("CheckLicense(%q) = %q, want %q", tt.license, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLicenseWarning(t *testing.T) {
+	projectDir := t.TempDir()
+	if warning, err := LicenseWarning(projectDir, ""); err != nil || warning != "" {
+		t.Fatalf("without a manifest: LicenseWarning() = %q, %v", warning, err)
+	}
+
+	manifest := &Manifest{AllowedLicenses: []string{"MIT"}}
+	if err := manifest.Save(projectDir); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	warning, err := LicenseWarning(projectDir, "BSD-3-Clause")
+	if err != nil {
+		t.Fatalf("LicenseWarning: %v", err)
+	}
+	if want := "license BSD-3-Clause is not allowed in this project; it allows MIT"; warning != want {
+		t.Errorf("LicenseWarning() = %q, want %q", warning, want)
+	}
+}
@@ -0,0 +1,277 @@
+// Package project tracks the rules deployed into a project and compares them
+// with their central versions.
+//
+// Every rule copied or linked into a project is recorded in the project
+// manifest, .rulem/deployed.yaml at the project root, with the repository and
+// path it came from and a hash of what was deployed:
+//
+//	rules:
+//	  - path: .github/instructions/go-style.instructions.md
+//	    repository: team-rules-3f9a0c12
+//	    source: go/style.md
+//	    mode: copy
+//	    commit: 9b1c3f0e...
+//	    sha256: 5e8f2a...
+//	    deployedAt: 1760000000
+//
+// A rule copied for an editor whose frontmatter differs from rulem's also
+// records how its frontmatter was rewritten (see editors.FrontmatterRewrite),
+// so the comparison with its central version applies the same rewrite:
+//
+//	rules:
+//	  - path: .cursor/rules/go-style.mdc
+//	    ...
+//	    rewrite:
+//	      rename: {applyTo: globs}
+//	      keep: [description, globs, alwaysApply]
+//
+// A project may also restrict the licenses of the rules deployed into it, so
+// deploying a rule without one of the listed `license` values warns:
+//
+//	allowedLicenses: [MIT, Apache-2.0]
+//
+// The manifest is meant to be committed with the project, so CI can check the
+// deployed rules against the central repository.
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"rulem/internal/editors"
+	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ManifestDir is the directory at the project root holding rulem's project files
+	ManifestDir = ".rulem"
+	// ManifestFileName is the project manifest inside ManifestDir
+	ManifestFileName = "deployed.yaml"
+)
+
+// Mode is how a rule was deployed
+type Mode string
+
+const (
+	ModeCopy Mode = "copy" // The project has its own copy of the rule
+	ModeLink Mode = "link" // The project has a symlink into the repository
+)
+
+// Entry records one deployed rule.
+//
+// Fields:
+//   - Path: Deployed file, relative to the project root (slash-separated)
+//   - Repository: ID of the repository the rule came from
+//   - Source: Rule file relative to the repository root (slash-separated)
+//   - Mode: Whether the rule was copied or linked
+//   - Commit: Commit of the repository at deploy time, when it is a git repository
+//   - SHA256: Hash of the deployed content
+//   - DeployedAt: Unix time of the deployment
+//   - Rewrite: How the frontmatter of a copied rule was rewritten, nil when it was not
+type Entry struct {
+	Path       string `yaml:"path"`
+	Repository string `yaml:"repository"`
+	Source     string `yaml:"source"`
+	Mode       Mode   `yaml:"mode"`
+	Commit     string `yaml:"commit,omitempty"`
+	SHA256     string `yaml:"sha256"`
+	DeployedAt int64  `yaml:"deployedAt"`
+
+	Rewrite *editors.FrontmatterRewrite `yaml:"rewrite,omitempty"`
+}
+
+// Manifest is the content of a project's .rulem/deployed.yaml
+type Manifest struct {
+	// AllowedLicenses is the project's license policy; empty allows any license
+	AllowedLicenses []string `yaml:"allowedLicenses,omitempty"`
+	Rules           []Entry  `yaml:"rules"`
+}
+
+// ManifestPath returns the path of the manifest of the project at projectDir
+func ManifestPath(projectDir string) string {
+	return filepath.Join(projectDir, ManifestDir, ManifestFileName)
+}
+
+// LoadManifest reads the manifest of the project at projectDir.
+//
+// Returns os.ErrNotExist (wrapped) when nothing was deployed to the project yet.
+func LoadManifest(projectDir string) (*Manifest, error) {
+	path := ManifestPath(projectDir)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no project manifest at %s: %w", path, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to read project manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid project manifest %s: %w", path, err)
+	}
+	for i, entry := range manifest.Rules {
+		if entry.Path == "" || entry.Repository == "" || entry.Source == "" {
+			return nil, fmt.Errorf("invalid project manifest %s: rule %d needs path, repository and source", path, i+1)
+		}
+	}
+	return &manifest, nil
+}
+
+// Save writes the manifest to the project at projectDir, replacing it atomically
+func (m *Manifest) Save(projectDir string) error {
+	path := ManifestPath(projectDir)
+	if err := fileops.EnsureDirectoryExists(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("cannot create %s: %w", ManifestDir, err)
+	}
+
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode project manifest: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write project manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write project manifest: %w", err)
+	}
+	return nil
+}
+
+// Find returns the entry for a deployed path
+func (m *Manifest) Find(path string) (Entry, bool) {
+	for _, entry := range m.Rules {
+		if entry.Path == path {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Record adds entry, replacing any entry for the same path. Entries are kept
+// sorted by path so the manifest diffs cleanly.
+func (m *Manifest) Record(entry Entry) {
+	replaced := false
+	for i := range m.Rules {
+		if m.Rules[i].Path == entry.Path {
+			m.Rules[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		m.Rules = append(m.Rules, entry)
+	}
+	sort.Slice(m.Rules, func(i, j int) bool { return m.Rules[i].Path < m.Rules[j].Path })
+}
+
+// Hash returns the hex SHA-256 of content, as stored in Entry.SHA256
+func Hash(content []byte) string {
+	return fileops.HashContent(content)
+}
+
+// RecordDeployment adds a rule that was just deployed to the manifest of the
+// project at projectDir, creating the manifest if needed.
+//
+// Parameters:
+//   - deployedPath: The copied file or created symlink (absolute or relative to projectDir)
+//   - source: The absolute path of the rule in the repository or its overlay
+//   - mode: How the rule was deployed
+//   - rewrite: How the frontmatter of a copy was rewritten, nil when it was not
+func RecordDeployment(projectDir, deployedPath string, source string, prep repository.PreparedRepository, mode Mode, rewrite *editors.FrontmatterRewrite) error {
+	absProject, err := filepath.Abs(projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+	if !filepath.IsAbs(deployedPath) {
+		deployedPath = filepath.Join(absProject, deployedPath)
+	}
+	path, err := relativeTo(absProject, deployedPath)
+	if err != nil {
+		return fmt.Errorf("deployed file is outside the project: %w", err)
+	}
+
+	sourceRel, inOverlay, err := sourcePath(prep, source)
+	if err != nil {
+		return err
+	}
+
+	// Symlinks are read through, so the hash is of the rule content
+	content, err := os.ReadFile(deployedPath)
+	if err != nil {
+		return fmt.Errorf("failed to read deployed file: %w", err)
+	}
+
+	entry := Entry{
+		Path:       path,
+		Repository: prep.ID(),
+		Source:     sourceRel,
+		Mode:       mode,
+		SHA256:     Hash(content),
+		DeployedAt: time.Now().Unix(),
+		Rewrite:    rewrite,
+	}
+	// Overlay files are not versioned, and local repositories need not be git repositories
+	if !inOverlay {
+		if commit, err := repository.HeadCommit(prep.GitRoot()); err == nil {
+			entry.Commit = commit
+		}
+	}
+
+	manifest, err := LoadManifest(projectDir)
+	if errors.Is(err, os.ErrNotExist) {
+		manifest = &Manifest{}
+	} else if err != nil {
+		return err
+	}
+	manifest.Record(entry)
+	return manifest.Save(projectDir)
+}
+
+// sourcePath returns source relative to the repository root, and whether it
+// lives in the repository's overlay
+func sourcePath(prep repository.PreparedRepository, source string) (string, bool, error) {
+	if prep.OverlayPath != "" {
+		if rel, err := relativeTo(prep.OverlayPath, source); err == nil {
+			return rel, true, nil
+		}
+	}
+	rel, err := relativeTo(prep.LocalPath, source)
+	if err != nil {
+		return "", false, fmt.Errorf("rule %s is not in repository %s", source, prep.Name())
+	}
+	return rel, false, nil
+}
+
+// relativeTo returns path relative to root, slash-separated, failing when
+// path is not inside root
+func relativeTo(root, path string) (string, error) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s is not inside %s", path, root)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// localPath converts a slash-separated manifest path to a path under root,
+// rejecting paths that escape it
+func localPath(root, path string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if !filepath.IsLocal(clean) || strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("invalid path %q: must be relative and stay inside %s", path, root)
+	}
+	return filepath.Join(root, clean), nil
+}
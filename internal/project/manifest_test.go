@@ -0,0 +1,87 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifest_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadManifest(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("LoadManifest without manifest = %v, want os.ErrNotExist", err)
+	}
+
+	manifest := &Manifest{}
+	manifest.Record(Entry{Path: "b.md", Repository: "rules-1", Source: "b.md", Mode: ModeCopy, SHA256: "1"})
+	manifest.Record(Entry{Path: "a.md", Repository: "rules-1", Source: "a.md", Mode: ModeLink, SHA256: "2"})
+	manifest.Record(Entry{Path: "b.md", Repository: "rules-1", Source: "go/b.md", Mode: ModeCopy, SHA256: "3"})
+	if err := manifest.Save(dir); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, manifest) {
+		t.Errorf("loaded %+v, want %+v", loaded, manifest)
+	}
+	if len(loaded.Rules) != 2 || loaded.Rules[0].Path != "a.md" {
+		t.Errorf("rules should be replaced by path and sorted: %+v", loaded.Rules)
+	}
+	if entry, ok := loaded.Find("b.md"); !ok || entry.Source != "go/b.md" {
+		t.Errorf("Find(b.md) = %+v, %v", entry, ok)
+	}
+}
+
+func TestLoadManifest_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "not yaml", content: "rules: [\n"},
+		{name: "missing source", content: "rules:\n  - path: a.md\n    repository: rules-1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, ManifestPath(dir), tt.content)
+			if _, err := LoadManifest(dir); err == nil || errors.Is(err, os.ErrNotExist) {
+				t.Errorf("LoadManifest = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+func TestRecordDeployment(t *testing.T) {
+	prep, repoDir := prepareRepository(t, map[string]string{"go/style.md": "# Style\n"})
+	projectDir := t.TempDir()
+	deployed := filepath.Join(projectDir, ".github", "instructions", "style.md")
+	writeFile(t, deployed, "# Style\n")
+
+	if err := RecordDeployment(projectDir, deployed, filepath.Join(repoDir, "go", "style.md"), prep, ModeCopy, nil); err != nil {
+		t.Fatalf("RecordDeployment: %v", err)
+	}
+
+	manifest, err := LoadManifest(projectDir)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	entry, ok := manifest.Find(".github/instructions/style.md")
+	if !ok {
+		t.Fatalf("deployment not recorded: %+v", manifest.Rules)
+	}
+	if entry.Repository != "rules-1" || entry.Source != "go/style.md" || entry.Mode != ModeCopy ||
+		entry.SHA256 != Hash([]byte("# Style\n")) || entry.DeployedAt == 0 || entry.Commit != "" {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	// A rule from outside the repository is rejected
+	if err := RecordDeployment(projectDir, deployed, filepath.Join(t.TempDir(), "other.md"), prep, ModeCopy, nil); err == nil {
+		t.Error("expected an error for a source outside the repository")
+	}
+}
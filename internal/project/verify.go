@@ -0,0 +1,155 @@
+package project
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"rulem/internal/checks"
+	"rulem/internal/frontmatter"
+	"rulem/internal/mcp"
+	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+)
+
+// FindingKind classifies a problem found by Verify
+type FindingKind string
+
+const (
+	FindingDrift  FindingKind = "drift"  // The deployed rule differs from its central version
+	FindingSchema FindingKind = "schema" // The rule would not be registered as an MCP tool
+	FindingLint   FindingKind = "lint"   // The rule has unknown frontmatter keys, invalid checks, likely prompt injections or hidden text
+	FindingError  FindingKind = "error"  // The rule could not be verified
+)
+
+// Finding is a problem with a deployed rule
+type Finding struct {
+	Path    string // Deployed file, relative to the project root
+	Line    int    // 1-based, 0 when the finding is about the whole file
+	Kind    FindingKind
+	Message string
+}
+
+// String formats the finding as "path:line: kind: message"
+func (f Finding) String() string {
+	location := f.Path
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", f.Path, f.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, f.Kind, f.Message)
+}
+
+// Annotation formats the finding as a GitHub Actions workflow command, which
+// shows it as an error on the file in the workflow run and pull request. A
+// finding without a path is annotated on the run only.
+func (f Finding) Annotation() string {
+	var properties []string
+	if f.Path != "" {
+		properties = append(properties, "file="+escapeProperty(f.Path))
+		if f.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", f.Line))
+		}
+	}
+	properties = append(properties, "title="+escapeProperty("rulem "+string(f.Kind)))
+	return fmt.Sprintf("::error %s::%s", strings.Join(properties, ","), escapeData(f.Message))
+}
+
+// escapeData escapes a workflow command message
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command property value
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// Verify checks every rule in the manifest of the project at projectDir: it
+// must match its central version (see Compare) and pass the validation the
+// MCP server applies to rule files, including the repository's rulem.yaml
+// schema and tags. Unknown frontmatter keys, invalid checks, likely prompt
+// injections and hidden text (see fileops.ScanPromptInjection and
+// fileops.ScanHiddenText) are reported as lint findings.
+//
+// Findings are returned in manifest order.
+func Verify(projectDir string, manifest *Manifest, prepared []repository.PreparedRepository, processor *mcp.RuleFileProcessor, revision string) []Finding {
+	var findings []Finding
+	for _, c := range Compare(projectDir, manifest, prepared, revision) {
+		findings = append(findings, driftFindings(c)...)
+		switch {
+		case c.Entry.Rewrite != nil && c.Rule != nil:
+			// The deployed frontmatter is the editor's, not rulem's, so the
+			// rule is validated as it is in the repository
+			findings = append(findings, ruleFindings(c.Entry, c.Rule, processor)...)
+		case c.Deployed != nil:
+			findings = append(findings, ruleFindings(c.Entry, c.Deployed, processor)...)
+		}
+	}
+	return findings
+}
+
+// driftFindings reports a comparison that failed or found drift
+func driftFindings(c Comparison) []Finding {
+	finding := Finding{Path: c.Entry.Path, Kind: FindingDrift}
+	switch c.Status {
+	case StatusUpToDate:
+		return nil
+	case StatusError:
+		finding.Kind = FindingError
+		finding.Message = c.Err.Error()
+	case StatusMissing:
+		finding.Message = fmt.Sprintf("deployed rule is missing; it comes from %s", c.CentralName)
+	case StatusSourceMissing:
+		finding.Message = fmt.Sprintf("%s no longer exists", c.CentralName)
+	case StatusChanged:
+		finding.Line = firstChangedLine(c.Deployed, c.Central)
+		if c.LocallyModified() {
+			finding.Message = fmt.Sprintf("modified in the project since it was deployed from %s; run rulem diff", c.CentralName)
+		} else {
+			finding.Message = fmt.Sprintf("outdated: %s has changed; run rulem diff", c.CentralName)
+		}
+	}
+	return []Finding{finding}
+}
+
+// ruleFindings validates the deployed content of a rule
+func ruleFindings(entry Entry, content []byte, processor *mcp.RuleFileProcessor) []Finding {
+	var findings []Finding
+	line := 1
+	if start, _, ok := processor.FrontmatterLines(content); ok {
+		line = start + 1
+	}
+
+	if err := processor.ValidateRuleContent(content, path.Base(entry.Path), entry.Repository); err != nil {
+		// Without valid frontmatter there is nothing more to lint
+		return append(findings, Finding{Path: entry.Path, Line: line, Kind: FindingSchema, Message: err.Error()})
+	}
+
+	if fields, err := processor.FrontmatterFields(content, path.Base(entry.Path)); err == nil {
+		for _, field := range fields {
+			if _, known := frontmatter.Keys[field]; !known {
+				findings = append(findings, Finding{Path: entry.Path, Line: line, Kind: FindingLint, Message: fmt.Sprintf("unknown frontmatter key '%s'", field)})
+			}
+		}
+	}
+	if _, err := checks.ParseChecks(path.Base(entry.Path), content, processor); err != nil {
+		findings = append(findings, Finding{Path: entry.Path, Line: line, Kind: FindingLint, Message: err.Error()})
+	}
+	for _, injection := range fileops.ScanPromptInjection(fileops.StripHiddenText(string(content))) {
+		findings = append(findings, Finding{
+			Path:    entry.Path,
+			Line:    injection.Line,
+			Kind:    FindingLint,
+			Message: fmt.Sprintf("possible prompt injection (%s): %s", injection.Kind, injection.Match),
+		})
+	}
+	for _, hidden := range fileops.ScanHiddenText(string(content)) {
+		findings = append(findings, Finding{
+			Path:    entry.Path,
+			Line:    hidden.Line,
+			Kind:    FindingLint,
+			Message: fmt.Sprintf("hidden text (%s): %s", hidden.Kind, hidden.Match),
+		})
+	}
+	return findings
+}
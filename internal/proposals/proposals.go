@@ -0,0 +1,274 @@
+// Package proposals stages rules that assistants propose over MCP until a
+// person reviews them, so an assistant can suggest additions to a rule
+// repository without changing what is served.
+//
+// A local repository opts in with accept_proposals. The propose_rule MCP tool
+// then writes each proposed rule, with its frontmatter, to the proposed/
+// directory (repository.ProposalsDir) at the root of the repository, or of its
+// overlay for shared storage. Scans of the repository leave that directory
+// out, so a proposal is not served. Proposals never overwrite anything: a name
+// already taken by a staged proposal or by a rule at the root of the
+// repository is refused.
+//
+// Proposals are reviewed on the repository status screen of the TUI.
+// Accepting one moves it to the root of the repository, where it is served
+// like any other rule, again without overwriting; rejecting one deletes it.
+package proposals
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"rulem/internal/filemanager"
+	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrExists is returned when a proposal would overwrite a file
+var ErrExists = errors.New("already exists")
+
+// Proposal is a rule staged for review
+type Proposal struct {
+	RepositoryID string
+	Name         string // File name, also the name of the rule once accepted
+	Path         string // Absolute path of the staged file
+	Proposed     time.Time
+}
+
+// Accepting reports whether prep takes proposals
+func Accepting(prep repository.PreparedRepository) bool {
+	return prep.Entry.AcceptProposals && prep.IsLocal() && prep.IsAvailable()
+}
+
+// Prepare returns the repositories of entries that accept proposals as they
+// are on disk, without syncing or validating them, for reviewing proposals
+func Prepare(entries []repository.RepositoryEntry) []repository.PreparedRepository {
+	var prepared []repository.PreparedRepository
+	for _, entry := range entries {
+		if !entry.AcceptProposals || !entry.IsLocal() {
+			continue
+		}
+		prep := repository.PreparedRepository{Entry: entry, LocalPath: fileops.ExpandPath(entry.Path)}
+		if overlay := entry.GetOverlay(); overlay != "" {
+			prep.OverlayPath = fileops.ExpandPath(overlay)
+		}
+		prepared = append(prepared, prep)
+	}
+	return prepared
+}
+
+// Render returns a rule file with fields as its YAML frontmatter and body after it
+func Render(fields map[string]any, body string) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("---\n")
+	if len(fields) > 0 {
+		block, err := yaml.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode frontmatter: %w", err)
+		}
+		b.Write(block)
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimLeft(body, "\r\n"))
+	if !strings.HasSuffix(body, "\n") {
+		b.WriteString("\n")
+	}
+	return []byte(b.String()), nil
+}
+
+// ValidateName checks that name is a plain rule file name, such as
+// "go-errors.md", without directories
+func ValidateName(name string) error {
+	clean, err := fileops.SanitizeFilename(name)
+	if err != nil {
+		return fmt.Errorf("invalid file name %q: %w", name, err)
+	}
+	if clean != name {
+		return fmt.Errorf("invalid file name %q: use a plain file name such as %q", name, clean)
+	}
+	if !filemanager.IsRuleFilePath(name) {
+		return fmt.Errorf("invalid file name %q: use a rule file extension such as .md", name)
+	}
+	return nil
+}
+
+// Stage writes content as the proposal name in prep and returns its path. It
+// fails with ErrExists when a staged proposal or a rule at the root of the
+// repository has the name.
+func Stage(prep repository.PreparedRepository, name string, content []byte) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	if err := ruleExists(prep, name); err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(writableRoot(prep), repository.ProposalsDir)
+	if err := fileops.EnsureDirectoryExists(dir); err != nil {
+		return "", fmt.Errorf("cannot create proposals directory: %w", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := writeNew(path, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	}); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("a proposal called %s %w", name, ErrExists)
+		}
+		return "", fmt.Errorf("failed to stage proposal: %w", err)
+	}
+	return path, nil
+}
+
+// List returns the proposals staged in prep, oldest first
+func List(prep repository.PreparedRepository) ([]Proposal, error) {
+	dir := filepath.Join(writableRoot(prep), repository.ProposalsDir)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proposals of %s: %w", prep.Name(), err)
+	}
+
+	var proposals []Proposal
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !filemanager.IsRuleFilePath(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed since it was listed
+		}
+		proposals = append(proposals, Proposal{
+			RepositoryID: prep.ID(),
+			Name:         entry.Name(),
+			Path:         filepath.Join(dir, entry.Name()),
+			Proposed:     info.ModTime(),
+		})
+	}
+	sortProposals(proposals)
+	return proposals, nil
+}
+
+// ListAll returns the proposals staged in every repository of prepared that
+// accepts them, oldest first. Repositories whose proposals cannot be read are
+// reported in the error after the proposals of the others.
+func ListAll(prepared []repository.PreparedRepository) ([]Proposal, error) {
+	var proposals []Proposal
+	var errs []error
+	for _, prep := range prepared {
+		if !prep.Entry.AcceptProposals {
+			continue
+		}
+		staged, err := List(prep)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		proposals = append(proposals, staged...)
+	}
+	sortProposals(proposals)
+	return proposals, errors.Join(errs...)
+}
+
+// Accept moves proposal to the root of prep, where it is served as a rule,
+// and returns its new path. It fails with ErrExists when a rule there has its
+// name, leaving the proposal staged.
+func Accept(prep repository.PreparedRepository, proposal Proposal) (string, error) {
+	if err := ruleExists(prep, proposal.Name); err != nil {
+		return "", err
+	}
+	src, err := os.Open(proposal.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read proposal: %w", err)
+	}
+	defer src.Close()
+
+	path := filepath.Join(writableRoot(prep), proposal.Name)
+	if err := writeNew(path, func(w io.Writer) error {
+		_, err := io.Copy(w, src)
+		return err
+	}); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("a rule called %s %w", proposal.Name, ErrExists)
+		}
+		return "", fmt.Errorf("failed to accept proposal: %w", err)
+	}
+	if err := os.Remove(proposal.Path); err != nil {
+		return path, fmt.Errorf("accepted %s but could not remove the proposal: %w", proposal.Name, err)
+	}
+	return path, nil
+}
+
+// Reject deletes proposal
+func Reject(proposal Proposal) error {
+	if err := os.Remove(proposal.Path); err != nil {
+		return fmt.Errorf("failed to reject proposal: %w", err)
+	}
+	return nil
+}
+
+// writableRoot returns the directory of prep that proposals and accepted
+// rules are written to
+func writableRoot(prep repository.PreparedRepository) string {
+	if prep.OverlayPath != "" {
+		return prep.OverlayPath
+	}
+	return prep.LocalPath
+}
+
+// ruleExists returns ErrExists when prep has a rule called name at its root,
+// in the shared directory or the overlay
+func ruleExists(prep repository.PreparedRepository, name string) error {
+	for _, root := range []string{prep.LocalPath, prep.OverlayPath} {
+		if root == "" {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(root, name)); err == nil {
+			return fmt.Errorf("a rule called %s %w in %s", name, ErrExists, prep.Name())
+		}
+	}
+	return nil
+}
+
+// writeNew creates the file at path with the content write gives it, failing
+// with os.ErrExist rather than replacing an existing file. A failed write
+// removes the file.
+func writeNew(path string, write func(io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// sortProposals orders proposals oldest first, then by repository and name
+func sortProposals(proposals []Proposal) {
+	sort.Slice(proposals, func(i, j int) bool {
+		a, b := proposals[i], proposals[j]
+		if !a.Proposed.Equal(b.Proposed) {
+			return a.Proposed.Before(b.Proposed)
+		}
+		if a.RepositoryID != b.RepositoryID {
+			return a.RepositoryID < b.RepositoryID
+		}
+		return a.Name < b.Name
+	})
+}
@@ -0,0 +1,131 @@
+package proposals
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rulem/internal/repository"
+)
+
+// localRepository returns a repository accepting proposals in a temporary directory
+func localRepository(t *testing.T) repository.PreparedRepository {
+	t.Helper()
+	dir := t.TempDir()
+	return repository.PreparedRepository{
+		Entry:     repository.RepositoryEntry{ID: "rules-1", Name: "Rules", Type: repository.RepositoryTypeLocal, Path: dir, AcceptProposals: true},
+		LocalPath: dir,
+	}
+}
+
+func TestRender(t *testing.T) {
+	content, err := Render(map[string]any{"description": "Go errors", "tags": []string{"go"}}, "\n# Errors\nWrap them.")
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	want := "---\ndescription: Go errors\ntags:\n    - go\n---\n\n# Errors\nWrap them.\n"
+	if string(content) != want {
+		t.Errorf("Render() = %q, want %q", content, want)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"go-errors.md", "style.mdc"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", "../escape.md", "go/errors.md", "notes.txt"} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) should fail", name)
+		}
+	}
+}
+
+func TestStageAndAccept(t *testing.T) {
+	prep := localRepository(t)
+
+	path, err := Stage(prep, "errors.md", []byte("# Errors\n"))
+	if err != nil {
+		t.Fatalf("Stage: %v", err)
+	}
+	if want := filepath.Join(prep.LocalPath, repository.ProposalsDir, "errors.md"); path != want {
+		t.Errorf("Stage() = %s, want %s", path, want)
+	}
+	if _, err := Stage(prep, "errors.md", []byte("# Other\n")); !errors.Is(err, ErrExists) {
+		t.Errorf("staging over a proposal = %v, want ErrExists", err)
+	}
+
+	proposals, err := ListAll([]repository.PreparedRepository{prep})
+	if err != nil || len(proposals) != 1 || proposals[0].Name != "errors.md" || proposals[0].RepositoryID != "rules-1" {
+		t.Fatalf("ListAll() = %+v, %v; want the staged proposal", proposals, err)
+	}
+
+	accepted, err := Accept(prep, proposals[0])
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if data, err := os.ReadFile(accepted); err != nil || string(data) != "# Errors\n" {
+		t.Errorf("accepted rule = %q, %v", data, err)
+	}
+	if _, err := os.Stat(proposals[0].Path); !os.IsNotExist(err) {
+		t.Errorf("expected the proposal to be removed once accepted: %v", err)
+	}
+
+	// Existing rules are never overwritten, by staging or accepting
+	if _, err := Stage(prep, "errors.md", []byte("# Again\n")); !errors.Is(err, ErrExists) {
+		t.Errorf("staging over a rule = %v, want ErrExists", err)
+	}
+	if err := os.WriteFile(filepath.Join(prep.LocalPath, "style.md"), []byte("# Mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	proposal := Proposal{RepositoryID: "rules-1", Name: "style.md", Path: filepath.Join(prep.LocalPath, repository.ProposalsDir, "style.md")}
+	if err := os.WriteFile(proposal.Path, []byte("# Theirs\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Accept(prep, proposal); !errors.Is(err, ErrExists) {
+		t.Errorf("accepting over a rule = %v, want ErrExists", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(prep.LocalPath, "style.md")); string(data) != "# Mine\n" {
+		t.Errorf("existing rule was changed to %q", data)
+	}
+
+	if err := Reject(proposal); err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if proposals, _ := List(prep); len(proposals) != 0 {
+		t.Errorf("List() after rejecting = %+v, want none", proposals)
+	}
+}
+
+func TestStage_Overlay(t *testing.T) {
+	prep := localRepository(t)
+	prep.OverlayPath = t.TempDir()
+	if err := os.WriteFile(filepath.Join(prep.LocalPath, "shared.md"), []byte("# Shared\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Stage(prep, "shared.md", []byte("# Proposed\n")); !errors.Is(err, ErrExists) {
+		t.Errorf("staging over a shared rule = %v, want ErrExists", err)
+	}
+	path, err := Stage(prep, "new.md", []byte("# New\n"))
+	if err != nil {
+		t.Fatalf("Stage: %v", err)
+	}
+	if want := filepath.Join(prep.OverlayPath, repository.ProposalsDir, "new.md"); path != want {
+		t.Errorf("Stage() = %s, want the overlay's %s", path, want)
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	url := "https://github.com/acme/rules"
+	prepared := Prepare([]repository.RepositoryEntry{
+		{ID: "rules-1", Type: repository.RepositoryTypeLocal, Path: "/rules", AcceptProposals: true},
+		{ID: "other-2", Type: repository.RepositoryTypeLocal, Path: "/other"},
+		{ID: "remote-3", Type: repository.RepositoryTypeGitHub, RemoteURL: &url, Path: "/clone", AcceptProposals: true},
+	})
+	if len(prepared) != 1 || prepared[0].ID() != "rules-1" || prepared[0].LocalPath != "/rules" {
+		t.Errorf("Prepare() = %+v, want only the local repository accepting proposals", prepared)
+	}
+}
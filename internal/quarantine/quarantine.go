@@ -0,0 +1,313 @@
+// Package quarantine holds back rules that newly appear in shared repositories
+// until the user approves them, so a malicious addition to a shared repository
+// cannot influence assistants as soon as it is synced.
+//
+// Quarantine is enabled per GitHub repository with quarantine_new_rules. The
+// first time such a repository is seen, its current rules are approved as a
+// baseline. Rules that appear after that are quarantined: they stay visible in
+// rulem, but are not served over MCP until approved on the repository status
+// screen. A removed rule loses its approval, so re-adding it quarantines it
+// again.
+//
+// The state lives in the user's data directory, in
+// rulem-quarantine/quarantine.yaml, beside the notes and outside every
+// repository:
+//
+//	repositories:
+//	  team-rules-3f9a0c12:
+//	    approved: [go/style.md, testing.md]
+//	    pending:
+//	      go/new-rule.md: 1760000000
+package quarantine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+
+	"github.com/adrg/xdg"
+	"gopkg.in/yaml.v3"
+)
+
+// RepositoryState is what the quarantine knows about one repository's rules,
+// by their slash-separated path in the repository
+type RepositoryState struct {
+	Approved []string         `yaml:"approved"`
+	Pending  map[string]int64 `yaml:"pending,omitempty"` // Unix time each rule was first seen
+}
+
+// Store holds the quarantine state of every repository
+type Store struct {
+	path         string
+	Repositories map[string]*RepositoryState `yaml:"repositories"`
+}
+
+// Rule is a quarantined rule awaiting approval
+type Rule struct {
+	RepositoryID string
+	Path         string // Slash-separated path in the repository
+	Since        time.Time
+}
+
+// Path returns the quarantine file in the user's data directory. It can be
+// overridden with the RULEM_QUARANTINE_PATH environment variable for testing.
+func Path() string {
+	if testPath := os.Getenv("RULEM_QUARANTINE_PATH"); testPath != "" {
+		return testPath
+	}
+	return filepath.Join(xdg.DataHome, "rulem-quarantine", "quarantine.yaml")
+}
+
+// Load reads the quarantine file at path. A missing file is an empty store.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path, Repositories: make(map[string]*RepositoryState)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quarantine: %w", err)
+	}
+	if err := yaml.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("invalid quarantine file %s: %w", path, err)
+	}
+	if store.Repositories == nil {
+		store.Repositories = make(map[string]*RepositoryState)
+	}
+	return store, nil
+}
+
+// Save writes the store back to the file it was loaded from, replacing it atomically
+func (s *Store) Save() error {
+	if err := fileops.EnsureDirectoryExists(filepath.Dir(s.path)); err != nil {
+		return fmt.Errorf("cannot create quarantine directory: %w", err)
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode quarantine: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write quarantine: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write quarantine: %w", err)
+	}
+	return nil
+}
+
+// RenameRepository moves the state of the repository with oldID to newID,
+// e.g. when its ID is migrated. State already kept under newID wins. Returns
+// whether the store changed and needs saving.
+func (s *Store) RenameRepository(oldID, newID string) bool {
+	state, ok := s.Repositories[oldID]
+	if !ok {
+		return false
+	}
+	if _, exists := s.Repositories[newID]; !exists {
+		s.Repositories[newID] = state
+	}
+	delete(s.Repositories, oldID)
+	return true
+}
+
+// Review records the rules currently in the repository with repositoryID and
+// returns the ones that are quarantined. The first review approves every rule
+// as the baseline; later ones quarantine rules that are not approved yet.
+// changed reports whether the store needs saving.
+func (s *Store) Review(repositoryID string, paths []string, now time.Time) (quarantined []string, changed bool) {
+	state, ok := s.Repositories[repositoryID]
+	if !ok {
+		approved := append([]string(nil), paths...)
+		sort.Strings(approved)
+		s.Repositories[repositoryID] = &RepositoryState{Approved: approved}
+		return nil, true
+	}
+	if state.Pending == nil {
+		state.Pending = make(map[string]int64)
+	}
+
+	present := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		present[path] = true
+	}
+
+	// Forget removed rules, so a rule that is re-added is quarantined again
+	var approved []string
+	isApproved := make(map[string]bool, len(state.Approved))
+	for _, path := range state.Approved {
+		if present[path] {
+			approved = append(approved, path)
+			isApproved[path] = true
+		} else {
+			changed = true
+		}
+	}
+	state.Approved = approved
+	for path := range state.Pending {
+		if !present[path] {
+			delete(state.Pending, path)
+			changed = true
+		}
+	}
+
+	for _, path := range paths {
+		if isApproved[path] {
+			continue
+		}
+		if _, pending := state.Pending[path]; !pending {
+			state.Pending[path] = now.Unix()
+			changed = true
+		}
+		quarantined = append(quarantined, path)
+	}
+	sort.Strings(quarantined)
+	return quarantined, changed
+}
+
+// Pending returns every quarantined rule, oldest first
+func (s *Store) Pending() []Rule {
+	var rules []Rule
+	for id, state := range s.Repositories {
+		for path, since := range state.Pending {
+			rules = append(rules, Rule{RepositoryID: id, Path: path, Since: time.Unix(since, 0)})
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if !rules[i].Since.Equal(rules[j].Since) {
+			return rules[i].Since.Before(rules[j].Since)
+		}
+		if rules[i].RepositoryID != rules[j].RepositoryID {
+			return rules[i].RepositoryID < rules[j].RepositoryID
+		}
+		return rules[i].Path < rules[j].Path
+	})
+	return rules
+}
+
+// Approve releases a quarantined rule, so it is served from now on
+func (s *Store) Approve(repositoryID, path string) error {
+	state, ok := s.Repositories[repositoryID]
+	if !ok {
+		return fmt.Errorf("rule %s is not quarantined", path)
+	}
+	if _, pending := state.Pending[path]; !pending {
+		return fmt.Errorf("rule %s is not quarantined", path)
+	}
+	delete(state.Pending, path)
+	state.Approved = append(state.Approved, path)
+	sort.Strings(state.Approved)
+	return nil
+}
+
+// Filter drops the quarantined rules of repositories with quarantine enabled
+// from files, recording newly seen rules in the quarantine file. Only the
+// repositories in prepared are reviewed, so a repository served at a revision
+// is left out and reviewed with FilterRevision instead.
+func Filter(files []filemanager.FileItem, prepared []repository.PreparedRepository, logger *logging.AppLogger) ([]filemanager.FileItem, error) {
+	roots := make(map[string]string)
+	for _, prep := range repository.AvailableRepositories(prepared) {
+		if prep.Entry.QuarantineNewRules {
+			roots[prep.ID()] = prep.LocalPath
+		}
+	}
+	if len(roots) == 0 {
+		return files, nil
+	}
+
+	// Every repository is reviewed, so one left without rules loses its approvals
+	relPaths := make(map[string][]string, len(roots))
+	for id := range roots {
+		relPaths[id] = nil
+	}
+	for _, file := range files {
+		root, ok := roots[file.RepositoryID]
+		if !ok {
+			continue
+		}
+		if rel, err := filepath.Rel(root, file.Path); err == nil {
+			relPaths[file.RepositoryID] = append(relPaths[file.RepositoryID], filepath.ToSlash(rel))
+		}
+	}
+	quarantined, err := review(relPaths, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var served []filemanager.FileItem
+	for _, file := range files {
+		root, ok := roots[file.RepositoryID]
+		if ok {
+			if rel, err := filepath.Rel(root, file.Path); err == nil && quarantined[file.RepositoryID+":"+filepath.ToSlash(rel)] {
+				continue
+			}
+		}
+		served = append(served, file)
+	}
+	return served, nil
+}
+
+// FilterRevision drops the quarantined rules from files, the rules of prep at
+// the revision it is served at, with paths relative to the directory it
+// exposes. Like Filter, it records newly seen rules in the quarantine file.
+func FilterRevision(prep repository.PreparedRepository, files []repository.RevisionFile, logger *logging.AppLogger) ([]repository.RevisionFile, error) {
+	if !prep.Entry.QuarantineNewRules {
+		return files, nil
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	quarantined, err := review(map[string][]string{prep.ID(): paths}, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var served []repository.RevisionFile
+	for _, file := range files {
+		if !quarantined[prep.ID()+":"+file.Path] {
+			served = append(served, file)
+		}
+	}
+	return served, nil
+}
+
+// review records the rules of each repository, by repository ID, in the
+// quarantine file and returns the quarantined ones as "<id>:<path>"
+func review(paths map[string][]string, logger *logging.AppLogger) (map[string]bool, error) {
+	store, err := Load(Path())
+	if err != nil {
+		return nil, err
+	}
+
+	quarantined := make(map[string]bool)
+	dirty := false
+	now := time.Now()
+	for id, rules := range paths {
+		held, changed := store.Review(id, rules, now)
+		dirty = dirty || changed
+		for _, path := range held {
+			quarantined[id+":"+path] = true
+		}
+		if len(held) > 0 && logger != nil {
+			logger.Warn("Rules held in quarantine until approved", "repository_id", id, "rules", held)
+		}
+	}
+	if dirty {
+		if err := store.Save(); err != nil {
+			return nil, err
+		}
+	}
+	return quarantined, nil
+}
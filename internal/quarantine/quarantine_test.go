@@ -0,0 +1,140 @@
+package quarantine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+)
+
+func TestStore_Review(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), "quarantine.yaml"))
+	if err != nil {
+		t.Fatalf("Load of a missing file: %v", err)
+	}
+	now := time.Unix(1760000000, 0)
+
+	// The first review approves the current rules as the baseline
+	if got, changed := store.Review("rules-1", []string{"style.md", "go/testing.md"}, now); got != nil || !changed {
+		t.Fatalf("baseline review = %v, %v; want nothing quarantined", got, changed)
+	}
+	if got, changed := store.Review("rules-1", []string{"style.md", "go/testing.md"}, now); got != nil || changed {
+		t.Errorf("unchanged review = %v, %v; want nothing quarantined or changed", got, changed)
+	}
+
+	got, changed := store.Review("rules-1", []string{"style.md", "go/testing.md", "new.md"}, now)
+	if !reflect.DeepEqual(got, []string{"new.md"}) || !changed {
+		t.Errorf("review with a new rule = %v, %v; want new.md quarantined", got, changed)
+	}
+	// A rule stays quarantined, since it was first seen
+	got, _ = store.Review("rules-1", []string{"style.md", "go/testing.md", "new.md"}, now.Add(time.Hour))
+	if !reflect.DeepEqual(got, []string{"new.md"}) || store.Repositories["rules-1"].Pending["new.md"] != now.Unix() {
+		t.Errorf("second review = %v, pending %v", got, store.Repositories["rules-1"].Pending)
+	}
+
+	// A removed rule loses its approval
+	store.Review("rules-1", []string{"go/testing.md", "new.md"}, now)
+	got, _ = store.Review("rules-1", []string{"style.md", "go/testing.md", "new.md"}, now)
+	if !reflect.DeepEqual(got, []string{"new.md", "style.md"}) {
+		t.Errorf("re-added rule: got %v, want it quarantined again", got)
+	}
+}
+
+func TestStore_ApproveAndPending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quarantine", "quarantine.yaml")
+	store, _ := Load(path)
+	store.Review("rules-1", nil, time.Unix(100, 0))
+	store.Review("rules-1", []string{"b.md"}, time.Unix(300, 0))
+	store.Review("rules-2", nil, time.Unix(100, 0))
+	store.Review("rules-2", []string{"a.md"}, time.Unix(200, 0))
+
+	pending := store.Pending()
+	if len(pending) != 2 || pending[0].Path != "a.md" || pending[1].Path != "b.md" {
+		t.Fatalf("Pending() = %+v, want oldest first", pending)
+	}
+
+	if err := store.Approve("rules-2", "a.md"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if err := store.Approve("rules-2", "a.md"); err == nil {
+		t.Error("approving a rule twice should fail")
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if pending := loaded.Pending(); len(pending) != 1 || pending[0].RepositoryID != "rules-1" {
+		t.Errorf("Pending() after reload = %+v", pending)
+	}
+	if got, _ := loaded.Review("rules-2", []string{"a.md"}, time.Now()); got != nil {
+		t.Errorf("approved rule is still quarantined: %v", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	t.Setenv("RULEM_QUARANTINE_PATH", filepath.Join(t.TempDir(), "quarantine.yaml"))
+	logger, _ := logging.NewTestLogger()
+
+	guarded, open := t.TempDir(), t.TempDir()
+	prepared := []repository.PreparedRepository{
+		{Entry: repository.RepositoryEntry{ID: "guarded-1", QuarantineNewRules: true}, LocalPath: guarded},
+		{Entry: repository.RepositoryEntry{ID: "open-1"}, LocalPath: open},
+	}
+	item := func(repoID, root, name string) filemanager.FileItem {
+		return filemanager.FileItem{Name: name, Path: filepath.Join(root, name), RepositoryID: repoID}
+	}
+
+	baseline := []filemanager.FileItem{item("guarded-1", guarded, "style.md"), item("open-1", open, "style.md")}
+	served, err := Filter(baseline, prepared, logger)
+	if err != nil || len(served) != 2 {
+		t.Fatalf("baseline Filter() = %v, %v; want every rule served", served, err)
+	}
+
+	synced := append(baseline, item("guarded-1", guarded, "new.md"), item("open-1", open, "new.md"))
+	served, err = Filter(synced, prepared, logger)
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	for _, file := range served {
+		if file.RepositoryID == "guarded-1" && file.Name == "new.md" {
+			t.Error("new rule of a quarantined repository was served")
+		}
+	}
+	if len(served) != 3 {
+		t.Errorf("served %d rules, want 3", len(served))
+	}
+
+	if _, err := os.Stat(Path()); err != nil {
+		t.Errorf("quarantine file not written: %v", err)
+	}
+}
+
+func TestFilterRevision(t *testing.T) {
+	t.Setenv("RULEM_QUARANTINE_PATH", filepath.Join(t.TempDir(), "quarantine.yaml"))
+	logger, _ := logging.NewTestLogger()
+	prep := repository.PreparedRepository{Entry: repository.RepositoryEntry{ID: "guarded-1", QuarantineNewRules: true}}
+
+	baseline := []repository.RevisionFile{{Path: "style.md"}}
+	if served, err := FilterRevision(prep, baseline, logger); err != nil || len(served) != 1 {
+		t.Fatalf("baseline FilterRevision() = %v, %v; want every rule served", served, err)
+	}
+
+	served, err := FilterRevision(prep, append(baseline, repository.RevisionFile{Path: "rules/new.md"}), logger)
+	if err != nil || len(served) != 1 || served[0].Path != "style.md" {
+		t.Errorf("FilterRevision() = %v, %v; want the new rule held back", served, err)
+	}
+
+	prep.Entry.QuarantineNewRules = false
+	if served, err := FilterRevision(prep, append(baseline, repository.RevisionFile{Path: "rules/new.md"}), logger); err != nil || len(served) != 2 {
+		t.Errorf("FilterRevision() = %v, %v; want every rule without quarantine", served, err)
+	}
+}
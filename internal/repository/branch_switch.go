@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/client"
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+// BranchSwitchStep identifies a stage of SwitchBranch, reported through its
+// progress callback so callers can show where a switch is.
+type BranchSwitchStep int
+
+const (
+	// BranchSwitchFetching fetches the target branch from origin
+	BranchSwitchFetching BranchSwitchStep = iota
+	// BranchSwitchVerifying checks the branch exists on origin after the fetch
+	BranchSwitchVerifying
+	// BranchSwitchCheckingOut checks out the branch and syncs it to origin
+	BranchSwitchCheckingOut
+	// BranchSwitchRollingBack restores the previous branch after a failed checkout
+	BranchSwitchRollingBack
+	// BranchSwitchDone indicates the switch completed successfully
+	BranchSwitchDone
+)
+
+// String returns a human-readable description of the step
+func (s BranchSwitchStep) String() string {
+	switch s {
+	case BranchSwitchFetching:
+		return "Fetching branch"
+	case BranchSwitchVerifying:
+		return "Verifying branch"
+	case BranchSwitchCheckingOut:
+		return "Checking out"
+	case BranchSwitchRollingBack:
+		return "Rolling back"
+	case BranchSwitchDone:
+		return "Done"
+	default:
+		return "Unknown"
+	}
+}
+
+// BranchSwitchError describes why SwitchBranch failed and what state the
+// repository was left in.
+type BranchSwitchError struct {
+	// Step is the stage that failed
+	Step BranchSwitchStep
+	// Err is the underlying failure
+	Err error
+	// Conflicts lists uncommitted files that would be overwritten by the checkout
+	Conflicts []string
+	// RolledBack is true when the previous branch was restored after a failed checkout
+	RolledBack bool
+	// RollbackErr is set when restoring the previous branch also failed
+	RollbackErr error
+}
+
+// Error implements the error interface
+func (e *BranchSwitchError) Error() string {
+	if len(e.Conflicts) > 0 {
+		return fmt.Sprintf("cannot switch branch: %d uncommitted file(s) would be overwritten: %s",
+			len(e.Conflicts), strings.Join(e.Conflicts, ", "))
+	}
+	msg := fmt.Sprintf("%s failed: %v", strings.ToLower(e.Step.String()), e.Err)
+	switch {
+	case e.RollbackErr != nil:
+		msg += fmt.Sprintf(" (rollback to the previous branch also failed: %v)", e.RollbackErr)
+	case e.RolledBack:
+		msg += " (restored the previous branch)"
+	}
+	return msg
+}
+
+// Unwrap returns the underlying failure
+func (e *BranchSwitchError) Unwrap() error {
+	return e.Err
+}
+
+// switchCheckout checks out branch and syncs it to origin during SwitchBranch.
+// It is a variable so tests can force a checkout failure to exercise rollback.
+var switchCheckout = func(gs GitSource, repo *git.Repository, worktree *git.Worktree, branch string, logger *logging.AppLogger) error {
+	if err := gs.checkoutBranch(repo, worktree, branch, logger); err != nil {
+		return err
+	}
+	return gs.syncWorktreeToRemote(repo, worktree, logger)
+}
+
+// SwitchBranch moves the clone at gs.Path to branch, or to the remote's default
+// branch when branch is empty. Unlike FetchUpdates, which keeps a repository on
+// its configured branch and tolerates checkout failures, SwitchBranch fails
+// loudly so callers only save the new branch once the working tree reflects it:
+//
+//  1. Refuse to start if uncommitted changes would be overwritten (reported as Conflicts)
+//  2. Fetch the branch from origin (shallow, like the initial clone)
+//  3. Verify the branch now exists as origin/<branch>
+//  4. Check it out and hard-reset it to origin/<branch>
+//
+// If step 4 fails, the previously checked-out branch and commit are restored.
+// A single-branch clone is set to fetch the new branch from then on, which is
+// also how a clone moves off a branch the remote deleted (see BranchGoneError).
+// progress, when non-nil, is called as each step starts. It returns the branch
+// that was checked out, which is the resolved default branch when branch is empty.
+func (gs GitSource) SwitchBranch(ctx context.Context, branch string, progress func(BranchSwitchStep), logger *logging.AppLogger) (string, error) {
+	report := func(step BranchSwitchStep) {
+		if progress != nil {
+			progress(step)
+		}
+	}
+
+	repo, worktree, err := openWorktree(gs.Path)
+	if err != nil {
+		return "", &BranchSwitchError{Step: BranchSwitchFetching, Err: err}
+	}
+
+	changes, err := ListChangedFiles(gs.Path)
+	if err != nil {
+		return "", &BranchSwitchError{Step: BranchSwitchCheckingOut, Err: err}
+	}
+	if len(changes) > 0 {
+		conflicts := make([]string, len(changes))
+		for i, change := range changes {
+			conflicts[i] = change.Path
+		}
+		return "", &BranchSwitchError{Step: BranchSwitchCheckingOut, Err: errors.New("uncommitted changes"), Conflicts: conflicts}
+	}
+
+	previous, err := repo.Head()
+	if err != nil {
+		return "", &BranchSwitchError{Step: BranchSwitchFetching, Err: fmt.Errorf("failed to resolve current branch: %w", err)}
+	}
+
+	report(BranchSwitchFetching)
+	branch, err = gs.fetchBranchWithAuth(ctx, repo, strings.TrimSpace(branch), logger)
+	if err != nil {
+		return "", &BranchSwitchError{Step: BranchSwitchFetching, Err: err}
+	}
+
+	report(BranchSwitchVerifying)
+	if err := ValidateRemoteBranchExists(ctx, gs.Path, branch, logger); err != nil {
+		return "", &BranchSwitchError{Step: BranchSwitchVerifying, Err: err}
+	}
+
+	report(BranchSwitchCheckingOut)
+	if err := switchCheckout(gs, repo, worktree, branch, logger); err != nil {
+		report(BranchSwitchRollingBack)
+		switchErr := &BranchSwitchError{Step: BranchSwitchCheckingOut, Err: err}
+		if rollbackErr := restoreHead(repo, worktree, previous); rollbackErr != nil {
+			switchErr.RollbackErr = rollbackErr
+		} else {
+			switchErr.RolledBack = true
+		}
+		if logger != nil {
+			logger.Error("Branch switch failed", "branch", branch, "error", err, "rolled_back", switchErr.RolledBack)
+		}
+		return "", switchErr
+	}
+
+	// Single-branch clones keep fetching the branch they were cloned with
+	if err := trackBranch(repo, branch); err != nil && logger != nil {
+		logger.Warn("Failed to track the new branch, later syncs may not update it", "branch", branch, "error", err)
+	}
+
+	// A switch from an inspected commit ends the inspection
+	if err := clearInspection(gs.Path); err != nil && logger != nil {
+		logger.Warn("Failed to clear inspection marker", "error", err)
+	}
+
+	report(BranchSwitchDone)
+	if logger != nil {
+		logger.Info("Switched branch", "path", gs.Path, "from", previous.Name().Short(), "to", branch)
+	}
+	return branch, nil
+}
+
+// fetchBranchWithAuth fetches branch from origin, retrying with the stored PAT
+// on authentication errors like performFetchWithAuth. An empty branch is
+// resolved to the remote's default branch, which is returned.
+func (gs GitSource) fetchBranchWithAuth(ctx context.Context, repo *git.Repository, branch string, logger *logging.AppLogger) (string, error) {
+	resolved, err := gs.fetchBranch(ctx, repo, branch, nil)
+	if err == nil || !gs.isAuthenticationError(err) {
+		return resolved, err
+	}
+
+	if logger != nil {
+		logger.Debug("Public branch fetch failed, trying with authentication")
+	}
+	auth, authErr := gs.getAuthentication(logger)
+	if authErr != nil {
+		return "", fmt.Errorf("%s authentication failed: %w", gs.provider().DisplayName(), authErr)
+	}
+	if auth == nil {
+		return "", gs.authRequiredError()
+	}
+	return gs.fetchBranch(ctx, repo, branch, auth)
+}
+
+// fetchBranch fetches a single branch into refs/remotes/origin/<branch>
+func (gs GitSource) fetchBranch(ctx context.Context, repo *git.Repository, branch string, auth *http.BasicAuth) (string, error) {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return "", fmt.Errorf("failed to get origin remote: %w", err)
+	}
+
+	var clientOpts []client.Option
+	if auth != nil {
+		clientOpts = []client.Option{client.WithHTTPAuth(auth)}
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	if branch == "" {
+		refs, err := remote.ListContext(opCtx, &git.ListOptions{ClientOptions: clientOpts})
+		if err != nil {
+			return "", gs.translateFetchError(err)
+		}
+		if branch = defaultBranchFromRefs(refs); branch == "" {
+			return "", fmt.Errorf("could not determine the remote's default branch - enter a branch name instead")
+		}
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("+%s:%s",
+		plumbing.NewBranchReferenceName(branch),
+		plumbing.NewRemoteReferenceName("origin", branch)))
+	err = remote.FetchContext(opCtx, &git.FetchOptions{
+		RefSpecs:      []config.RefSpec{refSpec},
+		Depth:         1,
+		Force:         true,
+		ClientOptions: clientOpts,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		if isMissingRemoteRef(err) {
+			return "", fmt.Errorf("branch '%s' does not exist on remote 'origin'", branch)
+		}
+		return "", gs.translateFetchError(err)
+	}
+	return branch, nil
+}
+
+// defaultBranchFromRefs returns the branch the remote HEAD points to, or an
+// empty string when the remote does not advertise it
+func defaultBranchFromRefs(refs []*plumbing.Reference) string {
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch() {
+			return ref.Target().Short()
+		}
+	}
+	return ""
+}
+
+// restoreHead checks out the branch (or detached commit) HEAD pointed to before
+// a failed switch. The working tree was verified clean before the switch
+// started, so a forced checkout cannot lose local work.
+func restoreHead(repo *git.Repository, worktree *git.Worktree, previous *plumbing.Reference) error {
+	// go-git's hard reset only deletes files that differ between the HEAD tree
+	// and the target, and Checkout moves HEAD before resetting. Reset while HEAD
+	// still points at the switched-to commit so its extra files are removed,
+	// then put that branch back where the failed switch left it.
+	current, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: previous.Hash(), Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("failed to reset to %s: %w", previous.Name().Short(), err)
+	}
+	if current.Name().IsBranch() && current.Name() != previous.Name() {
+		if err := repo.Storer.SetReference(current); err != nil {
+			return fmt.Errorf("failed to restore branch %s: %w", current.Name().Short(), err)
+		}
+	}
+
+	opts := &git.CheckoutOptions{Force: true}
+	if previous.Name().IsBranch() {
+		opts.Branch = previous.Name()
+		// The failed checkout may have moved the branch; point it back first
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(previous.Name(), previous.Hash())); err != nil {
+			return fmt.Errorf("failed to restore branch %s: %w", previous.Name().Short(), err)
+		}
+	} else {
+		opts.Hash = previous.Hash()
+	}
+	if err := worktree.Checkout(opts); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", previous.Name().Short(), err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"rulem/internal/logging"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// setupBranchOrigin extends setupOriginAndClone with a "develop" branch that
+// adds develop.md, pushed after the reader cloned. Returns (writerPath, readerPath).
+func setupBranchOrigin(t *testing.T) (string, string) {
+	t.Helper()
+	_, writer, reader := setupOriginAndClone(t)
+
+	repo, err := git.PlainOpen(writer)
+	if err != nil {
+		t.Fatalf("open writer: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("develop"), Create: true}); err != nil {
+		t.Fatalf("checkout develop: %v", err)
+	}
+	commitFile(t, writer, "develop.md", "# develop\n")
+	if err := repo.Push(&git.PushOptions{
+		RefSpecs: []config.RefSpec{"refs/heads/develop:refs/heads/develop"},
+	}); err != nil {
+		t.Fatalf("push develop: %v", err)
+	}
+	return writer, reader
+}
+
+func currentBranch(t *testing.T, repoPath string) string {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	return head.Name().Short()
+}
+
+func TestSwitchBranch(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+
+	t.Run("switches to a branch that was not fetched yet", func(t *testing.T) {
+		_, reader := setupBranchOrigin(t)
+
+		var steps []BranchSwitchStep
+		got, err := NewGitSource("", nil, reader).SwitchBranch(context.Background(), "develop",
+			func(step BranchSwitchStep) { steps = append(steps, step) }, logger)
+		if err != nil {
+			t.Fatalf("SwitchBranch: %v", err)
+		}
+		if got != "develop" {
+			t.Errorf("SwitchBranch() = %q, want develop", got)
+		}
+		if b := currentBranch(t, reader); b != "develop" {
+			t.Errorf("HEAD = %q, want develop", b)
+		}
+		if _, err := os.Stat(filepath.Join(reader, "develop.md")); err != nil {
+			t.Errorf("develop.md missing after switch: %v", err)
+		}
+		want := []BranchSwitchStep{BranchSwitchFetching, BranchSwitchVerifying, BranchSwitchCheckingOut, BranchSwitchDone}
+		if !reflect.DeepEqual(steps, want) {
+			t.Errorf("steps = %v, want %v", steps, want)
+		}
+	})
+
+	t.Run("empty branch switches back to the remote default", func(t *testing.T) {
+		_, reader := setupBranchOrigin(t)
+		gs := NewGitSource("", nil, reader)
+		if _, err := gs.SwitchBranch(context.Background(), "develop", nil, logger); err != nil {
+			t.Fatalf("SwitchBranch(develop): %v", err)
+		}
+
+		got, err := gs.SwitchBranch(context.Background(), "", nil, logger)
+		if err != nil {
+			t.Fatalf("SwitchBranch(default): %v", err)
+		}
+		if got != "master" || currentBranch(t, reader) != "master" {
+			t.Errorf("SwitchBranch() = %q on %q, want master", got, currentBranch(t, reader))
+		}
+		if _, err := os.Stat(filepath.Join(reader, "develop.md")); !os.IsNotExist(err) {
+			t.Errorf("develop.md should be gone on master, stat err = %v", err)
+		}
+	})
+
+	t.Run("missing branch fails at fetch and leaves the repository alone", func(t *testing.T) {
+		_, reader := setupBranchOrigin(t)
+
+		_, err := NewGitSource("", nil, reader).SwitchBranch(context.Background(), "nope", nil, logger)
+		var switchErr *BranchSwitchError
+		if !errors.As(err, &switchErr) {
+			t.Fatalf("expected BranchSwitchError, got %v", err)
+		}
+		if switchErr.Step != BranchSwitchFetching {
+			t.Errorf("Step = %v, want %v", switchErr.Step, BranchSwitchFetching)
+		}
+		if !strings.Contains(err.Error(), "does not exist") {
+			t.Errorf("error %q should say the branch does not exist", err)
+		}
+		if b := currentBranch(t, reader); b != "master" {
+			t.Errorf("HEAD = %q, want master", b)
+		}
+	})
+
+	t.Run("uncommitted changes are reported as conflicts", func(t *testing.T) {
+		_, reader := setupBranchOrigin(t)
+		writeFile(t, reader, "README.md", "local edit\n")
+
+		var steps []BranchSwitchStep
+		_, err := NewGitSource("", nil, reader).SwitchBranch(context.Background(), "develop",
+			func(step BranchSwitchStep) { steps = append(steps, step) }, logger)
+		var switchErr *BranchSwitchError
+		if !errors.As(err, &switchErr) {
+			t.Fatalf("expected BranchSwitchError, got %v", err)
+		}
+		if !reflect.DeepEqual(switchErr.Conflicts, []string{"README.md"}) {
+			t.Errorf("Conflicts = %v, want [README.md]", switchErr.Conflicts)
+		}
+		if len(steps) != 0 {
+			t.Errorf("no step should start with conflicts, got %v", steps)
+		}
+		if got := readFile(t, reader, "README.md"); got != "local edit\n" {
+			t.Errorf("local change must be kept, got %q", got)
+		}
+	})
+
+	t.Run("failed checkout rolls back to the previous branch", func(t *testing.T) {
+		_, reader := setupBranchOrigin(t)
+
+		orig := switchCheckout
+		t.Cleanup(func() { switchCheckout = orig })
+		switchCheckout = func(gs GitSource, repo *git.Repository, wt *git.Worktree, branch string, logger *logging.AppLogger) error {
+			if err := orig(gs, repo, wt, branch, logger); err != nil {
+				return err
+			}
+			return errors.New("simulated sync failure")
+		}
+
+		var steps []BranchSwitchStep
+		_, err := NewGitSource("", nil, reader).SwitchBranch(context.Background(), "develop",
+			func(step BranchSwitchStep) { steps = append(steps, step) }, logger)
+		var switchErr *BranchSwitchError
+		if !errors.As(err, &switchErr) {
+			t.Fatalf("expected BranchSwitchError, got %v", err)
+		}
+		if !switchErr.RolledBack || switchErr.RollbackErr != nil {
+			t.Errorf("RolledBack = %v, RollbackErr = %v, want a clean rollback", switchErr.RolledBack, switchErr.RollbackErr)
+		}
+		if !strings.Contains(err.Error(), "restored the previous branch") {
+			t.Errorf("error %q should mention the rollback", err)
+		}
+		if b := currentBranch(t, reader); b != "master" {
+			t.Errorf("HEAD = %q, want master after rollback", b)
+		}
+		if _, err := os.Stat(filepath.Join(reader, "develop.md")); !os.IsNotExist(err) {
+			t.Errorf("develop.md should be gone after rollback, stat err = %v", err)
+		}
+		if steps[len(steps)-1] != BranchSwitchRollingBack {
+			t.Errorf("last step = %v, want %v", steps[len(steps)-1], BranchSwitchRollingBack)
+		}
+	})
+}
+
+func TestBranchSwitchStep_String(t *testing.T) {
+	tests := []struct {
+		step BranchSwitchStep
+		want string
+	}{
+		{BranchSwitchFetching, "Fetching branch"},
+		{BranchSwitchVerifying, "Verifying branch"},
+		{BranchSwitchCheckingOut, "Checking out"},
+		{BranchSwitchRollingBack, "Rolling back"},
+		{BranchSwitchDone, "Done"},
+		{BranchSwitchStep(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.step.String(); got != tt.want {
+			t.Errorf("BranchSwitchStep(%d).String() = %q, want %q", tt.step, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/client"
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+// Clone directory conflicts
+//
+// The clone directory of a GitHub repository can end up holding something
+// else: a different repository, or files that are not a git repository at
+// all. rulem never overwrites it on its own; Prepare and FetchUpdates report a
+// DirectoryConflictError, and ResolveDirectoryConflict applies the resolution
+// the user picked:
+//   - ConflictBackupAndReclone moves the directory to a timestamped backup
+//     next to it and clones afresh, moving it back if the clone fails
+//   - ConflictAdoptExisting keeps the files and turns the directory into a
+//     clone of the configured repository; the files show up as local changes
+//     on top of the remote branch, to be published or discarded. When the
+//     directory's repository already has that branch, its old tip is kept as
+//     refs/rulem-backup/<branch> so its history stays reachable
+//   - ConflictAbort leaves the directory alone
+
+// DirectoryConflictError reports that the clone directory of a GitHub
+// repository holds a different repository or non-git content
+type DirectoryConflictError struct {
+	// Path is the clone directory
+	Path string
+	// Status is DirectoryStatusDifferentRepo or DirectoryStatusConflict
+	Status DirectoryStatus
+	// CurrentRemote is the origin of the repository found in Path, empty for
+	// non-git content
+	CurrentRemote string
+	// ExpectedRemote is the configured remote URL
+	ExpectedRemote string
+}
+
+// Error implements the error interface
+func (e *DirectoryConflictError) Error() string {
+	found := e.Status.String()
+	if e.CurrentRemote != "" {
+		found = fmt.Sprintf("%s %s", found, e.CurrentRemote)
+	}
+	return fmt.Sprintf("directory conflict at %s (%s): back it up and re-clone, or adopt it, from the repository's refresh in Settings",
+		e.Path, found)
+}
+
+// ErrNoDirectoryConflict is returned when a conflict is resolved that is no
+// longer there, e.g. because the directory was cleaned up by hand
+var ErrNoDirectoryConflict = errors.New("the clone directory no longer conflicts with the repository - refresh it instead")
+
+// ConflictResolution is a way out of a clone directory conflict
+type ConflictResolution int
+
+const (
+	// ConflictAbort leaves the directory untouched
+	ConflictAbort ConflictResolution = iota
+	// ConflictBackupAndReclone moves the directory to a timestamped backup and clones afresh
+	ConflictBackupAndReclone
+	// ConflictAdoptExisting keeps the files as local changes on top of the remote branch
+	ConflictAdoptExisting
+)
+
+// String returns a human-readable description of the resolution
+func (r ConflictResolution) String() string {
+	switch r {
+	case ConflictAbort:
+		return "Abort"
+	case ConflictBackupAndReclone:
+		return "Back up and re-clone"
+	case ConflictAdoptExisting:
+		return "Adopt existing directory"
+	default:
+		return "Unknown"
+	}
+}
+
+// backupTimeFormat names backups after the time they were taken
+const backupTimeFormat = "20060102-150405"
+
+// backupRefPrefix is where adopting a directory keeps the old tip of a branch
+// it moves
+const backupRefPrefix = "refs/rulem-backup/"
+
+// directoryConflict builds the DirectoryConflictError for a directory status,
+// or returns nil when the directory can be cloned into or fetched
+func (gs GitSource) directoryConflict(clonePath, expectedRemoteURL string, status DirectoryStatus) error {
+	if status != DirectoryStatusDifferentRepo && status != DirectoryStatusConflict {
+		return nil
+	}
+	conflict := &DirectoryConflictError{Path: clonePath, Status: status, ExpectedRemote: expectedRemoteURL}
+	if status == DirectoryStatusDifferentRepo {
+		conflict.CurrentRemote, _ = gs.getGitRemoteURL(clonePath)
+	}
+	return conflict
+}
+
+// ResolveDirectoryConflict resolves a conflict reported by Prepare or
+// FetchUpdates as the user chose (see ConflictResolution).
+//
+// Returns the backup directory for ConflictBackupAndReclone, the reference
+// keeping the old tip of the adopted branch for ConflictAdoptExisting (empty
+// when no branch was moved), or ErrNoDirectoryConflict when the directory
+// does not conflict.
+//
+// Example:
+//
+//	var conflict *repository.DirectoryConflictError
+//	if errors.As(err, &conflict) {
+//	    backup, err := source.ResolveDirectoryConflict(ctx, repository.ConflictBackupAndReclone, logger)
+//	}
+func (gs GitSource) ResolveDirectoryConflict(ctx context.Context, resolution ConflictResolution, logger *logging.AppLogger) (string, error) {
+	if err := gs.validateInputs(); err != nil {
+		return "", err
+	}
+	normalizedURL, err := gs.normalizeRemoteURL()
+	if err != nil {
+		return "", fmt.Errorf("invalid remote URL: %w", err)
+	}
+	cleanPath, err := gs.validateLocalPath()
+	if err != nil {
+		return "", err
+	}
+	return gs.resolveDirectoryConflict(ctx, cleanPath, normalizedURL, resolution, logger)
+}
+
+// resolveDirectoryConflict applies resolution to the directory at localPath,
+// which must conflict with remoteURL
+func (gs GitSource) resolveDirectoryConflict(ctx context.Context, localPath, remoteURL string, resolution ConflictResolution, logger *logging.AppLogger) (string, error) {
+	status, _ := gs.validateCloneDirectory(localPath, remoteURL)
+	if gs.directoryConflict(localPath, remoteURL, status) == nil {
+		return "", ErrNoDirectoryConflict
+	}
+
+	if logger != nil {
+		logger.Info("Resolving clone directory conflict", "path", localPath, "status", status.String(), "resolution", resolution.String())
+	}
+
+	switch resolution {
+	case ConflictAbort:
+		return "", nil
+	case ConflictBackupAndReclone:
+		return gs.backupAndReclone(ctx, localPath, remoteURL, logger)
+	case ConflictAdoptExisting:
+		return gs.adoptDirectory(ctx, localPath, remoteURL, status, logger)
+	default:
+		return "", fmt.Errorf("unknown conflict resolution: %d", resolution)
+	}
+}
+
+// backupAndReclone moves localPath to a timestamped backup and clones
+// remoteURL into it. A failed clone is removed and the backup moved back.
+func (gs GitSource) backupAndReclone(ctx context.Context, localPath, remoteURL string, logger *logging.AppLogger) (string, error) {
+	backup, err := backupDirectory(localPath, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if logger != nil {
+		logger.Info("Backed up conflicting clone directory", "path", localPath, "backup", backup)
+	}
+
+	cloneErr := gs.performCloneWithAuth(ctx, localPath, remoteURL, logger)
+	if cloneErr == nil {
+		return backup, nil
+	}
+
+	if err := os.RemoveAll(localPath); err == nil {
+		if err := os.Rename(backup, localPath); err == nil {
+			return "", cloneErr
+		}
+	}
+	if logger != nil {
+		logger.Warn("Failed to restore conflicting clone directory", "path", localPath, "backup", backup)
+	}
+	return "", fmt.Errorf("%w (the original directory was kept at %s)", cloneErr, backup)
+}
+
+// backupDirectory renames dir to <dir>.rulem-backup-<time>, adding a numeric
+// suffix when that backup already exists, and returns the backup path
+func backupDirectory(dir string, now time.Time) (string, error) {
+	base := fmt.Sprintf("%s.rulem-backup-%s", filepath.Clean(dir), now.Format(backupTimeFormat))
+	backup := base
+	for n := 2; ; n++ {
+		if _, err := os.Lstat(backup); os.IsNotExist(err) {
+			break
+		}
+		backup = fmt.Sprintf("%s-%d", base, n)
+	}
+	if err := os.Rename(dir, backup); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", dir, err)
+	}
+	return backup, nil
+}
+
+// adoptDirectory points the directory at remoteURL, initialising a repository
+// for non-git content, fetches the branch and moves HEAD to it without
+// touching the files. A failure puts the directory's git state back.
+//
+// Returns the reference keeping the branch's old tip, if it was moved.
+func (gs GitSource) adoptDirectory(ctx context.Context, localPath, remoteURL string, status DirectoryStatus, logger *logging.AppLogger) (string, error) {
+	if gs.frozen() {
+		return "", fmt.Errorf("a repository frozen at a %s cannot adopt an existing directory - back it up and re-clone instead", gs.frozenDescription())
+	}
+
+	var repo *git.Repository
+	var rollback func()
+	if status == DirectoryStatusConflict {
+		var err error
+		repo, err = git.PlainInit(localPath, false)
+		if err != nil {
+			return "", fmt.Errorf("failed to initialise a repository in %s: %w", localPath, err)
+		}
+		rollback = func() { _ = os.RemoveAll(filepath.Join(localPath, git.GitDirName)) }
+	} else {
+		var err error
+		repo, err = git.PlainOpen(localPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to open existing repository: %w", err)
+		}
+		if rollback, err = restorableGitState(repo); err != nil {
+			return "", err
+		}
+		if err := repo.DeleteRemote("origin"); err != nil && !errors.Is(err, git.ErrRemoteNotFound) {
+			return "", fmt.Errorf("failed to remove the existing origin remote: %w", err)
+		}
+	}
+
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteURL}}); err != nil {
+		rollback()
+		return "", fmt.Errorf("failed to add origin remote: %w", err)
+	}
+
+	backupRef, err := gs.attachToRemote(ctx, repo, nil, logger)
+	if err != nil && gs.isAuthenticationError(err) {
+		auth, authErr := gs.getAuthentication(logger)
+		switch {
+		case authErr != nil:
+			err = fmt.Errorf("%s authentication failed: %w", gs.provider().DisplayName(), authErr)
+		case auth == nil:
+			err = gs.authRequiredError()
+		default:
+			backupRef, err = gs.attachToRemote(ctx, repo, auth, logger)
+		}
+	}
+	if err != nil {
+		rollback()
+		return "", err
+	}
+
+	if logger != nil {
+		logger.Info("Adopted existing clone directory", "path", localPath, "backup_ref", backupRef)
+	}
+	return backupRef, nil
+}
+
+// restorableGitState returns a function that puts back the origin remote and
+// HEAD of repo as they are now
+func restorableGitState(repo *git.Repository) (func(), error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository config: %w", err)
+	}
+	origin := cfg.Remotes["origin"]
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	return func() {
+		if cfg, err := repo.Config(); err == nil {
+			delete(cfg.Remotes, "origin")
+			if origin != nil {
+				cfg.Remotes["origin"] = origin
+			}
+			_ = repo.SetConfig(cfg)
+		}
+		_ = repo.Storer.SetReference(head)
+	}, nil
+}
+
+// attachToRemote fetches the configured branch, or the remote's default
+// branch, tracks it like a single-branch clone and points HEAD at it with a
+// mixed reset, so the working tree is kept as local changes. A local branch
+// of that name that points elsewhere is saved under refs/rulem-backup/ first.
+//
+// Returns the saved reference, empty when no branch was moved.
+func (gs GitSource) attachToRemote(ctx context.Context, repo *git.Repository, auth *http.BasicAuth, logger *logging.AppLogger) (string, error) {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return "", fmt.Errorf("failed to get origin remote: %w", err)
+	}
+	var clientOpts []client.Option
+	if auth != nil {
+		clientOpts = []client.Option{client.WithHTTPAuth(auth)}
+	}
+
+	// Bound the fetch so a hung connection can't block forever
+	opCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	branch := ""
+	if gs.Branch != nil {
+		branch = *gs.Branch
+	}
+	if branch == "" {
+		refs, err := remote.ListContext(opCtx, &git.ListOptions{ClientOptions: clientOpts})
+		if err != nil {
+			return "", gs.translateFetchError(err)
+		}
+		if branch = defaultBranchFromRefs(refs); branch == "" {
+			return "", fmt.Errorf("the remote does not advertise a default branch - set the repository's branch in Settings")
+		}
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("+%s:%s",
+		plumbing.NewBranchReferenceName(branch),
+		plumbing.NewRemoteReferenceName("origin", branch)))
+	err = remote.FetchContext(opCtx, &git.FetchOptions{
+		RefSpecs:      []config.RefSpec{refSpec},
+		Depth:         1,
+		Tags:          git.NoTags,
+		Force:         true,
+		ClientOptions: clientOpts,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		if isMissingRemoteRef(err) {
+			return "", branchGoneError(opCtx, remote, clientOpts, branch)
+		}
+		return "", gs.translateFetchError(err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to read repository config: %w", err)
+	}
+	cfg.Remotes["origin"].Fetch = []config.RefSpec{refSpec}
+	if err := repo.SetConfig(cfg); err != nil {
+		return "", fmt.Errorf("failed to update fetch refspec: %w", err)
+	}
+
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), true)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve origin/%s: %w", branch, err)
+	}
+	// A freshly initialised repository has no commits, so the branch does
+	// not exist yet; create it at the fetched commit for HEAD to point at
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	backupRef := ""
+	oldTip, err := repo.Storer.Reference(branchRef)
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(branchRef, remoteRef.Hash())); err != nil {
+			return "", fmt.Errorf("failed to create branch %s: %w", branch, err)
+		}
+	case err != nil:
+		return "", fmt.Errorf("failed to read branch %s: %w", branch, err)
+	case oldTip.Hash() != remoteRef.Hash():
+		// The reset below moves the branch; keep its history reachable
+		if backupRef, err = saveBranchTip(repo, branch, oldTip.Hash()); err != nil {
+			return "", err
+		}
+	}
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branchRef)); err != nil {
+		return "", fmt.Errorf("failed to point HEAD at %s: %w", branch, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working tree: %w", err)
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: remoteRef.Hash(), Mode: git.MixedReset}); err != nil {
+		if backupRef != "" {
+			_ = repo.Storer.SetReference(plumbing.NewHashReference(branchRef, oldTip.Hash()))
+		}
+		return "", fmt.Errorf("failed to move %s to %s: %w", branch, remoteRef.Hash().String()[:8], err)
+	}
+
+	if logger != nil {
+		logger.Info("Attached directory to remote branch", "branch", branch, "commit", remoteRef.Hash().String()[:8], "backup_ref", backupRef)
+	}
+	return backupRef, nil
+}
+
+// saveBranchTip points refs/rulem-backup/<branch> at tip, adding a numeric
+// suffix when that reference already holds another commit, and returns the
+// reference's name
+func saveBranchTip(repo *git.Repository, branch string, tip plumbing.Hash) (string, error) {
+	name := plumbing.ReferenceName(backupRefPrefix + branch)
+	for n := 2; ; n++ {
+		existing, err := repo.Storer.Reference(name)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if existing.Hash() == tip {
+			return name.String(), nil
+		}
+		name = plumbing.ReferenceName(fmt.Sprintf("%s%s-%d", backupRefPrefix, branch, n))
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(name, tip)); err != nil {
+		return "", fmt.Errorf("failed to save the old tip of %s: %w", branch, err)
+	}
+	return name.String(), nil
+}
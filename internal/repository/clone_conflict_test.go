@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// writeConflictDir creates a directory holding files that are not a git repository
+func writeConflictDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "clone")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// changedFilesByPath lists the changes of the repo at dir keyed by path
+func changedFilesByPath(t *testing.T, dir string) map[string]FileChangeKind {
+	t.Helper()
+	changes, err := ListChangedFiles(dir)
+	if err != nil {
+		t.Fatalf("ListChangedFiles: %v", err)
+	}
+	kinds := map[string]FileChangeKind{}
+	for _, change := range changes {
+		kinds[change.Path] = change.Kind
+	}
+	return kinds
+}
+
+func TestResolveDirectoryConflict_BackupAndReclone(t *testing.T) {
+	origin, _, _ := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+	dir := writeConflictDir(t, map[string]string{"notes.txt": "mine\n"})
+
+	backup, err := GitSource{Path: dir}.resolveDirectoryConflict(context.Background(), dir, origin, ConflictBackupAndReclone, logger)
+	if err != nil {
+		t.Fatalf("resolveDirectoryConflict: %v", err)
+	}
+
+	if !strings.HasPrefix(filepath.Base(backup), "clone.rulem-backup-") || filepath.Dir(backup) != filepath.Dir(dir) {
+		t.Errorf("expected a timestamped backup next to the clone, got %s", backup)
+	}
+	if content, err := os.ReadFile(filepath.Join(backup, "notes.txt")); err != nil || string(content) != "mine\n" {
+		t.Errorf("backup should hold the original files, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "README.md")); err != nil {
+		t.Errorf("expected a fresh clone: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err == nil {
+		t.Error("the fresh clone must not contain the original files")
+	}
+}
+
+func TestResolveDirectoryConflict_FailedRecloneRestores(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	dir := writeConflictDir(t, map[string]string{"notes.txt": "mine\n"})
+	missing := filepath.Join(t.TempDir(), "missing.git")
+
+	_, err := GitSource{Path: dir}.resolveDirectoryConflict(context.Background(), dir, missing, ConflictBackupAndReclone, logger)
+	if err == nil {
+		t.Fatal("expected the clone to fail")
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "notes.txt")); err != nil || string(content) != "mine\n" {
+		t.Errorf("the original directory should be restored, got %q (%v)", content, err)
+	}
+	if backups, _ := filepath.Glob(dir + ".rulem-backup-*"); len(backups) != 0 {
+		t.Errorf("no backup should be left behind, found %v", backups)
+	}
+}
+
+func TestResolveDirectoryConflict_AdoptNonGitContent(t *testing.T) {
+	origin, _, _ := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+	dir := writeConflictDir(t, map[string]string{
+		"README.md": "# my version\n",
+		"notes.txt": "mine\n",
+	})
+
+	if _, err := (GitSource{Path: dir}).resolveDirectoryConflict(context.Background(), dir, origin, ConflictAdoptExisting, logger); err != nil {
+		t.Fatalf("resolveDirectoryConflict: %v", err)
+	}
+
+	if remote, err := (GitSource{}).getGitRemoteURL(dir); err != nil || remote != origin {
+		t.Errorf("expected origin %s, got %s (%v)", origin, remote, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "README.md")); string(content) != "# my version\n" {
+		t.Errorf("adopting must keep the files, got %q", content)
+	}
+	changes := changedFilesByPath(t, dir)
+	if changes["README.md"] != FileChangeModified || changes["notes.txt"] != FileChangeUntracked {
+		t.Errorf("expected the files as local changes on top of the remote, got %v", changes)
+	}
+	if head := headOf(t, dir); head.Name().Short() != "master" {
+		t.Errorf("expected HEAD on the remote's default branch, got %s", head.Name())
+	}
+}
+
+func TestResolveDirectoryConflict_AdoptDifferentRepository(t *testing.T) {
+	origin, _, _ := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+
+	dir := filepath.Join(t.TempDir(), "clone")
+	other, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := other.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"https://github.com/someone/else.git"}}); err != nil {
+		t.Fatalf("create remote: %v", err)
+	}
+	commitFile(t, dir, "else.md", "# else\n")
+	oldTip := headOf(t, dir).Hash()
+
+	if status, _ := (GitSource{}).validateCloneDirectory(dir, origin); status != DirectoryStatusDifferentRepo {
+		t.Fatalf("expected a different repository, got %s", status)
+	}
+
+	backupRef, err := (GitSource{Path: dir}).resolveDirectoryConflict(context.Background(), dir, origin, ConflictAdoptExisting, logger)
+	if err != nil {
+		t.Fatalf("resolveDirectoryConflict: %v", err)
+	}
+	if backupRef != "refs/rulem-backup/master" {
+		t.Fatalf("backup ref = %q, want the old tip of master saved", backupRef)
+	}
+	if saved, err := other.Reference(plumbing.ReferenceName(backupRef), false); err != nil || saved.Hash() != oldTip {
+		t.Errorf("expected %s at the old tip %s, got %v (%v)", backupRef, oldTip, saved, err)
+	}
+	if remote, _ := (GitSource{}).getGitRemoteURL(dir); remote != origin {
+		t.Errorf("expected origin %s, got %s", origin, remote)
+	}
+	changes := changedFilesByPath(t, dir)
+	if changes["else.md"] != FileChangeUntracked || changes["README.md"] != FileChangeDeleted {
+		t.Errorf("expected the other repository's files as local changes, got %v", changes)
+	}
+}
+
+func TestResolveDirectoryConflict_AdoptFailureRollsBack(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	dir := writeConflictDir(t, map[string]string{"notes.txt": "mine\n"})
+	missing := filepath.Join(t.TempDir(), "missing.git")
+
+	if _, err := (GitSource{Path: dir}).resolveDirectoryConflict(context.Background(), dir, missing, ConflictAdoptExisting, logger); err == nil {
+		t.Fatal("expected adopting to fail")
+	}
+	if _, err := os.Stat(filepath.Join(dir, git.GitDirName)); err == nil {
+		t.Error("a failed adoption must not leave a repository behind")
+	}
+}
+
+func TestResolveDirectoryConflict_NoConflictAndAbort(t *testing.T) {
+	origin, _, reader := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+
+	_, err := GitSource{Path: reader}.resolveDirectoryConflict(context.Background(), reader, origin, ConflictBackupAndReclone, logger)
+	if !errors.Is(err, ErrNoDirectoryConflict) {
+		t.Errorf("expected ErrNoDirectoryConflict for a clean clone, got %v", err)
+	}
+
+	dir := writeConflictDir(t, map[string]string{"notes.txt": "mine\n"})
+	if backup, err := (GitSource{Path: dir}).resolveDirectoryConflict(context.Background(), dir, origin, ConflictAbort, logger); err != nil || backup != "" {
+		t.Errorf("abort should do nothing, got %q (%v)", backup, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, git.GitDirName)); err == nil {
+		t.Error("abort must leave the directory untouched")
+	}
+}
+
+func TestDirectoryConflictError_FromPrepareAndFetch(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	dir := writeConflictDir(t, map[string]string{"notes.txt": "mine\n"})
+	gs := NewGitSource("https://github.com/octocat/Hello-World.git", nil, dir)
+
+	for name, run := range map[string]func() error{
+		"Prepare":      func() error { _, err := gs.Prepare(context.Background(), logger); return err },
+		"FetchUpdates": func() error { return gs.FetchUpdates(context.Background(), logger) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			var conflict *DirectoryConflictError
+			if err := run(); !errors.As(err, &conflict) {
+				t.Fatalf("expected a DirectoryConflictError, got %v", err)
+			}
+			if conflict.Path != dir || conflict.Status != DirectoryStatusConflict {
+				t.Errorf("unexpected conflict %+v", conflict)
+			}
+		})
+	}
+}
+
+func TestBackupDirectory_Suffix(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "rules")
+
+	var backups []string
+	for range 2 {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		backup, err := backupDirectory(dir, now)
+		if err != nil {
+			t.Fatalf("backupDirectory: %v", err)
+		}
+		backups = append(backups, filepath.Base(backup))
+	}
+
+	want := []string{"rules.rulem-backup-20240501-103000", "rules.rulem-backup-20240501-103000-2"}
+	if strings.Join(backups, ",") != strings.Join(want, ",") {
+		t.Errorf("backups = %v, want %v", backups, want)
+	}
+}
+
+func TestResolveDirectoryConflict_FrozenCannotAdopt(t *testing.T) {
+	origin, _, _ := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+	dir := writeConflictDir(t, map[string]string{"notes.txt": "mine\n"})
+
+	gs := GitSource{Path: dir, Tag: "v1.0"}
+	_, err := gs.resolveDirectoryConflict(context.Background(), dir, origin, ConflictAdoptExisting, logger)
+	if err == nil || !strings.Contains(err.Error(), "re-clone instead") {
+		t.Errorf("expected frozen repositories to refuse adopting, got %v", err)
+	}
+}
+
+func TestSaveBranchTip_Suffix(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	commitFile(t, dir, "a.md", "a\n")
+	first := headOf(t, dir).Hash()
+	commitFile(t, dir, "b.md", "b\n")
+	second := headOf(t, dir).Hash()
+
+	for _, tt := range []struct {
+		tip  plumbing.Hash
+		want string
+	}{
+		{first, "refs/rulem-backup/main"},
+		{first, "refs/rulem-backup/main"},
+		{second, "refs/rulem-backup/main-2"},
+	} {
+		got, err := saveBranchTip(repo, "main", tt.tip)
+		if err != nil || got != tt.want {
+			t.Errorf("saveBranchTip(%s) = %q, %v, want %q", tt.tip, got, err, tt.want)
+		}
+	}
+}
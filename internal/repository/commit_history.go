@@ -0,0 +1,444 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/storer"
+)
+
+// Commit history helpers
+//
+// When a rule regresses after a sync, the commit browser lists the recent
+// commits of a clone and checks one out in detached mode for inspection. The
+// branch that was checked out is recorded in .git/rulem-inspect; while that
+// file exists sync leaves the clone alone, and ReturnFromInspection puts the
+// branch back.
+//
+// Clones are shallow (depth 1), so history starts at the commit that was
+// cloned and grows with every sync.
+
+// inspectFileName is the file inside .git recording the branch to return to
+const inspectFileName = "rulem-inspect"
+
+var (
+	// ErrInspecting is returned by operations that would move HEAD while a commit is being inspected
+	ErrInspecting = errors.New("repository is checked out at an older commit for inspection - return to the branch first")
+	// ErrNotInspecting is returned by ReturnFromInspection when no commit is being inspected
+	ErrNotInspecting = errors.New("repository is not inspecting a commit")
+)
+
+// CommitInfo describes one commit for the commit browser
+type CommitInfo struct {
+	// Hash is the full commit hash
+	Hash string
+	// Message is the first line of the commit message
+	Message string
+	// Author is the author name
+	Author string
+	// When is the author time
+	When time.Time
+	// Files lists the paths touched relative to the first parent, sorted
+	Files []string
+	// Boundary is true when the parent was not fetched (shallow clone), so Files is unknown
+	Boundary bool
+}
+
+// ShortHash returns the abbreviated commit hash
+func (c CommitInfo) ShortHash() string {
+	if len(c.Hash) > 8 {
+		return c.Hash[:8]
+	}
+	return c.Hash
+}
+
+// Inspection describes a commit checked out with CheckoutCommit
+type Inspection struct {
+	// Branch is the branch ReturnFromInspection checks out again
+	Branch string
+	// Commit is the hash of the inspected commit
+	Commit string
+}
+
+// ListCommits returns up to limit commits of the checked-out branch, newest
+// first. While inspecting, the branch being returned to is listed rather than
+// the history behind the inspected commit.
+func ListCommits(repoPath string, limit int) ([]CommitInfo, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	var from plumbing.Hash
+	if inspection, ok := CurrentInspection(repoPath); ok {
+		ref, err := repo.Reference(plumbing.NewBranchReferenceName(inspection.Branch), true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve branch %s: %w", inspection.Branch, err)
+		}
+		from = ref.Hash()
+	} else {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+		}
+		from = head.Hash()
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit log: %w", err)
+	}
+	defer iter.Close()
+
+	var commits []CommitInfo
+	err = iter.ForEach(func(c *object.Commit) error {
+		if len(commits) >= limit {
+			return storer.ErrStop
+		}
+		info, err := commitInfo(c)
+		if err != nil {
+			return err
+		}
+		commits = append(commits, info)
+		return nil
+	})
+	// The log stops with ErrObjectNotFound at the shallow boundary
+	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, fmt.Errorf("failed to read commit log: %w", err)
+	}
+	return commits, nil
+}
+
+// commitInfo summarizes a commit and the files it touched
+func commitInfo(c *object.Commit) (CommitInfo, error) {
+	message, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	info := CommitInfo{
+		Hash:    c.Hash.String(),
+		Message: message,
+		Author:  c.Author.Name,
+		When:    c.Author.When,
+	}
+
+	tree, err := c.Tree()
+	if err != nil {
+		return info, fmt.Errorf("failed to load tree of %s: %w", info.ShortHash(), err)
+	}
+
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				info.Boundary = true
+				return info, nil
+			}
+			return info, fmt.Errorf("failed to load parent of %s: %w", info.ShortHash(), err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return info, fmt.Errorf("failed to load parent tree of %s: %w", info.ShortHash(), err)
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return info, fmt.Errorf("failed to diff %s: %w", info.ShortHash(), err)
+	}
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		info.Files = append(info.Files, name)
+	}
+	sort.Strings(info.Files)
+	return info, nil
+}
+
+// CurrentInspection reports whether repoPath has a commit checked out for inspection
+func CurrentInspection(repoPath string) (Inspection, bool) {
+	data, err := os.ReadFile(inspectFile(repoPath))
+	if err != nil {
+		return Inspection{}, false
+	}
+	inspection := Inspection{Branch: strings.TrimSpace(string(data))}
+	if repo, err := git.PlainOpen(repoPath); err == nil {
+		if head, err := repo.Head(); err == nil {
+			inspection.Commit = head.Hash().String()
+		}
+	}
+	return inspection, true
+}
+
+// CheckoutCommit checks out hash (full or abbreviated) in detached mode and
+// records the current branch so ReturnFromInspection can restore it. Another
+// commit can be checked out while inspecting; the original branch is kept.
+func CheckoutCommit(repoPath, hash string) error {
+	repo, worktree, err := openWorktree(repoPath)
+	if err != nil {
+		return err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get repository status: %w", err)
+	}
+	if !status.IsClean() {
+		return fmt.Errorf("repository has uncommitted changes - discard or stash them before checking out a commit")
+	}
+
+	target, err := repo.ResolveRevision(plumbing.Revision(hash))
+	if err != nil {
+		return fmt.Errorf("commit %s not found: %w", hash, err)
+	}
+
+	_, inspecting := CurrentInspection(repoPath)
+	if !inspecting {
+		head, err := repo.Head()
+		if err != nil {
+			return fmt.Errorf("failed to resolve HEAD: %w", err)
+		}
+		if !head.Name().IsBranch() {
+			return fmt.Errorf("HEAD is not on a branch - cannot record where to return to")
+		}
+		if err := os.WriteFile(inspectFile(repoPath), []byte(head.Name().Short()+"\n"), 0600); err != nil {
+			return fmt.Errorf("failed to record inspected branch: %w", err)
+		}
+	}
+
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: *target}); err != nil {
+		if !inspecting {
+			os.Remove(inspectFile(repoPath))
+		}
+		return fmt.Errorf("failed to check out %s: %w", hash, err)
+	}
+	return nil
+}
+
+// ReturnFromInspection checks out the branch recorded by CheckoutCommit and
+// returns its name.
+func ReturnFromInspection(repoPath string) (string, error) {
+	inspection, ok := CurrentInspection(repoPath)
+	if !ok {
+		return "", ErrNotInspecting
+	}
+
+	_, worktree, err := openWorktree(repoPath)
+	if err != nil {
+		return "", err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository status: %w", err)
+	}
+	if !status.IsClean() {
+		return "", fmt.Errorf("repository has uncommitted changes - discard them before returning to %s", inspection.Branch)
+	}
+
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(inspection.Branch)}); err != nil {
+		return "", fmt.Errorf("failed to check out %s: %w", inspection.Branch, err)
+	}
+	if err := clearInspection(repoPath); err != nil {
+		return "", err
+	}
+	return inspection.Branch, nil
+}
+
+// clearInspection forgets an inspection, e.g. after HEAD moved to a branch by other means
+func clearInspection(repoPath string) error {
+	if err := os.Remove(inspectFile(repoPath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear inspection marker: %w", err)
+	}
+	return nil
+}
+
+// inspectFile returns the path of the inspection marker for repoPath
+func inspectFile(repoPath string) string {
+	return filepath.Join(repoPath, git.GitDirName, inspectFileName)
+}
+
+// HeadCommit returns the hash of the commit checked out in repoPath
+func HeadCommit(repoPath string) (string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return head.Hash().String(), nil
+}
+
+// ReadFileAtRevision returns the content of path (relative to the repository
+// root, slash-separated) at revision, which may be a branch, tag or commit
+// hash, full or abbreviated.
+//
+// Returns os.ErrNotExist (wrapped) when the file is not in that revision.
+func ReadFileAtRevision(repoPath, revision, path string) ([]byte, error) {
+	clean, err := cleanRepoRelativePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("revision %s not found: %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit %s: %w", revision, err)
+	}
+
+	file, err := commit.File(clean)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, fmt.Errorf("%s not found at %s: %w", clean, revision, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", clean, revision, err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", clean, revision, err)
+	}
+	return []byte(content), nil
+}
+
+// RevisionFile is a file read from a commit rather than the working tree
+type RevisionFile struct {
+	// Path is relative to the repository root, slash-separated
+	Path string
+	// Content is the file's content at the commit
+	Content []byte
+}
+
+// ReadFilesAtRevision returns the regular files of revision accepted by keep,
+// sorted by path, along with the full hash of the commit revision resolves to.
+// Only git objects are read: the working tree and HEAD are left alone, so a
+// clone can be read at an older commit while it keeps syncing.
+func ReadFilesAtRevision(repoPath, revision string, keep func(path string) bool) (string, []RevisionFile, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return "", nil, fmt.Errorf("revision %s not found: %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load commit %s: %w", revision, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load tree of %s: %w", revision, err)
+	}
+
+	var files []RevisionFile
+	err = tree.Files().ForEach(func(file *object.File) error {
+		// Symlinks and submodules have no content of their own to serve
+		if file.Mode != filemode.Regular && file.Mode != filemode.Executable {
+			return nil
+		}
+		if !keep(file.Name) {
+			return nil
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return fmt.Errorf("failed to read %s at %s: %w", file.Name, revision, err)
+		}
+		files = append(files, RevisionFile{Path: file.Name, Content: []byte(content)})
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return hash.String(), files, nil
+}
+
+// ChangedFilesSince returns the files, added, modified or deleted, that differ between the checked-out
+// commit and the last commit before since, sorted by path. When history ends
+// before since (the first commit, or a shallow clone's oldest commit, is newer)
+// the oldest commit available is the base, and for the first commit its files
+// count as added.
+func ChangedFilesSince(repoPath string, since time.Time) ([]FileChange, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load HEAD commit: %w", err)
+	}
+
+	// Walk first parents back to the last commit before since
+	var base *object.Commit
+	for c := headCommit; ; {
+		if c.Committer.When.Before(since) {
+			base = c
+			break
+		}
+		if c.NumParents() == 0 {
+			break
+		}
+		parent, err := c.Parent(0)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			base = c
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit log: %w", err)
+		}
+		c = parent
+	}
+
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load HEAD tree: %w", err)
+	}
+	var baseTree *object.Tree
+	if base != nil {
+		if base.Hash == headCommit.Hash {
+			return nil, nil
+		}
+		if baseTree, err = base.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to load tree of %s: %w", base.Hash, err)
+		}
+	}
+
+	changes, err := object.DiffTree(baseTree, headTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff history: %w", err)
+	}
+	files := make([]FileChange, 0, len(changes))
+	for _, change := range changes {
+		switch {
+		case change.From.Name == "":
+			files = append(files, FileChange{Path: change.To.Name, Kind: FileChangeAdded})
+		case change.To.Name == "":
+			files = append(files, FileChange{Path: change.From.Name, Kind: FileChangeDeleted})
+		default:
+			files = append(files, FileChange{Path: change.To.Name, Kind: FileChangeModified})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
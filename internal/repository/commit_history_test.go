@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/storage/memory"
+)
+
+// historyRepo returns a reader clone synced to three commits:
+// "add README.md", "add rules.md", "add README.md" (README edited)
+func historyRepo(t *testing.T) string {
+	t.Helper()
+	_, writer, reader := setupOriginAndClone(t)
+	commitFile(t, writer, "rules.md", "# rules\n")
+	commitFile(t, writer, "README.md", "# hello again\n")
+	pushToOrigin(t, writer)
+
+	logger, _ := logging.NewTestLogger()
+	if err := NewGitSource("", nil, reader).FetchUpdates(context.Background(), logger); err != nil {
+		t.Fatalf("FetchUpdates: %v", err)
+	}
+	return reader
+}
+
+func TestListCommits(t *testing.T) {
+	reader := historyRepo(t)
+
+	tests := []struct {
+		name      string
+		limit     int
+		wantFiles [][]string
+	}{
+		{name: "all", limit: 10, wantFiles: [][]string{{"README.md"}, {"rules.md"}, {"README.md"}}},
+		{name: "limited", limit: 2, wantFiles: [][]string{{"README.md"}, {"rules.md"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits, err := ListCommits(reader, tt.limit)
+			if err != nil {
+				t.Fatalf("ListCommits: %v", err)
+			}
+			if len(commits) != len(tt.wantFiles) {
+				t.Fatalf("got %d commits, want %d", len(commits), len(tt.wantFiles))
+			}
+			for i, c := range commits {
+				if !reflect.DeepEqual(c.Files, tt.wantFiles[i]) {
+					t.Errorf("commit %d files = %v, want %v", i, c.Files, tt.wantFiles[i])
+				}
+				if c.Author != "test" || c.Boundary || len(c.ShortHash()) != 8 {
+					t.Errorf("unexpected commit %+v", c)
+				}
+			}
+			if commits[0].Message != "add README.md" || commits[1].Message != "add rules.md" {
+				t.Errorf("unexpected messages %q, %q", commits[0].Message, commits[1].Message)
+			}
+		})
+	}
+}
+
+func TestCommitInfo_ShallowBoundary(t *testing.T) {
+	// A shallow clone keeps the parent hash of its oldest commit but not the
+	// parent object; build such a commit in memory
+	storage := memory.NewStorage()
+	tree := &object.Tree{}
+	treeObj := storage.NewEncodedObject()
+	if err := tree.Encode(treeObj); err != nil {
+		t.Fatalf("encode tree: %v", err)
+	}
+	treeHash, err := storage.SetEncodedObject(treeObj)
+	if err != nil {
+		t.Fatalf("store tree: %v", err)
+	}
+
+	sig := object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := &object.Commit{
+		Author:       sig,
+		Committer:    sig,
+		Message:      "boundary\n\nbody",
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")},
+	}
+	commitObj := storage.NewEncodedObject()
+	if err := commit.Encode(commitObj); err != nil {
+		t.Fatalf("encode commit: %v", err)
+	}
+	commitHash, err := storage.SetEncodedObject(commitObj)
+	if err != nil {
+		t.Fatalf("store commit: %v", err)
+	}
+	stored, err := object.GetCommit(storage, commitHash)
+	if err != nil {
+		t.Fatalf("load commit: %v", err)
+	}
+
+	info, err := commitInfo(stored)
+	if err != nil {
+		t.Fatalf("commitInfo: %v", err)
+	}
+	if !info.Boundary || info.Files != nil || info.Message != "boundary" {
+		t.Errorf("expected a boundary commit without files, got %+v", info)
+	}
+}
+
+func TestChangedFilesSince(t *testing.T) {
+	reader := historyRepo(t)
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  []FileChange
+	}{
+		{
+			name:  "whole history",
+			since: time.Time{},
+			want:  []FileChange{{Path: "README.md", Kind: FileChangeAdded}, {Path: "rules.md", Kind: FileChangeAdded}},
+		},
+		{name: "nothing since", since: time.Now().Add(time.Hour), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ChangedFilesSince(reader, tt.since)
+			if err != nil {
+				t.Fatalf("ChangedFilesSince: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangedFilesSince() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ChangedFilesSince(t.TempDir(), time.Time{}); err == nil {
+		t.Error("expected an error for a directory that is not a repository")
+	}
+}
+
+func TestCheckoutCommitAndReturn(t *testing.T) {
+	reader := historyRepo(t)
+	commits, err := ListCommits(reader, 10)
+	if err != nil {
+		t.Fatalf("ListCommits: %v", err)
+	}
+	oldest := commits[2]
+
+	if err := CheckoutCommit(reader, oldest.ShortHash()); err != nil {
+		t.Fatalf("CheckoutCommit: %v", err)
+	}
+	inspection, ok := CurrentInspection(reader)
+	if !ok || inspection.Branch != "master" || inspection.Commit != oldest.Hash {
+		t.Fatalf("CurrentInspection() = %+v, %v", inspection, ok)
+	}
+	if _, err := os.Stat(filepath.Join(reader, "rules.md")); !os.IsNotExist(err) {
+		t.Errorf("rules.md should not exist at the oldest commit, stat err = %v", err)
+	}
+	if got := readFile(t, reader, "README.md"); got != "# hello\n" {
+		t.Errorf("README.md = %q at the oldest commit", got)
+	}
+
+	// The browser keeps listing the branch while inspecting
+	if listed, err := ListCommits(reader, 10); err != nil || len(listed) != 3 {
+		t.Errorf("ListCommits while inspecting = %d commits, err %v", len(listed), err)
+	}
+
+	// Moving to another commit keeps the original branch
+	if err := CheckoutCommit(reader, commits[1].Hash); err != nil {
+		t.Fatalf("CheckoutCommit second: %v", err)
+	}
+	if inspection, _ := CurrentInspection(reader); inspection.Branch != "master" {
+		t.Errorf("branch to return to = %q, want master", inspection.Branch)
+	}
+
+	// Sync leaves the inspected commit alone
+	logger, _ := logging.NewTestLogger()
+	if err := NewGitSource("", nil, reader).FetchUpdates(context.Background(), logger); !errors.Is(err, ErrInspecting) {
+		t.Errorf("FetchUpdates while inspecting = %v, want ErrInspecting", err)
+	}
+	if result := syncSingleRepository(context.Background(), githubEntry(reader), logger); result.Status != SyncStatusSkipped {
+		t.Errorf("sync status while inspecting = %v, want Skipped", result.Status)
+	}
+
+	branch, err := ReturnFromInspection(reader)
+	if err != nil || branch != "master" {
+		t.Fatalf("ReturnFromInspection() = %q, %v", branch, err)
+	}
+	if _, ok := CurrentInspection(reader); ok {
+		t.Error("inspection should be cleared")
+	}
+	if got := readFile(t, reader, "README.md"); got != "# hello again\n" {
+		t.Errorf("README.md = %q after return", got)
+	}
+	if _, err := ReturnFromInspection(reader); !errors.Is(err, ErrNotInspecting) {
+		t.Errorf("second ReturnFromInspection = %v, want ErrNotInspecting", err)
+	}
+}
+
+func TestCheckoutCommit_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		dirty bool
+		hash  string
+	}{
+		{name: "dirty worktree", dirty: true, hash: "HEAD~1"},
+		{name: "unknown commit", hash: "0123456789abcdef0123456789abcdef01234567"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := historyRepo(t)
+			if tt.dirty {
+				writeFile(t, reader, "README.md", "local\n")
+			}
+			if err := CheckoutCommit(reader, tt.hash); err == nil {
+				t.Fatal("expected an error")
+			}
+			if _, ok := CurrentInspection(reader); ok {
+				t.Error("a failed checkout must not leave an inspection behind")
+			}
+		})
+	}
+}
+
+func TestReadFileAtRevision(t *testing.T) {
+	reader := historyRepo(t)
+	head, err := HeadCommit(reader)
+	if err != nil || len(head) != 40 {
+		t.Fatalf("HeadCommit = %q, %v", head, err)
+	}
+
+	tests := []struct {
+		name     string
+		revision string
+		path     string
+		want     string
+		notExist bool
+		wantErr  bool
+	}{
+		{name: "head", revision: "HEAD", path: "README.md", want: "# hello again\n"},
+		{name: "commit hash", revision: head[:8], path: "rules.md", want: "# rules\n"},
+		{name: "older commit", revision: "HEAD~1", path: "README.md", want: "# hello\n"},
+		{name: "branch", revision: "master", path: "README.md", want: "# hello again\n"},
+		{name: "file added later", revision: "HEAD~2", path: "rules.md", notExist: true},
+		{name: "unknown revision", revision: "no-such-branch", path: "README.md", wantErr: true},
+		{name: "escaping path", revision: "HEAD", path: "../README.md", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadFileAtRevision(reader, tt.revision, tt.path)
+			switch {
+			case tt.notExist:
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("err = %v, want os.ErrNotExist", err)
+				}
+			case tt.wantErr:
+				if err == nil {
+					t.Error("expected an error")
+				}
+			case err != nil:
+				t.Fatalf("ReadFileAtRevision: %v", err)
+			case string(got) != tt.want:
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFilesAtRevision(t *testing.T) {
+	reader := historyRepo(t)
+	all := func(string) bool { return true }
+
+	head, files, err := ReadFilesAtRevision(reader, "HEAD~1", all)
+	if err != nil {
+		t.Fatalf("ReadFilesAtRevision: %v", err)
+	}
+	if len(head) != 40 {
+		t.Errorf("hash = %q, want a full commit hash", head)
+	}
+	want := []RevisionFile{{Path: "README.md", Content: []byte("# hello\n")}, {Path: "rules.md", Content: []byte("# rules\n")}}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %+v, want %+v", files, want)
+	}
+
+	// Reading a revision leaves the working tree at HEAD
+	content, err := os.ReadFile(filepath.Join(reader, "README.md"))
+	if err != nil || string(content) != "# hello again\n" {
+		t.Errorf("working tree README.md = %q, %v", content, err)
+	}
+
+	_, files, err = ReadFilesAtRevision(reader, "HEAD", func(path string) bool { return path == "rules.md" })
+	if err != nil || len(files) != 1 || files[0].Path != "rules.md" {
+		t.Errorf("filtered files = %+v, %v", files, err)
+	}
+
+	if _, _, err := ReadFilesAtRevision(reader, "no-such-tag", all); err == nil {
+		t.Error("expected an error for an unknown revision")
+	}
+}
+
+func githubEntry(path string) RepositoryEntry {
+	url := "https://github.com/example/rules.git"
+	return RepositoryEntry{ID: "rules-1", Name: "Rules", Type: RepositoryTypeGitHub, Path: path, RemoteURL: &url}
+}
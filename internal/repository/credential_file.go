@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/adrg/xdg"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted credential file
+//
+// Headless Linux servers, containers and CI runners usually have no Secret
+// Service, so the OS credential store is unavailable and private repositories
+// cannot be cloned. When RULEM_CREDENTIALS_KEY is set, tokens the OS store
+// cannot hold are kept in a file instead (RULEM_CREDENTIALS_FILE, by default
+// credentials.enc next to config.yaml), encrypted with AES-256-GCM under a key
+// derived from the passphrase with scrypt. The OS store is still preferred
+// whenever it works.
+
+const (
+	// Environment variable holding the passphrase of the credential file
+	credentialsKeyEnv = "RULEM_CREDENTIALS_KEY"
+	// Environment variable overriding the location of the credential file
+	credentialsFileEnv = "RULEM_CREDENTIALS_FILE"
+
+	credentialFileVersion = 1
+)
+
+// credentialStore reads and writes secrets by service and key. Missing
+// entries are reported as keyring.ErrNotFound.
+type credentialStore interface {
+	Get(service, key string) (string, error)
+	Set(service, key, value string) error
+	Delete(service, key string) error
+}
+
+// keyringStore is the OS credential store
+type keyringStore struct{}
+
+func (keyringStore) Get(service, key string) (string, error) { return keyring.Get(service, key) }
+func (keyringStore) Set(service, key, value string) error    { return keyring.Set(service, key, value) }
+func (keyringStore) Delete(service, key string) error        { return keyring.Delete(service, key) }
+
+// defaultCredentialStore returns the OS credential store, falling back to the
+// encrypted credential file when RULEM_CREDENTIALS_KEY is set
+func defaultCredentialStore() credentialStore {
+	passphrase := os.Getenv(credentialsKeyEnv)
+	if passphrase == "" {
+		return keyringStore{}
+	}
+	return fallbackStore{
+		primary:  keyringStore{},
+		fallback: &fileStore{path: CredentialsFilePath(), passphrase: passphrase},
+	}
+}
+
+// CredentialsFilePath returns where the encrypted credential file is kept
+func CredentialsFilePath() string {
+	if path := os.Getenv(credentialsFileEnv); path != "" {
+		return path
+	}
+	return filepath.Join(xdg.ConfigHome, "rulem", "credentials.enc")
+}
+
+// fallbackStore keeps secrets in primary, or in fallback when primary fails
+type fallbackStore struct {
+	primary  credentialStore
+	fallback credentialStore
+}
+
+// Get prefers primary, so a secret written there later wins over one left in fallback
+func (s fallbackStore) Get(service, key string) (string, error) {
+	if value, err := s.primary.Get(service, key); err == nil {
+		return value, nil
+	}
+	return s.fallback.Get(service, key)
+}
+
+// Set writes to primary and removes the secret from fallback, or writes to
+// fallback when primary is unavailable
+func (s fallbackStore) Set(service, key, value string) error {
+	if err := s.primary.Set(service, key, value); err != nil {
+		return s.fallback.Set(service, key, value)
+	}
+	if err := s.fallback.Delete(service, key); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Delete removes the secret from both stores. A primary store that cannot be
+// reached does not fail the deletion.
+func (s fallbackStore) Delete(service, key string) error {
+	primaryErr := s.primary.Delete(service, key)
+	fallbackErr := s.fallback.Delete(service, key)
+	switch {
+	case fallbackErr != nil && !errors.Is(fallbackErr, keyring.ErrNotFound):
+		return fallbackErr
+	case primaryErr == nil || fallbackErr == nil:
+		return nil
+	default:
+		return keyring.ErrNotFound
+	}
+}
+
+// fileStoreMu serializes access to credential files within the process
+var fileStoreMu sync.Mutex
+
+// fileStore keeps secrets in a file encrypted with a passphrase
+type fileStore struct {
+	path       string
+	passphrase string
+}
+
+// credentialFile is the on-disk form of a fileStore: the secrets, as a JSON
+// object keyed by "service/key", sealed with AES-256-GCM
+type credentialFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`  // scrypt salt of the key
+	Nonce   []byte `json:"nonce"` // GCM nonce, new for every write
+	Data    []byte `json:"data"`  // sealed secrets
+}
+
+func (s *fileStore) Get(service, key string) (string, error) {
+	fileStoreMu.Lock()
+	defer fileStoreMu.Unlock()
+
+	secrets, _, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[service+"/"+key]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *fileStore) Set(service, key, value string) error {
+	fileStoreMu.Lock()
+	defer fileStoreMu.Unlock()
+
+	secrets, salt, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[service+"/"+key] = value
+	return s.save(secrets, salt)
+}
+
+func (s *fileStore) Delete(service, key string) error {
+	fileStoreMu.Lock()
+	defer fileStoreMu.Unlock()
+
+	secrets, salt, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[service+"/"+key]; !ok {
+		return keyring.ErrNotFound
+	}
+	delete(secrets, service+"/"+key)
+	return s.save(secrets, salt)
+}
+
+// load decrypts the credential file, returning no secrets and no salt when it does not exist yet
+func (s *fileStore) load() (map[string]string, []byte, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read credential file: %w", err)
+	}
+
+	var file credentialFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("credential file %s is corrupted: %w", s.path, err)
+	}
+	if file.Version != credentialFileVersion {
+		return nil, nil, fmt.Errorf("credential file %s has unsupported version %d", s.path, file.Version)
+	}
+
+	aead, err := s.cipher(file.Salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	plain, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decrypt credential file %s - check %s", s.path, credentialsKeyEnv)
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, nil, fmt.Errorf("credential file %s is corrupted: %w", s.path, err)
+	}
+	return secrets, file.Salt, nil
+}
+
+// save encrypts secrets under salt, or a new salt when nil, and replaces the
+// credential file atomically
+func (s *fileStore) save(secrets map[string]string, salt []byte) error {
+	if salt == nil {
+		salt = make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+	}
+	aead, err := s.cipher(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(credentialFile{
+		Version: credentialFileVersion,
+		Salt:    salt,
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, plain, nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create credential file directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".credentials-*")
+	if err != nil {
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	return nil
+}
+
+// cipher derives the AES-256-GCM cipher of the passphrase and salt
+func (s *fileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(s.passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive credential file key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
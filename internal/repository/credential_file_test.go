@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// unavailableStore fails like an OS credential store without a Secret Service
+type unavailableStore struct{}
+
+var errNoSecretService = errors.New("The name org.freedesktop.secrets was not provided")
+
+func (unavailableStore) Get(service, key string) (string, error) { return "", errNoSecretService }
+func (unavailableStore) Set(service, key, value string) error    { return errNoSecretService }
+func (unavailableStore) Delete(service, key string) error        { return errNoSecretService }
+
+func TestFileStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rulem", "credentials.enc")
+	store := &fileStore{path: path, passphrase: "correct horse"}
+	token := CreateTestToken("")
+
+	if _, err := store.Get("rulem", githubTokenKey); !errors.Is(err, keyring.ErrNotFound) {
+		t.Fatalf("Get() before any write = %v, want keyring.ErrNotFound", err)
+	}
+	if err := store.Set("rulem", githubTokenKey, token); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read credential file: %v", err)
+	}
+	if strings.Contains(string(data), token) {
+		t.Error("the credential file must not contain the token in plain text")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("credential file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	reopened := &fileStore{path: path, passphrase: "correct horse"}
+	if got, err := reopened.Get("rulem", githubTokenKey); err != nil || got != token {
+		t.Errorf("Get() = (%q, %v), want the stored token", got, err)
+	}
+
+	wrong := &fileStore{path: path, passphrase: "wrong"}
+	if _, err := wrong.Get("rulem", githubTokenKey); err == nil || !strings.Contains(err.Error(), credentialsKeyEnv) {
+		t.Errorf("Get() with the wrong passphrase = %v, want a decryption error", err)
+	}
+
+	if err := store.Delete("rulem", githubTokenKey); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete("rulem", githubTokenKey); !errors.Is(err, keyring.ErrNotFound) {
+		t.Errorf("second Delete() = %v, want keyring.ErrNotFound", err)
+	}
+}
+
+func TestCredentialManager_EncryptedFileFallback(t *testing.T) {
+	file := &fileStore{path: filepath.Join(t.TempDir(), "credentials.enc"), passphrase: "secret"}
+	cm := &CredentialManager{
+		service: "rulem-test",
+		store:   fallbackStore{primary: unavailableStore{}, fallback: file},
+	}
+	token := CreateTestToken("github_pat_")
+
+	if cm.HasGitHubToken() {
+		t.Fatal("no token should be stored yet")
+	}
+	if _, err := cm.GetGitHubToken(); err == nil || !strings.Contains(err.Error(), "no GitHub token found") {
+		t.Errorf("GetGitHubToken() = %v, want the not found guidance", err)
+	}
+
+	if err := cm.StoreGitHubToken(token); err != nil {
+		t.Fatalf("StoreGitHubToken: %v", err)
+	}
+	if got, err := cm.GetGitHubToken(); err != nil || got != token {
+		t.Errorf("GetGitHubToken() = (%q, %v), want the token from the file", got, err)
+	}
+	if status := cm.CheckKeyring(t.Context()); !status.Available {
+		t.Errorf("the credential file should count as available storage, got %+v", status)
+	}
+
+	if err := cm.DeleteGitHubToken(); err != nil {
+		t.Fatalf("DeleteGitHubToken: %v", err)
+	}
+	if cm.HasGitHubToken() {
+		t.Error("the token should be deleted from the file")
+	}
+}
+
+func TestFallbackStore_PrefersPrimary(t *testing.T) {
+	file := &fileStore{path: filepath.Join(t.TempDir(), "credentials.enc"), passphrase: "secret"}
+	service := "rulem-test-" + t.Name()
+	store := fallbackStore{primary: keyringStore{}, fallback: file}
+	t.Cleanup(func() { _ = keyring.Delete(service, githubTokenKey) })
+
+	if err := file.Set(service, githubTokenKey, "old"); err != nil {
+		t.Fatalf("seed file: %v", err)
+	}
+	if got, _ := store.Get(service, githubTokenKey); got != "old" {
+		t.Errorf("Get() = %q, want the file's token while the OS store has none", got)
+	}
+
+	if err := store.Set(service, githubTokenKey, "new"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, _ := store.Get(service, githubTokenKey); got != "new" {
+		t.Errorf("Get() = %q, want the OS store's token", got)
+	}
+	if _, err := file.Get(service, githubTokenKey); !errors.Is(err, keyring.ErrNotFound) {
+		t.Errorf("a token written to the OS store should be removed from the file, got %v", err)
+	}
+}
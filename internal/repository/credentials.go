@@ -3,12 +3,13 @@ package repository
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/go-git/go-git/v6"
 	"github.com/go-git/go-git/v6/config"
 	"github.com/go-git/go-git/v6/plumbing/client"
-	"github.com/go-git/go-git/v6/plumbing/transport/http"
 	"github.com/go-git/go-git/v6/storage/memory"
 	"github.com/zalando/go-keyring"
 )
@@ -16,19 +17,21 @@ import (
 const (
 	// Service name for OS credential store
 	credentialService = "rulem"
-	// Key for GitHub Personal Access Token
+	// Key for GitHub Personal Access Token (see Provider.tokenKey for the others)
 	githubTokenKey = "github_pat"
 )
 
 // CredentialManager handles secure storage and retrieval of authentication credentials
 type CredentialManager struct {
 	service string
+	store   credentialStore // OS credential store, see defaultCredentialStore
 }
 
 // NewCredentialManager creates a new credential manager instance
 func NewCredentialManager() *CredentialManager {
 	return &CredentialManager{
 		service: credentialService,
+		store:   defaultCredentialStore(),
 	}
 }
 
@@ -40,31 +43,45 @@ func NewCredentialManager() *CredentialManager {
 // Returns:
 //   - error: Validation errors if token format is invalid
 func (cm *CredentialManager) ValidateGitHubToken(token string) error {
+	return cm.ValidateToken(ProviderGitHub, token)
+}
+
+// ValidateToken validates the format of a token for provider without storing it.
+func (cm *CredentialManager) ValidateToken(provider Provider, token string) error {
 	if strings.TrimSpace(token) == "" {
 		return fmt.Errorf("token cannot be empty")
 	}
 
-	// Validate token format (GitHub PATs have specific prefixes)
-	if err := validateTokenFormat(token); err != nil {
+	// Validate token format (each provider's tokens have specific prefixes)
+	if err := provider.ValidateTokenFormat(token); err != nil {
 		return fmt.Errorf("invalid token format: %w", err)
 	}
 
 	return nil
 }
 
-// ValidateGitHubTokenWithRepo validates a GitHub Personal Access Token by attempting to access a repository.
+// ValidateTokenForRemote validates the format of a token for the provider
+// hosting repoURL, see DetectProvider.
+func (cm *CredentialManager) ValidateTokenForRemote(token string, repoURL string) error {
+	return cm.ValidateToken(DetectProvider(repoURL), token)
+}
+
+// ValidateGitHubTokenWithRepo validates a Personal Access Token by attempting to access a repository.
 // This verifies that the token is valid, not expired, and has access to the specified repository using go-git.
+// Despite its name, the token is validated for the provider hosting repoURL (see DetectProvider),
+// so GitLab and Bitbucket tokens are accepted for their repositories.
 //
 // Parameters:
 //   - ctx: Context for the operation
-//   - token: GitHub Personal Access Token to validate
+//   - token: Personal Access Token to validate
 //   - repoURL: Repository URL to validate access (required for validation)
 //
 // Returns:
 //   - error: Validation errors if token is invalid or doesn't have access to the repository
 func (cm *CredentialManager) ValidateGitHubTokenWithRepo(ctx context.Context, token string, repoURL string) error {
 	// First validate token format
-	if err := cm.ValidateGitHubToken(token); err != nil {
+	provider := DetectProvider(repoURL)
+	if err := cm.ValidateToken(provider, token); err != nil {
 		return err
 	}
 
@@ -80,10 +97,7 @@ func (cm *CredentialManager) ValidateGitHubTokenWithRepo(ctx context.Context, to
 	}
 
 	// Create authentication with the token
-	auth := &http.BasicAuth{
-		Username: "token",
-		Password: token,
-	}
+	auth := provider.basicAuth(strings.TrimSpace(token))
 
 	// Set a reasonable timeout for the operation
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, validationTimeout)
@@ -129,10 +143,11 @@ func (cm *CredentialManager) ValidateGitHubTokenWithRepo(ctx context.Context, to
 
 // ValidateGitHubTokenForRepos validates a GitHub Personal Access Token against all GitHub repositories.
 // It ensures at least one GitHub repository exists and validates access to each repo.
+// Remote repositories hosted on GitLab or Bitbucket use their own tokens and are not checked.
 func (cm *CredentialManager) ValidateGitHubTokenForRepos(ctx context.Context, token string, repos []RepositoryEntry) error {
 	var githubRepos []RepositoryEntry
 	for _, repo := range repos {
-		if repo.Type == RepositoryTypeGitHub {
+		if repo.Type == RepositoryTypeGitHub && DetectProvider(repo.GetRemoteURL()) == ProviderGitHub {
 			githubRepos = append(githubRepos, repo)
 		}
 	}
@@ -163,42 +178,186 @@ func (cm *CredentialManager) ValidateGitHubTokenForRepos(ctx context.Context, to
 // Returns:
 //   - error: Storage errors
 func (cm *CredentialManager) StoreGitHubToken(token string) error {
+	return cm.StoreToken(ProviderGitHub, token)
+}
+
+// StoreToken securely stores a token for provider in the OS credential store,
+// in an entry of its own. The token should be validated using ValidateToken first.
+func (cm *CredentialManager) StoreToken(provider Provider, token string) error {
 	// Store in OS credential store
-	if err := keyring.Set(cm.service, githubTokenKey, token); err != nil {
+	if err := cm.store.Set(cm.service, provider.tokenKey(), token); err != nil {
 		return fmt.Errorf("failed to store token in credential store: %w", err)
 	}
 
 	return nil
 }
 
+// StoreTokenForRemote stores a token for the provider hosting repoURL
+func (cm *CredentialManager) StoreTokenForRemote(repoURL string, token string) error {
+	return cm.StoreToken(DetectProvider(repoURL), token)
+}
+
 // GetGitHubToken retrieves the stored GitHub Personal Access Token from the OS credential store.
 //
 // Returns:
 //   - string: The stored Personal Access Token
 //   - error: Retrieval errors or if no token is stored
 func (cm *CredentialManager) GetGitHubToken() (string, error) {
-	token, err := keyring.Get(cm.service, githubTokenKey)
+	return cm.GetToken(ProviderGitHub)
+}
+
+// GetToken retrieves the stored token of provider from the OS credential store.
+func (cm *CredentialManager) GetToken(provider Provider) (string, error) {
+	token, err := cm.store.Get(cm.service, provider.tokenKey())
 	if err != nil {
 		if err == keyring.ErrNotFound {
-			return "", fmt.Errorf("no GitHub token found - please configure authentication in Settings → GitHub Authentication")
+			return "", fmt.Errorf("no %s token found - please %s", provider.DisplayName(), provider.authGuidance())
 		}
 		return "", fmt.Errorf("failed to retrieve token from credential store: %w", err)
 	}
 
 	if strings.TrimSpace(token) == "" {
-		return "", fmt.Errorf("stored token is empty - please update authentication in Settings → GitHub Authentication")
+		return "", fmt.Errorf("stored token is empty - please %s", provider.authGuidance())
 	}
 
 	return token, nil
 }
 
+// GetTokenForRemote retrieves the stored token of the provider hosting repoURL
+func (cm *CredentialManager) GetTokenForRemote(repoURL string) (string, error) {
+	return cm.GetToken(DetectProvider(repoURL))
+}
+
+// Named tokens
+//
+// Repositories that need different tokens, e.g. a personal repository and one
+// behind an organization's SSO, can each have a token of their own, or share
+// one per host. Named tokens are stored next to the provider's default token
+// under "<default entry>:<name>", where the name comes from RepositoryTokenName
+// or HostTokenName. LookupToken tries them from the most specific name and
+// falls back to the default token.
+
+// RepositoryTokenName names the token used only for the repository with repositoryID
+func RepositoryTokenName(repositoryID string) string {
+	return "repo:" + strings.TrimSpace(repositoryID)
+}
+
+// HostTokenName names the token used for the repositories on host without a token of their own
+func HostTokenName(host string) string {
+	return "host:" + strings.ToLower(strings.TrimSpace(host))
+}
+
+// namedTokenKey returns the credential store entry of the provider's token named name
+func namedTokenKey(provider Provider, name string) string {
+	return provider.tokenKey() + ":" + name
+}
+
+// StoreNamedToken stores a token for provider under name, see RepositoryTokenName
+// and HostTokenName. The token should be validated using ValidateToken first.
+func (cm *CredentialManager) StoreNamedToken(provider Provider, name, token string) error {
+	if err := cm.store.Set(cm.service, namedTokenKey(provider, name), token); err != nil {
+		return fmt.Errorf("failed to store token in credential store: %w", err)
+	}
+	return nil
+}
+
+// GetNamedToken retrieves the token of provider stored under name.
+func (cm *CredentialManager) GetNamedToken(provider Provider, name string) (string, error) {
+	token, err := cm.store.Get(cm.service, namedTokenKey(provider, name))
+	if err != nil {
+		if err == keyring.ErrNotFound {
+			return "", fmt.Errorf("no %s token stored for %s", provider.DisplayName(), name)
+		}
+		return "", fmt.Errorf("failed to retrieve token from credential store: %w", err)
+	}
+
+	if strings.TrimSpace(token) == "" {
+		return "", fmt.Errorf("stored token for %s is empty - please store it again", name)
+	}
+
+	return token, nil
+}
+
+// HasNamedToken checks if a token of provider is stored under name without retrieving it.
+func (cm *CredentialManager) HasNamedToken(provider Provider, name string) bool {
+	_, err := cm.store.Get(cm.service, namedTokenKey(provider, name))
+	return err == nil
+}
+
+// DeleteNamedToken removes the token of provider stored under name (returns nil if it doesn't exist).
+func (cm *CredentialManager) DeleteNamedToken(provider Provider, name string) error {
+	err := cm.store.Delete(cm.service, namedTokenKey(provider, name))
+	if err != nil && err != keyring.ErrNotFound {
+		return fmt.Errorf("failed to delete token from credential store: %w", err)
+	}
+	return nil
+}
+
+// DeleteRepositoryToken removes the token stored for the repository with
+// repositoryID hosted at repoURL, e.g. when the repository is removed.
+func (cm *CredentialManager) DeleteRepositoryToken(repoURL, repositoryID string) error {
+	return cm.DeleteNamedToken(DetectProvider(repoURL), RepositoryTokenName(repositoryID))
+}
+
+// RenameRepositoryToken moves the tokens stored for the repository with oldID
+// to newID, for every provider, e.g. when its ID is migrated. A token already
+// stored for newID is kept. Returns whether a token was moved.
+func (cm *CredentialManager) RenameRepositoryToken(oldID, newID string) (bool, error) {
+	moved := false
+	for _, provider := range []Provider{ProviderGitHub, ProviderGitLab, ProviderBitbucket} {
+		oldName, newName := RepositoryTokenName(oldID), RepositoryTokenName(newID)
+		if !cm.HasNamedToken(provider, oldName) {
+			continue
+		}
+		if !cm.HasNamedToken(provider, newName) {
+			token, err := cm.GetNamedToken(provider, oldName)
+			if err != nil {
+				return moved, err
+			}
+			if err := cm.StoreNamedToken(provider, newName, token); err != nil {
+				return moved, err
+			}
+			moved = true
+		}
+		if err := cm.DeleteNamedToken(provider, oldName); err != nil {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// LookupToken returns the token of provider stored under the first of names
+// that has one, or else the provider's default token, along with the name it
+// was found under (empty for the default token). It returns an empty token
+// and no error when no token is stored at all.
+func (cm *CredentialManager) LookupToken(provider Provider, names ...string) (token, name string, err error) {
+	for _, name := range names {
+		if !cm.HasNamedToken(provider, name) {
+			continue
+		}
+		token, err := cm.GetNamedToken(provider, name)
+		return token, name, err
+	}
+
+	if !cm.HasToken(provider) {
+		return "", "", nil
+	}
+	token, err = cm.GetToken(provider)
+	return token, "", err
+}
+
 // DeleteGitHubToken removes the stored GitHub Personal Access Token from the OS credential store.
 // This is useful for token rotation or when switching authentication methods.
 //
 // Returns:
 //   - error: Deletion errors (returns nil if token doesn't exist)
 func (cm *CredentialManager) DeleteGitHubToken() error {
-	err := keyring.Delete(cm.service, githubTokenKey)
+	return cm.DeleteToken(ProviderGitHub)
+}
+
+// DeleteToken removes the stored token of provider from the OS credential store.
+func (cm *CredentialManager) DeleteToken(provider Provider) error {
+	err := cm.store.Delete(cm.service, provider.tokenKey())
 	if err != nil && err != keyring.ErrNotFound {
 		return fmt.Errorf("failed to delete token from credential store: %w", err)
 	}
@@ -211,7 +370,12 @@ func (cm *CredentialManager) DeleteGitHubToken() error {
 // Returns:
 //   - bool: true if a token is stored, false otherwise
 func (cm *CredentialManager) HasGitHubToken() bool {
-	_, err := keyring.Get(cm.service, githubTokenKey)
+	return cm.HasToken(ProviderGitHub)
+}
+
+// HasToken checks if a token is stored for provider without retrieving it.
+func (cm *CredentialManager) HasToken(provider Provider) bool {
+	_, err := cm.store.Get(cm.service, provider.tokenKey())
 	return err == nil
 }
 
@@ -289,7 +453,7 @@ func (cm *CredentialManager) GetCredentialStoreStatus() map[string]any {
 	testValue := "test_value"
 
 	// Try to set a test value
-	setErr := keyring.Set(cm.service, testKey, testValue)
+	setErr := cm.store.Set(cm.service, testKey, testValue)
 	if setErr != nil {
 		status["available"] = false
 		status["error"] = setErr.Error()
@@ -297,12 +461,12 @@ func (cm *CredentialManager) GetCredentialStoreStatus() map[string]any {
 	}
 
 	// Try to get the test value
-	retrievedValue, getErr := keyring.Get(cm.service, testKey)
+	retrievedValue, getErr := cm.store.Get(cm.service, testKey)
 	if getErr != nil {
 		status["available"] = false
 		status["error"] = getErr.Error()
 		// Clean up test key
-		keyring.Delete(cm.service, testKey)
+		cm.store.Delete(cm.service, testKey)
 		return status
 	}
 
@@ -311,12 +475,12 @@ func (cm *CredentialManager) GetCredentialStoreStatus() map[string]any {
 		status["available"] = false
 		status["error"] = "credential store corrupted - values don't match"
 		// Clean up test key
-		keyring.Delete(cm.service, testKey)
+		cm.store.Delete(cm.service, testKey)
 		return status
 	}
 
 	// Clean up test key
-	deleteErr := keyring.Delete(cm.service, testKey)
+	deleteErr := cm.store.Delete(cm.service, testKey)
 	if deleteErr != nil {
 		status["available"] = true
 		status["warning"] = "credential store works but cleanup failed: " + deleteErr.Error()
@@ -327,3 +491,114 @@ func (cm *CredentialManager) GetCredentialStoreStatus() map[string]any {
 	status["error"] = nil
 	return status
 }
+
+// KeyringStatus is the outcome of a credential store heartbeat, see CheckKeyring
+type KeyringStatus struct {
+	Checked   bool   // False until a heartbeat has completed
+	Available bool   // The credential store accepted a write, read and delete
+	Error     string // Why the store is unavailable (empty when available)
+	Guidance  string // What to do about it on this platform (empty when available)
+}
+
+// CheckKeyring probes the OS credential store without failing: it writes,
+// reads back and deletes a throwaway entry, giving up after
+// keyringProbeTimeout since some stores block on an unlock prompt. It is run
+// at startup so GitHub features can warn early instead of failing deep in a
+// settings flow when a PAT is stored. With RULEM_CREDENTIALS_KEY set, the
+// encrypted credential file counts as available storage.
+func (cm *CredentialManager) CheckKeyring(ctx context.Context) KeyringStatus {
+	return checkKeyringWith(ctx, func() error { return probeKeyring(cm.store, cm.service) }, runtime.GOOS)
+}
+
+// checkKeyringWith runs probe with a timeout and describes its outcome for goos
+func checkKeyringWith(ctx context.Context, probe func() error, goos string) KeyringStatus {
+	ctx, cancel := context.WithTimeout(ctx, keyringProbeTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- probe() }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("credential store did not respond: %w", ctx.Err())
+	}
+
+	if err != nil {
+		return KeyringStatus{
+			Checked:  true,
+			Error:    err.Error(),
+			Guidance: KeyringGuidance(goos),
+		}
+	}
+	return KeyringStatus{Checked: true, Available: true}
+}
+
+// probeKeyring round-trips a throwaway value through the credential store
+func probeKeyring(store credentialStore, service string) error {
+	const probeKey, probeValue = "rulem_heartbeat", "ok"
+
+	if err := store.Set(service, probeKey, probeValue); err != nil {
+		return err
+	}
+	defer store.Delete(service, probeKey)
+
+	got, err := store.Get(service, probeKey)
+	if err != nil {
+		return err
+	}
+	if got != probeValue {
+		return fmt.Errorf("credential store corrupted - values don't match")
+	}
+	return nil
+}
+
+// KeyringGuidance explains how to make the credential store available on goos
+// (a runtime.GOOS value)
+func KeyringGuidance(goos string) string {
+	switch goos {
+	case "darwin":
+		return "Open Keychain Access and make sure the login keychain exists and is unlocked."
+	case "windows":
+		return "Make sure the Credential Manager service is running (services.msc) and that your account can use it."
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "Install and unlock a Secret Service provider such as GNOME Keyring or KWallet. " +
+			"On headless machines, run rulem inside a D-Bus session (dbus-run-session) with gnome-keyring-daemon started."
+	default:
+		return "No supported credential store was found for this platform; GitHub PATs cannot be saved."
+	}
+}
+
+// oneShot holds a token supplied for a single clone or fetch, see UseTokenOnce
+var oneShot struct {
+	mu    sync.Mutex
+	token string
+}
+
+// UseTokenOnce makes the next clone or fetch that needs authentication use
+// token instead of the PAT in the credential store. The token only lives in
+// memory: it is never written to the credential store or the config, and it is
+// forgotten as soon as an operation has taken it. Calling it again replaces a
+// token that has not been used yet; an empty token clears it.
+func UseTokenOnce(token string) {
+	oneShot.mu.Lock()
+	defer oneShot.mu.Unlock()
+	oneShot.token = strings.TrimSpace(token)
+}
+
+// HasOneShotToken reports whether a token passed to UseTokenOnce is still waiting to be used
+func HasOneShotToken() bool {
+	oneShot.mu.Lock()
+	defer oneShot.mu.Unlock()
+	return oneShot.token != ""
+}
+
+// takeOneShotToken returns the pending one-shot token, if any, and forgets it
+func takeOneShotToken() string {
+	oneShot.mu.Lock()
+	defer oneShot.mu.Unlock()
+	token := oneShot.token
+	oneShot.token = ""
+	return token
+}
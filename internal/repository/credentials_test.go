@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -428,3 +430,176 @@ func TestCredentialManager_KeyringUnavailable(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckKeyringWith(t *testing.T) {
+	// release unblocks the probe that never answers once the test is done
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := []struct {
+		name          string
+		probe         func() error
+		canceled      bool
+		wantAvailable bool
+		wantError     string
+	}{
+		{
+			name:          "available",
+			probe:         func() error { return nil },
+			wantAvailable: true,
+		},
+		{
+			name:      "probe fails",
+			probe:     func() error { return errors.New("The name org.freedesktop.secrets was not provided") },
+			wantError: "org.freedesktop.secrets",
+		},
+		{
+			name:      "canceled before the store answers",
+			probe:     func() error { <-release; return nil },
+			canceled:  true,
+			wantError: "did not respond",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.canceled {
+				cancel()
+			}
+			defer cancel()
+
+			got := checkKeyringWith(ctx, tt.probe, "linux")
+			if !got.Checked {
+				t.Error("status should be marked as checked")
+			}
+			if got.Available != tt.wantAvailable {
+				t.Errorf("Available = %v, want %v", got.Available, tt.wantAvailable)
+			}
+			if tt.wantAvailable {
+				if got.Error != "" || got.Guidance != "" {
+					t.Errorf("available store should have no error or guidance, got %+v", got)
+				}
+				return
+			}
+			if !strings.Contains(got.Error, tt.wantError) {
+				t.Errorf("Error = %q, want it to contain %q", got.Error, tt.wantError)
+			}
+			if got.Guidance != KeyringGuidance("linux") {
+				t.Errorf("Guidance = %q, want the linux guidance", got.Guidance)
+			}
+		})
+	}
+}
+
+func TestKeyringGuidance(t *testing.T) {
+	tests := []struct {
+		goos string
+		want string
+	}{
+		{goos: "darwin", want: "Keychain Access"},
+		{goos: "windows", want: "Credential Manager"},
+		{goos: "linux", want: "Secret Service"},
+		{goos: "freebsd", want: "Secret Service"},
+		{goos: "plan9", want: "No supported credential store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			if got := KeyringGuidance(tt.goos); !strings.Contains(got, tt.want) {
+				t.Errorf("KeyringGuidance(%q) = %q, want it to mention %q", tt.goos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseTokenOnce(t *testing.T) {
+	t.Cleanup(func() { UseTokenOnce("") })
+	token := CreateTestToken("")
+	gs := NewGitSource("https://github.com/user/private.git", nil, t.TempDir())
+
+	UseTokenOnce("  " + token + "\n")
+	if !HasOneShotToken() {
+		t.Fatal("expected a pending one-shot token")
+	}
+
+	auth, err := gs.getAuthentication(nil)
+	if err != nil {
+		t.Fatalf("getAuthentication() error = %v", err)
+	}
+	if auth == nil || auth.Password != token || auth.Username != "token" {
+		t.Fatalf("getAuthentication() = %+v, want the one-shot token", auth)
+	}
+
+	// The token is forgotten once used; later operations fall back to the
+	// credential store (which may or may not hold a PAT on this machine)
+	if HasOneShotToken() {
+		t.Error("one-shot token should be forgotten after use")
+	}
+	if auth, _ := gs.getAuthentication(nil); auth != nil && auth.Password == token {
+		t.Error("one-shot token should not be used twice")
+	}
+}
+
+func TestCredentialManager_LookupToken(t *testing.T) {
+	testCM := NewTestCredentialManager(t)
+	repoName, hostName := RepositoryTokenName("work-rules"), HostTokenName("GitHub.com")
+	t.Cleanup(func() {
+		_ = testCM.DeleteNamedToken(ProviderGitHub, repoName)
+		_ = testCM.DeleteNamedToken(ProviderGitHub, hostName)
+	})
+
+	if token, name, err := testCM.LookupToken(ProviderGitHub, repoName, hostName); err != nil || token != "" || name != "" {
+		t.Fatalf("LookupToken() with nothing stored = (%q, %q, %v), want no token", token, name, err)
+	}
+
+	steps := []struct {
+		store    string // name to store a token under, empty for the default token
+		wantName string
+	}{
+		{store: "", wantName: ""},
+		{store: hostName, wantName: "host:github.com"},
+		{store: repoName, wantName: "repo:work-rules"},
+	}
+	for _, step := range steps {
+		token := CreateTestToken("github_pat_") + step.store
+		var err error
+		if step.store == "" {
+			err = testCM.StoreGitHubToken(token)
+		} else {
+			err = testCM.StoreNamedToken(ProviderGitHub, step.store, token)
+		}
+		if err != nil {
+			t.Fatalf("store %q: %v", step.store, err)
+		}
+
+		got, name, err := testCM.LookupToken(ProviderGitHub, repoName, hostName)
+		if err != nil || got != token || name != step.wantName {
+			t.Errorf("after storing %q LookupToken() = (%q, %q, %v), want (%q, %q)", step.store, got, name, err, token, step.wantName)
+		}
+	}
+
+	// Other repositories on the host use the host's token
+	if _, name, _ := testCM.LookupToken(ProviderGitHub, RepositoryTokenName("personal"), hostName); name != hostName {
+		t.Errorf("expected the host token for another repository, got %q", name)
+	}
+
+	if err := testCM.DeleteRepositoryToken("https://github.com/acme/rules.git", "work-rules"); err != nil {
+		t.Fatalf("DeleteRepositoryToken: %v", err)
+	}
+	if testCM.HasNamedToken(ProviderGitHub, repoName) {
+		t.Error("the repository's token should be deleted")
+	}
+}
+
+func TestGitSource_TokenNames(t *testing.T) {
+	gs := NewGitSource("git@github.com:acme/rules.git", nil, t.TempDir())
+	if got := strings.Join(gs.tokenNames(), ","); got != "host:github.com" {
+		t.Errorf("tokenNames() = %q, want the host only", got)
+	}
+
+	gs.RepositoryID = "work-rules"
+	if got := strings.Join(gs.tokenNames(), ","); got != "repo:work-rules,host:github.com" {
+		t.Errorf("tokenNames() = %q, want the repository before the host", got)
+	}
+}
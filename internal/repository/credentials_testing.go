@@ -86,6 +86,7 @@ func NewTestCredentialManager(t *testing.T) *TestCredentialManager {
 	cm := &TestCredentialManager{
 		CredentialManager: &CredentialManager{
 			service: testService,
+			store:   keyringStore{},
 		},
 		testService: testService,
 		t:           t,
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// GitHub device flow login
+//
+// Creating a PAT by hand means picking scopes on a settings page, which is
+// easy to get wrong. The device authorization grant lets the user sign in
+// with a browser instead: rulem shows a short code, the user enters it at
+// github.com/login/device and approves rulem, and GitHub hands rulem an OAuth
+// token that is stored like a PAT. The OAuth app is identified by its client
+// ID, built in with -ldflags or taken from RULEM_GITHUB_CLIENT_ID.
+
+// GitHubClientID is the client ID of rulem's GitHub OAuth app (set via
+// -ldflags at release time; RULEM_GITHUB_CLIENT_ID overrides it)
+var GitHubClientID = ""
+
+const (
+	// Environment variable overriding GitHubClientID
+	githubClientIDEnv = "RULEM_GITHUB_CLIENT_ID"
+
+	githubLoginURL = "https://github.com"
+
+	deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// Poll interval used when GitHub does not send one, and added on slow_down
+	defaultDeviceInterval = 5 * time.Second
+)
+
+// DeviceLoginScopes are the scopes requested by the device flow: read and
+// write access to private repositories, as a PAT for rulem needs
+var DeviceLoginScopes = []string{"repo"}
+
+var (
+	// ErrDeviceLoginDenied is returned when the user cancels the authorization
+	ErrDeviceLoginDenied = errors.New("the authorization was denied in the browser")
+	// ErrDeviceLoginExpired is returned when the code was not entered in time
+	ErrDeviceLoginExpired = errors.New("the code expired before it was entered - start the sign in again")
+)
+
+// DeviceFlow signs in to GitHub with the OAuth device authorization grant
+type DeviceFlow struct {
+	ClientID   string
+	BaseURL    string       // GitHub's web host, https://github.com unless testing
+	HTTPClient *http.Client // http.DefaultClient when nil
+}
+
+// DeviceCode is the code the user enters to authorize rulem
+type DeviceCode struct {
+	DeviceCode      string        // Identifies the sign in when polling, never shown
+	UserCode        string        // Code the user enters, e.g. "WDJB-MJHT"
+	VerificationURI string        // Page where the code is entered
+	ExpiresAt       time.Time     // When the code stops working
+	Interval        time.Duration // Minimum time between polls
+}
+
+// DeviceToken is the token obtained by a completed device flow
+type DeviceToken struct {
+	AccessToken string
+	Scopes      []string // Scopes granted by the user, which may differ from those requested
+}
+
+// NewGitHubDeviceFlow returns the device flow of rulem's GitHub OAuth app,
+// failing when no client ID is configured
+func NewGitHubDeviceFlow() (*DeviceFlow, error) {
+	clientID := GitHubClientID
+	if id := strings.TrimSpace(os.Getenv(githubClientIDEnv)); id != "" {
+		clientID = id
+	}
+	if clientID == "" {
+		return nil, fmt.Errorf("browser sign in is not available in this build - set %s to the client ID of a GitHub OAuth app with device flow enabled, or use a PAT", githubClientIDEnv)
+	}
+	return &DeviceFlow{ClientID: clientID, BaseURL: githubLoginURL}, nil
+}
+
+// Start requests a code for the user to enter at VerificationURI
+func (f *DeviceFlow) Start(ctx context.Context, scopes []string) (DeviceCode, error) {
+	var resp struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURI string `json:"verification_uri"`
+		ExpiresIn       int    `json:"expires_in"`
+		Interval        int    `json:"interval"`
+		deviceError
+	}
+	err := f.post(ctx, "/login/device/code", url.Values{
+		"client_id": {f.ClientID},
+		"scope":     {strings.Join(scopes, " ")},
+	}, &resp)
+	if err != nil {
+		return DeviceCode{}, fmt.Errorf("failed to start GitHub sign in: %w", err)
+	}
+	if resp.Code != "" {
+		return DeviceCode{}, fmt.Errorf("failed to start GitHub sign in: %w", resp.deviceError)
+	}
+
+	code := DeviceCode{
+		DeviceCode:      resp.DeviceCode,
+		UserCode:        resp.UserCode,
+		VerificationURI: resp.VerificationURI,
+		ExpiresAt:       time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second),
+		Interval:        time.Duration(resp.Interval) * time.Second,
+	}
+	if code.Interval <= 0 {
+		code.Interval = defaultDeviceInterval
+	}
+	return code, nil
+}
+
+// Wait polls GitHub until the user authorizes or denies the code, the code
+// expires or ctx is cancelled
+func (f *DeviceFlow) Wait(ctx context.Context, code DeviceCode) (DeviceToken, error) {
+	interval := code.Interval
+	for {
+		select {
+		case <-ctx.Done():
+			return DeviceToken{}, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		var resp struct {
+			AccessToken string `json:"access_token"`
+			Scope       string `json:"scope"`
+			Interval    int    `json:"interval"`
+			deviceError
+		}
+		err := f.post(ctx, "/login/oauth/access_token", url.Values{
+			"client_id":   {f.ClientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {deviceGrantType},
+		}, &resp)
+		if err != nil {
+			return DeviceToken{}, fmt.Errorf("failed to complete GitHub sign in: %w", err)
+		}
+
+		switch resp.Code {
+		case "":
+			if resp.AccessToken == "" {
+				return DeviceToken{}, fmt.Errorf("GitHub returned no token")
+			}
+			return DeviceToken{AccessToken: resp.AccessToken, Scopes: splitScopes(resp.Scope)}, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += defaultDeviceInterval
+			if resp.Interval > 0 {
+				interval = time.Duration(resp.Interval) * time.Second
+			}
+		case "expired_token":
+			return DeviceToken{}, ErrDeviceLoginExpired
+		case "access_denied":
+			return DeviceToken{}, ErrDeviceLoginDenied
+		default:
+			return DeviceToken{}, fmt.Errorf("failed to complete GitHub sign in: %w", resp.deviceError)
+		}
+	}
+}
+
+// deviceError is the error part of GitHub's OAuth responses
+type deviceError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e deviceError) Error() string {
+	if e.Description != "" {
+		return e.Description
+	}
+	return e.Code
+}
+
+// post sends form to the OAuth endpoint at path and decodes the JSON reply into out
+func (f *DeviceFlow) post(ctx context.Context, path string, form url.Values, out any) error {
+	base := f.BaseURL
+	if base == "" {
+		base = githubLoginURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(base, "/")+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := f.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// splitScopes parses the comma-separated scope list of a token response
+func splitScopes(scope string) []string {
+	var scopes []string
+	for s := range strings.SplitSeq(scope, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
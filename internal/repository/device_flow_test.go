@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeGitHubOAuth serves the device flow endpoints, answering token polls
+// with the given responses in order
+func fakeGitHubOAuth(t *testing.T, polls ...map[string]any) (*DeviceFlow, *atomic.Int32) {
+	t.Helper()
+	var count atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login/device/code", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_id") != "test-client" || r.FormValue("scope") != "repo" {
+			t.Errorf("unexpected device code request: %v", r.Form)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "device-123",
+			"user_code":        "WDJB-MJHT",
+			"verification_uri": "https://github.com/login/device",
+			"expires_in":       900,
+			"interval":         5,
+		})
+	})
+	mux.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("device_code") != "device-123" || r.FormValue("grant_type") != deviceGrantType {
+			t.Errorf("unexpected token request: %v", r.Form)
+		}
+		n := int(count.Add(1)) - 1
+		if n >= len(polls) {
+			t.Errorf("unexpected poll %d", n+1)
+			n = len(polls) - 1
+		}
+		_ = json.NewEncoder(w).Encode(polls[n])
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return &DeviceFlow{ClientID: "test-client", BaseURL: server.URL}, &count
+}
+
+func TestDeviceFlow_Login(t *testing.T) {
+	flow, polls := fakeGitHubOAuth(t,
+		map[string]any{"error": "authorization_pending"},
+		map[string]any{"access_token": "gho_abcdefghijklmnopqrstuvwxyz", "scope": "repo,read:org", "token_type": "bearer"},
+	)
+
+	code, err := flow.Start(t.Context(), DeviceLoginScopes)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if code.UserCode != "WDJB-MJHT" || code.VerificationURI != "https://github.com/login/device" || code.Interval != 5*time.Second {
+		t.Errorf("unexpected code %+v", code)
+	}
+
+	code.Interval = time.Millisecond
+	token, err := flow.Wait(t.Context(), code)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if token.AccessToken != "gho_abcdefghijklmnopqrstuvwxyz" || !slices.Equal(token.Scopes, []string{"repo", "read:org"}) {
+		t.Errorf("unexpected token %+v", token)
+	}
+	if polls.Load() != 2 {
+		t.Errorf("polls = %d, want 2", polls.Load())
+	}
+}
+
+func TestDeviceFlow_WaitErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		poll map[string]any
+		want error
+	}{
+		{name: "denied", poll: map[string]any{"error": "access_denied"}, want: ErrDeviceLoginDenied},
+		{name: "expired", poll: map[string]any{"error": "expired_token"}, want: ErrDeviceLoginExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow, _ := fakeGitHubOAuth(t, tt.poll)
+			_, err := flow.Wait(t.Context(), DeviceCode{DeviceCode: "device-123", Interval: time.Millisecond})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Wait() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	flow, _ := fakeGitHubOAuth(t, map[string]any{"error": "incorrect_client_credentials", "error_description": "The client_id is not valid."})
+	if _, err := flow.Wait(t.Context(), DeviceCode{DeviceCode: "device-123", Interval: time.Millisecond}); err == nil || !strings.Contains(err.Error(), "client_id is not valid") {
+		t.Errorf("Wait() = %v, want GitHub's error description", err)
+	}
+}
+
+func TestNewGitHubDeviceFlow_RequiresClientID(t *testing.T) {
+	t.Setenv(githubClientIDEnv, "")
+	if _, err := NewGitHubDeviceFlow(); err == nil || !strings.Contains(err.Error(), githubClientIDEnv) {
+		t.Errorf("NewGitHubDeviceFlow() = %v, want an error naming %s", err, githubClientIDEnv)
+	}
+
+	t.Setenv(githubClientIDEnv, "Iv1.abc")
+	flow, err := NewGitHubDeviceFlow()
+	if err != nil || flow.ClientID != "Iv1.abc" {
+		t.Errorf("NewGitHubDeviceFlow() = (%+v, %v), want the client ID from the environment", flow, err)
+	}
+}
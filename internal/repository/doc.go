@@ -19,11 +19,14 @@
 //   - GitSource: Handles Git clone/sync operations with authentication
 //   - CredentialManager: Secure GitHub PAT management via OS credential store
 //
-// Operations (preparation.go, validation.go, sync.go):
+// Operations (preparation.go, validation.go, sync.go, maintenance.go, push.go, relocation.go):
 //   - PrepareRepository: Prepares a single repository for use
 //   - PrepareAllRepositories: Orchestrates multi-repository preparation
 //   - ValidateRepositoryEntry: Validates repository configuration
 //   - SyncAllRepositories: Synchronizes all GitHub repositories
+//   - RunMaintenance: Repacks a clone and prunes unreachable objects (also run by sync when due)
+//   - GitSource.PushChanges: Commits local edits of a clone and pushes them to origin
+//   - PlanRelocation / Relocate: Moves every repository to a new base directory
 //
 // Utilities (defaults.go, setup.go):
 //   - GetDefaultStorageDir: Returns default repository storage location
@@ -160,6 +163,8 @@
 //   - OS credential store integration using go-keyring library
 //   - Cross-platform support (Keychain on macOS, libsecret on Linux, Credential Manager on Windows)
 //   - Token format validation for GitHub PATs (ghp_, github_pat_, gho_, ghu_, ghs_ prefixes)
+//   - Named tokens per repository or host, falling back to the default token (see LookupToken)
+//   - Encrypted credential file fallback when RULEM_CREDENTIALS_KEY is set (credential_file.go)
 //   - Graceful degradation when OS credential store is unavailable
 //
 // Credentials are stored securely in the OS credential store with service name "rulem" and key "github_pat".
@@ -215,7 +220,7 @@
 // **Validation (validation.go):**
 //   - ValidateRepositoryEntry: Structural validation for single entries
 //   - ValidateAllRepositories: Batch validation with uniqueness checks
-//   - ID format: "sanitized-name-timestamp" pattern enforcement
+//   - ID format: "sanitized-name-suffix" pattern enforcement
 //   - Name uniqueness: Case-insensitive duplicate detection
 //   - Type-specific validation: GitHub repos must have RemoteURL, etc.
 //
@@ -250,9 +255,11 @@
 //
 // **Conflict Resolution Strategy:**
 //   - If target directory exists, validate it's a git repository with the same remote URL
-//   - If not the same repository, error and ask user to resolve manually
-//   - If directory contains non-git content, require manual cleanup
-//   - This approach prioritizes safety and user control over automatic conflict resolution
+//   - If not the same repository, or the directory contains non-git content,
+//     return a DirectoryConflictError
+//   - The user resolves it with GitSource.ResolveDirectoryConflict: back the
+//     directory up to a timestamped sibling and re-clone, adopt the existing
+//     files as local changes, or abort
 //   - No automatic deletion or overwriting of existing user data
 //
 // The package maintains the security-first approach established in the application architecture,
@@ -294,12 +301,14 @@
 //   - local.go: LocalSource implementation
 //   - git.go: GitSource with Git operations
 //   - credentials.go: Secure credential management
+//   - device_flow.go: GitHub sign in with the OAuth device flow
 //
 // Operations:
 //   - preparation.go: Single repository preparation
 //   - validation.go: Repository validation logic
 //   - multi.go: Multi-repository orchestration
 //   - sync.go: Repository synchronization
+//   - template.go: New repositories created from a template
 //
 // Utilities:
 //   - defaults.go: Default paths and constants
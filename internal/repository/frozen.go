@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/client"
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+// Frozen checkouts
+//
+// Instead of tracking a branch, a GitHub repository can be frozen at a tag or
+// a commit, so a team serves an audited version of its rules until the
+// setting is changed. The clone is checked out detached at that commit. Sync
+// fetches only the tag or commit and moves HEAD when the setting changed, or
+// when the tag was moved on the remote.
+//
+// Unlike expected_commit and expected_tag (see pin.go), which refuse a clone
+// that is not on the expected commit, tag and commit decide which commit is
+// checked out. Both can be combined to freeze at a tag and refuse it if it moves.
+
+// frozenCommitRef is the ref a frozen commit is fetched into, keeping it
+// reachable for maintenance
+const frozenCommitRef = plumbing.ReferenceName("refs/rulem/frozen")
+
+// GetTag returns the tag the repository is frozen at, or empty string.
+func (r RepositoryEntry) GetTag() string {
+	if r.Tag != nil {
+		return *r.Tag
+	}
+	return ""
+}
+
+// GetCommit returns the commit the repository is frozen at, or empty string.
+func (r RepositoryEntry) GetCommit() string {
+	if r.Commit != nil {
+		return *r.Commit
+	}
+	return ""
+}
+
+// IsFrozen reports whether r checks out a tag or commit instead of a branch
+func (r RepositoryEntry) IsFrozen() bool {
+	return r.Tag != nil || r.Commit != nil
+}
+
+// validateFrozen checks the tag and commit settings of a GitHub repository
+func (r RepositoryEntry) validateFrozen() error {
+	if r.Tag != nil && r.Commit != nil {
+		return fmt.Errorf("tag and commit cannot both be set")
+	}
+	if r.IsFrozen() && r.Branch != nil {
+		return fmt.Errorf("branch cannot be set together with a tag or commit")
+	}
+	if r.Tag != nil {
+		tag := strings.TrimSpace(*r.Tag)
+		if tag == "" {
+			return fmt.Errorf("tag cannot be empty string (use nil to track a branch)")
+		}
+		if err := plumbing.NewTagReferenceName(tag).Validate(); err != nil {
+			return fmt.Errorf("invalid tag name %q: %w", tag, err)
+		}
+	}
+	// Only a full hash can be fetched without the history leading to it
+	if r.Commit != nil && (len(*r.Commit) != 40 || !isCommitPrefix(*r.Commit)) {
+		return fmt.Errorf("commit must be a full commit hash of 40 lowercase hex characters, got %q", *r.Commit)
+	}
+	if r.IsFrozen() && len(r.Worktrees) > 0 {
+		return fmt.Errorf("worktrees cannot be used with a tag or commit")
+	}
+	return nil
+}
+
+// frozen reports whether gs checks out a tag or commit instead of a branch
+func (gs GitSource) frozen() bool {
+	return gs.Tag != "" || gs.Commit != ""
+}
+
+// checkoutFrozen fetches the tag or commit gs is frozen at and checks it out
+// detached. A commit already in the clone is not fetched again; a tag is
+// always fetched, so a tag moved on the remote is followed. The caller must
+// have checked that the working tree is clean.
+func (gs GitSource) checkoutFrozen(ctx context.Context, repo *git.Repository, auth *http.BasicAuth, logger *logging.AppLogger) error {
+	var refSpec config.RefSpec
+	if gs.Tag != "" {
+		tagRef := plumbing.NewTagReferenceName(gs.Tag)
+		refSpec = config.RefSpec("+" + tagRef.String() + ":" + tagRef.String())
+	} else if _, err := repo.CommitObject(plumbing.NewHash(gs.Commit)); err != nil {
+		refSpec = config.RefSpec(gs.Commit + ":" + frozenCommitRef.String())
+	}
+
+	if refSpec != "" {
+		fetchOpts := &git.FetchOptions{
+			RemoteName: "origin",
+			RefSpecs:   []config.RefSpec{refSpec},
+			Depth:      1,
+			Tags:       git.NoTags,
+			Force:      true,
+		}
+		if auth != nil {
+			fetchOpts.ClientOptions = []client.Option{client.WithHTTPAuth(auth)}
+		}
+
+		// Bound the fetch so a hung connection can't block forever
+		opCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+		defer cancel()
+
+		if err := repo.FetchContext(opCtx, fetchOpts); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			if isMissingRemoteRef(err) {
+				return gs.frozenNotFoundError()
+			}
+			return gs.translateFetchError(err)
+		}
+	}
+
+	target, err := gs.frozenCommit(repo)
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err == nil && head.Name() == plumbing.HEAD && head.Hash() == target {
+		if logger != nil {
+			logger.Debug("Already at frozen commit", "commit", target.String()[:8])
+		}
+		return nil
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get working tree: %w", err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{
+		Hash:                      target,
+		Force:                     true,
+		SparseCheckoutDirectories: gs.sparseDirs(),
+	}); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", gs.frozenDescription(), err)
+	}
+
+	if logger != nil {
+		logger.Info("Checked out frozen revision", "revision", gs.frozenDescription(), "commit", target.String()[:8])
+	}
+	return nil
+}
+
+// frozenCommit returns the commit gs is frozen at, peeling annotated tags
+func (gs GitSource) frozenCommit(repo *git.Repository) (plumbing.Hash, error) {
+	if gs.Commit != "" {
+		if _, err := repo.CommitObject(plumbing.NewHash(gs.Commit)); err != nil {
+			return plumbing.ZeroHash, gs.frozenNotFoundError()
+		}
+		return plumbing.NewHash(gs.Commit), nil
+	}
+
+	ref, err := repo.Tag(gs.Tag)
+	if err != nil {
+		return plumbing.ZeroHash, gs.frozenNotFoundError()
+	}
+	tag, err := repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		// A lightweight tag points straight at its commit
+		return ref.Hash(), nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read tag %s: %w", gs.Tag, err)
+	}
+	commit, err := tag.Commit()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("tag %s does not point at a commit: %w", gs.Tag, err)
+	}
+	return commit.Hash, nil
+}
+
+// frozenDescription names the tag or commit gs is frozen at for messages
+func (gs GitSource) frozenDescription() string {
+	if gs.Tag != "" {
+		return "tag " + gs.Tag
+	}
+	return "commit " + gs.Commit[:8]
+}
+
+// frozenNotFoundError is returned when the remote has no such tag or commit
+func (gs GitSource) frozenNotFoundError() error {
+	return fmt.Errorf("%s was not found on the remote - check the repository's tag or commit setting", gs.frozenDescription())
+}
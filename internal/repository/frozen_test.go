@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// tagAndPush tags HEAD of the repo at repoPath, replacing an existing tag, and
+// pushes the tag to origin.
+func tagAndPush(t *testing.T, repoPath, tag string) plumbing.Hash {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	_ = repo.DeleteTag(tag)
+	if _, err := repo.CreateTag(tag, head.Hash(), nil); err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	spec := config.RefSpec("+refs/tags/" + tag + ":refs/tags/" + tag)
+	if err := repo.Push(&git.PushOptions{RefSpecs: []config.RefSpec{spec}}); err != nil && err != git.NoErrAlreadyUpToDate {
+		t.Fatalf("push tag: %v", err)
+	}
+	return head.Hash()
+}
+
+// headOf returns the HEAD reference of the repo at repoPath
+func headOf(t *testing.T, repoPath string) *plumbing.Reference {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	return head
+}
+
+func TestFrozenTag_CloneAndFollow(t *testing.T) {
+	origin, writer, _ := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+
+	commitFile(t, writer, "v1.md", "# v1\n")
+	pushToOrigin(t, writer)
+	tagged := tagAndPush(t, writer, "v1.0")
+	commitFile(t, writer, "unreleased.md", "# unreleased\n")
+	pushToOrigin(t, writer)
+
+	clone := filepath.Join(t.TempDir(), "frozen")
+	gs := GitSource{Path: clone, Tag: "v1.0"}
+	if err := gs.performClone(context.Background(), clone, origin, nil, logger); err != nil {
+		t.Fatalf("performClone: %v", err)
+	}
+	head := headOf(t, clone)
+	if head.Name() != plumbing.HEAD || head.Hash() != tagged {
+		t.Fatalf("expected detached HEAD at %s, got %s at %s", tagged, head.Name(), head.Hash())
+	}
+	if _, err := os.Stat(filepath.Join(clone, "unreleased.md")); err == nil {
+		t.Error("files committed after the tag must not be checked out")
+	}
+
+	// Moving the tag on the remote moves the clone on the next sync
+	moved := tagAndPush(t, writer, "v1.0")
+	if err := gs.FetchUpdates(context.Background(), logger); err != nil {
+		t.Fatalf("FetchUpdates: %v", err)
+	}
+	if head := headOf(t, clone); head.Hash() != moved {
+		t.Errorf("expected HEAD to follow the moved tag to %s, got %s", moved, head.Hash())
+	}
+	if _, err := os.Stat(filepath.Join(clone, "unreleased.md")); err != nil {
+		t.Errorf("files at the moved tag should be checked out: %v", err)
+	}
+}
+
+func TestFrozenCommit_Clone(t *testing.T) {
+	origin, writer, _ := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+
+	frozen := headOf(t, writer).Hash()
+	commitFile(t, writer, "later.md", "# later\n")
+	pushToOrigin(t, writer)
+
+	clone := filepath.Join(t.TempDir(), "frozen")
+	gs := GitSource{Path: clone, Commit: frozen.String()}
+	if err := gs.performClone(context.Background(), clone, origin, nil, logger); err != nil {
+		t.Fatalf("performClone: %v", err)
+	}
+	if head := headOf(t, clone); head.Hash() != frozen {
+		t.Fatalf("expected HEAD at %s, got %s", frozen, head.Hash())
+	}
+	if _, err := os.Stat(filepath.Join(clone, "later.md")); err == nil {
+		t.Error("files committed after the frozen commit must not be checked out")
+	}
+
+	// The remote moving on does not move a frozen commit
+	commitFile(t, writer, "even-later.md", "# even later\n")
+	pushToOrigin(t, writer)
+	if err := gs.FetchUpdates(context.Background(), logger); err != nil {
+		t.Fatalf("FetchUpdates: %v", err)
+	}
+	if head := headOf(t, clone); head.Hash() != frozen {
+		t.Errorf("frozen commit moved to %s", head.Hash())
+	}
+}
+
+func TestFrozenTag_Missing(t *testing.T) {
+	origin, _, _ := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+
+	clone := filepath.Join(t.TempDir(), "frozen")
+	gs := GitSource{Path: clone, Tag: "v9.9"}
+	err := gs.performClone(context.Background(), clone, origin, nil, logger)
+	if err == nil || !strings.Contains(err.Error(), "tag v9.9 was not found") {
+		t.Errorf("expected a missing tag error, got %v", err)
+	}
+}
+
+func TestRepositoryEntry_validateFrozen(t *testing.T) {
+	str := func(s string) *string { return &s }
+	fullHash := strings.Repeat("ab", 20)
+
+	tests := []struct {
+		name    string
+		entry   RepositoryEntry
+		wantErr string
+	}{
+		{name: "not frozen", entry: RepositoryEntry{Branch: str("main")}},
+		{name: "tag", entry: RepositoryEntry{Tag: str("v1.2.0")}},
+		{name: "commit", entry: RepositoryEntry{Commit: str(fullHash)}},
+		{name: "tag and commit", entry: RepositoryEntry{Tag: str("v1"), Commit: str(fullHash)}, wantErr: "cannot both be set"},
+		{name: "tag and branch", entry: RepositoryEntry{Tag: str("v1"), Branch: str("main")}, wantErr: "branch cannot be set"},
+		{name: "empty tag", entry: RepositoryEntry{Tag: str(" ")}, wantErr: "tag cannot be empty"},
+		{name: "invalid tag", entry: RepositoryEntry{Tag: str("v1..2")}, wantErr: "invalid tag name"},
+		{name: "short commit", entry: RepositoryEntry{Commit: str("3f9a0c12")}, wantErr: "full commit hash"},
+		{name: "uppercase commit", entry: RepositoryEntry{Commit: str(strings.ToUpper(fullHash))}, wantErr: "full commit hash"},
+		{name: "worktrees", entry: RepositoryEntry{Tag: str("v1"), Worktrees: []string{"next"}}, wantErr: "worktrees"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.entry.validateFrozen()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
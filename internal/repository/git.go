@@ -73,6 +73,11 @@ type GitSource struct {
 	RemoteURL string  // Git repository URL (HTTPS format, SSH URLs auto-converted)
 	Branch    *string // Optional branch name (nil defaults to remote's HEAD branch)
 	Path      string  // Local path where the repository will be cloned/cached
+	Subpath   string  // Optional directory checked out and exposed instead of the whole repository (see CleanSubpath)
+	Tag       string  // Optional tag checked out instead of a branch (see checkoutFrozen)
+	Commit    string  // Optional full commit hash checked out instead of a branch (see checkoutFrozen)
+
+	RepositoryID string // Optional ID of the configured repository, used to find a token stored for it (see tokenNames)
 }
 
 // NewGitSource creates a new GitSource instance with the specified parameters.
@@ -124,7 +129,7 @@ func NewGitSource(remoteURL string, branch *string, localPath string) GitSource
 // **Directory Conflict Resolution:**
 //   - Validates existing directories contain the same Git repository
 //   - Prevents accidental overwrites of different repositories
-//   - Provides clear error messages for manual conflict resolution
+//   - Returns a DirectoryConflictError, resolved with ResolveDirectoryConflict
 //
 // **Authentication Strategy:**
 //   - Public repositories: No authentication required
@@ -169,18 +174,16 @@ func (gs GitSource) Prepare(ctx context.Context, logger *logging.AppLogger) (str
 		return "", err
 	}
 
-	// Check directory status for conflicts
+	// Check directory status for conflicts, which are resolved with
+	// ResolveDirectoryConflict
 	dirStatus, err := gs.validateCloneDirectory(cleanPath, normalizedURL)
+	if conflictErr := gs.directoryConflict(cleanPath, normalizedURL, dirStatus); conflictErr != nil {
+		return "", conflictErr
+	}
 	if err != nil {
 		return "", err
 	}
 
-	// Handle directory conflicts
-	if dirStatus == DirectoryStatusConflict || dirStatus == DirectoryStatusDifferentRepo {
-		return "", fmt.Errorf("directory conflict at %s (%s): please resolve manually by removing or relocating the existing directory",
-			cleanPath, dirStatus.String())
-	}
-
 	// Perform clone or fetch based on directory status
 	// Try without authentication first, fall back to PAT if needed
 	switch dirStatus {
@@ -201,10 +204,10 @@ func (gs GitSource) Prepare(ctx context.Context, logger *logging.AppLogger) (str
 	}
 
 	if logger != nil {
-		logger.Info("Git repository prepared successfully", "localPath", cleanPath)
+		logger.Info("Git repository prepared successfully", "localPath", cleanPath, "subpath", gs.Subpath)
 	}
 
-	return cleanPath, nil
+	return gs.exposedPath(cleanPath)
 }
 
 // validateInputs validates the GitSource configuration
@@ -268,6 +271,17 @@ func (gs GitSource) FetchUpdates(ctx context.Context, logger *logging.AppLogger)
 	if _, err := os.Stat(gs.Path); os.IsNotExist(err) {
 		return fmt.Errorf("repository does not exist at %s - cannot fetch updates", gs.Path)
 	}
+	if _, inspecting := CurrentInspection(gs.Path); inspecting {
+		return ErrInspecting
+	}
+
+	// A directory that holds something else must not be fetched or reset
+	if normalizedURL, err := gs.normalizeRemoteURL(); err == nil {
+		status, _ := gs.validateCloneDirectory(gs.Path, normalizedURL)
+		if conflictErr := gs.directoryConflict(gs.Path, normalizedURL, status); conflictErr != nil {
+			return conflictErr
+		}
+	}
 
 	return gs.performFetchWithAuth(ctx, gs.Path, logger)
 }
@@ -341,41 +355,76 @@ func (gs GitSource) validateLocalPath() (string, error) {
 	return abs, nil
 }
 
+// provider returns the hosting provider of the remote, see DetectProvider
+func (gs GitSource) provider() Provider {
+	return DetectProvider(gs.RemoteURL)
+}
+
+// authRequiredError is returned when a repository needs authentication and no token is available
+func (gs GitSource) authRequiredError() error {
+	provider := gs.provider()
+	if provider == ProviderGitHub {
+		return fmt.Errorf("GitHub authentication required - please configure a Personal Access Token in Settings → GitHub Authentication, " +
+			"or run rulem with --token-once to use a token for this run without saving it")
+	}
+	return fmt.Errorf("%s authentication required - please %s, "+
+		"or run rulem with --token-once to use a token for this run without saving it", provider.DisplayName(), provider.authGuidance())
+}
+
 // getAuthentication retrieves PAT from credential manager for authentication.
 //
 // Authentication strategy:
+//   - Uses (and forgets) a one-shot token from UseTokenOnce when one is pending
 //   - Returns nil auth if no token is stored (allows public repository access)
-//   - Uses GitHub PAT authentication pattern (username="token", password=PAT)
+//   - Uses the token stored for the repository, else for its host, else the
+//     default token of the remote's provider (see CredentialManager.LookupToken),
+//     with the provider's username convention (see Provider.basicAuth;
+//     username="token" for GitHub PATs)
 //   - Provides user-friendly error messages with Settings guidance
 //   - Validates token availability before attempting Git operations
 //
 // Returns:
-//   - *http.BasicAuth: GitHub PAT authentication or nil for public access
+//   - *http.BasicAuth: Token authentication or nil for public access
 //   - error: Credential retrieval errors with actionable user guidance
 func (gs GitSource) getAuthentication(logger *logging.AppLogger) (*http.BasicAuth, error) {
-	credMgr := NewCredentialManager()
-
-	// Check if token exists
-	if !credMgr.HasGitHubToken() {
-		return nil, nil // No auth available - will try public access
+	provider := gs.provider()
+	if token := takeOneShotToken(); token != "" {
+		if logger != nil {
+			logger.Debug("Using one-shot token for authentication", "provider", provider)
+		}
+		return provider.basicAuth(token), nil
 	}
 
-	// Retrieve token
-	token, err := credMgr.GetGitHubToken()
+	// Retrieve the repository's own token, its host's, or the default one
+	token, name, err := NewCredentialManager().LookupToken(provider, gs.tokenNames()...)
 	if err != nil {
 		// The credential manager already provides user-friendly error messages
 		return nil, err
 	}
+	if token == "" {
+		return nil, nil // No auth available - will try public access
+	}
 
 	if logger != nil {
-		logger.Debug("Using GitHub Personal Access Token for authentication")
+		if name == "" {
+			name = "default"
+		}
+		logger.Debug("Using stored token for authentication", "provider", provider, "token", name)
 	}
 
-	// GitHub PAT authentication uses "token" as username
-	return &http.BasicAuth{
-		Username: "token",
-		Password: token,
-	}, nil
+	return provider.basicAuth(token), nil
+}
+
+// tokenNames lists the names of the tokens that may authenticate gs, most specific first
+func (gs GitSource) tokenNames() []string {
+	var names []string
+	if gs.RepositoryID != "" {
+		names = append(names, RepositoryTokenName(gs.RepositoryID))
+	}
+	if info, err := ParseGitURL(gs.RemoteURL); err == nil && info.Host != "" {
+		names = append(names, HostTokenName(info.Host))
+	}
+	return names
 }
 
 // performCloneWithAuth performs the initial repository clone, trying public access first.
@@ -402,10 +451,10 @@ func (gs GitSource) performCloneWithAuth(ctx context.Context, localPath, remoteU
 		// Get authentication credentials
 		auth, authErr := gs.getAuthentication(logger)
 		if authErr != nil {
-			return fmt.Errorf("GitHub authentication failed: %w", authErr)
+			return fmt.Errorf("%s authentication failed: %w", gs.provider().DisplayName(), authErr)
 		}
 		if auth == nil {
-			return fmt.Errorf("GitHub authentication required - please configure a Personal Access Token in Settings → GitHub Authentication")
+			return gs.authRequiredError()
 		}
 
 		// Retry with authentication
@@ -422,6 +471,7 @@ func (gs GitSource) performCloneWithAuth(ctx context.Context, localPath, remoteU
 //   - Creates secure parent directories using fileops for consistent security
 //   - Performs shallow clone (depth=1) for performance optimization
 //   - Handles branch-specific cloning when configured
+//   - Checks out the frozen tag or commit when configured
 //   - Provides user-friendly error translation for common failure scenarios
 //
 // go-git library functions used:
@@ -467,16 +517,43 @@ func (gs GitSource) performClone(ctx context.Context, localPath, remoteURL strin
 		cloneOpts.SingleBranch = true
 	}
 
+	// A subpath is checked out sparsely once the clone is done, and a
+	// frozen tag or commit is fetched and checked out afterwards
+	if gs.sparseDirs() != nil || gs.frozen() {
+		cloneOpts.NoCheckout = true
+	}
+
+	// Another process preparing the same repository must not clone into the
+	// directory at the same time, see fileops.Lock
+	lock, err := fileops.Lock(localPath, fileops.DefaultLockTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer lock.Unlock()
+
 	// Perform the clone, bounded so a hung connection can't block forever
 	opCtx, cancel := context.WithTimeout(ctx, cloneTimeout)
 	defer cancel()
 
-	_, err := git.PlainCloneContext(opCtx, localPath, cloneOpts)
+	repo, err := git.PlainCloneContext(opCtx, localPath, cloneOpts)
 	if err != nil {
 		// Provide user-friendly error messages for common failures
 		return gs.translateCloneError(err)
 	}
 
+	if gs.frozen() {
+		if err := gs.checkoutFrozen(ctx, repo, auth, logger); err != nil {
+			return err
+		}
+	} else if dirs := gs.sparseDirs(); dirs != nil {
+		if err := sparseCheckout(repo, dirs); err != nil {
+			return err
+		}
+		if logger != nil {
+			logger.Info("Checked out repository subpath", "subpath", gs.Subpath)
+		}
+	}
+
 	if logger != nil {
 		logger.Info("Repository cloned successfully", "localPath", localPath)
 	}
@@ -509,10 +586,10 @@ func (gs GitSource) performFetchWithAuth(ctx context.Context, localPath string,
 		// Get authentication credentials
 		auth, authErr := gs.getAuthentication(logger)
 		if authErr != nil {
-			return fmt.Errorf("GitHub authentication failed: %w", authErr)
+			return fmt.Errorf("%s authentication failed: %w", gs.provider().DisplayName(), authErr)
 		}
 		if auth == nil {
-			return fmt.Errorf("GitHub authentication required - please configure a Personal Access Token in Settings → GitHub Authentication")
+			return gs.authRequiredError()
 		}
 
 		// Retry with authentication
@@ -534,6 +611,11 @@ func (gs GitSource) performFetchWithAuth(ctx context.Context, localPath string,
 //  6. Hard-reset the working tree to origin/<branch> so the served files
 //     actually reflect the remote (cache-focused approach)
 //
+// A repository frozen at a tag or commit skips steps 4-6 and moves to its
+// tag or commit instead (see checkoutFrozen). Steps 4-6 run with the sync
+// marker in place (see SyncInProgress), and every step holds the lock of the
+// clone that writes into it take too (see fileops.Lock).
+//
 // go-git library functions explained:
 //   - git.PlainOpen: Opens existing Git repository from filesystem path
 //   - repo.Worktree(): Gets working tree interface for status/reset operations
@@ -551,6 +633,13 @@ func (gs GitSource) performFetch(ctx context.Context, localPath string, auth *ht
 		logger.Info("Fetching repository updates", "localPath", localPath)
 	}
 
+	// Saves into the clone and syncs by other processes wait, see fileops.Lock
+	lock, err := fileops.Lock(localPath, fileops.DefaultLockTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer lock.Unlock()
+
 	// Open existing repository
 	repo, err := git.PlainOpen(localPath)
 	if err != nil {
@@ -569,7 +658,7 @@ func (gs GitSource) performFetch(ctx context.Context, localPath string, auth *ht
 	}
 
 	// If working tree is dirty, continue with current state but inform user
-	if !status.IsClean() {
+	if !gs.cleanWithin(status) {
 		if logger != nil {
 			logger.Warn("Working tree has uncommitted changes, skipping sync")
 		}
@@ -577,6 +666,22 @@ func (gs GitSource) performFetch(ctx context.Context, localPath string, auth *ht
 		return nil
 	}
 
+	// A commit checked out from the commit browser stays until the user returns
+	if _, inspecting := CurrentInspection(localPath); inspecting {
+		if logger != nil {
+			logger.Warn("Repository is inspecting an older commit, skipping sync")
+		}
+		return nil
+	}
+
+	// Readers wait for the working tree to settle, see SyncInProgress
+	defer markSyncing(localPath)()
+
+	// A frozen repository only ever moves to its tag or commit
+	if gs.frozen() {
+		return gs.checkoutFrozen(ctx, repo, auth, logger)
+	}
+
 	// Perform fetch
 	// Get the remote
 	remote, err := repo.Remote("origin")
@@ -603,6 +708,11 @@ func (gs GitSource) performFetch(ctx context.Context, localPath string, auth *ht
 
 	err = remote.FetchContext(opCtx, fetchOpts)
 	if err != nil && err != git.NoErrAlreadyUpToDate {
+		// A single-branch clone fails here once the remote deletes its branch,
+		// e.g. after renaming master to main
+		if gs.Branch != nil && *gs.Branch != "" && isMissingRemoteRef(err) {
+			return branchGoneError(opCtx, remote, fetchOpts.ClientOptions, *gs.Branch)
+		}
 		return gs.translateFetchError(err)
 	}
 
@@ -664,10 +774,14 @@ func (gs GitSource) syncWorktreeToRemote(repo *git.Repository, worktree *git.Wor
 		return nil
 	}
 
-	if err := worktree.Reset(&git.ResetOptions{
+	resetOpts := &git.ResetOptions{
 		Commit: remoteRef.Hash(),
 		Mode:   git.HardReset,
-	}); err != nil {
+	}
+	if dirs := gs.sparseDirs(); dirs != nil {
+		resetOpts.SparseDirs = dirs
+	}
+	if err := worktree.Reset(resetOpts); err != nil {
 		return fmt.Errorf("failed to update working tree to %s: %w", remoteRef.Hash().String()[:8], err)
 	}
 
@@ -721,6 +835,13 @@ func (gs GitSource) translateCloneError(err error) error {
 
 	// Authentication errors
 	if gs.containsAuthErrorPatterns(errMsg) {
+		provider := gs.provider()
+		if provider != ProviderGitHub {
+			if strings.Contains(errStr, "403") || strings.Contains(errStr, "forbidden") {
+				return fmt.Errorf("%s token lacks required permissions - ensure it can read the repository", provider.DisplayName())
+			}
+			return fmt.Errorf("%s authentication failed - please %s", provider.DisplayName(), provider.authGuidance())
+		}
 		if strings.Contains(errStr, "403") || strings.Contains(errStr, "forbidden") {
 			return fmt.Errorf("GitHub token lacks required permissions - ensure 'repo' scope is enabled in Settings → GitHub Authentication")
 		}
@@ -794,6 +915,10 @@ func (gs GitSource) translateFetchError(err error) error {
 
 	// Authentication errors (similar to clone)
 	if gs.containsAuthErrorPatterns(errMsg) {
+		provider := gs.provider()
+		if provider != ProviderGitHub {
+			return fmt.Errorf("%s token has expired or is invalid - please %s", provider.DisplayName(), provider.authGuidance())
+		}
 		return fmt.Errorf("GitHub token has expired or is invalid - please update in Settings → GitHub Authentication")
 	}
 
@@ -826,7 +951,7 @@ func (gs GitSource) translateFetchError(err error) error {
 // GitURLInfo contains the parsed components of a Git repository URL.
 type GitURLInfo struct {
 	Host  string // Host (e.g., "github.com")
-	Owner string // Repository owner/organization
+	Owner string // Repository owner/organization, or GitLab group path (e.g., "group/subgroup")
 	Repo  string // Repository name (without .git suffix)
 }
 
@@ -835,6 +960,8 @@ type GitURLInfo struct {
 //
 // Returns a GitURLInfo struct containing the parsed host, owner, and repo name,
 // or an error if the URL format is invalid or required components are missing.
+// GitLab projects can be nested in subgroups, so for GitLab remotes Owner is
+// the whole group path.
 //
 // Example:
 //
@@ -846,6 +973,9 @@ func ParseGitURL(gitURL string) (GitURLInfo, error) {
 	// Handle SSH URLs like git@github.com:owner/repo.git
 	sshPattern := regexp.MustCompile(`^git@([^:]+):([^/]+)/(.+?)(?:\.git)?$`)
 	if matches := sshPattern.FindStringSubmatch(gitURL); matches != nil {
+		if DetectProvider(gitURL) == ProviderGitLab {
+			return gitLabURLInfo(matches[1], strings.Split(matches[2]+"/"+matches[3], "/"))
+		}
 		return GitURLInfo{
 			Host:  matches[1],
 			Owner: matches[2],
@@ -865,6 +995,9 @@ func ParseGitURL(gitURL string) (GitURLInfo, error) {
 
 	// Extract path components (remove leading slash and .git suffix)
 	pathParts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if DetectProvider(gitURL) == ProviderGitLab && len(pathParts) > 2 {
+		return gitLabURLInfo(parsedURL.Host, pathParts)
+	}
 	if len(pathParts) < 2 {
 		return GitURLInfo{}, fmt.Errorf("URL path should contain owner/repo: %s", parsedURL.Path)
 	}
@@ -884,6 +1017,32 @@ func ParseGitURL(gitURL string) (GitURLInfo, error) {
 	}, nil
 }
 
+// gitLabURLInfo builds the GitURLInfo of a GitLab project from its path
+// segments, which may nest the project in subgroups. Web URLs separate the
+// project from the page they show with a "-" segment.
+func gitLabURLInfo(host string, pathParts []string) (GitURLInfo, error) {
+	for i, part := range pathParts {
+		if part == "-" {
+			pathParts = pathParts[:i]
+			break
+		}
+	}
+	if len(pathParts) < 2 {
+		return GitURLInfo{}, fmt.Errorf("URL path should contain group/project: %s", strings.Join(pathParts, "/"))
+	}
+	for _, part := range pathParts {
+		if part == "" {
+			return GitURLInfo{}, fmt.Errorf("could not extract group/project from URL path: %s", strings.Join(pathParts, "/"))
+		}
+	}
+
+	return GitURLInfo{
+		Host:  host,
+		Owner: strings.Join(pathParts[:len(pathParts)-1], "/"),
+		Repo:  strings.TrimSuffix(pathParts[len(pathParts)-1], ".git"),
+	}, nil
+}
+
 // validateCloneDirectory checks if a target clone directory can be used safely for the given repository.
 // This function handles directory conflict resolution by checking if an existing directory
 // contains the same Git repository or needs manual user intervention.
@@ -892,10 +1051,10 @@ func ParseGitURL(gitURL string) (GitURLInfo, error) {
 //   - Directory doesn't exist: Safe to clone
 //   - Directory exists but is empty: Safe to clone
 //   - Directory exists with content:
-//   - Not a git repository: Error - ask user to resolve manually
+//   - Not a git repository: Error - ask user to resolve (see ResolveDirectoryConflict)
 //   - Is git repository with same remote URL: Safe to proceed (fetch/pull)
-//   - Is git repository with different remote URL: Error - ask user to resolve manually
-//   - Has non-git content: Error - ask user to resolve manually
+//   - Is git repository with different remote URL: Error - ask user to resolve
+//   - Has non-git content: Error - ask user to resolve
 //
 // Parameters:
 //   - clonePath: Target directory path where repository would be cloned
@@ -1088,8 +1247,9 @@ func (gs GitSource) checkoutBranch(repo *git.Repository, worktree *git.Worktree,
 
 	// Checkout the branch
 	checkoutOpts := &git.CheckoutOptions{
-		Branch: localBranchRef,
-		Force:  false, // Don't discard local changes
+		Branch:                    localBranchRef,
+		Force:                     false, // Don't discard local changes
+		SparseCheckoutDirectories: gs.sparseDirs(),
 	}
 
 	if err := worktree.Checkout(checkoutOpts); err != nil {
@@ -421,6 +421,30 @@ func TestParseGitURL(t *testing.T) {
 			wantRepo:  "project",
 			wantErr:   false,
 		},
+		{
+			name:      "gitlab https url with subgroups",
+			gitURL:    "https://gitlab.com/group/subgroup/project.git",
+			wantHost:  "gitlab.com",
+			wantOwner: "group/subgroup",
+			wantRepo:  "project",
+			wantErr:   false,
+		},
+		{
+			name:      "gitlab ssh url with subgroup",
+			gitURL:    "git@gitlab.com:group/subgroup/project.git",
+			wantHost:  "gitlab.com",
+			wantOwner: "group/subgroup",
+			wantRepo:  "project",
+			wantErr:   false,
+		},
+		{
+			name:      "gitlab web url",
+			gitURL:    "https://gitlab.com/group/project/-/tree/main",
+			wantHost:  "gitlab.com",
+			wantOwner: "group",
+			wantRepo:  "project",
+			wantErr:   false,
+		},
 		{
 			name:      "bitbucket https url",
 			gitURL:    "https://bitbucket.org/user/repository.git",
@@ -132,6 +132,56 @@ func (ls LocalSource) Prepare(_ context.Context, logger *logging.AppLogger) (str
 	return abs, nil
 }
 
+// PrepareOverlay validates the per-user overlay of a shared repository and returns its
+// absolute path. The overlay is created if missing and must be writable; the shared
+// directory only needs to be readable and is never modified.
+//
+// Parameters:
+//   - sharedPath: Absolute path of the prepared shared directory
+//   - overlay: Configured overlay path (absolute or "~/")
+//   - logger: Logger for structured logging (can be nil)
+//
+// Returns:
+//   - string: Absolute path to the writable overlay
+//   - error: Validation or permission errors
+func PrepareOverlay(sharedPath, overlay string, logger *logging.AppLogger) (string, error) {
+	trimmed := strings.TrimSpace(overlay)
+	if err := fileops.ValidateStoragePath(trimmed); err != nil {
+		return "", fmt.Errorf("invalid overlay path: %w", err)
+	}
+	overlayPath, err := filepath.Abs(filepath.Clean(fileops.ExpandPath(trimmed)))
+	if err != nil {
+		return "", fmt.Errorf("invalid overlay path: %w", err)
+	}
+	if isWithin(overlayPath, sharedPath) || isWithin(sharedPath, overlayPath) {
+		return "", fmt.Errorf("overlay %s and shared directory %s must not contain each other", overlayPath, sharedPath)
+	}
+
+	shared, err := fileops.CheckDirectoryAccess(sharedPath)
+	if err != nil {
+		return "", err
+	}
+	if !shared.Readable {
+		return "", fmt.Errorf("shared directory is not readable: %s", sharedPath)
+	}
+
+	if err := fileops.EnsureDirectoryExists(overlayPath); err != nil {
+		return "", fmt.Errorf("cannot create overlay directory: %w", err)
+	}
+	access, err := fileops.CheckDirectoryAccess(overlayPath)
+	if err != nil {
+		return "", err
+	}
+	if !access.Readable || !access.Writable {
+		return "", fmt.Errorf("overlay directory must be readable and writable: %s", overlayPath)
+	}
+
+	if logger != nil {
+		logger.Debug("Overlay prepared", "shared", sharedPath, "overlay", overlayPath, "shared_writable", shared.Writable)
+	}
+	return overlayPath, nil
+}
+
 // ValidatePath performs validation on the configured path without accessing the filesystem.
 // This is useful for pre-flight checks before attempting preparation.
 //
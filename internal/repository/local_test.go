@@ -83,3 +83,61 @@ func TestLocalSource_Prepare_NotADirectory(t *testing.T) {
 		t.Errorf("Prepare() error = %v, want error containing 'not a directory'", err)
 	}
 }
+
+func TestPrepareOverlay(t *testing.T) {
+	shared := t.TempDir()
+
+	tests := []struct {
+		name    string
+		overlay func(t *testing.T) string
+		wantErr string
+	}{
+		{
+			name:    "missing overlay is created",
+			overlay: func(t *testing.T) string { return filepath.Join(t.TempDir(), "overlay") },
+		},
+		{
+			name:    "overlay inside shared directory",
+			overlay: func(t *testing.T) string { return filepath.Join(shared, "overlay") },
+			wantErr: "must not contain each other",
+		},
+		{
+			name: "overlay is a file",
+			overlay: func(t *testing.T) string {
+				path := filepath.Join(t.TempDir(), "overlay")
+				if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+					t.Fatalf("failed to create test file: %v", err)
+				}
+				return path
+			},
+			wantErr: "cannot create overlay directory",
+		},
+		{
+			name:    "relative overlay",
+			overlay: func(t *testing.T) string { return "overlay" },
+			wantErr: "invalid overlay path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlay := tt.overlay(t)
+			got, err := PrepareOverlay(shared, overlay, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PrepareOverlay() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PrepareOverlay() unexpected error: %v", err)
+			}
+			if got != overlay {
+				t.Errorf("PrepareOverlay() = %q, want %q", got, overlay)
+			}
+			if info, err := os.Stat(got); err != nil || !info.IsDir() {
+				t.Errorf("overlay directory should exist, stat err = %v", err)
+			}
+		})
+	}
+}
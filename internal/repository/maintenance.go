@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/format/packfile"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/storer"
+)
+
+// Maintenance of local clones
+//
+// Every sync of a long-lived clone adds loose objects and small packs that
+// go-git never cleans up. RunMaintenance repacks everything reachable into a
+// single pack and prunes unreachable loose objects, like git gc. go-git's own
+// Prune and RepackObjects walk commit parents and fail on shallow clones, so the
+// reachable set is computed here, stopping at the shallow boundary. If the
+// go-git repack fails, the git CLI is used when it is installed.
+//
+// Maintenance runs from the repository actions menu, and after a successful
+// sync once maintenanceInterval has passed since the last run, which is
+// recorded in .git/rulem-maintenance.
+
+const (
+	// maintenanceFileName is the file inside .git recording the last maintenance run
+	maintenanceFileName = "rulem-maintenance"
+	// maintenanceInterval is how often sync runs maintenance on a clone
+	maintenanceInterval = 7 * 24 * time.Hour
+	// pruneGracePeriod keeps recent unreachable loose objects, which may belong to
+	// an operation still in progress
+	pruneGracePeriod = time.Hour
+)
+
+// ObjectStats summarizes the object database of a clone
+type ObjectStats struct {
+	// LooseObjects is the number of objects stored outside packs
+	LooseObjects int
+	// Packs is the number of pack files
+	Packs int
+	// Size is the total size of .git/objects in bytes
+	Size int64
+}
+
+// MaintenanceResult reports the effect of RunMaintenance
+type MaintenanceResult struct {
+	// Before describes the object database before maintenance
+	Before ObjectStats
+	// After describes the object database after maintenance
+	After ObjectStats
+	// UsedCLI is true when go-git failed and git gc was run instead
+	UsedCLI bool
+}
+
+// gitGC runs git gc in repoPath. It is a variable so tests can replace the CLI
+// fallback.
+var gitGC = func(ctx context.Context, repoPath string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is not installed: %w", err)
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", repoPath, "gc", "--prune=now", "--quiet").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git gc failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// RunMaintenance repacks the clone at repoPath into a single pack and removes
+// unreachable loose objects, then records the run for MaintenanceDue. The
+// working tree and refs are not changed.
+func RunMaintenance(ctx context.Context, repoPath string, logger *logging.AppLogger) (MaintenanceResult, error) {
+	var result MaintenanceResult
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	if result.Before, err = objectStats(repoPath); err != nil {
+		return result, err
+	}
+
+	if err := repack(repo); err != nil {
+		if logger != nil {
+			logger.Warn("go-git repack failed, trying git gc", "path", repoPath, "error", err)
+		}
+		gcCtx, cancel := context.WithTimeout(ctx, maintenanceTimeout)
+		defer cancel()
+		if gcErr := gitGC(gcCtx, repoPath); gcErr != nil {
+			return result, fmt.Errorf("maintenance failed: %w (fallback: %v)", err, gcErr)
+		}
+		result.UsedCLI = true
+	}
+
+	if result.After, err = objectStats(repoPath); err != nil {
+		return result, err
+	}
+	if err := os.WriteFile(maintenanceFile(repoPath), []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0600); err != nil {
+		return result, fmt.Errorf("failed to record maintenance: %w", err)
+	}
+
+	if logger != nil {
+		logger.Info("Repository maintenance complete", "path", repoPath,
+			"loose_before", result.Before.LooseObjects, "loose_after", result.After.LooseObjects,
+			"size_before", result.Before.Size, "size_after", result.After.Size, "cli", result.UsedCLI)
+	}
+	return result, nil
+}
+
+// MaintenanceDue reports whether maintenanceInterval has passed since the last
+// RunMaintenance on repoPath. A clone that was never maintained is due.
+func MaintenanceDue(repoPath string) bool {
+	data, err := os.ReadFile(maintenanceFile(repoPath))
+	if err != nil {
+		return true
+	}
+	last, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return true
+	}
+	return time.Since(last) >= maintenanceInterval
+}
+
+// repack writes every reachable object into a new pack, deletes the old packs
+// and the loose copies of packed objects, and prunes unreachable loose objects
+// older than pruneGracePeriod
+func repack(repo *git.Repository) error {
+	los, ok := repo.Storer.(storer.LooseObjectStorer)
+	if !ok {
+		return git.ErrLooseObjectsNotSupported
+	}
+	pos, ok := repo.Storer.(storer.PackedObjectStorer)
+	if !ok {
+		return git.ErrPackedObjectsNotSupported
+	}
+	pfw, ok := repo.Storer.(storer.PackfileWriter)
+	if !ok {
+		return errors.New("repository storage cannot write packfiles")
+	}
+
+	reachable, err := reachableObjects(repo)
+	if err != nil {
+		return err
+	}
+	if len(reachable) == 0 {
+		return nil
+	}
+
+	oldPacks, err := pos.ObjectPacks()
+	if err != nil {
+		return fmt.Errorf("failed to list packs: %w", err)
+	}
+
+	hashes := make([]plumbing.Hash, 0, len(reachable))
+	for hash := range reachable {
+		hashes = append(hashes, hash)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository config: %w", err)
+	}
+	w, err := pfw.PackfileWriter()
+	if err != nil {
+		return fmt.Errorf("failed to create pack: %w", err)
+	}
+	newPack, err := packfile.NewEncoder(w, repo.Storer, false).Encode(hashes, cfg.Pack.Window)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write pack: %w", err)
+	}
+
+	for _, pack := range oldPacks {
+		if pack == newPack {
+			continue
+		}
+		if err := pos.DeleteOldObjectPackAndIndex(pack, time.Time{}); err != nil {
+			return fmt.Errorf("failed to delete old pack %s: %w", pack, err)
+		}
+	}
+
+	cutoff := time.Now().Add(-pruneGracePeriod)
+	return los.ForEachObjectHash(func(hash plumbing.Hash) error {
+		if !reachable[hash] {
+			// Errors are not fatal, the object may have been removed concurrently
+			if modified, err := los.LooseObjectTime(hash); err != nil || !modified.Before(cutoff) {
+				return nil
+			}
+		}
+		return los.DeleteLooseObject(hash)
+	})
+}
+
+// reachableObjects returns the objects reachable from HEAD, the refs and the
+// index. Parents of shallow commits are not part of the clone and are skipped.
+func reachableObjects(repo *git.Repository) (map[plumbing.Hash]bool, error) {
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shallow commits: %w", err)
+	}
+	w := &reachableWalker{repo: repo, seen: map[plumbing.Hash]bool{}, shallow: map[plumbing.Hash]bool{}}
+	for _, hash := range shallow {
+		w.shallow[hash] = true
+	}
+
+	if head, err := repo.Head(); err == nil {
+		if err := w.walk(head.Hash()); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		return w.walk(ref.Hash())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Staged files are only referenced by the index
+	if idx, err := repo.Storer.Index(); err == nil {
+		for _, entry := range idx.Entries {
+			if entry.Mode != filemode.Submodule {
+				w.seen[entry.Hash] = true
+			}
+		}
+	}
+	return w.seen, nil
+}
+
+// reachableWalker collects the objects reachable from a set of starting points
+type reachableWalker struct {
+	repo    *git.Repository
+	seen    map[plumbing.Hash]bool
+	shallow map[plumbing.Hash]bool
+}
+
+// walk marks hash and everything it references as reachable
+func (w *reachableWalker) walk(hash plumbing.Hash) error {
+	if w.seen[hash] {
+		return nil
+	}
+	obj, err := w.repo.Storer.EncodedObject(plumbing.AnyObject, hash)
+	if err != nil {
+		return fmt.Errorf("failed to read object %s: %w", hash, err)
+	}
+	w.seen[hash] = true
+
+	switch obj.Type() {
+	case plumbing.CommitObject:
+		commit, err := object.DecodeCommit(w.repo.Storer, obj)
+		if err != nil {
+			return fmt.Errorf("failed to decode commit %s: %w", hash, err)
+		}
+		if err := w.walk(commit.TreeHash); err != nil {
+			return err
+		}
+		if w.shallow[hash] {
+			return nil
+		}
+		for _, parent := range commit.ParentHashes {
+			if err := w.walk(parent); err != nil {
+				return err
+			}
+		}
+	case plumbing.TreeObject:
+		tree, err := object.DecodeTree(w.repo.Storer, obj)
+		if err != nil {
+			return fmt.Errorf("failed to decode tree %s: %w", hash, err)
+		}
+		for _, entry := range tree.Entries {
+			switch {
+			case entry.Mode == filemode.Submodule:
+				// Submodule commits live in another repository
+			case entry.Mode.IsFile():
+				w.seen[entry.Hash] = true
+			default:
+				if err := w.walk(entry.Hash); err != nil {
+					return err
+				}
+			}
+		}
+	case plumbing.TagObject:
+		tag, err := object.DecodeTag(w.repo.Storer, obj)
+		if err != nil {
+			return fmt.Errorf("failed to decode tag %s: %w", hash, err)
+		}
+		return w.walk(tag.Target)
+	}
+	return nil
+}
+
+// objectStats counts the loose objects and packs under .git/objects
+func objectStats(repoPath string) (ObjectStats, error) {
+	var stats ObjectStats
+	root := filepath.Join(repoPath, git.GitDirName, "objects")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		stats.Size += info.Size()
+
+		dir := filepath.Base(filepath.Dir(path))
+		switch {
+		case dir == "pack" && strings.HasSuffix(path, ".pack"):
+			stats.Packs++
+		case len(dir) == 2 && filepath.Dir(filepath.Dir(path)) == root:
+			stats.LooseObjects++
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to inspect objects: %w", err)
+	}
+	return stats, nil
+}
+
+// maintenanceFile returns the path of the maintenance marker for repoPath
+func maintenanceFile(repoPath string) string {
+	return filepath.Join(repoPath, git.GitDirName, maintenanceFileName)
+}
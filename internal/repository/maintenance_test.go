@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// storeLooseBlob writes an unreachable loose blob into repoPath with the given
+// modification time and returns its hash
+func storeLooseBlob(t *testing.T, repoPath, content string, modified time.Time) plumbing.Hash {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		t.Fatalf("blob writer: %v", err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatalf("write blob: %v", err)
+	}
+	w.Close()
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatalf("store blob: %v", err)
+	}
+	path := filepath.Join(repoPath, ".git", "objects", hash.String()[:2], hash.String()[2:])
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	return hash
+}
+
+// hasObject reports whether hash can be read from repoPath
+func hasObject(t *testing.T, repoPath string, hash plumbing.Hash) bool {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_, err = repo.Storer.EncodedObject(plumbing.AnyObject, hash)
+	return err == nil
+}
+
+func TestRunMaintenance(t *testing.T) {
+	reader := historyRepo(t)
+	old := storeLooseBlob(t, reader, "stale\n", time.Now().Add(-2*pruneGracePeriod))
+	recent := storeLooseBlob(t, reader, "recent\n", time.Now())
+
+	logger, _ := logging.NewTestLogger()
+	result, err := RunMaintenance(context.Background(), reader, logger)
+	if err != nil {
+		t.Fatalf("RunMaintenance: %v", err)
+	}
+
+	if result.UsedCLI {
+		t.Error("go-git maintenance should not need the CLI fallback")
+	}
+	if result.Before.LooseObjects < 2 {
+		t.Errorf("Before.LooseObjects = %d, want at least the two stored blobs", result.Before.LooseObjects)
+	}
+	if result.After.Packs != 1 || result.After.LooseObjects != 1 {
+		t.Errorf("After = %+v, want one pack and only the recent blob loose", result.After)
+	}
+	if hasObject(t, reader, old) {
+		t.Error("old unreachable blob should be pruned")
+	}
+	if !hasObject(t, reader, recent) {
+		t.Error("recent unreachable blob should be kept")
+	}
+
+	// History and working tree are intact
+	if commits, err := ListCommits(reader, 10); err != nil || len(commits) != 3 {
+		t.Errorf("ListCommits after maintenance = %d commits, err %v", len(commits), err)
+	}
+	if changes, err := ListChangedFiles(reader); err != nil || len(changes) != 0 {
+		t.Errorf("ListChangedFiles after maintenance = %v, err %v", changes, err)
+	}
+	if MaintenanceDue(reader) {
+		t.Error("maintenance should not be due right after running")
+	}
+}
+
+func TestRunMaintenance_Shallow(t *testing.T) {
+	reader := historyRepo(t)
+	commits, err := ListCommits(reader, 10)
+	if err != nil {
+		t.Fatalf("ListCommits: %v", err)
+	}
+
+	// Mark the middle commit shallow: its parent is outside the clone and is
+	// dropped by the repack, leaving a real shallow boundary behind
+	if err := os.WriteFile(filepath.Join(reader, ".git", "shallow"), []byte(commits[1].Hash+"\n"), 0644); err != nil {
+		t.Fatalf("write shallow: %v", err)
+	}
+
+	logger, _ := logging.NewTestLogger()
+	for run := 1; run <= 2; run++ {
+		result, err := RunMaintenance(context.Background(), reader, logger)
+		if err != nil {
+			t.Fatalf("RunMaintenance run %d: %v", run, err)
+		}
+		if result.UsedCLI {
+			t.Errorf("run %d used the CLI fallback", run)
+		}
+	}
+
+	if hasObject(t, reader, plumbing.NewHash(commits[2].Hash)) {
+		t.Error("commit behind the shallow boundary should be dropped")
+	}
+	listed, err := ListCommits(reader, 10)
+	if err != nil || len(listed) != 2 || !listed[1].Boundary {
+		t.Errorf("ListCommits after shallow maintenance = %+v, err %v", listed, err)
+	}
+}
+
+func TestRunMaintenance_CLIFallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		gcErr   error
+		wantErr bool
+	}{
+		{name: "fallback succeeds"},
+		{name: "fallback fails", gcErr: errors.New("git is not installed"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := historyRepo(t)
+			commits, err := ListCommits(reader, 10)
+			if err != nil {
+				t.Fatalf("ListCommits: %v", err)
+			}
+			// A missing parent without a shallow entry makes the go-git walk fail
+			shallow := filepath.Join(reader, ".git", "shallow")
+			if err := os.WriteFile(shallow, []byte(commits[1].Hash+"\n"), 0644); err != nil {
+				t.Fatalf("write shallow: %v", err)
+			}
+			if _, err := RunMaintenance(context.Background(), reader, nil); err != nil {
+				t.Fatalf("RunMaintenance: %v", err)
+			}
+			if err := os.Remove(shallow); err != nil {
+				t.Fatalf("remove shallow: %v", err)
+			}
+
+			called := false
+			original := gitGC
+			gitGC = func(ctx context.Context, repoPath string) error {
+				called = repoPath == reader
+				return tt.gcErr
+			}
+			t.Cleanup(func() { gitGC = original })
+
+			result, err := RunMaintenance(context.Background(), reader, nil)
+			if !called {
+				t.Fatal("git gc fallback was not called")
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil || !result.UsedCLI {
+				t.Errorf("RunMaintenance() = %+v, %v, want CLI fallback", result, err)
+			}
+		})
+	}
+}
+
+func TestMaintenanceDue(t *testing.T) {
+	tests := []struct {
+		name   string
+		marker string
+		want   bool
+	}{
+		{name: "never maintained", want: true},
+		{name: "recent", marker: time.Now().UTC().Format(time.RFC3339), want: false},
+		{name: "old", marker: time.Now().Add(-maintenanceInterval - time.Hour).UTC().Format(time.RFC3339), want: true},
+		{name: "unreadable", marker: "yesterday", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(repoPath, ".git"), 0755); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+			if tt.marker != "" {
+				if err := os.WriteFile(maintenanceFile(repoPath), []byte(tt.marker+"\n"), 0600); err != nil {
+					t.Fatalf("write marker: %v", err)
+				}
+			}
+			if got := MaintenanceDue(repoPath); got != tt.want {
+				t.Errorf("MaintenanceDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"rulem/internal/logging"
+	"rulem/internal/version"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFileName is the optional manifest describing a repository, read from its root
+const ManifestFileName = "rulem.yaml"
+
+// Manifest is the content of a repository's rulem.yaml.
+//
+// Fields:
+//   - Name: Display name the repository describes itself with
+//   - Description: What the repository's rules are for
+//   - DefaultBundle: Name of the set of rules to suggest first
+//   - Tags: Tag taxonomy; rule files may only use these tags when set
+//   - Schema: Extra frontmatter requirements for the repository's rule files
+//   - MinRulemVersion: Oldest rulem release that understands this repository's metadata
+type Manifest struct {
+	Name            string         `yaml:"name,omitempty"`
+	Description     string         `yaml:"description,omitempty"`
+	DefaultBundle   string         `yaml:"defaultBundle,omitempty"`
+	Tags            []string       `yaml:"tags,omitempty"`
+	Schema          ManifestSchema `yaml:"schema,omitempty"`
+	MinRulemVersion string         `yaml:"minRulemVersion,omitempty"`
+}
+
+// ManifestSchema describes the frontmatter rule files in the repository must have.
+// The description field is always required and need not be listed.
+type ManifestSchema struct {
+	Required []string `yaml:"required,omitempty"` // Frontmatter fields every rule must set (e.g. "name", "applyTo", "tags", "license")
+}
+
+// HasTag reports whether tag belongs to the manifest's taxonomy. A manifest
+// without a taxonomy allows any tag.
+func (m *Manifest) HasTag(tag string) bool {
+	if m == nil || len(m.Tags) == 0 {
+		return true
+	}
+	return slices.Contains(m.Tags, tag)
+}
+
+// Summary returns a one-line description of the repository for status screens
+// and assistant instructions, or "" if the manifest has nothing to say.
+func (m *Manifest) Summary() string {
+	if m == nil {
+		return ""
+	}
+
+	var parts []string
+	switch {
+	case m.Name != "" && m.Description != "":
+		parts = append(parts, m.Name+": "+m.Description)
+	case m.Description != "":
+		parts = append(parts, m.Description)
+	case m.Name != "":
+		parts = append(parts, m.Name)
+	}
+	if m.DefaultBundle != "" {
+		parts = append(parts, "default bundle: "+m.DefaultBundle)
+	}
+	if len(m.Tags) > 0 {
+		parts = append(parts, "tags: "+strings.Join(m.Tags, ", "))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// LoadManifest reads the manifest from the root of a prepared repository.
+//
+// Returns:
+//   - *Manifest: The parsed manifest, or nil if the repository has none
+//   - error: If the manifest exists but cannot be read or parsed
+func LoadManifest(localPath string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(localPath, ManifestFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", ManifestFileName, err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestFileName, err)
+	}
+	return &m, nil
+}
+
+// CheckCompatibility reports whether clientVersion satisfies the manifest's
+// minimum rulem version. Development builds cannot be compared and are always
+// considered compatible.
+//
+// Returns:
+//   - error: Describes the incompatibility, or nil if the client is new enough
+func (m *Manifest) CheckCompatibility(clientVersion string) error {
+	if m == nil || m.MinRulemVersion == "" || !version.IsRelease(clientVersion) {
+		return nil
+	}
+
+	ok, err := version.AtLeast(clientVersion, m.MinRulemVersion)
+	if err != nil {
+		return fmt.Errorf("invalid minRulemVersion in %s: %w", ManifestFileName, err)
+	}
+	if !ok {
+		return fmt.Errorf("repository requires rulem %s or newer (running %s); upgrade rulem to use it safely",
+			m.MinRulemVersion, clientVersion)
+	}
+	return nil
+}
+
+// loadRepositoryManifests attaches each available repository's manifest and
+// applies its requirements to the running client. Incompatible repositories are
+// logged and recorded as a warning, or made unavailable when the repository
+// entry sets RefuseIncompatible.
+func loadRepositoryManifests(prepared []PreparedRepository, logger *logging.AppLogger) {
+	clientVersion := version.Current()
+
+	for i := range prepared {
+		if !prepared[i].IsAvailable() {
+			continue
+		}
+
+		manifest, err := LoadManifest(prepared[i].LocalPath)
+		if err == nil {
+			prepared[i].Manifest = manifest
+			err = manifest.CheckCompatibility(clientVersion)
+		}
+		if err == nil {
+			continue
+		}
+
+		repo := prepared[i].Entry
+		if repo.RefuseIncompatible {
+			if logger != nil {
+				logger.Error("Repository refused: incompatible with this rulem version",
+					"repository_id", repo.ID,
+					"repository_name", repo.Name,
+					"error", err,
+				)
+			}
+			prepared[i].LocalPath = ""
+			prepared[i].SyncResult = RepositorySyncResult{
+				RepositoryID:   repo.ID,
+				RepositoryName: repo.Name,
+				Status:         SyncStatusFailed,
+				Error:          err,
+			}
+			continue
+		}
+
+		if logger != nil {
+			logger.Warn("Repository may not be fully compatible with this rulem version",
+				"repository_id", repo.ID,
+				"repository_name", repo.Name,
+				"error", err,
+			)
+		}
+		prepared[i].CompatibilityWarning = err.Error()
+	}
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeManifest(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+}
+
+// TestLoadManifest tests reading rulem.yaml from a repository root
+func TestLoadManifest(t *testing.T) {
+	t.Run("missing manifest", func(t *testing.T) {
+		m, err := LoadManifest(t.TempDir())
+		if err != nil || m != nil {
+			t.Errorf("LoadManifest = %v, %v; want nil, nil", m, err)
+		}
+	})
+
+	t.Run("valid manifest", func(t *testing.T) {
+		dir := t.TempDir()
+		writeManifest(t, dir, "minRulemVersion: 1.3.0\n")
+
+		m, err := LoadManifest(dir)
+		if err != nil {
+			t.Fatalf("LoadManifest returned error: %v", err)
+		}
+		if m.MinRulemVersion != "1.3.0" {
+			t.Errorf("MinRulemVersion = %q, want %q", m.MinRulemVersion, "1.3.0")
+		}
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		dir := t.TempDir()
+		writeManifest(t, dir, "minRulemVersion: [\n")
+
+		if _, err := LoadManifest(dir); err == nil {
+			t.Error("expected error for invalid manifest")
+		}
+	})
+}
+
+// TestManifest_CheckCompatibility tests minimum version comparison
+func TestManifest_CheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name          string
+		manifest      *Manifest
+		clientVersion string
+		wantErr       bool
+	}{
+		{name: "nil manifest", manifest: nil, clientVersion: "1.0.0"},
+		{name: "no minimum", manifest: &Manifest{}, clientVersion: "1.0.0"},
+		{name: "new enough", manifest: &Manifest{MinRulemVersion: "1.2.0"}, clientVersion: "v1.2.0"},
+		{name: "too old", manifest: &Manifest{MinRulemVersion: "1.2.0"}, clientVersion: "v1.1.0", wantErr: true},
+		{name: "dev build", manifest: &Manifest{MinRulemVersion: "1.2.0"}, clientVersion: "dev"},
+		{name: "invalid minimum", manifest: &Manifest{MinRulemVersion: "latest"}, clientVersion: "1.0.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.manifest.CheckCompatibility(tt.clientVersion)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckCompatibility() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestLoadRepositoryManifests tests warn vs refuse handling of invalid manifests
+func TestLoadRepositoryManifests(t *testing.T) {
+	// An unparsable manifest is treated as incompatible regardless of client version
+	warnDir := t.TempDir()
+	refuseDir := t.TempDir()
+	okDir := t.TempDir()
+	writeManifest(t, warnDir, "minRulemVersion: [\n")
+	writeManifest(t, refuseDir, "minRulemVersion: [\n")
+
+	prepared := []PreparedRepository{
+		{Entry: RepositoryEntry{ID: "warn", Name: "Warn"}, LocalPath: warnDir},
+		{Entry: RepositoryEntry{ID: "refuse", Name: "Refuse", RefuseIncompatible: true}, LocalPath: refuseDir},
+		{Entry: RepositoryEntry{ID: "ok", Name: "OK"}, LocalPath: okDir},
+	}
+
+	loadRepositoryManifests(prepared, nil)
+
+	if !prepared[0].IsAvailable() || prepared[0].CompatibilityWarning == "" {
+		t.Errorf("warn repository should stay available with a warning, got %+v", prepared[0])
+	}
+	if prepared[1].IsAvailable() || prepared[1].SyncResult.Status != SyncStatusFailed {
+		t.Errorf("refuse repository should be unavailable, got %+v", prepared[1])
+	}
+	if !prepared[2].IsAvailable() || prepared[2].CompatibilityWarning != "" {
+		t.Errorf("repository without manifest should be untouched, got %+v", prepared[2])
+	}
+}
+
+// TestManifest_Summary tests the one-line description used by status screens and MCP instructions
+func TestManifest_Summary(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest *Manifest
+		want     string
+	}{
+		{name: "nil manifest", manifest: nil, want: ""},
+		{name: "empty manifest", manifest: &Manifest{MinRulemVersion: "1.0.0"}, want: ""},
+		{name: "name only", manifest: &Manifest{Name: "Acme"}, want: "Acme"},
+		{
+			name:     "all fields",
+			manifest: &Manifest{Name: "Acme", Description: "Shared rules", DefaultBundle: "backend", Tags: []string{"go", "sql"}},
+			want:     "Acme: Shared rules • default bundle: backend • tags: go, sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.manifest.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestManifest_HasTag tests tag taxonomy membership
+func TestManifest_HasTag(t *testing.T) {
+	var none *Manifest
+	if !none.HasTag("anything") {
+		t.Error("nil manifest should allow any tag")
+	}
+
+	m := &Manifest{Tags: []string{"go"}}
+	if !m.HasTag("go") || m.HasTag("rust") {
+		t.Errorf("HasTag should only accept tags in the taxonomy %v", m.Tags)
+	}
+}
+
+// TestLoadRepositoryManifests_AttachesManifest tests that prepared repositories carry their manifest
+func TestLoadRepositoryManifests_AttachesManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, "name: Acme\ndefaultBundle: backend\n")
+
+	prepared := []PreparedRepository{{Entry: RepositoryEntry{ID: "acme", Name: "Acme"}, LocalPath: dir}}
+	loadRepositoryManifests(prepared, nil)
+
+	if prepared[0].Manifest == nil || prepared[0].Manifest.DefaultBundle != "backend" {
+		t.Errorf("expected manifest to be attached, got %+v", prepared[0].Manifest)
+	}
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"rulem/internal/logging"
+	"rulem/pkg/fileops"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// Commit pins
+//
+// A GitHub repository can be pinned to the commit it is expected to be on,
+// either directly (expected_commit) or through a tag (expected_tag). After
+// every sync HEAD is checked against the pin and a repository that does not
+// match is made unavailable, so a compromised remote cannot feed new
+// instructions to assistants. With tag_keyring, the tag must also be an
+// annotated tag signed by one of the OpenPGP keys in that file.
+//
+// Shallow clones fetch tags pointing at the synced commit, which is the only
+// commit a pin can match.
+
+// minPinLength is the shortest abbreviated commit hash accepted as a pin
+const minPinLength = 7
+
+// HasPin reports whether r is pinned to a commit or tag
+func (r RepositoryEntry) HasPin() bool {
+	return r.ExpectedCommit != nil || r.ExpectedTag != nil
+}
+
+// validatePin checks the pin settings of a GitHub repository
+func (r RepositoryEntry) validatePin() error {
+	if r.ExpectedCommit != nil && r.ExpectedTag != nil {
+		return fmt.Errorf("expected_commit and expected_tag cannot both be set")
+	}
+	if r.ExpectedCommit != nil && !isCommitPrefix(*r.ExpectedCommit) {
+		return fmt.Errorf("expected_commit must be a commit hash of %d to 40 hex characters, got %q", minPinLength, *r.ExpectedCommit)
+	}
+	if r.ExpectedTag != nil && strings.TrimSpace(*r.ExpectedTag) == "" {
+		return fmt.Errorf("expected_tag cannot be empty string (use nil for no tag)")
+	}
+	if r.TagKeyring != nil && r.ExpectedTag == nil {
+		return fmt.Errorf("tag_keyring requires expected_tag")
+	}
+	return nil
+}
+
+// isCommitPrefix reports whether s is a full or abbreviated lowercase commit hash
+func isCommitPrefix(s string) bool {
+	if len(s) < minPinLength || len(s) > 40 {
+		return false
+	}
+	for _, ch := range s {
+		if (ch < '0' || ch > '9') && (ch < 'a' || ch > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyPin checks that HEAD of the clone at repoPath is the commit r is pinned
+// to. It returns nil when r has no pin.
+func (r RepositoryEntry) VerifyPin(repoPath string) error {
+	if !r.HasPin() {
+		return nil
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	headHash := head.Hash().String()
+
+	if r.ExpectedCommit != nil {
+		if !strings.HasPrefix(headHash, *r.ExpectedCommit) {
+			return fmt.Errorf("HEAD is at %s, but the repository is pinned to commit %s", headHash[:8], *r.ExpectedCommit)
+		}
+		return nil
+	}
+
+	tagName := *r.ExpectedTag
+	tagCommit, err := r.resolvePinnedTag(repo, tagName)
+	if err != nil {
+		return err
+	}
+	if tagCommit != head.Hash() {
+		return fmt.Errorf("HEAD is at %s, but the repository is pinned to tag %s at %s", headHash[:8], tagName, tagCommit.String()[:8])
+	}
+	return nil
+}
+
+// resolvePinnedTag returns the commit tagName points to, verifying its
+// signature when r has a tag keyring
+func (r RepositoryEntry) resolvePinnedTag(repo *git.Repository, tagName string) (plumbing.Hash, error) {
+	ref, err := repo.Tag(tagName)
+	if err != nil {
+		if errors.Is(err, git.ErrTagNotFound) {
+			return plumbing.ZeroHash, fmt.Errorf("pinned tag %s was not found in the clone", tagName)
+		}
+		return plumbing.ZeroHash, fmt.Errorf("failed to resolve tag %s: %w", tagName, err)
+	}
+
+	tag, err := repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		// A lightweight tag points straight at its commit and cannot be signed
+		if r.TagKeyring != nil {
+			return plumbing.ZeroHash, fmt.Errorf("pinned tag %s is not signed: lightweight tags carry no signature", tagName)
+		}
+		return ref.Hash(), nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read tag %s: %w", tagName, err)
+	}
+
+	if r.TagKeyring != nil {
+		keyring, err := os.ReadFile(fileops.ExpandPath(*r.TagKeyring))
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read tag keyring: %w", err)
+		}
+		if tag.Signature == "" {
+			return plumbing.ZeroHash, fmt.Errorf("pinned tag %s is not signed", tagName)
+		}
+		if _, err := tag.Verify(string(keyring)); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("pinned tag %s is not signed by a trusted key: %w", tagName, err)
+		}
+	}
+
+	commit, err := tag.Commit()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("pinned tag %s does not point at a commit: %w", tagName, err)
+	}
+	return commit.Hash, nil
+}
+
+// verifyPins makes synced repositories whose HEAD does not match their pin
+// unavailable, so their rules are not served
+func verifyPins(prepared []PreparedRepository, logger *logging.AppLogger) {
+	for i := range prepared {
+		repo := prepared[i].Entry
+		if !prepared[i].IsAvailable() || !repo.HasPin() {
+			continue
+		}
+
+		err := repo.VerifyPin(prepared[i].GitRoot())
+		if err == nil {
+			if logger != nil {
+				logger.Debug("Repository matches its pin", "repository_id", repo.ID)
+			}
+			continue
+		}
+
+		if logger != nil {
+			logger.Error("Repository refused: HEAD does not match its pin",
+				"repository_id", repo.ID,
+				"repository_name", repo.Name,
+				"error", err,
+			)
+		}
+		prepared[i].LocalPath = ""
+		prepared[i].SyncResult = RepositorySyncResult{
+			RepositoryID:   repo.ID,
+			RepositoryName: repo.Name,
+			Status:         SyncStatusFailed,
+			Error:          fmt.Errorf("refusing to serve rules: %w", err),
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// tagHead tags HEAD of the clone at repoPath, annotated when message is set
+func tagHead(t *testing.T, repoPath, name, message string) {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	var opts *git.CreateTagOptions
+	if message != "" {
+		opts = &git.CreateTagOptions{Message: message, Tagger: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}}
+	}
+	if _, err := repo.CreateTag(name, head.Hash(), opts); err != nil {
+		t.Fatalf("create tag %s: %v", name, err)
+	}
+}
+
+func headHash(t *testing.T, repoPath string) string {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	return head.Hash().String()
+}
+
+func TestRepositoryEntry_VerifyPin(t *testing.T) {
+	reader := historyRepo(t)
+	head := headHash(t, reader)
+	tagHead(t, reader, "v1", "")
+	tagHead(t, reader, "v1-annotated", "release v1")
+
+	commits, err := ListCommits(reader, 2)
+	if err != nil || len(commits) != 2 {
+		t.Fatalf("ListCommits: %v", err)
+	}
+	parent := commits[1].Hash
+
+	tests := []struct {
+		name      string
+		commit    *string
+		tag       *string
+		keyring   *string
+		expectErr string
+	}{
+		{name: "no pin"},
+		{name: "full commit", commit: stringPtr(head)},
+		{name: "abbreviated commit", commit: stringPtr(head[:7])},
+		{name: "other commit", commit: stringPtr(parent), expectErr: "pinned to commit " + parent},
+		{name: "lightweight tag", tag: stringPtr("v1")},
+		{name: "annotated tag", tag: stringPtr("v1-annotated")},
+		{name: "missing tag", tag: stringPtr("v2"), expectErr: "was not found"},
+		{name: "unsigned tag with keyring", tag: stringPtr("v1"), keyring: stringPtr("/nonexistent"), expectErr: "is not signed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := githubEntry(reader)
+			repo.ExpectedCommit = tt.commit
+			repo.ExpectedTag = tt.tag
+			repo.TagKeyring = tt.keyring
+
+			err := repo.VerifyPin(reader)
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("expected error containing %q, got: %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyPins_RefusesMismatchedRepositories(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	reader := historyRepo(t)
+
+	matching := githubEntry(reader)
+	matching.ExpectedCommit = stringPtr(headHash(t, reader))
+	moved := githubEntry(reader)
+	moved.ID = "rules-2"
+	moved.ExpectedCommit = stringPtr(strings.Repeat("0", 40))
+
+	prepared := []PreparedRepository{
+		{Entry: matching, LocalPath: reader},
+		{Entry: moved, LocalPath: reader},
+	}
+	verifyPins(prepared, logger)
+
+	if !prepared[0].IsAvailable() {
+		t.Errorf("repository on its pinned commit was refused: %v", prepared[0].SyncResult.Error)
+	}
+	if prepared[1].IsAvailable() || prepared[1].SyncResult.Status != SyncStatusFailed {
+		t.Fatalf("repository off its pinned commit was served: %+v", prepared[1])
+	}
+	if err := prepared[1].SyncResult.Error; err == nil || !strings.Contains(err.Error(), "refusing to serve rules") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRepositoryEntry_ValidatePin(t *testing.T) {
+	tests := []struct {
+		name      string
+		commit    *string
+		tag       *string
+		keyring   *string
+		expectErr string
+	}{
+		{name: "commit", commit: stringPtr("0123abcd")},
+		{name: "tag with keyring", tag: stringPtr("v1.0.0"), keyring: stringPtr(filepath.Join("/tmp", "keys.asc"))},
+		{name: "commit and tag", commit: stringPtr("0123abcd"), tag: stringPtr("v1"), expectErr: "cannot both be set"},
+		{name: "short commit", commit: stringPtr("0123ab"), expectErr: "expected_commit must be"},
+		{name: "uppercase commit", commit: stringPtr("0123ABCD"), expectErr: "expected_commit must be"},
+		{name: "empty tag", tag: stringPtr(" "), expectErr: "expected_tag cannot be empty"},
+		{name: "keyring without tag", keyring: stringPtr("/tmp/keys.asc"), expectErr: "requires expected_tag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := githubEntry("/opt/rules")
+			repo.ExpectedCommit = tt.commit
+			repo.ExpectedTag = tt.tag
+			repo.TagKeyring = tt.keyring
+
+			err := repo.validatePin()
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("expected error containing %q, got: %v", tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -79,7 +79,15 @@ func PrepareRepository(ctx context.Context, repo RepositoryEntry, logger *loggin
 	} else {
 		// Git repository mode - use GitSource with remote URL and branch
 		// GetRemoteURL() and GetBranch() handle nil pointer safety
-		source = NewGitSource(repo.GetRemoteURL(), repo.Branch, repo.Path)
+		gitSource := NewGitSource(repo.GetRemoteURL(), repo.Branch, repo.Path)
+		subpath, err := CleanSubpath(repo.GetSubpath())
+		if err != nil {
+			return "", fmt.Errorf("failed to prepare repository %s (%s): %w", repo.ID, repo.Name, err)
+		}
+		gitSource.Subpath = subpath
+		gitSource.Tag, gitSource.Commit = repo.GetTag(), repo.GetCommit()
+		gitSource.RepositoryID = repo.ID
+		source = gitSource
 	}
 
 	// Prepare the source and get the local path
@@ -109,6 +117,8 @@ func PrepareRepository(ctx context.Context, repo RepositoryEntry, logger *loggin
 // 2. Prepares each repository (clones if needed, validates paths)
 // 3. Syncs all GitHub repositories (fetches updates for clean repos)
 // 4. Logs sync results for each repository (success, failed, skipped)
+// 5. Refuses repositories whose HEAD does not match their pinned commit or tag
+// 6. Verifies commit signatures against allowed signers, refusing failures by default
 //
 // Parameters:
 //   - ctx: Context for cancellation across all repos
@@ -156,6 +166,13 @@ func PrepareAllRepositories(ctx context.Context, repos []RepositoryEntry, logger
 		}
 
 		localPath, err := PrepareRepository(ctx, repo, logger)
+		var overlayPath string
+		if err == nil && repo.IsShared() {
+			overlayPath, err = PrepareOverlay(localPath, repo.GetOverlay(), logger)
+			if err != nil {
+				err = fmt.Errorf("failed to prepare overlay for repository %s (%s): %w", repo.ID, repo.Name, err)
+			}
+		}
 		if err != nil {
 			errorMsg := fmt.Sprintf("repository %s (%s): %v", repo.ID, repo.Name, err)
 			preparationErrors = append(preparationErrors, errorMsg)
@@ -184,8 +201,9 @@ func PrepareAllRepositories(ctx context.Context, repos []RepositoryEntry, logger
 
 		// Create prepared repository with initial sync result (will be updated during sync)
 		preparedRepo := PreparedRepository{
-			Entry:     repo,
-			LocalPath: localPath,
+			Entry:       repo,
+			LocalPath:   localPath,
+			OverlayPath: overlayPath,
 			SyncResult: RepositorySyncResult{
 				RepositoryID:   repo.ID,
 				RepositoryName: repo.Name,
@@ -267,6 +285,19 @@ func PrepareAllRepositories(ctx context.Context, repos []RepositoryEntry, logger
 		}
 	}
 
+	// Step 4: Load manifests and check synced repositories against their minimum rulem version
+	loadRepositoryManifests(prepared, logger)
+
+	// Step 5: Refuse repositories that are not on the commit they are pinned to
+	verifyPins(prepared, logger)
+
+	// Step 6: Verify commit signatures, refusing unverified repositories unless they only warn
+	verifySignatures(prepared, logger)
+	available = AvailableRepositories(prepared)
+	if len(repos) > 0 && len(available) == 0 {
+		return prepared, fmt.Errorf("no repositories are usable: all require a newer rulem version, do not match their pinned commit, failed signature verification or failed to prepare")
+	}
+
 	if logger != nil {
 		logger.Info("Multi-repository preparation completed",
 			"total_repositories", len(repos),
@@ -278,6 +309,18 @@ func PrepareAllRepositories(ctx context.Context, repos []RepositoryEntry, logger
 	return prepared, nil
 }
 
+// VerifyRepositories runs the pin and signature checks of
+// PrepareAllRepositories again, for repositories being served while a
+// background sync moves their HEAD. It returns a copy of prepared in which the
+// repositories that now fail a check are unavailable, and reports whether any
+// repository was refused. Repositories that are unavailable already stay so.
+func VerifyRepositories(prepared []PreparedRepository, logger *logging.AppLogger) ([]PreparedRepository, bool) {
+	verified := append([]PreparedRepository(nil), prepared...)
+	verifyPins(verified, logger)
+	verifySignatures(verified, logger)
+	return verified, len(AvailableRepositories(verified)) < len(AvailableRepositories(prepared))
+}
+
 // AvailableRepositories filters a prepared repository list down to the
 // entries that were prepared successfully and can serve file operations.
 // Unavailable entries (failed preparation, e.g. a deleted local directory)
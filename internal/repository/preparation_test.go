@@ -181,6 +181,32 @@ func TestPrepareAllRepositories_SingleLocalRepo(t *testing.T) {
 	}
 }
 
+// TestPrepareAllRepositories_SharedWithOverlay tests that a shared repository gets its overlay prepared
+func TestPrepareAllRepositories_SharedWithOverlay(t *testing.T) {
+	shared := t.TempDir()
+	overlay := filepath.Join(t.TempDir(), "overlay")
+	logger, _ := logging.NewTestLogger()
+
+	repos := []RepositoryEntry{
+		{
+			ID:        "shared-rules-1234567890",
+			Name:      "Shared Rules",
+			Type:      RepositoryTypeLocal,
+			Path:      shared,
+			Overlay:   &overlay,
+			CreatedAt: 1234567890,
+		},
+	}
+
+	prepared, err := PrepareAllRepositories(context.Background(), repos, logger)
+	if err != nil {
+		t.Fatalf("PrepareAllRepositories failed: %v", err)
+	}
+	if prepared[0].LocalPath != shared || prepared[0].OverlayPath != overlay {
+		t.Errorf("expected shared %q with overlay %q, got %q with %q", shared, overlay, prepared[0].LocalPath, prepared[0].OverlayPath)
+	}
+}
+
 // TestPrepareAllRepositories_MultipleLocalRepos tests preparation of multiple local repositories
 func TestPrepareAllRepositories_MultipleLocalRepos(t *testing.T) {
 	tempDir1 := t.TempDir()
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+// Git hosting providers
+//
+// Remote repositories can be hosted on GitHub, GitLab or Bitbucket. The
+// provider is detected from the host of the remote URL, so self-hosted GitLab
+// and Bitbucket servers are recognised when their host name says so (e.g.
+// gitlab.company.com); any other host is treated as GitHub (Enterprise).
+//
+// Each provider has its own token format, HTTPS username convention and
+// credential store entry, so a GitHub PAT and a GitLab token can be stored
+// side by side.
+
+// Provider identifies the Git hosting service of a remote repository
+type Provider string
+
+const (
+	ProviderGitHub    Provider = "github"
+	ProviderGitLab    Provider = "gitlab"
+	ProviderBitbucket Provider = "bitbucket"
+)
+
+// DetectProvider returns the hosting provider of remoteURL (HTTPS or SSH).
+// URLs that cannot be parsed are treated as GitHub.
+func DetectProvider(remoteURL string) Provider {
+	host := remoteHost(remoteURL)
+	switch {
+	case host == "gitlab.com" || strings.Contains(host, "gitlab"):
+		return ProviderGitLab
+	case host == "bitbucket.org" || strings.Contains(host, "bitbucket"):
+		return ProviderBitbucket
+	default:
+		return ProviderGitHub
+	}
+}
+
+// remoteHost returns the lowercase host of an HTTPS or SSH remote URL, or ""
+func remoteHost(remoteURL string) string {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if matches := sshHostPattern.FindStringSubmatch(remoteURL); matches != nil {
+		return strings.ToLower(matches[1])
+	}
+	parsed, err := url.Parse(remoteURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// sshHostPattern matches the host of scp-like SSH URLs (git@host:path)
+var sshHostPattern = regexp.MustCompile(`^[\w.-]+@([^:/]+):`)
+
+// DisplayName returns the provider's name for messages, e.g. "GitLab"
+func (p Provider) DisplayName() string {
+	switch p {
+	case ProviderGitLab:
+		return "GitLab"
+	case ProviderBitbucket:
+		return "Bitbucket"
+	default:
+		return "GitHub"
+	}
+}
+
+// authGuidance tells the user where to configure the provider's token
+func (p Provider) authGuidance() string {
+	if p == ProviderGitHub {
+		return "configure authentication in Settings → GitHub Authentication"
+	}
+	return fmt.Sprintf("enter a %s token when adding the repository in Settings", p.DisplayName())
+}
+
+// tokenKey returns the credential store entry of the provider's token. The
+// GitHub entry keeps its original name, so stored PATs keep working.
+func (p Provider) tokenKey() string {
+	switch p {
+	case ProviderGitLab:
+		return "gitlab_token"
+	case ProviderBitbucket:
+		return "bitbucket_token"
+	default:
+		return githubTokenKey
+	}
+}
+
+// basicAuth returns the HTTPS credentials the provider expects for token:
+//   - GitHub: username "token" and the PAT
+//   - GitLab: username "oauth2" and the personal, project or group access token
+//   - Bitbucket: username "x-token-auth" and an access token, or the username
+//     and app password or API token of a "username:secret" token
+func (p Provider) basicAuth(token string) *http.BasicAuth {
+	switch p {
+	case ProviderGitLab:
+		return &http.BasicAuth{Username: "oauth2", Password: token}
+	case ProviderBitbucket:
+		if username, secret, ok := strings.Cut(token, ":"); ok {
+			return &http.BasicAuth{Username: username, Password: secret}
+		}
+		return &http.BasicAuth{Username: "x-token-auth", Password: token}
+	default:
+		// GitHub PAT authentication uses "token" as username
+		return &http.BasicAuth{Username: "token", Password: token}
+	}
+}
+
+// ValidateTokenFormat checks that token looks like a token of the provider,
+// without contacting it
+func (p Provider) ValidateTokenFormat(token string) error {
+	token = strings.TrimSpace(token)
+	switch p {
+	case ProviderGitLab:
+		return validateGitLabTokenFormat(token)
+	case ProviderBitbucket:
+		return validateBitbucketTokenFormat(token)
+	default:
+		return validateTokenFormat(token)
+	}
+}
+
+// validateGitLabTokenFormat validates GitLab personal, project and group
+// access tokens, which all start with glpat-
+func validateGitLabTokenFormat(token string) error {
+	if len(token) < 20 {
+		return fmt.Errorf("token too short (minimum 20 characters)")
+	}
+	if !strings.HasPrefix(token, "glpat-") {
+		return fmt.Errorf("token does not match expected GitLab access token format (should start with glpat-)")
+	}
+	return nil
+}
+
+// validateBitbucketTokenFormat validates Bitbucket repository, project and
+// workspace access tokens (ATCTT...), and app passwords (ATBB...) or API
+// tokens (ATATT...) given with their username as "username:secret"
+func validateBitbucketTokenFormat(token string) error {
+	if username, secret, ok := strings.Cut(token, ":"); ok {
+		if strings.TrimSpace(username) == "" {
+			return fmt.Errorf("username cannot be empty in username:app-password")
+		}
+		if !strings.HasPrefix(secret, "ATBB") && !strings.HasPrefix(secret, "ATATT") {
+			return fmt.Errorf("token does not match expected Bitbucket app password or API token format (should start with ATBB or ATATT)")
+		}
+		return nil
+	}
+	if len(token) < 20 {
+		return fmt.Errorf("token too short (minimum 20 characters)")
+	}
+	if !strings.HasPrefix(token, "ATCTT") {
+		return fmt.Errorf("token does not match expected Bitbucket access token format (should start with ATCTT, or be username:app-password)")
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/client"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+// Publishing local changes
+//
+// Clones are read-mostly caches of the remote, but rules are sometimes edited
+// in place. PushChanges commits the uncommitted changes of a clone and pushes
+// them to origin, so edits made locally reach every other machine through the
+// central repository. Only changes inside the repository's subpath are
+// published.
+//
+// A failed push undoes the commit with a mixed reset: the changes stay in the
+// working tree, where sync leaves them alone, instead of sitting in a local
+// commit that the next sync would hard-reset away.
+
+// defaultAuthorName is the commit author when git has no user.name configured
+const defaultAuthorName = "rulem"
+
+// ErrNothingToPush is returned by PushChanges when the clone has no changes to publish
+var ErrNothingToPush = errors.New("no local changes to publish")
+
+// PushResult reports the effect of PushChanges
+type PushResult struct {
+	// Commit is the hash of the pushed commit
+	Commit string
+	// Branch is the branch the commit was pushed to
+	Branch string
+	// Files are the published paths, relative to the repository root
+	Files []string
+}
+
+// CommitMessage generates a commit message describing changes
+func CommitMessage(changes []FileChange) string {
+	if len(changes) == 1 {
+		verb := "Update"
+		switch changes[0].Kind {
+		case FileChangeAdded, FileChangeUntracked:
+			verb = "Add"
+		case FileChangeDeleted:
+			verb = "Delete"
+		}
+		return fmt.Sprintf("%s %s", verb, changes[0].Path)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "Update %d rule files\n", len(changes))
+	for _, change := range changes {
+		kind := change.Kind
+		if kind == FileChangeUntracked {
+			kind = FileChangeAdded
+		}
+		fmt.Fprintf(&msg, "\n- %s %s", kind, change.Path)
+	}
+	return msg.String()
+}
+
+// PushChanges commits the uncommitted changes of the clone at gs.Path and
+// pushes the commit to the current branch on origin. An empty message is
+// replaced by CommitMessage. Returns ErrNothingToPush when there is nothing to
+// publish.
+func (gs GitSource) PushChanges(ctx context.Context, message string, logger *logging.AppLogger) (PushResult, error) {
+	var result PushResult
+
+	if _, inspecting := CurrentInspection(gs.Path); inspecting {
+		return result, fmt.Errorf("repository is inspecting an older commit - return to the branch before publishing")
+	}
+
+	repo, worktree, err := openWorktree(gs.Path)
+	if err != nil {
+		return result, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return result, fmt.Errorf("HEAD is detached - check out a branch before publishing")
+	}
+	result.Branch = head.Name().Short()
+
+	all, err := ListChangedFiles(gs.Path)
+	if err != nil {
+		return result, err
+	}
+	var changes []FileChange
+	for _, change := range all {
+		if _, ok := RelativeToSubpath(change.Path, gs.Subpath); ok {
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return result, ErrNothingToPush
+	}
+
+	for _, change := range changes {
+		if change.Kind == FileChangeDeleted {
+			_, err = worktree.Remove(change.Path)
+		} else {
+			_, err = worktree.Add(change.Path)
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to stage %s: %w", change.Path, err)
+		}
+		result.Files = append(result.Files, change.Path)
+	}
+
+	if strings.TrimSpace(message) == "" {
+		message = CommitMessage(changes)
+	}
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: commitAuthor(repo)})
+	if err != nil {
+		// Leave the changes unstaged, as they were found
+		_ = worktree.Reset(&git.ResetOptions{Mode: git.MixedReset, Commit: head.Hash()})
+		return result, fmt.Errorf("failed to commit changes: %w", err)
+	}
+	result.Commit = hash.String()
+
+	if err := gs.performPushWithAuth(ctx, repo, result.Branch, logger); err != nil {
+		if resetErr := worktree.Reset(&git.ResetOptions{Mode: git.MixedReset, Commit: head.Hash()}); resetErr != nil && logger != nil {
+			logger.Warn("Failed to undo unpublished commit", "commit", result.Commit[:8], "error", resetErr)
+		}
+		return PushResult{}, err
+	}
+
+	if logger != nil {
+		logger.Info("Published local changes", "path", gs.Path, "branch", result.Branch,
+			"commit", result.Commit[:8], "files", len(result.Files))
+	}
+	return result, nil
+}
+
+// performPushWithAuth pushes branch to origin, trying public access first and
+// retrying with the stored token on an authentication error
+func (gs GitSource) performPushWithAuth(ctx context.Context, repo *git.Repository, branch string, logger *logging.AppLogger) error {
+	err := gs.performPush(ctx, repo, branch, nil)
+	if err == nil || !gs.isAuthenticationError(err) {
+		return gs.translatePushError(err)
+	}
+
+	if logger != nil {
+		logger.Debug("Public push failed, trying with authentication")
+	}
+	auth, authErr := gs.getAuthentication(logger)
+	if authErr != nil {
+		return fmt.Errorf("%s authentication failed: %w", gs.provider().DisplayName(), authErr)
+	}
+	if auth == nil {
+		return gs.authRequiredError()
+	}
+	return gs.translatePushError(gs.performPush(ctx, repo, branch, auth))
+}
+
+// performPush pushes branch to the same branch on origin
+func (gs GitSource) performPush(ctx context.Context, repo *git.Repository, branch string, auth *http.BasicAuth) error {
+	ref := plumbing.NewBranchReferenceName(branch)
+	pushOpts := &git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{config.RefSpec(ref.String() + ":" + ref.String())},
+	}
+	if auth != nil {
+		pushOpts.ClientOptions = []client.Option{client.WithHTTPAuth(auth)}
+	}
+
+	// Bound the push so a hung connection can't block forever
+	opCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	err := repo.PushContext(opCtx, pushOpts)
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
+// translatePushError provides user-friendly error messages for push failures
+func (gs GitSource) translatePushError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if isContextError(err) {
+		return errTimedOutContactingRemote
+	}
+
+	errStr := strings.ToLower(err.Error())
+	if strings.Contains(errStr, "non-fast-forward") {
+		return fmt.Errorf("the remote has new commits - refresh the repository (stash & restore your changes) and publish again")
+	}
+	if gs.containsAuthErrorPatterns(err.Error()) {
+		provider := gs.provider()
+		if provider != ProviderGitHub {
+			return fmt.Errorf("%s token cannot push to this repository - it needs write access; please %s", provider.DisplayName(), provider.authGuidance())
+		}
+		return fmt.Errorf("GitHub token cannot push to this repository - it needs write access (Contents: read and write); please update it in Settings → GitHub Authentication")
+	}
+	return fmt.Errorf("failed to push changes: %w", err)
+}
+
+// commitAuthor returns the author for published commits, taken from the git
+// user.name and user.email settings when they are configured
+func commitAuthor(repo *git.Repository) *object.Signature {
+	author := &object.Signature{Name: defaultAuthorName, When: time.Now()}
+	if cfg, err := repo.ConfigScoped(config.GlobalScope); err == nil {
+		if cfg.User.Name != "" {
+			author.Name = cfg.User.Name
+		}
+		author.Email = cfg.User.Email
+	}
+	return author
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+func TestPushChanges(t *testing.T) {
+	origin, _, reader := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+
+	writeFile(t, reader, "README.md", "# edited\n")
+	writeFile(t, reader, "go.md", "# go\n")
+
+	gs := GitSource{Path: reader}
+	result, err := gs.PushChanges(context.Background(), "", logger)
+	if err != nil {
+		t.Fatalf("PushChanges: %v", err)
+	}
+	if len(result.Files) != 2 || result.Branch == "" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	originRepo, err := git.PlainOpen(origin)
+	if err != nil {
+		t.Fatalf("open origin: %v", err)
+	}
+	ref, err := originRepo.Reference(plumbing.NewBranchReferenceName(result.Branch), true)
+	if err != nil {
+		t.Fatalf("origin branch: %v", err)
+	}
+	if ref.Hash().String() != result.Commit {
+		t.Errorf("origin is at %s, want pushed commit %s", ref.Hash(), result.Commit)
+	}
+	commit, err := originRepo.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if want := "Update 2 rule files\n\n- M README.md\n- A go.md"; commit.Message != want {
+		t.Errorf("commit message = %q, want %q", commit.Message, want)
+	}
+
+	if changes, _ := ListChangedFiles(reader); len(changes) != 0 {
+		t.Errorf("working tree should be clean after publishing, got %v", changes)
+	}
+	if _, err := gs.PushChanges(context.Background(), "", logger); !errors.Is(err, ErrNothingToPush) {
+		t.Errorf("second PushChanges error = %v, want ErrNothingToPush", err)
+	}
+}
+
+func TestPushChanges_RejectedKeepsChanges(t *testing.T) {
+	_, writer, reader := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+
+	// The origin moves on, so the reader's push is not a fast-forward
+	commitFile(t, writer, "upstream.md", "# upstream\n")
+	pushToOrigin(t, writer)
+	writeFile(t, reader, "README.md", "# edited\n")
+
+	repo, err := git.PlainOpen(reader)
+	if err != nil {
+		t.Fatalf("open reader: %v", err)
+	}
+	before, err := repo.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+
+	gs := GitSource{Path: reader}
+	if _, err := gs.PushChanges(context.Background(), "edit readme", logger); err == nil {
+		t.Fatal("PushChanges should fail when the remote has new commits")
+	}
+
+	after, err := repo.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	if after.Hash() != before.Hash() {
+		t.Errorf("unpublished commit was not undone: HEAD moved from %s to %s", before.Hash(), after.Hash())
+	}
+	if got := readFile(t, reader, "README.md"); got != "# edited\n" {
+		t.Errorf("local change lost after failed push, got %q", got)
+	}
+}
+
+func TestPushChanges_OnlySubpath(t *testing.T) {
+	_, _, reader := setupOriginAndClone(t)
+	logger, _ := logging.NewTestLogger()
+
+	if err := os.MkdirAll(filepath.Join(reader, "rules"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, reader, "rules/go.md", "# go\n")
+	writeFile(t, reader, "README.md", "# edited\n")
+
+	gs := GitSource{Path: reader, Subpath: "rules"}
+	result, err := gs.PushChanges(context.Background(), "", logger)
+	if err != nil {
+		t.Fatalf("PushChanges: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0] != "rules/go.md" {
+		t.Errorf("published files = %v, want [rules/go.md]", result.Files)
+	}
+	if changes, _ := ListChangedFiles(reader); len(changes) != 1 || changes[0].Path != "README.md" {
+		t.Errorf("change outside the subpath should stay unpublished, got %v", changes)
+	}
+}
+
+func TestCommitMessage(t *testing.T) {
+	tests := []struct {
+		changes []FileChange
+		want    string
+	}{
+		{[]FileChange{{Path: "go/style.md", Kind: FileChangeModified}}, "Update go/style.md"},
+		{[]FileChange{{Path: "new.md", Kind: FileChangeUntracked}}, "Add new.md"},
+		{[]FileChange{{Path: "old.md", Kind: FileChangeDeleted}}, "Delete old.md"},
+		{
+			[]FileChange{{Path: "a.md", Kind: FileChangeModified}, {Path: "b.md", Kind: FileChangeDeleted}},
+			"Update 2 rule files\n\n- M a.md\n- D b.md",
+		},
+	}
+	for _, tt := range tests {
+		if got := CommitMessage(tt.changes); got != tt.want {
+			t.Errorf("CommitMessage(%v) = %q, want %q", tt.changes, got, tt.want)
+		}
+	}
+}
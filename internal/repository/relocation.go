@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+)
+
+// Storage relocation
+//
+// Relocate moves every repository to a new base directory in one operation:
+// all repositories are copied and verified first, then the caller commits the
+// new paths (saves the config), and only then are the originals removed. A
+// failure before the commit removes the copies and leaves everything as it
+// was. Per-repository state such as the rulem stash and inspection markers
+// lives inside .git with relative paths, so it moves with the copy. Branch
+// worktrees (see worktrees.go) move alongside their clone.
+
+// RelocationStep identifies a stage of Relocate, reported through its progress callback
+type RelocationStep int
+
+const (
+	// RelocationCopying copies a repository to its new path
+	RelocationCopying RelocationStep = iota
+	// RelocationVerifying compares a copy with its original
+	RelocationVerifying
+	// RelocationSaving commits the new paths
+	RelocationSaving
+	// RelocationCleaningUp removes the originals
+	RelocationCleaningUp
+	// RelocationDone indicates the relocation completed
+	RelocationDone
+)
+
+// String returns a human-readable description of the step
+func (s RelocationStep) String() string {
+	switch s {
+	case RelocationCopying:
+		return "Copying"
+	case RelocationVerifying:
+		return "Verifying"
+	case RelocationSaving:
+		return "Saving configuration"
+	case RelocationCleaningUp:
+		return "Removing old copies"
+	case RelocationDone:
+		return "Done"
+	default:
+		return "Unknown"
+	}
+}
+
+// RelocationMove describes moving one repository
+type RelocationMove struct {
+	// ID is the repository ID
+	ID string
+	// Name is the repository display name
+	Name string
+	// From is the current path
+	From string
+	// To is the path under the new base directory
+	To string
+	// Missing is true for a GitHub repository that was never cloned; only its
+	// path changes and it is cloned to To on the next sync
+	Missing bool
+	// Worktrees is true when the repository has branch worktrees, which move
+	// from WorktreesDir(From) to WorktreesDir(To)
+	Worktrees bool
+}
+
+// RelocationProgress reports where Relocate is
+type RelocationProgress struct {
+	// Step is the current stage
+	Step RelocationStep
+	// Repository is the name of the repository being copied or verified
+	Repository string
+	// Index is the 1-based position of Repository in the plan
+	Index int
+	// Total is the number of moves in the plan
+	Total int
+}
+
+// PlanRelocation works out where each repository goes under newBase: the last
+// element of its path, with a numeric suffix when two repositories share it.
+// Repositories already under newBase with that name are left out, and so is shared
+// storage (repositories with an overlay), which belongs to someone else.
+//
+// Returns an error if a repository contains newBase or another repository, a
+// destination already exists, or a local repository is missing.
+func PlanRelocation(repos []RepositoryEntry, newBase string) ([]RelocationMove, error) {
+	newBase = filepath.Clean(newBase)
+	if !filepath.IsAbs(newBase) {
+		return nil, fmt.Errorf("new storage directory must be an absolute path")
+	}
+
+	for _, repo := range repos {
+		if isWithin(newBase, repo.Path) {
+			return nil, fmt.Errorf("new storage directory is inside repository '%s'", repo.Name)
+		}
+		for _, other := range repos {
+			if other.ID != repo.ID && isWithin(other.Path, repo.Path) {
+				return nil, fmt.Errorf("repository '%s' is inside repository '%s' - move it to its own directory first", other.Name, repo.Name)
+			}
+		}
+	}
+
+	// Repositories already in place keep their paths
+	used := map[string]bool{}
+	for _, repo := range repos {
+		from := filepath.Clean(repo.Path)
+		if filepath.Dir(from) == newBase {
+			used[from] = true
+		}
+	}
+
+	var moves []RelocationMove
+	for _, repo := range repos {
+		from := filepath.Clean(repo.Path)
+		if filepath.Dir(from) == newBase || repo.IsShared() {
+			continue
+		}
+		name := filepath.Base(from)
+		to := filepath.Join(newBase, name)
+		for n := 2; used[to]; n++ {
+			to = filepath.Join(newBase, fmt.Sprintf("%s-%d", name, n))
+		}
+		used[to] = true
+
+		move := RelocationMove{ID: repo.ID, Name: repo.Name, From: from, To: to}
+		if _, err := os.Stat(from); err != nil {
+			if !os.IsNotExist(err) || !repo.IsRemote() {
+				return nil, fmt.Errorf("cannot move repository '%s': %w", repo.Name, err)
+			}
+			move.Missing = true
+		}
+		if _, err := os.Lstat(to); err == nil {
+			return nil, fmt.Errorf("cannot move repository '%s': %s already exists", repo.Name, to)
+		}
+		if info, err := os.Stat(WorktreesDir(from)); err == nil && info.IsDir() {
+			if isWithin(newBase, WorktreesDir(from)) {
+				return nil, fmt.Errorf("new storage directory is inside the worktrees of repository '%s'", repo.Name)
+			}
+			if _, err := os.Lstat(WorktreesDir(to)); err == nil {
+				return nil, fmt.Errorf("cannot move repository '%s': %s already exists", repo.Name, WorktreesDir(to))
+			}
+			move.Worktrees = true
+		}
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+// Relocate copies and verifies every move, calls commit to record the new
+// paths, and removes the originals. If anything fails before commit returns
+// successfully, the copies are removed and the originals are untouched.
+// progress, when non-nil, is called as each step starts.
+//
+// Returns the original paths that could not be removed after the commit;
+// the relocation itself succeeded and they only need manual cleanup.
+func Relocate(moves []RelocationMove, commit func() error, progress func(RelocationProgress), logger *logging.AppLogger) ([]string, error) {
+	report := func(step RelocationStep, name string, index int) {
+		if progress != nil {
+			progress(RelocationProgress{Step: step, Repository: name, Index: index, Total: len(moves)})
+		}
+	}
+
+	var copied []string
+	rollback := func() {
+		for _, path := range copied {
+			if err := os.RemoveAll(path); err != nil && logger != nil {
+				logger.Warn("Failed to remove relocated copy", "path", path, "error", err)
+			}
+		}
+	}
+
+	for i, move := range moves {
+		if move.Missing {
+			continue
+		}
+		report(RelocationCopying, move.Name, i+1)
+		if err := os.MkdirAll(filepath.Dir(move.To), 0755); err != nil {
+			rollback()
+			return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(move.To), err)
+		}
+		copied = append(copied, move.To)
+		if err := copyTree(move.From, move.To); err != nil {
+			rollback()
+			return nil, fmt.Errorf("failed to copy repository '%s': %w", move.Name, err)
+		}
+		if move.Worktrees {
+			copied = append(copied, WorktreesDir(move.To))
+			if err := copyTree(WorktreesDir(move.From), WorktreesDir(move.To)); err != nil {
+				rollback()
+				return nil, fmt.Errorf("failed to copy worktrees of repository '%s': %w", move.Name, err)
+			}
+		}
+
+		report(RelocationVerifying, move.Name, i+1)
+		if err := verifyCopy(move.From, move.To); err != nil {
+			rollback()
+			return nil, fmt.Errorf("copy of repository '%s' failed verification: %w", move.Name, err)
+		}
+		if move.Worktrees {
+			if err := verifyCopy(WorktreesDir(move.From), WorktreesDir(move.To)); err != nil {
+				rollback()
+				return nil, fmt.Errorf("copy of the worktrees of repository '%s' failed verification: %w", move.Name, err)
+			}
+		}
+	}
+
+	report(RelocationSaving, "", len(moves))
+	if err := commit(); err != nil {
+		rollback()
+		return nil, err
+	}
+
+	report(RelocationCleaningUp, "", len(moves))
+	var leftovers []string
+	for _, move := range moves {
+		if move.Missing {
+			continue
+		}
+		originals := []string{move.From}
+		if move.Worktrees {
+			originals = append(originals, WorktreesDir(move.From))
+		}
+		for _, path := range originals {
+			if err := os.RemoveAll(path); err != nil {
+				if logger != nil {
+					logger.Warn("Failed to remove relocated repository", "path", path, "error", err)
+				}
+				leftovers = append(leftovers, path)
+			}
+		}
+	}
+
+	report(RelocationDone, "", len(moves))
+	if logger != nil {
+		logger.Info("Relocated repositories", "count", len(moves), "leftovers", len(leftovers))
+	}
+	return leftovers, nil
+}
+
+// copyTree copies the directory src to dst, which must not exist, keeping
+// file modes and symlinks
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			// Keep directories writable by the owner so their contents can be copied
+			return os.Mkdir(target, info.Mode().Perm()|0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyRegularFile(path, target, info.Mode().Perm())
+		default:
+			return fmt.Errorf("unsupported file type: %s", rel)
+		}
+	})
+}
+
+// copyRegularFile streams src to a new file dst with mode perm
+func copyRegularFile(src, dst string, perm fs.FileMode) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// verifyCopy checks that dst holds the same entries as src with the same
+// content, and that a copied git repository opens and resolves HEAD
+func verifyCopy(src, dst string) error {
+	want, err := treeDigest(src)
+	if err != nil {
+		return err
+	}
+	got, err := treeDigest(dst)
+	if err != nil {
+		return err
+	}
+	if len(got) != len(want) {
+		return fmt.Errorf("copy has %d entries, original has %d", len(got), len(want))
+	}
+	for rel, digest := range want {
+		if !bytes.Equal(got[rel], digest) {
+			return fmt.Errorf("%s differs from the original", rel)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dst, git.GitDirName)); err == nil {
+		repo, err := git.PlainOpen(dst)
+		if err != nil {
+			return fmt.Errorf("copied repository cannot be opened: %w", err)
+		}
+		if _, err := repo.Head(); err != nil {
+			return fmt.Errorf("copied repository has no valid HEAD: %w", err)
+		}
+	}
+	return nil
+}
+
+// treeDigest maps every entry under root to a digest of its type and content
+func treeDigest(root string) (map[string][]byte, error) {
+	digests := map[string][]byte{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		switch {
+		case d.IsDir():
+			h.Write([]byte("dir"))
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			h.Write([]byte("link:" + link))
+		default:
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(h, f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+		digests[filepath.ToSlash(rel)] = h.Sum(nil)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+	return digests, nil
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
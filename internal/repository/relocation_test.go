@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"rulem/internal/logging"
+)
+
+// localEntry returns a local repository entry for path
+func localEntry(id, path string) RepositoryEntry {
+	return RepositoryEntry{ID: id, Name: id, Type: RepositoryTypeLocal, Path: path}
+}
+
+func TestPlanRelocation(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a/rules", "b/rules", "c/team", "new/placed", "new-taken/rules"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	path := func(rel string) string { return filepath.Join(root, rel) }
+	remote := func(id, rel string) RepositoryEntry {
+		entry := githubEntry(path(rel))
+		entry.ID = id
+		return entry
+	}
+
+	tests := []struct {
+		name    string
+		repos   []RepositoryEntry
+		newBase string
+		want    []RelocationMove
+		wantErr string
+	}{
+		{
+			name:    "name collision gets a suffix",
+			repos:   []RepositoryEntry{localEntry("a", path("a/rules")), localEntry("b", path("b/rules"))},
+			newBase: path("new"),
+			want: []RelocationMove{
+				{ID: "a", Name: "a", From: path("a/rules"), To: path("new/rules")},
+				{ID: "b", Name: "b", From: path("b/rules"), To: path("new/rules-2")},
+			},
+		},
+		{
+			name:    "repository already in place is skipped",
+			repos:   []RepositoryEntry{localEntry("p", path("new/placed")), localEntry("c", path("c/team"))},
+			newBase: path("new"),
+			want:    []RelocationMove{{ID: "c", Name: "c", From: path("c/team"), To: path("new/team")}},
+		},
+		{
+			name: "shared storage is skipped",
+			repos: []RepositoryEntry{func() RepositoryEntry {
+				entry := localEntry("shared", path("gone/shared"))
+				overlay := path("a/overlay")
+				entry.Overlay = &overlay
+				return entry
+			}(), localEntry("c", path("c/team"))},
+			newBase: path("new"),
+			want:    []RelocationMove{{ID: "c", Name: "c", From: path("c/team"), To: path("new/team")}},
+		},
+		{
+			name:    "missing clone only changes path",
+			repos:   []RepositoryEntry{remote("gh", "gone/team-rules")},
+			newBase: path("new"),
+			want:    []RelocationMove{{ID: "gh", Name: "Rules", From: path("gone/team-rules"), To: path("new/team-rules"), Missing: true}},
+		},
+		{
+			name:    "missing local repository",
+			repos:   []RepositoryEntry{localEntry("x", path("gone/x"))},
+			newBase: path("new"),
+			wantErr: "cannot move repository 'x'",
+		},
+		{
+			name:    "destination exists",
+			repos:   []RepositoryEntry{localEntry("a", path("a/rules"))},
+			newBase: path("new-taken"),
+			wantErr: "already exists",
+		},
+		{
+			name:    "new base inside a repository",
+			repos:   []RepositoryEntry{localEntry("a", path("a/rules"))},
+			newBase: path("a/rules/storage"),
+			wantErr: "inside repository 'a'",
+		},
+		{
+			name:    "nested repositories",
+			repos:   []RepositoryEntry{localEntry("outer", path("a")), localEntry("inner", path("a/rules"))},
+			newBase: path("new"),
+			wantErr: "'inner' is inside repository 'outer'",
+		},
+		{
+			name:    "relative base",
+			repos:   []RepositoryEntry{localEntry("a", path("a/rules"))},
+			newBase: "storage",
+			wantErr: "absolute path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moves, err := PlanRelocation(tt.repos, tt.newBase)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PlanRelocation() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanRelocation: %v", err)
+			}
+			if !reflect.DeepEqual(moves, tt.want) {
+				t.Errorf("PlanRelocation() = %+v, want %+v", moves, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelocate(t *testing.T) {
+	clone := historyRepo(t)
+	local := filepath.Join(t.TempDir(), "personal")
+	if err := os.MkdirAll(filepath.Join(local, "go"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, local, "go/style.md", "# style\n")
+	if err := os.Symlink("go/style.md", filepath.Join(local, "style.md")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	newBase := filepath.Join(t.TempDir(), "storage")
+	moves, err := PlanRelocation([]RepositoryEntry{githubEntry(clone), localEntry("personal", local)}, newBase)
+	if err != nil {
+		t.Fatalf("PlanRelocation: %v", err)
+	}
+
+	var steps []RelocationStep
+	committed := false
+	logger, _ := logging.NewTestLogger()
+	leftovers, err := Relocate(moves, func() error {
+		// Both copies exist and the originals are untouched when committing
+		for _, move := range moves {
+			if _, err := os.Stat(move.From); err != nil {
+				t.Errorf("original %s missing at commit: %v", move.From, err)
+			}
+			if _, err := os.Stat(move.To); err != nil {
+				t.Errorf("copy %s missing at commit: %v", move.To, err)
+			}
+		}
+		committed = true
+		return nil
+	}, func(p RelocationProgress) {
+		steps = append(steps, p.Step)
+	}, logger)
+	if err != nil {
+		t.Fatalf("Relocate: %v", err)
+	}
+	if !committed || len(leftovers) != 0 {
+		t.Fatalf("committed = %v, leftovers = %v", committed, leftovers)
+	}
+
+	wantSteps := []RelocationStep{RelocationCopying, RelocationVerifying, RelocationCopying, RelocationVerifying,
+		RelocationSaving, RelocationCleaningUp, RelocationDone}
+	if !reflect.DeepEqual(steps, wantSteps) {
+		t.Errorf("steps = %v, want %v", steps, wantSteps)
+	}
+
+	for _, move := range moves {
+		if _, err := os.Stat(move.From); !os.IsNotExist(err) {
+			t.Errorf("original %s should be removed, stat err = %v", move.From, err)
+		}
+	}
+	movedClone, movedLocal := moves[0].To, moves[1].To
+	if commits, err := ListCommits(movedClone, 10); err != nil || len(commits) != 3 {
+		t.Errorf("ListCommits on moved clone = %d commits, err %v", len(commits), err)
+	}
+	if changes, err := ListChangedFiles(movedClone); err != nil || len(changes) != 0 {
+		t.Errorf("moved clone should be clean, changes %v, err %v", changes, err)
+	}
+	if got := readFile(t, movedLocal, "style.md"); got != "# style\n" {
+		t.Errorf("symlinked style.md = %q", got)
+	}
+}
+
+func TestRelocate_CommitFails(t *testing.T) {
+	local := t.TempDir()
+	writeFile(t, local, "rule.md", "# rule\n")
+	moves, err := PlanRelocation([]RepositoryEntry{localEntry("personal", local)}, filepath.Join(t.TempDir(), "storage"))
+	if err != nil {
+		t.Fatalf("PlanRelocation: %v", err)
+	}
+
+	saveErr := errors.New("failed to save config")
+	if _, err := Relocate(moves, func() error { return saveErr }, nil, nil); !errors.Is(err, saveErr) {
+		t.Fatalf("Relocate() error = %v, want %v", err, saveErr)
+	}
+	if _, err := os.Stat(moves[0].To); !os.IsNotExist(err) {
+		t.Errorf("copy should be rolled back, stat err = %v", err)
+	}
+	if got := readFile(t, local, "rule.md"); got != "# rule\n" {
+		t.Errorf("original rule.md = %q", got)
+	}
+}
+
+func TestVerifyCopy(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(dst string)
+		wantErr string
+	}{
+		{name: "identical", tamper: func(string) {}},
+		{name: "changed content", tamper: func(dst string) { writeFile(t, dst, "rule.md", "# changed\n") }, wantErr: "rule.md differs"},
+		{name: "extra file", tamper: func(dst string) { writeFile(t, dst, "extra.md", "") }, wantErr: "entries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := t.TempDir()
+			writeFile(t, src, "rule.md", "# rule\n")
+			dst := filepath.Join(t.TempDir(), "copy")
+			if err := copyTree(src, dst); err != nil {
+				t.Fatalf("copyTree: %v", err)
+			}
+			tt.tamper(dst)
+
+			err := verifyCopy(src, dst)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("verifyCopy: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("verifyCopy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRelocationStep_String(t *testing.T) {
+	tests := []struct {
+		step RelocationStep
+		want string
+	}{
+		{RelocationCopying, "Copying"},
+		{RelocationVerifying, "Verifying"},
+		{RelocationSaving, "Saving configuration"},
+		{RelocationCleaningUp, "Removing old copies"},
+		{RelocationDone, "Done"},
+		{RelocationStep(99), "Unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.step.String(); got != tt.want {
+			t.Errorf("%d.String() = %q, want %q", tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestRelocate_Worktrees(t *testing.T) {
+	clone := historyRepo(t)
+	if err := os.MkdirAll(WorktreePath(clone, "experimental"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, WorktreePath(clone, "experimental"), "rule.md", "# experimental\n")
+
+	newBase := filepath.Join(t.TempDir(), "storage")
+	moves, err := PlanRelocation([]RepositoryEntry{githubEntry(clone)}, newBase)
+	if err != nil {
+		t.Fatalf("PlanRelocation: %v", err)
+	}
+	if len(moves) != 1 || !moves[0].Worktrees {
+		t.Fatalf("moves = %+v, want one move carrying the worktrees", moves)
+	}
+
+	if _, err := Relocate(moves, func() error { return nil }, nil, nil); err != nil {
+		t.Fatalf("Relocate: %v", err)
+	}
+	if got := readFile(t, WorktreePath(moves[0].To, "experimental"), "rule.md"); got != "# experimental\n" {
+		t.Errorf("moved worktree rule.md = %q", got)
+	}
+	if _, err := os.Stat(WorktreesDir(clone)); !os.IsNotExist(err) {
+		t.Errorf("original worktrees should be removed, stat err = %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/client"
+)
+
+// Renamed default branches
+//
+// Remotes rename their default branch (typically master → main) and delete the
+// old one. A clone configured for the old branch then fails to fetch; instead
+// of surfacing go-git's reference error, the fetch reports a BranchGoneError
+// naming the branch the remote HEAD now points to, so callers can offer to
+// switch to it (see GitSource.SwitchBranch).
+
+// BranchGoneError reports that a repository's configured branch no longer
+// exists on the remote
+type BranchGoneError struct {
+	// Branch is the configured branch
+	Branch string
+	// DefaultBranch is the branch the remote HEAD points to, empty when the
+	// remote does not advertise one
+	DefaultBranch string
+}
+
+// Error implements the error interface
+func (e *BranchGoneError) Error() string {
+	if e.DefaultBranch == "" || e.DefaultBranch == e.Branch {
+		return fmt.Sprintf("branch '%s' no longer exists on the remote - choose another branch in Settings", e.Branch)
+	}
+	return fmt.Sprintf("branch '%s' no longer exists on the remote, whose default branch is now '%s' - switch the repository to '%s' in Settings",
+		e.Branch, e.DefaultBranch, e.DefaultBranch)
+}
+
+// isMissingRemoteRef reports whether a fetch failed because a ref it asked for
+// does not exist on the remote
+func isMissingRemoteRef(err error) bool {
+	return strings.Contains(err.Error(), "couldn't find remote ref")
+}
+
+// branchGoneError builds the BranchGoneError for branch, asking the remote for
+// its default branch. Failing to list the remote only leaves DefaultBranch empty.
+func branchGoneError(ctx context.Context, remote *git.Remote, clientOpts []client.Option, branch string) error {
+	goneErr := &BranchGoneError{Branch: branch}
+	if refs, err := remote.ListContext(ctx, &git.ListOptions{ClientOptions: clientOpts}); err == nil {
+		goneErr.DefaultBranch = defaultBranchFromRefs(refs)
+	}
+	return goneErr
+}
+
+// trackBranch points the fetch refspec of a single-branch clone at branch, so
+// later syncs fetch the branch the clone is on. Clones that fetch every branch
+// are left alone.
+func trackBranch(repo *git.Repository, branch string) error {
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository config: %w", err)
+	}
+	remote, exists := cfg.Remotes["origin"]
+	if !exists || len(remote.Fetch) != 1 || remote.Fetch[0].IsWildcard() {
+		return nil
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("+%s:%s",
+		plumbing.NewBranchReferenceName(branch),
+		plumbing.NewRemoteReferenceName("origin", branch)))
+	if remote.Fetch[0] == refSpec {
+		return nil
+	}
+	remote.Fetch = []config.RefSpec{refSpec}
+	if err := repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update fetch refspec: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// renameOriginBranch renames branch from to to in the bare origin and points
+// its HEAD at the new name, like renaming the default branch on GitHub
+func renameOriginBranch(t *testing.T, origin, from, to string) {
+	t.Helper()
+	repo, err := git.PlainOpen(origin)
+	if err != nil {
+		t.Fatalf("open origin: %v", err)
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(from), true)
+	if err != nil {
+		t.Fatalf("resolve %s: %v", from, err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(to), ref.Hash())); err != nil {
+		t.Fatalf("create %s: %v", to, err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(to))); err != nil {
+		t.Fatalf("point HEAD at %s: %v", to, err)
+	}
+	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(from)); err != nil {
+		t.Fatalf("delete %s: %v", from, err)
+	}
+}
+
+func TestFetchUpdates_RenamedDefaultBranch(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	origin, writer, _ := setupOriginAndClone(t)
+
+	// Clone the configured branch only, as Prepare does
+	master := "master"
+	reader := filepath.Join(t.TempDir(), "reader")
+	if _, err := git.PlainClone(reader, &git.CloneOptions{
+		URL:           origin,
+		ReferenceName: plumbing.NewBranchReferenceName(master),
+		SingleBranch:  true,
+		Depth:         1,
+	}); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	renameOriginBranch(t, origin, "master", "main")
+
+	err := NewGitSource(origin, &master, reader).FetchUpdates(context.Background(), logger)
+	var goneErr *BranchGoneError
+	if !errors.As(err, &goneErr) {
+		t.Fatalf("expected BranchGoneError, got %v", err)
+	}
+	if goneErr.Branch != "master" || goneErr.DefaultBranch != "main" {
+		t.Errorf("got %+v, want master gone in favour of main", goneErr)
+	}
+
+	// Switching to the new default keeps later syncs on it
+	if _, err := NewGitSource(origin, &master, reader).SwitchBranch(context.Background(), goneErr.DefaultBranch, nil, logger); err != nil {
+		t.Fatalf("SwitchBranch: %v", err)
+	}
+	repo, err := git.PlainOpen(writer)
+	if err != nil {
+		t.Fatalf("open writer: %v", err)
+	}
+	commitFile(t, writer, "after.md", "# after the rename\n")
+	if err := repo.Push(&git.PushOptions{RefSpecs: []config.RefSpec{"refs/heads/master:refs/heads/main"}}); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+
+	mainBranch := "main"
+	if err := NewGitSource(origin, &mainBranch, reader).FetchUpdates(context.Background(), logger); err != nil {
+		t.Fatalf("FetchUpdates after switching: %v", err)
+	}
+	if got := readFile(t, reader, "after.md"); got != "# after the rename\n" {
+		t.Errorf("after.md = %q, want the commit pushed to main", got)
+	}
+}
+
+func TestBranchGoneError_Error(t *testing.T) {
+	renamed := &BranchGoneError{Branch: "master", DefaultBranch: "main"}
+	if got := renamed.Error(); got != "branch 'master' no longer exists on the remote, whose default branch is now 'main' - switch the repository to 'main' in Settings" {
+		t.Errorf("Error() = %q", got)
+	}
+	deleted := &BranchGoneError{Branch: "develop"}
+	if got := deleted.Error(); got != "branch 'develop' no longer exists on the remote - choose another branch in Settings" {
+		t.Errorf("Error() = %q", got)
+	}
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"rulem/internal/logging"
+)
+
+// Background sync
+//
+// While the TUI or the MCP server runs, a Scheduler can keep GitHub
+// repositories fresh by running SyncAllRepositories every interval (the
+// sync_interval setting). Repositories opt out with auto_sync: false. Runs
+// never overlap each other or a manual refresh (see syncMu), and the outcome
+// of the last run is kept for status screens.
+
+// MinSyncInterval is the shortest interval a Scheduler accepts, so a typo
+// cannot hammer the remotes
+const MinSyncInterval = time.Minute
+
+// syncMu serializes SyncAllRepositories, so a background run and a manual
+// refresh never fetch into the same clone at once
+var syncMu sync.Mutex
+
+// Scheduler periodically syncs a set of repositories in the background
+type Scheduler struct {
+	interval time.Duration
+	repos    []RepositoryEntry
+	logger   *logging.AppLogger
+
+	// sync runs one round, SyncAllRepositories outside tests
+	sync func(context.Context, []RepositoryEntry, *logging.AppLogger) []RepositorySyncResult
+
+	mu     sync.Mutex
+	status SchedulerStatus
+	onRun  func([]RepositorySyncResult)
+}
+
+// SchedulerStatus describes a Scheduler's runs
+type SchedulerStatus struct {
+	Interval time.Duration
+	Running  bool                   // A run is in progress
+	LastRun  time.Time              // When the last run finished, zero before the first
+	NextRun  time.Time              // When the next run starts, zero when stopped
+	Results  []RepositorySyncResult // Outcome of the last run, per repository
+}
+
+// NewScheduler creates a scheduler syncing every interval the GitHub
+// repositories among repos (and their worktrees) that have auto-sync enabled.
+// It does nothing until Start is called.
+func NewScheduler(interval time.Duration, repos []RepositoryEntry, logger *logging.AppLogger) (*Scheduler, error) {
+	if interval < MinSyncInterval {
+		return nil, fmt.Errorf("sync interval %s is too short (minimum %s)", interval, MinSyncInterval)
+	}
+
+	var scheduled []RepositoryEntry
+	for _, repo := range repos {
+		if repo.IsRemote() && repo.AutoSyncEnabled() {
+			scheduled = append(scheduled, repo)
+		}
+	}
+	return &Scheduler{
+		interval: interval,
+		repos:    WithWorktrees(scheduled),
+		logger:   logger,
+		sync:     SyncAllRepositories,
+		status:   SchedulerStatus{Interval: interval},
+	}, nil
+}
+
+// Repositories returns the repositories the scheduler syncs
+func (s *Scheduler) Repositories() []RepositoryEntry {
+	return s.repos
+}
+
+// OnRun registers fn to be called with the results after every run, e.g. to
+// reload rules. fn runs on the scheduler's goroutine.
+func (s *Scheduler) OnRun(fn func([]RepositorySyncResult)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRun = fn
+}
+
+// Start runs the scheduler in the background until ctx is done. The first run
+// starts one interval from now, since repositories are synced at startup.
+func (s *Scheduler) Start(ctx context.Context) {
+	if len(s.repos) == 0 {
+		if s.logger != nil {
+			s.logger.Info("Auto-sync has no repositories to sync")
+		}
+		return
+	}
+	if s.logger != nil {
+		s.logger.Info("Auto-sync started", "interval", s.interval, "repository_count", len(s.repos))
+	}
+
+	s.setNextRun(time.Now().Add(s.interval))
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				s.setNextRun(time.Time{})
+				return
+			case <-ticker.C:
+				s.RunOnce(ctx)
+				s.setNextRun(time.Now().Add(s.interval))
+			}
+		}
+	}()
+}
+
+// RunOnce syncs the scheduled repositories now and records the outcome
+func (s *Scheduler) RunOnce(ctx context.Context) []RepositorySyncResult {
+	s.mu.Lock()
+	s.status.Running = true
+	s.mu.Unlock()
+
+	results := s.sync(ctx, s.repos, s.logger)
+
+	s.mu.Lock()
+	s.status.Running = false
+	s.status.LastRun = time.Now()
+	s.status.Results = results
+	onRun := s.onRun
+	s.mu.Unlock()
+
+	if s.logger != nil {
+		s.logger.Info("Auto-sync run completed", "summary", summarizeSync(results))
+	}
+	if onRun != nil {
+		onRun(results)
+	}
+	return results
+}
+
+// setNextRun records when the next run starts
+func (s *Scheduler) setNextRun(next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.NextRun = next
+}
+
+// Status returns a snapshot of the scheduler's runs
+func (s *Scheduler) Status() SchedulerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status
+	status.Results = append([]RepositorySyncResult(nil), s.status.Results...)
+	return status
+}
+
+// Summary describes the outcome of the last run, e.g. "2 synced, 1 failed"
+func (st SchedulerStatus) Summary() string {
+	if st.LastRun.IsZero() {
+		return "not run yet"
+	}
+	return summarizeSync(st.Results)
+}
+
+// summarizeSync counts results by status, e.g. "2 synced, 1 failed"
+func summarizeSync(results []RepositorySyncResult) string {
+	var synced, failed, skipped int
+	for _, result := range results {
+		switch result.Status {
+		case SyncStatusSuccess:
+			synced++
+		case SyncStatusFailed:
+			failed++
+		case SyncStatusSkipped:
+			skipped++
+		}
+	}
+	parts := []string{fmt.Sprintf("%d synced", synced)}
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", failed))
+	}
+	if skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", skipped))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rulem/internal/logging"
+)
+
+func TestNewScheduler(t *testing.T) {
+	url := "https://github.com/owner/rules.git"
+	off := false
+	repos := []RepositoryEntry{
+		{ID: "local-1", Name: "Local", Type: RepositoryTypeLocal},
+		{ID: "team-2", Name: "Team", Type: RepositoryTypeGitHub, RemoteURL: &url},
+		{ID: "frozen-3", Name: "Frozen", Type: RepositoryTypeGitHub, RemoteURL: &url, AutoSync: &off},
+	}
+
+	scheduler, err := NewScheduler(30*time.Minute, repos, nil)
+	if err != nil {
+		t.Fatalf("NewScheduler: %v", err)
+	}
+	scheduled := scheduler.Repositories()
+	if len(scheduled) != 1 || scheduled[0].ID != "team-2" {
+		t.Errorf("Repositories() = %v, want only the GitHub repository with auto-sync enabled", scheduled)
+	}
+
+	if _, err := NewScheduler(10*time.Second, repos, nil); err == nil {
+		t.Error("NewScheduler() expected an error for an interval under a minute")
+	}
+}
+
+func TestScheduler_RunOnce(t *testing.T) {
+	url := "https://github.com/owner/rules.git"
+	repos := []RepositoryEntry{
+		{ID: "team-2", Name: "Team", Type: RepositoryTypeGitHub, RemoteURL: &url},
+		{ID: "docs-3", Name: "Docs", Type: RepositoryTypeGitHub, RemoteURL: &url},
+	}
+	scheduler, err := NewScheduler(time.Hour, repos, nil)
+	if err != nil {
+		t.Fatalf("NewScheduler: %v", err)
+	}
+	scheduler.sync = func(_ context.Context, repos []RepositoryEntry, _ *logging.AppLogger) []RepositorySyncResult {
+		return []RepositorySyncResult{
+			{RepositoryID: repos[0].ID, Status: SyncStatusSuccess},
+			{RepositoryID: repos[1].ID, Status: SyncStatusFailed, Error: errors.New("network unreachable")},
+		}
+	}
+
+	if summary := scheduler.Status().Summary(); summary != "not run yet" {
+		t.Errorf("Summary() before a run = %q", summary)
+	}
+
+	var notified []RepositorySyncResult
+	scheduler.OnRun(func(results []RepositorySyncResult) { notified = results })
+	scheduler.RunOnce(context.Background())
+
+	status := scheduler.Status()
+	if status.Running || status.LastRun.IsZero() || len(status.Results) != 2 {
+		t.Errorf("Status() = %+v, want the finished run", status)
+	}
+	if summary := status.Summary(); summary != "1 synced, 1 failed" {
+		t.Errorf("Summary() = %q, want %q", summary, "1 synced, 1 failed")
+	}
+	if len(notified) != 2 {
+		t.Errorf("OnRun callback got %d results, want 2", len(notified))
+	}
+}
+
+func TestScheduler_Start(t *testing.T) {
+	url := "https://github.com/owner/rules.git"
+	scheduler, err := NewScheduler(time.Minute, []RepositoryEntry{{ID: "team-2", Type: RepositoryTypeGitHub, RemoteURL: &url}}, nil)
+	if err != nil {
+		t.Fatalf("NewScheduler: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler.Start(ctx)
+	if next := scheduler.Status().NextRun; next.Before(time.Now()) {
+		t.Errorf("NextRun = %v, want one interval from now", next)
+	}
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for !scheduler.Status().NextRun.IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("scheduler did not stop when its context was cancelled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,382 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"rulem/internal/logging"
+	"rulem/pkg/fileops"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"golang.org/x/crypto/ssh"
+)
+
+// Commit signatures
+//
+// A GitHub repository with allowed_signers only trusts commits signed by the
+// keys in that file. After every sync the commit at the tip of the synced
+// branch is verified, the way `git merge --verify-signatures` checks the tip
+// of what it merges. The file uses git's gpg.ssh.allowedSignersFile format,
+// one SSH key per line:
+//
+//	alice@example.com ssh-ed25519 AAAAC3Nza...
+//	bob@example.com namespaces="git" ssh-rsa AAAAB3Nza...
+//
+// and may also hold armored OpenPGP public key blocks for GPG-signed commits.
+//
+// With signature_policy "block" (the default) a repository whose tip is not
+// signed by an allowed key is made unavailable, so its rules are not served;
+// with "warn" it stays available and the failure is reported.
+
+// Signature policies
+const (
+	SignaturePolicyBlock = "block" // Refuse to serve rules when verification fails
+	SignaturePolicyWarn  = "warn"  // Serve rules and report the failure
+)
+
+// sshSignatureNamespace is the namespace git signs commits in
+const sshSignatureNamespace = "git"
+
+// ErrUnsignedCommit is returned when the verified commit carries no signature
+var ErrUnsignedCommit = errors.New("commit is not signed")
+
+// SignatureCheck is the outcome of verifying the signature of a repository's
+// checked out commit
+type SignatureCheck struct {
+	Commit string // Full hash of the verified commit
+	Signer string // Principal or key identity that signed it, when verified
+	Err    error  // Why verification failed, nil when verified
+}
+
+// Verified reports whether the commit is signed by an allowed key
+func (c SignatureCheck) Verified() bool {
+	return c.Err == nil
+}
+
+// Summary describes the check in one line for status screens
+func (c SignatureCheck) Summary() string {
+	short := c.Commit
+	if len(short) > 8 {
+		short = short[:8]
+	}
+	if c.Err != nil {
+		if short == "" {
+			return fmt.Sprintf("signature not verified: %v", c.Err)
+		}
+		return fmt.Sprintf("signature of %s not verified: %v", short, c.Err)
+	}
+	return fmt.Sprintf("%s signed by %s", short, c.Signer)
+}
+
+// GetSignaturePolicy returns the signature policy, SignaturePolicyBlock when unset
+func (r RepositoryEntry) GetSignaturePolicy() string {
+	if r.SignaturePolicy == nil {
+		return SignaturePolicyBlock
+	}
+	return *r.SignaturePolicy
+}
+
+// validateSignatures checks the signature settings of a GitHub repository
+func (r RepositoryEntry) validateSignatures() error {
+	if r.AllowedSigners != nil && strings.TrimSpace(*r.AllowedSigners) == "" {
+		return fmt.Errorf("allowed_signers cannot be empty string (use nil for no verification)")
+	}
+	if r.SignaturePolicy != nil {
+		if r.AllowedSigners == nil {
+			return fmt.Errorf("signature_policy requires allowed_signers")
+		}
+		if policy := *r.SignaturePolicy; policy != SignaturePolicyBlock && policy != SignaturePolicyWarn {
+			return fmt.Errorf("signature_policy must be %q or %q, got %q", SignaturePolicyBlock, SignaturePolicyWarn, policy)
+		}
+	}
+	return nil
+}
+
+// VerifySignature checks that HEAD of the clone at repoPath is signed by one of
+// r's allowed signers. It returns false when r does not verify signatures.
+func (r RepositoryEntry) VerifySignature(repoPath string) (SignatureCheck, bool) {
+	if r.AllowedSigners == nil {
+		return SignatureCheck{}, false
+	}
+
+	signers, err := loadAllowedSigners(fileops.ExpandPath(*r.AllowedSigners))
+	if err != nil {
+		return SignatureCheck{Err: err}, true
+	}
+	return verifyHeadSignature(repoPath, signers), true
+}
+
+// allowedSigners holds the keys of an allowed-signers file
+type allowedSigners struct {
+	sshKeys    []allowedSSHKey
+	pgpKeyring string // Concatenated armored OpenPGP public key blocks
+}
+
+// allowedSSHKey is an SSH key line of an allowed-signers file
+type allowedSSHKey struct {
+	principals string
+	key        ssh.PublicKey
+}
+
+// loadAllowedSigners reads an allowed-signers file
+func loadAllowedSigners(path string) (allowedSigners, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return allowedSigners{}, fmt.Errorf("failed to read allowed signers: %w", err)
+	}
+
+	var signers allowedSigners
+	var pgp strings.Builder
+	inPGPBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case inPGPBlock:
+			pgp.WriteString(line + "\n")
+			inPGPBlock = !strings.HasPrefix(line, "-----END PGP PUBLIC KEY BLOCK-----")
+		case strings.HasPrefix(line, "-----BEGIN PGP PUBLIC KEY BLOCK-----"):
+			pgp.WriteString(line + "\n")
+			inPGPBlock = true
+		case line == "" || strings.HasPrefix(line, "#"):
+		default:
+			key, err := parseAllowedSSHKey(line)
+			if err != nil {
+				return allowedSigners{}, fmt.Errorf("allowed signers line %d: %w", lineNo, err)
+			}
+			if key != nil {
+				signers.sshKeys = append(signers.sshKeys, *key)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return allowedSigners{}, fmt.Errorf("failed to read allowed signers: %w", err)
+	}
+	if inPGPBlock {
+		return allowedSigners{}, fmt.Errorf("allowed signers: unterminated PGP public key block")
+	}
+	signers.pgpKeyring = pgp.String()
+
+	if len(signers.sshKeys) == 0 && signers.pgpKeyring == "" {
+		return allowedSigners{}, fmt.Errorf("allowed signers file %s has no keys", path)
+	}
+	return signers, nil
+}
+
+// parseAllowedSSHKey parses "principals [options] keytype key [comment]". It
+// returns nil for keys restricted to namespaces other than git.
+func parseAllowedSSHKey(line string) (*allowedSSHKey, error) {
+	principals, rest, ok := strings.Cut(line, " ")
+	if !ok {
+		return nil, fmt.Errorf("expected principals followed by a public key")
+	}
+	// What follows the principals is an authorized_keys line: options, then the key
+	key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(rest)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	for _, option := range options {
+		if value, ok := strings.CutPrefix(option, "namespaces="); ok {
+			namespaces := strings.Split(strings.Trim(value, `"`), ",")
+			if !slices.Contains(namespaces, sshSignatureNamespace) {
+				return nil, nil
+			}
+		}
+	}
+	return &allowedSSHKey{principals: principals, key: key}, nil
+}
+
+// verifyHeadSignature verifies the signature of the commit HEAD points to
+func verifyHeadSignature(repoPath string, signers allowedSigners) SignatureCheck {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return SignatureCheck{Err: fmt.Errorf("failed to open repository: %w", err)}
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return SignatureCheck{Err: fmt.Errorf("failed to resolve HEAD: %w", err)}
+	}
+	check := SignatureCheck{Commit: head.Hash().String()}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		check.Err = fmt.Errorf("failed to read HEAD commit: %w", err)
+		return check
+	}
+
+	switch signature := commit.Signature; {
+	case signature == "":
+		check.Err = ErrUnsignedCommit
+	case strings.HasPrefix(signature, "-----BEGIN SSH SIGNATURE-----"):
+		payload := &plumbing.MemoryObject{}
+		if err := commit.EncodeWithoutSignature(payload); err != nil {
+			check.Err = fmt.Errorf("failed to encode commit: %w", err)
+			return check
+		}
+		reader, err := payload.Reader()
+		if err != nil {
+			check.Err = fmt.Errorf("failed to encode commit: %w", err)
+			return check
+		}
+		message, err := io.ReadAll(reader)
+		if err != nil {
+			check.Err = fmt.Errorf("failed to encode commit: %w", err)
+			return check
+		}
+		check.Signer, check.Err = verifySSHSignature(message, signature, signers.sshKeys)
+	case signers.pgpKeyring == "":
+		check.Err = fmt.Errorf("commit is GPG-signed but the allowed signers file has no PGP keys")
+	default:
+		entity, err := commit.Verify(signers.pgpKeyring)
+		if err != nil {
+			check.Err = fmt.Errorf("not signed by an allowed key: %w", err)
+			return check
+		}
+		check.Signer = entity.PrimaryKey.KeyIdString()
+		if identity := entity.PrimaryIdentity(); identity != nil {
+			check.Signer = identity.Name
+		}
+	}
+	return check
+}
+
+// sshSignature is the SSHSIG wire format of an SSH signature, see
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+type sshSignature struct {
+	MagicPreamble [6]byte
+	Version       uint32
+	PublicKey     string
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     string
+}
+
+// sshSignedData is the blob an SSH signature signs
+type sshSignedData struct {
+	MagicPreamble [6]byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          string
+}
+
+// verifySSHSignature checks that armored is a git-namespace SSH signature of
+// message by one of keys, and returns the principals of the key that made it
+func verifySSHSignature(message []byte, armored string, keys []allowedSSHKey) (string, error) {
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return "", fmt.Errorf("malformed SSH signature")
+	}
+	var sig sshSignature
+	if err := ssh.Unmarshal(block.Bytes, &sig); err != nil {
+		return "", fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	if string(sig.MagicPreamble[:]) != "SSHSIG" || sig.Version != 1 {
+		return "", fmt.Errorf("unsupported SSH signature version")
+	}
+	if sig.Namespace != sshSignatureNamespace {
+		return "", fmt.Errorf("SSH signature is for namespace %q, not %q", sig.Namespace, sshSignatureNamespace)
+	}
+
+	publicKey, err := ssh.ParsePublicKey([]byte(sig.PublicKey))
+	if err != nil {
+		return "", fmt.Errorf("malformed SSH signature key: %w", err)
+	}
+	var principals string
+	for _, allowed := range keys {
+		if bytes.Equal(allowed.key.Marshal(), publicKey.Marshal()) {
+			principals = allowed.principals
+			break
+		}
+	}
+	if principals == "" {
+		return "", fmt.Errorf("signed by %s, which is not an allowed signer", ssh.FingerprintSHA256(publicKey))
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported SSH signature hash %q", sig.HashAlgorithm)
+	}
+	h.Write(message)
+
+	var signature ssh.Signature
+	if err := ssh.Unmarshal([]byte(sig.Signature), &signature); err != nil {
+		return "", fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	signed := sshSignedData{
+		Namespace:     sig.Namespace,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          string(h.Sum(nil)),
+	}
+	copy(signed.MagicPreamble[:], "SSHSIG")
+	if err := publicKey.Verify(ssh.Marshal(signed), &signature); err != nil {
+		return "", fmt.Errorf("SSH signature does not match the commit: %w", err)
+	}
+	return principals, nil
+}
+
+// verifySignatures verifies the signed commits of synced repositories with
+// allowed signers, making those that fail unavailable under the block policy
+func verifySignatures(prepared []PreparedRepository, logger *logging.AppLogger) {
+	for i := range prepared {
+		repo := prepared[i].Entry
+		if !prepared[i].IsAvailable() {
+			continue
+		}
+		check, ok := repo.VerifySignature(prepared[i].GitRoot())
+		if !ok {
+			continue
+		}
+		prepared[i].Signature = &check
+
+		if check.Verified() {
+			if logger != nil {
+				logger.Debug("Repository commit signature verified", "repository_id", repo.ID, "signer", check.Signer)
+			}
+			continue
+		}
+
+		if repo.GetSignaturePolicy() == SignaturePolicyWarn {
+			if logger != nil {
+				logger.Warn("Repository commit signature not verified, serving it anyway",
+					"repository_id", repo.ID,
+					"repository_name", repo.Name,
+					"error", check.Err,
+				)
+			}
+			continue
+		}
+
+		if logger != nil {
+			logger.Error("Repository refused: commit signature not verified",
+				"repository_id", repo.ID,
+				"repository_name", repo.Name,
+				"error", check.Err,
+			)
+		}
+		prepared[i].LocalPath = ""
+		prepared[i].SyncResult = RepositorySyncResult{
+			RepositoryID:   repo.ID,
+			RepositoryName: repo.Name,
+			Status:         SyncStatusFailed,
+			Error:          fmt.Errorf("refusing to serve rules: %s", check.Summary()),
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshCommitSigner signs commits the way `git commit -S` does with gpg.format=ssh
+type sshCommitSigner struct {
+	signer ssh.Signer
+}
+
+func (s sshCommitSigner) Sign(message io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(message)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha512.Sum512(data)
+	signed := sshSignedData{Namespace: sshSignatureNamespace, HashAlgorithm: "sha512", Hash: string(digest[:])}
+	copy(signed.MagicPreamble[:], "SSHSIG")
+	sig, err := s.signer.Sign(rand.Reader, ssh.Marshal(signed))
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := sshSignature{
+		Version:       1,
+		PublicKey:     string(s.signer.PublicKey().Marshal()),
+		Namespace:     sshSignatureNamespace,
+		HashAlgorithm: "sha512",
+		Signature:     string(ssh.Marshal(sig)),
+	}
+	copy(wrapped.MagicPreamble[:], "SSHSIG")
+	return pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: ssh.Marshal(wrapped)}), nil
+}
+
+func newSSHSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("signer: %v", err)
+	}
+	return signer
+}
+
+// commitSigned commits a file to the repo at repoPath, signed by signer. The
+// file names the signer, so each signer's commit changes it.
+func commitSigned(t *testing.T, repoPath string, signer ssh.Signer) {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("open repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "signed.md"), []byte("# signed by "+ssh.FingerprintSHA256(signer.PublicKey())+"\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := wt.Add("signed.md"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := wt.Commit("add signed.md", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Signer: sshCommitSigner{signer: signer},
+	}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+}
+
+// writeAllowedSigners writes an allowed-signers file listing signer as alice
+func writeAllowedSigners(t *testing.T, signer ssh.Signer) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "allowed_signers")
+	content := "# team keys\nalice@example.com " + string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write allowed signers: %v", err)
+	}
+	return path
+}
+
+func TestRepositoryEntry_VerifySignature(t *testing.T) {
+	_, writer, _ := setupOriginAndClone(t)
+	trusted := newSSHSigner(t)
+
+	repo := githubEntry(writer)
+	if _, ok := repo.VerifySignature(writer); ok {
+		t.Fatal("repository without allowed signers should not be verified")
+	}
+	repo.AllowedSigners = stringPtr(writeAllowedSigners(t, trusted))
+
+	check, _ := repo.VerifySignature(writer)
+	if !errors.Is(check.Err, ErrUnsignedCommit) {
+		t.Errorf("unsigned commit: got %v, want ErrUnsignedCommit", check.Err)
+	}
+
+	commitSigned(t, writer, newSSHSigner(t))
+	check, _ = repo.VerifySignature(writer)
+	if check.Err == nil || !strings.Contains(check.Err.Error(), "not an allowed signer") {
+		t.Errorf("commit by another key: got %v", check.Err)
+	}
+
+	commitSigned(t, writer, trusted)
+	check, _ = repo.VerifySignature(writer)
+	if !check.Verified() || check.Signer != "alice@example.com" {
+		t.Errorf("commit by allowed key: got %+v", check)
+	}
+	if summary := check.Summary(); !strings.HasSuffix(summary, "signed by alice@example.com") {
+		t.Errorf("Summary() = %q", summary)
+	}
+}
+
+func TestVerifySignatures_Policy(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	_, writer, _ := setupOriginAndClone(t)
+	allowed := writeAllowedSigners(t, newSSHSigner(t))
+
+	blocking := githubEntry(writer)
+	blocking.AllowedSigners = stringPtr(allowed)
+	warning := githubEntry(writer)
+	warning.ID = "rules-2"
+	warning.AllowedSigners = stringPtr(allowed)
+	warning.SignaturePolicy = stringPtr(SignaturePolicyWarn)
+
+	prepared := []PreparedRepository{
+		{Entry: blocking, LocalPath: writer},
+		{Entry: warning, LocalPath: writer},
+	}
+	verifySignatures(prepared, logger)
+
+	if prepared[0].IsAvailable() || !prepared[0].HasError() {
+		t.Errorf("unsigned repository was served under the block policy: %+v", prepared[0])
+	}
+	if !prepared[1].IsAvailable() {
+		t.Errorf("unsigned repository was refused under the warn policy: %v", prepared[1].SyncResult.Error)
+	}
+	for _, prep := range prepared {
+		if prep.Signature == nil || prep.Signature.Verified() {
+			t.Errorf("%s: signature check = %+v, want a failed check", prep.ID(), prep.Signature)
+		}
+	}
+}
+
+func TestParseAllowedSSHKey(t *testing.T) {
+	key := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(newSSHSigner(t).PublicKey())))
+
+	tests := []struct {
+		name      string
+		line      string
+		wantKey   bool
+		expectErr bool
+	}{
+		{name: "plain", line: "alice@example.com " + key, wantKey: true},
+		{name: "several principals", line: "alice@example.com,bob@example.com " + key + " laptop", wantKey: true},
+		{name: "git namespace", line: `alice@example.com namespaces="git,file" ` + key, wantKey: true},
+		{name: "other namespace", line: `alice@example.com namespaces="file" ` + key},
+		{name: "missing key", line: "alice@example.com", expectErr: true},
+		{name: "garbage key", line: "alice@example.com ssh-ed25519 not-base64", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAllowedSSHKey(tt.line)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (got != nil) != tt.wantKey {
+				t.Errorf("got key %v, want key: %v", got, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestRepositoryEntry_ValidateSignatures(t *testing.T) {
+	tests := []struct {
+		name      string
+		signers   *string
+		policy    *string
+		expectErr string
+	}{
+		{name: "signers", signers: stringPtr("~/.config/rulem/allowed_signers")},
+		{name: "warn policy", signers: stringPtr("/tmp/allowed_signers"), policy: stringPtr(SignaturePolicyWarn)},
+		{name: "empty signers", signers: stringPtr(" "), expectErr: "allowed_signers cannot be empty"},
+		{name: "policy without signers", policy: stringPtr(SignaturePolicyBlock), expectErr: "requires allowed_signers"},
+		{name: "unknown policy", signers: stringPtr("/tmp/allowed_signers"), policy: stringPtr("ignore"), expectErr: "signature_policy must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := githubEntry("/opt/rules")
+			repo.AllowedSigners = tt.signers
+			repo.SignaturePolicy = tt.policy
+
+			err := repo.validateSignatures()
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("expected error containing %q, got: %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyRepositories_SyncToUnsignedCommit(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	_, writer, reader := setupOriginAndClone(t)
+	trusted := newSSHSigner(t)
+	commitSigned(t, writer, trusted)
+	pushToOrigin(t, writer)
+
+	gs := GitSource{Path: reader}
+	if err := gs.FetchUpdates(context.Background(), logger); err != nil {
+		t.Fatalf("FetchUpdates: %v", err)
+	}
+	entry := githubEntry(reader)
+	entry.AllowedSigners = stringPtr(writeAllowedSigners(t, trusted))
+	prepared := []PreparedRepository{{Entry: entry, LocalPath: reader}}
+
+	verified, refused := VerifyRepositories(prepared, logger)
+	if refused || !verified[0].IsAvailable() {
+		t.Fatalf("signed repository was refused: %v", verified[0].SyncResult.Error)
+	}
+
+	// The remote's next commit is not signed
+	commitFile(t, writer, "injected.md", "# injected\n")
+	pushToOrigin(t, writer)
+	if err := gs.FetchUpdates(context.Background(), logger); err != nil {
+		t.Fatalf("FetchUpdates: %v", err)
+	}
+
+	verified, refused = VerifyRepositories(verified, logger)
+	if !refused || verified[0].IsAvailable() || !verified[0].HasError() {
+		t.Fatalf("repository synced to an unsigned commit is still served: %+v", verified[0])
+	}
+	if !prepared[0].IsAvailable() {
+		t.Error("VerifyRepositories should not modify its argument")
+	}
+
+	// It stays unavailable, and is not reported as refused again
+	if again, refused := VerifyRepositories(verified, logger); refused || again[0].IsAvailable() {
+		t.Errorf("VerifyRepositories again = available %v, refused %v", again[0].IsAvailable(), refused)
+	}
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+)
+
+// Subdirectory repositories
+//
+// Rules often live in one directory of a much larger repository, such as an
+// organisation's monorepo. A GitHub repository with a subpath is cloned with a
+// sparse checkout of just that directory, and only the directory is exposed:
+// PreparedRepository.LocalPath points at it, so scanning, serving and saving
+// never see the rest of the repository. Git operations that need the clone
+// itself use GitRoot, and paths from git history are relative to the subpath
+// once passed through RelativeToSubpath.
+
+// GetSubpath returns the directory of the repository holding its rules,
+// slash-separated, or empty string for the whole repository.
+func (r RepositoryEntry) GetSubpath() string {
+	if r.Subpath != nil {
+		return *r.Subpath
+	}
+	return ""
+}
+
+// validateSubpath checks that the subpath is a directory inside the repository
+func (r RepositoryEntry) validateSubpath() error {
+	if r.Subpath == nil {
+		return nil
+	}
+	clean, err := CleanSubpath(*r.Subpath)
+	if err != nil {
+		return err
+	}
+	if clean == "" {
+		return fmt.Errorf("subpath cannot be empty string (use nil for the whole repository)")
+	}
+	return nil
+}
+
+// CleanSubpath normalizes a subpath to a slash-separated path relative to the
+// repository root, e.g. "./teams/platform/" becomes "teams/platform". It
+// returns "" for the repository root.
+func CleanSubpath(subpath string) (string, error) {
+	subpath = strings.TrimSpace(subpath)
+	if subpath == "" {
+		return "", nil
+	}
+	clean := path.Clean(filepath.ToSlash(subpath))
+	if clean == "." {
+		return "", nil
+	}
+	if !filepath.IsLocal(filepath.FromSlash(clean)) || clean == git.GitDirName || strings.HasPrefix(clean, git.GitDirName+"/") {
+		return "", fmt.Errorf("invalid subpath %q: must be a directory inside the repository", subpath)
+	}
+	return clean, nil
+}
+
+// RelativeToSubpath returns file, a slash-separated path relative to the
+// repository root, relative to subpath instead. ok is false when file lies
+// outside subpath.
+func RelativeToSubpath(file, subpath string) (string, bool) {
+	subpath, err := CleanSubpath(subpath)
+	if err != nil {
+		return "", false
+	}
+	if subpath == "" {
+		return file, true
+	}
+	rel, found := strings.CutPrefix(file, subpath+"/")
+	return rel, found && rel != ""
+}
+
+// GitRoot returns the root of the repository's clone, which is LocalPath
+// unless the repository exposes only a subpath of it
+func (pr PreparedRepository) GitRoot() string {
+	subpath := pr.Entry.GetSubpath()
+	if subpath == "" || pr.LocalPath == "" {
+		return pr.LocalPath
+	}
+	clean, err := CleanSubpath(subpath)
+	if err != nil || clean == "" {
+		return pr.LocalPath
+	}
+	return strings.TrimSuffix(pr.LocalPath, string(filepath.Separator)+filepath.FromSlash(clean))
+}
+
+// sparseDirs returns the directories a sparse checkout of gs covers, nil to
+// check out everything
+func (gs GitSource) sparseDirs() []string {
+	if gs.Subpath == "" {
+		return nil
+	}
+	return []string{gs.Subpath}
+}
+
+// sparseCheckout checks out only dirs of the branch a NoCheckout clone of repo
+// is on
+func sparseCheckout(repo *git.Repository, dirs []string) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get working tree: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD after clone: %w", err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{
+		Branch:                    head.Name(),
+		Force:                     true,
+		SparseCheckoutDirectories: dirs,
+	}); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", strings.Join(dirs, ", "), err)
+	}
+	return nil
+}
+
+// exposedPath returns the directory of the clone at clonePath that is exposed,
+// checking that the subpath exists in the checked-out branch
+func (gs GitSource) exposedPath(clonePath string) (string, error) {
+	if gs.Subpath == "" {
+		return clonePath, nil
+	}
+	dir := filepath.Join(clonePath, filepath.FromSlash(gs.Subpath))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("subpath %q not found in the repository: check the subpath setting and branch", gs.Subpath)
+	}
+	return dir, nil
+}
+
+// cleanWithin reports whether the worktree status has no changes inside the
+// sparse checkout. Files outside it are never checked out, so they do not
+// count as local changes.
+func (gs GitSource) cleanWithin(status git.Status) bool {
+	if gs.Subpath == "" {
+		return status.IsClean()
+	}
+	for file, fileStatus := range status {
+		if fileStatus.Worktree == git.Unmodified && fileStatus.Staging == git.Unmodified {
+			continue
+		}
+		if _, inside := RelativeToSubpath(file, gs.Subpath); inside {
+			return false
+		}
+	}
+	return true
+}
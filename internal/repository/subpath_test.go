@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v6"
+)
+
+func TestCleanSubpath(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "", want: ""},
+		{input: ".", want: ""},
+		{input: "rules", want: "rules"},
+		{input: "./teams/platform/", want: "teams/platform"},
+		{input: "  teams//platform ", want: "teams/platform"},
+		{input: "../other", wantErr: true},
+		{input: "teams/../../other", wantErr: true},
+		{input: "/etc", wantErr: true},
+		{input: ".git", wantErr: true},
+		{input: ".git/hooks", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := CleanSubpath(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("CleanSubpath(%q) = %q, want an error", tt.input, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("CleanSubpath(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelativeToSubpath(t *testing.T) {
+	tests := []struct {
+		file, subpath string
+		want          string
+		wantOK        bool
+	}{
+		{file: "go/style.md", subpath: "", want: "go/style.md", wantOK: true},
+		{file: "rules/go/style.md", subpath: "rules", want: "go/style.md", wantOK: true},
+		{file: "rules/go/style.md", subpath: "./rules/", want: "go/style.md", wantOK: true},
+		{file: "rules-old/style.md", subpath: "rules", wantOK: false},
+		{file: "README.md", subpath: "rules", wantOK: false},
+		{file: "rules", subpath: "rules", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := RelativeToSubpath(tt.file, tt.subpath)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("RelativeToSubpath(%q, %q) = %q, %v; want %q, %v", tt.file, tt.subpath, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestValidateSubpath(t *testing.T) {
+	remote := "https://github.com/org/monorepo.git"
+	valid := "teams/platform/rules"
+	empty := " "
+	escaping := "../rules"
+
+	github := RepositoryEntry{Type: RepositoryTypeGitHub, RemoteURL: &remote, Path: "/tmp/monorepo"}
+	for _, tt := range []struct {
+		subpath *string
+		wantErr string
+	}{
+		{subpath: nil},
+		{subpath: &valid},
+		{subpath: &empty, wantErr: "subpath cannot be empty"},
+		{subpath: &escaping, wantErr: "invalid subpath"},
+	} {
+		github.Subpath = tt.subpath
+		err := github.ValidateTypeSpecificFields()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("subpath %v: unexpected error %v", github.GetSubpath(), err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("subpath %q: error = %v, want %q", github.GetSubpath(), err, tt.wantErr)
+		}
+	}
+
+	local := RepositoryEntry{Type: RepositoryTypeLocal, Path: "/tmp/rules", Subpath: &valid}
+	if err := local.ValidateTypeSpecificFields(); err == nil || !strings.Contains(err.Error(), "should not have a subpath") {
+		t.Errorf("local repository with a subpath: error = %v", err)
+	}
+}
+
+func TestPreparedRepository_GitRoot(t *testing.T) {
+	subpath := "./teams/platform/"
+	clone := filepath.Join(t.TempDir(), "monorepo")
+
+	whole := PreparedRepository{Entry: RepositoryEntry{Type: RepositoryTypeGitHub}, LocalPath: clone}
+	if got := whole.GitRoot(); got != clone {
+		t.Errorf("GitRoot() without subpath = %q, want %q", got, clone)
+	}
+
+	sub := PreparedRepository{
+		Entry:     RepositoryEntry{Type: RepositoryTypeGitHub, Subpath: &subpath},
+		LocalPath: filepath.Join(clone, "teams", "platform"),
+	}
+	if got := sub.GitRoot(); got != clone {
+		t.Errorf("GitRoot() with subpath = %q, want %q", got, clone)
+	}
+}
+
+func TestWithWorktrees_KeepsSubpath(t *testing.T) {
+	remote := "https://github.com/org/monorepo.git"
+	subpath := "rules"
+	repo := RepositoryEntry{
+		ID: "monorepo-3f9a0c12", Name: "Monorepo", Type: RepositoryTypeGitHub, CreatedAt: 1,
+		Path: "/tmp/monorepo", RemoteURL: &remote, Subpath: &subpath, Worktrees: []string{"next"},
+	}
+	entries := WithWorktrees([]RepositoryEntry{repo})
+	if len(entries) != 2 || entries[1].GetSubpath() != "rules" {
+		t.Errorf("worktree entry should serve the same subpath, got %+v", entries)
+	}
+}
+
+func TestGitSource_ExposedPath(t *testing.T) {
+	clone := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(clone, "teams", "platform"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	gs := GitSource{Path: clone}
+	if got, err := gs.exposedPath(clone); err != nil || got != clone {
+		t.Errorf("exposedPath() without subpath = %q, %v; want the clone", got, err)
+	}
+
+	gs.Subpath = "teams/platform"
+	if got, err := gs.exposedPath(clone); err != nil || got != filepath.Join(clone, "teams", "platform") {
+		t.Errorf("exposedPath() = %q, %v; want the subpath directory", got, err)
+	}
+
+	gs.Subpath = "teams/missing"
+	if _, err := gs.exposedPath(clone); err == nil || !strings.Contains(err.Error(), `subpath "teams/missing" not found`) {
+		t.Errorf("exposedPath() for a missing subpath: error = %v", err)
+	}
+}
+
+func TestGitSource_CleanWithin(t *testing.T) {
+	status := git.Status{
+		"README.md":            {Staging: git.Unmodified, Worktree: git.Deleted},
+		"services/api/main.go": {Staging: git.Unmodified, Worktree: git.Modified},
+	}
+
+	if (GitSource{}).cleanWithin(status) {
+		t.Error("without a subpath every change counts")
+	}
+	if !(GitSource{Subpath: "rules"}).cleanWithin(status) {
+		t.Error("changes outside the subpath should not count")
+	}
+
+	status["rules/go.md"] = &git.FileStatus{Staging: git.Unmodified, Worktree: git.Modified}
+	if (GitSource{Subpath: "rules"}).cleanWithin(status) {
+		t.Error("a change inside the subpath should count")
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"rulem/internal/logging"
@@ -42,7 +43,7 @@ func (s SyncStatus) String() string {
 // RepositorySyncResult contains the outcome of synchronizing a single repository.
 // It provides detailed status information for UI display and error reporting.
 type RepositorySyncResult struct {
-	// RepositoryID is the unique identifier of the repository (e.g., "personal-rules-1728756432")
+	// RepositoryID is the unique identifier of the repository (e.g., "personal-rules-3f9a0c12")
 	RepositoryID string
 
 	// RepositoryName is the user-friendly display name (e.g., "Personal Rules")
@@ -93,9 +94,10 @@ func (r *RepositorySyncResult) GetMessage() string {
 //
 // The function performs the following for each repository:
 // 1. Check if it's a GitHub repository (skip if local)
-// 2. Check for uncommitted changes (skip if dirty)
+// 2. Check for uncommitted changes (skip if dirty) or an inspected commit (skip)
 // 3. Fetch updates from the remote (fail on error)
-// 4. Track duration and status for each operation
+// 4. Run maintenance when it is due (see RunMaintenance; failures are only logged)
+// 5. Track duration and status for each operation
 //
 // Parameters:
 //   - ctx: Context for cancellation across all repos
@@ -112,6 +114,10 @@ func (r *RepositorySyncResult) GetMessage() string {
 //	    fmt.Printf("%s: %s\n", result.RepositoryName, result.GetMessage())
 //	}
 func SyncAllRepositories(ctx context.Context, repos []RepositoryEntry, logger *logging.AppLogger) []RepositorySyncResult {
+	// A background run (see Scheduler) and a manual refresh wait for each other
+	syncMu.Lock()
+	defer syncMu.Unlock()
+
 	if logger != nil {
 		logger.Info("Starting multi-repository sync", "repository_count", len(repos))
 	}
@@ -121,6 +127,9 @@ func SyncAllRepositories(ctx context.Context, repos []RepositoryEntry, logger *l
 	for _, repo := range repos {
 		result := syncSingleRepository(ctx, repo, logger)
 		results = append(results, result)
+		if result.Status == SyncStatusSuccess {
+			notifySynced(repo, result)
+		}
 
 		if logger != nil {
 			logger.Info("Repository sync completed",
@@ -157,6 +166,30 @@ func SyncAllRepositories(ctx context.Context, repos []RepositoryEntry, logger *l
 	return results
 }
 
+// syncObserver is called after every successful sync, see OnSync
+var syncObserver struct {
+	mu sync.Mutex
+	fn func(RepositoryEntry, RepositorySyncResult)
+}
+
+// OnSync registers fn to be called after each GitHub repository is synced
+// successfully, e.g. to run the post-sync hooks of the configuration. A nil fn
+// removes the observer.
+func OnSync(fn func(RepositoryEntry, RepositorySyncResult)) {
+	syncObserver.mu.Lock()
+	defer syncObserver.mu.Unlock()
+	syncObserver.fn = fn
+}
+
+func notifySynced(repo RepositoryEntry, result RepositorySyncResult) {
+	syncObserver.mu.Lock()
+	fn := syncObserver.fn
+	syncObserver.mu.Unlock()
+	if fn != nil {
+		fn(repo, result)
+	}
+}
+
 // syncSingleRepository synchronizes a single repository and returns the result.
 // This is an internal helper function used by SyncAllRepositories.
 func syncSingleRepository(ctx context.Context, repo RepositoryEntry, logger *logging.AppLogger) RepositorySyncResult {
@@ -191,8 +224,17 @@ func syncSingleRepository(ctx context.Context, repo RepositoryEntry, logger *log
 		return result
 	}
 
+	if _, inspecting := CurrentInspection(repo.Path); inspecting {
+		result.Status = SyncStatusSkipped
+		result.SkipReason = "inspecting an older commit"
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
 	// Perform sync operation
 	gitSource := NewGitSource(*repo.RemoteURL, repo.Branch, repo.Path)
+	gitSource.Tag, gitSource.Commit = repo.GetTag(), repo.GetCommit()
+	gitSource.RepositoryID = repo.ID
 	err = gitSource.FetchUpdates(ctx, logger)
 	if err != nil {
 		result.Status = SyncStatusFailed
@@ -201,6 +243,13 @@ func syncSingleRepository(ctx context.Context, repo RepositoryEntry, logger *log
 		return result
 	}
 
+	// Keep long-lived clones compact; a failure here does not fail the sync
+	if MaintenanceDue(repo.Path) {
+		if _, err := RunMaintenance(ctx, repo.Path, logger); err != nil && logger != nil {
+			logger.Warn("Scheduled repository maintenance failed", "repository", repo.Name, "error", err)
+		}
+	}
+
 	// Success
 	result.Status = SyncStatusSuccess
 	result.Duration = time.Since(startTime)
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Sync marker
+//
+// Updating a clone's working tree is not atomic: while a sync checks out a new
+// commit, some rule files are already new and others still old. A sync keeps a
+// marker in .git/rulem-syncing while it fetches and updates the working tree,
+// so readers in this or another process, such as the MCP server reloading its
+// rules, can wait for it to finish instead of reading a mix of both commits.
+// A marker older than maxSyncMarkerAge was left by a sync that never finished,
+// e.g. because rulem was killed, and is ignored.
+
+const (
+	// syncMarkerName is the file inside .git present while a sync updates the clone
+	syncMarkerName = "rulem-syncing"
+	// maxSyncMarkerAge bounds how long a marker is honoured; it comfortably
+	// exceeds the network timeouts of a sync
+	maxSyncMarkerAge = 5 * time.Minute
+)
+
+// markSyncing creates the sync marker of the clone at gitRoot and returns a
+// function removing it. Clones without a .git directory get no marker.
+func markSyncing(gitRoot string) func() {
+	path := filepath.Join(gitRoot, ".git", syncMarkerName)
+	if err := os.WriteFile(path, fmt.Appendf(nil, "%d\n", os.Getpid()), 0o644); err != nil {
+		return func() {}
+	}
+	return func() { _ = os.Remove(path) }
+}
+
+// SyncInProgress reports whether a sync, in this or another process, is
+// updating the clone at gitRoot
+func SyncInProgress(gitRoot string) bool {
+	info, err := os.Stat(filepath.Join(gitRoot, ".git", syncMarkerName))
+	return err == nil && time.Since(info.ModTime()) < maxSyncMarkerAge
+}
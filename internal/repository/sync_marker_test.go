@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSyncMarker(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	if SyncInProgress(root) {
+		t.Fatal("no sync should be in progress before one starts")
+	}
+	done := markSyncing(root)
+	if !SyncInProgress(root) {
+		t.Error("a sync should be in progress while marked")
+	}
+	done()
+	if SyncInProgress(root) {
+		t.Error("no sync should be in progress once done")
+	}
+
+	// A marker left behind by a sync that never finished is ignored
+	markSyncing(root)
+	stale := time.Now().Add(-2 * maxSyncMarkerAge)
+	if err := os.Chtimes(filepath.Join(root, ".git", syncMarkerName), stale, stale); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if SyncInProgress(root) {
+		t.Error("a stale marker should be ignored")
+	}
+
+	// Directories that are not clones get no marker
+	plain := t.TempDir()
+	markSyncing(plain)()
+	if SyncInProgress(plain) {
+		t.Error("a directory without .git should never be syncing")
+	}
+}
@@ -601,3 +601,25 @@ func TestSyncAllRepositories_WithOptionalBranch(t *testing.T) {
 		t.Errorf("expected repository ID 'branch-repo-123', got %q", result.RepositoryID)
 	}
 }
+
+func TestSyncAllRepositories_OnSync(t *testing.T) {
+	origin, _, reader := setupOriginAndClone(t)
+	repos := []RepositoryEntry{
+		{ID: "local-1", Name: "Local", Type: RepositoryTypeLocal, Path: t.TempDir()},
+		{ID: "team-2", Name: "Team", Type: RepositoryTypeGitHub, Path: reader, RemoteURL: &origin},
+	}
+
+	var synced []string
+	OnSync(func(repo RepositoryEntry, result RepositorySyncResult) {
+		synced = append(synced, repo.ID+" "+result.Status.String())
+	})
+	defer OnSync(nil)
+
+	logger, _ := logging.NewTestLogger()
+	SyncAllRepositories(context.Background(), repos, logger)
+
+	// Only successful syncs are reported, not skipped local repositories
+	if len(synced) != 1 || synced[0] != "team-2 "+SyncStatusSuccess.String() {
+		t.Errorf("OnSync called for %v, want only team-2", synced)
+	}
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// Repository templates
+//
+// A team standing up its own rules repository can start from a template
+// instead of an empty repository. CreateFromTemplate clones the template,
+// fills in placeholders such as {{team}} and {{org}} in file contents and
+// names, and pushes the result to a new, empty remote as a single commit
+// without the template's history. The new repository is then added like any
+// other GitHub repository, which clones it.
+
+// DefaultTemplateBranch is the branch created on the new repository when none is given
+const DefaultTemplateBranch = "main"
+
+// ErrTemplateTargetNotEmpty is returned by CreateFromTemplate when the new
+// repository already has the branch it would create
+var ErrTemplateTargetNotEmpty = errors.New("the new repository is not empty - create it without a README, license or .gitignore and try again")
+
+// TemplateOptions describes a repository created from a template
+type TemplateOptions struct {
+	TemplateURL string            // Template repository to copy
+	RemoteURL   string            // New, empty repository the copy is pushed to
+	Branch      string            // Branch created on the new repository (DefaultTemplateBranch when empty)
+	Values      map[string]string // Placeholder values by name, each replacing {{name}}
+}
+
+// TemplateResult reports the effect of CreateFromTemplate
+type TemplateResult struct {
+	// Commit is the hash of the pushed commit
+	Commit string
+	// Branch is the branch created on the new repository
+	Branch string
+	// Files is the number of files in the new repository
+	Files int
+	// Rewritten are the paths, relative to the repository root and named as
+	// in the template, whose content or name had a placeholder filled in
+	Rewritten []string
+}
+
+// CreateFromTemplate copies the template repository at opts.TemplateURL to
+// the empty repository at opts.RemoteURL, filling in opts.Values. Nothing is
+// left on disk: the new repository is cloned when it is added to the config.
+func CreateFromTemplate(ctx context.Context, opts TemplateOptions, logger *logging.AppLogger) (TemplateResult, error) {
+	var result TemplateResult
+
+	if strings.TrimSpace(opts.TemplateURL) == "" || strings.TrimSpace(opts.RemoteURL) == "" {
+		return result, fmt.Errorf("both the template and the new repository URL are required")
+	}
+	if opts.TemplateURL == opts.RemoteURL {
+		return result, fmt.Errorf("the new repository must differ from the template")
+	}
+	result.Branch = opts.Branch
+	if result.Branch == "" {
+		result.Branch = DefaultTemplateBranch
+	}
+
+	workDir, err := os.MkdirTemp("", "rulem-template-*")
+	if err != nil {
+		return result, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	template := GitSource{RemoteURL: opts.TemplateURL}
+	if err := template.performCloneWithAuth(ctx, workDir, opts.TemplateURL, logger); err != nil {
+		return result, fmt.Errorf("failed to clone template: %w", err)
+	}
+	if err := os.RemoveAll(filepath.Join(workDir, git.GitDirName)); err != nil {
+		return result, fmt.Errorf("failed to remove the template's history: %w", err)
+	}
+
+	if result.Files, result.Rewritten, err = fillPlaceholders(workDir, opts.Values); err != nil {
+		return result, err
+	}
+	if result.Files == 0 {
+		return result, fmt.Errorf("the template repository has no files")
+	}
+
+	repo, err := git.PlainInit(workDir, false)
+	if err != nil {
+		return result, fmt.Errorf("failed to initialise the new repository: %w", err)
+	}
+	branchRef := plumbing.NewBranchReferenceName(result.Branch)
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branchRef)); err != nil {
+		return result, fmt.Errorf("failed to create branch %s: %w", result.Branch, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return result, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return result, fmt.Errorf("failed to stage the template's files: %w", err)
+	}
+	message := fmt.Sprintf("Create rules repository from template\n\nTemplate: %s", opts.TemplateURL)
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: commitAuthor(repo)})
+	if err != nil {
+		return result, fmt.Errorf("failed to commit the template's files: %w", err)
+	}
+	result.Commit = hash.String()
+
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{opts.RemoteURL}}); err != nil {
+		return result, fmt.Errorf("failed to add origin remote: %w", err)
+	}
+	if err := pushTemplate(ctx, GitSource{RemoteURL: opts.RemoteURL}, repo, result.Branch, logger); err != nil {
+		return TemplateResult{}, err
+	}
+
+	if logger != nil {
+		logger.Info("Created repository from template", "template", opts.TemplateURL, "remote", opts.RemoteURL,
+			"branch", result.Branch, "commit", result.Commit[:8], "files", result.Files, "rewritten", len(result.Rewritten))
+	}
+	return result, nil
+}
+
+// pushTemplate pushes branch to the new repository like performPushWithAuth,
+// reporting a remote that already has the branch as ErrTemplateTargetNotEmpty
+func pushTemplate(ctx context.Context, target GitSource, repo *git.Repository, branch string, logger *logging.AppLogger) error {
+	err := target.performPush(ctx, repo, branch, nil)
+	if err != nil && target.isAuthenticationError(err) {
+		if logger != nil {
+			logger.Debug("Public push failed, trying with authentication")
+		}
+		auth, authErr := target.getAuthentication(logger)
+		switch {
+		case authErr != nil:
+			return fmt.Errorf("%s authentication failed: %w", target.provider().DisplayName(), authErr)
+		case auth == nil:
+			return target.authRequiredError()
+		}
+		err = target.performPush(ctx, repo, branch, auth)
+	}
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "non-fast-forward") {
+		return ErrTemplateTargetNotEmpty
+	}
+	return target.translatePushError(err)
+}
+
+// fillPlaceholders replaces {{name}} with values[name] in the content of the
+// text files under dir and in file and directory names, returning the number
+// of files and the rewritten paths. Values are kept out of names as path
+// separators.
+func fillPlaceholders(dir string, values map[string]string) (int, []string, error) {
+	var contentPairs, namePairs []string
+	for name, value := range values {
+		placeholder := "{{" + name + "}}"
+		contentPairs = append(contentPairs, placeholder, value)
+		namePairs = append(namePairs, placeholder, strings.NewReplacer("/", "-", `\`, "-").Replace(value))
+	}
+	content, names := strings.NewReplacer(contentPairs...), strings.NewReplacer(namePairs...)
+
+	var files int
+	var rewritten, renames []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if names.Replace(d.Name()) != d.Name() {
+			renames = append(renames, path)
+			rewritten = append(rewritten, filepath.ToSlash(rel))
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		files++
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			return nil // binary file
+		}
+		filled := content.Replace(string(data))
+		if filled == string(data) {
+			return nil
+		}
+		if !slices.Contains(rewritten, filepath.ToSlash(rel)) {
+			rewritten = append(rewritten, filepath.ToSlash(rel))
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(filled), info.Mode().Perm())
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to fill in template placeholders: %w", err)
+	}
+
+	// Rename the deepest paths first so their parents are still where they were found
+	for i := len(renames) - 1; i >= 0; i-- {
+		path := renames[i]
+		target := filepath.Join(filepath.Dir(path), names.Replace(filepath.Base(path)))
+		if _, err := os.Lstat(target); err == nil {
+			return 0, nil, fmt.Errorf("cannot rename %s to %s: it already exists", filepath.Base(path), filepath.Base(target))
+		}
+		if err := os.Rename(path, target); err != nil {
+			return 0, nil, fmt.Errorf("failed to rename %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	slices.Sort(rewritten)
+	return files, rewritten, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+func TestCreateFromTemplate(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	templateURL, writer, _ := setupOriginAndClone(t)
+	commitFile(t, writer, "{{team}}.md", "# {{team}} rules for {{org}}\n")
+	commitFile(t, writer, "CODEOWNERS", "* @{{org}}/{{team}}\n")
+	pushToOrigin(t, writer)
+
+	target := filepath.Join(t.TempDir(), "new.git")
+	if _, err := git.PlainInit(target, true); err != nil {
+		t.Fatalf("init target: %v", err)
+	}
+
+	result, err := CreateFromTemplate(t.Context(), TemplateOptions{
+		TemplateURL: templateURL,
+		RemoteURL:   target,
+		Values:      map[string]string{"team": "platform", "org": "acme"},
+	}, logger)
+	if err != nil {
+		t.Fatalf("CreateFromTemplate: %v", err)
+	}
+	if result.Branch != DefaultTemplateBranch {
+		t.Errorf("Branch = %q, want %q", result.Branch, DefaultTemplateBranch)
+	}
+	if want := []string{"CODEOWNERS", "{{team}}.md"}; !slices.Equal(result.Rewritten, want) {
+		t.Errorf("Rewritten = %v, want %v", result.Rewritten, want)
+	}
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	repo, err := git.PlainClone(clone, &git.CloneOptions{URL: target, ReferenceName: plumbing.NewBranchReferenceName(DefaultTemplateBranch)})
+	if err != nil {
+		t.Fatalf("clone the new repository: %v", err)
+	}
+	if head := headOf(t, clone); head.Hash().String() != result.Commit || head.Name().Short() != "main" {
+		t.Errorf("HEAD = %s at %s, want main at %s", head.Name().Short(), head.Hash(), result.Commit)
+	}
+	log, _ := repo.Log(&git.LogOptions{})
+	commits := 0
+	_ = log.ForEach(func(c *object.Commit) error { commits++; return nil })
+	if commits != 1 {
+		t.Errorf("the new repository should not carry the template's history, got %d commits", commits)
+	}
+
+	data, err := os.ReadFile(filepath.Join(clone, "platform.md"))
+	if err != nil {
+		t.Fatalf("the placeholder in the file name should be filled in: %v", err)
+	}
+	if string(data) != "# platform rules for acme\n" {
+		t.Errorf("platform.md = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(clone, "README.md")); strings.Contains(string(data), "{{") {
+		t.Errorf("README.md should be copied as is, got %q", data)
+	}
+}
+
+func TestCreateFromTemplate_TargetNotEmpty(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	templateURL, _, _ := setupOriginAndClone(t)
+	target, _, _ := setupOriginAndClone(t)
+
+	_, err := CreateFromTemplate(t.Context(), TemplateOptions{
+		TemplateURL: templateURL,
+		RemoteURL:   target,
+		Branch:      "master",
+	}, logger)
+	if !errors.Is(err, ErrTemplateTargetNotEmpty) {
+		t.Fatalf("CreateFromTemplate() = %v, want ErrTemplateTargetNotEmpty", err)
+	}
+}
+
+func TestFillPlaceholders(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"rules/{{team}}/style.md": "Owned by {{team}}",
+		"rules/general.md":        "No placeholders",
+		"{{org}}.md":              "{{unknown}} stays",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, rewritten, err := fillPlaceholders(dir, map[string]string{"team": "web/ui", "org": "acme"})
+	if err != nil {
+		t.Fatalf("fillPlaceholders: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("files = %d, want 3", count)
+	}
+	if want := []string{"rules/{{team}}", "rules/{{team}}/style.md", "{{org}}.md"}; !slices.Equal(rewritten, want) {
+		t.Errorf("rewritten = %v, want %v", rewritten, want)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "rules", "web-ui", "style.md")); err != nil || string(data) != "Owned by web/ui" {
+		t.Errorf("style.md = (%q, %v), want the directory renamed without a separator and the content filled in", data, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "acme.md")); err != nil || string(data) != "{{unknown}} stays" {
+		t.Errorf("acme.md = (%q, %v), want unknown placeholders kept", data, err)
+	}
+}
@@ -21,4 +21,13 @@ const (
 	// validationTimeout bounds lightweight remote checks such as the ls-remote
 	// used to validate a PAT and repository access.
 	validationTimeout = 10 * time.Second
+
+	// keyringProbeTimeout bounds the startup credential store heartbeat
+	// (CheckKeyring). The store is local, but some backends block on an
+	// unlock prompt that may never be answered.
+	keyringProbeTimeout = 5 * time.Second
+
+	// maintenanceTimeout bounds the git gc fallback of RunMaintenance, which
+	// runs locally but may take a while on a large clone.
+	maintenanceTimeout = 120 * time.Second
 )
@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"rulem/internal/logging"
+	"rulem/pkg/fileops"
 )
 
 // Source abstracts different types of central rule repositories.
@@ -105,28 +107,89 @@ func (rt RepositoryType) IsValid() bool {
 // as it represents repository concepts, not configuration persistence concerns.
 //
 // Fields:
-//   - ID: Unique identifier in format "sanitized-name-timestamp" (e.g., "personal-rules-1728756432")
+//   - ID: Unique identifier in format "sanitized-name-suffix" (e.g., "personal-rules-3f9a0c12");
+//     configs written before version 1.1 used a timestamp suffix, see config.MigrateRepositoryIDs
 //   - Name: User-provided display name for UI (e.g., "Personal Rules")
 //   - Type: Repository type ("local" or "github")
-//   - CreatedAt: Unix timestamp when repository was added (used for ordering and ID generation)
+//   - CreatedAt: Unix timestamp when repository was added (used for ordering)
 //   - Path: Local filesystem path (for local repos) or clone path (for GitHub repos)
 //   - RemoteURL: GitHub repository URL (only for Type == RepositoryTypeGitHub)
 //   - Branch: Git branch name (optional, only for GitHub repos)
+//   - Tag, Commit: Tag or full commit hash checked out instead of a branch, freezing
+//     the rules at an audited version (only for GitHub repos); see IsFrozen
 //   - LastSyncTime: Unix timestamp of last sync (only for GitHub repos)
+//   - Subpath: Directory of the repository holding the rules; only it is checked out
+//     and exposed (only for GitHub repos); see GetSubpath
+//   - RefuseIncompatible: Make the repository unavailable, instead of warning, when its
+//     rulem.yaml requires a newer rulem
+//   - Overlay: Per-user writable directory layered over a shared, read-only Path
+//     (only for local repos); see filemanager.NewOverlayFileManager
+//   - ServeAt: Branch, tag or commit whose rules `rulem mcp` serves instead of the
+//     working tree, for reproducible assistant runs
+//   - Worktrees: Extra branches `rulem mcp` serves next to Branch, each from its own
+//     checkout (only for GitHub repos); see WithWorktrees
+//   - WorktreeOf: ID of the repository a derived worktree entry serves a branch of
+//     (never saved)
+//   - ExpectedCommit, ExpectedTag: Commit HEAD must be on after each sync, given directly
+//     or through a tag; the repository is refused otherwise (only for GitHub repos)
+//   - TagKeyring: Armored OpenPGP public keys, one of which must have signed ExpectedTag
+//   - AllowedSigners: File of keys that may sign the synced commit (only for GitHub repos)
+//   - SignaturePolicy: "block" (default) or "warn" when the synced commit is not signed
+//     by an allowed key
+//   - QuarantineNewRules: Hold back rules that appear after the first sync until they
+//     are approved (only for GitHub repos)
+//   - AcceptProposals: Let assistants propose rules with the propose_rule MCP tool,
+//     staged in ProposalsDir for review (only for local repos)
+//   - ContentSecurity: Content policy for this repository's rules, layered over the
+//     global one
+//   - AutoSync: Whether the background sync (see Scheduler) includes the repository;
+//     enabled when nil (only for GitHub repos)
+//   - ToolNamespace: Prefix of the repository's MCP tool names, replacing the one
+//     derived from Name; applied even when it is the only repository served
 type RepositoryEntry struct {
 	// Identity fields
-	ID        string         `yaml:"id"`         // Unique identifier (e.g., "personal-rules-1728756432")
+	ID        string         `yaml:"id"`         // Unique identifier (e.g., "personal-rules-3f9a0c12")
 	Name      string         `yaml:"name"`       // User-provided display name
 	Type      RepositoryType `yaml:"type"`       // Repository type ("local" or "github")
-	CreatedAt int64          `yaml:"created_at"` // Unix timestamp (for ordering and ID generation)
+	CreatedAt int64          `yaml:"created_at"` // Unix timestamp (for ordering)
 
 	// Location
-	Path string `yaml:"path"` // Local path for local repos, clone path for GitHub repos
+	Path    string  `yaml:"path"`              // Local path for local repos, clone path for GitHub repos
+	Overlay *string `yaml:"overlay,omitempty"` // Writable per-user overlay for shared storage (local only)
 
 	// Git-specific fields (only used when Type == RepositoryTypeGitHub)
 	RemoteURL    *string `yaml:"remote_url,omitempty"`     // GitHub repository URL
 	Branch       *string `yaml:"branch,omitempty"`         // Git branch (optional)
 	LastSyncTime *int64  `yaml:"last_sync_time,omitempty"` // Last sync timestamp
+	Subpath      *string `yaml:"subpath,omitempty"`        // Directory holding the rules, for monorepos (optional)
+	Tag          *string `yaml:"tag,omitempty"`            // Tag checked out instead of a branch (optional)
+	Commit       *string `yaml:"commit,omitempty"`         // Commit checked out instead of a branch (optional)
+
+	// Compatibility
+	RefuseIncompatible bool `yaml:"refuse_incompatible,omitempty"` // Refuse rather than warn when rulem is too old
+
+	// Serving
+	ServeAt       *string  `yaml:"serve_at,omitempty"`       // Revision whose rules the MCP server serves (optional)
+	Worktrees     []string `yaml:"worktrees,omitempty"`      // Extra branches served alongside Branch (GitHub only)
+	WorktreeOf    string   `yaml:"-"`                        // Parent repository ID of a derived worktree entry
+	ToolNamespace string   `yaml:"tool_namespace,omitempty"` // Namespace of its MCP tool names (optional)
+
+	// Supply-chain pins (GitHub only)
+	ExpectedCommit *string `yaml:"expected_commit,omitempty"` // Commit hash HEAD must match after a sync
+	ExpectedTag    *string `yaml:"expected_tag,omitempty"`    // Tag whose commit HEAD must match after a sync
+	TagKeyring     *string `yaml:"tag_keyring,omitempty"`     // File of armored OpenPGP keys that may sign ExpectedTag
+
+	// Commit signature verification (GitHub only)
+	AllowedSigners  *string `yaml:"allowed_signers,omitempty"`  // Allowed-signers file of SSH and OpenPGP keys
+	SignaturePolicy *string `yaml:"signature_policy,omitempty"` // "block" or "warn" when verification fails
+
+	QuarantineNewRules bool `yaml:"quarantine_new_rules,omitempty"` // Serve new rules only once approved (GitHub only)
+
+	AutoSync *bool `yaml:"auto_sync,omitempty"` // Include in background syncs, true when nil (GitHub only)
+
+	AcceptProposals bool `yaml:"accept_proposals,omitempty"` // Stage rules proposed over MCP for review (local only)
+
+	ContentSecurity *fileops.ContentSecurity `yaml:"content_security,omitempty"` // Overrides the global content policy
 }
 
 // IsRemote returns true if this repository is a remote Git repository.
@@ -139,6 +202,17 @@ func (r RepositoryEntry) IsLocal() bool {
 	return r.Type == RepositoryTypeLocal
 }
 
+// AutoSyncEnabled reports whether the background sync includes the repository.
+// Only GitHub repositories are synced.
+func (r RepositoryEntry) AutoSyncEnabled() bool {
+	return r.IsRemote() && (r.AutoSync == nil || *r.AutoSync)
+}
+
+// ProposalsDir is the directory at the root of a repository that accepts
+// proposals in which they are staged. Scans of such a repository leave it out,
+// so proposals are not served until they are accepted.
+const ProposalsDir = "proposed"
+
 // GetRemoteURL returns the remote URL if this is a GitHub repository.
 // Returns empty string for local repositories or if RemoteURL is nil.
 func (r RepositoryEntry) GetRemoteURL() string {
@@ -156,6 +230,28 @@ func (r RepositoryEntry) GetBranch() string {
 	return ""
 }
 
+// GetOverlay returns the overlay directory, or empty string when the repository has none.
+func (r RepositoryEntry) GetOverlay() string {
+	if r.Overlay != nil {
+		return *r.Overlay
+	}
+	return ""
+}
+
+// GetServeAt returns the revision the MCP server serves the rules at, or empty
+// string to serve the working tree.
+func (r RepositoryEntry) GetServeAt() string {
+	if r.ServeAt != nil {
+		return *r.ServeAt
+	}
+	return ""
+}
+
+// IsShared returns true if Path is shared storage with a per-user overlay for writes.
+func (r RepositoryEntry) IsShared() bool {
+	return strings.TrimSpace(r.GetOverlay()) != ""
+}
+
 // String returns a string representation of the repository entry for logging.
 func (r RepositoryEntry) String() string {
 	if r.IsRemote() {
@@ -182,10 +278,25 @@ type PreparedRepository struct {
 	// For GitHub repos: the path where the repository was cloned
 	LocalPath string
 
+	// OverlayPath is the absolute path of the writable overlay for shared storage,
+	// or empty when Entry has no overlay. Writes go here instead of LocalPath.
+	OverlayPath string
+
 	// SyncResult contains synchronization status for GitHub repositories
 	// For local repos: Status will be SyncStatusSkipped with appropriate reason
 	// For GitHub repos: Contains actual sync operation results
 	SyncResult RepositorySyncResult
+
+	// Manifest is the repository's rulem.yaml, or nil if it has none
+	Manifest *Manifest
+
+	// CompatibilityWarning is set when the repository's rulem.yaml requires a newer
+	// rulem but the repository was kept available (RefuseIncompatible is false)
+	CompatibilityWarning string
+
+	// Signature is the outcome of verifying the synced commit's signature, or nil
+	// when Entry has no allowed signers
+	Signature *SignatureCheck
 }
 
 // ID returns the repository ID for convenience.
@@ -250,26 +361,24 @@ func (pr PreparedRepository) String() string {
 // ValidateBasicFields validates the basic fields of a repository entry.
 // This is a helper for ValidateRepositoryEntry that checks non-type-specific fields.
 func (r RepositoryEntry) ValidateBasicFields() error {
-	// Validate ID format: must match pattern "sanitized-name-timestamp"
+	// Validate ID format: "sanitized-name-suffix" where the suffix is 8 hex
+	// characters, or a numeric timestamp for IDs created before config 1.1
 	if r.ID == "" {
 		return fmt.Errorf("repository ID cannot be empty")
 	}
 
-	// ID should contain at least one dash and end with digits
+	// ID should contain at least one dash
 	parts := strings.Split(r.ID, "-")
 	if len(parts) < 2 {
-		return fmt.Errorf("invalid repository ID format %q (expected: name-timestamp)", r.ID)
+		return fmt.Errorf("invalid repository ID format %q (expected: name-suffix)", r.ID)
 	}
 
-	// Last part should be numeric (timestamp)
 	lastPart := parts[len(parts)-1]
 	if len(lastPart) == 0 {
-		return fmt.Errorf("invalid repository ID format %q (missing timestamp)", r.ID)
+		return fmt.Errorf("invalid repository ID format %q (missing suffix)", r.ID)
 	}
-	for _, ch := range lastPart {
-		if ch < '0' || ch > '9' {
-			return fmt.Errorf("invalid repository ID format %q (timestamp must be numeric)", r.ID)
-		}
+	if !isHexIDSuffix(lastPart) && !isNumeric(lastPart) {
+		return fmt.Errorf("invalid repository ID format %q (suffix must be 8 hex characters or a timestamp)", r.ID)
 	}
 
 	// Validate name
@@ -298,6 +407,16 @@ func (r RepositoryEntry) ValidateBasicFields() error {
 		return fmt.Errorf("repository path cannot be empty")
 	}
 
+	if r.ContentSecurity != nil {
+		if err := r.ContentSecurity.Validate(); err != nil {
+			return fmt.Errorf("invalid content_security: %w", err)
+		}
+	}
+
+	if err := ValidateToolNamespace(r.ToolNamespace); err != nil {
+		return fmt.Errorf("invalid tool_namespace: %w", err)
+	}
+
 	return nil
 }
 
@@ -319,6 +438,29 @@ func (r RepositoryEntry) ValidateTypeSpecificFields() error {
 		if r.LastSyncTime != nil && *r.LastSyncTime <= 0 {
 			return fmt.Errorf("last_sync_time must be positive Unix timestamp, got: %d", *r.LastSyncTime)
 		}
+		// Clones are written by every sync, so they cannot be shared read-only
+		if r.Overlay != nil {
+			return fmt.Errorf("github repository cannot have an overlay (only local repositories support shared storage)")
+		}
+		// Staged proposals would count as local changes and stop syncs
+		if r.AcceptProposals {
+			return fmt.Errorf("github repository cannot accept proposals (only local repositories do)")
+		}
+		if err := r.validateSubpath(); err != nil {
+			return err
+		}
+		if err := r.validateFrozen(); err != nil {
+			return err
+		}
+		if err := r.validateWorktrees(); err != nil {
+			return err
+		}
+		if err := r.validatePin(); err != nil {
+			return err
+		}
+		if err := r.validateSignatures(); err != nil {
+			return err
+		}
 	} else if r.Type == RepositoryTypeLocal {
 		// Local repositories should not have GitHub-specific fields
 		if r.RemoteURL != nil && *r.RemoteURL != "" {
@@ -330,7 +472,62 @@ func (r RepositoryEntry) ValidateTypeSpecificFields() error {
 		if r.LastSyncTime != nil {
 			return fmt.Errorf("local repository should not have a last_sync_time")
 		}
+		if r.Subpath != nil {
+			return fmt.Errorf("local repository should not have a subpath (point its path at the directory instead)")
+		}
+		if r.IsFrozen() {
+			return fmt.Errorf("local repository should not have a tag or commit")
+		}
+		if len(r.Worktrees) > 0 {
+			return fmt.Errorf("local repository should not have worktrees")
+		}
+		if r.HasPin() || r.TagKeyring != nil {
+			return fmt.Errorf("local repository should not have an expected commit or tag")
+		}
+		if r.AllowedSigners != nil || r.SignaturePolicy != nil {
+			return fmt.Errorf("local repository should not have allowed signers")
+		}
+		if r.QuarantineNewRules {
+			return fmt.Errorf("local repository cannot quarantine new rules")
+		}
+		if r.AutoSync != nil {
+			return fmt.Errorf("local repository should not have auto_sync (local repositories are never synced)")
+		}
+		if r.Overlay != nil {
+			overlay := strings.TrimSpace(*r.Overlay)
+			if overlay == "" {
+				return fmt.Errorf("overlay cannot be empty string (use nil for no overlay)")
+			}
+			path := filepath.Clean(fileops.ExpandPath(strings.TrimSpace(r.Path)))
+			overlay = filepath.Clean(fileops.ExpandPath(overlay))
+			if isWithin(overlay, path) || isWithin(path, overlay) {
+				return fmt.Errorf("overlay and shared path must not contain each other")
+			}
+		}
 	}
 
 	return nil
 }
+
+// isHexIDSuffix reports whether s is an 8 character lowercase hex ID suffix
+func isHexIDSuffix(s string) bool {
+	if len(s) != 8 {
+		return false
+	}
+	for _, ch := range s {
+		if (ch < '0' || ch > '9') && (ch < 'a' || ch > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isNumeric reports whether s consists only of ASCII digits
+func isNumeric(s string) bool {
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
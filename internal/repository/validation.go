@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -10,7 +11,7 @@ import (
 // requirements (e.g., GitHub repos must have RemoteURL).
 //
 // Validation checks:
-//   - ID format matches pattern "sanitized-name-timestamp"
+//   - ID format matches pattern "sanitized-name-suffix" (hex suffix, or a legacy timestamp)
 //   - Name is non-empty and within length limits (1-100 characters)
 //   - Type is valid ("local" or "github")
 //   - CreatedAt is a positive Unix timestamp
@@ -137,6 +138,36 @@ func ValidateRepositoryName(name string) error {
 	return nil
 }
 
+// maxToolNamespaceLength bounds tool name prefixes and namespaces, leaving
+// room for the rule's own name within the tool name limits of MCP clients
+const maxToolNamespaceLength = 32
+
+// toolNamespacePattern allows only characters every MCP client accepts in
+// tool names
+var toolNamespacePattern = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+// ValidateToolNamespace validates a tool name prefix or repository tool
+// namespace, as set in tool_prefix and tool_namespace. Empty is valid.
+//
+// Validation rules:
+//   - Only ASCII letters, digits, underscores and hyphens
+//   - Maximum 32 characters
+//
+// Parameters:
+//   - namespace: Prefix or namespace to validate
+//
+// Returns:
+//   - error: Validation error, nil if valid
+func ValidateToolNamespace(namespace string) error {
+	if len(namespace) > maxToolNamespaceLength {
+		return fmt.Errorf("%q is too long (%d characters, maximum %d)", namespace, len(namespace), maxToolNamespaceLength)
+	}
+	if !toolNamespacePattern.MatchString(namespace) {
+		return fmt.Errorf("%q may only contain letters, digits, underscores and hyphens", namespace)
+	}
+	return nil
+}
+
 // ValidateRepositoryPath validates a repository path for basic correctness.
 // This is a lightweight check that doesn't access the filesystem.
 // For full validation including filesystem checks, use LocalSource.Prepare() or PrepareRepository().
@@ -21,6 +21,16 @@ func TestValidateRepositoryEntry_ValidEntries(t *testing.T) {
 				CreatedAt: 1234567890,
 			},
 		},
+		{
+			name: "valid local repository with hex ID suffix",
+			repo: RepositoryEntry{
+				ID:        "local-repo-3f9a0c12",
+				Name:      "Local Repository",
+				Type:      RepositoryTypeLocal,
+				Path:      "/home/user/rules",
+				CreatedAt: 1234567890,
+			},
+		},
 		{
 			name: "valid github repository",
 			repo: RepositoryEntry{
@@ -86,14 +96,14 @@ func TestValidateRepositoryEntry_InvalidID(t *testing.T) {
 			expectErr: "invalid repository ID format",
 		},
 		{
-			name:      "ID with non-numeric timestamp",
+			name:      "ID with invalid suffix",
 			id:        "repo-name-abc",
-			expectErr: "timestamp must be numeric",
+			expectErr: "suffix must be 8 hex characters or a timestamp",
 		},
 		{
 			name:      "ID ending with dash",
 			id:        "repo-name-",
-			expectErr: "missing timestamp",
+			expectErr: "missing suffix",
 		},
 	}
 
@@ -449,6 +459,49 @@ func TestValidateRepositoryEntry_LocalWithGitHubFields(t *testing.T) {
 	}
 }
 
+// TestValidateRepositoryEntry_Overlay tests overlay validation for shared storage
+func TestValidateRepositoryEntry_Overlay(t *testing.T) {
+	tests := []struct {
+		name      string
+		repoType  RepositoryType
+		overlay   *string
+		expectErr string
+	}{
+		{name: "local repo with overlay", repoType: RepositoryTypeLocal, overlay: stringPtr("/tmp/overlay")},
+		{name: "empty overlay", repoType: RepositoryTypeLocal, overlay: stringPtr("  "), expectErr: "overlay cannot be empty"},
+		{name: "overlay inside shared path", repoType: RepositoryTypeLocal, overlay: stringPtr("/opt/rules/mine"), expectErr: "must not contain each other"},
+		{name: "shared path inside overlay", repoType: RepositoryTypeLocal, overlay: stringPtr("/opt"), expectErr: "must not contain each other"},
+		{name: "github repo with overlay", repoType: RepositoryTypeGitHub, overlay: stringPtr("/tmp/overlay"), expectErr: "cannot have an overlay"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := RepositoryEntry{
+				ID:        "test-repo-1234567890",
+				Name:      "Test Repo",
+				Type:      tt.repoType,
+				Path:      "/opt/rules",
+				Overlay:   tt.overlay,
+				CreatedAt: 1234567890,
+			}
+			if tt.repoType == RepositoryTypeGitHub {
+				repo.RemoteURL = stringPtr("https://github.com/user/repo.git")
+			}
+
+			err := ValidateRepositoryEntry(repo)
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("expected error containing %q, got: %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
 // TestValidateRepositoryName tests the ValidateRepositoryName function
 func TestValidateRepositoryName(t *testing.T) {
 	tests := []struct {
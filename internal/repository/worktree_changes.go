@@ -0,0 +1,416 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"rulem/internal/logging"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// Local change helpers
+//
+// Sync skips repositories with uncommitted changes so local edits are never
+// clobbered. The helpers in this file let the TUI resolve that state without
+// sending the user to the command line: list the changed files, discard
+// selected ones, or stash everything, sync, and restore the changes afterwards.
+//
+// go-git has no stash support, so StashChanges keeps a file-level copy of the
+// changed files under .git/rulem-stash (outside the working tree) and resets
+// the worktree to HEAD. PopStash writes the copies back, detecting files that
+// were also changed upstream in the meantime.
+
+// stashDirName is the directory inside .git holding the rulem stash
+const stashDirName = "rulem-stash"
+
+// stashManifestName is the manifest file inside the stash directory
+const stashManifestName = "manifest.json"
+
+// stashConflictSuffix is appended to the path of a stashed file whose upstream
+// version changed during sync; the upstream version is kept in place
+const stashConflictSuffix = ".rulem-stash"
+
+var (
+	// ErrStashExists is returned by StashChanges when a previous stash was never popped
+	ErrStashExists = errors.New("a rulem stash already exists for this repository - restore it before stashing again")
+	// ErrNoStash is returned by PopStash when there is nothing to restore
+	ErrNoStash = errors.New("no rulem stash found for this repository")
+)
+
+// FileChangeKind classifies an uncommitted change in a repository
+type FileChangeKind int
+
+const (
+	// FileChangeModified indicates a tracked file with modified content
+	FileChangeModified FileChangeKind = iota
+	// FileChangeAdded indicates a new file staged for commit
+	FileChangeAdded
+	// FileChangeDeleted indicates a tracked file that was removed
+	FileChangeDeleted
+	// FileChangeUntracked indicates a new file git does not know about
+	FileChangeUntracked
+)
+
+// String returns the short git-style status code for the change
+func (k FileChangeKind) String() string {
+	switch k {
+	case FileChangeModified:
+		return "M"
+	case FileChangeAdded:
+		return "A"
+	case FileChangeDeleted:
+		return "D"
+	case FileChangeUntracked:
+		return "?"
+	default:
+		return "-"
+	}
+}
+
+// FileChange is a single uncommitted change, with Path relative to the repository root
+type FileChange struct {
+	Path string
+	Kind FileChangeKind
+}
+
+// stashEntry records one stashed file
+type stashEntry struct {
+	Path string `json:"path"`
+	// BaseHash is the blob hash of the file in HEAD at stash time, empty if
+	// the file was not in HEAD. PopStash compares it to detect upstream changes.
+	BaseHash string `json:"base_hash,omitempty"`
+	Deleted  bool   `json:"deleted,omitempty"`
+}
+
+// ListChangedFiles returns the uncommitted changes in the repository at repoPath,
+// sorted by path. An empty result means the working tree is clean.
+func ListChangedFiles(repoPath string) ([]FileChange, error) {
+	_, worktree, err := openWorktree(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository status: %w", err)
+	}
+
+	changes := make([]FileChange, 0, len(status))
+	for path, s := range status {
+		var kind FileChangeKind
+		switch {
+		case s.Worktree == git.Untracked:
+			kind = FileChangeUntracked
+		case s.Worktree == git.Deleted || s.Staging == git.Deleted:
+			kind = FileChangeDeleted
+		case s.Staging == git.Added:
+			kind = FileChangeAdded
+		case s.Worktree == git.Unmodified && s.Staging == git.Unmodified:
+			continue
+		default:
+			kind = FileChangeModified
+		}
+		changes = append(changes, FileChange{Path: path, Kind: kind})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// DiscardFiles reverts the given paths to their HEAD version. Files that are
+// not in HEAD (untracked or newly added) are deleted. This cannot be undone.
+func DiscardFiles(repoPath string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	repo, worktree, err := openWorktree(repoPath)
+	if err != nil {
+		return err
+	}
+	tree, err := headTree(repo)
+	if err != nil {
+		return err
+	}
+
+	var tracked []string
+	for _, path := range paths {
+		clean, err := cleanRepoRelativePath(path)
+		if err != nil {
+			return err
+		}
+		if _, err := tree.File(clean); err == nil {
+			tracked = append(tracked, clean)
+			continue
+		}
+		if err := removeFromIndexAndDisk(worktree, repoPath, clean); err != nil {
+			return err
+		}
+	}
+
+	if len(tracked) > 0 {
+		if err := worktree.Restore(&git.RestoreOptions{Staged: true, Worktree: true, Files: tracked}); err != nil {
+			return fmt.Errorf("failed to restore files: %w", err)
+		}
+	}
+	return nil
+}
+
+// HasStash reports whether a rulem stash is waiting to be restored in repoPath
+func HasStash(repoPath string) bool {
+	_, err := os.Stat(filepath.Join(stashDir(repoPath), stashManifestName))
+	return err == nil
+}
+
+// StashChanges saves all uncommitted changes in repoPath and resets the working
+// tree to HEAD so it can be synced. It returns the number of files stashed; a
+// clean repository stashes nothing. Staged and unstaged changes are stashed
+// alike and come back unstaged from PopStash.
+func StashChanges(repoPath string) (int, error) {
+	if HasStash(repoPath) {
+		return 0, ErrStashExists
+	}
+
+	changes, err := ListChangedFiles(repoPath)
+	if err != nil {
+		return 0, err
+	}
+	if len(changes) == 0 {
+		return 0, nil
+	}
+
+	repo, worktree, err := openWorktree(repoPath)
+	if err != nil {
+		return 0, err
+	}
+	tree, err := headTree(repo)
+	if err != nil {
+		return 0, err
+	}
+
+	dir := stashDir(repoPath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return 0, fmt.Errorf("failed to create stash directory: %w", err)
+	}
+	entries := make([]stashEntry, 0, len(changes))
+	for _, change := range changes {
+		entry := stashEntry{Path: change.Path, Deleted: change.Kind == FileChangeDeleted}
+		if f, err := tree.File(change.Path); err == nil {
+			entry.BaseHash = f.Hash.String()
+		}
+		if !entry.Deleted {
+			if err := copyFile(filepath.Join(repoPath, change.Path), filepath.Join(dir, "files", change.Path)); err != nil {
+				os.RemoveAll(dir)
+				return 0, fmt.Errorf("failed to stash %s: %w", change.Path, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		os.RemoveAll(dir)
+		return 0, fmt.Errorf("failed to encode stash manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, stashManifestName), data, 0600); err != nil {
+		os.RemoveAll(dir)
+		return 0, fmt.Errorf("failed to write stash manifest: %w", err)
+	}
+
+	// Only reset once every file is safely copied
+	head, err := repo.Head()
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if err := worktree.Reset(&git.ResetOptions{Mode: git.HardReset, Commit: head.Hash()}); err != nil {
+		return 0, fmt.Errorf("failed to reset working tree: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.BaseHash == "" {
+			if err := os.Remove(filepath.Join(repoPath, entry.Path)); err != nil && !os.IsNotExist(err) {
+				return 0, fmt.Errorf("failed to remove stashed file %s: %w", entry.Path, err)
+			}
+		}
+	}
+
+	return len(entries), nil
+}
+
+// PopStash restores the changes saved by StashChanges and removes the stash.
+//
+// A stashed file whose HEAD version changed since it was stashed (because the
+// sync brought in an upstream change) is a conflict: the upstream version is
+// kept and the stashed version is written next to it with a ".rulem-stash"
+// suffix. PopStash returns the paths of conflicting files.
+func PopStash(repoPath string) ([]string, error) {
+	dir := stashDir(repoPath)
+	data, err := os.ReadFile(filepath.Join(dir, stashManifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoStash
+		}
+		return nil, fmt.Errorf("failed to read stash manifest: %w", err)
+	}
+	var entries []stashEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode stash manifest: %w", err)
+	}
+
+	repo, _, err := openWorktree(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := headTree(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []string
+	for _, entry := range entries {
+		clean, err := cleanRepoRelativePath(entry.Path)
+		if err != nil {
+			return conflicts, err
+		}
+		target := filepath.Join(repoPath, clean)
+
+		currentHash := ""
+		if f, err := tree.File(clean); err == nil {
+			currentHash = f.Hash.String()
+		}
+
+		if currentHash != entry.BaseHash {
+			conflicts = append(conflicts, clean)
+			if !entry.Deleted {
+				if err := copyFile(filepath.Join(dir, "files", clean), target+stashConflictSuffix); err != nil {
+					return conflicts, fmt.Errorf("failed to restore %s: %w", clean, err)
+				}
+			}
+			continue
+		}
+
+		if entry.Deleted {
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return conflicts, fmt.Errorf("failed to restore deletion of %s: %w", clean, err)
+			}
+			continue
+		}
+		if err := copyFile(filepath.Join(dir, "files", clean), target); err != nil {
+			return conflicts, fmt.Errorf("failed to restore %s: %w", clean, err)
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return conflicts, fmt.Errorf("failed to remove stash: %w", err)
+	}
+	return conflicts, nil
+}
+
+// FetchUpdatesWithStash stashes local changes, syncs the repository with
+// FetchUpdates and restores the changes, returning any conflicting paths as
+// reported by PopStash. The changes are restored even when the sync fails.
+func (gs GitSource) FetchUpdatesWithStash(ctx context.Context, logger *logging.AppLogger) ([]string, error) {
+	stashed, err := StashChanges(gs.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stash local changes: %w", err)
+	}
+	logger.Info("Stashed local changes before sync", "path", gs.Path, "files", stashed)
+
+	fetchErr := gs.FetchUpdates(ctx, logger)
+
+	if stashed == 0 {
+		return nil, fetchErr
+	}
+	conflicts, popErr := PopStash(gs.Path)
+	if popErr != nil {
+		logger.Error("Failed to restore stashed changes", "path", gs.Path, "error", popErr)
+		return conflicts, fmt.Errorf("failed to restore local changes (kept in .git/%s): %w", stashDirName, popErr)
+	}
+	if len(conflicts) > 0 {
+		logger.Warn("Stashed changes conflict with upstream", "path", gs.Path, "conflicts", conflicts)
+	}
+	return conflicts, fetchErr
+}
+
+// openWorktree opens the repository at repoPath and its working tree
+func openWorktree(repoPath string) (*git.Repository, *git.Worktree, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get working tree: %w", err)
+	}
+	return repo, worktree, nil
+}
+
+// headTree returns the tree of the commit HEAD points to
+func headTree(repo *git.Repository) (*object.Tree, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load HEAD commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load HEAD tree: %w", err)
+	}
+	return tree, nil
+}
+
+// removeFromIndexAndDisk deletes a file that is not in HEAD, unstaging it first if it was added
+func removeFromIndexAndDisk(worktree *git.Worktree, repoPath, path string) error {
+	status, err := worktree.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get repository status: %w", err)
+	}
+	if s := status.File(path); s.Staging == git.Added {
+		if _, err := worktree.Remove(path); err != nil {
+			return fmt.Errorf("failed to unstage %s: %w", path, err)
+		}
+	}
+	if err := os.Remove(filepath.Join(repoPath, path)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete %s: %w", path, err)
+	}
+	return nil
+}
+
+// cleanRepoRelativePath rejects paths that would escape the repository root
+func cleanRepoRelativePath(path string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if !filepath.IsLocal(clean) {
+		return "", fmt.Errorf("invalid path %q: must stay inside the repository", path)
+	}
+	return filepath.ToSlash(clean), nil
+}
+
+// stashDir returns the rulem stash directory for the repository at repoPath
+func stashDir(repoPath string) string {
+	return filepath.Join(repoPath, git.GitDirName, stashDirName)
+}
+
+// copyFile copies src to dst, keeping its permissions and creating parent directories
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, info.Mode().Perm())
+}
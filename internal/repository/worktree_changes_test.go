@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"rulem/internal/logging"
+	"testing"
+
+	"github.com/go-git/go-git/v6"
+)
+
+// dirtyRepo returns a clone with README.md and rules.md committed, then
+// modifies README.md, deletes rules.md, stages added.md and leaves notes.md untracked.
+func dirtyRepo(t *testing.T) string {
+	t.Helper()
+	_, writer, reader := setupOriginAndClone(t)
+	commitFile(t, writer, "rules.md", "# rules\n")
+	pushToOrigin(t, writer)
+	logger, _ := logging.NewTestLogger()
+	if err := (GitSource{Path: reader}).FetchUpdates(context.Background(), logger); err != nil {
+		t.Fatalf("FetchUpdates: %v", err)
+	}
+
+	writeFile(t, reader, "README.md", "local edit\n")
+	if err := os.Remove(filepath.Join(reader, "rules.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	writeFile(t, reader, "added.md", "staged\n")
+	repo, err := git.PlainOpen(reader)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if _, err := wt.Add("added.md"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	writeFile(t, reader, "notes.md", "untracked\n")
+	return reader
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestListChangedFiles(t *testing.T) {
+	repo := dirtyRepo(t)
+
+	got, err := ListChangedFiles(repo)
+	if err != nil {
+		t.Fatalf("ListChangedFiles: %v", err)
+	}
+	want := []FileChange{
+		{Path: "README.md", Kind: FileChangeModified},
+		{Path: "added.md", Kind: FileChangeAdded},
+		{Path: "notes.md", Kind: FileChangeUntracked},
+		{Path: "rules.md", Kind: FileChangeDeleted},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListChangedFiles() = %v, want %v", got, want)
+	}
+
+	if _, err := ListChangedFiles(t.TempDir()); err == nil {
+		t.Error("expected error for non-git directory")
+	}
+}
+
+func TestFileChangeKind_String(t *testing.T) {
+	tests := []struct {
+		kind FileChangeKind
+		want string
+	}{
+		{FileChangeModified, "M"},
+		{FileChangeAdded, "A"},
+		{FileChangeDeleted, "D"},
+		{FileChangeUntracked, "?"},
+		{FileChangeKind(99), "-"},
+	}
+	for _, tt := range tests {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("FileChangeKind(%d).String() = %q, want %q", tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestDiscardFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		discard   []string
+		wantLeft  []string
+		wantErr   bool
+		checkFile func(t *testing.T, repo string)
+	}{
+		{
+			name:     "modified file is restored",
+			discard:  []string{"README.md"},
+			wantLeft: []string{"added.md", "notes.md", "rules.md"},
+			checkFile: func(t *testing.T, repo string) {
+				if got := readFile(t, repo, "README.md"); got != "# hello\n" {
+					t.Errorf("README.md = %q, want HEAD version", got)
+				}
+			},
+		},
+		{
+			name:     "deleted file is restored",
+			discard:  []string{"rules.md"},
+			wantLeft: []string{"README.md", "added.md", "notes.md"},
+			checkFile: func(t *testing.T, repo string) {
+				if got := readFile(t, repo, "rules.md"); got != "# rules\n" {
+					t.Errorf("rules.md = %q, want HEAD version", got)
+				}
+			},
+		},
+		{
+			name:     "untracked and added files are deleted",
+			discard:  []string{"notes.md", "added.md"},
+			wantLeft: []string{"README.md", "rules.md"},
+			checkFile: func(t *testing.T, repo string) {
+				for _, name := range []string{"notes.md", "added.md"} {
+					if _, err := os.Stat(filepath.Join(repo, name)); !os.IsNotExist(err) {
+						t.Errorf("%s should be deleted, stat err = %v", name, err)
+					}
+				}
+			},
+		},
+		{
+			name:     "everything",
+			discard:  []string{"README.md", "rules.md", "notes.md", "added.md"},
+			wantLeft: nil,
+		},
+		{
+			name:    "path outside repository",
+			discard: []string{"../outside.md"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := dirtyRepo(t)
+
+			err := DiscardFiles(repo, tt.discard)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DiscardFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			changes, err := ListChangedFiles(repo)
+			if err != nil {
+				t.Fatalf("ListChangedFiles: %v", err)
+			}
+			var left []string
+			for _, c := range changes {
+				left = append(left, c.Path)
+			}
+			if !reflect.DeepEqual(left, tt.wantLeft) {
+				t.Errorf("remaining changes = %v, want %v", left, tt.wantLeft)
+			}
+			if tt.checkFile != nil {
+				tt.checkFile(t, repo)
+			}
+		})
+	}
+}
+
+func TestStashChanges_RoundTrip(t *testing.T) {
+	repo := dirtyRepo(t)
+	before, err := ListChangedFiles(repo)
+	if err != nil {
+		t.Fatalf("ListChangedFiles: %v", err)
+	}
+
+	n, err := StashChanges(repo)
+	if err != nil {
+		t.Fatalf("StashChanges: %v", err)
+	}
+	if n != len(before) {
+		t.Errorf("StashChanges() = %d, want %d", n, len(before))
+	}
+	if dirty, err := CheckGithubRepositoryStatus(repo); err != nil || dirty {
+		t.Fatalf("repository should be clean after stash, dirty=%v err=%v", dirty, err)
+	}
+	if !HasStash(repo) {
+		t.Fatal("HasStash() = false after stashing")
+	}
+	if _, err := StashChanges(repo); !errors.Is(err, ErrStashExists) {
+		t.Errorf("second StashChanges() error = %v, want ErrStashExists", err)
+	}
+
+	conflicts, err := PopStash(repo)
+	if err != nil {
+		t.Fatalf("PopStash: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("PopStash() conflicts = %v, want none", conflicts)
+	}
+	if HasStash(repo) {
+		t.Error("HasStash() = true after pop")
+	}
+	if got := readFile(t, repo, "README.md"); got != "local edit\n" {
+		t.Errorf("README.md = %q, want local edit restored", got)
+	}
+	if got := readFile(t, repo, "notes.md"); got != "untracked\n" {
+		t.Errorf("notes.md = %q, want untracked file restored", got)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "rules.md")); !os.IsNotExist(err) {
+		t.Errorf("rules.md deletion should be restored, stat err = %v", err)
+	}
+
+	if _, err := PopStash(repo); !errors.Is(err, ErrNoStash) {
+		t.Errorf("PopStash() without stash error = %v, want ErrNoStash", err)
+	}
+}
+
+func TestStashChanges_CleanRepository(t *testing.T) {
+	_, _, reader := setupOriginAndClone(t)
+
+	n, err := StashChanges(reader)
+	if err != nil || n != 0 {
+		t.Fatalf("StashChanges() = (%d, %v), want (0, nil)", n, err)
+	}
+	if HasStash(reader) {
+		t.Error("clean repository should not leave a stash")
+	}
+}
+
+func TestFetchUpdatesWithStash(t *testing.T) {
+	t.Run("syncs and restores local changes", func(t *testing.T) {
+		_, writer, reader := setupOriginAndClone(t)
+		logger, _ := logging.NewTestLogger()
+
+		writeFile(t, reader, "local.md", "mine\n")
+		commitFile(t, writer, "upstream.md", "# upstream\n")
+		pushToOrigin(t, writer)
+
+		conflicts, err := NewGitSource("", nil, reader).FetchUpdatesWithStash(context.Background(), logger)
+		if err != nil {
+			t.Fatalf("FetchUpdatesWithStash: %v", err)
+		}
+		if len(conflicts) != 0 {
+			t.Errorf("conflicts = %v, want none", conflicts)
+		}
+		if _, err := os.Stat(filepath.Join(reader, "upstream.md")); err != nil {
+			t.Errorf("upstream change was not synced: %v", err)
+		}
+		if got := readFile(t, reader, "local.md"); got != "mine\n" {
+			t.Errorf("local.md = %q, want local change restored", got)
+		}
+	})
+
+	t.Run("upstream change to a stashed file is a conflict", func(t *testing.T) {
+		_, writer, reader := setupOriginAndClone(t)
+		logger, _ := logging.NewTestLogger()
+
+		writeFile(t, reader, "README.md", "local edit\n")
+		commitFile(t, writer, "README.md", "# upstream edit\n")
+		pushToOrigin(t, writer)
+
+		conflicts, err := NewGitSource("", nil, reader).FetchUpdatesWithStash(context.Background(), logger)
+		if err != nil {
+			t.Fatalf("FetchUpdatesWithStash: %v", err)
+		}
+		if !reflect.DeepEqual(conflicts, []string{"README.md"}) {
+			t.Errorf("conflicts = %v, want [README.md]", conflicts)
+		}
+		if got := readFile(t, reader, "README.md"); got != "# upstream edit\n" {
+			t.Errorf("README.md = %q, want upstream version kept", got)
+		}
+		if got := readFile(t, reader, "README.md"+stashConflictSuffix); got != "local edit\n" {
+			t.Errorf("stashed copy = %q, want local edit", got)
+		}
+	})
+}
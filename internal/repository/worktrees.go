@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Branch worktrees
+//
+// A GitHub repository can serve other branches next to its own, e.g. an
+// `experimental` rule set alongside `main`. Each extra branch listed under
+// worktrees gets a checkout of its own in <path>.worktrees/<branch>, which is
+// prepared and synced like any other repository and so has its own sync
+// status. go-git cannot add linked worktrees, so each checkout is a separate
+// shallow clone of the same remote.
+//
+// Worktree entries are derived from the configuration when repositories are
+// prepared for serving; they are never written back to config.yaml. The
+// <path>.worktrees directory belongs to rulem: it moves with the clone when
+// storage is relocated and is removed when the repository is deleted.
+
+// WithWorktrees returns repos followed by an entry for every extra branch of
+// the GitHub repositories among them. Repositories without worktrees are
+// returned unchanged.
+func WithWorktrees(repos []RepositoryEntry) []RepositoryEntry {
+	result := append([]RepositoryEntry(nil), repos...)
+	for _, repo := range repos {
+		if !repo.IsRemote() {
+			continue
+		}
+		for _, branch := range repo.Worktrees {
+			result = append(result, repo.worktreeEntry(branch))
+		}
+	}
+	return result
+}
+
+// worktreeEntry derives the entry serving branch of r. Its ID keeps r's
+// suffix so it stays a valid repository ID.
+func (r RepositoryEntry) worktreeEntry(branch string) RepositoryEntry {
+	slug := BranchSlug(branch)
+	id := r.ID + "-" + slug
+	if i := strings.LastIndex(r.ID, "-"); i >= 0 {
+		id = r.ID[:i] + "-" + slug + r.ID[i:]
+	}
+
+	return RepositoryEntry{
+		ID:                 id,
+		Name:               fmt.Sprintf("%s (%s)", r.Name, branch),
+		Type:               r.Type,
+		CreatedAt:          r.CreatedAt,
+		Path:               WorktreePath(r.Path, branch),
+		RemoteURL:          r.RemoteURL,
+		Branch:             &branch,
+		Subpath:            r.Subpath,
+		RefuseIncompatible: r.RefuseIncompatible,
+		AllowedSigners:     r.AllowedSigners,
+		SignaturePolicy:    r.SignaturePolicy,
+		QuarantineNewRules: r.QuarantineNewRules,
+		ContentSecurity:    r.ContentSecurity,
+		WorktreeOf:         r.ID,
+	}
+}
+
+// WorktreePath returns where the checkout of branch lives for a repository
+// cloned at clonePath
+func WorktreePath(clonePath, branch string) string {
+	return filepath.Join(WorktreesDir(clonePath), BranchSlug(branch))
+}
+
+// WorktreesDir returns the directory holding the branch checkouts of a
+// repository cloned at clonePath
+func WorktreesDir(clonePath string) string {
+	return filepath.Clean(clonePath) + ".worktrees"
+}
+
+// RemoveWorktrees deletes the branch checkouts of a repository cloned at
+// clonePath. A repository without any is left alone.
+func RemoveWorktrees(clonePath string) error {
+	if err := os.RemoveAll(WorktreesDir(clonePath)); err != nil {
+		return fmt.Errorf("failed to remove branch worktrees: %w", err)
+	}
+	return nil
+}
+
+// BranchSlug turns a branch name into a lowercase name safe for paths and IDs,
+// e.g. "feature/New_Rules" becomes "feature-new-rules"
+func BranchSlug(branch string) string {
+	var b strings.Builder
+	dash := false
+	for _, ch := range strings.ToLower(branch) {
+		if (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') {
+			b.WriteRune(ch)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// validateWorktrees checks the extra branches of a GitHub repository: each
+// must have a usable name that differs from the repository's own branch and
+// from the others once turned into a path.
+func (r RepositoryEntry) validateWorktrees() error {
+	seen := make(map[string]string, len(r.Worktrees))
+	for _, branch := range r.Worktrees {
+		slug := BranchSlug(branch)
+		if slug == "" {
+			return fmt.Errorf("invalid worktree branch %q", branch)
+		}
+		if branch == r.GetBranch() {
+			return fmt.Errorf("worktree branch %q is the repository's own branch", branch)
+		}
+		if other, exists := seen[slug]; exists {
+			return fmt.Errorf("worktree branches %q and %q would share the directory %q", other, branch, slug)
+		}
+		seen[slug] = branch
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBranchSlug(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{"experimental", "experimental"},
+		{"feature/New_Rules", "feature-new-rules"},
+		{"--release//2.0--", "release-2-0"},
+		{"///", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			if got := BranchSlug(tt.branch); got != tt.want {
+				t.Errorf("BranchSlug(%q) = %q, want %q", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithWorktrees(t *testing.T) {
+	clone := filepath.Join(t.TempDir(), "rules")
+	github := githubEntry(clone)
+	github.CreatedAt = 1234567890
+	github.Worktrees = []string{"experimental", "feature/next"}
+	local := RepositoryEntry{ID: "local-3f9a0c12", Name: "Local", Type: RepositoryTypeLocal, CreatedAt: 1234567890, Path: t.TempDir()}
+
+	repos := WithWorktrees([]RepositoryEntry{github, local})
+	if len(repos) != 4 {
+		t.Fatalf("got %d entries, want the 2 repositories and 2 worktrees", len(repos))
+	}
+
+	experimental := repos[2]
+	if experimental.ID != "rules-experimental-1" || experimental.Name != "Rules (experimental)" {
+		t.Errorf("unexpected worktree identity %q %q", experimental.ID, experimental.Name)
+	}
+	if experimental.GetBranch() != "experimental" || experimental.WorktreeOf != github.ID || experimental.GetRemoteURL() != github.GetRemoteURL() {
+		t.Errorf("unexpected worktree entry %s", experimental)
+	}
+	if want := filepath.Join(clone+".worktrees", "experimental"); experimental.Path != want {
+		t.Errorf("Path = %q, want %q", experimental.Path, want)
+	}
+	if repos[3].GetBranch() != "feature/next" || !strings.HasSuffix(repos[3].Path, "feature-next") {
+		t.Errorf("unexpected second worktree %s at %s", repos[3], repos[3].Path)
+	}
+	if len(experimental.Worktrees) != 0 {
+		t.Error("worktree entries should not have worktrees of their own")
+	}
+}
+
+func TestValidateWorktrees(t *testing.T) {
+	main := "main"
+	tests := []struct {
+		name      string
+		worktrees []string
+		wantErr   string
+	}{
+		{name: "valid", worktrees: []string{"experimental", "release/2.0"}},
+		{name: "own branch", worktrees: []string{"main"}, wantErr: "own branch"},
+		{name: "empty", worktrees: []string{"//"}, wantErr: "invalid worktree branch"},
+		{name: "same directory", worktrees: []string{"feature/x", "feature-x"}, wantErr: "share the directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := githubEntry(t.TempDir())
+			entry.Branch = &main
+			entry.Worktrees = tt.worktrees
+			err := entry.ValidateTypeSpecificFields()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	local := RepositoryEntry{ID: "local-3f9a0c12", Name: "Local", Type: RepositoryTypeLocal, Path: t.TempDir(), Worktrees: []string{"experimental"}}
+	if err := local.ValidateTypeSpecificFields(); err == nil {
+		t.Error("expected local repositories with worktrees to be rejected")
+	}
+}
@@ -0,0 +1,228 @@
+// Package search implements grep-like searches across rule repositories.
+//
+// Files come from the same validated repository scan the TUI and MCP server use
+// (filemanager.ScanAllRepositories). A repository the scanner cannot index, for
+// example one that fails a storage validation, is searched by walking its
+// directory for markdown files instead, so a search never silently skips it.
+//
+// Results are printed in ripgrep's line-oriented format:
+//
+//	/rules/go/style.md:12:Use the structured logger
+//	/rules/go/style.md-13-instead of fmt.Println.
+//	--
+package search
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+)
+
+// maxSearchedFileSize skips files that are unlikely to be rules
+const maxSearchedFileSize = 5 * 1024 * 1024
+
+// Options controls a search
+type Options struct {
+	Before int // Context lines printed before each match
+	After  int // Context lines printed after each match
+}
+
+// Line is a matching or context line
+type Line struct {
+	Number  int // 1-based
+	Text    string
+	IsMatch bool
+}
+
+// FileResult holds the lines to print for one file. Lines are in order and
+// Groups holds the index in Lines where each non-contiguous group starts.
+type FileResult struct {
+	Path   string
+	Lines  []Line
+	Groups []int
+}
+
+// Matches returns how many lines in the result matched
+func (r FileResult) Matches() int {
+	n := 0
+	for _, line := range r.Lines {
+		if line.IsMatch {
+			n++
+		}
+	}
+	return n
+}
+
+// Files returns the markdown files of every available repository, sorted by
+// path. Repositories missing from the scan are walked directly.
+func Files(prepared []repository.PreparedRepository, logger *logging.AppLogger) []filemanager.FileItem {
+	available := repository.AvailableRepositories(prepared)
+	files, err := filemanager.ScanAllRepositories(available, logger)
+	if err != nil {
+		logger.Warn("Repository scan incomplete, walking unindexed repositories", "error", err)
+	}
+
+	indexed := make(map[string]bool)
+	for _, file := range files {
+		indexed[file.RepositoryID] = true
+	}
+	for _, prep := range available {
+		if indexed[prep.ID()] {
+			continue
+		}
+		for _, root := range []string{prep.LocalPath, prep.OverlayPath} {
+			if root == "" {
+				continue
+			}
+			walked, err := walkMarkdownFiles(root, prep)
+			if err != nil {
+				logger.Warn("Failed to walk repository", "repository_id", prep.ID(), "path", root, "error", err)
+			}
+			files = append(files, walked...)
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// walkMarkdownFiles lists the markdown files under root, skipping hidden
+// directories such as .git
+func walkMarkdownFiles(root string, prep repository.PreparedRepository) ([]filemanager.FileItem, error) {
+	var files []filemanager.FileItem
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries are skipped, like the scanner does
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && strings.EqualFold(filepath.Ext(path), ".md") {
+			files = append(files, filemanager.FileItem{
+				Name:           d.Name(),
+				Path:           path,
+				RepositoryID:   prep.ID(),
+				RepositoryName: prep.Name(),
+				RepositoryType: string(prep.Type()),
+			})
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Grep searches files for lines matching pattern and returns the files with
+// matches, in the order given. Files that cannot be read are skipped.
+func Grep(files []filemanager.FileItem, pattern *regexp.Regexp, opts Options, logger *logging.AppLogger) []FileResult {
+	var results []FileResult
+	for _, file := range files {
+		result, err := grepFile(file.Path, pattern, opts)
+		if err != nil {
+			logger.Debug("Skipping file", "path", file.Path, "error", err)
+			continue
+		}
+		if len(result.Lines) > 0 {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// grepFile searches one file, keeping the context lines around each match
+func grepFile(path string, pattern *regexp.Regexp, opts Options) (FileResult, error) {
+	result := FileResult{Path: path}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return result, err
+	}
+	if info.Size() > maxSearchedFileSize {
+		return result, fmt.Errorf("file too large (%d bytes)", info.Size())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return result, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSearchedFileSize)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return result, err
+	}
+
+	// Mark every line to print: matches plus their context
+	include := make([]bool, len(lines))
+	matches := make([]bool, len(lines))
+	for i, line := range lines {
+		if !pattern.MatchString(line) {
+			continue
+		}
+		matches[i] = true
+		for j := max(0, i-opts.Before); j <= min(len(lines)-1, i+opts.After); j++ {
+			include[j] = true
+		}
+	}
+
+	for i, line := range lines {
+		if !include[i] {
+			continue
+		}
+		if i == 0 || !include[i-1] {
+			result.Groups = append(result.Groups, len(result.Lines))
+		}
+		result.Lines = append(result.Lines, Line{Number: i + 1, Text: line, IsMatch: matches[i]})
+	}
+	return result, nil
+}
+
+// Print writes results in ripgrep's format: "path:line:text" for matches and
+// "path-line-text" for context. With context, "--" separates groups of lines
+// that are not adjacent, also across files.
+func Print(w io.Writer, results []FileResult, opts Options) error {
+	withContext := opts.Before > 0 || opts.After > 0
+	first := true
+	for _, result := range results {
+		for i, line := range result.Lines {
+			if withContext && !first && isGroupStart(result.Groups, i) {
+				if _, err := fmt.Fprintln(w, "--"); err != nil {
+					return err
+				}
+			}
+			first = false
+
+			separator := "-"
+			if line.IsMatch {
+				separator = ":"
+			}
+			if _, err := fmt.Fprintf(w, "%s%s%d%s%s\n", result.Path, separator, line.Number, separator, line.Text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isGroupStart reports whether index i starts a group
+func isGroupStart(groups []int, i int) bool {
+	n := sort.SearchInts(groups, i)
+	return n < len(groups) && groups[n] == i
+}
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestGrepAndPrint(t *testing.T) {
+	dir := t.TempDir()
+	style := writeFile(t, dir, "style.md", "one\ntwo TODO\nthree\nfour\nfive\nsix TODO\nseven\n")
+	other := writeFile(t, dir, "other.md", "todo lowercase\nTODO upper\n")
+	files := []filemanager.FileItem{{Path: other}, {Path: style}}
+	logger, _ := logging.NewTestLogger()
+
+	tests := []struct {
+		name    string
+		pattern string
+		opts    Options
+		want    []string
+	}{
+		{
+			name:    "matches only",
+			pattern: "TODO",
+			want:    []string{other + ":2:TODO upper", style + ":2:two TODO", style + ":6:six TODO"},
+		},
+		{
+			name:    "context with separators",
+			pattern: "TODO",
+			opts:    Options{Before: 1, After: 1},
+			want: []string{
+				other + "-1-todo lowercase", other + ":2:TODO upper",
+				"--",
+				style + "-1-one", style + ":2:two TODO", style + "-3-three",
+				"--",
+				style + "-5-five", style + ":6:six TODO", style + "-7-seven",
+			},
+		},
+		{
+			name:    "overlapping context merges",
+			pattern: "TODO",
+			opts:    Options{After: 3},
+			want: []string{
+				other + ":2:TODO upper",
+				"--",
+				style + ":2:two TODO", style + "-3-three", style + "-4-four", style + "-5-five", style + ":6:six TODO", style + "-7-seven",
+			},
+		},
+		{
+			name:    "case insensitive",
+			pattern: "(?i)^todo",
+			want:    []string{other + ":1:todo lowercase", other + ":2:TODO upper"},
+		},
+		{
+			name:    "no matches",
+			pattern: "python",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Grep(files, regexp.MustCompile(tt.pattern), tt.opts, logger)
+			var buf bytes.Buffer
+			if err := Print(&buf, results, tt.opts); err != nil {
+				t.Fatalf("Print: %v", err)
+			}
+			got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if len(tt.want) == 0 {
+				if buf.Len() != 0 {
+					t.Errorf("expected no output, got %q", buf.String())
+				}
+				return
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("output:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	dir := t.TempDir()
+	writeFile(t, dir, "b.md", "# b\n")
+	writeFile(t, dir, "go/a.md", "# a\n")
+
+	prepared, err := repository.PrepareAllRepositories(context.Background(), []repository.RepositoryEntry{
+		{ID: "rules-1", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: dir},
+		{ID: "missing-1", Name: "Missing", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: filepath.Join(dir, "gone")},
+	}, logger)
+	if err != nil {
+		t.Fatalf("PrepareAllRepositories: %v", err)
+	}
+
+	var got []string
+	for _, file := range Files(prepared, logger) {
+		rel, _ := filepath.Rel(dir, file.Path)
+		got = append(got, file.RepositoryID+":"+filepath.ToSlash(rel))
+	}
+	if want := "rules-1:b.md,rules-1:go/a.md"; strings.Join(got, ",") != want {
+		t.Errorf("Files() = %v, want %s", got, want)
+	}
+}
+
+func TestWalkMarkdownFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "nested/b.md", "# b\n")
+	writeFile(t, dir, "notes.txt", "text\n")
+	writeFile(t, dir, ".git/c.md", "# c\n")
+	prep := repository.PreparedRepository{
+		Entry:     repository.RepositoryEntry{ID: "walked-1", Name: "Walked", Type: repository.RepositoryTypeLocal, Path: dir},
+		LocalPath: dir,
+	}
+
+	files, err := walkMarkdownFiles(dir, prep)
+	if err != nil {
+		t.Fatalf("walkMarkdownFiles: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "b.md" || files[0].RepositoryID != "walked-1" || files[0].RepositoryName != "Walked" {
+		t.Errorf("walkMarkdownFiles() = %+v", files)
+	}
+}
@@ -0,0 +1,141 @@
+// Package share exports rules as anonymized bundles that can be published.
+//
+// A bundle is a zip archive of the selected rule files, frontmatter included,
+// at their paths relative to the repository root. The redactions from the
+// configuration are applied to the content and path of every file, so
+// organization-identifying strings such as company names, internal hostnames
+// and repository URLs are replaced before the rules leave the machine:
+//
+//	redactions:
+//	  - pattern: '(?i)acme corp'
+//	    replace: Example Corp
+//	  - pattern: '[a-z0-9.-]+\.acme\.internal'
+//	    replace: example.com
+//
+// Bundles are reproducible: exporting the same rules twice gives identical
+// archives.
+package share
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"rulem/internal/config"
+)
+
+// DefaultReplacement replaces the matches of redactions without a replacement
+const DefaultReplacement = "[REDACTED]"
+
+// Redactor applies redactions to text
+type Redactor struct {
+	rules []redactionRule
+}
+
+type redactionRule struct {
+	pattern *regexp.Regexp
+	replace string
+}
+
+// NewRedactor compiles redactions, which are applied in order
+func NewRedactor(redactions []config.Redaction) (*Redactor, error) {
+	r := &Redactor{rules: make([]redactionRule, 0, len(redactions))}
+	for i, redaction := range redactions {
+		if redaction.Pattern == "" {
+			return nil, fmt.Errorf("redaction %d has no pattern", i+1)
+		}
+		pattern, err := regexp.Compile(redaction.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redaction %d has an invalid pattern: %w", i+1, err)
+		}
+		replace := redaction.Replace
+		if replace == "" {
+			replace = DefaultReplacement
+		}
+		r.rules = append(r.rules, redactionRule{pattern: pattern, replace: replace})
+	}
+	return r, nil
+}
+
+// Empty reports whether the redactor has no redactions, so exports are not anonymized
+func (r *Redactor) Empty() bool {
+	return len(r.rules) == 0
+}
+
+// Redact applies every redaction to s and returns the result with the number
+// of matches replaced
+func (r *Redactor) Redact(s string) (string, int) {
+	count := 0
+	for _, rule := range r.rules {
+		count += len(rule.pattern.FindAllStringIndex(s, -1))
+		s = rule.pattern.ReplaceAllString(s, rule.replace)
+	}
+	return s, count
+}
+
+// File is a rule to export
+type File struct {
+	Path    string // Relative to the repository root, slash-separated
+	Content []byte
+}
+
+// Exported describes a file written to a bundle
+type Exported struct {
+	Source     string // Path before redaction
+	Path       string // Path in the bundle
+	Redactions int    // Matches replaced in the path and content
+}
+
+// WriteBundle redacts files and writes them to w as a zip archive, in order of
+// their paths in the bundle. Files that would end up at the same path, or
+// whose redacted path leaves the bundle, are an error.
+func WriteBundle(w io.Writer, files []File, redactor *Redactor) ([]Exported, error) {
+	type entry struct {
+		Exported
+		content string
+	}
+	entries := make([]entry, 0, len(files))
+	seen := make(map[string]string, len(files))
+	for _, file := range files {
+		bundlePath, pathCount := redactor.Redact(file.Path)
+		bundlePath = path.Clean(bundlePath)
+		if !filepath.IsLocal(filepath.FromSlash(bundlePath)) || path.IsAbs(bundlePath) {
+			return nil, fmt.Errorf("%s is redacted to %s, which is not a relative path", file.Path, bundlePath)
+		}
+		if other, ok := seen[bundlePath]; ok {
+			return nil, fmt.Errorf("%s and %s would both be exported as %s", other, file.Path, bundlePath)
+		}
+		seen[bundlePath] = file.Path
+
+		content, contentCount := redactor.Redact(string(file.Content))
+		entries = append(entries, entry{
+			Exported: Exported{Source: file.Path, Path: bundlePath, Redactions: pathCount + contentCount},
+			content:  content,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	archive := zip.NewWriter(w)
+	exported := make([]Exported, 0, len(entries))
+	for _, e := range entries {
+		// A zero modification time keeps bundles reproducible
+		header := &zip.FileHeader{Name: e.Path, Method: zip.Deflate}
+		header.SetMode(0644)
+		fw, err := archive.CreateHeader(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to bundle: %w", e.Path, err)
+		}
+		if _, err := io.WriteString(fw, e.content); err != nil {
+			return nil, fmt.Errorf("failed to add %s to bundle: %w", e.Path, err)
+		}
+		exported = append(exported, e.Exported)
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return exported, nil
+}
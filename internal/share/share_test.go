@@ -0,0 +1,139 @@
+package share
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"rulem/internal/config"
+)
+
+func TestNewRedactor_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		redactions []config.Redaction
+		wantErr    string
+	}{
+		{"empty pattern", []config.Redaction{{Replace: "x"}}, "redaction 1 has no pattern"},
+		{"invalid pattern", []config.Redaction{{Pattern: "acme"}, {Pattern: "("}}, "redaction 2 has an invalid pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRedactor(tt.redactions)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewRedactor() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	redactor, err := NewRedactor([]config.Redaction{
+		{Pattern: `(?i)acme corp`, Replace: "Example Corp"},
+		{Pattern: `([a-z]+)\.acme\.internal`, Replace: "$1.example.com"},
+		{Pattern: `jane@acme\.com`},
+	})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+
+	got, count := redactor.Redact("ACME Corp rules: ask jane@acme.com, deploy to ci.acme.internal and cd.acme.internal")
+	want := "Example Corp rules: ask [REDACTED], deploy to ci.example.com and cd.example.com"
+	if got != want || count != 4 {
+		t.Errorf("Redact() = %q, %d; want %q, 4", got, count, want)
+	}
+
+	empty, _ := NewRedactor(nil)
+	if !empty.Empty() || redactor.Empty() {
+		t.Error("Empty() should only be true without redactions")
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	redactor, err := NewRedactor([]config.Redaction{{Pattern: `acme`, Replace: "example"}})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+	files := []File{
+		{Path: "go/style.md", Content: []byte("Use the acme logger.\n")},
+		{Path: "acme/review.md", Content: []byte("Ask acme reviewers at acme.dev.\n")},
+	}
+
+	var buf bytes.Buffer
+	exported, err := WriteBundle(&buf, files, redactor)
+	if err != nil {
+		t.Fatalf("WriteBundle: %v", err)
+	}
+	wantExported := []Exported{
+		{Source: "acme/review.md", Path: "example/review.md", Redactions: 3},
+		{Source: "go/style.md", Path: "go/style.md", Redactions: 1},
+	}
+	if !reflect.DeepEqual(exported, wantExported) {
+		t.Errorf("exported = %+v, want %+v", exported, wantExported)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open bundle: %v", err)
+	}
+	got := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(r)
+		r.Close()
+		got[f.Name] = string(content)
+	}
+	want := map[string]string{
+		"example/review.md": "Ask example reviewers at example.dev.\n",
+		"go/style.md":       "Use the example logger.\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bundle = %q, want %q", got, want)
+	}
+
+	// The same rules give the same bundle
+	var again bytes.Buffer
+	if _, err := WriteBundle(&again, files, redactor); err != nil {
+		t.Fatalf("WriteBundle: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Error("bundles of the same rules should be identical")
+	}
+}
+
+func TestWriteBundle_PathConflicts(t *testing.T) {
+	redactor, _ := NewRedactor([]config.Redaction{{Pattern: `(acme|globex)`, Replace: "example"}, {Pattern: `^private/`, Replace: "../"}})
+
+	tests := []struct {
+		name    string
+		files   []File
+		wantErr string
+	}{
+		{
+			name:    "same path after redaction",
+			files:   []File{{Path: "acme.md"}, {Path: "globex.md"}},
+			wantErr: "acme.md and globex.md would both be exported as example.md",
+		},
+		{
+			name:    "path leaves the bundle",
+			files:   []File{{Path: "private/notes.md"}},
+			wantErr: "private/notes.md is redacted to ../notes.md, which is not a relative path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := WriteBundle(io.Discard, tt.files, redactor)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("WriteBundle() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/frontmatter"
+	"rulem/internal/logging"
+	"rulem/internal/mcp"
+	"rulem/internal/repository"
+)
+
+// Rule usage statistics
+//
+// `rulem stats` ranks every rule by how often assistants were served it over a
+// period, from the MCP usage log, so the rules nobody reads stand out next to
+// the ones read all the time. Rules that were not served at all are listed
+// with a count of 0. Calls are matched to rules by file when the log recorded
+// one, so a rule keeps its count when its tool name changes; calls for rules
+// that are no longer served, e.g. deleted ones, are only counted in total.
+
+// DefaultStatsPeriod is the span of `rulem stats`
+const DefaultStatsPeriod = 30 * 24 * time.Hour
+
+// RuleUsage is how often a rule was served during a period
+type RuleUsage struct {
+	Rule       string    `json:"rule"`       // Tool name
+	Repository string    `json:"repository"` // Repository name
+	Path       string    `json:"path"`       // Relative to the repository root, slash-separated
+	Count      int       `json:"count"`
+	LastUsed   time.Time `json:"lastUsed,omitzero"`
+	Clients    []string  `json:"clients,omitempty"` // Clients the rule was served to, sorted
+}
+
+// Stats is the rule usage between From and To
+type Stats struct {
+	From    time.Time   `json:"from"`
+	To      time.Time   `json:"to"`
+	Calls   int         `json:"calls"`             // Rules served, including Removed
+	Removed int         `json:"removed,omitempty"` // Calls for rules that are no longer served
+	Clients []string    `json:"clients,omitempty"` // Sorted
+	Rules   []RuleUsage `json:"rules"`             // Most used first, then by rule
+}
+
+// GenerateStats prepares the configured repositories, syncing GitHub clones,
+// and reports the rule usage of the period ending at to. With repositoryID set
+// only the rules of that repository are reported.
+func GenerateStats(ctx context.Context, cfg *config.Config, logger *logging.AppLogger, repositoryID string, period time.Duration, to time.Time) (*Stats, error) {
+	prepared, tools, err := mcp.PrepareAndLoadRuleTools(ctx, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	if repositoryID != "" {
+		prepared = slices.DeleteFunc(prepared, func(prep repository.PreparedRepository) bool {
+			return prep.ID() != repositoryID
+		})
+	}
+
+	from := to.Add(-period)
+	calls, err := mcp.ReadToolCalls(mcp.UsagePath(), from)
+	if err != nil {
+		return nil, err
+	}
+	return BuildStats(prepared, tools, calls, from, to), nil
+}
+
+// BuildStats reports the usage of the rules in tools from prepared between
+// from and to. calls are the usage log entries of the period.
+func BuildStats(prepared []repository.PreparedRepository, tools map[string]*mcp.RuleFileTool, calls []mcp.ToolCall, from, to time.Time) *Stats {
+	stats := &Stats{From: from, To: to}
+	names := make(map[string]string, len(prepared))
+	for _, prep := range prepared {
+		names[prep.ID()] = prep.Name()
+	}
+
+	type toolKey struct{ repository, tool string }
+	byFile := make(map[string]*RuleUsage)
+	byTool := make(map[toolKey]*RuleUsage)
+	usage := make([]*RuleUsage, 0, len(tools))
+	for _, tool := range tools {
+		repoName, ok := names[tool.RuleFile.RepositoryID]
+		if !ok {
+			continue
+		}
+		rule := &RuleUsage{Rule: tool.Name, Repository: repoName, Path: relativePath(prepared, tool.RuleFile)}
+		usage = append(usage, rule)
+		byFile[tool.RuleFile.FilePath] = rule
+		byTool[toolKey{tool.RuleFile.RepositoryID, tool.Name}] = rule
+	}
+
+	clients := make(map[string]bool)
+	for _, call := range calls {
+		if call.Time.Before(from) || call.Time.After(to) {
+			continue
+		}
+		if _, ok := names[call.Repository]; !ok {
+			continue // Served from a repository that is not in this report
+		}
+		stats.Calls++
+		if call.Client != "" {
+			clients[call.Client] = true
+		}
+
+		rule, ok := byFile[call.File]
+		if !ok {
+			rule, ok = byTool[toolKey{call.Repository, call.Tool}]
+		}
+		if !ok {
+			stats.Removed++
+			continue
+		}
+		rule.Count++
+		if call.Time.After(rule.LastUsed) {
+			rule.LastUsed = call.Time
+		}
+		if call.Client != "" && !slices.Contains(rule.Clients, call.Client) {
+			rule.Clients = append(rule.Clients, call.Client)
+		}
+	}
+
+	for client := range clients {
+		stats.Clients = append(stats.Clients, client)
+	}
+	sort.Strings(stats.Clients)
+
+	stats.Rules = make([]RuleUsage, 0, len(usage))
+	for _, rule := range usage {
+		sort.Strings(rule.Clients)
+		stats.Rules = append(stats.Rules, *rule)
+	}
+	sort.Slice(stats.Rules, func(i, j int) bool {
+		a, b := stats.Rules[i], stats.Rules[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Repository < b.Repository
+	})
+	return stats
+}
+
+// MostUsed returns up to n of the most used rules, most used first
+func (s *Stats) MostUsed(n int) []RuleUsage {
+	return s.Rules[:min(n, len(s.Rules))]
+}
+
+// LeastUsed returns up to n of the least used rules that are not among the n
+// most used, least used first
+func (s *Stats) LeastUsed(n int) []RuleUsage {
+	rest := s.Rules[min(n, len(s.Rules)):]
+	least := slices.Clone(rest[max(len(rest)-n, 0):])
+	slices.Reverse(least)
+	return least
+}
+
+// Text renders the n most and least used rules for the terminal
+func (s *Stats) Text(n int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Rule usage %s to %s: %d served", s.From.Format(frontmatter.DateLayout), s.To.Format(frontmatter.DateLayout), s.Calls)
+	if len(s.Clients) > 0 {
+		fmt.Fprintf(&b, " to %s", strings.Join(s.Clients, ", "))
+	}
+	b.WriteString("\n")
+	if s.Removed > 0 {
+		fmt.Fprintf(&b, "%d of them were rules that are no longer served.\n", s.Removed)
+	}
+	if len(s.Rules) == 0 {
+		b.WriteString("\nNo rules found.\n")
+		return b.String()
+	}
+
+	writeUsage(&b, "Most used", s.MostUsed(n))
+	if least := s.LeastUsed(n); len(least) > 0 {
+		writeUsage(&b, "Least used", least)
+	}
+	return b.String()
+}
+
+// writeUsage writes a titled table of rules
+func writeUsage(b *strings.Builder, title string, rules []RuleUsage) {
+	fmt.Fprintf(b, "\n%s:\n", title)
+	w := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	for _, rule := range rules {
+		last := "never"
+		if !rule.LastUsed.IsZero() {
+			last = "last " + rule.LastUsed.Format(frontmatter.DateLayout)
+		}
+		fmt.Fprintf(w, "%6d\t%s\t%s\t%s\t%s\n", rule.Count, rule.Rule, rule.Repository, rule.Path, last)
+	}
+	w.Flush()
+}
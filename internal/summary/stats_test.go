@@ -0,0 +1,73 @@
+package summary
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"rulem/internal/mcp"
+	"rulem/internal/repository"
+)
+
+func TestBuildStats(t *testing.T) {
+	dir := t.TempDir()
+	to := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-DefaultStatsPeriod)
+
+	prepared := []repository.PreparedRepository{{
+		Entry:     repository.RepositoryEntry{ID: "rules-1", Name: "Rules", Type: repository.RepositoryTypeLocal, Path: dir},
+		LocalPath: dir,
+	}}
+	stylePath := filepath.Join(dir, "go", "style.md")
+	tools := map[string]*mcp.RuleFileTool{
+		"go_style":   {Name: "go_style", RuleFile: &mcp.RuleFile{RepositoryID: "rules-1", FilePath: stylePath}},
+		"go_errors":  {Name: "go_errors", RuleFile: &mcp.RuleFile{RepositoryID: "rules-1", FilePath: filepath.Join(dir, "go", "errors.md")}},
+		"py_testing": {Name: "py_testing", RuleFile: &mcp.RuleFile{RepositoryID: "rules-1", FilePath: filepath.Join(dir, "py", "testing.md")}},
+	}
+	calls := []mcp.ToolCall{
+		{Time: to.Add(-time.Hour), Tool: "go_style", Repository: "rules-1", File: stylePath, Client: "cursor"},
+		// Renamed since, but still the same file
+		{Time: to.Add(-2 * time.Hour), Tool: "style", Repository: "rules-1", File: stylePath, Client: "claude-code"},
+		// Logged before files were recorded
+		{Time: to.Add(-3 * time.Hour), Tool: "go_errors", Repository: "rules-1"},
+		{Time: to.Add(-4 * time.Hour), Tool: "deleted", Repository: "rules-1", File: filepath.Join(dir, "deleted.md"), Client: "cursor"},
+		{Time: from.Add(-time.Hour), Tool: "py_testing", Repository: "rules-1"},
+		{Time: to.Add(-time.Hour), Tool: "other", Repository: "removed-2"},
+	}
+
+	stats := BuildStats(prepared, tools, calls, from, to)
+
+	if stats.Calls != 4 || stats.Removed != 1 {
+		t.Errorf("Calls = %d, Removed = %d; want 4 and 1", stats.Calls, stats.Removed)
+	}
+	if want := []string{"claude-code", "cursor"}; !reflect.DeepEqual(stats.Clients, want) {
+		t.Errorf("Clients = %v, want %v", stats.Clients, want)
+	}
+	want := []RuleUsage{
+		{Rule: "go_style", Repository: "Rules", Path: "go/style.md", Count: 2, LastUsed: to.Add(-time.Hour), Clients: []string{"claude-code", "cursor"}},
+		{Rule: "go_errors", Repository: "Rules", Path: "go/errors.md", Count: 1, LastUsed: to.Add(-3 * time.Hour)},
+		{Rule: "py_testing", Repository: "Rules", Path: "py/testing.md"},
+	}
+	if !reflect.DeepEqual(stats.Rules, want) {
+		t.Errorf("Rules = %+v, want %+v", stats.Rules, want)
+	}
+
+	if most := stats.MostUsed(1); len(most) != 1 || most[0].Rule != "go_style" {
+		t.Errorf("MostUsed(1) = %+v, want go_style", most)
+	}
+	if least := stats.LeastUsed(1); len(least) != 1 || least[0].Rule != "py_testing" {
+		t.Errorf("LeastUsed(1) = %+v, want py_testing", least)
+	}
+	if least := stats.LeastUsed(3); len(least) != 0 {
+		t.Errorf("LeastUsed(3) = %+v, want none when every rule is among the most used", least)
+	}
+
+	text := stats.Text(1)
+	for _, want := range []string{"Rule usage 2026-09-16 to 2026-10-16: 4 served to claude-code, cursor", "1 of them were rules that are no longer served", "Most used:", "go_style", "Least used:", "py_testing", "never"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() should contain %q, got:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// Package summary builds the periodic report of rule activity: which rules were
+// added or changed, which rules assistants asked for most over MCP, and which
+// rules are due for review or about to expire.
+//
+// Changes come from git history for git repositories, and from modification
+// times for plain directories and overlays, which have no history. Served rules
+// come from the MCP usage log (see mcp.UsagePath). Reviews and expiry come from
+// the reviewBy and expires frontmatter dates: a rule is listed when its date
+// falls before the end of the next period, so overdue rules stay listed until
+// they are dealt with.
+package summary
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/frontmatter"
+	"rulem/internal/logging"
+	"rulem/internal/mcp"
+	"rulem/internal/repository"
+	"rulem/pkg/fileops"
+)
+
+// DefaultPeriod is the span of a weekly summary
+const DefaultPeriod = 7 * 24 * time.Hour
+
+// Dir is the directory of a repository summaries are written to. Its files are
+// not rules, so they are left out of the changes.
+const Dir = "summaries"
+
+// Change is a rule file added, modified or deleted during the period
+type Change struct {
+	Repository string // Repository name
+	Path       string // Relative to the repository root, slash-separated
+	Kind       repository.FileChangeKind
+}
+
+// Served is how often a rule was served to assistants during the period
+type Served struct {
+	Rule       string // Tool name
+	Repository string // Repository name
+	Count      int
+}
+
+// Due is a rule with a review or expiry date
+type Due struct {
+	Rule       string // Tool name
+	Repository string // Repository name
+	Path       string // Relative to the repository root, slash-separated
+	Date       time.Time
+}
+
+// Report is the rule activity between From and To
+type Report struct {
+	From     time.Time
+	To       time.Time
+	Changes  []Change
+	Served   []Served // Most served first
+	Reviews  []Due    // Earliest first
+	Expiring []Due    // Earliest first
+	// Warnings lists repositories whose changes could not be read
+	Warnings []string
+}
+
+// Generate prepares the configured repositories, syncing GitHub clones, and
+// reports the period ending at to. With repositoryID set only that repository
+// is reported.
+func Generate(ctx context.Context, cfg *config.Config, logger *logging.AppLogger, repositoryID string, period time.Duration, to time.Time) (*Report, error) {
+	prepared, tools, err := mcp.PrepareAndLoadRuleTools(ctx, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	if repositoryID != "" {
+		prepared = slices.DeleteFunc(prepared, func(prep repository.PreparedRepository) bool {
+			return prep.ID() != repositoryID
+		})
+	}
+
+	from := to.Add(-period)
+	calls, err := mcp.ReadToolCalls(mcp.UsagePath(), from)
+	if err != nil {
+		return nil, err
+	}
+	return Build(prepared, tools, calls, from, to), nil
+}
+
+// Build reports the activity in prepared between from and to. tools are the
+// rules loaded from prepared and calls the usage log entries of the period.
+func Build(prepared []repository.PreparedRepository, tools map[string]*mcp.RuleFileTool, calls []mcp.ToolCall, from, to time.Time) *Report {
+	report := &Report{From: from, To: to}
+	names := make(map[string]string, len(prepared))
+	for _, prep := range prepared {
+		names[prep.ID()] = prep.Name()
+		changes, err := repositoryChanges(prep, from)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", prep.Name(), err))
+		}
+		report.Changes = append(report.Changes, changes...)
+	}
+
+	counts := make(map[Served]int)
+	for _, call := range calls {
+		if call.Time.Before(from) || call.Time.After(to) {
+			continue
+		}
+		repoName, ok := names[call.Repository]
+		if !ok {
+			continue // Served from a repository that is not in this report
+		}
+		counts[Served{Rule: call.Tool, Repository: repoName}]++
+	}
+	for served, count := range counts {
+		served.Count = count
+		report.Served = append(report.Served, served)
+	}
+	sort.Slice(report.Served, func(i, j int) bool {
+		a, b := report.Served[i], report.Served[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Rule < b.Rule
+	})
+
+	horizon := to.Add(to.Sub(from))
+	for _, tool := range tools {
+		repoName, ok := names[tool.RuleFile.RepositoryID]
+		if !ok {
+			continue
+		}
+		due := Due{Rule: tool.Name, Repository: repoName, Path: relativePath(prepared, tool.RuleFile)}
+		if date, err := time.Parse(frontmatter.DateLayout, tool.RuleFile.ReviewBy); err == nil && date.Before(horizon) {
+			due.Date = date
+			report.Reviews = append(report.Reviews, due)
+		}
+		if date, err := time.Parse(frontmatter.DateLayout, tool.RuleFile.Expires); err == nil && date.Before(horizon) {
+			due.Date = date
+			report.Expiring = append(report.Expiring, due)
+		}
+	}
+	sortDue(report.Reviews)
+	sortDue(report.Expiring)
+	return report
+}
+
+// IsEmpty reports whether nothing happened during the period and nothing is due
+func (r *Report) IsEmpty() bool {
+	return len(r.Changes) == 0 && len(r.Served) == 0 && len(r.Reviews) == 0 && len(r.Expiring) == 0
+}
+
+// Markdown renders the report as a markdown document
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Rule activity %s to %s\n", r.From.Format(frontmatter.DateLayout), r.To.Format(frontmatter.DateLayout))
+
+	b.WriteString("\n## New and changed rules\n\n")
+	if len(r.Changes) == 0 {
+		b.WriteString("No rules changed.\n")
+	}
+	for _, change := range r.Changes {
+		fmt.Fprintf(&b, "- `%s` in %s (%s)\n", change.Path, change.Repository, kindName(change.Kind))
+	}
+
+	b.WriteString("\n## Most-served rules\n\n")
+	if len(r.Served) == 0 {
+		b.WriteString("No rules were served over MCP.\n")
+	} else {
+		b.WriteString("| Rule | Repository | Times served |\n|---|---|---|\n")
+	}
+	for _, served := range r.Served {
+		fmt.Fprintf(&b, "| `%s` | %s | %d |\n", served.Rule, served.Repository, served.Count)
+	}
+
+	writeDue(&b, "Reviews due", "No reviews due.", "review by", r.Reviews, r.To)
+	writeDue(&b, "Expiring rules", "No rules expiring.", "expires", r.Expiring, r.To)
+
+	if len(r.Warnings) > 0 {
+		b.WriteString("\n## Warnings\n\n")
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(&b, "- %s\n", warning)
+		}
+	}
+	return b.String()
+}
+
+// FileName is the name of the report's markdown file, e.g. "2026-10-16.md"
+func (r *Report) FileName() string {
+	return r.To.Format(frontmatter.DateLayout) + ".md"
+}
+
+// Write saves the report's markdown to Dir in the repository at root and
+// returns the path of the file, replacing an earlier report of the same day
+func (r *Report) Write(root string) (string, error) {
+	dir := filepath.Join(root, Dir)
+	if err := fileops.EnsureDirectoryExists(dir); err != nil {
+		return "", fmt.Errorf("cannot create summaries directory: %w", err)
+	}
+	path := filepath.Join(dir, r.FileName())
+	if err := os.WriteFile(path, []byte(r.Markdown()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write summary: %w", err)
+	}
+	return path, nil
+}
+
+func writeDue(b *strings.Builder, heading, none, verb string, due []Due, now time.Time) {
+	fmt.Fprintf(b, "\n## %s\n\n", heading)
+	if len(due) == 0 {
+		b.WriteString(none + "\n")
+	}
+	for _, d := range due {
+		overdue := ""
+		if d.Date.Before(now) {
+			overdue = " (overdue)"
+		}
+		fmt.Fprintf(b, "- `%s` in %s (`%s`): %s %s%s\n", d.Rule, d.Repository, d.Path, verb, d.Date.Format(frontmatter.DateLayout), overdue)
+	}
+}
+
+func kindName(kind repository.FileChangeKind) string {
+	switch kind {
+	case repository.FileChangeAdded:
+		return "new"
+	case repository.FileChangeDeleted:
+		return "deleted"
+	default:
+		return "changed"
+	}
+}
+
+func sortDue(due []Due) {
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].Date.Equal(due[j].Date) {
+			return due[i].Date.Before(due[j].Date)
+		}
+		return due[i].Rule < due[j].Rule
+	})
+}
+
+// repositoryChanges lists the rule files of prep changed since from: from git
+// history when the repository is a git repository, otherwise from modification
+// times. Overlay files are always compared by modification time.
+func repositoryChanges(prep repository.PreparedRepository, from time.Time) ([]Change, error) {
+	var changes []Change
+	if prep.OverlayPath != "" {
+		for _, path := range modifiedSince(prep.OverlayPath, from) {
+			changes = append(changes, Change{Repository: prep.Name(), Path: path, Kind: repository.FileChangeModified})
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(prep.GitRoot(), ".git")); err != nil {
+		for _, path := range modifiedSince(prep.LocalPath, from) {
+			changes = append(changes, Change{Repository: prep.Name(), Path: path, Kind: repository.FileChangeModified})
+		}
+		return changes, nil
+	}
+
+	files, err := repository.ChangedFilesSince(prep.GitRoot(), from)
+	if err != nil {
+		return changes, err
+	}
+	for _, file := range files {
+		path, ok := repository.RelativeToSubpath(file.Path, prep.Entry.GetSubpath())
+		if ok && isRuleFile(path) {
+			changes = append(changes, Change{Repository: prep.Name(), Path: path, Kind: file.Kind})
+		}
+	}
+	return changes, nil
+}
+
+// modifiedSince lists the rule files under root modified at or after from,
+// skipping hidden directories such as .git
+func modifiedSince(root string, from time.Time) []string {
+	var paths []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries are skipped
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		if !d.Type().IsRegular() || !isRuleFile(rel) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && !info.ModTime().Before(from) {
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return paths
+}
+
+// isRuleFile reports whether path, relative to a repository root, may be a rule
+func isRuleFile(path string) bool {
+	first, _, _ := strings.Cut(filepath.ToSlash(path), "/")
+	return strings.EqualFold(filepath.Ext(path), ".md") && first != Dir
+}
+
+// relativePath returns rule's path relative to its repository or overlay root,
+// or its absolute path when it is in neither
+func relativePath(prepared []repository.PreparedRepository, rule *mcp.RuleFile) string {
+	for _, prep := range prepared {
+		if prep.ID() != rule.RepositoryID {
+			continue
+		}
+		for _, root := range []string{prep.OverlayPath, prep.LocalPath} {
+			if root == "" {
+				continue
+			}
+			if rel, err := filepath.Rel(root, rule.FilePath); err == nil && filepath.IsLocal(rel) {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return rule.FilePath
+}
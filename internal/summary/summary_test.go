@@ -0,0 +1,115 @@
+package summary
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"rulem/internal/mcp"
+	"rulem/internal/repository"
+)
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	to := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-DefaultPeriod)
+
+	// A plain directory has no history, so modification times decide what changed
+	for name, modified := range map[string]time.Time{
+		"go/style.md":       to.Add(-time.Hour),
+		"old.md":            from.Add(-time.Hour),
+		"summaries/prev.md": to.Add(-time.Hour),
+		"notes.txt":         to.Add(-time.Hour),
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# rule\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prepared := []repository.PreparedRepository{{
+		Entry:     repository.RepositoryEntry{ID: "rules-1", Name: "Rules", Type: repository.RepositoryTypeLocal, Path: dir},
+		LocalPath: dir,
+	}}
+	tools := map[string]*mcp.RuleFileTool{
+		"go_style": {Name: "go_style", RuleFile: &mcp.RuleFile{RepositoryID: "rules-1", FilePath: filepath.Join(dir, "go", "style.md"), ReviewBy: "2026-10-20"}},
+		"old":      {Name: "old", RuleFile: &mcp.RuleFile{RepositoryID: "rules-1", FilePath: filepath.Join(dir, "old.md"), Expires: "2026-10-01", ReviewBy: "2027-01-01"}},
+	}
+	calls := []mcp.ToolCall{
+		{Time: to.Add(-time.Hour), Tool: "old", Repository: "rules-1"},
+		{Time: to.Add(-2 * time.Hour), Tool: "go_style", Repository: "rules-1"},
+		{Time: to.Add(-3 * time.Hour), Tool: "go_style", Repository: "rules-1"},
+		{Time: from.Add(-time.Hour), Tool: "old", Repository: "rules-1"},
+		{Time: to.Add(-time.Hour), Tool: "other", Repository: "removed-2"},
+	}
+
+	report := Build(prepared, tools, calls, from, to)
+
+	wantChanges := []Change{{Repository: "Rules", Path: "go/style.md", Kind: repository.FileChangeModified}}
+	if !reflect.DeepEqual(report.Changes, wantChanges) {
+		t.Errorf("Changes = %+v, want %+v", report.Changes, wantChanges)
+	}
+	wantServed := []Served{{Rule: "go_style", Repository: "Rules", Count: 2}, {Rule: "old", Repository: "Rules", Count: 1}}
+	if !reflect.DeepEqual(report.Served, wantServed) {
+		t.Errorf("Served = %+v, want %+v", report.Served, wantServed)
+	}
+	wantReviews := []Due{{Rule: "go_style", Repository: "Rules", Path: "go/style.md", Date: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)}}
+	if !reflect.DeepEqual(report.Reviews, wantReviews) {
+		t.Errorf("Reviews = %+v, want %+v", report.Reviews, wantReviews)
+	}
+	wantExpiring := []Due{{Rule: "old", Repository: "Rules", Path: "old.md", Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}}
+	if !reflect.DeepEqual(report.Expiring, wantExpiring) {
+		t.Errorf("Expiring = %+v, want %+v", report.Expiring, wantExpiring)
+	}
+	if report.IsEmpty() || report.FileName() != "2026-10-16.md" {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestReport_Markdown(t *testing.T) {
+	to := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	report := &Report{
+		From:     to.Add(-DefaultPeriod),
+		To:       to,
+		Changes:  []Change{{Repository: "Rules", Path: "go/style.md", Kind: repository.FileChangeAdded}},
+		Served:   []Served{{Rule: "go_style", Repository: "Rules", Count: 3}},
+		Expiring: []Due{{Rule: "old", Repository: "Rules", Path: "old.md", Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}},
+		Warnings: []string{"Team: failed to open repository"},
+	}
+
+	want := "# Rule activity 2026-10-09 to 2026-10-16\n" +
+		"\n## New and changed rules\n\n- `go/style.md` in Rules (new)\n" +
+		"\n## Most-served rules\n\n| Rule | Repository | Times served |\n|---|---|---|\n| `go_style` | Rules | 3 |\n" +
+		"\n## Reviews due\n\nNo reviews due.\n" +
+		"\n## Expiring rules\n\n- `old` in Rules (`old.md`): expires 2026-10-01 (overdue)\n" +
+		"\n## Warnings\n\n- Team: failed to open repository\n"
+	if got := report.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+
+	root := t.TempDir()
+	path, err := report.Write(root)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if want := filepath.Join(root, Dir, "2026-10-16.md"); path != want {
+		t.Errorf("Write() path = %q, want %q", path, want)
+	}
+	if written, _ := os.ReadFile(path); string(written) != want {
+		t.Errorf("written summary =\n%s\nwant\n%s", written, want)
+	}
+
+	empty := &Report{From: report.From, To: to}
+	if !empty.IsEmpty() || !strings.Contains(empty.Markdown(), "No rules were served over MCP.") {
+		t.Errorf("unexpected empty report:\n%s", empty.Markdown())
+	}
+}
@@ -0,0 +1,279 @@
+// Package dirbrowser provides a directory browser for the rulem TUI.
+//
+// The browser lets the user walk the folders below a root directory, such as
+// a rules repository, and pick one, or name a new folder to create. It is
+// confined to the root: paths are relative to it, use forward slashes, and ""
+// is the root itself. The browser only lists directories; it never creates
+// them, so a new folder only exists once the owning model writes into it.
+//
+// The first row picks the current folder, ".." goes up and every other row
+// opens a subfolder. Enter or → opens the highlighted row, ← or Backspace goes
+// up, and n starts naming a new folder.
+//
+// Like the form component, the browser does not handle Esc outside the new
+// folder input: the owning model decides what cancelling means.
+//
+// Typical usage:
+//
+//	b := dirbrowser.New(fm.ListSubdirectories)
+//	b, cmd = b.Update(msg)
+//	if b.Chosen() {
+//	    dir := b.Dir()
+//	}
+package dirbrowser
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"rulem/internal/tui/styles"
+	"rulem/pkg/fileops"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ListFunc returns the names of the folders directly inside dir, a path
+// relative to the browser's root
+type ListFunc func(dir string) ([]string, error)
+
+// defaultHeight is the number of rows shown before the list scrolls
+const defaultHeight = 10
+
+// Model is the Bubble Tea model for a directory browser
+type Model struct {
+	list    ListFunc
+	dir     string   // current folder relative to the root, "" for the root
+	entries []string // folders inside dir
+	cursor  int      // highlighted row; see rows
+	offset  int      // first visible row
+	height  int
+	err     error // listing or naming error shown under the rows
+
+	creating bool // naming a new folder
+	input    textinput.Model
+	chosen   bool
+}
+
+// New creates a browser at the root, listing folders with list
+func New(list ListFunc) Model {
+	input := textinput.New()
+	input.Placeholder = "folder name"
+	input.CharLimit = 255
+	input.Width = 40
+
+	m := Model{list: list, height: defaultHeight, input: input}
+	return m.open("")
+}
+
+// Open makes dir the current folder, e.g. to return to a folder picked
+// earlier. dir does not have to exist yet; an invalid dir opens the root.
+func (m Model) Open(dir string) Model {
+	clean, err := fileops.SanitizeSubdirectory(dir)
+	if err != nil {
+		clean = ""
+	}
+	m.creating = false
+	m.input.Blur()
+	return m.open(clean)
+}
+
+// Update handles navigation, picking the current folder and naming a new one.
+// Check Chosen afterwards to see whether a folder was picked.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	m.chosen = false
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		if m.creating {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	if m.creating {
+		return m.updateCreating(key)
+	}
+
+	switch key.String() {
+	case "up", "k":
+		m = m.move(-1)
+	case "down", "j":
+		m = m.move(1)
+	case "enter", "right", "l":
+		return m.activate(key.String() == "enter"), nil
+	case "left", "h", "backspace":
+		if m.dir != "" {
+			m = m.open(parent(m.dir))
+		}
+	case "n":
+		m.creating = true
+		m.err = nil
+		m.input.SetValue("")
+		return m, m.input.Focus()
+	}
+	return m, nil
+}
+
+// updateCreating handles keys while a new folder is named
+func (m Model) updateCreating(key tea.KeyMsg) (Model, tea.Cmd) {
+	switch key.String() {
+	case "enter":
+		name := strings.TrimSpace(m.input.Value())
+		if name == "" {
+			m.err = fmt.Errorf("folder name cannot be empty")
+			return m, nil
+		}
+		dir, err := fileops.SanitizeSubdirectory(path.Join(m.dir, name))
+		if err != nil || (m.dir != "" && !strings.HasPrefix(dir, m.dir+"/")) || dir == m.dir {
+			m.err = fmt.Errorf("invalid folder name %q", name)
+			return m, nil
+		}
+		m.creating = false
+		m.input.Blur()
+		return m.open(dir), nil
+	case "esc":
+		m.creating = false
+		m.err = nil
+		m.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(key)
+	m.err = nil
+	return m, cmd
+}
+
+// activate picks the current folder on the first row, goes up on "..", and
+// opens the highlighted folder otherwise. Only Enter picks a folder.
+func (m Model) activate(pick bool) Model {
+	switch row := m.rows()[m.cursor]; {
+	case m.cursor == 0:
+		m.chosen = pick
+	case row == "..":
+		m = m.open(parent(m.dir))
+	default:
+		m = m.open(path.Join(m.dir, row))
+	}
+	return m
+}
+
+// open lists dir and makes it the current folder, highlighting the first row
+func (m Model) open(dir string) Model {
+	m.dir = dir
+	m.cursor = 0
+	m.offset = 0
+	m.entries, m.err = m.list(dir)
+	return m
+}
+
+// move moves the highlight by delta rows, scrolling to keep it visible
+func (m Model) move(delta int) Model {
+	m.cursor = max(0, min(len(m.rows())-1, m.cursor+delta))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+	return m
+}
+
+// rows returns the rows of the list: the pick row (""), ".." below the root,
+// then the folders
+func (m Model) rows() []string {
+	rows := []string{""}
+	if m.dir != "" {
+		rows = append(rows, "..")
+	}
+	return append(rows, m.entries...)
+}
+
+// parent returns the folder containing dir
+func parent(dir string) string {
+	if p := path.Dir(dir); p != "." {
+		return p
+	}
+	return ""
+}
+
+// Chosen reports whether the last Update picked the current folder
+func (m Model) Chosen() bool {
+	return m.chosen
+}
+
+// Dir returns the current folder relative to the root, "" for the root
+func (m Model) Dir() string {
+	return m.dir
+}
+
+// Creating reports whether a new folder is being named, so the owning model
+// can pass every key, including Esc and q, to the browser
+func (m Model) Creating() bool {
+	return m.creating
+}
+
+// SetHeight sets the number of rows shown before the list scrolls
+func (m Model) SetHeight(height int) Model {
+	m.height = max(1, height)
+	return m.move(0)
+}
+
+var (
+	locationStyle = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#5fd7ff"))
+	hintStyle     = lipgloss.NewStyle().Faint(true)
+)
+
+// Location returns the current folder for display, "/" for the root
+func (m Model) Location() string {
+	return "/" + m.dir
+}
+
+// View renders the current folder and its rows, or the new folder input
+func (m Model) View() string {
+	var b strings.Builder
+	b.WriteString(locationStyle.Render("Folder: " + m.Location()))
+	b.WriteString("\n\n")
+
+	if m.creating {
+		b.WriteString(fmt.Sprintf("New folder in %s:\n", m.Location()))
+		b.WriteString(m.input.View())
+		b.WriteString("\n")
+		b.WriteString(hintStyle.Render("  Enter to open it • Esc to cancel"))
+		b.WriteString("\n")
+	} else {
+		rows := m.rows()
+		end := min(len(rows), m.offset+m.height)
+		for i := m.offset; i < end; i++ {
+			label := "📁 " + rows[i] + "/"
+			switch {
+			case i == 0:
+				label = "✓ Use this folder"
+			case rows[i] == "..":
+				label = "↩ .."
+			}
+			if i == m.cursor {
+				b.WriteString(selectedStyle.Render("› " + label))
+			} else {
+				b.WriteString("  " + label)
+			}
+			b.WriteString("\n")
+		}
+		if len(m.entries) == 0 {
+			b.WriteString(hintStyle.Render("  No folders here yet - press n to create one"))
+			b.WriteString("\n")
+		}
+	}
+
+	if m.err != nil {
+		b.WriteString(styles.ErrorStyle.Render("  ✗ " + m.err.Error()))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package dirbrowser
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// testTree lists the folders of a small fixed tree
+func testTree(dir string) ([]string, error) {
+	tree := map[string][]string{
+		"":         {"go", "react"},
+		"go":       {"testing"},
+		"react":    nil,
+		"go/tests": nil,
+	}
+	return tree[dir], nil
+}
+
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func TestNavigation(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []string
+		wantDir string
+		chosen  bool
+	}{
+		{name: "starts at the root", wantDir: ""},
+		{name: "enter on the first row picks the root", keys: []string{"enter"}, wantDir: "", chosen: true},
+		{name: "enter opens a folder", keys: []string{"down", "enter"}, wantDir: "go"},
+		{name: "nested folder", keys: []string{"down", "enter", "down", "down", "enter"}, wantDir: "go/testing"},
+		{name: "dot dot goes up", keys: []string{"down", "enter", "down", "enter"}, wantDir: ""},
+		{name: "backspace goes up", keys: []string{"down", "down", "enter", "backspace"}, wantDir: ""},
+		{name: "pick a nested folder", keys: []string{"down", "enter", "enter"}, wantDir: "go", chosen: true},
+		{name: "right opens but never picks", keys: []string{"right"}, wantDir: ""},
+		{name: "cursor stops at the last row", keys: []string{"down", "down", "down", "enter"}, wantDir: "react"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(New(testTree), tt.keys...)
+			if m.Dir() != tt.wantDir {
+				t.Errorf("Dir() = %q, want %q", m.Dir(), tt.wantDir)
+			}
+			if m.Chosen() != tt.chosen {
+				t.Errorf("Chosen() = %v, want %v", m.Chosen(), tt.chosen)
+			}
+		})
+	}
+}
+
+func TestNewFolder(t *testing.T) {
+	m := press(New(testTree), "down", "enter", "n")
+	if !m.Creating() {
+		t.Fatal("n should start naming a new folder")
+	}
+
+	m = press(m, "e", "2", "e", "enter")
+	if m.Creating() || m.Dir() != "go/e2e" {
+		t.Fatalf("expected to open go/e2e, got %q (creating %v)", m.Dir(), m.Creating())
+	}
+	if view := m.View(); !strings.Contains(view, "No folders here yet") {
+		t.Errorf("a new folder should be empty, got:\n%s", view)
+	}
+
+	m = press(m, "enter")
+	if !m.Chosen() || m.Dir() != "go/e2e" {
+		t.Errorf("expected go/e2e to be picked, got %q (chosen %v)", m.Dir(), m.Chosen())
+	}
+}
+
+func TestNewFolder_Invalid(t *testing.T) {
+	for _, name := range []string{"..", ".", ".git"} {
+		t.Run(name, func(t *testing.T) {
+			m := press(New(testTree), "n")
+			m = press(m, strings.Split(name, "")...)
+			m = press(m, "enter")
+			if !m.Creating() || m.Dir() != "" {
+				t.Errorf("%q should be rejected, got dir %q", name, m.Dir())
+			}
+			if view := m.View(); !strings.Contains(view, "invalid folder name") {
+				t.Errorf("expected an inline error, got:\n%s", view)
+			}
+		})
+	}
+
+	m := press(New(testTree), "n", "enter")
+	if !strings.Contains(m.View(), "cannot be empty") {
+		t.Error("an empty name should be rejected")
+	}
+
+	m = press(m, "esc")
+	if m.Creating() || m.Dir() != "" {
+		t.Error("esc should cancel naming the folder")
+	}
+}
+
+func TestScrolling(t *testing.T) {
+	list := func(dir string) ([]string, error) {
+		return []string{"a", "b", "c", "d", "e"}, nil
+	}
+	m := New(list).SetHeight(3)
+	m = press(m, "down", "down", "down", "down")
+
+	view := m.View()
+	if strings.Contains(view, "Use this folder") || !strings.Contains(view, "📁 d/") {
+		t.Errorf("expected the list to scroll to the cursor, got:\n%s", view)
+	}
+}
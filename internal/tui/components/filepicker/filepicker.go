@@ -6,10 +6,16 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"rulem/internal/config"
 	filemanager "rulem/internal/filemanager"
+	"rulem/internal/frontmatter"
 	"rulem/internal/logging"
+	"rulem/internal/mcp"
+	"rulem/internal/notes"
+	"rulem/internal/repository"
 	"rulem/internal/tui/helpers"
 	"rulem/internal/tui/styles"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -101,6 +107,13 @@ type FilePicker struct {
 	useGlamour         bool
 	glamourStyle       string
 
+	// processor reads the frontmatter shown in the list and above the
+	// preview; nil when it could not be created
+	processor *mcp.RuleFileProcessor
+
+	// notes holds the user's private notes on the files, by path
+	notes map[string]notes.Note
+
 	// focus management
 	focusPane focusedPane
 }
@@ -255,9 +268,24 @@ func fileListDelegate(files []filemanager.FileItem) list.DefaultDelegate {
 }
 
 func NewFilePicker(title, subtitle string, files []filemanager.FileItem, ctx helpers.UIContext) FilePicker {
-	// convert files to list Items
+	userNotes := fileNotes(files, ctx)
+
+	// Honour the configured frontmatter delimiters, falling back to the defaults
+	cfg := ctx.Config
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	processor, err := mcp.NewRuleFileProcessorForRepositories(cfg, nil, ctx.Logger)
+	if err != nil {
+		ctx.Logger.Warn("Rule metadata will not be shown in previews", "error", err)
+	}
+
+	// convert files to list Items, showing the user's ratings and which rules
+	// are deprecated
 	items := make([]list.Item, len(files))
 	for i, f := range files {
+		f.Rating = userNotes[f.Path].Rating
+		f.Metadata = fileMetadata(processor, f)
 		items[i] = f
 	}
 
@@ -296,6 +324,8 @@ func NewFilePicker(title, subtitle string, files []filemanager.FileItem, ctx hel
 		maxPreviewBytes:      2 * 1024, // 2KB
 		useGlamour:           true,
 		focusPane:            focusList,
+		notes:                userNotes,
+		processor:            processor,
 	}
 
 	// Size the panes immediately: the picker is usually created after program
@@ -831,6 +861,7 @@ func (fp *FilePicker) renderFileContent(path string, full bool, glamourOn bool)
 		if truncated {
 			header = fmt.Sprintf("[Preview truncated to %s of %s. Press 'f' to load full.]\n\n", humanSize(int64(n)), humanSize(fi.Size()))
 		}
+		banner := fp.noteLine(path) + fp.metadataLine(path, content)
 
 		var renderedContent string
 		if glamourOn {
@@ -848,12 +879,12 @@ func (fp *FilePicker) renderFileContent(path string, full bool, glamourOn bool)
 				fp.logger.Error("Failed to render content with glamour", "error", err, "renderID", renderID)
 				return FileReadErrorMsg{err: err, path: path, renderID: renderID}
 			}
-			renderedContent = header + rc + header
+			renderedContent = banner + header + rc + header
 		} else {
 			// Plain text without markdown rendering. Wrap to the viewport
 			// width — the viewport truncates long lines instead of wrapping,
 			// which would silently hide content.
-			renderedContent = header + wordwrap.String(string(content), vpWidth) + header
+			renderedContent = banner + header + wordwrap.String(string(content), vpWidth) + header
 		}
 
 		fp.logger.Debug("File rendered successfully", "path", path, "renderID", renderID, "content_length", len(renderedContent), "truncated", truncated, "glamour", glamourOn)
@@ -861,6 +892,98 @@ func (fp *FilePicker) renderFileContent(path string, full bool, glamourOn bool)
 	}
 }
 
+// fileNotes looks up the user's notes on files. Files outside the configured
+// repositories are looked up by absolute path.
+func fileNotes(files []filemanager.FileItem, ctx helpers.UIContext) map[string]notes.Note {
+	result := make(map[string]notes.Note)
+	if ctx.Notes == nil {
+		return result
+	}
+	var repos []repository.RepositoryEntry
+	if ctx.Config != nil {
+		repos = ctx.Config.Repositories
+	}
+	for _, f := range files {
+		if note, ok := ctx.Notes.Get(notes.Key(repos, f.RepositoryID, f.Path)); ok {
+			result[f.Path] = note
+		}
+	}
+	return result
+}
+
+// metadataReadLimit is how much of a file is read for the frontmatter shown
+// in the list; frontmatter sits at the top of a rule
+const metadataReadLimit = 64 << 10
+
+// fileMetadata reads the frontmatter of a file for the list, or returns nil
+// when there is no processor or the file or its frontmatter cannot be read
+func fileMetadata(processor *mcp.RuleFileProcessor, file filemanager.FileItem) *frontmatter.Metadata {
+	if processor == nil {
+		return nil
+	}
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	content, err := io.ReadAll(io.LimitReader(f, metadataReadLimit))
+	if err != nil {
+		return nil
+	}
+	matter, err := processor.Frontmatter(content, file.Name)
+	if err != nil {
+		return nil
+	}
+	return matter
+}
+
+// noteLine shows the user's rating and note on the file at path for the top
+// of the preview, or returns "" when there is neither
+func (fp *FilePicker) noteLine(path string) string {
+	note, ok := fp.notes[path]
+	if !ok {
+		return ""
+	}
+	var parts []string
+	if note.Rating > 0 {
+		parts = append(parts, "Rating: "+notes.Stars(note.Rating))
+	}
+	if note.Text != "" {
+		parts = append(parts, "Note: "+note.Text)
+	}
+	return fmt.Sprintf("[%s]\n\n", strings.Join(parts, " • "))
+}
+
+// metadataLine describes the deprecation, version, license and attribution
+// set in the frontmatter of the file at path for the top of the preview, or
+// returns "" when none is set
+func (fp *FilePicker) metadataLine(path string, content []byte) string {
+	if fp.processor == nil {
+		return ""
+	}
+	matter, err := fp.processor.Frontmatter(content, filepath.Base(path))
+	if err != nil {
+		return ""
+	}
+	var parts []string
+	if matter.Deprecated {
+		parts = append(parts, "Deprecated")
+	}
+	if matter.Version != "" {
+		parts = append(parts, "Version: "+matter.Version)
+	}
+	if matter.License != "" {
+		parts = append(parts, "License: "+matter.License)
+	}
+	if matter.Attribution != "" {
+		parts = append(parts, "Attribution: "+matter.Attribution)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[%s]\n\n", strings.Join(parts, " • "))
+}
+
 // humanSize renders a byte count for the preview banner.
 func humanSize(n int64) string {
 	const kib = 1024
@@ -8,8 +8,11 @@ import (
 	"testing"
 	"time"
 
+	"rulem/internal/config"
 	"rulem/internal/filemanager"
 	"rulem/internal/logging"
+	"rulem/internal/notes"
+	"rulem/internal/repository"
 	"rulem/internal/tui/helpers"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -49,6 +52,96 @@ func TestFilePicker_InitialView(t *testing.T) {
 	}
 }
 
+func TestFilePicker_PreviewShowsLicense(t *testing.T) {
+	tmpDir := t.TempDir()
+	rule := filepath.Join(tmpDir, "rule.md")
+	content := "---\ndescription: Style\nlicense: MIT\nattribution: Jane Doe\n---\n# Style\n"
+	if err := os.WriteFile(rule, []byte(content), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	files := []filemanager.FileItem{{Name: "rule.md", Path: rule}}
+	logger, _ := logging.NewTestLogger()
+	fp := NewFilePicker("Test", "", files, helpers.UIContext{Width: 100, Height: 30, Logger: logger})
+	fp.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	fp.fileList.Select(0)
+	runCmd(&fp, fp.renderFileContent(rule, false, false))
+
+	if out := fp.View(); !strings.Contains(out, "[License: MIT • Attribution: Jane Doe]") {
+		t.Fatalf("expected license and attribution in preview, got:\n%s", out)
+	}
+}
+
+func TestFilePicker_ShowsDeprecatedRules(t *testing.T) {
+	tmpDir := t.TempDir()
+	rule := filepath.Join(tmpDir, "old.md")
+	content := "---\ndescription: Old style\nversion: 1.2.0\ndeprecated: true\ntags: [legacy]\n---\n# Old style\n"
+	if err := os.WriteFile(rule, []byte(content), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	files := []filemanager.FileItem{{Name: "old.md", Path: rule}}
+	logger, _ := logging.NewTestLogger()
+	fp := NewFilePicker("Test", "", files, helpers.UIContext{Width: 100, Height: 30, Logger: logger})
+	fp.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	item := fp.fileList.Items()[0].(filemanager.FileItem)
+	if got := item.Title(); got != "old.md (deprecated)" {
+		t.Errorf("title = %q, want the rule marked deprecated", got)
+	}
+	if !strings.Contains(item.FilterValue(), "legacy") {
+		t.Errorf("filter value = %q, want the rule's tags", item.FilterValue())
+	}
+
+	fp.fileList.Select(0)
+	runCmd(&fp, fp.renderFileContent(rule, false, false))
+	if out := fp.View(); !strings.Contains(out, "[Deprecated • Version: 1.2.0]") {
+		t.Fatalf("expected deprecation and version in preview, got:\n%s", out)
+	}
+}
+
+func TestFilePicker_ShowsNotes(t *testing.T) {
+	repoDir := t.TempDir()
+	rated := filepath.Join(repoDir, "rated.md")
+	plain := filepath.Join(repoDir, "plain.md")
+	for _, path := range []string{rated, plain} {
+		if err := os.WriteFile(path, []byte("# Rule\n"), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	store, err := notes.Load(filepath.Join(t.TempDir(), "notes.yaml"))
+	if err != nil {
+		t.Fatalf("notes.Load: %v", err)
+	}
+	if err := store.Set("rules-1:rated.md", notes.Note{Rating: 4, Text: "Keeps reviews short"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	files := []filemanager.FileItem{
+		{Name: "rated.md", Path: rated, RepositoryID: "rules-1"},
+		{Name: "plain.md", Path: plain, RepositoryID: "rules-1"},
+	}
+	logger, _ := logging.NewTestLogger()
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{{ID: "rules-1", Path: repoDir}}}
+	ctx := helpers.UIContext{Width: 100, Height: 30, Logger: logger, Config: cfg, Notes: store}
+	fp := NewFilePicker("Test", "", files, ctx)
+	fp.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	if got := fp.fileList.Items()[0].(filemanager.FileItem).Title(); got != "rated.md ★★★★" {
+		t.Errorf("rated item title = %q", got)
+	}
+	if got := fp.fileList.Items()[1].(filemanager.FileItem).Title(); got != "plain.md" {
+		t.Errorf("unrated item title = %q", got)
+	}
+
+	fp.fileList.Select(0)
+	runCmd(&fp, fp.renderFileContent(rated, false, false))
+	if out := fp.View(); !strings.Contains(out, "[Rating: ★★★★☆ • Note: Keeps reviews short]") {
+		t.Fatalf("expected rating and note in preview, got:\n%s", out)
+	}
+}
+
 func TestFilePickerBaseFlow(t *testing.T) {
 	tmpDir := t.TempDir()
 	smallFile := filepath.Join(tmpDir, "small.md")
@@ -0,0 +1,294 @@
+// Package form provides a multi-field input form for the rulem TUI.
+//
+// A form shows several labelled text inputs on one screen. The user moves
+// between fields with Tab/Shift+Tab or ↑/↓, and Enter advances to the next
+// field or submits on the last one. Each field validates when the user leaves
+// it, and every field is validated again on submit; errors are shown inline
+// under the offending field and focus moves to the first invalid one.
+//
+// The form does not handle Esc: the owning model decides what cancelling means
+// (usually returning to the previous screen).
+//
+// Typical usage:
+//
+//	f := form.New(
+//	    form.Field{Key: "url", Label: "GitHub URL", Validate: validateURL},
+//	    form.Field{Key: "branch", Label: "Branch", Optional: true},
+//	)
+//	f, cmd = f.Update(msg)
+//	if f.Submitted() {
+//	    url := f.Value("url")
+//	}
+package form
+
+import (
+	"errors"
+	"strings"
+
+	"rulem/internal/tui/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Field describes a single input of the form
+type Field struct {
+	Key         string // Identifier used with Value and SetError
+	Label       string
+	Placeholder string
+	Hint        string // Faint help line shown under the input
+
+	// Optional fields may be left empty. Required fields get a default
+	// "<Label> cannot be empty" error.
+	Optional bool
+
+	// PlaceholderIsDefault makes an empty value fall back to the placeholder,
+	// e.g. a suggested clone path the user can accept with Enter.
+	PlaceholderIsDefault bool
+
+	Password  bool
+	CharLimit int // Defaults to 256
+
+	// Validate checks the trimmed value. It is not called for an empty
+	// optional field.
+	Validate func(value string) error
+}
+
+// Model is the Bubble Tea model for a form
+type Model struct {
+	fields    []Field
+	inputs    []textinput.Model
+	errs      []error
+	focus     int
+	submitted bool
+}
+
+// New creates a form with the first field focused
+func New(fields ...Field) Model {
+	m := Model{
+		fields: fields,
+		inputs: make([]textinput.Model, len(fields)),
+		errs:   make([]error, len(fields)),
+	}
+	for i, f := range fields {
+		ti := textinput.New()
+		ti.Placeholder = f.Placeholder
+		ti.CharLimit = f.CharLimit
+		if ti.CharLimit == 0 {
+			ti.CharLimit = 256
+		}
+		if f.Password {
+			ti.EchoMode = textinput.EchoPassword
+		}
+		m.inputs[i] = ti
+	}
+	if len(m.inputs) > 0 {
+		m.inputs[0].Focus()
+	}
+	return m
+}
+
+// Update handles navigation, submission and typing into the focused field.
+// Check Submitted afterwards to see whether the form was completed.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	m.submitted = false
+	if len(m.inputs) == 0 {
+		return m, nil
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+		return m, cmd
+	}
+
+	switch key.String() {
+	case "tab", "down":
+		m.errs[m.focus] = m.validate(m.focus)
+		return m.setFocus(m.focus + 1), nil
+	case "shift+tab", "up":
+		m.errs[m.focus] = m.validate(m.focus)
+		return m.setFocus(m.focus - 1), nil
+	case "enter":
+		if m.errs[m.focus] = m.validate(m.focus); m.errs[m.focus] != nil {
+			return m, nil
+		}
+		if m.focus < len(m.inputs)-1 {
+			return m.setFocus(m.focus + 1), nil
+		}
+		return m.submit(), nil
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	// Editing a field clears its error until it is validated again
+	m.errs[m.focus] = nil
+	return m, cmd
+}
+
+// submit validates every field and either marks the form submitted or focuses
+// the first invalid field
+func (m Model) submit() Model {
+	first := -1
+	for i := range m.fields {
+		m.errs[i] = m.validate(i)
+		if m.errs[i] != nil && first == -1 {
+			first = i
+		}
+	}
+	if first != -1 {
+		return m.setFocus(first)
+	}
+	m.submitted = true
+	return m
+}
+
+func (m Model) validate(i int) error {
+	f := m.fields[i]
+	value := m.value(i)
+	if value == "" {
+		if f.Optional {
+			return nil
+		}
+		return errors.New(f.Label + " cannot be empty")
+	}
+	if f.Validate != nil {
+		return f.Validate(value)
+	}
+	return nil
+}
+
+func (m Model) setFocus(i int) Model {
+	if i < 0 || i >= len(m.inputs) {
+		return m
+	}
+	m.inputs[m.focus].Blur()
+	m.focus = i
+	m.inputs[m.focus].Focus()
+	return m
+}
+
+func (m Model) value(i int) string {
+	value := strings.TrimSpace(m.inputs[i].Value())
+	if value == "" && m.fields[i].PlaceholderIsDefault {
+		return m.inputs[i].Placeholder
+	}
+	return value
+}
+
+func (m Model) index(key string) int {
+	for i, f := range m.fields {
+		if f.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Submitted reports whether the last Update completed the form with every
+// field valid
+func (m Model) Submitted() bool {
+	return m.submitted
+}
+
+// Value returns the trimmed value of a field, falling back to its placeholder
+// when PlaceholderIsDefault is set. Unknown keys return "".
+func (m Model) Value(key string) string {
+	if i := m.index(key); i != -1 {
+		return m.value(i)
+	}
+	return ""
+}
+
+// SetValue replaces a field's value
+func (m Model) SetValue(key, value string) Model {
+	if i := m.index(key); i != -1 {
+		m.inputs[i].SetValue(value)
+		m.errs[i] = nil
+	}
+	return m
+}
+
+// SetPlaceholder replaces a field's placeholder, e.g. to suggest a default
+// derived from another field
+func (m Model) SetPlaceholder(key, placeholder string) Model {
+	if i := m.index(key); i != -1 {
+		m.inputs[i].Placeholder = placeholder
+	}
+	return m
+}
+
+// SetError attaches an error found outside the form (e.g. by an asynchronous
+// check) to a field and focuses it
+func (m Model) SetError(key string, err error) Model {
+	if i := m.index(key); i != -1 {
+		m.errs[i] = err
+		m = m.setFocus(i)
+	}
+	return m
+}
+
+// Error returns the current inline error of a field, if any
+func (m Model) Error(key string) error {
+	if i := m.index(key); i != -1 {
+		return m.errs[i]
+	}
+	return nil
+}
+
+// Focused returns the key of the focused field
+func (m Model) Focused() string {
+	if len(m.fields) == 0 {
+		return ""
+	}
+	return m.fields[m.focus].Key
+}
+
+// SetWidth sets the width of every input
+func (m Model) SetWidth(width int) Model {
+	for i := range m.inputs {
+		m.inputs[i].Width = width
+	}
+	return m
+}
+
+var (
+	labelStyle        = lipgloss.NewStyle()
+	focusedLabelStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#5fd7ff"))
+	hintStyle         = lipgloss.NewStyle().Faint(true)
+)
+
+// View renders every field with its label, input, hint and inline error
+func (m Model) View() string {
+	var b strings.Builder
+	for i, f := range m.fields {
+		label := f.Label
+		if f.Optional {
+			label += " (optional)"
+		}
+		if i == m.focus {
+			b.WriteString(focusedLabelStyle.Render("› " + label))
+		} else {
+			b.WriteString(labelStyle.Render("  " + label))
+		}
+		b.WriteString("\n")
+		b.WriteString(m.inputs[i].View())
+		b.WriteString("\n")
+
+		switch {
+		case m.errs[i] != nil:
+			b.WriteString(styles.ErrorStyle.Render("  ✗ " + m.errs[i].Error()))
+			b.WriteString("\n")
+		case f.Hint != "" && i == m.focus:
+			b.WriteString(hintStyle.Render("  " + f.Hint))
+			b.WriteString("\n")
+		}
+
+		if i < len(m.fields)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
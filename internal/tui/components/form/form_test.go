@@ -0,0 +1,167 @@
+package form
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newTestForm() Model {
+	return New(
+		Field{Key: "name", Label: "Name"},
+		Field{Key: "branch", Label: "Branch", Optional: true, Validate: func(v string) error {
+			if strings.Contains(v, " ") {
+				return errors.New("branch cannot contain spaces")
+			}
+			return nil
+		}},
+		Field{Key: "path", Label: "Path", Placeholder: "/default/path", PlaceholderIsDefault: true},
+	)
+}
+
+func typeText(m Model, text string) Model {
+	for _, r := range text {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func press(m Model, keys ...tea.KeyType) Model {
+	for _, k := range keys {
+		m, _ = m.Update(tea.KeyMsg{Type: k})
+	}
+	return m
+}
+
+func TestFormNavigation(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []tea.KeyType
+		wantFocus string
+	}{
+		{name: "starts on first field", wantFocus: "name"},
+		{name: "tab moves forward", keys: []tea.KeyType{tea.KeyTab}, wantFocus: "branch"},
+		{name: "down moves forward", keys: []tea.KeyType{tea.KeyDown, tea.KeyDown}, wantFocus: "path"},
+		{name: "shift+tab moves back", keys: []tea.KeyType{tea.KeyTab, tea.KeyShiftTab}, wantFocus: "name"},
+		{name: "stops at last field", keys: []tea.KeyType{tea.KeyTab, tea.KeyTab, tea.KeyTab}, wantFocus: "path"},
+		{name: "stops at first field", keys: []tea.KeyType{tea.KeyUp}, wantFocus: "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(newTestForm(), tt.keys...)
+			if got := m.Focused(); got != tt.wantFocus {
+				t.Errorf("Focused() = %q, want %q", got, tt.wantFocus)
+			}
+		})
+	}
+}
+
+func TestFormInlineValidation(t *testing.T) {
+	m := press(newTestForm(), tea.KeyEnter)
+	if m.Focused() != "name" {
+		t.Errorf("Enter on an invalid field should not advance, focus %q", m.Focused())
+	}
+	if err := m.Error("name"); err == nil || err.Error() != "Name cannot be empty" {
+		t.Errorf("Error(name) = %v", err)
+	}
+	if !strings.Contains(m.View(), "✗ Name cannot be empty") {
+		t.Error("View should show the inline error")
+	}
+
+	m = typeText(m, "rules")
+	if m.Error("name") != nil {
+		t.Error("typing should clear the field error")
+	}
+
+	m = press(m, tea.KeyEnter)
+	m = typeText(m, "bad branch")
+	m = press(m, tea.KeyTab)
+	if m.Error("branch") == nil {
+		t.Error("leaving an invalid field should record its error")
+	}
+	if m.Focused() != "path" {
+		t.Errorf("tab should still move focus, got %q", m.Focused())
+	}
+}
+
+func TestFormSubmit(t *testing.T) {
+	t.Run("submits with optional and default values", func(t *testing.T) {
+		m := typeText(newTestForm(), "  rules  ")
+		m = press(m, tea.KeyEnter, tea.KeyEnter, tea.KeyEnter)
+
+		if !m.Submitted() {
+			t.Fatal("expected form to be submitted")
+		}
+		if got := m.Value("name"); got != "rules" {
+			t.Errorf("Value(name) = %q, want trimmed value", got)
+		}
+		if got := m.Value("branch"); got != "" {
+			t.Errorf("Value(branch) = %q, want empty", got)
+		}
+		if got := m.Value("path"); got != "/default/path" {
+			t.Errorf("Value(path) = %q, want placeholder default", got)
+		}
+	})
+
+	t.Run("focuses first invalid field", func(t *testing.T) {
+		m := press(newTestForm(), tea.KeyTab, tea.KeyTab, tea.KeyEnter)
+
+		if m.Submitted() {
+			t.Error("form with an empty required field should not submit")
+		}
+		if m.Focused() != "name" {
+			t.Errorf("expected focus on first invalid field, got %q", m.Focused())
+		}
+	})
+
+	t.Run("submitted is reset on next update", func(t *testing.T) {
+		m := typeText(newTestForm(), "rules")
+		m = press(m, tea.KeyEnter, tea.KeyEnter, tea.KeyEnter)
+		m = typeText(m, "x")
+		if m.Submitted() {
+			t.Error("Submitted should only report the update that completed the form")
+		}
+	})
+}
+
+func TestFormSetError(t *testing.T) {
+	m := newTestForm().SetValue("name", "rules").SetError("branch", errors.New("branch not found"))
+
+	if m.Focused() != "branch" {
+		t.Errorf("SetError should focus the field, got %q", m.Focused())
+	}
+	if m.Error("branch") == nil {
+		t.Error("expected branch error")
+	}
+	if got := m.Value("name"); got != "rules" {
+		t.Errorf("Value(name) = %q", got)
+	}
+	if m.Value("unknown") != "" || m.Error("unknown") != nil {
+		t.Error("unknown keys should be ignored")
+	}
+}
+
+func TestFormView(t *testing.T) {
+	m := New(
+		Field{Key: "url", Label: "URL", Hint: "HTTPS or SSH"},
+		Field{Key: "token", Label: "Token", Password: true, Optional: true},
+	)
+	m = press(m, tea.KeyTab)
+	m = typeText(m, "secret")
+
+	view := m.View()
+	for _, want := range []string{"  URL", "› Token (optional)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "HTTPS or SSH") {
+		t.Error("hint should only be shown for the focused field")
+	}
+	if strings.Contains(view, "secret") {
+		t.Error("password field should be masked")
+	}
+}
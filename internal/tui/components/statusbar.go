@@ -0,0 +1,107 @@
+package components
+
+import (
+	"strings"
+
+	"rulem/internal/tui/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// StatusInfo is the context shown in the status bar on every screen
+type StatusInfo struct {
+	Repository string // Active repository name, or a summary when several are configured
+	Sync       string // Short sync summary, e.g. "in sync" or "1 clone missing"
+	Dirty      bool   // At least one repository has uncommitted local changes
+}
+
+// StatusUpdateMsg replaces the status bar context. Any model can return it from
+// a command; MainModel applies it so all screens share the same context.
+type StatusUpdateMsg struct {
+	Info StatusInfo
+}
+
+// StatusBarModel renders a single-line bar pinned below the active screen
+type StatusBarModel struct {
+	info        StatusInfo
+	breadcrumbs string
+	warning     string
+	hints       string
+	width       int
+}
+
+func NewStatusBar() StatusBarModel {
+	return StatusBarModel{}
+}
+
+func (m StatusBarModel) Update(msg tea.Msg) StatusBarModel {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case StatusUpdateMsg:
+		m.info = msg.Info
+	}
+	return m
+}
+
+// SetHints sets the key hints shown on the right of the bar
+func (m StatusBarModel) SetHints(hints string) StatusBarModel {
+	m.hints = hints
+	return m
+}
+
+// SetBreadcrumbs sets where the user is, e.g. "Settings › Repository actions",
+// shown before the repository context; pass "" on the menu
+func (m StatusBarModel) SetBreadcrumbs(breadcrumbs string) StatusBarModel {
+	m.breadcrumbs = breadcrumbs
+	return m
+}
+
+// SetWarning sets an application-wide warning, such as an unavailable
+// credential store, shown after the repository context. Unlike StatusInfo it
+// is not replaced by StatusUpdateMsg; pass "" to clear it.
+func (m StatusBarModel) SetWarning(warning string) StatusBarModel {
+	m.warning = warning
+	return m
+}
+
+func (m StatusBarModel) Info() StatusInfo {
+	return m.info
+}
+
+// View renders the bar to the terminal width. The context segments on the left
+// take priority; the key hints are dropped when there is not enough room.
+func (m StatusBarModel) View() string {
+	var segments []string
+	if m.breadcrumbs != "" {
+		segments = append(segments, m.breadcrumbs)
+	}
+	if m.info.Repository != "" {
+		segments = append(segments, "📚 "+m.info.Repository)
+	}
+	if m.info.Sync != "" {
+		segments = append(segments, m.info.Sync)
+	}
+	if m.info.Dirty {
+		segments = append(segments, styles.StatusBarDirtyStyle.Render("● local changes"))
+	}
+	if m.warning != "" {
+		segments = append(segments, styles.StatusBarDirtyStyle.Render(m.warning))
+	}
+	left := strings.Join(segments, " │ ")
+
+	width := m.width
+	if width <= 0 {
+		return styles.StatusBarStyle.Render(left)
+	}
+
+	// Account for the bar's horizontal padding
+	inner := width - styles.StatusBarStyle.GetHorizontalPadding()
+	gap := inner - lipgloss.Width(left) - lipgloss.Width(m.hints)
+	line := left
+	if m.hints != "" && gap >= 2 {
+		line = left + strings.Repeat(" ", gap) + m.hints
+	}
+	return styles.StatusBarStyle.Width(width).MaxWidth(width).Render(line)
+}
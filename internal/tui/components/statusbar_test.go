@@ -0,0 +1,76 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestStatusBarView(t *testing.T) {
+	bar := NewStatusBar().
+		Update(tea.WindowSizeMsg{Width: 80, Height: 24}).
+		Update(StatusUpdateMsg{Info: StatusInfo{Repository: "Team Rules", Sync: "in sync"}}).
+		SetHints("esc back")
+
+	view := bar.View()
+	if lipgloss.Width(view) != 80 {
+		t.Errorf("Expected bar to span 80 cells, got %d", lipgloss.Width(view))
+	}
+	for _, want := range []string{"Team Rules", "in sync", "esc back"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View %q missing %q", view, want)
+		}
+	}
+	if strings.Contains(view, "local changes") {
+		t.Error("Dirty indicator shown for a clean status")
+	}
+}
+
+func TestStatusBarDropsHintsWhenNarrow(t *testing.T) {
+	bar := NewStatusBar().
+		Update(tea.WindowSizeMsg{Width: 30, Height: 24}).
+		Update(StatusUpdateMsg{Info: StatusInfo{Repository: "Team Rules", Dirty: true}}).
+		SetHints("esc back • ctrl+c quit")
+
+	view := bar.View()
+	if strings.Contains(view, "ctrl+c") {
+		t.Errorf("Hints should be dropped when they do not fit: %q", view)
+	}
+	if !strings.Contains(view, "Team Rules") {
+		t.Errorf("Context should be kept: %q", view)
+	}
+	if lipgloss.Width(view) > 30 {
+		t.Errorf("Bar exceeds terminal width: %d", lipgloss.Width(view))
+	}
+}
+
+func TestStatusBarWarningSurvivesStatusUpdates(t *testing.T) {
+	bar := NewStatusBar().
+		Update(tea.WindowSizeMsg{Width: 80, Height: 24}).
+		SetWarning("keyring unavailable").
+		Update(StatusUpdateMsg{Info: StatusInfo{Repository: "Team Rules"}})
+
+	if view := bar.View(); !strings.Contains(view, "keyring unavailable") {
+		t.Errorf("Warning should be kept across status updates: %q", view)
+	}
+	if view := bar.SetWarning("").View(); strings.Contains(view, "keyring") {
+		t.Errorf("Warning should be cleared: %q", view)
+	}
+}
+
+func TestStatusBarBreadcrumbs(t *testing.T) {
+	bar := NewStatusBar().
+		Update(tea.WindowSizeMsg{Width: 80, Height: 24}).
+		Update(StatusUpdateMsg{Info: StatusInfo{Repository: "Team Rules"}}).
+		SetBreadcrumbs("Settings › Repository actions")
+
+	view := bar.View()
+	if !strings.Contains(view, "Settings › Repository actions │ 📚 Team Rules") {
+		t.Errorf("Breadcrumbs should lead the bar: %q", view)
+	}
+	if view := bar.SetBreadcrumbs("").View(); strings.Contains(view, "Settings") {
+		t.Errorf("Breadcrumbs should be cleared: %q", view)
+	}
+}
@@ -0,0 +1,509 @@
+// Package deploymenu implements the "Deploy rules" screen.
+//
+// It lists the rule files of every available repository, lets the user tick
+// any number of them and pick the editor they are for, and copies or links
+// them into the current project in one go with the deploy package. Either all
+// ticked rules are copied or none is, and every deployed rule is recorded in
+// the project manifest. The import screen deploys a single rule with more
+// guidance; this screen is for setting up a project quickly.
+package deploymenu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/config"
+	"rulem/internal/deploy"
+	"rulem/internal/editors"
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/project"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/styles"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type menuState int
+
+const (
+	stateLoading menuState = iota
+	stateList              // Ticking the rules to deploy
+	stateEditor            // Choosing the editor the rules are for
+	stateConfirm           // Confirming the destinations
+)
+
+type (
+	// loadedMsg carries the prepared repositories and their rule files
+	loadedMsg struct {
+		prepared []repository.PreparedRepository
+		files    []filemanager.FileItem
+		err      error
+	}
+
+	// deployedMsg reports the outcome of a deploy
+	deployedMsg struct {
+		paths []string
+		err   error
+	}
+)
+
+// ruleItem is a rule file listed by its path relative to its repository, with
+// a checkbox showing whether it is ticked
+type ruleItem struct {
+	file     filemanager.FileItem
+	rel      string
+	selected map[string]bool // Shared with the model, keyed by file path
+}
+
+func (i ruleItem) Title() string {
+	if i.selected[i.file.Path] {
+		return "[x] " + i.rel
+	}
+	return "[ ] " + i.rel
+}
+func (i ruleItem) Description() string { return i.file.Description() }
+func (i ruleItem) FilterValue() string { return i.rel + " " + i.file.RepositoryName }
+
+// DeployModel is the Bubble Tea model for the deploy rules screen.
+type DeployModel struct {
+	logger  *logging.AppLogger
+	layout  components.LayoutModel
+	spinner spinner.Model
+	rules   list.Model
+	editors list.Model
+	cfg     *config.Config
+	cache   *helpers.ScreenCache // nil caches nothing
+
+	state    menuState
+	prepared []repository.PreparedRepository
+	selected map[string]bool // Ticked rules by file path
+	link     bool            // Link the rules instead of copying them
+	editor   editors.EditorRuleConfig
+	targets  []deploy.Target // Planned deploy, shown for confirmation
+	existing map[string]bool // Destinations of targets that already exist
+	status   string          // Outcome of the last deploy
+}
+
+// NewDeployModel creates the deploy rules screen model from the shared UI context.
+func NewDeployModel(ctx helpers.UIContext) *DeployModel {
+	layout := components.NewLayout(components.LayoutConfig{
+		MarginX:  2,
+		MarginY:  1,
+		MaxWidth: 100,
+	})
+	if ctx.HasValidDimensions() {
+		layout, _ = layout.Update(tea.WindowSizeMsg{Width: ctx.Width, Height: ctx.Height})
+	}
+
+	s := spinner.New()
+	s.Style = styles.SpinnerStyle
+	s.Spinner = spinner.Pulse
+
+	rules := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	rules.SetShowTitle(false)
+	rules.SetShowStatusBar(false)
+	rules.SetFilteringEnabled(true)
+	rules.SetShowHelp(false) // We'll use the layout for help
+
+	// An invalid deploy_targets leaves the built-in editors
+	editorConfigs, err := deploy.Profiles(ctx.Config)
+	if err != nil {
+		ctx.Logger.Warn("Ignoring configured deploy targets", "error", err)
+		editorConfigs = editors.GetAllEditorRuleConfigs()
+	}
+	editorItems := make([]list.Item, len(editorConfigs))
+	for i, editor := range editorConfigs {
+		editorItems[i] = editor
+	}
+	editorList := list.New(editorItems, list.NewDefaultDelegate(), 0, 0)
+	editorList.SetShowTitle(false)
+	editorList.SetShowStatusBar(false)
+	editorList.SetFilteringEnabled(true)
+	editorList.SetShowHelp(false)
+
+	m := &DeployModel{
+		logger:   ctx.Logger,
+		layout:   layout,
+		spinner:  s,
+		rules:    rules,
+		editors:  editorList,
+		cfg:      ctx.Config,
+		cache:    ctx.Cache,
+		state:    stateLoading,
+		selected: make(map[string]bool),
+	}
+	m.resizeLists()
+	return m
+}
+
+// Init prepares the repositories and scans them for rules.
+func (m *DeployModel) Init() tea.Cmd {
+	return tea.Batch(m.loadCmd(), m.spinner.Tick)
+}
+
+// Update handles loaded rules, finished deploys and key presses.
+func (m *DeployModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m.layout, _ = m.layout.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resizeLists()
+		return m, nil
+
+	case loadedMsg:
+		m.state = stateList
+		if msg.err != nil {
+			m.logger.Error("Failed to load rules", "error", msg.err)
+			m.layout = m.layout.SetError(msg.err)
+			return m, nil
+		}
+		m.prepared = msg.prepared
+		return m, m.rules.SetItems(m.ruleItems(msg.files))
+
+	case deployedMsg:
+		m.state = stateList
+		if msg.err != nil {
+			m.logger.Warn("Deploy failed", "error", msg.err)
+			m.status = ""
+			m.layout = m.layout.SetError(msg.err)
+			return m, nil
+		}
+		m.layout = m.layout.ClearError()
+		m.status = fmt.Sprintf("Deployed %d rule(s) to %s", len(msg.paths), m.editor.Name)
+		clear(m.selected)
+		// The deployed rules are not in the cached scan of the project yet
+		m.cache.InvalidateFiles(helpers.ScanWorkingDir)
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state == stateLoading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.state {
+		case stateList:
+			return m.updateList(msg)
+		case stateEditor:
+			return m.updateEditor(msg)
+		case stateConfirm:
+			return m.updateConfirm(msg)
+		}
+		switch msg.String() {
+		case "q":
+			return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+		case "esc":
+			return m, helpers.NavigateBack
+		}
+	}
+
+	return m, nil
+}
+
+// updateList handles the keys of the rule list; while the list is filtered
+// they are typed into the filter
+func (m *DeployModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.rules.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		m.rules, cmd = m.rules.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+	case "esc":
+		return m, helpers.NavigateBack
+	case "ctrl+r":
+		return m, helpers.RefreshScreen
+	case " ":
+		if item, ok := m.rules.SelectedItem().(ruleItem); ok {
+			m.selected[item.file.Path] = !m.selected[item.file.Path]
+		}
+		return m, nil
+	case "a":
+		// Tick every listed rule, or clear the ticks when all are ticked
+		items := m.rules.VisibleItems()
+		tickAll := false
+		for _, listItem := range items {
+			if item, ok := listItem.(ruleItem); ok && !m.selected[item.file.Path] {
+				tickAll = true
+			}
+		}
+		for _, listItem := range items {
+			if item, ok := listItem.(ruleItem); ok {
+				m.selected[item.file.Path] = tickAll
+			}
+		}
+		return m, nil
+	case "l":
+		m.link = !m.link
+		return m, nil
+	case "enter":
+		if len(m.selectedFiles()) == 0 {
+			// Enter on an unticked list deploys the rule under the cursor
+			item, ok := m.rules.SelectedItem().(ruleItem)
+			if !ok {
+				return m, nil
+			}
+			m.selected[item.file.Path] = true
+		}
+		m.logger.LogUserAction("deploy_rules_selected", fmt.Sprintf("%d rules", len(m.selectedFiles())))
+		m.status = ""
+		m.layout = m.layout.ClearError()
+		m.state = stateEditor
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.rules, cmd = m.rules.Update(msg)
+	return m, cmd
+}
+
+// updateEditor handles the keys of the editor list
+func (m *DeployModel) updateEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.editors.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		m.editors, cmd = m.editors.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q", "esc":
+		m.layout = m.layout.ClearError()
+		m.state = stateList
+		return m, nil
+	case "enter":
+		editor, ok := m.editors.SelectedItem().(editors.EditorRuleConfig)
+		if !ok {
+			return m, nil
+		}
+		m.editor = editor
+		if err := m.planTargets(); err != nil {
+			m.layout = m.layout.SetError(err)
+			return m, nil
+		}
+		m.layout = m.layout.ClearError()
+		m.state = stateConfirm
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.editors, cmd = m.editors.Update(msg)
+	return m, cmd
+}
+
+// updateConfirm handles the keys of the confirmation
+func (m *DeployModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		m.logger.LogUserAction("deploy_rules_confirmed", fmt.Sprintf("%d rules to %s", len(m.targets), m.editor.Name))
+		m.state = stateLoading
+		return m, tea.Batch(m.deployCmd(), m.spinner.Tick)
+	case "n", "N", "esc":
+		m.state = stateEditor
+	}
+	return m, nil
+}
+
+// planTargets works out the destination of every ticked rule for the chosen
+// editor. Editors that take a single file, such as AGENTS.md, take one rule.
+func (m *DeployModel) planTargets() error {
+	files := m.selectedFiles()
+	m.targets = make([]deploy.Target, 0, len(files))
+	m.existing = make(map[string]bool, len(files))
+	seen := make(map[string]string, len(files))
+	for _, file := range files {
+		prep, ok := m.repository(file)
+		if !ok {
+			return fmt.Errorf("repository of %s is no longer available", file.Name)
+		}
+		dest := filepath.Clean(m.editor.GenerateRuleFileFullPath(file.Name))
+		if other, ok := seen[dest]; ok {
+			return fmt.Errorf("%s and %s would both be deployed to %s; pick an editor with one file per rule", other, file.Name, dest)
+		}
+		seen[dest] = file.Name
+		if _, err := os.Lstat(dest); err == nil {
+			m.existing[dest] = true
+		}
+		m.targets = append(m.targets, deploy.Target{Source: file.Path, Repository: prep, Dest: dest, Frontmatter: m.editor.Frontmatter, VSCodeSnippet: m.editor.VSCodeSnippets})
+	}
+	return nil
+}
+
+// View renders the current state of the screen.
+func (m *DeployModel) View() string {
+	switch m.state {
+	case stateEditor:
+		return m.viewEditor()
+	case stateConfirm:
+		return m.viewConfirm()
+	}
+
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🚀 Deploy Rules",
+		Subtitle: m.subtitle(),
+		HelpText: "space tick • a tick all • l copy/link • enter continue • / filter • ctrl+r rescan • q/esc back",
+	})
+	if m.state == stateLoading {
+		return m.layout.Render(fmt.Sprintf("%s Loading rules...", m.spinner.View()))
+	}
+	if len(m.rules.Items()) == 0 {
+		return m.layout.Render("No rule files found.")
+	}
+	return m.layout.Render(m.rules.View())
+}
+
+func (m *DeployModel) viewEditor() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🚀 Deploy Rules - Editor",
+		Subtitle: fmt.Sprintf("%d rule(s) ticked", len(m.selectedFiles())),
+		HelpText: "Enter to continue • / to filter • q/Esc to go back",
+	})
+	return m.layout.Render("Choose the editor the rules are for:\n\n" + m.editors.View())
+}
+
+func (m *DeployModel) viewConfirm() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🚀 Deploy Rules - Confirmation",
+		Subtitle: fmt.Sprintf("Editor: %s | Mode: %s", m.editor.Name, m.mode()),
+		HelpText: "y to deploy • n/Esc to go back",
+	})
+
+	var content strings.Builder
+	for _, target := range m.targets {
+		fmt.Fprintf(&content, "%s → %s", filepath.Base(target.Source), target.Dest)
+		if m.existing[target.Dest] {
+			content.WriteString(styles.ErrorStyle.Render(" (replaces existing file)"))
+		}
+		content.WriteString("\n")
+	}
+	if !m.link && !m.editor.Frontmatter.IsZero() {
+		fmt.Fprintf(&content, "\nFrontmatter is rewritten for %s.\n", m.editor.Name)
+	}
+	content.WriteString("\nDeploy these rules into the current directory?")
+	return m.layout.Render(content.String())
+}
+
+func (m *DeployModel) subtitle() string {
+	if m.status != "" {
+		return styles.SuccessStyle.Render("✅ " + m.status)
+	}
+	ticked := len(m.selectedFiles())
+	return fmt.Sprintf("Tick the rules to deploy into the current directory • %d ticked • mode: %s", ticked, m.mode())
+}
+
+// mode is how the rules are deployed
+func (m *DeployModel) mode() project.Mode {
+	if m.link {
+		return project.ModeLink
+	}
+	return project.ModeCopy
+}
+
+// resizeLists fits the lists below the subtitle
+func (m *DeployModel) resizeLists() {
+	width := m.layout.ContentWidth()
+	height := max(m.layout.ContentHeight()-3, 3)
+	m.rules.SetSize(width, height)
+	m.editors.SetSize(width, height)
+}
+
+// selectedFiles returns the ticked rules in list order
+func (m *DeployModel) selectedFiles() []filemanager.FileItem {
+	var files []filemanager.FileItem
+	for _, listItem := range m.rules.Items() {
+		if item, ok := listItem.(ruleItem); ok && m.selected[item.file.Path] {
+			files = append(files, item.file)
+		}
+	}
+	return files
+}
+
+// repository returns the prepared repository file is in
+func (m *DeployModel) repository(file filemanager.FileItem) (repository.PreparedRepository, bool) {
+	for _, prep := range m.prepared {
+		if prep.ID() == file.RepositoryID {
+			return prep, true
+		}
+	}
+	return repository.PreparedRepository{}, false
+}
+
+// ruleItems lists files by their path relative to the root of their
+// repository, the overlay for files in a shared storage overlay
+func (m *DeployModel) ruleItems(files []filemanager.FileItem) []list.Item {
+	roots := make(map[string][]string, len(m.prepared))
+	for _, prep := range m.prepared {
+		roots[prep.ID()] = []string{prep.OverlayPath, prep.LocalPath}
+	}
+
+	items := make([]list.Item, 0, len(files))
+	for _, file := range files {
+		rel := file.Name
+		for _, root := range roots[file.RepositoryID] {
+			if root == "" {
+				continue
+			}
+			if r, err := filepath.Rel(root, file.Path); err == nil && !strings.HasPrefix(r, "..") {
+				rel = filepath.ToSlash(r)
+				break
+			}
+		}
+		items = append(items, ruleItem{file: file, rel: rel, selected: m.selected})
+	}
+	return items
+}
+
+func (m *DeployModel) loadCmd() tea.Cmd {
+	cfg := m.cfg
+	logger := m.logger
+	cache := m.cache
+	return func() tea.Msg {
+		if cfg == nil {
+			return loadedMsg{err: fmt.Errorf("configuration is not loaded")}
+		}
+		prepared, err := cache.PrepareRepositories(cfg, logger)
+		if err != nil {
+			return loadedMsg{err: fmt.Errorf("repository preparation failed: %w", err)}
+		}
+		available := repository.AvailableRepositories(prepared)
+		if len(available) == 0 {
+			return loadedMsg{err: fmt.Errorf("no repositories available - please run setup first")}
+		}
+		if files, ok := cache.Files(helpers.ScanRepositories); ok {
+			return loadedMsg{prepared: available, files: files}
+		}
+		files, err := filemanager.ScanAllRepositories(available, logger)
+		if err == nil {
+			cache.StoreFiles(helpers.ScanRepositories, files)
+		}
+		return loadedMsg{prepared: available, files: files, err: err}
+	}
+}
+
+// deployCmd deploys the planned targets. The user saw which files they
+// replace before confirming, so existing files are overwritten.
+func (m *DeployModel) deployCmd() tea.Cmd {
+	targets := m.targets
+	logger := m.logger
+	opts := deploy.Options{Mode: m.mode(), Overwrite: true}
+	if m.cfg != nil {
+		opts.Hooks = m.cfg.Hooks
+	}
+	return func() tea.Msg {
+		paths, err := deploy.Deploy(context.Background(), targets, opts, logger)
+		return deployedMsg{paths: paths, err: err}
+	}
+}
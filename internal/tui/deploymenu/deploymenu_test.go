@@ -0,0 +1,126 @@
+package deploymenu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/project"
+	"rulem/internal/repository"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel returns a loaded screen over a local repository holding files,
+// run from an empty project directory
+func newTestModel(t *testing.T, files map[string]string) *DeployModel {
+	t.Helper()
+	storage := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(storage, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	t.Chdir(t.TempDir())
+
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "rules-3f9a0c12", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: storage},
+	}}
+	logger, _ := logging.NewTestLogger()
+	m := NewDeployModel(helpers.NewUIContext(80, 24, cfg, logger))
+	loaded := m.loadCmd()()
+	if msg, ok := loaded.(loadedMsg); !ok || msg.err != nil {
+		t.Fatalf("loading rules: got %#v, want the prepared repository", loaded)
+	}
+	m, _ = tuitest.Run(t, m, nil, loaded)
+	return m
+}
+
+// isDeployed keeps the message that finishes a deploy
+func isDeployed(msg tea.Msg) bool {
+	_, ok := msg.(deployedMsg)
+	return ok
+}
+
+// selectEditor moves the editor list to the editor named name
+func selectEditor(t *testing.T, m *DeployModel, name string) {
+	t.Helper()
+	for i, item := range m.editors.Items() {
+		if strings.HasPrefix(item.FilterValue(), name) {
+			m.editors.Select(i)
+			return
+		}
+	}
+	t.Fatalf("no editor named %s", name)
+}
+
+func TestDeployModel_DeploysTickedRules(t *testing.T) {
+	m := newTestModel(t, map[string]string{"go.md": "# go\n", "python.md": "# python\n", "rust.md": "# rust\n"})
+
+	m = tuitest.Send(t, m, tuitest.Key("space"), tuitest.Key("down"), tuitest.Key("space"), tuitest.Key("enter"))
+	if m.state != stateEditor || len(m.selectedFiles()) != 2 {
+		t.Fatalf("state = %v with %d ticked, want the editor list for two rules", m.state, len(m.selectedFiles()))
+	}
+
+	selectEditor(t, m, "Cursor rules")
+	m = tuitest.Send(t, m, tuitest.Key("enter"))
+	if m.state != stateConfirm || !strings.Contains(m.View(), filepath.Join(".cursor", "rules", "go.mdc")) {
+		t.Fatalf("state = %v, want the confirmation listing the destinations\n%s", m.state, m.View())
+	}
+
+	m, _ = tuitest.Run(t, m, isDeployed, tuitest.Key("y"))
+	for _, name := range []string{"go.mdc", "python.mdc"} {
+		if _, err := os.Stat(filepath.Join(".cursor", "rules", name)); err != nil {
+			t.Errorf("%s not deployed: %v\n%s", name, err, m.View())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(".cursor", "rules", "rust.mdc")); !os.IsNotExist(err) {
+		t.Error("unticked rules should not be deployed")
+	}
+	if !strings.Contains(m.status, "Deployed 2 rule(s) to Cursor rules") || len(m.selectedFiles()) != 0 {
+		t.Errorf("status = %q with %d ticked, want the deploy reported and the ticks cleared", m.status, len(m.selectedFiles()))
+	}
+
+	manifest, err := project.LoadManifest(".")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if len(manifest.Rules) != 2 {
+		t.Errorf("manifest records %d rules, want 2", len(manifest.Rules))
+	}
+}
+
+func TestDeployModel_SingleFileEditorTakesOneRule(t *testing.T) {
+	m := newTestModel(t, map[string]string{"go.md": "# go\n", "python.md": "# python\n"})
+
+	m = tuitest.Send(t, m, tuitest.Key("a"), tuitest.Key("enter"))
+	selectEditor(t, m, "AGENTS.md")
+	m = tuitest.Send(t, m, tuitest.Key("enter"))
+
+	if m.state != stateEditor || !strings.Contains(m.View(), "would both be deployed to AGENTS.md") {
+		t.Errorf("state = %v, want an error on the editor list\n%s", m.state, m.View())
+	}
+}
+
+func TestDeployModel_Link(t *testing.T) {
+	m := newTestModel(t, map[string]string{"go.md": "# go\n"})
+
+	m = tuitest.Send(t, m, tuitest.Key("l"), tuitest.Key("enter"))
+	selectEditor(t, m, "Claude code")
+	m = tuitest.Send(t, m, tuitest.Key("enter"))
+	m, _ = tuitest.Run(t, m, isDeployed, tuitest.Key("y"))
+
+	info, err := os.Lstat("CLAUDE.md")
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("CLAUDE.md should link to the rule (%v)\n%s", err, m.View())
+	}
+}
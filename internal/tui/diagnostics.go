@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"fmt"
+
+	"rulem/internal/diagnostics"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Diagnostic bundles
+//
+// The error screen offers to write a diagnostic bundle (see the diagnostics
+// package) with d, and copies the bundle's path to the clipboard so it can be
+// pasted into a bug report. The offer is only made once a directory is set
+// with SetDiagnosticsDir.
+
+// diagnosticsWrittenMsg reports a diagnostic bundle written from the error screen
+type diagnosticsWrittenMsg struct {
+	path   string
+	copied bool // The path was copied to the clipboard
+	err    error
+}
+
+// copyToClipboard copies text to the system clipboard; tests replace it
+var copyToClipboard = clipboard.WriteAll
+
+// SetDiagnosticsDir lets the error screen write diagnostic bundles to dir
+func (m *MainModel) SetDiagnosticsDir(dir string) {
+	m.diagnosticsDir = dir
+}
+
+// writeDiagnostics returns a command writing a bundle for the current error
+func (m *MainModel) writeDiagnostics() tea.Cmd {
+	cause, cfg, logger, dir := m.err, m.config, m.logger, m.diagnosticsDir
+	return func() tea.Msg {
+		path, err := diagnostics.Collect(cause, cfg, logger).Write(dir)
+		if err != nil {
+			return diagnosticsWrittenMsg{err: err}
+		}
+		// Headless systems have no clipboard; the path is shown either way
+		copied := copyToClipboard(path) == nil
+		return diagnosticsWrittenMsg{path: path, copied: copied}
+	}
+}
+
+// status describes the outcome of writing a bundle for the error screen
+func (msg diagnosticsWrittenMsg) status() string {
+	switch {
+	case msg.err != nil:
+		return "❌ Could not write a diagnostic bundle: " + msg.err.Error()
+	case msg.copied:
+		return fmt.Sprintf("📋 Diagnostic bundle saved to %s (path copied to the clipboard).\nAttach it to your bug report; secrets have been redacted, but please check it first.", msg.path)
+	default:
+		return fmt.Sprintf("📋 Diagnostic bundle saved to %s.\nAttach it to your bug report; secrets have been redacted, but please check it first.", msg.path)
+	}
+}
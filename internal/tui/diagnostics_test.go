@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/logging"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestErrorScreen_DiagnosticBundle(t *testing.T) {
+	t.Setenv("RULEM_CONFIG_PATH", filepath.Join(t.TempDir(), "missing.yaml"))
+	var copied string
+	original := copyToClipboard
+	copyToClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	t.Cleanup(func() { copyToClipboard = original })
+
+	logger, _ := logging.NewTestLogger()
+	model := NewMainModel(createTestConfigWithPath("/test/path"), logger)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	dir := t.TempDir()
+	model.SetDiagnosticsDir(dir)
+
+	model.Update(ErrorMsg{Err: errors.New("failed to load configuration: permission denied")})
+	if view := model.View(); !strings.Contains(view, "Press d to save a diagnostic bundle") {
+		t.Fatalf("error screen should offer a diagnostic bundle:\n%s", view)
+	}
+
+	_, cmd := model.Update(tuitest.Key("d"))
+	if cmd == nil {
+		t.Fatal("d should write a diagnostic bundle")
+	}
+	model.Update(cmd())
+
+	if copied == "" || filepath.Dir(copied) != dir {
+		t.Fatalf("copied %q to the clipboard, want a bundle in %s", copied, dir)
+	}
+	data, err := os.ReadFile(copied)
+	if err != nil {
+		t.Fatalf("bundle not written: %v", err)
+	}
+	if !strings.Contains(string(data), "failed to load configuration: permission denied") {
+		t.Errorf("bundle does not contain the error:\n%s", data)
+	}
+	if !strings.Contains(model.diagnosticsStatus, "path copied to the clipboard") {
+		t.Errorf("error screen should report the bundle, got %q", model.diagnosticsStatus)
+	}
+}
+
+func TestErrorScreen_DiagnosticsOff(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	model := NewMainModel(createTestConfigWithPath("/test/path"), logger)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	model.Update(ErrorMsg{Err: errors.New("boom")})
+
+	if _, cmd := model.Update(tuitest.Key("d")); cmd != nil {
+		t.Error("d should do nothing without a diagnostics directory")
+	}
+	if strings.Contains(model.View(), "diagnostic bundle") {
+		t.Error("error screen should not offer a bundle without a diagnostics directory")
+	}
+}
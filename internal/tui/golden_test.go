@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"rulem/internal/logging"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestGoldenViews renders the main model screens, including the status bar, at
+// several terminal sizes and compares them with
+// testdata/<name>_<width>x<height>.golden. Regenerate with
+// go test ./internal/tui -run TestGoldenViews -update
+func TestGoldenViews(t *testing.T) {
+	sizes := []struct{ width, height int }{{60, 20}, {80, 24}, {120, 40}}
+	tests := []struct {
+		name string
+		msgs []tea.Msg
+	}{
+		{name: "menu"},
+		{name: "menu_filtered", msgs: append(tuitest.Keys("/"), tuitest.Type("sett")...)},
+		{name: "error", msgs: []tea.Msg{ErrorMsg{Err: errors.New("failed to load configuration: permission denied")}}},
+		{name: "coming_soon", msgs: []tea.Msg{ComingSoonMsg{Feature: "Sync rules"}}},
+		{name: "quitting", msgs: tuitest.Keys("ctrl+c")},
+		{name: "keyring_unavailable", msgs: []tea.Msg{helpers.KeyringStatusMsg{Status: unavailableKeyring(context.Background())}}},
+	}
+
+	for _, tt := range tests {
+		for _, size := range sizes {
+			name := fmt.Sprintf("%s_%dx%d", tt.name, size.width, size.height)
+			t.Run(name, func(t *testing.T) {
+				logger, _ := logging.NewTestLogger()
+				m := NewMainModel(createTestConfigWithPath("/test/path"), logger)
+				m.checkKeyring = availableKeyring
+
+				// Apply the initial status bar check like the runtime would
+				msgs := append(tuitest.Exec(m.Init()), tea.WindowSizeMsg{Width: size.width, Height: size.height})
+				m = tuitest.Send(t, m, msgs...)
+				// Run commands so asynchronous results such as filter
+				// matches are applied
+				m, _ = tuitest.Run(t, m, nil, tt.msgs...)
+
+				tuitest.Golden(t, name, m.View())
+			})
+		}
+	}
+}
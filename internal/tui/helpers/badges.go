@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Menu badges
+//
+// The main menu shows how many rules the repositories hold and how their last
+// sync went next to its entries, e.g. "Import rules (142)". The badges come
+// from the ScreenCache, so loading them prepares and scans the repositories
+// once for the screens opened afterwards too.
+
+// MenuBadgesMsg carries the main menu badges. MainModel asks for them with
+// LoadMenuBadges when the menu is shown.
+type MenuBadgesMsg struct {
+	// Rules is the number of rule files in the available repositories, -1
+	// when the repositories could not be prepared or scanned
+	Rules int
+
+	// Sync summarizes the last sync of the GitHub repositories, e.g.
+	// "1 failed, 2 skipped", or "in sync"; "" without GitHub repositories
+	Sync string
+}
+
+// LoadMenuBadges returns a command that computes the main menu badges and
+// reports them as a MenuBadgesMsg
+func LoadMenuBadges(cfg *config.Config, cache *ScreenCache, logger *logging.AppLogger) tea.Cmd {
+	return func() tea.Msg {
+		msg := MenuBadgesMsg{Rules: -1}
+		if cfg == nil || len(cfg.Repositories) == 0 {
+			return msg
+		}
+		prepared, err := cache.PrepareRepositories(cfg, logger)
+		if err != nil {
+			logger.Debug("Menu badges unavailable", "error", err)
+			return msg
+		}
+		msg.Sync = syncBadge(prepared)
+
+		files, ok := cache.Files(ScanRepositories)
+		if !ok {
+			files, err = filemanager.ScanAllRepositories(repository.AvailableRepositories(prepared), logger)
+			if err != nil {
+				logger.Debug("Menu rule count unavailable", "error", err)
+				return msg
+			}
+			cache.StoreFiles(ScanRepositories, files)
+		}
+		msg.Rules = len(files)
+		return msg
+	}
+}
+
+// syncBadge summarizes the sync results of the GitHub repositories; a
+// repository that could not be prepared at all counts as failed
+func syncBadge(prepared []repository.PreparedRepository) string {
+	var remote, failed, skipped int
+	for _, prep := range prepared {
+		if !prep.IsRemote() {
+			continue
+		}
+		remote++
+		switch {
+		case prep.HasError() || !prep.IsAvailable():
+			failed++
+		case prep.WasSkipped():
+			skipped++
+		}
+	}
+
+	var parts []string
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", failed))
+	}
+	if skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", skipped))
+	}
+	switch {
+	case len(parts) > 0:
+		return strings.Join(parts, ", ")
+	case remote > 0:
+		return "in sync"
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+)
+
+func TestLoadMenuBadges(t *testing.T) {
+	storage := t.TempDir()
+	for _, name := range []string{"style.md", "go/testing.md"} {
+		path := filepath.Join(storage, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("# rule"), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "rules-3f9a0c12", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: storage},
+	}}
+	logger, _ := logging.NewTestLogger()
+	cache := NewScreenCache()
+
+	msg, ok := LoadMenuBadges(cfg, cache, logger)().(MenuBadgesMsg)
+	if !ok || msg.Rules != 2 || msg.Sync != "" {
+		t.Fatalf("badges = %+v, want 2 rules and no sync badge without GitHub repositories", msg)
+	}
+	if files, ok := cache.Files(ScanRepositories); !ok || len(files) != 2 {
+		t.Errorf("cached scan = %v, %v; want the scan kept for the screens", files, ok)
+	}
+
+	missing := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "gone-5b1e77d0", Name: "Gone", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: filepath.Join(storage, "missing")},
+	}}
+	if msg := LoadMenuBadges(missing, NewScreenCache(), logger)().(MenuBadgesMsg); msg.Rules != -1 {
+		t.Errorf("badges = %+v, want an unknown rule count", msg)
+	}
+}
+
+func TestSyncBadge(t *testing.T) {
+	github := repository.RepositoryEntry{Type: repository.RepositoryTypeGitHub}
+	local := repository.RepositoryEntry{Type: repository.RepositoryTypeLocal}
+	prepared := func(entry repository.RepositoryEntry, status repository.SyncStatus, available bool) repository.PreparedRepository {
+		prep := repository.PreparedRepository{Entry: entry, SyncResult: repository.RepositorySyncResult{Status: status}}
+		if available {
+			prep.LocalPath = "/rules"
+		}
+		return prep
+	}
+
+	tests := []struct {
+		name     string
+		prepared []repository.PreparedRepository
+		want     string
+	}{
+		{"local only", []repository.PreparedRepository{prepared(local, repository.SyncStatusSkipped, true)}, ""},
+		{"synced", []repository.PreparedRepository{prepared(github, repository.SyncStatusSuccess, true)}, "in sync"},
+		{"problems", []repository.PreparedRepository{
+			prepared(github, repository.SyncStatusFailed, true),
+			prepared(github, repository.SyncStatusSuccess, false),
+			prepared(github, repository.SyncStatusSkipped, true),
+			prepared(local, repository.SyncStatusSkipped, true),
+		}, "2 failed, 1 skipped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syncBadge(tt.prepared); got != tt.want {
+				t.Errorf("syncBadge() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,28 +1,98 @@
 package helpers
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
 	"rulem/internal/config"
 	"rulem/internal/logging"
+	"rulem/internal/notes"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
+// Now returns the current time. It is used for timestamps such as a
+// repository's CreatedAt; tests replace it (see tuitest.FreezeTime) to make
+// them deterministic.
+var Now = time.Now
+
 // NavigateToMainMenuMsg is a common message for all submodels to navigate back to main menu
 type NavigateToMainMenuMsg struct{}
 
+// NavigateBackMsg asks MainModel to go back one step in its navigation
+// history, which for a screen opened from the menu is the menu. Screens send it
+// for esc at their first step, and NavigateToMainMenuMsg when the user asks for
+// the menu itself.
+type NavigateBackMsg struct{}
+
+// NavigateBack is a command that sends NavigateBackMsg
+func NavigateBack() tea.Msg {
+	return NavigateBackMsg{}
+}
+
+// RefreshScreenMsg asks MainModel to empty the ScreenCache and open the
+// current screen afresh, preparing the repositories and scanning again. Screens
+// send it for their rescan key, ctrl+r.
+type RefreshScreenMsg struct{}
+
+// RefreshScreen is a command that sends RefreshScreenMsg
+func RefreshScreen() tea.Msg {
+	return RefreshScreenMsg{}
+}
+
+// Breadcrumber is implemented by screens with several steps. MainModel shows
+// the steps they return, see navigation.Stack, after the screen's name in the
+// status bar's breadcrumbs.
+type Breadcrumber interface {
+	Breadcrumbs() []string
+}
+
+// KeyringStatusMsg carries the result of the startup credential store
+// heartbeat. MainModel records it and forwards it to the active screen.
+type KeyringStatusMsg struct {
+	Status repository.KeyringStatus
+}
+
 // UIContext carries environment information needed for creating UI models
 type UIContext struct {
 	Width  int
 	Height int
 	Config *config.Config
 	Logger *logging.AppLogger
+
+	// IDs generates IDs for new repositories. Tests replace it with a
+	// config.SequentialIDGenerator to get predictable IDs.
+	IDs config.IDGenerator
+
+	// Keyring is the latest credential store heartbeat; Checked is false
+	// until the startup check has completed
+	Keyring repository.KeyringStatus
+
+	// Notes holds the user's private rule notes and ratings; nil when they
+	// could not be loaded
+	Notes *notes.Store
+
+	// Scheduler runs the background sync; nil when sync_interval is not set
+	Scheduler *repository.Scheduler
+
+	// Cache keeps prepared repositories and scans between visits to a
+	// screen; nil caches nothing
+	Cache *ScreenCache
 }
 
 // NewUIContext creates a new UI context with the provided parameters
-func NewUIContext(width, height int, config *config.Config, logger *logging.AppLogger) UIContext {
+func NewUIContext(width, height int, cfg *config.Config, logger *logging.AppLogger) UIContext {
 	return UIContext{
 		Width:  width,
 		Height: height,
-		Config: config,
+		Config: cfg,
 		Logger: logger,
+		IDs:    config.DefaultIDGenerator(),
 	}
 }
 
@@ -30,3 +100,78 @@ func NewUIContext(width, height int, config *config.Config, logger *logging.AppL
 func (ctx UIContext) HasValidDimensions() bool {
 	return ctx.Width > 0 && ctx.Height > 0
 }
+
+// CheckKeyring returns a command that runs the credential store heartbeat
+// (normally repository.CredentialManager.CheckKeyring) and reports it as a
+// KeyringStatusMsg. The check never fails; an unavailable store is reported
+// in the status.
+func CheckKeyring(check func(context.Context) repository.KeyringStatus) tea.Cmd {
+	return func() tea.Msg {
+		return KeyringStatusMsg{Status: check(context.Background())}
+	}
+}
+
+// RefreshStatusBar checks the configured repositories on disk and returns a
+// components.StatusUpdateMsg summarizing them for the shared status bar.
+// Models return this command whenever they change repository state.
+func RefreshStatusBar(cfg *config.Config) tea.Cmd {
+	var repos []repository.RepositoryEntry
+	if cfg != nil {
+		repos = cfg.Repositories
+	}
+	return func() tea.Msg {
+		return components.StatusUpdateMsg{Info: SummarizeRepositories(repos)}
+	}
+}
+
+// SummarizeRepositories builds the status bar context from the configured
+// repositories: the repository name (or a count), a short sync summary, and
+// whether any GitHub clone has local changes.
+func SummarizeRepositories(repos []repository.RepositoryEntry) components.StatusInfo {
+	var info components.StatusInfo
+	switch len(repos) {
+	case 0:
+		info.Repository = "no repositories"
+		return info
+	case 1:
+		info.Repository = repos[0].Name
+	default:
+		info.Repository = fmt.Sprintf("%d repositories", len(repos))
+	}
+
+	var github, missing, unreadable int
+	for _, repo := range repos {
+		if !repo.IsRemote() {
+			continue
+		}
+		github++
+		if _, err := os.Stat(repo.Path); os.IsNotExist(err) {
+			missing++
+			continue
+		}
+		dirty, err := repository.CheckGithubRepositoryStatus(repo.Path)
+		switch {
+		case err != nil:
+			unreadable++
+		case dirty:
+			info.Dirty = true
+		}
+	}
+
+	var problems []string
+	if missing > 0 {
+		problems = append(problems, fmt.Sprintf("%d clone missing", missing))
+	}
+	if unreadable > 0 {
+		problems = append(problems, fmt.Sprintf("%d unreadable", unreadable))
+	}
+	switch {
+	case len(problems) > 0:
+		info.Sync = "⚠️ " + strings.Join(problems, ", ")
+	case github > 0:
+		info.Sync = "✅ in sync"
+	default:
+		info.Sync = "local only"
+	}
+	return info
+}
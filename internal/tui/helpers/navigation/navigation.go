@@ -0,0 +1,135 @@
+// Package navigation provides the navigation history shared by the TUI models.
+//
+// The root model records the screens opened from the menu, and screens with
+// several steps record their own steps, in a Stack. Back always returns to the
+// step the user came from, and the history doubles as the breadcrumbs shown in
+// the status bar. Going to a step already in the history unwinds the history
+// to it, so flows that return to an earlier step (cancelling a confirmation,
+// retrying after an error) keep a short trail and back never goes in circles.
+//
+// Example usage:
+//
+//	var history navigation.Stack[State]
+//	history.Visit(current, next) // when leaving current for next
+//	if prev, ok := history.Back(); ok {
+//	    current = prev
+//	}
+package navigation
+
+import (
+	"slices"
+	"strings"
+)
+
+// Separator is put between breadcrumbs
+const Separator = " › "
+
+// Stack is a navigation history: the states left to reach the current one,
+// oldest first. The current state is not part of it. The zero value is an
+// empty history.
+type Stack[S comparable] struct {
+	states []S
+}
+
+// Visit records leaving from for to. When to is already in the history, the
+// history is unwound to the states before it instead.
+func (s *Stack[S]) Visit(from, to S) {
+	if i := slices.Index(s.states, to); i >= 0 {
+		s.states = s.states[:i]
+		return
+	}
+	if from == to {
+		return
+	}
+	s.states = append(s.states, from)
+}
+
+// Back removes and returns the most recent state; ok is false when the
+// history is empty
+func (s *Stack[S]) Back() (state S, ok bool) {
+	if len(s.states) == 0 {
+		return state, false
+	}
+	state = s.states[len(s.states)-1]
+	s.states = s.states[:len(s.states)-1]
+	return state, true
+}
+
+// Previous returns the most recent state without removing it
+func (s *Stack[S]) Previous() (state S, ok bool) {
+	if len(s.states) == 0 {
+		return state, false
+	}
+	return s.states[len(s.states)-1], true
+}
+
+// States returns a copy of the history, oldest first
+func (s *Stack[S]) States() []S {
+	return slices.Clone(s.states)
+}
+
+// Len returns the number of states in the history
+func (s *Stack[S]) Len() int {
+	return len(s.states)
+}
+
+// Clear empties the history
+func (s *Stack[S]) Clear() {
+	s.states = nil
+}
+
+// Breadcrumbs joins crumbs with Separator. With more than limit crumbs only
+// the last limit are kept, after an ellipsis; a limit of zero keeps all.
+func Breadcrumbs(crumbs []string, limit int) string {
+	if limit > 0 && len(crumbs) > limit {
+		crumbs = append([]string{"…"}, crumbs[len(crumbs)-limit:]...)
+	}
+	return strings.Join(crumbs, Separator)
+}
+
+// Label turns the CamelCase name of a state into a breadcrumb, e.g.
+// "UpdateGitHubBranch" into "Update GitHub branch". Acronyms such as PAT stay
+// upper case.
+func Label(name string) string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		lowerBefore := isLower(runes[i-1])
+		// An upper case letter starts a word after a lower case one, or ends an
+		// acronym when a lower case letter follows it ("PATConfirm")
+		acronymEnd := isUpper(runes[i-1]) && i+1 < len(runes) && isLower(runes[i+1])
+		if isUpper(runes[i]) && (lowerBefore || acronymEnd) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	// Join the halves of compound names split above
+	var joined []string
+	for _, word := range words {
+		if n := len(joined); n > 0 && joined[n-1] == "Git" && word == "Hub" {
+			joined[n-1] = "GitHub"
+			continue
+		}
+		joined = append(joined, word)
+	}
+
+	for i, word := range joined {
+		if i > 0 && word != "GitHub" && !isAcronym(word) {
+			joined[i] = strings.ToLower(word)
+		}
+	}
+	return strings.Join(joined, " ")
+}
+
+func isUpper(r rune) bool { return r >= 'A' && r <= 'Z' }
+func isLower(r rune) bool { return r >= 'a' && r <= 'z' }
+
+// isAcronym reports whether word is all upper case, like PAT
+func isAcronym(word string) bool {
+	return len(word) > 1 && strings.ToUpper(word) == word
+}
@@ -0,0 +1,69 @@
+package navigation
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	var history Stack[string]
+	if _, ok := history.Back(); ok {
+		t.Fatal("Back() on an empty history should report no state")
+	}
+
+	history.Visit("menu", "settings")
+	history.Visit("settings", "actions")
+	history.Visit("actions", "confirm")
+	if got, want := history.States(), []string{"menu", "settings", "actions"}; !slices.Equal(got, want) {
+		t.Fatalf("States() = %v, want %v", got, want)
+	}
+	if prev, ok := history.Previous(); !ok || prev != "actions" {
+		t.Errorf("Previous() = %q, %v; want actions", prev, ok)
+	}
+
+	// Returning to a state in the history unwinds it
+	history.Visit("confirm", "actions")
+	if got, want := history.States(), []string{"menu", "settings"}; !slices.Equal(got, want) {
+		t.Fatalf("States() after returning = %v, want %v", got, want)
+	}
+
+	// Staying on a state records nothing
+	history.Visit("actions", "actions")
+	if history.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", history.Len())
+	}
+
+	if prev, ok := history.Back(); !ok || prev != "settings" {
+		t.Errorf("Back() = %q, %v; want settings", prev, ok)
+	}
+	history.Clear()
+	if history.Len() != 0 {
+		t.Errorf("Len() after Clear() = %d, want 0", history.Len())
+	}
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	crumbs := []string{"Settings", "Repository actions", "Update GitHub branch", "Edit branch confirm"}
+	if got, want := Breadcrumbs(crumbs, 0), "Settings › Repository actions › Update GitHub branch › Edit branch confirm"; got != want {
+		t.Errorf("Breadcrumbs(limit 0) = %q, want %q", got, want)
+	}
+	if got, want := Breadcrumbs(crumbs, 2), "… › Update GitHub branch › Edit branch confirm"; got != want {
+		t.Errorf("Breadcrumbs(limit 2) = %q, want %q", got, want)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	tests := map[string]string{
+		"RepositoryActions":  "Repository actions",
+		"UpdateGitHubBranch": "Update GitHub branch",
+		"UpdatePATConfirm":   "Update PAT confirm",
+		"AddGitHubPAT":       "Add GitHub PAT",
+		"Complete":           "Complete",
+		"":                   "",
+	}
+	for name, want := range tests {
+		if got := Label(name); got != want {
+			t.Errorf("Label(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -10,6 +10,9 @@
 //   - ActionListItem: A list.Item implementation for action items (add, delete)
 //   - BuildRepositoryList: Helper to create a configured list.Model from repositories
 //
+// Lists built with BuildRepositoryList middle-truncate repository paths to the
+// list width (see textutil.TruncatePath), so the directory name stays visible.
+//
 // Example usage:
 //
 //	items := repolist.BuildRepositoryListItems(prepared)
@@ -18,16 +21,19 @@ package repolist
 
 import (
 	"fmt"
+	"io"
 	"rulem/internal/repository"
+	"rulem/internal/tui/helpers/textutil"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // RepositoryListItem implements list.Item for displaying repositories
 // in Bubble Tea list components. It wraps a PreparedRepository with
 // display-friendly methods.
 type RepositoryListItem struct {
-	// ID is the unique repository identifier (e.g., "personal-rules-1728756432")
+	// ID is the unique repository identifier (e.g., "personal-rules-3f9a0c12")
 	ID string
 
 	// Name is the user-provided display name (e.g., "Personal Rules")
@@ -54,14 +60,19 @@ func (i RepositoryListItem) Title() string {
 // Description returns the repository type and path for display.
 // Shows an icon based on repository type followed by the path.
 func (i RepositoryListItem) Description() string {
+	return i.descriptionPrefix() + i.Path
+}
+
+// descriptionPrefix returns the part of the description before the path
+func (i RepositoryListItem) descriptionPrefix() string {
 	icon := "📁" // local
 	if i.Type == "github" {
 		icon = "🔗" // github
 	}
 	if !i.Available {
-		return fmt.Sprintf("%s %s • ⚠️ unavailable • %s", icon, i.Type, i.Path)
+		return fmt.Sprintf("%s %s • ⚠️ unavailable • ", icon, i.Type)
 	}
-	return fmt.Sprintf("%s %s • %s", icon, i.Type, i.Path)
+	return fmt.Sprintf("%s %s • ", icon, i.Type)
 }
 
 // FilterValue returns the combined search string for filtering.
@@ -109,7 +120,7 @@ func BuildRepositoryListItems(prepared []repository.PreparedRepository) []list.I
 // Returns:
 //   - list.Model: Configured Bubble Tea list ready for use
 func BuildRepositoryList(items []list.Item, width, height int) list.Model {
-	repoList := list.New(items, list.NewDefaultDelegate(), width, height)
+	repoList := list.New(items, repositoryDelegate{list.NewDefaultDelegate()}, width, height)
 	repoList.Title = "" // Use layout for titles
 	repoList.SetShowTitle(false)
 	repoList.SetShowStatusBar(false)
@@ -118,6 +129,22 @@ func BuildRepositoryList(items []list.Item, width, height int) list.Model {
 	return repoList
 }
 
+// repositoryDelegate renders items like the default delegate, but shortens
+// repository paths in the middle rather than at the end
+type repositoryDelegate struct {
+	list.DefaultDelegate
+}
+
+// Render renders item with its path truncated to the list width
+func (d repositoryDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if repo, ok := item.(RepositoryListItem); ok {
+		width := m.Width() - d.Styles.NormalDesc.GetHorizontalPadding() - lipgloss.Width(repo.descriptionPrefix())
+		repo.Path = textutil.TruncatePath(repo.Path, width)
+		item = repo
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
 // GetSelectedRepository returns the selected RepositoryListItem from a list along with
 // the raw list.Item. This permits callers to inspect non-repository selections (e.g., action items).
 // Returns (nil, nil) if nothing is selected.
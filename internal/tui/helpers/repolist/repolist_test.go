@@ -2,9 +2,11 @@ package repolist
 
 import (
 	"rulem/internal/repository"
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // TestBuildRepositoryListItems tests conversion of PreparedRepository slice to list items.
@@ -166,6 +168,29 @@ func TestBuildRepositoryList(t *testing.T) {
 	}
 }
 
+// TestBuildRepositoryList_TruncatesPaths tests that long paths are shortened in
+// the middle, keeping the repository directory visible.
+func TestBuildRepositoryList_TruncatesPaths(t *testing.T) {
+	path := "/home/user/projects/" + strings.Repeat("nested/", 10) + "team-rules"
+	items := BuildRepositoryListItems([]repository.PreparedRepository{
+		{
+			Entry:     repository.RepositoryEntry{ID: "team-rules-3f9a0c12", Name: "Team Rules", Type: repository.RepositoryTypeLocal},
+			LocalPath: path,
+		},
+	})
+	repoList := BuildRepositoryList(items, 50, 10)
+
+	rendered := repoList.View()
+	if !strings.Contains(rendered, "…/team-rules") {
+		t.Errorf("rendered item should keep the directory name after an ellipsis:\n%s", rendered)
+	}
+	for _, line := range strings.Split(rendered, "\n") {
+		if w := lipgloss.Width(line); w > 50 {
+			t.Errorf("line is %d cells wide, want at most 50: %q", w, line)
+		}
+	}
+}
+
 // TestGetSelectedRepository tests the selection helper.
 func TestGetSelectedRepository(t *testing.T) {
 	items := BuildRepositoryListItems([]repository.PreparedRepository{
@@ -0,0 +1,149 @@
+package helpers
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+)
+
+// Screen cache
+//
+// Opening the save, import or manage screen prepares every repository, which
+// can sync GitHub clones, and scans for rule files. MainModel keeps one
+// ScreenCache for the session and hands it to every screen in the UIContext,
+// so leaving a screen and coming back reuses both. Prepared repositories are
+// kept until the configuration is reloaded; scans until a screen changes the
+// files (InvalidateFiles), the background sync updates the clones (NoteSync)
+// or the user rescans with ctrl+r.
+
+// Keys of the scans kept in a ScreenCache
+const (
+	// ScanWorkingDir is the scan of the current directory, by the save screen
+	ScanWorkingDir = "working-dir"
+	// ScanRepositories is the scan of every available repository, by the
+	// import and manage screens
+	ScanRepositories = "repositories"
+)
+
+// ScreenCache keeps prepared repositories and scan results between visits to
+// a screen. A nil *ScreenCache caches nothing, so screens created without one,
+// as in tests, prepare and scan every time. It is safe for concurrent use by
+// the commands of the screens.
+type ScreenCache struct {
+	mu sync.Mutex
+
+	// config is the configuration the repositories were prepared for
+	config   *config.Config
+	prepared []repository.PreparedRepository
+
+	scans    map[string][]filemanager.FileItem
+	lastSync time.Time
+}
+
+// NewScreenCache returns an empty cache
+func NewScreenCache() *ScreenCache {
+	return &ScreenCache{scans: make(map[string][]filemanager.FileItem)}
+}
+
+// PrepareRepositories returns the repositories of cfg prepared with
+// repository.PrepareAllRepositories, reusing the last result while cfg is the
+// configuration they were prepared for. Failures are not cached.
+func (c *ScreenCache) PrepareRepositories(cfg *config.Config, logger *logging.AppLogger) ([]repository.PreparedRepository, error) {
+	if c == nil {
+		return repository.PrepareAllRepositories(context.Background(), cfg.Repositories, logger)
+	}
+
+	c.mu.Lock()
+	if c.config == cfg && c.prepared != nil {
+		prepared := slices.Clone(c.prepared)
+		c.mu.Unlock()
+		logger.Debug("Reusing prepared repositories", "count", len(prepared))
+		return prepared, nil
+	}
+	c.mu.Unlock()
+
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, logger)
+	if err != nil {
+		return prepared, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.config != cfg {
+		// Scans of another configuration's repositories are stale too
+		clear(c.scans)
+	}
+	c.config = cfg
+	c.prepared = slices.Clone(prepared)
+	return prepared, nil
+}
+
+// Files returns the cached scan stored under key
+func (c *ScreenCache) Files(key string) ([]filemanager.FileItem, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	files, ok := c.scans[key]
+	return slices.Clone(files), ok
+}
+
+// StoreFiles caches the result of a scan under key
+func (c *ScreenCache) StoreFiles(key string, files []filemanager.FileItem) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scans[key] = slices.Clone(files)
+}
+
+// InvalidateFiles drops the cached scans under keys, or every scan when no
+// key is given, after files were saved, imported, moved or deleted
+func (c *ScreenCache) InvalidateFiles(keys ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(keys) == 0 {
+		clear(c.scans)
+		return
+	}
+	for _, key := range keys {
+		delete(c.scans, key)
+	}
+}
+
+// NoteSync drops the cached repository scans when the background sync ran
+// after lastRun was last noted, since it may have changed the clones
+func (c *ScreenCache) NoteSync(lastRun time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if lastRun.After(c.lastSync) {
+		c.lastSync = lastRun
+		delete(c.scans, ScanRepositories)
+	}
+}
+
+// Invalidate empties the cache
+func (c *ScreenCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config = nil
+	c.prepared = nil
+	clear(c.scans)
+}
@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+)
+
+func TestScreenCacheFiles(t *testing.T) {
+	cache := NewScreenCache()
+	if _, ok := cache.Files(ScanWorkingDir); ok {
+		t.Fatal("an empty cache must not have scans")
+	}
+
+	files := []filemanager.FileItem{{Name: "style.md", Path: "/rules/style.md"}}
+	cache.StoreFiles(ScanWorkingDir, files)
+	cache.StoreFiles(ScanRepositories, files)
+	files[0].Name = "changed.md"
+	if got, ok := cache.Files(ScanWorkingDir); !ok || len(got) != 1 || got[0].Name != "style.md" {
+		t.Errorf("Files() = %v, %v; want the stored scan, unaffected by later changes", got, ok)
+	}
+
+	cache.InvalidateFiles(ScanWorkingDir)
+	if _, ok := cache.Files(ScanWorkingDir); ok {
+		t.Error("the invalidated scan must be gone")
+	}
+	if _, ok := cache.Files(ScanRepositories); !ok {
+		t.Error("other scans must be kept")
+	}
+
+	// A background sync drops the repository scans once per run
+	lastRun := time.Now()
+	cache.NoteSync(lastRun)
+	if _, ok := cache.Files(ScanRepositories); ok {
+		t.Error("repository scans must be dropped after a sync")
+	}
+	cache.StoreFiles(ScanRepositories, files)
+	cache.NoteSync(lastRun)
+	if _, ok := cache.Files(ScanRepositories); !ok {
+		t.Error("noting the same sync again must keep the scans")
+	}
+
+	cache.InvalidateFiles()
+	if _, ok := cache.Files(ScanRepositories); ok {
+		t.Error("InvalidateFiles() without keys must drop every scan")
+	}
+}
+
+func TestScreenCachePrepareRepositories(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "rules-3f9a0c12", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: t.TempDir()},
+	}}
+
+	cache := NewScreenCache()
+	first, err := cache.PrepareRepositories(cfg, logger)
+	if err != nil {
+		t.Fatalf("PrepareRepositories failed: %v", err)
+	}
+	cache.StoreFiles(ScanRepositories, []filemanager.FileItem{{Name: "style.md"}})
+
+	// The same configuration reuses the prepared repositories and scans
+	moved := cfg.Repositories[0].Path
+	cfg.Repositories[0].Path = t.TempDir()
+	again, err := cache.PrepareRepositories(cfg, logger)
+	if err != nil {
+		t.Fatalf("PrepareRepositories failed: %v", err)
+	}
+	if len(again) != 1 || again[0].LocalPath != first[0].LocalPath || again[0].LocalPath != moved {
+		t.Errorf("prepared = %+v, want the cached repositories", again)
+	}
+
+	// A reloaded configuration prepares again and drops the scans
+	reloaded := &config.Config{Repositories: cfg.Repositories}
+	fresh, err := cache.PrepareRepositories(reloaded, logger)
+	if err != nil {
+		t.Fatalf("PrepareRepositories failed: %v", err)
+	}
+	if len(fresh) != 1 || fresh[0].LocalPath != cfg.Repositories[0].Path {
+		t.Errorf("prepared = %+v, want %s prepared again", fresh, cfg.Repositories[0].Path)
+	}
+	if _, ok := cache.Files(ScanRepositories); ok {
+		t.Error("scans of the old configuration must be dropped")
+	}
+
+	// A nil cache prepares every time
+	var none *ScreenCache
+	if prepared, err := none.PrepareRepositories(reloaded, logger); err != nil || len(prepared) != 1 {
+		t.Errorf("nil cache PrepareRepositories() = %v, %v", prepared, err)
+	}
+	none.StoreFiles(ScanWorkingDir, nil)
+	if _, ok := none.Files(ScanWorkingDir); ok {
+		t.Error("a nil cache must not have scans")
+	}
+}
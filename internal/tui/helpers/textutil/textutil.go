@@ -0,0 +1,151 @@
+// Package textutil fits long single-token values such as paths and URLs into
+// the current layout width.
+//
+// The layout wraps text on word boundaries, which leaves a long path either
+// overflowing the terminal or broken at arbitrary points by the terminal itself.
+// Views instead middle-truncate the value so both its start and its meaningful
+// end (file name, repository name) stay visible, and show the full value on a
+// separate detail line where the user needs it.
+//
+// All widths are terminal cells, so wide characters are accounted for.
+package textutil
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Ellipsis replaces the removed part of a truncated value
+const Ellipsis = "…"
+
+// MiddleTruncate shortens s to at most width cells by replacing its middle
+// with an ellipsis. Values that already fit are returned unchanged.
+func MiddleTruncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	if width == 1 {
+		return Ellipsis
+	}
+
+	runes := []rune(s)
+	budget := width - lipgloss.Width(Ellipsis)
+	headBudget := (budget + 1) / 2
+	tailBudget := budget - headBudget
+
+	head := takeFront(runes, headBudget)
+	tail := takeBack(runes[len(head):], tailBudget)
+	return string(head) + Ellipsis + string(tail)
+}
+
+// TruncatePath shortens a filesystem path to at most width cells, keeping the
+// final element whole when it fits so the file or directory name stays readable.
+func TruncatePath(path string, width int) string {
+	if lipgloss.Width(path) <= width {
+		return path
+	}
+
+	sep := strings.LastIndexAny(path, `/\`)
+	if sep <= 0 {
+		return MiddleTruncate(path, width)
+	}
+
+	tail := path[sep:]
+	headWidth := width - lipgloss.Width(tail) - lipgloss.Width(Ellipsis)
+	if headWidth < 1 {
+		return MiddleTruncate(path, width)
+	}
+	return string(takeFront([]rune(path[:sep]), headWidth)) + Ellipsis + tail
+}
+
+// TruncateURL shortens a URL to at most width cells, keeping the scheme and
+// host when they fit so the destination remains recognisable.
+func TruncateURL(url string, width int) string {
+	if lipgloss.Width(url) <= width {
+		return url
+	}
+
+	hostStart := 0
+	if i := strings.Index(url, "://"); i != -1 {
+		hostStart = i + len("://")
+	}
+	hostEnd := strings.Index(url[hostStart:], "/")
+	if hostEnd == -1 {
+		return MiddleTruncate(url, width)
+	}
+	prefix := url[:hostStart+hostEnd+1]
+
+	tailWidth := width - lipgloss.Width(prefix) - lipgloss.Width(Ellipsis)
+	if tailWidth < 1 {
+		return MiddleTruncate(url, width)
+	}
+	return prefix + Ellipsis + string(takeBack([]rune(url[len(prefix):]), tailWidth))
+}
+
+// HardWrap breaks s into lines of at most width cells regardless of word
+// boundaries, which suits paths and URLs that contain no spaces.
+func HardWrap(s string, width int) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+
+	var lines []string
+	runes := []rune(s)
+	for len(runes) > 0 {
+		line := takeFront(runes, width)
+		if len(line) == 0 {
+			// A single character wider than the line; emit it on its own
+			line = runes[:1]
+		}
+		lines = append(lines, string(line))
+		runes = runes[len(line):]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WithDetail fits value into width cells using truncate. If the value had to be
+// shortened, detail holds the full value wrapped to detailWidth for display on
+// its own line; otherwise detail is empty.
+//
+// Parameters:
+//   - value: Full value to display
+//   - width: Cells available for the value on its line (excluding any label)
+//   - detailWidth: Cells available for the detail line (typically the content width)
+//   - truncate: TruncatePath, TruncateURL or MiddleTruncate
+func WithDetail(value string, width, detailWidth int, truncate func(string, int) string) (short, detail string) {
+	short = truncate(value, width)
+	if short == value {
+		return value, ""
+	}
+	return short, HardWrap(value, detailWidth)
+}
+
+// takeFront returns the longest prefix of runes that fits in width cells
+func takeFront(runes []rune, width int) []rune {
+	used := 0
+	for i, r := range runes {
+		w := lipgloss.Width(string(r))
+		if used+w > width {
+			return runes[:i]
+		}
+		used += w
+	}
+	return runes
+}
+
+// takeBack returns the longest suffix of runes that fits in width cells
+func takeBack(runes []rune, width int) []rune {
+	used := 0
+	for i := len(runes) - 1; i >= 0; i-- {
+		w := lipgloss.Width(string(runes[i]))
+		if used+w > width {
+			return runes[i+1:]
+		}
+		used += w
+	}
+	return runes
+}
@@ -0,0 +1,134 @@
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestMiddleTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{name: "fits", input: "short", width: 10, want: "short"},
+		{name: "exact fit", input: "abcdef", width: 6, want: "abcdef"},
+		{name: "truncated", input: "abcdefghij", width: 7, want: "abc…hij"},
+		{name: "uneven budget", input: "abcdefghij", width: 6, want: "abc…ij"},
+		{name: "width one", input: "abcdef", width: 1, want: "…"},
+		{name: "zero width", input: "abcdef", width: 0, want: ""},
+		{name: "wide characters", input: "日本語のパス名", width: 7, want: "日…名"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MiddleTruncate(tt.input, tt.width)
+			if got != tt.want {
+				t.Errorf("MiddleTruncate(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+			}
+			if tt.width > 0 && lipgloss.Width(got) > tt.width {
+				t.Errorf("result %q is %d cells wide, limit %d", got, lipgloss.Width(got), tt.width)
+			}
+		})
+	}
+}
+
+func TestTruncatePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{name: "fits", input: "/home/user/rules", width: 20, want: "/home/user/rules"},
+		{name: "keeps last element", input: "/home/user/projects/company/rules", width: 20, want: "/home/user/pr…/rules"},
+		{name: "windows separators", input: `C:\Users\someone\Documents\rules`, width: 16, want: `C:\Users\…\rules`},
+		{name: "last element too long", input: "/tmp/a-very-long-directory-name", width: 12, want: "/tmp/a…-name"},
+		{name: "no separator", input: "a-very-long-directory-name", width: 9, want: "a-ve…name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncatePath(tt.input, tt.width)
+			if got != tt.want {
+				t.Errorf("TruncatePath(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+			}
+			if lipgloss.Width(got) > tt.width {
+				t.Errorf("result %q is %d cells wide, limit %d", got, lipgloss.Width(got), tt.width)
+			}
+		})
+	}
+}
+
+func TestTruncateURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{name: "fits", input: "https://github.com/org/rules", width: 40, want: "https://github.com/org/rules"},
+		{name: "keeps host", input: "https://github.com/organization/shared-rules.git", width: 30, want: "https://github.com/…-rules.git"},
+		{name: "host only", input: "https://a-very-long-hostname.example.com", width: 20, want: "https://a-…ample.com"},
+		{name: "host too long", input: "https://a-very-long-hostname.example.com/org/repo", width: 20, want: "https://a-…/org/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateURL(tt.input, tt.width)
+			if got != tt.want {
+				t.Errorf("TruncateURL(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+			}
+			if lipgloss.Width(got) > tt.width {
+				t.Errorf("result %q is %d cells wide, limit %d", got, lipgloss.Width(got), tt.width)
+			}
+		})
+	}
+}
+
+func TestHardWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{name: "fits", input: "/a/b", width: 10, want: "/a/b"},
+		{name: "wraps", input: "/home/user/rules", width: 6, want: "/home/\nuser/r\nules"},
+		{name: "wide characters", input: "日本語パス", width: 4, want: "日本\n語パ\nス"},
+		{name: "character wider than line", input: "日本", width: 1, want: "日\n本"},
+		{name: "zero width", input: "abc", width: 0, want: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HardWrap(tt.input, tt.width); got != tt.want {
+				t.Errorf("HardWrap(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDetail(t *testing.T) {
+	short, detail := WithDetail("/home/user/rules", 40, 40, TruncatePath)
+	if short != "/home/user/rules" || detail != "" {
+		t.Errorf("fitting value should have no detail, got %q / %q", short, detail)
+	}
+
+	long := "/home/user/projects/company/rules"
+	short, detail = WithDetail(long, 20, 12, TruncatePath)
+	if short != "/home/user/pr…/rules" {
+		t.Errorf("short = %q", short)
+	}
+	if strings.ReplaceAll(detail, "\n", "") != long {
+		t.Errorf("detail should contain the full value, got %q", detail)
+	}
+	for _, line := range strings.Split(detail, "\n") {
+		if lipgloss.Width(line) > 12 {
+			t.Errorf("detail line %q exceeds detail width", line)
+		}
+	}
+}
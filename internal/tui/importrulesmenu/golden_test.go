@@ -0,0 +1,87 @@
+package importrulesmenu
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/editors"
+	"rulem/internal/filemanager"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createGoldenModel builds an import model with a fixed selected file, so
+// views do not depend on the temp directories of the machine running tests
+func createGoldenModel(t *testing.T, width, height int) *ImportRulesModel {
+	t.Helper()
+	// No repositories: nothing is prepared or scanned
+	m := NewImportRulesModel(helpers.NewUIContext(width, height, &config.Config{}, createTestLogger()))
+	m = tuitest.Send(t, m, tea.WindowSizeMsg{Width: width, Height: height})
+
+	m.selectedFile = filemanager.FileItem{
+		Name:           "react-rules.md",
+		Path:           "/home/user/rules/react-rules.md",
+		RepositoryName: "Personal Rules",
+	}
+	m.selectedEditor = editors.GetAllEditorRuleConfigs()[0]
+	m.selectedImportMode = m.importModeList.Items()[0].(CopyMode)
+	return m
+}
+
+// TestGoldenViews renders each import screen at several terminal sizes and
+// compares it with testdata/<name>_<width>x<height>.golden. Regenerate with
+// go test ./internal/tui/importrulesmenu -run TestGoldenViews -update
+func TestGoldenViews(t *testing.T) {
+	sizes := []struct{ width, height int }{{80, 24}, {120, 40}}
+	tests := []struct {
+		name  string
+		setup func(m *ImportRulesModel) *ImportRulesModel
+	}{
+		{name: "no_repositories", setup: func(m *ImportRulesModel) *ImportRulesModel { return m }},
+		{name: "loading", setup: func(m *ImportRulesModel) *ImportRulesModel {
+			m.state = StateLoading
+			return m
+		}},
+		{name: "editor_selection", setup: func(m *ImportRulesModel) *ImportRulesModel {
+			m.state = StateEditorSelection
+			return m
+		}},
+		{name: "import_mode_selection", setup: func(m *ImportRulesModel) *ImportRulesModel {
+			m.state = StateImportModeSelection
+			return m
+		}},
+		{name: "confirmation", setup: func(m *ImportRulesModel) *ImportRulesModel {
+			m.state = StateConfirmation
+			return m
+		}},
+		{name: "confirm_overwrite", setup: func(m *ImportRulesModel) *ImportRulesModel {
+			m.state = StateConfirmation
+			m.isOverwriteError = true
+			return m
+		}},
+		{name: "success", setup: func(m *ImportRulesModel) *ImportRulesModel {
+			m.state = StateSuccess
+			m.finalDestPath = m.selectedEditor.GenerateRuleFileFullPath(m.selectedFile.Name)
+			return m
+		}},
+		{name: "import_error", setup: func(m *ImportRulesModel) *ImportRulesModel {
+			m.state = StateError
+			m.err = errors.New("failed to create symlink: operation not permitted")
+			return m
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, size := range sizes {
+			name := fmt.Sprintf("%s_%dx%d", tt.name, size.width, size.height)
+			t.Run(name, func(t *testing.T) {
+				m := tt.setup(createGoldenModel(t, size.width, size.height))
+				tuitest.Golden(t, name, m.View())
+			})
+		}
+	}
+}
@@ -2,20 +2,27 @@ package importrulesmenu
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"rulem/internal/config"
+	"rulem/internal/deploy"
 	"rulem/internal/editors"
 	"rulem/internal/filemanager"
 	"rulem/internal/logging"
+	"rulem/internal/mcp"
+	"rulem/internal/notes"
+	"rulem/internal/project"
 	"rulem/internal/repository"
 	"rulem/internal/tui/components"
 	"rulem/internal/tui/components/filepicker"
 	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/helpers/textutil"
 	"rulem/internal/tui/styles"
-	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // Key event constants
@@ -28,6 +35,7 @@ const (
 	KeyRetry  = "r"
 	KeyMenu   = "m"
 	KeyAgain  = "a"
+	KeyRescan = "ctrl+r"
 )
 
 type CopyModeOption int
@@ -106,9 +114,21 @@ type ImportRulesModel struct {
 	// Multi-repository support (T009)
 	preparedRepos []repository.PreparedRepository // All prepared repositories
 
+	// Prepared repositories and scans kept between visits; nil caches nothing
+	cache *helpers.ScreenCache
+
+	// processor reads the license of the selected rule; nil when it could not be created
+	processor *mcp.RuleFileProcessor
+
+	// config and notes let the file picker show the user's rule notes and ratings
+	config *config.Config
+	notes  *notes.Store
+
 	// Data
 	ruleFiles        []filemanager.FileItem // List of markdown files found across all repositories
 	selectedFile     filemanager.FileItem
+	selectedLicense  project.RuleLicense
+	licenseWarning   string // Why the selected rule's license breaks the project's policy, if it does
 	finalDestPath    string // Final destination path after successful import
 	isOverwriteError bool
 
@@ -116,8 +136,13 @@ type ImportRulesModel struct {
 }
 
 func NewImportRulesModel(ctx helpers.UIContext) *ImportRulesModel {
-	// Initialize editors list (this will be constant)
-	editorsSlice := editors.GetAllEditorRuleConfigs()
+	// Initialize editors list (this will be constant); an invalid
+	// deploy_targets leaves the built-in editors
+	editorsSlice, err := deploy.Profiles(ctx.Config)
+	if err != nil {
+		ctx.Logger.Warn("Ignoring configured deploy targets", "error", err)
+		editorsSlice = editors.GetAllEditorRuleConfigs()
+	}
 	editors := make([]list.Item, len(editorsSlice))
 	for i, editor := range editorsSlice {
 		editors[i] = editor
@@ -165,7 +190,7 @@ func NewImportRulesModel(ctx helpers.UIContext) *ImportRulesModel {
 	s.Spinner = spinner.Pulse
 
 	// T009: Prepare all repositories using multi-repository orchestration.
-	prepared, err := repository.PrepareAllRepositories(context.Background(), ctx.Config.Repositories, ctx.Logger)
+	prepared, err := ctx.Cache.PrepareRepositories(ctx.Config, ctx.Logger)
 	if err != nil {
 		ctx.Logger.Error("Failed to prepare repositories", "error", err)
 		return &ImportRulesModel{
@@ -227,6 +252,14 @@ func NewImportRulesModel(ctx helpers.UIContext) *ImportRulesModel {
 		}
 	}
 
+	var processor *mcp.RuleFileProcessor
+	if ctx.Config != nil {
+		var err error
+		if processor, err = mcp.NewRuleFileProcessorForRepositories(ctx.Config, available, ctx.Logger); err != nil {
+			ctx.Logger.Warn("Rule licenses will not be checked", "error", err)
+		}
+	}
+
 	return &ImportRulesModel{
 		logger:           ctx.Logger,
 		windowWidth:      ctx.Width,
@@ -238,6 +271,10 @@ func NewImportRulesModel(ctx helpers.UIContext) *ImportRulesModel {
 		importModeList:   importModeList,
 		editorList:       editorsList,
 		preparedRepos:    available,
+		cache:            ctx.Cache,
+		processor:        processor,
+		config:           ctx.Config,
+		notes:            ctx.Notes,
 		ruleFiles:        nil, // will be populated after scan
 		selectedFile:     filemanager.FileItem{},
 		isOverwriteError: false,
@@ -314,13 +351,14 @@ func (m *ImportRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Build FilePicker once files are available, using the last known
 		// terminal dimensions. Bubble Tea only sends WindowSizeMsg on startup
 		// and real resizes, so the picker must be born with correct sizes.
-		ctx := helpers.NewUIContext(m.windowWidth, m.windowHeight, nil, m.logger)
+		ctx := helpers.NewUIContext(m.windowWidth, m.windowHeight, m.config, m.logger)
+		ctx.Notes = m.notes
 		m.logger.Debug("Import rules model - Creating FilePicker", "width", m.windowWidth, "height", m.windowHeight)
 
 		// Files now have repository metadata (RepositoryName, RepositoryType) for subtitle display
 		fp := filepicker.NewFilePicker(
 			"📄  Import rules",
-			"Select a rule file to import from your central rules repository (press Enter). \nUse / to filter, arrows to navigate, g to toggle formatting.",
+			"Select a rule file to import from your central rules repository (press Enter). \nUse / to filter, arrows to navigate, g to toggle formatting, ctrl+r to rescan.",
 			m.ruleFiles,
 			ctx,
 		)
@@ -344,6 +382,7 @@ func (m *ImportRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case filepicker.FileSelectedMsg:
 		m.logger.Debug("Import rules model - File selected from picker", "path", message.File.Path)
 		m.selectedFile = message.File
+		m.checkSelectedLicense()
 		m.state = StateEditorSelection
 		return m, nil
 
@@ -352,6 +391,8 @@ func (m *ImportRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.finalDestPath = message.DestPath
 		m.state = StateSuccess
 		m.err = nil
+		// The imported rule is not in the cached scan of the project yet
+		m.cache.InvalidateFiles(helpers.ScanWorkingDir)
 		return m, nil
 
 	case ImportFileErrorMsg:
@@ -396,8 +437,13 @@ func (m *ImportRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch m.state {
 		case StateFileSelection:
-			if message.String() == KeyQuit || message.String() == KeyEscape {
+			switch message.String() {
+			case KeyQuit:
 				return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+			case KeyEscape:
+				return m, helpers.NavigateBack
+			case KeyRescan:
+				return m, helpers.RefreshScreen
 			}
 
 			if m.filePicker != nil {
@@ -514,7 +560,7 @@ func (m *ImportRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.spinner.Tick,
 				)
 			case KeyEscape:
-				return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+				return m, helpers.NavigateBack
 			}
 			return m, nil
 
@@ -578,9 +624,52 @@ func (m *ImportRulesModel) validateImportModeSelection() bool {
 	return true
 }
 
+// checkSelectedLicense reads the selected rule's license and attribution and
+// checks the license against the allowed-license policy of the current
+// project. Problems are logged: the policy warns rather than blocking imports.
+func (m *ImportRulesModel) checkSelectedLicense() {
+	m.selectedLicense = project.RuleLicense{}
+	m.licenseWarning = ""
+	if m.processor == nil {
+		return
+	}
+
+	license, err := project.ReadRuleLicense(m.selectedFile.Path, m.processor)
+	if err != nil {
+		m.logger.Warn("Failed to read rule license", "path", m.selectedFile.Path, "error", err)
+		return
+	}
+	m.selectedLicense = license
+
+	if m.licenseWarning, err = project.LicenseWarning(".", license.License); err != nil {
+		m.logger.Warn("Failed to check project license policy", "error", err)
+	}
+	if m.licenseWarning != "" {
+		m.logger.Warn("Rule license does not meet project policy", "path", m.selectedFile.Path, "warning", m.licenseWarning)
+	}
+}
+
+// licenseLines describes the selected rule's license and attribution, followed
+// by any policy warning
+func (m *ImportRulesModel) licenseLines() string {
+	var content string
+	if m.selectedLicense.License != "" {
+		content += fmt.Sprintf("License: %s\n", m.selectedLicense.License)
+	}
+	if m.selectedLicense.Attribution != "" {
+		content += fmt.Sprintf("Attribution: %s\n", m.selectedLicense.Attribution)
+	}
+	if m.licenseWarning != "" {
+		content += styles.ErrorStyle.Render("⚠️ "+m.licenseWarning) + "\n"
+	}
+	return content
+}
+
 // resetSelectionState resets selection-related state for importing another file
 func (m *ImportRulesModel) resetSelectionState() {
 	m.selectedFile = filemanager.FileItem{}
+	m.selectedLicense = project.RuleLicense{}
+	m.licenseWarning = ""
 	m.selectedEditor = editors.EditorRuleConfig{}
 	m.selectedImportMode = CopyMode{}
 }
@@ -669,9 +758,10 @@ func (m *ImportRulesModel) viewConfirmation() string {
 	destPath := m.selectedEditor.GenerateRuleFileFullPath(m.selectedFile.Name)
 
 	content := fmt.Sprintf("Source File: %s\n", m.selectedFile.Name)
-	content += fmt.Sprintf("Destination: %s\n", destPath)
+	content += fmt.Sprintf("Destination: %s\n", textutil.TruncatePath(destPath, m.layout.ContentWidth()-lipgloss.Width("Destination: ")))
 	content += fmt.Sprintf("Editor: %s\n", m.selectedEditor.Name)
-	content += fmt.Sprintf("Import Mode: %s\n\n", m.selectedImportMode.title)
+	content += fmt.Sprintf("Import Mode: %s\n", m.selectedImportMode.title)
+	content += m.licenseLines() + "\n"
 
 	if m.isOverwriteError {
 		content += "A file with this name already exists in the current directory.\n\n"
@@ -714,9 +804,10 @@ func (m *ImportRulesModel) viewSuccess() string {
 
 	content := "✅ File imported successfully!\n\n"
 	content += fmt.Sprintf("Source: %s\n", m.selectedFile.Name)
-	content += fmt.Sprintf("Destination: %s\n", m.finalDestPath)
+	content += fmt.Sprintf("Destination: %s\n", textutil.TruncatePath(m.finalDestPath, m.layout.ContentWidth()-lipgloss.Width("Destination: ")))
 	content += fmt.Sprintf("Editor: %s\n", m.selectedEditor.Name)
-	content += fmt.Sprintf("Import Mode: %s\n\n", m.selectedImportMode.title)
+	content += fmt.Sprintf("Import Mode: %s\n", m.selectedImportMode.title)
+	content += m.licenseLines() + "\n"
 	content += fmt.Sprintf("The file has been %s to your current working directory.", actionText)
 	return m.layout.Render(content)
 }
@@ -741,12 +832,17 @@ func (m *ImportRulesModel) viewError() string {
 func (m *ImportRulesModel) scanForFilesCmd() tea.Cmd {
 	m.logger.Debug("Import rules - File scan started for all repositories", "repo_count", len(m.preparedRepos))
 	return func() tea.Msg {
+		if files, ok := m.cache.Files(helpers.ScanRepositories); ok {
+			return FileScanCompleteMsg{Files: files}
+		}
+
 		// T009: Scan all prepared repositories using ScanAllRepositories
 		files, err := filemanager.ScanAllRepositories(m.preparedRepos, m.logger)
 		if err != nil {
 			m.logger.Error("Import rules - File scan failed", "error", err)
 			return FileScanErrorMsg{Err: err}
 		}
+		m.cache.StoreFiles(helpers.ScanRepositories, files)
 		// Files already have absolute paths from ScanAllRepositories
 		return FileScanCompleteMsg{Files: files}
 	}
@@ -764,54 +860,42 @@ func (m *ImportRulesModel) saveFileCmd(overwrite bool) tea.Cmd {
 
 		// T009: Find the source repository to create FileManager for copy/link operations
 		// The file's RepositoryID tells us which repository it came from
-		var sourceRepoPath string
-		for _, prep := range m.preparedRepos {
+		var sourceRepo *repository.PreparedRepository
+		for i, prep := range m.preparedRepos {
 			if prep.ID() == m.selectedFile.RepositoryID {
-				sourceRepoPath = prep.LocalPath
+				sourceRepo = &m.preparedRepos[i]
 				break
 			}
 		}
 
-		if sourceRepoPath == "" {
+		if sourceRepo == nil {
 			// Fallback: use the first repository (for single-repo compatibility)
 			if len(m.preparedRepos) > 0 {
-				sourceRepoPath = m.preparedRepos[0].LocalPath
+				sourceRepo = &m.preparedRepos[0]
 			} else {
 				return ImportFileErrorMsg{Err: fmt.Errorf("no repository found for file: %s", m.selectedFile.Name), IsOverwriteError: false}
 			}
 		}
 
-		// Create FileManager for the source repository
-		fm, err := filemanager.NewFileManager(sourceRepoPath, m.logger)
-		if err != nil {
-			return ImportFileErrorMsg{Err: fmt.Errorf("failed to access source repository: %w", err), IsOverwriteError: false}
+		mode := project.ModeCopy
+		if m.selectedImportMode.copyMode == CopyModeOptionLink {
+			mode = project.ModeLink
 		}
 
-		var finalDestPath string
-		switch m.selectedImportMode.copyMode {
-		case CopyModeOptionCopy:
-			// Copy the file to the current working directory
-			m.logger.Debug("Calling CopyFileFromStorage", "storagePath", storagePath, "destFilePath", destFilePath)
-			finalDestPath, err = fm.CopyFileFromStorage(storagePath, destFilePath, overwrite)
-			if err != nil {
-				m.logger.Error("Failed to copy file from storage", "error", err, "storagePath", storagePath, "destFilePath", destFilePath)
-				isOverwriteError := strings.Contains(err.Error(), "already exists")
-				return ImportFileErrorMsg{Err: err, IsOverwriteError: isOverwriteError}
-			}
-			m.logger.Info("File copied successfully", "dest", finalDestPath)
-
-		case CopyModeOptionLink:
-			// Create a symbolic link to the file in the current working directory
-			m.logger.Debug("Calling CreateSymlinkFromStorage", "storagePath", storagePath, "destFilePath", destFilePath)
-			finalDestPath, err = fm.CreateSymlinkFromStorage(storagePath, destFilePath, overwrite)
-			if err != nil {
-				m.logger.Error("Failed to create symlink from storage", "error", err, "storagePath", storagePath, "destFilePath", destFilePath)
-				isOverwriteError := strings.Contains(err.Error(), "already exists")
-				return ImportFileErrorMsg{Err: err, IsOverwriteError: isOverwriteError}
-			}
-			m.logger.Info("Symlink created successfully", "dest", finalDestPath)
-
+		// The deploy package runs the pre-deploy hooks, which may veto the import,
+		// records the rule in the project manifest and, for VS Code editors, adds
+		// a snippet
+		var configuredHooks []config.Hook
+		if m.config != nil {
+			configuredHooks = m.config.Hooks
+		}
+		target := deploy.Target{Source: storagePath, Repository: *sourceRepo, Dest: destFilePath, Frontmatter: m.selectedEditor.Frontmatter, VSCodeSnippet: m.selectedEditor.VSCodeSnippets}
+		opts := deploy.Options{Mode: mode, Overwrite: overwrite, Hooks: configuredHooks}
+		paths, err := deploy.Deploy(context.Background(), []deploy.Target{target}, opts, m.logger)
+		if err != nil {
+			m.logger.Error("Failed to import rule", "error", err, "storagePath", storagePath, "destFilePath", destFilePath)
+			return ImportFileErrorMsg{Err: err, IsOverwriteError: errors.Is(err, deploy.ErrDestinationExists)}
 		}
-		return ImportFileCompleteMsg{DestPath: finalDestPath}
+		return ImportFileCompleteMsg{DestPath: paths[0]}
 	}
 }
@@ -5,13 +5,16 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"rulem/internal/config"
 	"rulem/internal/editors"
 	"rulem/internal/filemanager"
 	"rulem/internal/logging"
+	"rulem/internal/project"
 	"rulem/internal/repository"
 	"rulem/internal/tui/components/filepicker"
 	"rulem/internal/tui/helpers"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -170,7 +173,7 @@ func TestNewImportRulesModel(t *testing.T) {
 	if model.selectedFile != (filemanager.FileItem{}) {
 		t.Error("SelectedFile should be empty initially")
 	}
-	if model.selectedEditor != (editors.EditorRuleConfig{}) {
+	if !reflect.DeepEqual(model.selectedEditor, editors.EditorRuleConfig{}) {
 		t.Error("SelectedEditor should be empty initially")
 	}
 	if model.selectedImportMode != (CopyMode{}) {
@@ -251,7 +254,7 @@ func TestNewImportRulesModel_EditorListInitialization(t *testing.T) {
 			t.Errorf("Item %d should be EditorRuleConfig", i)
 			continue
 		}
-		if editor != editorConfigs[i] {
+		if !reflect.DeepEqual(editor, editorConfigs[i]) {
 			t.Errorf("Editor %d mismatch", i)
 		}
 	}
@@ -357,6 +360,50 @@ func TestImportRulesModel_FileSelectedMsg(t *testing.T) {
 	}
 }
 
+func TestImportRulesModel_FileSelectedMsg_LicensePolicy(t *testing.T) {
+	model, files := createTestModelWithFiles(t)
+	model.state = StateFileSelection
+
+	// The working directory is the project; only MIT rules are allowed in it
+	policy := &project.Manifest{AllowedLicenses: []string{"MIT"}}
+	if err := policy.Save("."); err != nil {
+		t.Fatalf("Failed to save project manifest: %v", err)
+	}
+	rule := files[0]
+	content := "---\ndescription: ESLint\nlicense: GPL-3.0\nattribution: Jane Doe\n---\n# ESLint Rules\n"
+	if err := os.WriteFile(rule.Path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write rule: %v", err)
+	}
+
+	model.Update(filepicker.FileSelectedMsg{File: rule})
+
+	if model.selectedLicense != (project.RuleLicense{License: "GPL-3.0", Attribution: "Jane Doe"}) {
+		t.Errorf("Unexpected license %+v", model.selectedLicense)
+	}
+	wantWarning := "license GPL-3.0 is not allowed in this project; it allows MIT"
+	if model.licenseWarning != wantWarning {
+		t.Errorf("Expected warning %q, got %q", wantWarning, model.licenseWarning)
+	}
+
+	model.state = StateConfirmation
+	view := model.View()
+	for _, want := range []string{"License: GPL-3.0", "Attribution: Jane Doe", "is not allowed in this project"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Confirmation view should contain %q", want)
+		}
+	}
+
+	// An allowed license clears the warning
+	allowed := files[1]
+	if err := os.WriteFile(allowed.Path, []byte("---\ndescription: Prettier\nlicense: mit\n---\n"), 0644); err != nil {
+		t.Fatalf("Failed to write rule: %v", err)
+	}
+	model.Update(filepicker.FileSelectedMsg{File: allowed})
+	if model.licenseWarning != "" {
+		t.Errorf("Expected no warning, got %q", model.licenseWarning)
+	}
+}
+
 func TestImportRulesModel_ImportFileCompleteMsg(t *testing.T) {
 	model := createTestModel(t)
 	model.state = StateImporting
@@ -496,7 +543,7 @@ func TestImportRulesModel_KeyHandling_FileSelection(t *testing.T) {
 		cmdContains string
 	}{
 		{"quit key", KeyQuit, true, "NavigateToMainMenuMsg"},
-		{"escape key", KeyEscape, true, "NavigateToMainMenuMsg"},
+		{"escape key", KeyEscape, true, "NavigateBackMsg"},
 		{"other key", "j", true, ""}, // FilePicker may return commands for navigation keys
 	}
 
@@ -583,7 +630,7 @@ func TestImportRulesModel_KeyHandling_EditorSelection_Enter(t *testing.T) {
 		if result.state != StateImportModeSelection {
 			t.Errorf("Expected state %v, got %v", StateImportModeSelection, result.state)
 		}
-		if result.selectedEditor != editorConfigs[0] {
+		if !reflect.DeepEqual(result.selectedEditor, editorConfigs[0]) {
 			t.Error("Selected editor should match")
 		}
 		if cmd != nil {
@@ -783,7 +830,7 @@ func TestImportRulesModel_KeyHandling_Success(t *testing.T) {
 				if result.selectedFile != (filemanager.FileItem{}) {
 					t.Error("Selected file should be reset")
 				}
-				if result.selectedEditor != (editors.EditorRuleConfig{}) {
+				if !reflect.DeepEqual(result.selectedEditor, editors.EditorRuleConfig{}) {
 					t.Error("Selected editor should be reset")
 				}
 				if result.selectedImportMode != (CopyMode{}) {
@@ -886,7 +933,7 @@ func TestImportRulesModel_ResetSelectionState(t *testing.T) {
 	if model.selectedFile != (filemanager.FileItem{}) {
 		t.Error("Selected file should be reset")
 	}
-	if model.selectedEditor != (editors.EditorRuleConfig{}) {
+	if !reflect.DeepEqual(model.selectedEditor, editors.EditorRuleConfig{}) {
 		t.Error("Selected editor should be reset")
 	}
 	if model.selectedImportMode != (CopyMode{}) {
@@ -1051,6 +1098,43 @@ func TestImportRulesModel_SaveFileCmd_OverwriteError(t *testing.T) {
 	}
 }
 
+func TestImportRulesModel_SaveFileCmd_Hooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks use a POSIX shell")
+	}
+	model, files := createTestModelWithFiles(t)
+	model.selectedFile = files[0]
+	model.selectedEditor = editors.GetAllEditorRuleConfigs()[0]
+	model.selectedImportMode = CopyMode{copyMode: CopyModeOptionCopy}
+	destPath := model.selectedEditor.GenerateRuleFileFullPath(model.selectedFile.Name)
+
+	// A failing pre-deploy hook cancels the import
+	model.config = &config.Config{Hooks: []config.Hook{{Event: "pre-deploy", Command: "echo not approved >&2; exit 1"}}}
+	errorMsg, ok := model.saveFileCmd(false)().(ImportFileErrorMsg)
+	if !ok || !strings.Contains(errorMsg.Err.Error(), "cancelled by hook") || !strings.Contains(errorMsg.Err.Error(), "not approved") {
+		t.Fatalf("Expected the import to be cancelled by the hook, got %+v", errorMsg)
+	}
+	if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+		t.Errorf("Cancelled import should not write %s", destPath)
+	}
+
+	// Post-deploy hooks receive the deployed rule
+	payloadPath := filepath.Join(t.TempDir(), "payload.json")
+	model.config = &config.Config{Hooks: []config.Hook{{Event: "post-deploy", Command: "cat > " + payloadPath}}}
+	if msg, ok := model.saveFileCmd(false)().(ImportFileCompleteMsg); !ok {
+		t.Fatalf("Expected ImportFileCompleteMsg, got %+v", msg)
+	}
+	payload, err := os.ReadFile(payloadPath)
+	if err != nil {
+		t.Fatalf("post-deploy hook did not run: %v", err)
+	}
+	for _, want := range []string{`"event":"post-deploy"`, `"rule":"` + model.selectedFile.Name + `"`, `"mode":"copy"`} {
+		if !strings.Contains(string(payload), want) {
+			t.Errorf("payload %s should contain %s", payload, want)
+		}
+	}
+}
+
 // Test CopyMode methods
 
 func TestCopyMode_Methods(t *testing.T) {
@@ -1275,7 +1359,7 @@ func TestImportRulesModel_CompleteWorkflow(t *testing.T) {
 		if model.state != StateImportModeSelection {
 			t.Errorf("Expected state %v, got %v", StateImportModeSelection, model.state)
 		}
-		if model.selectedEditor != editorConfigs[0] {
+		if !reflect.DeepEqual(model.selectedEditor, editorConfigs[0]) {
 			t.Error("Selected editor should match")
 		}
 	}
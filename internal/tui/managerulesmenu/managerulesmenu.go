@@ -0,0 +1,440 @@
+// Package managerulesmenu implements the "Manage rules" screen.
+//
+// It lists the rule files of every available repository by their path in the
+// repository, and moves, renames or deletes the selected one with the file
+// manager's MoveRuleFile and DeleteRuleFile, so a repository can be
+// reorganized without a shell. Deleted rules are moved to the trash unless
+// permanent_delete is set. Rules that only exist in shared storage are
+// read-only: the file manager refuses to change them.
+package managerulesmenu
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/helpers/textutil"
+	"rulem/internal/tui/styles"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type menuState int
+
+const (
+	stateLoading       menuState = iota
+	stateList                    // Choosing a rule and an action
+	stateMove                    // Entering the new path of the selected rule
+	stateConfirmDelete           // Confirming the deletion of the selected rule
+)
+
+type (
+	// loadedMsg carries the prepared repositories and their rule files
+	loadedMsg struct {
+		prepared []repository.PreparedRepository
+		files    []filemanager.FileItem
+		err      error
+	}
+
+	// doneMsg reports the outcome of a move or a delete
+	doneMsg struct {
+		status string
+		err    error
+	}
+)
+
+// ruleItem is a rule file listed by its path relative to its repository
+type ruleItem struct {
+	file filemanager.FileItem
+	rel  string
+}
+
+func (i ruleItem) Title() string       { return i.rel }
+func (i ruleItem) Description() string { return i.file.Description() }
+func (i ruleItem) FilterValue() string { return i.rel + " " + i.file.RepositoryName }
+
+// ManageRulesModel is the Bubble Tea model for the manage rules screen.
+type ManageRulesModel struct {
+	logger  *logging.AppLogger
+	layout  components.LayoutModel
+	spinner spinner.Model
+	rules   list.Model
+	input   textinput.Model
+	cfg     *config.Config
+	cache   *helpers.ScreenCache // nil caches nothing
+
+	state    menuState
+	prepared []repository.PreparedRepository
+	selected ruleItem
+	status   string // Outcome of the last move or delete
+}
+
+// NewManageRulesModel creates the manage rules screen model from the shared UI context.
+func NewManageRulesModel(ctx helpers.UIContext) *ManageRulesModel {
+	layout := components.NewLayout(components.LayoutConfig{
+		MarginX:  2,
+		MarginY:  1,
+		MaxWidth: 100,
+	})
+	if ctx.HasValidDimensions() {
+		layout, _ = layout.Update(tea.WindowSizeMsg{Width: ctx.Width, Height: ctx.Height})
+	}
+
+	s := spinner.New()
+	s.Style = styles.SpinnerStyle
+	s.Spinner = spinner.Pulse
+
+	rules := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	rules.SetShowTitle(false)
+	rules.SetShowStatusBar(false)
+	rules.SetFilteringEnabled(true)
+	rules.SetShowHelp(false) // We'll use the layout for help
+
+	input := textinput.New()
+	input.Placeholder = "folder/rule.md"
+	input.CharLimit = 255
+	input.Width = 50
+
+	m := &ManageRulesModel{
+		logger:  ctx.Logger,
+		layout:  layout,
+		spinner: s,
+		rules:   rules,
+		input:   input,
+		cfg:     ctx.Config,
+		cache:   ctx.Cache,
+		state:   stateLoading,
+	}
+	m.resizeList()
+	return m
+}
+
+// Init prepares the repositories and scans them for rules.
+func (m *ManageRulesModel) Init() tea.Cmd {
+	return tea.Batch(m.loadCmd(), m.spinner.Tick)
+}
+
+// Update handles loaded rules, finished moves and deletes, and key presses.
+func (m *ManageRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m.layout, _ = m.layout.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resizeList()
+		return m, nil
+
+	case loadedMsg:
+		m.state = stateList
+		if msg.err != nil {
+			m.logger.Error("Failed to load rules", "error", msg.err)
+			m.layout = m.layout.SetError(msg.err)
+			return m, nil
+		}
+		m.prepared = msg.prepared
+		return m, m.rules.SetItems(ruleItems(msg.prepared, msg.files))
+
+	case doneMsg:
+		m.state = stateList
+		if msg.err != nil {
+			m.logger.Warn("Rule file operation failed", "error", msg.err)
+			m.status = ""
+			m.layout = m.layout.SetError(msg.err)
+			return m, nil
+		}
+		m.layout = m.layout.ClearError()
+		m.status = msg.status
+		// Every cached scan may list the rule at its old path
+		m.cache.InvalidateFiles()
+		return m, m.scanCmd()
+
+	case spinner.TickMsg:
+		if m.state == stateLoading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.state {
+		case stateList:
+			return m.updateList(msg)
+		case stateMove:
+			return m.updateMove(msg)
+		case stateConfirmDelete:
+			return m.updateConfirmDelete(msg)
+		}
+		switch msg.String() {
+		case "q":
+			return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+		case "esc":
+			return m, helpers.NavigateBack
+		}
+	}
+
+	return m, nil
+}
+
+// updateList handles the keys of the rule list; while the list is filtered
+// they are typed into the filter
+func (m *ManageRulesModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.rules.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		m.rules, cmd = m.rules.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+	case "esc":
+		return m, helpers.NavigateBack
+	case "ctrl+r":
+		return m, helpers.RefreshScreen
+	case "enter", "m":
+		if item, ok := m.rules.SelectedItem().(ruleItem); ok {
+			m.logger.LogUserAction("manage_rules_move", item.file.Path)
+			m.selected = item
+			m.state = stateMove
+			m.layout = m.layout.ClearError()
+			m.input.SetValue(item.rel)
+			m.input.CursorEnd()
+			m.input.Focus()
+			return m, textinput.Blink
+		}
+		return m, nil
+	case "d":
+		if item, ok := m.rules.SelectedItem().(ruleItem); ok {
+			m.logger.LogUserAction("manage_rules_delete", item.file.Path)
+			m.selected = item
+			m.state = stateConfirmDelete
+			m.layout = m.layout.ClearError()
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.rules, cmd = m.rules.Update(msg)
+	return m, cmd
+}
+
+// updateMove handles the keys of the new path input
+func (m *ManageRulesModel) updateMove(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.input.Blur()
+		m.state = stateList
+		return m, nil
+	case "enter":
+		newPath := strings.TrimSpace(m.input.Value())
+		if newPath == "" || newPath == m.selected.rel {
+			m.input.Blur()
+			m.state = stateList
+			return m, nil
+		}
+		m.input.Blur()
+		m.state = stateLoading
+		return m, tea.Batch(m.moveCmd(newPath), m.spinner.Tick)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// updateConfirmDelete handles the keys of the delete confirmation
+func (m *ManageRulesModel) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.state = stateLoading
+		return m, tea.Batch(m.deleteCmd(), m.spinner.Tick)
+	case "n", "N", "esc":
+		m.state = stateList
+	}
+	return m, nil
+}
+
+// View renders the current state of the screen.
+func (m *ManageRulesModel) View() string {
+	switch m.state {
+	case stateMove:
+		return m.viewMove()
+	case stateConfirmDelete:
+		return m.viewConfirmDelete()
+	}
+
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📁 Manage Rules",
+		Subtitle: m.subtitle(),
+		HelpText: "↑/↓ navigate • enter/m move or rename • d delete • / filter • ctrl+r rescan • q/esc back",
+	})
+	if m.state == stateLoading {
+		return m.layout.Render(fmt.Sprintf("%s Loading rules...", m.spinner.View()))
+	}
+	if len(m.rules.Items()) == 0 {
+		return m.layout.Render("No rule files found.")
+	}
+	return m.layout.Render(m.rules.View())
+}
+
+func (m *ManageRulesModel) viewMove() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📁 Manage Rules - Move or Rename",
+		Subtitle: "Repository: " + m.selected.file.RepositoryName,
+		HelpText: "Enter to move • Esc to cancel",
+	})
+
+	rel := textutil.TruncatePath(m.selected.rel, m.layout.ContentWidth()-lipgloss.Width("New path of , relative to the repository root."))
+	content := fmt.Sprintf("New path of %s, relative to the repository root.\n", rel)
+	content += "Missing folders are created.\n\n"
+	content += m.input.View()
+	return m.layout.Render(content)
+}
+
+func (m *ManageRulesModel) viewConfirmDelete() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "⚠️  Confirm Rule Deletion",
+		Subtitle: "Repository: " + m.selected.file.RepositoryName,
+		HelpText: "y to delete • n/Esc to cancel",
+	})
+
+	content := fmt.Sprintf("Delete %s?\n\n", textutil.TruncatePath(m.selected.rel, m.layout.ContentWidth()-lipgloss.Width("Delete ?")))
+	if m.cfg != nil && m.cfg.PermanentDelete {
+		content += styles.ErrorStyle.Render("It will be deleted for good (permanent_delete is set).")
+	} else {
+		// The trash is where the rule is restored from, so its path is shown whole
+		content += "It will be moved to the trash, where it can be restored by hand:\n" + textutil.HardWrap(filemanager.TrashPath(), m.layout.ContentWidth())
+	}
+	return m.layout.Render(content)
+}
+
+func (m *ManageRulesModel) subtitle() string {
+	if m.status != "" {
+		return styles.SuccessStyle.Render("✅ " + m.status)
+	}
+	return "Move, rename or delete the rules in your repositories."
+}
+
+// resizeList fits the rule list below the subtitle
+func (m *ManageRulesModel) resizeList() {
+	m.rules.SetSize(m.layout.ContentWidth(), max(m.layout.ContentHeight()-3, 3))
+}
+
+// ruleItems lists files by their path relative to the root of their
+// repository, the overlay for files in a shared storage overlay
+func ruleItems(prepared []repository.PreparedRepository, files []filemanager.FileItem) []list.Item {
+	roots := make(map[string][]string, len(prepared))
+	for _, prep := range prepared {
+		roots[prep.ID()] = []string{prep.OverlayPath, prep.LocalPath}
+	}
+
+	items := make([]list.Item, 0, len(files))
+	for _, file := range files {
+		rel := file.Name
+		for _, root := range roots[file.RepositoryID] {
+			if root == "" {
+				continue
+			}
+			if r, err := filepath.Rel(root, file.Path); err == nil && !strings.HasPrefix(r, "..") {
+				rel = filepath.ToSlash(r)
+				break
+			}
+		}
+		items = append(items, ruleItem{file: file, rel: rel})
+	}
+	return items
+}
+
+// fileManager returns the file manager of the repository file is in
+func (m *ManageRulesModel) fileManager(file filemanager.FileItem) (*filemanager.FileManager, error) {
+	for _, prep := range m.prepared {
+		if prep.ID() == file.RepositoryID {
+			return filemanager.NewRepositoryFileManager(prep, m.logger)
+		}
+	}
+	return nil, fmt.Errorf("repository of %s is no longer available", file.Name)
+}
+
+func (m *ManageRulesModel) loadCmd() tea.Cmd {
+	cfg := m.cfg
+	logger := m.logger
+	cache := m.cache
+	return func() tea.Msg {
+		if cfg == nil {
+			return loadedMsg{err: fmt.Errorf("configuration is not loaded")}
+		}
+		prepared, err := cache.PrepareRepositories(cfg, logger)
+		if err != nil {
+			return loadedMsg{err: fmt.Errorf("repository preparation failed: %w", err)}
+		}
+		available := repository.AvailableRepositories(prepared)
+		if len(available) == 0 {
+			return loadedMsg{err: fmt.Errorf("no repositories available - please run setup first")}
+		}
+		if files, ok := cache.Files(helpers.ScanRepositories); ok {
+			return loadedMsg{prepared: available, files: files}
+		}
+		files, err := filemanager.ScanAllRepositories(available, logger)
+		if err == nil {
+			cache.StoreFiles(helpers.ScanRepositories, files)
+		}
+		return loadedMsg{prepared: available, files: files, err: err}
+	}
+}
+
+// scanCmd lists the rules again after a change, without preparing the
+// repositories again
+func (m *ManageRulesModel) scanCmd() tea.Cmd {
+	prepared := m.prepared
+	logger := m.logger
+	cache := m.cache
+	return func() tea.Msg {
+		files, err := filemanager.ScanAllRepositories(prepared, logger)
+		if err == nil {
+			cache.StoreFiles(helpers.ScanRepositories, files)
+		}
+		return loadedMsg{prepared: prepared, files: files, err: err}
+	}
+}
+
+func (m *ManageRulesModel) moveCmd(newPath string) tea.Cmd {
+	selected := m.selected
+	fm, err := m.fileManager(selected.file)
+	return func() tea.Msg {
+		if err != nil {
+			return doneMsg{err: err}
+		}
+		if _, err := fm.MoveRuleFile(selected.file.Path, newPath); err != nil {
+			return doneMsg{err: err}
+		}
+		return doneMsg{status: fmt.Sprintf("Moved %s to %s", selected.rel, newPath)}
+	}
+}
+
+func (m *ManageRulesModel) deleteCmd() tea.Cmd {
+	selected := m.selected
+	fm, err := m.fileManager(selected.file)
+	return func() tea.Msg {
+		if err != nil {
+			return doneMsg{err: err}
+		}
+		trashed, err := fm.DeleteRuleFile(selected.file.Path)
+		if err != nil {
+			return doneMsg{err: err}
+		}
+		if trashed == "" {
+			return doneMsg{status: fmt.Sprintf("Deleted %s", selected.rel)}
+		}
+		return doneMsg{status: fmt.Sprintf("Moved %s to the trash", selected.rel)}
+	}
+}
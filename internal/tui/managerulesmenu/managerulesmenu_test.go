@@ -0,0 +1,165 @@
+package managerulesmenu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel returns a loaded screen over a local repository holding files
+func newTestModel(t *testing.T, files map[string]string) (*ManageRulesModel, string) {
+	t.Helper()
+	t.Setenv("RULEM_TRASH_PATH", filepath.Join(t.TempDir(), "trash"))
+
+	storage := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(storage, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "rules-3f9a0c12", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: storage},
+	}}
+	logger, _ := logging.NewTestLogger()
+	m := NewManageRulesModel(helpers.NewUIContext(80, 24, cfg, logger))
+	m, _ = tuitest.Run(t, m, nil, m.loadCmd()())
+	return m, storage
+}
+
+// isLoaded keeps the messages that finish an operation, dropping list and
+// cursor commands
+func isLoaded(msg tea.Msg) bool {
+	switch msg.(type) {
+	case loadedMsg, doneMsg:
+		return true
+	}
+	return false
+}
+
+func TestManageRulesModel_ListsRulesByPath(t *testing.T) {
+	m, _ := newTestModel(t, map[string]string{"go/style.md": "# style"})
+
+	items := m.rules.Items()
+	if len(items) != 1 || items[0].(ruleItem).rel != "go/style.md" {
+		t.Fatalf("items = %+v, want go/style.md", items)
+	}
+}
+
+func TestManageRulesModel_Move(t *testing.T) {
+	m, storage := newTestModel(t, map[string]string{"style.md": "# style"})
+
+	m = tuitest.Send(t, m, tuitest.Key("m"))
+	if m.state != stateMove || m.input.Value() != "style.md" {
+		t.Fatalf("state = %v, input = %q; want the move input filled with the path", m.state, m.input.Value())
+	}
+
+	msgs := []tea.Msg{tuitest.Key("home")}
+	msgs = append(msgs, tuitest.Type("go/")...)
+	msgs = append(msgs, tuitest.Key("enter"))
+	m, _ = tuitest.Run(t, m, isLoaded, msgs...)
+
+	if _, err := os.Stat(filepath.Join(storage, "go", "style.md")); err != nil {
+		t.Fatalf("rule not moved: %v\n%s", err, m.View())
+	}
+	if !strings.Contains(m.status, "Moved style.md to go/style.md") {
+		t.Errorf("status = %q, want the move reported", m.status)
+	}
+	if items := m.rules.Items(); len(items) != 1 || items[0].(ruleItem).rel != "go/style.md" {
+		t.Errorf("items = %+v, want the list rescanned", items)
+	}
+}
+
+func TestManageRulesModel_MoveError(t *testing.T) {
+	m, storage := newTestModel(t, map[string]string{"style.md": "# style", "taken.md": "# taken"})
+
+	msgs := []tea.Msg{tuitest.Key("m")}
+	for range len("style.md") {
+		msgs = append(msgs, tuitest.Key("backspace"))
+	}
+	msgs = append(msgs, tuitest.Type("taken.md")...)
+	msgs = append(msgs, tuitest.Key("enter"))
+	m, _ = tuitest.Run(t, m, isLoaded, msgs...)
+
+	if m.state != stateList || !strings.Contains(m.View(), "already exists") {
+		t.Errorf("view does not report the existing destination:\n%s", m.View())
+	}
+	if _, err := os.Stat(filepath.Join(storage, "style.md")); err != nil {
+		t.Errorf("rule must stay in place: %v", err)
+	}
+}
+
+func TestManageRulesModel_Delete(t *testing.T) {
+	m, storage := newTestModel(t, map[string]string{"style.md": "# style"})
+
+	// Cancelling keeps the rule
+	m = tuitest.Send(t, m, tuitest.Key("d"), tuitest.Key("n"))
+	if m.state != stateList {
+		t.Fatalf("state = %v, want the list after cancelling", m.state)
+	}
+
+	m = tuitest.Send(t, m, tuitest.Key("d"))
+	if !strings.Contains(m.View(), "moved to the trash") {
+		t.Errorf("confirmation does not mention the trash:\n%s", m.View())
+	}
+	m, _ = tuitest.Run(t, m, isLoaded, tuitest.Key("y"))
+
+	if _, err := os.Stat(filepath.Join(storage, "style.md")); !os.IsNotExist(err) {
+		t.Errorf("rule not deleted: %v", err)
+	}
+	if len(m.rules.Items()) != 0 || !strings.Contains(m.status, "Moved style.md to the trash") {
+		t.Errorf("items = %+v, status = %q; want the rule gone", m.rules.Items(), m.status)
+	}
+}
+
+func TestManageRulesModel_ReusesCachedScan(t *testing.T) {
+	t.Setenv("RULEM_TRASH_PATH", filepath.Join(t.TempDir(), "trash"))
+	storage := t.TempDir()
+	if err := os.WriteFile(filepath.Join(storage, "style.md"), []byte("# style"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "rules-3f9a0c12", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: storage},
+	}}
+	logger, _ := logging.NewTestLogger()
+	ctx := helpers.NewUIContext(80, 24, cfg, logger)
+	ctx.Cache = helpers.NewScreenCache()
+
+	m := NewManageRulesModel(ctx)
+	m, _ = tuitest.Run(t, m, nil, m.loadCmd()())
+
+	// A rule added behind the screen's back is not listed until a rescan
+	if err := os.WriteFile(filepath.Join(storage, "added.md"), []byte("# added"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	m = NewManageRulesModel(ctx)
+	m, _ = tuitest.Run(t, m, nil, m.loadCmd()())
+	if items := m.rules.Items(); len(items) != 1 {
+		t.Fatalf("items = %+v, want the cached scan", items)
+	}
+
+	if _, cmd := tuitest.SendCmd(t, m, tuitest.Key("ctrl+r")); cmd == nil || cmd() != (helpers.RefreshScreenMsg{}) {
+		t.Error("ctrl+r must ask for a refresh")
+	}
+
+	// Changing a rule drops the cached scans
+	m, _ = tuitest.Run(t, m, isLoaded, tuitest.Key("d"), tuitest.Key("y"))
+	if items := m.rules.Items(); len(items) != 1 || items[0].(ruleItem).rel != "added.md" {
+		t.Errorf("items = %+v, want the rescanned rules", items)
+	}
+	if files, ok := ctx.Cache.Files(helpers.ScanRepositories); !ok || len(files) != 1 {
+		t.Errorf("cached scan = %v, %v; want the rescan cached", files, ok)
+	}
+}
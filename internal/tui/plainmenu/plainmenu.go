@@ -0,0 +1,255 @@
+// Package plainmenu provides rulem's plain text interface, used in place of the
+// TUI when stdin or stdout is not a terminal, e.g. when rulem is started from a
+// script, or when the terminal cannot display the TUI.
+//
+// It covers the essential flows with numbered prompts read line by line:
+//   - Setup: choose a local directory or a GitHub repository on first run
+//   - Save: copy a rule file from the working directory into a repository
+//   - Sync: fetch the latest rules of the GitHub repositories
+//
+// Answers can be piped in, one per line; the interface stops when the input
+// ends.
+package plainmenu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/hooks"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/setupmenu"
+	"rulem/pkg/fileops"
+)
+
+// RunSetup asks where rules are kept and saves the first configuration, as
+// the setup wizard does. Invalid answers are explained and the questions
+// asked again.
+func RunSetup(p *Prompter, ctx helpers.UIContext) error {
+	p.Printf("Welcome to rulem! Let's choose where your rules are kept.\n\n")
+	for {
+		setup := setupmenu.NewSetupModel(ctx)
+		choice, err := p.Choose("Where should your rules be stored?", []string{
+			"Local directory",
+			"GitHub repository (cloned locally)",
+		})
+		if err != nil {
+			return err
+		}
+
+		if choice == 0 {
+			err = setupLocal(p, setup)
+		} else {
+			err = setupGitHub(p, setup)
+		}
+		if errors.Is(err, ErrNoInput) {
+			return err
+		}
+		if err == nil {
+			p.Printf("Setup complete.\n\n")
+			return nil
+		}
+		ctx.Logger.Warn("Plain text setup failed", "error", err)
+		p.Printf("Setup failed: %v\nPlease try again.\n\n", err)
+	}
+}
+
+// setupLocal completes the setup with a local directory
+func setupLocal(p *Prompter, setup *setupmenu.SetupModel) error {
+	dir, err := p.Ask("Storage directory", repository.GetDefaultStorageDir())
+	if err != nil {
+		return err
+	}
+	return setup.CompleteLocal(dir)
+}
+
+// setupGitHub completes the setup with a GitHub repository
+func setupGitHub(p *Prompter, setup *setupmenu.SetupModel) error {
+	url, err := p.Ask("Repository URL (HTTPS or SSH)", "")
+	if err != nil {
+		return err
+	}
+	branch, err := p.Ask("Branch (empty for the default branch)", "")
+	if err != nil {
+		return err
+	}
+	path, err := p.Ask("Local clone path (empty to derive it from the URL)", "")
+	if err != nil {
+		return err
+	}
+	token, err := p.Ask("Personal Access Token", "")
+	if err != nil {
+		return err
+	}
+	return setup.CompleteGitHub(url, branch, path, token)
+}
+
+// Run shows the main menu until the user quits or the input ends
+func Run(p *Prompter, cfg *config.Config, logger *logging.AppLogger) error {
+	p.Printf("rulem (plain text mode)\n\n")
+	for {
+		choice, err := p.Choose("What would you like to do?", []string{
+			"Save a rule from this directory",
+			"Sync repositories",
+			"Quit",
+		})
+		if errors.Is(err, ErrNoInput) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch choice {
+		case 0:
+			err = saveRule(p, cfg, logger)
+		case 1:
+			syncRepositories(p, cfg, logger)
+		default:
+			return nil
+		}
+		if errors.Is(err, ErrNoInput) {
+			return nil
+		}
+		if err != nil {
+			logger.Warn("Plain text save failed", "error", err)
+			p.Printf("Error: %v\n", err)
+		}
+		p.Printf("\n")
+	}
+}
+
+// saveRule copies a rule file picked from the working directory into a
+// repository, asking what to do when the repository already has the file and
+// save_collision does not decide it
+func saveRule(p *Prompter, cfg *config.Config, logger *logging.AppLogger) error {
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, logger)
+	if err != nil {
+		return err
+	}
+	available := repository.AvailableRepositories(prepared)
+	if len(available) == 0 {
+		return fmt.Errorf("no repositories available - please run setup first")
+	}
+
+	scanner, err := filemanager.NewFileManager(available[0].LocalPath, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create file scanner: %w", err)
+	}
+	files, err := scanner.ScanCurrDirectory()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		p.Printf("No rule files found in this directory.\n")
+		return nil
+	}
+
+	cwd, _ := os.Getwd()
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.Path
+		if rel, err := filepath.Rel(cwd, file.Path); err == nil {
+			names[i] = rel
+		}
+	}
+	fileChoice, err := p.Choose("Which file do you want to save?", names)
+	if err != nil {
+		return err
+	}
+	file := files[fileChoice]
+
+	prep := available[0]
+	if len(available) > 1 {
+		repoNames := make([]string, len(available))
+		for i, repo := range available {
+			repoNames[i] = fmt.Sprintf("%s (%s)", repo.Name(), repo.Type())
+		}
+		repoChoice, err := p.Choose("Save it to which repository?", repoNames)
+		if err != nil {
+			return err
+		}
+		prep = available[repoChoice]
+	}
+
+	var newName *string
+	for {
+		name, err := p.Ask("File name", file.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := fileops.SanitizeFilename(name); err != nil {
+			p.Printf("Invalid file name: %v\n", err)
+			continue
+		}
+		if name != file.Name {
+			newName = &name
+		}
+		break
+	}
+
+	collision, err := cfg.SaveCollisionStrategy()
+	if err != nil {
+		logger.Warn("Ignoring save_collision setting", "error", err)
+		collision = fileops.CollisionAsk
+	}
+
+	fm, err := filemanager.NewRepositoryFileManager(prep, logger)
+	if err != nil {
+		return err
+	}
+	destPath, err := fm.SaveFileToStorage(file.Path, newName, collision)
+	if err != nil && collision == fileops.CollisionAsk && strings.Contains(err.Error(), "already exists") {
+		p.Printf("%v\n", err)
+		choice, chooseErr := p.Choose("What do you want to do?", []string{
+			"Overwrite it",
+			"Save with a numbered name",
+			"Cancel",
+		})
+		if chooseErr != nil {
+			return chooseErr
+		}
+		switch choice {
+		case 0:
+			collision = fileops.CollisionOverwrite
+		case 1:
+			collision = fileops.CollisionRename
+		default:
+			p.Printf("Not saved.\n")
+			return nil
+		}
+		destPath, err = fm.SaveFileToStorage(file.Path, newName, collision)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Rule saved", "source", file.Path, "dest", destPath, "repository_id", prep.ID())
+	if collision != fileops.CollisionOverwrite {
+		hooks.Notify(context.Background(), cfg.Hooks, hooks.RulePayload(hooks.EventRuleCreated, prep, destPath), logger)
+	}
+	p.Printf("Saved to %s\n", destPath)
+	return nil
+}
+
+// syncRepositories fetches the GitHub repositories and their worktrees,
+// printing the result of each
+func syncRepositories(p *Prompter, cfg *config.Config, logger *logging.AppLogger) {
+	repos := repository.WithWorktrees(cfg.Repositories)
+	if len(repos) == 0 {
+		p.Printf("No repositories configured.\n")
+		return
+	}
+
+	p.Printf("Syncing %d repositories...\n", len(repos))
+	for _, result := range repository.SyncAllRepositories(context.Background(), repos, logger) {
+		p.Printf("  %s: %s\n", result.RepositoryName, result.GetMessage())
+	}
+}
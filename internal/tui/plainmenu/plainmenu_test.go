@@ -0,0 +1,82 @@
+package plainmenu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/setupmenu"
+)
+
+func TestRunSetup_LocalDirectory(t *testing.T) {
+	_, cleanup := setupmenu.SetTestConfigPath(t)
+	defer cleanup()
+	logger, _ := logging.NewTestLogger()
+
+	// A relative storage directory is rejected and the questions asked again
+	dir := filepath.Join(t.TempDir(), "rules")
+	var out strings.Builder
+	p := NewPrompter(strings.NewReader("1\nrules\n1\n"+dir+"\n"), &out)
+	if err := RunSetup(p, helpers.NewUIContext(0, 0, nil, logger)); err != nil {
+		t.Fatalf("RunSetup() failed: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "Setup failed: path must be absolute") {
+		t.Errorf("output %q does not explain the rejected directory", out.String())
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if len(cfg.Repositories) != 1 || cfg.Repositories[0].Path != dir {
+		t.Errorf("repositories = %+v, want the local repository at %s", cfg.Repositories, dir)
+	}
+}
+
+func TestRun_SaveRule(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	storage := t.TempDir()
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{{
+		ID:        "rules-3f9a0c12",
+		Name:      "Rules",
+		Type:      repository.RepositoryTypeLocal,
+		CreatedAt: 1234567890,
+		Path:      storage,
+	}}}
+
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "style.md"), []byte("---\ndescription: Style\n---\n# Style\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	t.Chdir(project)
+
+	// Save style.md as go-style.md, save it again numbered rather than
+	// overwriting it, then quit
+	input := "1\n1\ngo-style.md\n" + "1\n1\ngo-style.md\n2\n" + "3\n"
+	var out strings.Builder
+	if err := Run(NewPrompter(strings.NewReader(input), &out), cfg, logger); err != nil {
+		t.Fatalf("Run() failed: %v\n%s", err, out.String())
+	}
+
+	for _, name := range []string{"go-style.md", "go-style-2.md"} {
+		if _, err := os.Stat(filepath.Join(storage, name)); err != nil {
+			t.Errorf("%s was not saved: %v\n%s", name, err, out.String())
+		}
+	}
+	if n := strings.Count(out.String(), "Saved to "); n != 2 {
+		t.Errorf("output reports %d saves, want 2:\n%s", n, out.String())
+	}
+}
+
+func TestRun_EndOfInputQuits(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	var out strings.Builder
+	if err := Run(NewPrompter(strings.NewReader(""), &out), &config.Config{}, logger); err != nil {
+		t.Fatalf("Run() = %v, want nil at end of input", err)
+	}
+}
@@ -0,0 +1,97 @@
+package plainmenu
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrNoInput is returned when the input ends before a prompt is answered
+var ErrNoInput = errors.New("input ended before the prompt was answered")
+
+// Prompter asks questions one line at a time. Answers are read from its input
+// whether or not it is a terminal, so a script can pipe them in.
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewPrompter creates a Prompter reading answers from in and writing
+// questions to out
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// Printf writes a message that is not a question
+func (p *Prompter) Printf(format string, args ...any) {
+	fmt.Fprintf(p.out, format, args...)
+}
+
+// Ask prints question and returns the trimmed answer, or def when the answer
+// is empty
+func (p *Prompter) Ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+
+	line, err := p.in.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		fmt.Fprintln(p.out)
+		return "", ErrNoInput
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// Choose prints options numbered from 1 and returns the index of the one
+// picked, asking again until a listed number is entered
+func (p *Prompter) Choose(question string, options []string) (int, error) {
+	fmt.Fprintln(p.out, question)
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+	}
+	for {
+		answer, err := p.Ask(fmt.Sprintf("Choice [1-%d]", len(options)), "")
+		if err != nil {
+			return 0, err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(p.out, "Enter a number from 1 to %d\n", len(options))
+	}
+}
+
+// Confirm asks a yes or no question, def being the answer to an empty line
+func (p *Prompter) Confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := p.Ask(fmt.Sprintf("%s (%s)", question, hint), "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Answer y or n")
+	}
+}
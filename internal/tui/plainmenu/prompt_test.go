@@ -0,0 +1,64 @@
+package plainmenu
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPrompter_Ask(t *testing.T) {
+	var out strings.Builder
+	p := NewPrompter(strings.NewReader("  team-rules  \n\nlast"), &out)
+
+	tests := []struct {
+		def  string
+		want string
+	}{
+		{"", "team-rules"},
+		{"default", "default"},
+		{"", "last"}, // A final line without a newline is still an answer
+	}
+	for _, tt := range tests {
+		got, err := p.Ask("Name", tt.def)
+		if err != nil || got != tt.want {
+			t.Errorf("Ask(%q) = %q, %v; want %q", tt.def, got, err, tt.want)
+		}
+	}
+	if _, err := p.Ask("Name", "default"); !errors.Is(err, ErrNoInput) {
+		t.Errorf("Ask() at end of input = %v, want ErrNoInput", err)
+	}
+	if !strings.Contains(out.String(), "Name [default]: ") {
+		t.Errorf("output %q does not show the default", out.String())
+	}
+}
+
+func TestPrompter_Choose(t *testing.T) {
+	var out strings.Builder
+	p := NewPrompter(strings.NewReader("0\nsync\n2\n"), &out)
+
+	got, err := p.Choose("What would you like to do?", []string{"Save", "Sync"})
+	if err != nil || got != 1 {
+		t.Fatalf("Choose() = %d, %v; want 1", got, err)
+	}
+	if !strings.Contains(out.String(), "  2) Sync\n") {
+		t.Errorf("output %q does not number the options", out.String())
+	}
+	if n := strings.Count(out.String(), "Enter a number from 1 to 2"); n != 2 {
+		t.Errorf("invalid answers were explained %d times, want 2", n)
+	}
+
+	if _, err := p.Choose("Again?", []string{"Yes"}); !errors.Is(err, ErrNoInput) {
+		t.Errorf("Choose() at end of input = %v, want ErrNoInput", err)
+	}
+}
+
+func TestPrompter_Confirm(t *testing.T) {
+	p := NewPrompter(strings.NewReader("\nmaybe\nYES\nn\n"), &strings.Builder{})
+
+	for _, want := range []bool{true, true, false} {
+		got, err := p.Confirm("Overwrite?", true)
+		if err != nil || got != want {
+			t.Errorf("Confirm() = %v, %v; want %v", got, err, want)
+		}
+	}
+}
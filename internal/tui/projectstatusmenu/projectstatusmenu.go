@@ -0,0 +1,426 @@
+// Package projectstatusmenu implements the "Project status" screen.
+//
+// It compares every rule recorded in the manifest of the project in the
+// current directory with its central version (see project.Compare) and lists
+// them as up to date, outdated, modified in the project, missing from the
+// project or missing from their repository. Enter shows how a rule differs,
+// and drifted rules can be re-synced from their repositories one at a time or
+// all at once, like `rulem status --sync`.
+package projectstatusmenu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"rulem/internal/config"
+	"rulem/internal/deploy"
+	"rulem/internal/logging"
+	"rulem/internal/project"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/helpers/textutil"
+	"rulem/internal/tui/styles"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type menuState int
+
+const (
+	stateLoading menuState = iota
+	stateList              // Browsing the deployed rules
+	stateDiff              // Reading how a rule differs from its central version
+	stateConfirm           // Confirming a re-sync
+)
+
+type (
+	// loadedMsg carries the comparison of every deployed rule
+	loadedMsg struct {
+		prepared    []repository.PreparedRepository
+		comparisons []project.Comparison
+		noManifest  bool // Nothing was deployed to the project yet
+		err         error
+	}
+
+	// resyncedMsg reports the outcome of a re-sync
+	resyncedMsg struct {
+		paths []string
+		err   error
+	}
+)
+
+// statusItem is a deployed rule labelled with how it compares
+type statusItem struct {
+	c project.Comparison
+}
+
+func (i statusItem) Title() string {
+	return fmt.Sprintf("%s %s", labelStyle(i.c).Render(fmt.Sprintf("%-14s", i.c.Label())), i.c.Entry.Path)
+}
+func (i statusItem) Description() string {
+	if i.c.Err != nil {
+		return i.c.Err.Error()
+	}
+	return "from " + i.c.CentralName
+}
+func (i statusItem) FilterValue() string { return i.c.Label() + " " + i.c.Entry.Path }
+
+// labelStyle colors up-to-date rules as successes and the others as errors
+func labelStyle(c project.Comparison) lipgloss.Style {
+	if c.Status == project.StatusUpToDate {
+		return styles.SuccessStyle
+	}
+	return styles.ErrorStyle
+}
+
+// ProjectStatusModel is the Bubble Tea model for the project status screen.
+type ProjectStatusModel struct {
+	logger   *logging.AppLogger
+	layout   components.LayoutModel
+	spinner  spinner.Model
+	rules    list.Model
+	viewport viewport.Model
+	cfg      *config.Config
+	cache    *helpers.ScreenCache // nil caches nothing
+
+	state       menuState
+	prepared    []repository.PreparedRepository
+	comparisons []project.Comparison
+	noManifest  bool
+	resync      []project.Comparison // Rules to re-sync, shown for confirmation
+	status      string               // Outcome of the last re-sync
+}
+
+// NewProjectStatusModel creates the project status screen model from the shared UI context.
+func NewProjectStatusModel(ctx helpers.UIContext) *ProjectStatusModel {
+	layout := components.NewLayout(components.LayoutConfig{
+		MarginX:  2,
+		MarginY:  1,
+		MaxWidth: 100,
+	})
+	if ctx.HasValidDimensions() {
+		layout, _ = layout.Update(tea.WindowSizeMsg{Width: ctx.Width, Height: ctx.Height})
+	}
+
+	s := spinner.New()
+	s.Style = styles.SpinnerStyle
+	s.Spinner = spinner.Pulse
+
+	rules := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	rules.SetShowTitle(false)
+	rules.SetShowStatusBar(false)
+	rules.SetFilteringEnabled(true)
+	rules.SetShowHelp(false) // We'll use the layout for help
+
+	m := &ProjectStatusModel{
+		logger:   ctx.Logger,
+		layout:   layout,
+		spinner:  s,
+		rules:    rules,
+		viewport: viewport.New(0, 0),
+		cfg:      ctx.Config,
+		cache:    ctx.Cache,
+		state:    stateLoading,
+	}
+	m.resize()
+	return m
+}
+
+// Init prepares the repositories and compares the deployed rules.
+func (m *ProjectStatusModel) Init() tea.Cmd {
+	return tea.Batch(m.loadCmd(), m.spinner.Tick)
+}
+
+// Update handles comparisons, finished re-syncs and key presses.
+func (m *ProjectStatusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m.layout, _ = m.layout.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resize()
+		return m, nil
+
+	case loadedMsg:
+		m.state = stateList
+		if msg.err != nil {
+			m.logger.Error("Failed to compare deployed rules", "error", msg.err)
+			m.layout = m.layout.SetError(msg.err)
+			return m, nil
+		}
+		m.prepared = msg.prepared
+		m.comparisons = msg.comparisons
+		m.noManifest = msg.noManifest
+		items := make([]list.Item, len(msg.comparisons))
+		for i, c := range msg.comparisons {
+			items[i] = statusItem{c: c}
+		}
+		return m, m.rules.SetItems(items)
+
+	case resyncedMsg:
+		if msg.err != nil {
+			m.state = stateList
+			m.logger.Warn("Re-sync failed", "error", msg.err)
+			m.status = ""
+			m.layout = m.layout.SetError(msg.err)
+			return m, nil
+		}
+		m.layout = m.layout.ClearError()
+		m.status = fmt.Sprintf("Re-synced %d rule(s)", len(msg.paths))
+		// The re-synced rules are not in the cached scan of the project yet
+		m.cache.InvalidateFiles(helpers.ScanWorkingDir)
+		return m, m.loadCmd()
+
+	case spinner.TickMsg:
+		if m.state == stateLoading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.state {
+		case stateList:
+			return m.updateList(msg)
+		case stateDiff:
+			return m.updateDiff(msg)
+		case stateConfirm:
+			return m.updateConfirm(msg)
+		}
+		switch msg.String() {
+		case "q":
+			return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+		case "esc":
+			return m, helpers.NavigateBack
+		}
+	}
+
+	return m, nil
+}
+
+// updateList handles the keys of the rule list; while the list is filtered
+// they are typed into the filter
+func (m *ProjectStatusModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.rules.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		m.rules, cmd = m.rules.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+	case "esc":
+		return m, helpers.NavigateBack
+	case "ctrl+r":
+		return m, helpers.RefreshScreen
+	case "enter":
+		item, ok := m.rules.SelectedItem().(statusItem)
+		if !ok || !item.c.Drifted() {
+			return m, nil
+		}
+		m.viewport.SetContent(item.c.Diff())
+		m.viewport.GotoTop()
+		m.state = stateDiff
+		return m, nil
+	case "s":
+		item, ok := m.rules.SelectedItem().(statusItem)
+		if !ok {
+			return m, nil
+		}
+		if !item.c.CanResync() {
+			m.layout = m.layout.SetError(fmt.Errorf("%s is %s and cannot be re-synced", item.c.Entry.Path, item.c.Label()))
+			return m, nil
+		}
+		return m.confirmResync([]project.Comparison{item.c})
+	case "a":
+		// Rules edited in the project are only re-synced one at a time, so
+		// the user sees each edit that is discarded
+		var resync []project.Comparison
+		for _, c := range m.comparisons {
+			if c.CanResync() && !c.LocallyModified() {
+				resync = append(resync, c)
+			}
+		}
+		if len(resync) == 0 {
+			m.status = "No outdated or missing rules to re-sync"
+			return m, nil
+		}
+		return m.confirmResync(resync)
+	}
+
+	var cmd tea.Cmd
+	m.rules, cmd = m.rules.Update(msg)
+	return m, cmd
+}
+
+// confirmResync asks before re-syncing comparisons
+func (m *ProjectStatusModel) confirmResync(comparisons []project.Comparison) (tea.Model, tea.Cmd) {
+	m.resync = comparisons
+	m.status = ""
+	m.layout = m.layout.ClearError()
+	m.state = stateConfirm
+	return m, nil
+}
+
+// updateDiff scrolls the diff
+func (m *ProjectStatusModel) updateDiff(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "enter":
+		m.state = stateList
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// updateConfirm handles the keys of the confirmation
+func (m *ProjectStatusModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		m.logger.LogUserAction("resync_rules_confirmed", fmt.Sprintf("%d rules", len(m.resync)))
+		m.state = stateLoading
+		return m, tea.Batch(m.resyncCmd(), m.spinner.Tick)
+	case "n", "N", "esc":
+		m.state = stateList
+	}
+	return m, nil
+}
+
+// View renders the current state of the screen.
+func (m *ProjectStatusModel) View() string {
+	switch m.state {
+	case stateDiff:
+		return m.viewDiff()
+	case stateConfirm:
+		return m.viewConfirm()
+	}
+
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🧭 Project Status",
+		Subtitle: m.subtitle(),
+		HelpText: "enter diff • s re-sync rule • a re-sync outdated and missing • / filter • ctrl+r refresh • q/esc back",
+	})
+	if m.state == stateLoading {
+		return m.layout.Render(fmt.Sprintf("%s Comparing deployed rules...", m.spinner.View()))
+	}
+	if m.noManifest || len(m.comparisons) == 0 {
+		return m.layout.Render("No rules have been deployed to this directory yet.\nUse Deploy rules or Import rules to add some.")
+	}
+	return m.layout.Render(m.rules.View())
+}
+
+func (m *ProjectStatusModel) viewDiff() string {
+	title := ""
+	if item, ok := m.rules.SelectedItem().(statusItem); ok {
+		suffix := " is " + item.c.Label()
+		title = textutil.TruncatePath(item.c.Entry.Path, m.layout.ContentWidth()-lipgloss.Width(suffix)) + suffix
+	}
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🧭 Project Status - Diff",
+		Subtitle: title,
+		HelpText: "↑/↓ scroll • q/esc back",
+	})
+	return m.layout.Render(m.viewport.View())
+}
+
+func (m *ProjectStatusModel) viewConfirm() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🧭 Project Status - Re-sync",
+		Subtitle: fmt.Sprintf("%d rule(s) to deploy again from their repositories", len(m.resync)),
+		HelpText: "y to re-sync • n/Esc to go back",
+	})
+
+	var content strings.Builder
+	for _, c := range m.resync {
+		fmt.Fprintf(&content, "%s ← %s", c.Entry.Path, c.CentralName)
+		if c.LocallyModified() {
+			content.WriteString(styles.ErrorStyle.Render(" (discards the project's edits)"))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\nReplace these rules with their central versions?")
+	return m.layout.Render(content.String())
+}
+
+func (m *ProjectStatusModel) subtitle() string {
+	if m.status != "" {
+		return styles.SuccessStyle.Render("✅ " + m.status)
+	}
+	counts := make(map[string]int)
+	var labels []string
+	for _, c := range m.comparisons {
+		label := c.Label()
+		if counts[label] == 0 {
+			labels = append(labels, label)
+		}
+		counts[label]++
+	}
+	if len(labels) == 0 {
+		return "Rules deployed in the current directory compared with their central versions"
+	}
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = fmt.Sprintf("%d %s", counts[label], label)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// resize fits the list and the diff below the subtitle
+func (m *ProjectStatusModel) resize() {
+	width := m.layout.ContentWidth()
+	height := max(m.layout.ContentHeight()-3, 3)
+	m.rules.SetSize(width, height)
+	m.viewport.Width = width
+	m.viewport.Height = height
+}
+
+func (m *ProjectStatusModel) loadCmd() tea.Cmd {
+	cfg := m.cfg
+	logger := m.logger
+	cache := m.cache
+	return func() tea.Msg {
+		if cfg == nil {
+			return loadedMsg{err: fmt.Errorf("configuration is not loaded")}
+		}
+		projectDir, err := os.Getwd()
+		if err != nil {
+			return loadedMsg{err: fmt.Errorf("cannot get current working directory: %w", err)}
+		}
+		manifest, err := project.LoadManifest(projectDir)
+		if errors.Is(err, os.ErrNotExist) {
+			return loadedMsg{noManifest: true}
+		} else if err != nil {
+			return loadedMsg{err: err}
+		}
+		prepared, err := cache.PrepareRepositories(cfg, logger)
+		if err != nil {
+			return loadedMsg{err: fmt.Errorf("repository preparation failed: %w", err)}
+		}
+		return loadedMsg{prepared: prepared, comparisons: project.Compare(projectDir, manifest, prepared, "")}
+	}
+}
+
+// resyncCmd deploys the confirmed rules again
+func (m *ProjectStatusModel) resyncCmd() tea.Cmd {
+	comparisons := m.resync
+	prepared := m.prepared
+	logger := m.logger
+	var hooks []config.Hook
+	if m.cfg != nil {
+		hooks = m.cfg.Hooks
+	}
+	return func() tea.Msg {
+		paths, err := deploy.Resync(context.Background(), comparisons, prepared, hooks, logger)
+		return resyncedMsg{paths: paths, err: err}
+	}
+}
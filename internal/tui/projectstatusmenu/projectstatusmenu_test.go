@@ -0,0 +1,126 @@
+package projectstatusmenu
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/deploy"
+	"rulem/internal/logging"
+	"rulem/internal/project"
+	"rulem/internal/repository"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel deploys files from a local repository into an empty project
+// directory and returns the screen, not loaded yet, with the repository path
+func newTestModel(t *testing.T, files map[string]string) (*ProjectStatusModel, string) {
+	t.Helper()
+	storage := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(storage, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	t.Chdir(t.TempDir())
+
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "rules-3f9a0c12", Name: "Rules", Type: repository.RepositoryTypeLocal, CreatedAt: 1234567890, Path: storage},
+	}}
+	logger, _ := logging.NewTestLogger()
+	prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, logger)
+	if err != nil {
+		t.Fatalf("PrepareAllRepositories: %v", err)
+	}
+	var targets []deploy.Target
+	for name := range files {
+		targets = append(targets, deploy.Target{Source: name, Repository: prepared[0], Dest: name})
+	}
+	if _, err := deploy.Deploy(context.Background(), targets, deploy.Options{}, logger); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+
+	m := NewProjectStatusModel(helpers.NewUIContext(80, 24, cfg, logger))
+	return m, storage
+}
+
+// isLoaded keeps the messages that finish a re-sync and the reload after it
+func isLoaded(msg tea.Msg) bool {
+	switch msg.(type) {
+	case loadedMsg, resyncedMsg:
+		return true
+	}
+	return false
+}
+
+func TestProjectStatusModel_ResyncsOutdatedRules(t *testing.T) {
+	m, storage := newTestModel(t, map[string]string{"go.md": "# go v1\n", "python.md": "# python\n"})
+	if err := os.WriteFile(filepath.Join(storage, "go.md"), []byte("# go v2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = tuitest.Run(t, m, nil, m.loadCmd()())
+
+	if view := m.View(); !strings.Contains(view, "outdated") || !strings.Contains(m.subtitle(), "1 outdated") {
+		t.Fatalf("want go.md listed as outdated\n%s", view)
+	}
+
+	m = tuitest.Send(t, m, tuitest.Key("a"))
+	if m.state != stateConfirm || len(m.resync) != 1 || !strings.Contains(m.View(), "go.md") {
+		t.Fatalf("state = %v, want go.md to confirm\n%s", m.state, m.View())
+	}
+	m, _ = tuitest.Run(t, m, isLoaded, tuitest.Key("y"))
+
+	data, err := os.ReadFile("go.md")
+	if err != nil || string(data) != "# go v2\n" {
+		t.Errorf("go.md = %q, %v; want the central version", data, err)
+	}
+	if !strings.Contains(m.status, "Re-synced 1 rule(s)") {
+		t.Errorf("status = %q, want the re-sync reported", m.status)
+	}
+	for _, c := range m.comparisons {
+		if c.Status != project.StatusUpToDate {
+			t.Errorf("%s is %s after the re-sync, want every rule up to date\n%s", c.Entry.Path, c.Label(), m.View())
+		}
+	}
+}
+
+func TestProjectStatusModel_ModifiedRules(t *testing.T) {
+	m, _ := newTestModel(t, map[string]string{"go.md": "# go\n"})
+	if err := os.WriteFile("go.md", []byte("# go, edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = tuitest.Run(t, m, nil, m.loadCmd()())
+
+	// Re-syncing everything leaves edited rules alone
+	m = tuitest.Send(t, m, tuitest.Key("a"))
+	if m.state != stateList || !strings.Contains(m.status, "No outdated or missing rules") {
+		t.Errorf("state = %v, status = %q; want nothing to re-sync", m.state, m.status)
+	}
+
+	m = tuitest.Send(t, m, tuitest.Key("enter"))
+	if m.state != stateDiff || !strings.Contains(m.View(), "-# go, edited") {
+		t.Errorf("state = %v, want the diff\n%s", m.state, m.View())
+	}
+
+	m = tuitest.Send(t, m, tuitest.Key("esc"), tuitest.Key("s"))
+	if m.state != stateConfirm || !strings.Contains(m.View(), "discards the project's edits") {
+		t.Errorf("state = %v, want a warning before discarding the edits\n%s", m.state, m.View())
+	}
+}
+
+func TestProjectStatusModel_NoManifest(t *testing.T) {
+	t.Chdir(t.TempDir())
+	logger, _ := logging.NewTestLogger()
+	m := NewProjectStatusModel(helpers.NewUIContext(80, 24, &config.Config{}, logger))
+	m, _ = tuitest.Run(t, m, nil, m.loadCmd()())
+
+	if !strings.Contains(m.View(), "No rules have been deployed to this directory yet") {
+		t.Errorf("want the empty state\n%s", m.View())
+	}
+}
@@ -0,0 +1,69 @@
+package repostatusmenu
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestGoldenViews renders the status board at several terminal sizes and
+// compares it with testdata/<name>_<width>x<height>.golden. Regenerate with
+// go test ./internal/tui/repostatusmenu -run TestGoldenViews -update
+func TestGoldenViews(t *testing.T) {
+	remote := "https://github.com/example/team-rules.git"
+	githubConfig := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "l1", Name: "Personal Rules", Type: repository.RepositoryTypeLocal, Path: "/home/user/rules"},
+		{ID: "g1", Name: "Team Rules", Type: repository.RepositoryTypeGitHub, Path: "/home/user/.local/share/rulem/team-rules", RemoteURL: &remote, Branch: strPtr("main")},
+	}}
+	localConfig := &config.Config{Repositories: githubConfig.Repositories[:1]}
+	rows := []repoRow{
+		{Name: "Personal Rules", Kind: "local", Path: "/home/user/rules", Status: "📁 local (not synced)"},
+		{Name: "Team Rules", Kind: "github • main", Path: "/home/user/.local/share/rulem/team-rules", About: "Shared coding rules", Status: "⚠️ uncommitted local changes"},
+	}
+
+	sizes := []struct{ width, height int }{{80, 24}, {120, 40}}
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		msgs []tea.Msg
+	}{
+		{name: "empty", cfg: &config.Config{}, msgs: []tea.Msg{statusRowsMsg{}}},
+		{name: "checking", cfg: githubConfig},
+		{name: "local_only", cfg: localConfig, msgs: []tea.Msg{statusRowsMsg{rows: rows[:1]}}},
+		{name: "github", cfg: githubConfig, msgs: []tea.Msg{statusRowsMsg{rows: rows}}},
+		{name: "keyring_unavailable", cfg: githubConfig, msgs: []tea.Msg{
+			statusRowsMsg{rows: rows},
+			helpers.KeyringStatusMsg{Status: repository.KeyringStatus{
+				Checked:  true,
+				Error:    "The name org.freedesktop.secrets was not provided by any .service files",
+				Guidance: repository.KeyringGuidance("linux"),
+			}},
+		}},
+		{name: "refresh_error", cfg: githubConfig, msgs: []tea.Msg{
+			statusRowsMsg{rows: rows},
+			refreshDoneMsg{err: errors.New("1 repository failed to sync")},
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, size := range sizes {
+			name := fmt.Sprintf("%s_%dx%d", tt.name, size.width, size.height)
+			t.Run(name, func(t *testing.T) {
+				logger, _ := logging.NewTestLogger()
+				m := NewRepoStatusModel(helpers.NewUIContext(size.width, size.height, tt.cfg, logger))
+				for _, msg := range tt.msgs {
+					m = tuitest.Send(t, m, msg)
+				}
+				tuitest.Golden(t, name, m.View())
+			})
+		}
+	}
+}
@@ -2,13 +2,23 @@
 //
 // It shows the sync status of every configured repository and offers a single
 // action: refetch all GitHub repositories. There is deliberately no per-repo
-// selection — the screen stays a simple status board with one button.
+// selection — the screen stays a simple status board with one button. Rules
+// held in quarantine (see the quarantine package) and rules proposed by
+// assistants (see the proposals package) are reviewed from here too.
 //
 // Status semantics:
 //   - local repositories are listed for completeness but are never synced
 //   - a GitHub repository with a missing clone directory is re-cloned on refresh
 //   - a GitHub repository with uncommitted local changes is skipped on refresh
 //     (rulem never discards local work)
+//   - a GitHub repository with allowed signers shows whether its commit is signed
+//     by an allowed key
+//   - rules that newly appeared in a repository with quarantine are counted, and
+//     are only served over MCP once approved on the review screen (v)
+//   - rules assistants proposed with propose_rule are counted, and are moved into
+//     their repository once accepted on the proposals screen (p)
+//   - when the background sync is on (sync_interval), its last and next run
+//     are shown with the outcome of the last run
 package repostatusmenu
 
 import (
@@ -18,14 +28,19 @@ import (
 	"strings"
 
 	"rulem/internal/config"
+	"rulem/internal/filemanager"
 	"rulem/internal/logging"
+	"rulem/internal/proposals"
+	"rulem/internal/quarantine"
 	"rulem/internal/repository"
 	"rulem/internal/tui/components"
 	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/helpers/textutil"
 	"rulem/internal/tui/styles"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 type menuState int
@@ -34,6 +49,8 @@ const (
 	stateChecking menuState = iota
 	stateReady
 	stateRefreshing
+	stateReview
+	stateProposals
 )
 
 // repoRow is one line of the status board.
@@ -41,12 +58,26 @@ type repoRow struct {
 	Name   string
 	Kind   string // "local" or "github (branch)"
 	Path   string
+	About  string // Summary from the repository's rulem.yaml, if any
 	Status string
+
+	// Signature is the commit signature check, for repositories with allowed signers
+	Signature string
 }
 
 type (
 	statusRowsMsg struct {
-		rows []repoRow
+		rows        []repoRow
+		quarantined []quarantine.Rule
+		proposed    []proposals.Proposal
+	}
+
+	approvedMsg struct {
+		err error
+	}
+
+	proposalReviewedMsg struct {
+		err error
 	}
 
 	refreshDoneMsg struct {
@@ -68,6 +99,22 @@ type RepoStatusModel struct {
 	// lastSync holds the most recent refresh outcome per repository ID and is
 	// merged into the status rows after a refresh.
 	lastSync map[string]string
+
+	// keyring is the startup credential store heartbeat, see renderKeyring
+	keyring repository.KeyringStatus
+
+	// scheduler runs the background sync, see renderAutoSync; nil when off
+	scheduler *repository.Scheduler
+
+	// quarantined lists the rules awaiting approval; reviewCursor selects one
+	// on the review screen
+	quarantined  []quarantine.Rule
+	reviewCursor int
+
+	// proposed lists the rules assistants proposed; proposalCursor selects one
+	// on the proposals screen
+	proposed       []proposals.Proposal
+	proposalCursor int
 }
 
 // NewRepoStatusModel creates the status screen model from the shared UI context.
@@ -86,12 +133,14 @@ func NewRepoStatusModel(ctx helpers.UIContext) *RepoStatusModel {
 	s.Spinner = spinner.Pulse
 
 	return &RepoStatusModel{
-		logger:   ctx.Logger,
-		layout:   layout,
-		spinner:  s,
-		cfg:      ctx.Config,
-		state:    stateChecking,
-		lastSync: map[string]string{},
+		logger:    ctx.Logger,
+		layout:    layout,
+		spinner:   s,
+		cfg:       ctx.Config,
+		state:     stateChecking,
+		lastSync:  map[string]string{},
+		keyring:   ctx.Keyring,
+		scheduler: ctx.Scheduler,
 	}
 }
 
@@ -107,7 +156,38 @@ func (m *RepoStatusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case statusRowsMsg:
 		m.rows = msg.rows
-		m.state = stateReady
+		m.quarantined = msg.quarantined
+		m.reviewCursor = min(m.reviewCursor, max(len(m.quarantined)-1, 0))
+		m.proposed = msg.proposed
+		m.proposalCursor = min(m.proposalCursor, max(len(m.proposed)-1, 0))
+		switch {
+		case m.state == stateReview && len(m.quarantined) > 0:
+		case m.state == stateProposals && len(m.proposed) > 0:
+		default:
+			m.state = stateReady
+		}
+		return m, nil
+
+	case approvedMsg:
+		if msg.err != nil {
+			m.logger.Error("Failed to approve quarantined rules", "error", msg.err)
+			m.layout = m.layout.SetError(msg.err)
+		} else {
+			m.layout = m.layout.ClearError()
+		}
+		return m, m.checkStatusCmd()
+
+	case proposalReviewedMsg:
+		if msg.err != nil {
+			m.logger.Error("Failed to review proposed rule", "error", msg.err)
+			m.layout = m.layout.SetError(msg.err)
+		} else {
+			m.layout = m.layout.ClearError()
+		}
+		return m, m.checkStatusCmd()
+
+	case helpers.KeyringStatusMsg:
+		m.keyring = msg.Status
 		return m, nil
 
 	case refreshDoneMsg:
@@ -121,9 +201,13 @@ func (m *RepoStatusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if prep.IsRemote() {
 				m.lastSync[prep.ID()] = prep.SyncResult.GetMessage()
 			}
+			if prep.CompatibilityWarning != "" {
+				m.lastSync[prep.ID()] = "⚠️ " + prep.CompatibilityWarning
+			}
 		}
-		// Re-check the on-disk state so dirty/missing markers are current.
-		return m, m.checkStatusCmd()
+		// Re-check the on-disk state so dirty/missing markers are current,
+		// here and in the shared status bar.
+		return m, tea.Batch(m.checkStatusCmd(), helpers.RefreshStatusBar(m.cfg))
 
 	case spinner.TickMsg:
 		if m.state == stateChecking || m.state == stateRefreshing {
@@ -134,26 +218,115 @@ func (m *RepoStatusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.state == stateReview {
+			return m.handleReviewKeys(msg)
+		}
+		if m.state == stateProposals {
+			return m.handleProposalKeys(msg)
+		}
 		switch msg.String() {
-		case "q", "esc":
+		case "q":
 			return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+		case "esc":
+			return m, helpers.NavigateBack
 		case "r", "enter":
 			if m.state == stateReady && m.hasGitHubRepos() {
 				m.state = stateRefreshing
 				return m, tea.Batch(m.refreshCmd(), m.spinner.Tick)
 			}
+		case "v":
+			if m.state == stateReady && len(m.quarantined) > 0 {
+				m.state = stateReview
+				m.reviewCursor = 0
+			}
+		case "p":
+			if m.state == stateReady && len(m.proposed) > 0 {
+				m.state = stateProposals
+				m.proposalCursor = 0
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// handleReviewKeys moves through the quarantined rules and approves them
+func (m *RepoStatusModel) handleReviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		m.state = stateReady
+	case "up", "k":
+		if m.reviewCursor > 0 {
+			m.reviewCursor--
+		}
+	case "down", "j":
+		if m.reviewCursor < len(m.quarantined)-1 {
+			m.reviewCursor++
+		}
+	case "a", "enter":
+		if m.reviewCursor < len(m.quarantined) {
+			return m, approveCmd([]quarantine.Rule{m.quarantined[m.reviewCursor]})
 		}
+	case "A":
+		return m, approveCmd(m.quarantined)
 	}
+	return m, nil
+}
 
+// handleProposalKeys moves through the proposed rules and accepts or rejects them
+func (m *RepoStatusModel) handleProposalKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		m.state = stateReady
+	case "up", "k":
+		if m.proposalCursor > 0 {
+			m.proposalCursor--
+		}
+	case "down", "j":
+		if m.proposalCursor < len(m.proposed)-1 {
+			m.proposalCursor++
+		}
+	case "a", "enter":
+		if m.proposalCursor < len(m.proposed) {
+			return m, acceptProposalCmd(m.cfg, m.proposed[m.proposalCursor])
+		}
+	case "d":
+		if m.proposalCursor < len(m.proposed) {
+			return m, rejectProposalCmd(m.proposed[m.proposalCursor])
+		}
+	}
 	return m, nil
 }
 
 // View renders the status board, or a spinner while checking/refreshing.
 func (m *RepoStatusModel) View() string {
+	if m.state == stateReview {
+		m.layout = m.layout.SetConfig(components.LayoutConfig{
+			Title:    "🛡  Quarantined Rules",
+			Subtitle: "These rules appeared after a sync and are not served over MCP until\nyou approve them. Check what they tell assistants before approving.",
+			HelpText: "↑/↓ select • a approve • A approve all • q/esc back",
+		})
+		return m.layout.Render(m.renderReview())
+	}
+	if m.state == stateProposals {
+		m.layout = m.layout.SetConfig(components.LayoutConfig{
+			Title:    "📝 Proposed Rules",
+			Subtitle: "Assistants proposed these rules over MCP. They are not served until you\naccept them, which moves them into their repository. Read them first.",
+			HelpText: "↑/↓ select • a accept • d reject (delete) • q/esc back",
+		})
+		return m.layout.Render(m.renderProposals())
+	}
+
 	help := "q/esc back"
 	if m.hasGitHubRepos() {
 		help = "r refresh all GitHub repositories • q/esc back"
 	}
+	if len(m.quarantined) > 0 {
+		help = "v review quarantine • " + help
+	}
+	if len(m.proposed) > 0 {
+		help = "p review proposals • " + help
+	}
 	m.layout = m.layout.SetConfig(components.LayoutConfig{
 		Title:    "🔄 GitHub Repositories",
 		Subtitle: m.subtitle(),
@@ -166,7 +339,28 @@ func (m *RepoStatusModel) View() string {
 	case stateRefreshing:
 		return m.layout.Render(fmt.Sprintf("%s Refreshing repositories... (clones may take a moment)", m.spinner.View()))
 	default:
-		return m.layout.Render(m.renderRows())
+		content := m.renderRows()
+		switch n := len(m.quarantined); n {
+		case 0:
+		case 1:
+			content += "\n\n🛡  1 new rule in quarantine, not served over MCP - press v to review"
+		default:
+			content += fmt.Sprintf("\n\n🛡  %d new rules in quarantine, not served over MCP - press v to review", n)
+		}
+		switch n := len(m.proposed); n {
+		case 0:
+		case 1:
+			content += "\n\n📝 1 rule proposed by an assistant - press p to review"
+		default:
+			content += fmt.Sprintf("\n\n📝 %d rules proposed by assistants - press p to review", n)
+		}
+		if autoSync := m.renderAutoSync(); autoSync != "" {
+			content += "\n\n" + autoSync
+		}
+		if keyring := m.renderKeyring(); keyring != "" {
+			content += "\n\n" + keyring
+		}
+		return m.layout.Render(content)
 	}
 }
 
@@ -196,15 +390,126 @@ func (m *RepoStatusModel) renderRows() string {
 	if len(m.rows) == 0 {
 		return "No repositories configured - add one in Settings."
 	}
+	// Rows are indented by four spaces; paths are shortened rather than wrapped
+	pathWidth := m.layout.ContentWidth() - 4
 	var b strings.Builder
 	for _, row := range m.rows {
 		fmt.Fprintf(&b, "%s  (%s)\n", row.Name, row.Kind)
-		fmt.Fprintf(&b, "    %s\n", row.Path)
-		fmt.Fprintf(&b, "    %s\n\n", row.Status)
+		fmt.Fprintf(&b, "    %s\n", textutil.TruncatePath(row.Path, pathWidth))
+		if row.About != "" {
+			fmt.Fprintf(&b, "    %s\n", row.About)
+		}
+		fmt.Fprintf(&b, "    %s\n", row.Status)
+		if row.Signature != "" {
+			fmt.Fprintf(&b, "    %s\n", row.Signature)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// renderReview lists the quarantined rules with the selected one marked
+func (m *RepoStatusModel) renderReview() string {
+	if len(m.quarantined) == 0 {
+		return "No rules in quarantine."
+	}
+	names := make(map[string]string)
+	if m.cfg != nil {
+		for _, repo := range m.cfg.Repositories {
+			names[repo.ID] = repo.Name
+		}
+	}
+
+	var b strings.Builder
+	for i, rule := range m.quarantined {
+		marker := "  "
+		if i == m.reviewCursor {
+			marker = "> "
+		}
+		repoName := names[rule.RepositoryID]
+		if repoName == "" {
+			repoName = rule.RepositoryID
+		}
+		suffix := fmt.Sprintf("  (%s, since %s)", repoName, rule.Since.Format("2006-01-02 15:04"))
+		path := textutil.TruncatePath(rule.Path, m.layout.ContentWidth()-lipgloss.Width(marker+suffix))
+		fmt.Fprintf(&b, "%s%s%s\n", marker, path, suffix)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// renderProposals lists the proposed rules with the selected one marked and
+// the path of its file, to read before accepting it
+func (m *RepoStatusModel) renderProposals() string {
+	if len(m.proposed) == 0 {
+		return "No proposed rules."
+	}
+	names := make(map[string]string)
+	if m.cfg != nil {
+		for _, repo := range m.cfg.Repositories {
+			names[repo.ID] = repo.Name
+		}
+	}
+
+	var b strings.Builder
+	for i, proposal := range m.proposed {
+		marker := "  "
+		if i == m.proposalCursor {
+			marker = "> "
+		}
+		repoName := names[proposal.RepositoryID]
+		if repoName == "" {
+			repoName = proposal.RepositoryID
+		}
+		fmt.Fprintf(&b, "%s%s  (%s, proposed %s)\n", marker, proposal.Name, repoName, proposal.Proposed.Format("2006-01-02 15:04"))
+	}
+	if m.proposalCursor < len(m.proposed) {
+		fmt.Fprintf(&b, "\n%s", textutil.TruncatePath(m.proposed[m.proposalCursor].Path, m.layout.ContentWidth()))
 	}
 	return strings.TrimRight(b.String(), "\n")
 }
 
+// renderKeyring describes the credential store used for GitHub PATs, with
+// platform guidance when it is unavailable. It is empty until the startup
+// heartbeat has completed.
+func (m *RepoStatusModel) renderKeyring() string {
+	switch {
+	case !m.keyring.Checked:
+		return ""
+	case m.keyring.Available:
+		return "🔑 Credential store: available"
+	default:
+		return fmt.Sprintf("🔑 Credential store: unavailable - GitHub PATs cannot be saved\n    %s\n    %s",
+			m.keyring.Error, m.keyring.Guidance)
+	}
+}
+
+// renderAutoSync describes the background sync: its interval, when it last
+// ran with what outcome, and when it runs next. Failed repositories are listed
+// with their error. It is empty when the background sync is off.
+func (m *RepoStatusModel) renderAutoSync() string {
+	if m.scheduler == nil {
+		return ""
+	}
+	status := m.scheduler.Status()
+	var b strings.Builder
+	fmt.Fprintf(&b, "⏱  Auto-sync: every %s", status.Interval)
+	switch {
+	case status.Running:
+		b.WriteString(" • syncing now")
+	case !status.LastRun.IsZero():
+		fmt.Fprintf(&b, " • last run %s: %s", status.LastRun.Format("15:04"), status.Summary())
+	}
+	if !status.Running && !status.NextRun.IsZero() {
+		fmt.Fprintf(&b, " • next run %s", status.NextRun.Format("15:04"))
+	}
+	for _, result := range status.Results {
+		if result.Status == repository.SyncStatusFailed {
+			fmt.Fprintf(&b, "\n    %s: %s", result.RepositoryName, result.GetMessage())
+		}
+	}
+	return b.String()
+}
+
 func (m *RepoStatusModel) checkStatusCmd() tea.Cmd {
 	cfg := m.cfg
 	lastSync := m.lastSync
@@ -212,7 +517,52 @@ func (m *RepoStatusModel) checkStatusCmd() tea.Cmd {
 		if cfg == nil {
 			return statusRowsMsg{}
 		}
-		return statusRowsMsg{rows: buildStatusRows(cfg.Repositories, lastSync)}
+		msg := statusRowsMsg{rows: buildStatusRows(cfg.Repositories, lastSync)}
+		if store, err := quarantine.Load(quarantine.Path()); err == nil {
+			msg.quarantined = store.Pending()
+		}
+		// Proposals of unreadable repositories are left out; the others are listed
+		msg.proposed, _ = proposals.ListAll(proposals.Prepare(cfg.Repositories))
+		return msg
+	}
+}
+
+// approveCmd releases rules from quarantine
+func approveCmd(rules []quarantine.Rule) tea.Cmd {
+	return func() tea.Msg {
+		store, err := quarantine.Load(quarantine.Path())
+		if err != nil {
+			return approvedMsg{err: err}
+		}
+		for _, rule := range rules {
+			if err := store.Approve(rule.RepositoryID, rule.Path); err != nil {
+				return approvedMsg{err: err}
+			}
+		}
+		return approvedMsg{err: store.Save()}
+	}
+}
+
+// acceptProposalCmd moves a proposed rule into its repository
+func acceptProposalCmd(cfg *config.Config, proposal proposals.Proposal) tea.Cmd {
+	return func() tea.Msg {
+		if cfg == nil {
+			return proposalReviewedMsg{err: fmt.Errorf("no configuration loaded")}
+		}
+		for _, prep := range proposals.Prepare(cfg.Repositories) {
+			if prep.ID() == proposal.RepositoryID {
+				_, err := proposals.Accept(prep, proposal)
+				return proposalReviewedMsg{err: err}
+			}
+		}
+		return proposalReviewedMsg{err: fmt.Errorf("repository %s no longer accepts proposals", proposal.RepositoryID)}
+	}
+}
+
+// rejectProposalCmd deletes a proposed rule
+func rejectProposalCmd(proposal proposals.Proposal) tea.Cmd {
+	return func() tea.Msg {
+		return proposalReviewedMsg{err: proposals.Reject(proposal)}
 	}
 }
 
@@ -221,6 +571,14 @@ func (m *RepoStatusModel) refreshCmd() tea.Cmd {
 	logger := m.logger
 	return func() tea.Msg {
 		prepared, err := repository.PrepareAllRepositories(context.Background(), cfg.Repositories, logger)
+		if err == nil {
+			// Quarantine the rules the sync brought in, as the MCP server would
+			if files, scanErr := filemanager.ScanAllRepositories(repository.AvailableRepositories(prepared), logger); scanErr == nil {
+				if _, qErr := quarantine.Filter(files, prepared, logger); qErr != nil {
+					logger.Warn("Failed to check quarantined rules", "error", qErr)
+				}
+			}
+		}
 		return refreshDoneMsg{prepared: prepared, err: err}
 	}
 }
@@ -231,10 +589,16 @@ func buildStatusRows(repos []repository.RepositoryEntry, lastSync map[string]str
 	rows := make([]repoRow, 0, len(repos))
 	for _, repo := range repos {
 		row := repoRow{Name: repo.Name, Path: repo.Path}
+		if manifest, err := repository.LoadManifest(repo.Path); err == nil {
+			row.About = manifest.Summary()
+		}
 
 		if repo.IsLocal() {
 			row.Kind = "local"
 			row.Status = "📁 local directory - not synced with any remote"
+			if msg, ok := lastSync[repo.ID]; ok && msg != "" {
+				row.Status += "\n    last refresh: " + msg
+			}
 			rows = append(rows, row)
 			continue
 		}
@@ -258,6 +622,7 @@ func buildStatusRows(repos []repository.RepositoryEntry, lastSync map[string]str
 			default:
 				row.Status = "✅ clean - in sync with the last fetch"
 			}
+			row.Signature = signatureStatus(repo)
 		}
 
 		if msg, ok := lastSync[repo.ID]; ok && msg != "" {
@@ -268,6 +633,22 @@ func buildStatusRows(repos []repository.RepositoryEntry, lastSync map[string]str
 	return rows
 }
 
+// signatureStatus describes the signature of the commit a clone is on, or is
+// empty when the repository does not verify signatures
+func signatureStatus(repo repository.RepositoryEntry) string {
+	check, ok := repo.VerifySignature(repo.Path)
+	switch {
+	case !ok:
+		return ""
+	case check.Verified():
+		return "🔏 " + check.Summary()
+	case repo.GetSignaturePolicy() == repository.SignaturePolicyWarn:
+		return "⚠️  " + check.Summary()
+	default:
+		return "⛔ " + check.Summary() + " - rules are not served"
+	}
+}
+
 func pathMissing(path string) bool {
 	_, err := os.Stat(path)
 	return os.IsNotExist(err)
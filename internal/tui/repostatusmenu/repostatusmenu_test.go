@@ -1,11 +1,19 @@
 package repostatusmenu
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/quarantine"
 	"rulem/internal/repository"
+	"rulem/internal/tui/helpers"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func strPtr(s string) *string { return &s }
@@ -40,6 +48,31 @@ func TestBuildStatusRows(t *testing.T) {
 	}
 }
 
+func TestBuildStatusRows_LocalCompatibilityWarning(t *testing.T) {
+	repos := []repository.RepositoryEntry{
+		{ID: "l1", Name: "Local Rules", Type: repository.RepositoryTypeLocal, Path: t.TempDir()},
+	}
+	rows := buildStatusRows(repos, map[string]string{"l1": "⚠️ repository requires rulem 9.0.0 or newer"})
+	if !strings.Contains(rows[0].Status, "requires rulem 9.0.0") {
+		t.Errorf("local repo must show its last refresh outcome: %+v", rows[0])
+	}
+}
+
+func TestBuildStatusRows_Manifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := "name: Acme Rules\ndescription: Shared coding rules\ndefaultBundle: backend\n"
+	if err := os.WriteFile(filepath.Join(dir, repository.ManifestFileName), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := buildStatusRows([]repository.RepositoryEntry{
+		{ID: "l1", Name: "Local Rules", Type: repository.RepositoryTypeLocal, Path: dir},
+	}, nil)
+	if rows[0].About != "Acme Rules: Shared coding rules • default bundle: backend" {
+		t.Errorf("manifest summary not shown: %q", rows[0].About)
+	}
+}
+
 func TestBuildStatusRows_DefaultBranch(t *testing.T) {
 	remote := "https://github.com/example/rules"
 	repos := []repository.RepositoryEntry{
@@ -50,3 +83,94 @@ func TestBuildStatusRows_DefaultBranch(t *testing.T) {
 		t.Errorf("expected default branch marker, got %q", rows[0].Kind)
 	}
 }
+
+func TestReviewQuarantine(t *testing.T) {
+	t.Setenv("RULEM_QUARANTINE_PATH", filepath.Join(t.TempDir(), "quarantine.yaml"))
+	store, _ := quarantine.Load(quarantine.Path())
+	store.Review("g1", nil, time.Now())
+	store.Review("g1", []string{"new.md", "other.md"}, time.Now())
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	logger, _ := logging.NewTestLogger()
+	m := NewRepoStatusModel(helpers.UIContext{Width: 100, Height: 40, Logger: logger, Config: &config.Config{}})
+	m.Update(m.checkStatusCmd()())
+	if len(m.quarantined) != 2 || !strings.Contains(m.View(), "2 new rules in quarantine") {
+		t.Fatalf("quarantined rules not shown: %+v", m.quarantined)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	if m.state != stateReview {
+		t.Fatalf("v did not open the review, state = %v", m.state)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if cmd == nil {
+		t.Fatal("a did not approve the selected rule")
+	}
+	_, cmd = m.Update(cmd())
+	m.Update(cmd())
+
+	if len(m.quarantined) != 1 || m.quarantined[0].Path != "new.md" {
+		t.Errorf("after approving other.md, quarantined = %+v", m.quarantined)
+	}
+	if m.state != stateReview {
+		t.Errorf("review closed with rules left to approve, state = %v", m.state)
+	}
+}
+
+func TestReviewProposals(t *testing.T) {
+	t.Setenv("RULEM_QUARANTINE_PATH", filepath.Join(t.TempDir(), "quarantine.yaml"))
+	dir := t.TempDir()
+	staged := filepath.Join(dir, repository.ProposalsDir)
+	if err := os.MkdirAll(staged, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"errors.md", "spam.md"} {
+		if err := os.WriteFile(filepath.Join(staged, name), []byte("# "+name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger, _ := logging.NewTestLogger()
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "l1", Name: "Local Rules", Type: repository.RepositoryTypeLocal, Path: dir, AcceptProposals: true},
+	}}
+	m := NewRepoStatusModel(helpers.UIContext{Width: 100, Height: 40, Logger: logger, Config: cfg})
+	m.Update(m.checkStatusCmd()())
+	if len(m.proposed) != 2 || !strings.Contains(m.View(), "2 rules proposed by assistants") {
+		t.Fatalf("proposed rules not shown: %+v", m.proposed)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if m.state != stateProposals {
+		t.Fatalf("p did not open the proposals, state = %v", m.state)
+	}
+	review := func(key string) {
+		t.Helper()
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		if cmd == nil {
+			t.Fatalf("%s did not review the selected proposal", key)
+		}
+		_, cmd = m.Update(cmd())
+		m.Update(cmd())
+	}
+	selected := m.proposed[0].Name
+	review("a")
+	if data, err := os.ReadFile(filepath.Join(dir, selected)); err != nil || string(data) != "# "+selected+"\n" {
+		t.Errorf("accepted rule = %q, %v", data, err)
+	}
+	if len(m.proposed) != 1 || m.state != stateProposals {
+		t.Fatalf("after accepting, proposed = %+v, state = %v", m.proposed, m.state)
+	}
+
+	rejected := m.proposed[0].Path
+	review("d")
+	if _, err := os.Stat(rejected); !os.IsNotExist(err) {
+		t.Errorf("rejected proposal still staged: %v", err)
+	}
+	if len(m.proposed) != 0 || m.state != stateReady {
+		t.Errorf("after reviewing all, proposed = %+v, state = %v", m.proposed, m.state)
+	}
+}
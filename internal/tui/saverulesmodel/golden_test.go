@@ -0,0 +1,93 @@
+package saverulesmodel
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"rulem/internal/config"
+	"rulem/internal/filemanager"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/helpers/repolist"
+	"rulem/internal/tui/tuitest"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createGoldenModel builds a save model whose rendered paths are fixed, so
+// views do not depend on the temp directories of the machine running tests
+func createGoldenModel(t *testing.T, width, height int) SaveRulesModel {
+	t.Helper()
+	// No repositories: nothing is prepared and no FileManager is created
+	m := NewSaveRulesModel(helpers.NewUIContext(width, height, &config.Config{}, createTestLogger()))
+	m = tuitest.Send(t, m, tea.WindowSizeMsg{Width: width, Height: height})
+
+	items := []repolist.RepositoryListItem{
+		{ID: "personal-rules-1", Name: "Personal Rules", Type: "local", Path: "/home/user/rules", Available: true},
+		{ID: "team-rules-2", Name: "Team Rules", Type: "github", Path: "/home/user/.local/share/rulem/team-rules", Available: true},
+	}
+	var listItems []list.Item
+	for _, item := range items {
+		listItems = append(listItems, item)
+	}
+	m.repositoryList = repolist.BuildRepositoryList(listItems, m.layout.ContentWidth(), m.layout.ContentHeight())
+	m.selectedRepoItem = &items[0]
+	m.selectedFile = filemanager.FileItem{Name: "react-rules.md", Path: "/home/user/project/docs/react-rules.md"}
+	m.nameInput.SetValue("react-rules.md")
+	m.newFileName = "react-rules.md"
+	return m
+}
+
+// TestGoldenViews renders each save screen at several terminal sizes and
+// compares it with testdata/<name>_<width>x<height>.golden. Regenerate with
+// go test ./internal/tui/saverulesmodel -run TestGoldenViews -update
+func TestGoldenViews(t *testing.T) {
+	sizes := []struct{ width, height int }{{80, 24}, {120, 40}}
+	tests := []struct {
+		name  string
+		setup func(m SaveRulesModel) SaveRulesModel
+	}{
+		{name: "no_repositories", setup: func(m SaveRulesModel) SaveRulesModel { return m }},
+		{name: "loading", setup: func(m SaveRulesModel) SaveRulesModel {
+			m.state = StateLoading
+			return m
+		}},
+		{name: "filename_input", setup: func(m SaveRulesModel) SaveRulesModel {
+			m.state = StateFileNameInput
+			return m
+		}},
+		{name: "repository_selection", setup: func(m SaveRulesModel) SaveRulesModel {
+			m.state = StateRepositorySelection
+			return m
+		}},
+		{name: "confirm_overwrite", setup: func(m SaveRulesModel) SaveRulesModel {
+			m.state = StateConfirmation
+			return m
+		}},
+		{name: "saving", setup: func(m SaveRulesModel) SaveRulesModel {
+			m.state = StateSaving
+			return m
+		}},
+		{name: "success", setup: func(m SaveRulesModel) SaveRulesModel {
+			m.state = StateSuccess
+			m.destinationPath = "/home/user/rules/react-rules.md"
+			return m
+		}},
+		{name: "save_error", setup: func(m SaveRulesModel) SaveRulesModel {
+			m.state = StateError
+			m.err = errors.New("failed to copy file: permission denied")
+			return m
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, size := range sizes {
+			name := fmt.Sprintf("%s_%dx%d", tt.name, size.width, size.height)
+			t.Run(name, func(t *testing.T) {
+				m := tt.setup(createGoldenModel(t, size.width, size.height))
+				tuitest.Golden(t, name, m.View())
+			})
+		}
+	}
+}
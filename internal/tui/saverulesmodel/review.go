@@ -0,0 +1,253 @@
+package saverulesmodel
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"rulem/internal/filemanager"
+	"rulem/internal/frontmatter"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/components/form"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/styles"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Review step
+//
+// Before a rule is saved, the review screen previews it with its frontmatter
+// and lets the user edit the description and tags, adding YAML frontmatter to
+// files that have none. A rule is only saved once the MCP server would serve
+// it, so every saved rule is registered as a tool right away. Edits only
+// change the saved copy, never the source file. The review also warns when
+// the same bytes are already saved in a repository, under any name.
+
+// Fields of the review form
+const (
+	reviewFieldDescription = "description"
+	reviewFieldTags        = "tags"
+)
+
+// reviewPreviewLines is the number of body lines shown on the review screen
+const reviewPreviewLines = 8
+
+// transitionToReview opens the review screen for the selected file, keeping
+// the edits of an earlier review of the same file. Without a rule processor
+// the file is saved as it is.
+func (m SaveRulesModel) transitionToReview() (SaveRulesModel, tea.Cmd) {
+	if m.processor == nil {
+		return m.startSave()
+	}
+
+	content := m.editedContent
+	if content == nil {
+		data, err := os.ReadFile(m.selectedFile.Path)
+		if err != nil {
+			m.err = fmt.Errorf("cannot read %s: %w", m.selectedFile.Name, err)
+			m.state = StateError
+			return m, nil
+		}
+		content = data
+	}
+	m.reviewContent = content
+	m.reviewErr = nil
+
+	m.reviewMatter = frontmatter.Metadata{}
+	if matter, err := m.processor.Frontmatter(content, m.newFileName); err != nil {
+		m.reviewErr = err
+	} else {
+		m.reviewMatter = *matter
+	}
+	m.reviewIssue = m.processor.ValidateRuleContent(content, m.newFileName, m.selectedRepoID())
+	m.reviewDuplicates = m.identicalRules(content)
+
+	m.reviewForm = m.newReviewForm()
+	m.state = StateReview
+	return m, textinput.Blink
+}
+
+// newReviewForm builds the description and tags form, filled in from the
+// frontmatter. A missing description is suggested from the first heading.
+func (m SaveRulesModel) newReviewForm() form.Model {
+	f := form.New(
+		form.Field{
+			Key:                  reviewFieldDescription,
+			Label:                "Description",
+			Placeholder:          suggestDescription(m.reviewBody(), m.newFileName),
+			Hint:                 "Shown to AI assistants to decide when to use the rule (Enter accepts the suggestion)",
+			PlaceholderIsDefault: true,
+			CharLimit:            500,
+		},
+		form.Field{
+			Key:         reviewFieldTags,
+			Label:       "Tags",
+			Placeholder: "e.g., go, testing",
+			Hint:        "Comma-separated",
+			Optional:    true,
+		},
+	)
+	f = f.SetValue(reviewFieldDescription, m.reviewMatter.Description)
+	f = f.SetValue(reviewFieldTags, strings.Join(m.reviewMatter.Tags, ", "))
+	return f.SetWidth(m.layout.InputWidth())
+}
+
+// submitReview applies the edited description and tags and saves the rule,
+// or explains why the rule would not be served over MCP.
+func (m SaveRulesModel) submitReview() (SaveRulesModel, tea.Cmd) {
+	description := m.reviewForm.Value(reviewFieldDescription)
+	tags := parseTags(m.reviewForm.Value(reviewFieldTags))
+
+	content := m.reviewContent
+	changed := description != m.reviewMatter.Description || !slices.Equal(tags, m.reviewMatter.Tags)
+	if changed {
+		edited, err := m.processor.EditFrontmatter(content, m.newFileName, description, tags)
+		if err != nil {
+			m.reviewErr = err
+			return m, nil
+		}
+		content = edited
+	}
+
+	if err := m.processor.ValidateRuleContent(content, m.newFileName, m.selectedRepoID()); err != nil {
+		m.reviewErr = fmt.Errorf("the rule would not be served over MCP: %w", err)
+		return m, nil
+	}
+
+	if changed {
+		m.editedContent = content
+	}
+	m.logger.Debug("Rule reviewed for save", "file", m.newFileName, "edited", m.editedContent != nil)
+	return m.startSave()
+}
+
+// identicalRules returns where rules with exactly content are already saved,
+// as repository-relative paths followed by the repository name, so the review
+// can warn before the same rule is saved twice. The scan of the repositories
+// is shared with the other screens through the screen cache.
+func (m SaveRulesModel) identicalRules(content []byte) []string {
+	files, ok := m.cache.Files(helpers.ScanRepositories)
+	if !ok {
+		scanned, err := filemanager.ScanAllRepositories(m.preparedRepos, m.logger)
+		if err != nil {
+			m.logger.Warn("Cannot check for identical rules", "error", err)
+			return nil
+		}
+		m.cache.StoreFiles(helpers.ScanRepositories, scanned)
+		files = scanned
+	}
+
+	var found []string
+	for _, file := range filemanager.FindIdentical(files, content) {
+		if file.Path == m.selectedFile.Path {
+			continue
+		}
+		name := file.Name
+		for _, prep := range m.preparedRepos {
+			if prep.ID() != file.RepositoryID {
+				continue
+			}
+			for _, root := range []string{prep.OverlayPath, prep.LocalPath} {
+				if rel, err := filepath.Rel(root, file.Path); root != "" && err == nil && !strings.HasPrefix(rel, "..") {
+					name = filepath.ToSlash(rel)
+					break
+				}
+			}
+		}
+		if file.RepositoryName != "" {
+			name += " in " + file.RepositoryName
+		}
+		found = append(found, name)
+	}
+	return found
+}
+
+// reviewBody returns the content below the frontmatter
+func (m SaveRulesModel) reviewBody() string {
+	content := string(m.reviewContent)
+	if _, end, ok := m.processor.FrontmatterLines(m.reviewContent); ok {
+		lines := strings.SplitAfter(content, "\n")
+		content = strings.Join(lines[min(end+1, len(lines)):], "")
+	}
+	return strings.TrimLeft(content, "\r\n")
+}
+
+// selectedRepoID returns the ID of the destination repository, or empty string
+func (m SaveRulesModel) selectedRepoID() string {
+	if m.selectedRepoItem != nil {
+		return m.selectedRepoItem.ID
+	}
+	return ""
+}
+
+// parseTags splits a comma-separated tag list, dropping empty tags
+func parseTags(value string) []string {
+	var tags []string
+	for tag := range strings.SplitSeq(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// suggestDescription uses the first markdown heading, or the file name
+func suggestDescription(body, fileName string) string {
+	for line := range strings.SplitSeq(body, "\n") {
+		line = strings.TrimSpace(line)
+		if heading, ok := strings.CutPrefix(line, "#"); ok {
+			if heading = strings.TrimSpace(strings.TrimLeft(heading, "#")); heading != "" {
+				return heading
+			}
+		}
+	}
+
+	base := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	return strings.NewReplacer("-", " ", "_", " ").Replace(base)
+}
+
+// viewReview renders the frontmatter form above a preview of the rule
+func (m SaveRulesModel) viewReview() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "💾 Save Rules File - Review",
+		Subtitle: fmt.Sprintf("File: %s", path.Join(m.subdirectory, m.newFileName)),
+		HelpText: "Enter for next field, then save • ↑/↓ move • Esc to go back",
+	})
+
+	var content strings.Builder
+	if m.reviewIssue == nil {
+		content.WriteString(styles.SuccessStyle.Render("✓ Ready to be served as an MCP tool"))
+	} else {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#ffaf00")).Render("⚠ Not an MCP tool yet: " + m.reviewIssue.Error()))
+	}
+	if m.reviewMatter.Deprecated {
+		content.WriteString("\n")
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#ffaf00")).Render("⚠ Marked deprecated in its frontmatter"))
+	}
+	if len(m.reviewDuplicates) > 0 {
+		content.WriteString("\n")
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#ffaf00")).Render("⚠ Already saved as " + strings.Join(m.reviewDuplicates, ", ")))
+	}
+	content.WriteString("\n\n")
+	content.WriteString(m.reviewForm.View())
+
+	if m.reviewErr != nil {
+		content.WriteString("\n")
+		content.WriteString(styles.ErrorStyle.Render("✗ " + m.reviewErr.Error()))
+		content.WriteString("\n")
+	}
+
+	lines := strings.Split(strings.TrimRight(m.reviewBody(), "\n"), "\n")
+	if len(lines) > reviewPreviewLines {
+		lines = append(lines[:reviewPreviewLines], "…")
+	}
+	content.WriteString("\nPreview:\n")
+	content.WriteString(lipgloss.NewStyle().Faint(true).Render(strings.Join(lines, "\n")))
+
+	return m.layout.Render(content.String())
+}
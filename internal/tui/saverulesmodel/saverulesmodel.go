@@ -3,20 +3,31 @@ package saverulesmodel
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"rulem/internal/config"
 	"rulem/internal/filemanager"
+	"rulem/internal/frontmatter"
+	"rulem/internal/hooks"
 	"rulem/internal/logging"
+	"rulem/internal/mcp"
 	"rulem/internal/repository"
 	"rulem/internal/tui/components"
+	"rulem/internal/tui/components/dirbrowser"
 	"rulem/internal/tui/components/filepicker"
+	"rulem/internal/tui/components/form"
 	"rulem/internal/tui/helpers"
 	"rulem/internal/tui/helpers/repolist"
+	"rulem/internal/tui/helpers/textutil"
 	"rulem/internal/tui/styles"
+	"rulem/pkg/fileops"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 type SaveFileModelState int
@@ -26,6 +37,8 @@ const (
 	StateFileSelection                                 // Showing file picker with preview
 	StateFileNameInput                                 // Allowing user to override destination filename
 	StateRepositorySelection                           // User selecting destination repository (only if multiple)
+	StateDirectorySelection                            // User choosing (or creating) the destination folder in the repository
+	StateReview                                        // Previewing the file and editing its description and tags
 	StateConfirmation                                  // Confirming overwrite scenario
 	StateSaving                                        // Performing save
 	StateSuccess                                       // Save completed
@@ -76,6 +89,20 @@ type SaveRulesModel struct {
 	repositoryList   list.Model                      // Bubble Tea list for repository selection
 	selectedRepoItem *repolist.RepositoryListItem    // Selected repository for saving
 
+	// Destination folder inside the selected repository
+	dirBrowser   dirbrowser.Model
+	subdirectory string // folder relative to the repository root, "" for the root
+
+	// Review of the content before saving (see review.go)
+	processor        *mcp.RuleFileProcessor // nil when rule frontmatter cannot be read with the configuration
+	reviewForm       form.Model
+	reviewContent    []byte               // content shown on the review screen
+	reviewMatter     frontmatter.Metadata // frontmatter of reviewContent
+	reviewIssue      error                // why reviewContent would not be served over MCP, nil if it would
+	reviewDuplicates []string             // where reviewContent is already saved, see identicalRules
+	reviewErr        error                // why the review could not be submitted
+	editedContent    []byte               // content to save instead of the file, nil to save the file as it is
+
 	// Data
 	markdownFiles    []filemanager.FileItem
 	selectedFile     filemanager.FileItem
@@ -86,6 +113,17 @@ type SaveRulesModel struct {
 
 	// FileManager instance (for the selected repository)
 	fileManager *filemanager.FileManager
+
+	// Prepared repositories and scans kept between visits; nil caches nothing
+	cache *helpers.ScreenCache
+
+	// hooks from the configuration; rule-created hooks run once a new rule is saved
+	hooks []config.Hook
+
+	// Filename collisions: the configured default (save_collision), and the
+	// strategy of the save in progress, which ctrl+o and ctrl+r override
+	defaultCollision fileops.CollisionStrategy
+	collision        fileops.CollisionStrategy
 }
 
 func NewSaveRulesModel(ctx helpers.UIContext) SaveRulesModel {
@@ -115,7 +153,7 @@ func NewSaveRulesModel(ctx helpers.UIContext) SaveRulesModel {
 	// path that no longer exists) are skipped with a warning, and the flow
 	// proceeds with whatever is usable. PrepareAllRepositories only errors
 	// when nothing could be prepared at all.
-	prepared, err := repository.PrepareAllRepositories(context.Background(), ctx.Config.Repositories, ctx.Logger)
+	prepared, err := ctx.Cache.PrepareRepositories(ctx.Config, ctx.Logger)
 	if err != nil {
 		ctx.Logger.Error("Failed to prepare repositories", "error", err)
 		return SaveRulesModel{
@@ -188,6 +226,16 @@ func NewSaveRulesModel(ctx helpers.UIContext) SaveRulesModel {
 	// Build repository selection list (used if multiple repos)
 	repoItems := repolist.BuildRepositoryListItems(available)
 	repoListModel := repolist.BuildRepositoryList(repoItems, layout.ContentWidth(), layout.ContentHeight())
+	// Start on the repository the project's .rulem.yaml prefers
+	if preferred, err := ctx.Config.PreferredRepository(); err != nil {
+		ctx.Logger.Warn("Ignoring preferred repository", "error", err)
+	} else if preferred != nil {
+		for i, item := range repoItems {
+			if item.(repolist.RepositoryListItem).ID == preferred.ID {
+				repoListModel.Select(i)
+			}
+		}
+	}
 
 	// For single repository, auto-select and create FileManager immediately
 	var fm *filemanager.FileManager
@@ -200,12 +248,25 @@ func NewSaveRulesModel(ctx helpers.UIContext) SaveRulesModel {
 			Path:      available[0].LocalPath,
 			Available: true,
 		}
-		fm, err = filemanager.NewFileManager(available[0].LocalPath, ctx.Logger)
+		fm, err = filemanager.NewRepositoryFileManager(available[0], ctx.Logger)
 		if err != nil {
 			ctx.Logger.Error("Failed to initialize FileManager", "error", err)
 		}
 	}
 
+	// The processor checks saved rules the same way the MCP server does
+	processor, err := mcp.NewRuleFileProcessorForRepositories(ctx.Config, available, ctx.Logger)
+	if err != nil {
+		ctx.Logger.Warn("Saved rules will not be reviewed", "error", err)
+	}
+
+	// An invalid save_collision falls back to asking rather than failing the screen
+	collision, err := ctx.Config.SaveCollisionStrategy()
+	if err != nil {
+		ctx.Logger.Warn("Ignoring save_collision setting", "error", err)
+		collision = fileops.CollisionAsk
+	}
+
 	return SaveRulesModel{
 		logger:           ctx.Logger,
 		windowWidth:      ctx.Width,
@@ -225,6 +286,11 @@ func NewSaveRulesModel(ctx helpers.UIContext) SaveRulesModel {
 		err:              nil,
 		isOverwriteError: false,
 		fileManager:      fm,
+		cache:            ctx.Cache,
+		processor:        processor,
+		hooks:            ctx.Config.Hooks,
+		defaultCollision: collision,
+		collision:        collision,
 	}
 }
 
@@ -291,6 +357,9 @@ func (m SaveRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			height := m.layout.ContentHeight()
 			m.repositoryList.SetSize(width, height)
 		}
+		if m.state == StateDirectorySelection {
+			m.dirBrowser = m.dirBrowser.SetHeight(m.dirBrowserHeight())
+		}
 
 		return m, tea.Batch(cmds...)
 
@@ -308,7 +377,7 @@ func (m SaveRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		ctx := helpers.NewUIContext(m.windowWidth, m.windowHeight, nil, m.logger)
 		fp := filepicker.NewFilePicker(
 			"💾 Save Rules File",
-			"Select a markdown file to save to your central rules repository (press Enter). \nUse / to filter, arrows to navigate, g to toggle formatting.",
+			"Select a markdown file to save to your central rules repository (press Enter). \nUse / to filter, arrows to navigate, g to toggle formatting, ctrl+r to rescan.",
 			m.markdownFiles,
 			ctx,
 		)
@@ -333,6 +402,7 @@ func (m SaveRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.logger.Debug("Save rules model - File selected from picker", "path", message.File.Path)
 		m.selectedFile = message.File
 		m.newFileName = message.File.Name
+		m.editedContent = nil
 
 		// Prepare name change input
 		m.nameInput.SetValue(m.newFileName)
@@ -345,6 +415,8 @@ func (m SaveRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.destinationPath = message.DestPath
 		m.state = StateSuccess
 		m.err = nil
+		// The saved rule is not in the cached repository scans yet
+		m.cache.InvalidateFiles(helpers.ScanRepositories)
 		return m, nil
 
 	case SaveFileErrorMsg:
@@ -371,9 +443,14 @@ func (m SaveRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch m.state {
 		case StateFileSelection:
-			// Intercept 'q' and 'esc' to return to main menu instead of quitting
-			if message.String() == "q" || message.String() == "esc" {
+			// Intercept 'q' and 'esc' to leave the screen instead of quitting
+			switch message.String() {
+			case "q":
 				return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+			case "esc":
+				return m, helpers.NavigateBack
+			case "ctrl+r":
+				return m, helpers.RefreshScreen
 			}
 
 			// Delegate everything else to FilePicker
@@ -390,26 +467,31 @@ func (m SaveRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case StateFileNameInput:
 			switch message.String() {
-			case "enter":
+			case "enter", "ctrl+o", "ctrl+r":
 				m.commitOrDefaultFilename()
 				m.nameInput.Blur()
 
+				// ctrl+o and ctrl+r override the configured strategy for this save
+				switch message.String() {
+				case "ctrl+o":
+					m.collision = fileops.CollisionOverwrite
+				case "ctrl+r":
+					m.collision = fileops.CollisionRename
+				default:
+					m.collision = m.defaultCollision
+				}
+
 				// T008: If multiple repositories, prompt for selection before saving
 				if len(m.preparedRepos) > 1 {
 					m.state = StateRepositorySelection
 					return m, nil
 				}
 
-				// Single repository - proceed directly to saving
-				m.state = StateSaving
-				newNamePtr := m.optionalNewNamePtr()
-				return m, tea.Batch(
-					m.saveFileCmd(m.selectedFile.Path, newNamePtr, false),
-					m.spinner.Tick,
-				)
+				// Single repository - proceed to choosing the folder
+				return m.transitionToDirectorySelection(), nil
 			case "esc":
-				// Return to main menu instead of reverting to selection
-				return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+				// Leave the screen instead of reverting to selection
+				return m, helpers.NavigateBack
 			default:
 				m.nameInput, cmd = m.nameInput.Update(message)
 				m.newFileName = m.nameInput.Value()
@@ -433,9 +515,9 @@ func (m SaveRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.selectedRepoItem = selected
 				m.logger.Debug("Repository selected for save", "repo_id", selected.ID, "repo_name", selected.Name)
 
-				// Initialize FileManager for the selected repository
+				// Initialize FileManager for the selected repository (writes go to its overlay, if any)
 				var err error
-				m.fileManager, err = filemanager.NewFileManager(selected.Path, m.logger)
+				m.fileManager, err = m.newFileManagerFor(selected)
 				if err != nil {
 					m.logger.Error("Failed to initialize FileManager for selected repo", "error", err)
 					m.err = fmt.Errorf("failed to access repository '%s': %w", selected.Name, err)
@@ -443,13 +525,8 @@ func (m SaveRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 
-				// Proceed to saving
-				m.state = StateSaving
-				newNamePtr := m.optionalNewNamePtr()
-				return m, tea.Batch(
-					m.saveFileCmd(m.selectedFile.Path, newNamePtr, false),
-					m.spinner.Tick,
-				)
+				// Proceed to choosing the folder
+				return m.transitionToDirectorySelection(), nil
 			case "esc":
 				// Go back to filename input
 				m.nameInput.Focus()
@@ -467,13 +544,60 @@ func (m SaveRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Batch(cmds...)
 			}
 
+		case StateDirectorySelection:
+			// While a new folder is named every key goes to the browser
+			if !m.dirBrowser.Creating() {
+				switch message.String() {
+				case "esc":
+					// Go back to the previous step
+					if len(m.preparedRepos) > 1 {
+						m.state = StateRepositorySelection
+						return m, nil
+					}
+					m.nameInput.Focus()
+					m.state = StateFileNameInput
+					return m, textinput.Blink
+				case "q":
+					// Return to main menu
+					return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+				}
+			}
+
+			m.dirBrowser, cmd = m.dirBrowser.Update(message)
+			if !m.dirBrowser.Chosen() {
+				return m, cmd
+			}
+
+			m.subdirectory = m.dirBrowser.Dir()
+			m.logger.Debug("Folder selected for save", "folder", m.subdirectory)
+			return m.transitionToReview()
+
+		case StateReview:
+			if message.String() == "esc" {
+				// Go back to choosing the folder
+				m.state = StateDirectorySelection
+				return m, nil
+			}
+
+			m.reviewForm, cmd = m.reviewForm.Update(message)
+			if m.reviewForm.Submitted() {
+				return m.submitReview()
+			}
+			// Editing clears the error until the form is submitted again
+			m.reviewErr = nil
+			return m, cmd
+
 		case StateConfirmation:
 			switch message.String() {
-			case "y":
+			case "y", "r":
+				m.collision = fileops.CollisionOverwrite
+				if message.String() == "r" {
+					m.collision = fileops.CollisionRename
+				}
 				m.state = StateSaving
 				newNamePtr := m.optionalNewNamePtr()
 				return m, tea.Batch(
-					m.saveFileCmd(m.selectedFile.Path, newNamePtr, true),
+					m.saveFileCmd(m.selectedFile.Path, newNamePtr, m.collision),
 					m.spinner.Tick,
 				)
 			case "n":
@@ -517,6 +641,7 @@ func (m SaveRulesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Reset only selection-related state; keep loaded file list to avoid re-scan
 				m.selectedFile = filemanager.FileItem{}
 				m.newFileName = ""
+				m.editedContent = nil
 				m.destinationPath = ""
 				m.nameInput.SetValue("")
 				m.state = StateFileSelection
@@ -554,6 +679,10 @@ func (m SaveRulesModel) View() string {
 		return m.viewFileNameInput()
 	case StateRepositorySelection:
 		return m.viewRepositorySelection()
+	case StateDirectorySelection:
+		return m.viewDirectorySelection()
+	case StateReview:
+		return m.viewReview()
 	case StateConfirmation:
 		return m.viewConfirmation()
 	case StateSaving:
@@ -583,18 +712,18 @@ func (m SaveRulesModel) viewFileNameInput() string {
 	m.layout = m.layout.SetConfig(components.LayoutConfig{
 		Title:    "💾 Save Rules File",
 		Subtitle: fmt.Sprintf("Selected: %s", m.selectedFile.Name),
-		HelpText: "Enter filename (or keep default) • Enter to continue • Esc to go back",
+		HelpText: "Enter to save • ctrl+r keep both • ctrl+o overwrite • Esc to go back",
 	})
 
 	// Handle the case where FileManager may not be initialized yet (multi-repo)
 	storageDir := "central repository"
 	if m.fileManager != nil {
-		storageDir = m.fileManager.GetStorageDir()
+		storageDir = m.fileManager.GetWriteDir()
 	} else if m.selectedRepoItem != nil {
 		storageDir = m.selectedRepoItem.Path
 	}
 
-	content := fmt.Sprintf("File will be saved to: %s\n\n", storageDir)
+	content := fmt.Sprintf("File will be saved to: %s\n\n", textutil.TruncatePath(storageDir, m.layout.ContentWidth()-lipgloss.Width("File will be saved to: ")))
 	content += "Filename:\n"
 	content += m.nameInput.View()
 	content += "\n\n"
@@ -617,24 +746,37 @@ func (m SaveRulesModel) viewRepositorySelection() string {
 	return m.layout.Render(content)
 }
 
+// viewDirectorySelection renders the folder browser for the destination folder
+func (m SaveRulesModel) viewDirectorySelection() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "💾 Save Rules File - Choose Folder",
+		Subtitle: fmt.Sprintf("File: %s", m.newFileName),
+		HelpText: "Enter to open or use • n new folder • ← up • Esc to go back • q to cancel",
+	})
+
+	root := textutil.TruncatePath(m.destinationRoot(), m.layout.ContentWidth()-lipgloss.Width("Choose the folder in  to save the file to:"))
+	content := fmt.Sprintf("Choose the folder in %s to save the file to:\n\n", root)
+	content += m.dirBrowser.View()
+
+	return m.layout.Render(content)
+}
+
 func (m SaveRulesModel) viewConfirmation() string {
 	m.layout = m.layout.SetConfig(components.LayoutConfig{
 		Title:    "💾 Save Rules File - Confirm Overwrite",
 		Subtitle: "File already exists",
-		HelpText: "y to overwrite • n to change filename • Esc to cancel",
+		HelpText: "y to overwrite • r to keep both • n for another name • Esc to cancel",
 	})
 
 	// Handle case where FileManager may not be initialized (multi-repo)
 	storageDir := "the storage directory"
-	if m.fileManager != nil {
-		storageDir = m.fileManager.GetStorageDir()
-	} else if m.selectedRepoItem != nil {
-		storageDir = m.selectedRepoItem.Path
+	if m.fileManager != nil || m.selectedRepoItem != nil {
+		storageDir = filepath.Join(m.destinationRoot(), filepath.FromSlash(m.subdirectory))
 	}
 
 	content := fmt.Sprintf("A file named '%s' already exists in the storage directory.\n\n", m.newFileName)
 	content += "Do you want to overwrite it?\n\n"
-	content += "Storage directory: " + storageDir
+	content += "Storage directory: " + textutil.TruncatePath(storageDir, m.layout.ContentWidth()-lipgloss.Width("Storage directory: "))
 	return m.layout.Render(content)
 }
 
@@ -656,8 +798,14 @@ func (m SaveRulesModel) viewSuccess() string {
 		HelpText: "m to return to main menu • a to save another file",
 	})
 	content := "✅ File saved successfully!\n\n"
-	content += fmt.Sprintf("Source: %s\n", m.selectedFile.Path)
-	content += fmt.Sprintf("Destination: %s\n\n", m.destinationPath)
+	content += fmt.Sprintf("Source: %s\n", textutil.TruncatePath(m.selectedFile.Path, m.layout.ContentWidth()-lipgloss.Width("Source: ")))
+	// The destination is shortened to fit, with the full path on a detail line
+	destination, detail := textutil.WithDetail(m.destinationPath, m.layout.ContentWidth()-lipgloss.Width("Destination: "), m.layout.ContentWidth(), textutil.TruncatePath)
+	content += fmt.Sprintf("Destination: %s\n", destination)
+	if detail != "" {
+		content += lipgloss.NewStyle().Faint(true).Render(detail) + "\n"
+	}
+	content += "\n"
 	content += "The file has been copied to your rules storage directory."
 	return m.layout.Render(content)
 }
@@ -685,6 +833,48 @@ func (m *SaveRulesModel) commitOrDefaultFilename() {
 	}
 }
 
+// destinationRoot returns the directory of the selected repository that new
+// files are written to.
+func (m SaveRulesModel) destinationRoot() string {
+	if m.fileManager != nil {
+		return m.fileManager.GetWriteDir()
+	}
+	if m.selectedRepoItem != nil {
+		return m.selectedRepoItem.Path
+	}
+	return "the repository"
+}
+
+// transitionToDirectorySelection opens the folder browser for the selected
+// repository, starting in the folder picked for the previous save, if any.
+func (m SaveRulesModel) transitionToDirectorySelection() SaveRulesModel {
+	if m.fileManager == nil {
+		m.err = fmt.Errorf("FileManager not initialized")
+		m.state = StateError
+		return m
+	}
+	m.dirBrowser = dirbrowser.New(m.fileManager.ListSubdirectories).
+		Open(m.subdirectory).
+		SetHeight(m.dirBrowserHeight())
+	m.state = StateDirectorySelection
+	return m
+}
+
+// dirBrowserHeight returns the number of folder rows that fit the layout below
+// the location and instructions
+func (m SaveRulesModel) dirBrowserHeight() int {
+	return max(3, m.layout.ContentHeight()-6)
+}
+
+// startSave saves the selected file with the chosen name, folder and collision strategy
+func (m SaveRulesModel) startSave() (SaveRulesModel, tea.Cmd) {
+	m.state = StateSaving
+	return m, tea.Batch(
+		m.saveFileCmd(m.selectedFile.Path, m.optionalNewNamePtr(), m.collision),
+		m.spinner.Tick,
+	)
+}
+
 // optionalNewNamePtr returns a pointer only if user changed the name (so FileManager can preserve original otherwise).
 func (m *SaveRulesModel) optionalNewNamePtr() *string {
 	if m.newFileName != "" && m.newFileName != m.selectedFile.Name {
@@ -700,10 +890,14 @@ func (m *SaveRulesModel) optionalNewNamePtr() *string {
 func (m SaveRulesModel) scanForFilesCmd() tea.Cmd {
 	m.logger.Debug("File scan started")
 	return func() tea.Msg {
+		if files, ok := m.cache.Files(helpers.ScanWorkingDir); ok {
+			return FileScanCompleteMsg{Files: files}
+		}
 		files, err := m.fileManager.ScanCurrDirectory()
 		if err != nil {
 			return FileScanErrorMsg{Err: err}
 		}
+		m.cache.StoreFiles(helpers.ScanWorkingDir, files)
 		return FileScanCompleteMsg{Files: files}
 	}
 }
@@ -720,6 +914,9 @@ func (m SaveRulesModel) scanForFilesCmdNoManager() tea.Cmd {
 		if len(m.preparedRepos) == 0 {
 			return FileScanErrorMsg{Err: fmt.Errorf("no repositories available")}
 		}
+		if files, ok := m.cache.Files(helpers.ScanWorkingDir); ok {
+			return FileScanCompleteMsg{Files: files}
+		}
 
 		tempFm, err := filemanager.NewFileManager(m.preparedRepos[0].LocalPath, m.logger)
 		if err != nil {
@@ -730,15 +927,29 @@ func (m SaveRulesModel) scanForFilesCmdNoManager() tea.Cmd {
 		if err != nil {
 			return FileScanErrorMsg{Err: err}
 		}
+		m.cache.StoreFiles(helpers.ScanWorkingDir, files)
 
 		// Files already have absolute paths from ScanCurrDirectory
 		return FileScanCompleteMsg{Files: files}
 	}
 }
 
-// saveFileCmd copies the selected file into the storage directory (with optional rename + overwrite).
-func (m SaveRulesModel) saveFileCmd(filePath string, newFileName *string, overwrite bool) tea.Cmd {
-	m.logger.Debug("Starting file save operation", "file", filePath, "newName", newFileName, "overwrite", overwrite)
+// newFileManagerFor creates the FileManager for a repository picked from the list,
+// using its prepared entry so shared storage writes are redirected to the overlay.
+func (m SaveRulesModel) newFileManagerFor(selected *repolist.RepositoryListItem) (*filemanager.FileManager, error) {
+	for _, prep := range m.preparedRepos {
+		if prep.ID() == selected.ID {
+			return filemanager.NewRepositoryFileManager(prep, m.logger)
+		}
+	}
+	return filemanager.NewFileManager(selected.Path, m.logger)
+}
+
+// saveFileCmd copies the selected file, or its content as edited on the review
+// screen, into the chosen folder of the storage directory (with optional
+// rename), handling an existing file according to collision.
+func (m SaveRulesModel) saveFileCmd(filePath string, newFileName *string, collision fileops.CollisionStrategy) tea.Cmd {
+	m.logger.Debug("Starting file save operation", "file", filePath, "newName", newFileName, "folder", m.subdirectory, "collision", collision)
 	return func() tea.Msg {
 		if m.fileManager == nil {
 			return SaveFileErrorMsg{
@@ -747,7 +958,26 @@ func (m SaveRulesModel) saveFileCmd(filePath string, newFileName *string, overwr
 			}
 		}
 
-		destPath, err := m.fileManager.CopyFileToStorage(filePath, newFileName, overwrite)
+		fm, err := m.fileManager.InSubdirectory(m.subdirectory)
+		if err != nil {
+			return SaveFileErrorMsg{Err: err, IsOverwriteError: false}
+		}
+
+		// Edited content is saved through a temporary copy named like the source
+		if m.editedContent != nil {
+			tmpDir, err := os.MkdirTemp("", "rulem-save-")
+			if err != nil {
+				return SaveFileErrorMsg{Err: fmt.Errorf("cannot stage edited rule: %w", err), IsOverwriteError: false}
+			}
+			defer os.RemoveAll(tmpDir)
+
+			filePath = filepath.Join(tmpDir, filepath.Base(filePath))
+			if err := os.WriteFile(filePath, m.editedContent, 0644); err != nil {
+				return SaveFileErrorMsg{Err: fmt.Errorf("cannot stage edited rule: %w", err), IsOverwriteError: false}
+			}
+		}
+
+		destPath, err := fm.SaveFileToStorage(filePath, newFileName, collision)
 		if err != nil {
 			isOverwriteError := strings.Contains(err.Error(), "already exists")
 			return SaveFileErrorMsg{
@@ -755,6 +985,22 @@ func (m SaveRulesModel) saveFileCmd(filePath string, newFileName *string, overwr
 				IsOverwriteError: isOverwriteError,
 			}
 		}
+		if collision != fileops.CollisionOverwrite {
+			m.notifyRuleCreated(destPath)
+		}
 		return SaveFileCompleteMsg{DestPath: destPath}
 	}
 }
+
+// notifyRuleCreated runs the rule-created hooks for a rule saved to the selected repository
+func (m SaveRulesModel) notifyRuleCreated(path string) {
+	if len(m.hooks) == 0 || m.selectedRepoItem == nil {
+		return
+	}
+	for _, prep := range m.preparedRepos {
+		if prep.ID() == m.selectedRepoItem.ID {
+			hooks.Notify(context.Background(), m.hooks, hooks.RulePayload(hooks.EventRuleCreated, prep, path), m.logger)
+			return
+		}
+	}
+}
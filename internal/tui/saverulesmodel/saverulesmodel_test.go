@@ -11,6 +11,8 @@ import (
 	"rulem/internal/repository"
 	"rulem/internal/tui/components/filepicker"
 	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
+	"rulem/pkg/fileops"
 	"strings"
 	"testing"
 
@@ -169,11 +171,7 @@ func TestCompleteSaveWorkflow(t *testing.T) {
 	}
 
 	scanMsg := FileScanCompleteMsg{Files: files}
-	updatedModel, _ := model.Update(scanMsg)
-	model, ok := updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
-	}
+	model = tuitest.Send(t, model, scanMsg)
 
 	// 2. Should be in file selection state
 	if model.state != StateFileSelection {
@@ -182,41 +180,38 @@ func TestCompleteSaveWorkflow(t *testing.T) {
 
 	// 3. Select a file
 	fileSelectedMsg := filepicker.FileSelectedMsg{File: model.markdownFiles[0]}
-	updatedModel, _ = model.Update(fileSelectedMsg)
-	model, ok = updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
-	}
+	model = tuitest.Send(t, model, fileSelectedMsg)
 
 	// 4. Should be in filename input state
 	if model.state != StateFileNameInput {
 		t.Errorf("Expected state %v, got %v", StateFileNameInput, model.state)
 	}
 
-	// 5. Enter custom filename and submit
+	// 5. Enter custom filename and submit, then keep the repository root
 	model.nameInput.SetValue("custom-rule.md")
-	keyMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, _ = model.Update(keyMsg)
-	model, ok = updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
+	model = tuitest.Send(t, model, tuitest.Key("enter"))
+	if model.state != StateDirectorySelection {
+		t.Errorf("Expected state %v, got %v", StateDirectorySelection, model.state)
+	}
+	model = tuitest.Send(t, model, tuitest.Key("enter"))
+
+	// 6. Accept the suggested description and no tags on the review screen
+	if model.state != StateReview {
+		t.Errorf("Expected state %v, got %v", StateReview, model.state)
 	}
+	model = tuitest.Send(t, model, tuitest.Keys("enter", "enter")...)
 
-	// 6. Should transition to saving state
+	// 7. Should transition to saving state
 	if model.state != StateSaving {
 		t.Errorf("Expected state %v, got %v", StateSaving, model.state)
 	}
 
-	// 7. Simulate successful save
+	// 8. Simulate successful save
 	destPath := filepath.Join(model.fileManager.GetStorageDir(), "custom-rule.md")
 	successMsg := SaveFileCompleteMsg{DestPath: destPath}
-	updatedModel, _ = model.Update(successMsg)
-	model, ok = updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
-	}
+	model = tuitest.Send(t, model, successMsg)
 
-	// 8. Should be in success state
+	// 9. Should be in success state
 	if model.state != StateSuccess {
 		t.Errorf("Expected state %v, got %v", StateSuccess, model.state)
 	}
@@ -247,11 +242,7 @@ func TestSaveWorkflowWithOverwrite(t *testing.T) {
 	}
 
 	scanMsg := FileScanCompleteMsg{Files: files}
-	updatedModel, _ := model.Update(scanMsg)
-	model, ok := updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
-	}
+	model = tuitest.Send(t, model, scanMsg)
 
 	// 2. Select a file
 	if model.state != StateFileSelection {
@@ -259,25 +250,17 @@ func TestSaveWorkflowWithOverwrite(t *testing.T) {
 	}
 
 	fileSelectedMsg := filepicker.FileSelectedMsg{File: model.markdownFiles[0]}
-	updatedModel, _ = model.Update(fileSelectedMsg)
-	model, ok = updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
-	}
+	model = tuitest.Send(t, model, fileSelectedMsg)
 
 	// 3. Should be in filename input state
 	if model.state != StateFileNameInput {
 		t.Errorf("Expected state %v, got %v", StateFileNameInput, model.state)
 	}
 
-	// 4. Enter conflicting filename and submit
+	// 4. Enter conflicting filename and submit, saving to the repository root
+	// with the suggested description
 	model.nameInput.SetValue("conflict.md")
-	keyMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, _ = model.Update(keyMsg)
-	model, ok = updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
-	}
+	model = tuitest.Send(t, model, tuitest.Keys("enter", "enter", "enter", "enter")...)
 
 	// 5. Should transition to saving state
 	if model.state != StateSaving {
@@ -289,11 +272,7 @@ func TestSaveWorkflowWithOverwrite(t *testing.T) {
 		Err:              errors.New("destination file already exists: conflict.md (use overwrite=true to replace)"),
 		IsOverwriteError: true,
 	}
-	updatedModel, _ = model.Update(errorMsg)
-	model, ok = updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
-	}
+	model = tuitest.Send(t, model, errorMsg)
 
 	// 7. Should be in confirmation state
 	if model.state != StateConfirmation {
@@ -305,12 +284,7 @@ func TestSaveWorkflowWithOverwrite(t *testing.T) {
 	}
 
 	// 8. Confirm overwrite
-	keyMsg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}
-	updatedModel, _ = model.Update(keyMsg)
-	model, ok = updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
-	}
+	model = tuitest.Send(t, model, tuitest.Key("y"))
 
 	// 9. Should transition back to saving state
 	if model.state != StateSaving {
@@ -319,11 +293,7 @@ func TestSaveWorkflowWithOverwrite(t *testing.T) {
 
 	// 10. Simulate successful save
 	successMsg := SaveFileCompleteMsg{DestPath: conflictFile}
-	updatedModel, _ = model.Update(successMsg)
-	model, ok = updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
-	}
+	model = tuitest.Send(t, model, successMsg)
 
 	// 11. Should be in success state
 	if model.state != StateSuccess {
@@ -335,6 +305,206 @@ func TestSaveWorkflowWithOverwrite(t *testing.T) {
 	}
 }
 
+func TestSaveWorkflowCollisionStrategies(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure string // save_collision
+		key       string // key submitting the filename
+		want      string // saved file name
+		content   string // content of conflict.md afterwards
+	}{
+		{name: "configured rename", configure: "rename", key: "enter", want: "conflict-2.md", content: "existing content"},
+		{name: "configured overwrite", configure: "overwrite", key: "enter", want: "conflict.md"},
+		{name: "ctrl+r overrides ask", key: "ctrl+r", want: "conflict-2.md", content: "existing content"},
+		{name: "ctrl+o overrides rename", configure: "rename", key: "ctrl+o", want: "conflict.md"},
+	}
+
+	// The source is a valid rule, so the review screen saves it unchanged
+	newContent := "---\ndescription: New rule\n---\nnew content\n"
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := createTestWorkingDir(t)
+			srcPath := filepath.Join(workDir, "source.md")
+			if err := os.WriteFile(srcPath, []byte(newContent), 0644); err != nil {
+				t.Fatalf("Failed to create source file: %v", err)
+			}
+			storageDir := createTestStorageDir(t)
+			conflictFile := filepath.Join(storageDir, "conflict.md")
+			if err := os.WriteFile(conflictFile, []byte("existing content"), 0644); err != nil {
+				t.Fatalf("Failed to create conflict file: %v", err)
+			}
+
+			cfg := createTestConfigWithPath(storageDir)
+			cfg.SaveCollision = tt.configure
+			model := NewSaveRulesModel(helpers.NewUIContext(80, 24, cfg, createTestLogger()))
+			model = tuitest.Send(t, model, filepicker.FileSelectedMsg{File: filemanager.FileItem{Name: "source.md", Path: srcPath}})
+			model.nameInput.SetValue("conflict.md")
+
+			keep := func(msg tea.Msg) bool {
+				_, done := msg.(SaveFileCompleteMsg)
+				_, failed := msg.(SaveFileErrorMsg)
+				return done || failed
+			}
+			model, msgs := tuitest.Run(t, model, keep, tuitest.Key(tt.key), tuitest.Key("enter"), tuitest.Key("enter"), tuitest.Key("enter"))
+			msg, ok := tuitest.FindMsg[SaveFileCompleteMsg](msgs)
+			if !ok || model.state != StateSuccess {
+				t.Fatalf("Expected a successful save, got state %v and messages %v", model.state, msgs)
+			}
+			if filepath.Base(msg.DestPath) != tt.want {
+				t.Errorf("Saved as %s, want %s", filepath.Base(msg.DestPath), tt.want)
+			}
+			want := tt.content
+			if want == "" {
+				want = newContent
+			}
+			if content, _ := os.ReadFile(conflictFile); string(content) != want {
+				t.Errorf("conflict.md holds %q, want %q", content, want)
+			}
+		})
+	}
+}
+
+func TestSaveWorkflowIntoFolder(t *testing.T) {
+	workDir := createTestWorkingDir(t)
+	srcPath := filepath.Join(workDir, "hooks.md")
+	if err := os.WriteFile(srcPath, []byte("# hooks"), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+	storageDir := createTestStorageDir(t)
+	if err := os.MkdirAll(filepath.Join(storageDir, "frontend"), 0755); err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+
+	model := NewSaveRulesModel(helpers.NewUIContext(80, 24, createTestConfigWithPath(storageDir), createTestLogger()))
+	model = tuitest.Send(t, model, filepicker.FileSelectedMsg{File: filemanager.FileItem{Name: "hooks.md", Path: srcPath}})
+	model = tuitest.Send(t, model, tuitest.Key("enter"))
+	if model.state != StateDirectorySelection {
+		t.Fatalf("Expected state %v, got %v", StateDirectorySelection, model.state)
+	}
+	if view := model.View(); !strings.Contains(view, "frontend/") {
+		t.Errorf("Expected the existing folder to be listed, got:\n%s", view)
+	}
+
+	// Open frontend, create react inside it and save there
+	model = tuitest.Send(t, model, tuitest.Keys("down", "enter", "n")...)
+	model = tuitest.Send(t, model, tuitest.Type("react")...)
+	keep := func(msg tea.Msg) bool {
+		_, done := msg.(SaveFileCompleteMsg)
+		_, failed := msg.(SaveFileErrorMsg)
+		return done || failed
+	}
+	model, msgs := tuitest.Run(t, model, keep, tuitest.Keys("enter", "enter", "enter", "enter")...)
+	msg, ok := tuitest.FindMsg[SaveFileCompleteMsg](msgs)
+	if !ok || model.state != StateSuccess {
+		t.Fatalf("Expected a successful save, got state %v and messages %v", model.state, msgs)
+	}
+	if want := filepath.Join(storageDir, "frontend", "react", "hooks.md"); msg.DestPath != want {
+		t.Errorf("Saved to %s, want %s", msg.DestPath, want)
+	}
+
+	// Saving another file starts in the same folder
+	model = tuitest.Send(t, model, tuitest.Key("a"))
+	model = tuitest.Send(t, model, filepicker.FileSelectedMsg{File: filemanager.FileItem{Name: "hooks.md", Path: srcPath}})
+	model = tuitest.Send(t, model, tuitest.Key("enter"))
+	if model.dirBrowser.Dir() != "frontend/react" {
+		t.Errorf("Expected the browser to open frontend/react, got %q", model.dirBrowser.Dir())
+	}
+
+	// Esc goes back to the filename
+	model = tuitest.Send(t, model, tuitest.Key("esc"))
+	if model.state != StateFileNameInput {
+		t.Errorf("Expected state %v after esc, got %v", StateFileNameInput, model.state)
+	}
+}
+
+func TestSaveWorkflowReview(t *testing.T) {
+	workDir := createTestWorkingDir(t)
+	srcPath := filepath.Join(workDir, "testing.md")
+	source := "# Testing\nUse table-driven tests.\n"
+	if err := os.WriteFile(srcPath, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+	storageDir := createTestStorageDir(t)
+
+	model := NewSaveRulesModel(helpers.NewUIContext(80, 24, createTestConfigWithPath(storageDir), createTestLogger()))
+	model = tuitest.Send(t, model, filepicker.FileSelectedMsg{File: filemanager.FileItem{Name: "testing.md", Path: srcPath}})
+	model = tuitest.Send(t, model, tuitest.Keys("enter", "enter")...)
+	if model.state != StateReview {
+		t.Fatalf("Expected state %v, got %v", StateReview, model.state)
+	}
+	view := model.View()
+	for _, want := range []string{"Not an MCP tool yet", "Testing", "Use table-driven tests."} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the review to show %q, got:\n%s", want, view)
+		}
+	}
+
+	// Esc goes back to the folder and keeps nothing from the review
+	model = tuitest.Send(t, model, tuitest.Key("esc"))
+	if model.state != StateDirectorySelection {
+		t.Fatalf("Expected state %v after esc, got %v", StateDirectorySelection, model.state)
+	}
+	model = tuitest.Send(t, model, tuitest.Key("enter"))
+
+	model = tuitest.Send(t, model, tuitest.Type("Go testing conventions")...)
+	model = tuitest.Send(t, model, tuitest.Key("enter"))
+	model = tuitest.Send(t, model, tuitest.Type("go, testing")...)
+	keep := func(msg tea.Msg) bool {
+		_, done := msg.(SaveFileCompleteMsg)
+		_, failed := msg.(SaveFileErrorMsg)
+		return done || failed
+	}
+	model, msgs := tuitest.Run(t, model, keep, tuitest.Key("enter"))
+	msg, ok := tuitest.FindMsg[SaveFileCompleteMsg](msgs)
+	if !ok || model.state != StateSuccess {
+		t.Fatalf("Expected a successful save, got state %v and messages %v", model.state, msgs)
+	}
+
+	want := "---\ndescription: Go testing conventions\ntags: [go, testing]\n---\n\n" + source
+	if content, _ := os.ReadFile(msg.DestPath); string(content) != want {
+		t.Errorf("Saved rule holds %q, want %q", content, want)
+	}
+	if content, _ := os.ReadFile(srcPath); string(content) != source {
+		t.Errorf("Source file must not be edited, got %q", content)
+	}
+}
+
+func TestSaveWorkflowReviewWarnsAboutIdenticalRule(t *testing.T) {
+	workDir := createTestWorkingDir(t)
+	srcPath := filepath.Join(workDir, "testing-copy.md")
+	source := "# Testing\nUse table-driven tests.\n"
+	if err := os.WriteFile(srcPath, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+	storageDir := createTestStorageDir(t)
+	if err := os.MkdirAll(filepath.Join(storageDir, "go"), 0755); err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(storageDir, "go", "testing.md"), []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to create saved rule: %v", err)
+	}
+
+	model := NewSaveRulesModel(helpers.NewUIContext(80, 24, createTestConfigWithPath(storageDir), createTestLogger()))
+	model = tuitest.Send(t, model, filepicker.FileSelectedMsg{File: filemanager.FileItem{Name: "testing-copy.md", Path: srcPath}})
+	model = tuitest.Send(t, model, tuitest.Keys("enter", "enter")...)
+	if model.state != StateReview {
+		t.Fatalf("Expected state %v, got %v", StateReview, model.state)
+	}
+	if view := model.View(); !strings.Contains(view, "Already saved as go/testing.md") {
+		t.Errorf("Expected the review to warn about the identical rule, got:\n%s", view)
+	}
+
+	// Changing a single byte is a different rule
+	if err := os.WriteFile(filepath.Join(storageDir, "go", "testing.md"), []byte(source+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to update saved rule: %v", err)
+	}
+	model = tuitest.Send(t, model, tuitest.Keys("esc", "enter")...)
+	if view := model.View(); strings.Contains(view, "Already saved") {
+		t.Errorf("Expected no warning for different content, got:\n%s", view)
+	}
+}
+
 func TestCancelWorkflow(t *testing.T) {
 	model, files, _ := createTestModelWithFiles(t)
 
@@ -944,16 +1114,28 @@ func TestSaveRulesModel_KeyHandling_FileNameInput(t *testing.T) {
 		t.Error("Update should return SaveRulesModel")
 	}
 
-	if result.state != StateSaving {
-		t.Errorf("Expected state %v after Enter, got %v", StateSaving, result.state)
+	if result.state != StateDirectorySelection {
+		t.Errorf("Expected state %v after Enter, got %v", StateDirectorySelection, result.state)
 	}
 
 	if result.nameInput.Focused() {
 		t.Error("Name input should be blurred after submit")
 	}
 
+	// Enter on the folder browser keeps the repository root
+	result = tuitest.Send(t, result, keyMsg)
+	if result.state != StateReview {
+		t.Errorf("Expected state %v after choosing the folder, got %v", StateReview, result.state)
+	}
+
+	// Enter twice on the review screen accepts the suggested description
+	result, cmd = tuitest.SendCmd(t, result, keyMsg, keyMsg)
+	if result.state != StateSaving {
+		t.Errorf("Expected state %v after the review, got %v", StateSaving, result.state)
+	}
+
 	if cmd == nil {
-		t.Error("Should return save command after Enter")
+		t.Error("Should return save command after the review")
 	}
 
 	// Test Escape key
@@ -996,6 +1178,7 @@ func TestSaveRulesModel_KeyHandling_Confirmation(t *testing.T) {
 		expectsCmd    bool
 	}{
 		{"yes", "y", StateSaving, true},
+		{"keep both", "r", StateSaving, true},
 		{"no", "n", StateFileNameInput, true},
 		{"escape", "esc", StateLoading, true}, // Should navigate to main menu
 	}
@@ -1255,7 +1438,7 @@ func TestSaveRulesModel_SaveFileCmd(t *testing.T) {
 
 	// Test with nil FileManager
 	model.fileManager = nil
-	cmd := model.saveFileCmd("test.md", nil, false)
+	cmd := model.saveFileCmd("test.md", nil, fileops.CollisionAsk)
 	if cmd == nil {
 		t.Error("Command should not be nil")
 	}
@@ -1435,14 +1618,11 @@ func TestSaveRulesModel_BrokenSymlinkHandling(t *testing.T) {
 		t.Fatal("Update should return SaveRulesModel")
 	}
 
-	// Enter conflicting filename that matches broken symlink
+	// Enter conflicting filename that matches broken symlink, saving to the root
+	// with the suggested description
 	model.nameInput.SetValue("broken-link.md")
 	keyMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, _ = model.Update(keyMsg)
-	model, ok = updatedModel.(SaveRulesModel)
-	if !ok {
-		t.Fatal("Update should return SaveRulesModel")
-	}
+	model = tuitest.Send(t, model, keyMsg, keyMsg, keyMsg, keyMsg)
 
 	// Should transition to saving state
 	if model.state != StateSaving {
@@ -6,13 +6,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"rulem/internal/config"
 	"rulem/internal/repository"
 	"rulem/internal/tui/components"
+	"rulem/internal/tui/components/form"
+	"rulem/internal/tui/helpers"
 	"rulem/internal/tui/helpers/settingshelpers"
+	"rulem/internal/tui/helpers/textutil"
 	"rulem/pkg/fileops"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,7 +21,7 @@ import (
 )
 
 // Add GitHub Repository Flow
-// Flow: AddRepositoryType → AddGitHubName → AddGitHubURL → AddGitHubBranch → AddGitHubPath → [Optional: AddGitHubPAT] → [AddGitHubError | Complete]
+// Flow: AddRepositoryType → AddGitHubForm → [Optional: AddGitHubPAT] → [AddGitHubError | Complete]
 //
 // This file contains all handlers, transitions, and business logic for adding
 // a new GitHub repository to the configuration.
@@ -30,11 +31,12 @@ import (
 // where the user can enter a new PAT. This provides a seamless experience for adding
 // the first GitHub repository without separately configuring authentication.
 //
+// The name, URL, branch and clone path are entered on a single form screen
+// (components/form) with inline validation, so there is one state to enter and
+// one Esc path out of it.
+//
 // Handlers (in order of flow):
-// - handleAddGitHubNameKeys: Validates and stores repository name
-// - handleAddGitHubURLKeys: Validates and stores GitHub URL
-// - handleAddGitHubBranchKeys: Validates and stores branch (optional)
-// - handleAddGitHubPathKeys: Validates clone path and triggers creation
+// - handleAddGitHubFormKeys: Validates all fields, stores them and triggers creation
 // - handleAddGitHubPATKeys: [Optional] Handles PAT input when needed
 // - handleAddGitHubErrorKeys: Handles error state dismissal
 //
@@ -43,174 +45,138 @@ import (
 // - createGitHubRepositoryWithPAT: Creates repository after PAT is entered
 //
 // Views (at end of file):
-// - viewAddGitHubName, viewAddGitHubURL, viewAddGitHubBranch, viewAddGitHubPath
-// - viewAddGitHubPAT, viewAddGitHubError
-
-// handleAddGitHubNameKeys processes user input in the AddGitHubName state.
-// Validates the repository name and proceeds to URL input if valid.
-func (m *SettingsModel) handleAddGitHubNameKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
-	switch msg.String() {
-	case "enter":
-		input := strings.TrimSpace(m.textInput.Value())
-		m.logger.LogUserAction("settings_add_github_name_submit", input)
-
-		// Validate name (same as local)
-		if input == "" {
-			m.layout = m.layout.SetError(fmt.Errorf("repository name cannot be empty"))
-			return m, nil
-		}
-		if len(input) > 100 {
-			m.layout = m.layout.SetError(fmt.Errorf("repository name must be 100 characters or less"))
-			return m, nil
-		}
+// - viewAddGitHubForm, viewAddGitHubPAT, viewAddGitHubError
+
+// Add GitHub form field keys
+const (
+	gitHubFieldName   = "name"
+	gitHubFieldURL    = "url"
+	gitHubFieldBranch = "branch"
+	gitHubFieldPath   = "path"
+)
 
-		// Check for duplicate name
-		if _, err := m.currentConfig.FindRepositoryByName(input); err == nil {
-			m.layout = m.layout.SetError(fmt.Errorf("repository name already exists"))
-			return m, nil
-		}
+// newAddGitHubForm builds the single-screen form for a new GitHub repository.
+// Validators close over the model so duplicate checks see the current config.
+func (m *SettingsModel) newAddGitHubForm() form.Model {
+	f := form.New(
+		form.Field{
+			Key:         gitHubFieldName,
+			Label:       "Repository Name",
+			Placeholder: "e.g., My GitHub Repository",
+			Hint:        "This name will help you identify the repository",
+			CharLimit:   100,
+			Validate:    m.validateNewRepositoryName,
+		},
+		form.Field{
+			Key:         gitHubFieldURL,
+			Label:       "GitHub URL",
+			Placeholder: "e.g., https://github.com/user/repo",
+			Hint:        "Format: https://github.com/username/repository",
+			Validate:    m.validateNewGitHubURL,
+		},
+		form.Field{
+			Key:         gitHubFieldBranch,
+			Label:       "Branch",
+			Placeholder: "e.g., main (leave empty for default)",
+			Hint:        "Leave empty to use the default branch",
+			Optional:    true,
+			Validate:    settingshelpers.ValidateBranchName,
+		},
+		form.Field{
+			Key:                  gitHubFieldPath,
+			Label:                "Local Clone Path",
+			Placeholder:          repository.GetDefaultStorageDir(),
+			Hint:                 "Where to clone the repository locally (Enter accepts the suggestion)",
+			PlaceholderIsDefault: true,
+			Validate:             m.validateNewClonePath,
+		},
+	)
+	return f.SetWidth(m.layout.InputWidth())
+}
 
-		m.addRepositoryName = input
-		m.textInput.SetValue("")
-		m.textInput.Placeholder = "e.g., https://github.com/user/repo"
-		return m.transitionTo(SettingsStateAddGitHubURL), nil
-	case "esc":
+// handleAddGitHubFormKeys processes input in the AddGitHubForm state.
+// The form handles field navigation and inline validation; on submit the
+// values are stored and repository creation starts.
+func (m *SettingsModel) handleAddGitHubFormKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	if msg.String() == "esc" {
 		m.logger.LogUserAction("settings_add_github_cancelled", "returning to type selection")
 		return m.transitionTo(SettingsStateAddRepositoryType), nil
-	default:
-		return m.updateTextInput(msg)
 	}
-}
-
-// handleAddGitHubURLKeys processes user input in the AddGitHubURL state.
-// Validates the GitHub URL and proceeds to branch input if valid.
-func (m *SettingsModel) handleAddGitHubURLKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
-	switch msg.String() {
-	case "enter":
-		input := strings.TrimSpace(m.textInput.Value())
-		m.logger.LogUserAction("settings_add_github_url_submit", input)
 
-		// Validate GitHub URL
-		if err := settingshelpers.ValidateGitHubURL(input); err != nil {
-			m.logger.Warn("GitHub URL validation failed", "error", err)
-			m.layout = m.layout.SetError(err)
-			return m, nil
-		}
+	var cmd tea.Cmd
+	m.addGitHubForm, cmd = m.addGitHubForm.Update(msg)
+	m.hasChanges = true
 
-		// Check for duplicate URL
-		for _, repo := range m.currentConfig.Repositories {
-			if repo.RemoteURL != nil && *repo.RemoteURL == input {
-				m.layout = m.layout.SetError(fmt.Errorf("GitHub URL already used by another repository"))
-				return m, nil
-			}
-		}
-
-		m.newGitHubURL = input
-		m.textInput.SetValue("")
-		m.textInput.Placeholder = "e.g., main (leave empty for default)"
-		return m.transitionTo(SettingsStateAddGitHubBranch), nil
-	case "esc":
-		m.logger.LogUserAction("settings_add_github_url_cancelled", "returning to name input")
-		return m.transitionTo(SettingsStateAddGitHubName), nil
-	default:
-		return m.updateTextInput(msg)
+	// Suggest a clone path derived from the URL as it is entered
+	placeholder := settingshelpers.DeriveClonePath(m.addGitHubForm.Value(gitHubFieldURL))
+	if placeholder == "" {
+		placeholder = repository.GetDefaultStorageDir()
 	}
-}
-
-// handleAddGitHubBranchKeys processes user input in the AddGitHubBranch state.
-// Validates the branch name (optional) and proceeds to path input.
-func (m *SettingsModel) handleAddGitHubBranchKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
-	switch msg.String() {
-	case "enter":
-		input := strings.TrimSpace(m.textInput.Value())
-		m.logger.LogUserAction("settings_add_github_branch_submit", input)
+	m.addGitHubForm = m.addGitHubForm.SetPlaceholder(gitHubFieldPath, placeholder)
 
-		// Validate branch (optional, can be empty for default)
-		if input != "" {
-			if err := settingshelpers.ValidateBranchName(input); err != nil {
-				m.logger.Warn("Branch validation failed", "error", err)
-				m.layout = m.layout.SetError(err)
-				return m, nil
-			}
-		}
+	if !m.addGitHubForm.Submitted() {
+		return m, cmd
+	}
 
-		m.newGitHubBranch = input
+	m.addRepositoryName = m.addGitHubForm.Value(gitHubFieldName)
+	m.newGitHubURL = m.addGitHubForm.Value(gitHubFieldURL)
+	m.newGitHubBranch = m.addGitHubForm.Value(gitHubFieldBranch)
+	m.addRepositoryPath = fileops.ExpandPath(m.addGitHubForm.Value(gitHubFieldPath))
+	m.logger.LogUserAction("settings_add_github_submit", m.newGitHubURL)
 
-		// Derive default clone path from URL
-		placeholder := settingshelpers.DeriveClonePath(m.newGitHubURL)
-		if placeholder == "" {
-			placeholder = repository.GetDefaultStorageDir()
-		}
+	m.layout = m.layout.ClearError()
+	return m, m.createGitHubRepository()
+}
 
-		m.textInput.SetValue("")
-		m.textInput.Placeholder = placeholder
-		return m.transitionTo(SettingsStateAddGitHubPath), nil
-	case "esc":
-		m.logger.LogUserAction("settings_add_github_branch_cancelled", "returning to URL input")
-		return m.transitionTo(SettingsStateAddGitHubURL), nil
-	default:
-		return m.updateTextInput(msg)
+// validateNewRepositoryName checks that a name is usable and not taken
+func (m *SettingsModel) validateNewRepositoryName(name string) error {
+	if len(name) > 100 {
+		return fmt.Errorf("repository name must be 100 characters or less")
 	}
+	if _, err := m.currentConfig.FindRepositoryByName(name); err == nil {
+		return fmt.Errorf("repository name already exists")
+	}
+	return nil
 }
 
-// handleAddGitHubPathKeys processes user input in the AddGitHubPath state.
-// Validates the clone path and creates the repository if valid.
-func (m *SettingsModel) handleAddGitHubPathKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
-	if m.layout.GetError() != nil {
-		m.layout = m.layout.ClearError()
+// validateNewGitHubURL checks the URL format and that no repository uses it
+func (m *SettingsModel) validateNewGitHubURL(url string) error {
+	if err := settingshelpers.ValidateGitHubURL(url); err != nil {
+		return err
 	}
-	switch msg.String() {
-	case "enter":
-		input := strings.TrimSpace(m.textInput.Value())
-		m.logger.LogUserAction("settings_add_github_path_submit", input)
-
-		// Use placeholder if empty
-		if input == "" {
-			input = m.textInput.Placeholder
+	for _, repo := range m.currentConfig.Repositories {
+		if repo.RemoteURL != nil && *repo.RemoteURL == url {
+			return fmt.Errorf("GitHub URL already used by another repository")
 		}
+	}
+	return nil
+}
 
-		expandedPath := fileops.ExpandPath(input)
-		if err := fileops.ValidateStoragePath(expandedPath); err != nil {
-			m.logger.Warn("Path validation failed", "error", err)
-			m.layout = m.layout.SetError(err)
-			return m, nil
-		}
+// validateNewClonePath checks that the clone path is valid, unused, and either
+// missing or an empty directory
+func (m *SettingsModel) validateNewClonePath(path string) error {
+	expandedPath := fileops.ExpandPath(path)
+	if err := fileops.ValidateStoragePath(expandedPath); err != nil {
+		return err
+	}
 
-		// Check for duplicate path
-		for _, repo := range m.currentConfig.Repositories {
-			if repo.Path == expandedPath {
-				m.layout = m.layout.SetError(fmt.Errorf("path already used by another repository"))
-				return m, nil
-			}
+	for _, repo := range m.currentConfig.Repositories {
+		if repo.Path == expandedPath {
+			return fmt.Errorf("path already used by another repository")
 		}
+	}
 
-		// Check if directory exists and contains content
-		if info, err := os.Stat(expandedPath); err == nil && info.IsDir() {
-			// Directory exists, check if it's empty
-			isEmpty, err := fileops.IsDirEmpty(expandedPath)
-			if err == nil && !isEmpty {
-				// Check if it's a git repository
-				gitDirPath := filepath.Join(expandedPath, ".git")
-				if _, err := os.Stat(gitDirPath); err == nil {
-					m.layout = m.layout.SetError(fmt.Errorf("directory already contains a Git repository.\n\nPlease choose an empty directory or remove the existing repository at:\n%s", expandedPath))
-					return m, nil
-				}
-				// Directory is not empty but not a git repo - warn user
-				m.logger.Warn("Directory is not empty", "path", expandedPath)
-				m.layout = m.layout.SetError(fmt.Errorf("directory is not empty.\n\nCloning will fail if the directory contains files. Please use an empty directory:\n%s", expandedPath))
-				return m, nil
+	if info, err := os.Stat(expandedPath); err == nil && info.IsDir() {
+		isEmpty, err := fileops.IsDirEmpty(expandedPath)
+		if err == nil && !isEmpty {
+			if _, err := os.Stat(filepath.Join(expandedPath, ".git")); err == nil {
+				return fmt.Errorf("directory already contains a Git repository - choose an empty directory")
 			}
+			m.logger.Warn("Directory is not empty", "path", expandedPath)
+			return fmt.Errorf("directory is not empty - cloning needs an empty directory")
 		}
-
-		m.addRepositoryPath = expandedPath
-		m.layout = m.layout.ClearError()
-		return m, m.createGitHubRepository()
-	case "esc":
-		m.logger.LogUserAction("settings_add_github_path_cancelled", "returning to branch input")
-		return m.transitionTo(SettingsStateAddGitHubBranch), nil
-	default:
-		return m.updateTextInput(msg)
 	}
+	return nil
 }
 
 // handleAddGitHubPATKeys processes user input in the AddGitHubPAT state.
@@ -218,9 +184,11 @@ func (m *SettingsModel) handleAddGitHubPathKeys(msg tea.KeyMsg) (*SettingsModel,
 // Validates the PAT and continues with repository creation if valid.
 func (m *SettingsModel) handleAddGitHubPATKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
 	switch msg.String() {
-	case "enter":
+	case "enter", "ctrl+o":
+		// ctrl+o uses the token for this clone only and never saves it
+		useOnce := msg.String() == "ctrl+o"
 		input := strings.TrimSpace(m.textInput.Value())
-		m.logger.LogUserAction("settings_add_github_pat_submit", "PAT provided")
+		m.logger.LogUserAction("settings_add_github_pat_submit", fmt.Sprintf("PAT provided (use once: %t)", useOnce))
 
 		if input == "" {
 			m.logger.Warn("Empty PAT submitted in Add GitHub flow")
@@ -230,7 +198,7 @@ func (m *SettingsModel) handleAddGitHubPATKeys(msg tea.KeyMsg) (*SettingsModel,
 
 		// Validate token format
 		m.logger.Debug("Validating GitHub PAT format")
-		if err := m.credManager.ValidateGitHubToken(input); err != nil {
+		if err := m.credManager.ValidateTokenForRemote(input, m.newGitHubURL); err != nil {
 			m.logger.Warn("GitHub PAT format validation failed", "error", err)
 			m.layout = m.layout.SetError(fmt.Errorf("invalid PAT format: %w", err))
 			return m, nil
@@ -244,27 +212,31 @@ func (m *SettingsModel) handleAddGitHubPATKeys(msg tea.KeyMsg) (*SettingsModel,
 			return m, nil
 		}
 
-		// Store the PAT
-		m.logger.Debug("Storing GitHub PAT")
-		if err := m.credManager.StoreGitHubToken(input); err != nil {
-			m.logger.Error("Failed to store GitHub PAT", "error", err)
-			m.layout = m.layout.SetError(fmt.Errorf("failed to store PAT: %w", err))
-			return m, nil
+		if useOnce {
+			// Kept in memory for the initial clone only
+			repository.UseTokenOnce(input)
+			m.logger.Info("GitHub PAT validated for a one-off clone, not stored")
+		} else {
+			// Store the PAT
+			m.logger.Debug("Storing GitHub PAT")
+			if err := m.credManager.StoreTokenForRemote(m.newGitHubURL, input); err != nil {
+				m.logger.Error("Failed to store GitHub PAT", "error", err)
+				m.layout = m.layout.SetError(fmt.Errorf("failed to store PAT: %w", err))
+				return m, nil
+			}
+			m.logger.Info("GitHub PAT validated and stored successfully")
 		}
 
-		m.logger.Info("GitHub PAT validated and stored successfully")
-
 		// Clear the input and continue with repository creation
 		m.textInput.SetValue("")
 		m.layout = m.layout.ClearError()
 
-		// Continue with repository creation using the newly stored PAT
 		return m, m.createGitHubRepositoryWithPAT(input)
 
 	case "esc":
-		m.logger.LogUserAction("settings_add_github_pat_cancelled", "returning to path input")
+		m.logger.LogUserAction("settings_add_github_pat_cancelled", "returning to form")
 		m.layout = m.layout.ClearError()
-		return m.transitionTo(SettingsStateAddGitHubPath), nil
+		return m.transitionTo(SettingsStateAddGitHubForm), nil
 
 	default:
 		return m.updateTextInput(msg)
@@ -272,11 +244,11 @@ func (m *SettingsModel) handleAddGitHubPATKeys(msg tea.KeyMsg) (*SettingsModel,
 }
 
 // handleAddGitHubErrorKeys processes input in the AddGitHubError state.
-// Any key returns to the clone path input state.
+// Any key returns to the form with the entered values kept.
 func (m *SettingsModel) handleAddGitHubErrorKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
 	m.logger.LogUserAction("settings_add_github_error_dismiss", msg.String())
 	m.layout = m.layout.ClearError()
-	return m.transitionTo(SettingsStateAddGitHubPath), nil
+	return m.transitionTo(SettingsStateAddGitHubForm), nil
 }
 
 // createGitHubRepository creates a new GitHub repository configuration entry.
@@ -285,7 +257,7 @@ func (m *SettingsModel) handleAddGitHubErrorKeys(msg tea.KeyMsg) (*SettingsModel
 func (m *SettingsModel) createGitHubRepository() tea.Cmd {
 	return func() tea.Msg {
 		// Get PAT from credential manager
-		pat, err := m.credManager.GetGitHubToken()
+		pat, err := m.credManager.GetTokenForRemote(m.newGitHubURL)
 		if err != nil {
 			// PAT not found - transition to PAT input instead of failing
 			m.logger.Info("GitHub PAT not found, prompting user to enter PAT", "error", err)
@@ -301,8 +273,7 @@ func (m *SettingsModel) createGitHubRepository() tea.Cmd {
 			return addGitHubPATNeededMsg{}
 		}
 
-		// Generate repository ID
-		timestamp := time.Now().Unix()
+		timestamp := helpers.Now().Unix()
 
 		// Extract repository name from URL
 		gitInfo, err := repository.ParseGitURL(m.newGitHubURL)
@@ -311,7 +282,7 @@ func (m *SettingsModel) createGitHubRepository() tea.Cmd {
 			gitInfo.Repo = m.addRepositoryName // Use user-provided name as fallback
 		}
 
-		id := config.GenerateRepositoryID(m.addRepositoryName, timestamp)
+		id := m.ctx.IDs.NewRepositoryID(m.addRepositoryName)
 
 		m.logger.Info("Creating GitHub repository",
 			"id", id,
@@ -377,98 +348,30 @@ func (m *SettingsModel) createGitHubRepository() tea.Cmd {
 	}
 }
 
-// transitionToAddGitHubName transitions to the AddGitHubName state.
-// Sets up the text input for repository name entry.
-func (m *SettingsModel) transitionToAddGitHubName() (*SettingsModel, tea.Cmd) {
-	m.textInput.SetValue("")
-	m.textInput.Placeholder = "e.g., My GitHub Repository"
-	m.textInput.CharLimit = 100
-	m.textInput.EchoMode = textinput.EchoNormal
-	m.textInput.Focus()
-
-	return m.transitionTo(SettingsStateAddGitHubName), nil
+// transitionToAddGitHubForm transitions to the AddGitHubForm state with an
+// empty form.
+func (m *SettingsModel) transitionToAddGitHubForm() (*SettingsModel, tea.Cmd) {
+	m.addGitHubForm = m.newAddGitHubForm()
+	return m.transitionTo(SettingsStateAddGitHubForm), textinput.Blink
 }
 
 // Views
 
-// viewAddGitHubName renders the repository name input screen for GitHub repositories.
-// This is the first step in the add GitHub repository flow.
-func (m *SettingsModel) viewAddGitHubName() string {
+// viewAddGitHubForm renders all fields of the add GitHub repository flow on
+// one screen, with inline validation errors under each field.
+func (m *SettingsModel) viewAddGitHubForm() string {
+	subtitle := "Enter the repository details"
+	if m.ctx.Keyring.Checked && !m.ctx.Keyring.Available {
+		// Warn before the user types a PAT that could not be saved
+		subtitle += "\n⚠️  The OS credential store is unavailable, so a new PAT cannot be saved. " + m.ctx.Keyring.Guidance
+	}
 	m.layout = m.layout.SetConfig(components.LayoutConfig{
 		Title:    "🔗 Add GitHub Repository",
-		Subtitle: "Enter a name for this repository",
-		HelpText: "Enter to continue • Esc to go back",
-	})
-
-	var content strings.Builder
-	content.WriteString("Repository Name:\n\n")
-	content.WriteString(m.textInput.View())
-	content.WriteString("\n\n")
-	content.WriteString(lipgloss.NewStyle().Faint(true).Render("This name will help you identify the repository"))
-
-	return m.layout.Render(content.String())
-}
-
-// viewAddGitHubURL renders the GitHub URL input screen.
-// This is the second step in the add GitHub repository flow.
-func (m *SettingsModel) viewAddGitHubURL() string {
-	m.layout = m.layout.SetConfig(components.LayoutConfig{
-		Title:    fmt.Sprintf("🔗 Add GitHub Repository: %s", m.addRepositoryName),
-		Subtitle: "Enter the GitHub repository URL",
-		HelpText: "Enter to continue • Esc to go back",
-	})
-
-	var content strings.Builder
-	content.WriteString("GitHub URL:\n\n")
-	content.WriteString(m.textInput.View())
-	content.WriteString("\n\n")
-	content.WriteString(lipgloss.NewStyle().Faint(true).Render("Format: https://github.com/username/repository"))
-
-	return m.layout.Render(content.String())
-}
-
-// viewAddGitHubBranch renders the branch name input screen.
-// This is the third step in the add GitHub repository flow.
-// Branch selection is optional - users can leave it empty to use the default branch.
-func (m *SettingsModel) viewAddGitHubBranch() string {
-	m.layout = m.layout.SetConfig(components.LayoutConfig{
-		Title:    fmt.Sprintf("🔗 Add GitHub Repository: %s", m.addRepositoryName),
-		Subtitle: "Enter the branch to sync (optional)",
-		HelpText: "Enter to continue • Esc to go back",
-	})
-
-	var content strings.Builder
-	content.WriteString(fmt.Sprintf("URL: %s\n\n", lipgloss.NewStyle().Faint(true).Render(m.newGitHubURL)))
-	content.WriteString("Branch:\n\n")
-	content.WriteString(m.textInput.View())
-	content.WriteString("\n\n")
-	content.WriteString(lipgloss.NewStyle().Faint(true).Render("Leave empty to use the default branch"))
-
-	return m.layout.Render(content.String())
-}
-
-// viewAddGitHubPath renders the local clone path input screen.
-// This is the fourth and final step in the add GitHub repository flow.
-// Shows a summary of the URL and branch (if specified) before path entry.
-func (m *SettingsModel) viewAddGitHubPath() string {
-	m.layout = m.layout.SetConfig(components.LayoutConfig{
-		Title:    fmt.Sprintf("🔗 Add GitHub Repository: %s", m.addRepositoryName),
-		Subtitle: "Enter the local clone path",
-		HelpText: "Enter to save • Esc to go back",
+		Subtitle: subtitle,
+		HelpText: "Tab/↑/↓ to move between fields • Enter to continue or save • Esc to go back",
 	})
 
-	var content strings.Builder
-	content.WriteString(fmt.Sprintf("URL: %s\n", lipgloss.NewStyle().Faint(true).Render(m.newGitHubURL)))
-	if m.newGitHubBranch != "" {
-		content.WriteString(fmt.Sprintf("Branch: %s\n", lipgloss.NewStyle().Faint(true).Render(m.newGitHubBranch)))
-	}
-	content.WriteString("\n")
-	content.WriteString("Local Clone Path:\n\n")
-	content.WriteString(m.textInput.View())
-	content.WriteString("\n\n")
-	content.WriteString(lipgloss.NewStyle().Faint(true).Render("Where to clone the repository locally"))
-
-	return m.layout.Render(content.String())
+	return m.layout.Render(m.addGitHubForm.View())
 }
 
 // viewAddGitHubError renders the error screen when GitHub repository creation fails.
@@ -503,11 +406,11 @@ func (m *SettingsModel) viewAddGitHubError() string {
 
 // createGitHubRepositoryWithPAT creates a GitHub repository using the provided PAT.
 // This is called after the user enters a PAT in the optional AddGitHubPAT state.
-// The PAT has already been validated and stored by handleAddGitHubPATKeys.
+// The PAT has already been validated and either stored or, for a one-off
+// clone, handed to repository.UseTokenOnce by handleAddGitHubPATKeys.
 func (m *SettingsModel) createGitHubRepositoryWithPAT(pat string) tea.Cmd {
 	return func() tea.Msg {
-		// Generate repository ID
-		timestamp := time.Now().Unix()
+		timestamp := helpers.Now().Unix()
 
 		// Extract repository name from URL
 		gitInfo, err := repository.ParseGitURL(m.newGitHubURL)
@@ -516,7 +419,7 @@ func (m *SettingsModel) createGitHubRepositoryWithPAT(pat string) tea.Cmd {
 			gitInfo.Repo = m.addRepositoryName // Use user-provided name as fallback
 		}
 
-		id := config.GenerateRepositoryID(m.addRepositoryName, timestamp)
+		id := m.ctx.IDs.NewRepositoryID(m.addRepositoryName)
 
 		m.logger.Info("Creating GitHub repository with new PAT",
 			"id", id,
@@ -589,12 +492,13 @@ func (m *SettingsModel) viewAddGitHubPAT() string {
 	m.layout = m.layout.SetConfig(components.LayoutConfig{
 		Title:    fmt.Sprintf("🔑 GitHub Access Required: %s", m.addRepositoryName),
 		Subtitle: "Enter your GitHub Personal Access Token",
-		HelpText: "Enter to continue • Esc to go back",
+		HelpText: "Enter to save and continue • Ctrl+O to use once without saving • Esc to go back",
 	})
 
 	var content strings.Builder
 	content.WriteString("To add this GitHub repository, you need to provide a Personal Access Token (PAT).\n\n")
-	content.WriteString(fmt.Sprintf("Repository: %s\n", lipgloss.NewStyle().Faint(true).Render(m.newGitHubURL)))
+	url := textutil.TruncateURL(m.newGitHubURL, m.layout.ContentWidth()-lipgloss.Width("Repository: "))
+	content.WriteString(fmt.Sprintf("Repository: %s\n", lipgloss.NewStyle().Faint(true).Render(url)))
 	if m.newGitHubBranch != "" {
 		content.WriteString(fmt.Sprintf("Branch: %s\n", lipgloss.NewStyle().Faint(true).Render(m.newGitHubBranch)))
 	}
@@ -603,6 +507,8 @@ func (m *SettingsModel) viewAddGitHubPAT() string {
 	content.WriteString(m.textInput.View())
 	content.WriteString("\n\n")
 	content.WriteString(lipgloss.NewStyle().Faint(true).Render("💡 Need a token? Visit: https://github.com/settings/tokens\n   Required scopes: repo (for private repos) or public_repo (for public repos)"))
+	content.WriteString("\n\n")
+	content.WriteString(lipgloss.NewStyle().Faint(true).Render("🔒 A token used once is only kept in memory for the initial clone. Later refreshes of a\n   private repository need a saved token or rulem --token-once."))
 
 	return m.layout.Render(content.String())
 }
@@ -3,6 +3,8 @@ package settingsmenu
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"rulem/internal/repository"
 	"strings"
 	"testing"
@@ -13,6 +15,41 @@ import (
 
 // TODO remove 3rd party test dependcies and use std lib only
 
+// startAddGitHubForm navigates from type selection to the Add GitHub form
+func startAddGitHubForm(t *testing.T, m *SettingsModel) *SettingsModel {
+	t.Helper()
+	m.state = SettingsStateAddRepositoryType
+	m.addRepositoryTypeIndex = 1 // GitHub
+	m, _ = m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != SettingsStateAddGitHubForm {
+		t.Fatalf("should transition to AddGitHubForm: expected %v, got %v", SettingsStateAddGitHubForm, m.state)
+	}
+	return m
+}
+
+// fillAddGitHubForm sets every field of the Add GitHub form
+func fillAddGitHubForm(m *SettingsModel, name, url, branch, path string) {
+	m.addGitHubForm = m.addGitHubForm.
+		SetValue(gitHubFieldName, name).
+		SetValue(gitHubFieldURL, url).
+		SetValue(gitHubFieldBranch, branch).
+		SetValue(gitHubFieldPath, path)
+}
+
+// submitAddGitHubForm presses Enter through the form, as a user accepting each
+// field would. It stops at the first field that fails validation.
+func submitAddGitHubForm(m *SettingsModel) (*SettingsModel, tea.Cmd) {
+	var cmd tea.Cmd
+	for range 4 {
+		focused := m.addGitHubForm.Focused()
+		m, cmd = m.handleAddGitHubFormKeys(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd != nil || m.addGitHubForm.Error(focused) != nil {
+			break
+		}
+	}
+	return m, cmd
+}
+
 // TestIntegration_AddGitHubComplete tests the complete
 // add GitHub repository flow from start to finish with successful completion
 func TestIntegration_AddGitHubComplete(t *testing.T) {
@@ -23,68 +60,33 @@ func TestIntegration_AddGitHubComplete(t *testing.T) {
 	m := createTestModel(t)
 	m.currentConfig.Repositories = []repository.RepositoryEntry{} // Start empty
 
-	// Step 1: Start at AddRepositoryType (user already selected "Add Repository")
-	m.state = SettingsStateAddRepositoryType
-	if m.state != SettingsStateAddRepositoryType {
-		t.Fatalf("expected state %v, got %v", SettingsStateAddRepositoryType, m.state)
-	}
+	// Select GitHub type - all details are entered on one form
+	m = startAddGitHubForm(t, m)
 
-	// Step 2: Select GitHub type (index 1)
-	m.addRepositoryTypeIndex = 1
-	m, _ = m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubName {
-		t.Fatalf("should transition to AddGitHubName: expected %v, got %v", SettingsStateAddGitHubName, m.state)
-	}
+	testURL := "https://github.com/test/repo"
+	testPath := t.TempDir()
+	fillAddGitHubForm(m, "My Integration GitHub Repo", testURL, "main", testPath)
 
-	// Step 3: Enter repository name
-	m.textInput.SetValue("My Integration GitHub Repo")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubURL {
-		t.Fatalf("should transition to AddGitHubURL: expected %v, got %v", SettingsStateAddGitHubURL, m.state)
+	m, cmd := submitAddGitHubForm(m)
+	if cmd == nil {
+		t.Fatalf("should return createGitHubRepository command")
 	}
 	if m.addRepositoryName != "My Integration GitHub Repo" {
 		t.Fatalf("should store repository name: expected %q, got %q", "My Integration GitHub Repo", m.addRepositoryName)
 	}
-
-	// Step 4: Enter GitHub URL
-	testURL := "https://github.com/test/repo"
-	m.textInput.SetValue(testURL)
-	m, _ = m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubBranch {
-		t.Fatalf("should transition to AddGitHubBranch: expected %v, got %v", SettingsStateAddGitHubBranch, m.state)
-	}
 	if m.newGitHubURL != testURL {
 		t.Fatalf("should store GitHub URL: expected %q, got %q", testURL, m.newGitHubURL)
 	}
-
-	// Step 5: Enter branch (can be empty for default)
-	m.textInput.SetValue("main")
-	m, _ = m.handleAddGitHubBranchKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubPath {
-		t.Fatalf("should transition to AddGitHubPath: expected %v, got %v", SettingsStateAddGitHubPath, m.state)
-	}
 	if m.newGitHubBranch != "main" {
 		t.Fatalf("should store branch: expected %q, got %q", "main", m.newGitHubBranch)
 	}
-
-	// Step 6: Enter clone path
-	testPath := t.TempDir()
-	m.textInput.SetValue(testPath)
-	m, cmd := m.handleAddGitHubPathKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if cmd == nil {
-		t.Fatalf("should return createGitHubRepository command")
-	}
-	if m.addRepositoryPath == "" {
-		t.Fatalf("should store repository path")
+	if m.addRepositoryPath != testPath {
+		t.Fatalf("should store repository path: expected %q, got %q", testPath, m.addRepositoryPath)
 	}
 
-	// Step 7: Execute createGitHubRepository command
+	// Execute createGitHubRepository command
 	// Note: This will fail without valid PAT, but we're testing the flow
-	msg := cmd()
-
-	// Command might return error if PAT not configured (expected in test environment)
-	// Just verify it returns a message
-	if msg == nil {
+	if msg := cmd(); msg == nil {
 		t.Fatalf("should return a message")
 	}
 
@@ -110,438 +112,186 @@ func TestIntegration_AddGitHub_CancelAtTypeSelection(t *testing.T) {
 	}
 }
 
-// TestIntegration_AddGitHub_CancelAtNameInput tests canceling
-// during the name input step
-func TestIntegration_AddGitHub_CancelAtNameInput(t *testing.T) {
-	m := createTestModel(t)
-	m.currentConfig.Repositories = []repository.RepositoryEntry{}
-
-	// Navigate to name input
-	m.state = SettingsStateAddRepositoryType
-	m.addRepositoryTypeIndex = 1 // GitHub
-	m, _ = m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubName {
-		t.Fatalf("expected %v, got %v", SettingsStateAddGitHubName, m.state)
-	}
-
-	// Enter some text, then cancel
-	m.textInput.SetValue("Partial Name")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEsc})
-
-	if m.state != SettingsStateAddRepositoryType {
-		t.Fatalf("should return to type selection: expected %v, got %v", SettingsStateAddRepositoryType, m.state)
-	}
-	if m.addRepositoryName != "" {
-		t.Fatalf("should not store partial name: got %q", m.addRepositoryName)
-	}
-	if len(m.currentConfig.Repositories) != 0 {
-		t.Fatalf("should not add repository: expected 0, got %d", len(m.currentConfig.Repositories))
-	}
-}
-
-// TestIntegration_AddGitHub_CancelAtURLInput tests canceling
-// during the URL input step
-func TestIntegration_AddGitHub_CancelAtURLInput(t *testing.T) {
-	m := createTestModel(t)
-	m.currentConfig.Repositories = []repository.RepositoryEntry{}
-
-	// Navigate through name input
-	m.state = SettingsStateAddGitHubName
-	m.textInput.SetValue("Test Repo")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubURL {
-		t.Fatalf("expected %v, got %v", SettingsStateAddGitHubURL, m.state)
-	}
-
-	// Enter URL, then cancel
-	m.textInput.SetValue("https://github.com/test/repo")
-	m, _ = m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEsc})
-
-	if m.state != SettingsStateAddGitHubName {
-		t.Fatalf("should return to name input: expected %v, got %v", SettingsStateAddGitHubName, m.state)
-	}
-	if m.newGitHubURL != "" {
-		t.Fatalf("should not store partial URL: got %q", m.newGitHubURL)
-	}
-	if len(m.currentConfig.Repositories) != 0 {
-		t.Fatalf("should not add repository: expected 0, got %d", len(m.currentConfig.Repositories))
-	}
-}
-
-// TestIntegration_AddGitHub_CancelAtBranchInput tests canceling
-// during the branch input step
-func TestIntegration_AddGitHub_CancelAtBranchInput(t *testing.T) {
-	m := createTestModel(t)
-	m.currentConfig.Repositories = []repository.RepositoryEntry{}
-
-	// Navigate through name and URL
-	m.state = SettingsStateAddGitHubName
-	m.textInput.SetValue("Test Repo")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	m.textInput.SetValue("https://github.com/test/repo")
-	m, _ = m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubBranch {
-		t.Fatalf("expected %v, got %v", SettingsStateAddGitHubBranch, m.state)
-	}
-
-	// Enter branch, then cancel
-	m.textInput.SetValue("develop")
-	m, _ = m.handleAddGitHubBranchKeys(tea.KeyMsg{Type: tea.KeyEsc})
-
-	if m.state != SettingsStateAddGitHubURL {
-		t.Fatalf("should return to URL input: expected %v, got %v", SettingsStateAddGitHubURL, m.state)
-	}
-	if m.newGitHubBranch != "" {
-		t.Fatalf("should not store partial branch: got %q", m.newGitHubBranch)
-	}
-	if len(m.currentConfig.Repositories) != 0 {
-		t.Fatalf("should not add repository: expected 0, got %d", len(m.currentConfig.Repositories))
-	}
-}
-
-// TestIntegration_AddGitHub_CancelAtPathInput tests canceling
-// during the path input step
-func TestIntegration_AddGitHub_CancelAtPathInput(t *testing.T) {
-	m := createTestModel(t)
-	m.currentConfig.Repositories = []repository.RepositoryEntry{}
-
-	// Navigate through all inputs
-	m.state = SettingsStateAddGitHubName
-	m.textInput.SetValue("Test Repo")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	m.textInput.SetValue("https://github.com/test/repo")
-	m, _ = m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	m.textInput.SetValue("main")
-	m, _ = m.handleAddGitHubBranchKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubPath {
-		t.Fatalf("expected %v, got %v", SettingsStateAddGitHubPath, m.state)
-	}
-
-	// Enter path, then cancel
-	m.textInput.SetValue("/some/path")
-	m, _ = m.handleAddGitHubPathKeys(tea.KeyMsg{Type: tea.KeyEsc})
+// TestIntegration_AddGitHub_CancelFromAnyField tests that Esc leaves the form
+// from every field, without adding a repository
+func TestIntegration_AddGitHub_CancelFromAnyField(t *testing.T) {
+	for _, field := range []string{gitHubFieldName, gitHubFieldURL, gitHubFieldBranch, gitHubFieldPath} {
+		t.Run(field, func(t *testing.T) {
+			m := createTestModel(t)
+			m.currentConfig.Repositories = []repository.RepositoryEntry{}
+			m = startAddGitHubForm(t, m)
+			fillAddGitHubForm(m, "Test Repo", "https://github.com/test/repo", "main", t.TempDir())
 
-	if m.state != SettingsStateAddGitHubBranch {
-		t.Fatalf("should return to branch input: expected %v, got %v", SettingsStateAddGitHubBranch, m.state)
-	}
-	if m.addRepositoryPath != "" {
-		t.Fatalf("should not store partial path: got %q", m.addRepositoryPath)
-	}
-	if len(m.currentConfig.Repositories) != 0 {
-		t.Fatalf("should not add repository: expected 0, got %d", len(m.currentConfig.Repositories))
+			// Move focus to the field, then cancel
+			for m.addGitHubForm.Focused() != field {
+				m, _ = m.handleAddGitHubFormKeys(tea.KeyMsg{Type: tea.KeyTab})
+			}
+			m, cmd := m.handleAddGitHubFormKeys(tea.KeyMsg{Type: tea.KeyEsc})
+			if cmd != nil {
+				t.Fatalf("cancel should not start repository creation")
+			}
+			if m.state != SettingsStateAddRepositoryType {
+				t.Fatalf("should return to AddRepositoryType: expected %v, got %v", SettingsStateAddRepositoryType, m.state)
+			}
+			if len(m.currentConfig.Repositories) != 0 {
+				t.Fatalf("should not add any repository: expected 0, got %d", len(m.currentConfig.Repositories))
+			}
+		})
 	}
 }
 
-// TestIntegration_AddGitHub_EmptyNameValidation tests validation
-// error when submitting empty repository name
-func TestIntegration_AddGitHub_EmptyNameValidation(t *testing.T) {
-	m := createTestModel(t)
-	m.state = SettingsStateAddGitHubName
-	m.textInput.SetValue("")
+// TestIntegration_AddGitHub_FieldValidation tests inline validation errors
+// for each field of the form
+func TestIntegration_AddGitHub_FieldValidation(t *testing.T) {
+	existingURL := "https://github.com/test/existing"
+	existingPath := t.TempDir()
 
-	// Try to submit empty name
-	m, cmd := m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	if m.state != SettingsStateAddGitHubName {
-		t.Fatalf("should stay in name input: expected %v, got %v", SettingsStateAddGitHubName, m.state)
+	gitDir := t.TempDir()
+	if err := mkdirAll(gitDir, ".git"); err != nil {
+		t.Fatal(err)
 	}
-	if cmd != nil {
-		t.Fatalf("should not proceed")
+	nonEmptyDir := t.TempDir()
+	if err := writeTestFile(nonEmptyDir, "README.md"); err != nil {
+		t.Fatal(err)
 	}
-	if m.layout.GetError() == nil {
-		t.Fatalf("should show error")
-	}
-	if !strings.Contains(m.layout.GetError().Error(), "cannot be empty") {
-		t.Fatalf("error should mention empty: got %q", m.layout.GetError().Error())
-	}
-}
-
-// TestIntegration_AddGitHub_DuplicateNameValidation tests validation
-// error when submitting a duplicate repository name
-func TestIntegration_AddGitHub_DuplicateNameValidation(t *testing.T) {
-	m := createTestModel(t)
-	m.state = SettingsStateAddGitHubName
-
-	// Add existing repository
-	m.currentConfig.Repositories = []repository.RepositoryEntry{
-		{
-			ID:   "existing-id",
-			Name: "Existing Repo",
-			Type: repository.RepositoryTypeGitHub,
-			Path: "/existing/path",
-		},
-	}
-
-	// Try to use duplicate name
-	m.textInput.SetValue("Existing Repo")
-	m, cmd := m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
 
-	if m.state != SettingsStateAddGitHubName {
-		t.Fatalf("should stay in name input: expected %v, got %v", SettingsStateAddGitHubName, m.state)
-	}
-	if cmd != nil {
-		t.Fatalf("should not proceed")
-	}
-	if m.layout.GetError() == nil {
-		t.Fatalf("should show error")
-	}
-	if !strings.Contains(m.layout.GetError().Error(), "already exists") {
-		t.Fatalf("error should mention duplicate: got %q", m.layout.GetError().Error())
-	}
-}
-
-// TestIntegration_AddGitHub_InvalidURLValidation tests validation
-// error when submitting invalid GitHub URL
-func TestIntegration_AddGitHub_InvalidURLValidation(t *testing.T) {
 	tests := []struct {
-		name string
-		url  string
+		name    string
+		field   string
+		value   string
+		wantErr string
 	}{
-		{
-			name: "invalid format",
-			url:  "not-a-valid-url",
-		},
-		{
-			name: "malformed URL",
-			url:  "ht!tp://invalid",
-		},
+		{name: "empty name", field: gitHubFieldName, value: "", wantErr: "cannot be empty"},
+		{name: "duplicate name", field: gitHubFieldName, value: "Existing Repo", wantErr: "already exists"},
+		{name: "empty URL", field: gitHubFieldURL, value: "", wantErr: "cannot be empty"},
+		{name: "invalid URL", field: gitHubFieldURL, value: "ht!tp://invalid"},
+		{name: "non GitHub URL", field: gitHubFieldURL, value: "not-a-valid-url"},
+		{name: "duplicate URL", field: gitHubFieldURL, value: existingURL, wantErr: "already used"},
+		{name: "invalid branch", field: gitHubFieldBranch, value: "feature..bad"},
+		{name: "branch with spaces", field: gitHubFieldBranch, value: "my branch"},
+		{name: "duplicate path", field: gitHubFieldPath, value: existingPath, wantErr: "already used"},
+		{name: "path with git repository", field: gitHubFieldPath, value: gitDir, wantErr: "Git repository"},
+		{name: "non-empty path", field: gitHubFieldPath, value: nonEmptyDir, wantErr: "not empty"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := createTestModel(t)
-			m.state = SettingsStateAddGitHubURL
-			m.addRepositoryName = "Test Repo"
-			m.textInput.SetValue(tt.url)
-
-			m, cmd := m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-			if m.state != SettingsStateAddGitHubURL {
-				t.Fatalf("should stay in URL input: expected %v, got %v", SettingsStateAddGitHubURL, m.state)
+			m.currentConfig.Repositories = []repository.RepositoryEntry{
+				{ID: "existing", Name: "Existing Repo", Type: repository.RepositoryTypeGitHub, Path: existingPath, RemoteURL: &existingURL},
 			}
+			m = startAddGitHubForm(t, m)
+			fillAddGitHubForm(m, "New Repo", "https://github.com/test/new", "main", t.TempDir())
+			m.addGitHubForm = m.addGitHubForm.SetValue(tt.field, tt.value)
+
+			m, cmd := submitAddGitHubForm(m)
 			if cmd != nil {
-				t.Fatalf("should not proceed")
+				t.Fatalf("should not start repository creation")
+			}
+			if m.state != SettingsStateAddGitHubForm {
+				t.Fatalf("should stay on the form: expected %v, got %v", SettingsStateAddGitHubForm, m.state)
+			}
+			if m.addGitHubForm.Focused() != tt.field {
+				t.Fatalf("should focus the invalid field %q, focused %q", tt.field, m.addGitHubForm.Focused())
+			}
+			err := m.addGitHubForm.Error(tt.field)
+			if err == nil {
+				t.Fatalf("should show an inline error on %q", tt.field)
 			}
-			if m.layout.GetError() == nil {
-				t.Fatalf("should show error")
+			if tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %q", tt.wantErr, err.Error())
+			}
+			// Long errors are wrapped by the layout, so only look for their start
+			if prefix := strings.SplitN(err.Error(), " ", 3); !strings.Contains(m.View(), "✗ "+prefix[0]) {
+				t.Fatalf("view should show the inline error %q", err.Error())
 			}
 		})
 	}
 }
 
-// TestIntegration_AddGitHub_DuplicateURLValidation tests validation
-// error when submitting a duplicate GitHub URL
-func TestIntegration_AddGitHub_DuplicateURLValidation(t *testing.T) {
-	m := createTestModel(t)
-	m.state = SettingsStateAddGitHubURL
-	m.addRepositoryName = "New Repo"
-
-	duplicateURL := "https://github.com/test/existing"
-
-	// Add existing repository with same URL
-	m.currentConfig.Repositories = []repository.RepositoryEntry{
-		{
-			ID:        "existing-id",
-			Name:      "Existing",
-			Type:      repository.RepositoryTypeGitHub,
-			Path:      "/existing/path",
-			RemoteURL: &duplicateURL,
-		},
-	}
-
-	// Try to use duplicate URL
-	m.textInput.SetValue(duplicateURL)
-	m, cmd := m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	if m.state != SettingsStateAddGitHubURL {
-		t.Fatalf("should stay in URL input: expected %v, got %v", SettingsStateAddGitHubURL, m.state)
-	}
-	if cmd != nil {
-		t.Fatalf("should not proceed")
-	}
-	if m.layout.GetError() == nil {
-		t.Fatalf("should show error")
-	}
-	if !strings.Contains(m.layout.GetError().Error(), "already used") {
-		t.Fatalf("error should mention duplicate: got %q", m.layout.GetError().Error())
-	}
-}
-
 // TestIntegration_AddGitHub_EmptyBranchAllowed tests that empty branch
 // is allowed (uses default branch)
 func TestIntegration_AddGitHub_EmptyBranchAllowed(t *testing.T) {
 	m := createTestModel(t)
-	m.state = SettingsStateAddGitHubBranch
-	m.addRepositoryName = "Test Repo"
-	m.newGitHubURL = "https://github.com/test/repo"
-	m.textInput.SetValue("")
-
-	// Submit empty branch
-	m, cmd := m.handleAddGitHubBranchKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	m.currentConfig.Repositories = []repository.RepositoryEntry{}
+	m = startAddGitHubForm(t, m)
+	fillAddGitHubForm(m, "Test Repo", "https://github.com/test/repo", "", t.TempDir())
 
-	if m.state != SettingsStateAddGitHubPath {
-		t.Fatalf("should proceed to path input: expected %v, got %v", SettingsStateAddGitHubPath, m.state)
-	}
-	if cmd != nil {
-		t.Fatalf("should not execute command yet")
+	m, cmd := submitAddGitHubForm(m)
+	if cmd == nil {
+		t.Fatalf("should proceed with creation")
 	}
 	if m.newGitHubBranch != "" {
-		t.Fatalf("branch should remain empty: got %q", m.newGitHubBranch)
-	}
-}
-
-// TestIntegration_AddGitHub_InvalidBranchValidation tests validation
-// error when submitting invalid branch name
-func TestIntegration_AddGitHub_InvalidBranchValidation(t *testing.T) {
-	tests := []struct {
-		name   string
-		branch string
-	}{
-		{
-			name:   "branch with spaces",
-			branch: "feature branch",
-		},
-		{
-			name:   "branch with special chars",
-			branch: "feature..branch",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			m := createTestModel(t)
-			m.state = SettingsStateAddGitHubBranch
-			m.addRepositoryName = "Test Repo"
-			m.newGitHubURL = "https://github.com/test/repo"
-			m.textInput.SetValue(tt.branch)
-
-			m, cmd := m.handleAddGitHubBranchKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-			if m.state != SettingsStateAddGitHubBranch {
-				t.Fatalf("should stay in branch input: expected %v, got %v", SettingsStateAddGitHubBranch, m.state)
-			}
-			if cmd != nil {
-				t.Fatalf("should not proceed")
-			}
-			if m.layout.GetError() == nil {
-				t.Fatalf("should show error")
-			}
-		})
+		t.Fatalf("branch should be empty (uses default): got %q", m.newGitHubBranch)
 	}
 }
 
-// TestIntegration_AddGitHub_EmptyPathValidation tests validation
-// error when submitting empty repository path
-func TestIntegration_AddGitHub_EmptyPathValidation(t *testing.T) {
+// TestIntegration_AddGitHub_ClonePathSuggestion tests that an empty clone path
+// uses the suggestion derived from the URL
+func TestIntegration_AddGitHub_ClonePathSuggestion(t *testing.T) {
 	m := createTestModel(t)
-	m.state = SettingsStateAddGitHubPath
-	m.addRepositoryName = "Test Repo"
-	m.newGitHubURL = "https://github.com/test/repo"
-	m.newGitHubBranch = "main"
-
-	// Set empty placeholder to ensure empty path
-	m.textInput.Placeholder = ""
-	m.textInput.SetValue("")
-
-	// Try to submit empty path with no placeholder
-	m, cmd := m.handleAddGitHubPathKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	m.currentConfig.Repositories = []repository.RepositoryEntry{}
+	m = startAddGitHubForm(t, m)
+	fillAddGitHubForm(m, "Test Repo", "https://github.com/test/suggested-repo", "", "")
 
-	// Empty path uses placeholder, so this test depends on placeholder logic
-	// Just verify command is returned or error shown
-	_ = cmd
-	_ = m
+	// Any keypress refreshes the suggestion from the URL field
+	m, _ = m.handleAddGitHubFormKeys(tea.KeyMsg{Type: tea.KeyTab})
+	suggested := m.addGitHubForm.Value(gitHubFieldPath)
+	if !strings.Contains(suggested, "suggested-repo") {
+		t.Fatalf("clone path should be derived from the URL, got %q", suggested)
+	}
 }
 
-// TestIntegration_AddGitHub_DuplicatePathValidation tests validation
-// error when submitting a duplicate repository path
-func TestIntegration_AddGitHub_DuplicatePathValidation(t *testing.T) {
+// TestIntegration_AddGitHub_RecoverFromValidationError tests
+// recovering from validation errors and successfully completing the flow
+func TestIntegration_AddGitHub_RecoverFromValidationError(t *testing.T) {
 	m := createTestModel(t)
-	m.state = SettingsStateAddGitHubPath
-	m.addRepositoryName = "New Repo"
-	m.newGitHubURL = "https://github.com/test/newrepo"
-	m.newGitHubBranch = "main"
-
 	testPath := t.TempDir()
-
-	// Add existing repository with same path
 	m.currentConfig.Repositories = []repository.RepositoryEntry{
-		{
-			ID:   "existing-id",
-			Name: "Existing",
-			Type: repository.RepositoryTypeGitHub,
-			Path: testPath,
-		},
+		{ID: "existing", Name: "Existing", Type: repository.RepositoryTypeLocal, Path: testPath},
 	}
+	m = startAddGitHubForm(t, m)
 
-	// Try to use duplicate path
-	m.textInput.SetValue(testPath)
-	m, cmd := m.handleAddGitHubPathKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	if m.state != SettingsStateAddGitHubPath {
-		t.Fatalf("should stay in path input: expected %v, got %v", SettingsStateAddGitHubPath, m.state)
-	}
-	if cmd != nil {
-		t.Fatalf("should not proceed")
+	// Submit with an invalid URL and a duplicate path
+	fillAddGitHubForm(m, "Valid GitHub Repo", "not-a-valid-url", "main", testPath)
+	m, cmd := submitAddGitHubForm(m)
+	if cmd != nil || m.addGitHubForm.Error(gitHubFieldURL) == nil {
+		t.Fatalf("should stop at the invalid URL")
 	}
-	if m.layout.GetError() == nil {
-		t.Fatalf("should show error")
-	}
-	if !strings.Contains(m.layout.GetError().Error(), "already used") {
-		t.Fatalf("error should mention duplicate: got %q", m.layout.GetError().Error())
-	}
-}
 
-// TestIntegration_AddGitHub_PathValidationRecovers tests that the path input can recover
-// after a duplicate-path error by editing the value and resubmitting.
-func TestIntegration_AddGitHub_PathValidationRecovers(t *testing.T) {
-	m := createTestModel(t)
-	m.state = SettingsStateAddGitHubPath
-	m.addRepositoryName = "Recovery Repo"
-	m.newGitHubURL = "https://github.com/test/recovery"
-	m.newGitHubBranch = "main"
-
-	duplicatePath := t.TempDir()
-	m.currentConfig.Repositories = []repository.RepositoryEntry{
-		{
-			ID:   "existing-id",
-			Name: "Existing",
-			Type: repository.RepositoryTypeGitHub,
-			Path: duplicatePath,
-		},
+	// Fix the URL; submission now stops at the duplicate path
+	m.addGitHubForm = m.addGitHubForm.SetValue(gitHubFieldURL, "https://github.com/test/validrepo")
+	m, cmd = submitAddGitHubForm(m)
+	if cmd != nil || m.addGitHubForm.Error(gitHubFieldPath) == nil {
+		t.Fatalf("should stop at the duplicate path")
 	}
 
-	m.textInput.SetValue(duplicatePath)
-	m, cmd := m.handleAddGitHubPathKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	if m.state != SettingsStateAddGitHubPath {
-		t.Fatalf("should stay in path input after duplicate: expected %v, got %v", SettingsStateAddGitHubPath, m.state)
-	}
-	if cmd != nil {
-		t.Fatalf("should not proceed when path is duplicate")
-	}
-	if m.layout.GetError() == nil {
-		t.Fatalf("should show duplicate error")
-	}
-	if !strings.Contains(m.layout.GetError().Error(), "already used") {
-		t.Fatalf("error should mention duplicate: got %q", m.layout.GetError().Error())
+	// Typing into the field clears its error
+	m, _ = m.handleAddGitHubFormKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if m.addGitHubForm.Error(gitHubFieldPath) != nil {
+		t.Fatalf("editing the path should clear its error")
 	}
 
-	// Simulate user editing the path (deleted characters and typed a new path)
+	// Fix the path and complete the flow
 	newPath := t.TempDir()
-	m.textInput.SetValue(newPath)
-	m, cmd = m.handleAddGitHubPathKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
+	m.addGitHubForm = m.addGitHubForm.SetValue(gitHubFieldPath, newPath)
+	m, cmd = submitAddGitHubForm(m)
 	if cmd == nil {
-		t.Fatalf("should return command after correcting path")
+		t.Fatalf("should proceed with creation")
 	}
-	if m.layout.GetError() != nil {
-		t.Fatalf("error should be cleared after correction: got %v", m.layout.GetError())
+
+	// Verify flow data is correct
+	if m.addRepositoryName != "Valid GitHub Repo" {
+		t.Fatalf("expected %q, got %q", "Valid GitHub Repo", m.addRepositoryName)
+	}
+	if m.newGitHubURL != "https://github.com/test/validrepo" {
+		t.Fatalf("expected %q, got %q", "https://github.com/test/validrepo", m.newGitHubURL)
+	}
+	if m.newGitHubBranch != "main" {
+		t.Fatalf("expected %q, got %q", "main", m.newGitHubBranch)
 	}
 	if m.addRepositoryPath != newPath {
-		t.Fatalf("should store the corrected path: expected %q, got %q", newPath, m.addRepositoryPath)
+		t.Fatalf("expected %q, got %q", newPath, m.addRepositoryPath)
 	}
 }
 
@@ -551,141 +301,53 @@ func TestIntegration_AddGitHub_StateIsolationFromOtherFlows(t *testing.T) {
 	m := createTestModel(t)
 
 	// Set state from other flows (these should not be affected)
-	m.selectedRepositoryID = "some-repo-id"
-
-	// Go through Add GitHub flow (partial)
-	m.state = SettingsStateAddGitHubName
-	m.textInput.SetValue("GitHub Repo")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	m.newGitHubPAT = "other-flow-pat"
+	m.selectedRepositoryID = "other-repo"
 
-	m.textInput.SetValue("https://github.com/test/repo")
-	m, _ = m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	m = startAddGitHubForm(t, m)
+	fillAddGitHubForm(m, "Test Repo", "https://github.com/test/repo", "", t.TempDir())
+	m, _ = submitAddGitHubForm(m)
 
-	// Verify other flow state is preserved
-	if m.selectedRepositoryID != "some-repo-id" {
-		t.Fatalf("should preserve selected repo ID: expected %q, got %q", "some-repo-id", m.selectedRepositoryID)
-	}
-
-	// Verify GitHub flow state is set
-	if m.addRepositoryName != "GitHub Repo" {
-		t.Fatalf("expected %q, got %q", "GitHub Repo", m.addRepositoryName)
+	if m.newGitHubPAT != "other-flow-pat" || m.selectedRepositoryID != "other-repo" {
+		t.Fatalf("other flow state was modified: pat %q, repo %q", m.newGitHubPAT, m.selectedRepositoryID)
 	}
 	if m.newGitHubURL != "https://github.com/test/repo" {
 		t.Fatalf("expected %q, got %q", "https://github.com/test/repo", m.newGitHubURL)
 	}
 }
 
-// TestIntegration_AddGitHub_RecoverFromValidationError tests
-// recovering from multiple validation errors and successfully completing the flow
-func TestIntegration_AddGitHub_RecoverFromValidationError(t *testing.T) {
+// TestIntegration_AddGitHub_FormView tests that all fields are rendered on one screen
+func TestIntegration_AddGitHub_FormView(t *testing.T) {
 	m := createTestModel(t)
-	m.currentConfig.Repositories = []repository.RepositoryEntry{}
-	m.state = SettingsStateAddGitHubName
-
-	// Step 1: Try empty name (should fail)
-	m.textInput.SetValue("")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubName {
-		t.Fatalf("should stay in name input: expected %v, got %v", SettingsStateAddGitHubName, m.state)
-	}
-	if m.layout.GetError() == nil {
-		t.Fatalf("should have error")
-	}
-
-	// Step 2: Fix the error by entering valid name
-	m.textInput.SetValue("Valid GitHub Repo")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubURL {
-		t.Fatalf("should proceed to URL input: expected %v, got %v", SettingsStateAddGitHubURL, m.state)
-	}
+	m = startAddGitHubForm(t, m)
 
-	// Step 3: Try invalid URL (should fail)
-	m.textInput.SetValue("not-a-valid-url")
-	m, _ = m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubURL {
-		t.Fatalf("should stay in URL input: expected %v, got %v", SettingsStateAddGitHubURL, m.state)
-	}
-	if m.layout.GetError() == nil {
-		t.Fatalf("should have error")
-	}
-
-	// Step 4: Fix with valid GitHub URL
-	m.textInput.SetValue("https://github.com/test/validrepo")
-	m, _ = m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubBranch {
-		t.Fatalf("should proceed to branch input: expected %v, got %v", SettingsStateAddGitHubBranch, m.state)
-	}
-
-	// Step 5: Enter valid branch
-	m.textInput.SetValue("main")
-	m, _ = m.handleAddGitHubBranchKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubPath {
-		t.Fatalf("should proceed to path input: expected %v, got %v", SettingsStateAddGitHubPath, m.state)
-	}
-
-	// Step 6: Try duplicate path (should fail)
-	testPath := t.TempDir()
-	m.currentConfig.Repositories = []repository.RepositoryEntry{
-		{ID: "existing", Name: "Existing", Type: repository.RepositoryTypeLocal, Path: testPath},
-	}
-	m.textInput.SetValue(testPath)
-	m, _ = m.handleAddGitHubPathKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubPath {
-		t.Fatalf("should stay in path input: expected %v, got %v", SettingsStateAddGitHubPath, m.state)
-	}
-	if m.layout.GetError() == nil {
-		t.Fatalf("should have error")
-	}
-
-	// Step 7: Fix with different path
-	newPath := t.TempDir()
-	m.textInput.SetValue(newPath)
-	m, cmd := m.handleAddGitHubPathKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if cmd == nil {
-		t.Fatalf("should proceed with creation")
-	}
-
-	// Verify flow data is correct
-	if m.addRepositoryName != "Valid GitHub Repo" {
-		t.Fatalf("expected %q, got %q", "Valid GitHub Repo", m.addRepositoryName)
-	}
-	if m.newGitHubURL != "https://github.com/test/validrepo" {
-		t.Fatalf("expected %q, got %q", "https://github.com/test/validrepo", m.newGitHubURL)
-	}
-	if m.newGitHubBranch != "main" {
-		t.Fatalf("expected %q, got %q", "main", m.newGitHubBranch)
-	}
-	if m.addRepositoryPath == "" {
-		t.Fatalf("expected non-empty repository path")
+	view := m.View()
+	for _, label := range []string{"Repository Name", "GitHub URL", "Branch (optional)", "Local Clone Path"} {
+		if !strings.Contains(view, label) {
+			t.Errorf("view should contain field %q", label)
+		}
 	}
 }
 
-// TestIntegration_AddGitHub_DefaultBranchFlow tests using empty branch
-// which should use the repository's default branch
-func TestIntegration_AddGitHub_DefaultBranchFlow(t *testing.T) {
+// TestIntegration_AddGitHub_ErrorReturnsToForm tests that dismissing a creation
+// error returns to the form with the entered values kept
+func TestIntegration_AddGitHub_ErrorReturnsToForm(t *testing.T) {
 	m := createTestModel(t)
-	m.currentConfig.Repositories = []repository.RepositoryEntry{}
-
-	// Navigate to branch input
-	m.state = SettingsStateAddGitHubName
-	m.textInput.SetValue("Test Repo")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	m = startAddGitHubForm(t, m)
+	fillAddGitHubForm(m, "Test Repo", "https://github.com/test/repo", "", t.TempDir())
 
-	m.textInput.SetValue("https://github.com/test/repo")
-	m, _ = m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubBranch {
-		t.Fatalf("expected %v, got %v", SettingsStateAddGitHubBranch, m.state)
+	updated, _ := m.Update(addGitHubErrorMsg{fmt.Errorf("clone failed")})
+	m = updated.(*SettingsModel)
+	if m.state != SettingsStateAddGitHubError {
+		t.Fatalf("expected %v, got %v", SettingsStateAddGitHubError, m.state)
 	}
 
-	// Submit empty branch (should use default)
-	m.textInput.SetValue("")
-	m, _ = m.handleAddGitHubBranchKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	if m.state != SettingsStateAddGitHubPath {
-		t.Fatalf("should proceed to path: expected %v, got %v", SettingsStateAddGitHubPath, m.state)
+	m, _ = m.handleAddGitHubErrorKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != SettingsStateAddGitHubForm {
+		t.Fatalf("should return to the form: expected %v, got %v", SettingsStateAddGitHubForm, m.state)
 	}
-	if m.newGitHubBranch != "" {
-		t.Fatalf("branch should be empty (uses default): got %q", m.newGitHubBranch)
+	if got := m.addGitHubForm.Value(gitHubFieldName); got != "Test Repo" {
+		t.Fatalf("form values should be kept, got name %q", got)
 	}
 }
 
@@ -705,23 +367,11 @@ func TestIntegration_AddGitHub_MissingPAT(t *testing.T) {
 	}
 	m.credManager = mockCred
 
-	// Navigate through the Add GitHub flow
-	m.state = SettingsStateAddRepositoryType
-	m.addRepositoryTypeIndex = 1 // GitHub
-	m, _ = m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	m.textInput.SetValue("Test Repo No PAT")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	m.textInput.SetValue("https://github.com/test/repo")
-	m, _ = m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
-	m.textInput.SetValue("main")
-	m, _ = m.handleAddGitHubBranchKeys(tea.KeyMsg{Type: tea.KeyEnter})
-
+	// Fill in and submit the Add GitHub form
+	m = startAddGitHubForm(t, m)
 	testPath := t.TempDir()
-	m.textInput.SetValue(testPath)
-	m, cmd := m.handleAddGitHubPathKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	fillAddGitHubForm(m, "Test Repo No PAT", "https://github.com/test/repo", "main", testPath)
+	m, cmd := submitAddGitHubForm(m)
 	if cmd == nil {
 		t.Fatalf("should return createGitHubRepository command")
 	}
@@ -886,8 +536,8 @@ func TestIntegration_AddGitHub_PATInput_Cancel(t *testing.T) {
 
 	// Press Esc to cancel
 	m, _ = m.handleAddGitHubPATKeys(tea.KeyMsg{Type: tea.KeyEsc})
-	if m.state != SettingsStateAddGitHubPath {
-		t.Fatalf("should return to AddGitHubPath: expected %v, got %v", SettingsStateAddGitHubPath, m.state)
+	if m.state != SettingsStateAddGitHubForm {
+		t.Fatalf("should return to AddGitHubForm: expected %v, got %v", SettingsStateAddGitHubForm, m.state)
 	}
 	if m.layout.GetError() != nil {
 		t.Fatalf("should clear any errors: got %v", m.layout.GetError())
@@ -931,3 +581,43 @@ func TestIntegration_AddGitHub_PATView(t *testing.T) {
 		t.Fatalf("view should mention PAT")
 	}
 }
+
+func mkdirAll(dir, name string) error {
+	return os.MkdirAll(filepath.Join(dir, name), 0755)
+}
+
+func writeTestFile(dir, name string) error {
+	return os.WriteFile(filepath.Join(dir, name), []byte("content"), 0644)
+}
+
+// TestIntegration_AddGitHub_PATInput_UseOnce tests that ctrl+o uses the PAT
+// for the initial clone without storing it
+func TestIntegration_AddGitHub_PATInput_UseOnce(t *testing.T) {
+	_, cleanup := SetTestConfigPath(t)
+	defer cleanup()
+	t.Cleanup(func() { repository.UseTokenOnce("") })
+
+	m := createTestModel(t)
+	m.state = SettingsStateAddGitHubPAT
+	m.addRepositoryName = "Test Repo"
+	m.newGitHubURL = "https://github.com/test/repo"
+	m.addRepositoryPath = t.TempDir()
+
+	mockCred := &mockCredentialManager{}
+	m.credManager = mockCred
+
+	m.textInput.SetValue("ghp_testtoken123456789")
+	m, cmd := m.handleAddGitHubPATKeys(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if cmd == nil {
+		t.Fatalf("should return createGitHubRepositoryWithPAT command")
+	}
+	if mockCred.storedToken != "" {
+		t.Fatalf("PAT should not be stored, got %q", mockCred.storedToken)
+	}
+	if !repository.HasOneShotToken() {
+		t.Fatal("PAT should be kept for a single clone")
+	}
+	if m.textInput.Value() != "" {
+		t.Error("PAT input should be cleared")
+	}
+}
@@ -4,12 +4,11 @@ package settingsmenu
 import (
 	"context"
 	"fmt"
-	"rulem/internal/config"
 	"rulem/internal/repository"
 	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers"
 	"rulem/pkg/fileops"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -111,9 +110,8 @@ func (m *SettingsModel) handleAddLocalErrorKeys(msg tea.KeyMsg) (*SettingsModel,
 // This is adapted from setupmenu.go but appends to existing repositories instead of replacing.
 func (m *SettingsModel) createLocalRepository() tea.Cmd {
 	return func() tea.Msg {
-		// Generate repository ID
-		timestamp := time.Now().Unix()
-		id := config.GenerateRepositoryID(m.addRepositoryName, timestamp)
+		timestamp := helpers.Now().Unix()
+		id := m.ctx.IDs.NewRepositoryID(m.addRepositoryName)
 
 		m.logger.Info("Creating local repository",
 			"id", id,
@@ -3,9 +3,12 @@ package settingsmenu
 
 import (
 	"fmt"
+	"rulem/internal/config"
 	"rulem/internal/repository"
+	"rulem/internal/tui/tuitest"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -284,7 +287,10 @@ func TestCreateLocalRepository_GeneratesID(t *testing.T) {
 	_, cleanup := SetTestConfigPath(t)
 	defer cleanup()
 
+	tuitest.FreezeTime(t, tuitest.NewClock(time.Unix(1728756432, 0)))
+
 	m := createTestModel(t)
+	m.ctx.IDs = &config.SequentialIDGenerator{}
 	m.addRepositoryName = "Test"
 	m.addRepositoryPath = t.TempDir()
 
@@ -298,11 +304,12 @@ func TestCreateLocalRepository_GeneratesID(t *testing.T) {
 	if len(m.currentConfig.Repositories) != 1 {
 		t.Fatalf("expected 1 repository, got %d", len(m.currentConfig.Repositories))
 	}
-	if m.currentConfig.Repositories[0].ID == "" {
-		t.Fatalf("expected non-empty ID")
+	repo := m.currentConfig.Repositories[0]
+	if got, want := repo.ID, "test-00000001"; got != want {
+		t.Fatalf("expected ID %q, got %q", want, got)
 	}
-	if !strings.Contains(m.currentConfig.Repositories[0].ID, "test") {
-		t.Fatalf("expected ID to contain %q, got %q", "test", m.currentConfig.Repositories[0].ID)
+	if repo.CreatedAt != 1728756432 {
+		t.Fatalf("expected CreatedAt 1728756432, got %d", repo.CreatedAt)
 	}
 }
 
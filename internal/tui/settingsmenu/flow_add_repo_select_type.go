@@ -9,12 +9,12 @@ import (
 )
 
 // Add Repository Type Selection Flow
-// Flow: MainMenu → AddRepositoryType → [AddLocalName | AddGitHubName]
+// Flow: MainMenu → AddRepositoryType → [AddLocalName | AddGitHubForm | AddTemplateForm]
 //
-// This file contains the handler & view for repository type selection (Local vs GitHub).
+// This file contains the handler & view for repository type selection (Local, GitHub or from a template).
 
 // handleAddRepositoryTypeKeys processes user input in the AddRepositoryType state.
-// User can navigate between Local and GitHub options and select one.
+// User can navigate between the Local, GitHub and Template options and select one.
 func (m *SettingsModel) handleAddRepositoryTypeKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
 	switch msg.String() {
 	case "up", "k":
@@ -22,19 +22,24 @@ func (m *SettingsModel) handleAddRepositoryTypeKeys(msg tea.KeyMsg) (*SettingsMo
 			m.addRepositoryTypeIndex--
 		}
 	case "down", "j":
-		if m.addRepositoryTypeIndex < 1 {
+		if m.addRepositoryTypeIndex < 2 {
 			m.addRepositoryTypeIndex++
 		}
 	case "enter", " ":
 		// Transition based on selected type
-		if m.addRepositoryTypeIndex == 0 {
+		switch m.addRepositoryTypeIndex {
+		case 0:
 			// Local repository
 			m.logger.LogUserAction("settings_add_type_local", "user selected local repository")
 			return m.transitionToAddLocalName()
-		} else {
+		case 1:
 			// GitHub repository
 			m.logger.LogUserAction("settings_add_type_github", "user selected GitHub repository")
-			return m.transitionToAddGitHubName()
+			return m.transitionToAddGitHubForm()
+		default:
+			// New repository from a template
+			m.logger.LogUserAction("settings_add_type_template", "user selected create from template")
+			return m.transitionToAddTemplateForm()
 		}
 	case "esc":
 		m.logger.LogUserAction("settings_add_type_cancelled", "returning to main menu")
@@ -51,7 +56,7 @@ func (m *SettingsModel) transitionToAddRepositoryType() (*SettingsModel, tea.Cmd
 }
 
 // viewAddRepositoryType renders the repository type selection screen.
-// Allows user to choose between Local and GitHub repository types.
+// Allows user to choose between Local and GitHub repository types, or to create one from a template.
 func (m *SettingsModel) viewAddRepositoryType() string {
 	m.layout = m.layout.SetConfig(components.LayoutConfig{
 		Title:    "➕ Add Repository",
@@ -79,6 +84,16 @@ func (m *SettingsModel) viewAddRepositoryType() string {
 	content += lipgloss.NewStyle().Bold(m.addRepositoryTypeIndex == 1).Render(prefix + "🔗 GitHub Repository")
 	content += "\n"
 	content += lipgloss.NewStyle().Faint(true).Render("   Sync with a remote GitHub repository")
+	content += "\n\n"
+
+	// Template option
+	prefix = "  "
+	if m.addRepositoryTypeIndex == 2 {
+		prefix = "▸ "
+	}
+	content += lipgloss.NewStyle().Bold(m.addRepositoryTypeIndex == 2).Render(prefix + "🧩 From Template")
+	content += "\n"
+	content += lipgloss.NewStyle().Faint(true).Render("   Create a new team repository from a template")
 
 	return m.layout.Render(content)
 }
@@ -29,10 +29,16 @@ func TestHandleAddRepositoryTypeKeys_Navigation(t *testing.T) {
 			expectedIndex: 1,
 		},
 		{
-			name:          "down at bottom stays at bottom",
+			name:          "down arrow moves from github to template",
 			initialIndex:  1,
 			key:           "down",
-			expectedIndex: 1,
+			expectedIndex: 2,
+		},
+		{
+			name:          "down at bottom stays at bottom",
+			initialIndex:  2,
+			key:           "down",
+			expectedIndex: 2,
 		},
 		{
 			name:          "up arrow moves from github to local",
@@ -115,13 +121,30 @@ func TestHandleAddRepositoryTypeKeys_SelectGitHub(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			newModel, _ := m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
 
-			if newModel.state != SettingsStateAddGitHubName {
-				t.Fatalf("expected state %v, got %v", SettingsStateAddGitHubName, newModel.state)
+			if newModel.state != SettingsStateAddGitHubForm {
+				t.Fatalf("expected state %v, got %v", SettingsStateAddGitHubForm, newModel.state)
 			}
 		})
 	}
 }
 
+// TestHandleAddRepositoryTypeKeys_SelectTemplate tests selecting creation from a template
+func TestHandleAddRepositoryTypeKeys_SelectTemplate(t *testing.T) {
+	m := createTestModel(t)
+	m.state = SettingsStateAddRepositoryType
+	m.addRepositoryTypeIndex = 2
+	m.currentConfig.TemplateURL = "https://github.com/acme/rules-template"
+
+	newModel, _ := m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if newModel.state != SettingsStateAddTemplateForm {
+		t.Fatalf("expected state %v, got %v", SettingsStateAddTemplateForm, newModel.state)
+	}
+	if got := newModel.addTemplateForm.Value(templateFieldTemplate); got != m.currentConfig.TemplateURL {
+		t.Fatalf("expected the configured template URL to be suggested, got %q", got)
+	}
+}
+
 // TestHandleAddRepositoryTypeKeys_Cancel tests canceling type selection
 func TestHandleAddRepositoryTypeKeys_Cancel(t *testing.T) {
 	m := createTestModel(t)
@@ -202,6 +225,11 @@ func TestViewAddRepositoryType(t *testing.T) {
 			selectedIndex: 1,
 			expectInView:  []string{"📁 Local Repository", "▸ 🔗 GitHub Repository", "Add Repository"},
 		},
+		{
+			name:          "template selected",
+			selectedIndex: 2,
+			expectInView:  []string{"📁 Local Repository", "▸ 🧩 From Template", "Add Repository"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -271,8 +299,8 @@ func TestAddTypeFlow_FullGitHubSelection(t *testing.T) {
 
 	// Select GitHub
 	m, _ = m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	if m.state != SettingsStateAddGitHubName {
-		t.Fatalf("expected state %v, got %v", SettingsStateAddGitHubName, m.state)
+	if m.state != SettingsStateAddGitHubForm {
+		t.Fatalf("expected state %v, got %v", SettingsStateAddGitHubForm, m.state)
 	}
 }
 
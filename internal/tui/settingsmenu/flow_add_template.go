@@ -0,0 +1,269 @@
+// Package settingsmenu provides the settings modification flow for the rulem TUI application.
+package settingsmenu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/components/form"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/helpers/settingshelpers"
+	"rulem/pkg/fileops"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Create From Template Flow
+// Flow: AddRepositoryType → AddTemplateForm → AddTemplateInProgress → [AddTemplateError | Complete]
+//
+// This file contains the handlers and views for standing up a new team rules
+// repository from a template: repository.CreateFromTemplate copies the
+// template with the team and organization filled in and pushes it to a new,
+// empty remote, which is then added and cloned like any GitHub repository.
+// The template URL is suggested from template_url in config.yaml.
+
+// Add from template form field keys
+const (
+	templateFieldName     = "name"
+	templateFieldTemplate = "template"
+	templateFieldURL      = "url"
+	templateFieldTeam     = "team"
+	templateFieldOrg      = "org"
+	templateFieldBranch   = "branch"
+	templateFieldPath     = "path"
+)
+
+// newAddTemplateForm builds the form for a repository created from a template.
+func (m *SettingsModel) newAddTemplateForm() form.Model {
+	f := form.New(
+		form.Field{
+			Key:         templateFieldName,
+			Label:       "Repository Name",
+			Placeholder: "e.g., Platform Team Rules",
+			Hint:        "This name will help you identify the repository",
+			CharLimit:   100,
+			Validate:    m.validateNewRepositoryName,
+		},
+		form.Field{
+			Key:                  templateFieldTemplate,
+			Label:                "Template URL",
+			Placeholder:          m.currentConfig.TemplateURL,
+			Hint:                 "Repository copied as the starting point (Enter accepts the suggestion)",
+			PlaceholderIsDefault: m.currentConfig.TemplateURL != "",
+			Validate:             settingshelpers.ValidateGitHubURL,
+		},
+		form.Field{
+			Key:         templateFieldURL,
+			Label:       "New Repository URL",
+			Placeholder: "e.g., https://github.com/org/team-rules",
+			Hint:        "An empty repository you can push to",
+			Validate:    m.validateNewGitHubURL,
+		},
+		form.Field{
+			Key:         templateFieldTeam,
+			Label:       "Team",
+			Placeholder: "e.g., Platform",
+			Hint:        "Replaces {{team}} in the template",
+			CharLimit:   100,
+		},
+		form.Field{
+			Key:                  templateFieldOrg,
+			Label:                "Organization",
+			Hint:                 "Replaces {{org}} in the template (defaults to the new repository's owner)",
+			Optional:             true,
+			PlaceholderIsDefault: true,
+			CharLimit:            100,
+		},
+		form.Field{
+			Key:                  templateFieldBranch,
+			Label:                "Branch",
+			Placeholder:          repository.DefaultTemplateBranch,
+			Hint:                 "Branch created on the new repository",
+			PlaceholderIsDefault: true,
+			Validate:             settingshelpers.ValidateBranchName,
+		},
+		form.Field{
+			Key:                  templateFieldPath,
+			Label:                "Local Clone Path",
+			Placeholder:          repository.GetDefaultStorageDir(),
+			Hint:                 "Where to clone the new repository locally (Enter accepts the suggestion)",
+			PlaceholderIsDefault: true,
+			Validate:             m.validateNewClonePath,
+		},
+	)
+	return f.SetWidth(m.layout.InputWidth())
+}
+
+// transitionToAddTemplateForm transitions to the AddTemplateForm state with an
+// empty form.
+func (m *SettingsModel) transitionToAddTemplateForm() (*SettingsModel, tea.Cmd) {
+	m.addTemplateForm = m.newAddTemplateForm()
+	return m.transitionTo(SettingsStateAddTemplateForm), textinput.Blink
+}
+
+// handleAddTemplateFormKeys processes input in the AddTemplateForm state. The
+// organization and clone path are suggested from the new repository URL as it
+// is entered; on submit the repository is created.
+func (m *SettingsModel) handleAddTemplateFormKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	if msg.String() == "esc" {
+		m.logger.LogUserAction("settings_add_template_cancelled", "returning to type selection")
+		return m.transitionTo(SettingsStateAddRepositoryType), nil
+	}
+
+	var cmd tea.Cmd
+	m.addTemplateForm, cmd = m.addTemplateForm.Update(msg)
+	m.hasChanges = true
+
+	url := m.addTemplateForm.Value(templateFieldURL)
+	owner := ""
+	if info, err := repository.ParseGitURL(url); err == nil {
+		owner = info.Owner
+	}
+	m.addTemplateForm = m.addTemplateForm.SetPlaceholder(templateFieldOrg, owner)
+	placeholder := settingshelpers.DeriveClonePath(url)
+	if placeholder == "" {
+		placeholder = repository.GetDefaultStorageDir()
+	}
+	m.addTemplateForm = m.addTemplateForm.SetPlaceholder(templateFieldPath, placeholder)
+
+	if !m.addTemplateForm.Submitted() {
+		return m, cmd
+	}
+
+	m.addRepositoryName = m.addTemplateForm.Value(templateFieldName)
+	m.newGitHubURL = url
+	m.newGitHubBranch = m.addTemplateForm.Value(templateFieldBranch)
+	m.addRepositoryPath = fileops.ExpandPath(m.addTemplateForm.Value(templateFieldPath))
+	opts := repository.TemplateOptions{
+		TemplateURL: m.addTemplateForm.Value(templateFieldTemplate),
+		RemoteURL:   m.newGitHubURL,
+		Branch:      m.newGitHubBranch,
+		Values: map[string]string{
+			"team": m.addTemplateForm.Value(templateFieldTeam),
+			"org":  m.addTemplateForm.Value(templateFieldOrg),
+		},
+	}
+	m.logger.LogUserAction("settings_add_template_submit", fmt.Sprintf("%s from %s", opts.RemoteURL, opts.TemplateURL))
+
+	m.layout = m.layout.ClearError()
+	return m.transitionTo(SettingsStateAddTemplateInProgress), m.createFromTemplate(opts)
+}
+
+// handleAddTemplateInProgressKeys blocks input while the repository is created.
+func (m *SettingsModel) handleAddTemplateInProgressKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	return m, nil
+}
+
+// handleAddTemplateErrorKeys processes input in the AddTemplateError state.
+// Any key returns to the form with the entered values kept.
+func (m *SettingsModel) handleAddTemplateErrorKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	m.logger.LogUserAction("settings_add_template_error_dismiss", msg.String())
+	m.layout = m.layout.ClearError()
+	return m.transitionTo(SettingsStateAddTemplateForm), nil
+}
+
+// createFromTemplate pushes the filled in template to the new repository,
+// then adds it to the configuration and clones it.
+func (m *SettingsModel) createFromTemplate(opts repository.TemplateOptions) tea.Cmd {
+	return func() tea.Msg {
+		result, err := repository.CreateFromTemplate(m.context, opts, m.logger)
+		if err != nil {
+			return addTemplateErrorMsg{err}
+		}
+
+		id := m.ctx.IDs.NewRepositoryID(m.addRepositoryName)
+		m.logger.Info("Adding repository created from template",
+			"id", id,
+			"name", m.addRepositoryName,
+			"url", m.newGitHubURL,
+			"branch", result.Branch,
+			"path", m.addRepositoryPath)
+
+		url, branch := m.newGitHubURL, result.Branch
+		m.currentConfig.Repositories = append(m.currentConfig.Repositories, repository.RepositoryEntry{
+			ID:        id,
+			Name:      m.addRepositoryName,
+			Type:      repository.RepositoryTypeGitHub,
+			Path:      m.addRepositoryPath,
+			RemoteURL: &url,
+			Branch:    &branch,
+			CreatedAt: helpers.Now().Unix(),
+		})
+
+		if err := m.currentConfig.Save(); err != nil {
+			return addTemplateErrorMsg{fmt.Errorf("the repository was created at %s but could not be added: failed to save configuration: %w", url, err)}
+		}
+
+		// Reload repositories (this clones the new repository)
+		m.preparedRepos, err = repository.PrepareAllRepositories(context.Background(), m.currentConfig.Repositories, m.logger)
+		if err != nil {
+			m.logger.Warn("Failed to reload repositories after creation", "error", err)
+			return addTemplateErrorMsg{fmt.Errorf("failed to prepare new repository: %w", err)}
+		}
+		m.repoList.SetItems(BuildSettingsMainMenuItems(m.preparedRepos))
+
+		m.logger.Info("Repository created from template successfully", "commit", result.Commit[:8], "rewritten", len(result.Rewritten))
+		m.addRepositoryName = ""
+		m.addRepositoryPath = ""
+		m.newGitHubURL = ""
+		m.newGitHubBranch = ""
+		return settingsCompleteMsg{}
+	}
+}
+
+// Views
+
+// viewAddTemplateForm renders the template form with inline validation errors.
+func (m *SettingsModel) viewAddTemplateForm() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🧩 Create From Template",
+		Subtitle: "Start a new team rules repository from a template",
+		HelpText: "Tab/↑/↓ to move between fields • Enter to continue or create • Esc to go back",
+	})
+
+	return m.layout.Render(m.addTemplateForm.View())
+}
+
+// viewAddTemplateInProgress renders the screen shown while the repository is created.
+func (m *SettingsModel) viewAddTemplateInProgress() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🧩 Creating Repository...",
+		Subtitle: "Copying the template to the new repository",
+		HelpText: "Please wait",
+	})
+
+	content := lipgloss.NewStyle().Faint(true).Render("Cloning the template, filling in placeholders and pushing...")
+
+	return m.layout.Render(content)
+}
+
+// viewAddTemplateError renders why the repository could not be created.
+func (m *SettingsModel) viewAddTemplateError() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "❌ Failed to Create From Template",
+		Subtitle: "Cannot create repository",
+		HelpText: "Press any key to return",
+	})
+
+	var content strings.Builder
+	content.WriteString("Failed to create the repository from the template:\n\n")
+
+	err := m.layout.GetError()
+	if err == nil {
+		err = errors.New("unknown error occurred")
+	}
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff5f87")).
+		Render(fmt.Sprintf("• %s", err.Error())))
+
+	content.WriteString("\n\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
+		Render("💡 Common reasons:\n  - The new repository does not exist yet or is not empty\n  - The stored PAT cannot push to the new repository\n  - Template URL not accessible\n  - Network connectivity issues"))
+
+	return m.layout.Render(content.String())
+}
@@ -0,0 +1,97 @@
+// Package settingsmenu provides the settings modification flow for the rulem TUI application.
+package settingsmenu
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startAddTemplateForm navigates from type selection to the template form
+func startAddTemplateForm(t *testing.T, m *SettingsModel) *SettingsModel {
+	t.Helper()
+	m.state = SettingsStateAddRepositoryType
+	m.addRepositoryTypeIndex = 2 // Template
+	m, _ = m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != SettingsStateAddTemplateForm {
+		t.Fatalf("should transition to AddTemplateForm: expected %v, got %v", SettingsStateAddTemplateForm, m.state)
+	}
+	return m
+}
+
+// submitAddTemplateForm presses Enter through the form, stopping at the first
+// field that fails validation.
+func submitAddTemplateForm(m *SettingsModel) (*SettingsModel, tea.Cmd) {
+	var cmd tea.Cmd
+	for range 7 {
+		focused := m.addTemplateForm.Focused()
+		m, cmd = m.handleAddTemplateFormKeys(tea.KeyMsg{Type: tea.KeyEnter})
+		if m.state != SettingsStateAddTemplateForm || m.addTemplateForm.Error(focused) != nil {
+			break
+		}
+	}
+	return m, cmd
+}
+
+func TestAddTemplateForm_Submit(t *testing.T) {
+	m := createTestModel(t)
+	m.currentConfig.TemplateURL = "https://github.com/acme/rules-template"
+	m = startAddTemplateForm(t, m)
+
+	path := t.TempDir()
+	m.addTemplateForm = m.addTemplateForm.
+		SetValue(templateFieldName, "Platform Rules").
+		SetValue(templateFieldURL, "https://github.com/acme/platform-rules").
+		SetValue(templateFieldTeam, "Platform").
+		SetValue(templateFieldPath, path)
+
+	m, cmd := submitAddTemplateForm(m)
+	if m.state != SettingsStateAddTemplateInProgress || cmd == nil {
+		t.Fatalf("expected the repository to be created, got state %v (cmd %v)", m.state, cmd != nil)
+	}
+	if m.addRepositoryName != "Platform Rules" || m.newGitHubURL != "https://github.com/acme/platform-rules" || m.addRepositoryPath != path {
+		t.Errorf("the entered details were not stored: %q %q %q", m.addRepositoryName, m.newGitHubURL, m.addRepositoryPath)
+	}
+	if m.newGitHubBranch != "main" {
+		t.Errorf("expected the default branch, got %q", m.newGitHubBranch)
+	}
+	if got := m.addTemplateForm.Value(templateFieldOrg); got != "acme" {
+		t.Errorf("the organization should default to the new repository's owner, got %q", got)
+	}
+}
+
+func TestAddTemplateForm_RequiresTemplateAndTeam(t *testing.T) {
+	m := startAddTemplateForm(t, createTestModel(t))
+	m.addTemplateForm = m.addTemplateForm.
+		SetValue(templateFieldName, "Platform Rules").
+		SetValue(templateFieldURL, "https://github.com/acme/platform-rules")
+
+	m, _ = submitAddTemplateForm(m)
+	if m.state != SettingsStateAddTemplateForm {
+		t.Fatalf("expected to stay on the form, got %v", m.state)
+	}
+	if m.addTemplateForm.Error(templateFieldTemplate) == nil {
+		t.Error("a template URL is required when none is configured")
+	}
+}
+
+func TestAddTemplateError_ReturnsToForm(t *testing.T) {
+	m := startAddTemplateForm(t, createTestModel(t))
+
+	updated, _ := m.Update(addTemplateErrorMsg{errors.New("push rejected")})
+	m = updated.(*SettingsModel)
+	if m.state != SettingsStateAddTemplateError {
+		t.Fatalf("expected state %v, got %v", SettingsStateAddTemplateError, m.state)
+	}
+
+	m, _ = m.handleAddTemplateErrorKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != SettingsStateAddTemplateForm {
+		t.Fatalf("expected state %v, got %v", SettingsStateAddTemplateForm, m.state)
+	}
+
+	m, _ = m.handleAddTemplateFormKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != SettingsStateAddRepositoryType {
+		t.Fatalf("expected state %v, got %v", SettingsStateAddRepositoryType, m.state)
+	}
+}
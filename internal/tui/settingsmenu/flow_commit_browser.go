@@ -0,0 +1,275 @@
+// Package settingsmenu provides the settings modification flow for the rulem TUI application.
+package settingsmenu
+
+import (
+	"fmt"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Commit Browser Flow
+// Flow: RepositoryActions → CommitBrowser → [CommitCheckoutConfirm → CommitBrowser]
+//
+// This file contains the handlers and views for browsing the recent commits of
+// a GitHub clone. A commit can be checked out in detached mode to inspect the
+// rules as they were, e.g. when a rule regressed after a sync; sync is paused
+// until the user returns to the branch with r.
+
+const (
+	// commitBrowserLimit is the number of commits loaded into the browser
+	commitBrowserLimit = 50
+	// commitBrowserVisible is the number of commits shown at once
+	commitBrowserVisible = 6
+	// commitBrowserFiles is the number of touched files shown for the selected commit
+	commitBrowserFiles = 5
+)
+
+// handleCommitBrowserKeys processes user input on the commit list.
+func (m *SettingsModel) handleCommitBrowserKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.commitsCursor > 0 {
+			m.commitsCursor--
+		}
+	case "down", "j":
+		if m.commitsCursor < len(m.commits)-1 {
+			m.commitsCursor++
+		}
+	case "enter", "c":
+		if len(m.commits) == 0 {
+			return m, nil
+		}
+		m.logger.LogUserAction("settings_commit_checkout_requested", m.commits[m.commitsCursor].Hash)
+		return m.transitionTo(SettingsStateCommitCheckoutConfirm), nil
+	case "r":
+		if m.commitInspection == nil {
+			return m, nil
+		}
+		m.logger.LogUserAction("settings_commit_inspection_return", m.commitInspection.Branch)
+		return m, m.returnFromInspection()
+	case "esc":
+		m.resetCommitBrowser()
+		return m.transitionTo(SettingsStateRepositoryActions), nil
+	}
+	return m, nil
+}
+
+// handleCommitCheckoutConfirmKeys processes user input on the checkout confirmation screen.
+func (m *SettingsModel) handleCommitCheckoutConfirmKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		hash := m.commits[m.commitsCursor].Hash
+		m.logger.LogUserAction("settings_commit_checkout_confirmed", hash)
+		return m, m.checkoutCommit(hash)
+	case "n", "N", "esc":
+		m.logger.LogUserAction("settings_commit_checkout_cancelled", "returning to commit list")
+		return m.transitionTo(SettingsStateCommitBrowser), nil
+	}
+	return m, nil
+}
+
+// transitionToCommitBrowser opens the commit list and starts loading it.
+func (m *SettingsModel) transitionToCommitBrowser() (*SettingsModel, tea.Cmd) {
+	m.resetCommitBrowser()
+	return m.transitionTo(SettingsStateCommitBrowser), m.loadCommits()
+}
+
+// handleCommitsLoadedMsg stores the loaded commits and inspection state.
+func (m *SettingsModel) handleCommitsLoadedMsg(msg commitsLoadedMsg) (*SettingsModel, tea.Cmd) {
+	if msg.err != nil {
+		m.logger.Error("Failed to load commits", "error", msg.err)
+		m.layout = m.layout.SetError(msg.err)
+		return m, nil
+	}
+
+	m.commits = msg.commits
+	m.commitInspection = msg.inspection
+	m.commitsLoaded = true
+	if m.commitsCursor >= len(m.commits) {
+		m.commitsCursor = max(len(m.commits)-1, 0)
+	}
+	return m, nil
+}
+
+// handleCommitCheckoutComplete handles the outcome of checking out a commit or
+// returning to the branch, reloading the list on success.
+func (m *SettingsModel) handleCommitCheckoutComplete(msg commitCheckoutCompleteMsg) (*SettingsModel, tea.Cmd) {
+	m = m.transitionTo(SettingsStateCommitBrowser)
+	if msg.err != nil {
+		m.logger.Error("Commit checkout failed", "error", msg.err)
+		m.layout = m.layout.SetError(msg.err)
+		return m, nil
+	}
+	return m, m.loadCommits()
+}
+
+// resetCommitBrowser clears the commit browser state.
+func (m *SettingsModel) resetCommitBrowser() {
+	m.commits = nil
+	m.commitsCursor = 0
+	m.commitsLoaded = false
+	m.commitInspection = nil
+}
+
+// loadCommits lists the recent commits of the selected repository.
+func (m *SettingsModel) loadCommits() tea.Cmd {
+	return func() tea.Msg {
+		path, err := m.selectedRepositoryPath()
+		if err != nil {
+			return commitsLoadedMsg{err: err}
+		}
+		commits, err := repository.ListCommits(path, commitBrowserLimit)
+		if err != nil {
+			return commitsLoadedMsg{err: err}
+		}
+		msg := commitsLoadedMsg{commits: commits}
+		if inspection, ok := repository.CurrentInspection(path); ok {
+			msg.inspection = &inspection
+		}
+		return msg
+	}
+}
+
+// checkoutCommit checks out hash in detached mode for inspection.
+func (m *SettingsModel) checkoutCommit(hash string) tea.Cmd {
+	return func() tea.Msg {
+		path, err := m.selectedRepositoryPath()
+		if err != nil {
+			return commitCheckoutCompleteMsg{err: err}
+		}
+		if err := repository.CheckoutCommit(path, hash); err != nil {
+			return commitCheckoutCompleteMsg{err: err}
+		}
+		m.logger.Info("Checked out commit for inspection", "path", path, "commit", hash)
+		return commitCheckoutCompleteMsg{}
+	}
+}
+
+// returnFromInspection checks out the branch that was active before inspecting.
+func (m *SettingsModel) returnFromInspection() tea.Cmd {
+	return func() tea.Msg {
+		path, err := m.selectedRepositoryPath()
+		if err != nil {
+			return commitCheckoutCompleteMsg{err: err}
+		}
+		branch, err := repository.ReturnFromInspection(path)
+		if err != nil {
+			return commitCheckoutCompleteMsg{err: err}
+		}
+		m.logger.Info("Returned from commit inspection", "path", path, "branch", branch)
+		return commitCheckoutCompleteMsg{}
+	}
+}
+
+// Views
+
+// viewCommitBrowser renders the commit list with the files touched by the selected commit.
+func (m *SettingsModel) viewCommitBrowser() string {
+	helpText := "↑/↓ to navigate • Enter to check out • Esc to go back"
+	if m.commitInspection != nil {
+		helpText = "↑/↓ to navigate • Enter to check out • r to return to branch • Esc to go back"
+	}
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📜 Commit History",
+		Subtitle: "Recent commits of this repository",
+		HelpText: helpText,
+	})
+
+	var content strings.Builder
+
+	if m.commitInspection != nil {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#ffaf00")).
+			Render(fmt.Sprintf("🔍 Inspecting %s - sync is paused until you return to %s",
+				shortHash(m.commitInspection.Commit), m.commitInspection.Branch)))
+		content.WriteString("\n\n")
+	}
+
+	if !m.commitsLoaded {
+		content.WriteString(lipgloss.NewStyle().Faint(true).Render("Loading commits..."))
+		return m.layout.Render(content.String())
+	}
+
+	if len(m.commits) == 0 {
+		content.WriteString(lipgloss.NewStyle().Faint(true).Render("No commits found"))
+		return m.layout.Render(content.String())
+	}
+
+	start := 0
+	if m.commitsCursor >= commitBrowserVisible {
+		start = m.commitsCursor - commitBrowserVisible + 1
+	}
+	end := min(start+commitBrowserVisible, len(m.commits))
+
+	for i := start; i < end; i++ {
+		commit := m.commits[i]
+		prefix := "  "
+		if i == m.commitsCursor {
+			prefix = "▸ "
+		}
+		line := fmt.Sprintf("%s%s  %s  %s", prefix, commit.ShortHash(), commit.When.Format("2006-01-02"), commit.Message)
+		if m.commitInspection != nil && commit.Hash == m.commitInspection.Commit {
+			line += " (checked out)"
+		}
+		content.WriteString(lipgloss.NewStyle().Bold(i == m.commitsCursor).Render(line))
+		content.WriteString("\n")
+	}
+	if len(m.commits) > commitBrowserVisible {
+		content.WriteString(lipgloss.NewStyle().Faint(true).
+			Render(fmt.Sprintf("  %d-%d of %d", start+1, end, len(m.commits))))
+		content.WriteString("\n")
+	}
+
+	selected := m.commits[m.commitsCursor]
+	content.WriteString("\n")
+	content.WriteString(lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("Author: %s", selected.Author)))
+	content.WriteString("\n")
+	switch {
+	case selected.Boundary:
+		content.WriteString(lipgloss.NewStyle().Faint(true).
+			Render("Files: unknown - earlier history was not fetched (shallow clone)"))
+	case len(selected.Files) == 0:
+		content.WriteString(lipgloss.NewStyle().Faint(true).Render("Files: none"))
+	default:
+		content.WriteString(lipgloss.NewStyle().Faint(true).Render("Files:"))
+		for i, file := range selected.Files {
+			if i == commitBrowserFiles {
+				content.WriteString(lipgloss.NewStyle().Faint(true).
+					Render(fmt.Sprintf("\n  … and %d more", len(selected.Files)-commitBrowserFiles)))
+				break
+			}
+			content.WriteString(lipgloss.NewStyle().Faint(true).Render("\n  • " + file))
+		}
+	}
+
+	return m.layout.Render(content.String())
+}
+
+// viewCommitCheckoutConfirm renders the confirmation screen before checking out a commit.
+func (m *SettingsModel) viewCommitCheckoutConfirm() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📜 Check Out Commit",
+		Subtitle: "Inspect the rules as they were at this commit",
+		HelpText: "y to check out • n to cancel • Esc to go back",
+	})
+
+	commit := m.commits[m.commitsCursor]
+	highlightStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#5fd7ff"))
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Commit: %s  %s\n\n", highlightStyle.Render(commit.ShortHash()), commit.Message))
+	content.WriteString("The repository will be checked out at this commit in detached mode.\n")
+	content.WriteString("Rules are served from this commit and sync is paused until you\n")
+	content.WriteString("return to the branch with r on the commit list.\n\n")
+	content.WriteString("Check out this commit? (y/N)")
+
+	return m.layout.Render(content.String())
+}
+
+// shortHash abbreviates a full commit hash for display.
+func shortHash(hash string) string {
+	return repository.CommitInfo{Hash: hash}.ShortHash()
+}
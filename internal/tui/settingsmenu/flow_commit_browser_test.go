@@ -0,0 +1,137 @@
+package settingsmenu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/repository"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createCommitBrowserModel returns a model on the commit browser for a real
+// clone with two commits: "initial commit" and "add rules.md".
+func createCommitBrowserModel(t *testing.T) (*SettingsModel, string) {
+	t.Helper()
+	clonePath := createOriginAndClone(t, "")
+	commitToRepo(t, clonePath, "rules.md", "# rules\n")
+
+	m := createTestModelWithConfig(t, createGitHubConfig(clonePath, "https://github.com/test/repo.git", "main"))
+	m.selectedRepositoryID = "test-github-1"
+	m.state = SettingsStateRepositoryActions
+
+	m, cmd := m.transitionToCommitBrowser()
+	if m.state != SettingsStateCommitBrowser {
+		t.Fatalf("expected %v, got %v", SettingsStateCommitBrowser, m.state)
+	}
+	updated, _ := m.Update(cmd())
+	return updated.(*SettingsModel), clonePath
+}
+
+// runCommitCmd feeds cmd's message and the follow-up reload back into the model.
+func runCommitCmd(t *testing.T, m *SettingsModel, cmd tea.Cmd) *SettingsModel {
+	t.Helper()
+	for cmd != nil {
+		var updated tea.Model
+		updated, cmd = m.Update(cmd())
+		m = updated.(*SettingsModel)
+	}
+	return m
+}
+
+func TestCommitBrowser_CheckoutAndReturn(t *testing.T) {
+	m, clonePath := createCommitBrowserModel(t)
+
+	if len(m.commits) != 2 || m.commits[0].Message != "add rules.md" {
+		t.Fatalf("unexpected commits %+v", m.commits)
+	}
+	if m.commitInspection != nil {
+		t.Fatal("should not be inspecting yet")
+	}
+
+	m, _ = m.handleCommitBrowserKeys(keyRune("j"))
+	m, _ = m.handleCommitBrowserKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != SettingsStateCommitCheckoutConfirm {
+		t.Fatalf("expected %v, got %v", SettingsStateCommitCheckoutConfirm, m.state)
+	}
+
+	m, cmd := m.handleCommitCheckoutConfirmKeys(keyRune("y"))
+	m = runCommitCmd(t, m, cmd)
+	if m.state != SettingsStateCommitBrowser {
+		t.Fatalf("expected %v, got %v", SettingsStateCommitBrowser, m.state)
+	}
+	if m.commitInspection == nil || m.commitInspection.Commit != m.commits[1].Hash {
+		t.Fatalf("expected to inspect %s, got %+v", m.commits[1].Hash, m.commitInspection)
+	}
+	if _, err := os.Stat(filepath.Join(clonePath, "rules.md")); !os.IsNotExist(err) {
+		t.Errorf("rules.md should not exist at the initial commit, stat err = %v", err)
+	}
+	view := m.View()
+	for _, s := range []string{"Inspecting", "(checked out)", "r to return to branch"} {
+		if !strings.Contains(view, s) {
+			t.Errorf("expected view to contain %q", s)
+		}
+	}
+
+	m, cmd = m.handleCommitBrowserKeys(keyRune("r"))
+	m = runCommitCmd(t, m, cmd)
+	if m.commitInspection != nil {
+		t.Errorf("inspection should end, got %+v", m.commitInspection)
+	}
+	if _, err := os.Stat(filepath.Join(clonePath, "rules.md")); err != nil {
+		t.Errorf("rules.md should be back on the branch: %v", err)
+	}
+}
+
+func TestCommitBrowser_Keys(t *testing.T) {
+	tests := []struct {
+		name      string
+		commits   []repository.CommitInfo
+		key       tea.KeyMsg
+		wantState SettingsState
+		wantCmd   bool
+	}{
+		{name: "enter without commits", key: tea.KeyMsg{Type: tea.KeyEnter}, wantState: SettingsStateCommitBrowser},
+		{name: "r without inspection", commits: []repository.CommitInfo{{Hash: "abc"}}, key: keyRune("r"), wantState: SettingsStateCommitBrowser},
+		{name: "c opens confirm", commits: []repository.CommitInfo{{Hash: "abc"}}, key: keyRune("c"), wantState: SettingsStateCommitCheckoutConfirm},
+		{name: "esc returns", commits: []repository.CommitInfo{{Hash: "abc"}}, key: tea.KeyMsg{Type: tea.KeyEsc}, wantState: SettingsStateRepositoryActions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := createTestModel(t)
+			m.state = SettingsStateCommitBrowser
+			m, _ = m.handleCommitsLoadedMsg(commitsLoadedMsg{commits: tt.commits})
+
+			m, cmd := m.handleCommitBrowserKeys(tt.key)
+			if m.state != tt.wantState {
+				t.Errorf("expected %v, got %v", tt.wantState, m.state)
+			}
+			if (cmd != nil) != tt.wantCmd {
+				t.Errorf("cmd = %v, want cmd %v", cmd != nil, tt.wantCmd)
+			}
+			if tt.wantState == SettingsStateRepositoryActions && (m.commits != nil || m.commitsLoaded) {
+				t.Error("expected commit browser state to be reset on exit")
+			}
+		})
+	}
+}
+
+func TestHandleCommitCheckoutComplete_Error(t *testing.T) {
+	m := createTestModel(t)
+	m.state = SettingsStateCommitCheckoutConfirm
+
+	m, cmd := m.handleCommitCheckoutComplete(commitCheckoutCompleteMsg{err: errors.New("repository has uncommitted changes")})
+	if m.state != SettingsStateCommitBrowser {
+		t.Errorf("expected %v, got %v", SettingsStateCommitBrowser, m.state)
+	}
+	if m.layout.GetError() == nil {
+		t.Error("expected the error on the commit list")
+	}
+	if cmd != nil {
+		t.Error("should not reload commits after a failure")
+	}
+}
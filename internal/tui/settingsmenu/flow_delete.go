@@ -4,8 +4,11 @@ package settingsmenu
 import (
 	"context"
 	"fmt"
+	"os"
 	"rulem/internal/repository"
 	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers/textutil"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -71,6 +74,8 @@ func (m *SettingsModel) deleteRepository() tea.Cmd {
 			"type", deletedRepo.Type,
 			"path", deletedRepo.Path)
 
+		id, remoteURL, path := deletedRepo.ID, deletedRepo.GetRemoteURL(), deletedRepo.Path
+
 		// Remove from slice
 		m.currentConfig.Repositories = append(
 			m.currentConfig.Repositories[:idx],
@@ -83,6 +88,20 @@ func (m *SettingsModel) deleteRepository() tea.Cmd {
 			return deleteErrorMsg{fmt.Errorf("failed to save configuration: %w", err)}
 		}
 
+		// Forget a token stored for this repository only
+		if remoteURL != "" {
+			if err := m.credManager.DeleteRepositoryToken(remoteURL, id); err != nil {
+				m.logger.Warn("Failed to delete the repository's token", "id", id, "error", err)
+			}
+		}
+
+		// Branch worktrees are rulem's own checkouts, unlike the clone itself
+		if remoteURL != "" {
+			if err := repository.RemoveWorktrees(path); err != nil {
+				m.logger.Warn("Failed to remove the repository's branch worktrees", "id", id, "error", err)
+			}
+		}
+
 		// Reload repositories
 		var err error
 		m.preparedRepos, err = repository.PrepareAllRepositories(
@@ -129,11 +148,17 @@ func (m *SettingsModel) getCleanupWarning() string {
 		return "⚠️  Your GitHub clone directory will NOT be automatically deleted.\nYou may want to clean it up manually."
 	}
 
-	return fmt.Sprintf(`⚠️  Your current GitHub clone at:
+	warning := fmt.Sprintf(`⚠️  Your current GitHub clone at:
 
   %s
 
-will NOT be automatically deleted. You may want to clean it up manually.`, currentPath)
+will NOT be automatically deleted. You may want to clean it up manually.`, textutil.TruncatePath(currentPath, m.layout.ContentWidth()-2))
+	worktrees := repository.WorktreesDir(currentPath)
+	if info, err := os.Stat(worktrees); err == nil && info.IsDir() {
+		pathWidth := m.layout.ContentWidth() - lipgloss.Width("Its branch worktrees in  will be deleted.")
+		warning += fmt.Sprintf("\nIts branch worktrees in %s will be deleted.", textutil.TruncatePath(worktrees, pathWidth))
+	}
+	return warning
 }
 
 // Views
@@ -143,11 +168,19 @@ will NOT be automatically deleted. You may want to clean it up manually.`, curre
 func (m *SettingsModel) viewConfirmDelete() string {
 	// Get selected repository info
 	selectedRepo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID)
-	var repoInfo string
+	var repoInfo, repoDetail string
 	if err != nil {
 		repoInfo = "Unknown Repository"
 	} else {
-		repoInfo = fmt.Sprintf("%s (%s: %s)", selectedRepo.Name, string(selectedRepo.Type), selectedRepo.Path)
+		// Keep the name and type on one line; the path is shortened to fit and
+		// shown in full on the detail line below
+		prefix := fmt.Sprintf("%s (%s: ", selectedRepo.Name, string(selectedRepo.Type))
+		pathWidth := m.layout.ContentWidth() - 2 - lipgloss.Width(prefix) - 1
+		path, detail := textutil.WithDetail(selectedRepo.Path, pathWidth, m.layout.ContentWidth()-2, textutil.TruncatePath)
+		repoInfo = prefix + path + ")"
+		if detail != "" {
+			repoDetail = lipgloss.NewStyle().Faint(true).Render(detail)
+		}
 	}
 
 	m.layout = m.layout.SetConfig(components.LayoutConfig{
@@ -159,7 +192,7 @@ func (m *SettingsModel) viewConfirmDelete() string {
 	// Build warning message
 	warningText := fmt.Sprintf(`You are about to delete the repository:
 
-  %s
+  %s%s
 
 This will remove the repository from your configuration.
 
@@ -167,6 +200,7 @@ This will remove the repository from your configuration.
 
 Are you sure you want to proceed? (y/N)`,
 		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#ff5f5f")).Render(repoInfo),
+		indentDetail(repoDetail),
 		lipgloss.NewStyle().Faint(true).Render(m.getCleanupWarning()))
 
 	return m.layout.Render(warningText)
@@ -200,3 +234,12 @@ func (m *SettingsModel) viewDeleteError() string {
 
 	return m.layout.Render(content)
 }
+
+// indentDetail formats an optional detail block to sit under the indented
+// repository line of the confirmation screen
+func indentDetail(detail string) string {
+	if detail == "" {
+		return ""
+	}
+	return "\n  " + strings.ReplaceAll(detail, "\n", "\n  ")
+}
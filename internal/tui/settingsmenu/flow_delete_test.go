@@ -2,6 +2,7 @@
 package settingsmenu
 
 import (
+	"os"
 	"rulem/internal/repository"
 	"strings"
 	"testing"
@@ -75,6 +76,8 @@ func TestIntegration_DeleteGitHubRepositoryComplete(t *testing.T) {
 	defer cleanup()
 
 	m := createTestModel(t)
+	mockCred := &mockCredentialManager{}
+	m.credManager = mockCred
 
 	// Set up multiple repositories
 	path1 := t.TempDir()
@@ -102,6 +105,11 @@ func TestIntegration_DeleteGitHubRepositoryComplete(t *testing.T) {
 		},
 	}
 
+	worktree := repository.WorktreePath(path1, "experimental")
+	if err := os.MkdirAll(worktree, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
 	// Step 1: Select repository for deletion
 	m.selectedRepositoryID = "github-repo-1"
 	m.state = SettingsStateConfirmDelete
@@ -128,6 +136,15 @@ func TestIntegration_DeleteGitHubRepositoryComplete(t *testing.T) {
 	if m.state != SettingsStateMainMenu {
 		t.Fatalf("expected state %v, got %v", SettingsStateMainMenu, m.state)
 	}
+	if len(mockCred.deletedRepositoryTokens) != 1 || mockCred.deletedRepositoryTokens[0] != "github-repo-1" {
+		t.Errorf("expected the deleted repository's token to be forgotten, got %v", mockCred.deletedRepositoryTokens)
+	}
+	if _, err := os.Stat(repository.WorktreesDir(path1)); !os.IsNotExist(err) {
+		t.Errorf("expected the branch worktrees to be removed, stat err = %v", err)
+	}
+	if _, err := os.Stat(path1); err != nil {
+		t.Errorf("expected the clone itself to be kept: %v", err)
+	}
 
 	_ = configPath
 }
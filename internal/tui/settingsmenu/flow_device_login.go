@@ -0,0 +1,114 @@
+// Package settingsmenu provides the settings modification flow for the rulem TUI application.
+package settingsmenu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Sign In With GitHub Flow
+// Flow: UpdateGitHubPAT → (Ctrl+B) → DeviceLogin → [UpdatePATConfirm | UpdatePATError]
+//
+// This file contains the handlers and views for obtaining the global GitHub
+// token with the OAuth device flow instead of pasting a PAT. The user enters
+// the displayed code at github.com/login/device; the token GitHub issues goes
+// through the same confirmation and validation as a pasted PAT.
+
+// startDeviceLogin requests a device code and moves to the DeviceLogin state
+// once it arrives.
+func (m *SettingsModel) startDeviceLogin() (*SettingsModel, tea.Cmd) {
+	m.logger.LogUserAction("settings_pat_device_login", "user chose to sign in with the browser")
+
+	flow, err := repository.NewGitHubDeviceFlow()
+	if err != nil {
+		return m, func() tea.Msg { return updatePATErrorMsg{err} }
+	}
+
+	ctx, cancel := context.WithCancel(m.context)
+	m.deviceLoginCancel = cancel
+	return m, func() tea.Msg {
+		code, err := flow.Start(ctx, repository.DeviceLoginScopes)
+		if err != nil {
+			return updatePATErrorMsg{err}
+		}
+		return deviceCodeMsg{flow: flow, code: code, ctx: ctx}
+	}
+}
+
+// waitForDeviceLogin polls GitHub until the user has entered the code.
+func waitForDeviceLogin(msg deviceCodeMsg) tea.Cmd {
+	return func() tea.Msg {
+		token, err := msg.flow.Wait(msg.ctx, msg.code)
+		if errors.Is(err, context.Canceled) {
+			return nil // cancelled with Esc
+		}
+		if err != nil {
+			return updatePATErrorMsg{err}
+		}
+		return deviceTokenMsg{token: token}
+	}
+}
+
+// handleDeviceLoginKeys processes input in the DeviceLogin state. Esc stops
+// waiting and returns to PAT entry.
+func (m *SettingsModel) handleDeviceLoginKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	if msg.String() != "esc" {
+		return m, nil
+	}
+	m.logger.LogUserAction("settings_pat_device_login_cancel", "user stopped waiting for the browser sign in")
+	m.cancelDeviceLogin()
+	return m.transitionToUpdateGitHubPAT()
+}
+
+// cancelDeviceLogin stops a pending device flow, if any.
+func (m *SettingsModel) cancelDeviceLogin() {
+	if m.deviceLoginCancel != nil {
+		m.deviceLoginCancel()
+		m.deviceLoginCancel = nil
+	}
+	m.deviceCode = repository.DeviceCode{}
+}
+
+// Views
+
+// viewDeviceLogin renders the code to enter while waiting for authorization.
+func (m *SettingsModel) viewDeviceLogin() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🔑 Sign In With GitHub",
+		Subtitle: "Authorize rulem in your browser",
+		HelpText: "Esc to cancel",
+	})
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("1. Open %s\n", lipgloss.NewStyle().Underline(true).Render(m.deviceCode.VerificationURI)))
+	content.WriteString("2. Enter this code:\n\n")
+	content.WriteString("   " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#5fd7ff")).Render(m.deviceCode.UserCode))
+	content.WriteString("\n\n")
+	content.WriteString(lipgloss.NewStyle().Faint(true).Render(
+		fmt.Sprintf("Waiting for authorization... the code expires at %s", m.deviceCode.ExpiresAt.Format("15:04"))))
+
+	return m.layout.Render(content.String())
+}
+
+// viewGrantedScopes renders the scopes granted by a browser sign in, warning
+// when the repo scope private repositories need is missing.
+func (m *SettingsModel) viewGrantedScopes() string {
+	scopes := "none"
+	if len(m.deviceScopes) > 0 {
+		scopes = strings.Join(m.deviceScopes, ", ")
+	}
+	view := fmt.Sprintf("Granted scopes: %s\n", scopes)
+	if slices.Contains(m.deviceScopes, "repo") {
+		return view + "\n"
+	}
+	return view + lipgloss.NewStyle().Foreground(lipgloss.Color("#ff8700")).
+		Render(`⚠️  The "repo" scope was not granted, so private repositories cannot be cloned`) + "\n\n"
+}
@@ -0,0 +1,77 @@
+// Package settingsmenu provides the settings modification flow for the rulem TUI application.
+package settingsmenu
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"rulem/internal/repository"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDeviceLogin_WithoutClientID(t *testing.T) {
+	t.Setenv("RULEM_GITHUB_CLIENT_ID", "")
+	m := createTestModel(t)
+	m, _ = m.transitionToUpdateGitHubPAT()
+
+	m, cmd := m.handleUpdateGitHubPATKeys(tea.KeyMsg{Type: tea.KeyCtrlB})
+	if cmd == nil {
+		t.Fatal("expected an error command")
+	}
+	msg, ok := cmd().(updatePATErrorMsg)
+	if !ok || !strings.Contains(msg.err.Error(), "RULEM_GITHUB_CLIENT_ID") {
+		t.Fatalf("expected updatePATErrorMsg naming the client ID variable, got %#v", msg)
+	}
+	if m.state != SettingsStateUpdateGitHubPAT {
+		t.Fatalf("expected state %v, got %v", SettingsStateUpdateGitHubPAT, m.state)
+	}
+}
+
+func TestDeviceLogin_CodeAndToken(t *testing.T) {
+	m := createTestModel(t)
+	m, _ = m.transitionToUpdateGitHubPAT()
+	ctx, cancel := context.WithCancel(t.Context())
+	m.deviceLoginCancel = cancel
+
+	code := repository.DeviceCode{UserCode: "WDJB-MJHT", VerificationURI: "https://github.com/login/device"}
+	updated, cmd := m.Update(deviceCodeMsg{code: code, ctx: ctx})
+	m = updated.(*SettingsModel)
+	if m.state != SettingsStateDeviceLogin || cmd == nil {
+		t.Fatalf("expected to wait in %v, got %v (cmd %v)", SettingsStateDeviceLogin, m.state, cmd != nil)
+	}
+	if view := m.View(); !strings.Contains(view, "WDJB-MJHT") || !strings.Contains(view, code.VerificationURI) {
+		t.Errorf("the view should show the code and where to enter it:\n%s", view)
+	}
+
+	updated, _ = m.Update(deviceTokenMsg{token: repository.DeviceToken{AccessToken: "gho_abcdefghijklmnopqrstuvwxyz", Scopes: []string{"read:org"}}})
+	m = updated.(*SettingsModel)
+	if m.state != SettingsStateUpdatePATConfirm {
+		t.Fatalf("expected state %v, got %v", SettingsStateUpdatePATConfirm, m.state)
+	}
+	if m.newGitHubPAT != "gho_abcdefghijklmnopqrstuvwxyz" {
+		t.Errorf("the token should be pending confirmation, got %q", m.newGitHubPAT)
+	}
+	if ctx.Err() == nil {
+		t.Error("the sign in should be finished")
+	}
+	if view := m.View(); !strings.Contains(view, "Granted scopes: read:org") || !strings.Contains(view, `"repo" scope was not granted`) {
+		t.Errorf("the confirmation should show the granted scopes:\n%s", view)
+	}
+}
+
+func TestDeviceLogin_Cancel(t *testing.T) {
+	m := createTestModel(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	m.deviceLoginCancel = cancel
+	m.state = SettingsStateDeviceLogin
+
+	m, _ = m.handleDeviceLoginKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != SettingsStateUpdateGitHubPAT {
+		t.Fatalf("expected state %v, got %v", SettingsStateUpdateGitHubPAT, m.state)
+	}
+	if ctx.Err() == nil {
+		t.Error("Esc should stop waiting for the sign in")
+	}
+}
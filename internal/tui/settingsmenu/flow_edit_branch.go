@@ -17,13 +17,16 @@ import (
 )
 
 // Edit Branch Flow
-// Flow: UpdateGitHubBranch → (Dirty Check) → EditBranchConfirm → [EditBranchError | Complete]
+// Flow: UpdateGitHubBranch → (Dirty Check) → EditBranchConfirm → EditBranchInProgress → [EditBranchError | Complete]
 //
 // This file contains all handlers, transitions, and business logic for editing
 // the GitHub branch of an existing repository.
 //
 // IMPORTANT: This flow includes a dirty state check to ensure the repository
-// has no uncommitted changes before changing the branch.
+// has no uncommitted changes before changing the branch. On confirm the branch
+// is switched immediately through repository.GitSource.SwitchBranch (fetch,
+// verify, checkout, rollback on failure) and the config is only saved once the
+// clone is on the new branch.
 
 // handleUpdateGitHubBranchKeys processes user input in the UpdateGitHubBranch state.
 // Validates the branch name and triggers dirty state check before proceeding to confirmation.
@@ -69,7 +72,8 @@ func (m *SettingsModel) handleEditBranchConfirmKeys(msg tea.KeyMsg) (*SettingsMo
 	switch msg.String() {
 	case "enter", "y":
 		m.logger.LogUserAction("settings_branch_confirm", "user confirmed branch change")
-		return m, m.saveChanges()
+		m.branchSwitchStep = repository.BranchSwitchFetching
+		return m.transitionTo(SettingsStateEditBranchInProgress), m.startBranchSwitch()
 
 	case "esc", "n":
 		m.logger.LogUserAction("settings_branch_cancel", "user cancelled branch change at confirmation")
@@ -79,6 +83,12 @@ func (m *SettingsModel) handleEditBranchConfirmKeys(msg tea.KeyMsg) (*SettingsMo
 	return m, nil
 }
 
+// handleEditBranchInProgressKeys blocks input while the branch switch runs.
+// The switch rolls back on its own if it fails, so it is not cancellable.
+func (m *SettingsModel) handleEditBranchInProgressKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	return m, nil
+}
+
 // handleEditBranchErrorKeys processes user input in the EditBranchError state.
 // Any key dismisses the error and returns to repository actions.
 func (m *SettingsModel) handleEditBranchErrorKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
@@ -106,9 +116,61 @@ func (m *SettingsModel) transitionToUpdateGitHubBranch() (*SettingsModel, tea.Cm
 	return m.transitionTo(SettingsStateUpdateGitHubBranch), nil
 }
 
+// startBranchSwitch runs the branch switch in the background and streams its
+// progress. Each step arrives as a branchSwitchProgressMsg carrying the channel
+// for the next message; the switch ends with settingsCompleteMsg, which reloads
+// the config and prepared repositories, or editBranchErrorMsg.
+func (m *SettingsModel) startBranchSwitch() tea.Cmd {
+	return func() tea.Msg {
+		updates := make(chan tea.Msg, int(repository.BranchSwitchDone)+2)
+		go func() {
+			defer close(updates)
+			updates <- m.switchGitHubBranch(func(step repository.BranchSwitchStep) {
+				updates <- branchSwitchProgressMsg{step: step, updates: updates}
+			})
+		}()
+		return <-updates
+	}
+}
+
+// waitForProgress returns the next message of a running operation that reports
+// progress on updates, such as a branch switch or a storage relocation.
+func waitForProgress(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
+	}
+}
+
+// switchGitHubBranch switches the selected repository's clone to the new branch
+// and saves the branch to the config once the checkout succeeded.
+func (m *SettingsModel) switchGitHubBranch(progress func(repository.BranchSwitchStep)) tea.Msg {
+	repo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID)
+	if err != nil {
+		return editBranchErrorMsg{fmt.Errorf("failed to get repository: %w", err)}
+	}
+	if repo.RemoteURL == nil {
+		return editBranchErrorMsg{fmt.Errorf("repository has no remote URL configured")}
+	}
+
+	m.logger.Info("Switching GitHub branch", "repo", repo.Name, "branch", m.newGitHubBranch)
+	source := repository.NewGitSource(*repo.RemoteURL, repo.Branch, repo.Path)
+	source.RepositoryID = repo.ID
+	branch, err := source.SwitchBranch(context.Background(), m.newGitHubBranch, progress, m.logger)
+	if err != nil {
+		m.logger.Error("Branch switch failed", "repo", repo.Name, "error", err)
+		return editBranchErrorMsg{err}
+	}
+
+	if err := m.updateGitHubBranch(m.currentConfig); err != nil {
+		m.logger.Error("Branch switched but config update failed", "branch", branch, "error", err)
+		return editBranchErrorMsg{err}
+	}
+	return settingsCompleteMsg{}
+}
+
 // updateGitHubBranch updates the GitHub branch for a repository in the configuration.
 // Handles both setting a specific branch and using the default branch (nil).
-// After updating the configuration, it triggers an immediate fetch to checkout the new branch.
+// The clone is expected to be on the new branch already (see switchGitHubBranch).
 func (m *SettingsModel) updateGitHubBranch(cfg *config.Config) error {
 	repo, err := cfg.FindRepositoryByID(m.selectedRepositoryID)
 	if err != nil {
@@ -126,17 +188,6 @@ func (m *SettingsModel) updateGitHubBranch(cfg *config.Config) error {
 		"old", oldBranch,
 		"new", m.newGitHubBranch)
 
-	// Validate that the branch exists on the remote before saving
-	// (skip validation for empty branch as it means "use default")
-	if m.newGitHubBranch != "" {
-		m.logger.Debug("Validating branch exists on remote", "branch", m.newGitHubBranch, "path", repo.Path)
-		err := repository.ValidateRemoteBranchExists(context.Background(), repo.Path, m.newGitHubBranch, m.logger)
-		if err != nil {
-			return fmt.Errorf("branch validation failed: %w", err)
-		}
-		m.logger.Debug("Branch exists on remote", "branch", m.newGitHubBranch)
-	}
-
 	// Handle branch value
 	if m.newGitHubBranch == "" {
 		// Empty branch means use default
@@ -162,29 +213,6 @@ func (m *SettingsModel) updateGitHubBranch(cfg *config.Config) error {
 		"repo", repo.Name,
 		"new_branch", m.newGitHubBranch)
 
-	// Trigger an immediate fetch to checkout the new branch
-	// This ensures the working directory reflects the new branch immediately
-	m.logger.Info("Triggering fetch to checkout new branch", "branch", m.newGitHubBranch)
-
-	if repo.RemoteURL == nil {
-		m.logger.Warn("Cannot fetch: repository missing remote URL")
-		return nil // Don't fail the update, just skip the fetch
-	}
-
-	source := repository.NewGitSource(
-		*repo.RemoteURL,
-		repo.Branch,
-		repo.Path,
-	)
-
-	if err := source.FetchUpdates(context.Background(), m.logger); err != nil {
-		m.logger.Warn("Failed to fetch after branch update (config saved successfully)", "error", err)
-		// Don't return error - config was saved successfully
-		// The fetch will happen on next manual refresh
-	} else {
-		m.logger.Info("Successfully fetched and checked out new branch")
-	}
-
 	return nil
 }
 
@@ -216,7 +244,7 @@ func (m *SettingsModel) viewUpdateGitHubBranch() string {
 	content += "Branch name (leave empty for default):\n"
 	content += styles.InputStyle.Render(m.textInput.View())
 	content += "\n\n"
-	content += lipgloss.NewStyle().Faint(true).Render("💡 The repository will checkout to the new branch on next sync.")
+	content += lipgloss.NewStyle().Faint(true).Render("💡 The repository switches to the new branch as soon as you confirm.")
 
 	return m.layout.Render(content)
 }
@@ -251,8 +279,8 @@ func (m *SettingsModel) viewEditBranchConfirm() string {
 		content += fmt.Sprintf("New branch:     %s\n\n", highlightStyle.Render(newBranch))
 
 		content += lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500")).Render("⚠️  Note:") + "\n"
-		content += "The repository will checkout to the new branch on next sync.\n"
-		content += lipgloss.NewStyle().Faint(true).Render("Make sure all changes are committed before syncing.\n\n")
+		content += "The branch will be fetched and checked out now.\n"
+		content += lipgloss.NewStyle().Faint(true).Render("If the checkout fails, the current branch is restored.\n\n")
 	}
 
 	content += "Do you want to proceed? (y/N)"
@@ -260,6 +288,50 @@ func (m *SettingsModel) viewEditBranchConfirm() string {
 	return m.layout.Render(content)
 }
 
+// viewEditBranchInProgress renders the branch switch steps with the current one highlighted.
+func (m *SettingsModel) viewEditBranchInProgress() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🌿 Switching Branch...",
+		Subtitle: "Fetching and checking out the new branch",
+		HelpText: "Please wait",
+	})
+
+	newBranch := m.newGitHubBranch
+	if newBranch == "" {
+		newBranch = "(default)"
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Switching to %s\n\n", lipgloss.NewStyle().Bold(true).Render(newBranch)))
+
+	steps := []repository.BranchSwitchStep{
+		repository.BranchSwitchFetching,
+		repository.BranchSwitchVerifying,
+		repository.BranchSwitchCheckingOut,
+	}
+	for _, step := range steps {
+		switch {
+		case step == repository.BranchSwitchCheckingOut && m.branchSwitchStep == repository.BranchSwitchRollingBack:
+			content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5f87")).Render("✗ " + step.String()))
+		case step < m.branchSwitchStep:
+			content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00")).Render("✓ " + step.String()))
+		case step == m.branchSwitchStep:
+			content.WriteString(lipgloss.NewStyle().Bold(true).Render("▸ " + step.String() + "..."))
+		default:
+			content.WriteString(lipgloss.NewStyle().Faint(true).Render("  " + step.String()))
+		}
+		content.WriteString("\n")
+	}
+
+	if m.branchSwitchStep == repository.BranchSwitchRollingBack {
+		content.WriteString("\n")
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#ffaf00")).
+			Render("⚠ Checkout failed - restoring the previous branch..."))
+	}
+
+	return m.layout.Render(content.String())
+}
+
 // viewEditBranchError renders the branch change error screen.
 // Displays the error message and instructions to return.
 func (m *SettingsModel) viewEditBranchError() string {
@@ -287,6 +359,8 @@ func (m *SettingsModel) viewEditBranchError() string {
 		Render("💡 Common issues:\n")
 	content += lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
 		Render("  • Repository has uncommitted changes\n")
+	content += lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
+		Render("  • Branch does not exist on the remote\n")
 	content += lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
 		Render("  • Invalid branch name\n")
 	content += lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
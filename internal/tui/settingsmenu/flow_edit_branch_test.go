@@ -3,6 +3,10 @@ package settingsmenu
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"rulem/internal/repository"
@@ -10,17 +14,40 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// runBranchSwitch executes the command returned on confirm, feeding progress
+// messages back into the model until the switch finishes. It returns the model
+// after handling the final message, the final message and the steps seen.
+func runBranchSwitch(t *testing.T, m *SettingsModel, cmd tea.Cmd) (*SettingsModel, tea.Msg, []repository.BranchSwitchStep) {
+	t.Helper()
+	var steps []repository.BranchSwitchStep
+	for cmd != nil {
+		msg := cmd()
+		model, next := m.Update(msg)
+		m = model.(*SettingsModel)
+		progress, ok := msg.(branchSwitchProgressMsg)
+		if !ok {
+			return m, msg, steps
+		}
+		steps = append(steps, progress.step)
+		if m.state != SettingsStateEditBranchInProgress {
+			t.Fatalf("expected state %v during switch, got %v", SettingsStateEditBranchInProgress, m.state)
+		}
+		cmd = next
+	}
+	t.Fatal("branch switch ended without a final message")
+	return m, nil, steps
+}
+
 // TestIntegration_EditBranchComplete tests the complete
 // edit branch flow from start to finish with successful completion (clean repo)
 func TestIntegration_EditBranchComplete(t *testing.T) {
-	t.Skip("Requires real git repository - updateGitHubBranch validates branch on remote")
 	configPath, cleanup := SetTestConfigPath(t)
 	defer cleanup()
 
 	m := createTestModel(t)
 
 	// Set up existing GitHub repository
-	testPath := t.TempDir()
+	testPath := createOriginAndClone(t, "develop")
 	testURL := "https://github.com/test/repo"
 	testBranch := "main"
 	m.currentConfig.Repositories = []repository.RepositoryEntry{
@@ -55,21 +82,34 @@ func TestIntegration_EditBranchComplete(t *testing.T) {
 		t.Fatalf("expected state %v, got %v", SettingsStateEditBranchConfirm, m.state)
 	}
 
-	// Step 5: Confirm the change
+	// Step 5: Confirm the change (starts the branch switch)
 	m, cmd = m.handleEditBranchConfirmKeys(tea.KeyMsg{Type: tea.KeyEnter})
 	if cmd == nil {
-		t.Fatalf("should return saveChanges command")
+		t.Fatalf("should return branch switch command")
+	}
+	if m.state != SettingsStateEditBranchInProgress {
+		t.Fatalf("expected state %v, got %v", SettingsStateEditBranchInProgress, m.state)
 	}
 
-	// Step 6: Execute saveChanges command
-	completeMsg := cmd()
+	// Step 6: Run the switch to completion
+	m, completeMsg, steps := runBranchSwitch(t, m, cmd)
 	if _, ok := completeMsg.(settingsCompleteMsg); !ok {
-		t.Fatalf("should return settingsCompleteMsg, got %T", completeMsg)
+		t.Fatalf("should return settingsCompleteMsg, got %T (%v)", completeMsg, completeMsg)
+	}
+	wantSteps := []repository.BranchSwitchStep{
+		repository.BranchSwitchFetching,
+		repository.BranchSwitchVerifying,
+		repository.BranchSwitchCheckingOut,
+		repository.BranchSwitchDone,
+	}
+	if !reflect.DeepEqual(steps, wantSteps) {
+		t.Fatalf("expected steps %v, got %v", wantSteps, steps)
 	}
 
-	// Step 7: Handle the complete message
-	model, _ = m.Update(completeMsg)
-	m = model.(*SettingsModel)
+	// Step 7: The clone is on the new branch
+	if _, err := os.Stat(filepath.Join(testPath, "branch.txt")); err != nil {
+		t.Fatalf("expected develop's branch.txt after switch: %v", err)
+	}
 	if m.state != SettingsStateComplete {
 		t.Fatalf("expected state %v, got %v", SettingsStateComplete, m.state)
 	}
@@ -242,7 +282,7 @@ func TestIntegration_EditBranchEmptyValue(t *testing.T) {
 
 	m := createTestModel(t)
 
-	testPath := t.TempDir()
+	testPath := createOriginAndClone(t, "develop")
 	testURL := "https://github.com/test/repo"
 	testBranch := "develop"
 	m.currentConfig.Repositories = []repository.RepositoryEntry{
@@ -276,9 +316,10 @@ func TestIntegration_EditBranchEmptyValue(t *testing.T) {
 	if cmd == nil {
 		t.Fatalf("expected command on confirm")
 	}
-	completeMsg := cmd()
-	model, _ = m.Update(completeMsg)
-	m = model.(*SettingsModel)
+	m, completeMsg, _ := runBranchSwitch(t, m, cmd)
+	if _, ok := completeMsg.(settingsCompleteMsg); !ok {
+		t.Fatalf("expected settingsCompleteMsg, got %T (%v)", completeMsg, completeMsg)
+	}
 
 	// Verify branch is nil (default)
 	if len(m.currentConfig.Repositories) != 1 {
@@ -403,20 +444,19 @@ func TestIntegration_EditBranchDirtyCheckError(t *testing.T) {
 // TestIntegration_EditBranchMultipleRepositories tests editing branch
 // with multiple repositories (should only affect selected one)
 func TestIntegration_EditBranchMultipleRepositories(t *testing.T) {
-	t.Skip("Requires real git repository - updateGitHubBranch validates branch on remote")
 	configPath, cleanup := SetTestConfigPath(t)
 	defer cleanup()
 
 	m := createTestModel(t)
 
 	path1 := t.TempDir()
-	path2 := t.TempDir()
+	path2 := createOriginAndClone(t, "release")
 	path3 := t.TempDir()
 	url1 := "https://github.com/test/repo1"
 	url2 := "https://github.com/test/repo2"
 	url3 := "https://github.com/test/repo3"
 	branch1 := "main"
-	branch2 := "develop"
+	branch2 := "main"
 	branch3 := "feature"
 
 	m.currentConfig.Repositories = []repository.RepositoryEntry{
@@ -443,9 +483,10 @@ func TestIntegration_EditBranchMultipleRepositories(t *testing.T) {
 
 	// Confirm
 	m, cmd = m.handleEditBranchConfirmKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	completeMsg := cmd()
-	model, _ = m.Update(completeMsg)
-	m = model.(*SettingsModel)
+	m, completeMsg, _ := runBranchSwitch(t, m, cmd)
+	if _, ok := completeMsg.(settingsCompleteMsg); !ok {
+		t.Fatalf("expected settingsCompleteMsg, got %T (%v)", completeMsg, completeMsg)
+	}
 
 	// Verify only the selected repo was updated
 	if m.currentConfig.Repositories[0].Branch == nil || *m.currentConfig.Repositories[0].Branch != "main" {
@@ -464,13 +505,12 @@ func TestIntegration_EditBranchMultipleRepositories(t *testing.T) {
 // TestIntegration_EditBranchPreservesOtherFields tests that editing
 // branch doesn't affect other repository fields
 func TestIntegration_EditBranchPreservesOtherFields(t *testing.T) {
-	t.Skip("Requires real git repository - updateGitHubBranch validates branch on remote")
 	configPath, cleanup := SetTestConfigPath(t)
 	defer cleanup()
 
 	m := createTestModel(t)
 
-	testPath := t.TempDir()
+	testPath := createOriginAndClone(t, "new-branch")
 	testURL := "https://github.com/test/repo"
 	testBranch := "main"
 	createdAt := int64(1234567890)
@@ -504,9 +544,10 @@ func TestIntegration_EditBranchPreservesOtherFields(t *testing.T) {
 
 	// Confirm
 	m, cmd = m.handleEditBranchConfirmKeys(tea.KeyMsg{Type: tea.KeyEnter})
-	completeMsg := cmd()
-	model, _ = m.Update(completeMsg)
-	m = model.(*SettingsModel)
+	m, completeMsg, _ := runBranchSwitch(t, m, cmd)
+	if _, ok := completeMsg.(settingsCompleteMsg); !ok {
+		t.Fatalf("expected settingsCompleteMsg, got %T (%v)", completeMsg, completeMsg)
+	}
 
 	// Verify all other fields are preserved
 	repo := m.currentConfig.Repositories[0]
@@ -578,3 +619,68 @@ func TestIntegration_EditBranchStateIsolation(t *testing.T) {
 		t.Fatalf("expected newGitHubPath preserved, got %q", m.newGitHubPath)
 	}
 }
+
+// TestIntegration_EditBranchMissingBranch tests that a branch missing on the
+// remote fails the switch without touching the config or the clone
+func TestIntegration_EditBranchMissingBranch(t *testing.T) {
+	configPath, cleanup := SetTestConfigPath(t)
+	defer cleanup()
+
+	testPath := createOriginAndClone(t, "")
+	m := createTestModelWithConfig(t, createGitHubConfig(testPath, "https://github.com/test/repo", "main"))
+	m.selectedRepositoryID = "test-github-1"
+	m.newGitHubBranch = "does-not-exist"
+	m.hasChanges = true
+	m.changeType = ChangeOptionGitHubBranch
+	m.state = SettingsStateEditBranchConfirm
+
+	m, cmd := m.handleEditBranchConfirmKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	m, finalMsg, steps := runBranchSwitch(t, m, cmd)
+
+	if _, ok := finalMsg.(editBranchErrorMsg); !ok {
+		t.Fatalf("expected editBranchErrorMsg, got %T", finalMsg)
+	}
+	if m.state != SettingsStateEditBranchError {
+		t.Fatalf("expected state %v, got %v", SettingsStateEditBranchError, m.state)
+	}
+	if err := m.layout.GetError(); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected missing branch error, got %v", err)
+	}
+	if !reflect.DeepEqual(steps, []repository.BranchSwitchStep{repository.BranchSwitchFetching}) {
+		t.Fatalf("expected to stop after fetching, got %v", steps)
+	}
+	if branch := m.currentConfig.Repositories[0].Branch; branch == nil || *branch != "main" {
+		t.Fatalf("expected branch to remain %q, got %v", "main", branch)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Fatalf("config should not be saved on failure, stat err = %v", err)
+	}
+}
+
+func TestViewEditBranchInProgress(t *testing.T) {
+	tests := []struct {
+		name string
+		step repository.BranchSwitchStep
+		want []string
+	}{
+		{name: "fetching", step: repository.BranchSwitchFetching, want: []string{"▸ Fetching branch...", "Checking out"}},
+		{name: "checking out", step: repository.BranchSwitchCheckingOut, want: []string{"✓ Fetching branch", "✓ Verifying branch", "▸ Checking out..."}},
+		{name: "rolling back", step: repository.BranchSwitchRollingBack, want: []string{"✗ Checking out", "restoring the previous branch"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := createTestModel(t)
+			m.state = SettingsStateEditBranchInProgress
+			m.newGitHubBranch = "develop"
+			m.branchSwitchStep = tt.step
+
+			view := m.View()
+			for _, s := range tt.want {
+				if !strings.Contains(view, s) {
+					t.Errorf("expected view to contain %q, got:\n%s", s, view)
+				}
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"rulem/internal/repository"
 	"rulem/internal/tui/components"
 	"rulem/internal/tui/helpers/settingshelpers"
+	"rulem/internal/tui/helpers/textutil"
 	"rulem/internal/tui/styles"
 	"rulem/pkg/fileops"
 	"strings"
@@ -161,7 +162,8 @@ func (m *SettingsModel) viewUpdateGitHubPath() string {
 
 	if m.currentConfig != nil {
 		if repo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID); err == nil {
-			content.WriteString(fmt.Sprintf("Current: %s\n\n", lipgloss.NewStyle().Faint(true).Render(repo.Path)))
+			current := textutil.TruncatePath(repo.Path, m.layout.ContentWidth()-lipgloss.Width("Current: "))
+			content.WriteString(fmt.Sprintf("Current: %s\n\n", lipgloss.NewStyle().Faint(true).Render(current)))
 		}
 	}
 
@@ -185,8 +187,20 @@ func (m *SettingsModel) viewEditClonePathConfirm() string {
 	// Get current repository info
 	if repo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID); err == nil {
 		content.WriteString(fmt.Sprintf("Repository: %s\n\n", highlightStyle.Render(repo.Name)))
-		content.WriteString(fmt.Sprintf("Current path: %s\n", lipgloss.NewStyle().Faint(true).Render(repo.Path)))
-		content.WriteString(fmt.Sprintf("New path:     %s\n\n", highlightStyle.Render(m.newGitHubPath)))
+		// Both paths are shortened to fit, with the full value on a detail line
+		faint := lipgloss.NewStyle().Faint(true)
+		pathWidth := m.layout.ContentWidth() - lipgloss.Width("Current path: ")
+		current, currentDetail := textutil.WithDetail(repo.Path, pathWidth, m.layout.ContentWidth(), textutil.TruncatePath)
+		content.WriteString(fmt.Sprintf("Current path: %s\n", faint.Render(current)))
+		if currentDetail != "" {
+			content.WriteString(faint.Render(currentDetail) + "\n")
+		}
+		newPath, newDetail := textutil.WithDetail(m.newGitHubPath, pathWidth, m.layout.ContentWidth(), textutil.TruncatePath)
+		content.WriteString(fmt.Sprintf("New path:     %s\n", highlightStyle.Render(newPath)))
+		if newDetail != "" {
+			content.WriteString(faint.Render(newDetail) + "\n")
+		}
+		content.WriteString("\n")
 
 		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500")).Render("⚠️  Warning:\n"))
 		content.WriteString("The repository will be re-cloned to the new path on next sync.\n")
@@ -6,6 +6,7 @@ import (
 	"rulem/internal/config"
 	"rulem/internal/tui/components"
 	"rulem/internal/tui/helpers/settingshelpers"
+	"rulem/internal/tui/helpers/textutil"
 	"rulem/internal/tui/styles"
 	"strings"
 
@@ -210,12 +211,14 @@ func (m *SettingsModel) viewEditNameConfirm() string {
 
 		// Show repository type context
 		if repo.RemoteURL != nil {
+			url := textutil.TruncateURL(*repo.RemoteURL, m.layout.ContentWidth()-lipgloss.Width("📦 GitHub Repository: "))
 			content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render(
-				fmt.Sprintf("📦 GitHub Repository: %s", *repo.RemoteURL),
+				fmt.Sprintf("📦 GitHub Repository: %s", url),
 			))
 		} else {
+			path := textutil.TruncatePath(repo.Path, m.layout.ContentWidth()-lipgloss.Width("📁 Local Repository: "))
 			content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render(
-				fmt.Sprintf("📁 Local Repository: %s", repo.Path),
+				fmt.Sprintf("📁 Local Repository: %s", path),
 			))
 		}
 	}
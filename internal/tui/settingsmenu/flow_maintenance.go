@@ -0,0 +1,125 @@
+// Package settingsmenu provides the settings modification flow for the rulem TUI application.
+package settingsmenu
+
+import (
+	"context"
+	"fmt"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Maintenance Flow
+// Flow: RepositoryActions → MaintenanceInProgress → MaintenanceComplete → RepositoryActions
+//
+// This file contains the handlers and views for repacking a GitHub clone on
+// demand. Sync also runs the same maintenance on a schedule, see
+// repository.RunMaintenance.
+
+// handleMaintenanceInProgressKeys blocks input while maintenance runs.
+func (m *SettingsModel) handleMaintenanceInProgressKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	return m, nil
+}
+
+// handleMaintenanceCompleteKeys returns to the repository actions menu on any key.
+func (m *SettingsModel) handleMaintenanceCompleteKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	m.logger.LogUserAction("settings_maintenance_dismiss", "returning to repository actions")
+	m.maintenanceResult = repository.MaintenanceResult{}
+	return m.transitionTo(SettingsStateRepositoryActions), nil
+}
+
+// transitionToMaintenance starts maintenance on the selected repository.
+func (m *SettingsModel) transitionToMaintenance() (*SettingsModel, tea.Cmd) {
+	m.logger.LogUserAction("settings_maintenance_started", m.selectedRepositoryID)
+	m.maintenanceResult = repository.MaintenanceResult{}
+	return m.transitionTo(SettingsStateMaintenanceInProgress), m.runMaintenance()
+}
+
+// handleMaintenanceComplete shows the maintenance result, or its error.
+func (m *SettingsModel) handleMaintenanceComplete(msg maintenanceCompleteMsg) (*SettingsModel, tea.Cmd) {
+	m = m.transitionTo(SettingsStateMaintenanceComplete)
+	if msg.err != nil {
+		m.logger.Error("Repository maintenance failed", "error", msg.err)
+		m.layout = m.layout.SetError(msg.err)
+		return m, nil
+	}
+	m.maintenanceResult = msg.result
+	return m, nil
+}
+
+// runMaintenance repacks the selected repository.
+func (m *SettingsModel) runMaintenance() tea.Cmd {
+	return func() tea.Msg {
+		path, err := m.selectedRepositoryPath()
+		if err != nil {
+			return maintenanceCompleteMsg{err: err}
+		}
+		result, err := repository.RunMaintenance(context.Background(), path, m.logger)
+		return maintenanceCompleteMsg{result: result, err: err}
+	}
+}
+
+// Views
+
+// viewMaintenanceInProgress renders the screen shown while maintenance runs.
+func (m *SettingsModel) viewMaintenanceInProgress() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🧹 Running Maintenance...",
+		Subtitle: "Repacking the local clone",
+		HelpText: "Please wait",
+	})
+
+	content := lipgloss.NewStyle().Faint(true).Render("Packing objects and pruning unused ones...")
+
+	return m.layout.Render(content)
+}
+
+// viewMaintenanceComplete renders the object counts before and after maintenance.
+func (m *SettingsModel) viewMaintenanceComplete() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🧹 Maintenance",
+		Subtitle: "Local clone maintenance",
+		HelpText: "Press any key to return",
+	})
+
+	if m.layout.GetError() != nil {
+		return m.layout.Render(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
+			Render("💡 The clone is unchanged. Installing git enables a fallback to git gc."))
+	}
+
+	result := m.maintenanceResult
+	labelStyle := lipgloss.NewStyle().Faint(true)
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#5fd787")).Render("✓ Maintenance complete"))
+	content.WriteString("\n\n")
+	content.WriteString(fmt.Sprintf("%s %d → %d\n", labelStyle.Render("Loose objects:"),
+		result.Before.LooseObjects, result.After.LooseObjects))
+	content.WriteString(fmt.Sprintf("%s %d → %d\n", labelStyle.Render("Packs:        "),
+		result.Before.Packs, result.After.Packs))
+	content.WriteString(fmt.Sprintf("%s %s → %s", labelStyle.Render("Size:         "),
+		formatSize(result.Before.Size), formatSize(result.After.Size)))
+	if result.UsedCLI {
+		content.WriteString("\n\n")
+		content.WriteString(labelStyle.Render("go-git could not repack this clone; git gc was used instead."))
+	}
+
+	return m.layout.Render(content.String())
+}
+
+// formatSize formats a byte count for display.
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGT"[exp])
+}
@@ -0,0 +1,76 @@
+package settingsmenu
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMaintenance_RunAndDismiss(t *testing.T) {
+	clonePath := createOriginAndClone(t, "")
+	commitToRepo(t, clonePath, "rules.md", "# rules\n")
+
+	m := createTestModelWithConfig(t, createGitHubConfig(clonePath, "https://github.com/test/repo.git", "main"))
+	m.selectedRepositoryID = "test-github-1"
+	m.state = SettingsStateRepositoryActions
+
+	m, cmd := m.transitionToMaintenance()
+	if m.state != SettingsStateMaintenanceInProgress {
+		t.Fatalf("expected %v, got %v", SettingsStateMaintenanceInProgress, m.state)
+	}
+	if m, _ = m.handleMaintenanceInProgressKeys(keyRune("q")); m.state != SettingsStateMaintenanceInProgress {
+		t.Fatal("input should be blocked while maintenance runs")
+	}
+
+	updated, _ := m.Update(cmd())
+	m = updated.(*SettingsModel)
+	if m.state != SettingsStateMaintenanceComplete {
+		t.Fatalf("expected %v, got %v", SettingsStateMaintenanceComplete, m.state)
+	}
+	if err := m.layout.GetError(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.maintenanceResult.After.Packs != 1 || m.maintenanceResult.After.LooseObjects != 0 {
+		t.Errorf("expected a single pack and no loose objects, got %+v", m.maintenanceResult.After)
+	}
+	if view := m.View(); !strings.Contains(view, "Maintenance complete") {
+		t.Error("expected the view to report completion")
+	}
+
+	m, _ = m.handleMaintenanceCompleteKeys(keyRune("x"))
+	if m.state != SettingsStateRepositoryActions {
+		t.Errorf("expected %v, got %v", SettingsStateRepositoryActions, m.state)
+	}
+}
+
+func TestHandleMaintenanceComplete_Error(t *testing.T) {
+	m := createTestModelWithConfig(t, createGitHubConfig(t.TempDir(), "https://github.com/test/repo.git", "main"))
+	m.state = SettingsStateMaintenanceInProgress
+
+	m, _ = m.handleMaintenanceComplete(maintenanceCompleteMsg{err: errors.New("maintenance failed")})
+	if m.state != SettingsStateMaintenanceComplete {
+		t.Fatalf("expected %v, got %v", SettingsStateMaintenanceComplete, m.state)
+	}
+	if err := m.layout.GetError(); err == nil || err.Error() != "maintenance failed" {
+		t.Errorf("expected the error on the layout, got %v", err)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSize(tt.bytes); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Package settingsmenu provides the settings modification flow for the rulem TUI application.
+package settingsmenu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers/settingshelpers"
+	"rulem/internal/tui/helpers/textutil"
+	"rulem/internal/tui/styles"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Publish Changes Flow
+// Flow: RepositoryActions → PublishChanges → PublishInProgress → PublishComplete → RepositoryActions
+//
+// This file contains the handlers and views for publishing rule edits made in
+// a GitHub clone: the changed files are committed and pushed to the remote
+// with repository.GitSource.PushChanges, using the stored token.
+
+// handlePublishChangesKeys processes user input on the publish screen, where
+// the commit message is entered.
+func (m *SettingsModel) handlePublishChangesKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if !m.changedFilesLoaded {
+			return m, nil
+		}
+		if len(m.publishableFiles()) == 0 {
+			m.layout = m.layout.SetError(repository.ErrNothingToPush)
+			return m, nil
+		}
+		message := strings.TrimSpace(m.textInput.Value())
+		m.logger.LogUserAction("settings_publish_confirmed", message)
+		m.publishResult = repository.PushResult{}
+		return m.transitionTo(SettingsStatePublishInProgress), m.publishChanges(message)
+	case "esc":
+		m.logger.LogUserAction("settings_publish_cancelled", "returning to repository actions")
+		m.resetChangedFiles()
+		m.resetTemporaryChanges()
+		return m.transitionTo(SettingsStateRepositoryActions), nil
+	default:
+		return m.updateTextInput(msg)
+	}
+}
+
+// handlePublishInProgressKeys blocks input while the changes are pushed.
+func (m *SettingsModel) handlePublishInProgressKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	return m, nil
+}
+
+// handlePublishCompleteKeys returns to the repository actions menu on any key.
+func (m *SettingsModel) handlePublishCompleteKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	m.logger.LogUserAction("settings_publish_dismiss", "returning to repository actions")
+	m.publishResult = repository.PushResult{}
+	m.resetChangedFiles()
+	m.resetTemporaryChanges()
+	return m.transitionTo(SettingsStateRepositoryActions), nil
+}
+
+// transitionToPublishChanges opens the publish screen and starts loading the
+// changed files.
+func (m *SettingsModel) transitionToPublishChanges() (*SettingsModel, tea.Cmd) {
+	m.logger.LogUserAction("settings_publish_started", m.selectedRepositoryID)
+	m.resetChangedFiles()
+	blink := settingshelpers.ResetTextInputForState(&m.textInput, "", "Leave empty for a generated message", textinput.EchoNormal)
+	return m.transitionTo(SettingsStatePublishChanges), tea.Batch(m.loadChangedFiles(), blink)
+}
+
+// handlePublishComplete shows the pushed commit, or the error.
+func (m *SettingsModel) handlePublishComplete(msg publishCompleteMsg) (*SettingsModel, tea.Cmd) {
+	m = m.transitionTo(SettingsStatePublishComplete)
+	if msg.err != nil {
+		m.logger.Error("Publishing local changes failed", "error", msg.err)
+		m.layout = m.layout.SetError(msg.err)
+		return m, nil
+	}
+	m.publishResult = msg.result
+	return m, nil
+}
+
+// publishChanges commits and pushes the changes of the selected repository.
+func (m *SettingsModel) publishChanges(message string) tea.Cmd {
+	return func() tea.Msg {
+		source, err := m.selectedGitSource()
+		if err != nil {
+			return publishCompleteMsg{err: err}
+		}
+		result, err := source.PushChanges(context.Background(), message, m.logger)
+		return publishCompleteMsg{result: result, err: err}
+	}
+}
+
+// selectedGitSource returns the GitSource of the selected GitHub repository,
+// including its subpath.
+func (m *SettingsModel) selectedGitSource() (repository.GitSource, error) {
+	repo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID)
+	if err != nil {
+		return repository.GitSource{}, err
+	}
+	if !repo.IsRemote() || repo.RemoteURL == nil {
+		return repository.GitSource{}, fmt.Errorf("not a GitHub repository")
+	}
+	source := repository.NewGitSource(*repo.RemoteURL, repo.Branch, repo.Path)
+	source.RepositoryID = repo.ID
+	if source.Subpath, err = repository.CleanSubpath(repo.GetSubpath()); err != nil {
+		return repository.GitSource{}, err
+	}
+	return source, nil
+}
+
+// publishableFiles returns the loaded changed files that PushChanges would
+// publish, i.e. those inside the repository's subpath.
+func (m *SettingsModel) publishableFiles() []repository.FileChange {
+	subpath := ""
+	if repo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID); err == nil {
+		subpath = repo.GetSubpath()
+	}
+	var files []repository.FileChange
+	for _, file := range m.changedFiles {
+		if _, ok := repository.RelativeToSubpath(file.Path, subpath); ok {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Views
+
+// viewPublishChanges renders the files to publish and the commit message input.
+func (m *SettingsModel) viewPublishChanges() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📤 Publish Local Changes",
+		Subtitle: "Commit your rule edits and push them to GitHub",
+		HelpText: "Enter to publish • Esc to cancel",
+	})
+
+	var content strings.Builder
+
+	if !m.changedFilesLoaded {
+		content.WriteString(lipgloss.NewStyle().Faint(true).Render("Loading changed files..."))
+		return m.layout.Render(content.String())
+	}
+
+	files := m.publishableFiles()
+	if len(files) == 0 {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00")).
+			Render("✓ No local changes to publish"))
+		return m.layout.Render(content.String())
+	}
+
+	content.WriteString(fmt.Sprintf("%d file(s) will be committed and pushed:\n", len(files)))
+	for _, file := range files {
+		line := fmt.Sprintf("  %s  ", file.Kind)
+		content.WriteString(line + textutil.TruncatePath(file.Path, m.layout.ContentWidth()-lipgloss.Width(line)) + "\n")
+	}
+
+	content.WriteString("\nCommit message:\n")
+	content.WriteString(styles.InputStyle.Render(m.textInput.View()))
+	content.WriteString("\n\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
+		Render("💡 Pushing needs a token with write access to the repository."))
+
+	return m.layout.Render(content.String())
+}
+
+// viewPublishInProgress renders the screen shown while the changes are pushed.
+func (m *SettingsModel) viewPublishInProgress() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📤 Publishing...",
+		Subtitle: "Committing and pushing your changes",
+		HelpText: "Please wait",
+	})
+
+	content := lipgloss.NewStyle().Faint(true).Render("Pushing to the remote...")
+
+	return m.layout.Render(content)
+}
+
+// viewPublishComplete renders the pushed commit, or the error.
+func (m *SettingsModel) viewPublishComplete() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📤 Publish Local Changes",
+		Subtitle: "Publishing result",
+		HelpText: "Press any key to return",
+	})
+
+	if err := m.layout.GetError(); err != nil {
+		hint := "💡 Your changes are still in the working tree; nothing was lost."
+		if errors.Is(err, repository.ErrNothingToPush) {
+			hint = "💡 Edit rules in the clone first, then publish them from here."
+		}
+		return m.layout.Render(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render(hint))
+	}
+
+	result := m.publishResult
+	labelStyle := lipgloss.NewStyle().Faint(true)
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#5fd787")).Render("✓ Changes published"))
+	content.WriteString("\n\n")
+	content.WriteString(fmt.Sprintf("%s %s\n", labelStyle.Render("Branch:"), result.Branch))
+	if len(result.Commit) >= 8 {
+		content.WriteString(fmt.Sprintf("%s %s\n", labelStyle.Render("Commit:"), result.Commit[:8]))
+	}
+	content.WriteString(fmt.Sprintf("%s %d", labelStyle.Render("Files: "), len(result.Files)))
+
+	return m.layout.Render(content.String())
+}
@@ -0,0 +1,110 @@
+package settingsmenu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/repository"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createPublishModel returns a model on the publish screen for a real clone
+// with the given uncommitted files written into it.
+func createPublishModel(t *testing.T, files map[string]string) *SettingsModel {
+	t.Helper()
+	clonePath := createOriginAndClone(t, "")
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(clonePath, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	m := createTestModelWithConfig(t, createGitHubConfig(clonePath, "https://github.com/test/repo.git", "main"))
+	m.selectedRepositoryID = "test-github-1"
+	m.state = SettingsStateRepositoryActions
+
+	m, _ = m.transitionToPublishChanges()
+	if m.state != SettingsStatePublishChanges {
+		t.Fatalf("expected %v, got %v", SettingsStatePublishChanges, m.state)
+	}
+	updated, _ := m.Update(m.loadChangedFiles()())
+	return updated.(*SettingsModel)
+}
+
+func TestPublishChanges_PublishAndDismiss(t *testing.T) {
+	m := createPublishModel(t, map[string]string{"rules.md": "# rules\n"})
+	if view := m.View(); !strings.Contains(view, "rules.md") {
+		t.Error("expected the view to list the changed file")
+	}
+
+	for _, r := range "Add rules" {
+		m, _ = m.handlePublishChangesKeys(keyRune(string(r)))
+	}
+	m, cmd := m.handlePublishChangesKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != SettingsStatePublishInProgress {
+		t.Fatalf("expected %v, got %v", SettingsStatePublishInProgress, m.state)
+	}
+	if m, _ = m.handlePublishInProgressKeys(keyRune("q")); m.state != SettingsStatePublishInProgress {
+		t.Fatal("input should be blocked while publishing")
+	}
+
+	updated, _ := m.Update(cmd())
+	m = updated.(*SettingsModel)
+	if m.state != SettingsStatePublishComplete {
+		t.Fatalf("expected %v, got %v", SettingsStatePublishComplete, m.state)
+	}
+	if err := m.layout.GetError(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m.publishResult.Files) != 1 || m.publishResult.Files[0] != "rules.md" {
+		t.Errorf("expected rules.md to be published, got %+v", m.publishResult)
+	}
+	if view := m.View(); !strings.Contains(view, "Changes published") {
+		t.Error("expected the view to report success")
+	}
+
+	m, _ = m.handlePublishCompleteKeys(keyRune("x"))
+	if m.state != SettingsStateRepositoryActions {
+		t.Errorf("expected %v, got %v", SettingsStateRepositoryActions, m.state)
+	}
+}
+
+func TestPublishChanges_NothingToPublish(t *testing.T) {
+	m := createPublishModel(t, nil)
+	if view := m.View(); !strings.Contains(view, "No local changes to publish") {
+		t.Error("expected the view to report a clean clone")
+	}
+
+	m, cmd := m.handlePublishChangesKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.state != SettingsStatePublishChanges {
+		t.Fatalf("enter on a clean clone should not publish, got state %v", m.state)
+	}
+	if err := m.layout.GetError(); !errors.Is(err, repository.ErrNothingToPush) {
+		t.Errorf("expected ErrNothingToPush on the layout, got %v", err)
+	}
+
+	m, _ = m.handlePublishChangesKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != SettingsStateRepositoryActions {
+		t.Errorf("expected %v, got %v", SettingsStateRepositoryActions, m.state)
+	}
+}
+
+func TestHandlePublishComplete_Error(t *testing.T) {
+	m := createTestModelWithConfig(t, createGitHubConfig(t.TempDir(), "https://github.com/test/repo.git", "main"))
+	m.state = SettingsStatePublishInProgress
+
+	m, _ = m.handlePublishComplete(publishCompleteMsg{err: errors.New("push rejected")})
+	if m.state != SettingsStatePublishComplete {
+		t.Fatalf("expected %v, got %v", SettingsStatePublishComplete, m.state)
+	}
+	if err := m.layout.GetError(); err == nil || err.Error() != "push rejected" {
+		t.Errorf("expected the error on the layout, got %v", err)
+	}
+	if view := m.View(); !strings.Contains(view, "still in the working tree") {
+		t.Error("expected the view to reassure that nothing was lost")
+	}
+}
@@ -3,6 +3,7 @@ package settingsmenu
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"rulem/internal/repository"
 	"rulem/internal/tui/components"
@@ -14,6 +15,9 @@ import (
 
 // Manual Refresh Flow
 // Flow: RepositoryActions → ManualRefresh → RefreshInProgress → [RefreshError | Complete]
+// A refresh blocked by local changes can be resolved from RefreshError, see flow_resolve_changes.go
+// A refresh that failed because the remote renamed its default branch can switch
+// to the new branch from RefreshError: RefreshError → EditBranchInProgress → Complete
 //
 // This file contains all handlers, transitions, and business logic for manually
 // refreshing a GitHub repository from its remote source.
@@ -48,8 +52,25 @@ func (m *SettingsModel) handleRefreshInProgressKeys(msg tea.KeyMsg) (*SettingsMo
 }
 
 // handleRefreshErrorKeys processes user input on the refresh error screen.
-// Any key dismisses the error and returns to repository actions menu.
+// When the refresh was blocked by local changes, r opens the resolve changes
+// screen. When the configured branch was renamed on the remote, m switches to
+// the remote's default branch, saves it and re-syncs. Any other key dismisses
+// the error and returns to repository actions menu.
 func (m *SettingsModel) handleRefreshErrorKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	if m.isDirty && msg.String() == "r" {
+		m.logger.LogUserAction("settings_resolve_changes", "user opened local changes helper")
+		return m.transitionToResolveChanges()
+	}
+	if branch := m.renamedDefaultBranch(); branch != "" && msg.String() == "m" {
+		m.logger.LogUserAction("settings_migrate_branch", branch)
+		m.lastRefreshError = nil
+		m.newGitHubBranch = branch
+		m.hasChanges = true
+		m.changeType = ChangeOptionGitHubBranch
+		m.branchSwitchStep = repository.BranchSwitchFetching
+		return m.transitionTo(SettingsStateEditBranchInProgress), m.startBranchSwitch()
+	}
+
 	// Any key returns to repository actions
 	m.logger.LogUserAction("settings_refresh_error_dismiss", "user dismissed error")
 	m.lastRefreshError = nil
@@ -92,6 +113,8 @@ func (m *SettingsModel) triggerRefresh() tea.Cmd {
 			selectedRepo.Branch,
 			selectedRepo.Path,
 		)
+		source.Tag, source.Commit = selectedRepo.GetTag(), selectedRepo.GetCommit()
+		source.RepositoryID = selectedRepo.ID
 
 		err = source.FetchUpdates(context.Background(), m.logger)
 		if err != nil {
@@ -104,6 +127,16 @@ func (m *SettingsModel) triggerRefresh() tea.Cmd {
 	}
 }
 
+// renamedDefaultBranch returns the remote's new default branch when the last
+// refresh failed because the configured branch no longer exists, or "" otherwise
+func (m *SettingsModel) renamedDefaultBranch() string {
+	var goneErr *repository.BranchGoneError
+	if !errors.As(m.lastRefreshError, &goneErr) || goneErr.DefaultBranch == goneErr.Branch {
+		return ""
+	}
+	return goneErr.DefaultBranch
+}
+
 // transitionToManualRefresh transitions to the ManualRefresh confirmation state.
 // Sets up the state for confirming a manual refresh operation.
 func (m *SettingsModel) transitionToManualRefresh() (*SettingsModel, tea.Cmd) {
@@ -147,10 +180,18 @@ func (m *SettingsModel) viewRefreshInProgress() string {
 // viewRefreshError renders the error screen when a refresh operation fails.
 // Displays the error message and instructions to return.
 func (m *SettingsModel) viewRefreshError() string {
+	helpText := "Press any key to return"
+	renamedBranch := m.renamedDefaultBranch()
+	switch {
+	case m.isDirty:
+		helpText = "r to resolve local changes • any other key to return"
+	case renamedBranch != "":
+		helpText = fmt.Sprintf("m to switch to %s and re-sync • any other key to return", renamedBranch)
+	}
 	m.layout = m.layout.SetConfig(components.LayoutConfig{
 		Title:    "❌ Refresh Failed",
 		Subtitle: "Cannot refresh repository",
-		HelpText: "Press any key to return",
+		HelpText: helpText,
 	})
 
 	var content strings.Builder
@@ -167,6 +208,16 @@ func (m *SettingsModel) viewRefreshError() string {
 	}
 
 	content.WriteString("\n\n")
+	if m.isDirty {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
+			Render("💡 Press r to review the changed files, discard them, or stash them while refreshing."))
+		return m.layout.Render(content.String())
+	}
+	if renamedBranch != "" {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
+			Render(fmt.Sprintf("💡 The remote renamed its default branch. Press m to switch this repository to %s, save it to the config and re-sync.", renamedBranch)))
+		return m.layout.Render(content.String())
+	}
 	content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
 		Render("💡 Common reasons:\n  - Network connectivity issues\n  - Invalid or expired GitHub PAT\n  - Local repository has uncommitted changes\n  - Merge conflicts with remote changes\n  - Repository not found or access denied"))
 
@@ -154,6 +154,37 @@ func TestHandleRefreshErrorKeys_Dismiss(t *testing.T) {
 	}
 }
 
+// TestHandleRefreshErrorKeys_RenamedBranch tests switching to the remote's new
+// default branch after a refresh found the configured branch gone
+func TestHandleRefreshErrorKeys_RenamedBranch(t *testing.T) {
+	m := createTestModel(t)
+	m.state = SettingsStateRefreshError
+	m.lastRefreshError = fmt.Errorf("refresh: %w", &repository.BranchGoneError{Branch: "master", DefaultBranch: "main"})
+
+	view := m.viewRefreshError()
+	if !strings.Contains(view, "m to switch to main and re-sync") {
+		t.Errorf("expected the view to offer the switch, got %q", view)
+	}
+
+	newModel, cmd := m.handleRefreshErrorKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if newModel.state != SettingsStateEditBranchInProgress {
+		t.Fatalf("expected state %v, got %v", SettingsStateEditBranchInProgress, newModel.state)
+	}
+	if newModel.newGitHubBranch != "main" || newModel.changeType != ChangeOptionGitHubBranch {
+		t.Errorf("expected a switch to main, got %q (%v)", newModel.newGitHubBranch, newModel.changeType)
+	}
+	if cmd == nil {
+		t.Fatal("expected the branch switch to start")
+	}
+
+	// Without a new default branch, m only dismisses the error
+	m.state = SettingsStateRefreshError
+	m.lastRefreshError = &repository.BranchGoneError{Branch: "develop"}
+	if newModel, _ := m.handleRefreshErrorKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")}); newModel.state != SettingsStateRepositoryActions {
+		t.Errorf("expected state %v, got %v", SettingsStateRepositoryActions, newModel.state)
+	}
+}
+
 // TestTriggerRefresh_NonGitHubRepository tests refresh with non-GitHub repo
 func TestTriggerRefresh_NonGitHubRepository(t *testing.T) {
 	m := createTestModel(t)
@@ -0,0 +1,228 @@
+// Package settingsmenu provides the settings modification flow for the rulem TUI application.
+package settingsmenu
+
+import (
+	"fmt"
+	"path/filepath"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers/settingshelpers"
+	"rulem/internal/tui/helpers/textutil"
+	"rulem/internal/tui/styles"
+	"rulem/pkg/fileops"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Relocate Storage Flow
+// Flow: RelocateStorageInput → RelocateStorageConfirm → RelocateStorageInProgress → [RelocateStorageError | Complete]
+//
+// This file contains all handlers, transitions, and business logic for moving
+// every repository to a new base directory at once. Repositories are copied
+// and verified before the config is saved, and the old copies are removed
+// last, see repository.Relocate.
+
+// handleRelocateStorageInputKeys processes user input for the new base directory.
+// Validates the path and plans the moves before proceeding to confirmation.
+func (m *SettingsModel) handleRelocateStorageInputKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		input := strings.TrimSpace(m.textInput.Value())
+		if input == "" {
+			input = m.textInput.Placeholder
+		}
+		m.logger.LogUserAction("settings_relocate_storage_submit", input)
+
+		if err := fileops.ValidateStoragePath(input); err != nil {
+			m.logger.Warn("Storage path validation failed", "error", err)
+			return m, func() tea.Msg { return relocateStorageErrorMsg{err} }
+		}
+		newBase := fileops.ExpandPath(input)
+
+		moves, err := repository.PlanRelocation(m.currentConfig.Repositories, newBase)
+		if err != nil {
+			m.logger.Warn("Storage relocation plan failed", "error", err)
+			return m, func() tea.Msg { return relocateStorageErrorMsg{err} }
+		}
+		if len(moves) == 0 {
+			err := fmt.Errorf("all repositories are already in %s", newBase)
+			return m, func() tea.Msg { return relocateStorageErrorMsg{err} }
+		}
+
+		m.newStorageDir = newBase
+		m.relocationMoves = moves
+		return m.transitionTo(SettingsStateRelocateStorageConfirm), nil
+	case "esc":
+		m.resetRelocation()
+		return m.transitionTo(SettingsStateMainMenu), nil
+	default:
+		return m.updateTextInput(msg)
+	}
+}
+
+// handleRelocateStorageConfirmKeys processes user input on the relocation confirmation screen.
+func (m *SettingsModel) handleRelocateStorageConfirmKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "y":
+		m.logger.LogUserAction("settings_relocate_storage_confirm", m.newStorageDir)
+		m.relocationProgress = repository.RelocationProgress{Total: len(m.relocationMoves)}
+		return m.transitionTo(SettingsStateRelocateStorageInProgress), m.startRelocation()
+	case "esc", "n":
+		m.logger.LogUserAction("settings_relocate_storage_cancel", "user cancelled storage relocation")
+		m.resetRelocation()
+		return m.transitionTo(SettingsStateMainMenu), nil
+	}
+	return m, nil
+}
+
+// handleRelocateStorageInProgressKeys blocks input while repositories are moved;
+// stopping halfway would leave copies behind.
+func (m *SettingsModel) handleRelocateStorageInProgressKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	return m, nil
+}
+
+// handleRelocateStorageErrorKeys dismisses the error and returns to the main menu.
+func (m *SettingsModel) handleRelocateStorageErrorKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	m.logger.LogUserAction("settings_relocate_storage_error_dismiss", "user dismissed error")
+	m.layout = m.layout.ClearError()
+	m.resetRelocation()
+	return m.transitionTo(SettingsStateMainMenu), nil
+}
+
+// transitionToRelocateStorage prompts for the new base directory, suggesting
+// the parent directory of the first repository.
+func (m *SettingsModel) transitionToRelocateStorage() (*SettingsModel, tea.Cmd) {
+	m.resetRelocation()
+	m.relocationLeftovers = nil
+	placeholder := repository.GetDefaultStorageDir()
+	if m.currentConfig != nil && len(m.currentConfig.Repositories) > 0 {
+		placeholder = filepath.Dir(m.currentConfig.Repositories[0].Path)
+	}
+	return m.transitionTo(SettingsStateRelocateStorageInput), settingshelpers.ResetTextInputForState(&m.textInput, "", placeholder, textinput.EchoNormal)
+}
+
+// resetRelocation clears the planned relocation.
+func (m *SettingsModel) resetRelocation() {
+	m.resetTemporaryChanges()
+	m.relocationMoves = nil
+	m.relocationProgress = repository.RelocationProgress{}
+}
+
+// startRelocation runs the relocation in the background, reporting each step
+// as a relocationProgressMsg until the final message.
+func (m *SettingsModel) startRelocation() tea.Cmd {
+	return func() tea.Msg {
+		updates := make(chan tea.Msg, 2*len(m.relocationMoves)+int(repository.RelocationDone)+2)
+		go func() {
+			defer close(updates)
+			updates <- m.relocateStorage(func(progress repository.RelocationProgress) {
+				updates <- relocationProgressMsg{progress: progress, updates: updates}
+			})
+		}()
+		return <-updates
+	}
+}
+
+// relocateStorage moves every planned repository and saves the new paths.
+func (m *SettingsModel) relocateStorage(progress func(repository.RelocationProgress)) tea.Msg {
+	m.logger.Info("Relocating storage", "to", m.newStorageDir, "repositories", len(m.relocationMoves))
+	leftovers, err := repository.Relocate(m.relocationMoves, func() error {
+		return m.currentConfig.ApplyRelocation(m.relocationMoves)
+	}, progress, m.logger)
+	if err != nil {
+		m.logger.Error("Storage relocation failed", "error", err)
+		return relocateStorageErrorMsg{err}
+	}
+	return relocationCompleteMsg{leftovers: leftovers}
+}
+
+// Views
+
+// viewRelocateStorageInput renders the new base directory input.
+func (m *SettingsModel) viewRelocateStorageInput() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📦 Move Storage",
+		Subtitle: "Move all repositories to a new base directory",
+		HelpText: "Enter to review • Esc to cancel",
+	})
+
+	var content strings.Builder
+	content.WriteString("Each repository is moved into its own directory under the new base.\n\n")
+	content.WriteString("New base directory:\n")
+	content.WriteString(styles.InputStyle.Render(m.textInput.View()))
+
+	return m.layout.Render(content.String())
+}
+
+// viewRelocateStorageConfirm renders where each repository will move.
+func (m *SettingsModel) viewRelocateStorageConfirm() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📦 Confirm Storage Move",
+		Subtitle: "Review where each repository will move",
+		HelpText: "Enter/y to move • Esc/n to cancel",
+	})
+
+	highlightStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#5fd7ff"))
+	faint := lipgloss.NewStyle().Faint(true)
+	pathWidth := m.layout.ContentWidth() - lipgloss.Width("  from ")
+
+	var content strings.Builder
+	for _, move := range m.relocationMoves {
+		content.WriteString(highlightStyle.Render(move.Name))
+		if move.Missing {
+			content.WriteString(faint.Render(" (not cloned yet - path only)"))
+		}
+		content.WriteString("\n")
+		content.WriteString(faint.Render("  from " + textutil.TruncatePath(move.From, pathWidth)))
+		content.WriteString("\n")
+		content.WriteString("  to   " + textutil.TruncatePath(move.To, pathWidth))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString("Repositories are copied and verified before the configuration is\n")
+	content.WriteString("saved; the old directories are removed only after that succeeds.\n\n")
+	content.WriteString("Do you want to proceed? (y/N)")
+
+	return m.layout.Render(content.String())
+}
+
+// viewRelocateStorageInProgress renders the relocation progress.
+func (m *SettingsModel) viewRelocateStorageInProgress() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📦 Moving Storage...",
+		Subtitle: "Copying and verifying repositories",
+		HelpText: "Please wait",
+	})
+
+	progress := m.relocationProgress
+	var content strings.Builder
+	prefix := fmt.Sprintf("Moving %d repositories to ", progress.Total)
+	newStorageDir := textutil.TruncatePath(m.newStorageDir, m.layout.ContentWidth()-lipgloss.Width(prefix))
+	content.WriteString(prefix + lipgloss.NewStyle().Bold(true).Render(newStorageDir) + "\n\n")
+
+	step := progress.Step.String()
+	if progress.Repository != "" {
+		step = fmt.Sprintf("%s %s (%d/%d)", step, progress.Repository, progress.Index, progress.Total)
+	}
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("▸ " + step + "..."))
+
+	return m.layout.Render(content.String())
+}
+
+// viewRelocateStorageError renders the relocation error screen.
+func (m *SettingsModel) viewRelocateStorageError() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "❌ Storage Move Failed",
+		Subtitle: "Repositories were not moved",
+		HelpText: "Press any key to return",
+	})
+
+	content := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
+		Render("💡 Any copies were removed and the configuration is unchanged.")
+
+	return m.layout.Render(content)
+}
@@ -0,0 +1,145 @@
+package settingsmenu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"rulem/internal/config"
+	"rulem/internal/repository"
+)
+
+func TestRelocateStorage_MovesRepositories(t *testing.T) {
+	t.Setenv("RULEM_CONFIG_PATH", filepath.Join(t.TempDir(), "config.yaml"))
+
+	clonePath := filepath.Join(t.TempDir(), "team-rules")
+	if err := os.Rename(createOriginAndClone(t, ""), clonePath); err != nil {
+		t.Fatalf("rename clone: %v", err)
+	}
+	commitToRepo(t, clonePath, "rules.md", "# rules\n")
+
+	cfg := createGitHubConfig(clonePath, "https://github.com/test/repo.git", "main")
+	m := createTestModelWithConfig(t, cfg)
+	m.state = SettingsStateMainMenu
+
+	m, _ = m.transitionToRelocateStorage()
+	if m.state != SettingsStateRelocateStorageInput {
+		t.Fatalf("expected %v, got %v", SettingsStateRelocateStorageInput, m.state)
+	}
+
+	newBase := filepath.Join(t.TempDir(), "storage")
+	m.textInput.SetValue(newBase)
+	m, cmd := m.handleRelocateStorageInputKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Fatalf("unexpected error: %v", cmd())
+	}
+	if m.state != SettingsStateRelocateStorageConfirm || len(m.relocationMoves) != 1 {
+		t.Fatalf("expected confirmation of 1 move, got %v with %d moves", m.state, len(m.relocationMoves))
+	}
+
+	m, cmd = m.handleRelocateStorageConfirmKeys(keyRune("y"))
+	if m.state != SettingsStateRelocateStorageInProgress {
+		t.Fatalf("expected %v, got %v", SettingsStateRelocateStorageInProgress, m.state)
+	}
+	if m, _ = m.handleRelocateStorageInProgressKeys(keyRune("q")); m.state != SettingsStateRelocateStorageInProgress {
+		t.Fatal("input should be blocked while repositories are moved")
+	}
+
+	// Drain progress messages until the relocation finishes
+	for msg := cmd(); ; {
+		updated, next := m.Update(msg)
+		m = updated.(*SettingsModel)
+		if _, ok := msg.(relocationProgressMsg); !ok {
+			break
+		}
+		msg = next()
+	}
+	if m.state != SettingsStateComplete {
+		t.Fatalf("expected %v, got %v (error %v)", SettingsStateComplete, m.state, m.layout.GetError())
+	}
+
+	want := filepath.Join(newBase, "team-rules")
+	if cfg.Repositories[0].Path != want {
+		t.Errorf("config path = %q, want %q", cfg.Repositories[0].Path, want)
+	}
+	if _, err := os.Stat(clonePath); !os.IsNotExist(err) {
+		t.Errorf("old clone should be removed, stat err = %v", err)
+	}
+	saved, err := config.LoadFrom(os.Getenv("RULEM_CONFIG_PATH"))
+	if err != nil || saved.Repositories[0].Path != want {
+		t.Errorf("saved config = %+v, err %v", saved, err)
+	}
+}
+
+func TestHandleRelocateStorageInputKeys_Errors(t *testing.T) {
+	repoPath := t.TempDir()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "inside repository", input: filepath.Join(repoPath, "storage"), wantErr: "inside repository"},
+		{name: "already in place", input: filepath.Dir(repoPath), wantErr: "already in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := createTestModelWithConfig(t, createLocalConfig(repoPath))
+			m, _ = m.transitionToRelocateStorage()
+			m.textInput.SetValue(tt.input)
+
+			m, cmd := m.handleRelocateStorageInputKeys(tea.KeyMsg{Type: tea.KeyEnter})
+			if cmd == nil {
+				t.Fatal("expected an error command")
+			}
+			updated, _ := m.Update(cmd())
+			m = updated.(*SettingsModel)
+			if m.state != SettingsStateRelocateStorageError {
+				t.Fatalf("expected %v, got %v", SettingsStateRelocateStorageError, m.state)
+			}
+			if err := m.layout.GetError(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+
+			m, _ = m.handleRelocateStorageErrorKeys(keyRune("x"))
+			if m.state != SettingsStateMainMenu || m.relocationMoves != nil {
+				t.Errorf("expected a reset main menu, got %v with moves %v", m.state, m.relocationMoves)
+			}
+		})
+	}
+}
+
+func TestHandleRelocateStorageConfirmKeys_Cancel(t *testing.T) {
+	m := createTestModelWithConfig(t, createLocalConfig(t.TempDir()))
+	m.newStorageDir = "/mnt/data/rulem"
+	m.relocationMoves = []repository.RelocationMove{{ID: "test-local-1", From: "/a", To: "/mnt/data/rulem/a"}}
+	m.state = SettingsStateRelocateStorageConfirm
+
+	m, cmd := m.handleRelocateStorageConfirmKeys(keyRune("n"))
+	if cmd != nil || m.state != SettingsStateMainMenu {
+		t.Fatalf("expected to return to the main menu, got %v", m.state)
+	}
+	if m.relocationMoves != nil || m.newStorageDir != "" {
+		t.Errorf("expected the plan to be cleared, got %v in %q", m.relocationMoves, m.newStorageDir)
+	}
+}
+
+func TestViewComplete_RelocationLeftovers(t *testing.T) {
+	m := createTestModelWithConfig(t, createLocalConfig(t.TempDir()))
+	updated, _ := m.Update(relocationCompleteMsg{leftovers: []string{"/old/rules"}})
+	m = updated.(*SettingsModel)
+
+	if view := m.View(); !strings.Contains(view, "/old/rules") {
+		t.Error("expected the view to list directories left behind")
+	}
+
+	updated, _ = m.Update(relocateStorageErrorMsg{errors.New("boom")})
+	if updated.(*SettingsModel).state != SettingsStateRelocateStorageError {
+		t.Error("expected the error state")
+	}
+}
@@ -43,6 +43,12 @@ func (m *SettingsModel) handleRepositoryActionsKeys(msg tea.KeyMsg) (*SettingsMo
 			return m.transitionToUpdateRepoName()
 		case ChangeOptionManualRefresh:
 			return m.transitionTo(SettingsStateManualRefresh), nil
+		case ChangeOptionBrowseCommits:
+			return m.transitionToCommitBrowser()
+		case ChangeOptionMaintenance:
+			return m.transitionToMaintenance()
+		case ChangeOptionPublishChanges:
+			return m.transitionToPublishChanges()
 		case ChangeOptionDelete:
 			m.logger.LogUserAction("settings_delete_repository", "user selected delete from menu")
 			return m.transitionTo(SettingsStateConfirmDelete), nil
@@ -56,7 +62,7 @@ func (m *SettingsModel) handleRepositoryActionsKeys(msg tea.KeyMsg) (*SettingsMo
 // viewRepositoryActions renders the repository actions menu for a selected repository.
 // Shows available actions based on repository type (Local vs GitHub).
 // Local repositories: Delete, Rename
-// GitHub repositories: Delete, Rename, Edit Branch, Edit Clone Path, Manual Refresh
+// GitHub repositories: Delete, Rename, Edit Branch, Edit Clone Path, Manual Refresh, Publish, Browse Commits, Maintenance
 func (m *SettingsModel) viewRepositoryActions() string {
 	// Get selected repository info
 	selectedRepo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID)
@@ -110,13 +116,17 @@ func (m *SettingsModel) getMenuOptions() []ChangeOptionInfo {
 	options := []ChangeOptionInfo{}
 
 	if m.isGitHubRepo() {
-		// GitHub repository options
-		options = append(options,
-			ChangeOptionInfo{
+		// A repository frozen at a tag or commit tracks no branch
+		if repo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID); err == nil && !repo.IsFrozen() {
+			options = append(options, ChangeOptionInfo{
 				Option:      ChangeOptionGitHubBranch,
 				Title:       "🌿 Update GitHub Branch",
 				Description: "Change the branch to sync with",
-			},
+			})
+		}
+
+		// GitHub repository options
+		options = append(options,
 			ChangeOptionInfo{
 				Option:      ChangeOptionGitHubPath,
 				Title:       "📂 Update Clone Path",
@@ -127,6 +137,21 @@ func (m *SettingsModel) getMenuOptions() []ChangeOptionInfo {
 				Title:       "🔄 Manual Refresh",
 				Description: "Pull latest changes from GitHub now",
 			},
+			ChangeOptionInfo{
+				Option:      ChangeOptionPublishChanges,
+				Title:       "📤 Publish Local Changes",
+				Description: "Commit your rule edits and push them to GitHub",
+			},
+			ChangeOptionInfo{
+				Option:      ChangeOptionBrowseCommits,
+				Title:       "📜 Browse Commits",
+				Description: "View recent commits and inspect an older one",
+			},
+			ChangeOptionInfo{
+				Option:      ChangeOptionMaintenance,
+				Title:       "🧹 Run Maintenance",
+				Description: "Repack the clone and prune unused objects",
+			},
 		)
 	}
 
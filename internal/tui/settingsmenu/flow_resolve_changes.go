@@ -0,0 +1,291 @@
+// Package settingsmenu provides the settings modification flow for the rulem TUI application.
+package settingsmenu
+
+import (
+	"context"
+	"fmt"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers/textutil"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Resolve Local Changes Flow
+// Flow: RefreshError (dirty) → ResolveChanges → [DiscardConfirm → ResolveChanges | RefreshInProgress → Complete]
+//
+// This file contains the handlers and views that help the user out of a refresh
+// blocked by uncommitted changes: review the changed files, discard selected
+// files, or stash the changes, refresh and restore them afterwards. All git
+// operations go through the repository package (go-git), never the git CLI.
+
+// handleResolveChangesKeys processes user input on the changed files list.
+func (m *SettingsModel) handleResolveChangesKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.changedFilesCursor > 0 {
+			m.changedFilesCursor--
+		}
+	case "down", "j":
+		if m.changedFilesCursor < len(m.changedFiles)-1 {
+			m.changedFilesCursor++
+		}
+	case " ":
+		if len(m.changedFiles) > 0 {
+			path := m.changedFiles[m.changedFilesCursor].Path
+			m.changedFilesSelected[path] = !m.changedFilesSelected[path]
+		}
+	case "a":
+		// Select all, or clear the selection when everything is already selected
+		selectAll := len(m.selectedChangedFiles()) < len(m.changedFiles)
+		for _, file := range m.changedFiles {
+			m.changedFilesSelected[file.Path] = selectAll
+		}
+	case "d":
+		if len(m.selectedChangedFiles()) == 0 {
+			m.layout = m.layout.SetError(fmt.Errorf("select files to discard with Space first"))
+			return m, nil
+		}
+		m.logger.LogUserAction("settings_discard_files_requested", fmt.Sprintf("%d files", len(m.selectedChangedFiles())))
+		return m.transitionTo(SettingsStateDiscardConfirm), nil
+	case "s":
+		m.logger.LogUserAction("settings_stash_sync_confirmed", "stashing changes and refreshing")
+		m.stashConflicts = nil
+		m.refreshInProgress = true
+		return m.transitionTo(SettingsStateRefreshInProgress), m.triggerStashSync()
+	case "esc":
+		m.resetChangedFiles()
+		return m.transitionTo(SettingsStateRepositoryActions), nil
+	}
+	return m, nil
+}
+
+// handleDiscardConfirmKeys processes user input on the discard confirmation screen.
+func (m *SettingsModel) handleDiscardConfirmKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.logger.LogUserAction("settings_discard_files_confirmed", strings.Join(m.selectedChangedFiles(), ", "))
+		return m, m.discardSelectedFiles()
+	case "n", "N", "esc":
+		m.logger.LogUserAction("settings_discard_files_cancelled", "returning to changed files")
+		return m.transitionTo(SettingsStateResolveChanges), nil
+	}
+	return m, nil
+}
+
+// transitionToResolveChanges opens the changed files list and starts loading it.
+func (m *SettingsModel) transitionToResolveChanges() (*SettingsModel, tea.Cmd) {
+	m.resetChangedFiles()
+	m.lastRefreshError = nil
+	return m.transitionTo(SettingsStateResolveChanges), m.loadChangedFiles()
+}
+
+// handleChangedFilesMsg stores the loaded changed files, keeping the selection
+// for files that are still changed.
+func (m *SettingsModel) handleChangedFilesMsg(msg changedFilesMsg) (*SettingsModel, tea.Cmd) {
+	if msg.err != nil {
+		m.logger.Error("Failed to list changed files", "error", msg.err)
+		m.layout = m.layout.SetError(msg.err)
+		return m, nil
+	}
+
+	selected := make(map[string]bool, len(msg.files))
+	for _, file := range msg.files {
+		if m.changedFilesSelected[file.Path] {
+			selected[file.Path] = true
+		}
+	}
+	m.changedFiles = msg.files
+	m.changedFilesSelected = selected
+	m.changedFilesLoaded = true
+	m.isDirty = len(msg.files) > 0
+	if m.changedFilesCursor >= len(m.changedFiles) {
+		m.changedFilesCursor = max(len(m.changedFiles)-1, 0)
+	}
+	return m, nil
+}
+
+// handleStashSyncComplete handles the outcome of a stash, refresh and restore.
+// Conflicting files are shown on the changed files list so the user can decide
+// which version to keep.
+func (m *SettingsModel) handleStashSyncComplete(msg stashSyncCompleteMsg) (*SettingsModel, tea.Cmd) {
+	m.refreshInProgress = false
+	if msg.err != nil {
+		m.logger.Error("Stash and refresh failed", "error", msg.err)
+		m.lastRefreshError = msg.err
+		return m.transitionTo(SettingsStateRefreshError), nil
+	}
+
+	if len(msg.conflicts) > 0 {
+		m.logger.Warn("Restored changes conflict with upstream", "files", msg.conflicts)
+		m.stashConflicts = msg.conflicts
+		m.changedFilesLoaded = false
+		return m.transitionTo(SettingsStateResolveChanges), m.loadChangedFiles()
+	}
+
+	m.logger.Info("Repository refreshed with local changes restored", "repositoryID", m.selectedRepositoryID)
+	m.resetChangedFiles()
+	m.lastRefreshError = nil
+	return m.transitionTo(SettingsStateMainMenu), nil
+}
+
+// resetChangedFiles clears the resolve changes state.
+func (m *SettingsModel) resetChangedFiles() {
+	m.changedFiles = nil
+	m.changedFilesSelected = make(map[string]bool)
+	m.changedFilesCursor = 0
+	m.changedFilesLoaded = false
+	m.stashConflicts = nil
+}
+
+// selectedChangedFiles returns the selected paths in list order.
+func (m *SettingsModel) selectedChangedFiles() []string {
+	var paths []string
+	for _, file := range m.changedFiles {
+		if m.changedFilesSelected[file.Path] {
+			paths = append(paths, file.Path)
+		}
+	}
+	return paths
+}
+
+// selectedRepositoryPath returns the local path of the selected GitHub repository.
+func (m *SettingsModel) selectedRepositoryPath() (string, error) {
+	repo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID)
+	if err != nil {
+		return "", err
+	}
+	if !repo.IsRemote() {
+		return "", fmt.Errorf("not a GitHub repository")
+	}
+	if repo.Path == "" {
+		return "", fmt.Errorf("repository path not configured")
+	}
+	return repo.Path, nil
+}
+
+// loadChangedFiles lists the uncommitted changes of the selected repository.
+func (m *SettingsModel) loadChangedFiles() tea.Cmd {
+	return func() tea.Msg {
+		path, err := m.selectedRepositoryPath()
+		if err != nil {
+			return changedFilesMsg{err: err}
+		}
+		files, err := repository.ListChangedFiles(path)
+		return changedFilesMsg{files: files, err: err}
+	}
+}
+
+// discardSelectedFiles reverts the selected files and reloads the list.
+func (m *SettingsModel) discardSelectedFiles() tea.Cmd {
+	paths := m.selectedChangedFiles()
+	return func() tea.Msg {
+		path, err := m.selectedRepositoryPath()
+		if err != nil {
+			return discardCompleteMsg{err: err}
+		}
+		if err := repository.DiscardFiles(path, paths); err != nil {
+			m.logger.Error("Failed to discard files", "error", err, "path", path)
+			return discardCompleteMsg{err: err}
+		}
+		m.logger.Info("Discarded local changes", "path", path, "files", paths)
+		return discardCompleteMsg{}
+	}
+}
+
+// triggerStashSync stashes local changes, refreshes the repository and restores
+// the changes, mirroring triggerRefresh.
+func (m *SettingsModel) triggerStashSync() tea.Cmd {
+	return func() tea.Msg {
+		selectedRepo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID)
+		if err != nil {
+			return stashSyncCompleteMsg{err: err}
+		}
+		if selectedRepo.Type != repository.RepositoryTypeGitHub || selectedRepo.RemoteURL == nil {
+			return stashSyncCompleteMsg{err: fmt.Errorf("cannot refresh: not a GitHub repository")}
+		}
+
+		source := repository.NewGitSource(*selectedRepo.RemoteURL, selectedRepo.Branch, selectedRepo.Path)
+		source.Tag, source.Commit = selectedRepo.GetTag(), selectedRepo.GetCommit()
+		source.RepositoryID = selectedRepo.ID
+		conflicts, err := source.FetchUpdatesWithStash(context.Background(), m.logger)
+		return stashSyncCompleteMsg{conflicts: conflicts, err: err}
+	}
+}
+
+// Views
+
+// viewResolveChanges renders the changed files list with the available actions.
+func (m *SettingsModel) viewResolveChanges() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "✋ Local Changes",
+		Subtitle: "Uncommitted changes are blocking the refresh",
+		HelpText: "↑/↓ to navigate • Space to select • a to select all • d to discard selected • s to stash, refresh & restore • Esc to go back",
+	})
+
+	var content strings.Builder
+
+	if len(m.stashConflicts) > 0 {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#ffaf00")).
+			Render(fmt.Sprintf("⚠ Refreshed, but %d file(s) also changed upstream. The upstream version was kept and yours saved next to it with a .rulem-stash suffix:", len(m.stashConflicts))))
+		content.WriteString("\n")
+		for _, path := range m.stashConflicts {
+			content.WriteString("  • " + textutil.TruncatePath(path, m.layout.ContentWidth()-4) + "\n")
+		}
+		content.WriteString("\n")
+	}
+
+	if !m.changedFilesLoaded {
+		content.WriteString(lipgloss.NewStyle().Faint(true).Render("Loading changed files..."))
+		return m.layout.Render(content.String())
+	}
+
+	if len(m.changedFiles) == 0 {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00")).
+			Render("✓ No local changes left - press s to refresh"))
+		return m.layout.Render(content.String())
+	}
+
+	for i, file := range m.changedFiles {
+		prefix := "  "
+		if i == m.changedFilesCursor {
+			prefix = "▸ "
+		}
+		check := "[ ]"
+		if m.changedFilesSelected[file.Path] {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s%s %s  ", prefix, check, file.Kind)
+		content.WriteString(lipgloss.NewStyle().Bold(i == m.changedFilesCursor).
+			Render(line + textutil.TruncatePath(file.Path, m.layout.ContentWidth()-lipgloss.Width(line))))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(lipgloss.NewStyle().Faint(true).
+		Render("M modified • A added • D deleted • ? untracked"))
+
+	return m.layout.Render(content.String())
+}
+
+// viewDiscardConfirm renders the confirmation screen before discarding files.
+func (m *SettingsModel) viewDiscardConfirm() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "🗑️  Discard Changes",
+		Subtitle: "This cannot be undone",
+		HelpText: "y to discard • n to cancel • Esc to go back",
+	})
+
+	var content strings.Builder
+	content.WriteString("The following files will be reverted to their last committed version.\n")
+	content.WriteString("Untracked and newly added files will be deleted.\n\n")
+	for _, path := range m.selectedChangedFiles() {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5f87")).Render("  • " + textutil.TruncatePath(path, m.layout.ContentWidth()-4)))
+		content.WriteString("\n")
+	}
+	content.WriteString("\nDiscard these changes? (y/N)")
+
+	return m.layout.Render(content.String())
+}
@@ -0,0 +1,254 @@
+package settingsmenu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"rulem/internal/repository"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func keyRune(r string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(r)}
+}
+
+// createResolveModel returns a model on the resolve changes screen for a real
+// clone with the given uncommitted files written into it.
+func createResolveModel(t *testing.T, files map[string]string) (*SettingsModel, string) {
+	t.Helper()
+	clonePath := createOriginAndClone(t, "")
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(clonePath, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// The configured remote must be the clone's origin, or syncing reports a
+	// directory conflict instead of fetching
+	m := createTestModelWithConfig(t, createGitHubConfig(clonePath, originURL(t, clonePath), "main"))
+	m.selectedRepositoryID = "test-github-1"
+	m.isDirty = true
+	m.state = SettingsStateRefreshError
+
+	m, cmd := m.handleRefreshErrorKeys(keyRune("r"))
+	if m.state != SettingsStateResolveChanges {
+		t.Fatalf("expected %v, got %v", SettingsStateResolveChanges, m.state)
+	}
+	if cmd == nil {
+		t.Fatal("expected command loading changed files")
+	}
+	updated, _ := m.Update(cmd())
+	return updated.(*SettingsModel), clonePath
+}
+
+func TestHandleRefreshErrorKeys_ResolveOnlyWhenDirty(t *testing.T) {
+	tests := []struct {
+		name      string
+		isDirty   bool
+		key       string
+		wantState SettingsState
+	}{
+		{name: "dirty r opens helper", isDirty: true, key: "r", wantState: SettingsStateResolveChanges},
+		{name: "dirty other key dismisses", isDirty: true, key: "x", wantState: SettingsStateRepositoryActions},
+		{name: "clean r dismisses", isDirty: false, key: "r", wantState: SettingsStateRepositoryActions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := createTestModel(t)
+			m.state = SettingsStateRefreshError
+			m.isDirty = tt.isDirty
+			m.lastRefreshError = errors.New("repository has uncommitted changes")
+
+			m, _ = m.handleRefreshErrorKeys(keyRune(tt.key))
+			if m.state != tt.wantState {
+				t.Errorf("expected %v, got %v", tt.wantState, m.state)
+			}
+			if m.lastRefreshError != nil {
+				t.Error("expected lastRefreshError to be cleared")
+			}
+		})
+	}
+}
+
+func TestViewRefreshError_DirtyOffersResolve(t *testing.T) {
+	m := createTestModel(t)
+	m.state = SettingsStateRefreshError
+	m.isDirty = true
+	m.lastRefreshError = errors.New("repository has uncommitted changes")
+
+	view := m.viewRefreshError()
+	if !strings.Contains(view, "r to resolve local changes") {
+		t.Errorf("expected resolve hint in view, got:\n%s", view)
+	}
+}
+
+func TestResolveChanges_ListsChangedFiles(t *testing.T) {
+	m, _ := createResolveModel(t, map[string]string{"README.md": "edited\n", "notes.md": "new\n"})
+
+	want := []repository.FileChange{
+		{Path: "README.md", Kind: repository.FileChangeModified},
+		{Path: "notes.md", Kind: repository.FileChangeUntracked},
+	}
+	if !reflect.DeepEqual(m.changedFiles, want) {
+		t.Errorf("changedFiles = %v, want %v", m.changedFiles, want)
+	}
+
+	view := m.View()
+	for _, s := range []string{"README.md", "notes.md", "Local Changes"} {
+		if !strings.Contains(view, s) {
+			t.Errorf("expected view to contain %q", s)
+		}
+	}
+}
+
+func TestResolveChanges_Selection(t *testing.T) {
+	m := createTestModel(t)
+	m.state = SettingsStateResolveChanges
+	m, _ = m.handleChangedFilesMsg(changedFilesMsg{files: []repository.FileChange{
+		{Path: "a.md", Kind: repository.FileChangeModified},
+		{Path: "b.md", Kind: repository.FileChangeUntracked},
+	}})
+
+	steps := []struct {
+		key  string
+		want []string
+	}{
+		{key: " ", want: []string{"a.md"}},
+		{key: "j", want: []string{"a.md"}},
+		{key: " ", want: []string{"a.md", "b.md"}},
+		{key: " ", want: []string{"a.md"}},
+		{key: "a", want: []string{"a.md", "b.md"}},
+		{key: "a", want: nil},
+	}
+	for i, step := range steps {
+		m, _ = m.handleResolveChangesKeys(keyRune(step.key))
+		if got := m.selectedChangedFiles(); !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("step %d (%q): selected = %v, want %v", i, step.key, got, step.want)
+		}
+	}
+
+	// Discard needs a selection
+	m, _ = m.handleResolveChangesKeys(keyRune("d"))
+	if m.state != SettingsStateResolveChanges || m.layout.GetError() == nil {
+		t.Errorf("expected error without selection, state %v", m.state)
+	}
+
+	m, _ = m.handleResolveChangesKeys(keyRune(" "))
+	m, _ = m.handleResolveChangesKeys(keyRune("d"))
+	if m.state != SettingsStateDiscardConfirm {
+		t.Errorf("expected %v, got %v", SettingsStateDiscardConfirm, m.state)
+	}
+
+	m, _ = m.handleDiscardConfirmKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != SettingsStateResolveChanges {
+		t.Errorf("expected %v after cancel, got %v", SettingsStateResolveChanges, m.state)
+	}
+
+	m, _ = m.handleResolveChangesKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != SettingsStateRepositoryActions {
+		t.Errorf("expected %v after esc, got %v", SettingsStateRepositoryActions, m.state)
+	}
+	if len(m.changedFiles) != 0 {
+		t.Error("expected changed files to be reset on exit")
+	}
+}
+
+func TestResolveChanges_DiscardSelected(t *testing.T) {
+	m, clonePath := createResolveModel(t, map[string]string{"README.md": "edited\n", "notes.md": "new\n"})
+
+	m, _ = m.handleResolveChangesKeys(keyRune("j"))
+	m, _ = m.handleResolveChangesKeys(keyRune(" "))
+	m, _ = m.handleResolveChangesKeys(keyRune("d"))
+	m, cmd := m.handleDiscardConfirmKeys(keyRune("y"))
+	if cmd == nil {
+		t.Fatal("expected discard command")
+	}
+
+	updated, cmd := m.Update(cmd())
+	m = updated.(*SettingsModel)
+	if cmd == nil {
+		t.Fatal("expected reload command after discard")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(*SettingsModel)
+
+	if m.state != SettingsStateResolveChanges {
+		t.Errorf("expected %v, got %v", SettingsStateResolveChanges, m.state)
+	}
+	if _, err := os.Stat(filepath.Join(clonePath, "notes.md")); !os.IsNotExist(err) {
+		t.Errorf("notes.md should be deleted, stat err = %v", err)
+	}
+	want := []repository.FileChange{{Path: "README.md", Kind: repository.FileChangeModified}}
+	if !reflect.DeepEqual(m.changedFiles, want) {
+		t.Errorf("changedFiles = %v, want %v", m.changedFiles, want)
+	}
+}
+
+func TestResolveChanges_StashSyncRestoresChanges(t *testing.T) {
+	m, clonePath := createResolveModel(t, map[string]string{"notes.md": "mine\n"})
+
+	m, cmd := m.handleResolveChangesKeys(keyRune("s"))
+	if m.state != SettingsStateRefreshInProgress || !m.refreshInProgress {
+		t.Fatalf("expected refresh in progress, got %v", m.state)
+	}
+	if cmd == nil {
+		t.Fatal("expected stash sync command")
+	}
+
+	msg := cmd()
+	if done, ok := msg.(stashSyncCompleteMsg); !ok || done.err != nil || len(done.conflicts) != 0 {
+		t.Fatalf("unexpected stash sync result: %#v", msg)
+	}
+	updated, _ := m.Update(msg)
+	m = updated.(*SettingsModel)
+
+	if m.state != SettingsStateMainMenu {
+		t.Errorf("expected %v, got %v", SettingsStateMainMenu, m.state)
+	}
+	if got, err := os.ReadFile(filepath.Join(clonePath, "notes.md")); err != nil || string(got) != "mine\n" {
+		t.Errorf("local change should be restored, got %q err %v", got, err)
+	}
+	if repository.HasStash(clonePath) {
+		t.Error("stash should be removed after sync")
+	}
+}
+
+func TestHandleStashSyncComplete(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       stashSyncCompleteMsg
+		wantState SettingsState
+	}{
+		{name: "success", msg: stashSyncCompleteMsg{}, wantState: SettingsStateMainMenu},
+		{name: "failure", msg: stashSyncCompleteMsg{err: errors.New("network error")}, wantState: SettingsStateRefreshError},
+		{name: "conflicts", msg: stashSyncCompleteMsg{conflicts: []string{"README.md"}}, wantState: SettingsStateResolveChanges},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := createTestModel(t)
+			m.state = SettingsStateRefreshInProgress
+			m.refreshInProgress = true
+
+			m, _ = m.handleStashSyncComplete(tt.msg)
+			if m.state != tt.wantState {
+				t.Errorf("expected %v, got %v", tt.wantState, m.state)
+			}
+			if m.refreshInProgress {
+				t.Error("refreshInProgress should be cleared")
+			}
+			if tt.msg.err != nil && m.lastRefreshError != tt.msg.err {
+				t.Errorf("lastRefreshError = %v, want %v", m.lastRefreshError, tt.msg.err)
+			}
+			if !reflect.DeepEqual(m.stashConflicts, tt.msg.conflicts) {
+				t.Errorf("stashConflicts = %v, want %v", m.stashConflicts, tt.msg.conflicts)
+			}
+		})
+	}
+}
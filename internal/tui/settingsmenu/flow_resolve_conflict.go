@@ -0,0 +1,227 @@
+// Package settingsmenu provides the settings modification flow for the rulem TUI application.
+package settingsmenu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rulem/internal/config"
+	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers/textutil"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Resolve Clone Directory Conflict Flow
+// Flow: RefreshInProgress (conflict) → ResolveConflict → ResolveConflictInProgress →
+// [ResolveConflictComplete | RefreshError]
+//
+// This file contains the handlers and views that help the user out of a clone
+// directory holding a different repository or non-git content: back it up to
+// a timestamped directory and re-clone, adopt the existing files, or abort.
+// The work is done by repository.GitSource.ResolveDirectoryConflict.
+
+// conflictResolutions lists the choices of the conflict dialog in display order
+var conflictResolutions = []repository.ConflictResolution{
+	repository.ConflictBackupAndReclone,
+	repository.ConflictAdoptExisting,
+	repository.ConflictAbort,
+}
+
+// handleResolveConflictKeys processes user input on the conflict dialog.
+func (m *SettingsModel) handleResolveConflictKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.conflictCursor > 0 {
+			m.conflictCursor--
+		}
+	case "down", "j":
+		if m.conflictCursor < len(conflictResolutions)-1 {
+			m.conflictCursor++
+		}
+	case "enter":
+		resolution := conflictResolutions[m.conflictCursor]
+		if resolution == repository.ConflictAbort {
+			return m.abortResolveConflict()
+		}
+		m.logger.LogUserAction("settings_resolve_conflict_confirmed", resolution.String())
+		return m.transitionTo(SettingsStateResolveConflictInProgress), m.resolveConflict(resolution)
+	case "esc":
+		return m.abortResolveConflict()
+	}
+	return m, nil
+}
+
+// handleResolveConflictInProgressKeys blocks input while the conflict is resolved.
+func (m *SettingsModel) handleResolveConflictInProgressKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	return m, nil
+}
+
+// handleResolveConflictCompleteKeys returns to the repository actions menu on any key.
+func (m *SettingsModel) handleResolveConflictCompleteKeys(msg tea.KeyMsg) (*SettingsModel, tea.Cmd) {
+	m.resetConflict()
+	return m.transitionTo(SettingsStateRepositoryActions), nil
+}
+
+// abortResolveConflict leaves the directory alone and returns to the repository actions.
+func (m *SettingsModel) abortResolveConflict() (*SettingsModel, tea.Cmd) {
+	m.logger.LogUserAction("settings_resolve_conflict_aborted", "leaving the clone directory untouched")
+	m.resetConflict()
+	return m.transitionTo(SettingsStateRepositoryActions), nil
+}
+
+// transitionToResolveConflict opens the conflict dialog for a refresh that
+// found a conflicting clone directory.
+func (m *SettingsModel) transitionToResolveConflict(conflict *repository.DirectoryConflictError) (*SettingsModel, tea.Cmd) {
+	m.resetConflict()
+	m.directoryConflict = conflict
+	m.lastRefreshError = nil
+	return m.transitionTo(SettingsStateResolveConflict), nil
+}
+
+// handleConflictResolved shows the outcome of a resolution, or its error on
+// the refresh error screen. A resolved clone is prepared again by reloading
+// the config.
+func (m *SettingsModel) handleConflictResolved(msg conflictResolvedMsg) (*SettingsModel, tea.Cmd) {
+	if msg.err != nil {
+		m.logger.Error("Failed to resolve clone directory conflict", "error", msg.err)
+		m.resetConflict()
+		m.lastRefreshError = msg.err
+		return m.transitionTo(SettingsStateRefreshError), nil
+	}
+
+	m.logger.Info("Resolved clone directory conflict", "resolution", msg.resolution.String(), "backup", msg.backup)
+	m.conflictResolution = msg.resolution
+	m.conflictBackup = msg.backup
+	return m.transitionTo(SettingsStateResolveConflictComplete), config.ReloadConfig()
+}
+
+// resetConflict clears the conflict dialog state.
+func (m *SettingsModel) resetConflict() {
+	m.directoryConflict = nil
+	m.conflictCursor = 0
+	m.conflictResolution = repository.ConflictAbort
+	m.conflictBackup = ""
+}
+
+// resolveConflict applies resolution to the selected repository's clone directory.
+func (m *SettingsModel) resolveConflict(resolution repository.ConflictResolution) tea.Cmd {
+	return func() tea.Msg {
+		selectedRepo, err := m.currentConfig.FindRepositoryByID(m.selectedRepositoryID)
+		if err != nil {
+			return conflictResolvedMsg{err: err}
+		}
+		if selectedRepo.Type != repository.RepositoryTypeGitHub || selectedRepo.RemoteURL == nil {
+			return conflictResolvedMsg{err: fmt.Errorf("cannot resolve: not a GitHub repository")}
+		}
+
+		source := repository.NewGitSource(*selectedRepo.RemoteURL, selectedRepo.Branch, selectedRepo.Path)
+		subpath, err := repository.CleanSubpath(selectedRepo.GetSubpath())
+		if err != nil {
+			return conflictResolvedMsg{err: err}
+		}
+		source.Subpath = subpath
+		source.Tag, source.Commit = selectedRepo.GetTag(), selectedRepo.GetCommit()
+		source.RepositoryID = selectedRepo.ID
+
+		backup, err := source.ResolveDirectoryConflict(context.Background(), resolution, m.logger)
+		return conflictResolvedMsg{resolution: resolution, backup: backup, err: err}
+	}
+}
+
+// refreshConflict returns the conflict of the last refresh, or nil.
+func refreshConflict(err error) *repository.DirectoryConflictError {
+	var conflict *repository.DirectoryConflictError
+	if errors.As(err, &conflict) {
+		return conflict
+	}
+	return nil
+}
+
+// Views
+
+// viewResolveConflict renders what was found in the clone directory and the
+// ways out.
+func (m *SettingsModel) viewResolveConflict() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "⚠️  Clone Directory Conflict",
+		Subtitle: "The clone directory holds something else",
+		HelpText: "↑/↓ to navigate • Enter to select • Esc to abort",
+	})
+
+	var content strings.Builder
+	if conflict := m.directoryConflict; conflict != nil {
+		content.WriteString(fmt.Sprintf("Directory: %s\n", textutil.TruncatePath(conflict.Path, m.layout.ContentWidth()-11)))
+		if conflict.CurrentRemote != "" {
+			content.WriteString(fmt.Sprintf("Contains:  a different repository (%s)\n", conflict.CurrentRemote))
+		} else {
+			content.WriteString("Contains:  files that are not a git repository\n")
+		}
+		content.WriteString(fmt.Sprintf("Expected:  %s\n\n", conflict.ExpectedRemote))
+	}
+
+	descriptions := map[repository.ConflictResolution]string{
+		repository.ConflictBackupAndReclone: "Move the directory to a timestamped backup next to it and clone again",
+		repository.ConflictAdoptExisting:    "Keep the files and track the repository; they show up as local changes to publish or discard",
+		repository.ConflictAbort:            "Leave the directory as it is",
+	}
+	for i, resolution := range conflictResolutions {
+		prefix := "  "
+		if i == m.conflictCursor {
+			prefix = "▸ "
+		}
+		content.WriteString(lipgloss.NewStyle().Bold(i == m.conflictCursor).Render(prefix + resolution.String()))
+		content.WriteString("\n")
+		content.WriteString(lipgloss.NewStyle().Faint(true).Render("   " + descriptions[resolution]))
+		content.WriteString("\n")
+		if i < len(conflictResolutions)-1 {
+			content.WriteString("\n")
+		}
+	}
+
+	return m.layout.Render(content.String())
+}
+
+// viewResolveConflictInProgress renders the screen shown while the conflict is resolved.
+func (m *SettingsModel) viewResolveConflictInProgress() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "⚠️  Resolving Conflict...",
+		Subtitle: "Fixing the clone directory",
+		HelpText: "Please wait",
+	})
+
+	content := lipgloss.NewStyle().Faint(true).Render("Contacting the remote repository...")
+
+	return m.layout.Render(content)
+}
+
+// viewResolveConflictComplete renders where the backup went, or how to review
+// adopted files and where the adopted branch's old commits were kept.
+func (m *SettingsModel) viewResolveConflictComplete() string {
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "✅ Conflict Resolved",
+		Subtitle: m.conflictResolution.String(),
+		HelpText: "Press any key to return",
+	})
+
+	var content strings.Builder
+	if m.conflictResolution == repository.ConflictBackupAndReclone {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#5fd787")).Render("✓ The repository was cloned again"))
+		content.WriteString("\n\nThe previous directory was moved to:\n")
+		content.WriteString("• " + textutil.TruncatePath(m.conflictBackup, m.layout.ContentWidth()-2))
+	} else {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#5fd787")).Render("✓ The existing directory now tracks the repository"))
+		content.WriteString("\n\n")
+		if m.conflictBackup != "" {
+			content.WriteString("The branch's previous commits were kept as:\n")
+			content.WriteString("• " + m.conflictBackup + "\n\n")
+		}
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
+			Render("💡 Files that differ from the remote are local changes. Publish them, or run Manual Refresh to review and discard them."))
+	}
+
+	return m.layout.Render(content.String())
+}
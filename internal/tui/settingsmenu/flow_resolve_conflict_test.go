@@ -0,0 +1,94 @@
+package settingsmenu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/repository"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestResolveConflict_RefreshOpensDialog(t *testing.T) {
+	clonePath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(clonePath, "notes.txt"), []byte("mine\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	m := createTestModelWithConfig(t, createGitHubConfig(clonePath, "https://github.com/test/repo.git", "main"))
+	m.selectedRepositoryID = "test-github-1"
+	m.state = SettingsStateRefreshInProgress
+
+	updated, _ := m.Update(m.triggerRefresh()())
+	m = updated.(*SettingsModel)
+	if m.state != SettingsStateResolveConflict {
+		t.Fatalf("expected %v, got %v", SettingsStateResolveConflict, m.state)
+	}
+	view := m.View()
+	for _, want := range []string{"not a git repository", "Back up and re-clone", "Adopt existing directory", "Abort"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected the dialog to show %q", want)
+		}
+	}
+
+	m, _ = m.handleResolveConflictKeys(keyRune("j"))
+	m, _ = m.handleResolveConflictKeys(keyRune("j"))
+	m, _ = m.handleResolveConflictKeys(keyRune("j"))
+	if m.conflictCursor != len(conflictResolutions)-1 {
+		t.Fatalf("cursor should stop at the last choice, got %d", m.conflictCursor)
+	}
+
+	// Abort leaves the directory untouched
+	m, cmd := m.handleResolveConflictKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != SettingsStateRepositoryActions || cmd != nil {
+		t.Errorf("expected abort to return to %v without a command, got %v", SettingsStateRepositoryActions, m.state)
+	}
+	if m.directoryConflict != nil {
+		t.Error("abort should clear the conflict")
+	}
+	if _, err := os.Stat(filepath.Join(clonePath, "notes.txt")); err != nil {
+		t.Errorf("abort must keep the files: %v", err)
+	}
+}
+
+func TestHandleConflictResolved(t *testing.T) {
+	m := createTestModelWithConfig(t, createGitHubConfig(t.TempDir(), "https://github.com/test/repo.git", "main"))
+	m.state = SettingsStateResolveConflictInProgress
+
+	m, cmd := m.handleConflictResolved(conflictResolvedMsg{
+		resolution: repository.ConflictBackupAndReclone,
+		backup:     "/tmp/rules.rulem-backup-20240501-103000",
+	})
+	if m.state != SettingsStateResolveConflictComplete || cmd == nil {
+		t.Fatalf("expected %v with a config reload, got %v", SettingsStateResolveConflictComplete, m.state)
+	}
+	if view := m.View(); !strings.Contains(view, "rules.rulem-backup-20240501-103000") {
+		t.Error("expected the view to show where the backup went")
+	}
+	m, _ = m.handleResolveConflictCompleteKeys(keyRune("x"))
+	if m.state != SettingsStateRepositoryActions || m.conflictBackup != "" {
+		t.Errorf("expected %v with the state cleared, got %v", SettingsStateRepositoryActions, m.state)
+	}
+
+	m.state = SettingsStateResolveConflictInProgress
+	m, _ = m.handleConflictResolved(conflictResolvedMsg{
+		resolution: repository.ConflictAdoptExisting,
+		backup:     "refs/rulem-backup/main",
+	})
+	if view := m.View(); !strings.Contains(view, "refs/rulem-backup/main") {
+		t.Error("expected the view to show where the adopted branch's old commits were kept")
+	}
+	m, _ = m.handleResolveConflictCompleteKeys(keyRune("x"))
+
+	m.state = SettingsStateResolveConflictInProgress
+	m, _ = m.handleConflictResolved(conflictResolvedMsg{
+		resolution: repository.ConflictAdoptExisting,
+		err:        errors.New("authentication failed"),
+	})
+	if m.state != SettingsStateRefreshError || m.lastRefreshError == nil {
+		t.Errorf("expected a failed resolution on %v, got %v", SettingsStateRefreshError, m.state)
+	}
+}
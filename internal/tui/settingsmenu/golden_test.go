@@ -0,0 +1,378 @@
+package settingsmenu
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
+)
+
+// Golden view tests: each screen is rendered at several terminal sizes and
+// compared with testdata/<name>_<width>x<height>.golden. Regenerate with
+// go test ./internal/tui/settingsmenu -run TestGoldenViews -update
+
+var goldenSizes = []struct{ width, height int }{
+	{80, 24},
+	{120, 40},
+}
+
+// goldenRepositories returns fixed repository entries so rendered paths do
+// not depend on the machine running the tests
+func goldenRepositories() []repository.RepositoryEntry {
+	url := "https://github.com/example/team-rules.git"
+	branch := "main"
+	return []repository.RepositoryEntry{
+		{
+			ID:   "personal-rules-1728756432",
+			Name: "Personal Rules",
+			Type: repository.RepositoryTypeLocal,
+			Path: "/home/user/rules",
+		},
+		{
+			ID:        "team-rules-1728756433",
+			Name:      "Team Rules",
+			Type:      repository.RepositoryTypeGitHub,
+			Path:      "/home/user/.local/share/rulem/team-rules",
+			RemoteURL: &url,
+			Branch:    &branch,
+		},
+	}
+}
+
+// goldenChangedFiles returns one uncommitted change of each kind
+func goldenChangedFiles() []repository.FileChange {
+	return []repository.FileChange{
+		{Path: "README.md", Kind: repository.FileChangeModified},
+		{Path: "notes.md", Kind: repository.FileChangeUntracked},
+		{Path: "rules/go.md", Kind: repository.FileChangeAdded},
+		{Path: "rules/old.md", Kind: repository.FileChangeDeleted},
+	}
+}
+
+// goldenCommits returns a fixed commit history, newest first
+func goldenCommits() []repository.CommitInfo {
+	when := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	return []repository.CommitInfo{
+		{Hash: "9f2c4e1a7b3d5f6e8a0c1b2d3e4f5a6b7c8d9e0f", Message: "Tighten Go error handling rule", Author: "Ada", When: when,
+			Files: []string{"go/errors.md", "go/style.md"}},
+		{Hash: "4b1d7e9c2a6f8e0b3d5c7a9e1f2b4d6c8e0a2b4c", Message: "Add TypeScript rules", Author: "Grace", When: when.AddDate(0, 0, -2),
+			Files: []string{"ts/async.md", "ts/imports.md", "ts/naming.md", "ts/react.md", "ts/style.md", "ts/testing.md", "ts/types.md"}},
+		{Hash: "0a5e3c8b1d9f7e2a4c6b8d0f1e3a5c7b9d1f3e5a", Message: "Initial rules", Author: "Ada", When: when.AddDate(0, 0, -9),
+			Boundary: true},
+	}
+}
+
+// createGoldenModel builds a settings model with the given repositories
+// without touching the filesystem or network
+func createGoldenModel(t *testing.T, width, height int, repos []repository.RepositoryEntry) *SettingsModel {
+	t.Helper()
+	logger, _ := logging.NewTestLogger()
+	cfg := &config.Config{Repositories: repos}
+	m := NewSettingsModel(helpers.NewUIContext(width, height, &config.Config{}, logger))
+	m.currentConfig = cfg
+
+	m.preparedRepos = nil
+	for _, entry := range repos {
+		m.preparedRepos = append(m.preparedRepos, repository.PreparedRepository{
+			Entry:     entry,
+			LocalPath: entry.Path,
+			SyncResult: repository.RepositorySyncResult{
+				RepositoryID:   entry.ID,
+				RepositoryName: entry.Name,
+				Status:         repository.SyncStatusSkipped,
+			},
+		})
+	}
+	m.repoList.SetItems(BuildSettingsMainMenuItems(m.preparedRepos))
+	return m
+}
+
+func TestGoldenViews(t *testing.T) {
+	tuitest.PinDataHome(t)
+	github := goldenRepositories()[1].ID
+
+	tests := []struct {
+		name  string
+		repos []repository.RepositoryEntry
+		setup func(m *SettingsModel) *SettingsModel
+	}{
+		{
+			name:  "main_menu_empty",
+			setup: func(m *SettingsModel) *SettingsModel { return m },
+		},
+		{
+			name:  "main_menu",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel { return m },
+		},
+		{
+			name:  "repository_actions_github",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				return m.transitionTo(SettingsStateRepositoryActions)
+			},
+		},
+		{
+			name:  "confirm_delete",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				return m.transitionTo(SettingsStateConfirmDelete)
+			},
+		},
+		{
+			name:  "update_branch",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m, _ = m.transitionToUpdateGitHubBranch()
+				return m
+			},
+		},
+		{
+			name:  "edit_branch_confirm",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m.newGitHubBranch = "develop"
+				return m.transitionTo(SettingsStateEditBranchConfirm)
+			},
+		},
+		{
+			name:  "edit_branch_in_progress",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m.newGitHubBranch = "develop"
+				m.branchSwitchStep = repository.BranchSwitchVerifying
+				return m.transitionTo(SettingsStateEditBranchInProgress)
+			},
+		},
+		{
+			name:  "edit_branch_rolling_back",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m.newGitHubBranch = "develop"
+				m.branchSwitchStep = repository.BranchSwitchRollingBack
+				return m.transitionTo(SettingsStateEditBranchInProgress)
+			},
+		},
+		{
+			name: "add_repository_type",
+			setup: func(m *SettingsModel) *SettingsModel {
+				m, _ = m.transitionToAddRepositoryType()
+				return m
+			},
+		},
+		{
+			name: "add_local_name",
+			setup: func(m *SettingsModel) *SettingsModel {
+				m, _ = m.transitionToAddLocalName()
+				return m
+			},
+		},
+		{
+			name: "add_github_form",
+			setup: func(m *SettingsModel) *SettingsModel {
+				m, _ = m.transitionToAddGitHubForm()
+				return m
+			},
+		},
+		{
+			name: "add_github_form_keyring_unavailable",
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.ctx.Keyring = repository.KeyringStatus{
+					Checked:  true,
+					Error:    "The name org.freedesktop.secrets was not provided by any .service files",
+					Guidance: repository.KeyringGuidance("linux"),
+				}
+				m, _ = m.transitionToAddGitHubForm()
+				return m
+			},
+		},
+		{
+			name: "add_github_form_errors",
+			setup: func(m *SettingsModel) *SettingsModel {
+				m, _ = m.transitionToAddGitHubForm()
+				m.addGitHubForm = m.addGitHubForm.
+					SetValue(gitHubFieldURL, "not-a-url").
+					SetError(gitHubFieldURL, errors.New("invalid GitHub URL"))
+				return m
+			},
+		},
+		{
+			name:  "refresh_error",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m.lastRefreshError = errors.New("authentication required: invalid or expired token")
+				return m.transitionTo(SettingsStateRefreshError)
+			},
+		},
+		{
+			name:  "refresh_error_dirty",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m.isDirty = true
+				m.lastRefreshError = errors.New("repository has uncommitted changes - please commit or stash them before refreshing")
+				return m.transitionTo(SettingsStateRefreshError)
+			},
+		},
+		{
+			name:  "resolve_changes",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m = m.transitionTo(SettingsStateResolveChanges)
+				m, _ = m.handleChangedFilesMsg(changedFilesMsg{files: goldenChangedFiles()})
+				m.changedFilesSelected["notes.md"] = true
+				m.changedFilesCursor = 1
+				return m
+			},
+		},
+		{
+			name:  "resolve_changes_conflicts",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m = m.transitionTo(SettingsStateResolveChanges)
+				m.stashConflicts = []string{"README.md"}
+				m, _ = m.handleChangedFilesMsg(changedFilesMsg{files: []repository.FileChange{
+					{Path: "README.md.rulem-stash", Kind: repository.FileChangeUntracked},
+				}})
+				return m
+			},
+		},
+		{
+			name:  "discard_confirm",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m, _ = m.handleChangedFilesMsg(changedFilesMsg{files: goldenChangedFiles()})
+				m.changedFilesSelected["README.md"] = true
+				m.changedFilesSelected["notes.md"] = true
+				return m.transitionTo(SettingsStateDiscardConfirm)
+			},
+		},
+		{
+			name:  "commit_browser",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m = m.transitionTo(SettingsStateCommitBrowser)
+				m, _ = m.handleCommitsLoadedMsg(commitsLoadedMsg{commits: goldenCommits()})
+				m.commitsCursor = 1
+				return m
+			},
+		},
+		{
+			name:  "commit_browser_inspecting",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m = m.transitionTo(SettingsStateCommitBrowser)
+				commits := goldenCommits()
+				m, _ = m.handleCommitsLoadedMsg(commitsLoadedMsg{
+					commits:    commits,
+					inspection: &repository.Inspection{Branch: "main", Commit: commits[2].Hash},
+				})
+				m.commitsCursor = 2
+				return m
+			},
+		},
+		{
+			name:  "commit_checkout_confirm",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m, _ = m.handleCommitsLoadedMsg(commitsLoadedMsg{commits: goldenCommits()})
+				m.commitsCursor = 1
+				return m.transitionTo(SettingsStateCommitCheckoutConfirm)
+			},
+		},
+		{
+			name:  "maintenance_complete",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m, _ = m.handleMaintenanceComplete(maintenanceCompleteMsg{result: repository.MaintenanceResult{
+					Before: repository.ObjectStats{LooseObjects: 412, Packs: 9, Size: 3 * 1024 * 1024},
+					After:  repository.ObjectStats{LooseObjects: 0, Packs: 1, Size: 1200 * 1024},
+				}})
+				return m
+			},
+		},
+		{
+			name:  "maintenance_error",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.selectedRepositoryID = github
+				m, _ = m.handleMaintenanceComplete(maintenanceCompleteMsg{
+					err: errors.New("maintenance failed: failed to read object 4b1d7e9c: object not found (fallback: git is not installed)"),
+				})
+				return m
+			},
+		},
+		{
+			name:  "relocate_storage_confirm",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.newStorageDir = "/mnt/data/rulem"
+				m.relocationMoves = []repository.RelocationMove{
+					{ID: "personal-rules-1728756432", Name: "Personal Rules", From: "/home/user/rules", To: "/mnt/data/rulem/rules"},
+					{ID: "team-rules-1728756433", Name: "Team Rules", From: "/home/user/.local/share/rulem/team-rules",
+						To: "/mnt/data/rulem/team-rules", Missing: true},
+				}
+				return m.transitionTo(SettingsStateRelocateStorageConfirm)
+			},
+		},
+		{
+			name:  "relocate_storage_in_progress",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m.newStorageDir = "/mnt/data/rulem"
+				m = m.transitionTo(SettingsStateRelocateStorageInProgress)
+				updated, _ := m.Update(relocationProgressMsg{progress: repository.RelocationProgress{
+					Step: repository.RelocationVerifying, Repository: "Personal Rules", Index: 1, Total: 2,
+				}})
+				return updated.(*SettingsModel)
+			},
+		},
+		{
+			name:  "relocate_storage_complete_leftovers",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				updated, _ := m.Update(relocationCompleteMsg{leftovers: []string{"/home/user/rules"}})
+				return updated.(*SettingsModel)
+			},
+		},
+		{
+			name:  "delete_error",
+			repos: goldenRepositories(),
+			setup: func(m *SettingsModel) *SettingsModel {
+				m = m.transitionTo(SettingsStateDeleteError)
+				m.layout = m.layout.SetError(errors.New("failed to save config: permission denied"))
+				return m
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, size := range goldenSizes {
+			name := fmt.Sprintf("%s_%dx%d", tt.name, size.width, size.height)
+			t.Run(name, func(t *testing.T) {
+				m := tt.setup(createGoldenModel(t, size.width, size.height, tt.repos))
+				tuitest.Golden(t, name, m.View())
+			})
+		}
+	}
+}
@@ -62,6 +62,15 @@ func BuildSettingsMainMenuItems(prepared []repository.PreparedRepository) []list
 		DisplayDescription: "Update Personal Access Token for GitHub repositories",
 	})
 
+	// Moving storage only makes sense once there is something to move
+	if len(prepared) > 0 {
+		items = append(items, SettingsActionListItem{
+			Action:             ChangeOptionRelocateStorage,
+			DisplayTitle:       "📦 Move Storage",
+			DisplayDescription: "Move all repositories to a new base directory",
+		})
+	}
+
 	return items
 }
 
@@ -14,6 +14,9 @@ type mockCredentialManager struct {
 	storedToken      string
 	getToken         string
 	getErr           error
+
+	storedNamedTokens       map[string]string
+	deletedRepositoryTokens []string
 }
 
 func (m *mockCredentialManager) ValidateGitHubToken(token string) error {
@@ -45,3 +48,31 @@ func (m *mockCredentialManager) GetGitHubToken() (string, error) {
 	}
 	return m.getToken, nil
 }
+
+func (m *mockCredentialManager) ValidateTokenForRemote(token string, repoURL string) error {
+	return m.ValidateGitHubToken(token)
+}
+
+func (m *mockCredentialManager) StoreTokenForRemote(repoURL string, token string) error {
+	return m.StoreGitHubToken(token)
+}
+
+func (m *mockCredentialManager) GetTokenForRemote(repoURL string) (string, error) {
+	return m.GetGitHubToken()
+}
+
+func (m *mockCredentialManager) DeleteRepositoryToken(repoURL, repositoryID string) error {
+	m.deletedRepositoryTokens = append(m.deletedRepositoryTokens, repositoryID)
+	return nil
+}
+
+func (m *mockCredentialManager) StoreNamedToken(provider repository.Provider, name, token string) error {
+	if m.storeErr != nil {
+		return m.storeErr
+	}
+	if m.storedNamedTokens == nil {
+		m.storedNamedTokens = map[string]string{}
+	}
+	m.storedNamedTokens[name] = token
+	return nil
+}
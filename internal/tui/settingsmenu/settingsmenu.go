@@ -14,6 +14,7 @@
 //   - UpdateGitHubBranch: Change GitHub branch
 //   - UpdateGitHubPath: Change local clone path
 //   - ManualRefresh: Trigger manual sync from GitHub
+//   - ResolveChanges: Discard or stash local changes blocking a refresh
 //   - Confirmation: Review and confirm all changes
 //   - Complete: Settings successfully updated
 //   - Error: Error occurred during settings modification
@@ -21,7 +22,7 @@
 // Key features:
 //   - Full repository type switching (Local ↔ GitHub)
 //   - Granular updates for individual settings
-//   - Dirty state detection for GitHub repositories
+//   - Dirty state detection for GitHub repositories, with guided stash/discard
 //   - Manual refresh operations
 //   - Comprehensive validation and error handling
 //   - State history for back navigation
@@ -35,7 +36,9 @@ import (
 	"rulem/internal/logging"
 	"rulem/internal/repository"
 	"rulem/internal/tui/components"
+	"rulem/internal/tui/components/form"
 	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/helpers/navigation"
 	"rulem/internal/tui/helpers/repolist"
 	"strings"
 
@@ -54,13 +57,22 @@ type credentialManager interface {
 	ValidateGitHubTokenForRepos(ctx context.Context, token string, repos []repository.RepositoryEntry) error
 	StoreGitHubToken(token string) error
 	GetGitHubToken() (string, error)
+
+	// Tokens of the provider hosting a remote (GitHub, GitLab or Bitbucket)
+	ValidateTokenForRemote(token string, repoURL string) error
+	StoreTokenForRemote(repoURL string, token string) error
+	GetTokenForRemote(repoURL string) (string, error)
+
+	// Tokens stored for a single repository or host, see repository.RepositoryTokenName
+	StoreNamedToken(provider repository.Provider, name, token string) error
+	DeleteRepositoryToken(repoURL, repositoryID string) error
 }
 
 // SettingsModel handles the settings modification flow
 type SettingsModel struct {
 	// State management
-	state         SettingsState
-	previousState SettingsState
+	state   SettingsState
+	history navigation.Stack[SettingsState] // states left to reach state, for back and breadcrumbs
 
 	// Current configuration - contains loaded repositories
 	currentConfig *config.Config
@@ -75,14 +87,16 @@ type SettingsModel struct {
 	newGitHubPAT    string // Used in global PAT management
 
 	// Add repository flow state
-	addRepositoryTypeIndex int    // 0=Local, 1=GitHub
+	addRepositoryTypeIndex int    // 0=Local, 1=GitHub, 2=Template
 	addRepositoryName      string // name for new repository
 	addRepositoryPath      string // path for new repository (local or github clone)
 
 	// Components
-	textInput textinput.Model
-	layout    components.LayoutModel
-	repoList  list.Model
+	textInput       textinput.Model
+	addGitHubForm   form.Model
+	addTemplateForm form.Model
+	layout          components.LayoutModel
+	repoList        list.Model
 
 	// Selection state
 	selectedRepositoryActionOption int
@@ -96,6 +110,47 @@ type SettingsModel struct {
 	refreshInProgress bool
 	lastRefreshError  error
 
+	// Resolve local changes state
+	changedFiles         []repository.FileChange
+	changedFilesSelected map[string]bool
+	changedFilesCursor   int
+	changedFilesLoaded   bool
+	stashConflicts       []string // files restored next to their upstream version
+
+	// Clone directory conflict state
+	directoryConflict  *repository.DirectoryConflictError
+	conflictCursor     int
+	conflictResolution repository.ConflictResolution
+	conflictBackup     string // where a backed up clone directory was moved, or the ref keeping an adopted branch's old tip
+
+	// Branch switch state
+	branchSwitchStep repository.BranchSwitchStep
+
+	// Commit browser state
+	commits          []repository.CommitInfo
+	commitsCursor    int
+	commitsLoaded    bool
+	commitInspection *repository.Inspection // set while a commit is checked out for inspection
+
+	// Maintenance state
+	maintenanceResult repository.MaintenanceResult
+
+	// Publish state
+	publishResult repository.PushResult
+
+	// Storage relocation state
+	relocationMoves     []repository.RelocationMove
+	relocationProgress  repository.RelocationProgress
+	relocationLeftovers []string // old paths that could not be removed after relocating
+
+	// Browser sign in state
+	deviceCode        repository.DeviceCode
+	deviceScopes      []string // scopes granted to a token from a browser sign in
+	deviceLoginCancel context.CancelFunc
+
+	// Token scope picked on the Update GitHub PAT screen, an index into tokenScopes
+	tokenScopeIndex int
+
 	// Dependencies
 	logger      *logging.AppLogger
 	credManager credentialManager
@@ -105,6 +160,10 @@ type SettingsModel struct {
 
 // NewSettingsModel creates a new settings model
 func NewSettingsModel(ctx helpers.UIContext) *SettingsModel {
+	if ctx.IDs == nil {
+		ctx.IDs = config.DefaultIDGenerator()
+	}
+
 	ti := textinput.New()
 	ti.Focus()
 	ti.CharLimit = 256
@@ -151,6 +210,8 @@ func NewSettingsModel(ctx helpers.UIContext) *SettingsModel {
 		credManager:   repository.NewCredentialManager(),
 		ctx:           ctx,
 		context:       context.Background(),
+
+		changedFilesSelected: make(map[string]bool),
 	}
 }
 
@@ -180,6 +241,8 @@ func (m *SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update layout and text input width responsively
 		m.layout, _ = m.layout.Update(msg)
 		m.textInput.Width = m.layout.InputWidth()
+		m.addGitHubForm = m.addGitHubForm.SetWidth(m.layout.InputWidth())
+		m.addTemplateForm = m.addTemplateForm.SetWidth(m.layout.InputWidth())
 		return m, nil
 
 	case tea.KeyMsg:
@@ -214,6 +277,11 @@ func (m *SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case refreshCompleteMsg:
 		m.refreshInProgress = false
+		if conflict := refreshConflict(msg.err); conflict != nil {
+			// A clone directory holding something else gets its own dialog
+			m.logger.Warn("Refresh found a conflicting clone directory", "error", msg.err)
+			return m.transitionToResolveConflict(conflict)
+		}
 		if msg.err != nil {
 			// Surface the failure to the user via the RefreshError state.
 			m.logger.Error("Refresh failed", "error", msg.err)
@@ -226,6 +294,36 @@ func (m *SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.layout = m.layout.ClearError()
 		return m, nil
 
+	case changedFilesMsg:
+		return m.handleChangedFilesMsg(msg)
+
+	case discardCompleteMsg:
+		if msg.err != nil {
+			m.layout = m.layout.SetError(fmt.Errorf("failed to discard files: %w", msg.err))
+			m.state = SettingsStateResolveChanges
+			return m, nil
+		}
+		m.changedFilesSelected = make(map[string]bool)
+		return m.transitionTo(SettingsStateResolveChanges), m.loadChangedFiles()
+
+	case stashSyncCompleteMsg:
+		return m.handleStashSyncComplete(msg)
+
+	case conflictResolvedMsg:
+		return m.handleConflictResolved(msg)
+
+	case commitsLoadedMsg:
+		return m.handleCommitsLoadedMsg(msg)
+
+	case commitCheckoutCompleteMsg:
+		return m.handleCommitCheckoutComplete(msg)
+
+	case maintenanceCompleteMsg:
+		return m.handleMaintenanceComplete(msg)
+
+	case publishCompleteMsg:
+		return m.handlePublishComplete(msg)
+
 	case editBranchDirtyStateMsg:
 		// Handle dirty state check result for branch editing
 		m.isDirty = msg.isDirty
@@ -249,11 +347,32 @@ func (m *SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.logger.Debug("Repository clean, proceeding to branch confirmation")
 		return m.transitionTo(SettingsStateEditBranchConfirm), nil
 
+	case branchSwitchProgressMsg:
+		m.branchSwitchStep = msg.step
+		return m, waitForProgress(msg.updates)
+
+	case relocationProgressMsg:
+		m.relocationProgress = msg.progress
+		return m, waitForProgress(msg.updates)
+
+	case relocationCompleteMsg:
+		m.relocationLeftovers = msg.leftovers
+		m.state = SettingsStateComplete
+		m.layout = m.layout.ClearError()
+		return m, config.ReloadConfig()
+
+	case relocateStorageErrorMsg:
+		m.logger.Error("Storage relocation error", "error", msg.err)
+		m = m.transitionTo(SettingsStateRelocateStorageError)
+		m.layout = m.layout.SetError(msg.err)
+		return m, nil
+
 	case editBranchErrorMsg:
 		// Transition to error state and display error
 		m.logger.Error("Branch edit error", "error", msg.err)
+		m = m.transitionTo(SettingsStateEditBranchError)
 		m.layout = m.layout.SetError(msg.err)
-		return m.transitionTo(SettingsStateEditBranchError), nil
+		return m, nil
 
 	case refreshDirtyStateMsg:
 		// Handle dirty state check result for manual refresh
@@ -317,9 +436,27 @@ func (m *SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.layout = m.layout.SetError(msg.err)
 		return m.transitionTo(SettingsStateDeleteError), nil
 
+	case deviceCodeMsg:
+		if m.state != SettingsStateUpdateGitHubPAT {
+			m.cancelDeviceLogin() // sign in was abandoned
+			return m, nil
+		}
+		m.deviceCode = msg.code
+		return m.transitionTo(SettingsStateDeviceLogin), waitForDeviceLogin(msg)
+
+	case deviceTokenMsg:
+		m.logger.Info("GitHub browser sign in completed", "scopes", msg.token.Scopes)
+		m.cancelDeviceLogin()
+		m.newGitHubPAT = msg.token.AccessToken
+		m.deviceScopes = append([]string{}, msg.token.Scopes...) // non-nil: shown even when empty
+		m.hasChanges = true
+		m.changeType = ChangeOptionGitHubPAT
+		return m.transitionTo(SettingsStateUpdatePATConfirm), nil
+
 	case updatePATErrorMsg:
 		// Transition to error state and display error
 		m.logger.Error("PAT update error", "error", msg.err)
+		m.cancelDeviceLogin()
 		m.layout = m.layout.SetError(msg.err)
 		return m.transitionTo(SettingsStateUpdatePATError), nil
 
@@ -333,6 +470,11 @@ func (m *SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.layout = m.layout.SetError(msg.err)
 		return m.transitionTo(SettingsStateAddGitHubError), nil
 
+	case addTemplateErrorMsg:
+		m.logger.Error("Create from template error", "error", msg.err)
+		m.layout = m.layout.SetError(msg.err)
+		return m.transitionTo(SettingsStateAddTemplateError), nil
+
 	case addGitHubPATNeededMsg:
 		// PAT is missing - transition to PAT input state
 		m.logger.Info("GitHub PAT needed for repository creation, transitioning to PAT input")
@@ -397,6 +539,8 @@ func (m *SettingsModel) handleKeyPress(msg tea.KeyMsg) (*SettingsModel, tea.Cmd)
 		return m.handleUpdateGitHubBranchKeys(msg)
 	case SettingsStateEditBranchConfirm:
 		return m.handleEditBranchConfirmKeys(msg)
+	case SettingsStateEditBranchInProgress:
+		return m.handleEditBranchInProgressKeys(msg)
 	case SettingsStateEditBranchError:
 		return m.handleEditBranchErrorKeys(msg)
 	case SettingsStateUpdateRepoName:
@@ -411,12 +555,46 @@ func (m *SettingsModel) handleKeyPress(msg tea.KeyMsg) (*SettingsModel, tea.Cmd)
 		return m.handleUpdatePATConfirmKeys(msg)
 	case SettingsStateUpdatePATError:
 		return m.handleUpdatePATErrorKeys(msg)
+	case SettingsStateDeviceLogin:
+		return m.handleDeviceLoginKeys(msg)
+	case SettingsStateRelocateStorageInput:
+		return m.handleRelocateStorageInputKeys(msg)
+	case SettingsStateRelocateStorageConfirm:
+		return m.handleRelocateStorageConfirmKeys(msg)
+	case SettingsStateRelocateStorageInProgress:
+		return m.handleRelocateStorageInProgressKeys(msg)
+	case SettingsStateRelocateStorageError:
+		return m.handleRelocateStorageErrorKeys(msg)
 	case SettingsStateManualRefresh:
 		return m.handleManualRefreshKeys(msg)
 	case SettingsStateRefreshInProgress:
 		return m.handleRefreshInProgressKeys(msg)
 	case SettingsStateRefreshError:
 		return m.handleRefreshErrorKeys(msg)
+	case SettingsStateResolveChanges:
+		return m.handleResolveChangesKeys(msg)
+	case SettingsStateDiscardConfirm:
+		return m.handleDiscardConfirmKeys(msg)
+	case SettingsStateResolveConflict:
+		return m.handleResolveConflictKeys(msg)
+	case SettingsStateResolveConflictInProgress:
+		return m.handleResolveConflictInProgressKeys(msg)
+	case SettingsStateResolveConflictComplete:
+		return m.handleResolveConflictCompleteKeys(msg)
+	case SettingsStateCommitBrowser:
+		return m.handleCommitBrowserKeys(msg)
+	case SettingsStateCommitCheckoutConfirm:
+		return m.handleCommitCheckoutConfirmKeys(msg)
+	case SettingsStateMaintenanceInProgress:
+		return m.handleMaintenanceInProgressKeys(msg)
+	case SettingsStateMaintenanceComplete:
+		return m.handleMaintenanceCompleteKeys(msg)
+	case SettingsStatePublishChanges:
+		return m.handlePublishChangesKeys(msg)
+	case SettingsStatePublishInProgress:
+		return m.handlePublishInProgressKeys(msg)
+	case SettingsStatePublishComplete:
+		return m.handlePublishCompleteKeys(msg)
 	case SettingsStateAddRepositoryType:
 		return m.handleAddRepositoryTypeKeys(msg)
 	case SettingsStateAddLocalName:
@@ -425,18 +603,18 @@ func (m *SettingsModel) handleKeyPress(msg tea.KeyMsg) (*SettingsModel, tea.Cmd)
 		return m.handleAddLocalPathKeys(msg)
 	case SettingsStateAddLocalError:
 		return m.handleAddLocalErrorKeys(msg)
-	case SettingsStateAddGitHubName:
-		return m.handleAddGitHubNameKeys(msg)
-	case SettingsStateAddGitHubURL:
-		return m.handleAddGitHubURLKeys(msg)
-	case SettingsStateAddGitHubBranch:
-		return m.handleAddGitHubBranchKeys(msg)
-	case SettingsStateAddGitHubPath:
-		return m.handleAddGitHubPathKeys(msg)
+	case SettingsStateAddGitHubForm:
+		return m.handleAddGitHubFormKeys(msg)
 	case SettingsStateAddGitHubPAT:
 		return m.handleAddGitHubPATKeys(msg)
 	case SettingsStateAddGitHubError:
 		return m.handleAddGitHubErrorKeys(msg)
+	case SettingsStateAddTemplateForm:
+		return m.handleAddTemplateFormKeys(msg)
+	case SettingsStateAddTemplateInProgress:
+		return m.handleAddTemplateInProgressKeys(msg)
+	case SettingsStateAddTemplateError:
+		return m.handleAddTemplateErrorKeys(msg)
 	case SettingsStateComplete:
 		return m.handleCompleteKeys(msg)
 	default:
@@ -464,6 +642,9 @@ func (m *SettingsModel) handleMainMenuKeys(msg tea.KeyMsg) (*SettingsModel, tea.
 			case ChangeOptionGitHubPAT:
 				m.logger.LogUserAction("settings_update_pat", "user selected update GitHub PAT")
 				return m.transitionToUpdateGitHubPAT()
+			case ChangeOptionRelocateStorage:
+				m.logger.LogUserAction("settings_relocate_storage", "user selected move storage")
+				return m.transitionToRelocateStorage()
 			}
 			return m, nil
 		}
@@ -476,7 +657,7 @@ func (m *SettingsModel) handleMainMenuKeys(msg tea.KeyMsg) (*SettingsModel, tea.
 		}
 		return m, nil
 	case "esc":
-		return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+		return m, helpers.NavigateBack
 	default:
 		// Update the list with navigation keys
 		m.repoList, cmd = m.repoList.Update(msg)
@@ -511,26 +692,43 @@ func (m *SettingsModel) handleNavigation(msg tea.KeyMsg) (*SettingsModel, tea.Cm
 // Clears errors, resets selection, and logs the transition.
 func (m *SettingsModel) transitionTo(newState SettingsState) *SettingsModel {
 	m.logger.LogStateTransition("SettingsModel", m.state.String(), newState.String())
-	m.previousState = m.state
-	m.state = newState
 	// Only clear error if we're actually changing states
 	// This prevents clearing errors when transitioning to the same error state
-	if m.previousState != newState {
+	if m.state != newState {
 		m.layout = m.layout.ClearError()
 	}
+	m.history.Visit(m.state, newState)
+	m.state = newState
 	m.selectedRepositoryActionOption = 0
 	return m
 }
 
-// transitionBack navigates back to the previous state.
+// transitionBack navigates back to the previous state in the history, or the
+// main menu when there is none.
 // Used for escape key handling to maintain navigation history.
 func (m *SettingsModel) transitionBack() *SettingsModel {
-	m.logger.LogStateTransition("SettingsModel", m.state.String(), m.previousState.String())
-	m.state = m.previousState
+	prev, ok := m.history.Back()
+	if !ok {
+		prev = SettingsStateMainMenu
+	}
+	m.logger.LogStateTransition("SettingsModel", m.state.String(), prev.String())
+	m.state = prev
 	m.layout = m.layout.ClearError()
 	return m
 }
 
+// Breadcrumbs returns the steps taken from the repository list to the current
+// state, shown by MainModel in the status bar
+func (m *SettingsModel) Breadcrumbs() []string {
+	var crumbs []string
+	for _, state := range append(m.history.States(), m.state) {
+		if state != SettingsStateMainMenu {
+			crumbs = append(crumbs, navigation.Label(state.String()))
+		}
+	}
+	return crumbs
+}
+
 // resetTemporaryChanges clears all pending changes.
 // Called when user cancels or discards changes.
 func (m *SettingsModel) resetTemporaryChanges() {
@@ -540,6 +738,7 @@ func (m *SettingsModel) resetTemporaryChanges() {
 	m.newGitHubBranch = ""
 	m.newGitHubPath = ""
 	m.newGitHubPAT = "" // Reset for global PAT management
+	m.deviceScopes = nil
 	m.hasChanges = false
 }
 
@@ -685,6 +884,8 @@ func (m *SettingsModel) View() string {
 		return m.viewUpdateGitHubBranch()
 	case SettingsStateEditBranchConfirm:
 		return m.viewEditBranchConfirm()
+	case SettingsStateEditBranchInProgress:
+		return m.viewEditBranchInProgress()
 	case SettingsStateEditBranchError:
 		return m.viewEditBranchError()
 	case SettingsStateUpdateRepoName:
@@ -699,12 +900,46 @@ func (m *SettingsModel) View() string {
 		return m.viewUpdatePATConfirm()
 	case SettingsStateUpdatePATError:
 		return m.viewUpdatePATError()
+	case SettingsStateDeviceLogin:
+		return m.viewDeviceLogin()
+	case SettingsStateRelocateStorageInput:
+		return m.viewRelocateStorageInput()
+	case SettingsStateRelocateStorageConfirm:
+		return m.viewRelocateStorageConfirm()
+	case SettingsStateRelocateStorageInProgress:
+		return m.viewRelocateStorageInProgress()
+	case SettingsStateRelocateStorageError:
+		return m.viewRelocateStorageError()
 	case SettingsStateManualRefresh:
 		return m.viewManualRefresh()
 	case SettingsStateRefreshInProgress:
 		return m.viewRefreshInProgress()
 	case SettingsStateRefreshError:
 		return m.viewRefreshError()
+	case SettingsStateResolveChanges:
+		return m.viewResolveChanges()
+	case SettingsStateDiscardConfirm:
+		return m.viewDiscardConfirm()
+	case SettingsStateResolveConflict:
+		return m.viewResolveConflict()
+	case SettingsStateResolveConflictInProgress:
+		return m.viewResolveConflictInProgress()
+	case SettingsStateResolveConflictComplete:
+		return m.viewResolveConflictComplete()
+	case SettingsStateCommitBrowser:
+		return m.viewCommitBrowser()
+	case SettingsStateCommitCheckoutConfirm:
+		return m.viewCommitCheckoutConfirm()
+	case SettingsStateMaintenanceInProgress:
+		return m.viewMaintenanceInProgress()
+	case SettingsStateMaintenanceComplete:
+		return m.viewMaintenanceComplete()
+	case SettingsStatePublishChanges:
+		return m.viewPublishChanges()
+	case SettingsStatePublishInProgress:
+		return m.viewPublishInProgress()
+	case SettingsStatePublishComplete:
+		return m.viewPublishComplete()
 	case SettingsStateAddRepositoryType:
 		return m.viewAddRepositoryType()
 	case SettingsStateAddLocalName:
@@ -713,18 +948,18 @@ func (m *SettingsModel) View() string {
 		return m.viewAddLocalPath()
 	case SettingsStateAddLocalError:
 		return m.viewAddLocalError()
-	case SettingsStateAddGitHubName:
-		return m.viewAddGitHubName()
-	case SettingsStateAddGitHubURL:
-		return m.viewAddGitHubURL()
-	case SettingsStateAddGitHubBranch:
-		return m.viewAddGitHubBranch()
-	case SettingsStateAddGitHubPath:
-		return m.viewAddGitHubPath()
+	case SettingsStateAddGitHubForm:
+		return m.viewAddGitHubForm()
 	case SettingsStateAddGitHubPAT:
 		return m.viewAddGitHubPAT()
 	case SettingsStateAddGitHubError:
 		return m.viewAddGitHubError()
+	case SettingsStateAddTemplateForm:
+		return m.viewAddTemplateForm()
+	case SettingsStateAddTemplateInProgress:
+		return m.viewAddTemplateInProgress()
+	case SettingsStateAddTemplateError:
+		return m.viewAddTemplateError()
 	case SettingsStateComplete:
 		return m.viewComplete()
 	}
@@ -8,6 +8,7 @@ import (
 	"rulem/internal/logging"
 	"rulem/internal/repository"
 	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
 	"slices"
 	"testing"
 
@@ -51,8 +52,7 @@ func TestSettingsMenuBasicFlow(t *testing.T) {
 	// Load config
 	testConfig := createLocalConfig("/test/path")
 	configMsg := config.LoadConfigMsg{Config: testConfig, Error: nil}
-	updatedModel, _ := model.Update(configMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, configMsg)
 
 	if model.currentConfig == nil {
 		t.Error("Config should be loaded")
@@ -60,8 +60,7 @@ func TestSettingsMenuBasicFlow(t *testing.T) {
 
 	// Navigate to select change menu
 	enterMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, _ = model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	if model.state != SettingsStateRepositoryActions {
 		t.Errorf("Expected SelectChange state after Enter from MainMenu, got %v", model.state)
@@ -69,8 +68,7 @@ func TestSettingsMenuBasicFlow(t *testing.T) {
 
 	// Test navigation back to main menu
 	escMsg := tea.KeyMsg{Type: tea.KeyEsc}
-	updatedModel, _ = model.Update(escMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, escMsg)
 
 	if model.state != SettingsStateMainMenu {
 		t.Errorf("Expected MainMenu state after Esc from SelectChange, got %v", model.state)
@@ -109,8 +107,7 @@ func TestCompleteFlow_GitHubBranchUpdate_Standalone(t *testing.T) {
 
 	model.selectedRepositoryActionOption = branchIndex
 	enterMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, _ := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	if model.state != SettingsStateUpdateGitHubBranch {
 		t.Fatalf("Expected UpdateGitHubBranch state, got %v", model.state)
@@ -122,8 +119,7 @@ func TestCompleteFlow_GitHubBranchUpdate_Standalone(t *testing.T) {
 
 	// Enter new branch
 	model.textInput.SetValue("develop")
-	updatedModel, cmd := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model, cmd := tuitest.SendCmd(t, model, enterMsg)
 
 	// Should return dirty check command
 	if cmd == nil {
@@ -143,8 +139,7 @@ func TestCompleteFlow_GitHubBranchUpdate_EmptyBranch(t *testing.T) {
 	// Enter empty branch (should use default)
 	model.textInput.SetValue("")
 	enterMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, cmd := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model, cmd := tuitest.SendCmd(t, model, enterMsg)
 
 	// Should still proceed with dirty check
 	if cmd == nil {
@@ -182,8 +177,7 @@ func TestCompleteFlow_GitHubPathUpdate_Standalone(t *testing.T) {
 
 	model.selectedRepositoryActionOption = pathIndex
 	enterMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, _ := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	if model.state != SettingsStateUpdateGitHubPath {
 		t.Fatalf("Expected UpdateGitHubPath state, got %v", model.state)
@@ -191,8 +185,7 @@ func TestCompleteFlow_GitHubPathUpdate_Standalone(t *testing.T) {
 
 	// Enter new path
 	model.textInput.SetValue(testPath)
-	updatedModel, _ = model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	// Should go to confirmation
 	if model.state != SettingsStateEditClonePathConfirm {
@@ -231,8 +224,7 @@ func TestCompleteFlow_GitHubPATUpdate(t *testing.T) {
 
 	model.selectedRepositoryActionOption = patIndex
 	enterMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, _ := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	if model.state != SettingsStateUpdateGitHubPAT {
 		t.Fatalf("Expected UpdateGitHubPAT state, got %v", model.state)
@@ -240,14 +232,12 @@ func TestCompleteFlow_GitHubPATUpdate(t *testing.T) {
 
 	// Enter new PAT value
 	model.textInput.SetValue("ghp_newtoken456")
-	updatedModel, cmd := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model, cmd := tuitest.SendCmd(t, model, enterMsg)
 
 	// Execute any validation command if returned
 	if cmd != nil {
 		msg := cmd()
-		updatedModel, _ = model.Update(msg)
-		model = updatedModel.(*SettingsModel)
+		model = tuitest.Send(t, model, msg)
 	}
 
 	// After entering PAT, should validate and transition
@@ -318,8 +308,7 @@ func TestCompleteFlow_ManualRefresh(t *testing.T) {
 
 	model.selectedRepositoryActionOption = refreshIndex
 	enterMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, _ := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	if model.state != SettingsStateManualRefresh {
 		t.Fatalf("Expected ManualRefresh state, got %v", model.state)
@@ -327,8 +316,7 @@ func TestCompleteFlow_ManualRefresh(t *testing.T) {
 
 	// Confirm refresh
 	yMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}
-	updatedModel, cmd := model.Update(yMsg)
-	model = updatedModel.(*SettingsModel)
+	model, cmd := tuitest.SendCmd(t, model, yMsg)
 
 	if cmd == nil {
 		t.Fatal("Expected command from refresh trigger (dirty check)")
@@ -337,8 +325,8 @@ func TestCompleteFlow_ManualRefresh(t *testing.T) {
 	// Execute the dirty check command
 	msg := cmd()
 	if msg != nil {
-		updatedModel, cmd2 := model.Update(msg)
-		model = updatedModel.(*SettingsModel)
+		var cmd2 tea.Cmd
+		model, cmd2 = tuitest.SendCmd(t, model, msg)
 
 		// After dirty check, verify state
 		if model.state == SettingsStateRefreshError || model.state == SettingsStateManualRefresh {
@@ -357,8 +345,7 @@ func TestCompleteFlow_ManualRefresh(t *testing.T) {
 		// Execute the actual refresh command if returned
 		if cmd2 != nil {
 			msg2 := cmd2()
-			updatedModel, _ = model.Update(msg2)
-			model = updatedModel.(*SettingsModel)
+			model = tuitest.Send(t, model, msg2)
 		}
 	}
 
@@ -392,8 +379,7 @@ func TestCompleteFlow_ManualRefresh_Cancel(t *testing.T) {
 
 	// Decline refresh
 	nMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}
-	updatedModel, _ := model.Update(nMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, nMsg)
 
 	// Should return to SelectChange
 	if model.state != SettingsStateRepositoryActions {
@@ -416,8 +402,7 @@ func TestCompleteFlow_ManualRefresh_WithDirtyRepository(t *testing.T) {
 
 	// Confirm refresh
 	yMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}
-	updatedModel, cmd := model.Update(yMsg)
-	model = updatedModel.(*SettingsModel)
+	model, cmd := tuitest.SendCmd(t, model, yMsg)
 
 	if cmd == nil {
 		t.Fatal("Expected dirty check command")
@@ -425,8 +410,7 @@ func TestCompleteFlow_ManualRefresh_WithDirtyRepository(t *testing.T) {
 
 	// Simulate dirty state message
 	refreshDirtyMsg := refreshDirtyStateMsg{isDirty: true, err: nil}
-	updatedModel, _ = model.Update(refreshDirtyMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, refreshDirtyMsg)
 
 	// Should transition to error state when dirty
 	if model.state != SettingsStateRefreshError {
@@ -505,8 +489,7 @@ func TestBackNavigation(t *testing.T) {
 				tt.setupFunc(model)
 			}
 
-			updatedModel, _ := model.Update(escMsg)
-			model = updatedModel.(*SettingsModel)
+			model = tuitest.Send(t, model, escMsg)
 
 			if model.state != tt.expectedState {
 				t.Errorf("Expected state %v after Esc from %v (changeType=%v), got %v",
@@ -541,8 +524,7 @@ func TestBackNavigation_FromSelectChange_BackOption(t *testing.T) {
 	// Select back option
 	model.selectedRepositoryActionOption = backIndex
 	enterMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, _ := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	// Should return to main menu
 	if model.state != SettingsStateMainMenu {
@@ -875,17 +857,14 @@ func TestComplexScenario_MultipleChangesAndCancellations(t *testing.T) {
 	escMsg := tea.KeyMsg{Type: tea.KeyEsc}
 
 	// Navigate to select change
-	updatedModel, _ := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	// Try to change local path
 	model.selectedRepositoryActionOption = 1
-	updatedModel, _ = model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	// Cancel
-	updatedModel, _ = model.Update(escMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, escMsg)
 
 	if model.state != SettingsStateRepositoryActions {
 		t.Fatalf("Expected SelectChange after cancel, got %v", model.state)
@@ -893,8 +872,7 @@ func TestComplexScenario_MultipleChangesAndCancellations(t *testing.T) {
 
 	// Try to change repository type
 	model.selectedRepositoryActionOption = 0
-	updatedModel, _ = model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	// State should remain at RepositoryActions since type change is deprecated
 	if model.state != SettingsStateRepositoryActions {
@@ -903,8 +881,7 @@ func TestComplexScenario_MultipleChangesAndCancellations(t *testing.T) {
 
 	// Cancel type change
 	noMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}
-	updatedModel, _ = model.Update(noMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, noMsg)
 
 	if model.state != SettingsStateRepositoryActions {
 		t.Errorf("Expected SelectChange after rejecting type change, got %v", model.state)
@@ -926,23 +903,20 @@ func TestComplexScenario_GitHubFullFlow(t *testing.T) {
 	enterMsg := tea.KeyMsg{Type: tea.KeyEnter}
 
 	// Navigate to select change
-	updatedModel, _ := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	// Update GitHub URL (which triggers full flow: URL -> Branch -> Path)
 	// GitHub URL option no longer exists - skip this test
 	t.Skip("GitHub URL editing deprecated in Phase 1.2")
 
-	updatedModel, _ = model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	// URL editing removed - skip this check
 	t.Skip("GitHub URL editing deprecated in Phase 1.2")
 
 	// Enter URL
 	model.textInput.SetValue("https://github.com/new/repo.git")
-	updatedModel, _ = model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model = tuitest.Send(t, model, enterMsg)
 
 	// Should be at branch
 	if model.state != SettingsStateUpdateGitHubBranch {
@@ -951,14 +925,12 @@ func TestComplexScenario_GitHubFullFlow(t *testing.T) {
 
 	// Enter branch
 	model.textInput.SetValue("develop")
-	updatedModel, cmd := model.Update(enterMsg)
-	model = updatedModel.(*SettingsModel)
+	model, cmd := tuitest.SendCmd(t, model, enterMsg)
 
 	// Execute dirty check
 	if cmd != nil {
 		msg := cmd()
-		updatedModel, _ = model.Update(msg)
-		model = updatedModel.(*SettingsModel)
+		model = tuitest.Send(t, model, msg)
 	}
 
 	// Verify that URL, branch, and changeType were properly set
@@ -989,8 +961,7 @@ func TestEdgeCase_RapidStateTransitions(t *testing.T) {
 
 	// Rapid transitions
 	for range 5 {
-		updatedModel, _ := model.Update(enterMsg)
-		model = updatedModel.(*SettingsModel)
+		model = tuitest.Send(t, model, enterMsg)
 	}
 
 	// Should not panic and should be in a valid state
@@ -1090,28 +1061,10 @@ func TestCrossFlow_BackNavigationFromAddFlows(t *testing.T) {
 			keyHandler:    (*SettingsModel).handleAddLocalPathKeys,
 		},
 		{
-			name:          "From AddGitHubName",
-			startState:    SettingsStateAddGitHubName,
+			name:          "From AddGitHubForm",
+			startState:    SettingsStateAddGitHubForm,
 			expectedState: SettingsStateAddRepositoryType,
-			keyHandler:    (*SettingsModel).handleAddGitHubNameKeys,
-		},
-		{
-			name:          "From AddGitHubURL",
-			startState:    SettingsStateAddGitHubURL,
-			expectedState: SettingsStateAddGitHubName,
-			keyHandler:    (*SettingsModel).handleAddGitHubURLKeys,
-		},
-		{
-			name:          "From AddGitHubBranch",
-			startState:    SettingsStateAddGitHubBranch,
-			expectedState: SettingsStateAddGitHubURL,
-			keyHandler:    (*SettingsModel).handleAddGitHubBranchKeys,
-		},
-		{
-			name:          "From AddGitHubPath",
-			startState:    SettingsStateAddGitHubPath,
-			expectedState: SettingsStateAddGitHubBranch,
-			keyHandler:    (*SettingsModel).handleAddGitHubPathKeys,
+			keyHandler:    (*SettingsModel).handleAddGitHubFormKeys,
 		},
 	}
 
@@ -1255,18 +1208,27 @@ func TestCrossFlow_StateIsolationBetweenFlows(t *testing.T) {
 	m.addRepositoryTypeIndex = 1
 	m, _ = m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEnter})
 
-	// Verify we start fresh at AddGitHubName
-	if m.state != SettingsStateAddGitHubName {
-		t.Errorf("expected AddGitHubName state, got %v", m.state)
+	// Verify we start fresh at AddGitHubForm
+	if m.state != SettingsStateAddGitHubForm {
+		t.Errorf("expected AddGitHubForm state, got %v", m.state)
 	}
 
 	// Set GitHub values
-	m.textInput.SetValue("GitHub Repo")
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	m.addGitHubForm = m.addGitHubForm.SetValue(gitHubFieldName, "GitHub Repo")
+	m, _ = m.handleAddGitHubFormKeys(tea.KeyMsg{Type: tea.KeyEnter})
 
 	// Cancel GitHub flow
-	m, _ = m.handleAddGitHubURLKeys(tea.KeyMsg{Type: tea.KeyEsc})
-	m, _ = m.handleAddGitHubNameKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = m.handleAddGitHubFormKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEsc})
+
+	// Re-entering the GitHub flow starts with an empty form
+	m.state = SettingsStateAddRepositoryType
+	m.addRepositoryTypeIndex = 1
+	m, _ = m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.addGitHubForm.Value(gitHubFieldName); got != "" {
+		t.Errorf("expected empty GitHub form, got name %q", got)
+	}
+	m, _ = m.handleAddGitHubFormKeys(tea.KeyMsg{Type: tea.KeyEsc})
 	m, _ = m.handleAddRepositoryTypeKeys(tea.KeyMsg{Type: tea.KeyEsc})
 
 	// Start Add Local flow again
@@ -1406,9 +1368,7 @@ func TestEdgeCase_EmptyInputSubmission(t *testing.T) {
 	}{
 		{"Empty Name", SettingsStateAddLocalName},
 		{"Empty Path", SettingsStateAddLocalPath},
-		{"Empty GitHub Name", SettingsStateAddGitHubName},
-		{"Empty GitHub URL", SettingsStateAddGitHubURL},
-		{"Empty GitHub Path", SettingsStateAddGitHubPath},
+		{"Empty GitHub Form", SettingsStateAddGitHubForm},
 	}
 
 	for _, tc := range testCases {
@@ -9,6 +9,7 @@ import (
 	"rulem/internal/logging"
 	"rulem/internal/repository"
 	"rulem/internal/tui/helpers"
+	"slices"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -134,8 +135,8 @@ func TestTransitionTo(t *testing.T) {
 		t.Errorf("Expected state to be SettingsStateRepositoryActions, got %v", updatedModel.state)
 	}
 
-	if updatedModel.previousState != SettingsStateMainMenu {
-		t.Errorf("Expected previousState to be SettingsStateMainMenu, got %v", updatedModel.previousState)
+	if prev, _ := updatedModel.history.Previous(); prev != SettingsStateMainMenu {
+		t.Errorf("Expected previous state to be SettingsStateMainMenu, got %v", prev)
 	}
 
 	if updatedModel.selectedRepositoryActionOption != 0 {
@@ -145,14 +146,47 @@ func TestTransitionTo(t *testing.T) {
 
 func TestTransitionBack(t *testing.T) {
 	model := createTestModel(t)
-	model.state = SettingsStateRepositoryActions
-	model.previousState = SettingsStateMainMenu
+	model.state = SettingsStateMainMenu
+	model.transitionTo(SettingsStateRepositoryActions)
+	model.transitionTo(SettingsStateUpdateRepoName)
 
 	updatedModel := model.transitionBack()
+	if updatedModel.state != SettingsStateRepositoryActions {
+		t.Errorf("Expected state to be SettingsStateRepositoryActions, got %v", updatedModel.state)
+	}
 
+	updatedModel = updatedModel.transitionBack()
 	if updatedModel.state != SettingsStateMainMenu {
 		t.Errorf("Expected state to be SettingsStateMainMenu, got %v", updatedModel.state)
 	}
+
+	// With no history left, back stays on the main menu
+	updatedModel = updatedModel.transitionBack()
+	if updatedModel.state != SettingsStateMainMenu {
+		t.Errorf("Expected state to stay SettingsStateMainMenu, got %v", updatedModel.state)
+	}
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	model := createTestModel(t)
+	model.state = SettingsStateMainMenu
+	if crumbs := model.Breadcrumbs(); len(crumbs) != 0 {
+		t.Errorf("Expected no breadcrumbs on the repository list, got %v", crumbs)
+	}
+
+	model.transitionTo(SettingsStateRepositoryActions)
+	model.transitionTo(SettingsStateUpdateGitHubBranch)
+	model.transitionTo(SettingsStateEditBranchConfirm)
+	want := []string{"Repository actions", "Update GitHub branch", "Edit branch confirm"}
+	if crumbs := model.Breadcrumbs(); !slices.Equal(crumbs, want) {
+		t.Errorf("Breadcrumbs() = %v, want %v", crumbs, want)
+	}
+
+	// Cancelling the confirmation back to the actions drops the later steps
+	model.transitionTo(SettingsStateRepositoryActions)
+	if crumbs := model.Breadcrumbs(); !slices.Equal(crumbs, want[:1]) {
+		t.Errorf("Breadcrumbs() after cancelling = %v, want %v", crumbs, want[:1])
+	}
 }
 
 func TestResetTemporaryChanges(t *testing.T) {
@@ -257,18 +291,20 @@ func TestGetMenuOptions_GitHubRepo(t *testing.T) {
 
 	options := model.getMenuOptions()
 
-	// GitHub repo should have: Branch, Path, Change Name, Manual Refresh, Delete (if >1 repo), Back
-	// Since we only have 1 repo, expect 5 options (no delete)
-	if len(options) != 5 {
-		t.Errorf("Expected 5 options for single GitHub repo, got %d", len(options))
+	// GitHub repo should have: Branch, Path, Manual Refresh, Publish Changes, Browse Commits, Maintenance, Change Name, Delete (if >1 repo), Back
+	// Since we only have 1 repo, expect 8 options (no delete)
+	if len(options) != 8 {
+		t.Errorf("Expected 8 options for single GitHub repo, got %d", len(options))
 	}
 
 	// Verify all GitHub options are present
-	// GitHub repo should have: Branch, Path, Change Name, Manual Refresh, Delete (if >1 repo), Back
 	hasBranch := false
 	hasPath := false
 	hasChangeName := false
 	hasRefresh := false
+	hasCommits := false
+	hasMaintenance := false
+	hasPublish := false
 
 	for _, opt := range options {
 		switch opt.Option {
@@ -280,6 +316,12 @@ func TestGetMenuOptions_GitHubRepo(t *testing.T) {
 			hasChangeName = true
 		case ChangeOptionManualRefresh:
 			hasRefresh = true
+		case ChangeOptionBrowseCommits:
+			hasCommits = true
+		case ChangeOptionMaintenance:
+			hasMaintenance = true
+		case ChangeOptionPublishChanges:
+			hasPublish = true
 		}
 	}
 	if !hasBranch {
@@ -294,6 +336,15 @@ func TestGetMenuOptions_GitHubRepo(t *testing.T) {
 	if !hasRefresh {
 		t.Error("GitHub repo should have Manual Refresh option")
 	}
+	if !hasCommits {
+		t.Error("GitHub repo should have Browse Commits option")
+	}
+	if !hasMaintenance {
+		t.Error("GitHub repo should have Run Maintenance option")
+	}
+	if !hasPublish {
+		t.Error("GitHub repo should have Publish Local Changes option")
+	}
 }
 
 // Phase 2: Repository Type Switching Tests
@@ -1072,8 +1123,8 @@ func TestStateTransitionConsistency(t *testing.T) {
 				t.Errorf("Expected state %v, got %v", tt.newState, updatedModel.state)
 			}
 
-			if updatedModel.previousState != previousState {
-				t.Errorf("Expected previousState %v, got %v", previousState, updatedModel.previousState)
+			if prev, _ := updatedModel.history.Previous(); prev != previousState {
+				t.Errorf("Expected previous state %v, got %v", previousState, prev)
 			}
 		})
 	}
@@ -1434,10 +1485,10 @@ func TestLoadConfigMsg_ReloadsRepositories(t *testing.T) {
 	}
 
 	// Verify repository list was rebuilt with items
-	// The list should contain the 2 repositories plus 2 action items ("Add Repository" and "Update PAT")
+	// The list should contain the 2 repositories plus 3 action items ("Add Repository", "Update PAT" and "Move Storage")
 	items := settingsModel.repoList.Items()
-	if len(items) != 4 { // 2 repos + 2 action items
-		t.Errorf("Expected 4 items in repository list (2 repos + 2 actions), got %d", len(items))
+	if len(items) != 5 { // 2 repos + 3 action items
+		t.Errorf("Expected 5 items in repository list (2 repos + 3 actions), got %d", len(items))
 	}
 }
 
@@ -1550,9 +1601,9 @@ func TestNewSettingsModel_LoadsExistingRepositories(t *testing.T) {
 		t.Errorf("Expected repository name 'Existing Repository', got %q", model.preparedRepos[0].Name())
 	}
 
-	// Verify repository list contains the repository + 2 action items
+	// Verify repository list contains the repository + 3 action items
 	items := model.repoList.Items()
-	if len(items) != 3 { // 1 repo + 2 action items
-		t.Errorf("Expected 3 items in repository list, got %d", len(items))
+	if len(items) != 4 { // 1 repo + 3 action items
+		t.Errorf("Expected 4 items in repository list, got %d", len(items))
 	}
 }
@@ -19,10 +19,17 @@ func createBareRemoteRepo(t *testing.T) string {
 	t.Helper()
 
 	remotePath := t.TempDir()
-	if _, err := git.PlainInit(remotePath, true); err != nil {
+	repo, err := git.PlainInit(remotePath, true)
+	if err != nil {
 		t.Fatalf("failed to init bare repo: %v", err)
 	}
 
+	// Clones check out the remote HEAD, which must match the pushed branch
+	head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main"))
+	if err := repo.Storer.SetReference(head); err != nil {
+		t.Fatalf("failed to set bare repo HEAD: %v", err)
+	}
+
 	return remotePath
 }
 
@@ -44,11 +51,11 @@ func createLocalRepoWithOrigin(t *testing.T, originPath string) string {
 		t.Fatalf("failed to get worktree: %v", err)
 	}
 
-	if err := worktree.Checkout(&git.CheckoutOptions{
-		Branch: plumbing.NewBranchReferenceName("main"),
-		Create: true,
-	}); err != nil {
-		t.Fatalf("failed to checkout main branch: %v", err)
+	// The branch does not exist before the first commit, so point HEAD at it
+	// instead of checking it out
+	head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main"))
+	if err := repo.Storer.SetReference(head); err != nil {
+		t.Fatalf("failed to set HEAD to main branch: %v", err)
 	}
 
 	filePath := filepath.Join(repoPath, "README.md")
@@ -183,3 +190,44 @@ func addUncommittedChange(t *testing.T, repoPath string) {
 		t.Fatalf("failed to write dirty file: %v", err)
 	}
 }
+
+// commitToRepo commits a file directly in the repo at repoPath.
+func commitToRepo(t *testing.T, repoPath, name, content string) {
+	t.Helper()
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatalf("failed to add %s: %v", name, err)
+	}
+	if _, err := worktree.Commit("add "+name, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit %s: %v", name, err)
+	}
+}
+
+// originURL returns the origin remote URL of the repository at repoPath, so
+// a config can point at the same remote as a clone made by createOriginAndClone.
+func originURL(t *testing.T, repoPath string) string {
+	t.Helper()
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		t.Fatalf("failed to get origin remote: %v", err)
+	}
+	return remote.Config().URLs[0]
+}
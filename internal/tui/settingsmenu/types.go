@@ -1,6 +1,13 @@
 // Package settingsmenu provides the settings modification flow for the rulem TUI application.
 package settingsmenu
 
+import (
+	"context"
+	"rulem/internal/repository"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
 // State Definitions
 // Architecture: Mutually Exclusive States
 // Each flow has its own dedicated states to prevent state pollution and ensure
@@ -31,23 +38,27 @@ const (
 	// SettingsStateAddLocalError displays error during local repository creation
 	SettingsStateAddLocalError
 
-	// Add GitHub Repository Flow (5 states)
-	// Flow: AddRepositoryType → AddGitHubName → AddGitHubURL → AddGitHubBranch → AddGitHubPath → [AddGitHubError | Complete]
-
-	// SettingsStateAddGitHubName prompts for the GitHub repository name
-	SettingsStateAddGitHubName
-	// SettingsStateAddGitHubURL prompts for the GitHub repository URL
-	SettingsStateAddGitHubURL
-	// SettingsStateAddGitHubBranch prompts for the GitHub branch to track
-	SettingsStateAddGitHubBranch
-	// SettingsStateAddGitHubPath prompts for the local clone directory path
-	SettingsStateAddGitHubPath
+	// Add GitHub Repository Flow (3 states)
+	// Flow: AddRepositoryType → AddGitHubForm → [AddGitHubPAT] → [AddGitHubError | Complete]
+
+	// SettingsStateAddGitHubForm collects name, URL, branch and clone path on one form
+	SettingsStateAddGitHubForm
 	// SettingsStateAddGitHubPAT prompts for GitHub PAT when none exists (optional state)
 	// This is an optional flow state - only entered when PAT is missing during Add GitHub flow
 	SettingsStateAddGitHubPAT
 	// SettingsStateAddGitHubError displays error during GitHub repository creation
 	SettingsStateAddGitHubError
 
+	// Create From Template Flow (3 states)
+	// Flow: AddRepositoryType → AddTemplateForm → AddTemplateInProgress → [AddTemplateError | Complete]
+
+	// SettingsStateAddTemplateForm collects the template, new repository and placeholder values on one form
+	SettingsStateAddTemplateForm
+	// SettingsStateAddTemplateInProgress shows progress while the template is copied to the new repository
+	SettingsStateAddTemplateInProgress
+	// SettingsStateAddTemplateError displays error during creation from a template
+	SettingsStateAddTemplateError
+
 	// Delete Repository Flow (3 states)
 	// Flow: RepositoryActions → ConfirmDelete → [DeleteError | Complete]
 
@@ -58,13 +69,15 @@ const (
 	// SettingsStateDeleteError displays error during repository deletion
 	SettingsStateDeleteError
 
-	// Edit Branch Flow (3 states)
-	// Flow: UpdateGitHubBranch → EditBranchConfirm → [EditBranchError | Complete]
+	// Edit Branch Flow (4 states)
+	// Flow: UpdateGitHubBranch → EditBranchConfirm → EditBranchInProgress → [EditBranchError | Complete]
 
 	// SettingsStateUpdateGitHubBranch prompts for new GitHub branch name
 	SettingsStateUpdateGitHubBranch
 	// SettingsStateEditBranchConfirm displays confirmation for branch change
 	SettingsStateEditBranchConfirm
+	// SettingsStateEditBranchInProgress shows the fetch/verify/checkout steps of a branch switch
+	SettingsStateEditBranchInProgress
 	// SettingsStateEditBranchError displays error during branch update
 	SettingsStateEditBranchError
 
@@ -89,8 +102,10 @@ const (
 	// SettingsStateEditNameError displays error during name update
 	SettingsStateEditNameError
 
-	// Manual Refresh Flow (3 states)
+	// Manual Refresh Flow (8 states)
 	// Flow: ManualRefresh → RefreshInProgress → [RefreshError | Complete]
+	// Dirty: RefreshError → ResolveChanges → [DiscardConfirm | RefreshInProgress]
+	// Conflict: RefreshInProgress → ResolveConflict → ResolveConflictInProgress → ResolveConflictComplete
 
 	// SettingsStateManualRefresh prompts for confirmation before refreshing from GitHub
 	SettingsStateManualRefresh
@@ -98,9 +113,45 @@ const (
 	SettingsStateRefreshInProgress
 	// SettingsStateRefreshError displays error during manual refresh
 	SettingsStateRefreshError
-
-	// Update PAT Flow (3 states)
-	// Flow: UpdateGitHubPAT → UpdatePATConfirm → [UpdatePATError | Complete]
+	// SettingsStateResolveChanges lists uncommitted changes blocking a refresh
+	SettingsStateResolveChanges
+	// SettingsStateDiscardConfirm prompts for confirmation before discarding selected files
+	SettingsStateDiscardConfirm
+	// SettingsStateResolveConflict offers ways out of a clone directory holding something else
+	SettingsStateResolveConflict
+	// SettingsStateResolveConflictInProgress shows progress while a clone directory conflict is resolved
+	SettingsStateResolveConflictInProgress
+	// SettingsStateResolveConflictComplete displays where the backup went or how to review adopted files
+	SettingsStateResolveConflictComplete
+
+	// Commit Browser Flow (2 states)
+	// Flow: RepositoryActions → CommitBrowser → [CommitCheckoutConfirm → CommitBrowser]
+
+	// SettingsStateCommitBrowser lists recent commits of a GitHub repository
+	SettingsStateCommitBrowser
+	// SettingsStateCommitCheckoutConfirm prompts for confirmation before checking out a commit
+	SettingsStateCommitCheckoutConfirm
+
+	// Maintenance Flow (2 states)
+	// Flow: RepositoryActions → MaintenanceInProgress → MaintenanceComplete
+
+	// SettingsStateMaintenanceInProgress shows progress while a clone is repacked
+	SettingsStateMaintenanceInProgress
+	// SettingsStateMaintenanceComplete displays the maintenance result or error
+	SettingsStateMaintenanceComplete
+
+	// Publish Changes Flow (3 states)
+	// Flow: RepositoryActions → PublishChanges → PublishInProgress → PublishComplete
+
+	// SettingsStatePublishChanges lists the changes to publish and prompts for a commit message
+	SettingsStatePublishChanges
+	// SettingsStatePublishInProgress shows progress while changes are committed and pushed
+	SettingsStatePublishInProgress
+	// SettingsStatePublishComplete displays the pushed commit or error
+	SettingsStatePublishComplete
+
+	// Update PAT Flow (4 states)
+	// Flow: UpdateGitHubPAT → [DeviceLogin] → UpdatePATConfirm → [UpdatePATError | Complete]
 
 	// SettingsStateUpdateGitHubPAT prompts for new GitHub Personal Access Token
 	SettingsStateUpdateGitHubPAT
@@ -108,6 +159,20 @@ const (
 	SettingsStateUpdatePATConfirm
 	// SettingsStateUpdatePATError displays error during PAT update
 	SettingsStateUpdatePATError
+	// SettingsStateDeviceLogin shows the code to enter while signing in with GitHub in the browser
+	SettingsStateDeviceLogin
+
+	// Relocate Storage Flow (4 states)
+	// Flow: RelocateStorageInput → RelocateStorageConfirm → RelocateStorageInProgress → [RelocateStorageError | Complete]
+
+	// SettingsStateRelocateStorageInput prompts for the new storage base directory
+	SettingsStateRelocateStorageInput
+	// SettingsStateRelocateStorageConfirm shows where each repository will move
+	SettingsStateRelocateStorageConfirm
+	// SettingsStateRelocateStorageInProgress shows progress while repositories are copied and verified
+	SettingsStateRelocateStorageInProgress
+	// SettingsStateRelocateStorageError displays error during storage relocation
+	SettingsStateRelocateStorageError
 )
 
 // String returns a human-readable name for the state, useful for debugging and logging.
@@ -130,19 +195,21 @@ func (s SettingsState) String() string {
 		return "AddLocalError"
 
 	// Add GitHub Repository flow
-	case SettingsStateAddGitHubName:
-		return "AddGitHubName"
-	case SettingsStateAddGitHubURL:
-		return "AddGitHubURL"
-	case SettingsStateAddGitHubBranch:
-		return "AddGitHubBranch"
-	case SettingsStateAddGitHubPath:
-		return "AddGitHubPath"
+	case SettingsStateAddGitHubForm:
+		return "AddGitHubForm"
 	case SettingsStateAddGitHubPAT:
 		return "AddGitHubPAT"
 	case SettingsStateAddGitHubError:
 		return "AddGitHubError"
 
+	// Create From Template flow
+	case SettingsStateAddTemplateForm:
+		return "AddTemplateForm"
+	case SettingsStateAddTemplateInProgress:
+		return "AddTemplateInProgress"
+	case SettingsStateAddTemplateError:
+		return "AddTemplateError"
+
 	// Delete Repository flow
 	case SettingsStateRepositoryActions:
 		return "RepositoryActions"
@@ -156,6 +223,8 @@ func (s SettingsState) String() string {
 		return "UpdateGitHubBranch"
 	case SettingsStateEditBranchConfirm:
 		return "EditBranchConfirm"
+	case SettingsStateEditBranchInProgress:
+		return "EditBranchInProgress"
 	case SettingsStateEditBranchError:
 		return "EditBranchError"
 
@@ -182,6 +251,32 @@ func (s SettingsState) String() string {
 		return "RefreshInProgress"
 	case SettingsStateRefreshError:
 		return "RefreshError"
+	case SettingsStateResolveChanges:
+		return "ResolveChanges"
+	case SettingsStateDiscardConfirm:
+		return "DiscardConfirm"
+	case SettingsStateResolveConflict:
+		return "ResolveConflict"
+	case SettingsStateResolveConflictInProgress:
+		return "ResolveConflictInProgress"
+	case SettingsStateResolveConflictComplete:
+		return "ResolveConflictComplete"
+
+	// Commit Browser flow
+	case SettingsStateCommitBrowser:
+		return "CommitBrowser"
+	case SettingsStateCommitCheckoutConfirm:
+		return "CommitCheckoutConfirm"
+	case SettingsStateMaintenanceInProgress:
+		return "MaintenanceInProgress"
+	case SettingsStateMaintenanceComplete:
+		return "MaintenanceComplete"
+	case SettingsStatePublishChanges:
+		return "PublishChanges"
+	case SettingsStatePublishInProgress:
+		return "PublishInProgress"
+	case SettingsStatePublishComplete:
+		return "PublishComplete"
 
 	// Update PAT flow
 	case SettingsStateUpdateGitHubPAT:
@@ -190,6 +285,18 @@ func (s SettingsState) String() string {
 		return "UpdatePATConfirm"
 	case SettingsStateUpdatePATError:
 		return "UpdatePATError"
+	case SettingsStateDeviceLogin:
+		return "DeviceLogin"
+
+	// Relocate Storage flow
+	case SettingsStateRelocateStorageInput:
+		return "RelocateStorageInput"
+	case SettingsStateRelocateStorageConfirm:
+		return "RelocateStorageConfirm"
+	case SettingsStateRelocateStorageInProgress:
+		return "RelocateStorageInProgress"
+	case SettingsStateRelocateStorageError:
+		return "RelocateStorageError"
 
 	default:
 		return "Unknown"
@@ -230,6 +337,24 @@ type refreshDirtyStateMsg struct {
 	err     error // error from dirty state check, if any
 }
 
+// changedFilesMsg carries the uncommitted changes of the selected repository
+// for the resolve changes screen.
+type changedFilesMsg struct {
+	files []repository.FileChange
+	err   error
+}
+
+// discardCompleteMsg signals completion of discarding selected files.
+// On success the changed files are reloaded.
+type discardCompleteMsg struct{ err error }
+
+// stashSyncCompleteMsg signals completion of a stash, refresh and restore.
+// conflicts lists files changed both locally and upstream.
+type stashSyncCompleteMsg struct {
+	conflicts []string
+	err       error
+}
+
 // editClonePathDirtyStateMsg reports dirty state check result for clone path editing flow.
 // If isDirty=true, transitions to SettingsStateEditClonePathError.
 // If isDirty=false, proceeds to SettingsStateEditClonePathConfirm.
@@ -249,10 +374,57 @@ type addLocalErrorMsg struct{ err error }
 // Transitions to SettingsStateAddGitHubError.
 type addGitHubErrorMsg struct{ err error }
 
+// addTemplateErrorMsg signals an error while creating a repository from a template.
+// Transitions to SettingsStateAddTemplateError.
+type addTemplateErrorMsg struct{ err error }
+
 // deleteErrorMsg signals an error during repository deletion.
 // Transitions to SettingsStateDeleteError.
 type deleteErrorMsg struct{ err error }
 
+// branchSwitchProgressMsg reports that a branch switch started a new step.
+// updates carries the remaining messages of the switch, ending with
+// settingsCompleteMsg or editBranchErrorMsg.
+type branchSwitchProgressMsg struct {
+	step    repository.BranchSwitchStep
+	updates <-chan tea.Msg
+}
+
+// conflictResolvedMsg carries the outcome of resolving a clone directory
+// conflict. backup is set when the directory was backed up and re-cloned, or
+// to the reference keeping the old tip of a branch moved by adopting it.
+type conflictResolvedMsg struct {
+	resolution repository.ConflictResolution
+	backup     string
+	err        error
+}
+
+// commitsLoadedMsg carries the recent commits of the selected repository and,
+// when a commit is checked out for inspection, the inspection state.
+type commitsLoadedMsg struct {
+	commits    []repository.CommitInfo
+	inspection *repository.Inspection
+	err        error
+}
+
+// commitCheckoutCompleteMsg signals that checking out a commit, or returning
+// to the branch, finished. Returns to SettingsStateCommitBrowser.
+type commitCheckoutCompleteMsg struct{ err error }
+
+// maintenanceCompleteMsg carries the outcome of repacking the selected
+// repository. Transitions to SettingsStateMaintenanceComplete.
+type maintenanceCompleteMsg struct {
+	result repository.MaintenanceResult
+	err    error
+}
+
+// publishCompleteMsg carries the outcome of committing and pushing the local
+// changes of the selected repository. Transitions to SettingsStatePublishComplete.
+type publishCompleteMsg struct {
+	result repository.PushResult
+	err    error
+}
+
 // editBranchErrorMsg signals an error during branch update.
 // Transitions to SettingsStateEditBranchError.
 type editBranchErrorMsg struct{ err error }
@@ -269,6 +441,34 @@ type editNameErrorMsg struct{ err error }
 // Transitions to SettingsStateUpdatePATError.
 type updatePATErrorMsg struct{ err error }
 
+// deviceCodeMsg carries the code of a browser sign in started from PAT entry.
+// Transitions to SettingsStateDeviceLogin and waits for the user on ctx.
+type deviceCodeMsg struct {
+	flow *repository.DeviceFlow
+	code repository.DeviceCode
+	ctx  context.Context
+}
+
+// deviceTokenMsg carries the token of a completed browser sign in.
+// Transitions to SettingsStateUpdatePATConfirm.
+type deviceTokenMsg struct{ token repository.DeviceToken }
+
+// relocationProgressMsg reports that a storage relocation started a new step.
+// updates carries the remaining messages of the relocation, ending with
+// relocationCompleteMsg or relocateStorageErrorMsg.
+type relocationProgressMsg struct {
+	progress repository.RelocationProgress
+	updates  <-chan tea.Msg
+}
+
+// relocationCompleteMsg signals that all repositories were moved. leftovers
+// lists old paths that could not be removed. Transitions to SettingsStateComplete.
+type relocationCompleteMsg struct{ leftovers []string }
+
+// relocateStorageErrorMsg signals an error during storage relocation.
+// Transitions to SettingsStateRelocateStorageError.
+type relocateStorageErrorMsg struct{ err error }
+
 // addGitHubPATNeededMsg signals that PAT is required to complete GitHub repository creation.
 // This is an optional flow message - only sent when PAT is missing during Add GitHub flow.
 // Transitions to SettingsStateAddGitHubPAT to allow inline PAT entry.
@@ -295,6 +495,14 @@ const (
 	ChangeOptionAddNewRepository
 	// ChangeOptionGitHubPAT updates or removes the GitHub Personal Access Token (global, not per-repo)
 	ChangeOptionGitHubPAT
+	// ChangeOptionBrowseCommits opens the commit history of a GitHub repository
+	ChangeOptionBrowseCommits
+	// ChangeOptionMaintenance repacks a GitHub clone and prunes unreachable objects
+	ChangeOptionMaintenance
+	// ChangeOptionPublishChanges commits local edits of a GitHub clone and pushes them
+	ChangeOptionPublishChanges
+	// ChangeOptionRelocateStorage moves all repositories to a new base directory (global, not per-repo)
+	ChangeOptionRelocateStorage
 	// ChangeOptionBack returns to the previous menu
 	ChangeOptionBack
 )
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers/textutil"
 )
 
 // === Common View Functions ===
@@ -25,6 +26,15 @@ func (m *SettingsModel) viewComplete() string {
 
 	content := "Your settings have been updated successfully!\n\nThe changes will take effect immediately."
 
+	// A relocation succeeds even when some old directories could not be removed
+	if len(m.relocationLeftovers) > 0 {
+		warning := "\n\n⚠️  These old directories could not be removed and can be deleted manually:"
+		for _, path := range m.relocationLeftovers {
+			warning += "\n• " + textutil.TruncatePath(path, m.layout.ContentWidth()-2)
+		}
+		content += lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500")).Render(warning)
+	}
+
 	return m.layout.Render(content)
 }
 
@@ -57,9 +67,11 @@ func (m *SettingsModel) formatChangesSummary() string {
 			lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00")).Render(m.newGitHubBranch)))
 
 	case ChangeOptionGitHubPath:
+		// Both paths share the line, so each gets half of what is left of it
+		pathWidth := (m.layout.ContentWidth() - lipgloss.Width("  Clone Path:  → ")) / 2
 		summary.WriteString(fmt.Sprintf("  Clone Path: %s → %s\n",
-			lipgloss.NewStyle().Faint(true).Render(selectedRepo.Path),
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00")).Render(m.newGitHubPath)))
+			lipgloss.NewStyle().Faint(true).Render(textutil.TruncatePath(selectedRepo.Path, pathWidth)),
+			lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00")).Render(textutil.TruncatePath(m.newGitHubPath, pathWidth))))
 
 	case ChangeOptionChangeRepoName:
 		summary.WriteString(fmt.Sprintf("  Name: %s → %s\n",
@@ -96,13 +108,13 @@ func (m *SettingsModel) formatCurrentConfig() string {
 
 		// Path
 		content.WriteString(fmt.Sprintf("   Path: %s\n",
-			lipgloss.NewStyle().Faint(true).Render(repo.Path)))
+			lipgloss.NewStyle().Faint(true).Render(textutil.TruncatePath(repo.Path, m.layout.ContentWidth()-lipgloss.Width("   Path: ")))))
 
 		// GitHub-specific details
 		if repo.Type == "github" {
 			if repo.RemoteURL != nil {
 				content.WriteString(fmt.Sprintf("   URL: %s\n",
-					lipgloss.NewStyle().Faint(true).Render(*repo.RemoteURL)))
+					lipgloss.NewStyle().Faint(true).Render(textutil.TruncateURL(*repo.RemoteURL, m.layout.ContentWidth()-lipgloss.Width("   URL: ")))))
 			}
 			if repo.Branch != nil {
 				content.WriteString(fmt.Sprintf("   Branch: %s\n",
@@ -2,18 +2,18 @@ package setupmenu
 
 import (
 	"errors"
-	"os"
+	"fmt"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
 
+	"rulem/internal/config"
 	"rulem/internal/logging"
 	"rulem/internal/repository"
 	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
 
-	"github.com/adrg/xdg"
-	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -52,17 +52,9 @@ func createModelInState(t *testing.T, state SetupState) *SetupModel {
 	switch state {
 	case SetupStateConfirmation, SetupStateComplete:
 		model.StorageDir = "/test/storage/dir"
-	case SetupStateGitHubBranch, SetupStateGitHubPath, SetupStateGitHubPAT:
+	case SetupStateGitHubForm:
 		model.repositoryType = RepositoryTypeGitHub
-		model.GitHubURL = "https://github.com/test/repo.git"
-	}
-
-	if state == SetupStateGitHubPath || state == SetupStateGitHubPAT {
-		model.GitHubBranch = "main"
-	}
-
-	if state == SetupStateGitHubPAT {
-		model.GitHubPath = "~/test-repo"
+		model.githubForm = model.newGitHubForm()
 	}
 
 	return model
@@ -269,8 +261,11 @@ func TestRepositoryTypeSelection(t *testing.T) {
 		if model.repositoryType != RepositoryTypeGitHub {
 			t.Errorf("expected repositoryType %v, got %v", RepositoryTypeGitHub, model.repositoryType)
 		}
-		if model.state != SetupStateGitHubURL {
-			t.Errorf("expected state %v, got %v", SetupStateGitHubURL, model.state)
+		if model.state != SetupStateGitHubForm {
+			t.Errorf("expected state %v, got %v", SetupStateGitHubForm, model.state)
+		}
+		if model.githubForm.Focused() != fieldURL {
+			t.Errorf("expected URL field focused, got %q", model.githubForm.Focused())
 		}
 	})
 }
@@ -335,350 +330,182 @@ func TestLocalStorageInput(t *testing.T) {
 	})
 }
 
-func TestGitHubURLInput(t *testing.T) {
+func TestGitHubFormFieldValidation(t *testing.T) {
 	tests := []struct {
 		name          string
 		url           string
 		shouldError   bool
-		expectedState SetupState
+		expectedFocus string
 	}{
-		{
-			name:          "valid https url",
-			url:           "https://github.com/owner/repo.git",
-			shouldError:   false,
-			expectedState: SetupStateGitHubBranch,
-		},
-		{
-			name:          "valid ssh url",
-			url:           "git@github.com:owner/repo.git",
-			shouldError:   false,
-			expectedState: SetupStateGitHubBranch,
-		},
-		{
-			name:          "valid http url",
-			url:           "http://github.com/owner/repo.git",
-			shouldError:   false,
-			expectedState: SetupStateGitHubBranch,
-		},
-		{
-			name:          "empty url",
-			url:           "",
-			shouldError:   true,
-			expectedState: SetupStateGitHubURL,
-		},
-		{
-			name:          "invalid format",
-			url:           "invalid-url",
-			shouldError:   true,
-			expectedState: SetupStateGitHubURL,
-		},
+		{name: "valid https url", url: "https://github.com/owner/repo.git", expectedFocus: fieldBranch},
+		{name: "valid ssh url", url: "git@github.com:owner/repo.git", expectedFocus: fieldBranch},
+		{name: "valid http url", url: "http://github.com/owner/repo.git", expectedFocus: fieldBranch},
+		{name: "empty url", url: "", shouldError: true, expectedFocus: fieldURL},
+		{name: "invalid format", url: "invalid-url", shouldError: true, expectedFocus: fieldURL},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			model := createModelInState(t, SetupStateGitHubURL)
-			model.textInput.SetValue(tt.url)
+			model := createModelInState(t, SetupStateGitHubForm)
+			model = tuitest.Send(t, model, tuitest.Type(tt.url)...)
+			model = tuitest.Send(t, model, tuitest.Key("enter"))
 
-			key := tea.KeyMsg{Type: tea.KeyEnter}
-			updatedModel, cmd := model.Update(key)
-			model = updatedModel.(*SetupModel)
-
-			if model.state != tt.expectedState {
-				t.Errorf("expected state %v, got %v", tt.expectedState, model.state)
+			if model.state != SetupStateGitHubForm {
+				t.Errorf("expected state %v, got %v", SetupStateGitHubForm, model.state)
 			}
-
-			if tt.shouldError {
-				if cmd == nil {
-					t.Error("expected non-nil cmd for error")
-				} else {
-					msg := cmd()
-					if _, ok := msg.(setupErrorMsg); !ok {
-						t.Error("expected setupErrorMsg")
-					}
-				}
+			if got := model.githubForm.Focused(); got != tt.expectedFocus {
+				t.Errorf("expected focus on %q, got %q", tt.expectedFocus, got)
+			}
+			if hasErr := model.githubForm.Error(fieldURL) != nil; hasErr != tt.shouldError {
+				t.Errorf("expected URL error %v, got %v", tt.shouldError, model.githubForm.Error(fieldURL))
 			}
 		})
 	}
 }
 
-func TestGitHubBranchInput(t *testing.T) {
-	t.Run("branch value is stored", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubBranch)
-		model.textInput.SetValue("develop")
+func TestGitHubFormNavigation(t *testing.T) {
+	t.Run("tab and shift+tab move between fields", func(t *testing.T) {
+		model := createModelInState(t, SetupStateGitHubForm)
 
-		key := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedModel, _ := model.Update(key)
-		model = updatedModel.(*SetupModel)
-
-		if model.GitHubBranch != "develop" {
-			t.Errorf("expected branch 'develop', got %q", model.GitHubBranch)
+		model = tuitest.Send(t, model, tuitest.Key("tab"))
+		if model.githubForm.Focused() != fieldBranch {
+			t.Errorf("expected focus on branch, got %q", model.githubForm.Focused())
 		}
-		if model.state != SetupStateGitHubPath {
-			t.Errorf("expected state %v, got %v", SetupStateGitHubPath, model.state)
+		model = tuitest.Send(t, model, tuitest.Key("shift+tab"))
+		if model.githubForm.Focused() != fieldURL {
+			t.Errorf("expected focus on url, got %q", model.githubForm.Focused())
 		}
 	})
 
 	t.Run("empty branch is allowed", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubBranch)
-		model.textInput.SetValue("")
-
-		key := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedModel, _ := model.Update(key)
-		model = updatedModel.(*SetupModel)
+		model := createModelInState(t, SetupStateGitHubForm)
+		model = tuitest.Send(t, model, tuitest.Type("https://github.com/owner/repo.git")...)
+		model = tuitest.Send(t, model, tuitest.Key("enter"))
+		model = tuitest.Send(t, model, tuitest.Key("enter"))
 
-		if model.GitHubBranch != "" {
-			t.Errorf("expected empty branch, got %q", model.GitHubBranch)
+		if model.githubForm.Error(fieldBranch) != nil {
+			t.Errorf("unexpected branch error: %v", model.githubForm.Error(fieldBranch))
 		}
-		if model.state != SetupStateGitHubPath {
-			t.Errorf("expected state %v, got %v", SetupStateGitHubPath, model.state)
+		if model.githubForm.Focused() != fieldPath {
+			t.Errorf("expected focus on path, got %q", model.githubForm.Focused())
 		}
 	})
 
-	t.Run("invalid branch name shows error", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubBranch)
-		model.textInput.SetValue("invalid branch name") // Contains spaces
-
-		key := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedModel, _ := model.Update(key)
-		model = updatedModel.(*SetupModel)
+	t.Run("invalid branch shows inline error", func(t *testing.T) {
+		model := createModelInState(t, SetupStateGitHubForm)
+		model = tuitest.Send(t, model, tuitest.Key("tab"))
+		model = tuitest.Send(t, model, tuitest.Type("feature..bad")...)
+		model = tuitest.Send(t, model, tuitest.Key("enter"))
 
-		// Should stay in same state when validation fails
-		if model.state != SetupStateGitHubBranch {
-			t.Errorf("expected to stay in SetupStateGitHubBranch, got %v", model.state)
+		if model.githubForm.Error(fieldBranch) == nil {
+			t.Error("expected inline branch error")
 		}
-		// Should have error set
-		if model.layout.GetError() == nil {
-			t.Error("expected error to be set for invalid branch name")
+		if model.githubForm.Focused() != fieldBranch {
+			t.Errorf("expected focus to stay on branch, got %q", model.githubForm.Focused())
 		}
 	})
 
-	t.Run("branch with special characters shows error", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubBranch)
-		model.textInput.SetValue("feature/test~branch") // Contains ~
-
-		key := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedModel, _ := model.Update(key)
-		model = updatedModel.(*SetupModel)
+	t.Run("q is typed instead of quitting", func(t *testing.T) {
+		model := createModelInState(t, SetupStateGitHubForm)
+		model = tuitest.Send(t, model, tuitest.Type("q")...)
 
-		// Should stay in same state when validation fails
-		if model.state != SetupStateGitHubBranch {
-			t.Errorf("expected to stay in SetupStateGitHubBranch, got %v", model.state)
+		if model.state != SetupStateGitHubForm || model.Cancelled {
+			t.Errorf("expected to stay in form, got state %v cancelled %v", model.state, model.Cancelled)
 		}
-		// Should have error set
-		if model.layout.GetError() == nil {
-			t.Error("expected error to be set for branch with special characters")
+		if got := model.githubForm.Value(fieldURL); got != "q" {
+			t.Errorf("expected url value 'q', got %q", got)
 		}
 	})
 
-	t.Run("escape goes back to url", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubBranch)
-
-		key := tea.KeyMsg{Type: tea.KeyEscape}
-		updatedModel, _ := model.Update(key)
-		model = updatedModel.(*SetupModel)
+	t.Run("escape goes back to repository type", func(t *testing.T) {
+		model := createModelInState(t, SetupStateGitHubForm)
+		model = tuitest.Send(t, model, tuitest.Key("esc"))
 
-		if model.state != SetupStateGitHubURL {
-			t.Errorf("expected state %v, got %v", SetupStateGitHubURL, model.state)
+		if model.state != SetupStateRepositoryType {
+			t.Errorf("expected state %v, got %v", SetupStateRepositoryType, model.state)
 		}
 	})
 }
 
-func TestGitHubPathInput(t *testing.T) {
-	t.Run("valid path proceeds to PAT", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubPath)
-		// Use temp dir which is guaranteed to be valid
-		validPath := t.TempDir()
-		model.textInput.SetValue(validPath)
-
-		key := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedModel, _ := model.Update(key)
-		model = updatedModel.(*SetupModel)
-
-		// Should advance to PAT input state and set password mode
-		if model.state != SetupStateGitHubPAT {
-			t.Errorf("expected state %v, got %v", SetupStateGitHubPAT, model.state)
-		}
-		if model.textInput.EchoMode != textinput.EchoPassword {
-			t.Errorf("expected EchoPassword mode, got %v", model.textInput.EchoMode)
-		}
-	})
-
-	t.Run("empty input accepts placeholder and proceeds", func(t *testing.T) {
-		// The empty-input placeholder derives from xdg.DataHome, and
-		// ValidateStoragePath requires the placeholder's parent directory
-		// (<DataHome>/rulem) to exist. Point XDG_DATA_HOME at a temp dir so
-		// the test doesn't depend on the machine's real storage dir.
-		dataHome := t.TempDir()
-		t.Cleanup(xdg.Reload) // runs after t.Setenv restores the env
-		t.Setenv("XDG_DATA_HOME", dataHome)
-		xdg.Reload()
-		if err := os.MkdirAll(filepath.Join(dataHome, "rulem"), 0o755); err != nil {
-			t.Fatalf("mkdir data home: %v", err)
-		}
-
-		model := createModelInState(t, SetupStateGitHubPath)
-		model.GitHubURL = "https://github.com/user/repo.git"
-		// Leave textInput empty to test placeholder acceptance
-		model.textInput.SetValue("")
-
-		key := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedModel, _ := model.Update(key)
-		model = updatedModel.(*SetupModel)
-
-		// Should advance to PAT input state with placeholder path
-		if model.state != SetupStateGitHubPAT {
-			t.Errorf("expected state %v, got %v", SetupStateGitHubPAT, model.state)
-		}
-
-		// GitHubPath should be set to derived path or default
-		if model.GitHubPath == "" {
-			t.Error("expected GitHubPath to be set from placeholder")
-		}
-
-		// Should be in expanded form
-		if !strings.HasPrefix(model.GitHubPath, "/") && !strings.HasPrefix(model.GitHubPath, "~") {
-			t.Logf("GitHubPath set to: %s", model.GitHubPath)
-		}
-	})
-
-	t.Run("invalid path shows error", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubPath)
-		// Set an invalid path that will fail validation
-		model.textInput.SetValue("/invalid/system/path/outside/home")
-
-		key := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedModel, cmd := model.Update(key)
-		model = updatedModel.(*SetupModel)
-
-		if cmd == nil {
-			t.Error("expected non-nil cmd for error")
-		}
-
-		// Should remain in same state on error
-		if model.state != SetupStateGitHubPath {
-			t.Errorf("expected to remain in GitHubPath state, got %v", model.state)
-		}
-	})
-
-	t.Run("escape returns to branch input", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubPath)
+func TestGitHubFormClonePathSuggestion(t *testing.T) {
+	model := createModelInState(t, SetupStateGitHubForm)
+	if got := model.githubForm.Value(fieldPath); got != repository.GetDefaultStorageDir() {
+		t.Errorf("expected default storage dir before URL entry, got %q", got)
+	}
 
-		key := tea.KeyMsg{Type: tea.KeyEsc}
-		updatedModel, _ := model.Update(key)
-		model = updatedModel.(*SetupModel)
+	model = tuitest.Send(t, model, tuitest.Type("https://github.com/user/my-rules.git")...)
 
-		if model.state != SetupStateGitHubBranch {
-			t.Errorf("expected state %v on escape, got %v", SetupStateGitHubBranch, model.state)
-		}
-	})
+	got := model.githubForm.Value(fieldPath)
+	if !strings.HasSuffix(got, "my-rules") {
+		t.Errorf("expected clone path derived from URL, got %q", got)
+	}
 }
 
-func TestGitHubPATInput(t *testing.T) {
+func TestGitHubFormPAT(t *testing.T) {
+	fillForm := func(t *testing.T, token string) *SetupModel {
+		t.Helper()
+		model := createModelInState(t, SetupStateGitHubForm)
+		model = tuitest.Send(t, model, tuitest.Type("https://github.com/test/repo.git")...)
+		model = tuitest.Send(t, model, tuitest.Key("tab"))
+		model = tuitest.Send(t, model, tuitest.Key("tab"))
+		model.githubForm = model.githubForm.SetValue(fieldPath, filepath.Join(t.TempDir(), "repo"))
+		model = tuitest.Send(t, model, tuitest.Key("tab"))
+		model = tuitest.Send(t, model, tuitest.Type(token)...)
+		return tuitest.Send(t, model, tuitest.Key("enter"))
+	}
+
 	t.Run("PAT input is in password mode", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubPAT)
-		model.textInput.EchoMode = textinput.EchoPassword
+		model := createModelInState(t, SetupStateGitHubForm)
+		for range 3 {
+			model = tuitest.Send(t, model, tuitest.Key("tab"))
+		}
+		model = tuitest.Send(t, model, tuitest.Type("secret")...)
 
-		if model.textInput.EchoMode != textinput.EchoPassword {
-			t.Errorf("expected EchoPassword mode, got %v", model.textInput.EchoMode)
+		if strings.Contains(model.View(), "secret") {
+			t.Error("expected PAT to be masked in the view")
 		}
 	})
 
 	t.Run("empty PAT shows error", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubPAT)
-		model.textInput.SetValue("")
+		model := fillForm(t, "")
 
-		key := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedModel, cmd := model.Update(key)
-		model = updatedModel.(*SetupModel)
-
-		if model.state != SetupStateGitHubPAT {
-			t.Errorf("expected state %v, got %v", SetupStateGitHubPAT, model.state)
+		if model.state != SetupStateGitHubForm {
+			t.Errorf("expected state %v, got %v", SetupStateGitHubForm, model.state)
 		}
-		if cmd == nil {
-			t.Error("expected non-nil cmd for error")
-		}
-
-		msg := cmd()
-		if errMsg, ok := msg.(setupErrorMsg); ok {
-			if errMsg.err == nil {
-				t.Error("expected error in setupErrorMsg")
-			}
-			if !strings.Contains(errMsg.err.Error(), "cannot be empty") {
-				t.Errorf("expected 'cannot be empty' in error, got %q", errMsg.err.Error())
-			}
+		err := model.githubForm.Error(fieldPAT)
+		if err == nil || !strings.Contains(err.Error(), "cannot be empty") {
+			t.Errorf("expected 'cannot be empty' error, got %v", err)
 		}
 	})
 
 	t.Run("invalid PAT format shows error", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubPAT)
-		model.textInput.SetValue("invalid-token")
+		model := fillForm(t, "invalid-token")
 
-		key := tea.KeyMsg{Type: tea.KeyEnter}
-		_, cmd := model.Update(key)
-		if cmd == nil {
-			t.Error("expected non-nil cmd for error")
-		}
-
-		msg := cmd()
-		if errMsg, ok := msg.(setupErrorMsg); ok {
-			if errMsg.err == nil {
-				t.Error("expected error in setupErrorMsg")
-			}
-			if !strings.Contains(errMsg.err.Error(), "token") {
-				t.Errorf("expected 'token' in error, got %q", errMsg.err.Error())
-			}
+		err := model.githubForm.Error(fieldPAT)
+		if err == nil || !strings.Contains(err.Error(), "token") {
+			t.Errorf("expected token error, got %v", err)
 		}
 	})
 
 	t.Run("valid PAT format is validated but repository access fails without real GitHub", func(t *testing.T) {
-		// Note: This test demonstrates that format validation passes but repository validation
-		// will fail without real GitHub repository access. In production, valid tokens will pass
-		// repository validation and advance to confirmation state.
-		model := createModelInState(t, SetupStateGitHubPAT)
-		testToken := repository.CreateTestToken("")
+		// Token format is valid, but repository validation will fail (no real GitHub
+		// repository). In production, valid tokens advance to the confirmation state.
+		model := fillForm(t, repository.CreateTestToken(""))
 
-		model.textInput.SetValue(testToken)
-
-		key := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedModel, cmd := model.Update(key)
-		if m, ok := updatedModel.(*SetupModel); ok {
-			model = m
-		} else {
-			t.Fatal("expected SetupModel")
+		if model.state != SetupStateGitHubForm {
+			t.Errorf("expected to stay on form, got %v", model.state)
 		}
-
-		// Token format is valid, but repository validation will fail (no real GitHub repository)
-		if cmd == nil {
-			t.Error("expected non-nil cmd due to repository validation failure")
+		if model.githubForm.Error(fieldPAT) == nil {
+			t.Error("expected repository validation error on PAT field")
 		}
-
-		// Execute the command to verify it's an error
-		msg := cmd()
-		if errMsg, ok := msg.(setupErrorMsg); ok {
-			if errMsg.err == nil {
-				t.Error("expected error in setupErrorMsg")
-			}
-			// Repository operation will fail with connection/auth error
+		if model.githubForm.Focused() != fieldPAT {
+			t.Errorf("expected focus on PAT, got %q", model.githubForm.Focused())
 		}
-
 		// Token is NOT stored in memory because validation failed
-		// In production with valid GitHub access, token would be stored
 		if model.GitHubPAT != "" {
 			t.Errorf("expected empty PAT after validation failure, got %q", model.GitHubPAT)
 		}
 	})
-
-	t.Run("escape goes back to path", func(t *testing.T) {
-		model := createModelInState(t, SetupStateGitHubPAT)
-
-		key := tea.KeyMsg{Type: tea.KeyEscape}
-		updatedModel, _ := model.Update(key)
-		model = updatedModel.(*SetupModel)
-
-		if model.state != SetupStateGitHubPath {
-			t.Errorf("expected state %v, got %v", SetupStateGitHubPath, model.state)
-		}
-	})
 }
 
 func TestConfirmationState(t *testing.T) {
@@ -711,17 +538,21 @@ func TestConfirmationState(t *testing.T) {
 		}
 	})
 
-	t.Run("n goes back to PAT for github", func(t *testing.T) {
-		model := createModelInState(t, SetupStateConfirmation)
-		model.repositoryType = RepositoryTypeGitHub
+	t.Run("n goes back to form for github with values kept", func(t *testing.T) {
+		model := createModelInState(t, SetupStateGitHubForm)
+		model = tuitest.Send(t, model, tuitest.Type("https://github.com/test/repo.git")...)
+		model.state = SetupStateConfirmation
 		model.GitHubPAT = "test-token"
 
 		key := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}
 		updatedModel, _ := model.Update(key)
 		model = updatedModel.(*SetupModel)
 
-		if model.state != SetupStateGitHubPAT {
-			t.Errorf("expected state %v, got %v", SetupStateGitHubPAT, model.state)
+		if model.state != SetupStateGitHubForm {
+			t.Errorf("expected state %v, got %v", SetupStateGitHubForm, model.state)
+		}
+		if got := model.githubForm.Value(fieldURL); got != "https://github.com/test/repo.git" {
+			t.Errorf("expected URL to be kept, got %q", got)
 		}
 	})
 
@@ -803,24 +634,9 @@ func TestViewRendering(t *testing.T) {
 			contains: []string{"storage", "directory"},
 		},
 		{
-			name:     "github url view",
-			state:    SetupStateGitHubURL,
-			contains: []string{"GitHub", "URL"},
-		},
-		{
-			name:     "github branch view",
-			state:    SetupStateGitHubBranch,
-			contains: []string{"branch"},
-		},
-		{
-			name:     "github path view",
-			state:    SetupStateGitHubPath,
-			contains: []string{"path"},
-		},
-		{
-			name:     "github pat view",
-			state:    SetupStateGitHubPAT,
-			contains: []string{"token", "PAT"},
+			name:     "github form view",
+			state:    SetupStateGitHubForm,
+			contains: []string{"GitHub", "URL", "Branch", "clone path", "Token", "PAT"},
 		},
 		{
 			name:     "complete view",
@@ -903,6 +719,7 @@ func TestLocalRepositorySetupCreatesCorrectRepositoryEntry(t *testing.T) {
 	defer cleanup()
 
 	model := createTestModel(t)
+	model.ids = &config.SequentialIDGenerator{}
 	model.repositoryType = RepositoryTypeLocal
 
 	// Create a temp directory with a specific name we can verify
@@ -922,13 +739,13 @@ func TestLocalRepositorySetupCreatesCorrectRepositoryEntry(t *testing.T) {
 
 	// Verify Repositories array exists and has single entry
 	if len(cfg.Repositories) != 1 {
-		t.Errorf("expected 1 repository, got %d", len(cfg.Repositories))
+		t.Fatalf("expected 1 repository, got %d", len(cfg.Repositories))
 	}
 
 	// Verify RepositoryEntry structure
 	repo := cfg.Repositories[0]
-	if repo.ID == "" {
-		t.Error("expected non-empty repository ID")
+	if repo.ID != "my-local-rules-00000001" {
+		t.Errorf("expected ID 'my-local-rules-00000001', got %q", repo.ID)
 	}
 	// Name should be extracted from directory path
 	if repo.Name != "my-local-rules" {
@@ -953,6 +770,7 @@ func TestGitHubRepositorySetupCreatesCorrectRepositoryEntry(t *testing.T) {
 	defer cleanup()
 
 	model := createTestModel(t)
+	model.ids = &config.SequentialIDGenerator{}
 	model.repositoryType = RepositoryTypeGitHub
 	model.GitHubURL = "https://github.com/test/awesome-rules.git"
 	model.GitHubBranch = "main"
@@ -972,13 +790,13 @@ func TestGitHubRepositorySetupCreatesCorrectRepositoryEntry(t *testing.T) {
 
 	// Verify Repositories array exists and has single entry
 	if len(cfg.Repositories) != 1 {
-		t.Errorf("expected 1 repository, got %d", len(cfg.Repositories))
+		t.Fatalf("expected 1 repository, got %d", len(cfg.Repositories))
 	}
 
 	// Verify RepositoryEntry structure
 	repo := cfg.Repositories[0]
-	if repo.ID == "" {
-		t.Error("expected non-empty repository ID")
+	if repo.ID != "awesome-rules-00000001" {
+		t.Errorf("expected ID 'awesome-rules-00000001', got %q", repo.ID)
 	}
 
 	// Name should be extracted from URL ("awesome-rules")
@@ -1007,6 +825,7 @@ func TestRepositoryIDGeneration(t *testing.T) {
 	defer cleanup()
 
 	model := createTestModel(t)
+	model.ids = &config.SequentialIDGenerator{}
 	model.repositoryType = RepositoryTypeLocal
 
 	// Create a temp directory with a specific name we can verify
@@ -1023,21 +842,34 @@ func TestRepositoryIDGeneration(t *testing.T) {
 		t.Fatalf("failed to load config: %v", err)
 	}
 
-	repo := cfg.Repositories[0]
+	// ID is the sanitized name followed by the generator's suffix
+	if got, want := cfg.Repositories[0].ID, "my-rules-00000001"; got != want {
+		t.Errorf("repository ID = %q, want %q", got, want)
+	}
+}
+
+func TestCompleteLocal(t *testing.T) {
+	_, cleanup := SetTestConfigPath(t)
+	defer cleanup()
 
-	// Verify ID format: "sanitized-name-timestamp"
-	idPattern := `^[a-z0-9-]+-\d+$`
-	matched, err := regexp.MatchString(idPattern, repo.ID)
+	model := createTestModel(t)
+	if err := model.CompleteLocal(""); err == nil {
+		t.Error("expected an empty storage directory to be rejected")
+	}
+
+	dir := filepath.Join(t.TempDir(), "team-rules")
+	if err := model.CompleteLocal(dir); err != nil {
+		t.Fatalf("CompleteLocal() failed: %v", err)
+	}
+	cfg, err := LoadTestConfig(t)
 	if err != nil {
-		t.Fatalf("regex error: %v", err)
+		t.Fatalf("failed to load config: %v", err)
 	}
-	if !matched {
-		t.Errorf("repository ID %q does not match expected pattern %q", repo.ID, idPattern)
+	if len(cfg.Repositories) != 1 || cfg.Repositories[0].Path != dir || !cfg.Repositories[0].IsLocal() {
+		t.Errorf("repositories = %+v, want the local repository at %s", cfg.Repositories, dir)
 	}
-
-	// Verify ID starts with sanitized name
-	if !strings.HasPrefix(repo.ID, "my-rules-") {
-		t.Errorf("expected ID to start with 'my-rules-', got %q", repo.ID)
+	if !FileExists(dir) {
+		t.Errorf("storage directory %s was not created", dir)
 	}
 }
 
@@ -1273,3 +1105,71 @@ func BenchmarkView(b *testing.B) {
 		model.View()
 	}
 }
+
+// TestGoldenViews renders each setup screen at several terminal sizes and
+// compares it with testdata/<name>_<width>x<height>.golden. Regenerate with
+// go test ./internal/tui/setupmenu -run TestGoldenViews -update
+func TestGoldenViews(t *testing.T) {
+	tuitest.PinDataHome(t)
+
+	sizes := []struct{ width, height int }{{80, 24}, {120, 40}}
+	tests := []struct {
+		name  string
+		state SetupState
+		setup func(model *SetupModel) *SetupModel
+	}{
+		{name: "welcome", state: SetupStateWelcome},
+		{name: "repository_type", state: SetupStateRepositoryType},
+		{name: "storage_input", state: SetupStateStorageInput},
+		{name: "github_form", state: SetupStateGitHubForm},
+		{
+			name:  "github_form_errors",
+			state: SetupStateGitHubForm,
+			setup: func(model *SetupModel) *SetupModel {
+				model = tuitest.Send(t, model, tuitest.Type("not-a-url")...)
+				return tuitest.Send(t, model, tuitest.Key("enter"))
+			},
+		},
+		{
+			name:  "confirmation_local",
+			state: SetupStateConfirmation,
+			setup: func(model *SetupModel) *SetupModel {
+				model.repositoryType = RepositoryTypeLocal
+				return model
+			},
+		},
+		{
+			name:  "confirmation_github",
+			state: SetupStateConfirmation,
+			setup: func(model *SetupModel) *SetupModel {
+				model.repositoryType = RepositoryTypeGitHub
+				model.GitHubURL = "https://github.com/example/team-rules.git"
+				model.GitHubBranch = "main"
+				model.GitHubPath = "/home/user/.local/share/rulem/team-rules"
+				return model
+			},
+		},
+		{
+			name:  "storage_input_error",
+			state: SetupStateStorageInput,
+			setup: func(model *SetupModel) *SetupModel {
+				return tuitest.Send(t, model, setupErrorMsg{errors.New("path is not writable: /home/user/rules")})
+			},
+		},
+		{name: "cancelled", state: SetupStateCancelled},
+	}
+
+	for _, tt := range tests {
+		for _, size := range sizes {
+			name := fmt.Sprintf("%s_%dx%d", tt.name, size.width, size.height)
+			t.Run(name, func(t *testing.T) {
+				model := createModelInState(t, tt.state)
+				model = tuitest.Send(t, model, tea.WindowSizeMsg{Width: size.width, Height: size.height})
+				if tt.setup != nil {
+					model = tt.setup(model)
+				}
+				tuitest.Golden(t, name, model.View())
+			})
+		}
+	}
+}
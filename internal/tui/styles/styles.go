@@ -40,9 +40,24 @@ var (
 			MarginTop(1).
 			Padding(0, 1)
 
+	// Status bar pinned to the bottom of every screen
+	StatusBarStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#d0d0d0")).
+			Background(lipgloss.Color("#303030")).
+			Padding(0, 1)
+
+	StatusBarDirtyStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#ffaf00")).
+				Background(lipgloss.Color("#303030"))
+
 	SpinnerStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#5fd7ff"))
 
+	// One-time contextual tip shown above the status bar
+	TipStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#5fd7ff")).
+			Padding(0, 1)
+
 	// Containers for consistent layout spacing
 	HeaderContainerStyle = lipgloss.NewStyle().
 				MarginLeft(1).
@@ -0,0 +1,66 @@
+package summarymenu
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/internal/summary"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestGoldenViews renders the summary at several terminal sizes and compares
+// it with testdata/<name>_<width>x<height>.golden. Regenerate with
+// go test ./internal/tui/summarymenu -run TestGoldenViews -update
+func TestGoldenViews(t *testing.T) {
+	repos := []repository.RepositoryEntry{
+		{ID: "l1", Name: "Personal Rules", Type: repository.RepositoryTypeLocal, Path: "/home/user/rules"},
+		{ID: "l2", Name: "Team Rules", Type: repository.RepositoryTypeLocal, Path: "/home/user/team-rules"},
+	}
+	single := &config.Config{Repositories: repos[:1]}
+	several := &config.Config{Repositories: repos}
+
+	to := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	report := &summary.Report{
+		From:    to.Add(-summary.DefaultPeriod),
+		To:      to,
+		Changes: []summary.Change{{Repository: "Personal Rules", Path: "go/style.md", Kind: repository.FileChangeAdded}},
+		Served:  []summary.Served{{Rule: "go_style", Repository: "Personal Rules", Count: 12}},
+		Reviews: []summary.Due{{Rule: "testing", Repository: "Personal Rules", Path: "testing.md", Date: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)}},
+	}
+
+	sizes := []struct{ width, height int }{{80, 24}, {120, 40}}
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		msgs []tea.Msg
+	}{
+		{name: "empty", cfg: &config.Config{}, msgs: []tea.Msg{reportMsg{report: &summary.Report{From: report.From, To: to}}}},
+		{name: "loading", cfg: single},
+		{name: "report", cfg: single, msgs: []tea.Msg{reportMsg{report: report}}},
+		{name: "several_repositories", cfg: several, msgs: []tea.Msg{reportMsg{report: report}}},
+		{name: "written", cfg: single, msgs: []tea.Msg{reportMsg{report: report}, writtenMsg{path: "/home/user/rules/summaries/2026-10-16.md"}}},
+		{name: "error", cfg: single, msgs: []tea.Msg{reportMsg{err: errors.New("failed to prepare repositories")}}},
+	}
+
+	for _, tt := range tests {
+		for _, size := range sizes {
+			name := fmt.Sprintf("%s_%dx%d", tt.name, size.width, size.height)
+			t.Run(name, func(t *testing.T) {
+				logger, _ := logging.NewTestLogger()
+				m := NewSummaryModel(helpers.NewUIContext(size.width, size.height, tt.cfg, logger))
+				for _, msg := range tt.msgs {
+					m = tuitest.Send(t, m, msg)
+				}
+				tuitest.Golden(t, name, m.View())
+			})
+		}
+	}
+}
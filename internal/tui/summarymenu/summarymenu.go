@@ -0,0 +1,230 @@
+// Package summarymenu implements the "Weekly summary" screen.
+//
+// It shows the summary package's report of the last week of rule activity -
+// new and changed rules, the rules served most over MCP, reviews due and
+// expiring rules - as scrollable markdown. When a single repository is
+// configured the report can be saved to its summaries directory; with several,
+// `rulem summary --repo <name> --write` chooses the repository.
+package summarymenu
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/summary"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/styles"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type menuState int
+
+const (
+	stateLoading menuState = iota
+	stateReady
+)
+
+type (
+	reportMsg struct {
+		report *summary.Report
+		err    error
+	}
+
+	writtenMsg struct {
+		path string
+		err  error
+	}
+)
+
+// SummaryModel is the Bubble Tea model for the weekly summary screen.
+type SummaryModel struct {
+	logger   *logging.AppLogger
+	layout   components.LayoutModel
+	spinner  spinner.Model
+	viewport viewport.Model
+	cfg      *config.Config
+
+	state   menuState
+	report  *summary.Report
+	written string // Path the report was saved to, if it was
+}
+
+// NewSummaryModel creates the summary screen model from the shared UI context.
+func NewSummaryModel(ctx helpers.UIContext) *SummaryModel {
+	layout := components.NewLayout(components.LayoutConfig{
+		MarginX:  2,
+		MarginY:  1,
+		MaxWidth: 100,
+	})
+	if ctx.HasValidDimensions() {
+		layout, _ = layout.Update(tea.WindowSizeMsg{Width: ctx.Width, Height: ctx.Height})
+	}
+
+	s := spinner.New()
+	s.Style = styles.SpinnerStyle
+	s.Spinner = spinner.Pulse
+
+	m := &SummaryModel{
+		logger:   ctx.Logger,
+		layout:   layout,
+		spinner:  s,
+		viewport: viewport.New(0, 0),
+		cfg:      ctx.Config,
+		state:    stateLoading,
+	}
+	m.resizeViewport()
+	return m
+}
+
+// Init starts loading the report and the spinner.
+func (m *SummaryModel) Init() tea.Cmd {
+	return tea.Batch(m.loadCmd(), m.spinner.Tick)
+}
+
+// Update handles the loaded report, key presses, and spinner ticks.
+func (m *SummaryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m.layout, _ = m.layout.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resizeViewport()
+		return m, nil
+
+	case reportMsg:
+		m.state = stateReady
+		if msg.err != nil {
+			m.logger.Error("Failed to build summary", "error", msg.err)
+			m.layout = m.layout.SetError(msg.err)
+			return m, nil
+		}
+		m.layout = m.layout.ClearError()
+		m.report = msg.report
+		m.viewport.SetContent(msg.report.Markdown())
+		m.viewport.GotoTop()
+		return m, nil
+
+	case writtenMsg:
+		if msg.err != nil {
+			m.logger.Error("Failed to write summary", "error", msg.err)
+			m.layout = m.layout.SetError(msg.err)
+			return m, nil
+		}
+		m.layout = m.layout.ClearError()
+		m.written = msg.path
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state == stateLoading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q":
+			return m, func() tea.Msg { return helpers.NavigateToMainMenuMsg{} }
+		case "esc":
+			return m, helpers.NavigateBack
+		case "r":
+			if m.state == stateReady {
+				m.state = stateLoading
+				m.written = ""
+				return m, tea.Batch(m.loadCmd(), m.spinner.Tick)
+			}
+			return m, nil
+		case "w":
+			if m.report != nil && m.writeRoot() != "" {
+				return m, m.writeCmd()
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// View renders the report, or a spinner while it is built.
+func (m *SummaryModel) View() string {
+	help := "↑/↓ scroll • r reload • q/esc back"
+	if m.writeRoot() != "" {
+		help = "↑/↓ scroll • w save to repository • r reload • q/esc back"
+	}
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    "📊 Weekly Summary",
+		Subtitle: m.subtitle(),
+		HelpText: help,
+	})
+
+	if m.state == stateLoading {
+		return m.layout.Render(fmt.Sprintf("%s Building summary... (GitHub repositories are synced first)", m.spinner.View()))
+	}
+	if m.report == nil {
+		return m.layout.Render("No summary available.")
+	}
+	return m.layout.Render(m.viewport.View())
+}
+
+func (m *SummaryModel) subtitle() string {
+	switch {
+	case m.cfg == nil || len(m.cfg.Repositories) == 0:
+		return "No repositories configured."
+	case m.written != "":
+		return styles.SuccessStyle.Render("✅ Summary saved to " + m.written)
+	case m.writeRoot() == "":
+		return "Rule activity over the last week. Save it to a repository with\nrulem summary --repo <name> --write."
+	default:
+		return "Rule activity over the last week."
+	}
+}
+
+// writeRoot is the directory the report is saved to, or "" when there is not
+// exactly one repository to save it to
+func (m *SummaryModel) writeRoot() string {
+	if m.cfg == nil || len(m.cfg.Repositories) != 1 {
+		return ""
+	}
+	repo := m.cfg.Repositories[0]
+	if overlay := repo.GetOverlay(); overlay != "" {
+		return overlay
+	}
+	return repo.Path
+}
+
+// resizeViewport fits the viewport below the subtitle
+func (m *SummaryModel) resizeViewport() {
+	m.viewport.Width = m.layout.ContentWidth()
+	m.viewport.Height = max(m.layout.ContentHeight()-3, 3)
+}
+
+func (m *SummaryModel) loadCmd() tea.Cmd {
+	cfg := m.cfg
+	logger := m.logger
+	return func() tea.Msg {
+		if cfg == nil {
+			return reportMsg{err: fmt.Errorf("configuration is not loaded")}
+		}
+		report, err := summary.Generate(context.Background(), cfg, logger, "", summary.DefaultPeriod, time.Now())
+		return reportMsg{report: report, err: err}
+	}
+}
+
+func (m *SummaryModel) writeCmd() tea.Cmd {
+	report := m.report
+	root := m.writeRoot()
+	return func() tea.Msg {
+		path, err := report.Write(root)
+		return writtenMsg{path: path, err: err}
+	}
+}
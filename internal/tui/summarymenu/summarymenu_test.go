@@ -0,0 +1,57 @@
+package summarymenu
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rulem/internal/config"
+	"rulem/internal/logging"
+	"rulem/internal/repository"
+	"rulem/internal/summary"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSummaryModel_Write(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "l1", Name: "Rules", Type: repository.RepositoryTypeLocal, Path: dir},
+	}}
+	logger, _ := logging.NewTestLogger()
+	m := NewSummaryModel(helpers.NewUIContext(80, 24, cfg, logger))
+
+	to := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	m = tuitest.Send(t, m, reportMsg{report: &summary.Report{From: to.Add(-summary.DefaultPeriod), To: to}})
+
+	m, cmd := tuitest.SendCmd(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if cmd == nil {
+		t.Fatal("w should save the summary")
+	}
+	m = tuitest.Send(t, m, cmd())
+
+	want := filepath.Join(dir, summary.Dir, "2026-10-16.md")
+	if m.written != want {
+		t.Errorf("written = %q, want %q", m.written, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("summary not saved: %v", err)
+	}
+}
+
+func TestSummaryModel_WriteNeedsSingleRepository(t *testing.T) {
+	cfg := &config.Config{Repositories: []repository.RepositoryEntry{
+		{ID: "l1", Name: "Rules", Path: t.TempDir()},
+		{ID: "l2", Name: "Team", Path: t.TempDir()},
+	}}
+	logger, _ := logging.NewTestLogger()
+	m := NewSummaryModel(helpers.NewUIContext(80, 24, cfg, logger))
+	m = tuitest.Send(t, m, reportMsg{report: &summary.Report{}})
+
+	if _, cmd := tuitest.SendCmd(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")}); cmd != nil {
+		t.Error("w should do nothing with several repositories")
+	}
+}
@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"fmt"
+
+	"rulem/internal/onboarding"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// First-run tour and contextual tips
+//
+// After the setup wizard, the tour walks new users through the three things
+// rulem is for: saving a rule, deploying it to a project and connecting an MCP
+// client. Screens also show a one-time tip above the status bar until it is
+// dismissed with ctrl+t. Both are remembered in an onboarding.Store, and are
+// off when no store is set (as in tests).
+
+// tourStep is one page of the first-run tour
+type tourStep struct {
+	title string
+	body  string
+}
+
+var tourSteps = []tourStep{
+	{
+		title: "👋 Welcome to rulem",
+		body: "Setup is done! This short tour shows how to save a rule, deploy it to a project\n" +
+			"and connect an AI assistant to your rules over MCP.\n\n" +
+			"It takes a minute. Press Esc to skip it at any time; run `rulem --tour` to see it again.",
+	},
+	{
+		title: "💾 Save a rule",
+		body: "Run rulem in a project with a rules file you like (e.g. AGENTS.md or\n" +
+			".cursor/rules/go.mdc) and pick \"Save rules file\" from the menu.\n\n" +
+			"Choose the file in the picker, which previews it, and give it a name. It is\n" +
+			"copied to your central rules repository, ready for every other project.",
+	},
+	{
+		title: "📄 Deploy it to a project",
+		body: "In another project, pick \"Import rules\" and choose the rule.\n\n" +
+			"Copy it to get a file you can adapt, or link it to always follow the central\n" +
+			"version. Select your assistant (Copilot, Cursor, Claude, ...) and rulem writes\n" +
+			"the file where that assistant looks for it.",
+	},
+	{
+		title: "🔌 Connect an MCP client",
+		body: "Assistants that speak MCP can read your rules without deploying them. Add\n" +
+			"rulem to your client's MCP configuration:\n\n" +
+			`  { "mcpServers": { "rulem": { "command": "rulem", "args": ["mcp"] } } }` + "\n\n" +
+			"Every rule with frontmatter becomes a tool the assistant can call.",
+	},
+}
+
+// tip is a one-time hint shown on a screen until dismissed
+type tip struct {
+	id   string // Key in onboarding.Store.DismissedTips
+	text string
+}
+
+// screenTips holds the tip of each screen
+var screenTips = map[AppState]tip{
+	StateMenu:          {id: "menu", text: "Press / to filter the menu. Add more rule repositories in Update settings."},
+	StateSaveRules:     {id: "save-rules", text: "Add frontmatter with a description to a rule to serve it as an MCP tool."},
+	StateImportCopy:    {id: "import", text: "Link a rule instead of copying it to pick up central changes automatically."},
+	StateDeploy:        {id: "deploy", text: "Run `rulem deploy <rule> --as <path>` to deploy a rule from scripts."},
+	StateProjectStatus: {id: "project-status", text: "Run `rulem status --sync` to bring outdated rules up to date from scripts."},
+	StateManageRules:   {id: "manage-rules", text: "Moving a rule to a new folder creates the folder; folders left empty are removed."},
+	StateRepoStatus:    {id: "repo-status", text: "Set sync_interval in config.yaml to keep repositories fresh in the background."},
+	StateSummary:       {id: "summary", text: "Rate rules with `rulem note <rule> --rating 1-5` to remember which work best."},
+	StateSettings:      {id: "settings", text: "Tokens are kept in your OS credential store, never in config.yaml."},
+}
+
+// SetOnboarding enables the tour and contextual tips, recording progress in store
+func (m *MainModel) SetOnboarding(store *onboarding.Store) {
+	m.onboarding = store
+}
+
+// StartTour shows the first-run tour instead of the menu. It does nothing
+// without an onboarding store.
+func (m *MainModel) StartTour() {
+	if m.onboarding == nil {
+		return
+	}
+	m.state = StateTour
+	m.tourStep = 0
+}
+
+// updateTour handles keys while the tour is shown
+func (m *MainModel) updateTour(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "right", "l", "n":
+		if m.tourStep < len(tourSteps)-1 {
+			m.tourStep++
+			return m, nil
+		}
+		m.logger.LogUserAction("tour_completed", "")
+		return m.endTour(), nil
+	case "left", "h", "p":
+		if m.tourStep > 0 {
+			m.tourStep--
+		}
+	case "esc", "q":
+		m.logger.LogUserAction("tour_skipped", fmt.Sprintf("step %d", m.tourStep+1))
+		return m.endTour(), nil
+	}
+	return m, nil
+}
+
+// endTour records that the tour was seen and returns to the menu
+func (m *MainModel) endTour() tea.Model {
+	m.onboarding.CompleteTour()
+	if err := m.onboarding.Save(); err != nil {
+		m.logger.Warn("Failed to save onboarding progress", "error", err)
+	}
+	return m.returnToMenu()
+}
+
+func (m *MainModel) viewTour() string {
+	step := tourSteps[m.tourStep]
+	help := "Enter/→ next • ← back • Esc skip tour"
+	if m.tourStep == len(tourSteps)-1 {
+		help = "Enter to finish • ← back • Esc skip tour"
+	}
+	m.layout = m.layout.SetConfig(components.LayoutConfig{
+		Title:    step.title,
+		Subtitle: fmt.Sprintf("Tour: step %d of %d", m.tourStep+1, len(tourSteps)),
+		HelpText: help,
+	})
+	return m.layout.Render(step.body)
+}
+
+// currentTip returns the tip to show on the current screen, if any
+func (m *MainModel) currentTip() (tip, bool) {
+	if m.onboarding == nil {
+		return tip{}, false
+	}
+	t, ok := screenTips[m.state]
+	if !ok || m.onboarding.TipDismissed(t.id) {
+		return tip{}, false
+	}
+	return t, true
+}
+
+// dismissTip hides the current screen's tip for good
+func (m *MainModel) dismissTip() bool {
+	t, ok := m.currentTip()
+	if !ok {
+		return false
+	}
+	m.logger.LogUserAction("tip_dismissed", t.id)
+	m.onboarding.DismissTip(t.id)
+	if err := m.onboarding.Save(); err != nil {
+		m.logger.Warn("Failed to save onboarding progress", "error", err)
+	}
+	return true
+}
+
+// viewTip renders the current screen's tip, or "" when there is none
+func (m *MainModel) viewTip() string {
+	t, ok := m.currentTip()
+	if !ok {
+		return ""
+	}
+	return styles.TipStyle.Render("💡 Tip: "+t.text+" (ctrl+t to dismiss)") + "\n"
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rulem/internal/logging"
+	"rulem/internal/onboarding"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newOnboardingModel(t *testing.T) (*MainModel, string) {
+	t.Helper()
+	logger, _ := logging.NewTestLogger()
+	model := NewMainModel(createTestConfigWithPath(t.TempDir()), logger)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+
+	path := filepath.Join(t.TempDir(), "onboarding.yaml")
+	store, err := onboarding.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	model.SetOnboarding(store)
+	return model, path
+}
+
+func TestStartTour_WithoutOnboarding(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	model := NewMainModel(createTestConfigWithPath("/test/path"), logger)
+	model.StartTour()
+	if model.state != StateMenu {
+		t.Errorf("tour started without an onboarding store, state = %v", model.state)
+	}
+	if tip := model.viewTip(); tip != "" {
+		t.Errorf("tips should be off without an onboarding store, got %q", tip)
+	}
+}
+
+func TestTour_StepsAndCompletion(t *testing.T) {
+	model, path := newOnboardingModel(t)
+	model.StartTour()
+	if model.state != StateTour {
+		t.Fatalf("state = %v, want StateTour", model.state)
+	}
+	if view := model.View(); !strings.Contains(view, "Welcome to rulem") || !strings.Contains(view, "step 1 of 4") {
+		t.Errorf("first tour step not shown:\n%s", view)
+	}
+
+	model.Update(tuitest.Key("enter"))
+	model.Update(tuitest.Key("left"))
+	if model.tourStep != 0 {
+		t.Errorf("tourStep = %d after next and back, want 0", model.tourStep)
+	}
+
+	for range tourSteps[1:] {
+		model.Update(tuitest.Key("enter"))
+	}
+	if view := model.View(); !strings.Contains(view, `"args": ["mcp"]`) {
+		t.Errorf("last tour step should show the MCP configuration:\n%s", view)
+	}
+
+	model.Update(tuitest.Key("enter"))
+	if model.state != StateMenu {
+		t.Errorf("state = %v after the last step, want StateMenu", model.state)
+	}
+	saved, err := onboarding.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !saved.TourCompleted {
+		t.Error("finishing the tour should be saved")
+	}
+}
+
+func TestTour_Skip(t *testing.T) {
+	model, path := newOnboardingModel(t)
+	model.StartTour()
+	model.Update(tuitest.Key("esc"))
+
+	if model.state != StateMenu {
+		t.Errorf("state = %v after skipping, want StateMenu", model.state)
+	}
+	saved, _ := onboarding.Load(path)
+	if !saved.TourCompleted {
+		t.Error("skipping the tour should be saved")
+	}
+}
+
+func TestTip_Dismiss(t *testing.T) {
+	model, path := newOnboardingModel(t)
+
+	if view := model.View(); !strings.Contains(view, screenTips[StateMenu].text) {
+		t.Fatalf("menu tip not shown:\n%s", view)
+	}
+
+	model.Update(tuitest.Key("ctrl+t"))
+	if strings.Contains(model.View(), "Tip:") {
+		t.Error("dismissed tip is still shown")
+	}
+	saved, _ := onboarding.Load(path)
+	if !saved.TipDismissed("menu") {
+		t.Errorf("dismissed tip not saved, got %v", saved.DismissedTips)
+	}
+}
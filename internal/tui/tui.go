@@ -7,6 +7,9 @@
 // - Main navigation menu with filtering capabilities
 // - Save rules functionality for storing rule files in a central repository
 // - Import rules functionality for copying/linking rules to current directory
+// - Deploy rules functionality for copying/linking several rules at once
+// - Project status for comparing deployed rules with the central repository
+// - Manage rules functionality for moving, renaming and deleting stored rules
 // - Settings management for configuring storage locations
 // - GitHub integration for fetching rules from remote repositories
 // - Error handling and user feedback through consistent UI patterns
@@ -14,7 +17,10 @@
 // The TUI follows a state-based architecture where different application states
 // (menu, settings, save rules, etc.) are handled by specialized models that
 // implement the tea.Model interface. State transitions are managed through
-// custom message types and a centralized navigation system.
+// custom message types and a centralized navigation system: MainModel keeps
+// the navigation history for the session, so back (helpers.NavigateBackMsg)
+// returns to where the user came from, and shows it as breadcrumbs in the
+// status bar.
 //
 // Key Components:
 //   - MainModel: Root model that orchestrates the entire TUI application
@@ -27,14 +33,25 @@
 package tui
 
 import (
+	"context"
+	"strconv"
+
 	"rulem/internal/config"
 	"rulem/internal/logging"
+	"rulem/internal/notes"
+	"rulem/internal/onboarding"
+	"rulem/internal/repository"
 	"rulem/internal/tui/components"
+	"rulem/internal/tui/deploymenu"
 	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/helpers/navigation"
 	"rulem/internal/tui/importrulesmenu"
+	"rulem/internal/tui/managerulesmenu"
+	"rulem/internal/tui/projectstatusmenu"
 	"rulem/internal/tui/repostatusmenu"
 	saverulesmodel "rulem/internal/tui/saverulesmodel"
 	settingsmenu "rulem/internal/tui/settingsmenu"
+	"rulem/internal/tui/summarymenu"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -54,9 +71,64 @@ const (
 	StateSettings
 	StateSaveRules
 	StateImportCopy
+	StateDeploy
+	StateProjectStatus
+	StateManageRules
 	StateRepoStatus
+	StateSummary
+
+	// StateTour shows the first-run tour, see StartTour
+	StateTour
 )
 
+// maxBreadcrumbs is how many breadcrumbs the status bar shows before it
+// shortens the trail with an ellipsis
+const maxBreadcrumbs = 3
+
+// String returns the name of the state, as shown in the breadcrumbs
+func (s AppState) String() string {
+	switch s {
+	case StateMenu:
+		return "Menu"
+	case StateError:
+		return "Error"
+	case StateComingSoon:
+		return "Coming soon"
+	case StateQuitting:
+		return "Quitting"
+	case StateSettings:
+		return "Settings"
+	case StateSaveRules:
+		return "Save rules"
+	case StateImportCopy:
+		return "Import rules"
+	case StateDeploy:
+		return "Deploy rules"
+	case StateProjectStatus:
+		return "Project status"
+	case StateManageRules:
+		return "Manage rules"
+	case StateRepoStatus:
+		return "Repository status"
+	case StateSummary:
+		return "Weekly summary"
+	case StateTour:
+		return "Tour"
+	default:
+		return "Unknown"
+	}
+}
+
+// isScreen reports whether the state is a screen opened from the menu, shown
+// by the active model
+func (s AppState) isScreen() bool {
+	switch s {
+	case StateSettings, StateSaveRules, StateImportCopy, StateDeploy, StateProjectStatus, StateManageRules, StateRepoStatus, StateSummary:
+		return true
+	}
+	return false
+}
+
 // Custom messages for internal state transitions
 type (
 	NavigateMsg struct {
@@ -84,9 +156,17 @@ type item struct {
 	title       string
 	description string
 	state       AppState
+	badge       string // shown after the title, see helpers.MenuBadgesMsg
+}
+
+// Title returns the title followed by the badge in parentheses, if any
+func (i item) Title() string {
+	if i.badge == "" {
+		return i.title
+	}
+	return i.title + " (" + i.badge + ")"
 }
 
-func (i item) Title() string       { return i.title }
 func (i item) Description() string { return i.description }
 func (i item) FilterValue() string { return i.title }
 
@@ -108,10 +188,12 @@ func (i item) FilterValue() string { return i.title }
 // correspond to different views and behaviors. It maintains references to
 // configuration, logging, and UI components to provide a cohesive user experience.
 type MainModel struct {
-	config    *config.Config
-	logger    *logging.AppLogger
-	state     AppState
-	prevState AppState // For returning from error states
+	config *config.Config
+	logger *logging.AppLogger
+	state  AppState
+
+	// Navigation history of the session: the states left to reach state
+	history navigation.Stack[AppState]
 
 	// Main menu list
 	menu list.Model
@@ -122,6 +204,33 @@ type MainModel struct {
 	// Layout for consistent UI
 	layout components.LayoutModel
 
+	// Status bar shared by every screen, updated via components.StatusUpdateMsg
+	statusBar components.StatusBarModel
+
+	// Credential store heartbeat run at startup, and its latest result
+	checkKeyring func(context.Context) repository.KeyringStatus
+	keyring      repository.KeyringStatus
+
+	// Background sync, nil when disabled
+	scheduler *repository.Scheduler
+
+	// Prepared repositories and scans reused between visits to a screen
+	cache *helpers.ScreenCache
+
+	// Screen opened instead of the menu on launch, see OpenAtStart;
+	// StateMenu once it was opened or when there is none
+	startScreen AppState
+
+	// Tour and tip progress, nil when onboarding is off; tourStep is the
+	// tour page shown in StateTour
+	onboarding *onboarding.Store
+	tourStep   int
+
+	// Where the error screen writes diagnostic bundles, "" when it does not
+	// offer them; diagnosticsStatus reports the last bundle written
+	diagnosticsDir    string
+	diagnosticsStatus string
+
 	// Window dimensions for creating submodels
 	windowWidth  int
 	windowHeight int
@@ -145,11 +254,31 @@ func NewMainModel(cfg *config.Config, logger *logging.AppLogger) *MainModel {
 			description: "Import a rule file from the central rules repository, to the current directory.\nYou will have the option to either copy or link the rules file. \nYou can also select your AI assistant or IDE or CLI coding tool so we can customize the file for you.",
 			state:       StateImportCopy,
 		},
+		item{
+			title:       "🚀  Deploy rules",
+			description: "Tick any number of rules and copy or link them into the current project at once.\nEither every rule is copied or none is, and each is recorded in .rulem/deployed.yaml.",
+			state:       StateDeploy,
+		},
+		item{
+			title:       "🧭  Project status",
+			description: "Compare the rules deployed in the current project with the central repository.\nSee which are outdated, edited or missing and re-sync them.",
+			state:       StateProjectStatus,
+		},
+		item{
+			title:       "📁  Manage rules",
+			description: "Move, rename or delete the rules in your repositories.\nDeleted rules are moved to the trash unless permanent_delete is set.",
+			state:       StateManageRules,
+		},
 		item{
 			title:       "🔄  Refresh GitHub repositories",
 			description: "See whether your GitHub repositories are in sync and refetch them.\nRepositories with local changes are skipped so your edits are never lost.",
 			state:       StateRepoStatus,
 		},
+		item{
+			title:       "📊  Weekly summary",
+			description: "See the last week of rule activity: new and changed rules, the rules served most\nover MCP, and rules due for review or about to expire.",
+			state:       StateSummary,
+		},
 		item{
 			title:       "⚙️  Update settings",
 			description: "Modify your Rulem configuration settings, such as storage directory.",
@@ -176,15 +305,18 @@ func NewMainModel(cfg *config.Config, logger *logging.AppLogger) *MainModel {
 		config:    cfg,
 		logger:    logger,
 		state:     StateMenu,
-		prevState: StateMenu,
 		menu:      menuList,
 		layout:    layout,
+		statusBar: components.NewStatusBar(),
+		cache:     helpers.NewScreenCache(),
+
+		checkKeyring: repository.NewCredentialManager().CheckKeyring,
 	}
 }
 
 func (m *MainModel) Init() tea.Cmd {
 	m.logger.Info("MainModel initialized")
-	return nil
+	return tea.Batch(m.refreshMenu(), helpers.CheckKeyring(m.checkKeyring))
 }
 
 func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -196,8 +328,9 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.logger.Debug("window resize", "width", wm.Width, "height", wm.Height)
 	}
 
-	// Update layout first for size changes
+	// Update layout and status bar first for size changes
 	m.layout, _ = m.layout.Update(msg)
+	m.statusBar = m.statusBar.Update(msg)
 
 	// Single comprehensive switch statement handling all message types and states
 	switch msg := msg.(type) {
@@ -208,9 +341,18 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Handle window resize with validation
 		if msg.Width > 0 && msg.Height > 0 {
-			v := 14 // footer margins
+			v := 15 // footer margins and status bar
 			m.menu.SetSize(msg.Width-4, msg.Height-v)
 
+			// Screens need the window size, so the start screen opens with the first one
+			if start := m.startScreen; start != StateMenu {
+				m.startScreen = StateMenu
+				if selected, ok := m.menuItem(start); ok && m.state == StateMenu {
+					m.logger.Debug("Opening start screen", "state", start.String())
+					return m.handleMenuSelection(selected)
+				}
+			}
+
 			// Propagate size to active model if present
 			if m.activeModel != nil {
 				updatedModel, modelCmd := m.activeModel.Update(msg)
@@ -231,6 +373,9 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state = StateQuitting
 			return m, tea.Quit
 		}
+		if msg.String() == "ctrl+t" && m.dismissTip() {
+			return m, nil
+		}
 
 		// Handle keyboard input based on current state
 		switch m.state {
@@ -268,10 +413,13 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case StateTour:
+			return m.updateTour(msg)
+
 		case StateComingSoon:
 			switch msg.String() {
 			case "esc":
-				m.logger.LogStateTransition("MainModel", "StateComingSoon", "StateMenu")
+				m.logger.LogStateTransition("MainModel", StateComingSoon.String(), StateMenu.String())
 				m.state = StateMenu
 				m.comingSoonFeature = ""
 				m.layout = m.layout.ClearError()
@@ -281,16 +429,26 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case StateError:
 			switch msg.String() {
 			case "esc":
-				m.logger.LogStateTransition("MainModel", "StateError", string(rune(m.prevState)))
-				m.state = m.prevState
+				prev, ok := m.history.Back()
+				if !ok {
+					prev = StateMenu
+				}
+				m.logger.LogStateTransition("MainModel", m.state.String(), prev.String())
+				m.state = prev
 				m.err = nil
+				m.diagnosticsStatus = ""
 				m.layout = m.layout.ClearError()
 				return m, nil
+			case "d":
+				if m.diagnosticsDir != "" {
+					m.logger.LogUserAction("write_diagnostics", m.diagnosticsDir)
+					return m, m.writeDiagnostics()
+				}
 			}
 
-		case StateSettings, StateSaveRules, StateImportCopy, StateRepoStatus:
-			// Delegate all messages to active model - they handle their own navigation
-			if m.activeModel != nil {
+		default:
+			// Screens handle their own navigation: delegate all keys to the active model
+			if m.state.isScreen() && m.activeModel != nil {
 				updatedModel, modelCmd := m.activeModel.Update(msg)
 				m.activeModel = updatedModel.(MenuItemModel)
 				if modelCmd != nil {
@@ -311,8 +469,8 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case NavigateMsg:
 		// Handle navigation between states
-		m.logger.LogStateTransition("MainModel", string(rune(m.state)), string(rune(msg.State)))
-		m.prevState = m.state
+		m.logger.LogStateTransition("MainModel", m.state.String(), msg.State.String())
+		m.history.Visit(m.state, msg.State)
 		m.state = msg.State
 		m.err = nil
 		m.loading = false
@@ -324,12 +482,22 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle error display
 		m.logger.Error("Application error occurred", "error", msg.Err)
 		m.err = msg.Err
-		m.prevState = m.state
+		m.diagnosticsStatus = ""
+		m.history.Visit(m.state, StateError)
 		m.state = StateError
 		m.loading = false
 		m.layout = m.layout.SetError(msg.Err)
 		return m, nil
 
+	case diagnosticsWrittenMsg:
+		if msg.err != nil {
+			m.logger.Warn("Failed to write diagnostic bundle", "error", msg.err)
+		} else {
+			m.logger.Info("Diagnostic bundle written", "path", msg.path)
+		}
+		m.diagnosticsStatus = msg.status()
+		return m, nil
+
 	case ComingSoonMsg:
 		// Handle coming soon display
 		m.logger.Debug("Showing coming soon for feature", "feature", msg.Feature)
@@ -339,8 +507,58 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case helpers.NavigateToMainMenuMsg:
 		// Handle navigation back to main menu from any submodel
-		m.logger.LogStateTransition("MainModel", "FeatureState", "StateMenu")
-		return m.returnToMenu(), nil
+		m.logger.LogStateTransition("MainModel", m.state.String(), StateMenu.String())
+		return m.returnToMenu(), m.refreshMenu()
+
+	case helpers.NavigateBackMsg:
+		// Go back one step; screens are opened from the menu, so leaving one
+		// returns to the menu
+		prev, ok := m.history.Back()
+		if !ok || prev == StateMenu {
+			m.logger.LogStateTransition("MainModel", m.state.String(), StateMenu.String())
+			return m.returnToMenu(), m.refreshMenu()
+		}
+		m.logger.LogStateTransition("MainModel", m.state.String(), prev.String())
+		m.state = prev
+		return m, nil
+
+	case helpers.RefreshScreenMsg:
+		// Prepare and scan again: drop the cache and open the screen afresh
+		if !m.state.isScreen() {
+			return m, nil
+		}
+		m.logger.Debug("Refreshing screen", "state", m.state.String())
+		m.cache.Invalidate()
+		model := m.getOrInitializeModel(m.state)
+		if model == nil {
+			return m, nil
+		}
+		m.activeModel = model
+		return m, model.Init()
+
+	case components.StatusUpdateMsg:
+		// Already applied to the status bar above; not forwarded to submodels
+		return m, nil
+
+	case helpers.KeyringStatusMsg:
+		// Soft check: an unavailable store only warns, GitHub flows still
+		// report the underlying error if a PAT is saved anyway
+		m.keyring = msg.Status
+		if msg.Status.Available {
+			m.statusBar = m.statusBar.SetWarning("")
+		} else {
+			m.logger.Warn("Credential store unavailable", "error", msg.Status.Error, "guidance", msg.Status.Guidance)
+			m.statusBar = m.statusBar.SetWarning("🔑 keyring unavailable")
+		}
+		// Let the active screen (e.g. the repository status board) show it too
+		if m.activeModel != nil {
+			updatedModel, modelCmd := m.activeModel.Update(msg)
+			if menuModel, ok := updatedModel.(MenuItemModel); ok {
+				m.activeModel = menuModel
+			}
+			return m, modelCmd
+		}
+		return m, nil
 
 	case config.ReloadConfigMsg:
 		// Handle config reload after settings updates
@@ -352,6 +570,10 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.logger.Info("Configuration reloaded successfully")
 			m.config = msg.Config
 		}
+		return m, m.refreshMenu()
+
+	case helpers.MenuBadgesMsg:
+		m.setMenuBadges(msg)
 		return m, nil
 
 	default:
@@ -409,9 +631,44 @@ func (m *MainModel) handleMenuSelection(selectedItem item) (tea.Model, tea.Cmd)
 	return m, tea.Batch(cmds...)
 }
 
+// OpenAtStart opens the menu entry's screen for state instead of the main
+// menu when the TUI starts, as if it had been selected, so going back shows
+// the menu. It has no effect while the tour is shown.
+func (m *MainModel) OpenAtStart(state AppState) {
+	m.startScreen = state
+}
+
+// menuItem returns the menu entry that opens state
+func (m *MainModel) menuItem(state AppState) (item, bool) {
+	for _, listItem := range m.menu.Items() {
+		if entry, ok := listItem.(item); ok && entry.state == state {
+			return entry, true
+		}
+	}
+	return item{}, false
+}
+
+// SetScheduler makes the background sync's status available to screens
+func (m *MainModel) SetScheduler(scheduler *repository.Scheduler) {
+	m.scheduler = scheduler
+}
+
 // GetUIContext creates a UI context with current dimensions and app state
 func (m *MainModel) GetUIContext() helpers.UIContext {
-	return helpers.NewUIContext(m.windowWidth, m.windowHeight, m.config, m.logger)
+	ctx := helpers.NewUIContext(m.windowWidth, m.windowHeight, m.config, m.logger)
+	ctx.Keyring = m.keyring
+	ctx.Scheduler = m.scheduler
+	ctx.Cache = m.cache
+	if m.scheduler != nil {
+		m.cache.NoteSync(m.scheduler.Status().LastRun)
+	}
+	// Notes are reloaded for every screen, so changes made with `rulem note` show up
+	if store, err := notes.Load(notes.Path()); err != nil {
+		m.logger.Warn("Rule notes are unavailable", "error", err)
+	} else {
+		ctx.Notes = store
+	}
+	return ctx
 }
 
 // getOrInitializeModel always creates a fresh model to ensure up-to-date settings
@@ -437,10 +694,26 @@ func (m *MainModel) getOrInitializeModel(state AppState) MenuItemModel {
 		m.logger.Debug("Creating fresh import rules model")
 		return importrulesmenu.NewImportRulesModel(ctx)
 
+	case StateDeploy:
+		m.logger.Debug("Creating fresh deploy model")
+		return deploymenu.NewDeployModel(ctx)
+
+	case StateProjectStatus:
+		m.logger.Debug("Creating fresh project status model")
+		return projectstatusmenu.NewProjectStatusModel(ctx)
+
+	case StateManageRules:
+		m.logger.Debug("Creating fresh manage rules model")
+		return managerulesmenu.NewManageRulesModel(ctx)
+
 	case StateRepoStatus:
 		m.logger.Debug("Creating fresh repository status model")
 		return repostatusmenu.NewRepoStatusModel(ctx)
 
+	case StateSummary:
+		m.logger.Debug("Creating fresh summary model")
+		return summarymenu.NewSummaryModel(ctx)
+
 	default:
 		m.logger.Warn("Unknown state requested for model initialization", "state", state)
 		return nil
@@ -456,20 +729,59 @@ func (m *MainModel) View() string {
 	}
 
 	// Configure layout based on current state
+	var view string
 	switch m.state {
 	case StateMenu:
-		return m.viewMenu()
+		view = m.viewMenu()
 	case StateError:
-		return m.viewError()
+		view = m.viewError()
 	case StateComingSoon:
-		return m.viewComingSoon()
+		view = m.viewComingSoon()
+	case StateTour:
+		view = m.viewTour()
 	default:
 		// Use active model's view if available
 		if m.activeModel != nil {
-			return m.activeModel.View()
+			view = m.activeModel.View()
+		} else {
+			// Show coming soon for unimplemented states
+			view = m.viewComingSoon()
+		}
+	}
+
+	m.statusBar = m.statusBar.SetHints(m.statusHints()).SetBreadcrumbs(m.breadcrumbs())
+	return view + "\n" + m.viewTip() + m.statusBar.View()
+}
+
+// breadcrumbs returns where the user is on a screen: the screens in the
+// navigation history, then the steps of the active screen when it reports
+// them. The menu, errors and the tour have none.
+func (m *MainModel) breadcrumbs() string {
+	if !m.state.isScreen() {
+		return ""
+	}
+	var crumbs []string
+	for _, state := range append(m.history.States(), m.state) {
+		if state.isScreen() {
+			crumbs = append(crumbs, state.String())
 		}
-		// Show coming soon for unimplemented states
-		return m.viewComingSoon()
+	}
+	if steps, ok := m.activeModel.(helpers.Breadcrumber); ok {
+		crumbs = append(crumbs, steps.Breadcrumbs()...)
+	}
+	return navigation.Breadcrumbs(crumbs, maxBreadcrumbs)
+}
+
+// statusHints returns the global key hints for the status bar; screen-specific
+// keys stay in each screen's help text
+func (m *MainModel) statusHints() string {
+	switch m.state {
+	case StateMenu:
+		return "/ filter • q quit"
+	case StateTour:
+		return "esc skip tour • ctrl+c quit"
+	default:
+		return "esc back • ctrl+c quit"
 	}
 }
 
@@ -491,9 +803,45 @@ func (m *MainModel) hasValidDimensions() bool {
 	return m.windowWidth > 0 && m.windowHeight > 0
 }
 
+// refreshMenu returns the commands that update the status bar and the menu
+// badges for the current configuration
+func (m *MainModel) refreshMenu() tea.Cmd {
+	return tea.Batch(helpers.RefreshStatusBar(m.config), helpers.LoadMenuBadges(m.config, m.cache, m.logger))
+}
+
+// setMenuBadges shows the rule count after the entries that list rules and
+// the sync summary after the refresh entry
+func (m *MainModel) setMenuBadges(msg helpers.MenuBadgesMsg) {
+	rules := ""
+	if msg.Rules >= 0 {
+		rules = strconv.Itoa(msg.Rules)
+	}
+	for i, listItem := range m.menu.Items() {
+		menuItem, ok := listItem.(item)
+		if !ok {
+			continue
+		}
+		switch menuItem.state {
+		case StateImportCopy, StateDeploy, StateManageRules:
+			menuItem.badge = rules
+		case StateRepoStatus:
+			menuItem.badge = msg.Sync
+		default:
+			continue
+		}
+		m.menu.SetItem(i, menuItem)
+	}
+}
+
 // returnToMenu safely returns to the main menu and cleans up state
 func (m *MainModel) returnToMenu() tea.Model {
+	if m.state == StateSettings {
+		// Settings can sync, relocate or replace repositories without a new
+		// configuration, so nothing prepared or scanned before can be trusted
+		m.cache.Invalidate()
+	}
 	m.state = StateMenu
+	m.history.Clear()
 	m.activeModel = nil
 	m.err = nil
 	m.comingSoonFeature = ""
@@ -502,16 +850,27 @@ func (m *MainModel) returnToMenu() tea.Model {
 }
 
 func (m *MainModel) viewError() string {
+	helpText := "Press Esc to return • Ctrl+C to quit"
+	if m.diagnosticsDir != "" {
+		helpText = "Press Esc to return • d diagnostic bundle • Ctrl+C to quit"
+	}
 	m.layout = m.layout.SetConfig(components.LayoutConfig{
 		Title:    "❌ Error",
 		Subtitle: "Something went wrong",
-		HelpText: "Press Esc to return • Ctrl+C to quit",
+		HelpText: helpText,
 	})
 
 	errorContent := ""
 	if m.err != nil {
 		errorContent = m.err.Error()
 	}
+	if m.diagnosticsDir != "" {
+		status := m.diagnosticsStatus
+		if status == "" {
+			status = "Press d to save a diagnostic bundle for a bug report."
+		}
+		errorContent += "\n\n" + status
+	}
 
 	return m.layout.Render(errorContent)
 }
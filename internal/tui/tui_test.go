@@ -1,11 +1,19 @@
 package tui
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"rulem/internal/config"
 	"rulem/internal/logging"
 	"rulem/internal/repository"
+	"rulem/internal/tui/components"
+	"rulem/internal/tui/helpers"
+	"rulem/internal/tui/summarymenu"
+	"rulem/internal/tui/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func createTestConfigWithPath(path string) *config.Config {
@@ -40,8 +48,22 @@ func TestNewMainModel(t *testing.T) {
 		t.Errorf("Expected initial state to be StateMenu, got %v", model.state)
 	}
 
-	if model.prevState != StateMenu {
-		t.Errorf("Expected initial prevState to be StateMenu, got %v", model.prevState)
+	if model.history.Len() != 0 {
+		t.Errorf("Expected an empty navigation history, got %v", model.history.States())
+	}
+}
+
+// availableKeyring and unavailableKeyring stand in for the credential store
+// heartbeat so tests never touch the OS keyring
+func availableKeyring(context.Context) repository.KeyringStatus {
+	return repository.KeyringStatus{Checked: true, Available: true}
+}
+
+func unavailableKeyring(context.Context) repository.KeyringStatus {
+	return repository.KeyringStatus{
+		Checked:  true,
+		Error:    "The name org.freedesktop.secrets was not provided by any .service files",
+		Guidance: repository.KeyringGuidance("linux"),
 	}
 }
 
@@ -50,11 +72,74 @@ func TestMainModelInit(t *testing.T) {
 	logger, _ := logging.NewTestLogger()
 
 	model := NewMainModel(cfg, logger)
+	model.checkKeyring = availableKeyring
 	cmd := model.Init()
 
-	// Init should not return a command for the main model
-	if cmd != nil {
-		t.Error("Init should not return a command")
+	// Init schedules the initial status bar check and the keyring heartbeat
+	if cmd == nil {
+		t.Fatal("Init should return the status bar refresh command")
+	}
+	msgs := tuitest.Exec(cmd)
+	status, ok := tuitest.FindMsg[components.StatusUpdateMsg](msgs)
+	if !ok {
+		t.Fatalf("Expected StatusUpdateMsg in %v", msgs)
+	}
+	if status.Info.Repository != "Test Repository" || status.Info.Sync != "local only" {
+		t.Errorf("Unexpected status info: %+v", status.Info)
+	}
+	keyring, ok := tuitest.FindMsg[helpers.KeyringStatusMsg](msgs)
+	if !ok {
+		t.Fatalf("Expected KeyringStatusMsg in %v", msgs)
+	}
+	if !keyring.Status.Available {
+		t.Errorf("Unexpected keyring status: %+v", keyring.Status)
+	}
+}
+
+func TestMainModelKeyringHeartbeat(t *testing.T) {
+	cfg := createTestConfigWithPath("/test/path")
+	logger, _ := logging.NewTestLogger()
+
+	model := NewMainModel(cfg, logger)
+	model.checkKeyring = unavailableKeyring
+	model = tuitest.Send(t, model, append(tuitest.Exec(model.Init()), tea.WindowSizeMsg{Width: 100, Height: 40})...)
+
+	view := model.View()
+	lastLine := view[strings.LastIndex(view, "\n")+1:]
+	if !strings.Contains(lastLine, "keyring unavailable") {
+		t.Errorf("Status bar %q should warn about the keyring", lastLine)
+	}
+	if ctx := model.GetUIContext(); !ctx.Keyring.Checked || ctx.Keyring.Available {
+		t.Errorf("UI context should carry the heartbeat result, got %+v", ctx.Keyring)
+	}
+
+	// A later successful check clears the warning
+	model = tuitest.Send(t, model, helpers.KeyringStatusMsg{Status: availableKeyring(context.Background())})
+	view = model.View()
+	if strings.Contains(view[strings.LastIndex(view, "\n")+1:], "keyring") {
+		t.Error("warning should be cleared once the keyring is available")
+	}
+}
+
+func TestMainModelStatusBar(t *testing.T) {
+	cfg := createTestConfigWithPath("/test/path")
+	logger, _ := logging.NewTestLogger()
+
+	model := NewMainModel(cfg, logger)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	model.Update(components.StatusUpdateMsg{Info: components.StatusInfo{Repository: "Team Rules", Sync: "✅ in sync", Dirty: true}})
+
+	view := model.View()
+	lastLine := view[strings.LastIndex(view, "\n")+1:]
+	for _, want := range []string{"Team Rules", "in sync", "local changes", "q quit"} {
+		if !strings.Contains(lastLine, want) {
+			t.Errorf("Status bar %q missing %q", lastLine, want)
+		}
+	}
+
+	// Status updates are consumed by the main model, not forwarded
+	if _, cmd := model.Update(components.StatusUpdateMsg{}); cmd != nil {
+		t.Error("StatusUpdateMsg should not produce a command")
 	}
 }
 
@@ -168,6 +253,94 @@ func TestReturnToMenu(t *testing.T) {
 	}
 }
 
+// stepsModel stands in for a screen with several steps
+type stepsModel struct{ steps []string }
+
+func (m stepsModel) Init() tea.Cmd                       { return nil }
+func (m stepsModel) Update(tea.Msg) (tea.Model, tea.Cmd) { return m, nil }
+func (m stepsModel) View() string                        { return "steps" }
+func (m stepsModel) Breadcrumbs() []string               { return m.steps }
+
+func TestNavigationHistory(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	model := NewMainModel(createTestConfigWithPath("/test/path"), logger)
+	model = tuitest.Send(t, model, tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	model.activeModel = stepsModel{steps: []string{"Repository actions"}}
+	model = tuitest.Send(t, model, NavigateMsg{State: StateSettings})
+	if got, want := model.breadcrumbs(), "Settings › Repository actions"; got != want {
+		t.Errorf("breadcrumbs() = %q, want %q", got, want)
+	}
+	if view := model.View(); !strings.Contains(view, "Settings › Repository actions") {
+		t.Errorf("status bar does not show the breadcrumbs:\n%s", view)
+	}
+
+	// Leaving an error returns to the screen it was shown on
+	model = tuitest.Send(t, model, ErrorMsg{Err: &testError{"test error"}})
+	if model.breadcrumbs() != "" {
+		t.Errorf("errors should have no breadcrumbs, got %q", model.breadcrumbs())
+	}
+	model = tuitest.Send(t, model, tuitest.Key("esc"))
+	if model.state != StateSettings || model.activeModel == nil {
+		t.Fatalf("state = %v after leaving the error, want the settings screen", model.state)
+	}
+
+	// Back from a screen returns to the menu and clears the history
+	model = tuitest.Send(t, model, helpers.NavigateBackMsg{})
+	if model.state != StateMenu || model.activeModel != nil || model.history.Len() != 0 {
+		t.Errorf("state = %v, history = %v after going back; want the menu", model.state, model.history.States())
+	}
+	if model.breadcrumbs() != "" {
+		t.Errorf("the menu should have no breadcrumbs, got %q", model.breadcrumbs())
+	}
+}
+
+func TestMenuBadges(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	model := NewMainModel(createTestConfigWithPath("/test/path"), logger)
+	model = tuitest.Send(t, model, tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	model = tuitest.Send(t, model, helpers.MenuBadgesMsg{Rules: 142, Sync: "2 failed"})
+	view := model.View()
+	for _, want := range []string{"Import rules (Copy) (142)", "Manage rules (142)", "Refresh GitHub repositories (2 failed)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("menu should show %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "Save rules file (") {
+		t.Errorf("entries without a badge should show none:\n%s", view)
+	}
+
+	// Unknown counts remove the badges
+	model = tuitest.Send(t, model, helpers.MenuBadgesMsg{Rules: -1})
+	if view := model.View(); strings.Contains(view, "(142)") || strings.Contains(view, "(2 failed)") {
+		t.Errorf("badges should be cleared:\n%s", view)
+	}
+}
+
+func TestOpenAtStart(t *testing.T) {
+	logger, _ := logging.NewTestLogger()
+	model := NewMainModel(createTestConfigWithPath("/test/path"), logger)
+	model.OpenAtStart(StateSummary)
+
+	// The screen opens once the window size is known
+	model, cmd := tuitest.SendCmd(t, model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	if _, ok := model.activeModel.(*summarymenu.SummaryModel); !ok || cmd == nil {
+		t.Fatalf("activeModel = %T, want the summary screen opened", model.activeModel)
+	}
+	model = tuitest.Send(t, model, NavigateMsg{State: StateSummary})
+	if model.history.Len() != 1 {
+		t.Errorf("history = %v, want the menu to go back to", model.history.States())
+	}
+
+	// Later resizes keep the screen as it is
+	opened := model.activeModel
+	model = tuitest.Send(t, model, tea.WindowSizeMsg{Width: 100, Height: 30})
+	if model.activeModel != opened || model.startScreen != StateMenu {
+		t.Error("the start screen must only be opened once")
+	}
+}
+
 func TestGetOrInitializeModel(t *testing.T) {
 	cfg := createTestConfigWithPath("/test/path")
 	logger, _ := logging.NewTestLogger()
@@ -0,0 +1,44 @@
+package tuitest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"rulem/internal/tui/helpers"
+)
+
+// Clock is a fake clock that only moves when advanced
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a clock stopped at start
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the clock's current time
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// FreezeTime makes helpers.Now read from clock for the rest of the test, so
+// timestamps such as a repository's CreatedAt are deterministic. Tests using
+// it must not run in parallel.
+func FreezeTime(t testing.TB, clock *Clock) {
+	t.Helper()
+	original := helpers.Now
+	helpers.Now = clock.Now
+	t.Cleanup(func() { helpers.Now = original })
+}
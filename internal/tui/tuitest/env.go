@@ -0,0 +1,20 @@
+package tuitest
+
+import (
+	"testing"
+
+	"github.com/adrg/xdg"
+)
+
+// GoldenDataHome is the XDG data directory used by PinDataHome
+const GoldenDataHome = "/home/user/.local/share"
+
+// PinDataHome points xdg.DataHome at GoldenDataHome for the rest of the test,
+// so default storage and clone paths render the same on every machine. Tests
+// using it must not run in parallel.
+func PinDataHome(t testing.TB) {
+	t.Helper()
+	original := xdg.DataHome
+	xdg.DataHome = GoldenDataHome
+	t.Cleanup(func() { xdg.DataHome = original })
+}
@@ -0,0 +1,53 @@
+package tuitest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// ansiPattern matches CSI and OSC escape sequences
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+
+// Normalize strips ANSI escape sequences and trailing whitespace from every
+// line, so views compare the same regardless of the terminal color profile
+func Normalize(view string) string {
+	view = ansiPattern.ReplaceAllString(view, "")
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// Golden compares a rendered view with testdata/<name>.golden. With -update
+// the golden file is (re)written instead. Views are normalized first, see
+// Normalize.
+func Golden(t testing.TB, name, view string) {
+	t.Helper()
+	got := Normalize(view)
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create testdata dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file %s (run with -update to create it): %v", path, err)
+	}
+	if got != string(want) {
+		t.Errorf("view does not match %s (run with -update to accept)\n--- got ---\n%s--- want ---\n%s", path, got, want)
+	}
+}
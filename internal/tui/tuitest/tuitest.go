@@ -0,0 +1,202 @@
+// Package tuitest provides helpers for testing Bubble Tea models.
+//
+// It removes the boilerplate of building key messages, calling Update and
+// type-asserting the result, runs the commands a model returns, compares
+// rendered views against golden files, and freezes the clock used for
+// timestamps.
+//
+// Typical usage:
+//
+//	m = tuitest.Send(t, m, tuitest.Keys("down", "enter")...)
+//	m = tuitest.Send(t, m, tuitest.Type("my-rules")...)
+//	tuitest.Golden(t, "main_menu", m.View())
+//
+// Golden files live in the calling package's testdata directory. Regenerate
+// them with -update on the packages that use them, or with "task golden":
+//
+//	go test ./internal/tui/settingsmenu -run TestGoldenViews -update
+package tuitest
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyTypes maps key names as reported by tea.KeyMsg.String() to key types
+var keyTypes = map[string]tea.KeyType{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"tab":       tea.KeyTab,
+	"shift+tab": tea.KeyShiftTab,
+	"backspace": tea.KeyBackspace,
+	"delete":    tea.KeyDelete,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"space":     tea.KeySpace,
+	"ctrl+c":    tea.KeyCtrlC,
+	"ctrl+o":    tea.KeyCtrlO,
+	"ctrl+r":    tea.KeyCtrlR,
+	"ctrl+s":    tea.KeyCtrlS,
+	"ctrl+t":    tea.KeyCtrlT,
+}
+
+// Key builds a key message from its name, using the same names as
+// tea.KeyMsg.String() ("enter", "esc", "shift+tab", "ctrl+c", ...).
+// Any other string is sent as typed runes, so Key("q") is the q key.
+func Key(name string) tea.KeyMsg {
+	if kt, ok := keyTypes[name]; ok {
+		return tea.KeyMsg{Type: kt}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+// Keys builds one key message per name, see Key
+func Keys(names ...string) []tea.Msg {
+	msgs := make([]tea.Msg, len(names))
+	for i, name := range names {
+		msgs[i] = Key(name)
+	}
+	return msgs
+}
+
+// Type builds one rune key message per character of text, as if typed
+func Type(text string) []tea.Msg {
+	var msgs []tea.Msg
+	for _, r := range text {
+		msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return msgs
+}
+
+// Send feeds msgs to the model in order and returns the updated model.
+// Commands returned by Update are discarded; use SendCmd or Run when they matter.
+func Send[M tea.Model](t testing.TB, m M, msgs ...tea.Msg) M {
+	t.Helper()
+	m, _ = SendCmd(t, m, msgs...)
+	return m
+}
+
+// SendCmd feeds msgs to the model in order and returns the updated model and
+// the command returned by the last Update
+func SendCmd[M tea.Model](t testing.TB, m M, msgs ...tea.Msg) (M, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, msg := range msgs {
+		var updated tea.Model
+		updated, cmd = m.Update(msg)
+		next, ok := updated.(M)
+		if !ok {
+			t.Fatalf("Update returned %T, want %T", updated, m)
+		}
+		m = next
+	}
+	return m, cmd
+}
+
+// Exec runs a command and returns the messages it produces. Batches are
+// flattened and nil commands or messages are dropped. Commands run
+// synchronously, so timer commands such as spinner ticks block until they
+// fire; Run skips them instead.
+func Exec(cmd tea.Cmd) []tea.Msg {
+	return execWithin(cmd, 0)
+}
+
+// Filter reports whether a message produced by a command should be fed back
+// into the model by Run
+type Filter func(tea.Msg) bool
+
+const (
+	// maxRunSteps bounds Run so a model that keeps producing messages fails
+	// the test instead of hanging
+	maxRunSteps = 100
+
+	// timerTimeout is how long Run waits for a command. Commands still running
+	// after it are timers (cursor blinks, spinner ticks, tea.Tick) and are
+	// dropped, as if the clock never reached them.
+	timerTimeout = 50 * time.Millisecond
+)
+
+// Run feeds msgs to the model, then keeps executing the returned commands
+// and feeding their messages back until no command is left, like the Bubble
+// Tea runtime would. Timer commands never fire (see timerTimeout). Messages
+// rejected by keep are dropped; pass nil to keep everything. It returns the
+// final model and every message that was fed back.
+func Run[M tea.Model](t testing.TB, m M, keep Filter, msgs ...tea.Msg) (M, []tea.Msg) {
+	t.Helper()
+	var produced []tea.Msg
+	queue := msgs
+	for steps := 0; len(queue) > 0; steps++ {
+		if steps >= maxRunSteps {
+			t.Fatalf("model still producing messages after %d steps", maxRunSteps)
+		}
+		var cmd tea.Cmd
+		m, cmd = SendCmd(t, m, queue[0])
+		queue = queue[1:]
+		for _, msg := range execWithin(cmd, timerTimeout) {
+			if keep != nil && !keep(msg) {
+				continue
+			}
+			produced = append(produced, msg)
+			queue = append(queue, msg)
+		}
+	}
+	return m, produced
+}
+
+// execWithin runs cmd and flattens batches. With a non-zero timeout, commands
+// that do not return in time are abandoned and produce no message.
+func execWithin(cmd tea.Cmd, timeout time.Duration) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+
+	var msg tea.Msg
+	if timeout == 0 {
+		msg = cmd()
+	} else {
+		done := make(chan tea.Msg, 1)
+		go func() { done <- cmd() }()
+		select {
+		case msg = <-done:
+		case <-time.After(timeout):
+			return nil
+		}
+	}
+
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, c := range batch {
+			msgs = append(msgs, execWithin(c, timeout)...)
+		}
+		return msgs
+	}
+	if msg == nil {
+		return nil
+	}
+	return []tea.Msg{msg}
+}
+
+// FindMsg returns the first message of type T
+func FindMsg[T tea.Msg](msgs []tea.Msg) (T, bool) {
+	for _, msg := range msgs {
+		if found, ok := msg.(T); ok {
+			return found, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Msg returns a command that produces msg, standing in for a command that
+// would do real work (I/O, network) in tests
+func Msg(msg tea.Msg) tea.Cmd {
+	return func() tea.Msg { return msg }
+}
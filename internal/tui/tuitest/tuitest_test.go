@@ -0,0 +1,122 @@
+package tuitest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"rulem/internal/tui/helpers"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// echoModel records typed text and answers "enter" with a command chain
+type echoModel struct {
+	text  string
+	saved bool
+}
+
+type saveMsg struct{}
+type savedMsg struct{}
+type tickMsg struct{}
+
+func (m echoModel) Init() tea.Cmd { return nil }
+
+func (m echoModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "enter" {
+			return m, tea.Batch(Msg(saveMsg{}), nil, tea.Tick(time.Hour, func(time.Time) tea.Msg { return tickMsg{} }))
+		}
+		m.text += msg.String()
+	case saveMsg:
+		return m, Msg(savedMsg{})
+	case savedMsg:
+		m.saved = true
+	}
+	return m, nil
+}
+
+func (m echoModel) View() string { return "\x1b[1m" + m.text + "\x1b[0m   \n\n" }
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "enter", want: "enter"},
+		{name: "shift+tab", want: "shift+tab"},
+		{name: "ctrl+c", want: "ctrl+c"},
+		{name: "q", want: "q"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key(tt.name).String(); got != tt.want {
+				t.Errorf("Key(%q).String() = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendAndType(t *testing.T) {
+	m := Send(t, echoModel{}, Type("abc")...)
+	if m.text != "abc" {
+		t.Errorf("text = %q, want %q", m.text, "abc")
+	}
+}
+
+func TestRun(t *testing.T) {
+	m, msgs := Run(t, echoModel{}, nil, Key("enter"))
+	if !m.saved {
+		t.Error("expected command chain to be run")
+	}
+	if _, ok := FindMsg[savedMsg](msgs); !ok {
+		t.Errorf("expected savedMsg in %v", msgs)
+	}
+	if _, ok := FindMsg[tickMsg](msgs); ok {
+		t.Error("timer commands should not fire")
+	}
+
+	m, _ = Run(t, echoModel{}, func(msg tea.Msg) bool {
+		_, isSave := msg.(saveMsg)
+		return !isSave
+	}, Key("enter"))
+	if m.saved {
+		t.Error("filtered message should not be fed back")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got := Normalize(echoModel{text: "hi"}.View())
+	if got != "hi\n" {
+		t.Errorf("Normalize = %q", got)
+	}
+	if strings.Contains(got, "\x1b") {
+		t.Error("escape sequences should be stripped")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, "echo", Send(t, echoModel{}, Type("golden")...).View())
+}
+
+func TestFreezeTime(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := NewClock(start)
+
+	t.Run("frozen", func(t *testing.T) {
+		FreezeTime(t, clock)
+		if !helpers.Now().Equal(start) {
+			t.Errorf("helpers.Now() = %v, want %v", helpers.Now(), start)
+		}
+		clock.Advance(time.Minute)
+		if got := helpers.Now().Sub(start); got != time.Minute {
+			t.Errorf("clock advanced by %v, want 1m", got)
+		}
+	})
+
+	if helpers.Now().Equal(clock.Now()) {
+		t.Error("helpers.Now should be restored after the test")
+	}
+}
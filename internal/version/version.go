@@ -0,0 +1,110 @@
+// Package version reports and compares rulem release versions.
+//
+// The running version is stamped by GoReleaser via
+// -ldflags "-X rulem/internal/version.version=<tag>".
+//
+// Versions are release tags such as "v1.4.0" or "1.4"; the leading "v" is
+// optional and missing components count as zero. Pre-release and build suffixes
+// ("-rc.1", "+dirty") are ignored, so "1.4.0-rc.1" compares equal to "1.4.0".
+// Go pseudo-versions of untagged commits are treated like development builds.
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Dev is the version reported by builds without release information
+const Dev = "dev"
+
+// version is set at release time via -ldflags
+var version = Dev
+
+// pseudoVersionSuffix matches the timestamp and commit hash of a Go pseudo-version
+// such as v0.0.0-20240102150405-abcdef123456
+var pseudoVersionSuffix = regexp.MustCompile(`\d{14}-[0-9a-f]{12}$`)
+
+// Current returns the release tag of the running binary. GoReleaser stamps it
+// into `version`, but for builds that skip those ldflags (`go install`, `go build`)
+// fall back to the module version the toolchain recorded, which is the git tag it resolved.
+func Current() string {
+	if version != Dev {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return version
+}
+
+// IsRelease reports whether v is a comparable release version rather than a
+// development build
+func IsRelease(v string) bool {
+	_, err := parse(v)
+	return err == nil
+}
+
+// Compare returns -1, 0 or 1 when a is older than, equal to or newer than b.
+// It returns an error if either version is not a release version.
+func Compare(a, b string) (int, error) {
+	pa, err := parse(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parse(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1, nil
+		case pa[i] > pb[i]:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// AtLeast reports whether current is the same as or newer than minimum
+func AtLeast(current, minimum string) (bool, error) {
+	cmp, err := Compare(current, minimum)
+	if err != nil {
+		return false, err
+	}
+	return cmp >= 0, nil
+}
+
+// parse splits a version into major, minor and patch numbers
+func parse(v string) ([3]int, error) {
+	var parts [3]int
+
+	s := strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if pseudoVersionSuffix.MatchString(strings.SplitN(s, "+", 2)[0]) {
+		return parts, fmt.Errorf("pseudo-version %q is not a release", v)
+	}
+	if i := strings.IndexAny(s, "-+"); i != -1 {
+		s = s[:i]
+	}
+	if s == "" {
+		return parts, fmt.Errorf("invalid version %q", v)
+	}
+
+	fields := strings.Split(s, ".")
+	if len(fields) > len(parts) {
+		return parts, fmt.Errorf("invalid version %q: too many components", v)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, fmt.Errorf("invalid version %q", v)
+		}
+		parts[i] = n
+	}
+
+	return parts, nil
+}
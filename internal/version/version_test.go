@@ -0,0 +1,54 @@
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    int
+		wantErr bool
+	}{
+		{a: "1.2.3", b: "1.2.3", want: 0},
+		{a: "v1.2.3", b: "1.2.3", want: 0},
+		{a: "1.2", b: "1.2.0", want: 0},
+		{a: "1.10.0", b: "1.9.9", want: 1},
+		{a: "0.9.0", b: "1.0.0", want: -1},
+		{a: "1.4.0-rc.1", b: "1.4.0", want: 0},
+		{a: "2", b: "1.99.99", want: 1},
+		{a: Dev, b: "1.0.0", wantErr: true},
+		{a: "v0.0.0-20240102150405-abcdef123456", b: "1.0.0", wantErr: true},
+		{a: "1.0.0", b: "", wantErr: true},
+		{a: "1.0.0.0", b: "1.0.0", wantErr: true},
+		{a: "1.x", b: "1.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			got, err := Compare(tt.a, tt.b)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Compare returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	if ok, err := AtLeast("1.5.0", "1.4.2"); err != nil || !ok {
+		t.Errorf("AtLeast(1.5.0, 1.4.2) = %v, %v; want true", ok, err)
+	}
+	if ok, err := AtLeast("1.4.1", "1.4.2"); err != nil || ok {
+		t.Errorf("AtLeast(1.4.1, 1.4.2) = %v, %v; want false", ok, err)
+	}
+	if IsRelease(Dev) {
+		t.Error("dev builds should not be treated as releases")
+	}
+}
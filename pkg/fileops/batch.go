@@ -0,0 +1,214 @@
+package fileops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Batch copies
+//
+// AtomicCopy makes one file appear complete or not at all. Operations that
+// write several files, such as deploying a set of rules into a project, use
+// AtomicBatchCopy so that either every destination is written or none is
+// changed. All sources are first copied to temporary files next to their
+// destinations; only when every copy is staged are they renamed into place,
+// and a failed rename puts the files that were already replaced back.
+
+// CopyOp is one copy of an AtomicBatchCopy
+type CopyOp struct {
+	Src  string // Path of the file to copy
+	Dest string // Path to write, replaced when it exists
+	Data []byte // Content to write instead of Src's, when not nil
+}
+
+// stagedCopy tracks one CopyOp through staging and commit
+type stagedCopy struct {
+	op        CopyOp
+	temp      string // Staged copy of Src, "" once renamed into place
+	backup    string // Previous Dest moved aside, "" when there was none
+	committed bool
+}
+
+// AtomicBatchCopy copies every op.Src to its op.Dest as one operation: when it
+// returns nil all destinations hold their new content, and when it returns an
+// error every destination is as it was before the call.
+//
+// The copy happens in two phases:
+//  1. Every source is copied to a temporary file in its destination directory
+//     and synced to disk; any failure removes the staged files
+//  2. Existing destinations are moved aside and the staged files are renamed
+//     into place; a failed rename restores the destinations already replaced
+//
+// Parameters:
+//   - ops: The copies to make. Destinations must be distinct and their
+//     directories must exist; a source may be the destination of another op,
+//     since all sources are read before anything is replaced. An op with Data
+//     writes it instead of copying Src, e.g. a rule rewritten for an editor.
+//
+// Returns:
+//   - error: Validation, staging or rename errors, joined with any error met
+//     while rolling back
+//
+// Security considerations:
+//   - Paths should be validated before calling this function, as with AtomicCopy
+//   - Written files get permissions 0644
+//
+// Usage example:
+//
+//	err := fileops.AtomicBatchCopy([]fileops.CopyOp{
+//	    {Src: "/rules/go.md", Dest: "/project/.cursor/rules/go.md"},
+//	    {Src: "/rules/style.md", Dest: "/project/.cursor/rules/style.md"},
+//	})
+//	if err != nil {
+//	    log.Fatalf("Deploy failed, project unchanged: %v", err)
+//	}
+//
+// Note: Rollback is best effort. If the process dies during the second phase,
+// the originals moved aside remain as hidden ".bak" files next to their
+// destinations.
+func AtomicBatchCopy(ops []CopyOp) error {
+	seen := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		dest := filepath.Clean(op.Dest)
+		if seen[dest] {
+			return fmt.Errorf("duplicate destination in batch copy: %s", op.Dest)
+		}
+		seen[dest] = true
+	}
+
+	staged := make([]stagedCopy, 0, len(ops))
+	defer func() {
+		for _, s := range staged {
+			if s.temp != "" {
+				os.Remove(s.temp)
+			}
+		}
+	}()
+
+	for _, op := range ops {
+		temp, err := stageCopy(op)
+		if err != nil {
+			return fmt.Errorf("failed to stage copy to %s: %w", op.Dest, err)
+		}
+		staged = append(staged, stagedCopy{op: op, temp: temp})
+	}
+
+	for i := range staged {
+		if err := staged[i].commit(); err != nil {
+			err = fmt.Errorf("failed to commit copy to %s: %w", staged[i].op.Dest, err)
+			return errors.Join(err, rollbackCopies(staged[:i+1]))
+		}
+	}
+
+	for _, s := range staged {
+		if s.backup != "" {
+			os.Remove(s.backup)
+		}
+	}
+	return nil
+}
+
+// stageCopy writes the content of op to a new temporary file in the directory
+// of its destination and returns its path
+func stageCopy(op CopyOp) (string, error) {
+	var src io.Reader = bytes.NewReader(op.Data)
+	if op.Data == nil {
+		srcFile, err := os.Open(op.Src)
+		if err != nil {
+			return "", fmt.Errorf("failed to open source file: %w", err)
+		}
+		defer srcFile.Close()
+		src = srcFile
+	}
+
+	dest := op.Dest
+	tempFile, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempPath := tempFile.Name()
+
+	err = func() error {
+		defer tempFile.Close()
+		if err := tempFile.Chmod(0644); err != nil {
+			return fmt.Errorf("failed to set file permissions: %w", err)
+		}
+		if _, err := io.Copy(tempFile, src); err != nil {
+			return fmt.Errorf("failed to copy file contents: %w", err)
+		}
+		if err := tempFile.Sync(); err != nil {
+			return fmt.Errorf("failed to sync file: %w", err)
+		}
+		return tempFile.Close()
+	}()
+	if err != nil {
+		os.Remove(tempPath)
+		return "", err
+	}
+	return tempPath, nil
+}
+
+// commit moves an existing destination aside and renames the staged copy
+// into its place. On failure the destination is left as it was.
+func (s *stagedCopy) commit() error {
+	dest := s.op.Dest
+	if _, err := os.Lstat(dest); err == nil {
+		// Reserve a unique name for the original, then move it there
+		placeholder, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*.bak")
+		if err != nil {
+			return fmt.Errorf("failed to create backup file: %w", err)
+		}
+		placeholder.Close()
+		if err := os.Rename(dest, placeholder.Name()); err != nil {
+			os.Remove(placeholder.Name())
+			return fmt.Errorf("failed to back up existing file: %w", err)
+		}
+		s.backup = placeholder.Name()
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to check destination: %w", err)
+	}
+
+	if err := os.Rename(s.temp, dest); err != nil {
+		if s.backup != "" {
+			if restoreErr := os.Rename(s.backup, dest); restoreErr == nil {
+				s.backup = ""
+			}
+		}
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+	s.temp = ""
+	s.committed = true
+	return nil
+}
+
+// rollbackCopies undoes the committed copies in reverse order, restoring the
+// original files or removing the new ones
+func rollbackCopies(staged []stagedCopy) error {
+	var errs []error
+	for i := len(staged) - 1; i >= 0; i-- {
+		s := &staged[i]
+		switch {
+		case s.committed && s.backup != "":
+			if err := os.Rename(s.backup, s.op.Dest); err != nil {
+				errs = append(errs, fmt.Errorf("failed to restore %s: %w", s.op.Dest, err))
+				continue
+			}
+			s.backup = ""
+		case s.committed:
+			if err := os.Remove(s.op.Dest); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", s.op.Dest, err))
+			}
+		case s.backup != "":
+			// The rename of the staged copy failed and restoring the original
+			// in commit did too
+			if err := os.Rename(s.backup, s.op.Dest); err != nil {
+				errs = append(errs, fmt.Errorf("failed to restore %s: %w", s.op.Dest, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,174 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dirEntries returns the names in dir, to check that no temporary or backup
+// files are left behind
+func dirEntries(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", dir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestAtomicBatchCopy(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	goRule := createTestFile(t, srcDir, "go.md", "# go")
+	style := createTestFile(t, srcDir, "style.md", "# new style")
+	createTestFile(t, destDir, "style.md", "# old style")
+
+	err := AtomicBatchCopy([]CopyOp{
+		{Src: goRule, Dest: filepath.Join(destDir, "go.md")},
+		{Src: style, Dest: filepath.Join(destDir, "style.md")},
+	})
+	if err != nil {
+		t.Fatalf("AtomicBatchCopy failed: %v", err)
+	}
+
+	if got := readFileContent(t, filepath.Join(destDir, "go.md")); got != "# go" {
+		t.Errorf("go.md = %q, want the copied content", got)
+	}
+	if got := readFileContent(t, filepath.Join(destDir, "style.md")); got != "# new style" {
+		t.Errorf("style.md = %q, want the existing file replaced", got)
+	}
+	info, err := os.Stat(filepath.Join(destDir, "go.md"))
+	if err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("go.md mode = %v, %v; want 0644", info.Mode().Perm(), err)
+	}
+	if names := dirEntries(t, destDir); len(names) != 2 {
+		t.Errorf("destination holds %v, want only go.md and style.md", names)
+	}
+
+	if err := AtomicBatchCopy(nil); err != nil {
+		t.Errorf("an empty batch must succeed, got %v", err)
+	}
+}
+
+func TestAtomicBatchCopyStagingFailure(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	goRule := createTestFile(t, srcDir, "go.md", "# go")
+	createTestFile(t, destDir, "go.md", "# old go")
+
+	err := AtomicBatchCopy([]CopyOp{
+		{Src: goRule, Dest: filepath.Join(destDir, "go.md")},
+		{Src: filepath.Join(srcDir, "missing.md"), Dest: filepath.Join(destDir, "missing.md")},
+	})
+	if err == nil {
+		t.Fatal("a missing source must fail the batch")
+	}
+
+	if got := readFileContent(t, filepath.Join(destDir, "go.md")); got != "# old go" {
+		t.Errorf("go.md = %q, want it unchanged", got)
+	}
+	if names := dirEntries(t, destDir); len(names) != 1 {
+		t.Errorf("destination holds %v, want only the original go.md", names)
+	}
+}
+
+func TestAtomicBatchCopyRollback(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	goRule := createTestFile(t, srcDir, "go.md", "# go")
+	style := createTestFile(t, srcDir, "style.md", "# new style")
+	python := createTestFile(t, srcDir, "python.md", "# python")
+	createTestFile(t, destDir, "style.md", "# old style")
+
+	// A non-empty directory cannot be replaced by a file, so the last op
+	// fails after the first two were committed
+	blocked := filepath.Join(destDir, "python.md")
+	if err := os.Mkdir(blocked, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	createTestFile(t, blocked, "keep.md", "# keep")
+
+	err := AtomicBatchCopy([]CopyOp{
+		{Src: goRule, Dest: filepath.Join(destDir, "go.md")},
+		{Src: style, Dest: filepath.Join(destDir, "style.md")},
+		{Src: python, Dest: blocked},
+	})
+	if err == nil {
+		t.Fatal("replacing a directory must fail the batch")
+	}
+	if !strings.Contains(err.Error(), "python.md") {
+		t.Errorf("error %q should name the failed destination", err)
+	}
+
+	if fileExists(filepath.Join(destDir, "go.md")) {
+		t.Error("the new go.md must be removed on rollback")
+	}
+	if got := readFileContent(t, filepath.Join(destDir, "style.md")); got != "# old style" {
+		t.Errorf("style.md = %q, want the original restored", got)
+	}
+	if got := readFileContent(t, filepath.Join(blocked, "keep.md")); got != "# keep" {
+		t.Errorf("keep.md = %q, want the directory untouched", got)
+	}
+	if names := dirEntries(t, destDir); len(names) != 2 {
+		t.Errorf("destination holds %v, want only style.md and python.md", names)
+	}
+}
+
+func TestAtomicBatchCopyDuplicateDestination(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	goRule := createTestFile(t, srcDir, "go.md", "# go")
+	style := createTestFile(t, srcDir, "style.md", "# style")
+
+	err := AtomicBatchCopy([]CopyOp{
+		{Src: goRule, Dest: filepath.Join(destDir, "rule.md")},
+		{Src: style, Dest: filepath.Join(destDir, "nested", "..", "rule.md")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "duplicate destination") {
+		t.Fatalf("AtomicBatchCopy() error = %v, want a duplicate destination error", err)
+	}
+	if names := dirEntries(t, destDir); len(names) != 0 {
+		t.Errorf("destination holds %v, want nothing written", names)
+	}
+}
+
+func TestAtomicBatchCopySwap(t *testing.T) {
+	dir := t.TempDir()
+	a := createTestFile(t, dir, "a.md", "# a")
+	b := createTestFile(t, dir, "b.md", "# b")
+
+	// Sources are read before anything is replaced, so two files can swap
+	if err := AtomicBatchCopy([]CopyOp{{Src: a, Dest: b}, {Src: b, Dest: a}}); err != nil {
+		t.Fatalf("AtomicBatchCopy failed: %v", err)
+	}
+	if readFileContent(t, a) != "# b" || readFileContent(t, b) != "# a" {
+		t.Errorf("a.md = %q, b.md = %q; want them swapped", readFileContent(t, a), readFileContent(t, b))
+	}
+}
+
+func TestAtomicBatchCopyData(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	goRule := createTestFile(t, srcDir, "go.md", "---\nname: go\n---\n# go")
+
+	// Data is written as is; the source is not read
+	err := AtomicBatchCopy([]CopyOp{
+		{Src: goRule, Dest: filepath.Join(destDir, "go.mdc"), Data: []byte("# go")},
+		{Src: filepath.Join(srcDir, "missing.md"), Dest: filepath.Join(destDir, "empty.md"), Data: []byte{}},
+	})
+	if err != nil {
+		t.Fatalf("AtomicBatchCopy failed: %v", err)
+	}
+	if got := readFileContent(t, filepath.Join(destDir, "go.mdc")); got != "# go" {
+		t.Errorf("go.mdc = %q, want Data written", got)
+	}
+	if got := readFileContent(t, filepath.Join(destDir, "empty.md")); got != "" {
+		t.Errorf("empty.md = %q, want an empty file", got)
+	}
+}
@@ -0,0 +1,57 @@
+package fileops
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// CollisionStrategy is what a save does when its destination file already
+// exists
+type CollisionStrategy string
+
+const (
+	CollisionAsk       CollisionStrategy = "ask"       // Ask the user; non-interactive saves fail instead
+	CollisionRename    CollisionStrategy = "rename"    // Save under a free name with a numeric suffix, e.g. rule-2.md
+	CollisionOverwrite CollisionStrategy = "overwrite" // Replace the existing file
+)
+
+// CollisionStrategies lists every strategy for help text and validation
+var CollisionStrategies = []CollisionStrategy{CollisionAsk, CollisionRename, CollisionOverwrite}
+
+// ParseCollisionStrategy returns the named strategy; an empty name is
+// CollisionAsk
+func ParseCollisionStrategy(name string) (CollisionStrategy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return CollisionAsk, nil
+	}
+	for _, strategy := range CollisionStrategies {
+		if CollisionStrategy(name) == strategy {
+			return strategy, nil
+		}
+	}
+	return "", fmt.Errorf("unknown collision strategy %q (must be %q, %q or %q)",
+		name, CollisionAsk, CollisionRename, CollisionOverwrite)
+}
+
+// maxCollisionSuffix bounds the search for a free name
+const maxCollisionSuffix = 10000
+
+// UniqueFilename returns name if it is not taken, or else the first free name
+// with a numeric suffix before the extension: rule.md, rule-2.md, rule-3.md
+// and so on. name may include directories; only the last element changes.
+func UniqueFilename(name string, taken func(name string) bool) (string, error) {
+	if !taken(name) {
+		return name, nil
+	}
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for i := 2; i <= maxCollisionSuffix; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", stem, i, ext)
+		if !taken(candidate) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free name found for %s", name)
+}
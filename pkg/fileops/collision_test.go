@@ -0,0 +1,58 @@
+package fileops
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseCollisionStrategy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    CollisionStrategy
+		wantErr bool
+	}{
+		{input: "", want: CollisionAsk},
+		{input: "ask", want: CollisionAsk},
+		{input: " Rename ", want: CollisionRename},
+		{input: "overwrite", want: CollisionOverwrite},
+		{input: "skip", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCollisionStrategy(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCollisionStrategy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCollisionStrategy(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueFilename(t *testing.T) {
+	tests := []struct {
+		name  string
+		taken []string
+		want  string
+	}{
+		{name: "rule.md", want: "rule.md"},
+		{name: "rule.md", taken: []string{"rule.md"}, want: "rule-2.md"},
+		{name: "rule.md", taken: []string{"rule.md", "rule-2.md", "rule-3.md"}, want: "rule-4.md"},
+		{name: "AGENTS", taken: []string{"AGENTS"}, want: "AGENTS-2"},
+		{name: "go/style.md", taken: []string{"go/style.md"}, want: "go/style-2.md"},
+	}
+	for _, tt := range tests {
+		got, err := UniqueFilename(tt.name, func(name string) bool { return slices.Contains(tt.taken, name) })
+		if err != nil {
+			t.Errorf("UniqueFilename(%q) error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("UniqueFilename(%q) with %v taken = %q, want %q", tt.name, tt.taken, got, tt.want)
+		}
+	}
+
+	if _, err := UniqueFilename("rule.md", func(string) bool { return true }); err == nil {
+		t.Error("UniqueFilename() with every name taken should fail")
+	}
+}
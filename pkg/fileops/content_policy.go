@@ -0,0 +1,276 @@
+package fileops
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContentAction is what a content policy does with content matching one of its
+// categories of suspicious patterns
+type ContentAction string
+
+const (
+	ContentBlock ContentAction = "block" // Reject the content
+	ContentWarn  ContentAction = "warn"  // Accept the content, reporting the finding
+	ContentAllow ContentAction = "allow" // Accept the content silently
+	ContentStrip ContentAction = "strip" // Accept the content with the matches removed, reporting them (hidden text only)
+)
+
+// ContentCategory is a category of suspicious patterns checked by a content policy
+type ContentCategory string
+
+const (
+	ContentScripts         ContentCategory = "scripts"          // Script tags, javascript: URLs, eval(, event handlers
+	ContentDataURIs        ContentCategory = "data_uris"        // Data URIs carrying HTML or scripts
+	ContentControlChars    ContentCategory = "control_chars"    // Control characters other than newlines and tabs
+	ContentHiddenText      ContentCategory = "hidden_text"      // Invisible characters, bidi controls and homoglyphs, see ScanHiddenText
+	ContentPromptInjection ContentCategory = "prompt_injection" // Likely prompt injections, see ScanPromptInjection
+)
+
+// ContentCategories lists every category, in the order content is checked
+var ContentCategories = []ContentCategory{ContentControlChars, ContentHiddenText, ContentScripts, ContentDataURIs, ContentPromptInjection}
+
+// Content policy profiles
+const (
+	ContentProfileStrict     = "strict"     // Block every category
+	ContentProfileStandard   = "standard"   // Block scripts, data URIs and control characters; strip hidden text; warn on prompt injection
+	ContentProfilePermissive = "permissive" // Allow scripts and data URIs, e.g. for web development rules; warn on the rest
+)
+
+// contentPatterns are the lowercase substrings each category looks for.
+// Control characters are checked rune by rune, hidden text by ScanHiddenText
+// and prompt injections by ScanPromptInjection instead.
+var contentPatterns = map[ContentCategory][]string{
+	ContentScripts: {
+		"<script",
+		"javascript:",
+		"vbscript:",
+		"eval(",
+		"exec(",
+		"onload=",
+		"onerror=",
+		"onclick=",
+	},
+	ContentDataURIs: {
+		"data:text/html",
+		"data:text/javascript",
+		"data:application/javascript",
+	},
+}
+
+// ContentPolicy maps each category to the action taken on content matching it.
+// Categories missing from the map are allowed.
+type ContentPolicy map[ContentCategory]ContentAction
+
+// ContentProfile returns the policy of the named profile
+func ContentProfile(name string) (ContentPolicy, error) {
+	switch name {
+	case ContentProfileStrict:
+		return ContentPolicy{
+			ContentControlChars:    ContentBlock,
+			ContentHiddenText:      ContentBlock,
+			ContentScripts:         ContentBlock,
+			ContentDataURIs:        ContentBlock,
+			ContentPromptInjection: ContentBlock,
+		}, nil
+	case ContentProfileStandard:
+		return ContentPolicy{
+			ContentControlChars:    ContentBlock,
+			ContentHiddenText:      ContentStrip,
+			ContentScripts:         ContentBlock,
+			ContentDataURIs:        ContentBlock,
+			ContentPromptInjection: ContentWarn,
+		}, nil
+	case ContentProfilePermissive:
+		return ContentPolicy{
+			ContentControlChars:    ContentWarn,
+			ContentHiddenText:      ContentWarn,
+			ContentScripts:         ContentAllow,
+			ContentDataURIs:        ContentAllow,
+			ContentPromptInjection: ContentWarn,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown content security profile %q (must be %q, %q or %q)",
+			name, ContentProfileStrict, ContentProfileStandard, ContentProfilePermissive)
+	}
+}
+
+// StandardContentPolicy returns the policy of the standard profile, which
+// ValidateContentSecurity applies
+func StandardContentPolicy() ContentPolicy {
+	policy, _ := ContentProfile(ContentProfileStandard)
+	return policy
+}
+
+// ContentFinding is suspicious content matched by a policy that did not allow it
+type ContentFinding struct {
+	Category ContentCategory
+	Pattern  string // Matched pattern or text, empty for control characters
+	Action   ContentAction
+}
+
+// String describes the finding, e.g. "scripts: <script"
+func (f ContentFinding) String() string {
+	if f.Pattern == "" {
+		return string(f.Category)
+	}
+	return fmt.Sprintf("%s: %s", f.Category, f.Pattern)
+}
+
+// Check applies the policy to content. It returns an error for the first
+// finding in a blocked category, and otherwise the findings in warned and
+// stripped categories. Prompt injections are looked for in the content with
+// its hidden text stripped, so they cannot be obfuscated with it.
+func (p ContentPolicy) Check(content string) ([]ContentFinding, error) {
+	var warnings []ContentFinding
+	lowerContent := strings.ToLower(content)
+	for _, category := range ContentCategories {
+		action := p[category]
+		if action == "" || action == ContentAllow {
+			continue
+		}
+
+		var found []ContentFinding
+		switch category {
+		case ContentControlChars:
+			if hasControlChars(content) {
+				found = append(found, ContentFinding{Category: category, Action: action})
+			}
+		case ContentHiddenText:
+			for _, hidden := range ScanHiddenText(content) {
+				found = append(found, ContentFinding{Category: category, Pattern: hidden.String(), Action: action})
+			}
+		case ContentPromptInjection:
+			for _, injection := range ScanPromptInjection(StripHiddenText(content)) {
+				found = append(found, ContentFinding{Category: category, Pattern: injection.Match, Action: action})
+			}
+		default:
+			for _, pattern := range contentPatterns[category] {
+				if strings.Contains(lowerContent, pattern) {
+					found = append(found, ContentFinding{Category: category, Pattern: pattern, Action: action})
+				}
+			}
+		}
+		if len(found) == 0 {
+			continue
+		}
+
+		if action == ContentBlock {
+			return nil, findingError(found[0])
+		}
+		warnings = append(warnings, found...)
+	}
+	return warnings, nil
+}
+
+// Apply checks content like Check and returns it with the hidden text removed
+// when the policy strips it, as it should be served
+func (p ContentPolicy) Apply(content string) (string, []ContentFinding, error) {
+	findings, err := p.Check(content)
+	if err != nil {
+		return "", nil, err
+	}
+	if p[ContentHiddenText] == ContentStrip {
+		content = StripHiddenText(content)
+	}
+	return content, findings, nil
+}
+
+// hasControlChars reports whether content has control characters other than
+// newlines, carriage returns and tabs. Null bytes are control characters.
+func hasControlChars(content string) bool {
+	for _, r := range content {
+		if r < 32 && r != '\n' && r != '\r' && r != '\t' {
+			return true
+		}
+	}
+	return false
+}
+
+// findingError describes a blocked finding
+func findingError(f ContentFinding) error {
+	switch f.Category {
+	case ContentControlChars:
+		return fmt.Errorf("content contains control characters")
+	case ContentHiddenText:
+		return fmt.Errorf("content contains hidden text: %s", f.Pattern)
+	case ContentPromptInjection:
+		return fmt.Errorf("content contains a likely prompt injection: %s", f.Pattern)
+	default:
+		return fmt.Errorf("content contains potentially malicious pattern: %s", f.Pattern)
+	}
+}
+
+// ContentSecurity configures a content policy: a profile, standard when empty,
+// and actions overriding the profile's for single categories.
+//
+//	content_security:
+//	  profile: permissive
+//	  prompt_injection: block
+type ContentSecurity struct {
+	Profile         string        `yaml:"profile,omitempty"`
+	Scripts         ContentAction `yaml:"scripts,omitempty"`
+	DataURIs        ContentAction `yaml:"data_uris,omitempty"`
+	ControlChars    ContentAction `yaml:"control_chars,omitempty"`
+	HiddenText      ContentAction `yaml:"hidden_text,omitempty"`
+	PromptInjection ContentAction `yaml:"prompt_injection,omitempty"`
+}
+
+// overrides returns the category actions set in c
+func (c ContentSecurity) overrides() map[ContentCategory]ContentAction {
+	overrides := make(map[ContentCategory]ContentAction)
+	for category, action := range map[ContentCategory]ContentAction{
+		ContentScripts:         c.Scripts,
+		ContentDataURIs:        c.DataURIs,
+		ContentControlChars:    c.ControlChars,
+		ContentHiddenText:      c.HiddenText,
+		ContentPromptInjection: c.PromptInjection,
+	} {
+		if action != "" {
+			overrides[category] = action
+		}
+	}
+	return overrides
+}
+
+// Validate checks that c names a known profile and known actions
+func (c ContentSecurity) Validate() error {
+	if c.Profile != "" {
+		if _, err := ContentProfile(c.Profile); err != nil {
+			return err
+		}
+	}
+	for category, action := range c.overrides() {
+		if action == ContentStrip && category == ContentHiddenText {
+			continue
+		}
+		if action != ContentBlock && action != ContentWarn && action != ContentAllow {
+			return fmt.Errorf("invalid content security action %q for %s (must be %q, %q or %q)",
+				action, category, ContentBlock, ContentWarn, ContentAllow)
+		}
+	}
+	return nil
+}
+
+// ResolveContentPolicy builds the policy configured by layers, such as the
+// global settings followed by a repository's. Each layer's profile replaces the
+// policy built so far and its category actions override it. Nil layers are
+// skipped; without any profile the standard profile applies.
+func ResolveContentPolicy(layers ...*ContentSecurity) (ContentPolicy, error) {
+	policy := StandardContentPolicy()
+	for _, layer := range layers {
+		if layer == nil {
+			continue
+		}
+		if err := layer.Validate(); err != nil {
+			return nil, err
+		}
+		if layer.Profile != "" {
+			policy, _ = ContentProfile(layer.Profile)
+		}
+		for category, action := range layer.overrides() {
+			policy[category] = action
+		}
+	}
+	return policy, nil
+}
@@ -6,7 +6,10 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 // DirectoryScanOptions configures the behavior of directory scanning operations.
@@ -36,12 +39,31 @@ type DirectoryScanOptions struct {
 	// If nil, standard skip patterns are used. If provided, this takes precedence.
 	DirFilter func(dirname string) bool
 
+	// Exclude is an optional function that reports whether the file or directory at
+	// path, relative to the scan root, is left out, in addition to the other filters.
+	// Excluded directories are not entered. See IgnoreMatcher.Match.
+	Exclude func(path string, isDir bool) bool
+
 	// ValidateFileAccess enables file access validation for each discovered file.
 	// Uses ValidateFileAccess from validation.go to ensure files are readable.
 	// This is optional for performance reasons in cases where you trust the file system.
 	ValidateFileAccess bool
+
+	// IncludeDirs adds an entry for every directory scanned, the root included
+	// as ".", ahead of its contents. Callers can compare the modification times
+	// of these entries to tell whether a later scan would find the same files.
+	IncludeDirs bool
+
+	// Workers bounds how many directories and files are read at once. Reading
+	// in parallel hides the latency of network file systems; results are in
+	// the same order whatever the number of workers. Zero uses
+	// DefaultScanWorkers and one scans serially.
+	Workers int
 }
 
+// DefaultScanWorkers is the number of workers of a scan that does not set one
+const DefaultScanWorkers = 16
+
 // FileInfo represents information about a discovered file during directory scanning.
 // This provides a platform-independent view of file metadata.
 type FileInfo struct {
@@ -80,7 +102,11 @@ type SecureDirectoryScanner struct {
 	results []FileInfo
 
 	// visited tracks visited directories to prevent infinite loops
-	visited map[string]bool
+	visited   map[string]bool
+	visitedMu sync.Mutex
+
+	// workers holds a token for every worker busy besides the scanning goroutine
+	workers chan struct{}
 
 	// scanRoot stores the absolute path of the scan root for security validation
 	scanRoot string
@@ -234,11 +260,18 @@ func (s *SecureDirectoryScanner) ScanDirectory() ([]FileInfo, error) {
 	// Reset state for new scan
 	s.results = []FileInfo{}
 	s.visited = make(map[string]bool)
+	workers := s.opts.Workers
+	if workers <= 0 {
+		workers = DefaultScanWorkers
+	}
+	s.workers = make(chan struct{}, workers-1)
 
 	// Start recursive scan from root
-	if err := s.scanRecursive(".", 1); err != nil {
+	results, err := s.scanRecursive(".", 1)
+	if err != nil {
 		return nil, fmt.Errorf("directory scan failed: %w", err)
 	}
+	s.results = results
 
 	// Return a copy of results to prevent external modification
 	resultsCopy := make([]FileInfo, len(s.results))
@@ -246,33 +279,39 @@ func (s *SecureDirectoryScanner) ScanDirectory() ([]FileInfo, error) {
 	return resultsCopy, nil
 }
 
-// scanRecursive performs the actual recursive directory scanning.
-func (s *SecureDirectoryScanner) scanRecursive(relativePath string, depth int) error {
+// scanRecursive performs the actual recursive directory scanning, returning
+// the directory's results in directory order. Entries are read by a free
+// worker when there is one and by the calling goroutine otherwise, so nested
+// directories cannot exhaust the workers and deadlock.
+func (s *SecureDirectoryScanner) scanRecursive(relativePath string, depth int) ([]FileInfo, error) {
 	// Check depth limit
 	if depth > s.opts.MaxDepth {
-		return nil // Silently stop at max depth
+		return nil, nil // Silently stop at max depth
 	}
 
 	// Clean path and check for loops
 	cleanPath := filepath.Clean(relativePath)
-	if s.visited[cleanPath] {
-		return nil // Skip already visited directory (prevents symlink loops)
-	}
+	s.visitedMu.Lock()
+	seen := s.visited[cleanPath]
 	s.visited[cleanPath] = true
+	s.visitedMu.Unlock()
+	if seen {
+		return nil, nil // Skip already visited directory (prevents symlink loops)
+	}
 
 	// Check if directory should be skipped
 	dirName := filepath.Base(relativePath)
 	if s.shouldSkipDirectory(dirName) {
-		return nil
+		return nil, nil
 	}
 
 	// Open directory within secure root
 	dir, err := s.root.Open(relativePath)
 	if err != nil {
 		if s.opts.SkipUnreadableDirs {
-			return nil // Skip unreadable directories
+			return nil, nil // Skip unreadable directories
 		}
-		return fmt.Errorf("failed to open directory %s: %w", relativePath, err)
+		return nil, fmt.Errorf("failed to open directory %s: %w", relativePath, err)
 	}
 	defer dir.Close()
 
@@ -280,48 +319,94 @@ func (s *SecureDirectoryScanner) scanRecursive(relativePath string, depth int) e
 	entries, err := dir.ReadDir(-1)
 	if err != nil {
 		if s.opts.SkipUnreadableDirs {
-			return nil
+			return nil, nil
 		}
-		return fmt.Errorf("failed to read directory %s: %w", relativePath, err)
-	}
-
-	// Process each entry
-	for _, entry := range entries {
-		entryPath := filepath.Join(relativePath, entry.Name())
-
-		if entry.IsDir() {
-			// Built-in symlink security validation - always enabled
-			fullEntryPath := filepath.Join(s.scanRoot, entryPath)
-			if isLink, err := IsSymlink(fullEntryPath); err == nil && isLink {
-				// Validate symlink security with scan root as allowed path
-				if err := ValidateSymlinkSecurity(fullEntryPath, []string{s.scanRoot}); err != nil {
-					if s.opts.SkipUnreadableDirs {
-						continue // Skip unsafe symlinks
-					}
-					return fmt.Errorf("symlink security check failed for %s: %w", entryPath, err)
-				}
-			}
+		return nil, fmt.Errorf("failed to read directory %s: %w", relativePath, err)
+	}
 
-			// Recursively scan subdirectory
-			if err := s.scanRecursive(entryPath, depth+1); err != nil {
-				return err
+	var results []FileInfo
+	if s.opts.IncludeDirs {
+		if info, err := dir.Stat(); err == nil {
+			results = append(results, FileInfo{
+				Name:    dirName,
+				Path:    relativePath,
+				IsDir:   true,
+				ModTime: info.ModTime(),
+				Mode:    info.Mode(),
+			})
+		}
+	}
+
+	// Process each entry, keeping its results in its own slot so the order
+	// does not depend on which worker finishes first
+	parts := make([][]FileInfo, len(entries))
+	var group errgroup.Group
+	for i, entry := range entries {
+		process := func() error {
+			var err error
+			parts[i], err = s.scanEntry(relativePath, entry, depth)
+			return err
+		}
+		select {
+		case s.workers <- struct{}{}:
+			group.Go(func() error {
+				defer func() { <-s.workers }()
+				return process()
+			})
+		default:
+			if err := process(); err != nil {
+				_ = group.Wait()
+				return nil, err
 			}
-		} else {
-			// Process file entry
-			if s.shouldIncludeFile(entry.Name()) {
-				fileInfo, err := s.createFileInfo(entry, entryPath)
-				if err != nil {
-					if s.opts.SkipUnreadableDirs {
-						continue // Skip files we can't stat
-					}
-					return fmt.Errorf("failed to get file info for %s: %w", entryPath, err)
+		}
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	for _, part := range parts {
+		results = append(results, part...)
+	}
+	return results, nil
+}
+
+// scanEntry returns the results of one entry of the directory at relativePath:
+// the file itself, or the results of the subdirectory
+func (s *SecureDirectoryScanner) scanEntry(relativePath string, entry os.DirEntry, depth int) ([]FileInfo, error) {
+	entryPath := filepath.Join(relativePath, entry.Name())
+	if s.opts.Exclude != nil && s.opts.Exclude(entryPath, entry.IsDir()) {
+		return nil, nil
+	}
+
+	if entry.IsDir() {
+		// Built-in symlink security validation - always enabled
+		fullEntryPath := filepath.Join(s.scanRoot, entryPath)
+		if isLink, err := IsSymlink(fullEntryPath); err == nil && isLink {
+			// Validate symlink security with scan root as allowed path
+			if err := ValidateSymlinkSecurity(fullEntryPath, []string{s.scanRoot}); err != nil {
+				if s.opts.SkipUnreadableDirs {
+					return nil, nil // Skip unsafe symlinks
 				}
-				s.results = append(s.results, fileInfo)
+				return nil, fmt.Errorf("symlink security check failed for %s: %w", entryPath, err)
 			}
 		}
+
+		// Recursively scan subdirectory
+		return s.scanRecursive(entryPath, depth+1)
 	}
 
-	return nil
+	// Process file entry
+	if !s.shouldIncludeFile(entry.Name()) {
+		return nil, nil
+	}
+	fileInfo, err := s.createFileInfo(entry, entryPath)
+	if err != nil {
+		if s.opts.SkipUnreadableDirs {
+			return nil, nil // Skip files we can't stat
+		}
+		return nil, fmt.Errorf("failed to get file info for %s: %w", entryPath, err)
+	}
+	return []FileInfo{fileInfo}, nil
 }
 
 // shouldSkipDirectory determines if a directory should be skipped based on configured rules.
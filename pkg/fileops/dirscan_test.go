@@ -1,6 +1,7 @@
 package fileops
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -335,6 +336,117 @@ func TestSecureDirectoryScanner_FileInfo(t *testing.T) {
 	}
 }
 
+func TestSecureDirectoryScanner_IncludeDirs(t *testing.T) {
+	tempDir := createTempDirStructure(t)
+	defer os.RemoveAll(tempDir)
+
+	scanner, err := NewDirectoryScanner(tempDir, &DirectoryScanOptions{
+		MaxDepth:     5,
+		SkipPatterns: []string{"node_modules", "build"},
+		FileFilter:   func(name string) bool { return strings.HasSuffix(name, ".md") },
+		IncludeDirs:  true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create scanner: %v", err)
+	}
+	defer scanner.Close()
+
+	files, err := scanner.ScanDirectory()
+	if err != nil {
+		t.Fatalf("ScanDirectory() failed: %v", err)
+	}
+
+	var dirs []string
+	for _, file := range files {
+		if file.IsDir {
+			if file.ModTime.IsZero() {
+				t.Errorf("Expected non-zero ModTime for directory %s", file.Path)
+			}
+			dirs = append(dirs, filepath.ToSlash(file.Path))
+		}
+	}
+	slices.Sort(dirs)
+	want := []string{".", "docs", "docs/api", "src", "src/main", "src/test"}
+	if !slices.Equal(dirs, want) {
+		t.Errorf("Expected directories %v, got %v", want, dirs)
+	}
+	if stats := scanner.GetScanStats(); stats.TotalFiles != 3 {
+		t.Errorf("Expected 3 files besides the directories, got %d", stats.TotalFiles)
+	}
+}
+
+func TestSecureDirectoryScanner_Exclude(t *testing.T) {
+	tempDir := createTempDirStructure(t)
+	defer os.RemoveAll(tempDir)
+
+	ignore := NewIgnoreMatcher([]string{"docs/api/", "README.md"})
+	scanner, err := NewDirectoryScanner(tempDir, &DirectoryScanOptions{
+		MaxDepth:   5,
+		FileFilter: func(name string) bool { return strings.HasSuffix(name, ".md") },
+		Exclude:    ignore.Match,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create scanner: %v", err)
+	}
+	defer scanner.Close()
+
+	files, err := scanner.ScanDirectory()
+	if err != nil {
+		t.Fatalf("ScanDirectory() failed: %v", err)
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, filepath.ToSlash(file.Path))
+	}
+	if want := []string{"docs/guide.md"}; !slices.Equal(paths, want) {
+		t.Errorf("Expected %v, got %v", want, paths)
+	}
+}
+
+func TestSecureDirectoryScanner_WorkersKeepOrder(t *testing.T) {
+	tempDir := createTempDir(t)
+	defer os.RemoveAll(tempDir)
+	for i := range 20 {
+		dir := filepath.Join(tempDir, fmt.Sprintf("dir%02d", i), "nested")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory %s: %v", dir, err)
+		}
+		for _, name := range []string{"a.md", "b.md", filepath.Join("..", "c.md")} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte("# Rule"), 0644); err != nil {
+				t.Fatalf("Failed to create file %s: %v", name, err)
+			}
+		}
+	}
+
+	scan := func(workers int) []string {
+		t.Helper()
+		scanner, err := NewDirectoryScanner(tempDir, &DirectoryScanOptions{MaxDepth: 5, IncludeDirs: true, Workers: workers})
+		if err != nil {
+			t.Fatalf("Failed to create scanner: %v", err)
+		}
+		defer scanner.Close()
+		files, err := scanner.ScanDirectory()
+		if err != nil {
+			t.Fatalf("ScanDirectory() failed: %v", err)
+		}
+		var paths []string
+		for _, file := range files {
+			paths = append(paths, file.Path)
+		}
+		return paths
+	}
+
+	serial := scan(1)
+	if len(serial) != 1+20*5 {
+		t.Fatalf("Expected %d entries, got %d", 1+20*5, len(serial))
+	}
+	for range 5 {
+		if parallel := scan(8); !slices.Equal(parallel, serial) {
+			t.Fatalf("Parallel scan order differs from serial scan:\n%v\n%v", parallel, serial)
+		}
+	}
+}
+
 func TestSecureDirectoryScanner_GetScanStats(t *testing.T) {
 	tempDir := createTempDirStructure(t)
 	defer os.RemoveAll(tempDir)
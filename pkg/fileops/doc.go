@@ -42,6 +42,18 @@
 //	    }
 //	}
 //
+// # Content Policies
+//
+// ValidateContentSecurity applies the standard content policy. ContentProfile
+// returns the strict, standard and permissive policies, and ResolveContentPolicy
+// layers ContentSecurity settings on top of each other. ContentPolicy.Check
+// blocks, warns about or allows each category of suspicious patterns, and
+// ContentPolicy.Apply also strips hidden text (see ScanHiddenText) when the
+// policy says so:
+//
+//	policy, err := fileops.ResolveContentPolicy(globalSettings, repoSettings)
+//	served, warnings, err := policy.Apply(string(content))
+//
 // # Atomic Operations
 //
 // Use AtomicCopy() for reliable file transfers that prevent partial writes:
@@ -49,7 +61,26 @@
 //	err := fileops.AtomicCopy(srcPath, destPath)
 //	// Destination appears atomically or remains unchanged on failure
 //
+// AtomicBatchCopy() does the same for several files at once: every destination
+// is written, or on failure every destination is left as it was:
+//
+//	err := fileops.AtomicBatchCopy([]fileops.CopyOp{{Src: a, Dest: x}, {Src: b, Dest: y}})
+//
+// # File Locks
+//
+// Lock() takes an advisory lock (flock on Unix, LockFileEx on Windows) that
+// keeps rulem processes writing the same file or directory apart. The lock
+// file lives in the user's cache directory, never next to the locked path:
+//
+//	lock, err := fileops.Lock(repoDir, fileops.DefaultLockTimeout)
+//	if err != nil {
+//	    return err
+//	}
+//	defer lock.Unlock()
+//
 // # Directory Operations
 //
 // EnsureDirectoryExists() creates directories safely with proper permissions (0755).
+// CheckDirectoryAccess() reports whether an existing directory is readable and writable
+// without creating it, for shared storage that must stay untouched.
 package fileops
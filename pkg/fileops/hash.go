@@ -0,0 +1,37 @@
+package fileops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Content hashes
+//
+// Files are compared by the hex SHA-256 of their bytes, e.g. to find the same
+// rule saved under different names. HashFile streams the file, so large files
+// are never read into memory at once.
+
+// HashContent returns the hex SHA-256 of content
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashFile returns the hex SHA-256 of the content of the file at path, the
+// same as HashContent of the file's bytes
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file for hashing: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,39 @@
+package fileops
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHashFile(t *testing.T) {
+	dir := t.TempDir()
+	path := createTestFile(t, dir, "rule.md", "# rule\n")
+
+	got, err := HashFile(path)
+	if err != nil {
+		t.Fatalf("HashFile failed: %v", err)
+	}
+	// HashFile agrees with HashContent of the same bytes
+	if want := HashContent([]byte("# rule\n")); got != want {
+		t.Errorf("HashFile() = %s, want %s", got, want)
+	}
+	if len(got) != 64 {
+		t.Errorf("HashFile() = %q, want 64 hex digits", got)
+	}
+
+	other := createTestFile(t, dir, "other.md", "# other\n")
+	if otherHash, _ := HashFile(other); otherHash == got {
+		t.Error("different content must hash differently")
+	}
+
+	if _, err := HashFile(filepath.Join(dir, "missing.md")); err == nil {
+		t.Error("hashing a missing file must fail")
+	}
+}
+
+func TestHashContent(t *testing.T) {
+	// Known SHA-256 of the empty input
+	if got, want := HashContent(nil), "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"; got != want {
+		t.Errorf("HashContent(nil) = %s, want %s", got, want)
+	}
+}
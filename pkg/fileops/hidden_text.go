@@ -0,0 +1,169 @@
+package fileops
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// HiddenTextKind classifies text found by ScanHiddenText that reads differently
+// to a human reviewer than to a model
+type HiddenTextKind string
+
+const (
+	HiddenZeroWidth HiddenTextKind = "zero_width" // Invisible characters: zero-width spaces and joiners, tag characters, ...
+	HiddenBidi      HiddenTextKind = "bidi"       // Bidirectional controls that reorder how text is displayed
+	HiddenHomoglyph HiddenTextKind = "homoglyph"  // Latin words spelled with look-alike letters of other scripts
+)
+
+// HiddenTextFinding is hidden text in rule content
+type HiddenTextFinding struct {
+	Line  int // 1-based line of the first occurrence
+	Kind  HiddenTextKind
+	Match string // Code point (e.g. "U+200B") or the obfuscated word
+}
+
+// String describes the finding, e.g. "zero_width U+200B"
+func (f HiddenTextFinding) String() string {
+	return fmt.Sprintf("%s %s", f.Kind, f.Match)
+}
+
+// bidiControls are the characters that change the display order of text, used
+// in "Trojan Source" attacks
+var bidiControls = map[rune]bool{
+	// Marks
+	'\u061C': true, '\u200E': true, '\u200F': true,
+	// Embeddings and overrides
+	'\u202A': true, '\u202B': true, '\u202C': true, '\u202D': true, '\u202E': true,
+	// Isolates
+	'\u2066': true, '\u2067': true, '\u2068': true, '\u2069': true,
+}
+
+// isZeroWidth reports whether r is rendered without any width. Tag characters
+// (U+E0000 to U+E007F) mirror ASCII invisibly and can smuggle whole sentences.
+func isZeroWidth(r rune) bool {
+	switch r {
+	case '\u00AD', '\u034F', '\u180E', '\u200B', '\u200C', '\u200D', '\u2060', '\u2061', '\u2062', '\u2063', '\u2064', '\uFEFF':
+		return true
+	}
+	return r >= 0xE0000 && r <= 0xE007F
+}
+
+// confusables maps Cyrillic, Greek and fullwidth letters to the Latin letters
+// they are indistinguishable from in most fonts
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x',
+	'і': 'i', 'ј': 'j', 'ѕ': 's', 'ԁ': 'd', 'һ': 'h', 'ӏ': 'l', 'ԛ': 'q', 'ԝ': 'w',
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P',
+	'С': 'C', 'Т': 'T', 'Х': 'X', 'І': 'I', 'Ј': 'J', 'Ѕ': 'S', 'Ү': 'Y', 'Ԍ': 'G',
+	// Greek
+	'ο': 'o', 'ν': 'v', 'ι': 'i', 'κ': 'k', 'ρ': 'p', 'υ': 'u',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M',
+	'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+}
+
+// latinLookalike returns the Latin letter r is confusable with
+func latinLookalike(r rune) (rune, bool) {
+	if l, ok := confusables[r]; ok {
+		return l, true
+	}
+	// Fullwidth forms of ASCII letters
+	if (r >= '\uFF21' && r <= '\uFF3A') || (r >= '\uFF41' && r <= '\uFF5A') {
+		return r - 0xFEE0, true
+	}
+	return 0, false
+}
+
+// isASCIILetter reports whether r is an ASCII letter
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// ScanHiddenText returns the hidden text in content, in the order it appears:
+// invisible characters, bidirectional controls, and words mixing Latin letters
+// with look-alikes from other scripts. Words written entirely in another script
+// are not flagged. Each distinct character or word is reported once, at its
+// first occurrence.
+func ScanHiddenText(content string) []HiddenTextFinding {
+	var findings []HiddenTextFinding
+	seen := make(map[string]bool)
+	report := func(line int, kind HiddenTextKind, match string) {
+		if key := string(kind) + match; !seen[key] {
+			seen[key] = true
+			findings = append(findings, HiddenTextFinding{Line: line, Kind: kind, Match: match})
+		}
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		lineNumber := i + 1
+		for offset, r := range line {
+			switch {
+			case offset == 0 && i == 0 && r == '\uFEFF':
+				// A leading byte order mark is an encoding artifact, not hidden text
+			case isZeroWidth(r):
+				report(lineNumber, HiddenZeroWidth, fmt.Sprintf("U+%04X", r))
+			case bidiControls[r]:
+				report(lineNumber, HiddenBidi, fmt.Sprintf("U+%04X", r))
+			}
+		}
+		for _, word := range strings.FieldsFunc(line, func(r rune) bool { return !unicode.IsLetter(r) }) {
+			if isHomoglyphWord(word) {
+				report(lineNumber, HiddenHomoglyph, word)
+			}
+		}
+	}
+	return findings
+}
+
+// isHomoglyphWord reports whether word mixes ASCII letters with letters
+// confusable with them
+func isHomoglyphWord(word string) bool {
+	var latin, lookalike bool
+	for _, r := range word {
+		if isASCIILetter(r) {
+			latin = true
+		} else if _, ok := latinLookalike(r); ok {
+			lookalike = true
+		}
+	}
+	return latin && lookalike
+}
+
+// StripHiddenText removes invisible characters and bidirectional controls from
+// content and spells words mixing scripts with the Latin letters they imitate,
+// so content reads the same to models as it does to reviewers
+func StripHiddenText(content string) string {
+	var b strings.Builder
+	b.Grow(len(content))
+	for i, r := range content {
+		if (isZeroWidth(r) && !(i == 0 && r == '\uFEFF')) || bidiControls[r] {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	stripped := b.String()
+
+	var words []string
+	seen := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(stripped, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if !seen[word] && isHomoglyphWord(word) {
+			seen[word] = true
+			words = append(words, word, foldHomoglyphs(word))
+		}
+	}
+	if len(words) == 0 {
+		return stripped
+	}
+	return strings.NewReplacer(words...).Replace(stripped)
+}
+
+// foldHomoglyphs replaces the look-alike letters of word with Latin ones
+func foldHomoglyphs(word string) string {
+	return strings.Map(func(r rune) rune {
+		if l, ok := latinLookalike(r); ok {
+			return l
+		}
+		return r
+	}, word)
+}
@@ -0,0 +1,165 @@
+package fileops
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Ignore patterns
+//
+// IgnoreMatcher decides which paths a scan leaves out using gitignore syntax:
+// one pattern per line, # comments, ! to re-include, a trailing / to match
+// only directories, a leading or inner / to anchor the pattern to the root,
+// and ** to match any number of directories. A path inside an ignored
+// directory is ignored too, whatever later patterns say, as in git.
+
+// IgnoreMatcher matches relative paths against gitignore patterns. The zero
+// value and nil ignore nothing.
+type IgnoreMatcher struct {
+	patterns []ignorePattern
+}
+
+// ignorePattern is one parsed line of an ignore file
+type ignorePattern struct {
+	segments []string // Slash-separated parts of the pattern
+	negate   bool     // Re-includes what earlier patterns ignored
+	dirOnly  bool     // Matches directories only
+	anchored bool     // Matches from the root rather than at any depth
+}
+
+// NewIgnoreMatcher parses gitignore patterns, one per entry. Blank entries and
+// comments are skipped; later patterns take precedence over earlier ones.
+func NewIgnoreMatcher(patterns []string) *IgnoreMatcher {
+	m := &IgnoreMatcher{}
+	for _, line := range patterns {
+		if p, ok := parseIgnorePattern(line); ok {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+	return m
+}
+
+// parseIgnorePattern parses one line of an ignore file
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	var p ignorePattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+	p.segments = strings.Split(line, "/")
+	return p, true
+}
+
+// ReadIgnoreFile returns the lines of the ignore file at path, or none when
+// the file does not exist
+func ReadIgnoreFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ignore file: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read ignore file %s: %w", path, err)
+	}
+	return lines, nil
+}
+
+// Match reports whether the file or directory at relPath, relative to the
+// root the patterns apply to, is ignored, either itself or because one of its
+// parent directories is
+func (m *IgnoreMatcher) Match(relPath string, isDir bool) bool {
+	if m == nil || len(m.patterns) == 0 {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(relPath)), "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matchParts(parts[:i], true) {
+			return true
+		}
+	}
+	return m.matchParts(parts, isDir)
+}
+
+// matchParts applies the patterns to a path, the last matching pattern
+// deciding whether it is ignored
+func (m *IgnoreMatcher) matchParts(parts []string, isDir bool) bool {
+	ignored := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.matches(parts) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the pattern selects the path made of parts
+func (p ignorePattern) matches(parts []string) bool {
+	if !p.anchored {
+		ok, _ := path.Match(p.segments[0], parts[len(parts)-1])
+		return ok
+	}
+	return matchSegments(p.segments, parts)
+}
+
+// matchSegments matches pattern segments against path parts, ** matching any
+// number of parts
+func matchSegments(segments, parts []string) bool {
+	if len(segments) == 0 {
+		return len(parts) == 0
+	}
+	if segments[0] == "**" {
+		if len(segments) == 1 {
+			// A trailing ** matches everything inside, not the directory itself
+			return len(parts) > 0
+		}
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(segments[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(segments[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(segments[1:], parts[1:])
+}
@@ -0,0 +1,85 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestIgnoreMatcher_Match(t *testing.T) {
+	m := NewIgnoreMatcher([]string{
+		"# dependencies",
+		"node_modules/",
+		"",
+		"*.draft.md",
+		"/notes.md",
+		"docs/internal",
+		"docs/**/generated/",
+		"archive/**",
+		"!archive/keep.md",
+		`\#literal.md`,
+	})
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"node_modules", true, true},
+		{"web/node_modules/pkg/README.md", false, true},
+		{"node_modules", false, false}, // Directory-only pattern
+		{"style.draft.md", false, true},
+		{"go/errors.draft.md", false, true},
+		{"notes.md", false, true},
+		{"go/notes.md", false, false}, // Anchored to the root
+		{"docs/internal/secret.md", false, true},
+		{"go/docs/internal/secret.md", false, false},
+		{"docs/api/v1/generated/types.md", false, true},
+		{"docs/generated/types.md", false, true},
+		{"archive/old.md", false, true},
+		{"archive/keep.md", false, false}, // Re-included
+		{"archive", true, false},
+		{"#literal.md", false, true},
+		{"style.md", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	var none *IgnoreMatcher
+	if none.Match("node_modules", true) {
+		t.Error("a nil matcher should ignore nothing")
+	}
+}
+
+func TestIgnoreMatcher_IgnoredDirectoryCannotBeReincluded(t *testing.T) {
+	m := NewIgnoreMatcher([]string{"vendor/", "!vendor/rules.md"})
+	if !m.Match("vendor/rules.md", false) {
+		t.Error("a file in an ignored directory should stay ignored")
+	}
+}
+
+func TestReadIgnoreFile(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	lines, err := ReadIgnoreFile(filepath.Join(dir, ".rulemignore"))
+	if err != nil || lines != nil {
+		t.Errorf("ReadIgnoreFile() of a missing file = %v, %v; want nothing", lines, err)
+	}
+
+	path := filepath.Join(dir, ".rulemignore")
+	if err := os.WriteFile(path, []byte("# comment\nvendor/\r\n*.tmp.md\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+	lines, err = ReadIgnoreFile(path)
+	if err != nil {
+		t.Fatalf("ReadIgnoreFile() failed: %v", err)
+	}
+	if want := []string{"# comment", "vendor/", "*.tmp.md"}; !slices.Equal(lines, want) {
+		t.Errorf("ReadIgnoreFile() = %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,90 @@
+package fileops
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// InjectionKind classifies a likely prompt injection found by ScanPromptInjection
+type InjectionKind string
+
+const (
+	InjectionIgnoreInstructions InjectionKind = "ignore_instructions" // Tells the assistant to drop its instructions
+	InjectionRevealPrompt       InjectionKind = "reveal_prompt"       // Asks for the system prompt or hidden instructions
+	InjectionExfiltration       InjectionKind = "exfiltration"        // Tells the assistant to send secrets somewhere
+	InjectionConcealment        InjectionKind = "concealment"         // Tells the assistant to hide something from the user
+	InjectionHiddenDirective    InjectionKind = "hidden_directive"    // Addresses the assistant from an HTML comment, unseen when rendered
+)
+
+// InjectionFinding is a likely prompt injection in rule content
+type InjectionFinding struct {
+	Line  int // 1-based line the match starts on
+	Kind  InjectionKind
+	Match string // Matched text, shortened and on one line
+}
+
+// maxInjectionMatch is the longest match kept in a finding
+const maxInjectionMatch = 80
+
+// injectionPatterns are the heuristics applied to the whole content. Rules
+// commonly tell assistants what not to do, so the patterns look for the
+// wording of attacks rather than single words.
+var injectionPatterns = []struct {
+	kind    InjectionKind
+	pattern *regexp.Regexp
+}{
+	{InjectionIgnoreInstructions, regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override)\s+(?:(?:all|any|the|your|of|these)\s+)*(?:previous|prior|above|earlier|preceding|system|original)\s+(?:instructions|prompts?|rules|directions|guidelines)\b`)},
+	{InjectionRevealPrompt, regexp.MustCompile(`(?i)\b(?:reveal|print|show|output|repeat|disclose|leak)\s+(?:\w+\s+){0,3}?(?:system\s+prompt|hidden\s+instructions|initial\s+instructions)\b`)},
+	{InjectionExfiltration, regexp.MustCompile(`(?i)\b(?:send|post|upload|exfiltrate|transmit|forward|email)\s+[^.\n]{0,60}?\b(?:secrets?|credentials|api[ _-]?keys?|access\s+tokens?|passwords?|private\s+keys?|ssh\s+keys?|\.env|environment\s+variables)\b[^.\n]{0,60}?\b(?:to|into)\s+(?:https?://\S+|[\w.+-]+@[\w-]+\.\w+|(?:an?\s+|the\s+|this\s+)?(?:external\s+|remote\s+)?(?:url|endpoint|webhook)\b)`)},
+	{InjectionExfiltration, regexp.MustCompile(`(?i)\b(?:curl|wget)\b[^\n]*\$\{?\w*(?:token|secret|key|password)\w*`)},
+	{InjectionConcealment, regexp.MustCompile(`(?i)\b(?:do\s+not|don'?t|never)\s+(?:tell|inform|mention\s+(?:this\s+|it\s+)?to|reveal\s+(?:this\s+|it\s+)?to)\s+(?:the\s+)?user\b`)},
+}
+
+// negationPattern matches wording before an exfiltration match that turns it
+// into a prohibition, e.g. "never send secrets to a webhook"
+var negationPattern = regexp.MustCompile(`(?i)\b(?:never|not|don'?t|avoid|must\s+not)\b[^.\n]{0,20}$`)
+
+// htmlCommentPattern matches HTML comments, which markdown renderers hide
+var htmlCommentPattern = regexp.MustCompile(`(?s)<!--(.*?)-->`)
+
+// directivePattern matches wording that addresses an assistant
+var directivePattern = regexp.MustCompile(`(?i)\b(?:ignore|disregard)\b|\byou\s+(?:must|should|will|are\s+now)\b|\b(?:assistant|ai|llm|model)\s*(?:[,:]|must\b|should\b)|\bdo\s+not\s+(?:tell|mention|reveal)\b|\bsystem\s+prompt\b`)
+
+// ScanPromptInjection returns the likely prompt injections in content, in the
+// order they appear. It is a heuristic: it flags instructions to ignore prior
+// instructions, reveal the system prompt, send secrets elsewhere or hide
+// things from the user, and directives hidden in HTML comments.
+func ScanPromptInjection(content string) []InjectionFinding {
+	var findings []InjectionFinding
+	for _, p := range injectionPatterns {
+		for _, loc := range p.pattern.FindAllStringIndex(content, -1) {
+			if p.kind == InjectionExfiltration && negationPattern.MatchString(content[:loc[0]]) {
+				continue
+			}
+			findings = append(findings, newInjectionFinding(content, loc[0], content[loc[0]:loc[1]], p.kind))
+		}
+	}
+
+	for _, loc := range htmlCommentPattern.FindAllStringSubmatchIndex(content, -1) {
+		if directivePattern.MatchString(content[loc[2]:loc[3]]) {
+			findings = append(findings, newInjectionFinding(content, loc[0], content[loc[0]:loc[1]], InjectionHiddenDirective))
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings
+}
+
+// newInjectionFinding describes the match at offset in content
+func newInjectionFinding(content string, offset int, match string, kind InjectionKind) InjectionFinding {
+	match = strings.Join(strings.Fields(match), " ")
+	if runes := []rune(match); len(runes) > maxInjectionMatch {
+		match = string(runes[:maxInjectionMatch]) + "..."
+	}
+	return InjectionFinding{
+		Line:  strings.Count(content[:offset], "\n") + 1,
+		Kind:  kind,
+		Match: match,
+	}
+}
@@ -0,0 +1,133 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File locks
+//
+// The TUI, `rulem mcp` and `rulem sync` run from cron may write the same
+// files at once: the config, rules saved into a repository, or a clone being
+// synced. FileLock is an advisory lock on a lock file, taken with flock on
+// Unix and LockFileEx on Windows, that these writers take first so they
+// never interleave. The operating system releases the lock when its holder
+// exits, so a killed process never leaves a stale lock behind. Locks are
+// advisory: they only keep out other writers that take the same lock.
+//
+// Each lock is held by one open file, so two goroutines of one process
+// exclude each other as two processes do. Locks are not reentrant: a holder
+// that asks for the same lock again waits for itself until the timeout.
+
+// DefaultLockTimeout is how long writers wait for a lock. It exceeds the
+// longest a sync holds the lock of its clone for.
+const DefaultLockTimeout = 90 * time.Second
+
+// lockRetryInterval is how often a busy lock is tried again
+const lockRetryInterval = 50 * time.Millisecond
+
+// ErrLockTimeout is returned when a lock is still held by another writer
+// after the timeout
+var ErrLockTimeout = errors.New("timed out waiting for another rulem process to finish writing")
+
+// errLocked is returned by tryLock when another holder has the lock
+var errLocked = errors.New("file is locked")
+
+// FileLock is a held advisory lock, released with Unlock
+type FileLock struct {
+	file *os.File
+}
+
+// LockFile takes the exclusive lock of the lock file at path, creating the
+// file and its directory when needed, and waits up to timeout for another
+// holder to release it.
+//
+// Parameters:
+//   - path: Path of the lock file; use Lock to lock a file or directory by its own path
+//   - timeout: How long to wait for the lock; 0 tries once
+//
+// Returns:
+//   - *FileLock: The held lock, to be released with Unlock
+//   - error: ErrLockTimeout when the lock stayed busy, or file system errors
+//
+// Usage example:
+//
+//	lock, err := fileops.LockFile(filepath.Join(stateDir, "rulem.lock"), fileops.DefaultLockTimeout)
+//	if err != nil {
+//	    return err
+//	}
+//	defer lock.Unlock()
+func LockFile(path string, timeout time.Duration) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLock(file)
+		if err == nil {
+			return &FileLock{file: file}, nil
+		}
+		if !errors.Is(err, errLocked) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w (lock %s)", ErrLockTimeout, path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Lock takes the lock of path, a file or a directory, kept at LockPath(path)
+// so nothing is written next to path. The config and rule repositories are
+// locked this way, by every write to them and by syncs of the repositories.
+func Lock(path string, timeout time.Duration) (*FileLock, error) {
+	return LockFile(LockPath(path), timeout)
+}
+
+// LockPath returns the lock file of path, in the user's cache directory (or
+// $RULEM_LOCK_DIR) and named after a hash of the absolute path with symlinks
+// resolved, so every path to the same file or directory shares one lock
+func LockPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	name := HashContent([]byte(filepath.Clean(path)))[:16] + ".lock"
+	if testDir := os.Getenv("RULEM_LOCK_DIR"); testDir != "" {
+		return filepath.Join(testDir, name)
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "rulem", "locks", name)
+}
+
+// Unlock releases the lock. The lock file is kept for the next holder.
+func (l *FileLock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := unlock(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
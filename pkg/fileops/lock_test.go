@@ -0,0 +1,113 @@
+package fileops
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "config.yaml.lock")
+
+	lock, err := LockFile(path, 0)
+	if err != nil {
+		t.Fatalf("LockFile failed: %v", err)
+	}
+	if !fileExists(path) {
+		t.Error("the lock file and its directory must be created")
+	}
+
+	// A second holder, in this process or another, has to wait
+	if _, err := LockFile(path, 100*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("LockFile() on a held lock = %v, want ErrLockTimeout", err)
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Errorf("a second Unlock must do nothing, got %v", err)
+	}
+	again, err := LockFile(path, 0)
+	if err != nil {
+		t.Fatalf("LockFile after Unlock failed: %v", err)
+	}
+	again.Unlock()
+}
+
+func TestLockFileWaits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.lock")
+	lock, err := LockFile(path, 0)
+	if err != nil {
+		t.Fatalf("LockFile failed: %v", err)
+	}
+
+	// Writers taking turns never run at the same time
+	var mu sync.Mutex
+	held := 0
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l, err := LockFile(path, 5*time.Second)
+			if err != nil {
+				t.Errorf("LockFile failed: %v", err)
+				return
+			}
+			mu.Lock()
+			held++
+			if held > 1 {
+				t.Error("two holders of the same lock")
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			held--
+			mu.Unlock()
+			l.Unlock()
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	lock.Unlock()
+	wg.Wait()
+}
+
+func TestLockPath(t *testing.T) {
+	locks := t.TempDir()
+	t.Setenv("RULEM_LOCK_DIR", locks)
+
+	dir := t.TempDir()
+	link := filepath.Join(t.TempDir(), "rules")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	path := LockPath(dir)
+	if LockPath(link) != path || LockPath(dir+string(filepath.Separator)) != path {
+		t.Error("every path to a directory must share its lock")
+	}
+	if LockPath(t.TempDir()) == path {
+		t.Error("different directories must have different locks")
+	}
+	if filepath.Dir(path) != locks || strings.HasPrefix(path, dir) {
+		t.Errorf("lock path %s must be in $RULEM_LOCK_DIR, outside the locked directory", path)
+	}
+
+	lock, err := Lock(link, 0)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	defer lock.Unlock()
+	if _, err := Lock(dir, 0); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("Lock() through another path = %v, want ErrLockTimeout", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Error("locking a directory must not write into it")
+	}
+}
@@ -235,6 +235,50 @@ func SanitizeFilename(filename string) (string, error) {
 	return clean, nil
 }
 
+// SanitizeSubdirectory validates a directory path relative to a storage root,
+// such as the folder a rule is saved into, and returns it cleaned with forward
+// slashes. Unlike SanitizeFilename it keeps the path components, but it rejects
+// anything that could leave the root.
+//
+// Parameters:
+//   - subdir: Relative directory path; empty string or "." means the root itself
+//
+// Returns:
+//   - string: Cleaned relative path, empty string for the root
+//   - error: Validation errors for absolute paths, traversal and .git
+//
+// Usage example:
+//
+//	clean, err := fileops.SanitizeSubdirectory("frontend//react/")
+//	// clean will be "frontend/react"
+func SanitizeSubdirectory(subdir string) (string, error) {
+	trimmed := strings.TrimSpace(subdir)
+	if trimmed == "" {
+		return "", nil
+	}
+	if filepath.IsAbs(trimmed) || strings.HasPrefix(trimmed, "/") {
+		return "", fmt.Errorf("directory must be relative: %q", subdir)
+	}
+
+	clean := filepath.ToSlash(filepath.Clean(trimmed))
+	if clean == "." {
+		return "", nil
+	}
+	for _, part := range strings.Split(clean, "/") {
+		if strings.TrimSpace(part) != part {
+			return "", fmt.Errorf("directory name has leading or trailing spaces: %q", part)
+		}
+		if part == ".." {
+			return "", fmt.Errorf("directory escapes the storage root: %q", subdir)
+		}
+		// Git metadata is never a place for rules
+		if part == ".git" {
+			return "", fmt.Errorf("directory cannot be inside .git: %q", subdir)
+		}
+	}
+	return clean, nil
+}
+
 // ValidateFileAccess checks if a file exists and is accessible with specified permissions.
 // This function provides a way to verify file accessibility before performing operations.
 //
@@ -535,6 +579,61 @@ func ValidateDirectoryWritable(dirPath string) error {
 	return nil
 }
 
+// DirectoryAccess describes what the current user can do in a directory.
+type DirectoryAccess struct {
+	Readable bool // Entries can be listed
+	Writable bool // Files can be created
+}
+
+// CheckDirectoryAccess reports whether an existing directory can be read and
+// written by the current user. Unlike ValidateDirectoryWritable it never
+// creates the directory, so it is safe to use on shared storage that must not
+// be modified.
+//
+// Parameters:
+//   - dirPath: The directory to check
+//
+// Returns:
+//   - DirectoryAccess: The permissions the current user has
+//   - error: The path does not exist or is not a directory
+//
+// Writability is probed by creating and removing a temporary file, because
+// permission bits alone do not account for ACLs or read-only mounts.
+//
+// Usage example:
+//
+//	access, err := fileops.CheckDirectoryAccess("/opt/rules")
+//	if err == nil && !access.Writable {
+//	    // Redirect writes to a per-user directory
+//	}
+func CheckDirectoryAccess(dirPath string) (DirectoryAccess, error) {
+	expandedPath := ExpandPath(strings.TrimSpace(dirPath))
+
+	info, err := os.Stat(expandedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DirectoryAccess{}, fmt.Errorf("directory does not exist: %s", expandedPath)
+		}
+		return DirectoryAccess{}, fmt.Errorf("cannot access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return DirectoryAccess{}, fmt.Errorf("path is not a directory: %s", expandedPath)
+	}
+
+	var access DirectoryAccess
+	if dir, err := os.Open(expandedPath); err == nil {
+		_, err = dir.Readdirnames(1)
+		access.Readable = err == nil || err == io.EOF
+		dir.Close()
+	}
+	if probe, err := os.CreateTemp(expandedPath, ".fileops-access-*"); err == nil {
+		probe.Close()
+		os.Remove(probe.Name())
+		access.Writable = true
+	}
+	return access, nil
+}
+
 // ValidatePathInHome checks if a path is within the user's home directory
 // and returns the relative path from home. This function helps ensure
 // paths don't escape the user's home directory boundary.
@@ -660,11 +759,14 @@ func ValidateStoragePath(path string) error {
 // Returns:
 //   - error: Validation error if suspicious content is detected
 //
-// The function checks for:
+// The function applies the standard content policy (see ContentPolicy), which blocks:
 //   - Control characters (except newlines, carriage returns, and tabs)
 //   - Null bytes
 //   - Script injection patterns (script tags, javascript:, eval, etc.)
-//   - Other potentially dangerous content patterns
+//   - Data URIs carrying HTML or scripts
+//
+// Prompt injection phrases are only warned about by the standard policy, so
+// they pass. Use ContentPolicy.Check to apply another policy or see warnings.
 //
 // Usage example:
 //
@@ -672,39 +774,8 @@ func ValidateStoragePath(path string) error {
 //	    return fmt.Errorf("suspicious content detected: %w", err)
 //	}
 func ValidateContentSecurity(content string) error {
-	// Check for control characters (except newlines and tabs)
-	for _, r := range content {
-		if r < 32 && r != '\n' && r != '\r' && r != '\t' {
-			return fmt.Errorf("content contains control characters")
-		}
-	}
-
-	// Check for null bytes
-	if strings.Contains(content, "\x00") {
-		return fmt.Errorf("content contains null bytes")
-	}
-
-	// Check for script injection patterns
-	suspiciousPatterns := []string{
-		"<script",
-		"javascript:",
-		"vbscript:",
-		"data:text/html",
-		"eval(",
-		"exec(",
-		"onload=",
-		"onerror=",
-		"onclick=",
-	}
-
-	lowerContent := strings.ToLower(content)
-	for _, pattern := range suspiciousPatterns {
-		if strings.Contains(lowerContent, pattern) {
-			return fmt.Errorf("content contains potentially malicious pattern: %s", pattern)
-		}
-	}
-
-	return nil
+	_, err := StandardContentPolicy().Check(content)
+	return err
 }
 
 // SanitizeIdentifier sanitizes a string to be safe for use as an identifier.
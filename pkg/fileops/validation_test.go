@@ -351,6 +351,43 @@ func TestSanitizeFilename(t *testing.T) {
 	}
 }
 
+func TestSanitizeSubdirectory(t *testing.T) {
+	tests := []struct {
+		name      string
+		subdir    string
+		expected  string
+		errorText string
+	}{
+		{name: "root", subdir: "", expected: ""},
+		{name: "dot is root", subdir: " . ", expected: ""},
+		{name: "single folder", subdir: "frontend", expected: "frontend"},
+		{name: "nested with redundant slashes", subdir: "frontend//react/", expected: "frontend/react"},
+		{name: "inner traversal stays inside", subdir: "a/../b", expected: "b"},
+		{name: "hidden folder allowed", subdir: ".cursor/rules", expected: ".cursor/rules"},
+		{name: "absolute path", subdir: "/etc", errorText: "must be relative"},
+		{name: "escapes root", subdir: "a/../../etc", errorText: "escapes the storage root"},
+		{name: "git metadata", subdir: ".git/hooks", errorText: "inside .git"},
+		{name: "padded component", subdir: "a/ b", errorText: "leading or trailing spaces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SanitizeSubdirectory(tt.subdir)
+			if tt.errorText != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorText) {
+					t.Errorf("Expected error containing %q, got: %v", tt.errorText, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			} else if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
 // Tests for ValidateFileAccess
 
 func TestValidateFileAccess(t *testing.T) {
@@ -676,6 +713,81 @@ func TestValidateDirectoryWritable(t *testing.T) {
 	}
 }
 
+// Tests for CheckDirectoryAccess
+
+func TestCheckDirectoryAccess(t *testing.T) {
+	tempDir := createTempDir(t)
+	defer os.RemoveAll(tempDir)
+
+	tests := []struct {
+		name      string
+		setup     func() string
+		want      DirectoryAccess
+		skipRoot  bool
+		errorText string
+	}{
+		{
+			name:  "readable and writable directory",
+			setup: func() string { return tempDir },
+			want:  DirectoryAccess{Readable: true, Writable: true},
+		},
+		{
+			name: "read-only directory",
+			setup: func() string {
+				dir := filepath.Join(tempDir, "readonly")
+				if err := os.Mkdir(dir, 0555); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+				t.Cleanup(func() { os.Chmod(dir, 0755) })
+				return dir
+			},
+			want:     DirectoryAccess{Readable: true},
+			skipRoot: true,
+		},
+		{
+			name:      "missing directory",
+			setup:     func() string { return filepath.Join(tempDir, "missing") },
+			errorText: "does not exist",
+		},
+		{
+			name: "file instead of directory",
+			setup: func() string {
+				return createTestFile(t, tempDir, "file.txt", "content")
+			},
+			errorText: "not a directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.skipRoot && os.Geteuid() == 0 {
+				t.Skip("Permission bits are not enforced for root")
+			}
+			dirPath := tt.setup()
+
+			access, err := CheckDirectoryAccess(dirPath)
+			if tt.errorText != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorText) {
+					t.Errorf("CheckDirectoryAccess(%q) error = %v, want error containing %q", dirPath, err, tt.errorText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckDirectoryAccess(%q) unexpected error: %v", dirPath, err)
+			}
+			if access != tt.want {
+				t.Errorf("CheckDirectoryAccess(%q) = %+v, want %+v", dirPath, access, tt.want)
+			}
+			entries, _ := os.ReadDir(dirPath)
+			for _, entry := range entries {
+				if strings.HasPrefix(entry.Name(), ".fileops-access-") {
+					t.Errorf("CheckDirectoryAccess(%q) left %s behind", dirPath, entry.Name())
+				}
+			}
+		})
+	}
+}
+
 // Tests for ValidatePathInHome
 
 func TestValidatePathInHome(t *testing.T) {